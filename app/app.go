@@ -11,6 +11,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/objectstorage"
 
 	"github.com/redis/go-redis/v9"
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -28,6 +29,8 @@ var (
 	ES *elasticsearch.TypedClient
 	// Etcd Etcd 客户端实例，用于服务发现或分布式配置
 	Etcd *clientv3.Client
+	// Storage 对象存储客户端实例（S3 / OSS / MinIO），开启 system.useObjectStorage 后初始化
+	Storage *objectstorage.Client
 	// DBList 多数据库连接池，按别名索引。并发访问需通过 GetDbByName 方法
 	DBList map[string]*gorm.DB
 	// Redis 主 Redis 客户端实例（支持单机/哨兵/集群模式）
@@ -40,6 +43,9 @@ var (
 	// key: queueInfo (string), value: *config.MessageQueue
 	// 使用 sync.Map 替代 map + mutex，提供更好的并发读写性能
 	RabbitMQProducerList sync.Map
+	// RabbitMQConsumerList 使用 sync.Map 存储启动的 RabbitMQ 消费者，用于汇总消费统计
+	// key: queueInfo (string), value: *config.MessageQueue
+	RabbitMQConsumerList sync.Map
 	// Config 用户自定义配置指针，默认指向 BaseConfig，可在启动前替换为包含业务字段的扩展配置结构体
 	Config any = new(config.BaseConfig)
 	// Logger 全局日志实例（logrus），在框架启动时初始化
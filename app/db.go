@@ -1,11 +1,72 @@
 package app
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/model/config"
 	"gorm.io/gorm"
 )
 
+// DBResolverAliasName DBStats 中数据库解析器（读写分离）使用的别名
+const DBResolverAliasName = "resolver"
+
+// dbSessionKey 请求级数据库会话在 gin 上下文中的存储键
+const dbSessionKey = "_ginCore_dbSession"
+
+// dbSession 缓存在 gin 上下文中的请求级数据库会话，ctx 为创建会话时请求的 context.Context
+type dbSession struct {
+	ctx context.Context
+	db  *gorm.DB
+}
+
+// DBWithContext 获取绑定当前请求 context.Context 的主数据库会话
+// 会话在同一请求内缓存，重复调用返回同一个实例；请求的 context.Context 被替换（如超时中间件）后会重新创建。
+// 请求取消或超时时，正在执行的查询会被中断并返回 context 错误；
+// 请求上下文携带 traceId，开启 db.traceComment 时 SQL 会附带 /* trace:<traceId> */ 注释
+//
+// 参数：
+//   - c: gin 上下文
+//
+// 返回：
+//   - *gorm.DB: 数据库会话，主数据库未初始化时为 nil
+//
+// 使用示例：
+//
+//	var user User
+//	err := app.DBWithContext(c).Where("id = ?", id).First(&user).Error
+func DBWithContext(c *gin.Context) *gorm.DB {
+	if DB == nil {
+		return nil
+	}
+	ctx := c.Request.Context()
+	if v, ok := c.Get(dbSessionKey); ok {
+		if session, ok := v.(*dbSession); ok && session.ctx == ctx {
+			return session.db
+		}
+	}
+	db := DB.WithContext(ctx)
+	c.Set(dbSessionKey, &dbSession{ctx: ctx, db: db})
+	return db
+}
+
+// DBWithStdContext 获取绑定指定 context.Context 的主数据库会话，用于没有 gin 上下文的场景（如异步任务）
+// 每次调用都会创建新的会话，ctx 中的 RequestContext 或 OpenTelemetry span 提供 traceId
+//
+// 参数：
+//   - ctx: 上下文
+//
+// 返回：
+//   - *gorm.DB: 数据库会话，主数据库未初始化时为 nil
+func DBWithStdContext(ctx context.Context) *gorm.DB {
+	if DB == nil {
+		return nil
+	}
+	return DB.WithContext(ctx)
+}
+
 // GetDbByName 通过名称获取db，如果不存在则返回错误
 // 参数：
 //   - dbname: 数据库别名
@@ -23,6 +84,55 @@ func GetDbByName(dbname string) (*gorm.DB, error) {
 	return db, nil
 }
 
+// DBStats 获取数据库连接池统计信息，包含打开、使用中、空闲的连接数和等待连接的次数、时间
+// 主数据库的别名为 db 配置的 aliasName，未配置时为 config.DefaultDbAliasName；
+// 数据库解析器的别名为 DBResolverAliasName；多数据库列表使用各自的 aliasName
+// 参数：
+//   - alias: 数据库别名，为空时返回所有已初始化的数据库，不存在的别名会被忽略
+//
+// 返回：
+//   - map[string]sql.DBStats: 按别名索引的连接池统计信息
+func DBStats(alias ...string) map[string]sql.DBStats {
+	all := make(map[string]*gorm.DB)
+	if DB != nil {
+		mainAlias := config.DefaultDbAliasName
+		if BaseConfig.Db != nil {
+			mainAlias = BaseConfig.Db.GetAliasName()
+		}
+		all[mainAlias] = DB
+	}
+	if DBResolver != nil {
+		all[DBResolverAliasName] = DBResolver
+	}
+	lock.RLock()
+	for name, db := range DBList {
+		if db != nil {
+			all[name] = db
+		}
+	}
+	lock.RUnlock()
+
+	if len(alias) > 0 {
+		selected := make(map[string]*gorm.DB, len(alias))
+		for _, name := range alias {
+			if db, ok := all[name]; ok {
+				selected[name] = db
+			}
+		}
+		all = selected
+	}
+
+	stats := make(map[string]sql.DBStats, len(all))
+	for name, db := range all {
+		sqlDB, err := db.DB()
+		if err != nil {
+			continue
+		}
+		stats[name] = sqlDB.Stats()
+	}
+	return stats
+}
+
 // closeGormDB 关闭单个 gorm.DB 实例的底层 sql.DB 连接
 func closeGormDB(db *gorm.DB) error {
 	if db == nil {
@@ -0,0 +1,178 @@
+// Package app 数据库连接池统计测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含数据库连接池统计的单元测试，使用 SQLite 内存数据库，不需要 MySQL。
+//
+// 测试覆盖内容：
+// 1. 返回主数据库、数据库解析器和多数据库列表中每个别名的统计信息
+// 2. 按别名筛选，不存在的别名被忽略
+// 3. DBWithContext 在同一请求内返回同一个会话，请求的 context.Context 被替换后重新创建
+// 4. 请求 context 超时时，正在执行的查询被中断并返回 context.DeadlineExceeded
+//
+// 运行测试：go test -v ./app/... -run "DBStats|DBWithContext"
+// ==================================================
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/model/config"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// openStatsTestDB 打开 SQLite 内存数据库并设置最大打开连接数
+func openStatsTestDB(t *testing.T, maxOpenConns int) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取 sqlDB 失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(maxOpenConns)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db
+}
+
+// setupStatsTestDBs 设置主数据库、多数据库列表，测试结束后恢复
+func setupStatsTestDBs(t *testing.T) {
+	originalDB, originalResolver, originalList, originalConfig := DB, DBResolver, DBList, BaseConfig.Db
+	t.Cleanup(func() {
+		DB, DBResolver, DBList, BaseConfig.Db = originalDB, originalResolver, originalList, originalConfig
+	})
+
+	DB = openStatsTestDB(t, 1)
+	DBResolver = nil
+	BaseConfig.Db = &config.DbInfo{AliasName: "main"}
+	DBList = map[string]*gorm.DB{
+		"order": openStatsTestDB(t, 2),
+		"user":  openStatsTestDB(t, 3),
+	}
+}
+
+// TestDBStats 测试获取所有数据库的连接池统计
+//
+// 【功能点】验证返回每个已初始化数据库的统计信息，主数据库使用配置的别名，未配置时使用默认别名
+// 【测试流程】
+//  1. 设置主数据库（别名 main）和多数据库列表（order、user），各自的最大打开连接数不同
+//  2. 断言返回 3 个别名，最大打开连接数与各自的设置一致
+//  3. 设置数据库解析器、清空主数据库别名，断言包含 default 和 resolver
+func TestDBStats(t *testing.T) {
+	setupStatsTestDBs(t)
+
+	stats := DBStats()
+	want := map[string]int{"main": 1, "order": 2, "user": 3}
+	if len(stats) != len(want) {
+		t.Fatalf("统计信息数量 = %d, 期望 %d: %v", len(stats), len(want), stats)
+	}
+	for alias, maxOpen := range want {
+		if got := stats[alias].MaxOpenConnections; got != maxOpen {
+			t.Errorf("%s 的最大打开连接数 = %d, 期望 %d", alias, got, maxOpen)
+		}
+	}
+
+	DBResolver = openStatsTestDB(t, 4)
+	BaseConfig.Db = &config.DbInfo{}
+	stats = DBStats()
+	if _, ok := stats[config.DefaultDbAliasName]; !ok {
+		t.Errorf("未配置别名时应使用 %s, 实际 %v", config.DefaultDbAliasName, stats)
+	}
+	if got := stats[DBResolverAliasName].MaxOpenConnections; got != 4 {
+		t.Errorf("解析器的最大打开连接数 = %d, 期望 4", got)
+	}
+}
+
+// TestDBStats_Alias 测试按别名获取连接池统计
+//
+// 【功能点】验证只返回指定别名的统计信息，不存在的别名被忽略
+// 【测试流程】按 order、missing 获取，断言只返回 order
+func TestDBStats_Alias(t *testing.T) {
+	setupStatsTestDBs(t)
+
+	stats := DBStats("order", "missing")
+	if len(stats) != 1 {
+		t.Fatalf("统计信息数量 = %d, 期望 1: %v", len(stats), stats)
+	}
+	if got := stats["order"].MaxOpenConnections; got != 2 {
+		t.Errorf("order 的最大打开连接数 = %d, 期望 2", got)
+	}
+}
+
+// newDBTestContext 创建携带请求的 gin 上下文
+func newDBTestContext(ctx context.Context) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	return c
+}
+
+// TestDBWithContext_Session 测试请求级数据库会话
+//
+// 【功能点】验证会话绑定请求的 context.Context，并在同一请求内复用
+// 【测试流程】
+//  1. 主数据库为 nil 时断言返回 nil
+//  2. 同一请求内两次调用，断言返回同一个实例，且会话的 context 为请求的 context
+//  3. 替换请求的 context 后再次调用，断言返回新的会话；不同请求的会话不同
+func TestDBWithContext_Session(t *testing.T) {
+	originalDB := DB
+	t.Cleanup(func() { DB = originalDB })
+
+	DB = nil
+	if db := DBWithContext(newDBTestContext(context.Background())); db != nil {
+		t.Fatalf("主数据库未初始化时应返回 nil")
+	}
+
+	DB = openStatsTestDB(t, 1)
+	c := newDBTestContext(context.Background())
+	first := DBWithContext(c)
+	if first == nil || first != DBWithContext(c) {
+		t.Fatalf("同一请求内应返回同一个会话")
+	}
+	if first.Statement.Context != c.Request.Context() {
+		t.Errorf("会话应绑定请求的 context")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), time.Second)
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+	if second := DBWithContext(c); second == first || second.Statement.Context != ctx {
+		t.Errorf("请求的 context 被替换后应重新创建会话")
+	}
+	if DBWithContext(newDBTestContext(context.Background())) == first {
+		t.Errorf("不同请求的会话应不同")
+	}
+}
+
+// TestDBWithContext_Deadline 测试请求超时中断查询
+//
+// 【功能点】验证请求 context 超时后，正在执行的长查询被中断并返回 context.DeadlineExceeded
+// 【测试流程】以 50ms 超时的请求执行计数一亿行的递归查询，断言返回超时错误且耗时远小于查询完成所需时间
+func TestDBWithContext_Deadline(t *testing.T) {
+	originalDB := DB
+	t.Cleanup(func() { DB = originalDB })
+	DB = openStatsTestDB(t, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	c := newDBTestContext(ctx)
+
+	var count int64
+	start := time.Now()
+	err := DBWithContext(c).Raw("WITH RECURSIVE cnt(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM cnt WHERE x < 100000000) SELECT count(*) FROM cnt").Scan(&count).Error
+	elapsed := time.Since(start)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("错误 = %v, 期望 context.DeadlineExceeded", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("查询未被及时中断, 耗时 %v", elapsed)
+	}
+}
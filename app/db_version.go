@@ -0,0 +1,127 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/zzsen/gin_core/exception"
+	"github.com/zzsen/gin_core/model/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// versionType 乐观锁版本号的类型，用于查找模型的版本号字段
+var versionType = reflect.TypeOf(types.Version(0))
+
+// UpdateWithVersion 使用乐观锁更新数据
+// 执行 UPDATE ... SET version = version + 1, ... WHERE id = ? AND version = ?，
+// 版本号与模型中的值不一致（数据已被其他请求修改）时不更新任何数据并返回 exception.Conflict。
+// 更新成功后模型的版本号加 1，updates 中的字段同时写入模型；模型的 UpdatedAt、更新钩子和软删除条件按 GORM 的规则生效
+// 参数：
+//   - db: 数据库连接，传入事务时在该事务中执行
+//   - model: 模型指针，需包含主键值和 types.Version 类型的字段（值为读取时的版本号）
+//   - updates: 要更新的列，键为字段名或列名，不能包含版本号列
+//
+// 返回：
+//   - error: 版本号不一致时返回 exception.Conflict（可用 exception.IsConflict 判断），
+//     数据不存在或已被软删除时返回 gorm.ErrRecordNotFound
+//
+// 使用示例：
+//
+//	var order Order
+//	if err := app.DB.First(&order, id).Error; err != nil {
+//	    return err
+//	}
+//	err := app.UpdateWithVersion(app.DB, &order, map[string]any{"status": "paid"})
+func UpdateWithVersion(db *gorm.DB, model any, updates map[string]any) error {
+	s, version, err := parseVersionSchema(db, model)
+	if err != nil {
+		return err
+	}
+	modelValue := reflect.Indirect(reflect.ValueOf(model))
+	pkValue, zero := s.PrioritizedPrimaryField.ValueOf(db.Statement.Context, modelValue)
+	if zero {
+		return fmt.Errorf("乐观锁更新失败: %s 的主键值为空", s.Name)
+	}
+	current, _ := version.ValueOf(db.Statement.Context, modelValue)
+
+	values := make(map[string]any, len(updates)+1)
+	for column, value := range updates {
+		if column == version.Name || column == version.DBName {
+			return fmt.Errorf("乐观锁更新失败: 不能直接更新版本号字段 %s", version.Name)
+		}
+		values[column] = value
+	}
+	values[version.DBName] = gorm.Expr("? + 1", clause.Column{Name: version.DBName})
+
+	pk := clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: s.PrioritizedPrimaryField.DBName}, Value: pkValue}
+	result := db.Model(model).
+		Where(pk).
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: version.DBName}, Value: current}).
+		Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// 没有更新任何数据：数据不存在（或已被软删除），或版本号不一致
+		var count int64
+		if err := db.Session(&gorm.Session{NewDB: true}).Model(reflect.New(s.ModelType).Interface()).Where(pk).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return exception.NewConflict("")
+	}
+	return version.Set(db.Statement.Context, modelValue, current.(types.Version)+1)
+}
+
+// RetryOnConflict 在 fn 返回乐观锁冲突时重试，最多执行 attempts 次
+// fn 应在每次执行时重新读取数据再更新，返回非冲突的错误时立即返回
+// 参数：
+//   - attempts: 最多执行次数，小于 1 时按 1 处理
+//   - fn: 读取并更新数据的函数
+//
+// 返回：
+//   - error: fn 最后一次返回的错误
+//
+// 使用示例：
+//
+//	err := app.RetryOnConflict(3, func() error {
+//	    var account Account
+//	    if err := app.DB.First(&account, id).Error; err != nil {
+//	        return err
+//	    }
+//	    return app.UpdateWithVersion(app.DB, &account, map[string]any{"balance": account.Balance + amount})
+//	})
+func RetryOnConflict(attempts int, fn func() error) error {
+	var err error
+	for i := 0; i < max(attempts, 1); i++ {
+		if err = fn(); !exception.IsConflict(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// parseVersionSchema 解析模型，返回模型结构和版本号字段
+func parseVersionSchema(db *gorm.DB, model any) (*schema.Schema, *schema.Field, error) {
+	if model == nil || reflect.TypeOf(model).Kind() != reflect.Ptr {
+		return nil, nil, errors.New("乐观锁更新失败: 模型必须为结构体指针")
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, nil, fmt.Errorf("解析模型失败: %w", err)
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil, nil, fmt.Errorf("乐观锁更新失败: %s 没有主键", stmt.Schema.Name)
+	}
+	for _, field := range stmt.Schema.Fields {
+		if field.FieldType == versionType {
+			return stmt.Schema, field, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("乐观锁更新失败: %s 没有 types.Version 类型的字段", stmt.Schema.Name)
+}
@@ -0,0 +1,272 @@
+// Package app 乐观锁更新测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含乐观锁更新 UpdateWithVersion 和重试函数 RetryOnConflict 的单元测试，使用 SQLite 内存数据库，不需要 MySQL。
+//
+// 测试覆盖内容：
+// 1. 更新成功时版本号加 1，数据库和模型同步更新
+// 2. 两个请求基于同一版本号先后更新，后一个返回 exception.Conflict 且不修改数据
+// 3. RetryOnConflict 重新读取数据后第二次执行成功，非冲突错误立即返回
+// 4. 在事务中更新，事务回滚后版本号不变
+// 5. 数据已被软删除时返回 gorm.ErrRecordNotFound，模型缺少版本号字段时返回错误
+//
+// 运行测试：go test -v ./app/... -run "UpdateWithVersion|RetryOnConflict"
+// ==================================================
+package app
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/zzsen/gin_core/exception"
+	"github.com/zzsen/gin_core/model/entity"
+	"github.com/zzsen/gin_core/model/types"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// versionedAccount 测试用实体，包含版本号字段和软删除字段
+type versionedAccount struct {
+	entity.SoftDeleteModel
+	Name    string `gorm:"size:64"`
+	Balance int
+	Version types.Version `gorm:"not null;default:0"`
+}
+
+// openVersionTestDB 创建 SQLite 内存数据库并插入一条余额为 100 的数据
+func openVersionTestDB(t *testing.T) (*gorm.DB, *versionedAccount) {
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取 sqlDB 失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.AutoMigrate(&versionedAccount{}); err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+	account := &versionedAccount{Name: "alice", Balance: 100}
+	if err := db.Create(account).Error; err != nil {
+		t.Fatalf("插入数据失败: %v", err)
+	}
+	return db, account
+}
+
+// loadAccount 按主键读取数据
+func loadAccount(t *testing.T, db *gorm.DB, id uint64) *versionedAccount {
+	var account versionedAccount
+	if err := db.First(&account, id).Error; err != nil {
+		t.Fatalf("读取数据失败: %v", err)
+	}
+	return &account
+}
+
+// TestUpdateWithVersion_Success 测试更新成功
+//
+// 【功能点】验证更新成功时版本号加 1，数据库中的字段和模型的版本号同步更新
+// 【测试流程】
+//  1. 读取版本号为 0 的数据，UpdateWithVersion 更新余额
+//  2. 断言模型版本号为 1，数据库中余额和版本号已更新
+//  3. 基于新版本号再次更新，断言版本号为 2
+func TestUpdateWithVersion_Success(t *testing.T) {
+	db, created := openVersionTestDB(t)
+
+	account := loadAccount(t, db, created.ID)
+	if err := UpdateWithVersion(db, account, map[string]any{"balance": 150}); err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	if account.Version != 1 {
+		t.Errorf("期望模型版本号为 1，实际为 %d", account.Version)
+	}
+	stored := loadAccount(t, db, created.ID)
+	if stored.Balance != 150 || stored.Version != 1 {
+		t.Errorf("期望数据库中余额为 150、版本号为 1，实际为 %d、%d", stored.Balance, stored.Version)
+	}
+
+	if err := UpdateWithVersion(db, account, map[string]any{"Name": "bob"}); err != nil {
+		t.Fatalf("第二次更新失败: %v", err)
+	}
+	stored = loadAccount(t, db, created.ID)
+	if stored.Name != "bob" || stored.Version != 2 || account.Version != 2 {
+		t.Errorf("期望名称为 bob、版本号为 2，实际为 %s、%d（模型 %d）", stored.Name, stored.Version, account.Version)
+	}
+}
+
+// TestUpdateWithVersion_Conflict 测试并发更新冲突
+//
+// 【功能点】验证两个请求基于同一版本号更新时，后提交的请求返回 exception.Conflict 且不修改数据
+// 【测试流程】
+//  1. 两个请求分别读取版本号为 0 的数据
+//  2. 第一个请求更新成功
+//  3. 第二个请求更新，断言返回冲突错误，数据库中余额和版本号保持第一个请求的结果
+func TestUpdateWithVersion_Conflict(t *testing.T) {
+	db, created := openVersionTestDB(t)
+
+	first := loadAccount(t, db, created.ID)
+	second := loadAccount(t, db, created.ID)
+
+	if err := UpdateWithVersion(db, first, map[string]any{"balance": first.Balance + 10}); err != nil {
+		t.Fatalf("第一个请求更新失败: %v", err)
+	}
+	err := UpdateWithVersion(db, second, map[string]any{"balance": second.Balance + 20})
+	if !exception.IsConflict(err) {
+		t.Fatalf("期望返回冲突错误，实际为 %v", err)
+	}
+	if second.Version != 0 {
+		t.Errorf("期望冲突时模型版本号不变，实际为 %d", second.Version)
+	}
+	stored := loadAccount(t, db, created.ID)
+	if stored.Balance != 110 || stored.Version != 1 {
+		t.Errorf("期望数据库中余额为 110、版本号为 1，实际为 %d、%d", stored.Balance, stored.Version)
+	}
+}
+
+// TestRetryOnConflict 测试冲突重试
+//
+// 【功能点】验证 RetryOnConflict 在冲突时重新执行，第二次读取最新数据后更新成功；非冲突错误立即返回
+// 【测试流程】
+//  1. 第一次执行时在读取数据后由另一个请求修改数据，使本次更新冲突
+//  2. 断言共执行 2 次，最终余额包含两次修改
+//  3. 断言次数用尽时返回冲突错误，非冲突错误只执行 1 次
+func TestRetryOnConflict(t *testing.T) {
+	db, created := openVersionTestDB(t)
+
+	attempts := 0
+	err := RetryOnConflict(3, func() error {
+		attempts++
+		account := loadAccount(t, db, created.ID)
+		if attempts == 1 {
+			other := loadAccount(t, db, created.ID)
+			if err := UpdateWithVersion(db, other, map[string]any{"balance": other.Balance + 50}); err != nil {
+				return err
+			}
+		}
+		return UpdateWithVersion(db, account, map[string]any{"balance": account.Balance + 10})
+	})
+	if err != nil {
+		t.Fatalf("期望重试后成功，实际为 %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("期望执行 2 次，实际为 %d", attempts)
+	}
+	stored := loadAccount(t, db, created.ID)
+	if stored.Balance != 160 || stored.Version != 2 {
+		t.Errorf("期望余额为 160、版本号为 2，实际为 %d、%d", stored.Balance, stored.Version)
+	}
+
+	attempts = 0
+	err = RetryOnConflict(2, func() error {
+		attempts++
+		return exception.NewConflict("conflict")
+	})
+	if !exception.IsConflict(err) || attempts != 2 {
+		t.Errorf("期望执行 2 次后返回冲突错误，实际执行 %d 次，错误为 %v", attempts, err)
+	}
+
+	attempts = 0
+	otherErr := errors.New("other")
+	err = RetryOnConflict(3, func() error {
+		attempts++
+		return otherErr
+	})
+	if !errors.Is(err, otherErr) || attempts != 1 {
+		t.Errorf("期望非冲突错误只执行 1 次，实际执行 %d 次，错误为 %v", attempts, err)
+	}
+}
+
+// TestUpdateWithVersion_Transaction 测试在事务中更新
+//
+// 【功能点】验证 UpdateWithVersion 使用传入的事务执行，事务回滚后数据和版本号不变，冲突错误可使事务回滚
+// 【测试流程】
+//  1. 在事务中更新成功后返回错误使事务回滚，断言数据库中版本号仍为 0
+//  2. 在事务中基于过期版本号更新，断言事务返回冲突错误
+//  3. 在事务中正常更新并提交，断言版本号为 1
+func TestUpdateWithVersion_Transaction(t *testing.T) {
+	db, created := openVersionTestDB(t)
+
+	rollback := errors.New("rollback")
+	err := db.Transaction(func(tx *gorm.DB) error {
+		account := loadAccount(t, tx, created.ID)
+		if err := UpdateWithVersion(tx, account, map[string]any{"balance": 0}); err != nil {
+			return err
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("期望事务返回 rollback，实际为 %v", err)
+	}
+	if stored := loadAccount(t, db, created.ID); stored.Balance != 100 || stored.Version != 0 {
+		t.Errorf("期望回滚后余额为 100、版本号为 0，实际为 %d、%d", stored.Balance, stored.Version)
+	}
+
+	stale := &versionedAccount{SoftDeleteModel: created.SoftDeleteModel, Version: 5}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return UpdateWithVersion(tx, stale, map[string]any{"balance": 0})
+	})
+	if !exception.IsConflict(err) {
+		t.Errorf("期望事务返回冲突错误，实际为 %v", err)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return UpdateWithVersion(tx, loadAccount(t, tx, created.ID), map[string]any{"balance": 80})
+	})
+	if err != nil {
+		t.Fatalf("事务更新失败: %v", err)
+	}
+	if stored := loadAccount(t, db, created.ID); stored.Balance != 80 || stored.Version != 1 {
+		t.Errorf("期望提交后余额为 80、版本号为 1，实际为 %d、%d", stored.Balance, stored.Version)
+	}
+}
+
+// TestUpdateWithVersion_Errors 测试数据不存在和参数错误
+//
+// 【功能点】验证数据已被软删除时返回 gorm.ErrRecordNotFound 而非冲突错误，模型不合法时返回错误
+// 【测试流程】
+//  1. 读取数据后软删除，UpdateWithVersion 断言返回 gorm.ErrRecordNotFound
+//  2. 断言模型缺少版本号字段、主键为空、非指针、updates 包含版本号时返回错误
+func TestUpdateWithVersion_Errors(t *testing.T) {
+	db, created := openVersionTestDB(t)
+
+	account := loadAccount(t, db, created.ID)
+	if err := db.Delete(&versionedAccount{}, created.ID).Error; err != nil {
+		t.Fatalf("删除数据失败: %v", err)
+	}
+	err := UpdateWithVersion(db, account, map[string]any{"balance": 1})
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("期望软删除后返回 gorm.ErrRecordNotFound，实际为 %v", err)
+	}
+
+	type plain struct {
+		ID   uint
+		Name string
+	}
+	cases := map[string]struct {
+		model   any
+		updates map[string]any
+	}{
+		"缺少版本号字段": {model: &plain{ID: 1}, updates: map[string]any{"name": "x"}},
+		"主键为空":    {model: &versionedAccount{}, updates: map[string]any{"balance": 1}},
+		"非指针":     {model: versionedAccount{}, updates: map[string]any{"balance": 1}},
+		"更新版本号字段": {model: loadAccountUnscoped(t, db, created.ID), updates: map[string]any{"version": 9}},
+	}
+	for name, tc := range cases {
+		if err := UpdateWithVersion(db, tc.model, tc.updates); err == nil {
+			t.Errorf("%s: 期望返回错误", name)
+		}
+	}
+}
+
+// loadAccountUnscoped 按主键读取数据，包含已软删除的数据
+func loadAccountUnscoped(t *testing.T, db *gorm.DB, id uint64) *versionedAccount {
+	var account versionedAccount
+	if err := db.Unscoped().First(&account, id).Error; err != nil {
+		t.Fatalf("读取数据失败: %v", err)
+	}
+	return &account
+}
@@ -0,0 +1,186 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	elasticsearch "github.com/elastic/go-elasticsearch/v9"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+var (
+	// esClients 按别名索引的 Elasticsearch 集群客户端，并发访问需通过 ESByName 方法
+	esClients = make(map[string]*esClient)
+
+	// esPingTimeout 连接集群时探测请求的超时时间
+	esPingTimeout = 3 * time.Second
+	// esMinBackoff、esMaxBackoff 连接失败后的重试等待时间，从 esMinBackoff 开始翻倍，上限 esMaxBackoff
+	esMinBackoff = time.Second
+	esMaxBackoff = 30 * time.Second
+)
+
+// esClient 可延迟连接的 Elasticsearch 集群客户端
+// 连接失败时记录错误并按指数退避等待，等待期间的访问直接返回上次的错误，等待结束后的第一次访问重新连接
+type esClient struct {
+	alias     string
+	cfg       config.EsInfo
+	mu        sync.Mutex
+	client    *elasticsearch.TypedClient
+	lastErr   error
+	backoff   time.Duration
+	nextRetry time.Time
+}
+
+// get 获取客户端，未连接且已到重试时间时重新连接
+func (c *esClient) get(ctx context.Context) (*elasticsearch.TypedClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != nil {
+		return c.client, nil
+	}
+	if time.Now().Before(c.nextRetry) {
+		return nil, fmt.Errorf("[es] Elasticsearch `%s` 不可用, %s 后重试: %w", c.alias, c.nextRetry.Format(time.TimeOnly), c.lastErr)
+	}
+
+	return c.reconnect(ctx)
+}
+
+// reconnect 重新连接，失败时记录错误并延长退避时间，调用方需持有 c.mu
+func (c *esClient) reconnect(ctx context.Context) (*elasticsearch.TypedClient, error) {
+	client, err := c.connect(ctx)
+	if err != nil {
+		c.lastErr = err
+		c.backoff = min(max(c.backoff*2, esMinBackoff), esMaxBackoff)
+		c.nextRetry = time.Now().Add(c.backoff)
+		return nil, fmt.Errorf("[es] Elasticsearch `%s` 连接失败: %w", c.alias, err)
+	}
+	c.client, c.lastErr, c.backoff = client, nil, 0
+	return client, nil
+}
+
+// connect 创建客户端并发送探测请求
+func (c *esClient) connect(ctx context.Context) (*elasticsearch.TypedClient, error) {
+	esConfig := elasticsearch.Config{
+		Addresses: c.cfg.Addresses,
+		Username:  c.cfg.Username,
+		Password:  c.cfg.Password,
+	}
+	if c.cfg.CACert != "" {
+		cert, err := os.ReadFile(c.cfg.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+		}
+		esConfig.CACert = cert
+	}
+	client, err := elasticsearch.NewTypedClient(esConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, esPingTimeout)
+	defer cancel()
+	if _, err := client.Info().Do(ctx); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// AddES 添加 Elasticsearch 集群并尝试连接
+// 连接失败时集群仍被添加，之后通过 ESByName 访问时按退避时间重新连接
+// 参数：
+//   - cfg: 集群配置，别名为空时使用 config.DefaultEsAliasName
+//
+// 返回：
+//   - *elasticsearch.TypedClient: 连接成功时的客户端，失败时为 nil
+//   - error: 别名重复或连接失败时返回错误
+func AddES(cfg config.EsInfo) (*elasticsearch.TypedClient, error) {
+	c := &esClient{alias: cfg.GetAliasName(), cfg: cfg}
+	lock.Lock()
+	if _, ok := esClients[c.alias]; ok {
+		lock.Unlock()
+		return nil, fmt.Errorf("[es] Elasticsearch 集群别名重复: %s", c.alias)
+	}
+	esClients[c.alias] = c
+	lock.Unlock()
+	return c.get(context.Background())
+}
+
+// ESByName 根据别名获取 Elasticsearch 客户端
+// 集群启动时不可达的，在退避时间结束后的第一次调用时重新连接
+// 参数：
+//   - alias: 集群别名，单个 es 配置未设置别名时为 config.DefaultEsAliasName
+//
+// 返回：
+//   - *elasticsearch.TypedClient: 客户端
+//   - error: 别名未配置或集群不可用时返回错误
+func ESByName(alias string) (*elasticsearch.TypedClient, error) {
+	lock.RLock()
+	c, ok := esClients[alias]
+	lock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("[es] Elasticsearch `%s` 未配置", alias)
+	}
+	return c.get(context.Background())
+}
+
+// ESAliases 获取已配置的 Elasticsearch 集群别名，按字母顺序返回
+func ESAliases() []string {
+	lock.RLock()
+	defer lock.RUnlock()
+	aliases := make([]string, 0, len(esClients))
+	for alias := range esClients {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// ESHealth 检查所有 Elasticsearch 集群的健康状态
+// 未连接的集群按退避时间尝试重新连接，已连接的集群发送探测请求
+// 参数：
+//   - ctx: 上下文，用于控制探测请求的超时
+//
+// 返回：
+//   - map[string]error: 集群别名到检查结果的映射，健康时为 nil
+func ESHealth(ctx context.Context) map[string]error {
+	result := make(map[string]error)
+	for _, alias := range ESAliases() {
+		lock.RLock()
+		c := esClients[alias]
+		lock.RUnlock()
+		client, err := c.get(ctx)
+		if err == nil {
+			_, err = client.Info().Do(ctx)
+		}
+		result[alias] = err
+	}
+	return result
+}
+
+// ConnectES 立即连接所有尚未连接的集群，不等待退避时间，用于启动时等待集群就绪
+// 参数：
+//   - ctx: 上下文，控制探测请求
+//
+// 返回：
+//   - error: 仍不可用的集群的连接错误
+func ConnectES(ctx context.Context) error {
+	var errs []error
+	for _, alias := range ESAliases() {
+		lock.RLock()
+		c := esClients[alias]
+		lock.RUnlock()
+		c.mu.Lock()
+		if c.client == nil {
+			if _, err := c.reconnect(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		c.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
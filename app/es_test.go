@@ -0,0 +1,199 @@
+// Package app Elasticsearch 多集群客户端测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 Elasticsearch 多集群客户端的单元测试，使用 httptest 模拟集群，不需要真实的 Elasticsearch。
+//
+// 测试覆盖内容：
+// 1. 按别名获取客户端，未配置的别名返回错误
+// 2. 启动时集群不可达不影响其他集群，退避时间内直接返回错误，集群恢复后重新连接
+// 3. 健康检查按别名报告各集群状态
+// 4. ConnectES 不等待退避时间，立即连接尚未连接的集群
+//
+// 运行测试：go test -v ./app/... -run ES
+// ==================================================
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// fakeESCluster 模拟 Elasticsearch 集群，down 为 true 时返回 503
+type fakeESCluster struct {
+	server *httptest.Server
+	down   atomic.Bool
+	calls  atomic.Int32
+}
+
+// newFakeESCluster 启动模拟集群
+func newFakeESCluster(t *testing.T) *fakeESCluster {
+	c := &fakeESCluster{}
+	c.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.calls.Add(1)
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		if c.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":{"type":"unavailable","reason":"cluster down"},"status":503}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"node-1","cluster_name":"test","cluster_uuid":"uuid",` +
+			`"version":{"number":"9.0.0","build_flavor":"default","build_type":"docker","build_hash":"hash",` +
+			`"build_date":"2025-01-01T00:00:00Z","build_snapshot":false,"lucene_version":"10.0.0",` +
+			`"minimum_wire_compatibility_version":"8.0.0","minimum_index_compatibility_version":"8.0.0"},` +
+			`"tagline":"You Know, for Search"}`))
+	}))
+	t.Cleanup(c.server.Close)
+	return c
+}
+
+// setupESTest 清空已添加的集群并缩短退避时间，测试结束后恢复
+func setupESTest(t *testing.T, backoff time.Duration) {
+	lock.Lock()
+	original := esClients
+	esClients = make(map[string]*esClient)
+	lock.Unlock()
+	originalMin, originalMax := esMinBackoff, esMaxBackoff
+	esMinBackoff, esMaxBackoff = backoff, 4*backoff
+	t.Cleanup(func() {
+		lock.Lock()
+		esClients = original
+		lock.Unlock()
+		esMinBackoff, esMaxBackoff = originalMin, originalMax
+	})
+}
+
+// TestESByName 测试按别名获取客户端
+//
+// 【功能点】验证按别名获取已连接的客户端，单个配置未设置别名时使用默认别名，未配置的别名返回错误
+// 【测试流程】
+//  1. 添加未设置别名的集群和别名为 logs 的集群
+//  2. 断言两个别名都能获取到客户端，别名列表有序
+//  3. 获取未配置的别名，断言返回包含别名的错误；重复添加别名返回错误
+func TestESByName(t *testing.T) {
+	setupESTest(t, time.Hour)
+	search, logs := newFakeESCluster(t), newFakeESCluster(t)
+
+	if _, err := AddES(config.EsInfo{Addresses: []string{search.server.URL}}); err != nil {
+		t.Fatalf("添加默认集群失败: %v", err)
+	}
+	if _, err := AddES(config.EsInfo{AliasName: "logs", Addresses: []string{logs.server.URL}}); err != nil {
+		t.Fatalf("添加 logs 集群失败: %v", err)
+	}
+
+	for _, alias := range []string{config.DefaultEsAliasName, "logs"} {
+		if client, err := ESByName(alias); err != nil || client == nil {
+			t.Errorf("获取集群 %s 失败: %v", alias, err)
+		}
+	}
+	if aliases := ESAliases(); len(aliases) != 2 || aliases[0] != "default" || aliases[1] != "logs" {
+		t.Errorf("别名列表应为 [default logs]，实际为 %v", aliases)
+	}
+
+	client, err := ESByName("metrics")
+	if client != nil || err == nil || err.Error() != "[es] Elasticsearch `metrics` 未配置" {
+		t.Errorf("未配置的别名应返回描述性错误，实际: %v", err)
+	}
+	if _, err := AddES(config.EsInfo{AliasName: "logs", Addresses: []string{logs.server.URL}}); err == nil {
+		t.Error("重复的别名应返回错误")
+	}
+}
+
+// TestESByName_Reconnect 测试启动时集群不可达
+//
+// 【功能点】验证集群启动时不可达时仍被添加，退避时间内不重复连接，集群恢复后访问时重新连接
+// 【测试流程】
+//  1. logs 集群不可达，添加后断言返回错误，search 集群正常添加
+//  2. 退避时间内访问 logs，断言返回错误且没有发送请求
+//  3. 恢复 logs 集群，等待退避时间结束后访问，断言获取到客户端
+func TestESByName_Reconnect(t *testing.T) {
+	setupESTest(t, 100*time.Millisecond)
+	search, logs := newFakeESCluster(t), newFakeESCluster(t)
+	logs.down.Store(true)
+
+	if _, err := AddES(config.EsInfo{AliasName: "logs", Addresses: []string{logs.server.URL}}); err == nil {
+		t.Fatal("集群不可达时应返回连接错误")
+	}
+	if _, err := AddES(config.EsInfo{AliasName: "search", Addresses: []string{search.server.URL}}); err != nil {
+		t.Fatalf("添加 search 集群失败: %v", err)
+	}
+
+	calls := logs.calls.Load()
+	if _, err := ESByName("logs"); err == nil {
+		t.Error("退避时间内应返回错误")
+	}
+	if logs.calls.Load() != calls {
+		t.Error("退避时间内不应重新连接")
+	}
+
+	logs.down.Store(false)
+	time.Sleep(150 * time.Millisecond)
+	if client, err := ESByName("logs"); err != nil || client == nil {
+		t.Fatalf("集群恢复后应重新连接成功，实际: %v", err)
+	}
+}
+
+// TestESHealth 测试健康检查
+//
+// 【功能点】验证健康检查按别名报告各集群状态
+// 【测试流程】
+//  1. 添加两个集群后将 logs 集群设为不可用
+//  2. 断言 search 为健康、logs 返回错误
+func TestESHealth(t *testing.T) {
+	setupESTest(t, time.Hour)
+	search, logs := newFakeESCluster(t), newFakeESCluster(t)
+	for alias, cluster := range map[string]*fakeESCluster{"search": search, "logs": logs} {
+		if _, err := AddES(config.EsInfo{AliasName: alias, Addresses: []string{cluster.server.URL}}); err != nil {
+			t.Fatalf("添加集群 %s 失败: %v", alias, err)
+		}
+	}
+	logs.down.Store(true)
+
+	health := ESHealth(context.Background())
+	if len(health) != 2 {
+		t.Fatalf("应返回 2 个集群的状态，实际: %v", health)
+	}
+	if health["search"] != nil {
+		t.Errorf("search 集群应健康，实际: %v", health["search"])
+	}
+	if health["logs"] == nil {
+		t.Error("logs 集群不可用时应返回错误")
+	}
+}
+
+// TestConnectES 测试立即连接尚未连接的集群
+//
+// 【功能点】验证 ConnectES 不等待退避时间，集群不可达时返回错误，恢复后立即连接成功
+// 【测试流程】
+//  1. 退避时间设为 1 小时，logs 集群不可达时添加，断言 ConnectES 返回错误且发送了探测请求
+//  2. 恢复 logs 集群，断言 ConnectES 成功，ESByName 获取到客户端
+func TestConnectES(t *testing.T) {
+	setupESTest(t, time.Hour)
+	logs := newFakeESCluster(t)
+	logs.down.Store(true)
+	if _, err := AddES(config.EsInfo{AliasName: "logs", Addresses: []string{logs.server.URL}}); err == nil {
+		t.Fatal("集群不可达时应返回连接错误")
+	}
+
+	calls := logs.calls.Load()
+	if err := ConnectES(context.Background()); err == nil {
+		t.Error("集群不可达时 ConnectES 应返回错误")
+	}
+	if logs.calls.Load() == calls {
+		t.Error("ConnectES 不应等待退避时间")
+	}
+
+	logs.down.Store(false)
+	if err := ConnectES(context.Background()); err != nil {
+		t.Fatalf("集群恢复后 ConnectES 应成功，实际: %v", err)
+	}
+	if client, err := ESByName("logs"); err != nil || client == nil {
+		t.Errorf("连接成功后应获取到客户端，实际: %v", err)
+	}
+}
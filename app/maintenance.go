@@ -0,0 +1,84 @@
+package app
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/zzsen/gin_core/logger"
+)
+
+// MaintenanceState 维护模式状态
+type MaintenanceState struct {
+	// Enabled 是否处于维护模式
+	Enabled bool `json:"enabled"`
+	// Message 维护提示消息，为空时使用 51503 响应码的默认消息
+	Message string `json:"message"`
+	// Operator 最近一次切换维护模式的操作人，未在运行时切换过时为空
+	Operator string `json:"operator,omitempty"`
+	// UpdatedAt 最近一次切换的时间，未在运行时切换过时为零值
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// maintenanceState 运行时切换后的维护模式状态，未切换过时为 nil，使用 maintenance 配置
+var maintenanceState atomic.Pointer[MaintenanceState]
+
+// SetMaintenanceMode 开启或关闭维护模式，立即对后续请求生效，可并发调用
+// 维护模式开启时，maintenanceHandler 中间件对健康检查接口和放行的请求以外的请求返回 503
+//
+// 参数：
+//   - on: 是否开启维护模式
+//   - message: 维护提示消息，为空时使用 maintenance.message 配置
+func SetMaintenanceMode(on bool, message string) {
+	SetMaintenanceModeBy(on, message, "")
+}
+
+// SetMaintenanceModeBy 开启或关闭维护模式，并记录操作人
+// 状态变更记录 info 日志，包含操作人，管理接口使用该函数记录调用方
+//
+// 参数：
+//   - on: 是否开启维护模式
+//   - message: 维护提示消息，为空时使用 maintenance.message 配置
+//   - operator: 操作人，为空时记录为 system
+//
+// 返回：
+//   - MaintenanceState: 切换后的状态
+func SetMaintenanceModeBy(on bool, message, operator string) MaintenanceState {
+	if message == "" {
+		message = BaseConfig.Maintenance.Message
+	}
+	if operator == "" {
+		operator = "system"
+	}
+	state := MaintenanceState{Enabled: on, Message: message, Operator: operator, UpdatedAt: time.Now()}
+	previous := maintenanceState.Swap(&state)
+	wasEnabled := BaseConfig.Maintenance.Enabled
+	if previous != nil {
+		wasEnabled = previous.Enabled
+	}
+	if on {
+		logger.Info("[维护模式] 开启维护模式, message: %s, previous: %t, operator: %s", message, wasEnabled, operator)
+	} else {
+		logger.Info("[维护模式] 关闭维护模式, previous: %t, operator: %s", wasEnabled, operator)
+	}
+	return state
+}
+
+// MaintenanceStatus 获取当前的维护模式状态
+// 未在运行时切换过时返回 maintenance 配置中的 enabled 和 message
+//
+// 返回：
+//   - MaintenanceState: 维护模式状态
+func MaintenanceStatus() MaintenanceState {
+	if state := maintenanceState.Load(); state != nil {
+		return *state
+	}
+	return MaintenanceState{
+		Enabled: BaseConfig.Maintenance.Enabled,
+		Message: BaseConfig.Maintenance.Message,
+	}
+}
+
+// ResetMaintenanceMode 清除运行时切换的状态，恢复使用 maintenance 配置，用于重新加载配置后和测试
+func ResetMaintenanceMode() {
+	maintenanceState.Store(nil)
+}
@@ -2,20 +2,31 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/zzsen/gin_core/logger"
 	"github.com/zzsen/gin_core/model/config"
 )
 
+// findRabbitMQInfo 根据实例别名获取 RabbitMQ 实例配置，别名为空时返回默认配置，未找到时返回 nil
+func findRabbitMQInfo(mqConfigName string) *config.RabbitMQInfo {
+	if mqConfigName == "" {
+		return &BaseConfig.RabbitMQ
+	}
+	return BaseConfig.RabbitMQList.Find(mqConfigName)
+}
+
 // buildProducerMQ 构建生产者消息队列实例
 //
-// 抽取公共逻辑：构建 MessageQueue 结构体、获取连接字符串、校验连接字符串
+// 抽取公共逻辑：构建 MessageQueue 结构体、获取连接字符串、校验连接配置
 // 流程：
 // 1. 根据参数构建 MessageQueue 结构体
-// 2. 根据 mqConfigName 获取对应的连接字符串（默认配置或命名配置）
-// 3. 校验连接字符串是否为空
+// 2. 根据 mqConfigName 获取对应的实例配置（默认配置或命名配置）
+// 3. 校验实例配置是否存在，并设置连接字符串、发布通道池容量和实例共享的发布限流器
 func buildProducerMQ(queueName, exchangeName, exchangeType, routingKey, mqConfigName string) (*config.MessageQueue, error) {
 	mq := &config.MessageQueue{
 		MQName:       mqConfigName,
@@ -24,14 +35,18 @@ func buildProducerMQ(queueName, exchangeName, exchangeType, routingKey, mqConfig
 		ExchangeType: exchangeType,
 		RoutingKey:   routingKey,
 	}
-	mqConnStr := BaseConfig.RabbitMQ.Url()
-	if mqConfigName != "" {
-		mqConnStr = BaseConfig.RabbitMQList.Url(mqConfigName)
-	}
-	if mqConnStr == "" {
+	mqInfo := findRabbitMQInfo(mqConfigName)
+	if mqInfo == nil {
 		return nil, fmt.Errorf("[消息队列] 未找到对应的消息队列配置, MQName: %s", mqConfigName)
 	}
-	mq.MqConnStr = mqConnStr
+	tlsConfig, err := mqInfo.TLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("[消息队列] 生成 TLS 配置失败, MQName: %s, error: %w", mqConfigName, err)
+	}
+	mq.MqConnStr = mqInfo.Url()
+	mq.TLSConfig = tlsConfig
+	mq.PublisherChannelPoolSize = mqInfo.GetPublisherChannelPoolSize()
+	mq.PublishThrottle = publishThrottleFor(mqConfigName, mqInfo)
 	return mq, nil
 }
 
@@ -49,6 +64,30 @@ func buildProducerMQ(queueName, exchangeName, exchangeType, routingKey, mqConfig
 //   - error: 如果所有消息队列都发送失败则返回错误，部分成功时返回最后一个错误
 func SendRabbitMqMsg(queueName string, exchangeName string,
 	exchangeType string, routingKey string, message string, mqConfigNames ...string) error {
+	return SendRabbitMqMsgOpts(context.Background(), queueName, exchangeName, exchangeType, routingKey, message, nil, mqConfigNames...)
+}
+
+// SendRabbitMqMsgOpts 发送RabbitMQ消息，支持设置过期时间、优先级、持久化等消息属性
+// 与 SendRabbitMqMsg 相同，支持向多个消息队列实例发送消息，并提供重试机制
+// 参数：
+//   - ctx: context，取消后不再重试
+//   - queueName: 队列名称
+//   - exchangeName: 交换机名称
+//   - exchangeType: 交换机类型（direct, fanout, topic, headers）
+//   - routingKey: 路由键
+//   - message: 消息内容
+//   - opts: 发布选项，如 config.WithTTL(time.Minute)、config.WithPriority(5)、config.WithPersistent(false)，可为 nil
+//   - mqConfigNames: 消息队列配置名称列表（可选，为空时使用默认配置）
+//
+// 返回：
+//   - error: 如果所有消息队列都发送失败则返回错误；实例启用发布限流且被限流时不重试，可通过 errors.Is(err, config.ErrPublishThrottled) 判断
+//
+// 使用示例：
+//
+//	err := app.SendRabbitMqMsgOpts(ctx, "notify", "notify-exchange", "direct", "notify-key", msg,
+//	  []config.PublishOption{config.WithTTL(10 * time.Minute), config.WithPriority(5)})
+func SendRabbitMqMsgOpts(ctx context.Context, queueName string, exchangeName string,
+	exchangeType string, routingKey string, message string, opts []config.PublishOption, mqConfigNames ...string) error {
 	if len(mqConfigNames) == 0 {
 		mqConfigNames = []string{""}
 	}
@@ -65,10 +104,11 @@ func SendRabbitMqMsg(queueName string, exchangeName string,
 		}
 
 		// 发送消息，带重试机制
-		err = sendRabbitMqMsgWithRetry(messageQueue, message, 3, 100*time.Millisecond)
+		err = sendRabbitMqMsgWithRetry(ctx, messageQueue, message, 3, 100*time.Millisecond, opts...)
 		if err != nil {
 			lastErr = err
 			logger.Error("[消息队列] 消息发送失败, queueInfo: %s, error: %v", messageQueue.GetInfo(), err)
+			recordFailedMessage(messageQueue, message, opts, err)
 		} else {
 			successCount++
 		}
@@ -87,16 +127,47 @@ func SendRabbitMqMsg(queueName string, exchangeName string,
 	return nil
 }
 
+// SendRabbitMqJSON 将 v 序列化为 JSON 后发送RabbitMQ消息，消息的内容类型为 application/json
+// 与 SendRabbitMqMsgOpts 相同，支持向多个消息队列实例发送消息，并提供重试机制；消费端可使用 mq.TypedHandler 反序列化
+// 参数：
+//   - ctx: context，取消后不再重试
+//   - queueName: 队列名称
+//   - exchangeName: 交换机名称
+//   - exchangeType: 交换机类型（direct, fanout, topic, headers）
+//   - routingKey: 路由键
+//   - v: 消息内容，使用 encoding/json 序列化
+//   - opts: 发布选项，可为 nil；通过 config.WithContentType 可覆盖内容类型
+//   - mqConfigNames: 消息队列配置名称列表（可选，为空时使用默认配置）
+//
+// 返回：
+//   - error: 序列化失败或所有消息队列都发送失败时返回错误
+//
+// 使用示例：
+//
+//	err := app.SendRabbitMqJSON(ctx, "order.paid", "order-exchange", "direct", "order.paid",
+//	  OrderPaid{OrderID: "o-1", Amount: 100}, []config.PublishOption{config.WithMessageID("o-1")})
+func SendRabbitMqJSON(ctx context.Context, queueName string, exchangeName string,
+	exchangeType string, routingKey string, v any, opts []config.PublishOption, mqConfigNames ...string) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("[消息队列] 消息序列化失败: %w", err)
+	}
+	opts = append([]config.PublishOption{config.WithContentType("application/json")}, opts...)
+	return SendRabbitMqMsgOpts(ctx, queueName, exchangeName, exchangeType, routingKey, string(body), opts, mqConfigNames...)
+}
+
 // sendRabbitMqMsgWithRetry 发送RabbitMQ消息，带重试机制
 // 参数：
+//   - ctx: context，取消后不再重试
 //   - messageQueue: 消息队列配置（指针）
 //   - message: 消息内容
 //   - maxRetries: 最大重试次数（不包括首次尝试）
 //   - retryInterval: 重试间隔时间
+//   - opts: 发布选项
 //
 // 返回：
-//   - error: 发送失败时返回错误
-func sendRabbitMqMsgWithRetry(messageQueue *config.MessageQueue, message string, maxRetries int, retryInterval time.Duration) error {
+//   - error: 发送失败时返回错误，被限流（config.ErrPublishThrottled）时不重试直接返回
+func sendRabbitMqMsgWithRetry(ctx context.Context, messageQueue *config.MessageQueue, message string, maxRetries int, retryInterval time.Duration, opts ...config.PublishOption) error {
 	queueInfo := messageQueue.GetInfo()
 	var lastErr error
 
@@ -104,7 +175,11 @@ func sendRabbitMqMsgWithRetry(messageQueue *config.MessageQueue, message string,
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		// 如果不是首次尝试，等待重试间隔
 		if attempt > 0 {
-			time.Sleep(retryInterval)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("消息发送已取消: %w", errors.Join(ctx.Err(), lastErr))
+			case <-time.After(retryInterval):
+			}
 			logger.Info("[消息队列] 重试发送消息, queueInfo: %s, 尝试次数: %d/%d", queueInfo, attempt, maxRetries)
 		}
 
@@ -131,13 +206,14 @@ func sendRabbitMqMsgWithRetry(messageQueue *config.MessageQueue, message string,
 		}
 
 		// 尝试发布消息
-		err = producer.Publish(message)
+		err = producer.PublishWithContext(ctx, message, opts...)
 		if err != nil {
-			lastErr = err
-			// 如果是连接相关错误，标记通道为关闭状态，下次重试时会重新初始化
-			if producer.Channel != nil {
-				producer.Channel.Close()
+			// 被限流时不重试，避免重试进一步加重 broker 的负担，由调用方稍后重新发布
+			if errors.Is(err, config.ErrPublishThrottled) {
+				return fmt.Errorf("消息发布失败: %w", err)
 			}
+			lastErr = err
+			// 发布失败的通道已由发布通道池丢弃，下次重试时会借用或重新创建通道
 			if attempt < maxRetries {
 				continue // 继续重试
 			}
@@ -158,43 +234,38 @@ func sendRabbitMqMsgWithRetry(messageQueue *config.MessageQueue, message string,
 }
 
 // getOrInitProducer 获取或初始化消息队列生产者
-// 该函数是线程安全的，使用 sync.Map 提供并发安全的读写访问
+// 缓存的生产者连接可用时直接复用；连接已断开时从缓存中移除并关闭，重新创建。
+// 同一 queueInfo 的并发初始化通过 singleflight 合并为一次，避免缓存失效时大量请求同时建立连接
 // 参数：
-//   - messageQueue: 消息队列配置（指针）
+//   - messageQueue: 消息队列配置（指针），缓存中没有可用的生产者时使用该实例初始化
 //   - queueInfo: 队列信息字符串
 //
 // 返回：
 //   - *config.MessageQueue: 生产者实例
 //   - error: 初始化失败时返回错误
 func getOrInitProducer(messageQueue *config.MessageQueue, queueInfo string) (*config.MessageQueue, error) {
-	// 快速路径：尝试从 sync.Map 中读取已存在的生产者
-	if producer, ok := RabbitMQProducerList.Load(queueInfo); ok {
-		return producer.(*config.MessageQueue), nil
-	}
-
-	// 慢路径：需要初始化新的生产者
-	// 先初始化连接和通道
-	err := messageQueue.InitChannelForProducer()
-	if err != nil {
-		return nil, fmt.Errorf("初始化发送者失败, queueInfo: %s, error: %w", queueInfo, err)
+	// 快速路径：缓存的生产者可用时直接返回
+	if producer, ok := loadProducer(queueInfo); ok {
+		return producer, nil
 	}
 
-	// 使用 LoadOrStore 确保并发安全，避免重复初始化
-	// 如果另一个 goroutine 已经存储了该 key，则使用已存储的值
-	actual, loaded := RabbitMQProducerList.LoadOrStore(queueInfo, messageQueue)
-	if loaded {
-		// 已存在，关闭我们刚初始化的连接，使用已有的
-		if messageQueue.Channel != nil {
-			messageQueue.Channel.Close()
+	// 慢路径：同一 queueInfo 只有一个协程执行初始化，其余协程等待并共享结果
+	value, err, _ := producerInitGroup.Do(queueInfo, func() (any, error) {
+		// 等待期间其他协程可能已完成初始化
+		if producer, ok := loadProducer(queueInfo); ok {
+			return producer, nil
 		}
-		if messageQueue.Conn != nil {
-			messageQueue.Conn.Close()
+		if err := initProducer(messageQueue); err != nil {
+			return nil, fmt.Errorf("初始化发送者失败, queueInfo: %s, error: %w", queueInfo, err)
 		}
-		return actual.(*config.MessageQueue), nil
+		storeProducer(queueInfo, messageQueue)
+		logger.Info("[消息队列] 动态初始化发送者成功, queueInfo: %s", queueInfo)
+		return messageQueue, nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	logger.Info("[消息队列] 动态初始化发送者成功, queueInfo: %s", queueInfo)
-	return messageQueue, nil
+	return value.(*config.MessageQueue), nil
 }
 
 // SendRabbitMqMsgBatch 批量发送RabbitMQ消息
@@ -228,6 +299,24 @@ func SendRabbitMqMsgBatch(queueName string, exchangeName string,
 //   - error: 如果所有消息队列都发送失败则返回错误
 func SendRabbitMqMsgBatchWithContext(ctx context.Context, queueName string, exchangeName string,
 	exchangeType string, routingKey string, messages []string, mqConfigNames ...string) error {
+	return SendRabbitMqMsgBatchOpts(ctx, queueName, exchangeName, exchangeType, routingKey, messages, nil, mqConfigNames...)
+}
+
+// SendRabbitMqMsgBatchOpts 批量发送RabbitMQ消息，发布选项作用于每条消息
+// 参数：
+//   - ctx: context
+//   - queueName: 队列名称
+//   - exchangeName: 交换机名称
+//   - exchangeType: 交换机类型（direct, fanout, topic, headers）
+//   - routingKey: 路由键
+//   - messages: 消息内容列表
+//   - opts: 发布选项，如 config.WithTTL(time.Minute)、config.WithPriority(5)，可为 nil；不应使用 config.WithMessageID
+//   - mqConfigNames: 消息队列配置名称列表（可选，为空时使用默认配置）
+//
+// 返回：
+//   - error: 如果所有消息队列都发送失败则返回错误
+func SendRabbitMqMsgBatchOpts(ctx context.Context, queueName string, exchangeName string,
+	exchangeType string, routingKey string, messages []string, opts []config.PublishOption, mqConfigNames ...string) error {
 	if len(messages) == 0 {
 		return nil
 	}
@@ -253,14 +342,16 @@ func SendRabbitMqMsgBatchWithContext(ctx context.Context, queueName string, exch
 		if err != nil {
 			lastErr = err
 			logger.Error("[消息队列] 初始化生产者失败, queueInfo: %s, error: %v", queueInfo, err)
+			recordFailedBatch(messageQueue, messages, opts, err)
 			continue
 		}
 
 		// 批量发送消息
-		err = producer.PublishBatchWithContext(ctx, messages)
+		err = producer.PublishBatchWithContext(ctx, messages, opts...)
 		if err != nil {
 			lastErr = err
 			logger.Error("[消息队列] 批量消息发送失败, queueInfo: %s, error: %v", queueInfo, err)
+			recordFailedBatch(messageQueue, messages, opts, err)
 		} else {
 			successCount++
 			logger.Info("[消息队列] 批量消息发布成功, queueInfo: %s, 消息数量: %d", queueInfo, len(messages))
@@ -310,10 +401,11 @@ func SendRabbitMqMsgWithConfirm(queueName string, exchangeName string,
 		}
 
 		// 发送消息，带重试机制
-		err = sendRabbitMqMsgWithRetry(messageQueue, message, 3, 100*time.Millisecond)
+		err = sendRabbitMqMsgWithRetry(context.Background(), messageQueue, message, 3, 100*time.Millisecond)
 		if err != nil {
 			lastErr = err
 			logger.Error("[消息队列] 消息发送失败, queueInfo: %s, error: %v", messageQueue.GetInfo(), err)
+			recordFailedMessage(messageQueue, message, nil, err)
 		} else {
 			successCount++
 		}
@@ -326,3 +418,80 @@ func SendRabbitMqMsgWithConfirm(queueName string, exchangeName string,
 
 	return nil
 }
+
+// RabbitMQStats 汇总所有已启动消费者的统计信息
+//
+// 返回：
+//   - map[string]config.ConsumerStats: 按队列标识（GetInfo）索引的消费统计，未启动消费者时为空 map
+func RabbitMQStats() map[string]config.ConsumerStats {
+	stats := make(map[string]config.ConsumerStats)
+	RabbitMQConsumerList.Range(func(key, value any) bool {
+		stats[key.(string)] = value.(*config.MessageQueue).Stats()
+		return true
+	})
+	return stats
+}
+
+// ConsumerState 消费者的暂停状态
+type ConsumerState = config.ConsumerState
+
+// findRabbitMQConsumer 根据队列标识（GetInfo）查找已启动的消费者
+func findRabbitMQConsumer(queueInfo string) (*config.MessageQueue, error) {
+	value, ok := RabbitMQConsumerList.Load(queueInfo)
+	if !ok {
+		return nil, fmt.Errorf("[消息队列] 未找到消费者, queueInfo: %s", queueInfo)
+	}
+	return value.(*config.MessageQueue), nil
+}
+
+// PauseConsumer 暂停消费者
+// 取消通道上的消费者，已取出的消息继续处理完毕，未取出的消息留在队列中；暂停状态在断线重连后保持
+//
+// 参数：
+//   - queueInfo: 队列标识，格式同 MessageQueue.GetInfo
+//
+// 返回：
+//   - error: 未找到消费者或取消消费者失败时返回错误
+func PauseConsumer(queueInfo string) error {
+	mq, err := findRabbitMQConsumer(queueInfo)
+	if err != nil {
+		return err
+	}
+	if err := mq.Pause(); err != nil {
+		logger.Error("[消息队列] 暂停消费者失败, queueInfo: %s, error: %v", queueInfo, err)
+		return err
+	}
+	logger.Info("[消息队列] 消费者已暂停, queueInfo: %s", queueInfo)
+	return nil
+}
+
+// ResumeConsumer 恢复已暂停的消费者，未暂停时无操作
+//
+// 参数：
+//   - queueInfo: 队列标识，格式同 MessageQueue.GetInfo
+//
+// 返回：
+//   - error: 未找到消费者时返回错误
+func ResumeConsumer(queueInfo string) error {
+	mq, err := findRabbitMQConsumer(queueInfo)
+	if err != nil {
+		return err
+	}
+	mq.Resume()
+	logger.Info("[消息队列] 消费者已恢复, queueInfo: %s", queueInfo)
+	return nil
+}
+
+// ListConsumers 列出所有已启动消费者的暂停状态
+//
+// 返回：
+//   - []ConsumerState: 按队列标识排序，未启动消费者时为空切片
+func ListConsumers() []ConsumerState {
+	states := make([]ConsumerState, 0)
+	RabbitMQConsumerList.Range(func(_, value any) bool {
+		states = append(states, value.(*config.MessageQueue).ConsumerState())
+		return true
+	})
+	sort.Slice(states, func(i, j int) bool { return states[i].QueueInfo < states[j].QueueInfo })
+	return states
+}
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/zzsen/gin_core/failedmsg"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// RetryReport 发送失败消息的重放结果
+type RetryReport = failedmsg.Report
+
+// failedMessageRecorders 发送失败消息记录器，按持久化方式（redis / db）索引
+var failedMessageRecorders = struct {
+	mu        sync.RWMutex
+	recorders map[string]*failedmsg.Recorder
+}{recorders: map[string]*failedmsg.Recorder{}}
+
+// InitFailedMessageRecorders 根据 rabbitMQ、rabbitMQList 中的 failedMessageStore 配置创建发送失败消息记录器
+// 同一持久化方式的实例共用一个记录器；使用 db 时自动创建 failed_messages 表。
+// 由 RabbitMQ 服务在 Redis、MySQL 初始化之后调用
+//
+// 返回：
+//   - error: 使用的 Redis / 数据库未初始化或建表失败时返回错误
+func InitFailedMessageRecorders() error {
+	infos := make([]config.RabbitMQInfo, 0, len(BaseConfig.RabbitMQList)+1)
+	if BaseConfig.RabbitMQ.Host != "" {
+		infos = append(infos, BaseConfig.RabbitMQ)
+	}
+	infos = append(infos, BaseConfig.RabbitMQList...)
+
+	failedMessageRecorders.mu.Lock()
+	defer failedMessageRecorders.mu.Unlock()
+	for _, info := range infos {
+		kind := info.GetFailedMessageStore()
+		if kind == config.FailedMessageStoreNone {
+			continue
+		}
+		if _, ok := failedMessageRecorders.recorders[kind]; ok {
+			continue
+		}
+		var store failedmsg.Store
+		switch kind {
+		case config.FailedMessageStoreRedis:
+			if Redis == nil {
+				return errors.New("[消息队列] 发送失败消息保存到 Redis, 但 Redis 未初始化")
+			}
+			store = failedmsg.NewRedisStore(Redis, "")
+		case config.FailedMessageStoreDB:
+			if DB == nil {
+				return errors.New("[消息队列] 发送失败消息保存到数据表, 但数据库未初始化")
+			}
+			dbStore, err := failedmsg.NewDBStore(DB)
+			if err != nil {
+				return fmt.Errorf("[消息队列] %w", err)
+			}
+			store = dbStore
+		default:
+			return fmt.Errorf("[消息队列] 无法识别的发送失败消息持久化方式: %s", kind)
+		}
+		failedMessageRecorders.recorders[kind] = failedmsg.NewRecorder(store, failedmsg.DefaultQueueSize)
+		logger.Info("[消息队列] 发送失败消息记录器已创建, 持久化方式: %s", kind)
+	}
+	return nil
+}
+
+// CloseFailedMessageRecorders 关闭发送失败消息记录器，等待写入队列中的消息写入存储
+//
+// 返回：
+//   - error: ctx 结束时仍有消息未写入时返回错误
+func CloseFailedMessageRecorders(ctx context.Context) error {
+	failedMessageRecorders.mu.Lock()
+	recorders := failedMessageRecorders.recorders
+	failedMessageRecorders.recorders = map[string]*failedmsg.Recorder{}
+	failedMessageRecorders.mu.Unlock()
+
+	var errs []error
+	for kind, recorder := range recorders {
+		if err := recorder.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", kind, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FailedMessageCount 获取当前保存的发送失败消息数，未配置 failedMessageStore 时为 0
+func FailedMessageCount() int64 {
+	failedMessageRecorders.mu.RLock()
+	defer failedMessageRecorders.mu.RUnlock()
+	var count int64
+	for _, recorder := range failedMessageRecorders.recorders {
+		count += recorder.Stored()
+	}
+	return count
+}
+
+// RetryFailedMessages 重放保存的发送失败消息
+// 消息按记录时的实例配置、交换机、路由键和消息头通过普通发送流程重新发送（不再重试），
+// 发送成功后删除记录，失败时失败次数加 1 并保留记录，重放失败不会产生新的记录。
+// 投递语义为至少一次：发送成功但删除记录失败的消息会在下次重放时再次发送
+//
+// 使用示例：
+//
+//	report, err := app.RetryFailedMessages(ctx, 100)
+//	logger.Info("重放 %d 条, 成功 %d 条, 剩余 %d 条", report.Attempted, report.Succeeded, report.Remaining)
+//
+// 参数：
+//   - ctx: context，取消后停止重放
+//   - limit: 最多重放的消息数
+//
+// 返回：
+//   - RetryReport: 重放结果，多个持久化方式的结果合并
+//   - error: 读取存储失败或 ctx 已取消时返回错误
+func RetryFailedMessages(ctx context.Context, limit int) (RetryReport, error) {
+	failedMessageRecorders.mu.RLock()
+	recorders := make([]*failedmsg.Recorder, 0, len(failedMessageRecorders.recorders))
+	for _, recorder := range failedMessageRecorders.recorders {
+		recorders = append(recorders, recorder)
+	}
+	failedMessageRecorders.mu.RUnlock()
+
+	var total RetryReport
+	for _, recorder := range recorders {
+		if limit-total.Attempted <= 0 {
+			total.Remaining += recorder.Stored()
+			continue
+		}
+		report, err := recorder.Replay(ctx, limit-total.Attempted, replayFailedMessage)
+		total.Attempted += report.Attempted
+		total.Succeeded += report.Succeeded
+		total.Failed += report.Failed
+		total.Remaining += report.Remaining
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// replayFailedMessage 通过普通发送流程重新发送一条保存的消息，不重试
+func replayFailedMessage(ctx context.Context, msg *failedmsg.Message) error {
+	messageQueue, err := buildProducerMQ(msg.QueueName, msg.Exchange, msg.ExchangeType, msg.RoutingKey, msg.MQName)
+	if err != nil {
+		return err
+	}
+	return sendRabbitMqMsgWithRetry(ctx, messageQueue, msg.Body, 0, 0, config.WithHeaders(msg.Headers))
+}
+
+// recordFailedBatch 记录批量发送失败的消息，无法确定批次中哪些消息已被接收，因此记录全部消息，重放时可能重复发送
+func recordFailedBatch(messageQueue *config.MessageQueue, messages []string, opts []config.PublishOption, sendErr error) {
+	for _, message := range messages {
+		recordFailedMessage(messageQueue, message, opts, sendErr)
+	}
+}
+
+// recordFailedMessage 记录重试后仍发送失败的消息，实例未配置 failedMessageStore 或记录器未创建时不记录
+// 只将消息放入记录器的写入队列，不等待写入存储
+func recordFailedMessage(messageQueue *config.MessageQueue, message string, opts []config.PublishOption, sendErr error) {
+	mqInfo := findRabbitMQInfo(messageQueue.MQName)
+	if mqInfo == nil {
+		return
+	}
+	failedMessageRecorders.mu.RLock()
+	recorder := failedMessageRecorders.recorders[mqInfo.GetFailedMessageStore()]
+	failedMessageRecorders.mu.RUnlock()
+	if recorder == nil {
+		return
+	}
+
+	var publishing amqp.Publishing
+	for _, opt := range opts {
+		opt(&publishing)
+	}
+	recorder.Record(&failedmsg.Message{
+		MQName:       messageQueue.MQName,
+		QueueName:    messageQueue.QueueName,
+		Exchange:     messageQueue.ExchangeName,
+		ExchangeType: messageQueue.ExchangeType,
+		RoutingKey:   messageQueue.RoutingKey,
+		Body:         message,
+		Headers:      publishing.Headers,
+		Error:        sendErr.Error(),
+	})
+}
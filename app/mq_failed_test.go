@@ -0,0 +1,156 @@
+// Package app 发送失败消息持久化测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含发送失败消息记录与重放的单元测试，RabbitMQ 使用无法连接的地址模拟发送失败，
+// Redis 使用 miniredis，数据库使用 SQLite 内存数据库，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 重试后仍发送失败的消息（含消息头）被写入 Redis 列表或 failed_messages 表
+// 2. 重放仍失败的消息时保留记录并增加失败次数，不产生新的记录
+// 3. 未配置 failedMessageStore 时不记录
+//
+// 运行测试：go test -v ./app/... -run FailedMessage
+// ==================================================
+package app
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/failedmsg"
+	"github.com/zzsen/gin_core/model/config"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// unreachablePort 返回一个当前没有监听的本地端口
+func unreachablePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+	return port
+}
+
+// setupFailedMessageTest 将默认 RabbitMQ 实例指向无法连接的地址并设置持久化方式，
+// 使用 miniredis 和 SQLite 作为 Redis 和数据库，测试结束后关闭记录器并恢复全局变量
+func setupFailedMessageTest(t *testing.T, store string) {
+	originalMQ, originalRedis, originalDB := BaseConfig.RabbitMQ, Redis, DB
+	t.Cleanup(func() {
+		_ = CloseFailedMessageRecorders(context.Background())
+		BaseConfig.RabbitMQ, Redis, DB = originalMQ, originalRedis, originalDB
+	})
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	Redis = client
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	DB = db
+
+	BaseConfig.RabbitMQ = config.RabbitMQInfo{
+		Host:               "127.0.0.1",
+		Port:               unreachablePort(t),
+		Username:           "guest",
+		Password:           "guest",
+		FailedMessageStore: store,
+	}
+	require.NoError(t, InitFailedMessageRecorders())
+}
+
+// listFailedMessages 读取当前持久化方式中保存的消息
+func listFailedMessages(t *testing.T, store string) []failedmsg.Message {
+	var s failedmsg.Store
+	if store == config.FailedMessageStoreRedis {
+		s = failedmsg.NewRedisStore(Redis, "")
+	} else {
+		dbStore, err := failedmsg.NewDBStore(DB)
+		require.NoError(t, err)
+		s = dbStore
+	}
+	msgs, err := s.List(context.Background(), 100)
+	require.NoError(t, err)
+	return msgs
+}
+
+// TestFailedMessage_RecordAndRetry 测试发送失败消息的记录和重放
+//
+// 【功能点】验证重试后仍发送失败的消息被保存，重放仍失败时保留记录并增加失败次数
+// 【测试流程】
+//  1. 分别使用 redis 和 db 持久化方式，向无法连接的 RabbitMQ 发送带消息头的消息，断言返回错误
+//  2. 等待 FailedMessageCount 变为 1，读取存储断言交换机、路由键、消息内容、消息头、错误信息和失败次数
+//  3. 调用 RetryFailedMessages，断言重放 1 条、失败 1 条、剩余 1 条，存储中仍只有该消息且失败次数为 2
+func TestFailedMessage_RecordAndRetry(t *testing.T) {
+	for _, store := range []string{config.FailedMessageStoreRedis, config.FailedMessageStoreDB} {
+		t.Run(store, func(t *testing.T) {
+			setupFailedMessageTest(t, store)
+
+			err := SendRabbitMqMsgOpts(context.Background(), "orders", "order-exchange", "direct", "order.created", `{"id":1}`,
+				[]config.PublishOption{config.WithHeaders(amqp.Table{"tenant": "a"})})
+			require.Error(t, err)
+			require.Eventually(t, func() bool { return FailedMessageCount() == 1 }, 2*time.Second, 10*time.Millisecond)
+
+			msgs := listFailedMessages(t, store)
+			require.Len(t, msgs, 1)
+			assert.Equal(t, "orders", msgs[0].QueueName)
+			assert.Equal(t, "order-exchange", msgs[0].Exchange)
+			assert.Equal(t, "direct", msgs[0].ExchangeType)
+			assert.Equal(t, "order.created", msgs[0].RoutingKey)
+			assert.Equal(t, `{"id":1}`, msgs[0].Body)
+			assert.Equal(t, map[string]any{"tenant": "a"}, msgs[0].Headers)
+			assert.Contains(t, msgs[0].Error, "连接失败")
+			assert.Equal(t, 1, msgs[0].Attempts)
+
+			report, err := RetryFailedMessages(context.Background(), 10)
+			require.NoError(t, err)
+			assert.Equal(t, RetryReport{Attempted: 1, Failed: 1, Remaining: 1}, report)
+
+			msgs = listFailedMessages(t, store)
+			require.Len(t, msgs, 1)
+			assert.Equal(t, 2, msgs[0].Attempts)
+			assert.Equal(t, int64(1), FailedMessageCount())
+		})
+	}
+}
+
+// TestFailedMessage_WithConfirm 测试启用发布确认的发送失败记录
+//
+// 【功能点】验证 SendRabbitMqMsgWithConfirm 重试后仍失败时同样保存消息
+// 【测试流程】使用 redis 持久化方式调用 SendRabbitMqMsgWithConfirm，断言返回错误且 FailedMessageCount 变为 1
+func TestFailedMessage_WithConfirm(t *testing.T) {
+	setupFailedMessageTest(t, config.FailedMessageStoreRedis)
+
+	err := SendRabbitMqMsgWithConfirm("orders", "order-exchange", "direct", "order.created", "hello", 100*time.Millisecond)
+	require.Error(t, err)
+	require.Eventually(t, func() bool { return FailedMessageCount() == 1 }, 2*time.Second, 10*time.Millisecond)
+}
+
+// TestFailedMessage_NoStore 测试未配置持久化方式
+//
+// 【功能点】验证 failedMessageStore 为 none 时不创建记录器、不保存消息，重放结果为空
+// 【测试流程】使用 none 发送消息失败后，断言 FailedMessageCount 为 0，RetryFailedMessages 返回空结果
+func TestFailedMessage_NoStore(t *testing.T) {
+	setupFailedMessageTest(t, config.FailedMessageStoreNone)
+
+	require.Error(t, SendRabbitMqMsg("orders", "order-exchange", "direct", "order.created", "hello"))
+	assert.Zero(t, FailedMessageCount())
+	report, err := RetryFailedMessages(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, RetryReport{}, report)
+}
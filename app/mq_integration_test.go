@@ -23,6 +23,8 @@ import (
 	"testing"
 	"time"
 
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/zzsen/gin_core/exception/mq"
 	"github.com/zzsen/gin_core/model/config"
 )
 
@@ -92,12 +94,16 @@ func setupIntegrationTestConfig() func() {
 	}
 }
 
-// clearIntegrationRabbitMQProducerList 清空生产者列表（用于集成测试）
+// clearIntegrationRabbitMQProducerList 清空生产者列表和动态生产者的使用记录（用于集成测试）
 func clearIntegrationRabbitMQProducerList() {
 	RabbitMQProducerList.Range(func(key, value any) bool {
 		RabbitMQProducerList.Delete(key)
 		return true
 	})
+	producerUsages.Range(func(key, value any) bool {
+		producerUsages.Delete(key)
+		return true
+	})
 }
 
 // generateQueueName 生成唯一的队列名称
@@ -258,6 +264,42 @@ func TestIntegration_SendRabbitMqMsgWithConfirm(t *testing.T) {
 	t.Log("确认模式消息发送成功")
 }
 
+// ==================== 集成测试：生产者重连（需要 RabbitMQ 连接） ====================
+// 测试点：验证缓存的生产者连接断开后重新创建
+
+// TestIntegration_ProducerReconnect 测试生产者连接断开后重新创建
+// 需要 RabbitMQ 连接：发送消息后关闭缓存的生产者的连接，再次发送时应创建新的生产者
+func TestIntegration_ProducerReconnect(t *testing.T) {
+	requireRabbitMQConnection(t)
+	cleanup := setupIntegrationTestConfig()
+	defer cleanup()
+
+	queueName := generateQueueName("test-reconnect")
+	exchangeName := queueName + "-exchange"
+	exchangeType := "direct"
+	routingKey := queueName + "-key"
+	queueInfo := (&config.MessageQueue{QueueName: queueName, ExchangeName: exchangeName, ExchangeType: exchangeType, RoutingKey: routingKey}).GetInfo()
+
+	if err := SendRabbitMqMsg(queueName, exchangeName, exchangeType, routingKey, "before reconnect"); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	cached, ok := RabbitMQProducerList.Load(queueInfo)
+	if !ok {
+		t.Fatal("发送后应缓存生产者")
+	}
+	oldProducer := cached.(*config.MessageQueue)
+	if err := oldProducer.Conn.Close(); err != nil {
+		t.Fatalf("关闭生产者连接失败: %v", err)
+	}
+
+	if err := SendRabbitMqMsg(queueName, exchangeName, exchangeType, routingKey, "after reconnect"); err != nil {
+		t.Fatalf("连接断开后发送消息失败: %v", err)
+	}
+	if cached, _ := RabbitMQProducerList.Load(queueInfo); cached == oldProducer {
+		t.Error("连接断开后应创建新的生产者")
+	}
+}
+
 // ==================== 集成测试：端到端消息收发（需要 RabbitMQ 连接） ====================
 // 测试点：验证消息发送和消费的完整流程
 
@@ -411,6 +453,75 @@ func TestIntegration_EndToEnd_JSONMessage(t *testing.T) {
 	}
 }
 
+// TestIntegration_SendRabbitMqJSON 测试发送 JSON 消息并使用类型化消费函数消费
+// 需要 RabbitMQ 连接：验证 SendRabbitMqJSON 设置内容类型为 application/json，mq.TypedHandler 收到的结构体与发送的一致
+func TestIntegration_SendRabbitMqJSON(t *testing.T) {
+	requireRabbitMQConnection(t)
+	cleanup := setupIntegrationTestConfig()
+	defer cleanup()
+
+	queueName := generateQueueName("test-json-typed")
+	exchangeName := queueName + "-exchange"
+	routingKey := queueName + "-key"
+
+	contentTypes := make(chan string, 1)
+	receivedOrders := make(chan OrderMessage, 1)
+	consumer := config.MessageQueue{
+		QueueName:    queueName,
+		ExchangeName: exchangeName,
+		ExchangeType: "direct",
+		RoutingKey:   routingKey,
+		MqConnStr:    BaseConfig.RabbitMQ.Url(),
+		Middlewares: []config.ConsumerMiddleware{func(next config.ConsumerHandlerFunc) config.ConsumerHandlerFunc {
+			return func(ctx context.Context, msg amqp.Delivery) error {
+				contentTypes <- msg.ContentType
+				return next(ctx, msg)
+			}
+		}},
+		FunWithCtx: mq.TypedHandler(func(ctx context.Context, order OrderMessage) error {
+			receivedOrders <- order
+			return nil
+		}),
+	}
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go func() {
+		_ = consumer.ConsumeWithContext(ctx)
+	}()
+
+	time.Sleep(1 * time.Second)
+
+	order := OrderMessage{
+		OrderID:   "ORD-" + fmt.Sprintf("%d", time.Now().UnixNano()),
+		UserID:    "USER-001",
+		Amount:    199.99,
+		Timestamp: time.Now().Unix(),
+	}
+	if err := SendRabbitMqJSON(ctx, queueName, exchangeName, "direct", routingKey, order, nil); err != nil {
+		t.Fatalf("发送 JSON 消息失败: %v", err)
+	}
+
+	select {
+	case contentType := <-contentTypes:
+		if contentType != "application/json" {
+			t.Errorf("ContentType 不匹配: got %s, want application/json", contentType)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("接收消息超时")
+	}
+	select {
+	case received := <-receivedOrders:
+		if received != order {
+			t.Errorf("消息不匹配: got %+v, want %+v", received, order)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("接收订单消息超时")
+	}
+}
+
 // ==================== 集成测试：并发发送（需要 RabbitMQ 连接） ====================
 // 测试点：验证并发发送消息的正确性和线程安全性
 
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"golang.org/x/sync/singleflight"
+)
+
+// producerInitGroup 按 queueInfo 合并并发的生产者初始化
+var producerInitGroup singleflight.Group
+
+// producerUsages 动态创建的生产者的最近使用时间，key: queueInfo，value: *producerUsage
+// 空闲清理只处理其中的生产者，启动时通过 AddMessageQueueProducer 注册的生产者不会被清理
+var producerUsages sync.Map
+
+// producerUsage 动态创建的生产者及其最近使用时间
+type producerUsage struct {
+	producer *config.MessageQueue
+	lastUsed atomic.Int64 // 最近使用时间（UnixNano）
+}
+
+// 生产者的初始化、健康检查和关闭，单元测试中可替换为模拟实现
+var (
+	initProducer  = (*config.MessageQueue).InitChannelForProducer
+	producerAlive = (*config.MessageQueue).ConnAlive
+	closeProducer = (*config.MessageQueue).Close
+)
+
+// loadProducer 获取缓存的生产者并更新最近使用时间
+// 生产者的连接已断开时从缓存中移除并关闭，返回 false
+func loadProducer(queueInfo string) (*config.MessageQueue, bool) {
+	value, ok := RabbitMQProducerList.Load(queueInfo)
+	if !ok {
+		return nil, false
+	}
+	producer := value.(*config.MessageQueue)
+	if !producerAlive(producer) {
+		evictProducer(queueInfo, producer, "连接已断开")
+		return nil, false
+	}
+	if value, ok := producerUsages.Load(queueInfo); ok {
+		if usage := value.(*producerUsage); usage.producer == producer {
+			usage.lastUsed.Store(time.Now().UnixNano())
+		}
+	}
+	return producer, true
+}
+
+// storeProducer 缓存动态创建的生产者，并记录最近使用时间
+func storeProducer(queueInfo string, producer *config.MessageQueue) {
+	usage := &producerUsage{producer: producer}
+	usage.lastUsed.Store(time.Now().UnixNano())
+	producerUsages.Store(queueInfo, usage)
+	RabbitMQProducerList.Store(queueInfo, producer)
+}
+
+// evictProducer 从缓存中移除生产者并关闭，缓存中已替换为其他实例时不处理
+//
+// 返回：
+//   - bool: 是否移除
+func evictProducer(queueInfo string, producer *config.MessageQueue, reason string) bool {
+	if !RabbitMQProducerList.CompareAndDelete(queueInfo, producer) {
+		return false
+	}
+	if value, ok := producerUsages.Load(queueInfo); ok && value.(*producerUsage).producer == producer {
+		producerUsages.CompareAndDelete(queueInfo, value)
+	}
+	closeProducer(producer)
+	logger.Warn("[消息队列] 已移除发送者, queueInfo: %s, 原因: %s", queueInfo, reason)
+	return true
+}
+
+// sweepIdleProducers 关闭并移除空闲超过 idleTimeout 的动态生产者
+//
+// 返回：
+//   - int: 移除的生产者数量
+func sweepIdleProducers(idleTimeout time.Duration) int {
+	deadline := time.Now().Add(-idleTimeout).UnixNano()
+	removed := 0
+	producerUsages.Range(func(key, value any) bool {
+		usage := value.(*producerUsage)
+		if usage.lastUsed.Load() < deadline && evictProducer(key.(string), usage.producer, "空闲超时") {
+			removed++
+		}
+		return true
+	})
+	return removed
+}
+
+// StartProducerSweeper 启动空闲生产者清理协程，ctx 取消后退出
+// 每隔 interval 检查一次通过 SendRabbitMqMsg 等函数动态创建的生产者，空闲超过 idleTimeout 的关闭并从缓存中移除，
+// 下次发送时重新创建，避免只发送一次的队列长期占用连接和通道
+// 参数：
+//   - ctx: 控制清理协程退出
+//   - interval: 检查间隔
+//   - idleTimeout: 空闲超时时间
+func StartProducerSweeper(ctx context.Context, interval, idleTimeout time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if removed := sweepIdleProducers(idleTimeout); removed > 0 {
+					logger.Info("[消息队列] 已清理空闲发送者, 数量: %d", removed)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,230 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// ==================== 单元测试：生产者缓存的健康检查与空闲清理（不需要 RabbitMQ 连接） ====================
+//
+// 本文件使用模拟的连接替换生产者的初始化、健康检查和关闭函数。
+// 这些测试主要验证：
+// - 缓存的生产者连接断开后被移除并关闭，下次获取时重新创建
+// - 并发获取失效的生产者时只初始化一次
+// - 空闲超时的动态生产者被清理，启动时注册的生产者和最近使用的生产者保留
+
+// fakeProducerConn 模拟的生产者连接
+type fakeProducerConn struct {
+	closed atomic.Bool
+}
+
+// fakeProducers 模拟的生产者连接管理，记录初始化和关闭次数
+type fakeProducers struct {
+	mu        sync.Mutex
+	conns     map[*config.MessageQueue]*fakeProducerConn
+	inits     atomic.Int32
+	closes    atomic.Int32
+	initDelay time.Duration
+}
+
+// useFakeProducers 替换生产者的初始化、健康检查和关闭函数，测试结束后恢复并清空生产者列表
+func useFakeProducers(t *testing.T) *fakeProducers {
+	t.Helper()
+	fake := &fakeProducers{conns: make(map[*config.MessageQueue]*fakeProducerConn)}
+	originalInit, originalAlive, originalClose := initProducer, producerAlive, closeProducer
+	initProducer = func(m *config.MessageQueue) error {
+		fake.inits.Add(1)
+		time.Sleep(fake.initDelay)
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		fake.conns[m] = &fakeProducerConn{}
+		return nil
+	}
+	producerAlive = func(m *config.MessageQueue) bool {
+		return !fake.conn(m).closed.Load()
+	}
+	closeProducer = func(m *config.MessageQueue) {
+		fake.closes.Add(1)
+		fake.conn(m).closed.Store(true)
+	}
+	clearRabbitMQProducerList()
+	t.Cleanup(func() {
+		initProducer, producerAlive, closeProducer = originalInit, originalAlive, originalClose
+		clearRabbitMQProducerList()
+	})
+	return fake
+}
+
+// conn 返回生产者的模拟连接，未初始化的生产者视为连接可用
+func (f *fakeProducers) conn(m *config.MessageQueue) *fakeProducerConn {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if conn, ok := f.conns[m]; ok {
+		return conn
+	}
+	conn := &fakeProducerConn{}
+	f.conns[m] = conn
+	return conn
+}
+
+// newTestProducer 创建测试用的生产者配置
+func newTestProducer(queueName string) *config.MessageQueue {
+	return &config.MessageQueue{QueueName: queueName, ExchangeName: queueName + "-exchange", ExchangeType: "direct", RoutingKey: queueName + "-key"}
+}
+
+// TestGetOrInitProducer_EvictBroken 测试连接断开的生产者被移除并重新创建
+//
+// 【功能点】验证缓存的生产者连接可用时复用，连接断开后移除并关闭，下次获取时重新创建
+// 【测试流程】
+//  1. 获取两次生产者，断言返回同一实例且只初始化一次
+//  2. 关闭模拟连接后再次获取，断言返回新实例、初始化两次、旧实例被关闭
+//  3. 断言缓存中为新实例
+func TestGetOrInitProducer_EvictBroken(t *testing.T) {
+	fake := useFakeProducers(t)
+	queueInfo := newTestProducer("evict").GetInfo()
+
+	first, err := getOrInitProducer(newTestProducer("evict"), queueInfo)
+	if err != nil {
+		t.Fatalf("初始化生产者失败: %v", err)
+	}
+	second, _ := getOrInitProducer(newTestProducer("evict"), queueInfo)
+	if second != first || fake.inits.Load() != 1 {
+		t.Fatalf("连接可用时应复用生产者，初始化次数: %d", fake.inits.Load())
+	}
+
+	fake.conn(first).closed.Store(true)
+	third, err := getOrInitProducer(newTestProducer("evict"), queueInfo)
+	if err != nil {
+		t.Fatalf("重新创建生产者失败: %v", err)
+	}
+	if third == first {
+		t.Error("连接断开后应返回新的生产者")
+	}
+	if fake.inits.Load() != 2 || fake.closes.Load() != 1 {
+		t.Errorf("期望初始化 2 次、关闭 1 次，实际初始化 %d 次、关闭 %d 次", fake.inits.Load(), fake.closes.Load())
+	}
+	if cached, _ := RabbitMQProducerList.Load(queueInfo); cached != third {
+		t.Error("缓存中应为新的生产者")
+	}
+}
+
+// TestGetOrInitProducer_Singleflight 测试并发获取失效的生产者时只初始化一次
+//
+// 【功能点】验证缓存的生产者失效后，100 个协程并发获取只执行一次初始化，并返回同一实例
+// 【测试流程】
+//  1. 创建生产者后关闭模拟连接，初始化耗时设为 20ms
+//  2. 100 个协程并发获取，断言重新初始化只执行一次、旧实例只关闭一次、所有协程得到同一实例
+func TestGetOrInitProducer_Singleflight(t *testing.T) {
+	fake := useFakeProducers(t)
+	queueInfo := newTestProducer("singleflight").GetInfo()
+	broken, err := getOrInitProducer(newTestProducer("singleflight"), queueInfo)
+	if err != nil {
+		t.Fatalf("初始化生产者失败: %v", err)
+	}
+	fake.conn(broken).closed.Store(true)
+	fake.initDelay = 20 * time.Millisecond
+
+	var wg sync.WaitGroup
+	results := make([]*config.MessageQueue, 100)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			producer, err := getOrInitProducer(newTestProducer("singleflight"), queueInfo)
+			if err != nil {
+				t.Errorf("获取生产者失败: %v", err)
+			}
+			results[i] = producer
+		}(i)
+	}
+	wg.Wait()
+
+	if inits := fake.inits.Load(); inits != 2 {
+		t.Errorf("失效后应只重新初始化 1 次，实际 %d 次", inits-1)
+	}
+	if closes := fake.closes.Load(); closes != 1 {
+		t.Errorf("失效的生产者应只关闭 1 次，实际 %d 次", closes)
+	}
+	for _, producer := range results {
+		if producer != results[0] || producer == broken {
+			t.Fatal("所有协程应得到同一个新的生产者")
+		}
+	}
+}
+
+// TestSweepIdleProducers 测试清理空闲的生产者
+//
+// 【功能点】验证空闲超时的动态生产者被关闭并移除，最近使用的动态生产者和启动时注册的生产者保留
+// 【测试流程】
+//  1. 动态创建 idle、active 两个生产者，将 idle 的最近使用时间设为 2 分钟前；直接存入 registered 生产者
+//  2. 以 1 分钟空闲超时清理，断言移除 1 个、idle 被关闭且不在缓存中，active 和 registered 保留
+//  3. 再次获取 idle，断言重新创建
+func TestSweepIdleProducers(t *testing.T) {
+	fake := useFakeProducers(t)
+	idleInfo, activeInfo := newTestProducer("idle").GetInfo(), newTestProducer("active").GetInfo()
+	idle, _ := getOrInitProducer(newTestProducer("idle"), idleInfo)
+	_, _ = getOrInitProducer(newTestProducer("active"), activeInfo)
+	registered := newTestProducer("registered")
+	RabbitMQProducerList.Store(registered.GetInfo(), registered)
+
+	usage, _ := producerUsages.Load(idleInfo)
+	usage.(*producerUsage).lastUsed.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+
+	if removed := sweepIdleProducers(time.Minute); removed != 1 {
+		t.Fatalf("期望清理 1 个生产者，实际 %d 个", removed)
+	}
+	if !fake.conn(idle).closed.Load() {
+		t.Error("空闲的生产者应被关闭")
+	}
+	if _, ok := RabbitMQProducerList.Load(idleInfo); ok {
+		t.Error("空闲的生产者应从缓存中移除")
+	}
+	for _, queueInfo := range []string{activeInfo, registered.GetInfo()} {
+		if _, ok := RabbitMQProducerList.Load(queueInfo); !ok {
+			t.Errorf("生产者 %s 不应被清理", queueInfo)
+		}
+	}
+
+	recreated, _ := getOrInitProducer(newTestProducer("idle"), idleInfo)
+	if recreated == idle || fake.inits.Load() != 3 {
+		t.Error("清理后再次获取应重新创建生产者")
+	}
+}
+
+// TestStartProducerSweeper 测试空闲生产者清理协程
+//
+// 【功能点】验证清理协程按间隔清理空闲的生产者，ctx 取消后退出
+// 【测试流程】
+//  1. 动态创建生产者，以 10ms 间隔、10ms 空闲超时启动清理协程
+//  2. 断言 1 秒内生产者被移除；取消 ctx 后新建的生产者不再被清理
+func TestStartProducerSweeper(t *testing.T) {
+	useFakeProducers(t)
+	queueInfo := newTestProducer("sweeper").GetInfo()
+	_, _ = getOrInitProducer(newTestProducer("sweeper"), queueInfo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	StartProducerSweeper(ctx, 10*time.Millisecond, 10*time.Millisecond)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := RabbitMQProducerList.Load(queueInfo); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("等待清理空闲生产者超时")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	_, _ = getOrInitProducer(newTestProducer("sweeper"), queueInfo)
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := RabbitMQProducerList.Load(queueInfo); !ok {
+		t.Error("ctx 取消后清理协程应退出")
+	}
+}
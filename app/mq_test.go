@@ -4,12 +4,13 @@
 // 本文件包含 RabbitMQ 消息发送功能的单元测试和集成测试。
 //
 // 测试覆盖内容：
-// 1. 参数校验 - 空配置、空消息列表处理
+// 1. 参数校验 - 空配置、空消息列表处理、JSON 消息序列化失败
 // 2. 生产者管理 - 获取、创建、复用生产者
 // 3. 消息发送 - 单条消息、批量消息发送
 // 4. 连接重试 - 连接失败时的重试机制
 // 5. 并发安全 - 多协程并发发送消息
 // 6. 延迟消息 - 使用 x-delayed-message 插件的延迟消息
+// 7. 消费者暂停 / 恢复 - 按队列标识暂停、恢复和列出消费者状态
 //
 // 运行单元测试：go test -v ./app/... -run "^Test.*_No"
 // 运行集成测试：需要真实 RabbitMQ 连接
@@ -18,6 +19,8 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -84,12 +87,16 @@ func setupTestConfig() func() {
 	}
 }
 
-// clearRabbitMQProducerList 清空生产者列表（用于测试）
+// clearRabbitMQProducerList 清空生产者列表和动态生产者的使用记录（用于测试）
 func clearRabbitMQProducerList() {
 	RabbitMQProducerList.Range(func(key, value any) bool {
 		RabbitMQProducerList.Delete(key)
 		return true
 	})
+	producerUsages.Range(func(key, value any) bool {
+		producerUsages.Delete(key)
+		return true
+	})
 }
 
 // getRabbitMQProducerListLength 获取生产者列表长度（用于测试）
@@ -191,6 +198,43 @@ func TestSendRabbitMqMsgWithConfirm_NoConfig(t *testing.T) {
 	}
 }
 
+// TestSendRabbitMqMsgOpts_CancelledContext 测试 context 取消后不再重试
+//
+// 【功能点】验证 SendRabbitMqMsgOpts 首次发送失败后，context 已取消时立即返回而不是继续重试
+// 【测试流程】清空配置使连接失败，传入已取消的 context 和发布选项，验证返回包含 context.Canceled 的错误
+func TestSendRabbitMqMsgOpts_CancelledContext(t *testing.T) {
+	originalConfig := BaseConfig
+	BaseConfig = config.BaseConfig{}
+	defer func() { BaseConfig = originalConfig }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := SendRabbitMqMsgOpts(ctx, "test-queue", "test-exchange", "direct", "test-key", "test message",
+		[]config.PublishOption{config.WithTTL(time.Minute), config.WithPriority(5)})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("context 取消后应返回 context.Canceled, got %v", err)
+	}
+
+	err = SendRabbitMqMsgBatchOpts(ctx, "test-queue", "test-exchange", "direct", "test-key", nil,
+		[]config.PublishOption{config.WithPriority(5)})
+	if err != nil {
+		t.Errorf("空消息列表应返回 nil，实际返回 %v", err)
+	}
+}
+
+// TestSendRabbitMqJSON_MarshalError 测试消息无法序列化时返回错误
+//
+// 【功能点】验证 SendRabbitMqJSON 序列化失败时直接返回错误，不尝试发送
+// 【测试流程】传入包含 channel 的消息，验证返回包含 *json.UnsupportedTypeError 的错误
+func TestSendRabbitMqJSON_MarshalError(t *testing.T) {
+	err := SendRabbitMqJSON(context.Background(), "test-queue", "test-exchange", "direct", "test-key",
+		map[string]any{"ch": make(chan int)}, nil)
+	var typeErr *json.UnsupportedTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("无法序列化的消息应返回 *json.UnsupportedTypeError, got %v", err)
+	}
+}
+
 // TestSendRabbitMqMsg_InvalidMQName 测试使用不存在的 MQName 应返回错误
 //
 // 【功能点】验证使用不存在的 MQName 时返回错误
@@ -258,6 +302,59 @@ func TestGetOrInitProducer_ExistingProducer(t *testing.T) {
 	}
 }
 
+// ==================== 单元测试：消费者暂停 / 恢复（不需要 RabbitMQ 连接） ====================
+// 测试点：验证按队列标识暂停、恢复和列出已启动消费者的状态
+
+// TestPauseConsumer_NotFound 测试暂停、恢复未启动的消费者应返回错误
+//
+// 【功能点】验证队列标识不在 RabbitMQConsumerList 中时返回错误
+// 【测试流程】分别调用 PauseConsumer 和 ResumeConsumer，验证返回错误
+func TestPauseConsumer_NotFound(t *testing.T) {
+	if err := PauseConsumer("missing"); err == nil {
+		t.Error("消费者不存在时暂停应返回错误")
+	}
+	if err := ResumeConsumer("missing"); err == nil {
+		t.Error("消费者不存在时恢复应返回错误")
+	}
+}
+
+// TestListConsumers_NoConnection 测试未连接时暂停、恢复和列出消费者状态
+//
+// 【功能点】验证消费者未连接时暂停只记录状态，ListConsumers 按队列标识排序返回状态
+// 【测试流程】
+//  1. 登记两个未连接的消费者，暂停其中一个
+//  2. 验证列表按队列标识排序，暂停的消费者状态为 paused 且记录暂停时间
+//  3. 恢复后验证状态为 running
+func TestListConsumers_NoConnection(t *testing.T) {
+	orders := &config.MessageQueue{QueueName: "orders", ExchangeName: "shop", ExchangeType: "direct", RoutingKey: "orders"}
+	audit := &config.MessageQueue{QueueName: "audit", ExchangeName: "shop", ExchangeType: "direct", RoutingKey: "audit"}
+	RabbitMQConsumerList.Store(orders.GetInfo(), orders)
+	RabbitMQConsumerList.Store(audit.GetInfo(), audit)
+	defer RabbitMQConsumerList.Clear()
+
+	if err := PauseConsumer(orders.GetInfo()); err != nil {
+		t.Fatalf("暂停未连接的消费者不应返回错误: %v", err)
+	}
+
+	states := ListConsumers()
+	if len(states) != 2 {
+		t.Fatalf("应返回 2 个消费者状态, 实际: %d", len(states))
+	}
+	if states[0].QueueInfo != audit.GetInfo() || states[0].State != config.ConsumerStateRunning {
+		t.Errorf("第一个消费者应为运行中的 audit, 实际: %+v", states[0])
+	}
+	if states[1].State != config.ConsumerStatePaused || states[1].PausedAt == nil {
+		t.Errorf("orders 应为已暂停并记录暂停时间, 实际: %+v", states[1])
+	}
+
+	if err := ResumeConsumer(orders.GetInfo()); err != nil {
+		t.Fatalf("恢复消费者不应返回错误: %v", err)
+	}
+	if state := orders.ConsumerState(); state.State != config.ConsumerStateRunning || state.PausedAt != nil {
+		t.Errorf("恢复后应为运行中, 实际: %+v", state)
+	}
+}
+
 // ==================== 集成测试：生产者初始化（需要 RabbitMQ 连接） ====================
 // 测试点：验证生产者的初始化和并发初始化
 
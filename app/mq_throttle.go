@@ -0,0 +1,61 @@
+package app
+
+import (
+	"sync"
+
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// publishThrottles RabbitMQ 发布限流器，按实例别名索引（默认实例为空字符串）
+// 同一实例的所有发送者共享一个限流器，未启用发布限流的实例不存储
+var publishThrottles sync.Map
+
+// publishThrottleFor 获取实例的发布限流器，首次获取时根据实例配置创建
+// 参数：
+//   - mqConfigName: 实例别名，为空时表示默认实例
+//   - info: 实例配置
+//
+// 返回：
+//   - *config.PublishThrottle: 未启用发布限流时返回 nil
+func publishThrottleFor(mqConfigName string, info *config.RabbitMQInfo) *config.PublishThrottle {
+	if v, ok := publishThrottles.Load(mqConfigName); ok {
+		return v.(*config.PublishThrottle)
+	}
+	throttle := config.NewPublishThrottle(info.PublishRateLimit)
+	if throttle == nil {
+		return nil
+	}
+	v, _ := publishThrottles.LoadOrStore(mqConfigName, throttle)
+	return v.(*config.PublishThrottle)
+}
+
+// RabbitMQPublishThrottle 获取 RabbitMQ 实例的发布限流器
+// 通过 InitialRabbitMqProducer 预初始化的发送者和 SendRabbitMqMsg 系列函数创建的发送者共享同一个限流器
+// 参数：
+//   - mqConfigName: 实例别名，为空时表示默认实例
+//
+// 返回：
+//   - *config.PublishThrottle: 实例不存在或未启用发布限流时返回 nil
+func RabbitMQPublishThrottle(mqConfigName string) *config.PublishThrottle {
+	info := findRabbitMQInfo(mqConfigName)
+	if info == nil {
+		return nil
+	}
+	return publishThrottleFor(mqConfigName, info)
+}
+
+// RabbitMQPublishThrottleStats 获取已启用发布限流的 RabbitMQ 实例的限流统计信息
+// 返回：
+//   - map[string]config.PublishThrottleStats: 按实例别名索引，默认实例为 "default"
+func RabbitMQPublishThrottleStats() map[string]config.PublishThrottleStats {
+	stats := make(map[string]config.PublishThrottleStats)
+	publishThrottles.Range(func(key, value any) bool {
+		name := key.(string)
+		if name == "" {
+			name = "default"
+		}
+		stats[name] = value.(*config.PublishThrottle).Stats()
+		return true
+	})
+	return stats
+}
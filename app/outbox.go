@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/outbox"
+	"gorm.io/gorm"
+)
+
+// OutboxMessage 发件箱消息
+type OutboxMessage = outbox.Message
+
+// PublishInTx 在调用方的事务中写入一条待发送的 RabbitMQ 消息
+// 消息与业务数据在同一事务中提交或回滚；事务提交后由发件箱中继（outbox.enabled）负责投递，
+// 避免"数据库提交成功但消息发送失败"导致的消息丢失。投递语义为至少一次，消费者需要保证幂等。
+//
+// 使用示例：
+//
+//	err := app.DB.Transaction(func(tx *gorm.DB) error {
+//	    if err := tx.Create(&order).Error; err != nil {
+//	        return err
+//	    }
+//	    return app.PublishInTx(tx, app.OutboxMessage{
+//	        Exchange:   "order",
+//	        RoutingKey: "order.created",
+//	        Body:       string(payload),
+//	    })
+//	})
+//
+// 参数：
+//   - tx: 调用方的事务
+//   - msg: 待发送消息，需设置 Exchange、Body，可选设置 ExchangeType（默认 direct）、RoutingKey、QueueName、MQName
+//
+// 返回：
+//   - error: 参数非法或写入失败时返回错误
+func PublishInTx(tx *gorm.DB, msg OutboxMessage) error {
+	return outbox.Insert(tx, &msg)
+}
+
+// RabbitMQOutboxPublisher 基于 RabbitMQ 的发件箱消息发布者
+// 复用 MessageQueue 的发布通道池，并始终启用 Publisher Confirms，
+// 只有收到 RabbitMQ 确认后才视为发送成功
+type RabbitMQOutboxPublisher struct {
+	confirmTimeout time.Duration
+	// producers 启用发布确认的生产者，key: queueInfo，与 RabbitMQProducerList 分开存放，
+	// 避免复用到未启用发布确认的生产者
+	producers sync.Map
+}
+
+// NewRabbitMQOutboxPublisher 创建基于 RabbitMQ 的发件箱消息发布者
+// 参数：
+//   - confirmTimeout: 发布确认超时时间
+//
+// 返回：
+//   - *RabbitMQOutboxPublisher: 发布者实例
+func NewRabbitMQOutboxPublisher(confirmTimeout time.Duration) *RabbitMQOutboxPublisher {
+	return &RabbitMQOutboxPublisher{confirmTimeout: confirmTimeout}
+}
+
+// Publish 发布消息并等待 RabbitMQ 确认
+// 被发布限流时返回包装了 outbox.ErrDeferred 的错误，中继稍后重新投递且不计入失败次数
+func (p *RabbitMQOutboxPublisher) Publish(ctx context.Context, msg *OutboxMessage) error {
+	producer, err := p.producer(msg)
+	if err != nil {
+		return err
+	}
+	// 使用发件箱记录 ID 作为消息 ID，确认超时后重发的消息可被消费端去重
+	if err := producer.PublishWithMessageID(ctx, msg.Body, fmt.Sprintf("outbox-%d", msg.ID)); err != nil {
+		if errors.Is(err, config.ErrPublishThrottled) {
+			return fmt.Errorf("%w: %w", outbox.ErrDeferred, err)
+		}
+		return err
+	}
+	if BaseConfig.RabbitMQ.LogMessageContent {
+		logger.Info("[消息队列] 发件箱消息发布成功, id: %d, queueInfo: %s, message: %s", msg.ID, producer.GetInfo(), msg.Body)
+	} else {
+		logger.Info("[消息队列] 发件箱消息发布成功, id: %d, queueInfo: %s", msg.ID, producer.GetInfo())
+	}
+	return nil
+}
+
+// producer 获取或创建消息对应的生产者
+func (p *RabbitMQOutboxPublisher) producer(msg *OutboxMessage) (*config.MessageQueue, error) {
+	mq, err := buildProducerMQ(msg.QueueName, msg.Exchange, msg.ExchangeType, msg.RoutingKey, msg.MQName)
+	if err != nil {
+		return nil, err
+	}
+	queueInfo := mq.GetInfo()
+	if producer, ok := p.producers.Load(queueInfo); ok {
+		return producer.(*config.MessageQueue), nil
+	}
+
+	mq.PublishConfirm = config.PublishConfirmConfig{
+		Enabled: true,
+		Timeout: p.confirmTimeout,
+	}
+	actual, _ := p.producers.LoadOrStore(queueInfo, mq)
+	return actual.(*config.MessageQueue), nil
+}
+
+// Close 关闭所有生产者连接
+func (p *RabbitMQOutboxPublisher) Close() {
+	p.producers.Range(func(key, value any) bool {
+		value.(*config.MessageQueue).Close()
+		p.producers.Delete(key)
+		return true
+	})
+}
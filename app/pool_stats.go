@@ -6,6 +6,7 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
 	"gorm.io/gorm"
 )
 
@@ -51,6 +52,8 @@ type PoolStats struct {
 	DBResolverStats *DBInstanceStats `json:"db_resolver_stats,omitempty"`
 	// 多 Redis 连接池统计（按别名索引）
 	RedisListStats map[string]*RedisInstanceStats `json:"redis_list_stats,omitempty"`
+	// RabbitMQ 发送者发布通道池统计（按 queueInfo 索引）
+	RabbitMQProducerStats map[string]config.ChannelPoolStats `json:"rabbitmq_producer_stats,omitempty"`
 }
 
 // HealthStatus 健康状态
@@ -103,6 +106,7 @@ func collectRedisStats(client redis.UniversalClient) *RedisInstanceStats {
 // 3. 多数据库列表（app.DBList）
 // 4. 主 Redis（app.Redis）
 // 5. 多 Redis 列表（app.RedisList）
+// 6. RabbitMQ 发送者发布通道池（app.RabbitMQProducerList）
 func GetPoolStats() *PoolStats {
 	stats := &PoolStats{}
 
@@ -150,6 +154,15 @@ func GetPoolStats() *PoolStats {
 		stats.RedisIdleConns = rs.IdleConns
 	}
 
+	// 6. RabbitMQ 发送者发布通道池统计
+	RabbitMQProducerList.Range(func(key, value any) bool {
+		if stats.RabbitMQProducerStats == nil {
+			stats.RabbitMQProducerStats = make(map[string]config.ChannelPoolStats)
+		}
+		stats.RabbitMQProducerStats[key.(string)] = value.(*config.MessageQueue).PublisherPoolStats()
+		return true
+	})
+
 	return stats
 }
 
@@ -216,6 +229,7 @@ func checkRedisHealth(client redis.UniversalClient) HealthStatus {
 // 5. 多 Redis 列表（redis:<aliasName>）
 // 6. Elasticsearch
 // 7. Etcd
+// 8. 降级启动的服务（MarkUnavailable），按服务名称记录
 func CheckPoolHealth() map[string]HealthStatus {
 	result := make(map[string]HealthStatus)
 
@@ -250,17 +264,21 @@ func CheckPoolHealth() map[string]HealthStatus {
 		result["redis"] = checkRedisHealth(Redis)
 	}
 
-	// 6. 检查 Elasticsearch
-	if BaseConfig.System.UseEs && ES != nil {
-		status := HealthStatus{Healthy: true}
+	// 6. 检查 Elasticsearch，默认集群记为 elasticsearch，其他集群按别名记为 elasticsearch:别名
+	if BaseConfig.System.UseEs {
 		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 		defer cancel()
-		_, err := ES.Info().Do(ctx)
-		if err != nil {
-			status.Healthy = false
-			status.Error = err.Error()
+		for alias, err := range ESHealth(ctx) {
+			key := "elasticsearch:" + alias
+			if alias == config.DefaultEsAliasName {
+				key = "elasticsearch"
+			}
+			status := HealthStatus{Healthy: err == nil}
+			if err != nil {
+				status.Error = err.Error()
+			}
+			result[key] = status
 		}
-		result["elasticsearch"] = status
 	}
 
 	// 7. 检查 Etcd
@@ -276,6 +294,15 @@ func CheckPoolHealth() map[string]HealthStatus {
 		result["etcd"] = status
 	}
 
+	// 8. 降级启动的服务
+	for name, err := range UnavailableServices() {
+		status := HealthStatus{Healthy: false, Error: "降级启动，服务不可用"}
+		if err != nil {
+			status.Error += ": " + err.Error()
+		}
+		result[name] = status
+	}
+
 	return result
 }
 
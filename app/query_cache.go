@@ -0,0 +1,736 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zzsen/gin_core/logger"
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// QueryCacheSkipKey 跳过查询缓存的会话设置键，db.Set(QueryCacheSkipKey, true) 后的查询直接访问数据库
+	QueryCacheSkipKey = "cache:skip"
+
+	// queryCachePluginName 插件名称
+	queryCachePluginName = "gin_core:query_cache"
+	// queryCacheKeyPrefix 查询缓存的 Redis 键前缀，完整键为 前缀 + 数据库名称 + ":" + 表名 + ":" + 哈希值
+	queryCacheKeyPrefix = "query_cache:"
+)
+
+// queryCacheModel 模型的查询缓存配置
+type queryCacheModel struct {
+	ttl  time.Duration // 缓存过期时间
+	keys []string      // 额外关联的表名，这些表的写操作同样失效该模型的缓存
+}
+
+// queryCacheRegistry 已开启查询缓存的模型，按模型类型索引
+var queryCacheRegistry = struct {
+	sync.RWMutex
+	models map[reflect.Type]queryCacheModel
+	keys   map[string]int // 各模型 keys 中的表名及引用次数
+}{
+	models: make(map[reflect.Type]queryCacheModel),
+	keys:   make(map[string]int),
+}
+
+// EnableQueryCache 为模型开启查询缓存
+// 需要在框架初始化数据库之前调用：初始化数据库时只有已开启查询缓存的模型且 Redis 已初始化，才会为数据库实例添加查询缓存插件。
+// 开启后，该模型的查询（Find、First、Count 等）结果按模型字段序列化后缓存在 app.Redis 中，
+// 缓存键由数据库名称、表名、规范化的 SQL 和参数的哈希值组成；该表（及 keys 中的表）的创建、更新、删除会失效该表的全部缓存。
+// 以下查询不使用缓存：事务中的查询、带锁的查询（FOR UPDATE 等）、设置了 QueryCacheSkipKey 的会话、Redis 未初始化时
+//
+// 参数：
+//   - model: 模型，如 &Region{}
+//   - ttl: 缓存过期时间，<= 0 时关闭该模型的查询缓存
+//   - keys: 额外关联的表名，查询中 JOIN 了其他表时传入这些表名，它们的写操作同样失效该模型的缓存
+//
+// 使用示例：
+//
+//	app.EnableQueryCache(&Region{}, 10*time.Minute)
+//	app.EnableQueryCache(&RegionView{}, time.Minute, "region", "country")
+func EnableQueryCache(model any, ttl time.Duration, keys ...string) {
+	modelType := queryCacheModelType(model)
+	if modelType == nil {
+		logger.Warn("[查询缓存] 模型必须为结构体或结构体指针, model: %T", model)
+		return
+	}
+
+	queryCacheRegistry.Lock()
+	defer queryCacheRegistry.Unlock()
+	if previous, ok := queryCacheRegistry.models[modelType]; ok {
+		for _, key := range previous.keys {
+			if queryCacheRegistry.keys[key]--; queryCacheRegistry.keys[key] <= 0 {
+				delete(queryCacheRegistry.keys, key)
+			}
+		}
+		delete(queryCacheRegistry.models, modelType)
+	}
+	if ttl <= 0 {
+		return
+	}
+	queryCacheRegistry.models[modelType] = queryCacheModel{ttl: ttl, keys: append([]string(nil), keys...)}
+	for _, key := range keys {
+		queryCacheRegistry.keys[key]++
+	}
+}
+
+// QueryCacheEnabled 判断是否有模型通过 EnableQueryCache 开启了查询缓存
+// 框架初始化数据库时据此决定是否添加查询缓存插件，没有模型开启查询缓存时不包装查询回调和连接池
+func QueryCacheEnabled() bool {
+	queryCacheRegistry.RLock()
+	defer queryCacheRegistry.RUnlock()
+	return len(queryCacheRegistry.models) > 0
+}
+
+// queryCacheModelType 获取模型的结构体类型，不是结构体时返回 nil
+func queryCacheModelType(model any) reflect.Type {
+	t := reflect.TypeOf(model)
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// queryCacheResult 缓存的查询结果
+type queryCacheResult struct {
+	Rows int64           `json:"rows"`
+	Data json.RawMessage `json:"data"`
+}
+
+// queryCachePlugin 查询缓存的 GORM 插件，包装 gorm:query 回调，并在创建、更新、删除后失效缓存
+// 每个数据库实例使用独立的插件，缓存键包含数据库名称，不同数据库的同名表互不影响
+type queryCachePlugin struct {
+	name   string             // 数据库名称
+	group  singleflight.Group // 合并同一缓存键的并发查询
+	tables sync.Map           // 已开启查询缓存的模型对应的表名，用于识别不带模型的写操作（如 db.Table("region").Updates）
+}
+
+// NewQueryCachePlugin 创建查询缓存插件，框架初始化数据库时为每个数据库实例添加
+// 参数：
+//   - name: 数据库名称，作为缓存键的一部分
+//
+// 返回：
+//   - gorm.Plugin: 查询缓存插件
+func NewQueryCachePlugin(name string) gorm.Plugin {
+	return &queryCachePlugin{name: name}
+}
+
+// Name 返回插件名称
+// 实现 gorm.Plugin 接口
+func (p *queryCachePlugin) Name() string {
+	return queryCachePluginName
+}
+
+// Initialize 初始化插件，包装已注册的 gorm:query 回调，在创建、更新、删除后注册失效缓存的回调，
+// 并包装数据库连接池，事务提交后再次失效事务中写过的表
+// 实现 gorm.Plugin 接口
+func (p *queryCachePlugin) Initialize(db *gorm.DB) error {
+	query := db.Callback().Query().Get("gorm:query")
+	if query == nil {
+		return errors.New("gorm:query 回调未注册")
+	}
+	if err := db.Callback().Query().Replace("gorm:query", p.queryCallback(query)); err != nil {
+		return fmt.Errorf("包装 gorm:query 回调失败: %w", err)
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("query_cache:after_create", p.invalidate); err != nil {
+		return fmt.Errorf("注册 query_cache:after_create 回调失败: %w", err)
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("query_cache:after_update", p.invalidate); err != nil {
+		return fmt.Errorf("注册 query_cache:after_update 回调失败: %w", err)
+	}
+	if err := db.Callback().Delete().After("gorm:delete").Register("query_cache:after_delete", p.invalidate); err != nil {
+		return fmt.Errorf("注册 query_cache:after_delete 回调失败: %w", err)
+	}
+	switch db.ConnPool.(type) {
+	case gorm.TxBeginner, gorm.ConnPoolBeginner:
+		db.ConnPool = &queryCacheConnPool{ConnPool: db.ConnPool, plugin: p}
+		db.Statement.ConnPool = db.ConnPool
+	}
+	return nil
+}
+
+// queryCallback 返回包装 gorm:query 的回调
+// 可缓存的查询先读取缓存，命中时将结果写入 Dest 并跳过数据库查询；
+// 未命中时通过 singleflight 合并同一缓存键的并发查询，执行查询后回写缓存。Redis 不可用时降级为直接查询
+func (p *queryCachePlugin) queryCallback(query func(*gorm.DB)) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ttl, tags, ok := p.cacheable(db)
+		if !ok {
+			query(db)
+			return
+		}
+		callbacks.BuildQuerySQL(db)
+		if db.Error != nil {
+			return
+		}
+		key, err := p.cacheKey(db.Statement.Table, db.Statement.Dest, db.Statement.SQL.String(), db.Statement.Vars)
+		if err != nil {
+			query(db)
+			return
+		}
+
+		ctx := db.Statement.Context
+		data, err := Redis.Get(ctx, key).Bytes()
+		switch {
+		case err == nil:
+			if loadErr := loadQueryCache(db, data); loadErr == nil {
+				return
+			}
+			// 缓存数据已损坏，重新查询并覆盖
+		case !errors.Is(err, redis.Nil):
+			logger.Warn("[查询缓存] Redis 不可用, 降级为直接查询, table: %s, error: %v", db.Statement.Table, err)
+			query(db)
+			return
+		}
+
+		// 查询前读取各标签的失效版本号，查询期间发生失效时不写入缓存；
+		// 版本号参与合并的键，失效后发起的查询不会复用失效前开始的查询结果
+		gens, err := p.generations(ctx, tags)
+		if err != nil {
+			logger.Warn("[查询缓存] Redis 不可用, 降级为直接查询, table: %s, error: %v", db.Statement.Table, err)
+			query(db)
+			return
+		}
+
+		executed := false
+		shared, err, _ := p.group.Do(key+":"+strings.Join(gens, ","), func() (any, error) {
+			executed = true
+			query(db)
+			if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+				return nil, db.Error
+			}
+			data, err := encodeQueryCache(db)
+			if err != nil {
+				logger.Warn("[查询缓存] 序列化查询结果失败, table: %s, error: %v", db.Statement.Table, err)
+				return nil, err
+			}
+			p.store(ctx, key, data, ttl, tags, gens)
+			return data, nil
+		})
+		if executed {
+			return
+		}
+		// 合并的查询失败（如发起查询的请求已取消）时自行查询
+		if err != nil || loadQueryCache(db, shared.([]byte)) != nil {
+			query(db)
+		}
+	}
+}
+
+// cacheable 判断查询是否使用缓存，返回缓存过期时间和失效标签（表名及模型的 keys）
+func (p *queryCachePlugin) cacheable(db *gorm.DB) (time.Duration, []string, bool) {
+	stmt := db.Statement
+	if stmt.Schema == nil || stmt.Dest == nil || db.DryRun || Redis == nil {
+		return 0, nil, false
+	}
+	queryCacheRegistry.RLock()
+	model, ok := queryCacheRegistry.models[stmt.Schema.ModelType]
+	queryCacheRegistry.RUnlock()
+	if !ok {
+		return 0, nil, false
+	}
+	p.tables.Store(stmt.Table, struct{}{})
+
+	if skip, ok := db.Get(QueryCacheSkipKey); ok {
+		if skip, _ := skip.(bool); skip {
+			return 0, nil, false
+		}
+	}
+	// 事务中的查询可能读到未提交的数据，不读取也不写入缓存
+	if _, ok := stmt.ConnPool.(gorm.TxCommitter); ok {
+		return 0, nil, false
+	}
+	if _, ok := stmt.Clauses["FOR"]; ok {
+		return 0, nil, false
+	}
+	return model.ttl, append([]string{stmt.Table}, model.keys...), true
+}
+
+// invalidate 写操作成功后失效该表的全部缓存
+// 事务中的写操作同样立即失效缓存，并记录到事务中，事务提交后再次失效，
+// 清除事务提交前事务外的查询写入的旧数据；跳过查询缓存的会话不影响失效
+func (p *queryCachePlugin) invalidate(db *gorm.DB) {
+	if db.Error != nil || db.DryRun || Redis == nil || !p.watched(db) {
+		return
+	}
+	table := db.Statement.Table
+	if tx := queryCacheTxOf(db.Statement.ConnPool); tx != nil {
+		tx.markDirty(table)
+	}
+	if err := p.invalidateTable(db.Statement.Context, table); err != nil {
+		logger.Warn("[查询缓存] 失效缓存失败, table: %s, error: %v", table, err)
+	}
+}
+
+// watched 判断写操作的表是否需要失效缓存：模型已开启查询缓存，或表名在某个模型的 keys 中
+func (p *queryCachePlugin) watched(db *gorm.DB) bool {
+	table := db.Statement.Table
+	queryCacheRegistry.RLock()
+	_, isKey := queryCacheRegistry.keys[table]
+	isModel := false
+	if db.Statement.Schema != nil {
+		_, isModel = queryCacheRegistry.models[db.Statement.Schema.ModelType]
+	}
+	queryCacheRegistry.RUnlock()
+	if isModel {
+		p.tables.Store(table, struct{}{})
+		return true
+	}
+	if isKey {
+		return true
+	}
+	_, ok := p.tables.Load(table)
+	return ok
+}
+
+// queryCacheTagScript 将缓存键加入标签集合，并保证标签集合的过期时间不短于成员的过期时间
+// KEYS[1]: 标签集合；ARGV[1]: 缓存键；ARGV[2]: 缓存键的过期时间（毫秒）
+var queryCacheTagScript = redis.NewScript(`
+local pttl = redis.call('PTTL', KEYS[1])
+redis.call('SADD', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if pttl == -2 or (pttl >= 0 and pttl < ttl) then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// store 先将缓存键登记到各标签集合，再写入缓存；登记失败时不写入缓存
+// 写入后重新读取各标签的失效版本号，与查询前读取的 gens 不一致（查询期间发生了失效）时删除刚写入的缓存。
+// 失效先递增版本号再删除标签集合中的缓存键：写入发生在递增之后时由版本号检查删除，发生在递增之前时已登记到标签集合，由失效删除
+func (p *queryCachePlugin) store(ctx context.Context, key string, data []byte, ttl time.Duration, tags []string, gens []string) {
+	ttlMs := max(ttl.Milliseconds(), 1)
+	for _, tag := range tags {
+		if err := queryCacheTagScript.Run(ctx, Redis, []string{p.tagKey(tag)}, key, ttlMs).Err(); err != nil {
+			logger.Warn("[查询缓存] 登记缓存键失败, table: %s, error: %v", tag, err)
+			return
+		}
+	}
+	if err := Redis.Set(ctx, key, data, ttl).Err(); err != nil {
+		logger.Warn("[查询缓存] 写入缓存失败, key: %s, error: %v", key, err)
+		return
+	}
+	if current, err := p.generations(ctx, tags); err == nil && slices.Equal(current, gens) {
+		return
+	}
+	if err := Redis.Del(ctx, key).Err(); err != nil {
+		logger.Warn("[查询缓存] 删除过期的缓存失败, key: %s, error: %v", key, err)
+	}
+}
+
+// generations 读取各标签的失效版本号，版本号不存在时为空字符串
+// 逐个键执行 GET，集群模式下不会因键分布在不同槽位而失败
+func (p *queryCachePlugin) generations(ctx context.Context, tags []string) ([]string, error) {
+	pipe := Redis.Pipeline()
+	cmds := make([]*redis.StringCmd, len(tags))
+	for i, tag := range tags {
+		cmds[i] = pipe.Get(ctx, p.genKey(tag))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	gens := make([]string, len(tags))
+	for i, cmd := range cmds {
+		gens[i] = cmd.Val()
+	}
+	return gens, nil
+}
+
+// invalidateTable 递增表的失效版本号，删除表的标签集合中的全部缓存键，最后删除标签集合
+// 逐个键执行 DEL，集群模式下不会因键分布在不同槽位而失败
+func (p *queryCachePlugin) invalidateTable(ctx context.Context, table string) error {
+	if err := Redis.Incr(ctx, p.genKey(table)).Err(); err != nil {
+		return err
+	}
+	tagKey := p.tagKey(table)
+	members, err := Redis.SMembers(ctx, tagKey).Result()
+	if err != nil {
+		return err
+	}
+	pipe := Redis.Pipeline()
+	for _, member := range members {
+		pipe.Del(ctx, member)
+	}
+	pipe.Del(ctx, tagKey)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// tagKey 拼接表的标签集合的键
+func (p *queryCachePlugin) tagKey(table string) string {
+	return queryCacheKeyPrefix + p.name + ":tag:" + table
+}
+
+// genKey 拼接表的失效版本号的键，每次失效该表的缓存时递增
+func (p *queryCachePlugin) genKey(table string) string {
+	return queryCacheKeyPrefix + p.name + ":gen:" + table
+}
+
+// queryCacheConnPool 包装数据库连接池，开启的事务为 queryCacheTx，用于在事务提交后失效事务中写过的表
+type queryCacheConnPool struct {
+	gorm.ConnPool
+	plugin *queryCachePlugin
+}
+
+// BeginTx 通过被包装的连接池开启事务
+// 实现 gorm.ConnPoolBeginner 接口
+func (c *queryCacheConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	var (
+		tx  gorm.ConnPool
+		err error
+	)
+	switch beginner := c.ConnPool.(type) {
+	case gorm.TxBeginner:
+		var sqlTx *sql.Tx
+		if sqlTx, err = beginner.BeginTx(ctx, opts); err == nil {
+			tx = sqlTx
+		}
+	case gorm.ConnPoolBeginner:
+		tx, err = beginner.BeginTx(ctx, opts)
+	default:
+		err = gorm.ErrInvalidTransaction
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &queryCacheTx{ConnPool: tx, pool: c, ctx: context.WithoutCancel(ctx)}, nil
+}
+
+// GetDBConn 返回被包装的连接池的 *sql.DB，保证 db.DB() 正常工作
+// 实现 gorm.GetDBConnector 接口
+func (c *queryCacheConnPool) GetDBConn() (*sql.DB, error) {
+	switch pool := c.ConnPool.(type) {
+	case *sql.DB:
+		return pool, nil
+	case gorm.GetDBConnector:
+		return pool.GetDBConn()
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+// queryCacheTx 包装事务，记录事务中写过的开启了查询缓存的表，提交成功后再次失效这些表的缓存
+type queryCacheTx struct {
+	gorm.ConnPool
+	pool  *queryCacheConnPool
+	ctx   context.Context // 开启事务时的上下文（不随其取消），用于提交后失效缓存
+	mu    sync.Mutex
+	dirty map[string]struct{}
+}
+
+// queryCacheTxOf 获取会话所在的查询缓存事务，不在事务中或事务不是由查询缓存插件开启时返回 nil
+func queryCacheTxOf(pool gorm.ConnPool) *queryCacheTx {
+	if prepared, ok := pool.(*gorm.PreparedStmtTX); ok {
+		pool = prepared.Tx
+	}
+	tx, _ := pool.(*queryCacheTx)
+	return tx
+}
+
+// markDirty 记录事务中写过的表
+func (t *queryCacheTx) markDirty(table string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.dirty == nil {
+		t.dirty = make(map[string]struct{})
+	}
+	t.dirty[table] = struct{}{}
+}
+
+// takeDirty 取出并清空事务中写过的表
+func (t *queryCacheTx) takeDirty() map[string]struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	dirty := t.dirty
+	t.dirty = nil
+	return dirty
+}
+
+// Commit 提交事务，成功后失效事务中写过的表的缓存
+// 实现 gorm.TxCommitter 接口
+func (t *queryCacheTx) Commit() error {
+	committer, ok := t.ConnPool.(gorm.TxCommitter)
+	if !ok {
+		return gorm.ErrInvalidTransaction
+	}
+	if err := committer.Commit(); err != nil {
+		return err
+	}
+	if Redis == nil {
+		return nil
+	}
+	for table := range t.takeDirty() {
+		if err := t.pool.plugin.invalidateTable(t.ctx, table); err != nil {
+			logger.Warn("[查询缓存] 事务提交后失效缓存失败, table: %s, error: %v", table, err)
+		}
+	}
+	return nil
+}
+
+// Rollback 回滚事务，丢弃事务中写过的表
+// 实现 gorm.TxCommitter 接口
+func (t *queryCacheTx) Rollback() error {
+	committer, ok := t.ConnPool.(gorm.TxCommitter)
+	if !ok {
+		return gorm.ErrInvalidTransaction
+	}
+	t.takeDirty()
+	return committer.Rollback()
+}
+
+// StmtContext 返回事务专用的预编译语句，开启 PrepareStmt 时使用
+// 实现 gorm.Tx 接口
+func (t *queryCacheTx) StmtContext(ctx context.Context, stmt *sql.Stmt) *sql.Stmt {
+	if tx, ok := t.ConnPool.(interface {
+		StmtContext(context.Context, *sql.Stmt) *sql.Stmt
+	}); ok {
+		return tx.StmtContext(ctx, stmt)
+	}
+	return stmt
+}
+
+// GetDBConn 返回连接池的 *sql.DB，保证事务中 tx.DB() 正常工作
+// 实现 gorm.GetDBConnector 接口
+func (t *queryCacheTx) GetDBConn() (*sql.DB, error) {
+	return t.pool.GetDBConn()
+}
+
+// cacheKey 拼接查询的缓存键，SQL 经过规范化（去除前置注释、合并空白），参数按类型和值参与哈希；
+// Dest 的类型同样参与哈希，相同 SQL 扫描到不同结构体（如 DTO）时使用各自的缓存
+func (p *queryCachePlugin) cacheKey(table string, dest any, sql string, vars []any) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%T\x00%s", dest, normalizeQuerySQL(sql))
+	for _, v := range vars {
+		if valuer, ok := v.(driver.Valuer); ok {
+			value, err := valuer.Value()
+			if err != nil {
+				return "", err
+			}
+			v = value
+		}
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				v = nil
+			} else {
+				v = rv.Elem().Interface()
+			}
+		}
+		fmt.Fprintf(h, "\x00%T:%#v", v, v)
+	}
+	return queryCacheKeyPrefix + p.name + ":" + table + ":" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// normalizeQuerySQL 规范化 SQL：去除前置的 /* */ 注释（如 db.traceComment 附加的 traceId），
+// 将引号外的连续空白合并为一个空格，引号内的内容保持不变
+func normalizeQuerySQL(sql string) string {
+	sql = strings.TrimSpace(sql)
+	for strings.HasPrefix(sql, "/*") {
+		end := strings.Index(sql, "*/")
+		if end < 0 {
+			break
+		}
+		sql = strings.TrimSpace(sql[end+2:])
+	}
+
+	var b strings.Builder
+	b.Grow(len(sql))
+	var quote byte
+	space := false
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		if quote != 0 {
+			b.WriteByte(ch)
+			if ch == '\\' && i+1 < len(sql) {
+				i++
+				b.WriteByte(sql[i])
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case ' ', '\t', '\n', '\r':
+			space = true
+			continue
+		case '\'', '"', '`':
+			quote = ch
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+// queryCacheSchemas 查询结果结构体的 GORM 模型信息缓存，用于按模型字段序列化
+var queryCacheSchemas sync.Map
+
+// encodeQueryCache 序列化查询结果（影响行数和 Dest）
+func encodeQueryCache(db *gorm.DB) ([]byte, error) {
+	data, err := encodeQueryCacheDest(db)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(queryCacheResult{Rows: db.RowsAffected, Data: data})
+}
+
+// loadQueryCache 将缓存的查询结果写入 Dest，影响行数为 0 且查询要求数据存在（First、Take、Last）时返回 gorm.ErrRecordNotFound
+func loadQueryCache(db *gorm.DB, data []byte) error {
+	var result queryCacheResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	if err := decodeQueryCacheDest(db, result.Data); err != nil {
+		return err
+	}
+	db.RowsAffected = result.Rows
+	if db.Statement.Result != nil {
+		db.Statement.Result.RowsAffected = result.Rows
+	}
+	if result.Rows == 0 && db.Statement.RaiseErrorOnNotFound {
+		db.AddError(gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// queryCacheDestSchema 获取 Dest 的结构体的 GORM 模型信息
+// Dest 为结构体指针、结构体切片指针（元素可以为指针）时返回 Dest 指向的值和模型信息；
+// 其他类型（Count 的 *int64、Pluck 的切片、map 等）返回的模型信息为 nil
+func queryCacheDestSchema(db *gorm.DB) (reflect.Value, *schema.Schema, error) {
+	rv := reflect.ValueOf(db.Statement.Dest)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return rv, nil, nil
+	}
+	rv = rv.Elem()
+	t := rv.Type()
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return rv, nil, nil
+	}
+	s, err := schema.Parse(reflect.New(t).Interface(), &queryCacheSchemas, db.NamingStrategy)
+	return rv, s, err
+}
+
+// encodeQueryCacheDest 序列化 Dest
+// 结构体按 GORM 模型字段序列化为 列名 → 字段值 的 JSON 对象，json 标签（如 json:"-"）不影响缓存的内容；
+// 关联字段不缓存，命中缓存后由 Preload 重新查询。其他类型使用 encoding/json 序列化
+func encodeQueryCacheDest(db *gorm.DB) ([]byte, error) {
+	rv, s, err := queryCacheDestSchema(db)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return json.Marshal(db.Statement.Dest)
+	}
+	ctx := db.Statement.Context
+	if rv.Kind() == reflect.Struct {
+		return json.Marshal(encodeQueryCacheRow(ctx, s, rv))
+	}
+	rows := make([]map[string]any, rv.Len())
+	for i := range rows {
+		if elem := reflect.Indirect(rv.Index(i)); elem.IsValid() {
+			rows[i] = encodeQueryCacheRow(ctx, s, elem)
+		}
+	}
+	return json.Marshal(rows)
+}
+
+// encodeQueryCacheRow 将结构体的模型字段转换为 列名 → 字段值
+func encodeQueryCacheRow(ctx context.Context, s *schema.Schema, rv reflect.Value) map[string]any {
+	row := make(map[string]any, len(s.DBNames))
+	for _, name := range s.DBNames {
+		field := s.FieldsByDBName[name]
+		if !field.Readable {
+			continue
+		}
+		value, _ := field.ValueOf(ctx, rv)
+		row[name] = value
+	}
+	return row
+}
+
+// decodeQueryCacheDest 将 encodeQueryCacheDest 序列化的数据写入 Dest
+func decodeQueryCacheDest(db *gorm.DB, data []byte) error {
+	rv, s, err := queryCacheDestSchema(db)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return json.Unmarshal(data, db.Statement.Dest)
+	}
+	ctx := db.Statement.Context
+	if rv.Kind() == reflect.Struct {
+		var row map[string]json.RawMessage
+		if err := json.Unmarshal(data, &row); err != nil {
+			return err
+		}
+		return decodeQueryCacheRow(ctx, s, rv, row)
+	}
+
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return err
+	}
+	elemType := rv.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Pointer
+	slice := reflect.MakeSlice(rv.Type(), len(rows), len(rows))
+	for i, row := range rows {
+		if row == nil {
+			continue
+		}
+		elem := reflect.New(s.ModelType)
+		if err := decodeQueryCacheRow(ctx, s, elem.Elem(), row); err != nil {
+			return err
+		}
+		if isPtr {
+			slice.Index(i).Set(elem)
+		} else {
+			slice.Index(i).Set(elem.Elem())
+		}
+	}
+	rv.Set(slice)
+	return nil
+}
+
+// decodeQueryCacheRow 将 列名 → 字段值 写入结构体的模型字段
+func decodeQueryCacheRow(ctx context.Context, s *schema.Schema, rv reflect.Value, row map[string]json.RawMessage) error {
+	for name, raw := range row {
+		field := s.FieldsByDBName[name]
+		if field == nil {
+			continue
+		}
+		value := reflect.New(field.FieldType)
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return err
+		}
+		if err := field.Set(ctx, rv, value.Elem().Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,433 @@
+// Package app 查询缓存测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含查询缓存插件的单元测试，使用 SQLite 内存数据库和 miniredis，不需要 MySQL 和真实的 Redis。
+// 通过包装 gorm:query 的计数回调统计实际执行的数据库查询次数。
+//
+// 测试覆盖内容：
+// 1. 开启查询缓存的模型第二次查询命中缓存，未开启的模型不缓存
+// 2. 创建、更新、删除（包括 keys 中关联的表）后失效该表的缓存
+// 3. 事务中的查询不读取也不写入缓存
+// 4. db.Set("cache:skip", true) 跳过缓存
+// 5. 空白不同的相同查询使用同一个缓存键，引号内的内容和前置注释的处理
+// 6. 事务中更新后、提交前事务外的查询写入的旧数据在事务提交后失效
+// 7. 查询期间发生失效时不写入查询结果
+// 8. 查询结果按模型字段缓存，json:"-" 的字段命中缓存后保持原值
+//
+// 运行测试：go test -v ./app/... -run QueryCache
+// ==================================================
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// queryCacheRegion 开启查询缓存的测试模型
+type queryCacheRegion struct {
+	ID   uint
+	Name string
+	Code string `json:"-"`
+}
+
+// queryCacheCountry 通过 keys 关联到 queryCacheRegion 的测试模型，本身不开启查询缓存
+type queryCacheCountry struct {
+	ID   uint
+	Name string
+}
+
+// setupQueryCacheTest 打开 SQLite 内存数据库并建表，启动 miniredis 并设置为主 Redis，添加查询缓存插件
+// 返回数据库实例和统计实际数据库查询次数的计数器
+func setupQueryCacheTest(t *testing.T) (*gorm.DB, *int) {
+	return setupQueryCacheTestWithDSN(t, "file::memory:", 1)
+}
+
+// setupQueryCacheTestWithDSN 与 setupQueryCacheTest 相同，使用指定的 SQLite DSN 和最大连接数
+func setupQueryCacheTestWithDSN(t *testing.T, dsn string, maxOpenConns int) (*gorm.DB, *int) {
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取 sqlDB 失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(maxOpenConns)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.AutoMigrate(&queryCacheRegion{}, &queryCacheCountry{}); err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+
+	// 计数回调在插件之前包装 gorm:query，只统计实际执行的查询
+	queries := 0
+	if err := db.Callback().Query().Replace("gorm:query", func(db *gorm.DB) {
+		queries++
+		callbacks.Query(db)
+	}); err != nil {
+		t.Fatalf("注册计数回调失败: %v", err)
+	}
+	if err := db.Use(NewQueryCachePlugin("test")); err != nil {
+		t.Fatalf("添加查询缓存插件失败: %v", err)
+	}
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	original := Redis
+	Redis = client
+	t.Cleanup(func() {
+		Redis = original
+		_ = client.Close()
+		EnableQueryCache(&queryCacheRegion{}, 0)
+	})
+
+	EnableQueryCache(&queryCacheRegion{}, time.Minute, "query_cache_countries")
+	if err := db.Create(&[]queryCacheRegion{{Name: "华东"}, {Name: "华南"}}).Error; err != nil {
+		t.Fatalf("写入测试数据失败: %v", err)
+	}
+	return db, &queries
+}
+
+// findRegionNames 查询全部地区名称
+func findRegionNames(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var regions []queryCacheRegion
+	if err := db.Order("id").Find(&regions).Error; err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	names := make([]string, 0, len(regions))
+	for _, region := range regions {
+		names = append(names, region.Name)
+	}
+	return names
+}
+
+// TestQueryCache_Hit 测试查询命中缓存
+//
+// 【功能点】验证开启查询缓存的模型第二次查询不访问数据库，结果与第一次相同；First 未找到时同样缓存并返回 ErrRecordNotFound；未开启的模型每次都查询
+// 【测试流程】
+//  1. 连续两次查询全部地区，断言只执行一次查询、两次结果相同
+//  2. 连续两次 First 查询不存在的地区，断言只执行一次查询、两次均返回 gorm.ErrRecordNotFound
+//  3. 连续两次查询未开启缓存的 queryCacheCountry，断言执行两次查询
+func TestQueryCache_Hit(t *testing.T) {
+	db, queries := setupQueryCacheTest(t)
+
+	first := findRegionNames(t, db)
+	second := findRegionNames(t, db)
+	if *queries != 1 {
+		t.Errorf("期望执行 1 次查询, 实际 %d 次", *queries)
+	}
+	if len(second) != 2 || second[0] != first[0] || second[1] != first[1] {
+		t.Errorf("缓存结果不一致: %v, %v", first, second)
+	}
+
+	for i := 0; i < 2; i++ {
+		var region queryCacheRegion
+		if err := db.Where("name = ?", "西北").First(&region).Error; err != gorm.ErrRecordNotFound {
+			t.Errorf("第 %d 次查询期望 ErrRecordNotFound, 实际 %v", i+1, err)
+		}
+	}
+	if *queries != 2 {
+		t.Errorf("期望执行 2 次查询, 实际 %d 次", *queries)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := db.Find(&[]queryCacheCountry{}).Error; err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+	}
+	if *queries != 4 {
+		t.Errorf("未开启缓存的模型期望共执行 4 次查询, 实际 %d 次", *queries)
+	}
+}
+
+// TestQueryCache_InvalidateOnWrite 测试写操作后失效缓存
+//
+// 【功能点】验证更新、创建、删除该表，以及写入 keys 中关联的表后，下一次查询重新访问数据库并返回最新数据
+// 【测试流程】
+//  1. 查询一次写入缓存，更新一条数据后再次查询，断言执行了查询且返回更新后的名称
+//  2. 依次执行创建、删除、写入 queryCacheCountry，每次写入后查询两次，断言各执行一次查询
+func TestQueryCache_InvalidateOnWrite(t *testing.T) {
+	db, queries := setupQueryCacheTest(t)
+
+	findRegionNames(t, db)
+	if err := db.Model(&queryCacheRegion{ID: 1}).Update("name", "华北").Error; err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	names := findRegionNames(t, db)
+	if *queries != 2 {
+		t.Errorf("更新后期望重新查询, 实际共执行 %d 次查询", *queries)
+	}
+	if names[0] != "华北" {
+		t.Errorf("期望返回更新后的名称 华北, 实际 %v", names)
+	}
+
+	writes := map[string]func() error{
+		"创建": func() error { return db.Create(&queryCacheRegion{Name: "西南"}).Error },
+		"删除": func() error { return db.Delete(&queryCacheRegion{}, 1).Error },
+		"关联表": func() error {
+			return db.Table("query_cache_countries").Create(map[string]any{"name": "中国"}).Error
+		},
+	}
+	for _, name := range []string{"创建", "删除", "关联表"} {
+		before := *queries
+		if err := writes[name](); err != nil {
+			t.Fatalf("%s失败: %v", name, err)
+		}
+		findRegionNames(t, db)
+		findRegionNames(t, db)
+		if got := *queries - before; got != 1 {
+			t.Errorf("%s后期望执行 1 次查询, 实际 %d 次", name, got)
+		}
+	}
+}
+
+// TestQueryCache_Transaction 测试事务中的查询不使用缓存
+//
+// 【功能点】验证事务中的查询不读取已有的缓存，也不写入缓存，事务中可以读到未提交的更新
+// 【测试流程】
+//  1. 在事务外查询一次写入缓存
+//  2. 在事务中更新数据后查询两次，断言每次都访问数据库并读到更新后的名称
+//  3. 回滚事务后在事务外查询，断言回滚后的数据正确
+func TestQueryCache_Transaction(t *testing.T) {
+	db, queries := setupQueryCacheTest(t)
+	findRegionNames(t, db)
+
+	tx := db.Begin()
+	if err := tx.Model(&queryCacheRegion{ID: 1}).Update("name", "华北").Error; err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if names := findRegionNames(t, tx); names[0] != "华北" {
+			t.Errorf("事务中期望读到未提交的更新 华北, 实际 %v", names)
+		}
+	}
+	if *queries != 3 {
+		t.Errorf("事务中的查询期望每次都访问数据库, 实际共执行 %d 次查询", *queries)
+	}
+	tx.Rollback()
+
+	if names := findRegionNames(t, db); names[0] != "华东" {
+		t.Errorf("回滚后期望读到 华东, 实际 %v", names)
+	}
+}
+
+// TestQueryCache_TransactionCommit 测试事务提交后失效缓存
+//
+// 【功能点】验证事务中更新后、提交前事务外的查询读到并缓存的旧数据，在事务提交后失效，提交后的查询读到新数据
+// 【测试流程】
+//  1. 使用 WAL 模式的 SQLite 文件数据库，事务外的查询不会被未提交的事务阻塞
+//  2. 开启事务并更新一条数据，在事务外查询两次，断言读到旧名称 华东 且第二次命中缓存
+//  3. 提交事务后在事务外查询，断言读到更新后的名称 华北
+//  4. 再开启事务更新并回滚，断言回滚后查询仍命中缓存
+func TestQueryCache_TransactionCommit(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "query_cache.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	db, queries := setupQueryCacheTestWithDSN(t, dsn, 2)
+
+	tx := db.Begin()
+	if err := tx.Model(&queryCacheRegion{ID: 1}).Update("name", "华北").Error; err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if names := findRegionNames(t, db); names[0] != "华东" {
+			t.Errorf("提交前事务外期望读到 华东, 实际 %v", names)
+		}
+	}
+	if *queries != 1 {
+		t.Errorf("提交前事务外的第二次查询期望命中缓存, 实际共执行 %d 次查询", *queries)
+	}
+	if err := tx.Commit().Error; err != nil {
+		t.Fatalf("提交失败: %v", err)
+	}
+	if names := findRegionNames(t, db); names[0] != "华北" {
+		t.Errorf("提交后期望读到 华北, 实际 %v", names)
+	}
+
+	before := *queries
+	tx = db.Begin()
+	if err := tx.Model(&queryCacheRegion{ID: 2}).Update("name", "西南").Error; err != nil {
+		t.Fatalf("更新失败: %v", err)
+	}
+	if err := tx.Rollback().Error; err != nil {
+		t.Fatalf("回滚失败: %v", err)
+	}
+	findRegionNames(t, db)
+	if names := findRegionNames(t, db); names[1] != "华南" {
+		t.Errorf("回滚后期望读到 华南, 实际 %v", names)
+	}
+	if got := *queries - before; got != 1 {
+		t.Errorf("回滚后期望执行 1 次查询, 实际 %d 次", got)
+	}
+}
+
+// TestQueryCache_FillRacingInvalidation 测试查询期间发生失效时不写入缓存
+//
+// 【功能点】验证查询前读取失效版本号后，表的缓存被失效（版本号递增），查询结果不会留在缓存中；没有失效时正常写入
+// 【测试流程】
+//  1. 读取失效版本号后失效该表，再写入查询结果，断言缓存键不存在
+//  2. 重新读取失效版本号后直接写入，断言缓存键存在
+func TestQueryCache_FillRacingInvalidation(t *testing.T) {
+	setupQueryCacheTest(t)
+	p := &queryCachePlugin{name: "test"}
+	ctx := context.Background()
+	tags := []string{"query_cache_regions"}
+	key := queryCacheKeyPrefix + "test:query_cache_regions:race"
+
+	gens, err := p.generations(ctx, tags)
+	if err != nil {
+		t.Fatalf("读取失效版本号失败: %v", err)
+	}
+	if err := p.invalidateTable(ctx, tags[0]); err != nil {
+		t.Fatalf("失效缓存失败: %v", err)
+	}
+	p.store(ctx, key, []byte(`{"rows":0,"data":[]}`), time.Minute, tags, gens)
+	if n, _ := Redis.Exists(ctx, key).Result(); n != 0 {
+		t.Error("查询期间发生失效时期望不写入缓存")
+	}
+
+	if gens, err = p.generations(ctx, tags); err != nil {
+		t.Fatalf("读取失效版本号失败: %v", err)
+	}
+	p.store(ctx, key, []byte(`{"rows":0,"data":[]}`), time.Minute, tags, gens)
+	if n, _ := Redis.Exists(ctx, key).Result(); n != 1 {
+		t.Error("没有发生失效时期望写入缓存")
+	}
+}
+
+// TestQueryCache_ModelFields 测试按模型字段缓存查询结果
+//
+// 【功能点】验证查询结果按 GORM 模型字段缓存，json:"-" 的字段命中缓存后不为零值；结构体、结构体切片、指针切片和 Count 均可命中缓存
+// 【测试流程】
+//  1. 创建带 Code（json:"-"）的地区
+//  2. 分别以 First、[]T、[]*T、Count 各查询两次，断言第二次命中缓存且 Code 和计数与数据库一致
+func TestQueryCache_ModelFields(t *testing.T) {
+	db, queries := setupQueryCacheTest(t)
+	if err := db.Create(&queryCacheRegion{Name: "西北", Code: "XB"}).Error; err != nil {
+		t.Fatalf("创建失败: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		var region queryCacheRegion
+		if err := db.Where("name = ?", "西北").First(&region).Error; err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+		if region.Code != "XB" || region.ID == 0 {
+			t.Errorf("第 %d 次 First 期望 Code=XB, 实际 %+v", i+1, region)
+		}
+
+		var regions []queryCacheRegion
+		if err := db.Where("code = ?", "XB").Find(&regions).Error; err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+		if len(regions) != 1 || regions[0].Code != "XB" {
+			t.Errorf("第 %d 次 Find 期望 Code=XB, 实际 %+v", i+1, regions)
+		}
+
+		var pointers []*queryCacheRegion
+		if err := db.Where("code = ?", "XB").Order("id").Find(&pointers).Error; err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+		if len(pointers) != 1 || pointers[0].Code != "XB" {
+			t.Errorf("第 %d 次 Find 指针切片期望 Code=XB, 实际 %v", i+1, pointers)
+		}
+
+		var count int64
+		if err := db.Model(&queryCacheRegion{}).Count(&count).Error; err != nil {
+			t.Fatalf("计数失败: %v", err)
+		}
+		if count != 3 {
+			t.Errorf("第 %d 次 Count 期望 3, 实际 %d", i+1, count)
+		}
+	}
+	if *queries != 4 {
+		t.Errorf("第二轮查询期望全部命中缓存, 实际共执行 %d 次查询", *queries)
+	}
+}
+
+// TestQueryCache_Skip 测试跳过缓存
+//
+// 【功能点】验证设置 cache:skip 的会话直接访问数据库，不影响其他会话使用缓存
+// 【测试流程】
+//  1. 查询一次写入缓存
+//  2. 使用 db.Set("cache:skip", true) 查询两次，断言均访问数据库
+//  3. 不设置跳过时再次查询，断言命中缓存
+func TestQueryCache_Skip(t *testing.T) {
+	db, queries := setupQueryCacheTest(t)
+	findRegionNames(t, db)
+
+	skip := db.Set(QueryCacheSkipKey, true)
+	findRegionNames(t, skip)
+	findRegionNames(t, skip)
+	if *queries != 3 {
+		t.Errorf("跳过缓存期望共执行 3 次查询, 实际 %d 次", *queries)
+	}
+	findRegionNames(t, db)
+	if *queries != 3 {
+		t.Errorf("未跳过缓存期望命中缓存, 实际共执行 %d 次查询", *queries)
+	}
+}
+
+// TestQueryCache_KeyNormalization 测试缓存键的规范化
+//
+// 【功能点】验证只有空白不同的相同查询使用同一个缓存键，前置注释不影响缓存键；引号内的空白、参数不同时缓存键不同
+// 【测试流程】
+//  1. 直接计算空白、换行、前置注释不同的 SQL 的缓存键，断言相同
+//  2. 断言引号内空白不同、参数值不同、参数类型不同、Dest 类型不同时缓存键不同
+//  3. 使用空白不同的 Where 条件各查询一次，断言只执行一次查询
+func TestQueryCache_KeyNormalization(t *testing.T) {
+	p := &queryCachePlugin{name: "test"}
+	dest := &[]queryCacheRegion{}
+	key := func(dest any, sql string, vars ...any) string {
+		k, err := p.cacheKey("region", dest, sql, vars)
+		if err != nil {
+			t.Fatalf("计算缓存键失败: %v", err)
+		}
+		return k
+	}
+
+	base := key(dest, "SELECT * FROM `region` WHERE name = ? ORDER BY id", "华东")
+	for _, sql := range []string{
+		"SELECT  *  FROM `region`\n\tWHERE name = ?   ORDER BY id",
+		"  SELECT * FROM `region` WHERE name = ? ORDER BY id\n",
+		"/* trace:abc */ SELECT * FROM `region` WHERE name = ? ORDER BY id",
+	} {
+		if got := key(dest, sql, "华东"); got != base {
+			t.Errorf("期望与基准缓存键相同: %q", sql)
+		}
+	}
+	if key(dest, "SELECT * FROM `region` WHERE name = 'a  b'") == key(dest, "SELECT * FROM `region` WHERE name = 'a b'") {
+		t.Error("引号内的空白不同时期望缓存键不同")
+	}
+	if key(dest, "SELECT * FROM `region` WHERE name = ? ORDER BY id", "华南") == base {
+		t.Error("参数值不同时期望缓存键不同")
+	}
+	if key(dest, "SELECT * FROM `region` WHERE id = ?", 1) == key(dest, "SELECT * FROM `region` WHERE id = ?", "1") {
+		t.Error("参数类型不同时期望缓存键不同")
+	}
+	if key(&[]queryCacheCountry{}, "SELECT * FROM `region`") == key(dest, "SELECT * FROM `region`") {
+		t.Error("Dest 类型不同时期望缓存键不同")
+	}
+
+	db, queries := setupQueryCacheTest(t)
+	var a, b []queryCacheRegion
+	if err := db.Where("name = ?", "华东").Find(&a).Error; err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if err := db.Where("name   =\n ?", "华东").Find(&b).Error; err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if *queries != 1 {
+		t.Errorf("空白不同的相同查询期望只执行 1 次查询, 实际 %d 次", *queries)
+	}
+	if len(b) != 1 || b[0].Name != "华东" {
+		t.Errorf("期望命中缓存返回 华东, 实际 %v", b)
+	}
+}
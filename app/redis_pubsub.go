@@ -0,0 +1,323 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zzsen/gin_core/logger"
+)
+
+// 订阅连接断开后的重连退避时间，从 subscribeMinBackoff 开始翻倍，最大 subscribeMaxBackoff
+var (
+	subscribeMinBackoff = 100 * time.Millisecond
+	subscribeMaxBackoff = 5 * time.Second
+)
+
+var (
+	subscriptionsMu sync.Mutex
+	subscriptions   = make(map[*redisSubscription]struct{})
+)
+
+// Subscription Redis 订阅
+type Subscription interface {
+	// Close 取消订阅并等待接收循环退出，可重复调用
+	Close() error
+	// Stats 返回订阅的统计信息
+	Stats() SubscriptionStats
+}
+
+// SubscriptionStats Redis 订阅的统计信息
+type SubscriptionStats struct {
+	// Received 已接收的消息数
+	Received int64
+	// Reconnects 连接断开后重新订阅的次数
+	Reconnects int64
+	// LastError 最近一次连接错误，没有错误时为 nil
+	LastError error
+}
+
+// subscribeOptions 订阅选项
+type subscribeOptions struct {
+	pattern   bool
+	redisName string
+}
+
+// SubscribeOption 订阅选项函数
+type SubscribeOption func(*subscribeOptions)
+
+// WithPattern 按模式订阅（PSUBSCRIBE），channels 作为模式处理，如 "cache:*"
+func WithPattern() SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.pattern = true
+	}
+}
+
+// WithRedisName 使用 RedisList 中指定别名的 Redis，默认使用主 Redis
+func WithRedisName(name string) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.redisName = name
+	}
+}
+
+// redisSubscription Subscription 的实现
+type redisSubscription struct {
+	client   redis.UniversalClient
+	channels []string
+	pattern  bool
+	handler  func(channel, payload string)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	pubsub  *redis.PubSub
+	lastErr error
+
+	received   atomic.Int64
+	reconnects atomic.Int64
+}
+
+// SubscribeRedis 订阅 Redis 频道
+// 在独立的协程中接收消息并依次调用 handler；连接断开后按退避时间自动重新订阅。
+// handler 中的 panic 会被恢复并记录日志，不会中断接收循环。
+// ctx 取消、调用 Subscription.Close 或 Redis 服务关闭时结束订阅。
+//
+// 使用示例：
+//
+//	sub, err := app.SubscribeRedis(ctx, []string{"cache:*"}, func(channel, payload string) {
+//	    localCache.Delete(payload)
+//	}, app.WithPattern())
+//	if err != nil {
+//	    return err
+//	}
+//	defer sub.Close()
+//
+// 参数：
+//   - ctx: 订阅的生命周期
+//   - channels: 订阅的频道，使用 WithPattern 时为模式
+//   - handler: 消息处理函数，channel 为消息实际所在的频道
+//   - opts: 订阅选项
+//
+// 返回：
+//   - Subscription: 订阅实例
+//   - error: Redis 未初始化、参数为空或首次订阅失败时返回错误
+func SubscribeRedis(ctx context.Context, channels []string, handler func(channel, payload string), opts ...SubscribeOption) (Subscription, error) {
+	if len(channels) == 0 {
+		return nil, errors.New("[redis] 订阅频道不能为空")
+	}
+	if handler == nil {
+		return nil, errors.New("[redis] 订阅处理函数不能为空")
+	}
+	options := &subscribeOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	client, err := pubsubClient(options.redisName)
+	if err != nil {
+		return nil, err
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	s := &redisSubscription{
+		client:   client,
+		channels: append([]string(nil), channels...),
+		pattern:  options.pattern,
+		handler:  handler,
+		ctx:      subCtx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	if err := s.subscribe(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// ReceiveMessage 不响应 ctx 取消，订阅结束时关闭连接以中断阻塞的读取
+	context.AfterFunc(subCtx, s.closePubSub)
+
+	subscriptionsMu.Lock()
+	subscriptions[s] = struct{}{}
+	subscriptionsMu.Unlock()
+
+	go s.run()
+	return s, nil
+}
+
+// PublishRedis 向 Redis 频道发布消息
+// 参数：
+//   - ctx: 上下文
+//   - channel: 频道
+//   - payload: 消息内容
+//
+// 返回：
+//   - error: Redis 未初始化或发布失败时返回错误
+func PublishRedis(ctx context.Context, channel, payload string) error {
+	client, err := pubsubClient("")
+	if err != nil {
+		return err
+	}
+	return client.Publish(ctx, channel, payload).Err()
+}
+
+// CloseRedisSubscriptions 关闭所有未关闭的 Redis 订阅，由 Redis 服务关闭时调用
+func CloseRedisSubscriptions() {
+	subscriptionsMu.Lock()
+	list := make([]*redisSubscription, 0, len(subscriptions))
+	for s := range subscriptions {
+		list = append(list, s)
+	}
+	subscriptionsMu.Unlock()
+
+	for _, s := range list {
+		_ = s.Close()
+	}
+}
+
+// pubsubClient 获取订阅使用的 Redis 客户端，name 为空时使用主 Redis
+func pubsubClient(name string) (redis.UniversalClient, error) {
+	if name != "" {
+		return GetRedisByName(name)
+	}
+	if Redis == nil {
+		return nil, errors.New("[redis] Redis 未初始化或不可用")
+	}
+	return Redis, nil
+}
+
+// subscribe 建立订阅并等待 Redis 确认
+func (s *redisSubscription) subscribe() error {
+	var pubsub *redis.PubSub
+	if s.pattern {
+		pubsub = s.client.PSubscribe(s.ctx, s.channels...)
+	} else {
+		pubsub = s.client.Subscribe(s.ctx, s.channels...)
+	}
+	// 先保存连接，订阅结束时 closePubSub 可以中断等待中的确认
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		_ = pubsub.Close()
+		return s.ctx.Err()
+	}
+	s.pubsub = pubsub
+	s.mu.Unlock()
+
+	// 读取订阅确认，确保返回时已开始接收消息
+	if _, err := pubsub.Receive(s.ctx); err != nil {
+		_ = pubsub.Close()
+		return fmt.Errorf("[redis] 订阅 %v 失败: %w", s.channels, err)
+	}
+	return nil
+}
+
+// run 接收循环，连接断开后按退避时间重新订阅
+func (s *redisSubscription) run() {
+	defer close(s.done)
+	defer s.unregister()
+
+	backoff := subscribeMinBackoff
+	for {
+		s.mu.Lock()
+		pubsub := s.pubsub
+		s.mu.Unlock()
+
+		err := s.receive(pubsub)
+		_ = pubsub.Close()
+		if s.ctx.Err() != nil {
+			return
+		}
+		s.setLastError(err)
+		logger.Warn("[redis] 订阅 %v 连接断开, %v 后重新订阅: %v", s.channels, backoff, err)
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, subscribeMaxBackoff)
+
+			if err := s.subscribe(); err != nil {
+				if s.ctx.Err() != nil {
+					return
+				}
+				s.setLastError(err)
+				logger.Warn("[redis] 重新订阅 %v 失败, %v 后重试: %v", s.channels, backoff, err)
+				continue
+			}
+			s.reconnects.Add(1)
+			backoff = subscribeMinBackoff
+			logger.Info("[redis] 已重新订阅 %v", s.channels)
+			break
+		}
+	}
+}
+
+// receive 接收消息直到连接出错或订阅关闭
+func (s *redisSubscription) receive(pubsub *redis.PubSub) error {
+	for {
+		msg, err := pubsub.ReceiveMessage(s.ctx)
+		if err != nil {
+			return err
+		}
+		s.received.Add(1)
+		s.handle(msg)
+	}
+}
+
+// handle 调用消息处理函数，恢复处理函数中的 panic
+func (s *redisSubscription) handle(msg *redis.Message) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("[redis] 订阅消息处理异常, channel: %s, error: %v", msg.Channel, r)
+		}
+	}()
+	s.handler(msg.Channel, msg.Payload)
+}
+
+// setLastError 记录最近一次连接错误
+func (s *redisSubscription) setLastError(err error) {
+	s.mu.Lock()
+	s.lastErr = err
+	s.mu.Unlock()
+}
+
+// unregister 从订阅列表中移除
+func (s *redisSubscription) unregister() {
+	subscriptionsMu.Lock()
+	delete(subscriptions, s)
+	subscriptionsMu.Unlock()
+}
+
+// closePubSub 关闭当前的订阅连接
+func (s *redisSubscription) closePubSub() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pubsub != nil {
+		_ = s.pubsub.Close()
+	}
+}
+
+// Close 取消订阅并等待接收循环退出，可重复调用
+func (s *redisSubscription) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// Stats 返回订阅的统计信息
+func (s *redisSubscription) Stats() SubscriptionStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SubscriptionStats{
+		Received:   s.received.Load(),
+		Reconnects: s.reconnects.Load(),
+		LastError:  s.lastErr,
+	}
+}
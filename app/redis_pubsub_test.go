@@ -0,0 +1,255 @@
+// Package app Redis 发布订阅测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 Redis 发布订阅的单元测试，使用 miniredis 模拟 Redis，不需要真实的 Redis。
+//
+// 测试覆盖内容：
+// 1. 订阅频道后接收发布的消息
+// 2. 按模式订阅时接收匹配频道的消息
+// 3. 处理函数 panic 不中断接收循环
+// 4. Close 可重复调用，关闭后不再接收消息
+// 5. 连接断开后自动重新订阅，Redis 服务关闭时关闭所有订阅
+//
+// 运行测试：go test -v ./app/... -run Redis
+// ==================================================
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// setupPubSubTest 启动 miniredis 并设置为主 Redis，测试结束后恢复
+func setupPubSubTest(t *testing.T) *miniredis.Miniredis {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	original := Redis
+	Redis = client
+	t.Cleanup(func() {
+		Redis = original
+		_ = client.Close()
+	})
+	return mr
+}
+
+// messageRecorder 记录收到的消息
+type messageRecorder struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+// handle 记录 "{channel}={payload}"
+func (r *messageRecorder) handle(channel, payload string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, channel+"="+payload)
+}
+
+// wait 等待收到 n 条消息，超时返回已收到的消息
+func (r *messageRecorder) wait(n int) []string {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		r.mu.Lock()
+		got := append([]string(nil), r.messages...)
+		r.mu.Unlock()
+		if len(got) >= n || time.Now().After(deadline) {
+			return got
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestSubscribeRedis_Delivery 测试消息接收
+//
+// 【功能点】验证订阅后能收到 PublishRedis 发布的消息，Stats 统计接收数
+// 【测试流程】
+//  1. 订阅 cache:user 和 cache:order
+//  2. 向两个频道和未订阅的频道各发布一条消息
+//  3. 断言只收到已订阅频道的消息，Stats.Received 为 2
+func TestSubscribeRedis_Delivery(t *testing.T) {
+	setupPubSubTest(t)
+	ctx := context.Background()
+	rec := &messageRecorder{}
+
+	sub, err := SubscribeRedis(ctx, []string{"cache:user", "cache:order"}, rec.handle)
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	defer sub.Close()
+
+	for _, channel := range []string{"cache:user", "cache:other", "cache:order"} {
+		if err := PublishRedis(ctx, channel, "1"); err != nil {
+			t.Fatalf("发布失败: %v", err)
+		}
+	}
+
+	got := rec.wait(2)
+	if len(got) != 2 || got[0] != "cache:user=1" || got[1] != "cache:order=1" {
+		t.Errorf("期望收到 cache:user 和 cache:order 的消息, 实际 %v", got)
+	}
+	if stats := sub.Stats(); stats.Received != 2 || stats.Reconnects != 0 || stats.LastError != nil {
+		t.Errorf("统计信息不正确: %+v", stats)
+	}
+}
+
+// TestSubscribeRedis_Pattern 测试模式订阅
+//
+// 【功能点】验证 WithPattern 按模式订阅，处理函数收到消息实际所在的频道
+// 【测试流程】
+//  1. 按模式 cache:* 订阅
+//  2. 向 cache:user、other:user 各发布一条消息
+//  3. 断言只收到 cache:user 的消息
+func TestSubscribeRedis_Pattern(t *testing.T) {
+	setupPubSubTest(t)
+	ctx := context.Background()
+	rec := &messageRecorder{}
+
+	sub, err := SubscribeRedis(ctx, []string{"cache:*"}, rec.handle, WithPattern())
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	defer sub.Close()
+
+	_ = PublishRedis(ctx, "other:user", "1")
+	_ = PublishRedis(ctx, "cache:user", "2")
+
+	if got := rec.wait(1); len(got) != 1 || got[0] != "cache:user=2" {
+		t.Errorf("期望只收到 cache:user=2, 实际 %v", got)
+	}
+}
+
+// TestSubscribeRedis_HandlerPanic 测试处理函数 panic
+//
+// 【功能点】验证处理函数 panic 被恢复，后续消息仍能正常处理
+// 【测试流程】
+//  1. 订阅 events，处理函数收到 "boom" 时 panic
+//  2. 依次发布 boom、ok
+//  3. 断言收到 ok，Stats.Received 为 2
+func TestSubscribeRedis_HandlerPanic(t *testing.T) {
+	setupPubSubTest(t)
+	ctx := context.Background()
+	rec := &messageRecorder{}
+
+	sub, err := SubscribeRedis(ctx, []string{"events"}, func(channel, payload string) {
+		if payload == "boom" {
+			panic("handler failed")
+		}
+		rec.handle(channel, payload)
+	})
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	defer sub.Close()
+
+	_ = PublishRedis(ctx, "events", "boom")
+	_ = PublishRedis(ctx, "events", "ok")
+
+	if got := rec.wait(1); len(got) != 1 || got[0] != "events=ok" {
+		t.Errorf("panic 后应继续处理消息, 实际 %v", got)
+	}
+	if received := sub.Stats().Received; received != 2 {
+		t.Errorf("期望接收 2 条消息, 实际 %d", received)
+	}
+}
+
+// TestSubscribeRedis_Close 测试关闭订阅
+//
+// 【功能点】验证 Close 可重复调用，关闭后不再接收消息，ctx 取消同样结束订阅
+// 【测试流程】
+//  1. 订阅后调用两次 Close，断言都返回 nil
+//  2. 断言频道没有订阅者，处理函数没有被调用
+//  3. 取消 ctx 后断言订阅从全局列表中移除
+func TestSubscribeRedis_Close(t *testing.T) {
+	mr := setupPubSubTest(t)
+	rec := &messageRecorder{}
+
+	sub, err := SubscribeRedis(context.Background(), []string{"events"}, rec.handle)
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	if err := sub.Close(); err != nil {
+		t.Errorf("Close 返回错误: %v", err)
+	}
+	if err := sub.Close(); err != nil {
+		t.Errorf("重复 Close 返回错误: %v", err)
+	}
+	// miniredis 异步处理连接关闭，等待订阅者数量变为 0
+	deadline := time.Now().Add(2 * time.Second)
+	for mr.Publish("events", "1") != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := mr.Publish("events", "1"); n != 0 {
+		t.Errorf("关闭后频道不应有订阅者, 实际 %d", n)
+	}
+	if got := rec.wait(0); len(got) != 0 {
+		t.Errorf("关闭后不应再处理消息, 实际 %v", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, err = SubscribeRedis(ctx, []string{"events"}, rec.handle)
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	cancel()
+	<-sub.(*redisSubscription).done
+	subscriptionsMu.Lock()
+	remaining := len(subscriptions)
+	subscriptionsMu.Unlock()
+	if remaining != 0 {
+		t.Errorf("ctx 取消后订阅应从列表中移除, 剩余 %d", remaining)
+	}
+
+	if _, err := SubscribeRedis(context.Background(), nil, rec.handle); err == nil {
+		t.Error("频道为空时应返回错误")
+	}
+}
+
+// TestSubscribeRedis_Reconnect 测试断线重连
+//
+// 【功能点】验证连接断开后自动重新订阅，CloseRedisSubscriptions 关闭所有订阅
+// 【测试流程】
+//  1. 缩短退避时间，订阅 events
+//  2. 重启 miniredis，断开已有连接
+//  3. 断言重新订阅后能收到消息，Stats.Reconnects 为 1，LastError 不为空
+//  4. 调用 CloseRedisSubscriptions，断言接收循环退出
+func TestSubscribeRedis_Reconnect(t *testing.T) {
+	mr := setupPubSubTest(t)
+	originalMin, originalMax := subscribeMinBackoff, subscribeMaxBackoff
+	subscribeMinBackoff, subscribeMaxBackoff = 10*time.Millisecond, 50*time.Millisecond
+	t.Cleanup(func() { subscribeMinBackoff, subscribeMaxBackoff = originalMin, originalMax })
+	rec := &messageRecorder{}
+
+	sub, err := SubscribeRedis(context.Background(), []string{"events"}, rec.handle)
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+
+	mr.Close()
+	if err := mr.Restart(); err != nil {
+		t.Fatalf("重启 miniredis 失败: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for sub.Stats().Reconnects == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	_ = PublishRedis(context.Background(), "events", "after-restart")
+	if got := rec.wait(1); len(got) != 1 || got[0] != "events=after-restart" {
+		t.Errorf("重新订阅后应收到消息, 实际 %v", got)
+	}
+	if stats := sub.Stats(); stats.Reconnects != 1 || stats.LastError == nil {
+		t.Errorf("统计信息不正确: %+v", stats)
+	}
+
+	CloseRedisSubscriptions()
+	select {
+	case <-sub.(*redisSubscription).done:
+	case <-time.After(time.Second):
+		t.Error("CloseRedisSubscriptions 后接收循环应退出")
+	}
+}
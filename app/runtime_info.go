@@ -0,0 +1,53 @@
+package app
+
+import (
+	"os"
+	"time"
+
+	"github.com/zzsen/gin_core/version"
+)
+
+// startTime 进程启动时间
+var startTime = time.Now()
+
+// RuntimeSnapshot 运行信息
+type RuntimeSnapshot struct {
+	// Version 版本号
+	Version string `json:"version"`
+	// Commit 构建时的 Git 提交
+	Commit string `json:"commit"`
+	// BuildTime 构建时间
+	BuildTime string `json:"buildTime"`
+	// GoVersion Go 版本
+	GoVersion string `json:"goVersion"`
+	// Env 运行环境
+	Env string `json:"env"`
+	// StartTime 进程启动时间
+	StartTime time.Time `json:"startTime"`
+	// UptimeSeconds 运行时长（秒）
+	UptimeSeconds float64 `json:"uptimeSeconds"`
+	// Pid 进程 ID
+	Pid int `json:"pid"`
+	// Hostname 主机名
+	Hostname string `json:"hostname"`
+}
+
+// RuntimeInfo 获取当前进程的运行信息，包括构建元数据（见 version 包）、运行环境和运行时长
+// 可用于健康检查等接口的响应内容补充
+//
+// 返回：
+//   - RuntimeSnapshot: 运行信息
+func RuntimeInfo() RuntimeSnapshot {
+	hostname, _ := os.Hostname()
+	return RuntimeSnapshot{
+		Version:       version.Version,
+		Commit:        version.GitCommit,
+		BuildTime:     version.BuildTime,
+		GoVersion:     version.GetGoVersion(),
+		Env:           Env,
+		StartTime:     startTime,
+		UptimeSeconds: time.Since(startTime).Seconds(),
+		Pid:           os.Getpid(),
+		Hostname:      hostname,
+	}
+}
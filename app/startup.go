@@ -0,0 +1,38 @@
+package app
+
+import (
+	"maps"
+	"sync"
+)
+
+var (
+	// unavailableServices 降级启动的服务，服务名称 -> 启动时的最后一次连接错误
+	unavailableServices   = make(map[string]error)
+	unavailableServicesMu sync.RWMutex
+)
+
+// MarkUnavailable 将服务标记为不可用
+// 启用 system.startupRetry 且服务在 optional 中时，超过最长等待时间后服务降级启动并被标记为不可用，
+// 就绪检查（/healthy/ready）报告该服务不健康，直到进程重启或调用 MarkAvailable
+// 参数：
+//   - name: 服务名称，如 mysql、redis
+//   - err: 启动时的最后一次连接错误
+func MarkUnavailable(name string, err error) {
+	unavailableServicesMu.Lock()
+	defer unavailableServicesMu.Unlock()
+	unavailableServices[name] = err
+}
+
+// MarkAvailable 取消服务的不可用标记，用于降级启动的服务在运行期间手动恢复后
+func MarkAvailable(name string) {
+	unavailableServicesMu.Lock()
+	defer unavailableServicesMu.Unlock()
+	delete(unavailableServices, name)
+}
+
+// UnavailableServices 获取降级启动的服务及其启动时的最后一次连接错误
+func UnavailableServices() map[string]error {
+	unavailableServicesMu.RLock()
+	defer unavailableServicesMu.RUnlock()
+	return maps.Clone(unavailableServices)
+}
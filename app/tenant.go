@@ -0,0 +1,163 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"gorm.io/gorm"
+)
+
+// TenantResolver 租户解析函数，将租户 ID 映射为 dbList 中的数据库别名
+// 租户不存在时应返回 ErrUnknownTenant（或包装了 ErrUnknownTenant 的错误），其他错误视为解析失败
+type TenantResolver func(ctx context.Context, tenantID string) (alias string, err error)
+
+var (
+	// ErrTenantMissing 请求上下文中没有租户 ID
+	ErrTenantMissing = errors.New("[tenant] 缺少租户 ID")
+	// ErrUnknownTenant 租户 ID 无法映射到数据库别名
+	ErrUnknownTenant = errors.New("[tenant] 租户不存在")
+
+	// DBOpener 按配置打开数据库连接，用于首次使用时才连接的 dbList 数据库（lazy: true）
+	// 由 initialize.InitDBList 设置为框架的 MySQL 初始化函数，测试或使用其他驱动时可替换
+	DBOpener func(dbInfo config.DbInfo) (*gorm.DB, error)
+
+	// tenantResolver 通过 SetTenantResolver 注册的租户解析函数
+	tenantResolver   TenantResolver
+	tenantResolverMu sync.RWMutex
+	// dbOpenMu 串行化延迟连接，避免并发请求重复打开同一个数据库
+	dbOpenMu sync.Mutex
+)
+
+// SetTenantResolver 注册租户解析函数
+// 注册后租户到数据库别名的映射完全由该函数决定，不再使用 tenant.mapping 配置；传入 nil 时恢复使用配置
+//
+// 参数：
+//   - resolver: 租户解析函数
+func SetTenantResolver(resolver TenantResolver) {
+	tenantResolverMu.Lock()
+	defer tenantResolverMu.Unlock()
+	tenantResolver = resolver
+}
+
+// ResolveTenant 将租户 ID 映射为 dbList 中的数据库别名
+// 优先使用 SetTenantResolver 注册的解析函数，未注册时使用 tenant.mapping 配置
+//
+// 参数：
+//   - ctx: 上下文
+//   - tenantID: 租户 ID
+//
+// 返回：
+//   - string: 数据库别名
+//   - error: 租户 ID 为空时返回 ErrTenantMissing，租户不存在时返回包装了 ErrUnknownTenant 的错误
+func ResolveTenant(ctx context.Context, tenantID string) (string, error) {
+	if tenantID == "" {
+		return "", ErrTenantMissing
+	}
+	tenantResolverMu.RLock()
+	resolver := tenantResolver
+	tenantResolverMu.RUnlock()
+
+	if resolver != nil {
+		alias, err := resolver(ctx, tenantID)
+		if err != nil {
+			return "", err
+		}
+		if alias == "" {
+			return "", fmt.Errorf("%w: %s", ErrUnknownTenant, tenantID)
+		}
+		return alias, nil
+	}
+	if alias, ok := BaseConfig.Tenant.Mapping[tenantID]; ok && alias != "" {
+		return alias, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownTenant, tenantID)
+}
+
+// GetTenantDB 获取租户对应的数据库连接
+// 数据库按别名缓存在 DBList 中，配置了 lazy: true 的 dbList 数据库在首次使用时通过 DBOpener 连接
+//
+// 参数：
+//   - ctx: 上下文
+//   - tenantID: 租户 ID
+//
+// 返回：
+//   - *gorm.DB: 数据库实例
+//   - error: 租户不存在、数据库别名未配置或连接失败时返回错误
+func GetTenantDB(ctx context.Context, tenantID string) (*gorm.DB, error) {
+	alias, err := ResolveTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if db, err := GetDbByName(alias); err == nil {
+		return db, nil
+	}
+	return openLazyDB(alias)
+}
+
+// openLazyDB 连接 dbList 中指定别名的数据库并缓存到 DBList
+func openLazyDB(alias string) (*gorm.DB, error) {
+	dbOpenMu.Lock()
+	defer dbOpenMu.Unlock()
+	// 等待锁期间其他请求可能已完成连接
+	if db, err := GetDbByName(alias); err == nil {
+		return db, nil
+	}
+
+	var dbInfo *config.DbInfo
+	for i := range BaseConfig.DbList {
+		if BaseConfig.DbList[i].AliasName == alias {
+			dbInfo = &BaseConfig.DbList[i]
+			break
+		}
+	}
+	if dbInfo == nil {
+		return nil, fmt.Errorf("[tenant] 数据库别名 `%s` 未在 dbList 中配置", alias)
+	}
+	if DBOpener == nil {
+		return nil, fmt.Errorf("[tenant] 数据库 `%s` 未初始化，且未设置 app.DBOpener", alias)
+	}
+	db, err := DBOpener(*dbInfo)
+	if err != nil {
+		return nil, fmt.Errorf("[tenant] 连接数据库 `%s` 失败: %w", alias, err)
+	}
+
+	lock.Lock()
+	if DBList == nil {
+		DBList = make(map[string]*gorm.DB)
+	}
+	DBList[alias] = db
+	lock.Unlock()
+	logger.Info("[tenant] 数据库 `%s` 已连接", alias)
+	return db, nil
+}
+
+// TenantDB 获取当前请求租户对应的数据库连接
+// 租户 ID 由 tenantHandler 中间件存入请求上下文，返回的连接已绑定请求的 context.Context。
+// tenantHandler 会拒绝缺少租户 ID、租户不存在或数据库连接失败的请求，因此在其之后的 handler 中返回值不为 nil；
+// 未使用 tenantHandler 时，获取失败会记录错误日志并返回 nil，需要处理错误时请使用 GetTenantDB
+//
+// 参数：
+//   - c: gin 上下文
+//
+// 返回：
+//   - *gorm.DB: 数据库实例，获取失败时为 nil
+//
+// 使用示例：
+//
+//	var orders []Order
+//	app.TenantDB(c).Where("status = ?", status).Find(&orders)
+func TenantDB(c *gin.Context) *gorm.DB {
+	ctx := c.Request.Context()
+	db, err := GetTenantDB(ctx, ginContext.GetTenantID(c))
+	if err != nil {
+		logger.Error("%v", err)
+		return nil
+	}
+	return db.WithContext(ctx)
+}
@@ -40,6 +40,11 @@ func (s State) String() string {
 	}
 }
 
+// MarshalText 实现 encoding.TextMarshaler，JSON 序列化时输出状态的字符串表示
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
 // 预定义错误
 var (
 	// ErrCircuitOpen 熔断器处于打开状态
@@ -251,6 +256,7 @@ func (cb *CircuitBreaker) setState(state State, now time.Time) {
 	}
 
 	prev := cb.state
+	counts := cb.counts
 	cb.state = state
 	cb.reset(now)
 
@@ -258,6 +264,8 @@ func (cb *CircuitBreaker) setState(state State, now time.Time) {
 	if cb.config.OnStateChange != nil {
 		go cb.config.OnStateChange(cb.name, prev, state)
 	}
+	// 发布事件，由订阅者的协程异步处理
+	publish(BreakerEvent{Name: cb.name, From: prev, To: state, Counts: counts, At: now})
 }
 
 // reset 重置计数器
@@ -295,9 +303,15 @@ func (cb *CircuitBreaker) Name() string {
 }
 
 // Reset 手动重置熔断器到关闭状态
+// 重置前不是关闭状态时发布状态变更事件，不触发 OnStateChange 回调
 func (cb *CircuitBreaker) Reset() {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
+	now := time.Now()
+	prev, counts := cb.state, cb.counts
 	cb.state = StateClosed
-	cb.reset(time.Now())
+	cb.reset(now)
+	if prev != StateClosed {
+		publish(BreakerEvent{Name: cb.name, From: prev, To: StateClosed, Counts: counts, At: now})
+	}
 }
@@ -0,0 +1,104 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zzsen/gin_core/logger"
+)
+
+// DefaultEventBufferSize 每个订阅者的事件缓冲区大小
+const DefaultEventBufferSize = 256
+
+// BreakerEvent 熔断器状态变更事件
+type BreakerEvent struct {
+	Name   string    // 熔断器名称
+	From   State     // 变更前的状态
+	To     State     // 变更后的状态
+	Counts Counts    // 状态变更前的请求计数（触发变更的统计数据）
+	At     time.Time // 状态变更时间
+}
+
+// subscriber 事件订阅者，每个订阅者有独立的缓冲区和投递协程
+type subscriber struct {
+	fn     func(BreakerEvent)
+	events chan BreakerEvent
+}
+
+var (
+	subscribersMu sync.RWMutex
+	subscribers   = make(map[*subscriber]struct{})
+	droppedEvents atomic.Uint64
+)
+
+// Subscribe 订阅所有熔断器的状态变更事件
+// 事件在独立协程中按发生顺序投递给 fn，fn 执行缓慢不会阻塞 Execute；
+// 订阅者的缓冲区（DefaultEventBufferSize）已满时丢弃新事件，丢弃数量通过 DroppedEvents 获取。
+// 所有熔断器（包括注册中心创建的熔断器）都会发布事件，无需在 Config 中设置
+//
+// 参数：
+//   - fn: 事件处理函数
+//
+// 返回：
+//   - func(): 取消订阅函数，调用后不再投递新事件，缓冲区中的事件仍会投递完
+//
+// 使用示例：
+//
+//	unsubscribe := circuitbreaker.Subscribe(func(e circuitbreaker.BreakerEvent) {
+//	    if e.To == circuitbreaker.StateOpen {
+//	        alerting.Send(fmt.Sprintf("服务 %s 触发熔断", e.Name))
+//	    }
+//	})
+//	defer unsubscribe()
+func Subscribe(fn func(BreakerEvent)) func() {
+	s := &subscriber{fn: fn, events: make(chan BreakerEvent, DefaultEventBufferSize)}
+	subscribersMu.Lock()
+	subscribers[s] = struct{}{}
+	subscribersMu.Unlock()
+
+	go func() {
+		for e := range s.events {
+			s.fn(e)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			subscribersMu.Lock()
+			delete(subscribers, s)
+			close(s.events)
+			subscribersMu.Unlock()
+		})
+	}
+}
+
+// DroppedEvents 获取因订阅者缓冲区已满而丢弃的事件总数
+func DroppedEvents() uint64 {
+	return droppedEvents.Load()
+}
+
+// publish 向所有订阅者发布事件，不阻塞调用方
+func publish(e BreakerEvent) {
+	subscribersMu.RLock()
+	defer subscribersMu.RUnlock()
+	for s := range subscribers {
+		select {
+		case s.events <- e:
+		default:
+			droppedEvents.Add(1)
+		}
+	}
+}
+
+// LogEvent 通过框架日志记录状态变更事件，进入打开状态时记录 Warn，其他状态记录 Info
+// 可直接作为订阅函数使用：circuitbreaker.Subscribe(circuitbreaker.LogEvent)
+func LogEvent(e BreakerEvent) {
+	if e.To == StateOpen {
+		logger.Warn("[熔断器] %s 状态变更: %s -> %s, 请求数: %d, 失败数: %d, 连续失败数: %d",
+			e.Name, e.From, e.To, e.Counts.Requests, e.Counts.TotalFailures, e.Counts.ConsecutiveFailures)
+		return
+	}
+	logger.Info("[熔断器] %s 状态变更: %s -> %s", e.Name, e.From, e.To)
+}
@@ -0,0 +1,191 @@
+// Package circuitbreaker 熔断器状态变更事件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含状态变更事件订阅与 Webhook 通知的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 订阅 - 按顺序收到 Closed→Open→HalfOpen→Closed 的事件，取消订阅后不再收到
+// 2. 异步投递 - 处理缓慢的订阅者不阻塞 Execute，缓冲区已满时丢弃事件并计数
+// 3. Webhook - 5xx 响应时重试，4xx 响应不重试
+//
+// 运行测试：go test -v ./circuitbreaker/... -run "Subscribe|Webhook"
+// ==================================================
+package circuitbreaker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tripBreaker 通过连续失败使熔断器进入打开状态
+func tripBreaker(cb *CircuitBreaker, failures int) {
+	for i := 0; i < failures; i++ {
+		_ = cb.Execute(context.Background(), func() error { return errors.New("error") })
+	}
+}
+
+// TestSubscribe_Cycle 测试订阅完整的状态变更周期
+//
+// 【功能点】验证订阅者按发生顺序收到 Closed→Open→HalfOpen→Closed 的事件，事件包含触发变更的计数
+// 【测试流程】
+//  1. 订阅事件，只记录本测试熔断器的事件
+//  2. 连续失败触发熔断，等待超时进入半开状态，探测成功后关闭
+//  3. 断言收到三个事件，顺序和计数正确
+//  4. 取消订阅后再次触发熔断，断言不再收到事件
+func TestSubscribe_Cycle(t *testing.T) {
+	const name = "subscribe-cycle"
+	var mu sync.Mutex
+	var events []BreakerEvent
+	unsubscribe := Subscribe(func(e BreakerEvent) {
+		if e.Name != name {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+
+	cb := New(&Config{Name: name, MaxRequests: 1, Interval: time.Minute, Timeout: 50 * time.Millisecond, FailureThreshold: 2, FailureRatio: 1, MinRequests: 10})
+	tripBreaker(cb, 2)
+	time.Sleep(60 * time.Millisecond)
+	_ = cb.Execute(context.Background(), func() error { return nil })
+
+	received := func() []BreakerEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]BreakerEvent(nil), events...)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(received()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	got := received()
+	want := [][2]State{{StateClosed, StateOpen}, {StateOpen, StateHalfOpen}, {StateHalfOpen, StateClosed}}
+	if len(got) != len(want) {
+		t.Fatalf("期望收到 %d 个事件，实际 %d 个: %+v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		if got[i].From != w[0] || got[i].To != w[1] {
+			t.Errorf("第 %d 个事件应为 %s -> %s，实际为 %s -> %s", i+1, w[0], w[1], got[i].From, got[i].To)
+		}
+		if i > 0 && got[i].At.Before(got[i-1].At) {
+			t.Errorf("第 %d 个事件的时间早于前一个事件", i+1)
+		}
+	}
+	if got[0].Counts.ConsecutiveFailures != 2 {
+		t.Errorf("打开事件的连续失败数应为 2，实际为 %d", got[0].Counts.ConsecutiveFailures)
+	}
+
+	unsubscribe()
+	cb.Reset()
+	time.Sleep(20 * time.Millisecond)
+	if n := len(received()); n != 3 {
+		t.Errorf("取消订阅后不应再收到事件，实际共 %d 个", n)
+	}
+}
+
+// TestSubscribe_SlowSubscriber 测试处理缓慢的订阅者
+//
+// 【功能点】验证订阅者处理缓慢时 Execute 不被阻塞，缓冲区已满时丢弃事件并增加丢弃计数
+// 【测试流程】
+//  1. 订阅一个阻塞直到测试结束的处理函数
+//  2. 反复触发熔断和重置，产生超过缓冲区大小的事件，测量总耗时
+//  3. 断言总耗时远小于订阅者的处理时间，丢弃计数增加
+func TestSubscribe_SlowSubscriber(t *testing.T) {
+	release := make(chan struct{})
+	unsubscribe := Subscribe(func(e BreakerEvent) { <-release })
+	defer func() {
+		close(release)
+		unsubscribe()
+	}()
+
+	dropped := DroppedEvents()
+	cb := New(&Config{Name: "slow-subscriber", MaxRequests: 1, Interval: time.Minute, Timeout: time.Minute, FailureThreshold: 1})
+	start := time.Now()
+	for i := 0; i < DefaultEventBufferSize+10; i++ {
+		tripBreaker(cb, 1)
+		cb.Reset()
+	}
+	elapsed := time.Since(start)
+
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("订阅者处理缓慢不应阻塞 Execute，耗时 %v", elapsed)
+	}
+	if DroppedEvents() <= dropped {
+		t.Error("缓冲区已满时应丢弃事件并增加丢弃计数")
+	}
+}
+
+// TestWebhookNotifier_RetryOn500 测试 Webhook 失败重试
+//
+// 【功能点】验证 5xx 响应时按配置重试并在成功后停止，请求体包含事件信息
+// 【测试流程】
+//  1. 启动前两次返回 500、之后返回 200 的服务
+//  2. 发送事件，断言成功且共请求 3 次，请求体中的状态为字符串
+func TestWebhookNotifier_RetryOn500(t *testing.T) {
+	var calls atomic.Int32
+	var payload webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(WebhookOptions{URL: server.URL, MaxRetries: 3, RetryInterval: time.Millisecond})
+	err := n.Send(context.Background(), BreakerEvent{Name: "user-service", From: StateClosed, To: StateOpen, At: time.Now()})
+	if err != nil {
+		t.Fatalf("重试后应发送成功，实际错误: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("应请求 3 次，实际 %d 次", calls.Load())
+	}
+	if payload.Name != "user-service" || payload.From != "closed" || payload.To != "open" {
+		t.Errorf("请求体不正确: %+v", payload)
+	}
+}
+
+// TestWebhookNotifier_NoRetryOn4xx 测试 Webhook 4xx 响应
+//
+// 【功能点】验证 4xx 响应不重试，5xx 响应超过最大重试次数后返回错误
+// 【测试流程】
+//  1. 服务返回 400，断言只请求 1 次并返回错误
+//  2. 服务始终返回 503，断言请求 MaxRetries+1 次并返回错误
+func TestWebhookNotifier_NoRetryOn4xx(t *testing.T) {
+	var calls atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusBadRequest)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(WebhookOptions{URL: server.URL, MaxRetries: 2, RetryInterval: time.Millisecond})
+	if err := n.Send(context.Background(), BreakerEvent{Name: "user-service"}); err == nil {
+		t.Error("4xx 响应应返回错误")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("4xx 响应不应重试，实际请求 %d 次", calls.Load())
+	}
+
+	calls.Store(0)
+	status.Store(http.StatusServiceUnavailable)
+	if err := n.Send(context.Background(), BreakerEvent{Name: "user-service"}); err == nil {
+		t.Error("超过最大重试次数应返回错误")
+	}
+	if calls.Load() != 3 {
+		t.Errorf("应请求 3 次，实际 %d 次", calls.Load())
+	}
+}
@@ -105,6 +105,21 @@ func (r *Registry) Remove(name string) {
 	r.breakers.Delete(name)
 }
 
+// Lookup 查找已注册的熔断器，不存在时不会创建
+// 参数：
+//   - name: 熔断器名称
+//
+// 返回：
+//   - *CircuitBreaker: 熔断器实例
+//   - bool: 是否存在
+func (r *Registry) Lookup(name string) (*CircuitBreaker, bool) {
+	cb, ok := r.breakers.Load(name)
+	if !ok {
+		return nil, false
+	}
+	return cb.(*CircuitBreaker), true
+}
+
 // Reset 重置指定熔断器
 // 参数：
 //   - name: 熔断器名称
@@ -152,8 +167,8 @@ func (r *Registry) Stats() map[string]BreakerStats {
 
 // BreakerStats 熔断器状态统计
 type BreakerStats struct {
-	State  State
-	Counts Counts
+	State  State  `json:"state"`
+	Counts Counts `json:"counts"`
 }
 
 // ==================== 便捷函数 ====================
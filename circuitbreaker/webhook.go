@@ -0,0 +1,155 @@
+package circuitbreaker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zzsen/gin_core/logger"
+)
+
+// WebhookOptions Webhook 通知配置
+type WebhookOptions struct {
+	// URL 通知地址，状态变更时以 POST JSON 发送事件
+	URL string
+	// Timeout 单次请求的超时时间，默认 5s
+	Timeout time.Duration
+	// MaxRetries 请求失败（网络错误或 5xx 响应）后的最大重试次数，默认 0（不重试）
+	MaxRetries int
+	// RetryInterval 重试间隔，默认 1s
+	RetryInterval time.Duration
+	// Client 发送请求的 HTTP 客户端，默认 http.DefaultClient
+	Client *http.Client
+}
+
+// withDefaults 补全未配置的选项
+func (o WebhookOptions) withDefaults() WebhookOptions {
+	if o.Timeout <= 0 {
+		o.Timeout = 5 * time.Second
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = time.Second
+	}
+	if o.Client == nil {
+		o.Client = http.DefaultClient
+	}
+	return o
+}
+
+// webhookPayload Webhook 请求体
+type webhookPayload struct {
+	Name   string    `json:"name"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Counts Counts    `json:"counts"`
+	At     time.Time `json:"at"`
+}
+
+// WebhookNotifier 熔断器状态变更的 Webhook 通知器
+// Start 后订阅状态变更事件，在订阅者协程中逐个发送通知，通知耗时不会阻塞 Execute
+type WebhookNotifier struct {
+	opts        WebhookOptions
+	mu          sync.Mutex
+	unsubscribe func()
+}
+
+// NewWebhookNotifier 创建 Webhook 通知器
+// 参数：
+//   - opts: 通知配置
+//
+// 返回：
+//   - *WebhookNotifier: 通知器，需要调用 Start 开始订阅
+func NewWebhookNotifier(opts WebhookOptions) *WebhookNotifier {
+	return &WebhookNotifier{opts: opts.withDefaults()}
+}
+
+// Start 订阅状态变更事件，重复调用无效果
+func (n *WebhookNotifier) Start() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.unsubscribe != nil {
+		return
+	}
+	n.unsubscribe = Subscribe(func(e BreakerEvent) {
+		if err := n.Send(context.Background(), e); err != nil {
+			logger.Error("[熔断器] 发送状态变更通知失败, 熔断器: %s, %s -> %s, error: %v", e.Name, e.From, e.To, err)
+		}
+	})
+}
+
+// Stop 取消订阅，已进入缓冲区的事件仍会发送
+func (n *WebhookNotifier) Stop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.unsubscribe != nil {
+		n.unsubscribe()
+		n.unsubscribe = nil
+	}
+}
+
+// Send 发送单个事件的通知，网络错误或 5xx 响应时按配置重试，4xx 响应不重试
+// 参数：
+//   - ctx: 上下文，取消后停止重试
+//   - e: 状态变更事件
+//
+// 返回：
+//   - error: 重试后仍失败时返回最后一次的错误
+func (n *WebhookNotifier) Send(ctx context.Context, e BreakerEvent) error {
+	body, err := json.Marshal(webhookPayload{
+		Name:   e.Name,
+		From:   e.From.String(),
+		To:     e.To.String(),
+		Counts: e.Counts,
+		At:     e.At,
+	})
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		retryable, err := n.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= n.opts.MaxRetries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(n.opts.RetryInterval):
+		}
+	}
+}
+
+// post 发送一次请求，返回失败是否可以重试
+func (n *WebhookNotifier) post(ctx context.Context, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.opts.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook 响应状态码: %d", resp.StatusCode)
+	case resp.StatusCode >= 300:
+		return false, fmt.Errorf("webhook 响应状态码: %d", resp.StatusCode)
+	}
+	return false, nil
+}
@@ -23,6 +23,10 @@ const CustomConfigFileNamePrefix = "config."
 // 支持YAML的所有特性，包括多文档、引用等
 const CustomConfigFileNameSuffix = ".yml"
 
+// MaxConfigIncludeDepth 配置文件 include 最大嵌套深度
+// 防止过深的引用链导致配置难以追踪
+const MaxConfigIncludeDepth = 8
+
 // DefaultDBSlowThreshold 数据库慢查询阈值（毫秒）
 const DefaultDBSlowThreshold = 200
 
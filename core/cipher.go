@@ -0,0 +1,296 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zzsen/gin_core/utils/encrypt"
+)
+
+// 配置文件中加密内容的格式
+const (
+	cipherVersionV1 = "v1" // CIPHER(...)：AES-ECB，PKCS7 填充，无完整性校验，保留用于兼容已有配置
+	cipherVersionV2 = "v2" // CIPHERV2(...)：AES-GCM，带认证标签，篡改后解密失败
+)
+
+// cipherExpr 匹配 CIPHER(加密内容) 和 CIPHERV2(加密内容)，分组 1 为 V2 标记，分组 2 为加密内容
+var cipherExpr = regexp.MustCompile(`CIPHER(V2)?\((.*?)\)`)
+
+// cipherFallbackKeys 解密时在 -cipherKey 之后依次尝试的备用密钥
+// 由 loadConfig 在加载配置文件前从 system.cipherFallbackKeys 读取
+var cipherFallbackKeys []string
+
+// cipherKeyList 返回解密时依次尝试的密钥：主密钥在前，忽略空密钥和重复的密钥
+func cipherKeyList(primary string) []string {
+	keys := make([]string, 0, len(cipherFallbackKeys)+1)
+	seen := make(map[string]bool, len(cipherFallbackKeys)+1)
+	for _, key := range append([]string{primary}, cipherFallbackKeys...) {
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// encryptCipherValue 加密明文，返回可直接写入配置文件的 CIPHERV2(...) 或 CIPHER(...)
+// 参数：
+//   - plainText: 明文
+//   - key: 加密密钥，长度必须为16、24或32字节
+//   - version: 加密格式，v2 或 v1，为空时使用 v2
+//
+// 返回：
+//   - string: 加密后的占位符
+//   - error: 格式不支持或加密失败时返回错误
+func encryptCipherValue(plainText, key, version string) (string, error) {
+	switch version {
+	case "", cipherVersionV2:
+		data, err := encrypt.AesGcmEncrypt(plainText, key)
+		if err != nil {
+			return "", err
+		}
+		return "CIPHERV2(" + data + ")", nil
+	case cipherVersionV1:
+		data, err := encrypt.AesEcbEncrypt(plainText, key)
+		if err != nil {
+			return "", err
+		}
+		return "CIPHER(" + data + ")", nil
+	default:
+		return "", fmt.Errorf("不支持的加密格式: %s, 可选值: v2, v1", version)
+	}
+}
+
+// decryptCipherValue 依次使用 keys 中的密钥解密加密内容，返回明文和解密成功的密钥在 keys 中的下标
+// CIPHER(...) 以 PKCS7 填充合法且明文为合法 UTF-8 判断密钥正确，CIPHERV2(...) 以 GCM 认证通过判断
+// 参数：
+//   - version: 加密格式，v2 或 v1
+//   - data: 括号内的加密内容
+//   - keys: 依次尝试的密钥
+//
+// 返回：
+//   - string: 明文
+//   - int: 解密成功的密钥下标
+//   - error: 所有密钥都解密失败时返回最后一个错误
+func decryptCipherValue(version, data string, keys []string) (string, int, error) {
+	var lastErr error
+	for i, key := range keys {
+		var plain string
+		var err error
+		if version == cipherVersionV2 {
+			plain, err = encrypt.AesGcmDecrypt(data, key)
+		} else {
+			plain, err = encrypt.AesEcbDecryptStrict(data, key)
+			if err == nil && !utf8.ValidString(plain) {
+				err = errors.New("解密结果不是合法的 UTF-8 文本")
+			}
+		}
+		if err == nil {
+			return plain, i, nil
+		}
+		lastErr = err
+	}
+	return "", -1, lastErr
+}
+
+// loadCipherFallbackKeys 在解密前从配置文件中读取 system.cipherFallbackKeys
+// 依次读取 paths 中存在的文件（完成环境变量和密钥文件占位符替换），后面的文件中非空的配置覆盖前面的文件；
+// 读取或解析失败的文件跳过，由之后的正式加载报告错误
+// 参数：
+//   - paths: 配置文件路径，通常为默认配置文件和环境配置文件
+//
+// 返回：
+//   - []string: 备用密钥
+func loadCipherFallbackKeys(paths ...string) []string {
+	var keys []string
+	for _, path := range paths {
+		fileData, err := loadYamlFile(path)
+		if err != nil {
+			continue
+		}
+		if fileData, err = replaceWithEvn(fileData); err != nil {
+			continue
+		}
+		var holder struct {
+			System struct {
+				CipherFallbackKeys []string `yaml:"cipherFallbackKeys"`
+			} `yaml:"system"`
+		}
+		if yaml.Unmarshal(fileData, &holder) == nil && len(holder.System.CipherFallbackKeys) > 0 {
+			keys = holder.System.CipherFallbackKeys
+		}
+	}
+	return keys
+}
+
+// runCipherCommand 执行 -encrypt-value / -rotate-cipher 模式，将结果写入 out
+// 参数：
+//   - cmdArgs: 命令行参数
+//   - in: -encrypt-value 模式下未指定 -value 时读取明文的输入
+//   - out: 输出目标
+//
+// 返回：
+//   - int: 进程退出码，成功为 0
+func runCipherCommand(cmdArgs *CmdArgs, in io.Reader, out io.Writer) int {
+	if cmdArgs.RotateCipher {
+		if cmdArgs.OldKey == "" || cmdArgs.NewKey == "" {
+			fmt.Fprintln(out, "[配置加密] -rotate-cipher 需要同时指定 -old-key 和 -new-key")
+			return 1
+		}
+		if err := rotateCipherFiles(cmdArgs.Config, cmdArgs.OldKey, cmdArgs.NewKey, out); err != nil {
+			fmt.Fprintf(out, "[配置加密] 轮换失败: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if cmdArgs.CipherKey == "" {
+		fmt.Fprintln(out, "[配置加密] -encrypt-value 需要通过 -cipherKey 指定加密密钥")
+		return 1
+	}
+	plainText := cmdArgs.Value
+	if plainText == "" {
+		data, err := io.ReadAll(in)
+		if err != nil {
+			fmt.Fprintf(out, "[配置加密] 读取标准输入失败: %v\n", err)
+			return 1
+		}
+		plainText = strings.TrimRight(string(data), "\r\n")
+	}
+	value, err := encryptCipherValue(plainText, cmdArgs.CipherKey, cmdArgs.CipherVersion)
+	if err != nil {
+		fmt.Fprintf(out, "[配置加密] 加密失败: %v\n", err)
+		return 1
+	}
+	fmt.Fprintln(out, value)
+	return 0
+}
+
+// rotateCipherFiles 将目录（含子目录）中 .yml / .yaml 文件的加密内容从 oldKey 轮换为 newKey
+// 先处理全部文件，任一加密内容无法用 oldKey 解密时返回错误且不修改任何文件；
+// 之后逐个文件写入临时文件再重命名替换，加密格式保持不变，加密内容以外的字节保持原样，不含加密内容的文件不写入
+// 参数：
+//   - dir: 配置文件目录
+//   - oldKey: 旧密钥
+//   - newKey: 新密钥
+//   - out: 输出每个文件轮换的加密内容数量
+//
+// 返回：
+//   - error: 遍历、解密、加密或写入失败时返回错误
+func rotateCipherFiles(dir, oldKey, newKey string, out io.Writer) error {
+	rotated := make(map[string][]byte)
+	counts := make(map[string]int)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if d.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		newData, count, err := rotateCipherContent(data, oldKey, newKey)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if count > 0 {
+			rotated[path] = newData
+			counts[path] = count
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(rotated))
+	for path := range rotated {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := writeFileAtomic(path, rotated[path]); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		fmt.Fprintf(out, "[配置加密] %s: 已轮换 %d 个加密内容\n", path, counts[path])
+	}
+	fmt.Fprintf(out, "[配置加密] 轮换完成, 共修改 %d 个文件\n", len(paths))
+	return nil
+}
+
+// rotateCipherContent 将内容中的每个 CIPHER(...) / CIPHERV2(...) 用 oldKey 解密后以相同格式用 newKey 重新加密
+// 返回替换后的内容和轮换的加密内容数量，加密内容以外的字节保持原样
+func rotateCipherContent(data []byte, oldKey, newKey string) ([]byte, int, error) {
+	content := string(data)
+	matches := cipherExpr.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return data, 0, nil
+	}
+
+	var builder strings.Builder
+	last := 0
+	for _, loc := range matches {
+		match := content[loc[0]:loc[1]]
+		version := cipherVersionV1
+		if loc[2] >= 0 {
+			version = cipherVersionV2
+		}
+		plain, _, err := decryptCipherValue(version, content[loc[4]:loc[5]], []string{oldKey})
+		if err != nil {
+			return nil, 0, fmt.Errorf("使用旧密钥解密 %s 失败: %w", match, err)
+		}
+		value, err := encryptCipherValue(plain, newKey, version)
+		if err != nil {
+			return nil, 0, fmt.Errorf("使用新密钥加密失败: %w", err)
+		}
+		builder.WriteString(content[last:loc[0]])
+		builder.WriteString(value)
+		last = loc[1]
+	}
+	builder.WriteString(content[last:])
+	return []byte(builder.String()), len(matches), nil
+}
+
+// writeFileAtomic 在同一目录写入临时文件后重命名替换 path，保持原文件的权限
+func writeFileAtomic(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,210 @@
+// Package core 配置加密测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 CIPHER(...) / CIPHERV2(...) 加密配置的单元测试，使用临时目录中的配置文件。
+//
+// 测试覆盖内容：
+// 1. -encrypt-value 加密后由 decryptConfig 解密，v1 / v2 格式往返一致
+// 2. 备用密钥 - system.cipherFallbackKeys 中的密钥可解密旧密钥加密的内容
+// 3. CIPHERV2 篡改检测 - 密文被篡改时解密失败
+// 4. -rotate-cipher - 轮换后加密内容以外的字节保持不变，旧密钥错误时不修改文件
+//
+// 运行测试：go test -v ./core/... -run Cipher
+// ==================================================
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOldCipherKey = "old-key-12345678"
+	testNewCipherKey = "new-key-12345678"
+)
+
+// useCipherFallbackKeys 设置备用密钥，测试结束后恢复
+func useCipherFallbackKeys(t *testing.T, keys ...string) {
+	original := cipherFallbackKeys
+	cipherFallbackKeys = keys
+	t.Cleanup(func() { cipherFallbackKeys = original })
+}
+
+// TestRunCipherCommand_EncryptValue 测试 -encrypt-value 模式
+//
+// 【功能点】验证从 -value 或标准输入读取明文，输出的 CIPHERV2(...) / CIPHER(...) 可由 decryptConfig 解密
+// 【测试流程】
+//  1. 分别以默认格式、v2、v1 加密，明文来自标准输入（去掉末尾换行符）或 -value
+//  2. 将输出写入配置内容后解密，断言得到原始明文
+//  3. 未指定 -cipherKey、格式不支持时返回退出码 1
+func TestRunCipherCommand_EncryptValue(t *testing.T) {
+	useCipherFallbackKeys(t)
+	cases := []struct {
+		version string
+		value   string
+		stdin   string
+		prefix  string
+	}{
+		{version: "", stdin: "p@ss: word\n", prefix: "CIPHERV2("},
+		{version: "v2", value: "p@ss: word", prefix: "CIPHERV2("},
+		{version: "v1", stdin: "p@ss: word\r\n", prefix: "CIPHER("},
+	}
+	for _, c := range cases {
+		var out bytes.Buffer
+		code := runCipherCommand(&CmdArgs{EncryptValue: true, CipherKey: testNewCipherKey, Value: c.value, CipherVersion: c.version}, strings.NewReader(c.stdin), &out)
+		require.Equal(t, 0, code, out.String())
+		value := strings.TrimSpace(out.String())
+		assert.True(t, strings.HasPrefix(value, c.prefix), value)
+
+		result, err := decryptConfig([]byte("password: \""+value+"\"\n"), testNewCipherKey)
+		require.NoError(t, err)
+		assert.Equal(t, "password: \"p@ss: word\"\n", string(result))
+	}
+
+	var out bytes.Buffer
+	assert.Equal(t, 1, runCipherCommand(&CmdArgs{EncryptValue: true, Value: "x"}, strings.NewReader(""), &out))
+	assert.Contains(t, out.String(), "-cipherKey")
+	assert.Equal(t, 1, runCipherCommand(&CmdArgs{EncryptValue: true, CipherKey: testNewCipherKey, Value: "x", CipherVersion: "v3"}, strings.NewReader(""), &out))
+	assert.Equal(t, 1, runCipherCommand(&CmdArgs{RotateCipher: true, OldKey: testOldCipherKey}, strings.NewReader(""), &out))
+}
+
+// TestDecryptConfig_FallbackKeys 测试使用备用密钥解密
+//
+// 【功能点】验证主密钥无法解密时依次尝试备用密钥，v1 / v2 格式均支持
+// 【测试流程】
+//  1. 使用旧密钥加密两种格式的内容
+//  2. 没有备用密钥时使用新密钥解密，断言返回错误
+//  3. 备用密钥包含旧密钥时解密成功；未指定主密钥时也使用备用密钥解密
+func TestDecryptConfig_FallbackKeys(t *testing.T) {
+	v1, err := encryptCipherValue("legacy-pass", testOldCipherKey, cipherVersionV1)
+	require.NoError(t, err)
+	v2, err := encryptCipherValue("gcm-pass", testOldCipherKey, cipherVersionV2)
+	require.NoError(t, err)
+	yamlData := []byte("a: " + v1 + "\nb: " + v2 + "\n")
+
+	useCipherFallbackKeys(t)
+	_, err = decryptConfig(yamlData, testNewCipherKey)
+	assert.Error(t, err)
+
+	useCipherFallbackKeys(t, "unused-key-12345", testOldCipherKey)
+	for _, primary := range []string{testNewCipherKey, ""} {
+		result, err := decryptConfig(yamlData, primary)
+		require.NoError(t, err)
+		assert.Equal(t, "a: legacy-pass\nb: gcm-pass\n", string(result))
+	}
+}
+
+// TestDecryptConfig_CipherV2Tampered 测试 CIPHERV2 的篡改检测
+//
+// 【功能点】验证 CIPHERV2 密文被篡改时解密返回错误，而不是得到错误的明文
+// 【测试流程】
+//  1. 加密后翻转密文中的一个字节
+//  2. 解密篡改后的内容，断言返回错误
+func TestDecryptConfig_CipherV2Tampered(t *testing.T) {
+	useCipherFallbackKeys(t)
+	value, err := encryptCipherValue("gcm-pass", testNewCipherKey, cipherVersionV2)
+	require.NoError(t, err)
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(value, "CIPHERV2("), ")"))
+	require.NoError(t, err)
+	sealed[len(sealed)-1] ^= 0x01
+
+	result, err := decryptConfig([]byte("password: CIPHERV2("+base64.StdEncoding.EncodeToString(sealed)+")\n"), testNewCipherKey)
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+// TestLoadCipherFallbackKeys 测试读取备用密钥
+//
+// 【功能点】验证从配置文件读取 system.cipherFallbackKeys，支持环境变量占位符，后面文件的配置覆盖前面的文件
+// 【测试流程】
+//  1. 默认配置文件和环境配置文件分别配置备用密钥，环境配置文件使用 {{ENV}} 占位符
+//  2. 断言只读取默认配置文件时得到默认配置中的密钥，同时读取时得到环境配置中的密钥
+//  3. 文件不存在时返回空
+func TestLoadCipherFallbackKeys(t *testing.T) {
+	t.Setenv("TEST_CIPHER_FALLBACK_KEY", testOldCipherKey)
+	dir := writeConfigFiles(t, map[string]string{
+		"config.default.yml": "system:\n  cipherFallbackKeys:\n    - default-key-1234\npassword: CIPHER(abc)\n",
+		"config.prod.yml":    "system:\n  cipherFallbackKeys:\n    - \"{{TEST_CIPHER_FALLBACK_KEY}}\"\n",
+	})
+
+	defaultPath, prodPath := filepath.Join(dir, "config.default.yml"), filepath.Join(dir, "config.prod.yml")
+	assert.Equal(t, []string{"default-key-1234"}, loadCipherFallbackKeys(defaultPath))
+	assert.Equal(t, []string{testOldCipherKey}, loadCipherFallbackKeys(defaultPath, prodPath))
+	assert.Empty(t, loadCipherFallbackKeys(filepath.Join(dir, "missing.yml")))
+}
+
+// TestRotateCipherFiles 测试轮换配置目录中的加密内容
+//
+// 【功能点】验证轮换后加密内容可用新密钥解密、格式不变，其余字节（注释、CRLF 换行、引号）保持不变，不含加密内容的文件不写入
+// 【测试流程】
+//  1. 在目录和子目录中写入含 CIPHER / CIPHERV2 的配置文件、不含加密内容的配置文件和非 YAML 文件
+//  2. 使用错误的旧密钥轮换，断言返回错误且文件未修改
+//  3. 使用正确的旧密钥轮换，断言将加密内容替换为占位符后与原内容一致，且可用新密钥解密
+//  4. 断言不含加密内容的文件和非 YAML 文件未修改
+func TestRotateCipherFiles(t *testing.T) {
+	useCipherFallbackKeys(t)
+	v1, err := encryptCipherValue("legacy-pass", testOldCipherKey, cipherVersionV1)
+	require.NoError(t, err)
+	v2, err := encryptCipherValue("gcm-pass", testOldCipherKey, cipherVersionV2)
+	require.NoError(t, err)
+
+	original := map[string]string{
+		"config.default.yml": "# 数据库配置\r\ndb:\r\n  password: \"" + v1 + "\"   # 行尾注释\r\n  host:   localhost\r\n",
+		"common/redis.yaml":  "redis:\n  password: '" + v2 + "'\n\n  extra: [" + v1 + ", " + v2 + "]\n",
+		"config.test.yml":    "service:\n  port: 8080\n",
+		"notes.txt":          "CIPHER(" + v1 + ")\n",
+	}
+	dir := writeConfigFiles(t, original)
+	readAll := func() map[string]string {
+		files := make(map[string]string, len(original))
+		for name := range original {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			require.NoError(t, err)
+			files[name] = string(data)
+		}
+		return files
+	}
+
+	var out bytes.Buffer
+	assert.Error(t, rotateCipherFiles(dir, "wrong-key-123456", testNewCipherKey, &out))
+	assert.Equal(t, original, readAll())
+
+	out.Reset()
+	require.NoError(t, rotateCipherFiles(dir, testOldCipherKey, testNewCipherKey, &out))
+	assert.Contains(t, out.String(), "共修改 2 个文件")
+	rotated := readAll()
+
+	mask := func(s string) string {
+		return cipherExpr.ReplaceAllStringFunc(s, func(match string) string {
+			if strings.HasPrefix(match, "CIPHERV2(") {
+				return "CIPHERV2(*)"
+			}
+			return "CIPHER(*)"
+		})
+	}
+	for _, name := range []string{"config.default.yml", "common/redis.yaml"} {
+		assert.NotEqual(t, original[name], rotated[name])
+		assert.Equal(t, mask(original[name]), mask(rotated[name]))
+	}
+	assert.Equal(t, original["config.test.yml"], rotated["config.test.yml"])
+	assert.Equal(t, original["notes.txt"], rotated["notes.txt"])
+
+	result, err := decryptConfig([]byte(rotated["common/redis.yaml"]), testNewCipherKey)
+	require.NoError(t, err)
+	assert.Equal(t, "redis:\n  password: 'gcm-pass'\n\n  extra: [legacy-pass, gcm-pass]\n", string(result))
+	_, err = decryptConfig([]byte(rotated["config.default.yml"]), testOldCipherKey)
+	assert.Error(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.False(t, strings.Contains(entry.Name(), ".tmp-"), "临时文件应被重命名: %s", entry.Name())
+	}
+}
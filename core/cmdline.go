@@ -9,9 +9,22 @@ import (
 )
 
 type CmdArgs struct {
-	Env       string
-	Config    string
-	CipherKey string
+	Env            string
+	Config         string
+	CipherKey      string
+	ValidateConfig bool   // 仅加载并校验配置，输出校验报告后退出，不启动服务
+	PrintRoutes    bool   // 输出已注册的路由列表后退出，不启动服务
+	RoutesFormat   string // 路由列表输出格式：table / json，默认 table
+	Migrate        bool   // 执行待执行的数据库迁移后退出，不启动服务
+	Rollback       int    // 回滚最近 N 个已执行的数据库迁移后退出，不启动服务
+	Seed           bool   // 强制执行与运行环境匹配的数据填充后退出，不启动服务
+	SeedFresh      bool   // 清空数据填充声明的表后强制执行数据填充并退出，不启动服务
+	EncryptValue   bool   // 使用 CipherKey 加密 Value（为空时从标准输入读取），输出 CIPHERV2(...) / CIPHER(...) 后退出，不加载配置
+	Value          string // -encrypt-value 模式下待加密的明文
+	CipherVersion  string // -encrypt-value 模式下的加密格式：v2（AES-GCM，CIPHERV2）/ v1（AES-ECB，CIPHER），默认 v2
+	RotateCipher   bool   // 将配置目录中的加密内容从 OldKey 轮换为 NewKey 后退出，不加载配置
+	OldKey         string // -rotate-cipher 模式下的旧密钥
+	NewKey         string // -rotate-cipher 模式下的新密钥
 }
 
 func parseCmdArgs() (*CmdArgs, error) {
@@ -20,6 +33,19 @@ func parseCmdArgs() (*CmdArgs, error) {
 	argv.StringVar(&info.Env, "env", "", "运行环境，dev, test, prod等， 默认dev")
 	argv.StringVar(&info.Config, "config", constant.DefaultConfigDirPath, "配置文件路径，默认./conf")
 	argv.StringVar(&info.CipherKey, "cipherKey", "", "加密key, 配置文件加密时使用")
+	argv.BoolVar(&info.ValidateConfig, "validate-config", false, "仅校验配置文件, 输出校验报告后退出, 不启动服务")
+	argv.BoolVar(&info.PrintRoutes, "print-routes", false, "输出已注册的路由列表后退出, 不启动服务")
+	argv.StringVar(&info.RoutesFormat, "routes-format", "", "路由列表输出格式, table 或 json, 默认table")
+	argv.BoolVar(&info.Migrate, "migrate", false, "执行待执行的数据库迁移后退出, 不启动服务")
+	argv.IntVar(&info.Rollback, "rollback", 0, "回滚最近N个已执行的数据库迁移后退出, 不启动服务")
+	argv.BoolVar(&info.Seed, "seed", false, "强制执行与运行环境匹配的数据填充后退出, 不启动服务")
+	argv.BoolVar(&info.SeedFresh, "seed-fresh", false, "清空数据填充声明的表后强制执行数据填充并退出, 不启动服务")
+	argv.BoolVar(&info.EncryptValue, "encrypt-value", false, "使用cipherKey加密明文, 输出加密内容后退出, 不启动服务")
+	argv.StringVar(&info.Value, "value", "", "待加密的明文, 为空时从标准输入读取")
+	argv.StringVar(&info.CipherVersion, "cipher-version", "", "加密格式, v2(AES-GCM) 或 v1(AES-ECB), 默认v2")
+	argv.BoolVar(&info.RotateCipher, "rotate-cipher", false, "将配置目录中的加密内容从旧密钥轮换为新密钥后退出, 不启动服务")
+	argv.StringVar(&info.OldKey, "old-key", "", "轮换加密内容时的旧密钥")
+	argv.StringVar(&info.NewKey, "new-key", "", "轮换加密内容时的新密钥")
 	if !argv.Parsed() {
 		_ = argv.Parse(os.Args[1:])
 	}
@@ -11,11 +11,19 @@
 // 5. 配置路径 - -config 参数解析
 // 6. 解密密钥 - -cipherKey 参数解析
 // 7. 组合参数 - 多参数组合使用
+// 8. 配置校验 - -validate-config 参数解析
+// 9. 路由列表 - -print-routes、-routes-format 参数解析
+// 10. 数据库迁移 - -migrate、-rollback 参数解析
 //
 // 支持的参数：
 //   -env        环境标识（如 dev、test、prod）
 //   -config     配置文件目录路径
 //   -cipherKey  配置加密密钥
+//   -validate-config  仅校验配置后退出
+//   -print-routes     输出路由列表后退出
+//   -routes-format    路由列表输出格式（table、json）
+//   -migrate          执行数据库迁移后退出
+//   -rollback         回滚最近 N 个数据库迁移后退出
 //
 // 运行测试：go test -v ./core/... -run CmdArgs
 // ==================================================
@@ -121,6 +129,47 @@ func TestParseCmdArgs(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "with validate-config parameter",
+			args: []string{"program", "-env", "prod", "-validate-config"},
+			expected: &CmdArgs{
+				Env:            "prod",
+				Config:         "./conf", // 默认值
+				CipherKey:      "",
+				ValidateConfig: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "with print-routes parameter",
+			args: []string{"program", "-print-routes", "-routes-format", "json"},
+			expected: &CmdArgs{
+				Config:       "./conf", // 默认值
+				PrintRoutes:  true,
+				RoutesFormat: "json",
+			},
+			wantErr: false,
+		},
+		{
+			name: "with migrate parameters",
+			args: []string{"program", "-migrate", "-rollback", "2"},
+			expected: &CmdArgs{
+				Config:   "./conf", // 默认值
+				Migrate:  true,
+				Rollback: 2,
+			},
+			wantErr: false,
+		},
+		{
+			name: "with seed parameters",
+			args: []string{"program", "-seed", "-seed-fresh"},
+			expected: &CmdArgs{
+				Config:    "./conf", // 默认值
+				Seed:      true,
+				SeedFresh: true,
+			},
+			wantErr: false,
+		},
 		{
 			name: "with empty values",
 			args: []string{"program", "-env", "", "-config", "", "-cipherKey", ""},
@@ -7,13 +7,13 @@ import (
 	"io"
 	"os"
 	"path"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/zzsen/gin_core/utils/encrypt"
 	"gopkg.in/yaml.v3"
 
 	"github.com/zzsen/gin_core/app"
@@ -29,15 +29,21 @@ func InitCustomConfig(conf any) {
 	app.Config = conf
 }
 
+// configSources 每个顶层配置项依次来自的配置文件，按合并顺序排列，后面的文件覆盖前面文件中相同的配置项
+// 由 loadYamlConfig 记录，通过配置查看接口输出
+var configSources = map[string][]string{}
+
 // loadConfig 加载配置文件
 // 这是配置加载的主入口函数，负责：
 // 1. 解析命令行参数
 // 2. 确定运行环境
 // 3. 加载默认配置文件
 // 4. 加载环境特定的配置文件
-// 5. 设置Gin运行模式
+// 5. 使用环境变量覆盖配置项（见 applyEnvOverrides）
+// 6. 设置Gin运行模式
 // 参数 conf: 用户自定义的配置结构体指针
-func loadConfig(conf any) {
+// 返回值: 解析后的命令行参数
+func loadConfig(conf any) *CmdArgs {
 	// 使用defer+recover确保配置加载失败时程序能优雅退出
 	defer func() {
 		if err := recover(); err != nil {
@@ -48,6 +54,7 @@ func loadConfig(conf any) {
 
 	// 解析命令行参数获取环境和配置路径
 	cmdArgs, err := parseCmdArgs()
+	configSources = map[string][]string{}
 
 	// 如果命令行未指定环境，尝试从env文件读取
 	if cmdArgs.Env == "" {
@@ -75,8 +82,22 @@ func loadConfig(conf any) {
 		os.Exit(1)
 	}
 
-	// 构建默认配置文件路径并加载
+	// -encrypt-value / -rotate-cipher 模式：加密明文或轮换配置目录中的加密内容后退出，不加载配置文件
+	if cmdArgs.EncryptValue || cmdArgs.RotateCipher {
+		os.Exit(runCipherCommand(cmdArgs, os.Stdin, os.Stdout))
+	}
+
+	// 解密前读取备用密钥，环境配置文件中的配置覆盖默认配置文件
 	defaultConfigFilePath := path.Join(cmdArgs.Config, constant.DefaultConfigFileName)
+	customConfigFileName := fmt.Sprintf("%s%s%s", constant.CustomConfigFileNamePrefix, cmdArgs.Env, constant.CustomConfigFileNameSuffix)
+	customConfigFilePath := path.Join(cmdArgs.Config, customConfigFileName)
+	if cmdArgs.Env != constant.DefaultEnv {
+		cipherFallbackKeys = loadCipherFallbackKeys(defaultConfigFilePath, customConfigFilePath)
+	} else {
+		cipherFallbackKeys = loadCipherFallbackKeys(defaultConfigFilePath)
+	}
+
+	// 构建默认配置文件路径并加载
 	if fileUtil.PathExists(defaultConfigFilePath) {
 		// 如果有默认文件，则先加载默认配置文件
 		// 默认配置提供基础配置，后续的环境配置会覆盖相同的配置项
@@ -89,8 +110,6 @@ func loadConfig(conf any) {
 
 	// 如果不是默认环境，加载环境特定的配置文件
 	if cmdArgs.Env != constant.DefaultEnv {
-		customConfigFileName := fmt.Sprintf("%s%s%s", constant.CustomConfigFileNamePrefix, cmdArgs.Env, constant.CustomConfigFileNameSuffix)
-		customConfigFilePath := path.Join(cmdArgs.Config, customConfigFileName)
 		if !fileUtil.PathExists(customConfigFilePath) {
 			// 如果没有自定义配置文件，程序无法继续运行
 			logger.Error("[配置解析] 加载自定义配置失败, 配置文件目录%s下, 不存在自定义配置文件%s", cmdArgs.Config, customConfigFilePath)
@@ -104,8 +123,14 @@ func loadConfig(conf any) {
 			os.Exit(1)
 		}
 	}
+	// 最后使用环境变量覆盖配置项，优先级高于所有配置文件
+	if err = loadEnvOverrides(conf); err != nil {
+		logger.Error("%s", err.Error())
+		os.Exit(1)
+	}
 	// 将确定的环境保存到全局变量
 	app.Env = cmdArgs.Env
+	return cmdArgs
 }
 
 // getEnvFromFile 从env文件中获取环境变量
@@ -173,6 +198,9 @@ func getDateTime() string {
 // 2. 环境变量替换
 // 3. 加密配置解密
 // 4. 同时加载到基础配置和自定义配置
+// 5. 通过顶层 include 字段引用并合并其他配置文件
+// 6. 加载通过 RegisterConfigSection 注册的配置段
+// 7. 在 configSources 中记录每个顶层配置项来自的文件
 // 参数：
 //   - path: 配置文件路径
 //   - conf: 自定义配置结构体指针
@@ -184,38 +212,174 @@ func loadYamlConfig(path string, conf any, CipherKey string) error {
 		return err
 	}
 
-	// 读取YAML文件内容
-	fileData, err := loadYamlFile(path)
+	// 读取YAML文件内容，并展开 include 引用的配置文件
+	// 每个文件各自完成环境变量替换和解密后再合并
+	fileData, err := loadYamlFileWithIncludes(path, CipherKey, nil, configSources)
 	if err != nil {
 		return err
 	}
 
-	// 替换配置文件中的环境变量占位符
-	// 支持 {{ENV_VAR_NAME}} 格式的占位符
-	fileData, err = replaceWithEvn(fileData)
+	// 先将配置加载到基础配置结构体
+	// 基础配置包含框架所需的所有配置项
+	err = yaml.Unmarshal(fileData, &app.BaseConfig)
 	if err != nil {
+		logger.Error("[配置解析] 加载基础配置%s失败: %s", path, err.Error())
 		return err
 	}
 
+	// 再将配置加载到用户自定义配置结构体
+	// 用户配置可能包含业务特定的配置项
+	err = yaml.Unmarshal(fileData, conf)
+	if err != nil {
+		return err
+	}
+
+	// 最后加载已注册的配置段，严格模式下配置段中的未知字段会导致加载失败
+	return loadConfigSections(fileData, app.BaseConfig.System.StrictConfigSections)
+}
+
+// readConfigFile 读取单个配置文件并完成占位符处理
+// 依次执行：读取文件内容 → 替换 {{ENV_VAR_NAME}} 环境变量和 {{file:...}} / {{env_file:...}} 密钥文件占位符 → 解密 CIPHER(...) 加密内容
+// 参数：
+//   - path: 配置文件路径
+//   - CipherKey: 解密密钥
+//
+// 返回值: 处理后的YAML内容和可能的错误
+func readConfigFile(path string, CipherKey string) ([]byte, error) {
+	fileData, err := loadYamlFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// 替换配置文件中的环境变量和密钥文件占位符
+	// 支持 {{ENV_VAR_NAME}}、{{file:PATH}}、{{env_file:DIR:KEY}} 格式的占位符
+	fileData, err = replaceWithEvn(fileData)
+	if err != nil {
+		return nil, err
+	}
+
 	// 解密配置文件中的加密内容
 	// 支持 CIPHER(encrypted_content) 格式的加密配置
-	fileData, err = decryptConfig(fileData, CipherKey)
+	return decryptConfig(fileData, CipherKey)
+}
+
+// includeHolder 用于解析配置文件顶层的 include 字段
+type includeHolder struct {
+	Include []string `yaml:"include"`
+}
+
+// loadYamlFileWithIncludes 读取配置文件并递归展开 include 引用
+// 配置文件可在顶层通过 include 字段引用其他YAML文件（路径相对于当前文件所在目录）：
+//
+//	include:
+//	  - common/base.yml
+//	  - common/middleware.yml
+//
+// 合并规则：
+// 1. 按 include 列表顺序依次合并被引用文件，最后合并当前文件自身内容
+// 2. 后合并的标量值覆盖先前的值，map 递归深度合并，列表整体替换
+// 3. 每个文件在合并前各自完成环境变量替换和解密
+// 4. 检测循环引用，并限制最大嵌套深度为 constant.MaxConfigIncludeDepth
+//
+// 参数：
+//   - path: 配置文件路径
+//   - CipherKey: 解密密钥
+//   - chain: 当前的引用链（用于循环检测），顶层调用传 nil
+//   - sources: 按合并顺序记录每个顶层配置项来自的文件，为 nil 时不记录
+//
+// 返回值: 合并后的YAML内容和可能的错误
+func loadYamlFileWithIncludes(path string, CipherKey string, chain []string, sources map[string][]string) ([]byte, error) {
+	absPath, err := filepath.Abs(path)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	chain = append(chain, absPath)
+	for _, parent := range chain[:len(chain)-1] {
+		if parent == absPath {
+			return nil, fmt.Errorf("[配置解析] 检测到配置文件循环引用: %s", strings.Join(chain, " -> "))
+		}
+	}
+	if len(chain) > constant.MaxConfigIncludeDepth {
+		return nil, fmt.Errorf("[配置解析] 配置文件引用层级超过上限 %d: %s", constant.MaxConfigIncludeDepth, strings.Join(chain, " -> "))
 	}
 
-	// 先将配置加载到基础配置结构体
-	// 基础配置包含框架所需的所有配置项
-	err = yaml.Unmarshal(fileData, &app.BaseConfig)
+	fileData, err := readConfigFile(path, CipherKey)
 	if err != nil {
-		logger.Error("[配置解析] 加载基础配置%s失败: %s", path, err.Error())
-		return err
+		return nil, err
 	}
 
-	// 再将配置加载到用户自定义配置结构体
-	// 用户配置可能包含业务特定的配置项
-	err = yaml.Unmarshal(fileData, conf)
-	return err
+	var holder includeHolder
+	if err := yaml.Unmarshal(fileData, &holder); err != nil {
+		return nil, fmt.Errorf("[配置解析] 解析配置文件%s失败: %w", path, err)
+	}
+	// 没有 include 时保持原样返回，避免不必要的序列化开销
+	if len(holder.Include) == 0 {
+		if sources != nil {
+			if err := recordConfigSources(sources, path, fileData); err != nil {
+				return nil, err
+			}
+		}
+		return fileData, nil
+	}
+
+	merged := map[string]any{}
+	for _, include := range holder.Include {
+		includePath := include
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(filepath.Dir(path), includePath)
+		}
+		includeData, err := loadYamlFileWithIncludes(includePath, CipherKey, chain, sources)
+		if err != nil {
+			return nil, err
+		}
+		includeMap := map[string]any{}
+		if err := yaml.Unmarshal(includeData, &includeMap); err != nil {
+			return nil, fmt.Errorf("[配置解析] 解析配置文件%s失败: %w", includePath, err)
+		}
+		mergeConfigMap(merged, includeMap)
+	}
+
+	own := map[string]any{}
+	if err := yaml.Unmarshal(fileData, &own); err != nil {
+		return nil, fmt.Errorf("[配置解析] 解析配置文件%s失败: %w", path, err)
+	}
+	delete(own, "include")
+	mergeConfigMap(merged, own)
+	if sources != nil {
+		for key := range own {
+			sources[key] = append(sources[key], path)
+		}
+	}
+
+	return yaml.Marshal(merged)
+}
+
+// recordConfigSources 在 sources 中记录配置文件包含的顶层配置项，include 字段除外
+func recordConfigSources(sources map[string][]string, path string, fileData []byte) error {
+	own := map[string]any{}
+	if err := yaml.Unmarshal(fileData, &own); err != nil {
+		return fmt.Errorf("[配置解析] 解析配置文件%s失败: %w", path, err)
+	}
+	for key := range own {
+		if key != "include" {
+			sources[key] = append(sources[key], path)
+		}
+	}
+	return nil
+}
+
+// mergeConfigMap 将 src 深度合并到 dst
+// 两侧均为 map 的键递归合并，其余情况（标量、列表）由 src 覆盖 dst
+func mergeConfigMap(dst, src map[string]any) {
+	for key, srcValue := range src {
+		srcMap, srcIsMap := srcValue.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeConfigMap(dstMap, srcMap)
+			continue
+		}
+		dst[key] = srcValue
+	}
 }
 
 // checkConfType 检查配置结构体类型
@@ -262,9 +426,13 @@ func loadYamlFile(path string) ([]byte, error) {
 	return data, err
 }
 
-// replaceWithEvn 使用环境变量的值替换YAML文件中的占位符
-// 支持 {{ENV_VAR_NAME}} 格式的占位符替换
-// 这允许在配置文件中使用环境变量，提高配置的灵活性
+// replaceWithEvn 使用环境变量和密钥文件的值替换YAML文件中的占位符
+// 支持以下格式的占位符，在同一次替换中处理，可以混合使用：
+//   - {{ENV_VAR_NAME}}: 环境变量的值，原样替换
+//   - {{file:/path/to/secret}}: 文件内容，去掉末尾的换行符
+//   - {{env_file:DIR:KEY}}: 文件 DIR/KEY 的内容，去掉末尾的换行符
+//
+// 密钥文件的值按占位符所在的位置转义（见 escapeSecretValue），替换后的值不会输出到日志
 // 参数 yamlData: 原始YAML内容
 // 返回值: 替换后的YAML内容和可能的错误
 func replaceWithEvn(yamlData []byte) ([]byte, error) {
@@ -272,24 +440,50 @@ func replaceWithEvn(yamlData []byte) ([]byte, error) {
 	// 定义占位符的正则表达式：{{任意内容}}
 	placeholderExpr := "{{.*?}}"
 	regexpObj, _ := regexp.Compile(placeholderExpr)
-	// 查找所有占位符
-	placeholderList := regexpObj.FindAllString(yamlStr, -1)
+	// 查找所有占位符的位置
+	indexList := regexpObj.FindAllStringIndex(yamlStr, -1)
 
-	if len(placeholderList) > 0 {
-		// 加载环境变量值
-		evnData, err := loadEvnValue(placeholderList)
-		if err != nil {
-			return nil, err
-		}
-		// 逐个替换占位符
-		for key, value := range evnData {
-			yamlStr = strings.Replace(yamlStr, key, value, -1)
+	// 如果没有占位符，直接返回原内容
+	if len(indexList) == 0 {
+		return yamlData, nil
+	}
+
+	// 加载环境变量值
+	envKeys := make([]string, 0, len(indexList))
+	for _, index := range indexList {
+		key := yamlStr[index[0]:index[1]]
+		if !isSecretPlaceholder(key[2 : len(key)-2]) {
+			envKeys = append(envKeys, key)
 		}
-		return []byte(yamlStr), nil
+	}
+	evnData, err := loadEvnValue(envKeys)
+	if err != nil {
+		return nil, err
 	}
 
-	// 如果没有占位符，直接返回原内容
-	return yamlData, nil
+	// 按位置逐个替换占位符，密钥文件的值需要根据所在位置转义
+	var b strings.Builder
+	b.Grow(len(yamlStr))
+	last := 0
+	for _, index := range indexList {
+		key := yamlStr[index[0]:index[1]]
+		value, ok := evnData[key]
+		if !ok {
+			secret, err := loadSecretValue(key[2 : len(key)-2])
+			if err != nil {
+				return nil, err
+			}
+			value, err = escapeSecretValue(yamlStr, index[0], index[1], key, secret)
+			if err != nil {
+				return nil, err
+			}
+		}
+		b.WriteString(yamlStr[last:index[0]])
+		b.WriteString(value)
+		last = index[1]
+	}
+	b.WriteString(yamlStr[last:])
+	return []byte(b.String()), nil
 }
 
 // loadEvnValue 从环境变量中加载占位符对应的值
@@ -316,8 +510,9 @@ func loadEvnValue(keys []string) (map[string]string, error) {
 }
 
 // decryptConfig 解密配置文件中的加密内容
-// 支持 CIPHER(encrypted_content) 格式的加密配置解密
+// 支持 CIPHER(encrypted_content)（AES-ECB）和 CIPHERV2(encrypted_content)（AES-GCM）格式的加密配置解密
 // 这允许在配置文件中存储敏感信息（如密码、密钥等）
+// 依次尝试 CipherKey 和 system.cipherFallbackKeys 中的备用密钥，使用备用密钥解密时输出警告日志
 // 参数：
 //   - yamlData: 原始YAML内容
 //   - CipherKey: 解密密钥
@@ -325,11 +520,8 @@ func loadEvnValue(keys []string) (map[string]string, error) {
 // 返回值: 解密后的YAML内容和可能的错误
 func decryptConfig(yamlData []byte, CipherKey string) ([]byte, error) {
 	yamlStr := string(yamlData)
-	// 定义加密内容的正则表达式：CIPHER(加密内容)
-	placeholderExpr := `CIPHER\((.*?)\)`
-	regexpObj := regexp.MustCompile(placeholderExpr)
 	// 查找所有加密占位符，返回完整匹配和分组匹配
-	placeholderList := regexpObj.FindAllStringSubmatch(yamlStr, -1)
+	placeholderList := cipherExpr.FindAllStringSubmatch(yamlStr, -1)
 
 	// 如果没有加密内容，直接返回
 	if len(placeholderList) == 0 {
@@ -337,7 +529,8 @@ func decryptConfig(yamlData []byte, CipherKey string) ([]byte, error) {
 	}
 
 	// 如果有加密内容但没有提供解密密钥
-	if CipherKey == "" {
+	keys := cipherKeyList(CipherKey)
+	if len(keys) == 0 {
 		// 仅输出警告日志，不中断服务
 		// 这样可以避免在某些环境下确实不需要解密时导致的服务启动失败
 		logger.Error("[配置解析] 配置中含加密内容, 但服务启动指令中不含解密key, 请检查配置或启动指令")
@@ -345,21 +538,31 @@ func decryptConfig(yamlData []byte, CipherKey string) ([]byte, error) {
 	}
 
 	// 逐个解密加密内容
+	fallbackCount := 0
 	for _, placeholder := range placeholderList {
-		// placeholder[0] 是完整匹配 CIPHER(...)
-		// placeholder[1] 是括号内的加密内容
-		if len(placeholder) != 2 {
+		// placeholder[0] 是完整匹配 CIPHER(...) / CIPHERV2(...)
+		// placeholder[1] 是 V2 标记，placeholder[2] 是括号内的加密内容
+		if len(placeholder) != 3 {
 			return nil, errors.New("无效占位符:" + placeholder[0])
 		}
-		// 使用AES ECB模式解密
-		data, err := encrypt.AesEcbDecrypt(placeholder[1], CipherKey)
+		version := cipherVersionV1
+		if placeholder[1] != "" {
+			version = cipherVersionV2
+		}
+		data, keyIndex, err := decryptCipherValue(version, placeholder[2], keys)
 		if err != nil {
 			return nil, err
 		}
+		if keys[keyIndex] != CipherKey {
+			fallbackCount++
+		}
 
 		// 将加密占位符替换为解密后的明文
 		yamlStr = strings.Replace(yamlStr, placeholder[0], data, -1)
 	}
+	if fallbackCount > 0 {
+		logger.Warn("[配置解析] %d 个加密内容使用备用密钥解密, 请使用 -rotate-cipher 轮换为当前密钥", fallbackCount)
+	}
 
 	return []byte(yamlStr), nil
 }
@@ -0,0 +1,413 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+)
+
+// envPathSeparator 环境变量名称中配置项路径的分隔符
+const envPathSeparator = "__"
+
+// durationType time.Duration 的类型，按 time.ParseDuration 解析
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// yamlUnmarshalerType yaml.Unmarshaler 接口的类型，实现该接口的字段按 YAML 解析
+var yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// envOverrideTarget 环境变量覆盖的目标配置
+type envOverrideTarget struct {
+	section string        // 配置段名称，为空时为基础配置或自定义配置
+	value   reflect.Value // 结构体的值
+}
+
+// envPathMiss 配置项路径查找失败的位置
+type envPathMiss struct {
+	depth      int      // 查找失败的路径段的下标
+	candidates []string // 查找失败处可用的配置项的完整 yaml 路径
+}
+
+// applyEnvOverrides 使用环境变量覆盖已加载的配置，在所有配置文件加载完成后调用，优先级高于配置文件
+// 名称以 prefix 开头的环境变量，去掉前缀后以 "__" 分隔为配置项路径，逐段与 yaml 字段名匹配（不区分大小写，忽略下划线），
+// 如 GIN_CORE_SERVICE__PORT=9000 覆盖 service.port，GIN_CORE_DB_LIST__0__HOST 覆盖 dbList 第 1 项的 host。
+// 同时作用于基础配置、自定义配置和通过 RegisterConfigSection 注册的配置段，路径在其中任一处存在即生效；
+// 脱敏字段同样可以覆盖，日志中不输出环境变量的值。
+//
+// 值按字段类型转换：
+//   - 字符串原样使用；布尔值、整数、浮点数按 strconv 解析；time.Duration 按 time.ParseDuration 解析
+//   - 切片按逗号分隔后逐项转换，以 [ 开头时按 YAML 流式序列解析
+//   - 结构体、map 及实现 yaml.Unmarshaler 的类型按 YAML 解析
+//
+// 参数：
+//   - environ: 环境变量列表，格式同 os.Environ
+//   - prefix: 环境变量前缀
+//   - conf: 自定义配置结构体指针，可为 nil
+//
+// 返回：
+//   - error: 值无法转换为字段类型时返回错误，错误信息不包含环境变量的值；路径不存在时只输出警告日志
+func applyEnvOverrides(environ []string, prefix string, conf any) error {
+	if prefix == "" {
+		return nil
+	}
+	targets := []envOverrideTarget{{value: reflect.ValueOf(&app.BaseConfig).Elem()}}
+	if conf != nil && checkConfType(conf) == nil && conf != any(&app.BaseConfig) {
+		targets = append(targets, envOverrideTarget{value: reflect.ValueOf(conf).Elem()})
+	}
+	configSectionRegistry.mu.RLock()
+	for name, target := range configSectionRegistry.sections {
+		targets = append(targets, envOverrideTarget{section: name, value: reflect.ValueOf(target).Elem()})
+	}
+	configSectionRegistry.mu.RUnlock()
+
+	// 按名称排序，多个环境变量指向同一配置项时结果稳定
+	env := map[string]string{}
+	names := make([]string, 0)
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+		env[name] = value
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		segments := strings.Split(name[len(prefix):], envPathSeparator)
+		var applied []string
+		var nearest *envPathMiss
+		for _, target := range targets {
+			path, root := segments, []string(nil)
+			if target.section != "" {
+				if normalizeEnvKey(path[0]) != normalizeEnvKey(target.section) {
+					nearest = nearerEnvMiss(nearest, &envPathMiss{candidates: []string{target.section}})
+					continue
+				}
+				path, root = path[1:], []string{target.section}
+			}
+			if _, _, miss := walkEnvPath(target.value, path, root, false); miss != nil {
+				nearest = nearerEnvMiss(nearest, miss)
+				continue
+			}
+			field, yamlPath, _ := walkEnvPath(target.value, path, root, true)
+			if err := setEnvValue(field, env[name]); err != nil {
+				return fmt.Errorf("[配置解析] 环境变量 %s 的值无法转换为 %s 类型: %w", name, field.Type(), err)
+			}
+			applied = append(applied, strings.Join(yamlPath, "."))
+			configSources[yamlPath[0]] = append(configSources[yamlPath[0]], "env:"+name)
+		}
+		if len(applied) == 0 {
+			logger.Warn("[配置解析] 环境变量 %s 对应的配置项 %s 不存在%s", name, strings.Join(segments, "."), suggestEnvPath(segments, nearest))
+			continue
+		}
+		logger.Info("[配置解析] 环境变量 %s 覆盖配置项 %s", name, strings.Join(uniqueStrings(applied), ", "))
+	}
+	return nil
+}
+
+// walkEnvPath 按路径段在结构体中查找字段
+// alloc 为 false 时只查找，不修改配置（nil 指针按零值继续查找）；为 true 时为路径上的 nil 指针分配内存
+// 参数：
+//   - v: 结构体的值
+//   - segments: 路径段
+//   - root: v 在配置中的 yaml 路径，基础配置和自定义配置为空，配置段为配置段名称
+//
+// 返回：
+//   - reflect.Value: 找到的字段，可设置
+//   - []string: 字段的 yaml 路径
+//   - *envPathMiss: 路径不存在时返回查找失败的位置
+func walkEnvPath(v reflect.Value, segments []string, root []string, alloc bool) (reflect.Value, []string, *envPathMiss) {
+	resolved := append([]string{}, root...)
+	for depth, segment := range segments {
+		v = derefEnvValue(v, alloc)
+		switch v.Kind() {
+		case reflect.Struct:
+			field, name, ok := findEnvField(v, segment, alloc)
+			if !ok {
+				var candidates []string
+				for _, name := range envFieldNames(v.Type()) {
+					candidates = append(candidates, strings.Join(append(append([]string{}, resolved...), name), "."))
+				}
+				return reflect.Value{}, nil, &envPathMiss{depth: len(root) + depth, candidates: candidates}
+			}
+			v = field
+			resolved = append(resolved, name)
+		case reflect.Slice, reflect.Array:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= v.Len() {
+				return reflect.Value{}, nil, &envPathMiss{depth: len(root) + depth}
+			}
+			v = v.Index(index)
+			resolved = append(resolved, segment)
+		default:
+			// map 的键区分大小写，无法从环境变量名称中还原，不支持按键覆盖
+			return reflect.Value{}, nil, &envPathMiss{depth: len(root) + depth}
+		}
+	}
+	return v, resolved, nil
+}
+
+// derefEnvValue 解引用指针；nil 指针在 alloc 为 true 时分配内存，否则返回零值的临时副本
+func derefEnvValue(v reflect.Value, alloc bool) reflect.Value {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if !alloc {
+				return reflect.New(v.Type().Elem()).Elem()
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// findEnvField 按 yaml 字段名查找结构体字段（不区分大小写，忽略下划线），展开 ,inline 的字段
+func findEnvField(v reflect.Value, segment string, alloc bool) (reflect.Value, string, bool) {
+	key := normalizeEnvKey(segment)
+	if key == "" {
+		return reflect.Value{}, "", false
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, inline, skip := envYamlFieldName(t.Field(i))
+		if skip {
+			continue
+		}
+		if inline {
+			if inner := derefEnvValue(v.Field(i), false); inner.Kind() == reflect.Struct {
+				if _, _, ok := findEnvField(inner, segment, false); ok {
+					return findEnvField(derefEnvValue(v.Field(i), alloc), segment, alloc)
+				}
+			}
+			continue
+		}
+		if normalizeEnvKey(name) == key {
+			return v.Field(i), name, true
+		}
+	}
+	return reflect.Value{}, "", false
+}
+
+// envFieldNames 获取结构体的 yaml 字段名，展开 ,inline 的字段
+func envFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, inline, skip := envYamlFieldName(field)
+		if skip {
+			continue
+		}
+		if inline {
+			inner := field.Type
+			for inner.Kind() == reflect.Pointer {
+				inner = inner.Elem()
+			}
+			if inner.Kind() == reflect.Struct {
+				names = append(names, envFieldNames(inner)...)
+			}
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// envYamlFieldName 按 yaml.v3 的规则获取字段名：未设置标签时为小写的字段名，未导出的字段只有内嵌且 inline 时才处理
+func envYamlFieldName(field reflect.StructField) (name string, inline, skip bool) {
+	tag := field.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "inline" {
+			inline = true
+		}
+	}
+	if !field.IsExported() && !(field.Anonymous && inline) {
+		return "", false, true
+	}
+	name = parts[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, inline, false
+}
+
+// normalizeEnvKey 将路径段或 yaml 字段名转换为比较用的形式：小写并去掉下划线
+func normalizeEnvKey(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+}
+
+// setEnvValue 将环境变量的值按字段类型转换后写入字段，返回的错误不包含值的内容
+func setEnvValue(field reflect.Value, raw string) error {
+	t := field.Type()
+	trimmed := strings.TrimSpace(raw)
+	if reflect.PointerTo(t).Implements(yamlUnmarshalerType) ||
+		(t.Kind() == reflect.Slice && strings.HasPrefix(trimmed, "[")) {
+		return setEnvYaml(field, raw)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(trimmed)
+		if err != nil {
+			return numErrorReason(err)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t == durationType {
+			d, err := time.ParseDuration(trimmed)
+			if err != nil {
+				return errors.New("无效的时长")
+			}
+			field.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(trimmed, 10, t.Bits())
+		if err != nil {
+			return numErrorReason(err)
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(trimmed, 10, t.Bits())
+		if err != nil {
+			return numErrorReason(err)
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(trimmed, t.Bits())
+		if err != nil {
+			return numErrorReason(err)
+		}
+		field.SetFloat(f)
+	case reflect.Pointer:
+		elem := reflect.New(t.Elem())
+		if err := setEnvValue(elem.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(elem)
+	case reflect.Slice:
+		var parts []string
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		slice := reflect.MakeSlice(t, len(parts), len(parts))
+		for i, part := range parts {
+			if err := setEnvValue(slice.Index(i), part); err != nil {
+				return fmt.Errorf("第 %d 项: %w", i+1, err)
+			}
+		}
+		field.Set(slice)
+	default:
+		return setEnvYaml(field, raw)
+	}
+	return nil
+}
+
+// numErrorReason 去掉 strconv 错误中的值，只保留原因（语法错误或超出范围）
+func numErrorReason(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+	return err
+}
+
+// setEnvYaml 将环境变量的值按 YAML 解析后写入字段
+func setEnvYaml(field reflect.Value, raw string) error {
+	value := reflect.New(field.Type())
+	if err := yaml.Unmarshal([]byte(raw), value.Interface()); err != nil {
+		// yaml 的错误信息可能包含值的内容，只保留错误类型
+		return errors.New("按 YAML 解析失败")
+	}
+	field.Set(value.Elem())
+	return nil
+}
+
+// nearerEnvMiss 返回匹配路径段更多的查找失败位置，匹配的路径段数相同时合并可用的配置项
+func nearerEnvMiss(a, b *envPathMiss) *envPathMiss {
+	if a == nil || b.depth > a.depth {
+		return b
+	}
+	if b.depth == a.depth {
+		a.candidates = append(a.candidates, b.candidates...)
+	}
+	return a
+}
+
+// suggestEnvPath 根据查找失败的位置给出最接近的配置项，没有接近的配置项时返回空字符串
+func suggestEnvPath(segments []string, miss *envPathMiss) string {
+	if miss == nil || miss.depth >= len(segments) || len(miss.candidates) == 0 {
+		return ""
+	}
+	key := normalizeEnvKey(segments[miss.depth])
+	best, bestDistance := "", -1
+	for _, candidate := range miss.candidates {
+		name := candidate[strings.LastIndex(candidate, ".")+1:]
+		if distance := editDistance(key, normalizeEnvKey(name)); bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if bestDistance > max(1, len(key)/3) {
+		return ""
+	}
+	return fmt.Sprintf(", 是否为 %s?", best)
+}
+
+// editDistance 计算两个字符串的编辑距离，相邻字符交换计为一次编辑
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// uniqueStrings 去掉重复的字符串，保持原有顺序
+func uniqueStrings(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	out := items[:0]
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// loadEnvOverrides 使用进程的环境变量覆盖已加载的配置，前缀为 system.envOverridePrefix
+func loadEnvOverrides(conf any) error {
+	return applyEnvOverrides(os.Environ(), app.BaseConfig.System.GetEnvOverridePrefix(), conf)
+}
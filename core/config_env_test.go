@@ -0,0 +1,239 @@
+// Package core 环境变量覆盖配置项测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含环境变量覆盖配置项的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 覆盖嵌套的整数、布尔值、时长、切片、指针和脱敏字段，路径不区分大小写并忽略下划线
+// 2. 同时覆盖自定义配置和注册的配置段，切片元素按下标覆盖
+// 3. 路径不存在时输出警告并提示最接近的配置项，不修改配置；值无法转换时返回不包含值的错误
+// 4. loadConfig 中环境变量的优先级高于环境配置文件和默认配置文件，配置来源记录环境变量
+//
+// 运行测试：go test -v ./core/... -run EnvOverride
+// ==================================================
+package core
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/constant"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// setupEnvOverrideTest 备份并在测试结束后恢复基础配置和配置来源
+func setupEnvOverrideTest(t *testing.T) {
+	originalConfig := app.BaseConfig
+	originalSources := configSources
+	t.Cleanup(func() {
+		app.BaseConfig = originalConfig
+		configSources = originalSources
+	})
+	app.BaseConfig = config.BaseConfig{}
+	configSources = map[string][]string{}
+}
+
+// TestApplyEnvOverrides_Types 测试按字段类型转换环境变量的值
+//
+// 【功能点】验证嵌套的整数、布尔值、时长、字符串切片、指针字段和脱敏字段可以被覆盖，nil 指针结构体按需创建
+// 【测试流程】
+//  1. 设置 service.port、system.useRedis、system.startupRetry.maxWait、service.trustedProxies、service.pprofPort、
+//     redis.password（脱敏字段，redis 为 nil）、rateLimit.defaultRate（RATE_LIMIT__DEFAULT_RATE 写法）、service.middlewares
+//  2. 断言各字段的值，切片按逗号分隔并去掉空白和空项
+//  3. 断言 configSources 记录环境变量，非前缀开头的环境变量被忽略
+func TestApplyEnvOverrides_Types(t *testing.T) {
+	setupEnvOverrideTest(t)
+	app.BaseConfig.Service.Port = 8080
+
+	err := applyEnvOverrides([]string{
+		"GIN_CORE_SERVICE__PORT=9000",
+		"GIN_CORE_SYSTEM__USEREDIS=true",
+		"GIN_CORE_SYSTEM__STARTUP_RETRY__MAX_WAIT=90s",
+		"GIN_CORE_SERVICE__TRUSTEDPROXIES=10.0.0.0/8, 192.168.1.1,,",
+		"GIN_CORE_SERVICE__PPROFPORT=6060",
+		"GIN_CORE_REDIS__PASSWORD=s3cret",
+		"GIN_CORE_RATE_LIMIT__DEFAULT_RATE=50",
+		"GIN_CORE_SERVICE__MIDDLEWARES=exceptionHandler,traceIdHandler",
+		"SERVICE__PORT=1",
+	}, "GIN_CORE_", nil)
+	require.NoError(t, err)
+
+	cfg := app.BaseConfig
+	assert.Equal(t, 9000, cfg.Service.Port)
+	assert.True(t, cfg.System.UseRedis)
+	assert.Equal(t, 90*time.Second, cfg.System.StartupRetry.MaxWait)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, cfg.Service.TrustedProxies)
+	require.NotNil(t, cfg.Service.PprofPort)
+	assert.Equal(t, 6060, *cfg.Service.PprofPort)
+	require.NotNil(t, cfg.Redis)
+	assert.Equal(t, "s3cret", cfg.Redis.Password)
+	assert.Equal(t, 50, cfg.RateLimit.DefaultRate)
+	assert.Equal(t, config.MiddlewareNames("exceptionHandler", "traceIdHandler"), cfg.Service.Middlewares)
+	assert.Equal(t, []string{"env:GIN_CORE_SERVICE__MIDDLEWARES", "env:GIN_CORE_SERVICE__PORT", "env:GIN_CORE_SERVICE__PPROFPORT", "env:GIN_CORE_SERVICE__TRUSTEDPROXIES"}, configSources["service"])
+}
+
+// TestApplyEnvOverrides_CustomConfigAndSection 测试覆盖自定义配置和注册的配置段
+//
+// 【功能点】验证自定义配置、inline 嵌入的基础配置、配置段和切片元素可以被覆盖
+// 【测试流程】
+//  1. 自定义配置 inline 嵌入 BaseConfig，包含 tags、servers 切片；注册 payment 配置段
+//  2. 覆盖 service.port（基础配置和自定义配置同时生效）、tags、servers[1].host、payment.timeout
+//  3. 断言各字段的值，servers 下标越界时路径不存在
+func TestApplyEnvOverrides_CustomConfigAndSection(t *testing.T) {
+	setupEnvOverrideTest(t)
+	resetConfigSections(t)
+	type server struct {
+		Host string `yaml:"host"`
+	}
+	type customConfig struct {
+		config.BaseConfig `yaml:",inline"`
+		Tags              []string `yaml:"tags"`
+		Servers           []server `yaml:"servers"`
+	}
+	type paymentConfig struct {
+		Timeout time.Duration `yaml:"timeout"`
+	}
+	payment := &paymentConfig{Timeout: time.Second}
+	require.NoError(t, RegisterConfigSection("payment", payment))
+	conf := &customConfig{Servers: []server{{Host: "a"}, {Host: "b"}}}
+
+	hook := logtest.NewLocal(logger.Logger)
+	defer hook.Reset()
+	err := applyEnvOverrides([]string{
+		"APP_SERVICE__PORT=9000",
+		"APP_TAGS=x,y",
+		"APP_SERVERS__1__HOST=c",
+		"APP_SERVERS__2__HOST=d",
+		"APP_PAYMENT__TIMEOUT=3s",
+	}, "APP_", conf)
+	require.NoError(t, err)
+
+	assert.Equal(t, 9000, app.BaseConfig.Service.Port)
+	assert.Equal(t, 9000, conf.Service.Port)
+	assert.Equal(t, []string{"x", "y"}, conf.Tags)
+	assert.Equal(t, []server{{Host: "a"}, {Host: "c"}}, conf.Servers)
+	assert.Equal(t, 3*time.Second, payment.Timeout)
+	assertLogged(t, hook, logrus.WarnLevel, "APP_SERVERS__2__HOST")
+	assertLogged(t, hook, logrus.InfoLevel, "覆盖配置项 payment.timeout")
+}
+
+// TestApplyEnvOverrides_UnknownPath 测试路径不存在的环境变量
+//
+// 【功能点】验证路径不存在时输出警告并提示最接近的配置项，不修改配置（不创建 nil 指针结构体）
+// 【测试流程】
+//  1. 设置 GIN_CORE_SERVICE__PROT、GIN_CORE_TRACING__ENDPOIN、GIN_CORE_SERVIC__PORT、GIN_CORE_FOO__BAR
+//  2. 断言前三个的警告分别提示 service.port、tracing.endpoint、service，最后一个没有提示
+//  3. 断言 tracing 仍为 nil，service.port 未被修改
+func TestApplyEnvOverrides_UnknownPath(t *testing.T) {
+	setupEnvOverrideTest(t)
+	hook := logtest.NewLocal(logger.Logger)
+	defer hook.Reset()
+
+	err := applyEnvOverrides([]string{
+		"GIN_CORE_SERVICE__PROT=9000",
+		"GIN_CORE_TRACING__ENDPOIN=http://collector",
+		"GIN_CORE_SERVIC__PORT=9000",
+		"GIN_CORE_FOO__BAR=1",
+	}, "GIN_CORE_", nil)
+	require.NoError(t, err)
+
+	assertLogged(t, hook, logrus.WarnLevel, "GIN_CORE_SERVICE__PROT 对应的配置项 SERVICE.PROT 不存在, 是否为 service.port?")
+	assertLogged(t, hook, logrus.WarnLevel, "是否为 tracing.endpoint?")
+	assertLogged(t, hook, logrus.WarnLevel, "GIN_CORE_SERVIC__PORT 对应的配置项 SERVIC.PORT 不存在, 是否为 service?")
+	assertLogged(t, hook, logrus.WarnLevel, "GIN_CORE_FOO__BAR 对应的配置项 FOO.BAR 不存在")
+	for _, entry := range hook.AllEntries() {
+		if strings.Contains(entry.Message, "FOO.BAR") {
+			assert.NotContains(t, entry.Message, "是否为")
+		}
+	}
+	assert.Nil(t, app.BaseConfig.Tracing)
+	assert.Zero(t, app.BaseConfig.Service.Port)
+}
+
+// TestApplyEnvOverrides_InvalidValue 测试无法转换的值
+//
+// 【功能点】验证值无法转换为字段类型时返回错误，错误信息包含环境变量名称和类型，不包含值
+// 【测试流程】
+//  1. 分别设置非数字的端口、非布尔值、非时长，断言返回错误
+//  2. 断言错误信息包含环境变量名称，不包含值
+func TestApplyEnvOverrides_InvalidValue(t *testing.T) {
+	setupEnvOverrideTest(t)
+	for _, kv := range []string{
+		"GIN_CORE_SERVICE__PORT=secret-port",
+		"GIN_CORE_SYSTEM__USEREDIS=secret-bool",
+		"GIN_CORE_SYSTEM__STARTUPRETRY__MAXWAIT=secret-duration",
+	} {
+		err := applyEnvOverrides([]string{kv}, "GIN_CORE_", nil)
+		require.Error(t, err, kv)
+		name, value, _ := strings.Cut(kv, "=")
+		assert.Contains(t, err.Error(), name)
+		assert.NotContains(t, err.Error(), value)
+	}
+}
+
+// TestLoadConfig_EnvOverridePrecedence 测试环境变量覆盖的优先级
+//
+// 【功能点】验证环境变量 > 环境配置文件 > 默认配置文件，system.envOverridePrefix 自定义前缀
+// 【测试流程】
+//  1. 默认配置设置 service.port=8080、service.readTimeout=10、redis.addr，环境配置覆盖 service.port=8081
+//  2. 设置 GIN_CORE_SERVICE__PORT=9000，断言 service.port 为 9000，readTimeout、redis.addr 保持配置文件的值
+//  3. 断言 service 的配置来源依次为默认配置、环境配置、环境变量
+//  4. 环境配置设置 system.envOverridePrefix=MYAPP_，断言 GIN_CORE_ 前缀不再生效、MYAPP_ 前缀生效
+func TestLoadConfig_EnvOverridePrecedence(t *testing.T) {
+	originalArgs := os.Args
+	originalEnv := app.Env
+	setupEnvOverrideTest(t)
+	defer func() {
+		os.Args = originalArgs
+		app.Env = originalEnv
+	}()
+
+	envFileName := constant.CustomConfigFileNamePrefix + "test" + constant.CustomConfigFileNameSuffix
+	dir := writeConfigFiles(t, map[string]string{
+		constant.DefaultConfigFileName: "service:\n  port: 8080\n  readTimeout: 10\nredis:\n  addr: file-redis:6379\n",
+		envFileName:                    "service:\n  port: 8081\n",
+	})
+	os.Args = []string{"program", "-env", "test", "-config", dir}
+	t.Setenv("GIN_CORE_SERVICE__PORT", "9000")
+
+	loadConfig(&includeTestConfig{})
+	assert.Equal(t, 9000, app.BaseConfig.Service.Port)
+	assert.Equal(t, 10, app.BaseConfig.Service.ReadTimeout)
+	require.NotNil(t, app.BaseConfig.Redis)
+	assert.Equal(t, "file-redis:6379", app.BaseConfig.Redis.Addr)
+	sources := configSources["service"]
+	require.Len(t, sources, 3)
+	assert.Equal(t, "env:GIN_CORE_SERVICE__PORT", sources[2])
+
+	app.BaseConfig = config.BaseConfig{}
+	dir = writeConfigFiles(t, map[string]string{
+		constant.DefaultConfigFileName: "service:\n  port: 8080\n",
+		envFileName:                    "system:\n  envOverridePrefix: MYAPP_\n",
+	})
+	os.Args = []string{"program", "-env", "test", "-config", dir}
+	t.Setenv("MYAPP_SERVICE__READ_TIMEOUT", "30")
+
+	loadConfig(&includeTestConfig{})
+	assert.Equal(t, 8080, app.BaseConfig.Service.Port)
+	assert.Equal(t, 30, app.BaseConfig.Service.ReadTimeout)
+}
+
+// assertLogged 断言日志中有指定级别且包含 substr 的记录
+func assertLogged(t *testing.T, hook *logtest.Hook, level logrus.Level, substr string) {
+	t.Helper()
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == level && strings.Contains(entry.Message, substr) {
+			return
+		}
+	}
+	t.Errorf("期望 %s 级别的日志包含 %q", level, substr)
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config/sanitize"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// configInspectOptionFuncs 配置查看接口的路由选项函数
+// 启用 configInspect 时注册 GET {configInspect.path}（默认 /system/config），由 configInspect.middleware 配置的中间件保护，
+// 返回当前运行环境、脱敏后的生效配置（带 mask:"true" 标签的字段替换为掩码）和每个顶层配置项来自的配置文件
+//
+// 返回：
+//   - []optionFunc: 未启用时为空
+//   - error: 未配置保护中间件或中间件未注册时返回错误
+func configInspectOptionFuncs() ([]optionFunc, error) {
+	cfg := app.BaseConfig.ConfigInspect
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.Middleware == "" {
+		return nil, fmt.Errorf("配置查看接口: 未配置 configInspect.middleware，配置查看接口必须由中间件保护")
+	}
+	fn, err := buildRoutes(cfg.GetPath(), []string{cfg.Middleware}, []RouteDef{
+		{Method: http.MethodGet, Path: "", Handler: configInspectHandler},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("配置查看接口: %w", err)
+	}
+	return []optionFunc{{fn: fn, source: "core.configInspectOptionFuncs"}}, nil
+}
+
+// configInspectHandler 返回脱敏后的生效配置及其来源
+// sources 的值按合并顺序排列，最后一个文件中的值生效
+func configInspectHandler(c *gin.Context) {
+	response.OkWithData(c, gin.H{
+		"env":     app.Env,
+		"config":  sanitize.Value(app.BaseConfig),
+		"sources": configSources,
+	})
+}
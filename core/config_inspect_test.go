@@ -0,0 +1,110 @@
+// Package core 配置查看接口测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含配置查看接口路由注册和响应内容的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 默认不启用，不注册接口
+// 2. 启用时未配置保护中间件启动失败
+// 3. 启用后挂载在路由前缀下，经过保护中间件，敏感字段已脱敏并包含配置来源
+//
+// 运行测试：go test -v ./core/... -run ConfigInspect
+// ==================================================
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/config/sanitize"
+)
+
+// TestConfigInspect_Disabled 测试默认不启用配置查看接口
+//
+// 【功能点】验证未启用 configInspect 时不注册接口
+// 【测试流程】使用默认配置初始化引擎，断言路由列表中没有该接口，请求返回 404
+func TestConfigInspect_Disabled(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+
+	engine, err := initEngine()
+	require.NoError(t, err)
+	for _, r := range Routes() {
+		assert.NotEqual(t, "/api/system/config", r.Path)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/system/config", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestConfigInspect_RequiresMiddleware 测试启用时必须配置保护中间件
+//
+// 【功能点】验证启用 configInspect 但未配置 middleware 时初始化引擎返回错误
+// 【测试流程】启用后不配置中间件，断言初始化引擎返回包含 configInspect.middleware 的错误
+func TestConfigInspect_RequiresMiddleware(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+	app.BaseConfig.ConfigInspect = config.ConfigInspectConfig{Enabled: true}
+
+	_, err := initEngine()
+	assert.ErrorContains(t, err, "configInspect.middleware")
+}
+
+// TestConfigInspect_Response 测试配置查看接口的响应内容
+//
+// 【功能点】验证启用后接口经过保护中间件，返回运行环境、脱敏后的配置和配置来源
+// 【测试流程】
+//  1. 配置数据库密码和服务端口，注册拒绝缺少 X-Admin 请求头的中间件
+//  2. 未带请求头断言返回 401
+//  3. 带请求头断言返回 200，密码为掩码且响应中不包含明文，端口和配置来源原样返回
+func TestConfigInspect_Response(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api", Port: 8080})
+	app.BaseConfig.ConfigInspect = config.ConfigInspectConfig{Enabled: true, Middleware: "adminAuth"}
+	app.BaseConfig.Db = &config.DbInfo{Host: "127.0.0.1", Password: "db-s3cret"}
+	originalSources := configSources
+	configSources = map[string][]string{"service": {"conf/app.yml", "conf/app.prod.yml"}}
+	t.Cleanup(func() { configSources = originalSources })
+
+	middleWareMap["adminAuth"] = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if c.GetHeader("X-Admin") == "" {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			c.Next()
+		}
+	}
+	engine, err := initEngine()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/system/config", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/system/config", nil)
+	req.Header.Set("X-Admin", "1")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "db-s3cret")
+
+	var body struct {
+		Data struct {
+			Config  map[string]map[string]any `json:"config"`
+			Sources map[string][]string       `json:"sources"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+	assert.Equal(t, sanitize.MaskedValue, body.Data.Config["db"]["password"])
+	assert.Equal(t, "127.0.0.1", body.Data.Config["db"]["host"])
+	assert.Equal(t, float64(8080), body.Data.Config["service"]["port"])
+	assert.Equal(t, []string{"conf/app.yml", "conf/app.prod.yml"}, body.Data.Sources["service"])
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// secretFilePrefix 密钥文件占位符前缀：{{file:/path/to/secret}}
+	secretFilePrefix = "file:"
+	// secretEnvFilePrefix 密钥目录占位符前缀：{{env_file:DIR:KEY}}，读取 DIR/KEY
+	secretEnvFilePrefix = "env_file:"
+)
+
+// isSecretPlaceholder 判断占位符内容是否为密钥文件占位符
+// 参数 name: 去掉 {{ }} 后的占位符内容
+func isSecretPlaceholder(name string) bool {
+	return strings.HasPrefix(name, secretFilePrefix) || strings.HasPrefix(name, secretEnvFilePrefix)
+}
+
+// loadSecretValue 读取密钥文件占位符对应的值，去掉末尾的换行符
+// 错误信息只包含文件路径，不包含文件内容
+// 参数 name: 去掉 {{ }} 后的占位符内容，格式为 file:PATH 或 env_file:DIR:KEY
+// 返回值: 文件内容和可能的错误
+func loadSecretValue(name string) (string, error) {
+	var secretPath string
+	if strings.HasPrefix(name, secretFilePrefix) {
+		secretPath = strings.TrimPrefix(name, secretFilePrefix)
+		if secretPath == "" {
+			return "", errors.New("无效占位符: {{" + name + "}}, 缺少文件路径")
+		}
+	} else {
+		ref := strings.TrimPrefix(name, secretEnvFilePrefix)
+		idx := strings.LastIndex(ref, ":")
+		if idx <= 0 || idx == len(ref)-1 {
+			return "", errors.New("无效占位符: {{" + name + "}}, 格式应为 {{env_file:DIR:KEY}}")
+		}
+		dir, key := ref[:idx], ref[idx+1:]
+		if key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+			return "", errors.New("无效占位符: {{" + name + "}}, KEY 不能包含路径")
+		}
+		secretPath = filepath.Join(dir, key)
+	}
+
+	data, err := os.ReadFile(secretPath)
+	if err != nil {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return "", fmt.Errorf("读取密钥文件失败: %s, %v", secretPath, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// escapeSecretValue 按占位符在YAML中的位置转义密钥的值，避免特殊字符破坏YAML结构
+// - 位于双引号字符串中：按双引号字符串的规则转义
+// - 位于单引号字符串中：单引号写为两个单引号，包含换行时返回错误
+// - 占位符为完整的无引号值：值不能原样作为无引号值时使用双引号字符串
+// - 占位符为无引号值的一部分：值包含换行、": "、" #" 时返回错误
+//
+// 参数：
+//   - yamlStr: YAML内容
+//   - start, end: 占位符在 yamlStr 中的位置
+//   - placeholder: 占位符，用于错误信息
+//   - value: 密钥的值
+//
+// 返回值: 转义后的值和可能的错误
+func escapeSecretValue(yamlStr string, start, end int, placeholder, value string) (string, error) {
+	lineStart := strings.LastIndexByte(yamlStr[:start], '\n') + 1
+	prefix := yamlStr[lineStart:start]
+
+	switch yamlQuoteContext(prefix) {
+	case '"':
+		quoted := strconv.Quote(value)
+		return quoted[1 : len(quoted)-1], nil
+	case '\'':
+		if strings.ContainsAny(value, "\r\n") {
+			return "", errors.New("密钥包含换行, 请将占位符 " + placeholder + " 放在双引号中")
+		}
+		return strings.ReplaceAll(value, "'", "''"), nil
+	}
+
+	lineEnd := strings.IndexByte(yamlStr[end:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(yamlStr) - end
+	}
+	suffix := strings.TrimRight(yamlStr[end:end+lineEnd], " \t\r")
+	before := strings.TrimSpace(prefix)
+	afterIndicator := before == "" || before == "-" ||
+		strings.HasSuffix(before, ":") && len(strings.TrimRight(prefix, " \t")) < len(prefix)
+	wholeValue := afterIndicator && (suffix == "" || strings.HasPrefix(suffix, " #"))
+	if wholeValue {
+		if isPlainYamlValue(value) {
+			return value, nil
+		}
+		return strconv.Quote(value), nil
+	}
+	if strings.ContainsAny(value, "\r\n") || strings.Contains(value, ": ") || strings.Contains(value, " #") {
+		return "", errors.New("密钥包含YAML特殊字符, 请将占位符 " + placeholder + " 所在的值放在双引号中")
+	}
+	return value, nil
+}
+
+// yamlQuoteContext 判断一行中前缀之后的位置是否位于引号字符串中
+// 引号只在值的开头（行首、": "、"- "、"["、"{"、"," 之后）才作为字符串的开始
+// 参数 prefix: 占位符所在行中占位符之前的内容
+// 返回值: 位于引号字符串中时返回对应的引号字符，不在引号字符串中时返回 0
+func yamlQuoteContext(prefix string) byte {
+	var quote byte
+	var last byte // 引号字符串之外的上一个非空白字符
+	for i := 0; i < len(prefix); i++ {
+		ch := prefix[i]
+		switch quote {
+		case '"':
+			if ch == '\\' {
+				i++
+			} else if ch == '"' {
+				quote = 0
+				last = ch
+			}
+		case '\'':
+			if ch == '\'' {
+				if i+1 < len(prefix) && prefix[i+1] == '\'' {
+					i++
+				} else {
+					quote = 0
+					last = ch
+				}
+			}
+		default:
+			if (ch == '"' || ch == '\'') && (last == 0 || strings.IndexByte(":-[{,", last) >= 0) {
+				quote = ch
+			} else if ch != ' ' && ch != '\t' {
+				last = ch
+			}
+		}
+	}
+	return quote
+}
+
+// isPlainYamlValue 判断值能否原样作为无引号的YAML值，解析结果与原值一致
+func isPlainYamlValue(value string) bool {
+	if value == "" || strings.ContainsAny(value, "\r\n") {
+		return false
+	}
+	var holder struct {
+		V string `yaml:"v"`
+	}
+	if err := yaml.Unmarshal([]byte("v: "+value), &holder); err != nil {
+		return false
+	}
+	return holder.V == value
+}
@@ -0,0 +1,167 @@
+// Package core 密钥文件占位符测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含配置文件中 {{file:PATH}}、{{env_file:DIR:KEY}} 占位符的单元测试，使用临时目录中的密钥文件。
+//
+// 测试覆盖内容：
+// 1. 读取文件内容替换占位符，去掉末尾的换行符，可与 {{ENV}} 混合使用
+// 2. 文件不存在时返回包含路径的错误，错误信息不包含文件内容
+// 3. 包含YAML特殊字符的值在无引号、双引号、单引号位置都能安全替换
+// 4. 在 include 引用的文件中生效，并在 CIPHER 解密之前完成替换
+//
+// 运行测试：go test -v ./core/... -run Secret
+// ==================================================
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/utils/encrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// writeSecretFile 在目录中写入密钥文件，返回文件路径
+func writeSecretFile(t *testing.T, dir, name, content string) string {
+	secretPath := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(secretPath, []byte(content), 0600))
+	return secretPath
+}
+
+// TestReplaceWithEvn_SecretFile 测试密钥文件占位符替换
+//
+// 【功能点】验证 {{file:PATH}}、{{env_file:DIR:KEY}} 读取文件内容并去掉末尾换行符，可与 {{ENV}} 混合使用
+// 【测试流程】
+//  1. 写入以换行符结尾的密钥文件，设置环境变量
+//  2. 替换同时包含三种占位符的配置，断言替换结果
+func TestReplaceWithEvn_SecretFile(t *testing.T) {
+	dir := t.TempDir()
+	passwordPath := writeSecretFile(t, dir, "db_password", "s3cret\n")
+	writeSecretFile(t, dir, "redis_password", "r3dis\r\n\n")
+	t.Setenv("TEST_DB_HOST", "localhost")
+
+	yamlData := []byte("host: {{TEST_DB_HOST}}\npassword: {{file:" + passwordPath + "}}\nredis: {{env_file:" + dir + ":redis_password}}\n")
+	result, err := replaceWithEvn(yamlData)
+	require.NoError(t, err)
+	assert.Equal(t, "host: localhost\npassword: s3cret\nredis: r3dis\n", string(result))
+}
+
+// TestReplaceWithEvn_SecretFileError 测试密钥文件占位符的错误
+//
+// 【功能点】验证文件不存在、占位符格式错误时返回错误，错误信息包含路径
+// 【测试流程】
+//  1. 引用不存在的文件，断言错误信息包含文件路径
+//  2. env_file 缺少 KEY、KEY 包含路径，断言返回无效占位符错误
+func TestReplaceWithEvn_SecretFileError(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+
+	_, err := replaceWithEvn([]byte("password: {{file:" + missing + "}}\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "读取密钥文件失败: "+missing)
+
+	_, err = replaceWithEvn([]byte("password: {{env_file:" + dir + ":missing}}\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), missing)
+
+	for _, placeholder := range []string{"{{env_file:" + dir + "}}", "{{env_file:" + dir + ":../passwd}}", "{{file:}}"} {
+		_, err = replaceWithEvn([]byte("password: " + placeholder + "\n"))
+		require.Error(t, err, placeholder)
+		assert.Contains(t, err.Error(), "无效占位符", placeholder)
+	}
+}
+
+// TestReplaceWithEvn_SecretFileEscape 测试密钥值的YAML转义
+//
+// 【功能点】验证包含YAML特殊字符的值替换后解析结果与文件内容一致，不会破坏YAML结构
+// 【测试流程】
+//  1. 写入包含 ": "、" #"、引号、反斜杠、换行的密钥文件
+//  2. 分别以无引号、双引号、单引号、列表项的形式引用，断言解析结果与文件内容一致，其他配置项不受影响
+//  3. 单引号中引用多行的值、无引号值中间引用包含 ": " 的值，断言返回错误且不包含密钥内容
+func TestReplaceWithEvn_SecretFileEscape(t *testing.T) {
+	dir := t.TempDir()
+	secrets := map[string]string{
+		"special":   `p@ss: wo#rd "x" 'y' \z #end`,
+		"multiline": "-----BEGIN KEY-----\nabc: def\n-----END KEY-----",
+		"number":    "123",
+		"empty":     "",
+	}
+	for name, content := range secrets {
+		writeSecretFile(t, dir, name, content+"\n")
+	}
+
+	type result struct {
+		Value string   `yaml:"value"`
+		List  []string `yaml:"list"`
+		Next  string   `yaml:"next"`
+	}
+	templates := map[string]string{
+		"无引号": "value: {{env_file:DIR:NAME}} # 注释\nnext: ok\n",
+		"双引号": "value: \"prefix-{{env_file:DIR:NAME}}\"\nnext: ok\n",
+		"单引号": "value: 'prefix-{{env_file:DIR:NAME}}'\nnext: ok\n",
+		"列表项": "list:\n  - {{env_file:DIR:NAME}}\nnext: ok\n",
+	}
+	for tplName, tpl := range templates {
+		for name, content := range secrets {
+			if tplName == "单引号" && name == "multiline" {
+				continue
+			}
+			t.Run(tplName+"/"+name, func(t *testing.T) {
+				yamlData := []byte(strings.NewReplacer("DIR", dir, "NAME", name).Replace(tpl))
+				replaced, err := replaceWithEvn(yamlData)
+				require.NoError(t, err)
+
+				var got result
+				require.NoError(t, yaml.Unmarshal(replaced, &got), string(replaced))
+				assert.Equal(t, "ok", got.Next)
+				switch tplName {
+				case "列表项":
+					require.Len(t, got.List, 1)
+					assert.Equal(t, content, got.List[0])
+				case "无引号":
+					assert.Equal(t, content, got.Value)
+				default:
+					assert.Equal(t, "prefix-"+content, got.Value)
+				}
+			})
+		}
+	}
+
+	for _, yamlData := range []string{
+		"value: 'x{{env_file:" + dir + ":multiline}}'\n",
+		"value: x-{{env_file:" + dir + ":special}}-y\n",
+	} {
+		_, err := replaceWithEvn([]byte(yamlData))
+		require.Error(t, err, yamlData)
+		assert.NotContains(t, err.Error(), "wo#rd")
+		assert.NotContains(t, err.Error(), "BEGIN KEY")
+	}
+}
+
+// TestLoadYamlConfig_SecretFile 测试加载配置时的密钥文件占位符
+//
+// 【功能点】验证 include 引用的文件中的密钥文件占位符生效，且在 CIPHER 解密之前完成替换
+// 【测试流程】
+//  1. 密钥文件内容为 CIPHER(加密内容)，被 include 引用的文件通过 {{file:...}} 引用该文件
+//  2. 加载主配置文件，断言得到解密后的明文
+func TestLoadYamlConfig_SecretFile(t *testing.T) {
+	const cipherKey = "1234567890abcdef"
+	encrypted, err := encrypt.AesEcbEncrypt("db-pass", cipherKey)
+	require.NoError(t, err)
+
+	secretDir := t.TempDir()
+	secretPath := writeSecretFile(t, secretDir, "db_password", "CIPHER("+encrypted+")\n")
+	dir := writeConfigFiles(t, map[string]string{
+		"main.yml":      "include:\n  - common/db.yml\nname: main\n",
+		"common/db.yml": "database:\n  host: db-host\n  password: {{file:" + secretPath + "}}\n",
+	})
+
+	config := &includeTestConfig{}
+	require.NoError(t, loadYamlConfig(filepath.Join(dir, "main.yml"), config, cipherKey))
+	assert.Equal(t, "db-host", config.Database.Host)
+	assert.Equal(t, "db-pass", config.Database.Password)
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configSectionRegistry 已注册的配置段
+// 注册只允许在 Start 之前进行，Start 之后只读，并发读取无需等待写入
+var configSectionRegistry = struct {
+	mu       sync.RWMutex
+	sections map[string]any
+	frozen   bool
+}{sections: map[string]any{}}
+
+// RegisterConfigSection 注册自定义配置段
+// 基于 gin_core 的内部库可以声明自己的配置段，而不需要应用在自定义配置结构体中嵌入对应字段。
+// 加载配置文件时，在解析基础配置和自定义配置之后，将配置文件顶层 name 键的内容解析到 target，
+// 环境变量替换、密钥文件占位符和 CIPHER(...) 解密均已生效；配置文件中没有该键时 target 保持原值（可作为默认值）。
+// 开启 system.strictConfigSections 时，配置段中存在 target 没有的字段会导致配置加载失败。
+//
+// 参数：
+//   - name: 配置文件中的顶层键名
+//   - target: 结构体指针，用于接收配置段的内容
+//
+// 返回：
+//   - error: name 为空、target 不是结构体指针、name 已注册或在 Start 之后注册时返回错误
+//
+// 使用示例：
+//
+//	type PaymentConfig struct {
+//	  Endpoint string `yaml:"endpoint"`
+//	  Timeout  int    `yaml:"timeout"`
+//	}
+//
+//	func init() {
+//	  if err := core.RegisterConfigSection("payment", &PaymentConfig{Timeout: 5}); err != nil {
+//	    panic(err)
+//	  }
+//	}
+func RegisterConfigSection(name string, target any) error {
+	if name == "" {
+		return errors.New("[配置段] 名称不能为空")
+	}
+	if target == nil {
+		return fmt.Errorf("[配置段] %s 的 target 不能为 nil", name)
+	}
+	if err := checkConfType(target); err != nil {
+		return fmt.Errorf("[配置段] %s 的 target 必须是结构体指针: %w", name, err)
+	}
+
+	configSectionRegistry.mu.Lock()
+	defer configSectionRegistry.mu.Unlock()
+	if configSectionRegistry.frozen {
+		return fmt.Errorf("[配置段] 服务已启动, 无法注册配置段 %s", name)
+	}
+	if _, exists := configSectionRegistry.sections[name]; exists {
+		return fmt.Errorf("[配置段] 配置段 %s 已注册", name)
+	}
+	configSectionRegistry.sections[name] = target
+	return nil
+}
+
+// ConfigSection 获取已注册的配置段
+// T 可以是注册时的结构体指针类型，也可以是结构体类型（返回副本）
+//
+// 参数：
+//   - name: 配置段名称
+//
+// 返回：
+//   - T: 配置段的内容
+//   - bool: 配置段未注册或类型不匹配时返回 false
+//
+// 使用示例：
+//
+//	cfg, ok := core.ConfigSection[*PaymentConfig]("payment")
+func ConfigSection[T any](name string) (T, bool) {
+	configSectionRegistry.mu.RLock()
+	target, ok := configSectionRegistry.sections[name]
+	configSectionRegistry.mu.RUnlock()
+
+	var zero T
+	if !ok {
+		return zero, false
+	}
+	if value, ok := target.(T); ok {
+		return value, true
+	}
+	if value, ok := reflect.ValueOf(target).Elem().Interface().(T); ok {
+		return value, true
+	}
+	return zero, false
+}
+
+// freezeConfigSections 禁止继续注册配置段，在 Start 开始时调用
+func freezeConfigSections() {
+	configSectionRegistry.mu.Lock()
+	defer configSectionRegistry.mu.Unlock()
+	configSectionRegistry.frozen = true
+}
+
+// loadConfigSections 将配置内容中已注册的配置段解析到对应的 target
+// 参数：
+//   - fileData: 已完成占位符替换、解密和 include 合并的配置内容
+//   - strict: 是否拒绝配置段中的未知字段
+//
+// 返回：
+//   - error: 配置段解析失败时返回错误，错误信息包含配置段名称
+func loadConfigSections(fileData []byte, strict bool) error {
+	configSectionRegistry.mu.RLock()
+	defer configSectionRegistry.mu.RUnlock()
+	if len(configSectionRegistry.sections) == 0 {
+		return nil
+	}
+
+	document := map[string]yaml.Node{}
+	if err := yaml.Unmarshal(fileData, &document); err != nil {
+		return err
+	}
+	for name, target := range configSectionRegistry.sections {
+		node, ok := document[name]
+		if !ok {
+			continue
+		}
+		sectionData, err := yaml.Marshal(&node)
+		if err != nil {
+			return fmt.Errorf("[配置段] 解析配置段 %s 失败: %w", name, err)
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(sectionData))
+		decoder.KnownFields(strict)
+		if err := decoder.Decode(target); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("[配置段] 解析配置段 %s 失败: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+// Package core 配置段注册功能测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 RegisterConfigSection / ConfigSection 的单元测试。
+//
+// 测试覆盖内容：
+// 1. 不同包注册的两个配置段分别获得各自的值，自定义配置不受影响
+// 2. 配置文件中没有的配置段保持默认值，环境配置文件覆盖默认配置文件中的字段
+// 3. 严格模式下配置段中的未知字段导致加载失败，错误信息包含配置段名称
+// 4. 参数校验、重复注册和 Start 之后注册返回错误
+// 5. 启动后并发读取配置段无数据竞争
+//
+// 运行测试：go test -v ./core/... -run ConfigSection
+// ==================================================
+package core
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+)
+
+// paymentSection 模拟支付库声明的配置段
+type paymentSection struct {
+	Endpoint string `yaml:"endpoint"`
+	Timeout  int    `yaml:"timeout"`
+	Secret   string `yaml:"secret"`
+}
+
+// searchSection 模拟搜索库声明的配置段
+type searchSection struct {
+	Index   string   `yaml:"index"`
+	Shards  int      `yaml:"shards"`
+	Fields  []string `yaml:"fields"`
+	Enabled bool     `yaml:"enabled"`
+}
+
+// resetConfigSections 清空已注册的配置段，并在测试结束后恢复基础配置
+func resetConfigSections(t *testing.T) {
+	originalBaseConfig := app.BaseConfig
+	reset := func() {
+		configSectionRegistry.mu.Lock()
+		configSectionRegistry.sections = map[string]any{}
+		configSectionRegistry.frozen = false
+		configSectionRegistry.mu.Unlock()
+	}
+	reset()
+	t.Cleanup(func() {
+		reset()
+		app.BaseConfig = originalBaseConfig
+	})
+}
+
+// TestConfigSection_Load 测试配置段加载
+//
+// 【功能点】验证注册的配置段从配置文件顶层键中获得值，环境变量已替换，自定义配置照常加载
+// 【测试流程】
+//  1. 注册 payment 和 search 两个配置段，payment 带默认超时时间
+//  2. 加载同时包含两个配置段和自定义配置字段的配置文件
+//  3. 断言两个配置段和自定义配置的值，payment.secret 的环境变量占位符已替换
+//  4. 通过 ConfigSection 获取指针类型和结构体类型，类型不匹配或未注册时返回 false
+func TestConfigSection_Load(t *testing.T) {
+	resetConfigSections(t)
+	t.Setenv("TEST_PAYMENT_SECRET", "s3cret")
+
+	payment := &paymentSection{Timeout: 5}
+	search := &searchSection{}
+	require.NoError(t, RegisterConfigSection("payment", payment))
+	require.NoError(t, RegisterConfigSection("search", search))
+
+	dir := writeConfigFiles(t, map[string]string{
+		"config.default.yml": `
+name: app
+service:
+  port: 8080
+payment:
+  endpoint: https://pay.example.com
+  secret: "{{TEST_PAYMENT_SECRET}}"
+search:
+  index: articles
+  shards: 3
+  fields: [title, body]
+  enabled: true
+`,
+	})
+	custom := &includeTestConfig{}
+	require.NoError(t, loadYamlConfig(filepath.Join(dir, "config.default.yml"), custom, ""))
+
+	assert.Equal(t, "app", custom.Name)
+	assert.Equal(t, 8080, app.BaseConfig.Service.Port)
+	assert.Equal(t, paymentSection{Endpoint: "https://pay.example.com", Timeout: 5, Secret: "s3cret"}, *payment)
+	assert.Equal(t, searchSection{Index: "articles", Shards: 3, Fields: []string{"title", "body"}, Enabled: true}, *search)
+
+	gotPtr, ok := ConfigSection[*paymentSection]("payment")
+	assert.True(t, ok)
+	assert.Same(t, payment, gotPtr)
+	gotValue, ok := ConfigSection[searchSection]("search")
+	assert.True(t, ok)
+	assert.Equal(t, *search, gotValue)
+
+	_, ok = ConfigSection[*searchSection]("payment")
+	assert.False(t, ok)
+	_, ok = ConfigSection[*paymentSection]("missing")
+	assert.False(t, ok)
+}
+
+// TestConfigSection_MissingAndOverride 测试配置段缺失和覆盖
+//
+// 【功能点】验证配置文件中没有的配置段保持默认值，后加载的配置文件只覆盖其中出现的字段
+// 【测试流程】
+//  1. 注册带默认值的 payment 和 search 配置段
+//  2. 加载只包含 payment 的默认配置文件，断言 search 保持默认值
+//  3. 加载只包含 payment.timeout 的环境配置文件，断言 timeout 被覆盖、endpoint 保持不变
+func TestConfigSection_MissingAndOverride(t *testing.T) {
+	resetConfigSections(t)
+
+	payment := &paymentSection{Timeout: 5}
+	search := &searchSection{Index: "default", Shards: 1}
+	require.NoError(t, RegisterConfigSection("payment", payment))
+	require.NoError(t, RegisterConfigSection("search", search))
+
+	dir := writeConfigFiles(t, map[string]string{
+		"config.default.yml": "payment:\n  endpoint: https://pay.example.com\n",
+		"config.prod.yml":    "payment:\n  timeout: 30\n",
+	})
+	custom := &includeTestConfig{}
+	require.NoError(t, loadYamlConfig(filepath.Join(dir, "config.default.yml"), custom, ""))
+	assert.Equal(t, searchSection{Index: "default", Shards: 1}, *search)
+
+	require.NoError(t, loadYamlConfig(filepath.Join(dir, "config.prod.yml"), custom, ""))
+	assert.Equal(t, paymentSection{Endpoint: "https://pay.example.com", Timeout: 30}, *payment)
+}
+
+// TestConfigSection_Strict 测试严格模式
+//
+// 【功能点】验证 system.strictConfigSections 开启时配置段中的未知字段导致加载失败，关闭时忽略
+// 【测试流程】
+//  1. 不开启严格模式，加载带拼写错误字段的配置，断言加载成功、其余字段生效
+//  2. 开启严格模式，断言加载失败，错误信息包含配置段名称和未知字段
+//  3. 严格模式只作用于配置段，配置文件中其他顶层未知键不影响加载
+func TestConfigSection_Strict(t *testing.T) {
+	resetConfigSections(t)
+
+	payment := &paymentSection{}
+	require.NoError(t, RegisterConfigSection("payment", payment))
+
+	dir := writeConfigFiles(t, map[string]string{
+		"loose.yml":  "unknownTopLevel: 1\npayment:\n  endpoint: https://pay.example.com\n  timout: 30\n",
+		"strict.yml": "system:\n  strictConfigSections: true\nunknownTopLevel: 1\npayment:\n  endpoint: https://pay.example.com\n  timout: 30\n",
+		"valid.yml":  "system:\n  strictConfigSections: true\nunknownTopLevel: 1\npayment:\n  timeout: 30\n",
+	})
+
+	custom := &includeTestConfig{}
+	require.NoError(t, loadYamlConfig(filepath.Join(dir, "loose.yml"), custom, ""))
+	assert.Equal(t, "https://pay.example.com", payment.Endpoint)
+
+	err := loadYamlConfig(filepath.Join(dir, "strict.yml"), custom, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "payment")
+	assert.Contains(t, err.Error(), "timout")
+
+	require.NoError(t, loadYamlConfig(filepath.Join(dir, "valid.yml"), custom, ""))
+	assert.Equal(t, 30, payment.Timeout)
+}
+
+// TestRegisterConfigSection_Errors 测试注册配置段的错误情况
+//
+// 【功能点】验证无效参数、重复注册和 Start 之后注册返回错误
+// 【测试流程】
+//  1. 名称为空、target 为 nil、target 不是结构体指针时返回错误
+//  2. 重复注册同名配置段返回错误
+//  3. freezeConfigSections 之后注册返回错误
+func TestRegisterConfigSection_Errors(t *testing.T) {
+	resetConfigSections(t)
+
+	assert.Error(t, RegisterConfigSection("", &paymentSection{}))
+	assert.Error(t, RegisterConfigSection("payment", nil))
+	assert.Error(t, RegisterConfigSection("payment", paymentSection{}))
+	notStruct := 1
+	assert.Error(t, RegisterConfigSection("payment", &notStruct))
+
+	require.NoError(t, RegisterConfigSection("payment", &paymentSection{}))
+	assert.Error(t, RegisterConfigSection("payment", &paymentSection{}))
+
+	freezeConfigSections()
+	err := RegisterConfigSection("search", &searchSection{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "已启动")
+}
+
+// TestConfigSection_ConcurrentRead 测试启动后并发读取配置段
+//
+// 【功能点】验证启动后多个协程并发读取配置段无数据竞争（配合 -race 运行）
+// 【测试流程】
+//  1. 注册并加载配置段后调用 freezeConfigSections
+//  2. 50 个协程并发调用 ConfigSection，断言读取到的值一致
+func TestConfigSection_ConcurrentRead(t *testing.T) {
+	resetConfigSections(t)
+
+	require.NoError(t, RegisterConfigSection("search", &searchSection{}))
+	dir := writeConfigFiles(t, map[string]string{
+		"config.default.yml": "search:\n  index: articles\n  shards: 3\n",
+	})
+	require.NoError(t, loadYamlConfig(filepath.Join(dir, "config.default.yml"), &includeTestConfig{}, ""))
+	freezeConfigSections()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cfg, ok := ConfigSection[*searchSection]("search")
+			if assert.True(t, ok) {
+				assert.Equal(t, "articles", cfg.Index)
+				assert.Equal(t, 3, cfg.Shards)
+			}
+			value, ok := ConfigSection[searchSection]("search")
+			assert.True(t, ok)
+			assert.Equal(t, 3, value.Shards)
+		}()
+	}
+	wg.Wait()
+}
+
@@ -18,6 +18,7 @@
 package core
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -811,3 +812,185 @@ func TestConcurrent(t *testing.T) {
 		}
 	})
 }
+
+// ==================== 配置文件 include 测试 ====================
+
+// includeTestConfig include 测试用配置结构体
+type includeTestConfig struct {
+	Name     string   `yaml:"name"`
+	Port     int      `yaml:"port"`
+	Tags     []string `yaml:"tags"`
+	Database struct {
+		Host     string `yaml:"host"`
+		Port     int    `yaml:"port"`
+		User     string `yaml:"user"`
+		Password string `yaml:"password"`
+	} `yaml:"database"`
+}
+
+// writeConfigFiles 在临时目录中写入配置文件，返回目录路径
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		fullPath := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		assert.Nil(t, os.WriteFile(fullPath, []byte(content), 0644))
+	}
+	return dir
+}
+
+// TestLoadYamlConfigInclude 测试配置文件 include 合并
+//
+// 【功能点】验证 include 多文件合并、覆盖优先级、循环检测和环境变量替换
+// 【测试流程】
+//  1. 三级引用链：main -> a -> b，验证所有文件内容被合并
+//  2. 覆盖优先级：后引用的文件覆盖先引用的文件，当前文件覆盖所有被引用文件；map 深度合并、列表整体替换
+//  3. 循环引用：a -> b -> a，验证返回包含引用链的错误
+//  4. 被引用文件中的 {{ENV}} 占位符被正确替换
+func TestLoadYamlConfigInclude(t *testing.T) {
+	t.Run("three file chain", func(t *testing.T) {
+		dir := writeConfigFiles(t, map[string]string{
+			"main.yml":     "include:\n  - common/a.yml\nname: main\n",
+			"common/a.yml": "include:\n  - b.yml\ndatabase:\n  host: a-host\n",
+			"common/b.yml": "port: 7000\ndatabase:\n  port: 3306\n",
+		})
+
+		config := &includeTestConfig{}
+		err := loadYamlConfig(filepath.Join(dir, "main.yml"), config, "")
+		assert.Nil(t, err)
+		assert.Equal(t, "main", config.Name)
+		assert.Equal(t, 7000, config.Port)
+		assert.Equal(t, "a-host", config.Database.Host)
+		assert.Equal(t, 3306, config.Database.Port)
+	})
+
+	t.Run("override precedence", func(t *testing.T) {
+		dir := writeConfigFiles(t, map[string]string{
+			"main.yml": `
+include:
+  - first.yml
+  - second.yml
+port: 9000
+database:
+  user: main-user
+`,
+			"first.yml": `
+name: first
+port: 1000
+tags: [a, b, c]
+database:
+  host: first-host
+  port: 1111
+  user: first-user
+`,
+			"second.yml": `
+name: second
+tags: [x]
+database:
+  host: second-host
+`,
+		})
+
+		config := &includeTestConfig{}
+		err := loadYamlConfig(filepath.Join(dir, "main.yml"), config, "")
+		assert.Nil(t, err)
+		// 后引用的文件覆盖先引用的文件
+		assert.Equal(t, "second", config.Name)
+		// 当前文件覆盖所有被引用文件
+		assert.Equal(t, 9000, config.Port)
+		// 列表整体替换
+		assert.Equal(t, []string{"x"}, config.Tags)
+		// map 深度合并
+		assert.Equal(t, "second-host", config.Database.Host)
+		assert.Equal(t, 1111, config.Database.Port)
+		assert.Equal(t, "main-user", config.Database.User)
+	})
+
+	t.Run("include cycle", func(t *testing.T) {
+		dir := writeConfigFiles(t, map[string]string{
+			"a.yml": "include:\n  - b.yml\nname: a\n",
+			"b.yml": "include:\n  - a.yml\nname: b\n",
+		})
+
+		config := &includeTestConfig{}
+		err := loadYamlConfig(filepath.Join(dir, "a.yml"), config, "")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "循环引用")
+		assert.Contains(t, err.Error(), "a.yml -> ")
+		assert.Contains(t, err.Error(), "b.yml -> ")
+	})
+
+	t.Run("include depth limit", func(t *testing.T) {
+		// 构造 f0 -> f1 -> ... -> fN 的引用链，长度超过上限
+		files := map[string]string{}
+		for i := 0; i <= constant.MaxConfigIncludeDepth; i++ {
+			files[fmt.Sprintf("f%d.yml", i)] = fmt.Sprintf("include:\n  - f%d.yml\n", i+1)
+		}
+		files[fmt.Sprintf("f%d.yml", constant.MaxConfigIncludeDepth+1)] = "name: leaf\n"
+		dir := writeConfigFiles(t, files)
+
+		config := &includeTestConfig{}
+		err := loadYamlConfig(filepath.Join(dir, "f0.yml"), config, "")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "层级超过上限")
+	})
+
+	t.Run("env placeholder in included file", func(t *testing.T) {
+		t.Setenv("INCLUDE_TEST_DB_PASSWORD", "s3cret")
+		dir := writeConfigFiles(t, map[string]string{
+			"main.yml":   "include:\n  - secret.yml\nname: main\n",
+			"secret.yml": "database:\n  password: \"{{INCLUDE_TEST_DB_PASSWORD}}\"\n",
+		})
+
+		config := &includeTestConfig{}
+		err := loadYamlConfig(filepath.Join(dir, "main.yml"), config, "")
+		assert.Nil(t, err)
+		assert.Equal(t, "s3cret", config.Database.Password)
+	})
+
+	t.Run("missing included file", func(t *testing.T) {
+		dir := writeConfigFiles(t, map[string]string{
+			"main.yml": "include:\n  - missing.yml\n",
+		})
+
+		config := &includeTestConfig{}
+		err := loadYamlConfig(filepath.Join(dir, "main.yml"), config, "")
+		assert.Error(t, err)
+	})
+}
+
+// TestLoadConfigSources 测试配置来源记录
+//
+// 【功能点】验证 loadConfig 按合并顺序记录每个顶层配置项来自的文件，被环境配置覆盖的配置项最后一个来源为环境配置文件
+// 【测试流程】
+//  1. 默认配置包含 name、port 并引用 common.yml，环境配置覆盖 port
+//  2. 断言 port 的来源依次为默认配置、环境配置，name 的来源只有默认配置
+//  3. 断言被引用文件中的配置项记录为被引用文件，不记录 include 字段
+func TestLoadConfigSources(t *testing.T) {
+	originalArgs := os.Args
+	originalEnv := app.Env
+	originalSources := configSources
+	defer func() {
+		os.Args = originalArgs
+		app.Env = originalEnv
+		configSources = originalSources
+	}()
+
+	dir := writeConfigFiles(t, map[string]string{
+		constant.DefaultConfigFileName: "include:\n  - common.yml\nname: default_app\nport: 8080\n",
+		"common.yml":                   "database:\n  host: common-host\n",
+		constant.CustomConfigFileNamePrefix + "test" + constant.CustomConfigFileNameSuffix: "port: 9090\n",
+	})
+	os.Args = []string{"program", "-env", "test", "-config", dir}
+
+	config := &includeTestConfig{}
+	loadConfig(config)
+	assert.Equal(t, 9090, config.Port)
+
+	defaultFile := filepath.Join(dir, constant.DefaultConfigFileName)
+	envFile := filepath.Join(dir, constant.CustomConfigFileNamePrefix+"test"+constant.CustomConfigFileNameSuffix)
+	assert.Equal(t, []string{defaultFile, envFile}, configSources["port"])
+	assert.Equal(t, []string{defaultFile}, configSources["name"])
+	assert.Equal(t, []string{filepath.Join(dir, "common.yml")}, configSources["database"])
+	assert.NotContains(t, configSources, "include")
+}
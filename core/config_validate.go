@@ -0,0 +1,38 @@
+package core
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// writeValidationReport 校验基础配置并输出可读的校验报告
+// 用于 -validate-config 模式：在部署前检查配置包，不启动 HTTP 服务，也不连接任何外部服务
+// 参数：
+//   - w: 报告输出目标
+//   - cfg: 已加载的基础配置
+//
+// 返回值: 进程退出码，配置合法时为 0，存在问题时为 1
+func writeValidationReport(w io.Writer, cfg *config.BaseConfig) int {
+	issues := config.Validate(cfg)
+	if len(issues) == 0 {
+		fmt.Fprintf(w, "[配置校验] 环境 %s 的配置校验通过\n", app.Env)
+		return 0
+	}
+
+	fmt.Fprintf(w, "[配置校验] 环境 %s 的配置存在 %d 个问题:\n", app.Env, len(issues))
+	for i, issue := range issues {
+		fmt.Fprintf(w, "  %d. %s\n", i+1, issue.String())
+	}
+	return 1
+}
+
+// logValidationIssues 正常启动时校验配置，仅输出警告日志，不中断启动
+func logValidationIssues(cfg *config.BaseConfig) {
+	for _, issue := range config.Validate(cfg) {
+		logger.Warn("[配置校验] %s", issue.String())
+	}
+}
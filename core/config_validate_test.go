@@ -0,0 +1,104 @@
+// Package core 配置校验模式测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 -validate-config 配置校验模式的单元测试。
+//
+// 测试覆盖内容：
+// 1. -validate-config 参数解析
+// 2. 完整加载流程（include、环境变量替换、自定义配置）后输出校验报告与退出码
+// 3. 合法配置的校验报告
+//
+// 运行测试：go test -v ./core/... -run ValidateConfig
+// ==================================================
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/constant"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// TestValidateConfig_Report 测试配置校验模式的完整流程
+//
+// 【功能点】验证 -validate-config 模式加载全部配置后输出所有问题并返回退出码 1
+// 【测试流程】
+//  1. 创建默认配置和 include 引用的配置文件，其中使用 {{ENV}} 占位符
+//  2. 携带 -validate-config 参数调用 loadConfig，确认参数被解析且自定义配置已加载
+//  3. 输出校验报告，断言包含 RabbitMQ、Redis、限流、CORS 问题且退出码为 1
+func TestValidateConfig_Report(t *testing.T) {
+	originalArgs := os.Args
+	originalBaseConfig := app.BaseConfig
+	originalEnv := app.Env
+	defer func() {
+		os.Args = originalArgs
+		app.BaseConfig = originalBaseConfig
+		app.Env = originalEnv
+	}()
+	app.BaseConfig = config.BaseConfig{}
+
+	confDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(confDir, "middleware.yml"), []byte(`
+rateLimit:
+  enabled: true
+  rules:
+    - path: "/api/*"
+      rate: 10
+      burst: -1
+cors:
+  enabled: true
+  allowCredentials: true
+  allowOrigins: ["*"]
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(confDir, constant.DefaultConfigFileName), []byte(`
+include:
+  - middleware.yml
+system:
+  useRedis: true
+  useRabbitMQ: true
+rabbitMQ:
+  host: "{{GIN_CORE_VALIDATE_MQ_HOST}}"
+  port: 5672
+appName: validate-demo
+`), 0644))
+	t.Setenv("GIN_CORE_VALIDATE_MQ_HOST", "")
+
+	os.Args = []string{"program", "-validate-config", "-config", confDir}
+	type customConfig struct {
+		AppName string `yaml:"appName"`
+	}
+	custom := &customConfig{}
+	cmdArgs := loadConfig(custom)
+	require.True(t, cmdArgs.ValidateConfig)
+	assert.Equal(t, "validate-demo", custom.AppName)
+
+	var out bytes.Buffer
+	code := writeValidationReport(&out, &app.BaseConfig)
+	assert.Equal(t, 1, code)
+	report := out.String()
+	assert.Contains(t, report, "存在 4 个问题")
+	assert.Contains(t, report, "rabbitMQ.host")
+	assert.Contains(t, report, "redis:")
+	assert.Contains(t, report, "rateLimit.rules[0].burst")
+	assert.Contains(t, report, "cors.allowOrigins")
+}
+
+// TestValidateConfig_Passed 测试合法配置的校验报告
+//
+// 【功能点】验证配置合法时输出校验通过并返回退出码 0
+// 【测试流程】对只开启 Redis 且配置了地址的配置输出报告，断言退出码和输出内容
+func TestValidateConfig_Passed(t *testing.T) {
+	cfg := &config.BaseConfig{
+		System: config.SystemInfo{UseRedis: true},
+		Redis:  &config.RedisInfo{Addr: "127.0.0.1:6379"},
+	}
+	var out bytes.Buffer
+	assert.Equal(t, 0, writeValidationReport(&out, cfg))
+	assert.Contains(t, out.String(), "校验通过")
+}
@@ -0,0 +1,269 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Controller 控制器标记，嵌入控制器结构体以声明基础路径和控制器级中间件
+// 结构体标签：
+//   - path: 基础路径，控制器的所有路由挂载在 service.routePrefix + path 下
+//   - middlewares: 控制器级中间件名称，逗号分隔，在路由级中间件之前执行
+//
+// 使用示例：
+//
+//	type UserController struct {
+//	  core.Controller `path:"/users" middlewares:"authHandler"`
+//	  _ core.Route    `route:"GET" handler:"List"`
+//	  _ core.Route    `route:"GET /:id" handler:"Detail" middlewares:"auditLogHandler"`
+//	}
+//
+//	func (ctrl *UserController) List(c *gin.Context)   { ... }
+//	func (ctrl *UserController) Detail(c *gin.Context) { ... }
+type Controller struct{}
+
+// Route 路由声明，作为控制器结构体的字段（通常为 _ 字段）通过结构体标签声明路由
+// 结构体标签：
+//   - route: 请求方法和路径，以空格分隔，如 "GET /:id"；省略路径时挂载在控制器基础路径上；方法为 ANY 时注册所有方法
+//   - handler: 处理函数的方法名，方法签名必须为 func(*gin.Context)
+//   - middlewares: 路由级中间件名称，逗号分隔
+type Route struct{}
+
+// RouteDef 路由定义
+// 控制器实现 Routes() []RouteDef 方法时，返回的路由与结构体标签声明的路由一同注册
+type RouteDef struct {
+	Method      string          // 请求方法，如 GET、POST，ANY 表示所有方法
+	Path        string          // 路径，相对于控制器基础路径
+	Handler     gin.HandlerFunc // 处理函数
+	Middlewares []string        // 路由级中间件名称，与 service.middlewares 使用相同的名称
+}
+
+// RouteProvider 以方法返回路由定义的控制器
+type RouteProvider interface {
+	Routes() []RouteDef
+}
+
+// registeredController 已注册的控制器及其注册位置
+type registeredController struct {
+	ctrl   any
+	source string // 格式为 "控制器类型 (文件:行号)"
+}
+
+var (
+	controllerList []registeredController
+	controllerMu   sync.Mutex
+)
+
+// controllerType / routeType 控制器标记和路由声明的类型，用于识别结构体字段
+var (
+	controllerType = reflect.TypeOf(Controller{})
+	routeType      = reflect.TypeOf(Route{})
+	methodPattern  = regexp.MustCompile(`^[A-Z]+$`)
+)
+
+// RegisterController 注册控制器
+// 控制器的路由在引擎初始化时挂载，与 AddOptionFunc 注册的路由一样参与路由列表和冲突检测；
+// 不同控制器注册了相同的方法和路径时启动失败，错误信息包含两个控制器的类型名。
+// 路由声明有误（处理函数不存在、签名不符、中间件未注册等）时同样启动失败。
+//
+// 参数：
+//   - ctrl: 控制器，通常为结构体指针；通过 Route 字段标签或 Routes() 方法声明路由
+//
+// 使用示例：
+//
+//	core.RegisterController(&controller.UserController{})
+func RegisterController(ctrl any) {
+	source := fmt.Sprintf("%T (%s)", ctrl, callerSource(1))
+	controllerMu.Lock()
+	defer controllerMu.Unlock()
+	controllerList = append(controllerList, registeredController{ctrl: ctrl, source: source})
+}
+
+// controllerOptionFuncs 将已注册的控制器转换为路由选项函数
+// 返回：
+//   - []optionFunc: 每个控制器对应一个选项函数，注册位置为控制器类型和 RegisterController 调用位置
+//   - error: 路由声明有误时返回错误
+func controllerOptionFuncs() ([]optionFunc, error) {
+	controllerMu.Lock()
+	controllers := make([]registeredController, len(controllerList))
+	copy(controllers, controllerList)
+	controllerMu.Unlock()
+
+	optionFuncs := make([]optionFunc, 0, len(controllers))
+	var errs []error
+	for _, rc := range controllers {
+		fn, err := buildController(rc.ctrl)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("控制器 %s: %w", rc.source, err))
+			continue
+		}
+		optionFuncs = append(optionFuncs, optionFunc{fn: fn, source: rc.source})
+	}
+	return optionFuncs, errors.Join(errs...)
+}
+
+// buildController 解析控制器的基础路径、中间件和路由，返回挂载路由的选项函数
+func buildController(ctrl any) (gin.OptionFunc, error) {
+	value := reflect.ValueOf(ctrl)
+	structType := value.Type()
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("控制器必须是结构体或结构体指针")
+	}
+
+	var (
+		basePath        string
+		middlewareNames []string
+		routes          []RouteDef
+		errs            []error
+	)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		switch field.Type {
+		case controllerType:
+			basePath = field.Tag.Get("path")
+			middlewareNames = splitNames(field.Tag.Get("middlewares"))
+		case routeType:
+			route, err := parseRouteField(value, field)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("字段 %s: %w", field.Name, err))
+				continue
+			}
+			routes = append(routes, route)
+		}
+	}
+	if provider, ok := ctrl.(RouteProvider); ok {
+		routes = append(routes, provider.Routes()...)
+	}
+	if len(routes) == 0 && len(errs) == 0 {
+		errs = append(errs, fmt.Errorf("未声明任何路由"))
+	}
+
+	fn, err := buildRoutes(basePath, middlewareNames, routes)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return fn, nil
+}
+
+// buildRoutes 解析路由分组的中间件和路由定义，返回挂载路由的选项函数
+// 参数：
+//   - basePath: 分组路径
+//   - middlewareNames: 分组中间件名称，在路由级中间件之前执行
+//   - routes: 路由定义
+//
+// 返回：
+//   - gin.OptionFunc: 挂载路由的选项函数
+//   - error: 请求方法无效、处理函数为空或中间件未注册时返回错误
+func buildRoutes(basePath string, middlewareNames []string, routes []RouteDef) (gin.OptionFunc, error) {
+	var errs []error
+	middlewares, err := resolveMiddlewares(middlewareNames)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	type mountedRoute struct {
+		method   string
+		path     string
+		handlers []gin.HandlerFunc
+	}
+	mounted := make([]mountedRoute, 0, len(routes))
+	for _, route := range routes {
+		method := strings.ToUpper(strings.TrimSpace(route.Method))
+		if !methodPattern.MatchString(method) {
+			errs = append(errs, fmt.Errorf("路由 %q %s 的请求方法无效", route.Method, route.Path))
+			continue
+		}
+		if route.Handler == nil {
+			errs = append(errs, fmt.Errorf("路由 %s %s 未设置处理函数", method, route.Path))
+			continue
+		}
+		handlers, err := resolveMiddlewares(route.Middlewares)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("路由 %s %s: %w", method, route.Path, err))
+			continue
+		}
+		mounted = append(mounted, mountedRoute{method: method, path: route.Path, handlers: append(handlers, route.Handler)})
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return func(e *gin.Engine) {
+		group := e.Group(basePath, middlewares...)
+		for _, r := range mounted {
+			if r.method == "ANY" {
+				group.Any(r.path, r.handlers...)
+			} else {
+				group.Handle(r.method, r.path, r.handlers...)
+			}
+		}
+	}, nil
+}
+
+// parseRouteField 解析 Route 字段的结构体标签
+func parseRouteField(ctrl reflect.Value, field reflect.StructField) (RouteDef, error) {
+	parts := strings.Fields(field.Tag.Get("route"))
+	if len(parts) == 0 || len(parts) > 2 {
+		return RouteDef{}, fmt.Errorf("route 标签格式应为 \"方法 路径\"，实际为 %q", field.Tag.Get("route"))
+	}
+	method, path := parts[0], ""
+	if len(parts) == 2 {
+		path = parts[1]
+	}
+	name := field.Tag.Get("handler")
+	if name == "" {
+		return RouteDef{}, fmt.Errorf("未设置 handler 标签")
+	}
+	methodValue := ctrl.MethodByName(name)
+	if !methodValue.IsValid() {
+		return RouteDef{}, fmt.Errorf("处理函数 %s 不存在或未导出（指针接收者的方法需要以指针注册控制器）", name)
+	}
+	handler, ok := methodValue.Interface().(func(*gin.Context))
+	if !ok {
+		return RouteDef{}, fmt.Errorf("处理函数 %s 的签名应为 func(*gin.Context)，实际为 %s", name, methodValue.Type())
+	}
+	return RouteDef{
+		Method:      method,
+		Path:        path,
+		Handler:     handler,
+		Middlewares: splitNames(field.Tag.Get("middlewares")),
+	}, nil
+}
+
+// resolveMiddlewares 通过中间件映射表将名称解析为中间件处理函数，接收配置的中间件收到的配置为 nil
+func resolveMiddlewares(names []string) ([]gin.HandlerFunc, error) {
+	middlewareMutex.RLock()
+	defer middlewareMutex.RUnlock()
+	handlers := make([]gin.HandlerFunc, 0, len(names))
+	for _, name := range names {
+		handler, ok := newMiddlewareHandler(name, nil)
+		if !ok {
+			return nil, fmt.Errorf("中间件 %s 未注册", name)
+		}
+		handlers = append(handlers, handler)
+	}
+	return handlers, nil
+}
+
+// splitNames 解析逗号分隔的名称列表，忽略空白项
+func splitNames(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,178 @@
+// Package core 控制器注册测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 RegisterController 声明式路由注册的单元测试。
+//
+// 测试覆盖内容：
+// 1. 通过结构体标签和 Routes() 方法声明路由，挂载在路由前缀和控制器基础路径下，与 AddOptionFunc 路由共存
+// 2. 控制器级、路由级中间件按声明顺序执行
+// 3. 两个控制器注册相同的方法和路径时返回包含两个控制器类型名的冲突错误
+// 4. 路由声明有误（处理函数不存在、签名不符、中间件未注册）时返回错误
+//
+// 运行测试：go test -v ./core/... -run Controller
+// ==================================================
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// userController 以结构体标签声明路由的控制器
+type userController struct {
+	Controller `path:"/users" middlewares:"mwA"`
+	_          Route `route:"GET" handler:"List"`
+	_          Route `route:"GET /:id" handler:"Detail" middlewares:"mwB,mwC"`
+}
+
+func (ctrl *userController) List(c *gin.Context) {
+	c.String(http.StatusOK, "users")
+}
+
+func (ctrl *userController) Detail(c *gin.Context) {
+	c.String(http.StatusOK, "user "+c.Param("id"))
+}
+
+// orderController 以 Routes() 方法声明路由的控制器
+type orderController struct {
+	Controller `path:"/orders"`
+}
+
+func (ctrl *orderController) Routes() []RouteDef {
+	return []RouteDef{
+		{Method: "post", Path: "", Handler: ctrl.Create, Middlewares: []string{"mwC", "mwA"}},
+	}
+}
+
+func (ctrl *orderController) Create(c *gin.Context) {
+	c.String(http.StatusCreated, "created")
+}
+
+// adminUserController 与 userController 注册了相同路径的控制器
+type adminUserController struct {
+	Controller `path:"/users"`
+	_          Route `route:"GET /:id" handler:"Detail"`
+}
+
+func (ctrl *adminUserController) Detail(c *gin.Context) {}
+
+// setupControllerTest 设置路由测试环境，注册测试中间件并清空控制器列表
+// 测试中间件 mwA、mwB、mwC 依次向响应头 X-Middleware-Order 追加自身名称
+func setupControllerTest(t *testing.T, service config.ServiceInfo) {
+	setupRouteTest(t, service)
+	controllerList = nil
+	t.Cleanup(func() { controllerList = nil })
+	for _, name := range []string{"mwA", "mwB", "mwC"} {
+		name := name
+		middleWareMap[name] = func() gin.HandlerFunc {
+			return func(c *gin.Context) {
+				c.Writer.Header().Add("X-Middleware-Order", name)
+				c.Next()
+			}
+		}
+	}
+}
+
+// TestRegisterController 测试控制器路由注册
+//
+// 【功能点】验证结构体标签和 Routes() 方法声明的路由挂载在路由前缀与基础路径下，中间件按声明顺序执行，AddOptionFunc 路由不受影响
+// 【测试流程】
+//  1. 路由前缀 /api，注册 userController、orderController 和一个 AddOptionFunc 路由
+//  2. 请求各路由，断言响应内容和 X-Middleware-Order 响应头
+//  3. 断言 Routes 中控制器路由的来源包含控制器类型名
+func TestRegisterController(t *testing.T) {
+	setupControllerTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+	RegisterController(&userController{})
+	RegisterController(&orderController{})
+	AddOptionFunc(func(e *gin.Engine) { e.GET("/ping", routeHandler("pong")) })
+
+	engine, err := initEngine()
+	require.NoError(t, err)
+
+	tests := []struct {
+		method, path string
+		code         int
+		body         string
+		order        []string
+	}{
+		{http.MethodGet, "/api/users", http.StatusOK, "users", []string{"mwA"}},
+		{http.MethodGet, "/api/users/7", http.StatusOK, "user 7", []string{"mwA", "mwB", "mwC"}},
+		{http.MethodPost, "/api/orders", http.StatusCreated, "created", []string{"mwC", "mwA"}},
+		{http.MethodGet, "/api/ping", http.StatusOK, "pong", nil},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		assert.Equal(t, tt.code, w.Code, tt.path)
+		assert.Equal(t, tt.body, w.Body.String(), tt.path)
+		assert.Equal(t, tt.order, w.Header().Values("X-Middleware-Order"), tt.path)
+	}
+
+	sources := map[string]string{}
+	for _, r := range Routes() {
+		sources[r.Method+" "+r.Path] = r.Source
+	}
+	assert.True(t, strings.HasPrefix(sources["GET /api/users/:id"], "*core.userController ("), sources["GET /api/users/:id"])
+	assert.True(t, strings.HasPrefix(sources["POST /api/orders"], "*core.orderController ("), sources["POST /api/orders"])
+}
+
+// TestRegisterController_Duplicate 测试控制器路由冲突
+//
+// 【功能点】验证两个控制器注册相同的方法和路径时启动失败，错误信息包含两个控制器的类型名
+// 【测试流程】注册 userController 和 adminUserController，断言 initEngine 返回的错误
+func TestRegisterController_Duplicate(t *testing.T) {
+	setupControllerTest(t, config.ServiceInfo{})
+	RegisterController(&userController{})
+	RegisterController(&adminUserController{})
+
+	_, err := initEngine()
+	var conflictErr *RouteConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	require.Len(t, conflictErr.Duplicates, 1)
+	d := conflictErr.Duplicates[0]
+	assert.Equal(t, "GET", d.Method)
+	assert.Equal(t, "/users/:id", d.Path)
+	assert.Contains(t, err.Error(), "*core.userController (")
+	assert.Contains(t, err.Error(), "*core.adminUserController (")
+}
+
+// invalidController 路由声明有误的控制器
+type invalidController struct {
+	Controller `path:"/invalid" middlewares:"missingHandler"`
+	_          Route `route:"GET /a" handler:"Missing"`
+	_          Route `route:"GET /b" handler:"WrongSignature"`
+	_          Route `route:"GET /c extra" handler:"Missing"`
+}
+
+func (ctrl *invalidController) WrongSignature(c *gin.Context) error { return nil }
+
+// TestRegisterController_Invalid 测试路由声明有误
+//
+// 【功能点】验证处理函数不存在、签名不符、route 标签格式错误、中间件未注册、非结构体控制器均在启动时返回错误
+// 【测试流程】分别注册 invalidController 和非结构体值，断言 initEngine 返回的错误信息
+func TestRegisterController_Invalid(t *testing.T) {
+	setupControllerTest(t, config.ServiceInfo{})
+	RegisterController(&invalidController{})
+
+	_, err := initEngine()
+	require.Error(t, err)
+	msg := err.Error()
+	assert.Contains(t, msg, "*core.invalidController")
+	assert.Contains(t, msg, "中间件 missingHandler 未注册")
+	assert.Contains(t, msg, "处理函数 Missing 不存在或未导出")
+	assert.Contains(t, msg, "处理函数 WrongSignature 的签名应为 func(*gin.Context)")
+	assert.Contains(t, msg, `route 标签格式应为 "方法 路径"`)
+
+	controllerList = nil
+	RegisterController(func(c *gin.Context) {})
+	_, err = initEngine()
+	assert.ErrorContains(t, err, "控制器必须是结构体或结构体指针")
+}
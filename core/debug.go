@@ -0,0 +1,84 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// debugStartupPath 启动耗时接口的路径，位于 service.routePrefix 之下
+const debugStartupPath = "/debug/startup"
+
+// debugOptionFuncs 调试接口的路由选项函数
+// 以下路由均由 debug.protectMiddleware 配置的中间件保护：
+//   - 开启 debug.enablePprof 时注册 pprof 接口：GET {pprofPrefix}/ 为索引页，GET {pprofPrefix}/:name 为
+//     cmdline、profile、symbol、trace 及 heap、goroutine 等命名分析，POST {pprofPrefix}/symbol 查询符号；
+//     运行环境为 debug.productionEnv 且未开启 debug.allowInProduction 时不注册
+//   - 开启 debug.enableStartupTimings 时注册 GET /debug/startup，返回 GetStartupTimings()
+//
+// 返回：
+//   - []optionFunc: 未启用时为空
+//   - error: 未配置保护中间件、中间件未注册或路径前缀无效时返回错误
+func debugOptionFuncs() ([]optionFunc, error) {
+	cfg := app.BaseConfig.Debug
+	pprofEnabled := cfg.PprofAllowed(app.Env)
+	if cfg.EnablePprof && !pprofEnabled {
+		logger.Warn("[调试接口] 当前运行环境为 %s，未开启 debug.allowInProduction，不注册 pprof 接口", app.Env)
+	}
+	if !pprofEnabled && !cfg.EnableStartupTimings {
+		return nil, nil
+	}
+	if cfg.ProtectMiddleware == "" {
+		return nil, fmt.Errorf("调试接口: 未配置 debug.protectMiddleware，调试接口必须由中间件保护")
+	}
+
+	var routes []RouteDef
+	if pprofEnabled {
+		prefix := cfg.GetPprofPrefix()
+		if !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("调试接口: pprof 接口路径前缀 %q 必须以 / 开头", prefix)
+		}
+		routes = append(routes,
+			RouteDef{Method: http.MethodGet, Path: prefix + "/", Handler: gin.WrapF(pprof.Index)},
+			RouteDef{Method: http.MethodGet, Path: prefix + "/:name", Handler: pprofHandler},
+			RouteDef{Method: http.MethodPost, Path: prefix + "/symbol", Handler: gin.WrapF(pprof.Symbol)},
+		)
+	}
+	if cfg.EnableStartupTimings {
+		routes = append(routes, RouteDef{Method: http.MethodGet, Path: debugStartupPath, Handler: startupTimingsHandler})
+	}
+
+	fn, err := buildRoutes("", []string{cfg.ProtectMiddleware}, routes)
+	if err != nil {
+		return nil, fmt.Errorf("调试接口: %w", err)
+	}
+	return []optionFunc{{fn: fn, source: "core.debugOptionFuncs"}}, nil
+}
+
+// pprofHandler 按名称处理 pprof 分析请求
+// pprof.Index 只能从固定的 /debug/pprof/ 前缀中解析分析名称，因此在自定义前缀或路由前缀下按路径参数分发
+func pprofHandler(c *gin.Context) {
+	switch name := c.Param("name"); name {
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// startupTimingsHandler 返回启动耗时
+func startupTimingsHandler(c *gin.Context) {
+	response.OkWithData(c, GetStartupTimings())
+}
@@ -0,0 +1,189 @@
+// Package core 调试接口测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 pprof 接口和启动耗时接口的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 开启 pprof 后索引页和命名分析可访问，经过保护中间件
+// 2. 生产环境未开启 allowInProduction 时不注册 pprof 接口，开启后注册
+// 3. 启用调试接口但未配置保护中间件时启动失败
+// 4. 启动耗时接口包含各启动阶段和每个已初始化服务的耗时
+//
+// 运行测试：go test -v ./core/... -run Debug
+// ==================================================
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/core/lifecycle"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// setupDebugTest 初始化调试接口测试：设置运行环境和调试配置，注册拒绝缺少 X-Admin 请求头的 adminAuth 中间件
+func setupDebugTest(t *testing.T, env string, debug config.DebugConfig) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+	originalEnv := app.Env
+	app.Env = env
+	t.Cleanup(func() { app.Env = originalEnv })
+	app.BaseConfig.Debug = debug
+	middleWareMap["adminAuth"] = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if c.GetHeader("X-Admin") == "" {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			c.Next()
+		}
+	}
+}
+
+// debugRequest 携带 X-Admin 请求头发送 GET 请求
+func debugRequest(engine *gin.Engine, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("X-Admin", "1")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+// TestDebug_Pprof 测试开启 pprof 接口
+//
+// 【功能点】验证 pprof 接口挂载在路由前缀和自定义路径前缀下，经过保护中间件
+// 【测试流程】
+//  1. 开发环境开启 pprof，路径前缀为 /ops/pprof
+//  2. 未带请求头访问索引页断言返回 401
+//  3. 带请求头访问索引页、goroutine 分析和 cmdline，断言返回 200 且内容正确
+func TestDebug_Pprof(t *testing.T) {
+	setupDebugTest(t, "dev", config.DebugConfig{EnablePprof: true, PprofPrefix: "/ops/pprof/", ProtectMiddleware: "adminAuth"})
+
+	engine, err := initEngine()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/ops/pprof/", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = debugRequest(engine, "/api/ops/pprof/")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Types of profiles available")
+
+	w = debugRequest(engine, "/api/ops/pprof/goroutine?debug=1")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "goroutine profile")
+
+	w = debugRequest(engine, "/api/ops/pprof/cmdline")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestDebug_PprofInProduction 测试生产环境的 pprof 接口
+//
+// 【功能点】验证运行环境为生产环境时，未开启 allowInProduction 不注册 pprof 接口，开启后注册
+// 【测试流程】
+//  1. 运行环境为 prod，开启 pprof，断言索引页返回 404
+//  2. 自定义生产环境标识为 production，运行环境为 production，断言返回 404
+//  3. 开启 allowInProduction，断言索引页返回 200
+func TestDebug_PprofInProduction(t *testing.T) {
+	setupDebugTest(t, "prod", config.DebugConfig{EnablePprof: true, ProtectMiddleware: "adminAuth"})
+	engine, err := initEngine()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, debugRequest(engine, "/api/debug/pprof/").Code)
+
+	app.Env = "production"
+	app.BaseConfig.Debug.ProductionEnv = "production"
+	engine, err = initEngine()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, debugRequest(engine, "/api/debug/pprof/").Code)
+
+	app.BaseConfig.Debug.AllowInProduction = true
+	engine, err = initEngine()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, debugRequest(engine, "/api/debug/pprof/").Code)
+}
+
+// TestDebug_RequiresMiddleware 测试调试接口必须配置保护中间件
+//
+// 【功能点】验证开启 pprof 或启动耗时接口但未配置 protectMiddleware 时初始化引擎返回错误
+// 【测试流程】分别开启 pprof、启动耗时接口，断言初始化引擎返回包含 debug.protectMiddleware 的错误
+func TestDebug_RequiresMiddleware(t *testing.T) {
+	setupDebugTest(t, "dev", config.DebugConfig{EnablePprof: true})
+	_, err := initEngine()
+	assert.ErrorContains(t, err, "debug.protectMiddleware")
+
+	app.BaseConfig.Debug = config.DebugConfig{EnableStartupTimings: true}
+	_, err = initEngine()
+	assert.ErrorContains(t, err, "debug.protectMiddleware")
+}
+
+// debugTestService 初始化时等待指定时间的测试服务
+type debugTestService struct {
+	name  string
+	delay time.Duration
+}
+
+func (s *debugTestService) Name() string                           { return s.name }
+func (s *debugTestService) Priority() int                          { return 0 }
+func (s *debugTestService) Dependencies() []string                 { return nil }
+func (s *debugTestService) ShouldInit(cfg *config.BaseConfig) bool { return true }
+func (s *debugTestService) Close(ctx context.Context) error        { return nil }
+func (s *debugTestService) Init(ctx context.Context) error {
+	time.Sleep(s.delay)
+	return nil
+}
+
+// TestDebug_StartupTimings 测试启动耗时接口
+//
+// 【功能点】验证启动耗时接口返回各启动阶段和每个已初始化服务的耗时，耗时均大于 0
+// 【测试流程】
+//  1. 在独立的注册中心中注册 db、cache 两个服务并初始化，记录服务耗时
+//  2. 开启启动耗时接口初始化引擎，记录 buildEngine、registerRoutes 阶段
+//  3. 请求 /api/debug/startup，断言 services 依次为 cache、db 且耗时大于 0，phases 包含引擎阶段，totalMs 大于 0
+func TestDebug_StartupTimings(t *testing.T) {
+	setupDebugTest(t, "dev", config.DebugConfig{EnableStartupTimings: true, ProtectMiddleware: "adminAuth"})
+	originalTimings := startupTimings
+	startupTimings = &startupRecorder{}
+	t.Cleanup(func() { startupTimings = originalTimings })
+
+	registry := lifecycle.NewServiceRegistry()
+	require.NoError(t, registry.Register(&debugTestService{name: "db", delay: 2 * time.Millisecond}))
+	require.NoError(t, registry.Register(&debugTestService{name: "cache", delay: time.Millisecond}))
+	startupTimings.reset()
+	require.NoError(t, lifecycle.NewParallelInitializer(registry, lifecycle.DefaultInitConfig).Init(context.Background(), &app.BaseConfig))
+	startupTimings.recordServices(registry, &app.BaseConfig)
+
+	engine, err := initEngine()
+	require.NoError(t, err)
+	startupTimings.finish()
+
+	w := debugRequest(engine, "/api/debug/startup")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data StartupTimings `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+
+	require.Len(t, body.Data.Services, 2)
+	assert.Equal(t, "cache", body.Data.Services[0].Name)
+	assert.Equal(t, "db", body.Data.Services[1].Name)
+	for _, service := range body.Data.Services {
+		assert.Greater(t, service.DurationMs, 0.0, service.Name)
+	}
+	phases := map[string]float64{}
+	for _, phase := range body.Data.Phases {
+		phases[phase.Name] = phase.DurationMs
+	}
+	assert.Contains(t, phases, startupPhaseBuildEngine)
+	assert.Contains(t, phases, startupPhaseRegisterRoutes)
+	assert.Greater(t, body.Data.TotalMs, 0.0)
+}
@@ -1,21 +1,26 @@
 package core
 
 import (
-	"os"
+	"fmt"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/i18n"
 	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/middleware"
+	"github.com/zzsen/gin_core/model/config"
 	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"github.com/zzsen/gin_core/utils/netutil"
 
 	"github.com/gin-gonic/gin"
 )
 
-// optionFuncList 存储用户自定义的路由选项函数列表
+// optionFuncList 存储用户自定义的路由选项函数列表及其注册位置
 // 这些函数会在引擎初始化时被依次调用，用于注册用户自定义的路由
 // 支持动态添加多个路由配置函数，提供灵活的路由注册机制
-var optionFuncList = make([]gin.OptionFunc, 0)
+var optionFuncList = make([]optionFunc, 0)
 
 // optionFuncMu 保护 optionFuncList 的互斥锁
 // 确保并发调用 AddOptionFunc 时的线程安全
@@ -25,7 +30,8 @@ var optionFuncMu sync.Mutex
 // 允许用户注册自定义的路由配置函数，这些函数会在引擎初始化时被调用
 // 支持传入多个函数，函数会按照添加顺序执行
 // 该函数是线程安全的，可以在多个 goroutine 中并发调用
-// 参数 optionFunc: 一个或多个 gin.OptionFunc 类型的路由配置函数
+// 调用位置会被记录，用于路由列表输出和冲突提示
+// 参数 optionFuncs: 一个或多个 gin.OptionFunc 类型的路由配置函数
 //
 // 使用示例：
 //
@@ -33,10 +39,13 @@ var optionFuncMu sync.Mutex
 //	  r := e.Group("/api")
 //	  r.GET("/users", getUsersHandler)
 //	})
-func AddOptionFunc(optionFunc ...gin.OptionFunc) {
+func AddOptionFunc(optionFuncs ...gin.OptionFunc) {
+	source := callerSource(1)
 	optionFuncMu.Lock()
 	defer optionFuncMu.Unlock()
-	optionFuncList = append(optionFuncList, optionFunc...)
+	for _, fn := range optionFuncs {
+		optionFuncList = append(optionFuncList, optionFunc{fn: fn, source: source})
+	}
 }
 
 // healthDetactEngine 健康检查路由配置函数
@@ -102,21 +111,40 @@ var metricsEngine = func(e *gin.Engine) {
 	logger.Info("[server] Prometheus 指标端点已启用: %s", path)
 }
 
+// builtinOptionFuncs 框架内置路由，在用户路由之前注册，与用户路由一样参与冲突检测
+var builtinOptionFuncs = []optionFunc{
+	{fn: healthDetactEngine, source: "core.healthDetactEngine"},
+	{fn: metricsEngine, source: "core.metricsEngine"},
+}
+
 // initEngine 初始化Gin引擎
 // 这是Web服务器引擎的核心初始化函数，负责：
-// 1. 创建Gin引擎实例
+// 1. 创建Gin引擎实例，设置受信任的代理（service.trustedProxies）
 // 2. 配置统一路由前缀
-// 3. 注册Recovery中间件（异常恢复）
-// 4. 注册用户配置的中间件
+// 3. 注册Recovery中间件（异常恢复），开启 service.enableServerTiming 时在其之前注册请求耗时分解中间件
+// 4. 注册用户配置的中间件（按运行环境筛选，按 order 排序），开启 service.enforceEnvelope 时注册统一响应结构检查中间件，开启 service.enableServerTiming 时包装为记录自身耗时的中间件并在最后注册记录处理函数耗时的中间件
 // 5. 配置404和405错误处理
-// 6. 注册健康检查路由
-// 7. 应用用户自定义的路由配置
+// 6. 注册内置路由（健康检查、指标端点、运行信息接口、OpenAPI 文档接口、死信队列管理接口）、回调接口、用户自定义的路由配置和控制器
+// 7. 检测路由冲突（重复注册、超出路由前缀），并保存路由列表供 Routes 查询
 //
-// 返回值: 配置完成的 *gin.Engine 实例
-func initEngine() *gin.Engine {
+// 返回值:
+//   - *gin.Engine: 配置完成的引擎实例
+//   - error: 存在路由冲突且 service.routeConflictPolicy 为 error 时返回 *RouteConflictError；
+//     service.middlewares 中的中间件未注册、受信任的代理地址无法解析、控制器的路由声明有误、运行信息接口、OpenAPI 文档接口、死信队列管理接口、维护模式管理接口或功能开关管理接口的保护中间件未配置或未注册、回调接口配置有误时返回对应错误
+func initEngine() (*gin.Engine, error) {
 	// 创建新的Gin引擎实例（不包含默认中间件）
+	endBuildEngine := startupTimings.begin(startupPhaseBuildEngine)
 	engine := gin.New()
 
+	// 设置受信任的代理，c.ClientIP() 与 netutil.ClientIP 只读取来自这些地址的 X-Forwarded-For、X-Real-IP
+	// 未配置时不信任任何代理，客户端 IP 为直连地址，避免客户端伪造 IP 绕过限流
+	if err := applyGlobalSettings(&app.BaseConfig); err != nil {
+		return nil, err
+	}
+	if err := engine.SetTrustedProxies(app.BaseConfig.Service.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("service.trustedProxies 配置有误: %w", err)
+	}
+
 	// 配置统一路由前缀
 	// 如果配置文件中设置了路由前缀，所有路由都会添加该前缀
 	// 例如：设置前缀为 "/api/v1"，则所有路由都会变成 "/api/v1/xxx"
@@ -126,23 +154,38 @@ func initEngine() *gin.Engine {
 			app.BaseConfig.Service.RoutePrefix)
 	}
 
+	// 开启 service.enableServerTiming 时最先注册请求耗时分解中间件，之后注册的中间件均包装为记录各自耗时的中间件
+	serverTiming := app.BaseConfig.Service.EnableServerTiming
+	middlewareNames := make([]string, 0)
+	if serverTiming {
+		engine.Use(middleware.ServerTimingHandler(app.BaseConfig.Service.ServerTiming))
+		middlewareNames = append(middlewareNames, "serverTimingHandler")
+	}
+
 	// 添加Recovery中间件，用于捕获panic并恢复程序运行
 	// 防止单个请求的panic导致整个服务崩溃
-	engine.Use(gin.Recovery())
+	engine.Use(timedMiddlewares(serverTiming, []gin.HandlerFunc{gin.Recovery()}, []string{"recovery"})...)
+	middlewareNames = append(middlewareNames, "recovery")
 
 	// 注册用户配置的中间件
-	// 从配置文件中读取需要启用的中间件列表，并按顺序注册
-	useMiddlewares := app.BaseConfig.Service.Middlewares
-	if len(useMiddlewares) > 0 {
-		for _, useMiddleware := range useMiddlewares {
-			// 检查中间件是否已注册到中间件映射表中
-			if _, ok := middleWareMap[useMiddleware]; !ok {
-				logger.Error("[server] can not find %s middleware, please register first", useMiddleware)
-				os.Exit(1)
-			}
-			// 注册中间件到引擎
-			engine.Use(middleWareMap[useMiddleware]())
-		}
+	// 从配置文件中读取当前运行环境启用的中间件，按 order（默认为列表位置）排序后注册
+	handlers, names, err := configuredMiddlewares(app.BaseConfig.Service.Middlewares, app.Env)
+	if err != nil {
+		return nil, err
+	}
+	engine.Use(timedMiddlewares(serverTiming, handlers, names)...)
+	middlewareNames = append(middlewareNames, names...)
+
+	// 开启 service.enforceEnvelope 时在用户配置的中间件之后注册统一响应结构检查中间件，直接检查处理函数输出的响应
+	if app.BaseConfig.Service.EnforceEnvelope {
+		engine.Use(timedMiddlewares(serverTiming, []gin.HandlerFunc{middleware.EnvelopeHandler()}, []string{"envelopeHandler"})...)
+		middlewareNames = append(middlewareNames, "envelopeHandler")
+	}
+
+	// 在所有中间件之后记录处理函数的耗时
+	if serverTiming {
+		engine.Use(middleware.HandlerTiming())
+		middlewareNames = append(middlewareNames, "handlerTiming")
 	}
 
 	// 启用HTTP方法不允许的处理
@@ -152,21 +195,104 @@ func initEngine() *gin.Engine {
 	engine.NoMethod(MethodNotAllowed)
 	// 设置404错误（路由不存在）的处理函数
 	engine.NoRoute(NotFound)
+	endBuildEngine()
 
-	// 添加健康检查路由，用于检测服务是否正常运行
-	AddOptionFunc(healthDetactEngine)
-	// 添加 Prometheus 指标端点
-	AddOptionFunc(metricsEngine)
-
-	// 应用所有用户自定义的路由配置函数
-	// 这些函数在应用启动时通过 AddOptionFunc 注册
+	// 依次应用内置路由、运行信息接口、配置查看接口、调试接口、OpenAPI 文档接口、死信队列管理接口、熔断器和限流管理接口、维护模式管理接口、功能开关管理接口、通过 RegisterWebhook 注册的回调接口、用户通过 AddOptionFunc 注册的路由配置函数和 RegisterController 注册的控制器
 	// 获取 optionFuncList 的副本以确保线程安全
+	endRegisterRoutes := startupTimings.begin(startupPhaseRegisterRoutes)
+	runtimeInfoFuncs, err := runtimeInfoOptionFuncs()
+	if err != nil {
+		return nil, err
+	}
+	configInspectFuncs, err := configInspectOptionFuncs()
+	if err != nil {
+		return nil, err
+	}
+	debugFuncs, err := debugOptionFuncs()
+	if err != nil {
+		return nil, err
+	}
+	openAPIFuncs, err := openAPIOptionFuncs()
+	if err != nil {
+		return nil, err
+	}
+	mqAdminFuncs, err := mqAdminOptionFuncs()
+	if err != nil {
+		return nil, err
+	}
+	resilienceAdminFuncs, err := resilienceAdminOptionFuncs()
+	if err != nil {
+		return nil, err
+	}
+	maintenanceFuncs, err := maintenanceOptionFuncs()
+	if err != nil {
+		return nil, err
+	}
+	featureFlagsFuncs, err := featureFlagsAdminOptionFuncs()
+	if err != nil {
+		return nil, err
+	}
+	webhookFuncs, err := webhookOptionFuncs()
+	if err != nil {
+		return nil, err
+	}
+	controllerFuncs, err := controllerOptionFuncs()
+	if err != nil {
+		return nil, err
+	}
 	optionFuncMu.Lock()
-	optionFuncs := make([]gin.OptionFunc, len(optionFuncList))
-	copy(optionFuncs, optionFuncList)
+	optionFuncs := make([]optionFunc, 0, len(builtinOptionFuncs)+len(runtimeInfoFuncs)+len(configInspectFuncs)+len(debugFuncs)+len(openAPIFuncs)+len(mqAdminFuncs)+len(resilienceAdminFuncs)+len(maintenanceFuncs)+len(featureFlagsFuncs)+len(webhookFuncs)+len(optionFuncList)+len(controllerFuncs))
+	optionFuncs = append(optionFuncs, builtinOptionFuncs...)
+	optionFuncs = append(optionFuncs, runtimeInfoFuncs...)
+	optionFuncs = append(optionFuncs, configInspectFuncs...)
+	optionFuncs = append(optionFuncs, debugFuncs...)
+	optionFuncs = append(optionFuncs, openAPIFuncs...)
+	optionFuncs = append(optionFuncs, mqAdminFuncs...)
+	optionFuncs = append(optionFuncs, resilienceAdminFuncs...)
+	optionFuncs = append(optionFuncs, maintenanceFuncs...)
+	optionFuncs = append(optionFuncs, featureFlagsFuncs...)
+	optionFuncs = append(optionFuncs, webhookFuncs...)
+	optionFuncs = append(optionFuncs, optionFuncList...)
 	optionFuncMu.Unlock()
+	optionFuncs = append(optionFuncs, controllerFuncs...)
+
+	routes, conflictErr := applyOptionFuncs(engine, optionFuncs, app.BaseConfig.Service.RoutePrefix, middlewareNames)
+	setRoutes(routes)
+	endRegisterRoutes()
+	if conflictErr != nil {
+		if app.BaseConfig.Service.GetRouteConflictPolicy() != config.RouteConflictWarn {
+			return nil, conflictErr
+		}
+		logger.Error("[server] %s", conflictErr.Error())
+	}
 
-	engine.With(optionFuncs...)
+	return engine, nil
+}
+
+// applyGlobalSettings 按配置设置请求处理中使用的包级全局选项
+// 包括受信任的代理、是否按响应码输出 HTTP 状态码、解析 JSON 请求体的大小上限、默认语言区域、JSON 时间格式和功能开关
+func applyGlobalSettings(cfg *config.BaseConfig) error {
+	if err := netutil.SetTrustedProxies(cfg.Service.TrustedProxies); err != nil {
+		return fmt.Errorf("service.trustedProxies 配置有误: %w", err)
+	}
+
+	// 设置响应是否按响应码输出对应的 HTTP 状态码，响应体格式不变
+	response.SetUseHTTPStatus(cfg.Service.UseHTTPStatus)
+
+	// 设置 ginContext.Get 解析 JSON 请求体的大小上限，超过时不读取请求体
+	ginContext.SetMaxParseBodyBytes(cfg.Service.GetMaxParseBodyBytes())
+
+	// 设置响应消息的默认语言区域，请求未协商出语言区域时使用
+	i18n.SetDefaultLocale(cfg.I18n.GetDefaultLocale())
+
+	// 设置 response.LocalTime 的 JSON 时间格式和时区
+	location, err := cfg.Service.JSONTime.GetLocation()
+	if err != nil {
+		return fmt.Errorf("service.jsonTime.timezone 配置有误: %w", err)
+	}
+	response.SetJSONTime(cfg.Service.JSONTime.GetLayout(), location)
 
-	return engine
+	// 按当前运行环境加载功能开关
+	loadFeatureFlags(cfg.FeatureFlags)
+	return nil
 }
@@ -24,11 +24,20 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/zzsen/gin_core/app"
 	"github.com/zzsen/gin_core/model/config"
 )
 
+// mustInitEngine 初始化引擎，失败时终止测试
+func mustInitEngine(t *testing.T) *gin.Engine {
+	t.Helper()
+	engine, err := initEngine()
+	require.NoError(t, err)
+	return engine
+}
+
 // ==================== AddOptionFunc 测试 ====================
 // 测试选项函数的注册功能
 
@@ -42,7 +51,7 @@ import (
 //  4. 测试添加nil函数 - 验证nil也被添加到列表
 func TestAddOptionFunc(t *testing.T) {
 	// 清空选项函数列表，确保测试环境干净
-	optionFuncList = make([]gin.OptionFunc, 0)
+	optionFuncList = make([]optionFunc, 0)
 
 	t.Run("add single option function", func(t *testing.T) {
 		// 测试添加单个选项函数
@@ -54,12 +63,12 @@ func TestAddOptionFunc(t *testing.T) {
 
 		AddOptionFunc(optionFunc)
 		assert.Len(t, optionFuncList, 1)
-		assert.NotNil(t, optionFuncList[0])
+		assert.NotNil(t, optionFuncList[0].fn)
 	})
 
 	t.Run("add multiple option functions", func(t *testing.T) {
 		// 清空列表
-		optionFuncList = make([]gin.OptionFunc, 0)
+		optionFuncList = make([]optionFunc, 0)
 
 		// 测试添加多个选项函数
 		optionFunc1 := func(e *gin.Engine) {
@@ -75,13 +84,13 @@ func TestAddOptionFunc(t *testing.T) {
 
 		AddOptionFunc(optionFunc1, optionFunc2)
 		assert.Len(t, optionFuncList, 2)
-		assert.NotNil(t, optionFuncList[0])
-		assert.NotNil(t, optionFuncList[1])
+		assert.NotNil(t, optionFuncList[0].fn)
+		assert.NotNil(t, optionFuncList[1].fn)
 	})
 
 	t.Run("add empty option functions", func(t *testing.T) {
 		// 清空列表
-		optionFuncList = make([]gin.OptionFunc, 0)
+		optionFuncList = make([]optionFunc, 0)
 
 		// 测试添加空参数
 		AddOptionFunc()
@@ -90,12 +99,12 @@ func TestAddOptionFunc(t *testing.T) {
 
 	t.Run("add nil option function", func(t *testing.T) {
 		// 清空列表
-		optionFuncList = make([]gin.OptionFunc, 0)
+		optionFuncList = make([]optionFunc, 0)
 
 		// 测试添加nil函数
 		AddOptionFunc(nil)
 		assert.Len(t, optionFuncList, 1)
-		assert.Nil(t, optionFuncList[0])
+		assert.Nil(t, optionFuncList[0].fn)
 	})
 }
 
@@ -175,14 +184,14 @@ func TestInitEngine(t *testing.T) {
 	}()
 
 	// 清空选项函数列表
-	optionFuncList = make([]gin.OptionFunc, 0)
+	optionFuncList = make([]optionFunc, 0)
 
 	t.Run("init engine without route prefix", func(t *testing.T) {
 		// 设置测试配置
 		app.BaseConfig = config.BaseConfig{
 			Service: config.ServiceInfo{
 				RoutePrefix: "",
-				Middlewares: []string{},
+				Middlewares: config.MiddlewareList{},
 			},
 		}
 
@@ -190,20 +199,20 @@ func TestInitEngine(t *testing.T) {
 		middleWareMap = make(map[string]func() gin.HandlerFunc)
 
 		// 初始化引擎
-		engine := initEngine()
+		engine := mustInitEngine(t)
 		assert.NotNil(t, engine)
 		assert.NotNil(t, engine.RouterGroup)
 	})
 
 	t.Run("init engine with route prefix", func(t *testing.T) {
-		// 清空选项函数列表，避免健康检查路由冲突
-		optionFuncList = make([]gin.OptionFunc, 0)
+		// 清空选项函数列表，避免之前用例注册的路由影响当前用例
+		optionFuncList = make([]optionFunc, 0)
 
 		// 设置测试配置
 		app.BaseConfig = config.BaseConfig{
 			Service: config.ServiceInfo{
 				RoutePrefix: "/api/v1",
-				Middlewares: []string{},
+				Middlewares: config.MiddlewareList{},
 			},
 		}
 
@@ -211,20 +220,20 @@ func TestInitEngine(t *testing.T) {
 		middleWareMap = make(map[string]func() gin.HandlerFunc)
 
 		// 初始化引擎
-		engine := initEngine()
+		engine := mustInitEngine(t)
 		assert.NotNil(t, engine)
 		assert.NotNil(t, engine.RouterGroup)
 	})
 
 	t.Run("init engine with middlewares", func(t *testing.T) {
-		// 清空选项函数列表，避免健康检查路由冲突
-		optionFuncList = make([]gin.OptionFunc, 0)
+		// 清空选项函数列表，避免之前用例注册的路由影响当前用例
+		optionFuncList = make([]optionFunc, 0)
 
 		// 设置测试配置
 		app.BaseConfig = config.BaseConfig{
 			Service: config.ServiceInfo{
 				RoutePrefix: "",
-				Middlewares: []string{"testMiddleware"},
+				Middlewares: config.MiddlewareNames("testMiddleware"),
 			},
 		}
 
@@ -239,40 +248,41 @@ func TestInitEngine(t *testing.T) {
 		})
 
 		// 初始化引擎
-		engine := initEngine()
+		engine := mustInitEngine(t)
 		assert.NotNil(t, engine)
 		assert.NotNil(t, engine.RouterGroup)
 	})
 
 	t.Run("init engine with unknown middleware", func(t *testing.T) {
-		// 清空选项函数列表，避免健康检查路由冲突
-		optionFuncList = make([]gin.OptionFunc, 0)
+		// 清空选项函数列表，避免之前用例注册的路由影响当前用例
+		optionFuncList = make([]optionFunc, 0)
 
 		// 设置测试配置
 		app.BaseConfig = config.BaseConfig{
 			Service: config.ServiceInfo{
 				RoutePrefix: "",
-				Middlewares: []string{"unknownMiddleware"},
+				Middlewares: config.MiddlewareNames("unknownMiddleware"),
 			},
 		}
 
 		// 清空中间件映射表
 		middleWareMap = make(map[string]func() gin.HandlerFunc)
 
-		// 这个测试会调用os.Exit(1)，所以我们需要在子进程中运行
-		// 这里我们只验证配置设置正确
-		assert.Equal(t, "unknownMiddleware", app.BaseConfig.Service.Middlewares[0])
+		// 未注册的中间件导致引擎初始化失败，错误信息包含配置项位置、名称和运行环境
+		assert.Equal(t, "unknownMiddleware", app.BaseConfig.Service.Middlewares[0].Name)
+		_, err := initEngine()
+		assert.ErrorContains(t, err, "service.middlewares[0] 中间件 unknownMiddleware 未注册")
 	})
 
 	t.Run("init engine with custom option functions", func(t *testing.T) {
-		// 清空选项函数列表，避免健康检查路由冲突
-		optionFuncList = make([]gin.OptionFunc, 0)
+		// 清空选项函数列表，避免之前用例注册的路由影响当前用例
+		optionFuncList = make([]optionFunc, 0)
 
 		// 设置测试配置
 		app.BaseConfig = config.BaseConfig{
 			Service: config.ServiceInfo{
 				RoutePrefix: "",
-				Middlewares: []string{},
+				Middlewares: config.MiddlewareList{},
 			},
 		}
 
@@ -287,7 +297,7 @@ func TestInitEngine(t *testing.T) {
 		})
 
 		// 初始化引擎
-		engine := initEngine()
+		engine := mustInitEngine(t)
 		assert.NotNil(t, engine)
 		assert.NotNil(t, engine.RouterGroup)
 	})
@@ -315,7 +325,7 @@ func TestEngineFeatures(t *testing.T) {
 	app.BaseConfig = config.BaseConfig{
 		Service: config.ServiceInfo{
 			RoutePrefix: "",
-			Middlewares: []string{},
+			Middlewares: config.MiddlewareList{},
 		},
 	}
 
@@ -323,11 +333,11 @@ func TestEngineFeatures(t *testing.T) {
 	middleWareMap = make(map[string]func() gin.HandlerFunc)
 
 	// 清空选项函数列表
-	optionFuncList = make([]gin.OptionFunc, 0)
+	optionFuncList = make([]optionFunc, 0)
 
 	t.Run("engine has recovery middleware", func(t *testing.T) {
 		// 初始化引擎
-		engine := initEngine()
+		engine := mustInitEngine(t)
 		assert.NotNil(t, engine)
 
 		// 创建测试请求
@@ -347,11 +357,11 @@ func TestEngineFeatures(t *testing.T) {
 	})
 
 	t.Run("engine has method not allowed handler", func(t *testing.T) {
-		// 清空选项函数列表，避免健康检查路由冲突
-		optionFuncList = make([]gin.OptionFunc, 0)
+		// 清空选项函数列表，避免之前用例注册的路由影响当前用例
+		optionFuncList = make([]optionFunc, 0)
 
 		// 初始化引擎
-		engine := initEngine()
+		engine := mustInitEngine(t)
 		assert.NotNil(t, engine)
 
 		// 添加一个只支持GET的路由
@@ -371,11 +381,11 @@ func TestEngineFeatures(t *testing.T) {
 	})
 
 	t.Run("engine has not found handler", func(t *testing.T) {
-		// 清空选项函数列表，避免健康检查路由冲突
-		optionFuncList = make([]gin.OptionFunc, 0)
+		// 清空选项函数列表，避免之前用例注册的路由影响当前用例
+		optionFuncList = make([]optionFunc, 0)
 
 		// 初始化引擎
-		engine := initEngine()
+		engine := mustInitEngine(t)
 		assert.NotNil(t, engine)
 
 		// 创建请求到不存在的路由
@@ -390,11 +400,11 @@ func TestEngineFeatures(t *testing.T) {
 	})
 
 	t.Run("engine has health check route", func(t *testing.T) {
-		// 清空选项函数列表，避免健康检查路由冲突
-		optionFuncList = make([]gin.OptionFunc, 0)
+		// 清空选项函数列表，避免之前用例注册的路由影响当前用例
+		optionFuncList = make([]optionFunc, 0)
 
 		// 初始化引擎
-		engine := initEngine()
+		engine := mustInitEngine(t)
 		assert.NotNil(t, engine)
 
 		// 创建健康检查请求
@@ -437,7 +447,7 @@ func TestEngineWithCustomRoutes(t *testing.T) {
 	app.BaseConfig = config.BaseConfig{
 		Service: config.ServiceInfo{
 			RoutePrefix: "/api/v1",
-			Middlewares: []string{},
+			Middlewares: config.MiddlewareList{},
 		},
 	}
 
@@ -445,7 +455,7 @@ func TestEngineWithCustomRoutes(t *testing.T) {
 	middleWareMap = make(map[string]func() gin.HandlerFunc)
 
 	// 清空选项函数列表
-	optionFuncList = make([]gin.OptionFunc, 0)
+	optionFuncList = make([]optionFunc, 0)
 
 	t.Run("engine with route prefix and custom routes", func(t *testing.T) {
 		// 添加自定义路由
@@ -459,7 +469,7 @@ func TestEngineWithCustomRoutes(t *testing.T) {
 		})
 
 		// 初始化引擎
-		engine := initEngine()
+		engine := mustInitEngine(t)
 		assert.NotNil(t, engine)
 
 		// 测试GET /api/v1/users
@@ -478,7 +488,7 @@ func TestEngineWithCustomRoutes(t *testing.T) {
 
 	t.Run("engine with multiple custom routes", func(t *testing.T) {
 		// 清空选项函数列表
-		optionFuncList = make([]gin.OptionFunc, 0)
+		optionFuncList = make([]optionFunc, 0)
 
 		// 添加多个自定义路由
 		AddOptionFunc(func(e *gin.Engine) {
@@ -494,7 +504,7 @@ func TestEngineWithCustomRoutes(t *testing.T) {
 		})
 
 		// 初始化引擎
-		engine := initEngine()
+		engine := mustInitEngine(t)
 		assert.NotNil(t, engine)
 
 		// 测试所有路由
@@ -516,10 +526,10 @@ func TestEngineWithCustomRoutes(t *testing.T) {
 // 【功能点】验证引擎初始化的并发安全性
 // 【测试流程】
 //  1. 设置基础配置
-//  2. 初始化引擎
-//  3. 验证引擎和RouterGroup非空
+//  2. 多次初始化引擎
+//  3. 验证每次初始化都成功，引擎和RouterGroup非空，且都注册了健康检查路由
 //
-// 【注意】由于健康检查路由的全局特性，仅测试单引擎初始化
+// 【注意】内置路由不再追加到全局选项函数列表，多次初始化不会重复注册
 func TestConcurrentEngineInit(t *testing.T) {
 	// 保存原始配置
 	originalConfig := app.BaseConfig
@@ -531,7 +541,7 @@ func TestConcurrentEngineInit(t *testing.T) {
 	app.BaseConfig = config.BaseConfig{
 		Service: config.ServiceInfo{
 			RoutePrefix: "",
-			Middlewares: []string{},
+			Middlewares: config.MiddlewareList{},
 		},
 	}
 
@@ -539,12 +549,16 @@ func TestConcurrentEngineInit(t *testing.T) {
 	middleWareMap = make(map[string]func() gin.HandlerFunc)
 
 	t.Run("concurrent engine initialization", func(t *testing.T) {
-		// 由于健康检查路由的全局特性，并发测试会导致路由冲突
-		// 这里只测试单个引擎初始化
-		optionFuncList = make([]gin.OptionFunc, 0)
+		optionFuncList = make([]optionFunc, 0)
 
-		engine := initEngine()
-		assert.NotNil(t, engine)
-		assert.NotNil(t, engine.RouterGroup)
+		for i := 0; i < 3; i++ {
+			engine := mustInitEngine(t)
+			assert.NotNil(t, engine)
+			assert.NotNil(t, engine.RouterGroup)
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest("GET", "/healthy", nil))
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
 	})
 }
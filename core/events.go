@@ -0,0 +1,40 @@
+package core
+
+import (
+	"context"
+
+	"github.com/zzsen/gin_core/events"
+)
+
+// SubscribeEvent 订阅进程内事件
+// 可以在 core.Start 之前订阅；同步处理函数在 PublishEvent 的协程中按订阅顺序执行，
+// 使用 events.Async() 时投递到有界队列异步处理，队列容量和协程数由 events 配置
+// 参数：
+//   - topic: 主题，由 . 分隔为多段，* 匹配任意一段，末尾的 # 匹配剩余的零段或多段，如 user.*、order.#
+//   - handler: 处理函数
+//   - opts: 订阅选项，如 events.Async()、events.WithTimeout(5*time.Second)
+//
+// 返回：
+//   - error: 主题格式错误或处理函数为 nil 时返回错误
+//
+// 使用示例：
+//
+//	_ = core.SubscribeEvent("user.created", func(ctx context.Context, payload any) error {
+//	    user := payload.(*model.User)
+//	    return email.SendWelcome(ctx, user.Email)
+//	}, events.Async(), events.WithTimeout(10*time.Second))
+func SubscribeEvent(topic string, handler events.Handler, opts ...events.SubOption) error {
+	return events.Subscribe(topic, handler, opts...)
+}
+
+// PublishEvent 发布进程内事件
+// 参数：
+//   - ctx: 发布上下文，异步处理函数继承其中的值（如追踪 ID）但不继承其取消
+//   - topic: 主题，如 user.created
+//   - payload: 事件数据
+//
+// 返回：
+//   - error: 同步处理函数的错误，以及异步事件因队列已满或总线已关闭被丢弃时的 events.ErrQueueFull / events.ErrBusClosed
+func PublishEvent(ctx context.Context, topic string, payload any) error {
+	return events.Publish(ctx, topic, payload)
+}
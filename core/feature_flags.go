@@ -0,0 +1,62 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/featureflags"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// featureFlagsAdminPath 功能开关管理接口的路径，位于 service.routePrefix 之下
+const featureFlagsAdminPath = "/admin/feature-flags"
+
+// loadFeatureFlags 按 featureFlags 配置和当前运行环境加载功能开关
+// featureflags.IsEnabled 从请求上下文中获取用户 ID：*gin.Context 使用 ginContext.GetUserID，其他上下文使用 ginContext.FromStdContext
+func loadFeatureFlags(flags config.FeatureFlags) {
+	featureflags.SetUserIDFunc(featureFlagUserID)
+	featureflags.Load(flags, app.Env)
+}
+
+// featureFlagUserID 从请求上下文中获取功能开关判断使用的用户 ID
+func featureFlagUserID(ctx context.Context) string {
+	if c, ok := ctx.(*gin.Context); ok {
+		userID, _ := ginContext.GetUserID(c)
+		return userID
+	}
+	if rc, ok := ginContext.FromStdContext(ctx); ok {
+		return rc.UserID()
+	}
+	return ""
+}
+
+// featureFlagsAdminOptionFuncs 功能开关管理接口的路由选项函数
+// 配置 featureFlagsAdmin.middleware 时注册以下路由，由该中间件保护：
+//   - GET /admin/feature-flags - 所有功能开关的配置、在当前运行环境是否生效，以及未知开关的判断次数
+//
+// 返回：
+//   - []optionFunc: 未配置 featureFlagsAdmin.middleware 时为空
+//   - error: 保护中间件未注册时返回错误
+func featureFlagsAdminOptionFuncs() ([]optionFunc, error) {
+	cfg := app.BaseConfig.FeatureFlagsAdmin
+	if cfg.Middleware == "" {
+		return nil, nil
+	}
+	fn, err := buildRoutes(featureFlagsAdminPath, []string{cfg.Middleware}, []RouteDef{
+		{Method: http.MethodGet, Path: "", Handler: featureFlagsHandler},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("功能开关管理接口: %w", err)
+	}
+	return []optionFunc{{fn: fn, source: "core.featureFlagsAdminOptionFuncs"}}, nil
+}
+
+// featureFlagsHandler 获取所有功能开关的生效状态
+func featureFlagsHandler(c *gin.Context) {
+	response.OkWithData(c, featureflags.Flags())
+}
@@ -0,0 +1,143 @@
+// Package core 功能开关测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含功能开关的加载、请求上下文中的用户 ID 和管理接口的单元测试，通过 initEngine 创建完整的引擎，不需要外部依赖。
+// 灰度、运行环境和白名单的判断规则见 featureflags 包的测试。
+//
+// 测试覆盖内容：
+// 1. 初始化引擎时按 featureFlags 配置和当前运行环境加载功能开关
+// 2. ginContext.Feature 和 featureflags.IsEnabled(c.Request.Context()) 使用认证中间件设置的用户 ID
+// 3. 配置 featureFlagsAdmin.middleware 时注册管理接口，由保护中间件保护，返回开关的生效状态和未知开关的计数
+//
+// 运行测试：go test -v ./core/... -run FeatureFlags
+// ==================================================
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/featureflags"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// TestFeatureFlags_RequestUser 测试按请求的用户判断功能开关
+//
+// 【功能点】验证初始化引擎后加载功能开关，处理函数中按认证中间件设置的用户 ID 判断
+// 【测试流程】
+//  1. 配置灰度比例为 0、allowUsers=[u-1] 的 beta 开关，运行环境为 prod，注册设置用户 ID 的 auth 中间件
+//  2. 处理函数返回 ginContext.Feature 和 featureflags.IsEnabled(c.Request.Context()) 的结果
+//  3. 断言 u-1 均为 true，u-2 和未登录均为 false；未配置管理中间件时不注册管理接口
+func TestFeatureFlags_RequestUser(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api", Middlewares: config.MiddlewareNames("auth")})
+	originalEnv := app.Env
+	app.Env = "prod"
+	t.Cleanup(func() {
+		app.Env = originalEnv
+		featureflags.Reset()
+	})
+	zero := 0.0
+	app.BaseConfig.FeatureFlags = config.FeatureFlags{"beta": {Enabled: true, Percentage: &zero, AllowUsers: []string{"u-1"}}}
+	middleWareMap["auth"] = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if userID := c.GetHeader("X-User"); userID != "" {
+				ginContext.SetUserID(c, userID)
+			}
+			c.Next()
+		}
+	}
+	AddOptionFunc(func(e *gin.Engine) {
+		e.GET("/beta", func(c *gin.Context) {
+			c.JSON(http.StatusOK, []bool{ginContext.Feature(c, "beta"), featureflags.IsEnabled(c.Request.Context(), "beta")})
+		})
+	})
+	engine, err := initEngine()
+	require.NoError(t, err)
+	for _, r := range Routes() {
+		assert.NotEqual(t, "/api"+featureFlagsAdminPath, r.Path)
+	}
+
+	for user, want := range map[string]bool{"u-1": true, "u-2": false, "": false} {
+		req := httptest.NewRequest(http.MethodGet, "/api/beta", nil)
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var got []bool
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, []bool{want, want}, got, user)
+	}
+}
+
+// TestFeatureFlags_AdminRoute 测试功能开关管理接口
+//
+// 【功能点】验证管理接口由保护中间件保护，返回运行环境、开关的生效状态和未知开关的计数；保护中间件未注册时启动失败
+// 【测试流程】
+//  1. 配置 featureFlagsAdmin.middleware 为未注册的中间件，断言初始化引擎返回错误
+//  2. 注册 adminAuth（缺少 X-Admin 请求头时返回 401），配置 checkout（只在 prod 生效）和 search 开关，运行环境为 test
+//  3. 未携带 X-Admin 请求头时断言返回 401
+//  4. 判断一次未知的开关后请求管理接口，断言 env、各开关的 active 和 percentage、未知开关的计数
+func TestFeatureFlags_AdminRoute(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+	originalEnv := app.Env
+	app.Env = "test"
+	t.Cleanup(func() {
+		app.Env = originalEnv
+		featureflags.Reset()
+	})
+	app.BaseConfig.FeatureFlagsAdmin.Middleware = "adminAuth"
+	_, err := initEngine()
+	assert.ErrorContains(t, err, "中间件 adminAuth 未注册")
+
+	half := 50.0
+	app.BaseConfig.FeatureFlags = config.FeatureFlags{
+		"checkout": {Enabled: true, AllowEnvs: []string{"prod"}},
+		"search":   {Enabled: true, Percentage: &half, Attributes: map[string]string{"owner": "search-team"}},
+	}
+	middleWareMap["adminAuth"] = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if c.GetHeader("X-Admin") == "" {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			c.Next()
+		}
+	}
+	engine, err := initEngine()
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/feature-flags", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	assert.False(t, featureflags.IsEnabledFor("serach", "u-1"))
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/feature-flags", nil)
+	req.Header.Set("X-Admin", "ops")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Code int                   `json:"code"`
+		Data featureflags.Snapshot `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+	assert.Equal(t, response.ResponseSuccess.GetCode(), body.Code)
+	assert.Equal(t, "test", body.Data.Env)
+	require.Len(t, body.Data.Flags, 2)
+	assert.Equal(t, "checkout", body.Data.Flags[0].Name)
+	assert.False(t, body.Data.Flags[0].Active)
+	assert.Equal(t, 100.0, body.Data.Flags[0].Percentage)
+	assert.True(t, body.Data.Flags[1].Active)
+	assert.Equal(t, 50.0, body.Data.Flags[1].Percentage)
+	assert.Equal(t, "search-team", body.Data.Flags[1].Attributes["owner"])
+	assert.Equal(t, map[string]int64{"serach": 1}, body.Data.Unknown)
+}
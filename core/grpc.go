@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/middleware"
+	"github.com/zzsen/gin_core/model/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
+)
+
+var (
+	// grpcServices 通过 RegisterGrpcService 注册的 gRPC 服务
+	grpcServices []func(*grpc.Server)
+	// grpcServicesMu 保护 grpcServices 的并发访问
+	grpcServicesMu sync.Mutex
+)
+
+// RegisterGrpcService 注册 gRPC 服务，需在 core.Start 之前调用，配置中启用 grpc 时生效
+//
+// 参数：
+//   - register: 注册函数，在 gRPC 服务创建后调用
+//
+// 使用示例：
+//
+//	core.RegisterGrpcService(func(s *grpc.Server) {
+//	  pb.RegisterOrderServiceServer(s, &orderServer{})
+//	})
+func RegisterGrpcService(register func(*grpc.Server)) {
+	grpcServicesMu.Lock()
+	defer grpcServicesMu.Unlock()
+	grpcServices = append(grpcServices, register)
+}
+
+// newGrpcServer 根据配置创建 gRPC 服务，挂载默认拦截器和已注册的服务
+// 拦截器按顺序为追踪 ID、请求日志、异常恢复，与 HTTP 服务的 traceIdHandler、traceLogHandler、exceptionHandler 对应
+func newGrpcServer(cfg config.GrpcConfig, shared bool) (*grpc.Server, error) {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(cfg.GetMaxRecvMsgSize()),
+		grpc.ChainUnaryInterceptor(middleware.GrpcUnaryInterceptors()...),
+		grpc.ChainStreamInterceptor(middleware.GrpcStreamInterceptors()...),
+	}
+	if cfg.TLS.Enabled() && !shared {
+		creds, err := credentials.NewServerTLSFromFile(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载 gRPC TLS 证书失败: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	server := grpc.NewServer(opts...)
+	grpcServicesMu.Lock()
+	for _, register := range grpcServices {
+		register(server)
+	}
+	grpcServicesMu.Unlock()
+	if cfg.EnableReflection {
+		reflection.Register(server)
+	}
+	return server, nil
+}
+
+// grpcHandler 与 HTTP 服务共用端口时的请求分流：HTTP/2 且 Content-Type 为 application/grpc 的请求交给 gRPC 服务，其余交给 gin 引擎
+func grpcHandler(grpcServer *grpc.Server, httpHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		httpHandler.ServeHTTP(w, r)
+	})
+}
+
+// enableSharedGrpc 让 HTTP 服务与 gRPC 服务共用端口
+// 开启未加密的 HTTP/2（h2c）以接收 gRPC 请求，关闭时由 http.Server.Shutdown 等待进行中的调用结束
+func enableSharedGrpc(server *http.Server, grpcServer *grpc.Server) {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	server.Protocols = protocols
+	server.Handler = grpcHandler(grpcServer, server.Handler)
+}
+
+// serveGrpc 在独立端口启动 gRPC 服务（阻塞调用）
+func serveGrpc(grpcServer *grpc.Server, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return grpcServer.Serve(lis)
+}
+
+// stopGrpcServer 优雅关闭独立端口的 gRPC 服务
+// 等待进行中的调用结束，超过 timeout 时强制关闭
+func stopGrpcServer(grpcServer *grpc.Server, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Warn("[grpc] 优雅关闭超时，强制关闭 gRPC 服务")
+		grpcServer.Stop()
+		<-done
+	}
+}
@@ -0,0 +1,329 @@
+// Package core gRPC 服务测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 gRPC 服务的单元测试，注册一个回显服务并使用真实的 gRPC 客户端调用，不需要外部服务。
+//
+// 测试覆盖内容：
+// 1. 独立端口的一元调用：追踪 ID 透传与生成、panic 转换为 codes.Internal、反射服务
+// 2. 优雅关闭时等待进行中的流式调用结束，超过关闭超时时间后强制关闭
+// 3. 与 HTTP 服务共用端口：HTTP 请求与 gRPC 调用分流，HTTP 服务关闭时等待进行中的流式调用结束
+//
+// 运行测试：go test -v ./core/... -run Grpc
+// ==================================================
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/model/config"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ==================== 测试辅助：回显服务 ====================
+
+// echoServiceDesc 回显服务：Echo 返回 "消息|追踪 ID"，消息为 "panic" 时 panic；
+// Count 按请求的数量每隔 countInterval 返回一个序号
+var echoServiceDesc = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*any)(nil),
+	Methods:     []grpc.MethodDesc{{MethodName: "Echo", Handler: echoHandler}},
+	Streams:     []grpc.StreamDesc{{StreamName: "Count", Handler: countHandler, ServerStreams: true}},
+}
+
+// countInterval Count 每条消息的间隔
+const countInterval = 50 * time.Millisecond
+
+func echoHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(wrapperspb.StringValue)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		msg := req.(*wrapperspb.StringValue).GetValue()
+		if msg == "panic" {
+			panic("echo panic")
+		}
+		traceID := ""
+		if rc, ok := ginContext.FromStdContext(ctx); ok {
+			traceID = rc.TraceID()
+		}
+		return wrapperspb.String(msg + "|" + traceID), nil
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Echo/Echo"}, handler)
+}
+
+func countHandler(srv any, stream grpc.ServerStream) error {
+	in := new(wrapperspb.Int32Value)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	for i := int32(0); i < in.GetValue(); i++ {
+		if err := stream.SendMsg(wrapperspb.Int32(i)); err != nil {
+			return err
+		}
+		time.Sleep(countInterval)
+	}
+	return nil
+}
+
+// setupGrpcTest 清空已注册的 gRPC 服务并注册回显服务，测试结束后恢复
+func setupGrpcTest(t *testing.T) {
+	grpcServicesMu.Lock()
+	original := grpcServices
+	grpcServices = nil
+	grpcServicesMu.Unlock()
+	t.Cleanup(func() {
+		grpcServicesMu.Lock()
+		grpcServices = original
+		grpcServicesMu.Unlock()
+	})
+	RegisterGrpcService(func(s *grpc.Server) {
+		s.RegisterService(&echoServiceDesc, struct{}{})
+	})
+}
+
+// startGrpcServer 在随机端口启动独立的 gRPC 服务，返回服务和客户端连接
+func startGrpcServer(t *testing.T, cfg config.GrpcConfig) (*grpc.Server, *grpc.ClientConn) {
+	t.Helper()
+	server, err := newGrpcServer(cfg, false)
+	if err != nil {
+		t.Fatalf("创建 gRPC 服务失败: %v", err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+	return server, dialGrpc(t, lis.Addr().String())
+}
+
+// dialGrpc 创建不加密的 gRPC 客户端连接
+func dialGrpc(t *testing.T, addr string) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("创建 gRPC 客户端失败: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// callEcho 调用 Echo，返回响应和响应头元数据
+func callEcho(ctx context.Context, conn *grpc.ClientConn, msg string) (string, metadata.MD, error) {
+	out := new(wrapperspb.StringValue)
+	var header metadata.MD
+	err := conn.Invoke(ctx, "/test.Echo/Echo", wrapperspb.String(msg), out, grpc.Header(&header))
+	return out.GetValue(), header, err
+}
+
+// startCount 调用 Count 并返回流
+func startCount(t *testing.T, conn *grpc.ClientConn, n int32) grpc.ClientStream {
+	t.Helper()
+	stream, err := conn.NewStream(context.Background(), &grpc.StreamDesc{ServerStreams: true}, "/test.Echo/Count")
+	if err != nil {
+		t.Fatalf("创建流失败: %v", err)
+	}
+	if err := stream.SendMsg(wrapperspb.Int32(n)); err != nil {
+		t.Fatalf("发送请求失败: %v", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("关闭发送失败: %v", err)
+	}
+	return stream
+}
+
+// recvCount 接收 Count 的消息直到流结束，返回收到的消息数和结束时的错误（正常结束时为 nil）
+func recvCount(stream grpc.ClientStream) (int, error) {
+	received := 0
+	for {
+		if err := stream.RecvMsg(new(wrapperspb.Int32Value)); err != nil {
+			if errors.Is(err, io.EOF) {
+				return received, nil
+			}
+			return received, err
+		}
+		received++
+	}
+}
+
+// ==================== 独立端口 ====================
+
+// TestGrpcServer_Unary 测试独立端口的一元调用
+//
+// 【功能点】验证拦截器透传或生成追踪 ID、将 panic 转换为 codes.Internal，开启反射时注册反射服务
+// 【测试流程】
+//  1. 携带 x-trace-id 元数据调用 Echo，断言处理函数读取到相同的追踪 ID，响应头返回该追踪 ID
+//  2. 不携带追踪 ID 调用，断言响应头返回生成的追踪 ID
+//  3. 调用会 panic 的消息，断言返回 codes.Internal，服务仍可继续处理请求
+//  4. 断言已注册反射服务
+func TestGrpcServer_Unary(t *testing.T) {
+	setupGrpcTest(t)
+	server, conn := startGrpcServer(t, config.GrpcConfig{Enabled: true, EnableReflection: true})
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-trace-id", "trace-grpc-1")
+	resp, header, err := callEcho(ctx, conn, "hello")
+	if err != nil {
+		t.Fatalf("调用失败: %v", err)
+	}
+	if resp != "hello|trace-grpc-1" {
+		t.Errorf("处理函数应读取到上游的追踪 ID, 实际响应 %q", resp)
+	}
+	if got := header.Get("x-trace-id"); len(got) != 1 || got[0] != "trace-grpc-1" {
+		t.Errorf("响应头应返回追踪 ID, 实际 %v", got)
+	}
+
+	_, header, err = callEcho(context.Background(), conn, "hello")
+	if err != nil || len(header.Get("x-trace-id")) != 1 || header.Get("x-trace-id")[0] == "" {
+		t.Errorf("未传递追踪 ID 时应生成, 实际 %v, err: %v", header.Get("x-trace-id"), err)
+	}
+
+	if _, _, err := callEcho(context.Background(), conn, "panic"); status.Code(err) != codes.Internal {
+		t.Errorf("panic 应返回 codes.Internal, 实际 %v", err)
+	}
+	if _, _, err := callEcho(context.Background(), conn, "again"); err != nil {
+		t.Errorf("panic 后服务应继续处理请求, 实际 %v", err)
+	}
+
+	if _, ok := server.GetServiceInfo()["grpc.reflection.v1.ServerReflection"]; !ok {
+		t.Error("开启 enableReflection 时应注册反射服务")
+	}
+}
+
+// TestGrpcServer_GracefulStop 测试独立端口的优雅关闭
+//
+// 【功能点】验证关闭时等待进行中的流式调用结束，超过关闭超时时间后强制关闭
+// 【测试流程】
+//  1. 调用返回 5 条消息的 Count，收到第 1 条后以 2s 超时关闭服务
+//  2. 断言客户端收到全部 5 条消息且流正常结束，关闭在流结束后返回，之后的新调用失败
+//  3. 重新启动服务，调用返回 100 条消息的 Count，以 100ms 超时关闭
+//  4. 断言关闭在超时后返回，客户端的流以错误结束
+func TestGrpcServer_GracefulStop(t *testing.T) {
+	setupGrpcTest(t)
+
+	t.Run("drain in-flight stream", func(t *testing.T) {
+		server, conn := startGrpcServer(t, config.GrpcConfig{Enabled: true})
+		stream := startCount(t, conn, 5)
+		if err := stream.RecvMsg(new(wrapperspb.Int32Value)); err != nil {
+			t.Fatalf("接收第一条消息失败: %v", err)
+		}
+
+		stopped := make(chan struct{})
+		go func() {
+			stopGrpcServer(server, 2*time.Second)
+			close(stopped)
+		}()
+
+		received, err := recvCount(stream)
+		if err != nil || received != 4 {
+			t.Fatalf("期望收到剩余 4 条消息并正常结束, 实际 %d 条, err: %v", received, err)
+		}
+		select {
+		case <-stopped:
+		case <-time.After(2 * time.Second):
+			t.Fatal("流结束后关闭应返回")
+		}
+		if _, _, err := callEcho(context.Background(), conn, "hello"); err == nil {
+			t.Error("关闭后的新调用应失败")
+		}
+	})
+
+	t.Run("force stop after timeout", func(t *testing.T) {
+		server, conn := startGrpcServer(t, config.GrpcConfig{Enabled: true})
+		stream := startCount(t, conn, 100)
+		if err := stream.RecvMsg(new(wrapperspb.Int32Value)); err != nil {
+			t.Fatalf("接收第一条消息失败: %v", err)
+		}
+
+		start := time.Now()
+		stopGrpcServer(server, 100*time.Millisecond)
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("关闭应在超时后返回, 实际耗时 %v", elapsed)
+		}
+		if _, err := recvCount(stream); err == nil {
+			t.Error("强制关闭后流应以错误结束")
+		}
+	})
+}
+
+// ==================== 共用端口 ====================
+
+// TestGrpcServer_SharedPort 测试与 HTTP 服务共用端口
+//
+// 【功能点】验证 HTTP 请求与 gRPC 调用按协议分流，HTTP 服务关闭时等待进行中的流式调用结束
+// 【测试流程】
+//  1. 创建 gin 引擎并挂载 gRPC 服务，在同一端口启动
+//  2. 断言 HTTP GET 返回 gin 路由的响应，gRPC Echo 调用成功
+//  3. 调用返回 5 条消息的 Count，收到第 1 条后关闭 HTTP 服务
+//  4. 断言客户端收到全部消息且流正常结束，关闭成功返回
+func TestGrpcServer_SharedPort(t *testing.T) {
+	setupGrpcTest(t)
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+
+	grpcServer, err := newGrpcServer(config.GrpcConfig{Enabled: true}, true)
+	if err != nil {
+		t.Fatalf("创建 gRPC 服务失败: %v", err)
+	}
+	t.Cleanup(grpcServer.Stop)
+	server := &http.Server{Handler: engine}
+	enableSharedGrpc(server, grpcServer)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	go func() { _ = server.Serve(lis) }()
+	addr := lis.Addr().String()
+
+	resp, err := http.Get("http://" + addr + "/ping")
+	if err != nil {
+		t.Fatalf("HTTP 请求失败: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "pong" {
+		t.Errorf("HTTP 请求应由 gin 处理, 实际 %d %s", resp.StatusCode, body)
+	}
+
+	conn := dialGrpc(t, addr)
+	if msg, _, err := callEcho(context.Background(), conn, "hello"); err != nil || msg == "" {
+		t.Fatalf("gRPC 调用失败: %v", err)
+	}
+
+	stream := startCount(t, conn, 5)
+	if err := stream.RecvMsg(new(wrapperspb.Int32Value)); err != nil {
+		t.Fatalf("接收第一条消息失败: %v", err)
+	}
+	shutdownErr := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		shutdownErr <- server.Shutdown(ctx)
+	}()
+
+	received, err := recvCount(stream)
+	if err != nil || received != 4 {
+		t.Fatalf("期望收到剩余 4 条消息并正常结束, 实际 %d 条, err: %v", received, err)
+	}
+	if err := <-shutdownErr; err != nil {
+		t.Errorf("HTTP 服务关闭失败: %v", err)
+	}
+}
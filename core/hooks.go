@@ -2,8 +2,10 @@ package core
 
 import (
 	"context"
+	"time"
 
 	"github.com/zzsen/gin_core/core/lifecycle"
+	"github.com/zzsen/gin_core/initialize"
 )
 
 // RegisterAppHook 注册应用级生命周期钩子
@@ -49,6 +51,13 @@ func OnBeforeShutdown(fn func(ctx context.Context) error) {
 	})
 }
 
+// OnSlowQuery 注册数据库慢查询回调
+// 执行耗时超过 db.slowThreshold 的 SQL 都会触发回调（不受 logLevel 影响），适用于慢查询指标统计；
+// 回调在执行 SQL 的协程中同步调用，应避免耗时操作
+func OnSlowQuery(fn func(sql string, elapsed time.Duration, rows int64)) {
+	initialize.AddSlowQueryHook(fn)
+}
+
 // OnAfterShutdown 注册应用关闭后钩子
 // 在所有服务关闭完成、进程退出前执行
 func OnAfterShutdown(fn func(ctx context.Context) error) {
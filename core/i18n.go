@@ -0,0 +1,22 @@
+package core
+
+import "github.com/zzsen/gin_core/i18n"
+
+// RegisterMessages 注册国际化消息目录
+// 追加或覆盖指定语言区域的消息，响应消息和参数校验消息按请求的语言区域从消息目录中解析
+// 应在 Start 之前调用（如 main 函数或 init 函数中）
+//
+// 参数：
+//   - locale: 语言区域，如 "en-US"、"ja-JP"
+//   - messages: 消息 ID 到消息文本的映射，键可以是响应码字符串（如 "60001"）、
+//     框架消息 ID（如 "exception.unknown"、"validation.required"）或应用自定义的消息 ID
+//
+// 使用示例：
+//
+//	core.RegisterMessages("en-US", map[string]string{
+//	  "60001":          "Order not found",
+//	  "order.canceled": "Order has been canceled",
+//	})
+func RegisterMessages(locale string, messages map[string]string) {
+	i18n.Register(locale, messages)
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	ginjson "github.com/gin-gonic/gin/codec/json"
+	"github.com/zzsen/gin_core/utils/jsoncodec"
+)
+
+// jsonCodecState JSON 编解码器的设置状态
+// 只允许在 Start 之前设置，请求处理过程中编解码器不会改变
+var jsonCodecState = struct {
+	mu     sync.Mutex
+	frozen bool
+}{}
+
+// SetJSONCodec 设置框架使用的 JSON 编解码器
+// 设置后框架自身的序列化路径（流式响应、ginContext.Get 解析请求体、审计日志、serialize 等）
+// 以及 gin 的 c.JSON 渲染和 ShouldBindJSON 参数绑定都使用该编解码器，应用无需修改业务代码即可切换 sonic、jsoniter 等实现。
+// 替换实现前应确认其与标准库在 HTML 转义、数字精度、map 键排序上的差异是否可以接受。
+//
+// 参数：
+//   - codec: JSON 编解码器，不能为 nil
+//
+// 返回：
+//   - error: codec 为 nil 或在 Start 之后调用时返回错误
+//
+// 使用示例：
+//
+//	type sonicCodec struct{}
+//	func (sonicCodec) Marshal(v any) ([]byte, error) { return sonic.ConfigStd.Marshal(v) }
+//	// ... Unmarshal、MarshalIndent、NewEncoder、NewDecoder
+//
+//	func main() {
+//	  if err := core.SetJSONCodec(sonicCodec{}); err != nil {
+//	    panic(err)
+//	  }
+//	  core.Start()
+//	}
+func SetJSONCodec(codec jsoncodec.Codec) error {
+	if codec == nil {
+		return errors.New("[JSON] 编解码器不能为 nil")
+	}
+	jsonCodecState.mu.Lock()
+	defer jsonCodecState.mu.Unlock()
+	if jsonCodecState.frozen {
+		return errors.New("[JSON] 服务已启动, 无法设置编解码器")
+	}
+	jsoncodec.Set(codec)
+	ginjson.API = ginJSONAPI{codec: codec}
+	return nil
+}
+
+// freezeJSONCodec 禁止继续设置 JSON 编解码器，在 Start 开始时调用
+func freezeJSONCodec() {
+	jsonCodecState.mu.Lock()
+	defer jsonCodecState.mu.Unlock()
+	jsonCodecState.frozen = true
+}
+
+// ginJSONAPI 将 jsoncodec.Codec 适配为 gin 的 JSON 编解码接口
+type ginJSONAPI struct {
+	codec jsoncodec.Codec
+}
+
+func (a ginJSONAPI) Marshal(v any) ([]byte, error) {
+	return a.codec.Marshal(v)
+}
+
+func (a ginJSONAPI) Unmarshal(data []byte, v any) error {
+	return a.codec.Unmarshal(data, v)
+}
+
+func (a ginJSONAPI) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return a.codec.MarshalIndent(v, prefix, indent)
+}
+
+func (a ginJSONAPI) NewEncoder(w io.Writer) ginjson.Encoder {
+	return a.codec.NewEncoder(w)
+}
+
+func (a ginJSONAPI) NewDecoder(r io.Reader) ginjson.Decoder {
+	return a.codec.NewDecoder(r)
+}
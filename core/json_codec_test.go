@@ -0,0 +1,114 @@
+// Package core JSON 编解码器设置功能测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 SetJSONCodec 的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 设置的编解码器同时用于框架的 jsoncodec 包、gin 的 c.JSON 渲染和 ShouldBindJSON 参数绑定
+// 2. 参数为 nil 和 Start 之后设置返回错误
+//
+// 运行测试：go test -v ./core/... -run JSONCodec
+// ==================================================
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	ginjson "github.com/gin-gonic/gin/codec/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/utils/jsoncodec"
+)
+
+// countingCodec 记录调用次数的编解码器，委托标准库实现
+type countingCodec struct {
+	jsoncodec.Codec
+	marshal *atomic.Int32
+	decode  *atomic.Int32
+}
+
+func newCountingCodec() countingCodec {
+	return countingCodec{Codec: jsoncodec.Standard(), marshal: &atomic.Int32{}, decode: &atomic.Int32{}}
+}
+
+func (c countingCodec) Marshal(v any) ([]byte, error) {
+	c.marshal.Add(1)
+	return c.Codec.Marshal(v)
+}
+
+func (c countingCodec) NewDecoder(r io.Reader) jsoncodec.Decoder {
+	c.decode.Add(1)
+	return c.Codec.NewDecoder(r)
+}
+
+// resetJSONCodec 测试结束后恢复默认编解码器和设置状态
+func resetJSONCodec(t *testing.T) {
+	originalAPI := ginjson.API
+	t.Cleanup(func() {
+		ginjson.API = originalAPI
+		jsoncodec.Set(nil)
+		jsonCodecState.mu.Lock()
+		jsonCodecState.frozen = false
+		jsonCodecState.mu.Unlock()
+	})
+}
+
+// TestSetJSONCodec 测试设置 JSON 编解码器
+//
+// 【功能点】验证设置的编解码器用于 jsoncodec 包、gin 的 c.JSON 渲染和 ShouldBindJSON 参数绑定
+// 【测试流程】
+//  1. 设置 countingCodec，断言 jsoncodec.Get 返回该编解码器
+//  2. 发送 JSON 请求体到调用 ShouldBindJSON 和 c.JSON 的路由，断言响应正确且编解码器的 Marshal 和 NewDecoder 被调用
+func TestSetJSONCodec(t *testing.T) {
+	resetJSONCodec(t)
+	codec := newCountingCodec()
+	require.NoError(t, SetJSONCodec(codec))
+	assert.IsType(t, countingCodec{}, jsoncodec.Get())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/echo", func(c *gin.Context) {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"name": req.Name})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{"name":"gin"}`))
+	req.Header.Set("Content-Type", gin.MIMEJSON)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name":"gin"}`, w.Body.String())
+	assert.Equal(t, int32(1), codec.marshal.Load())
+	assert.Equal(t, int32(1), codec.decode.Load())
+}
+
+// TestSetJSONCodec_Errors 测试设置 JSON 编解码器的错误情况
+//
+// 【功能点】验证参数为 nil 和 Start 之后设置返回错误，且不改变当前编解码器
+// 【测试流程】
+//  1. 传入 nil，断言返回错误
+//  2. 调用 freezeJSONCodec 模拟 Start 之后，设置 countingCodec 断言返回错误且 jsoncodec.Get 仍为标准库
+func TestSetJSONCodec_Errors(t *testing.T) {
+	resetJSONCodec(t)
+
+	assert.Error(t, SetJSONCodec(nil))
+
+	freezeJSONCodec()
+	err := SetJSONCodec(newCountingCodec())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "服务已启动")
+	assert.IsType(t, jsoncodec.Standard(), jsoncodec.Get())
+}
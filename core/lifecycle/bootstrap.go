@@ -46,6 +46,29 @@ func AddMessageQueueConsumer(messageQueue *config.MessageQueue) {
 		messageQueue.GetInfo(), messageQueue.GetFuncInfo())
 }
 
+// consumerMiddlewares 全局消费中间件，启动时加到每个消费者自身的中间件之前
+var consumerMiddlewares []config.ConsumerMiddleware
+
+// UseConsumerMiddleware 添加全局消费中间件，作用于所有通过 AddMessageQueueConsumer 添加的消费者
+// 全局中间件按添加顺序执行，且在消费者通过 MessageQueue.Use 添加的中间件之前执行
+//
+// 参数 mw: 消费中间件
+func UseConsumerMiddleware(mw ...config.ConsumerMiddleware) {
+	consumerMiddlewares = append(consumerMiddlewares, mw...)
+}
+
+// ApplyConsumerMiddlewares 将全局消费中间件加到已添加的消费者上，服务启动时调用一次
+func ApplyConsumerMiddlewares() {
+	if len(consumerMiddlewares) == 0 {
+		return
+	}
+	for _, mq := range messageQueueConsumerList {
+		middlewares := make([]config.ConsumerMiddleware, 0, len(consumerMiddlewares)+len(mq.Middlewares))
+		middlewares = append(middlewares, consumerMiddlewares...)
+		mq.Middlewares = append(middlewares, mq.Middlewares...)
+	}
+}
+
 // AddMessageQueueProducer 添加消息队列发送者配置
 // 将消息队列发送者配置添加到全局列表中，在服务启动时会自动初始化这些发送者
 // 支持多种消息队列类型和交换机模式，提供灵活的消息发送能力
@@ -83,9 +106,12 @@ var scheduleList []config.ScheduleInfo = make([]config.ScheduleInfo, 0)
 //
 // 参数 schedule: 定时任务配置对象，包含Cron表达式和执行函数
 //
+// 返回：cron 表达式或时区无效时返回包含任务名称的错误，任务不会被添加
+//
 // Cron表达式格式：
-// - 秒 分 时 日 月 周
+// - 分 时 日 月 周，或 秒 分 时 日 月 周（6 段或设置 WithSeconds 时）
 // - 支持特殊表达式如 @every 30s, @hourly, @daily 等
+// - 通过 Timezone 指定时区，如 Asia/Shanghai
 //
 // 功能特性：
 // - 支持多个任务并发执行
@@ -103,12 +129,20 @@ var scheduleList []config.ScheduleInfo = make([]config.ScheduleInfo, 0)
 //	  Cron: "@every 5m",  // 每5分钟执行
 //	  Cmd:  healthCheck,
 //	})
-func AddSchedule(schedule config.ScheduleInfo) {
-	scheduleList = append(scheduleList, schedule)
-	if schedule.Name == "" {
-		schedule.Name = schedule.GetFuncInfo()
+//
+//	AddSchedule(config.ScheduleInfo{
+//	  Cron:     "0 0 9 * * *",  // 每天北京时间 9 点整执行
+//	  Timezone: "Asia/Shanghai",
+//	  Cmd:      dailyReport,
+//	})
+func AddSchedule(schedule config.ScheduleInfo) error {
+	if _, err := schedule.ParseCron(); err != nil {
+		logger.Error("[定时任务] 添加定时任务失败, error: %v", err)
+		return err
 	}
-	logger.Info("[定时任务] 添加定时任务成功, cron表达式: %s, 名称: %s", schedule.Cron, schedule.Name)
+	scheduleList = append(scheduleList, schedule)
+	logger.Info("[定时任务] 添加定时任务成功, cron表达式: %s, 名称: %s", schedule.Cron, schedule.GetName())
+	return nil
 }
 
 // GetMessageQueueConsumerList 获取消息队列消费者列表
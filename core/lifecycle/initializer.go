@@ -2,6 +2,7 @@ package lifecycle
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -192,6 +193,9 @@ func (p *ParallelInitializer) initServiceWithTimeout(ctx context.Context, name s
 	case err := <-done:
 		return err
 	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return fmt.Errorf("服务 %s 初始化已取消", name)
+		}
 		return fmt.Errorf("服务 %s 初始化超时", name)
 	}
 }
@@ -250,8 +254,13 @@ func (p *ParallelInitializer) Close(ctx context.Context, baseConfig *config.Base
 // --- 全局便捷函数 ---
 
 // InitAllServices 初始化所有已注册的服务
+// 启用 system.startupRetry 时，单个服务的初始化超时延长 maxWait，避免等待依赖服务期间超时
 func InitAllServices(ctx context.Context, baseConfig *config.BaseConfig) error {
-	initializer := NewParallelInitializer(globalRegistry, globalInitConfig)
+	cfg := globalInitConfig
+	if retry := baseConfig.System.StartupRetry; retry.Enabled && cfg.Timeout > 0 {
+		cfg.Timeout += retry.GetMaxWait()
+	}
+	initializer := NewParallelInitializer(globalRegistry, cfg)
 	return initializer.Init(ctx, baseConfig)
 }
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/zzsen/gin_core/logger"
 	"github.com/zzsen/gin_core/model/config"
@@ -13,11 +14,12 @@ import (
 // ServiceRegistry 服务注册中心
 // 管理所有服务的注册、初始化和关闭，同时管理应用级生命周期钩子
 type ServiceRegistry struct {
-	services map[string]Service      // 已注册的服务
-	hooks    map[string][]Hook       // 服务钩子
-	appHooks []AppHook               // 应用级生命周期钩子
-	states   map[string]ServiceState // 服务状态
-	mu       sync.RWMutex            // 读写锁
+	services      map[string]Service       // 已注册的服务
+	hooks         map[string][]Hook        // 服务钩子
+	appHooks      []AppHook                // 应用级生命周期钩子
+	states        map[string]ServiceState  // 服务状态
+	initDurations map[string]time.Duration // 初始化成功的服务的耗时（包括初始化前后钩子）
+	mu            sync.RWMutex             // 读写锁
 }
 
 // 全局服务注册中心实例
@@ -26,9 +28,10 @@ var globalRegistry = NewServiceRegistry()
 // NewServiceRegistry 创建新的服务注册中心
 func NewServiceRegistry() *ServiceRegistry {
 	return &ServiceRegistry{
-		services: make(map[string]Service),
-		hooks:    make(map[string][]Hook),
-		states:   make(map[string]ServiceState),
+		services:      make(map[string]Service),
+		hooks:         make(map[string][]Hook),
+		states:        make(map[string]ServiceState),
+		initDurations: make(map[string]time.Duration),
 	}
 }
 
@@ -158,6 +161,7 @@ func (r *ServiceRegistry) InitService(ctx context.Context, name string) error {
 
 	// 设置为初始化中
 	r.SetState(name, StateInitializing)
+	start := time.Now()
 
 	// 执行初始化前钩子
 	if err := r.ExecuteHooks(ctx, name, BeforeInit); err != nil {
@@ -179,12 +183,31 @@ func (r *ServiceRegistry) InitService(ctx context.Context, name string) error {
 		return err
 	}
 
-	// 设置为就绪
-	r.SetState(name, StateReady)
-	logger.Info("[服务初始化] 服务 %s 初始化成功", name)
+	// 设置为就绪，记录初始化耗时
+	duration := time.Since(start)
+	r.mu.Lock()
+	r.states[name] = StateReady
+	r.initDurations[name] = duration
+	r.mu.Unlock()
+	logger.Info("[服务初始化] 服务 %s 初始化成功, 耗时: %s", name, duration)
 	return nil
 }
 
+// GetInitDuration 获取服务的初始化耗时
+// 参数：
+//   - name: 服务名称
+//
+// 返回：
+//   - time.Duration: 初始化耗时，包括初始化前后钩子
+//   - bool: 服务未初始化成功时返回 false
+func (r *ServiceRegistry) GetInitDuration(name string) (time.Duration, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	duration, ok := r.initDurations[name]
+	return duration, ok
+}
+
 // CloseService 关闭单个服务
 func (r *ServiceRegistry) CloseService(ctx context.Context, name string) error {
 	service, exists := r.GetService(name)
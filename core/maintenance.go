@@ -0,0 +1,61 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/middleware"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// maintenanceRequest 切换维护模式的请求体
+type maintenanceRequest struct {
+	// Enabled 是否开启维护模式
+	Enabled *bool `json:"enabled"`
+	// Message 维护提示消息，为空时使用 maintenance.message 配置
+	Message string `json:"message"`
+}
+
+// maintenanceOptionFuncs 维护模式管理接口的路由选项函数
+// 配置 maintenance.middleware 时注册以下路由，均由该中间件保护，维护期间始终放行：
+//   - GET  /admin/maintenance - 当前的维护模式状态
+//   - POST /admin/maintenance - 开启或关闭维护模式，请求体为 {"enabled": true, "message": "..."}
+//
+// 切换操作会记录操作人（ginContext.GetUserID）和客户端 IP
+//
+// 返回：
+//   - []optionFunc: 未配置 maintenance.middleware 时为空
+//   - error: 保护中间件未注册时返回错误
+func maintenanceOptionFuncs() ([]optionFunc, error) {
+	cfg := app.BaseConfig.Maintenance
+	if cfg.Middleware == "" {
+		return nil, nil
+	}
+	fn, err := buildRoutes(middleware.MaintenanceAdminPath, []string{cfg.Middleware}, []RouteDef{
+		{Method: http.MethodGet, Path: "", Handler: maintenanceStatusHandler},
+		{Method: http.MethodPost, Path: "", Handler: setMaintenanceHandler},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("维护模式管理接口: %w", err)
+	}
+	return []optionFunc{{fn: fn, source: "core.maintenanceOptionFuncs"}}, nil
+}
+
+// maintenanceStatusHandler 获取当前的维护模式状态
+func maintenanceStatusHandler(c *gin.Context) {
+	response.OkWithData(c, app.MaintenanceStatus())
+}
+
+// setMaintenanceHandler 开启或关闭维护模式，返回切换后的状态
+func setMaintenanceHandler(c *gin.Context) {
+	var req maintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Enabled == nil {
+		response.Result(c, response.ResponseParamInvalid.GetCode(), map[string]any{}, "请求体须为 {\"enabled\": true|false, \"message\": \"...\"}")
+		return
+	}
+	state := app.SetMaintenanceModeBy(*req.Enabled, strings.TrimSpace(req.Message), adminOperator(c))
+	response.OkWithData(c, state)
+}
@@ -0,0 +1,142 @@
+// Package core 维护模式管理接口测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含维护模式管理接口的单元测试，通过 initEngine 创建完整的引擎，不需要外部依赖。
+// 放行规则和限流配额见 middleware 包的 TestMaintenanceHandler_*。
+//
+// 测试覆盖内容：
+// 1. 未配置 maintenance.middleware 时不注册管理接口，保护中间件未注册时启动失败
+// 2. 通过管理接口开启、关闭维护模式，维护期间业务接口返回 503 和 Retry-After，健康检查接口可以访问
+// 3. 切换维护模式的日志记录操作人，管理接口由保护中间件保护
+//
+// 运行测试：go test -v ./core/... -run Maintenance
+// ==================================================
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/middleware"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// TestMaintenance_MiddlewareRequired 测试维护模式管理接口的注册条件
+//
+// 【功能点】验证未配置保护中间件时不注册管理接口，保护中间件未注册时初始化引擎返回错误
+// 【测试流程】
+//  1. 未配置 maintenance.middleware 时初始化引擎，断言路由列表中没有 /admin/maintenance
+//  2. 配置未注册的中间件名称，断言错误信息
+func TestMaintenance_MiddlewareRequired(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+	_, err := initEngine()
+	require.NoError(t, err)
+	for _, r := range Routes() {
+		assert.NotContains(t, r.Path, middleware.MaintenanceAdminPath)
+	}
+
+	app.BaseConfig.Maintenance.Middleware = "adminAuth"
+	_, err = initEngine()
+	assert.ErrorContains(t, err, "中间件 adminAuth 未注册")
+}
+
+// TestMaintenance_AdminToggle 测试通过管理接口切换维护模式
+//
+// 【功能点】验证管理接口开启维护模式后业务接口返回 503，健康检查接口和管理接口仍可访问，关闭后恢复
+// 【测试流程】
+//  1. 启用 maintenanceHandler，注册 adminAuth 保护中间件（缺少 X-Admin 请求头时返回 401）和 GET /api/orders
+//  2. 未携带 X-Admin 请求头切换维护模式，断言返回 401 且维护模式未开启
+//  3. 开启维护模式，断言响应中的状态和操作人，日志包含操作人
+//  4. 断言 /api/orders 返回 503、Retry-After: 120、51503 响应码和维护消息，/api/healthy 返回 200，状态接口返回 enabled=true
+//  5. 关闭维护模式，断言 /api/orders 恢复 200；请求体缺少 enabled 时返回参数校验不通过
+func TestMaintenance_AdminToggle(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api", Middlewares: config.MiddlewareNames("maintenanceHandler"), UseHTTPStatus: true})
+	app.BaseConfig.Maintenance = config.MaintenanceConfig{RetryAfterSeconds: 120, Middleware: "adminAuth"}
+	t.Cleanup(func() {
+		app.ResetMaintenanceMode()
+		response.SetUseHTTPStatus(false)
+	})
+	middleWareMap["maintenanceHandler"] = middleware.MaintenanceHandler
+	middleWareMap["adminAuth"] = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			operator := c.GetHeader("X-Admin")
+			if operator == "" {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			ginContext.SetUserID(c, operator)
+			c.Next()
+		}
+	}
+	AddOptionFunc(func(e *gin.Engine) {
+		e.GET("/orders", func(c *gin.Context) { response.Ok(c) })
+	})
+	engine, err := initEngine()
+	require.NoError(t, err)
+
+	do := func(method, path, admin, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if admin != "" {
+			req.Header.Set("X-Admin", admin)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) response.Response {
+		var body response.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+		return body
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "/api/admin/maintenance", "", `{"enabled":true}`).Code)
+	assert.False(t, app.MaintenanceStatus().Enabled)
+
+	hook := logtest.NewLocal(logger.Logger)
+	defer hook.Reset()
+	w := do(http.MethodPost, "/api/admin/maintenance", "ops", `{"enabled":true,"message":"数据库迁移中"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	state, ok := decode(w).Data.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, true, state["enabled"])
+	assert.Equal(t, "数据库迁移中", state["message"])
+	assert.Contains(t, state["operator"], "ops@")
+	var logged bool
+	for _, entry := range hook.AllEntries() {
+		if strings.Contains(entry.Message, "开启维护模式") && strings.Contains(entry.Message, "operator: ops@") {
+			logged = true
+		}
+	}
+	assert.True(t, logged, "切换维护模式应记录操作人")
+
+	w = do(http.MethodGet, "/api/orders", "", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "120", w.Header().Get("Retry-After"))
+	body := decode(w)
+	assert.Equal(t, response.ResponseMaintenance.GetCode(), body.Code)
+	assert.Equal(t, "数据库迁移中", body.Msg)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/healthy", "", "").Code)
+	w = do(http.MethodGet, "/api/admin/maintenance", "ops", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, true, decode(w).Data.(map[string]any)["enabled"])
+
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/api/admin/maintenance", "ops", `{"enabled":false}`).Code)
+	assert.False(t, app.MaintenanceStatus().Enabled)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/orders", "", "").Code)
+
+	w = do(http.MethodPost, "/api/admin/maintenance", "ops", `{"message":"x"}`)
+	assert.Equal(t, response.ResponseParamInvalid.GetCode(), decode(w).Code)
+}
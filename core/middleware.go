@@ -2,11 +2,13 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/zzsen/gin_core/logger"
 	"github.com/zzsen/gin_core/middleware"
+	"github.com/zzsen/gin_core/model/config"
 )
 
 // middleWareMap 中间件注册映射表
@@ -16,7 +18,17 @@ import (
 // 这种设计模式允许通过配置文件动态启用/禁用中间件，提高了系统的灵活性
 var middleWareMap = make(map[string]func() gin.HandlerFunc)
 
-// middlewareMutex 保护middleWareMap并发访问的互斥锁
+// configurableMiddlewareMap 接收配置的中间件注册映射表
+// key: 中间件名称，与 middleWareMap 共用名称空间
+// value: 中间件工厂函数，参数为 service.middlewares 中该项的 config
+var configurableMiddlewareMap = make(map[string]func(cfg map[string]any) gin.HandlerFunc)
+
+// MiddlewareFactory 中间件工厂函数，可以不接收参数，也可以接收 service.middlewares 中该项的 config
+type MiddlewareFactory interface {
+	func() gin.HandlerFunc | func(cfg map[string]any) gin.HandlerFunc
+}
+
+// middlewareMutex 保护middleWareMap、configurableMiddlewareMap并发访问的互斥锁
 var middlewareMutex sync.RWMutex
 
 // getMiddleware 安全地获取中间件处理函数 (仅用于测试)
@@ -39,21 +51,50 @@ func clearMiddlewares() {
 	middlewareMutex.Lock()
 	defer middlewareMutex.Unlock()
 	middleWareMap = make(map[string]func() gin.HandlerFunc)
+	configurableMiddlewareMap = make(map[string]func(cfg map[string]any) gin.HandlerFunc)
 }
 
 // getMiddlewareCount 安全地获取中间件数量（仅用于测试）
 func getMiddlewareCount() int {
 	middlewareMutex.RLock()
 	defer middlewareMutex.RUnlock()
-	return len(middleWareMap)
+	return len(middleWareMap) + len(configurableMiddlewareMap)
 }
 
 // hasMiddleware 安全地检查中间件是否存在（仅用于测试）
 func hasMiddleware(name string) bool {
 	middlewareMutex.RLock()
 	defer middlewareMutex.RUnlock()
-	_, exists := middleWareMap[name]
-	return exists
+	return middlewareRegistered(name)
+}
+
+// middlewareRegistered 检查中间件名称是否已注册，调用方需持有 middlewareMutex
+func middlewareRegistered(name string) bool {
+	if _, ok := middleWareMap[name]; ok {
+		return true
+	}
+	_, ok := configurableMiddlewareMap[name]
+	return ok
+}
+
+// newMiddlewareHandler 通过注册的工厂函数创建中间件处理函数，调用方需持有 middlewareMutex
+// 不接收配置的中间件会忽略 cfg
+//
+// 返回：
+//   - gin.HandlerFunc: 中间件处理函数
+//   - bool: 中间件是否已注册
+func newMiddlewareHandler(name string, cfg map[string]any) (gin.HandlerFunc, bool) {
+	if factory, ok := configurableMiddlewareMap[name]; ok {
+		return factory(cfg), true
+	}
+	factory, ok := middleWareMap[name]
+	if !ok {
+		return nil, false
+	}
+	if len(cfg) > 0 {
+		logger.Warn("[server] 中间件 %s 不接收配置，已忽略 config", name)
+	}
+	return factory(), true
 }
 
 // RegisterMiddleware 注册中间件到映射表
@@ -62,7 +103,8 @@ func hasMiddleware(name string) bool {
 //
 // 参数：
 //   - name: 中间件名称，必须唯一，用于在配置文件中引用
-//   - handlerFunc: 中间件工厂函数，返回gin.HandlerFunc类型的处理函数
+//   - handlerFunc: 中间件工厂函数，返回gin.HandlerFunc类型的处理函数；
+//     类型为 func(cfg map[string]any) gin.HandlerFunc 时，参数为 service.middlewares 中该项的 config（未配置时为 nil）
 //
 // 返回值：
 //   - error: 如果中间件名称已存在则返回错误，否则返回nil
@@ -75,20 +117,114 @@ func hasMiddleware(name string) bool {
 //	    c.Next()
 //	  })
 //	})
-func RegisterMiddleware(name string, handlerFunc func() gin.HandlerFunc) error {
+//
+//	// 接收配置的中间件，配置来自 service.middlewares 中的 config
+//	err := RegisterMiddleware("debugHeader", func(cfg map[string]any) gin.HandlerFunc {
+//	  header, _ := cfg["header"].(string)
+//	  return func(c *gin.Context) {
+//	    c.Header(header, "1")
+//	    c.Next()
+//	  }
+//	})
+func RegisterMiddleware[F MiddlewareFactory](name string, handlerFunc F) error {
 	// 使用写锁保护并发访问
 	middlewareMutex.Lock()
 	defer middlewareMutex.Unlock()
 
 	// 检查中间件名称是否已被使用，防止重复注册
-	if _, ok := middleWareMap[name]; ok {
+	if middlewareRegistered(name) {
 		return errors.New("this name is already in use")
 	}
 	// 将中间件注册到映射表
-	middleWareMap[name] = handlerFunc
+	switch factory := any(handlerFunc).(type) {
+	case func() gin.HandlerFunc:
+		middleWareMap[name] = factory
+	case func(cfg map[string]any) gin.HandlerFunc:
+		configurableMiddlewareMap[name] = factory
+	}
 	return nil
 }
 
+// configuredMiddlewares 创建 service.middlewares 中当前运行环境启用的中间件
+// 按 envs 筛选后按 order（默认为列表位置）稳定排序，config 传给接收配置的中间件工厂函数
+//
+// 参数：
+//   - list: 中间件配置列表
+//   - env: 运行环境
+//
+// 返回：
+//   - []gin.HandlerFunc: 中间件处理函数，按执行顺序排列
+//   - []string: 对应的中间件名称
+//   - error: 存在未注册的中间件时返回错误，包含配置项位置、名称和运行环境
+func configuredMiddlewares(list config.MiddlewareList, env string) ([]gin.HandlerFunc, []string, error) {
+	middlewareMutex.RLock()
+	defer middlewareMutex.RUnlock()
+
+	entries := list.ForEnv(env)
+	handlers := make([]gin.HandlerFunc, 0, len(entries))
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		handler, ok := newMiddlewareHandler(entry.Name, entry.Config)
+		if !ok {
+			return nil, nil, fmt.Errorf("service.middlewares[%d] 中间件 %s 未注册（env: %s），请先通过 core.RegisterMiddleware 注册", entry.Index, entry.Name, env)
+		}
+		handlers = append(handlers, handler)
+		names = append(names, entry.Name)
+	}
+	return handlers, names, nil
+}
+
+// timedMiddlewares 开启 service.enableServerTiming 时将中间件包装为以名称记录自身耗时的中间件，未开启时原样返回
+// 参数：
+//   - enabled: 是否开启请求耗时分解
+//   - handlers: 中间件列表
+//   - names: 与 handlers 一一对应的中间件名称
+//
+// 返回：
+//   - []gin.HandlerFunc: 包装后的中间件列表
+func timedMiddlewares(enabled bool, handlers []gin.HandlerFunc, names []string) []gin.HandlerFunc {
+	if !enabled {
+		return handlers
+	}
+	timed := make([]gin.HandlerFunc, len(handlers))
+	for i, handler := range handlers {
+		timed[i] = middleware.TimedMiddleware(names[i], handler)
+	}
+	return timed
+}
+
+// OnPanic 注册未处理异常的回调，用于将 exceptionHandler 捕获的异常转发到 Sentry 或内部的异常跟踪系统
+// 回调在响应写出后于独立协程中执行，回调自身 panic 时只记录日志，不影响响应
+//
+// 参数：
+//   - hook: 回调函数，ctx 为请求上下文的副本，recovered 为原始 panic 值，stack 为完整堆栈
+//
+// 使用示例：
+//
+//	core.OnPanic(func(ctx *gin.Context, recovered any, stack []byte) {
+//	  sentry.CurrentHub().Recover(recovered)
+//	})
+func OnPanic(hook func(ctx *gin.Context, recovered any, stack []byte)) {
+	middleware.OnPanic(hook)
+}
+
+// OnMirrorResult 注册流量镜像结果的回调，用于对比影子服务与当前服务的状态码和耗时
+// 回调在发送镜像请求的协程中执行，不影响原请求，回调自身 panic 时只记录日志
+//
+// 参数：
+//   - hook: 回调函数，result 包含原请求和镜像请求的状态码、耗时，以及镜像请求失败的原因
+//
+// 使用示例：
+//
+//	core.OnMirrorResult(func(result middleware.MirrorResult) {
+//	  if result.Err == nil && result.StatusCode != result.PrimaryStatus {
+//	    logger.Warn("影子服务状态码不一致: %s %d / %d", result.Path, result.PrimaryStatus, result.StatusCode)
+//	  }
+//	})
+func OnMirrorResult(hook func(result middleware.MirrorResult)) {
+	middleware.OnMirrorResult(hook)
+}
+
 // 中间件注册列表
 // 每个元素包含中间件名称和对应的处理函数
 var defaultMiddlewares = []struct {
@@ -99,6 +235,8 @@ var defaultMiddlewares = []struct {
 	{"prometheusHandler", middleware.PrometheusHandler},
 	// 异常处理中间件：提供统一的异常捕获和错误响应处理，确保应用在遇到异常时能够优雅降级
 	{"exceptionHandler", middleware.ExceptionHandler},
+	// 国际化中间件：根据查询参数、请求头和 Accept-Language 协商语言区域，响应消息按该语言解析
+	{"i18nHandler", middleware.I18nHandler},
 	// 请求追踪 ID 中间件（兼容旧版）：为每个 HTTP 请求生成唯一的追踪 ID，便于在分布式系统中追踪请求链路
 	{"traceIdHandler", middleware.TraceIdHandler},
 	// OpenTelemetry 链路追踪中间件：支持 W3C Trace Context 标准，可与 Jaeger、Zipkin 等追踪系统集成
@@ -107,10 +245,38 @@ var defaultMiddlewares = []struct {
 	{"traceLogHandler", middleware.TraceLogHandler},
 	// 超时处理中间件：防止请求处理时间过长导致的资源耗尽，超时时间通过 Service.ApiTimeout 配置
 	{"timeoutHandler", middleware.TimeoutHandler},
+	// 请求体解压中间件：解压 Content-Encoding 为 gzip / deflate 的请求体，解压后的大小超过限制时返回 413
+	{"decompressHandler", middleware.DecompressHandler},
+	// API Key 认证中间件：校验请求头或查询参数中的 API Key，认证通过后将调用方名称和权限范围存入请求上下文
+	{"apiKeyHandler", middleware.APIKeyHandler},
+	// 多租户识别中间件：从请求头或认证信息中识别租户 ID 存入请求上下文，租户不存在时拒绝请求，配合 app.TenantDB 使用
+	{"tenantHandler", middleware.TenantHandler},
+	// 维护模式中间件：维护期间对健康检查接口和放行的路径、IP、令牌以外的请求返回 503，可在运行时切换
+	{"maintenanceHandler", middleware.MaintenanceHandler},
 	// 限流中间件：控制 API 请求速率，支持多种限流维度（IP/用户/全局）和存储方式（内存/Redis）
 	{"rateLimitHandler", middleware.RateLimitHandler},
+	// 并发限制中间件：限制同时处理中的请求数，超出限制的请求有限排队，排队已满或等待超时时返回 503
+	{"concurrencyLimitHandler", middleware.ConcurrencyLimitHandler},
 	// CORS 跨域中间件：处理浏览器的跨域请求，支持预检请求（OPTIONS）
 	{"corsHandler", middleware.CORSHandler},
+	// 安全响应头中间件：设置 HSTS、Content-Security-Policy、X-Frame-Options 等安全响应头
+	{"secureHeadersHandler", middleware.SecureHeadersHandler},
+	// 会话中间件：基于 Cookie 的服务端会话，支持 Redis / 内存存储和滑动过期
+	{"sessionHandler", middleware.SessionHandler},
+	// CSRF 防护中间件：校验写请求的 CSRF 令牌（Cookie 双重提交或会话）和请求来源，需注册在 sessionHandler 之后
+	{"csrfHandler", middleware.CSRFHandler},
+	// 幂等键中间件：按 Idempotency-Key 请求头保证写请求只执行一次，重复请求重放首次的响应
+	{"idempotencyHandler", middleware.IdempotencyHandler},
+	// 请求合并中间件：并发的相同 GET 请求只执行一次处理函数，响应共享给所有等待的请求，可按 TTL 短暂缓存
+	{"coalesceHandler", middleware.CoalesceHandler},
+	// 响应缓存中间件：缓存 GET 请求的响应，支持 ETag 协商缓存、Cache-Control 和按用户区分的私有缓存
+	{"cacheHandler", middleware.CacheHandler},
+	// 审计日志中间件：记录指定路径的请求体和响应体，支持字段脱敏、截断，写入日志或数据表
+	{"auditLogHandler", middleware.AuditLogHandler},
+	// 故障注入中间件：为匹配的请求注入延迟、错误响应或断开连接，用于韧性测试，生产环境不生效
+	{"chaosHandler", middleware.ChaosHandler},
+	// 流量镜像中间件：将匹配的请求异步复制到影子服务，记录状态码和耗时用于对比，镜像的响应不会返回给客户端
+	{"mirrorHandler", middleware.MirrorHandler},
 }
 
 // initMiddleware 初始化系统默认中间件
@@ -9,6 +9,7 @@
 // 3. clearMiddlewares - 清空中间件映射表
 // 4. 并发安全 - 多协程并发注册中间件
 // 5. 中间件加载 - 从配置加载中间件
+// 6. 配置项格式 - 按运行环境筛选、order 排序、传递 config，兼容字符串列表
 //
 // 中间件机制：
 //   - 中间件按名称注册到全局映射表
@@ -21,11 +22,17 @@ package core
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"gopkg.in/yaml.v3"
 )
 
 // newTestHandler 创建测试用的中间件处理函数
@@ -109,7 +116,7 @@ func TestRegisterMiddleware(t *testing.T) {
 		clearMiddlewares()
 
 		// 测试注册nil处理函数
-		err := RegisterMiddleware("nilHandler", nil)
+		err := RegisterMiddleware[func() gin.HandlerFunc]("nilHandler", nil)
 		assert.NoError(t, err) // nil处理函数应该被允许注册
 		assert.True(t, hasMiddleware("nilHandler"))
 		handler, exists := getMiddleware("nilHandler")
@@ -275,7 +282,7 @@ func TestMiddlewareHandlerExecution(t *testing.T) {
 
 	t.Run("execute nil middleware handler", func(t *testing.T) {
 		// 注册一个nil处理函数
-		err := RegisterMiddleware("nilHandler", nil)
+		err := RegisterMiddleware[func() gin.HandlerFunc]("nilHandler", nil)
 		assert.NoError(t, err)
 
 		// 获取nil处理函数
@@ -424,3 +431,109 @@ func TestMiddlewareErrorHandling(t *testing.T) {
 		assert.NotNil(t, handler)
 	})
 }
+
+// ==================== 中间件配置项测试 ====================
+
+// newOrderHandler 创建测试用的中间件处理函数，在 X-Order 响应头中追加中间件名称
+func newOrderHandler(name string) func() gin.HandlerFunc {
+	return func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Writer.Header().Add("X-Order", name)
+			c.Next()
+		}
+	}
+}
+
+// serveConfiguredMiddlewares 以指定运行环境和 service.middlewares 配置初始化引擎，请求 /ping 并返回响应
+func serveConfiguredMiddlewares(t *testing.T, env, middlewaresYAML string) *httptest.ResponseRecorder {
+	t.Helper()
+	originalConfig, originalEnv, originalOptions := app.BaseConfig, app.Env, optionFuncList
+	t.Cleanup(func() {
+		app.BaseConfig, app.Env, optionFuncList = originalConfig, originalEnv, originalOptions
+	})
+
+	var service config.ServiceInfo
+	require.NoError(t, yaml.Unmarshal([]byte(middlewaresYAML), &service))
+	app.BaseConfig = config.BaseConfig{Service: service}
+	app.Env = env
+	optionFuncList = make([]optionFunc, 0)
+
+	engine := mustInitEngine(t)
+	engine.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	return w
+}
+
+// TestConfiguredMiddlewares 测试 service.middlewares 配置项
+//
+// 【功能点】验证中间件配置项按运行环境筛选、按 order 排序、向中间件工厂函数传递 config，并兼容字符串列表
+// 【测试流程】
+//  1. 注册 a、b、c 三个追加 X-Order 响应头的中间件和一个读取 config 的中间件
+//  2. 字符串列表：断言按列表顺序执行
+//  3. envs 筛选：断言只在 envs 包含当前运行环境时执行
+//  4. order 排序：断言 order 覆盖列表顺序，未配置 order 的项按列表位置排序
+//  5. config 传递：断言中间件工厂函数收到该项的 config，未配置时为 nil
+func TestConfiguredMiddlewares(t *testing.T) {
+	clearMiddlewares()
+	t.Cleanup(clearMiddlewares)
+	for _, name := range []string{"a", "b", "c"} {
+		require.NoError(t, RegisterMiddleware(name, newOrderHandler(name)))
+	}
+	require.NoError(t, RegisterMiddleware("configHeader", func(cfg map[string]any) gin.HandlerFunc {
+		value, ok := cfg["value"].(string)
+		if !ok {
+			value = "<nil>"
+		}
+		return func(c *gin.Context) {
+			c.Header("X-Config", value)
+			c.Next()
+		}
+	}))
+
+	t.Run("legacy string list", func(t *testing.T) {
+		w := serveConfiguredMiddlewares(t, "prod", "middlewares: [c, a, b]")
+		assert.Equal(t, []string{"c", "a", "b"}, w.Header().Values("X-Order"))
+	})
+
+	t.Run("filter by env", func(t *testing.T) {
+		const middlewares = `
+middlewares:
+  - a
+  - name: b
+    envs: [dev, test]
+  - name: c
+    envs: [prod]
+`
+		assert.Equal(t, []string{"a", "b"}, serveConfiguredMiddlewares(t, "dev", middlewares).Header().Values("X-Order"))
+		assert.Equal(t, []string{"a", "c"}, serveConfiguredMiddlewares(t, "prod", middlewares).Header().Values("X-Order"))
+	})
+
+	t.Run("order overrides list position", func(t *testing.T) {
+		const middlewares = `
+middlewares:
+  - name: a
+    order: 10
+  - b
+  - name: c
+    order: -1
+`
+		assert.Equal(t, []string{"c", "b", "a"}, serveConfiguredMiddlewares(t, "prod", middlewares).Header().Values("X-Order"))
+	})
+
+	t.Run("deliver config", func(t *testing.T) {
+		w := serveConfiguredMiddlewares(t, "prod", `
+middlewares:
+  - name: configHeader
+    config:
+      value: hello
+`)
+		assert.Equal(t, "hello", w.Header().Get("X-Config"))
+
+		w = serveConfiguredMiddlewares(t, "prod", "middlewares: [configHeader]")
+		assert.Equal(t, "<nil>", w.Header().Get("X-Config"))
+	})
+}
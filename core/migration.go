@@ -0,0 +1,83 @@
+package core
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/initialize"
+	"github.com/zzsen/gin_core/migrations"
+	"gorm.io/gorm"
+)
+
+// RegisterMigration 注册数据库迁移
+// 应在 Start 之前调用（如 main 函数或 init 函数中），迁移按注册顺序执行。
+// 主数据库配置 db.autoMigrate: true 时，启动阶段初始化数据库后执行待执行的迁移，迁移失败时启动失败；
+// 也可以通过 -migrate / -rollback N 参数只执行迁移后退出。
+// 迁移 ID 重复、已执行的迁移不再注册时，执行迁移会返回错误
+//
+// 参数：
+//   - id: 迁移标识，全局唯一，建议使用 "20240101_create_users" 形式
+//   - up: 执行迁移的函数
+//   - down: 回滚迁移的函数，为 nil 时该迁移不能回滚
+//
+// 使用示例：
+//
+//	core.RegisterMigration("20240101_create_users",
+//	  func(db *gorm.DB) error { return db.Migrator().CreateTable(&User{}) },
+//	  func(db *gorm.DB) error { return db.Migrator().DropTable(&User{}) },
+//	)
+func RegisterMigration(id string, up, down func(*gorm.DB) error) {
+	migrations.Register(id, up, down)
+}
+
+// runMigrationCommand 连接主数据库执行或回滚迁移，将结果写入 w
+// 参数：
+//   - w: 输出目标
+//   - rollback: 回滚数量，为 0 时执行待执行的迁移
+//
+// 返回：
+//   - int: 进程退出码，成功为 0
+func runMigrationCommand(w io.Writer, rollback int) (code int) {
+	if app.BaseConfig.Db == nil {
+		fmt.Fprintln(w, "[数据库迁移] 未找到主数据库配置（db）")
+		return 1
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(w, "[数据库迁移] %v\n", r)
+			code = 1
+		}
+	}()
+	initialize.InitDB()
+	defer func() { _ = app.CloseAllDB() }()
+
+	return writeMigrationResult(w, app.DB, rollback)
+}
+
+// writeMigrationResult 在 db 上执行或回滚迁移，输出成功的迁移 ID 和错误
+func writeMigrationResult(w io.Writer, db *gorm.DB, rollback int) int {
+	runner, err := migrations.NewRunner(db, migrations.Registered())
+	if err != nil {
+		fmt.Fprintf(w, "[数据库迁移] %v\n", err)
+		return 1
+	}
+
+	action := "执行"
+	var done []string
+	if rollback > 0 {
+		action = "回滚"
+		done, err = runner.Rollback(rollback)
+	} else {
+		done, err = runner.Up()
+	}
+	for _, id := range done {
+		fmt.Fprintf(w, "[数据库迁移] 已%s: %s\n", action, id)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "[数据库迁移] %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(w, "[数据库迁移] 完成, 共%s %d 个迁移\n", action, len(done))
+	return 0
+}
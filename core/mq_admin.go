@@ -0,0 +1,186 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/core/lifecycle"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// mqAdminBasePath 死信队列管理接口的分组路径，位于 service.routePrefix 之下
+const mqAdminBasePath = "/admin/mq/dlq"
+
+// mqAdminConsumersPath 消费者统计接口的分组路径，位于 service.routePrefix 之下
+const mqAdminConsumersPath = "/admin/mq/consumers"
+
+// mqAdminPublishersPath 发送者统计接口的分组路径，位于 service.routePrefix 之下
+const mqAdminPublishersPath = "/admin/mq/publishers"
+
+// mqAdminOptionFuncs 消息队列管理接口的路由选项函数
+// 启用 mqAdmin 时注册以下路由，均由 mqAdmin.middleware 配置的中间件保护：
+//   - GET  /admin/mq/dlq/:queue/stats         - 死信队列统计信息
+//   - POST /admin/mq/dlq/:queue/replay?limit= - 重放死信队列中的消息
+//   - GET  /admin/mq/consumers/stats          - 消费者统计信息（消费计数、处理耗时、队列积压）
+//   - GET  /admin/mq/consumers/states         - 消费者暂停状态
+//   - POST /admin/mq/consumers/:queue/pause   - 暂停消费者
+//   - POST /admin/mq/consumers/:queue/resume  - 恢复消费者
+//   - GET  /admin/mq/publishers/throttle      - 发布限流统计信息（按实例别名）
+//
+// :queue 为通过 AddMessageQueueConsumer 注册的消费者的队列名称
+//
+// 返回：
+//   - []optionFunc: 未启用时为空
+//   - error: 未配置保护中间件或中间件未注册时返回错误
+func mqAdminOptionFuncs() ([]optionFunc, error) {
+	cfg := app.BaseConfig.MQAdmin
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.Middleware == "" {
+		return nil, fmt.Errorf("消息队列管理接口: 未配置 mqAdmin.middleware，管理接口必须由中间件保护")
+	}
+	fn, err := buildRoutes(mqAdminBasePath, []string{cfg.Middleware}, []RouteDef{
+		{Method: http.MethodGet, Path: "/:queue/stats", Handler: deadLetterStatsHandler},
+		{Method: http.MethodPost, Path: "/:queue/replay", Handler: replayDeadLettersHandler},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("消息队列管理接口: %w", err)
+	}
+	consumersFn, err := buildRoutes(mqAdminConsumersPath, []string{cfg.Middleware}, []RouteDef{
+		{Method: http.MethodGet, Path: "/stats", Handler: consumerStatsHandler},
+		{Method: http.MethodGet, Path: "/states", Handler: consumerStatesHandler},
+		{Method: http.MethodPost, Path: "/:queue/pause", Handler: pauseConsumerHandler},
+		{Method: http.MethodPost, Path: "/:queue/resume", Handler: resumeConsumerHandler},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("消息队列管理接口: %w", err)
+	}
+	publishersFn, err := buildRoutes(mqAdminPublishersPath, []string{cfg.Middleware}, []RouteDef{
+		{Method: http.MethodGet, Path: "/throttle", Handler: publishThrottleStatsHandler},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("消息队列管理接口: %w", err)
+	}
+	return []optionFunc{
+		{fn: fn, source: "core.mqAdminOptionFuncs"},
+		{fn: consumersFn, source: "core.mqAdminOptionFuncs"},
+		{fn: publishersFn, source: "core.mqAdminOptionFuncs"},
+	}, nil
+}
+
+// consumerQueues 获取已注册的消费者列表，单元测试中可替换
+var consumerQueues = lifecycle.GetMessageQueueConsumerList
+
+// findConsumerQueue 根据队列名称查找已注册的消费者
+func findConsumerQueue(queueName string) *config.MessageQueue {
+	for _, mq := range consumerQueues() {
+		if mq.QueueName == queueName {
+			return mq
+		}
+	}
+	return nil
+}
+
+// deadLetterStatsHandler 获取死信队列统计信息
+func deadLetterStatsHandler(c *gin.Context) {
+	mq := findConsumerQueue(c.Param("queue"))
+	if mq == nil {
+		response.FailWithMessage(c, fmt.Sprintf("未找到消费者队列: %s", c.Param("queue")))
+		return
+	}
+	stats, err := mq.DeadLetterStats(c.Request.Context())
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, stats)
+}
+
+// consumerStatsHandler 获取所有已注册消费者的统计信息，按队列标识（GetInfo）索引
+func consumerStatsHandler(c *gin.Context) {
+	stats := make(map[string]config.ConsumerStats)
+	for _, mq := range consumerQueues() {
+		stats[mq.GetInfo()] = mq.Stats()
+	}
+	response.OkWithData(c, stats)
+}
+
+// publishThrottleStatsHandler 获取已启用发布限流的 RabbitMQ 实例的限流统计信息，按实例别名索引（默认实例为 default）
+func publishThrottleStatsHandler(c *gin.Context) {
+	response.OkWithData(c, app.RabbitMQPublishThrottleStats())
+}
+
+// consumerStatesHandler 获取所有已注册消费者的暂停状态，按队列标识排序
+func consumerStatesHandler(c *gin.Context) {
+	states := make([]config.ConsumerState, 0)
+	for _, mq := range consumerQueues() {
+		states = append(states, mq.ConsumerState())
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].QueueInfo < states[j].QueueInfo })
+	response.OkWithData(c, states)
+}
+
+// pauseConsumerHandler 暂停消费者，返回暂停后的状态
+func pauseConsumerHandler(c *gin.Context) {
+	mq := findConsumerQueue(c.Param("queue"))
+	if mq == nil {
+		response.FailWithMessage(c, fmt.Sprintf("未找到消费者队列: %s", c.Param("queue")))
+		return
+	}
+	if err := mq.Pause(); err != nil {
+		logger.Error("[消息队列] 暂停消费者失败, queueInfo: %s, error: %v", mq.GetInfo(), err)
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	logger.Info("[消息队列] 消费者已暂停, queueInfo: %s", mq.GetInfo())
+	response.OkWithData(c, mq.ConsumerState())
+}
+
+// resumeConsumerHandler 恢复消费者，返回恢复后的状态
+func resumeConsumerHandler(c *gin.Context) {
+	mq := findConsumerQueue(c.Param("queue"))
+	if mq == nil {
+		response.FailWithMessage(c, fmt.Sprintf("未找到消费者队列: %s", c.Param("queue")))
+		return
+	}
+	mq.Resume()
+	logger.Info("[消息队列] 消费者已恢复, queueInfo: %s", mq.GetInfo())
+	response.OkWithData(c, mq.ConsumerState())
+}
+
+// replayDeadLettersHandler 重放死信队列中的消息
+// limit 未指定时使用 mqAdmin.defaultReplayLimit，超过 mqAdmin.maxReplayLimit 时按上限重放
+func replayDeadLettersHandler(c *gin.Context) {
+	mq := findConsumerQueue(c.Param("queue"))
+	if mq == nil {
+		response.FailWithMessage(c, fmt.Sprintf("未找到消费者队列: %s", c.Param("queue")))
+		return
+	}
+
+	cfg := app.BaseConfig.MQAdmin
+	limit := cfg.GetDefaultReplayLimit()
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			response.Result(c, response.ResponseParamInvalid.GetCode(), map[string]any{}, fmt.Sprintf("limit 必须为正整数: %s", raw))
+			return
+		}
+		limit = min(n, cfg.GetMaxReplayLimit())
+	}
+
+	report, err := mq.ReplayDeadLetters(c.Request.Context(), limit)
+	logger.Info("[消息队列] 重放死信消息, queueInfo: %s, 成功: %d, 失败: %d", mq.GetInfo(), report.Replayed, report.Failed)
+	if err != nil {
+		logger.Error("[消息队列] 重放死信消息失败, queueInfo: %s, error: %v", mq.GetInfo(), err)
+		response.FailWithDetail(c, err.Error(), report)
+		return
+	}
+	response.OkWithData(c, report)
+}
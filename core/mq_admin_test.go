@@ -0,0 +1,164 @@
+// Package core 消息队列管理接口测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含死信队列管理接口路由注册和参数处理的单元测试，不需要 RabbitMQ 连接。
+// 连接 RabbitMQ 的统计和重放流程见 model/config 的集成测试 TestIntegration_ReplayDeadLetters。
+//
+// 测试覆盖内容：
+// 1. 未启用时不注册管理接口
+// 2. 启用时未配置或未注册保护中间件，启动失败
+// 3. 管理接口挂载在路由前缀下并经过保护中间件，队列不存在、limit 非法、未启用死信队列时返回错误
+// 4. 消费者统计接口返回已注册消费者的统计信息
+// 5. 消费者暂停 / 恢复接口切换消费者状态，状态接口返回暂停状态
+// 6. 发布限流统计接口返回已启用发布限流的实例的统计信息
+//
+// 运行测试：go test -v ./core/... -run MQAdmin
+// ==================================================
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// setupMQAdminTest 设置路由测试环境并启用管理接口，消费者列表替换为 queues
+func setupMQAdminTest(t *testing.T, adminCfg config.MQAdminConfig, queues ...*config.MessageQueue) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+	app.BaseConfig.MQAdmin = adminCfg
+	original := consumerQueues
+	consumerQueues = func() []*config.MessageQueue { return queues }
+	t.Cleanup(func() { consumerQueues = original })
+}
+
+// TestMQAdmin_Disabled 测试未启用管理接口
+//
+// 【功能点】验证未启用 mqAdmin 时不注册管理接口
+// 【测试流程】初始化引擎，断言路由列表中没有管理接口
+func TestMQAdmin_Disabled(t *testing.T) {
+	setupMQAdminTest(t, config.MQAdminConfig{})
+
+	_, err := initEngine()
+	require.NoError(t, err)
+	for _, r := range Routes() {
+		assert.NotContains(t, r.Path, mqAdminBasePath)
+		assert.NotContains(t, r.Path, mqAdminConsumersPath)
+	}
+}
+
+// TestMQAdmin_MiddlewareRequired 测试保护中间件校验
+//
+// 【功能点】验证启用管理接口时未配置保护中间件或中间件未注册，初始化引擎返回错误
+// 【测试流程】分别以空中间件名称和未注册的中间件名称初始化引擎，断言错误信息
+func TestMQAdmin_MiddlewareRequired(t *testing.T) {
+	setupMQAdminTest(t, config.MQAdminConfig{Enabled: true})
+	_, err := initEngine()
+	assert.ErrorContains(t, err, "未配置 mqAdmin.middleware")
+
+	app.BaseConfig.MQAdmin.Middleware = "adminAuth"
+	_, err = initEngine()
+	assert.ErrorContains(t, err, "中间件 adminAuth 未注册")
+}
+
+// TestMQAdmin_Routes 测试管理接口
+//
+// 【功能点】验证管理接口挂载在路由前缀下并经过保护中间件，参数和队列错误时返回失败响应
+// 【测试流程】
+//  1. 注册拒绝缺少 X-Admin 请求头的中间件，断言未带请求头的请求返回 401
+//  2. 请求不存在的队列，断言返回失败消息
+//  3. 以非法 limit 重放，断言返回参数校验响应码
+//  4. 统计、重放未启用死信队列的队列，断言返回 ErrDeadLetterDisabled 的消息
+//  5. 获取消费者统计，断言按队列标识返回
+//  6. 暂停、查询状态、恢复消费者，断言状态依次为 paused、paused、running
+func TestMQAdmin_Routes(t *testing.T) {
+	orders := &config.MessageQueue{QueueName: "orders", MqConnStr: "amqp://invalid:1/"}
+	setupMQAdminTest(t, config.MQAdminConfig{Enabled: true, Middleware: "adminAuth"}, orders)
+	middleWareMap["adminAuth"] = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if c.GetHeader("X-Admin") == "" {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			c.Next()
+		}
+	}
+
+	engine, err := initEngine()
+	require.NoError(t, err)
+
+	do := func(method, path string, admin bool) (int, response.Response) {
+		req := httptest.NewRequest(method, path, nil)
+		if admin {
+			req.Header.Set("X-Admin", "1")
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		var body response.Response
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+		}
+		return w.Code, body
+	}
+
+	code, _ := do(http.MethodGet, "/api/admin/mq/dlq/orders/stats", false)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	_, body := do(http.MethodGet, "/api/admin/mq/dlq/missing/stats", true)
+	assert.Equal(t, response.ResponseFail.GetCode(), body.Code)
+	assert.Equal(t, "未找到消费者队列: missing", body.Msg)
+
+	_, body = do(http.MethodPost, "/api/admin/mq/dlq/orders/replay?limit=abc", true)
+	assert.Equal(t, response.ResponseParamInvalid.GetCode(), body.Code)
+	assert.Equal(t, "limit 必须为正整数: abc", body.Msg)
+
+	_, body = do(http.MethodGet, "/api/admin/mq/dlq/orders/stats", true)
+	assert.Equal(t, response.ResponseFail.GetCode(), body.Code)
+	assert.Contains(t, body.Msg, config.ErrDeadLetterDisabled.Error())
+
+	_, body = do(http.MethodPost, "/api/admin/mq/dlq/orders/replay?limit=5", true)
+	assert.Equal(t, response.ResponseFail.GetCode(), body.Code)
+	assert.Contains(t, body.Msg, config.ErrDeadLetterDisabled.Error())
+	assert.Nil(t, orders.Conn)
+
+	_, body = do(http.MethodGet, "/api/admin/mq/consumers/stats", true)
+	assert.Equal(t, response.ResponseSuccess.GetCode(), body.Code)
+	stats, ok := body.Data.(map[string]any)
+	require.True(t, ok)
+	assert.Contains(t, stats, orders.GetInfo())
+
+	_, body = do(http.MethodPost, "/api/admin/mq/consumers/missing/pause", true)
+	assert.Equal(t, "未找到消费者队列: missing", body.Msg)
+
+	_, body = do(http.MethodPost, "/api/admin/mq/consumers/orders/pause", true)
+	assert.Equal(t, response.ResponseSuccess.GetCode(), body.Code)
+	assert.True(t, orders.Paused())
+
+	_, body = do(http.MethodGet, "/api/admin/mq/consumers/states", true)
+	states, ok := body.Data.([]any)
+	require.True(t, ok)
+	require.Len(t, states, 1)
+	assert.Equal(t, config.ConsumerStatePaused, states[0].(map[string]any)["state"])
+
+	_, body = do(http.MethodPost, "/api/admin/mq/consumers/orders/resume", true)
+	assert.Equal(t, response.ResponseSuccess.GetCode(), body.Code)
+	assert.Equal(t, config.ConsumerStateRunning, body.Data.(map[string]any)["state"])
+	assert.False(t, orders.Paused())
+
+	app.BaseConfig.RabbitMQ.PublishRateLimit = config.PublishRateLimitConfig{Enabled: true, MessagesPerSecond: 10}
+	t.Cleanup(func() { app.BaseConfig.RabbitMQ.PublishRateLimit = config.PublishRateLimitConfig{} })
+	require.NotNil(t, app.RabbitMQPublishThrottle(""))
+	_, body = do(http.MethodGet, "/api/admin/mq/publishers/throttle", true)
+	assert.Equal(t, response.ResponseSuccess.GetCode(), body.Code)
+	throttles, ok := body.Data.(map[string]any)
+	require.True(t, ok)
+	assert.Contains(t, throttles, "default")
+}
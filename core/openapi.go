@@ -0,0 +1,163 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/openapi"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"github.com/zzsen/gin_core/version"
+)
+
+// DescribeOption 接口描述选项
+type DescribeOption func(doc *openapi.RouteDoc)
+
+// WithRequest 设置请求结构体，参数为该结构体的零值，如 WithRequest(CreateUserReq{})
+// uri、header、form 标签的字段生成为路径参数、请求头和查询参数，其余字段生成为请求体（POST、PUT、PATCH）
+func WithRequest(req any) DescribeOption {
+	return func(doc *openapi.RouteDoc) {
+		doc.Request = reflect.TypeOf(req)
+	}
+}
+
+// WithResponse 设置响应，data 为响应中 data 字段的零值，为 nil 时 data 为任意值
+// 生成的响应自动包装在统一响应结构 {code, data, msg} 中，可多次调用声明多个 HTTP 状态码的响应
+func WithResponse(status int, data any) DescribeOption {
+	return func(doc *openapi.RouteDoc) {
+		doc.Responses = append(doc.Responses, openapi.ResponseDoc{Status: status, Type: reflect.TypeOf(data)})
+	}
+}
+
+// WithSummary 设置接口摘要
+func WithSummary(summary string) DescribeOption {
+	return func(doc *openapi.RouteDoc) {
+		doc.Summary = summary
+	}
+}
+
+// WithTags 设置接口分组标签
+func WithTags(tags ...string) DescribeOption {
+	return func(doc *openapi.RouteDoc) {
+		doc.Tags = append(doc.Tags, tags...)
+	}
+}
+
+var (
+	// routeDocs DescribeRoute 注册的接口描述，键为 "方法 路径"
+	routeDocs   = make(map[string]*openapi.RouteDoc)
+	routeDocsMu sync.RWMutex
+)
+
+// DescribeRoute 描述接口的请求和响应，用于生成 OpenAPI 文档
+// 未描述的路由也会出现在文档中，只包含路径参数和默认响应。该函数是线程安全的，通常在 init 或注册路由时调用
+//
+// 参数：
+//   - method: 请求方法，如 GET、POST
+//   - path: 路由路径，与注册路由时的写法一致（如 /users/:id），可以包含或省略 service.routePrefix
+//   - opts: 描述选项，如 WithRequest、WithResponse、WithSummary、WithTags
+//
+// 使用示例：
+//
+//	core.DescribeRoute(http.MethodPost, "/users",
+//	  core.WithSummary("创建用户"),
+//	  core.WithTags("用户"),
+//	  core.WithRequest(CreateUserReq{}),
+//	  core.WithResponse(http.StatusOK, UserResp{}),
+//	)
+func DescribeRoute(method, path string, opts ...DescribeOption) {
+	doc := &openapi.RouteDoc{}
+	for _, opt := range opts {
+		opt(doc)
+	}
+	routeDocsMu.Lock()
+	defer routeDocsMu.Unlock()
+	routeDocs[strings.ToUpper(method)+" "+path] = doc
+}
+
+// lookupRouteDoc 查找路由的接口描述，依次按完整路径和去除路由前缀后的路径匹配
+func lookupRouteDoc(method, fullPath, prefix string) *openapi.RouteDoc {
+	routeDocsMu.RLock()
+	defer routeDocsMu.RUnlock()
+	if doc, ok := routeDocs[method+" "+fullPath]; ok {
+		return doc
+	}
+	if prefix != "/" {
+		if rel, ok := strings.CutPrefix(fullPath, prefix); ok {
+			if rel == "" {
+				rel = "/"
+			}
+			return routeDocs[method+" "+rel]
+		}
+	}
+	return nil
+}
+
+// openAPIOptionFuncs OpenAPI 文档接口的路由选项函数
+// 启用 openapi 时注册 GET {openapi.path}（默认 /openapi.json）返回文档；
+// openapi.ui 未关闭时注册 GET {openapi.uiPath}（默认 /swagger）返回 Swagger UI 页面。配置 openapi.middleware 时由该中间件保护
+//
+// 返回：
+//   - []optionFunc: 未启用时为空
+//   - error: 保护中间件未注册时返回错误
+func openAPIOptionFuncs() ([]optionFunc, error) {
+	cfg := app.BaseConfig.OpenAPI
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	var middlewares []string
+	if cfg.Middleware != "" {
+		middlewares = []string{cfg.Middleware}
+	}
+	routes := []RouteDef{{Method: http.MethodGet, Path: cfg.GetPath(), Handler: openAPIHandler}}
+	if cfg.GetUI() {
+		routes = append(routes, RouteDef{Method: http.MethodGet, Path: cfg.GetUIPath(), Handler: swaggerUIHandler})
+	}
+	fn, err := buildRoutes("", middlewares, routes)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAPI 文档接口: %w", err)
+	}
+	return []optionFunc{{fn: fn, source: "core.openAPIOptionFuncs"}}, nil
+}
+
+// buildOpenAPIDocument 根据已注册的路由和 DescribeRoute 注册的接口描述生成 OpenAPI 文档
+// 文档接口和 Swagger UI 页面本身不出现在文档中
+func buildOpenAPIDocument() *openapi.Document {
+	cfg := app.BaseConfig.OpenAPI
+	docVersion := cfg.Version
+	if docVersion == "" {
+		docVersion = version.Version
+	}
+	doc := openapi.New(openapi.Info{Title: cfg.GetTitle(), Version: docVersion})
+
+	prefix := path.Join("/", app.BaseConfig.Service.RoutePrefix)
+	excluded := map[string]bool{
+		path.Join(prefix, cfg.GetPath()):   true,
+		path.Join(prefix, cfg.GetUIPath()): true,
+	}
+	for _, r := range Routes() {
+		if excluded[r.Path] {
+			continue
+		}
+		doc.AddRoute(r.Method, r.Path, lookupRouteDoc(r.Method, r.Path, prefix))
+	}
+	return doc
+}
+
+// openAPIHandler 返回 OpenAPI 文档（JSON），不包装统一响应结构
+func openAPIHandler(c *gin.Context) {
+	ginContext.SkipEnvelope(c)
+	c.JSON(http.StatusOK, buildOpenAPIDocument())
+}
+
+// swaggerUIHandler 返回加载 OpenAPI 文档的 Swagger UI 页面
+func swaggerUIHandler(c *gin.Context) {
+	cfg := app.BaseConfig.OpenAPI
+	specURL := path.Join("/", app.BaseConfig.Service.RoutePrefix, cfg.GetPath())
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.SwaggerUIHTML(cfg.GetTitle(), specURL, cfg.GetUIAssetsURL()))
+}
@@ -0,0 +1,197 @@
+// Package core OpenAPI 文档接口测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 OpenAPI 文档接口和 Swagger UI 页面的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 默认不注册文档接口，开启后挂载在路由前缀下
+// 2. 文档包含已注册的路由，DescribeRoute 描述的请求、响应生成到文档中，描述可省略路由前缀
+// 3. 文档接口和 Swagger UI 页面本身不出现在文档中，生成的文档通过结构校验
+// 4. Swagger UI 页面加载文档地址，关闭 openapi.ui 后不注册页面
+// 5. 开启统一响应结构检查的严格模式时文档接口不被替换
+//
+// 运行测试：go test -v ./core/... -run OpenAPI
+// ==================================================
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/openapi"
+)
+
+// createUserReq 测试请求
+type createUserReq struct {
+	TenantID string `header:"X-Tenant-Id" binding:"required"`
+	Name     string `json:"name" binding:"required,max=32"`
+	Role     string `json:"role" binding:"oneof=admin member"`
+}
+
+// userResp 测试响应
+type userResp struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// setupOpenAPITest 设置路由测试环境，开启 OpenAPI 文档接口，注册测试路由和接口描述
+func setupOpenAPITest(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+	app.BaseConfig.OpenAPI = config.OpenAPIConfig{Enabled: true, Title: "demo", Version: "v1.0.0"}
+	originalDocs := routeDocs
+	routeDocs = make(map[string]*openapi.RouteDoc)
+	t.Cleanup(func() { routeDocs = originalDocs })
+
+	AddOptionFunc(func(e *gin.Engine) {
+		e.POST("/users", routeHandler("create"))
+		e.GET("/users/:id", routeHandler("detail"))
+	})
+	DescribeRoute(http.MethodPost, "/users",
+		WithSummary("创建用户"),
+		WithTags("用户"),
+		WithRequest(createUserReq{}),
+		WithResponse(http.StatusOK, userResp{}),
+	)
+}
+
+// getOpenAPIDocument 请求文档接口并解析
+func getOpenAPIDocument(t *testing.T, engine *gin.Engine) (map[string]any, openapi.Document) {
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &raw))
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	return raw, doc
+}
+
+// TestOpenAPI_Document 测试文档内容
+//
+// 【功能点】验证文档包含已注册的路由和接口描述，不包含文档接口本身，并通过结构校验
+// 【测试流程】
+//  1. 开启 openapi，注册 POST /users（省略路由前缀描述）和 GET /users/:id（未描述）
+//  2. 请求 /api/openapi.json，断言 info 使用配置的标题和版本
+//  3. 断言 POST /api/users 的摘要、标签、请求头参数、请求体约束和统一响应结构
+//  4. 断言 GET /api/users/{id} 生成路径参数，文档接口和 Swagger UI 页面不在文档中
+//  5. 断言文档通过 Validate 校验
+func TestOpenAPI_Document(t *testing.T) {
+	setupOpenAPITest(t)
+	engine, err := initEngine()
+	require.NoError(t, err)
+
+	raw, doc := getOpenAPIDocument(t, engine)
+	assert.Equal(t, map[string]any{"title": "demo", "version": "v1.0.0"}, raw["info"])
+
+	create := (*doc.Paths["/api/users"])["post"]
+	require.NotNil(t, create)
+	assert.Equal(t, "创建用户", create.Summary)
+	assert.Equal(t, []string{"用户"}, create.Tags)
+	require.Len(t, create.Parameters, 1)
+	assert.Equal(t, "X-Tenant-Id", create.Parameters[0].Name)
+	assert.Equal(t, "header", create.Parameters[0].In)
+
+	body := create.RequestBody.Content["application/json"].Schema
+	assert.Equal(t, []string{"name"}, body.Required)
+	assert.Equal(t, 32, *body.Properties["name"].MaxLength)
+	assert.Equal(t, []any{"admin", "member"}, body.Properties["role"].Enum)
+
+	data := create.Responses["200"].Content["application/json"].Schema.Properties["data"]
+	assert.Equal(t, "#/components/schemas/userResp", data.Ref)
+	assert.Contains(t, doc.Components.Schemas, "userResp")
+
+	detail := (*doc.Paths["/api/users/{id}"])["get"]
+	require.NotNil(t, detail)
+	assert.Equal(t, "id", detail.Parameters[0].Name)
+
+	assert.NotContains(t, doc.Paths, "/api/openapi.json")
+	assert.NotContains(t, doc.Paths, "/api/swagger")
+	assert.Contains(t, doc.Paths, "/api/healthy")
+	require.NoError(t, doc.Validate())
+}
+
+// TestOpenAPI_SwaggerUI 测试 Swagger UI 页面
+//
+// 【功能点】验证 Swagger UI 页面加载文档地址和配置的静态资源地址，关闭 openapi.ui 后不注册页面
+// 【测试流程】
+//  1. 配置静态资源地址，请求 /api/swagger，断言返回 HTML，包含文档地址和静态资源地址
+//  2. 关闭 openapi.ui 后重新初始化引擎，断言页面返回 404，文档接口仍可访问
+func TestOpenAPI_SwaggerUI(t *testing.T) {
+	setupOpenAPITest(t)
+	app.BaseConfig.OpenAPI.UIAssetsURL = "https://static.example.com/swagger-ui/"
+	engine, err := initEngine()
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/swagger", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), `url: "/api/openapi.json"`)
+	assert.Contains(t, w.Body.String(), `href="https://static.example.com/swagger-ui/swagger-ui.css"`)
+
+	disabled := false
+	app.BaseConfig.OpenAPI.UI = &disabled
+	engine, err = initEngine()
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/swagger", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	getOpenAPIDocument(t, engine)
+}
+
+// TestOpenAPI_Disabled 测试默认不注册文档接口
+//
+// 【功能点】验证未开启 openapi.enabled 时不注册文档接口和 Swagger UI 页面，保护中间件未注册时启动失败
+// 【测试流程】
+//  1. 使用默认配置初始化引擎，断言路由列表中没有文档接口和 Swagger UI 页面
+//  2. 开启 openapi 并配置未注册的保护中间件，断言初始化引擎返回错误
+func TestOpenAPI_Disabled(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+	_, err := initEngine()
+	require.NoError(t, err)
+	for _, r := range Routes() {
+		assert.NotEqual(t, "/api/openapi.json", r.Path)
+		assert.NotEqual(t, "/api/swagger", r.Path)
+	}
+
+	app.BaseConfig.OpenAPI = config.OpenAPIConfig{Enabled: true, Middleware: "notRegistered"}
+	_, err = initEngine()
+	assert.ErrorContains(t, err, "OpenAPI 文档接口")
+}
+
+// TestOpenAPI_EnforceEnvelope 测试开启统一响应结构检查时的文档接口
+//
+// 【功能点】验证开启 service.enforceEnvelope 严格模式时，文档接口不被替换为错误响应，自定义 JSON 结构的处理函数被替换
+// 【测试流程】
+//  1. 开启 openapi 和严格模式的统一响应结构检查，注册返回 gin.H{"message": ...} 的 GET /raw
+//  2. 请求 /api/openapi.json，断言返回文档；请求 /api/healthy，断言返回 200
+//  3. 请求 /api/raw，断言返回 502
+func TestOpenAPI_EnforceEnvelope(t *testing.T) {
+	setupOpenAPITest(t)
+	app.BaseConfig.Service.EnforceEnvelope = true
+	app.BaseConfig.Service.Envelope = config.EnvelopeConfig{StrictMode: true}
+	AddOptionFunc(func(e *gin.Engine) {
+		e.GET("/raw", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "ok"})
+		})
+	})
+	engine, err := initEngine()
+	require.NoError(t, err)
+
+	_, doc := getOpenAPIDocument(t, engine)
+	assert.Equal(t, "demo", doc.Info.Title)
+
+	for path, status := range map[string]int{"/api/healthy": http.StatusOK, "/api/raw": http.StatusBadGateway} {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, status, w.Code, path)
+	}
+}
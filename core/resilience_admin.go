@@ -0,0 +1,157 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/circuitbreaker"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/middleware"
+	"github.com/zzsen/gin_core/model/response"
+	"github.com/zzsen/gin_core/ratelimit"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"github.com/zzsen/gin_core/utils/netutil"
+)
+
+// resilienceAdminBasePath 熔断器和限流管理接口的分组路径，位于 service.routePrefix 之下
+const resilienceAdminBasePath = "/admin"
+
+// resilienceAdminLimiter 获取限流中间件使用的限流器，单元测试中可替换
+var resilienceAdminLimiter = middleware.GetLimiter
+
+// resilienceAdminOptionFuncs 熔断器和限流管理接口的路由选项函数
+// 启用 resilienceAdmin 时注册以下路由，均由 resilienceAdmin.middleware 配置的中间件保护：
+//   - GET    /admin/breakers                     - 全局熔断器注册中心中所有熔断器的状态和计数
+//   - POST   /admin/breakers/:name/reset         - 重置指定熔断器到关闭状态
+//   - POST   /admin/breakers/reset-all           - 重置所有熔断器
+//   - GET    /admin/ratelimit/keys?prefix=&limit= - 列出限流中间件的活跃限流键及剩余配额
+//   - DELETE /admin/ratelimit/keys/*key          - 清除限流键，该键的配额立即恢复
+//
+// 重置和清除操作会记录操作人（ginContext.GetUserID）和客户端 IP
+//
+// 返回：
+//   - []optionFunc: 未启用时为空
+//   - error: 未配置保护中间件或中间件未注册时返回错误
+func resilienceAdminOptionFuncs() ([]optionFunc, error) {
+	cfg := app.BaseConfig.ResilienceAdmin
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.Middleware == "" {
+		return nil, fmt.Errorf("熔断器和限流管理接口: 未配置 resilienceAdmin.middleware，管理接口必须由中间件保护")
+	}
+	fn, err := buildRoutes(resilienceAdminBasePath, []string{cfg.Middleware}, []RouteDef{
+		{Method: http.MethodGet, Path: "/breakers", Handler: breakerStatsHandler},
+		{Method: http.MethodPost, Path: "/breakers/reset-all", Handler: resetAllBreakersHandler},
+		{Method: http.MethodPost, Path: "/breakers/:name/reset", Handler: resetBreakerHandler},
+		{Method: http.MethodGet, Path: "/ratelimit/keys", Handler: rateLimitKeysHandler},
+		{Method: http.MethodDelete, Path: "/ratelimit/keys/*key", Handler: deleteRateLimitKeyHandler},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("熔断器和限流管理接口: %w", err)
+	}
+	return []optionFunc{{fn: fn, source: "core.resilienceAdminOptionFuncs"}}, nil
+}
+
+// adminOperator 获取管理接口的操作人，未登录时为 anonymous
+func adminOperator(c *gin.Context) string {
+	userID, ok := ginContext.GetUserID(c)
+	if !ok || userID == "" {
+		userID = "anonymous"
+	}
+	return userID + "@" + netutil.ClientIP(c)
+}
+
+// breakerStatsHandler 获取所有熔断器的状态和计数，按熔断器名称索引
+func breakerStatsHandler(c *gin.Context) {
+	response.OkWithData(c, circuitbreaker.GetRegistry().Stats())
+}
+
+// resetBreakerHandler 重置指定熔断器，返回重置后的状态
+func resetBreakerHandler(c *gin.Context) {
+	name := c.Param("name")
+	cb, ok := circuitbreaker.GetRegistry().Lookup(name)
+	if !ok {
+		response.FailWithMessage(c, fmt.Sprintf("未找到熔断器: %s", name))
+		return
+	}
+	from := cb.State()
+	cb.Reset()
+	logger.Info("[管理接口] 重置熔断器, name: %s, from: %s, operator: %s", name, from, adminOperator(c))
+	response.OkWithData(c, circuitbreaker.BreakerStats{State: cb.State(), Counts: cb.Counts()})
+}
+
+// resetAllBreakersHandler 重置所有熔断器，返回重置后所有熔断器的状态
+func resetAllBreakersHandler(c *gin.Context) {
+	registry := circuitbreaker.GetRegistry()
+	registry.ResetAll()
+	stats := registry.Stats()
+	logger.Info("[管理接口] 重置所有熔断器, count: %d, operator: %s", len(stats), adminOperator(c))
+	response.OkWithData(c, stats)
+}
+
+// rateLimitKeyStore 获取支持枚举和清除限流键的限流器，未启用限流或限流器不支持时返回失败响应
+func rateLimitKeyStore(c *gin.Context) (ratelimit.KeyStore, bool) {
+	if !app.BaseConfig.RateLimit.Enabled {
+		response.FailWithMessage(c, "未启用限流")
+		return nil, false
+	}
+	store, ok := resilienceAdminLimiter().(ratelimit.KeyStore)
+	if !ok {
+		response.FailWithMessage(c, "当前限流器不支持枚举和清除限流键")
+		return nil, false
+	}
+	return store, true
+}
+
+// rateLimitKeysHandler 列出以 prefix 开头的活跃限流键及剩余配额
+// limit 未指定或超过 resilienceAdmin.maxListKeys 时按上限返回
+func rateLimitKeysHandler(c *gin.Context) {
+	limit := app.BaseConfig.ResilienceAdmin.GetMaxListKeys()
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			response.Result(c, response.ResponseParamInvalid.GetCode(), map[string]any{}, fmt.Sprintf("limit 必须为正整数: %s", raw))
+			return
+		}
+		limit = min(n, limit)
+	}
+	store, ok := rateLimitKeyStore(c)
+	if !ok {
+		return
+	}
+	keys, err := store.Keys(c.Request.Context(), c.Query("prefix"), limit)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, keys)
+}
+
+// deleteRateLimitKeyHandler 清除限流键，键中可以包含 "/"（如 ip:127.0.0.1:/api/users）
+func deleteRateLimitKeyHandler(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if key == "" {
+		response.Result(c, response.ResponseParamInvalid.GetCode(), map[string]any{}, "限流键不能为空")
+		return
+	}
+	store, ok := rateLimitKeyStore(c)
+	if !ok {
+		return
+	}
+	deleted, err := store.Delete(c.Request.Context(), key)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	if !deleted {
+		response.FailWithMessage(c, fmt.Sprintf("未找到限流键: %s", key))
+		return
+	}
+	logger.Info("[管理接口] 清除限流键, key: %s, operator: %s", key, adminOperator(c))
+	response.Ok(c)
+}
@@ -0,0 +1,184 @@
+// Package core 熔断器和限流管理接口测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含熔断器和限流管理接口的单元测试，使用全局熔断器注册中心和内存限流器，不需要外部依赖。
+// Redis 限流器的枚举和清除见 ratelimit 包的 TestRedisLimiter_KeysAndDelete。
+//
+// 测试覆盖内容：
+// 1. 未启用时不注册管理接口，启用时未配置或未注册保护中间件，启动失败
+// 2. 熔断器状态接口的 JSON 结构，重置接口将强制打开的熔断器恢复为关闭状态
+// 3. 限流键列表接口的 JSON 结构，清除限流键后配额立即恢复
+//
+// 运行测试：go test -v ./core/... -run ResilienceAdmin
+// ==================================================
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/circuitbreaker"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	"github.com/zzsen/gin_core/ratelimit"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// setupResilienceAdminTest 设置路由测试环境，启用管理接口并注册 adminAuth 保护中间件
+// adminAuth 拒绝缺少 X-Admin 请求头的请求，并将请求头的值作为操作人
+//
+// 返回：
+//   - func(method, path string) (int, response.Response): 携带 X-Admin 请求头发送请求
+func setupResilienceAdminTest(t *testing.T) func(method, path string) (int, response.Response) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+	app.BaseConfig.ResilienceAdmin = config.ResilienceAdminConfig{Enabled: true, Middleware: "adminAuth"}
+	middleWareMap["adminAuth"] = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			operator := c.GetHeader("X-Admin")
+			if operator == "" {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			ginContext.SetUserID(c, operator)
+			c.Next()
+		}
+	}
+
+	engine, err := initEngine()
+	require.NoError(t, err)
+
+	return func(method, path string) (int, response.Response) {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-Admin", "ops")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		var body response.Response
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+		}
+		return w.Code, body
+	}
+}
+
+// TestResilienceAdmin_MiddlewareRequired 测试管理接口的注册条件
+//
+// 【功能点】验证未启用时不注册管理接口，启用时未配置保护中间件或中间件未注册，初始化引擎返回错误
+// 【测试流程】
+//  1. 未启用时初始化引擎，断言路由列表中没有管理接口
+//  2. 分别以空中间件名称和未注册的中间件名称初始化引擎，断言错误信息
+func TestResilienceAdmin_MiddlewareRequired(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+	_, err := initEngine()
+	require.NoError(t, err)
+	for _, r := range Routes() {
+		assert.NotContains(t, r.Path, "/admin/breakers")
+		assert.NotContains(t, r.Path, "/admin/ratelimit")
+	}
+
+	app.BaseConfig.ResilienceAdmin = config.ResilienceAdminConfig{Enabled: true}
+	_, err = initEngine()
+	assert.ErrorContains(t, err, "未配置 resilienceAdmin.middleware")
+
+	app.BaseConfig.ResilienceAdmin.Middleware = "adminAuth"
+	_, err = initEngine()
+	assert.ErrorContains(t, err, "中间件 adminAuth 未注册")
+}
+
+// TestResilienceAdmin_Breakers 测试熔断器管理接口
+//
+// 【功能点】验证状态接口返回按名称索引的状态和计数，重置接口将打开的熔断器恢复为关闭状态
+// 【测试流程】
+//  1. 在全局注册中心注册连续失败 1 次即打开的熔断器，执行失败的请求使其打开
+//  2. 获取熔断器状态，断言 state 为 open，并包含 counts 计数
+//  3. 重置该熔断器，断言响应和熔断器的状态均为 closed，请求可以再次执行
+//  4. 重置不存在的熔断器，断言返回失败消息；重置所有熔断器，断言返回所有熔断器的状态
+func TestResilienceAdmin_Breakers(t *testing.T) {
+	do := setupResilienceAdminTest(t)
+	registry := circuitbreaker.GetRegistry()
+	name := "resilience-admin-test"
+	cb := registry.GetWithConfig(&circuitbreaker.Config{Name: name, FailureThreshold: 1, Timeout: time.Minute, Interval: time.Minute})
+	t.Cleanup(func() { registry.Remove(name) })
+
+	_ = cb.Execute(context.Background(), func() error { return errors.New("下游服务异常") })
+	require.Equal(t, circuitbreaker.StateOpen, cb.State())
+
+	_, body := do(http.MethodGet, "/api/admin/breakers")
+	require.Equal(t, response.ResponseSuccess.GetCode(), body.Code)
+	stats, ok := body.Data.(map[string]any)
+	require.True(t, ok)
+	breaker, ok := stats[name].(map[string]any)
+	require.True(t, ok, stats)
+	assert.Equal(t, "open", breaker["state"])
+	assert.Contains(t, breaker["counts"], "ConsecutiveFailures")
+
+	_, body = do(http.MethodPost, "/api/admin/breakers/"+name+"/reset")
+	require.Equal(t, response.ResponseSuccess.GetCode(), body.Code)
+	assert.Equal(t, "closed", body.Data.(map[string]any)["state"])
+	assert.Equal(t, circuitbreaker.StateClosed, cb.State())
+	assert.NoError(t, cb.Execute(context.Background(), func() error { return nil }))
+
+	_, body = do(http.MethodPost, "/api/admin/breakers/missing/reset")
+	assert.Equal(t, response.ResponseFail.GetCode(), body.Code)
+	assert.Equal(t, "未找到熔断器: missing", body.Msg)
+
+	_, body = do(http.MethodPost, "/api/admin/breakers/reset-all")
+	require.Equal(t, response.ResponseSuccess.GetCode(), body.Code)
+	assert.Contains(t, body.Data, name)
+}
+
+// TestResilienceAdmin_RateLimitKeys 测试限流键管理接口
+//
+// 【功能点】验证列表接口按前缀返回限流键和剩余配额，清除限流键后配额立即恢复
+// 【测试流程】
+//  1. 替换为内存限流器，耗尽 ip:10.0.0.1:/api/orders 的配额（burst=2），global:/api/orders 消耗 1 次
+//  2. 以前缀 ip: 列出限流键，断言只返回 ip 键且 remaining 为 0；limit 非法时返回参数校验响应码
+//  3. 清除该键（键中包含 /），断言请求立即放行；清除不存在的键返回失败消息
+//  4. 未启用限流时，断言返回失败消息
+func TestResilienceAdmin_RateLimitKeys(t *testing.T) {
+	do := setupResilienceAdminTest(t)
+	app.BaseConfig.RateLimit.Enabled = true
+	limiter := ratelimit.NewMemoryLimiter(time.Minute)
+	t.Cleanup(func() { _ = limiter.Close() })
+	original := resilienceAdminLimiter
+	resilienceAdminLimiter = func() ratelimit.Limiter { return limiter }
+	t.Cleanup(func() { resilienceAdminLimiter = original })
+
+	ctx := context.Background()
+	key := "ip:10.0.0.1:/api/orders"
+	for _, k := range []string{key, key, "global:/api/orders"} {
+		_, _ = limiter.Allow(ctx, k, 1, 2)
+	}
+	allowed, _ := limiter.Allow(ctx, key, 1, 2)
+	require.False(t, allowed)
+
+	_, body := do(http.MethodGet, "/api/admin/ratelimit/keys?prefix=ip:")
+	require.Equal(t, response.ResponseSuccess.GetCode(), body.Code)
+	assert.Equal(t, []any{map[string]any{"key": key, "limit": float64(2), "remaining": float64(0)}}, body.Data)
+
+	_, body = do(http.MethodGet, "/api/admin/ratelimit/keys?limit=0")
+	assert.Equal(t, response.ResponseParamInvalid.GetCode(), body.Code)
+
+	_, body = do(http.MethodDelete, "/api/admin/ratelimit/keys/"+key)
+	require.Equal(t, response.ResponseSuccess.GetCode(), body.Code, body.Msg)
+	allowed, _ = limiter.Allow(ctx, key, 1, 2)
+	assert.True(t, allowed)
+
+	_, body = do(http.MethodDelete, "/api/admin/ratelimit/keys/ip:missing")
+	assert.Equal(t, response.ResponseFail.GetCode(), body.Code)
+	assert.Equal(t, "未找到限流键: ip:missing", body.Msg)
+
+	app.BaseConfig.RateLimit.Enabled = false
+	_, body = do(http.MethodGet, "/api/admin/ratelimit/keys")
+	assert.Equal(t, response.ResponseFail.GetCode(), body.Code)
+	assert.Equal(t, "未启用限流", body.Msg)
+}
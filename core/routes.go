@@ -0,0 +1,242 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/utils/jsoncodec"
+)
+
+// 路由列表输出格式
+const (
+	RoutesFormatTable = "table" // 表格
+	RoutesFormatJSON  = "json"  // JSON 数组
+)
+
+// RouteInfo 路由信息
+type RouteInfo struct {
+	Method      string   `json:"method"`      // 请求方法
+	Path        string   `json:"path"`        // 完整路径（含路由前缀）
+	HandlerName string   `json:"handlerName"` // 处理函数名
+	Middlewares []string `json:"middlewares"` // 全局中间件（recovery 与 service.middlewares），不含路由分组上的中间件
+	Source      string   `json:"source"`      // 注册该路由的 AddOptionFunc 调用位置，内置路由为函数名，控制器路由为控制器类型和 RegisterController 调用位置
+}
+
+// RouteConflict 重复注册的路由
+type RouteConflict struct {
+	Method       string // 请求方法
+	Path         string // 完整路径
+	FirstSource  string // 先注册的 AddOptionFunc 调用位置或控制器
+	SecondSource string // 后注册的 AddOptionFunc 调用位置或控制器
+}
+
+// RouteConflictError 路由冲突错误
+// 重复注册时 gin 会在后注册的选项函数中 panic，该选项函数在冲突路由之后的路由不会被注册；
+// 处理方式为 warn 时服务仍会启动，但这些路由不可用
+type RouteConflictError struct {
+	Duplicates    []RouteConflict // 被多个选项函数（或同一选项函数多次）注册的路由
+	RoutePrefix   string          // 配置的路由前缀
+	OutsidePrefix []RouteInfo     // 不在路由前缀下的路由
+}
+
+// Error 输出所有冲突，每个冲突一行
+func (e *RouteConflictError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "路由冲突 %d 个", len(e.Duplicates)+len(e.OutsidePrefix))
+	for _, d := range e.Duplicates {
+		fmt.Fprintf(&b, "\n  重复注册 %s %s: %s, %s", d.Method, d.Path, d.FirstSource, d.SecondSource)
+	}
+	for _, r := range e.OutsidePrefix {
+		fmt.Fprintf(&b, "\n  超出路由前缀 %s: %s %s, %s", e.RoutePrefix, r.Method, r.Path, r.Source)
+	}
+	return b.String()
+}
+
+// optionFunc 路由选项函数及其注册位置
+type optionFunc struct {
+	fn     gin.OptionFunc
+	source string // 调用 AddOptionFunc 的位置，格式为 "文件:行号"；控制器为 "控制器类型 (文件:行号)"
+}
+
+// registeredRoutes 最近一次 initEngine 注册的路由
+var (
+	registeredRoutes []RouteInfo
+	routesMu         sync.RWMutex
+)
+
+// Routes 获取已注册的路由列表
+// 在引擎初始化（服务启动）之后可用，按注册顺序排列
+//
+// 返回：
+//   - []RouteInfo: 路由列表副本
+func Routes() []RouteInfo {
+	routesMu.RLock()
+	defer routesMu.RUnlock()
+	routes := make([]RouteInfo, len(registeredRoutes))
+	copy(routes, registeredRoutes)
+	return routes
+}
+
+// setRoutes 保存已注册的路由列表
+func setRoutes(routes []RouteInfo) {
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	registeredRoutes = routes
+}
+
+// callerSource 获取调用位置，格式为 "文件:行号"
+func callerSource(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "unknown:0"
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// applyOptionFuncs 依次应用路由选项函数，记录每条路由的注册位置并检测冲突
+// 参数：
+//   - engine: gin 引擎
+//   - optionFuncs: 路由选项函数列表
+//   - routePrefix: 配置的路由前缀，为空时不检查
+//   - middlewares: 全局中间件名称
+//
+// 返回：
+//   - []RouteInfo: 已注册的路由
+//   - *RouteConflictError: 存在冲突时返回，否则为 nil
+func applyOptionFuncs(engine *gin.Engine, optionFuncs []optionFunc, routePrefix string, middlewares []string) ([]RouteInfo, *RouteConflictError) {
+	var routes []RouteInfo
+	owners := make(map[string]int) // key: "方法 路径"，value: 注册该路由的选项函数下标
+	conflictErr := &RouteConflictError{}
+
+	for i, of := range optionFuncs {
+		if of.fn == nil {
+			continue
+		}
+		duplicatePath := applyOptionFunc(engine, of.fn)
+
+		for _, r := range engine.Routes() {
+			key := r.Method + " " + r.Path
+			if _, ok := owners[key]; ok {
+				continue
+			}
+			owners[key] = i
+			routes = append(routes, RouteInfo{
+				Method:      r.Method,
+				Path:        r.Path,
+				HandlerName: r.Handler,
+				Middlewares: middlewares,
+				Source:      of.source,
+			})
+		}
+
+		if duplicatePath != "" {
+			conflictErr.Duplicates = append(conflictErr.Duplicates, findDuplicates(routes, owners, duplicatePath, i, of.source)...)
+		}
+	}
+
+	if prefix := path.Join("/", routePrefix); prefix != "/" {
+		conflictErr.RoutePrefix = prefix
+		for _, r := range routes {
+			if r.Path != prefix && !strings.HasPrefix(r.Path, prefix+"/") {
+				conflictErr.OutsidePrefix = append(conflictErr.OutsidePrefix, r)
+			}
+		}
+	}
+
+	if len(conflictErr.Duplicates) == 0 && len(conflictErr.OutsidePrefix) == 0 {
+		return routes, nil
+	}
+	return routes, conflictErr
+}
+
+// applyOptionFunc 应用单个路由选项函数
+// 捕获 gin 重复注册路由时的 panic，返回重复的路径；其他 panic 继续抛出
+func applyOptionFunc(engine *gin.Engine, fn gin.OptionFunc) (duplicatePath string) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		msg, ok := r.(string)
+		const marker = "handlers are already registered for path '"
+		if !ok || !strings.HasPrefix(msg, marker) {
+			panic(r)
+		}
+		duplicatePath = strings.TrimSuffix(strings.TrimPrefix(msg, marker), "'")
+	}()
+	engine.With(fn)
+	return ""
+}
+
+// findDuplicates 根据重复的路径找出冲突的路由
+// gin 的 panic 信息只包含路径，优先匹配其他选项函数注册的同路径路由，没有时视为同一选项函数内重复注册
+func findDuplicates(routes []RouteInfo, owners map[string]int, duplicatePath string, current int, source string) []RouteConflict {
+	var others, own []RouteConflict
+	for _, r := range routes {
+		if r.Path != duplicatePath {
+			continue
+		}
+		conflict := RouteConflict{Method: r.Method, Path: r.Path, FirstSource: r.Source, SecondSource: source}
+		if owners[r.Method+" "+r.Path] == current {
+			own = append(own, conflict)
+		} else {
+			others = append(others, conflict)
+		}
+	}
+	if len(others) > 0 {
+		return others
+	}
+	return own
+}
+
+// writeRoutes 按指定格式输出路由列表
+// 参数：
+//   - w: 输出目标
+//   - routes: 路由列表
+//   - format: 输出格式，table 或 json
+//
+// 返回：
+//   - error: 格式不支持或写入失败时返回错误
+func writeRoutes(w io.Writer, routes []RouteInfo, format string) error {
+	switch format {
+	case RoutesFormatJSON:
+		enc := jsoncodec.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(routes)
+	case RoutesFormatTable, "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "METHOD\tPATH\tHANDLER\tMIDDLEWARES\tSOURCE")
+		for _, r := range routes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Method, r.Path, r.HandlerName, strings.Join(r.Middlewares, ","), r.Source)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("不支持的路由列表格式: %s，可选值 table / json", format)
+	}
+}
+
+// printRoutes 初始化引擎并输出路由列表，用于 -print-routes 模式
+// 存在路由冲突时先输出路由列表，再输出冲突信息
+// 参数：
+//   - w: 输出目标
+//   - format: 输出格式，table 或 json
+//
+// 返回值: 进程退出码，无冲突时为 0，存在冲突或输出失败时为 1
+func printRoutes(w io.Writer, format string) int {
+	_, err := initEngine()
+	if writeErr := writeRoutes(w, Routes(), format); writeErr != nil {
+		fmt.Fprintf(w, "[路由] %v\n", writeErr)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(w, "[路由] %v\n", err)
+		return 1
+	}
+	return 0
+}
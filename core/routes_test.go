@@ -0,0 +1,225 @@
+// Package core 路由列表与冲突检测测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含路由列表（Routes）、-print-routes 输出与路由冲突检测的单元测试。
+//
+// 测试覆盖内容：
+// 1. 两个选项函数注册相同的方法和路径 - 返回冲突错误，包含两处 AddOptionFunc 调用位置
+// 2. 用户路由与内置健康检查路由冲突
+// 3. routeConflictPolicy 为 warn 时继续启动，先注册的路由生效
+// 4. 路由超出 service.routePrefix
+// 5. Routes 返回的路由信息与 table / json 输出
+//
+// 运行测试：go test -v ./core/... -run Route
+// ==================================================
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// setupRouteTest 设置服务配置并清空选项函数列表和中间件映射表，测试结束后恢复
+func setupRouteTest(t *testing.T, service config.ServiceInfo) {
+	originalConfig := app.BaseConfig
+	app.BaseConfig = config.BaseConfig{Service: service}
+	middleWareMap = make(map[string]func() gin.HandlerFunc)
+	optionFuncList = make([]optionFunc, 0)
+	t.Cleanup(func() {
+		app.BaseConfig = originalConfig
+		optionFuncList = make([]optionFunc, 0)
+	})
+}
+
+// routeHandler 返回固定文本的处理函数
+func routeHandler(body string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.String(http.StatusOK, body)
+	}
+}
+
+// nextLineSource 返回调用处下一行的位置，格式与 AddOptionFunc 记录的调用位置一致
+func nextLineSource() string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("%s:%d", file, line+1)
+}
+
+// TestInitEngine_DuplicateRoute 测试重复注册检测
+//
+// 【功能点】验证两个选项函数注册相同的方法和路径时返回冲突错误，并包含两处 AddOptionFunc 的调用位置
+// 【测试流程】
+//  1. 两次调用 AddOptionFunc，分别注册 GET /users，另一个注册 POST /users
+//  2. 调用 initEngine，断言返回 *RouteConflictError
+//  3. 断言冲突为 GET /users，FirstSource、SecondSource 分别为两处调用位置，POST /users 不算冲突
+func TestInitEngine_DuplicateRoute(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{})
+
+	firstSource := nextLineSource()
+	AddOptionFunc(func(e *gin.Engine) { e.GET("/users", routeHandler("first")) })
+	secondSource := nextLineSource()
+	AddOptionFunc(func(e *gin.Engine) {
+		e.POST("/users", routeHandler("create"))
+		e.GET("/users", routeHandler("second"))
+	})
+
+	engine, err := initEngine()
+	assert.Nil(t, engine)
+	var conflictErr *RouteConflictError
+	require.True(t, errors.As(err, &conflictErr), "应返回 *RouteConflictError, err: %v", err)
+	require.Len(t, conflictErr.Duplicates, 1)
+	assert.Equal(t, RouteConflict{
+		Method:       http.MethodGet,
+		Path:         "/users",
+		FirstSource:  firstSource,
+		SecondSource: secondSource,
+	}, conflictErr.Duplicates[0])
+	assert.Empty(t, conflictErr.OutsidePrefix)
+	assert.Contains(t, err.Error(), firstSource)
+	assert.Contains(t, err.Error(), secondSource)
+}
+
+// TestInitEngine_DuplicateBuiltinRoute 测试与内置路由冲突
+//
+// 【功能点】验证用户注册 /healthy 时与内置健康检查路由冲突，内置路由的来源为函数名
+// 【测试流程】注册 GET /healthy，断言冲突的 FirstSource 为 core.healthDetactEngine
+func TestInitEngine_DuplicateBuiltinRoute(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{})
+
+	source := nextLineSource()
+	AddOptionFunc(func(e *gin.Engine) { e.GET("/healthy", routeHandler("custom")) })
+
+	_, err := initEngine()
+	var conflictErr *RouteConflictError
+	require.True(t, errors.As(err, &conflictErr))
+	require.Len(t, conflictErr.Duplicates, 1)
+	assert.Equal(t, "core.healthDetactEngine", conflictErr.Duplicates[0].FirstSource)
+	assert.Equal(t, source, conflictErr.Duplicates[0].SecondSource)
+}
+
+// TestInitEngine_DuplicateRouteWarn 测试 warn 处理方式
+//
+// 【功能点】验证 routeConflictPolicy 为 warn 时引擎正常返回，先注册的路由生效
+// 【测试流程】
+//  1. 配置 routeConflictPolicy: warn，两个选项函数注册 GET /users
+//  2. 断言 initEngine 无错误，请求 /users 返回先注册的处理函数结果
+//  3. 断言 Routes 中 GET /users 只出现一次
+func TestInitEngine_DuplicateRouteWarn(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RouteConflictPolicy: config.RouteConflictWarn})
+
+	AddOptionFunc(func(e *gin.Engine) { e.GET("/users", routeHandler("first")) })
+	AddOptionFunc(func(e *gin.Engine) { e.GET("/users", routeHandler("second")) })
+
+	engine := mustInitEngine(t)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, "first", w.Body.String())
+
+	count := 0
+	for _, r := range Routes() {
+		if r.Method == http.MethodGet && r.Path == "/users" {
+			count++
+		}
+	}
+	assert.Equal(t, 1, count)
+}
+
+// TestInitEngine_OutsideRoutePrefix 测试超出路由前缀的检测
+//
+// 【功能点】验证配置 routePrefix 时，通过相对路径注册到前缀之外的路由被报告
+// 【测试流程】
+//  1. 配置 routePrefix 为 /api/v1，注册 /users 和 ../../internal/debug
+//  2. 断言冲突错误中只有 /internal/debug，前缀为 /api/v1
+func TestInitEngine_OutsideRoutePrefix(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "api/v1"})
+
+	source := nextLineSource()
+	AddOptionFunc(func(e *gin.Engine) {
+		e.GET("/users", routeHandler("users"))
+		e.GET("../../internal/debug", routeHandler("debug"))
+	})
+
+	_, err := initEngine()
+	var conflictErr *RouteConflictError
+	require.True(t, errors.As(err, &conflictErr))
+	assert.Empty(t, conflictErr.Duplicates)
+	assert.Equal(t, "/api/v1", conflictErr.RoutePrefix)
+	require.Len(t, conflictErr.OutsidePrefix, 1)
+	assert.Equal(t, "/internal/debug", conflictErr.OutsidePrefix[0].Path)
+	assert.Equal(t, source, conflictErr.OutsidePrefix[0].Source)
+}
+
+// TestRoutes 测试路由列表
+//
+// 【功能点】验证 Routes 返回内置路由和用户路由的方法、路径、处理函数、全局中间件和注册位置，并能输出为 table / json
+// 【测试流程】
+//  1. 注册中间件 testMiddleware，配置路由前缀 /api，注册 GET /orders
+//  2. 断言 Routes 包含 /api/healthy（来源为 core.healthDetactEngine）和 /api/orders（来源为调用位置）
+//  3. 以 json 输出并解析，断言与 Routes 一致；以 table 输出，断言包含表头和路径；不支持的格式返回错误
+func TestRoutes(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api", Middlewares: config.MiddlewareNames("testMiddleware")})
+	RegisterMiddleware("testMiddleware", func() gin.HandlerFunc {
+		return func(c *gin.Context) { c.Next() }
+	})
+
+	source := nextLineSource()
+	AddOptionFunc(func(e *gin.Engine) { e.GET("/orders", routeHandler("orders")) })
+	mustInitEngine(t)
+
+	routes := Routes()
+	byPath := make(map[string]RouteInfo)
+	for _, r := range routes {
+		byPath[r.Method+" "+r.Path] = r
+	}
+	assert.Equal(t, "core.healthDetactEngine", byPath["GET /api/healthy"].Source)
+	orders, ok := byPath["GET /api/orders"]
+	require.True(t, ok)
+	assert.Equal(t, source, orders.Source)
+	assert.Contains(t, orders.HandlerName, "routeHandler")
+	assert.Equal(t, []string{"recovery", "testMiddleware"}, orders.Middlewares)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeRoutes(&buf, routes, RoutesFormatJSON))
+	var decoded []RouteInfo
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, routes, decoded)
+
+	buf.Reset()
+	require.NoError(t, writeRoutes(&buf, routes, RoutesFormatTable))
+	assert.Contains(t, buf.String(), "METHOD")
+	assert.Contains(t, buf.String(), "/api/orders")
+
+	assert.Error(t, writeRoutes(&buf, routes, "xml"))
+}
+
+// TestPrintRoutes 测试 -print-routes 输出
+//
+// 【功能点】验证无冲突时退出码为 0；存在冲突时输出路由列表和冲突信息，退出码为 1
+// 【测试流程】
+//  1. 注册 GET /users，断言退出码为 0 且输出包含 /users
+//  2. 再注册一次 GET /users，断言退出码为 1 且输出包含冲突信息
+func TestPrintRoutes(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{})
+
+	AddOptionFunc(func(e *gin.Engine) { e.GET("/users", routeHandler("first")) })
+	var buf bytes.Buffer
+	assert.Equal(t, 0, printRoutes(&buf, ""))
+	assert.Contains(t, buf.String(), "/users")
+
+	AddOptionFunc(func(e *gin.Engine) { e.GET("/users", routeHandler("second")) })
+	buf.Reset()
+	assert.Equal(t, 1, printRoutes(&buf, RoutesFormatTable))
+	assert.Contains(t, buf.String(), "重复注册 GET /users")
+}
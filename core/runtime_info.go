@@ -0,0 +1,74 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/core/lifecycle"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// runtimeInfoOptionFuncs 运行信息接口的路由选项函数
+// 启用 runtimeInfo 时注册 GET {runtimeInfo.path}（默认 /system/info），返回 app.RuntimeInfo()；
+// 配置 runtimeInfo.middleware 时由该中间件保护。该接口默认不参与限流，见 middleware.RateLimitHandler
+//
+// 返回：
+//   - []optionFunc: 未启用时为空
+//   - error: 保护中间件未注册时返回错误
+func runtimeInfoOptionFuncs() ([]optionFunc, error) {
+	cfg := app.BaseConfig.RuntimeInfo
+	if !cfg.GetEnabled() {
+		return nil, nil
+	}
+	var middlewares []string
+	if cfg.Middleware != "" {
+		middlewares = []string{cfg.Middleware}
+	}
+	fn, err := buildRoutes(cfg.GetPath(), middlewares, []RouteDef{
+		{Method: http.MethodGet, Path: "", Handler: runtimeInfoHandler},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("运行信息接口: %w", err)
+	}
+	return []optionFunc{{fn: fn, source: "core.runtimeInfoOptionFuncs"}}, nil
+}
+
+// runtimeInfoHandler 返回当前进程的运行信息
+func runtimeInfoHandler(c *gin.Context) {
+	response.OkWithData(c, app.RuntimeInfo())
+}
+
+// logStartupInfo 输出启动信息：构建元数据、运行环境、配置目录、启用的服务和中间件
+// 参数：
+//   - configDir: 配置文件目录
+func logStartupInfo(configDir string) {
+	info := app.RuntimeInfo()
+	services := make([]string, 0)
+	for _, service := range lifecycle.GetGlobalRegistry().GetServicesToInit(&app.BaseConfig) {
+		services = append(services, service.Name())
+	}
+	sort.Strings(services)
+
+	// 当前运行环境启用的中间件，按执行顺序排列
+	middlewares := make([]string, 0, len(app.BaseConfig.Service.Middlewares))
+	for _, entry := range app.BaseConfig.Service.Middlewares.ForEnv(info.Env) {
+		middlewares = append(middlewares, entry.Name)
+	}
+
+	logger.InfoWithFields(map[string]any{
+		"version":     info.Version,
+		"commit":      info.Commit,
+		"buildTime":   info.BuildTime,
+		"goVersion":   info.GoVersion,
+		"env":         info.Env,
+		"configDir":   configDir,
+		"pid":         info.Pid,
+		"hostname":    info.Hostname,
+		"services":    services,
+		"middlewares": middlewares,
+	}, "[server] 启动信息, version: %s, commit: %s, env: %s", info.Version, info.Commit, info.Env)
+}
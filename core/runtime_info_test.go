@@ -0,0 +1,123 @@
+// Package core 运行信息接口测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含运行信息接口路由注册和响应内容的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 默认启用，挂载在路由前缀下，响应包含全部构建元数据和运行信息字段
+// 2. 两次请求之间运行时长单调递增
+// 3. 关闭 runtimeInfo.enabled 后不注册接口
+// 4. 自定义路径和保护中间件，中间件未注册时启动失败
+//
+// 运行测试：go test -v ./core/... -run RuntimeInfo
+// ==================================================
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/version"
+)
+
+// getRuntimeInfo 请求运行信息接口，返回状态码和 data 字段
+func getRuntimeInfo(t *testing.T, engine *gin.Engine, path string) (int, map[string]any) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		return w.Code, nil
+	}
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+	return w.Code, body.Data
+}
+
+// TestRuntimeInfo_Response 测试运行信息接口的响应内容
+//
+// 【功能点】验证默认启用的运行信息接口挂载在路由前缀下，响应包含全部字段，运行时长单调递增
+// 【测试流程】
+//  1. 使用默认配置初始化引擎，请求 /api/system/info
+//  2. 断言 data 包含 version、commit、buildTime、goVersion、env、startTime、uptimeSeconds、pid、hostname
+//  3. 再次请求，断言 uptimeSeconds 大于第一次，startTime 不变
+func TestRuntimeInfo_Response(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+
+	engine, err := initEngine()
+	require.NoError(t, err)
+
+	code, first := getRuntimeInfo(t, engine, "/api/system/info")
+	require.Equal(t, http.StatusOK, code)
+	for _, key := range []string{"version", "commit", "buildTime", "goVersion", "env", "startTime", "uptimeSeconds", "pid", "hostname"} {
+		assert.Contains(t, first, key)
+	}
+	assert.Equal(t, version.Version, first["version"])
+	assert.Equal(t, version.GetGoVersion(), first["goVersion"])
+
+	_, second := getRuntimeInfo(t, engine, "/api/system/info")
+	assert.Greater(t, second["uptimeSeconds"].(float64), first["uptimeSeconds"].(float64))
+	assert.Equal(t, first["startTime"], second["startTime"])
+}
+
+// TestRuntimeInfo_Disabled 测试关闭运行信息接口
+//
+// 【功能点】验证 runtimeInfo.enabled 为 false 时不注册接口
+// 【测试流程】关闭后初始化引擎，断言路由列表中没有该接口，请求返回 404
+func TestRuntimeInfo_Disabled(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+	disabled := false
+	app.BaseConfig.RuntimeInfo.Enabled = &disabled
+
+	engine, err := initEngine()
+	require.NoError(t, err)
+	for _, r := range Routes() {
+		assert.NotEqual(t, "/api/system/info", r.Path)
+	}
+	code, _ := getRuntimeInfo(t, engine, "/api/system/info")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+// TestRuntimeInfo_CustomPathAndMiddleware 测试自定义路径和保护中间件
+//
+// 【功能点】验证自定义路径生效并经过保护中间件，中间件未注册时初始化引擎返回错误
+// 【测试流程】
+//  1. 配置未注册的中间件，断言初始化引擎返回错误
+//  2. 注册拒绝缺少 X-Admin 请求头的中间件，断言未带请求头返回 401，带请求头返回 200
+func TestRuntimeInfo_CustomPathAndMiddleware(t *testing.T) {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api"})
+	app.BaseConfig.RuntimeInfo = config.RuntimeInfoConfig{Path: "/ops/info", Middleware: "adminAuth"}
+
+	_, err := initEngine()
+	assert.ErrorContains(t, err, "中间件 adminAuth 未注册")
+
+	middleWareMap["adminAuth"] = func() gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if c.GetHeader("X-Admin") == "" {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			c.Next()
+		}
+	}
+	engine, err := initEngine()
+	require.NoError(t, err)
+
+	code, _ := getRuntimeInfo(t, engine, "/api/ops/info")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/ops/info", nil)
+	req.Header.Set("X-Admin", "1")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
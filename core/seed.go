@@ -0,0 +1,84 @@
+package core
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/initialize"
+	"github.com/zzsen/gin_core/seeds"
+	"gorm.io/gorm"
+)
+
+// RegisterSeeder 注册开发、测试环境的数据填充
+// 应在 Start 之前调用（如 main 函数或 init 函数中），数据填充按注册顺序执行。
+// 启动时在主数据库初始化和迁移完成后，执行与当前运行环境（app.Env）匹配且当前版本未执行过的数据填充，
+// 每个数据填充在单独的事务中执行，失败时只记录错误日志，不影响启动；
+// 也可以通过 -seed / -seed-fresh 参数强制执行后退出
+//
+// 参数：
+//   - name: 数据填充名称，全局唯一
+//   - envs: 可运行的环境，如 []string{"dev", "test"}，不能为空
+//   - fn: 数据填充函数，db 为事务
+//   - opts: seeds.WithVersion 设置版本（版本变化后重新执行），seeds.WithTables 声明 -seed-fresh 时清空的表
+//
+// 使用示例：
+//
+//	core.RegisterSeeder("demo_users", []string{"dev", "test"},
+//	  func(db *gorm.DB) error {
+//	    return db.Create(&[]User{{Name: "alice"}, {Name: "bob"}}).Error
+//	  },
+//	  seeds.WithVersion("2"), seeds.WithTables("users"),
+//	)
+func RegisterSeeder(name string, envs []string, fn func(db *gorm.DB) error, opts ...seeds.Option) {
+	seeds.Register(name, envs, fn, opts...)
+}
+
+// runSeedCommand 连接主数据库强制执行与当前运行环境匹配的数据填充，将结果写入 w
+// 参数：
+//   - w: 输出目标
+//   - fresh: 是否先清空数据填充声明的表
+//
+// 返回：
+//   - int: 进程退出码，全部成功为 0
+func runSeedCommand(w io.Writer, fresh bool) (code int) {
+	if app.BaseConfig.Db == nil {
+		fmt.Fprintln(w, "[数据填充] 未找到主数据库配置（db）")
+		return 1
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(w, "[数据填充] %v\n", r)
+			code = 1
+		}
+	}()
+	initialize.InitDB()
+	defer func() { _ = app.CloseAllDB() }()
+
+	return writeSeedResult(w, app.DB, seeds.Registered(), app.Env, fresh)
+}
+
+// writeSeedResult 在 db 上强制执行 seeders 中与 env 匹配的数据填充，输出执行成功和失败的数据填充
+func writeSeedResult(w io.Writer, db *gorm.DB, seeders []seeds.Seeder, env string, fresh bool) int {
+	runner, err := seeds.NewRunner(db, seeders)
+	if err != nil {
+		fmt.Fprintf(w, "[数据填充] %v\n", err)
+		return 1
+	}
+	result, err := runner.Run(env, seeds.RunOptions{Force: true, Fresh: fresh})
+	if err != nil {
+		fmt.Fprintf(w, "[数据填充] %v\n", err)
+		return 1
+	}
+	for _, name := range result.Applied {
+		fmt.Fprintf(w, "[数据填充] 已执行: %s\n", name)
+	}
+	for _, name := range result.Failed {
+		fmt.Fprintf(w, "[数据填充] 执行失败: %s, %v\n", name, result.Errors[name])
+	}
+	fmt.Fprintf(w, "[数据填充] 完成, 运行环境: %s, 已执行 %d 个, 失败 %d 个\n", env, len(result.Applied), len(result.Failed))
+	if len(result.Failed) > 0 {
+		return 1
+	}
+	return 0
+}
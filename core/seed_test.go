@@ -0,0 +1,84 @@
+// Package core 数据填充命令测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 -seed / -seed-fresh 命令输出的单元测试，使用 SQLite 内存数据库，不需要 MySQL。
+//
+// 测试覆盖内容：
+// 1. 强制执行与运行环境匹配的数据填充，输出执行结果，全部成功时退出码为 0
+// 2. fresh 模式先清空声明的表
+// 3. 存在执行失败的数据填充或数据填充不合法时退出码为 1
+//
+// 运行测试：go test -v ./core/... -run SeedResult
+// ==================================================
+package core
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/seeds"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// seedDemo 数据填充测试使用的表
+type seedDemo struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+}
+
+// TestWriteSeedResult 测试数据填充命令
+//
+// 【功能点】验证强制执行匹配运行环境的数据填充、fresh 模式清空声明的表、失败时退出码为 1
+// 【测试流程】
+//  1. 注册 dev 环境的 demo（声明 seed_demos 表）和 test 环境的 other，在 dev 环境执行两次，断言退出码 0、只执行 demo 且数据累加
+//  2. fresh 模式执行，断言表被清空后只剩一条数据
+//  3. 加入返回错误的数据填充，断言退出码 1 且输出失败原因；名称重复时退出码 1
+func TestWriteSeedResult(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:TestWriteSeedResult?mode=memory&cache=shared"), &gorm.Config{
+		Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&seedDemo{}))
+
+	insert := func(db *gorm.DB) error { return db.Create(&seedDemo{Name: "demo"}).Error }
+	seeders := []seeds.Seeder{
+		{Name: "demo", Envs: []string{"dev"}, Fn: insert, Tables: []string{"seed_demos"}},
+		{Name: "other", Envs: []string{"test"}, Fn: insert},
+	}
+	count := func() int64 {
+		var n int64
+		require.NoError(t, db.Model(&seedDemo{}).Count(&n).Error)
+		return n
+	}
+
+	for i := 0; i < 2; i++ {
+		var out bytes.Buffer
+		assert.Equal(t, 0, writeSeedResult(&out, db, seeders, "dev", false))
+		assert.Contains(t, out.String(), "已执行: demo")
+		assert.NotContains(t, out.String(), "other")
+	}
+	assert.Equal(t, int64(2), count())
+
+	var out bytes.Buffer
+	assert.Equal(t, 0, writeSeedResult(&out, db, seeders, "dev", true))
+	assert.Equal(t, int64(1), count())
+
+	failing := append(seeders, seeds.Seeder{Name: "broken", Envs: []string{"dev"}, Fn: func(db *gorm.DB) error {
+		return errors.New("数据有误")
+	}})
+	out.Reset()
+	assert.Equal(t, 1, writeSeedResult(&out, db, failing, "dev", false))
+	assert.Contains(t, out.String(), "执行失败: broken, 数据有误")
+
+	out.Reset()
+	assert.Equal(t, 1, writeSeedResult(&out, db, append(seeders, seeders[0]), "dev", false))
+	assert.Contains(t, out.String(), "名称重复: demo")
+}
@@ -7,6 +7,8 @@ import (
 	"net/http/pprof"
 	"os"
 	"os/signal"
+	"path"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,6 +17,9 @@ import (
 	"github.com/zzsen/gin_core/core/lifecycle"
 	"github.com/zzsen/gin_core/logger"
 	"github.com/zzsen/gin_core/metrics"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	"google.golang.org/grpc"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,23 +27,24 @@ import (
 // Start 启动 Web 服务器
 // 这是应用程序的主入口函数，负责完整的服务器启动流程（钩子驱动）：
 // 1. overrideValidator — 自定义验证器
-// 2. loadConfig — 加载配置
-// 3. ExecuteAppHooks(AppBeforeInit) — 应用初始化前钩子
+// 2. loadConfig — 加载配置，并校验配置（-validate-config 输出校验报告、-print-routes 输出路由列表、-migrate / -rollback 执行数据库迁移、-seed / -seed-fresh 执行数据填充后直接退出）
+// 3. ExecuteAppHooks(AppBeforeInit) — 应用初始化前钩子，然后执行 AfterConfigLoaded 阶段钩子
 // 4. initMiddleware — 初始化中间件
 // 5. initService — 初始化服务组件
-//   - 成功 → ExecuteAppHooks(AppAfterInit)
+//   - 成功 → ExecuteAppHooks(AppAfterInit)，然后执行 AfterServicesInit 阶段钩子
 //   - 失败 → ExecuteAppHooks(AppOnInitFailed)，然后 panic
 //
-// 6. 创建 HTTP Server
+// 6. 输出启动信息（版本、构建信息、运行环境、启用的服务和中间件），开启 debug.enableStartupTimings 时输出各阶段的启动耗时，创建 HTTP Server，执行 BeforeListen 阶段钩子，启用 grpc 时创建 gRPC 服务（与 HTTP 共用端口时按协议分流，否则在独立端口监听）
 // 7. server.ListenAndServe()
 // 8. ExecuteAppHooks(AppOnReady)（在独立 goroutine 中，确认监听成功后触发）
 //
 // 关闭流程：
-// 9.  收到 SIGINT/SIGTERM
-// 10. ExecuteAppHooks(AppBeforeShutdown)
-// 11. lifecycle.CloseServices()
-// 12. server.Shutdown(shutdownTimeout)
+// 9.  收到 SIGINT/SIGTERM 或调用 Shutdown
+// 10. ExecuteAppHooks(AppBeforeShutdown)，然后执行 BeforeShutdown 阶段钩子
+// 11. server.Shutdown(shutdownTimeout)，同时 gRPC 服务 GracefulStop（超时后强制关闭），然后执行 AfterServerClosed 阶段钩子
+// 12. lifecycle.CloseServices()，然后执行 AfterServicesClosed 阶段钩子
 // 13. ExecuteAppHooks(AppAfterShutdown)
+// 14. logger.Flush，等待异步日志写入完成
 //
 // 服务器特性：
 // - 支持优雅关闭（接收 SIGINT/SIGTERM 信号）
@@ -49,8 +55,36 @@ func Start() {
 	// 1. 重写 gin 的 Validator
 	overrideValidator()
 
-	// 2. 加载配置文件
-	loadConfig(app.Config)
+	// 2. 加载配置文件，之后不再允许注册配置段和设置 JSON 编解码器
+	startupTimings.reset()
+	endLoadConfig := startupTimings.begin(startupPhaseLoadConfig)
+	freezeConfigSections()
+	freezeJSONCodec()
+	freezeStageHooks()
+	cmdArgs := loadConfig(app.Config)
+
+	// 校验配置：-validate-config 模式下输出报告后退出，正常启动时仅输出警告日志
+	if cmdArgs.ValidateConfig {
+		os.Exit(writeValidationReport(os.Stdout, &app.BaseConfig))
+	}
+	logValidationIssues(&app.BaseConfig)
+	endLoadConfig()
+
+	// -print-routes 模式：注册中间件和路由后输出路由列表并退出，不初始化服务组件
+	if cmdArgs.PrintRoutes {
+		initMiddleware()
+		os.Exit(printRoutes(os.Stdout, cmdArgs.RoutesFormat))
+	}
+
+	// -migrate / -rollback 模式：连接主数据库执行或回滚迁移后退出，不初始化服务组件
+	if cmdArgs.Migrate || cmdArgs.Rollback > 0 {
+		os.Exit(runMigrationCommand(os.Stdout, cmdArgs.Rollback))
+	}
+
+	// -seed / -seed-fresh 模式：连接主数据库强制执行数据填充后退出，不初始化服务组件
+	if cmdArgs.Seed || cmdArgs.SeedFresh {
+		os.Exit(runSeedCommand(os.Stdout, cmdArgs.SeedFresh))
+	}
 
 	// 3. 执行应用初始化前钩子
 	if err := lifecycle.ExecuteAppHooks(context.Background(), lifecycle.AppBeforeInit); err != nil {
@@ -58,12 +92,20 @@ func Start() {
 		_ = lifecycle.ExecuteAppHooks(context.Background(), lifecycle.AppOnInitFailed)
 		panic(err)
 	}
+	if err := runStartHooks(AfterConfigLoaded); err != nil {
+		abortStart(err)
+	}
 
 	// 4. 初始化系统中间件
+	endInitMiddleware := startupTimings.begin(startupPhaseInitMiddleware)
 	initMiddleware()
+	endInitMiddleware()
 
-	// 5. 初始化各种服务组件
+	// 5. 初始化各种服务组件，记录各服务的初始化耗时
+	endInitService := startupTimings.begin(startupPhaseInitService)
 	initService()
+	endInitService()
+	startupTimings.recordServices(lifecycle.GetGlobalRegistry(), &app.BaseConfig)
 
 	// 6. 执行应用初始化后钩子
 	if err := lifecycle.ExecuteAppHooks(context.Background(), lifecycle.AppAfterInit); err != nil {
@@ -71,39 +113,98 @@ func Start() {
 		_ = lifecycle.ExecuteAppHooks(context.Background(), lifecycle.AppOnInitFailed)
 		panic(err)
 	}
+	if err := runStartHooks(AfterServicesInit); err != nil {
+		abortStart(err)
+	}
 
-	// 创建用于优雅关闭的上下文
+	// 创建用于优雅关闭的上下文，收到信号或调用 Shutdown 时取消
 	ctx, cancel := context.WithCancel(context.Background())
+	setShutdownTrigger(cancel)
 
 	// 启动 Prometheus 指标收集器
 	if app.BaseConfig.Metrics.Enabled {
 		metrics.StartCollector(ctx, 15*time.Second)
 		logger.Info("[server] Prometheus 指标收集器已启动")
 	}
-	defer cancel()
+	defer func() {
+		setShutdownTrigger(nil)
+		cancel()
+	}()
 
 	// 启动信号监听协程，处理优雅关闭
 	go func() {
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-		<-quit
-		cancel()
+		defer signal.Stop(quit)
+		select {
+		case <-quit:
+			cancel()
+		case <-ctx.Done():
+		}
 	}()
 
+	// 输出启动信息，便于确认当前运行的构建版本
+	logStartupInfo(cmdArgs.Config)
+
 	// 构建服务器监听地址
 	serverAddr := fmt.Sprintf("%s:%d", app.BaseConfig.Service.Ip, app.BaseConfig.Service.Port)
 	logger.Info("[server] Service start by %s:%d", app.BaseConfig.Service.Ip, app.BaseConfig.Service.Port)
 
+	// 初始化引擎，路由冲突时启动失败
+	engine, err := initEngine()
+	if err != nil {
+		logger.Error("[server] 引擎初始化失败: %v", err)
+		_ = lifecycle.ExecuteAppHooks(context.Background(), lifecycle.AppOnInitFailed)
+		panic(err)
+	}
+	startupTimings.finish()
+	if app.BaseConfig.Debug.EnableStartupTimings {
+		logStartupTimings()
+	}
+
 	// 创建 HTTP 服务器实例
 	server := &http.Server{
 		Addr:         serverAddr,
-		Handler:      initEngine(),
+		Handler:      engine,
 		ReadTimeout:  time.Duration(app.BaseConfig.Service.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(app.BaseConfig.Service.WriteTimeout) * time.Second,
 	}
 
-	// 启动优雅关闭处理协程
+	// 执行监听前阶段钩子，失败时中止启动
+	if err := runStartHooks(BeforeListen); err != nil {
+		abortStart(err)
+	}
+
+	// 创建 gRPC 服务：与 HTTP 共用端口时挂载到 HTTP 服务上，否则在独立端口监听
+	var grpcServer *grpc.Server
+	grpcShared := false
+	if grpcCfg := app.BaseConfig.Grpc; grpcCfg.Enabled {
+		grpcPort := grpcCfg.GetPort(app.BaseConfig.Service.Port)
+		grpcShared = grpcPort == app.BaseConfig.Service.Port
+		grpcServer, err = newGrpcServer(grpcCfg, grpcShared)
+		if err != nil {
+			logger.Error("[grpc] gRPC 服务初始化失败: %v", err)
+			_ = lifecycle.ExecuteAppHooks(context.Background(), lifecycle.AppOnInitFailed)
+			panic(err)
+		}
+		if grpcShared {
+			enableSharedGrpc(server, grpcServer)
+			logger.Info("[grpc] Service start by %s (shared with HTTP)", serverAddr)
+		} else {
+			grpcAddr := fmt.Sprintf("%s:%d", app.BaseConfig.Service.Ip, grpcPort)
+			go func() {
+				logger.Info("[grpc] Service start by %s", grpcAddr)
+				if err := serveGrpc(grpcServer, grpcAddr); err != nil {
+					logger.Error("[grpc] gRPC 服务启动异常: %v", err)
+				}
+			}()
+		}
+	}
+
+	// 启动优雅关闭处理协程，完成后关闭 shutdownDone
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
 		<-ctx.Done()
 		fmt.Println("Shutdown HTTP Server ...")
 
@@ -111,23 +212,47 @@ func Start() {
 		if err := lifecycle.ExecuteAppHooks(context.Background(), lifecycle.AppBeforeShutdown); err != nil {
 			logger.Error("[server] AppBeforeShutdown 钩子执行失败: %v", err)
 		}
+		runStopHooks(BeforeShutdown)
 
-		// 11. 关闭各种服务连接
-		_ = lifecycle.CloseServices(context.Background())
-
-		// 12. 使用可配置的关闭超时时间
+		// 11. 使用可配置的关闭超时时间，先关闭 HTTP、gRPC 服务，等待进行中的请求处理完成
 		shutdownSeconds := app.BaseConfig.Service.GetShutdownTimeout()
 		timeout, timeoutCancel := context.WithTimeout(context.Background(), time.Duration(shutdownSeconds)*time.Second)
 		defer timeoutCancel()
 
+		// 独立端口的 gRPC 服务与 HTTP 服务同时关闭，共用关闭超时时间
+		grpcStopped := make(chan struct{})
+		go func() {
+			defer close(grpcStopped)
+			if grpcServer != nil && !grpcShared {
+				stopGrpcServer(grpcServer, time.Duration(shutdownSeconds)*time.Second)
+			}
+		}()
+
 		if err := server.Shutdown(timeout); err != nil {
 			logger.Error("[server] HTTP Server 关闭失败: %v", err)
 		}
+		// 共用端口时进行中的 gRPC 调用已由 server.Shutdown 等待结束
+		if grpcServer != nil && grpcShared {
+			grpcServer.Stop()
+		}
+		<-grpcStopped
+		runStopHooks(AfterServerClosed)
+
+		// 12. 关闭各种服务连接
+		_ = lifecycle.CloseServices(context.Background())
+		runStopHooks(AfterServicesClosed)
 
 		// 13. 执行应用关闭后钩子
 		if err := lifecycle.ExecuteAppHooks(context.Background(), lifecycle.AppAfterShutdown); err != nil {
 			logger.Error("[server] AppAfterShutdown 钩子执行失败: %v", err)
 		}
+
+		// 14. 等待异步日志写入完成
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), time.Duration(shutdownSeconds)*time.Second)
+		defer flushCancel()
+		if err := logger.Flush(flushCtx); err != nil {
+			fmt.Printf("[server] 异步日志写入未完成: %v\n", err)
+		}
 	}()
 
 	// 在非生产环境启动 pprof 性能分析服务器
@@ -161,22 +286,34 @@ func Start() {
 	// 7. 启动主 HTTP 服务器（阻塞调用）
 	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		logger.Error("[server] 服务启动异常: %v", err)
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), time.Duration(app.BaseConfig.Service.GetShutdownTimeout())*time.Second)
+		defer flushCancel()
+		_ = logger.Flush(flushCtx)
+		return
 	}
+	// 服务关闭后 ListenAndServe 立即返回，等待关闭流程（包括关闭后钩子和日志写入）完成后再返回
+	<-shutdownDone
+}
+
+// abortStart 启动阶段钩子失败时中止启动：记录错误日志，执行 AppOnInitFailed 钩子后 panic
+func abortStart(err error) {
+	logger.Error("[server] %v", err)
+	_ = lifecycle.ExecuteAppHooks(context.Background(), lifecycle.AppOnInitFailed)
+	panic(err)
 }
 
 // NotFound 处理404错误（页面不存在）
 // 当请求的路由不存在时，Gin框架会调用此函数
-// 返回标准的HTTP 404响应，并记录详细的错误日志
+// 按 service.notFound 配置输出响应（见 writeNotFound），并记录详细的错误日志
 //
 // 参数 ctx: Gin上下文，包含请求和响应信息
 //
 // 响应内容：
-// - HTTP状态码：404
-// - 响应体：纯文本格式的"Not Found"
+// - 接口请求（路径以路由前缀开头或 Accept 包含 application/json）：HTTP 404 和响应码 50404 的统一响应结构
+// - 其他请求：按 service.notFound.mode 返回统一响应结构、HTML 页面或重定向，未配置时返回纯文本的"Not Found"
 // - 日志：包含请求的详细信息，便于问题排查
 func NotFound(ctx *gin.Context) {
-	// 返回标准的404响应
-	ctx.String(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+	writeNotFound(ctx, http.StatusNotFound, response.ResponseNotFound.GetCode(), response.ResponseNotFound.GetMsg())
 	// 记录详细的错误日志，包含请求信息
 	logger.Error("[server] Status: %d, Times(ms): %d, Ip: %s, Method: %s, Uri: %s, StatusText: %s",
 		ctx.Writer.Status(), 0, ctx.ClientIP(), ctx.Request.Method, ctx.Request.RequestURI, http.StatusText(http.StatusNotFound))
@@ -189,13 +326,53 @@ func NotFound(ctx *gin.Context) {
 // 参数 ctx: Gin上下文，包含请求和响应信息
 //
 // 响应内容：
-// - HTTP状态码：405
-// - 响应体：纯文本格式的"Method Not Allowed"
+// - Allow 响应头：该路径已注册的请求方法，由 Gin 在调用此函数前设置
+// - 接口请求：HTTP 405 和响应码 50405 的统一响应结构；其他请求与 NotFound 相同，按 service.notFound.mode 处理
 // - 日志：包含请求的详细信息，便于问题排查
 func MethodNotAllowed(ctx *gin.Context) {
-	// 返回标准的405响应
-	ctx.String(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+	writeNotFound(ctx, http.StatusMethodNotAllowed, response.ResponseMethodNotAllowed.GetCode(), response.ResponseMethodNotAllowed.GetMsg())
 	// 记录详细的错误日志，包含请求信息
 	logger.Error("[server] Status: %d, Times(ms): %d, Ip: %s, Method: %s, Uri: %s, StatusText: %s",
 		ctx.Writer.Status(), 0, ctx.ClientIP(), ctx.Request.Method, ctx.Request.RequestURI, http.StatusText(http.StatusMethodNotAllowed))
 }
+
+// writeNotFound 按 service.notFound 配置输出 404/405 响应
+// 接口请求或 json 模式返回统一响应结构，HTTP 状态码始终为 status；
+// html 模式返回页面文件（读取失败时退回纯文本），redirect 模式 302 重定向；未配置时返回纯文本的状态描述
+func writeNotFound(ctx *gin.Context, status, code int, msg string) {
+	cfg := app.BaseConfig.Service.NotFound
+	if cfg.Mode == config.NotFoundModeJSON || isAPIRequest(ctx) {
+		ctx.JSON(status, response.Response{
+			Code: code,
+			Data: map[string]any{},
+			Msg:  response.Localize(ctx, code, msg),
+		})
+		return
+	}
+	switch cfg.Mode {
+	case config.NotFoundModeHTML:
+		page, err := os.ReadFile(cfg.HTMLPath)
+		if err == nil {
+			ctx.Data(status, "text/html; charset=utf-8", page)
+			return
+		}
+		logger.Error("[server] 读取 %d 页面失败: %v", status, err)
+	case config.NotFoundModeRedirect:
+		ctx.Redirect(http.StatusFound, cfg.RedirectTo)
+		return
+	}
+	ctx.String(status, http.StatusText(status))
+}
+
+// isAPIRequest 判断请求是否为接口请求：路径以 service.routePrefix 开头（未配置路由前缀时不按路径判断），或 Accept 请求头包含 application/json
+func isAPIRequest(ctx *gin.Context) bool {
+	if strings.Contains(ctx.GetHeader("Accept"), gin.MIMEJSON) {
+		return true
+	}
+	prefix := strings.TrimSuffix(path.Join("/", app.BaseConfig.Service.RoutePrefix), "/")
+	if prefix == "" {
+		return false
+	}
+	requestPath := ctx.Request.URL.Path
+	return requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/")
+}
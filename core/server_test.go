@@ -6,18 +6,26 @@
 // 测试覆盖内容：
 // 1. NotFound - 404 错误处理函数
 // 2. MethodNotAllowed - 405 错误处理函数
+// 3. service.notFound 配置：接口请求返回统一响应结构，其他请求返回 HTML 页面或重定向，405 的 Allow 响应头
 //
 // 运行测试：go test -v ./core/... -run Server
 // ==================================================
 package core
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
 )
 
 // ==================== NotFound 测试 ====================
@@ -287,6 +295,117 @@ func TestNotFoundAndMethodNotAllowed_Combined(t *testing.T) {
 	})
 }
 
+// ==================== service.notFound 配置测试 ====================
+
+// setupNotFoundTest 使用路由前缀 /api 和指定的 404/405 配置构建引擎，注册 GET、PUT /api/users/:id 路由
+func setupNotFoundTest(t *testing.T, notFound config.NotFoundConfig) *gin.Engine {
+	setupRouteTest(t, config.ServiceInfo{RoutePrefix: "/api", NotFound: notFound})
+	AddOptionFunc(func(e *gin.Engine) {
+		e.GET("/users/:id", routeHandler("get"))
+		e.PUT("/users/:id", routeHandler("put"))
+	})
+	return mustInitEngine(t)
+}
+
+// performNotFoundRequest 发送请求，accept 不为空时设置 Accept 请求头
+func performNotFoundRequest(engine *gin.Engine, method, path, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+// browserAccept 浏览器访问页面时的 Accept 请求头
+const browserAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+// TestNotFound_APIRequestReturnsEnvelope 测试接口请求返回统一响应结构
+//
+// 【功能点】验证路径以路由前缀开头或 Accept 包含 application/json 的请求，无论 mode 如何配置都返回统一响应结构
+// 【测试流程】
+//  1. 配置 html 模式，请求 /api/missing，断言 HTTP 404、JSON 响应、响应码 50404
+//  2. 以 Accept: application/json 请求路由前缀之外的 /missing，断言同样返回统一响应结构
+func TestNotFound_APIRequestReturnsEnvelope(t *testing.T) {
+	page := filepath.Join(t.TempDir(), "404.html")
+	require.NoError(t, os.WriteFile(page, []byte("<h1>404</h1>"), 0o644))
+	engine := setupNotFoundTest(t, config.NotFoundConfig{Mode: config.NotFoundModeHTML, HTMLPath: page})
+
+	for _, tc := range []struct{ path, accept string }{
+		{"/api/missing", browserAccept},
+		{"/missing", "application/json"},
+	} {
+		w := performNotFoundRequest(engine, http.MethodGet, tc.path, tc.accept)
+		assert.Equal(t, http.StatusNotFound, w.Code, tc.path)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json", tc.path)
+		var body response.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), tc.path)
+		assert.Equal(t, response.ResponseNotFound.GetCode(), body.Code, tc.path)
+		assert.Equal(t, response.ResponseNotFound.GetMsg(), body.Msg, tc.path)
+	}
+}
+
+// TestNotFound_BrowserRequestReturnsHTML 测试浏览器请求返回 HTML 页面
+//
+// 【功能点】验证 html 模式下路由前缀之外的非 JSON 请求返回配置的页面，HTTP 状态码保持 404；页面文件不存在时退回纯文本
+// 【测试流程】
+//  1. 配置 html 模式和页面文件，以浏览器的 Accept 请求 /missing，断言 HTTP 404、text/html 响应和页面内容
+//  2. 删除页面文件后再次请求，断言返回纯文本 "Not Found"
+func TestNotFound_BrowserRequestReturnsHTML(t *testing.T) {
+	page := filepath.Join(t.TempDir(), "404.html")
+	require.NoError(t, os.WriteFile(page, []byte("<h1>页面不存在</h1>"), 0o644))
+	engine := setupNotFoundTest(t, config.NotFoundConfig{Mode: config.NotFoundModeHTML, HTMLPath: page})
+
+	w := performNotFoundRequest(engine, http.MethodGet, "/missing", browserAccept)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Equal(t, "<h1>页面不存在</h1>", w.Body.String())
+
+	require.NoError(t, os.Remove(page))
+	w = performNotFoundRequest(engine, http.MethodGet, "/missing", browserAccept)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "Not Found", w.Body.String())
+}
+
+// TestMethodNotAllowed_AllowHeader 测试 405 响应的 Allow 响应头
+//
+// 【功能点】验证 405 响应的 Allow 响应头恰好包含该路径已注册的请求方法，接口请求返回响应码 50405
+// 【测试流程】
+//  1. 注册 GET、PUT /api/users/:id，以 POST、DELETE 请求 /api/users/1
+//  2. 断言 HTTP 405、Allow 响应头为 GET 和 PUT、响应码 50405
+func TestMethodNotAllowed_AllowHeader(t *testing.T) {
+	engine := setupNotFoundTest(t, config.NotFoundConfig{})
+
+	for _, method := range []string{http.MethodPost, http.MethodDelete} {
+		w := performNotFoundRequest(engine, method, "/api/users/1", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code, method)
+		allowed := strings.Split(w.Header().Get("Allow"), ", ")
+		assert.ElementsMatch(t, []string{http.MethodGet, http.MethodPut}, allowed, method)
+		var body response.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), method)
+		assert.Equal(t, response.ResponseMethodNotAllowed.GetCode(), body.Code, method)
+	}
+}
+
+// TestNotFound_RedirectMode 测试 redirect 模式
+//
+// 【功能点】验证 redirect 模式下路由前缀之外的 404 请求 302 重定向到配置的地址，接口请求不重定向
+// 【测试流程】
+//  1. 配置 redirect 模式，请求 /missing，断言 HTTP 302 和 Location 响应头
+//  2. 请求 /api/missing，断言返回 HTTP 404 而不是重定向
+func TestNotFound_RedirectMode(t *testing.T) {
+	engine := setupNotFoundTest(t, config.NotFoundConfig{Mode: config.NotFoundModeRedirect, RedirectTo: "/index.html"})
+
+	w := performNotFoundRequest(engine, http.MethodGet, "/missing", browserAccept)
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/index.html", w.Header().Get("Location"))
+
+	w = performNotFoundRequest(engine, http.MethodGet, "/api/missing", browserAccept)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("Location"))
+}
+
 // ==================== 边界情况测试 ====================
 
 // TestNotFound_EdgeCases 测试 NotFound 边界情况
@@ -2,6 +2,8 @@ package core
 
 import (
 	"context"
+	"os/signal"
+	"syscall"
 
 	"github.com/zzsen/gin_core/app"
 	"github.com/zzsen/gin_core/core/lifecycle"
@@ -92,14 +94,25 @@ func AddMessageQueueConsumer(messageQueue *config.MessageQueue) {
 	lifecycle.AddMessageQueueConsumer(messageQueue)
 }
 
+// UseConsumerMiddleware 添加全局消费中间件，作用于所有通过 AddMessageQueueConsumer 添加的消费者
+// 全局中间件按添加顺序执行，且在消费者通过 MessageQueue.Use 添加的中间件之前执行
+//
+// 使用示例：
+//
+//	core.UseConsumerMiddleware(middleware.ConsumerRecoveryMiddleware, middleware.ConsumerLoggingMiddleware)
+func UseConsumerMiddleware(mw ...config.ConsumerMiddleware) {
+	lifecycle.UseConsumerMiddleware(mw...)
+}
+
 // AddMessageQueueProducer 添加消息队列发送者配置
 func AddMessageQueueProducer(messageQueue *config.MessageQueue) {
 	lifecycle.AddMessageQueueProducer(messageQueue)
 }
 
 // AddSchedule 添加定时任务配置
-func AddSchedule(schedule config.ScheduleInfo) {
-	lifecycle.AddSchedule(schedule)
+// cron 表达式或时区无效时返回包含任务名称的错误，任务不会被添加
+func AddSchedule(schedule config.ScheduleInfo) error {
+	return lifecycle.AddSchedule(schedule)
 }
 
 // --- 内部使用函数 ---
@@ -121,15 +134,37 @@ func registerBuiltinServices() {
 	// 注册Elasticsearch服务
 	_ = RegisterService(&services.ElasticsearchService{})
 
-	// 注册RabbitMQ服务
+	// 注册RabbitMQ服务，注册前为消费者加上全局消费中间件
+	lifecycle.ApplyConsumerMiddlewares()
 	_ = RegisterService(services.NewRabbitMQService(
 		lifecycle.GetMessageQueueConsumerList(),
 		lifecycle.GetMessageQueueProducerList(),
 	))
 
+	// 注册发件箱中继服务
+	_ = RegisterService(&services.OutboxService{})
+
 	// 注册Etcd服务
 	_ = RegisterService(&services.EtcdService{})
 
+	// 注册对象存储服务
+	_ = RegisterService(&services.ObjectStorageService{})
+
+	// 注册熔断器状态变更通知服务
+	_ = RegisterService(&services.CircuitBreakerService{})
+
+	// 注册后台任务执行器服务
+	_ = RegisterService(&services.TasksService{})
+
+	// 注册事件总线服务
+	_ = RegisterService(&services.EventsService{})
+
+	// 注册已处理消息表服务，配置了保留天数时添加清理过期记录的定时任务
+	_ = RegisterService(&services.ProcessedMessageService{})
+	if cfg := app.BaseConfig.ProcessedMessage; cfg.RetentionDays > 0 {
+		_ = lifecycle.AddSchedule(services.ProcessedMessageSweepSchedule(cfg))
+	}
+
 	// 注册定时任务服务
 	_ = RegisterService(services.NewScheduleService(lifecycle.GetScheduleList()))
 }
@@ -139,8 +174,9 @@ func initService() {
 	// 注册内置服务
 	registerBuiltinServices()
 
-	// 使用并行初始化器初始化所有服务
-	ctx := context.Background()
+	// 使用并行初始化器初始化所有服务，初始化期间收到 SIGINT/SIGTERM 时取消，等待依赖服务就绪的重试立即结束
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 	if err := lifecycle.InitAllServices(ctx, &app.BaseConfig); err != nil {
 		panic(err)
 	}
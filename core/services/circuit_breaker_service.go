@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/circuitbreaker"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// CircuitBreakerService 熔断器状态变更通知服务
+// 订阅所有熔断器的状态变更事件，按 circuitBreaker 配置记录日志并发送 Webhook 通知
+type CircuitBreakerService struct {
+	unsubscribeLog func()
+	notifier       *circuitbreaker.WebhookNotifier
+}
+
+// Name 返回服务名称
+func (s *CircuitBreakerService) Name() string { return "circuitBreaker" }
+
+// Priority 返回初始化优先级
+func (s *CircuitBreakerService) Priority() int { return 5 }
+
+// Dependencies 返回依赖
+func (s *CircuitBreakerService) Dependencies() []string { return []string{"logger"} }
+
+// ShouldInit 开启状态变更日志或配置了 Webhook 地址时初始化
+func (s *CircuitBreakerService) ShouldInit(cfg *config.BaseConfig) bool {
+	return cfg.CircuitBreaker.GetLogEvents() || cfg.CircuitBreaker.WebhookURL != ""
+}
+
+// Init 订阅熔断器状态变更事件
+func (s *CircuitBreakerService) Init(ctx context.Context) error {
+	cfg := app.BaseConfig.CircuitBreaker
+	if cfg.GetLogEvents() {
+		s.unsubscribeLog = circuitbreaker.Subscribe(circuitbreaker.LogEvent)
+	}
+	if cfg.WebhookURL != "" {
+		s.notifier = circuitbreaker.NewWebhookNotifier(circuitbreaker.WebhookOptions{
+			URL:           cfg.WebhookURL,
+			Timeout:       time.Duration(cfg.GetWebhookTimeout()) * time.Second,
+			MaxRetries:    cfg.GetWebhookMaxRetries(),
+			RetryInterval: time.Duration(cfg.GetWebhookRetryInterval()) * time.Millisecond,
+		})
+		s.notifier.Start()
+		logger.Info("[熔断器] 状态变更 Webhook 通知已启动, 地址: %s", cfg.WebhookURL)
+	}
+	return nil
+}
+
+// Close 取消订阅
+func (s *CircuitBreakerService) Close(ctx context.Context) error {
+	if s.unsubscribeLog != nil {
+		s.unsubscribeLog()
+	}
+	if s.notifier != nil {
+		s.notifier.Stop()
+	}
+	return nil
+}
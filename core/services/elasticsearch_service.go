@@ -3,9 +3,11 @@ package services
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/zzsen/gin_core/app"
 	"github.com/zzsen/gin_core/initialize"
+	"github.com/zzsen/gin_core/logger"
 	"github.com/zzsen/gin_core/model/config"
 )
 
@@ -29,12 +31,26 @@ func (s *ElasticsearchService) ShouldInit(cfg *config.BaseConfig) bool {
 // Init 初始化Elasticsearch
 func (s *ElasticsearchService) Init(ctx context.Context) error {
 	// 验证配置
-	if app.BaseConfig.Es == nil {
+	if app.BaseConfig.Es == nil && len(app.BaseConfig.EsList) == 0 {
 		return fmt.Errorf("未找到有效的Elasticsearch配置")
 	}
 
-	// 初始化Elasticsearch客户端
+	// 初始化Elasticsearch客户端，集群暂时不可达时不中断启动
 	initialize.InitElasticsearch()
+	if !app.BaseConfig.System.StartupRetry.Enabled {
+		return nil
+	}
+
+	// 启用启动重试时等待所有集群连接成功；降级启动后访问集群时仍按退避时间自动重连，就绪检查按集群实际状态报告，不标记为不可用
+	err := waitForDependency(ctx, s.Name(), func() error {
+		return app.ConnectES(ctx)
+	}, nil)
+	if err != nil && (ctx.Err() != nil || !app.BaseConfig.System.StartupRetry.IsOptional(s.Name())) {
+		return err
+	}
+	if err != nil {
+		logger.Error("[启动重试] %s 不可用，降级启动: %v", s.Name(), err)
+	}
 	return nil
 }
 
@@ -45,10 +61,21 @@ func (s *ElasticsearchService) Close(ctx context.Context) error {
 }
 
 // HealthCheck 健康检查
+// 探测所有已配置的集群，任一集群不可用时返回包含各集群错误的错误
 func (s *ElasticsearchService) HealthCheck(ctx context.Context) error {
-	if app.ES == nil {
+	aliases := app.ESAliases()
+	if len(aliases) == 0 {
 		return fmt.Errorf("elasticsearch未初始化")
 	}
-	_, err := app.ES.Info().Do(ctx)
-	return err
+	var failed []string
+	health := app.ESHealth(ctx)
+	for _, alias := range aliases {
+		if err := health[alias]; err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", alias, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("elasticsearch 集群不可用: %s", strings.Join(failed, "; "))
+	}
+	return nil
 }
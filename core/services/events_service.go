@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/events"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// EventsService 事件总线服务
+// 启动时根据 events 配置设置默认事件总线，关闭时停止接收异步事件并在关闭超时时间内处理完队列中的事件
+type EventsService struct{}
+
+// Name 返回服务名称
+func (s *EventsService) Name() string { return "events" }
+
+// Priority 返回初始化优先级
+func (s *EventsService) Priority() int { return 50 }
+
+// Dependencies 返回依赖
+// 依赖处理函数中可能用到的组件，使关闭时先处理完队列中的事件再关闭这些组件
+func (s *EventsService) Dependencies() []string {
+	return []string{"logger", "redis", "mysql", "elasticsearch", "rabbitmq", "etcd"}
+}
+
+// ShouldInit 事件总线始终初始化
+func (s *EventsService) ShouldInit(cfg *config.BaseConfig) bool {
+	return true
+}
+
+// Init 按配置设置默认事件总线
+func (s *EventsService) Init(ctx context.Context) error {
+	cfg := app.BaseConfig.Events
+	if err := events.Default().Configure(events.Options{
+		Workers:   cfg.GetWorkers(),
+		QueueSize: cfg.GetQueueSize(),
+		Timeout:   time.Duration(cfg.GetTimeout()) * time.Second,
+	}); err != nil {
+		logger.Warn("[事件] %v, 继续使用当前配置", err)
+	}
+	logger.Info("[事件] 事件总线已启动, 协程数: %d, 队列容量: %d", cfg.GetWorkers(), cfg.GetQueueSize())
+	return nil
+}
+
+// Close 停止接收异步事件，在 service.shutdownTimeout 内处理完队列中的事件
+func (s *EventsService) Close(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(app.BaseConfig.Service.GetShutdownTimeout())*time.Second)
+	defer cancel()
+	abandoned, err := events.Default().Shutdown(ctx)
+	if err != nil {
+		logger.Warn("[事件] 关闭超时, 放弃 %d 次异步处理", abandoned)
+		return nil
+	}
+	logger.Info("[事件] 事件总线已关闭")
+	return nil
+}
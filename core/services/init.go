@@ -8,6 +8,10 @@
 //   - ElasticsearchService: Elasticsearch搜索服务（优先级20，依赖logger）
 //   - RabbitMQService: RabbitMQ消息队列服务（优先级30，依赖logger）
 //   - EtcdService: Etcd配置中心服务（优先级20，依赖logger）
+//   - ObjectStorageService: 对象存储服务（优先级20，依赖logger）
+//   - CircuitBreakerService: 熔断器状态变更通知服务（优先级5，依赖logger，记录日志并发送 Webhook 通知）
+//   - TasksService: 后台任务执行器服务（优先级50，依赖logger及各存储组件，关闭时先执行完队列中的任务）
+//   - EventsService: 事件总线服务（优先级50，依赖logger及各存储组件，关闭时先处理完队列中的异步事件）
 //   - ScheduleService: 定时任务服务（优先级100，依赖logger）
 //
 // 使用示例：
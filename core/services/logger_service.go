@@ -25,14 +25,15 @@ func (s *LoggerService) ShouldInit(cfg *config.BaseConfig) bool {
 	return true
 }
 
-// Init 初始化日志
+// Init 初始化日志，按 log.async 开启或关闭异步写入
 func (s *LoggerService) Init(ctx context.Context) error {
 	logger.Logger = logger.InitLogger(app.BaseConfig.Log)
 	app.Logger = logger.Logger
+	logger.SetAsync(app.BaseConfig.Log.Async)
 	return nil
 }
 
-// Close 关闭日志（通常不需要）
+// Close 等待异步写入缓冲区中的日志写入完成，之后记录的日志仍正常写入
 func (s *LoggerService) Close(ctx context.Context) error {
-	return nil
+	return logger.Flush(ctx)
 }
@@ -6,7 +6,10 @@ import (
 
 	"github.com/zzsen/gin_core/app"
 	"github.com/zzsen/gin_core/initialize"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/migrations"
 	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/seeds"
 )
 
 // MySQLService MySQL数据库服务
@@ -19,7 +22,8 @@ func (s *MySQLService) Name() string { return "mysql" }
 func (s *MySQLService) Priority() int { return 10 }
 
 // Dependencies 返回依赖
-func (s *MySQLService) Dependencies() []string { return []string{"logger"} }
+// 依赖 Redis（启用时）：通过 app.EnableQueryCache 开启查询缓存的模型需要在 Redis 初始化后添加查询缓存插件
+func (s *MySQLService) Dependencies() []string { return []string{"logger", "redis"} }
 
 // ShouldInit 根据配置判断是否需要初始化
 func (s *MySQLService) ShouldInit(cfg *config.BaseConfig) bool {
@@ -27,21 +31,64 @@ func (s *MySQLService) ShouldInit(cfg *config.BaseConfig) bool {
 }
 
 // Init 初始化MySQL
+// 启用 system.startupRetry 时连接失败会重试，超过最长等待时间后按 optional 配置降级启动或启动失败，降级启动时不执行迁移和数据填充
 func (s *MySQLService) Init(ctx context.Context) error {
 	// 验证配置
 	if app.BaseConfig.Db == nil && len(app.BaseConfig.DbList) == 0 && len(app.BaseConfig.DbResolvers) == 0 {
 		return fmt.Errorf("未找到有效的数据库配置")
 	}
 
-	// 初始化主数据库连接
-	initialize.InitDB()
-	// 初始化多数据库连接列表
-	initialize.InitDBList()
-	// 初始化数据库读写分离解析器
-	initialize.InitDBResolver()
+	err := waitForDependency(ctx, s.Name(), func() error {
+		// 初始化主数据库连接
+		initialize.InitDB()
+		// 初始化多数据库连接列表
+		initialize.InitDBList()
+		// 初始化数据库读写分离解析器
+		initialize.InitDBResolver()
+		return nil
+	}, resetDB)
+	if err != nil {
+		return degradeOrFail(ctx, s.Name(), err)
+	}
+
+	// 执行通过 core.RegisterMigration 注册的待执行迁移
+	if app.BaseConfig.Db != nil && app.BaseConfig.Db.AutoMigrate {
+		runner, err := migrations.NewRunner(app.DB, migrations.Registered())
+		if err != nil {
+			return fmt.Errorf("数据库迁移: %w", err)
+		}
+		if _, err := runner.Up(); err != nil {
+			return fmt.Errorf("数据库迁移: %w", err)
+		}
+	}
+
+	runSeeders()
 	return nil
 }
 
+// runSeeders 执行通过 core.RegisterSeeder 注册、与当前运行环境匹配且当前版本未执行过的数据填充
+// 数据填充只用于开发、测试环境，失败时记录错误日志，不影响启动
+func runSeeders() {
+	registered := seeds.Registered()
+	if app.DB == nil || len(registered) == 0 {
+		return
+	}
+	runner, err := seeds.NewRunner(app.DB, registered)
+	if err != nil {
+		logger.Error("[数据填充] %v", err)
+		return
+	}
+	if _, err := runner.Run(app.Env, seeds.RunOptions{}); err != nil {
+		logger.Error("[数据填充] %v", err)
+	}
+}
+
+// resetDB 关闭连接失败前已创建的数据库连接，避免重试时泄漏
+func resetDB() {
+	_ = app.CloseAllDB()
+	app.DB, app.DBResolver, app.DBList = nil, nil, nil
+}
+
 // Close 关闭数据库连接，显式释放所有 sql.DB 底层连接资源
 func (s *MySQLService) Close(ctx context.Context) error {
 	return app.CloseAllDB()
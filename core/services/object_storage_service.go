@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/initialize"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// ObjectStorageService 对象存储服务
+type ObjectStorageService struct{}
+
+// Name 返回服务名称
+func (s *ObjectStorageService) Name() string { return "objectStorage" }
+
+// Priority 返回初始化优先级
+func (s *ObjectStorageService) Priority() int { return 20 }
+
+// Dependencies 返回依赖
+func (s *ObjectStorageService) Dependencies() []string { return []string{"logger"} }
+
+// ShouldInit 根据配置判断是否需要初始化
+func (s *ObjectStorageService) ShouldInit(cfg *config.BaseConfig) bool {
+	return cfg.System.UseObjectStorage
+}
+
+// Init 初始化对象存储客户端
+func (s *ObjectStorageService) Init(ctx context.Context) error {
+	initialize.InitObjectStorage()
+	return nil
+}
+
+// Close 关闭对象存储客户端
+func (s *ObjectStorageService) Close(ctx context.Context) error {
+	// 客户端基于 HTTP 请求，不需要显式关闭
+	return nil
+}
+
+// HealthCheck 健康检查
+// 发送 HEAD 请求探测存储桶是否存在且有访问权限
+func (s *ObjectStorageService) HealthCheck(ctx context.Context) error {
+	if app.Storage == nil {
+		return fmt.Errorf("对象存储未初始化")
+	}
+	return app.Storage.HeadBucket(ctx)
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/outbox"
+	"gorm.io/gorm"
+)
+
+// OutboxService 发件箱中继服务
+// 自动创建 outbox_messages 表，并在后台将事务中写入的消息投递到 RabbitMQ
+type OutboxService struct {
+	relay     *outbox.Relay
+	publisher *app.RabbitMQOutboxPublisher
+}
+
+// Name 返回服务名称
+func (s *OutboxService) Name() string { return "outbox" }
+
+// Priority 返回初始化优先级（在 MySQL、RabbitMQ 之后）
+func (s *OutboxService) Priority() int { return 40 }
+
+// Dependencies 返回依赖
+func (s *OutboxService) Dependencies() []string { return []string{"logger", "mysql", "rabbitmq"} }
+
+// ShouldInit 根据配置判断是否需要初始化
+func (s *OutboxService) ShouldInit(cfg *config.BaseConfig) bool {
+	return cfg.Outbox.Enabled
+}
+
+// Init 创建发件箱表并启动中继
+func (s *OutboxService) Init(ctx context.Context) error {
+	if !app.BaseConfig.System.UseMysql || !app.BaseConfig.System.UseRabbitMQ {
+		return fmt.Errorf("发件箱需要同时开启 system.useMysql 和 system.useRabbitMQ")
+	}
+
+	db, err := outboxDB(app.BaseConfig.Outbox.DbAliasName)
+	if err != nil {
+		return err
+	}
+	if err := outbox.AutoMigrate(db); err != nil {
+		return fmt.Errorf("创建发件箱表失败: %w", err)
+	}
+
+	cfg := app.BaseConfig.Outbox
+	s.publisher = app.NewRabbitMQOutboxPublisher(time.Duration(cfg.GetConfirmTimeout()) * time.Second)
+	s.relay = outbox.NewRelay(db, s.publisher, outbox.Options{
+		PollInterval: time.Duration(cfg.GetPollInterval()) * time.Millisecond,
+		BatchSize:    cfg.GetBatchSize(),
+		MaxRetries:   cfg.GetMaxRetries(),
+		MaxBackoff:   time.Duration(cfg.GetMaxBackoff()) * time.Second,
+	})
+	s.relay.Start()
+	logger.Info("[发件箱] 中继已启动, 轮询间隔: %dms, 批量大小: %d, 最大重试次数: %d",
+		cfg.GetPollInterval(), cfg.GetBatchSize(), cfg.GetMaxRetries())
+	return nil
+}
+
+// Close 停止中继并关闭发布连接
+func (s *OutboxService) Close(ctx context.Context) error {
+	if s.relay != nil {
+		s.relay.Stop()
+		logger.Info("[发件箱] 中继已停止")
+	}
+	if s.publisher != nil {
+		s.publisher.Close()
+	}
+	return nil
+}
+
+// outboxDB 获取发件箱表所在的数据库，别名为空时使用主数据库
+func outboxDB(aliasName string) (*gorm.DB, error) {
+	if aliasName != "" {
+		return app.GetDbByName(aliasName)
+	}
+	if app.DB == nil {
+		return nil, fmt.Errorf("主数据库未初始化，请配置 db 或通过 outbox.dbAliasName 指定数据库")
+	}
+	return app.DB, nil
+}
@@ -0,0 +1,97 @@
+// Package services 发件箱服务测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含发件箱中继服务的单元测试，使用 SQLite 内存数据库，不需要 MySQL / RabbitMQ。
+//
+// 测试覆盖内容：
+// 1. Name/Priority/Dependencies - 服务元数据方法
+// 2. ShouldInit - 初始化条件判断
+// 3. Init - 未开启 MySQL/RabbitMQ 时返回错误
+// 4. Init - 自动创建发件箱表并启动中继，app.PublishInTx 写入待发送消息
+//
+// 运行测试：go test -v ./core/services/... -run Outbox
+// ==================================================
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/outbox"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// TestOutboxService_Metadata 测试服务元数据
+//
+// 【功能点】验证服务名称、优先级、依赖以及 ShouldInit 判断
+// 【测试流程】检查 Name/Priority/Dependencies 返回值，分别在 outbox.enabled 开关下调用 ShouldInit
+func TestOutboxService_Metadata(t *testing.T) {
+	s := &OutboxService{}
+	assert.Equal(t, "outbox", s.Name())
+	assert.Equal(t, 40, s.Priority())
+	assert.Equal(t, []string{"logger", "mysql", "rabbitmq"}, s.Dependencies())
+
+	assert.False(t, s.ShouldInit(&config.BaseConfig{}))
+	assert.True(t, s.ShouldInit(&config.BaseConfig{Outbox: config.OutboxConfig{Enabled: true}}))
+}
+
+// TestOutboxService_Init_RequiresMysqlAndRabbitMQ 测试缺少依赖组件时初始化失败
+//
+// 【功能点】验证未开启 system.useMysql 或 system.useRabbitMQ 时 Init 返回错误
+// 【测试流程】只开启 RabbitMQ 调用 Init，断言返回错误
+func TestOutboxService_Init_RequiresMysqlAndRabbitMQ(t *testing.T) {
+	originalConfig := app.BaseConfig
+	defer func() { app.BaseConfig = originalConfig }()
+	app.BaseConfig = config.BaseConfig{
+		System: config.SystemInfo{UseRabbitMQ: true},
+		Outbox: config.OutboxConfig{Enabled: true},
+	}
+
+	assert.Error(t, (&OutboxService{}).Init(context.Background()))
+}
+
+// TestOutboxService_InitAndClose 测试初始化与关闭
+//
+// 【功能点】验证 Init 自动创建 outbox_messages 表并启动中继，Close 停止中继
+// 【测试流程】
+//  1. 使用 SQLite 内存数据库作为 app.DB，调用 Init
+//  2. 断言发件箱表已创建，调用 Close 停止中继
+//  3. 通过 app.PublishInTx 在事务中写入消息，状态为 pending
+func TestOutboxService_InitAndClose(t *testing.T) {
+	originalConfig, originalDB := app.BaseConfig, app.DB
+	defer func() { app.BaseConfig, app.DB = originalConfig, originalDB }()
+
+	db, err := gorm.Open(sqlite.Open("file:outbox_service?mode=memory&cache=shared"), &gorm.Config{
+		Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	app.DB = db
+	app.BaseConfig = config.BaseConfig{
+		System: config.SystemInfo{UseMysql: true, UseRabbitMQ: true},
+		Outbox: config.OutboxConfig{Enabled: true},
+	}
+
+	s := &OutboxService{}
+	require.NoError(t, s.Init(context.Background()))
+	assert.True(t, db.Migrator().HasTable(outbox.TableName))
+	assert.NoError(t, s.Close(context.Background()))
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return app.PublishInTx(tx, app.OutboxMessage{Exchange: "order", RoutingKey: "order.created", Body: "{}"})
+	})
+	require.NoError(t, err)
+	var msg app.OutboxMessage
+	require.NoError(t, db.First(&msg).Error)
+	assert.Equal(t, outbox.StatusPending, msg.Status)
+	assert.Equal(t, "direct", msg.ExchangeType)
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/exception/mq"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"gorm.io/gorm"
+)
+
+// ProcessedMessageService 已处理消息表服务
+// 启动时自动创建 mq.ProcessOnce 使用的 processed_messages 表
+type ProcessedMessageService struct{}
+
+// Name 返回服务名称
+func (s *ProcessedMessageService) Name() string { return "processedMessage" }
+
+// Priority 返回初始化优先级（在 MySQL 之后）
+func (s *ProcessedMessageService) Priority() int { return 40 }
+
+// Dependencies 返回依赖
+func (s *ProcessedMessageService) Dependencies() []string { return []string{"logger", "mysql"} }
+
+// ShouldInit 根据配置判断是否需要初始化
+func (s *ProcessedMessageService) ShouldInit(cfg *config.BaseConfig) bool {
+	return cfg.ProcessedMessage.AutoMigrate
+}
+
+// Init 创建已处理消息表
+func (s *ProcessedMessageService) Init(ctx context.Context) error {
+	if !app.BaseConfig.System.UseMysql {
+		return fmt.Errorf("自动创建已处理消息表需要开启 system.useMysql")
+	}
+	db, err := processedMessageDB(app.BaseConfig.ProcessedMessage.DbAliasName)
+	if err != nil {
+		return err
+	}
+	if err := mq.AutoMigrateProcessed(db); err != nil {
+		return fmt.Errorf("创建已处理消息表失败: %w", err)
+	}
+	logger.Info("[消息队列] 已处理消息表 %s 已就绪", mq.ProcessedTableName)
+	return nil
+}
+
+// Close 无需释放资源
+func (s *ProcessedMessageService) Close(ctx context.Context) error {
+	return nil
+}
+
+// ProcessedMessageSweepSchedule 创建清理过期的已处理消息记录的定时任务
+// 每次执行时删除处理时间早于 retentionDays 天之前的记录，数据库在执行时获取，因此可在数据库初始化前注册
+func ProcessedMessageSweepSchedule(cfg config.ProcessedMessageConfig) config.ScheduleInfo {
+	return config.ScheduleInfo{
+		Name: "processedMessageSweeper",
+		Cron: cfg.GetSweepCron(),
+		Cmd: func() {
+			db, err := processedMessageDB(cfg.DbAliasName)
+			if err != nil {
+				logger.Error("[消息队列] 清理已处理消息记录失败, error: %v", err)
+				return
+			}
+			deleted, err := mq.SweepProcessed(context.Background(), db, cfg.GetRetention())
+			if err != nil {
+				logger.Error("[消息队列] 清理已处理消息记录失败, error: %v", err)
+				return
+			}
+			logger.Info("[消息队列] 已清理 %d 天前的已处理消息记录 %d 条", cfg.RetentionDays, deleted)
+		},
+	}
+}
+
+// processedMessageDB 获取已处理消息表所在的数据库，别名为空时使用主数据库
+func processedMessageDB(aliasName string) (*gorm.DB, error) {
+	if aliasName != "" {
+		return app.GetDbByName(aliasName)
+	}
+	if app.DB == nil {
+		return nil, fmt.Errorf("主数据库未初始化，请配置 db 或通过 processedMessage.dbAliasName 指定数据库")
+	}
+	return app.DB, nil
+}
@@ -0,0 +1,106 @@
+// Package services 已处理消息表服务测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含已处理消息表服务和清理定时任务的单元测试，使用 SQLite 内存数据库，不需要 MySQL / RabbitMQ。
+//
+// 测试覆盖内容：
+// 1. Name/Priority/Dependencies - 服务元数据方法
+// 2. ShouldInit - 初始化条件判断
+// 3. Init - 未开启 MySQL 时返回错误，开启时自动创建 processed_messages 表
+// 4. ProcessedMessageSweepSchedule - 定时任务只删除超过保留天数的记录
+//
+// 运行测试：go test -v ./core/services/... -run ProcessedMessage
+// ==================================================
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/exception/mq"
+	"github.com/zzsen/gin_core/model/config"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// useProcessedMessageDB 使用 SQLite 内存数据库作为 app.DB，测试结束后恢复 app.BaseConfig 和 app.DB
+func useProcessedMessageDB(t *testing.T, name string) *gorm.DB {
+	originalConfig, originalDB := app.BaseConfig, app.DB
+	t.Cleanup(func() { app.BaseConfig, app.DB = originalConfig, originalDB })
+
+	db, err := gorm.Open(sqlite.Open("file:"+name+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: gormLogger.Default.LogMode(gormLogger.Silent),
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	app.DB = db
+	return db
+}
+
+// TestProcessedMessageService_Metadata 测试服务元数据
+//
+// 【功能点】验证服务名称、优先级、依赖以及 ShouldInit 判断
+// 【测试流程】检查 Name/Priority/Dependencies 返回值，分别在 processedMessage.autoMigrate 开关下调用 ShouldInit
+func TestProcessedMessageService_Metadata(t *testing.T) {
+	s := &ProcessedMessageService{}
+	assert.Equal(t, "processedMessage", s.Name())
+	assert.Equal(t, 40, s.Priority())
+	assert.Equal(t, []string{"logger", "mysql"}, s.Dependencies())
+
+	assert.False(t, s.ShouldInit(&config.BaseConfig{}))
+	assert.True(t, s.ShouldInit(&config.BaseConfig{ProcessedMessage: config.ProcessedMessageConfig{AutoMigrate: true}}))
+}
+
+// TestProcessedMessageService_Init 测试初始化
+//
+// 【功能点】验证未开启 system.useMysql 时 Init 返回错误，开启时自动创建已处理消息表
+// 【测试流程】
+//  1. 未开启 MySQL 调用 Init，断言返回错误
+//  2. 开启 MySQL 并使用 SQLite 内存数据库作为 app.DB，调用 Init，断言表已创建
+//  3. 调用 Close，断言无错误
+func TestProcessedMessageService_Init(t *testing.T) {
+	db := useProcessedMessageDB(t, "processed_message_service")
+	s := &ProcessedMessageService{}
+
+	app.BaseConfig = config.BaseConfig{ProcessedMessage: config.ProcessedMessageConfig{AutoMigrate: true}}
+	assert.Error(t, s.Init(context.Background()))
+
+	app.BaseConfig.System.UseMysql = true
+	require.NoError(t, s.Init(context.Background()))
+	assert.True(t, db.Migrator().HasTable(mq.ProcessedTableName))
+	assert.NoError(t, s.Close(context.Background()))
+}
+
+// TestProcessedMessageSweepSchedule 测试清理定时任务
+//
+// 【功能点】验证定时任务的名称、默认 cron 表达式，执行时只删除超过保留天数的记录
+// 【测试流程】
+//  1. 创建保留 7 天的定时任务，断言名称和默认 cron 表达式
+//  2. 写入 10 天前和 1 天前的记录，执行定时任务，断言只剩 1 天前的记录
+func TestProcessedMessageSweepSchedule(t *testing.T) {
+	db := useProcessedMessageDB(t, "processed_message_sweep")
+	require.NoError(t, mq.AutoMigrateProcessed(db))
+
+	schedule := ProcessedMessageSweepSchedule(config.ProcessedMessageConfig{RetentionDays: 7})
+	assert.Equal(t, "processedMessageSweeper", schedule.Name)
+	assert.Equal(t, "30 3 * * *", schedule.Cron)
+
+	now := time.Now()
+	require.NoError(t, db.Create([]mq.ProcessedMessage{
+		{MessageID: "old", ProcessedAt: now.Add(-10 * 24 * time.Hour)},
+		{MessageID: "recent", ProcessedAt: now.Add(-24 * time.Hour)},
+	}).Error)
+
+	schedule.Cmd()
+
+	var remaining []string
+	require.NoError(t, db.Model(&mq.ProcessedMessage{}).Pluck("message_id", &remaining).Error)
+	assert.Equal(t, []string{"recent"}, remaining)
+}
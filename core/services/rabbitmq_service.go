@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/zzsen/gin_core/app"
 	"github.com/zzsen/gin_core/initialize"
@@ -13,6 +15,8 @@ import (
 type RabbitMQService struct {
 	consumerList []*config.MessageQueue
 	producerList []*config.MessageQueue
+	// stopSweeper 停止空闲生产者清理协程
+	stopSweeper context.CancelFunc
 }
 
 // NewRabbitMQService 创建RabbitMQ服务
@@ -38,10 +42,19 @@ func (s *RabbitMQService) ShouldInit(cfg *config.BaseConfig) bool {
 }
 
 // Init 初始化RabbitMQ
+// 启用 system.startupRetry 时生产者连接失败会重试，超过最长等待时间后按 optional 配置降级启动或启动失败；
+// 未启用时生产者连接失败只记录日志
 func (s *RabbitMQService) Init(ctx context.Context) error {
+	// 创建发送失败消息记录器（rabbitMQ.failedMessageStore），失败时只记录日志，发送失败的消息不会被保存
+	if err := app.InitFailedMessageRecorders(); err != nil {
+		logger.Error("%v", err)
+	}
+
 	// 初始化消息队列生产者
 	if len(s.producerList) > 0 {
-		initialize.InitialRabbitMqProducer(s.producerList...)
+		if err := s.initProducers(ctx); err != nil {
+			return err
+		}
 	}
 
 	// 在协程中启动消息队列消费者，避免阻塞服务启动
@@ -49,18 +62,64 @@ func (s *RabbitMQService) Init(ctx context.Context) error {
 		go initialize.InitialRabbitMq(s.consumerList...)
 	}
 
+	// 启动空闲生产者清理协程，服务关闭时停止
+	if idleTimeout := app.BaseConfig.RabbitMQ.GetProducerIdleTimeout(); idleTimeout > 0 {
+		sweepCtx, cancel := context.WithCancel(context.Background())
+		s.stopSweeper = cancel
+		app.StartProducerSweeper(sweepCtx, app.BaseConfig.RabbitMQ.GetProducerSweepInterval(), idleTimeout)
+	}
+
+	return nil
+}
+
+// initProducers 初始化消息队列生产者
+func (s *RabbitMQService) initProducers(ctx context.Context) error {
+	if !app.BaseConfig.System.StartupRetry.Enabled {
+		initialize.InitialRabbitMqProducer(s.producerList...)
+		return nil
+	}
+	err := waitForDependency(ctx, s.Name(), func() error {
+		return initialize.ConnectRabbitMqProducer(s.producerList...)
+	}, nil)
+	if err != nil {
+		return degradeOrFail(ctx, s.Name(), err)
+	}
 	return nil
 }
 
 // Close 关闭RabbitMQ连接
+// 先停止空闲生产者清理协程，生产者再并发执行 Drain，在关闭超时时间内等待未确认的消息，超时后强制关闭并记录未确认的消息数；
+// 之后关闭发送失败消息记录器，等待写入队列中的消息写入存储
 func (s *RabbitMQService) Close(ctx context.Context) error {
+	if s.stopSweeper != nil {
+		s.stopSweeper()
+	}
+	timeout := time.Duration(app.BaseConfig.Service.GetShutdownTimeout()) * time.Second
+	drainCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
 	// 遍历 sync.Map 中的所有生产者并关闭
 	app.RabbitMQProducerList.Range(func(key, value any) bool {
 		producer := value.(*config.MessageQueue)
-		producer.Close()
-		logger.Info("[RabbitMQ] 已关闭生产者: %s", producer.GetInfo())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := producer.Drain(drainCtx); err != nil {
+				logger.Warn("[RabbitMQ] 生产者关闭时仍有未确认的消息: %s, 未确认消息数: %d, error: %v",
+					producer.GetInfo(), producer.UnconfirmedAtClose(), err)
+				return
+			}
+			logger.Info("[RabbitMQ] 已关闭生产者: %s", producer.GetInfo())
+		}()
 		return true // 继续遍历
 	})
+	wg.Wait()
+
+	// 生产者关闭后，等待发送失败的消息写入存储
+	if err := app.CloseFailedMessageRecorders(drainCtx); err != nil {
+		logger.Warn("[RabbitMQ] 发送失败消息写入未完成: %v", err)
+	}
 	return nil
 }
 
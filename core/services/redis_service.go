@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/zzsen/gin_core/app"
@@ -28,29 +29,65 @@ func (s *RedisService) ShouldInit(cfg *config.BaseConfig) bool {
 }
 
 // Init 初始化Redis
+// 启用 system.startupRetry 时连接失败会重试，超过最长等待时间后按 optional 配置降级启动或启动失败
 func (s *RedisService) Init(ctx context.Context) error {
 	// 验证配置
 	if app.BaseConfig.Redis == nil && len(app.BaseConfig.RedisList) == 0 {
 		return fmt.Errorf("未找到有效的Redis配置")
 	}
 
-	// 初始化主Redis连接
-	initialize.InitRedis()
-	// 初始化多Redis实例连接列表
-	initialize.InitRedisList()
+	err := waitForDependency(ctx, s.Name(), func() error {
+		// 初始化主Redis连接
+		initialize.InitRedisWithContext(ctx)
+		// 初始化多Redis实例连接列表
+		initialize.InitRedisListWithContext(ctx)
+		return nil
+	}, resetRedis)
+	if err != nil {
+		return degradeOrFail(ctx, s.Name(), err)
+	}
 	return nil
 }
 
+// resetRedis 关闭连接失败前已创建的Redis客户端，避免重试时泄漏
+func resetRedis() {
+	if app.Redis != nil {
+		_ = app.Redis.Close()
+	}
+	for _, client := range app.RedisList {
+		if client != nil {
+			_ = client.Close()
+		}
+	}
+	app.Redis, app.RedisList = nil, nil
+}
+
 // Close 关闭Redis连接
+// 依次关闭主实例和多实例列表中的所有客户端，单实例、哨兵和集群模式的客户端均通过 Close 释放连接池
 func (s *RedisService) Close(ctx context.Context) error {
+	// 先关闭订阅，避免连接关闭后订阅不断重连
+	app.CloseRedisSubscriptions()
+	var errs []error
 	if app.Redis != nil {
 		if err := app.Redis.Close(); err != nil {
 			logger.Error("[Redis] 关闭连接失败: %v", err)
-			return err
+			errs = append(errs, err)
+		} else {
+			logger.Info("[Redis] 连接已关闭")
 		}
-		logger.Info("[Redis] 连接已关闭")
 	}
-	return nil
+	for name, client := range app.RedisList {
+		if client == nil {
+			continue
+		}
+		if err := client.Close(); err != nil {
+			logger.Error("[Redis] 关闭连接失败, aliasName: %s, error: %v", name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		logger.Info("[Redis] 连接已关闭, aliasName: %s", name)
+	}
+	return errors.Join(errs...)
 }
 
 // HealthCheck 健康检查
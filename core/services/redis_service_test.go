@@ -0,0 +1,51 @@
+// Package services Redis 服务测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 Redis 服务健康检查和关闭的单元测试，使用 miniredis，不需要真实 Redis。
+//
+// 测试覆盖内容：
+// 1. HealthCheck - 未初始化时返回错误，连接正常时通过
+// 2. Close - 关闭主实例和多实例列表中的所有客户端
+//
+// 运行测试：go test -v ./core/services/... -run RedisService
+// ==================================================
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+)
+
+// TestRedisService_HealthCheckAndClose 测试 Redis 服务的健康检查和关闭
+//
+// 【功能点】验证健康检查通过 Ping 判断连接状态，关闭时主实例和多实例列表中的客户端均被关闭
+// 【测试流程】
+//  1. app.Redis 为 nil 时断言健康检查返回错误
+//  2. 设置连接 miniredis 的主实例和多实例列表，断言健康检查通过
+//  3. 关闭服务，断言所有客户端再次执行命令时返回 redis.ErrClosed
+func TestRedisService_HealthCheckAndClose(t *testing.T) {
+	originalRedis, originalList := app.Redis, app.RedisList
+	t.Cleanup(func() { app.Redis, app.RedisList = originalRedis, originalList })
+
+	s := &RedisService{}
+	ctx := context.Background()
+	app.Redis, app.RedisList = nil, nil
+	assert.ErrorContains(t, s.HealthCheck(ctx), "redis未初始化")
+
+	mr := miniredis.RunT(t)
+	main := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	cache := redis.NewClient(&redis.Options{Addr: mr.Addr(), DB: 1})
+	app.Redis = main
+	app.RedisList = map[string]redis.UniversalClient{"cache": cache}
+	require.NoError(t, s.HealthCheck(ctx))
+
+	require.NoError(t, s.Close(ctx))
+	assert.ErrorIs(t, main.Ping(ctx).Err(), redis.ErrClosed)
+	assert.ErrorIs(t, cache.Ping(ctx).Err(), redis.ErrClosed)
+}
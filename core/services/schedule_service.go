@@ -2,8 +2,13 @@ package services
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/robfig/cron/v3"
+	"github.com/zzsen/gin_core/distlock"
 	"github.com/zzsen/gin_core/logger"
 	"github.com/zzsen/gin_core/model/config"
 )
@@ -27,8 +32,15 @@ func (s *ScheduleService) Name() string { return "schedule" }
 // Priority 返回初始化优先级（最后初始化）
 func (s *ScheduleService) Priority() int { return 100 }
 
-// Dependencies 返回依赖
-func (s *ScheduleService) Dependencies() []string { return []string{"logger"} }
+// Dependencies 返回依赖，存在 Singleton 任务时依赖 Redis
+func (s *ScheduleService) Dependencies() []string {
+	for _, schedule := range s.scheduleList {
+		if schedule.Singleton {
+			return []string{"logger", "redis"}
+		}
+	}
+	return []string{"logger"}
+}
 
 // ShouldInit 根据配置判断是否需要初始化
 func (s *ScheduleService) ShouldInit(cfg *config.BaseConfig) bool {
@@ -36,28 +48,55 @@ func (s *ScheduleService) ShouldInit(cfg *config.BaseConfig) bool {
 }
 
 // Init 初始化定时任务
+// cron 表达式或时区无效的任务记录错误日志后跳过，不影响其他任务；注册成功的任务输出接下来 3 次执行时间
 func (s *ScheduleService) Init(ctx context.Context) error {
 	// 创建新的Cron调度器实例
 	s.cron = cron.New()
 
 	// 注册所有定时任务到调度器
+	registered := 0
 	for _, schedule := range s.scheduleList {
-		if schedule.ShouldRunImmediately {
-			schedule.Cmd()
-		}
-		_, err := s.cron.AddFunc(schedule.Cron, schedule.Cmd)
+		sched, err := schedule.ParseCron()
 		if err != nil {
-			logger.Error("[定时任务] 添加任务失败, cron: %s, error: %v", schedule.Cron, err)
-			return err
+			logger.Error("[定时任务] 添加任务失败, error: %v", err)
+			continue
+		}
+		cmd := schedule.Cmd
+		if schedule.Singleton {
+			cmd = singletonCmd(schedule)
 		}
+		if schedule.ShouldRunImmediately {
+			cmd()
+		}
+		s.cron.Schedule(sched, cron.FuncJob(cmd))
+		registered++
+		logger.Info("[定时任务] 添加任务成功, 名称: %s, cron: %s, 接下来的执行时间: %s",
+			schedule.GetName(), schedule.Cron, strings.Join(nextRuns(sched, time.Now(), 3), ", "))
 	}
 
 	// 启动调度器
 	s.cron.Start()
-	logger.Info("[定时任务] 调度器已启动，共注册 %d 个任务", len(s.scheduleList))
+	logger.Info("[定时任务] 调度器已启动，共注册 %d 个任务，%d 个任务添加失败", registered, len(s.scheduleList)-registered)
 	return nil
 }
 
+// nextRuns 计算从 from 开始的接下来 n 次执行时间，按调度规则的时区格式化
+func nextRuns(sched cron.Schedule, from time.Time, n int) []string {
+	loc := from.Location()
+	if spec, ok := sched.(*cron.SpecSchedule); ok && spec.Location != nil {
+		loc = spec.Location
+	}
+	runs := make([]string, 0, n)
+	for next := from; len(runs) < n; {
+		next = sched.Next(next)
+		if next.IsZero() {
+			break
+		}
+		runs = append(runs, next.In(loc).Format("2006-01-02 15:04:05 -07:00"))
+	}
+	return runs
+}
+
 // Close 关闭定时任务
 func (s *ScheduleService) Close(ctx context.Context) error {
 	if s.cron != nil {
@@ -67,6 +106,37 @@ func (s *ScheduleService) Close(ctx context.Context) error {
 	return nil
 }
 
+// singletonTickTTL Singleton 任务每次触发的占用记录的保留时间，各实例的时钟偏差应小于该值
+const singletonTickTTL = time.Minute
+
+// scheduleNow 获取当前时间，单元测试中可替换
+var scheduleNow = time.Now
+
+// singletonCmd 包装 Singleton 任务：获取分布式锁并占用本次触发后执行，未获取到锁或本次触发已被占用时跳过本次执行
+// 执行锁（lockKey）在任务执行结束后释放，执行期间由看门狗续期，避免多个实例同时执行；
+// 触发占用记录（lockKey:触发时间的 Unix 秒）不主动释放，保留 singletonTickTTL 后过期，
+// 避免任务很快执行完成后，时钟稍慢的实例在同一次触发中再次获取到锁并执行
+func singletonCmd(schedule config.ScheduleInfo) func() {
+	key := schedule.GetLockKey()
+	return func() {
+		// cron 按整秒触发，截断到秒即为本次触发的计划时间
+		tickKey := key + ":" + strconv.FormatInt(scheduleNow().Truncate(time.Second).Unix(), 10)
+		err := distlock.WithLock(context.Background(), key, 0, func(ctx context.Context) error {
+			if _, err := distlock.Acquire(ctx, tickKey, singletonTickTTL, distlock.WithWatchdog(false)); err != nil {
+				return err
+			}
+			schedule.Cmd()
+			return nil
+		})
+		switch {
+		case errors.Is(err, distlock.ErrNotAcquired):
+			logger.Info("[定时任务] 其他实例正在执行或已执行本次触发，跳过本次执行, lockKey: %s", tickKey)
+		case err != nil:
+			logger.Error("[定时任务] 执行 Singleton 任务失败, lockKey: %s, error: %v", key, err)
+		}
+	}
+}
+
 // SetScheduleList 设置定时任务列表
 func (s *ScheduleService) SetScheduleList(list []config.ScheduleInfo) {
 	s.scheduleList = list
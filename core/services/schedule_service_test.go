@@ -0,0 +1,196 @@
+// Package services 定时任务服务测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含定时任务服务的单元测试，使用 miniredis，不需要真实 Redis。
+//
+// 测试覆盖内容：
+// 1. Dependencies - 存在 Singleton 任务时依赖 Redis
+// 2. GetLockKey - 锁键名的默认值
+// 3. Singleton 任务 - 其他实例持有锁时跳过，锁释放后正常执行；同一次触发在其他实例执行完成后不会再次执行
+// 4. ParseCron - 按时区计算下一次执行时间，6 段表达式按秒级解析，无效表达式和时区返回包含任务名称的错误
+// 5. Init - 无效的任务被跳过，不影响其他任务；秒级任务按秒触发
+//
+// 运行测试：go test -v ./core/services/... -run Schedule
+// ==================================================
+package services
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/distlock"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// TestScheduleService_Dependencies 测试服务依赖
+//
+// 【功能点】验证只有存在 Singleton 任务时才依赖 Redis
+// 【测试流程】分别使用普通任务和包含 Singleton 任务的列表创建服务，检查 Dependencies 返回值
+func TestScheduleService_Dependencies(t *testing.T) {
+	s := NewScheduleService([]config.ScheduleInfo{{Cron: "@every 1m", Cmd: func() {}}})
+	assert.Equal(t, "schedule", s.Name())
+	assert.Equal(t, []string{"logger"}, s.Dependencies())
+
+	s.SetScheduleList([]config.ScheduleInfo{
+		{Cron: "@every 1m", Cmd: func() {}},
+		{Name: "report", Cron: "@every 1m", Cmd: func() {}, Singleton: true},
+	})
+	assert.Equal(t, []string{"logger", "redis"}, s.Dependencies())
+}
+
+// TestScheduleInfo_GetLockKey 测试锁键名
+//
+// 【功能点】验证 LockKey 优先，未配置时使用 "schedule:" + Name
+// 【测试流程】分别配置 LockKey 和 Name，检查 GetLockKey 返回值
+func TestScheduleInfo_GetLockKey(t *testing.T) {
+	assert.Equal(t, "custom", (&config.ScheduleInfo{Name: "report", LockKey: "custom"}).GetLockKey())
+	assert.Equal(t, "schedule:report", (&config.ScheduleInfo{Name: "report"}).GetLockKey())
+}
+
+// TestScheduleService_Singleton 测试 Singleton 任务只在获取到锁的实例上执行
+//
+// 【功能点】验证其他实例持有锁时跳过本次执行，锁释放后正常执行，执行结束后释放锁
+// 【测试流程】
+//  1. 使用 miniredis 作为 app.Redis，模拟其他实例获取任务的锁
+//  2. 执行包装后的任务，断言任务未执行
+//  3. 释放锁后再次执行，断言任务执行且锁已释放
+func TestScheduleService_Singleton(t *testing.T) {
+	originalRedis := app.Redis
+	defer func() { app.Redis = originalRedis }()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	app.Redis = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	runs := 0
+	schedule := config.ScheduleInfo{Name: "report", Cron: "@every 1m", Cmd: func() { runs++ }, Singleton: true}
+	cmd := singletonCmd(schedule)
+
+	other, err := distlock.Acquire(context.Background(), schedule.GetLockKey(), time.Minute, distlock.WithWatchdog(false))
+	require.NoError(t, err)
+	cmd()
+	assert.Equal(t, 0, runs, "其他实例持有锁时应跳过")
+
+	require.NoError(t, other.Unlock(context.Background()))
+	cmd()
+	assert.Equal(t, 1, runs, "锁释放后应执行")
+	assert.False(t, mr.Exists(other.Key()), "执行结束后应释放锁")
+}
+
+// TestScheduleService_SingletonSameTick 测试同一次触发只执行一次
+//
+// 【功能点】验证一个实例执行完成并释放执行锁后，另一个实例在同一次触发中（时钟稍慢、稍后触发）不会再次执行
+// 【测试流程】
+//  1. 使用 miniredis 作为 app.Redis，创建两个实例的包装任务，固定当前时间
+//  2. 实例 A 执行完成后，实例 B 在同一秒的稍后时间执行，断言任务只执行 1 次
+//  3. 时间推进到下一次触发，实例 B 执行，断言任务执行 2 次
+func TestScheduleService_SingletonSameTick(t *testing.T) {
+	originalRedis, originalNow := app.Redis, scheduleNow
+	defer func() { app.Redis, scheduleNow = originalRedis, originalNow }()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	app.Redis = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	var runs atomic.Int32
+	schedule := config.ScheduleInfo{Name: "report", Cron: "0 2 * * *", Cmd: func() { runs.Add(1) }, Singleton: true}
+	instanceA, instanceB := singletonCmd(schedule), singletonCmd(schedule)
+	tick := time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC)
+
+	scheduleNow = func() time.Time { return tick.Add(3 * time.Millisecond) }
+	instanceA()
+	scheduleNow = func() time.Time { return tick.Add(40 * time.Millisecond) }
+	instanceB()
+	assert.Equal(t, int32(1), runs.Load(), "同一次触发只应执行一次")
+	assert.False(t, mr.Exists(schedule.GetLockKey()), "执行结束后应释放执行锁")
+
+	scheduleNow = func() time.Time { return tick.Add(24 * time.Hour).Add(5 * time.Millisecond) }
+	instanceB()
+	assert.Equal(t, int32(2), runs.Load(), "下一次触发应执行")
+}
+
+// TestScheduleInfo_ParseCron 测试 cron 表达式解析
+//
+// 【功能点】验证按 Timezone 计算执行时间，6 段表达式自动按秒级解析，无效表达式和时区返回包含任务名称的错误
+// 【测试流程】
+//  1. "0 0 9 * * *" + Asia/Shanghai，断言 UTC 2026-01-01 00:00 之后的下一次执行时间为 UTC 01:00（北京时间 9 点）
+//  2. 5 段表达式 "0 9 * * *" + Asia/Shanghai 结果相同；WithSeconds 时 "*/10 * * * * *" 按秒级解析
+//  3. 断言无效表达式、段数不符（WithSeconds 的 5 段表达式）和无效时区返回的错误包含任务名称
+func TestScheduleInfo_ParseCron(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	for _, expr := range []string{"0 0 9 * * *", "0 9 * * *"} {
+		schedule := config.ScheduleInfo{Name: "dailyReport", Cron: expr, Timezone: "Asia/Shanghai"}
+		sched, err := schedule.ParseCron()
+		require.NoError(t, err, expr)
+		assert.True(t, sched.Next(from).Equal(want), "%s: %v", expr, sched.Next(from))
+	}
+
+	sched, err := (&config.ScheduleInfo{Cron: "*/10 * * * * *", WithSeconds: true}).ParseCron()
+	require.NoError(t, err)
+	assert.Equal(t, from.Add(10*time.Second), sched.Next(from).UTC())
+
+	for _, schedule := range []config.ScheduleInfo{
+		{Name: "badExpr", Cron: "61 * * * *"},
+		{Name: "badExpr", Cron: "0 9 * * *", WithSeconds: true},
+		{Name: "badExpr", Cron: ""},
+		{Name: "badExpr", Cron: "0 9 * * *", Timezone: "Mars/Olympus"},
+	} {
+		_, err := schedule.ParseCron()
+		assert.ErrorContains(t, err, "定时任务 badExpr", "%+v", schedule)
+	}
+}
+
+// TestScheduleService_Init 测试定时任务注册
+//
+// 【功能点】验证无效的任务被跳过且不影响其他任务，注册的调度规则使用配置的时区，秒级任务按秒触发
+// 【测试流程】
+//  1. 注册无效表达式的任务、Asia/Shanghai 的每天 9 点任务和每秒执行的秒级任务
+//  2. 断言 Init 不返回错误，调度器中只有 2 个任务，每天 9 点任务的 Next 为北京时间 9 点
+//  3. 等待秒级任务在 3 秒内至少触发一次
+func TestScheduleService_Init(t *testing.T) {
+	var fired atomic.Int32
+	s := NewScheduleService([]config.ScheduleInfo{
+		{Name: "invalid", Cron: "not a cron", Cmd: func() {}},
+		{Name: "dailyReport", Cron: "0 0 9 * * *", Timezone: "Asia/Shanghai", Cmd: func() {}},
+		{Name: "tick", Cron: "* * * * * *", Cmd: func() { fired.Add(1) }},
+	})
+	require.NoError(t, s.Init(context.Background()))
+	defer s.Close(context.Background())
+
+	entries := s.cron.Entries()
+	require.Len(t, entries, 2)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var nexts []time.Time
+	for _, entry := range entries {
+		nexts = append(nexts, entry.Schedule.Next(from).UTC())
+	}
+	assert.Contains(t, nexts, time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC))
+
+	assert.Eventually(t, func() bool { return fired.Load() > 0 }, 3*time.Second, 50*time.Millisecond)
+}
+
+// TestNextRuns 测试接下来的执行时间
+//
+// 【功能点】验证按调度规则计算接下来 n 次执行时间，并按调度规则的时区格式化
+// 【测试流程】计算 Asia/Shanghai 每天 9 点任务接下来 3 次执行时间，断言结果
+func TestNextRuns(t *testing.T) {
+	sched, err := (&config.ScheduleInfo{Cron: "0 9 * * *", Timezone: "Asia/Shanghai"}).ParseCron()
+	require.NoError(t, err)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{
+		"2026-01-01 09:00:00 +08:00",
+		"2026-01-02 09:00:00 +08:00",
+		"2026-01-03 09:00:00 +08:00",
+	}, nextRuns(sched, from, 3))
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+)
+
+// waitForDependency 按 system.startupRetry 连接依赖服务，失败时重试直到成功或超过最长等待时间
+// 未启用启动重试时只调用一次 connect，connect 中的 panic 照常向上传递；
+// 启用时 connect 中的 panic（如 exception.InitError）被转换为错误后重试，每次失败后调用 reset 释放本次创建的连接
+//
+// 参数：
+//   - ctx: 上下文，取消时立即停止等待（如启动过程中按下 Ctrl-C）
+//   - name: 服务名称，用于日志
+//   - connect: 连接函数，可以 panic
+//   - reset: 连接失败后的清理函数，可以为 nil
+//
+// 返回：
+//   - error: 超过最长等待时间时返回最后一次连接错误，上下文取消时返回包含 ctx.Err() 的错误
+func waitForDependency(ctx context.Context, name string, connect func() error, reset func()) error {
+	retry := app.BaseConfig.System.StartupRetry
+	if !retry.Enabled {
+		return connect()
+	}
+
+	deadline := time.Now().Add(retry.GetMaxWait())
+	maxAttempts := retry.MaxAttempts()
+	interval := min(retry.GetInterval(), retry.GetMaxInterval())
+	for attempt := 1; ; attempt++ {
+		err := tryConnect(connect)
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("[启动重试] %s 已就绪 (第 %d 次尝试)", name, attempt)
+			}
+			return nil
+		}
+		if reset != nil {
+			reset()
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("等待 %s 就绪超时（%s，已尝试 %d 次）: %w", name, retry.GetMaxWait(), attempt, err)
+		}
+		logger.Warn("[启动重试] 等待 %s 就绪 (第 %d/%d 次): %v", name, attempt, max(maxAttempts, attempt), err)
+
+		timer := time.NewTimer(min(interval, remaining))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("等待 %s 就绪已取消: %w", name, ctx.Err())
+		case <-timer.C:
+		}
+		interval = retry.NextInterval(interval)
+	}
+}
+
+// tryConnect 调用连接函数，将其中的 panic 转换为错误
+func tryConnect(connect func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+				return
+			}
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return connect()
+}
+
+// degradeOrFail 处理等待依赖服务失败
+// 启用启动重试且服务在 system.startupRetry.optional 中时，服务降级启动：记录错误日志并通过 app.MarkUnavailable 标记为不可用，返回 nil；
+// 其他情况（包括上下文已取消）返回原错误，启动失败
+func degradeOrFail(ctx context.Context, name string, err error) error {
+	retry := app.BaseConfig.System.StartupRetry
+	if ctx.Err() != nil || !retry.Enabled || !retry.IsOptional(name) {
+		return err
+	}
+	logger.Error("[启动重试] %s 不可用，降级启动: %v", name, err)
+	app.MarkUnavailable(name, err)
+	return nil
+}
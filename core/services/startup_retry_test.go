@@ -0,0 +1,168 @@
+// Package services 启动重试测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含启动时等待依赖服务就绪的单元测试，使用延迟启动的 miniredis 和 httptest 模拟依赖服务，不需要真实的 Redis 和 Elasticsearch。
+//
+// 测试覆盖内容：
+// 1. Redis 延迟启动时重试直到连接成功
+// 2. Elasticsearch 延迟启动时重试直到所有集群连接成功
+// 3. 超过最长等待时间后，optional 中的服务降级启动并在就绪检查中报告为不可用，其他服务启动失败
+// 4. 上下文取消时立即停止等待
+//
+// 运行测试：go test -v ./core/services/... -run StartupRetry
+// ==================================================
+package services
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// setupStartupRetryTest 启用启动重试并在测试结束后恢复系统配置和 Redis 配置
+func setupStartupRetryTest(t *testing.T, retry config.StartupRetryConfig) {
+	originalSystem, originalRedis := app.BaseConfig.System, app.BaseConfig.Redis
+	originalClient, originalList := app.Redis, app.RedisList
+	t.Cleanup(func() {
+		resetRedis()
+		app.BaseConfig.System, app.BaseConfig.Redis = originalSystem, originalRedis
+		app.Redis, app.RedisList = originalClient, originalList
+	})
+	retry.Enabled = true
+	app.BaseConfig.System.StartupRetry = retry
+}
+
+// unusedAddr 获取一个当前没有监听的本地地址
+func unusedAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return addr
+}
+
+// TestRedisService_StartupRetry 测试 Redis 延迟启动时重试连接
+//
+// 【功能点】验证 Redis 启动晚于应用时，初始化按间隔重试，Redis 开始监听后连接成功
+// 【测试流程】
+//  1. 配置一个尚未监听的地址，300ms 后在该地址启动 miniredis
+//  2. 初始化 Redis 服务，断言成功且耗时不少于 300ms
+//  3. 断言 app.Redis 可以正常执行命令
+func TestRedisService_StartupRetry(t *testing.T) {
+	setupStartupRetryTest(t, config.StartupRetryConfig{MaxWait: 5 * time.Second, Interval: 50 * time.Millisecond})
+	addr := unusedAddr(t)
+	app.BaseConfig.Redis = &config.RedisInfo{Addr: addr}
+
+	mr := miniredis.NewMiniRedis()
+	t.Cleanup(mr.Close)
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		_ = mr.StartAddr(addr)
+	}()
+
+	start := time.Now()
+	require.NoError(t, (&RedisService{}).Init(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	assert.NoError(t, app.Redis.Set(context.Background(), "k", "v", 0).Err())
+	assert.NotContains(t, app.UnavailableServices(), "redis")
+}
+
+// TestElasticsearchService_StartupRetry 测试 Elasticsearch 延迟启动时重试连接
+//
+// 【功能点】验证启用启动重试时，集群开始监听后所有集群连接成功才完成初始化
+// 【测试流程】
+//  1. 配置一个尚未监听的地址，300ms 后在该地址启动模拟集群
+//  2. 初始化 Elasticsearch 服务，断言成功且耗时不少于 300ms
+//  3. 断言健康检查通过
+func TestElasticsearchService_StartupRetry(t *testing.T) {
+	setupStartupRetryTest(t, config.StartupRetryConfig{MaxWait: 5 * time.Second, Interval: 50 * time.Millisecond})
+	originalEs := app.BaseConfig.Es
+	t.Cleanup(func() { app.BaseConfig.Es = originalEs })
+	addr := unusedAddr(t)
+	app.BaseConfig.Es = &config.EsInfo{AliasName: "startup-retry", Addresses: []string{"http://" + addr}}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"node-1","cluster_name":"test","cluster_uuid":"uuid",` +
+			`"version":{"number":"9.0.0","build_flavor":"default","build_type":"docker","build_hash":"hash",` +
+			`"build_date":"2025-01-01T00:00:00Z","build_snapshot":false,"lucene_version":"10.0.0",` +
+			`"minimum_wire_compatibility_version":"8.0.0","minimum_index_compatibility_version":"8.0.0"},` +
+			`"tagline":"You Know, for Search"}`))
+	}))
+	t.Cleanup(server.Close)
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return
+		}
+		server.Listener = l
+		server.Start()
+	}()
+
+	s := &ElasticsearchService{}
+	start := time.Now()
+	require.NoError(t, s.Init(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	assert.NoError(t, s.HealthCheck(context.Background()))
+}
+
+// TestRedisService_StartupRetryTimeout 测试超过最长等待时间
+//
+// 【功能点】验证 Redis 始终不可用时，不在 optional 中启动失败，在 optional 中降级启动并在就绪检查中报告为不可用
+// 【测试流程】
+//  1. 配置一个没有监听的地址，最长等待 200ms，断言初始化返回超时错误且没有标记为不可用
+//  2. 将 redis 加入 optional，断言初始化成功、app.Redis 为 nil
+//  3. 断言 app.UnavailableServices 和 app.CheckPoolHealth 报告 redis 不可用
+func TestRedisService_StartupRetryTimeout(t *testing.T) {
+	setupStartupRetryTest(t, config.StartupRetryConfig{MaxWait: 200 * time.Millisecond, Interval: 50 * time.Millisecond})
+	t.Cleanup(func() { app.MarkAvailable("redis") })
+	app.BaseConfig.Redis = &config.RedisInfo{Addr: unusedAddr(t)}
+	s := &RedisService{}
+
+	err := s.Init(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "等待 redis 就绪超时")
+	assert.NotContains(t, app.UnavailableServices(), "redis")
+
+	app.BaseConfig.System.StartupRetry.Optional = []string{"redis"}
+	require.NoError(t, s.Init(context.Background()))
+	assert.Nil(t, app.Redis)
+	assert.Error(t, app.UnavailableServices()["redis"])
+	health := app.CheckPoolHealth()["redis"]
+	assert.False(t, health.Healthy)
+	assert.Contains(t, health.Error, "降级启动")
+}
+
+// TestRedisService_StartupRetryCanceled 测试等待期间取消
+//
+// 【功能点】验证上下文取消时立即停止等待，即使服务在 optional 中也启动失败
+// 【测试流程】
+//  1. 配置一个没有监听的地址，最长等待 1 分钟，redis 在 optional 中
+//  2. 100ms 后取消上下文，断言初始化在 1 秒内返回 context.Canceled，且没有标记为不可用
+func TestRedisService_StartupRetryCanceled(t *testing.T) {
+	setupStartupRetryTest(t, config.StartupRetryConfig{
+		MaxWait:  time.Minute,
+		Interval: 50 * time.Millisecond,
+		Optional: []string{"redis"},
+	})
+	app.BaseConfig.Redis = &config.RedisInfo{Addr: unusedAddr(t)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	err := (&RedisService{}).Init(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.NotContains(t, app.UnavailableServices(), "redis")
+}
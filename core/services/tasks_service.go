@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/tasks"
+)
+
+// TasksService 后台任务执行器服务
+// 启动时根据 tasks 配置创建默认执行器，关闭时停止接收任务并在关闭超时时间内执行完队列中的任务
+type TasksService struct {
+	runner *tasks.Runner
+}
+
+// Name 返回服务名称
+func (s *TasksService) Name() string { return "tasks" }
+
+// Priority 返回初始化优先级
+func (s *TasksService) Priority() int { return 50 }
+
+// Dependencies 返回依赖
+// 依赖任务中可能用到的组件，使关闭时先执行完队列中的任务再关闭这些组件
+func (s *TasksService) Dependencies() []string {
+	return []string{"logger", "redis", "mysql", "elasticsearch", "rabbitmq", "etcd"}
+}
+
+// ShouldInit 后台任务执行器始终初始化
+func (s *TasksService) ShouldInit(cfg *config.BaseConfig) bool {
+	return true
+}
+
+// Init 创建并启动默认执行器
+func (s *TasksService) Init(ctx context.Context) error {
+	cfg := app.BaseConfig.Tasks
+	s.runner = tasks.NewRunner(tasks.Options{
+		Workers:       cfg.GetWorkers(),
+		QueueSize:     cfg.GetQueueSize(),
+		Timeout:       time.Duration(cfg.GetTimeout()) * time.Second,
+		MaxRetries:    cfg.MaxRetries,
+		RetryInterval: time.Duration(cfg.GetRetryInterval()) * time.Millisecond,
+		BlockOnFull:   cfg.BlockOnFull,
+		BlockTimeout:  time.Duration(cfg.GetBlockTimeout()) * time.Millisecond,
+	})
+	tasks.SetDefault(s.runner)
+	logger.Info("[后台任务] 执行器已启动, 协程数: %d, 队列容量: %d", cfg.GetWorkers(), cfg.GetQueueSize())
+	return nil
+}
+
+// Close 停止接收任务，在 service.shutdownTimeout 内执行完队列中的任务
+func (s *TasksService) Close(ctx context.Context) error {
+	if s.runner == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(app.BaseConfig.Service.GetShutdownTimeout())*time.Second)
+	defer cancel()
+	abandoned, err := s.runner.Shutdown(ctx)
+	if err != nil {
+		logger.Warn("[后台任务] 关闭超时, 放弃 %d 个未执行的任务", abandoned)
+		return nil
+	}
+	logger.Info("[后台任务] 执行器已关闭")
+	return nil
+}
@@ -0,0 +1,319 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// StartStage 启动阶段
+// 启动阶段钩子在 Start 的对应时机按注册顺序执行，任一钩子失败（包括超时）时启动中止
+type StartStage int
+
+const (
+	AfterConfigLoaded StartStage = iota // 配置加载并校验之后、服务初始化之前（AppBeforeInit 钩子之后）
+	AfterServicesInit                   // 所有服务初始化完成之后（AppAfterInit 钩子之后），适用于依赖数据库的缓存预热
+	BeforeListen                        // 路由和 HTTP Server 创建完成、开始监听之前
+)
+
+// String 返回启动阶段的字符串表示
+func (s StartStage) String() string {
+	switch s {
+	case AfterConfigLoaded:
+		return "AfterConfigLoaded"
+	case AfterServicesInit:
+		return "AfterServicesInit"
+	case BeforeListen:
+		return "BeforeListen"
+	default:
+		return "unknown"
+	}
+}
+
+// StopStage 关闭阶段
+// 关闭阶段钩子在优雅关闭的对应时机按注册顺序执行，钩子失败（包括超时）只记录日志，关闭流程继续
+type StopStage int
+
+const (
+	BeforeShutdown      StopStage = iota // 收到关闭信号或调用 Shutdown 之后、HTTP Server 关闭之前（AppBeforeShutdown 钩子之后），适用于从服务发现中注销
+	AfterServerClosed                    // HTTP、gRPC 服务关闭（进行中的请求处理完成）之后、关闭服务组件之前
+	AfterServicesClosed                  // 所有服务组件关闭之后（AppAfterShutdown 钩子之前）
+)
+
+// String 返回关闭阶段的字符串表示
+func (s StopStage) String() string {
+	switch s {
+	case BeforeShutdown:
+		return "BeforeShutdown"
+	case AfterServerClosed:
+		return "AfterServerClosed"
+	case AfterServicesClosed:
+		return "AfterServicesClosed"
+	default:
+		return "unknown"
+	}
+}
+
+// DefaultStageHookTimeout 启动、关闭阶段钩子的默认超时时间
+const DefaultStageHookTimeout = 30 * time.Second
+
+// stageHook 启动、关闭阶段钩子
+type stageHook struct {
+	name    string                          // 钩子名称，用于日志和错误信息
+	timeout time.Duration                   // 超时时间
+	fn      func(ctx context.Context) error // 钩子函数
+}
+
+// StageHookOption 启动、关闭阶段钩子选项
+type StageHookOption func(*stageHook)
+
+// WithHookTimeout 设置钩子的超时时间，d <= 0 时使用 DefaultStageHookTimeout
+// 超时后钩子的 context 被取消，不再等待钩子返回
+func WithHookTimeout(d time.Duration) StageHookOption {
+	return func(h *stageHook) {
+		if d > 0 {
+			h.timeout = d
+		}
+	}
+}
+
+// WithHookName 设置钩子名称，用于日志和错误信息，默认为 "<阶段>#<序号>"
+func WithHookName(name string) StageHookOption {
+	return func(h *stageHook) {
+		if name != "" {
+			h.name = name
+		}
+	}
+}
+
+// stageHookRegistry 已注册的启动、关闭阶段钩子
+// 注册只允许在 Start 之前进行；每个关闭阶段的钩子只执行一次
+var stageHookRegistry = struct {
+	mu       sync.Mutex
+	start    map[StartStage][]stageHook
+	stop     map[StopStage][]stageHook
+	stopDone map[StopStage]bool
+	frozen   bool
+}{
+	start:    map[StartStage][]stageHook{},
+	stop:     map[StopStage][]stageHook{},
+	stopDone: map[StopStage]bool{},
+}
+
+// OnStart 注册启动阶段钩子
+// 同一阶段的钩子按注册顺序依次执行，每个钩子有独立的超时时间（默认 DefaultStageHookTimeout）。
+// 任一钩子返回错误或超时时，后续钩子不再执行，触发 AppOnInitFailed 钩子后中止启动（panic），错误信息包含阶段和钩子名称。
+// 钩子的 context 携带运行环境和生效的配置，通过 HookEnv、HookConfig 读取。
+//
+// 参数：
+//   - stage: 启动阶段
+//   - hook: 钩子函数
+//   - opts: 钩子选项，如 WithHookTimeout、WithHookName
+//
+// 返回：
+//   - error: hook 为 nil、stage 无效或在 Start 之后注册时返回错误
+//
+// 使用示例：
+//
+//	_ = core.OnStart(core.AfterServicesInit, func(ctx context.Context) error {
+//	  return warmUpRegionCache(ctx)
+//	}, core.WithHookName("region-cache"), core.WithHookTimeout(time.Minute))
+func OnStart(stage StartStage, hook func(ctx context.Context) error, opts ...StageHookOption) error {
+	if stage < AfterConfigLoaded || stage > BeforeListen {
+		return fmt.Errorf("[阶段钩子] 无效的启动阶段: %d", stage)
+	}
+
+	stageHookRegistry.mu.Lock()
+	defer stageHookRegistry.mu.Unlock()
+	h, err := newStageHook(stage.String(), len(stageHookRegistry.start[stage]), hook, opts)
+	if err != nil {
+		return err
+	}
+	stageHookRegistry.start[stage] = append(stageHookRegistry.start[stage], h)
+	return nil
+}
+
+// OnStop 注册关闭阶段钩子
+// 同一阶段的钩子按注册顺序依次执行，每个钩子有独立的超时时间（默认 DefaultStageHookTimeout）。
+// 钩子返回错误或超时时只记录日志，同一阶段后续的钩子和关闭流程继续执行；
+// 信号和 Shutdown 同时触发关闭时，每个钩子也只执行一次。
+//
+// 参数：
+//   - stage: 关闭阶段
+//   - hook: 钩子函数
+//   - opts: 钩子选项，如 WithHookTimeout、WithHookName
+//
+// 返回：
+//   - error: hook 为 nil、stage 无效或在 Start 之后注册时返回错误
+//
+// 使用示例：
+//
+//	_ = core.OnStop(core.BeforeShutdown, func(ctx context.Context) error {
+//	  return registry.Deregister(ctx, instanceID)
+//	}, core.WithHookTimeout(5*time.Second))
+func OnStop(stage StopStage, hook func(ctx context.Context) error, opts ...StageHookOption) error {
+	if stage < BeforeShutdown || stage > AfterServicesClosed {
+		return fmt.Errorf("[阶段钩子] 无效的关闭阶段: %d", stage)
+	}
+
+	stageHookRegistry.mu.Lock()
+	defer stageHookRegistry.mu.Unlock()
+	h, err := newStageHook(stage.String(), len(stageHookRegistry.stop[stage]), hook, opts)
+	if err != nil {
+		return err
+	}
+	stageHookRegistry.stop[stage] = append(stageHookRegistry.stop[stage], h)
+	return nil
+}
+
+// newStageHook 创建阶段钩子，调用方需持有 stageHookRegistry.mu
+func newStageHook(stage string, index int, fn func(ctx context.Context) error, opts []StageHookOption) (stageHook, error) {
+	if fn == nil {
+		return stageHook{}, fmt.Errorf("[阶段钩子] %s 的钩子函数不能为 nil", stage)
+	}
+	if stageHookRegistry.frozen {
+		return stageHook{}, fmt.Errorf("[阶段钩子] 服务已启动, 无法注册 %s 阶段的钩子", stage)
+	}
+	h := stageHook{
+		name:    fmt.Sprintf("%s#%d", stage, index+1),
+		timeout: DefaultStageHookTimeout,
+		fn:      fn,
+	}
+	for _, opt := range opts {
+		opt(&h)
+	}
+	return h, nil
+}
+
+// freezeStageHooks 禁止继续注册启动、关闭阶段钩子，在 Start 开始时调用
+func freezeStageHooks() {
+	stageHookRegistry.mu.Lock()
+	defer stageHookRegistry.mu.Unlock()
+	stageHookRegistry.frozen = true
+}
+
+// runStartHooks 执行启动阶段钩子
+// 返回：
+//   - error: 第一个失败的钩子的错误，包含阶段和钩子名称
+func runStartHooks(stage StartStage) error {
+	stageHookRegistry.mu.Lock()
+	hooks := append([]stageHook(nil), stageHookRegistry.start[stage]...)
+	stageHookRegistry.mu.Unlock()
+
+	for _, h := range hooks {
+		logger.Info("[阶段钩子] 执行启动钩子: %s (阶段: %s)", h.name, stage)
+		if err := h.run(); err != nil {
+			return fmt.Errorf("启动钩子执行失败 [%s, stage=%s]: %w", h.name, stage, err)
+		}
+	}
+	return nil
+}
+
+// runStopHooks 执行关闭阶段钩子，每个阶段只执行一次，钩子失败时记录日志后继续执行
+func runStopHooks(stage StopStage) {
+	stageHookRegistry.mu.Lock()
+	if stageHookRegistry.stopDone[stage] {
+		stageHookRegistry.mu.Unlock()
+		return
+	}
+	stageHookRegistry.stopDone[stage] = true
+	hooks := append([]stageHook(nil), stageHookRegistry.stop[stage]...)
+	stageHookRegistry.mu.Unlock()
+
+	for _, h := range hooks {
+		logger.Info("[阶段钩子] 执行关闭钩子: %s (阶段: %s)", h.name, stage)
+		if err := h.run(); err != nil {
+			logger.Error("[阶段钩子] 关闭钩子执行失败 [%s, stage=%s]: %v", h.name, stage, err)
+		}
+	}
+}
+
+// run 在超时时间内执行钩子
+// 钩子在独立的 goroutine 中执行，超时后不再等待（钩子应在 context 取消后尽快返回），panic 转换为错误
+func (h stageHook) run() error {
+	ctx, cancel := context.WithTimeout(newStageHookContext(), h.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- h.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("执行超时（%s）: %w", h.timeout, ctx.Err())
+	}
+}
+
+// stageHookContextKey 阶段钩子 context 中的键
+type stageHookContextKey int
+
+const (
+	stageHookEnvKey stageHookContextKey = iota
+	stageHookConfigKey
+)
+
+// newStageHookContext 创建携带运行环境和生效配置的钩子 context
+func newStageHookContext() context.Context {
+	ctx := context.WithValue(context.Background(), stageHookEnvKey, app.Env)
+	return context.WithValue(ctx, stageHookConfigKey, &app.BaseConfig)
+}
+
+// HookEnv 获取阶段钩子 context 中的运行环境（app.Env）
+// 不是阶段钩子的 context 时返回空字符串
+func HookEnv(ctx context.Context) string {
+	env, _ := ctx.Value(stageHookEnvKey).(string)
+	return env
+}
+
+// HookConfig 获取阶段钩子 context 中生效的基础配置（已合并环境配置文件和环境变量覆盖），应只读使用
+// 不是阶段钩子的 context 时返回 nil
+func HookConfig(ctx context.Context) *config.BaseConfig {
+	cfg, _ := ctx.Value(stageHookConfigKey).(*config.BaseConfig)
+	return cfg
+}
+
+// shutdownTrigger 当前运行的服务的关闭函数，由 Start 设置
+var shutdownTrigger = struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}{}
+
+// ErrServerNotStarted 服务未启动时调用 Shutdown 返回的错误
+var ErrServerNotStarted = errors.New("[server] 服务未启动")
+
+// Shutdown 触发优雅关闭，与收到 SIGINT/SIGTERM 的效果相同，Start 在关闭流程完成后返回
+// 可多次调用，也可与信号同时触发，关闭流程只执行一次
+//
+// 返回：
+//   - error: 服务未运行（Start 尚未完成服务初始化或已返回）时返回 ErrServerNotStarted
+func Shutdown() error {
+	shutdownTrigger.mu.Lock()
+	cancel := shutdownTrigger.cancel
+	shutdownTrigger.mu.Unlock()
+	if cancel == nil {
+		return ErrServerNotStarted
+	}
+	cancel()
+	return nil
+}
+
+// setShutdownTrigger 设置 Shutdown 调用的关闭函数
+func setShutdownTrigger(cancel context.CancelFunc) {
+	shutdownTrigger.mu.Lock()
+	defer shutdownTrigger.mu.Unlock()
+	shutdownTrigger.cancel = cancel
+}
@@ -0,0 +1,282 @@
+// Package core 启动、关闭阶段钩子测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 OnStart / OnStop 阶段钩子和 Shutdown 的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 不同阶段按阶段顺序执行，同一阶段按注册顺序执行
+// 2. 启动钩子失败时后续钩子不再执行并中止启动，关闭钩子失败时继续执行
+// 3. 钩子超时后不再等待，启动钩子返回超时错误，关闭钩子继续执行后续钩子
+// 4. 信号和 Shutdown 同时触发关闭时，关闭钩子只执行一次
+// 5. 钩子 context 携带运行环境和生效的配置，参数校验和 Start 之后注册返回错误
+//
+// 运行测试：go test -v ./core/... -run StageHook
+// ==================================================
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// resetStageHooks 清空已注册的阶段钩子和关闭函数，测试结束后再次清空
+func resetStageHooks(t *testing.T) {
+	reset := func() {
+		stageHookRegistry.mu.Lock()
+		stageHookRegistry.start = map[StartStage][]stageHook{}
+		stageHookRegistry.stop = map[StopStage][]stageHook{}
+		stageHookRegistry.stopDone = map[StopStage]bool{}
+		stageHookRegistry.frozen = false
+		stageHookRegistry.mu.Unlock()
+		setShutdownTrigger(nil)
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+// stageRecorder 记录钩子的执行顺序
+type stageRecorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+// hook 返回记录名称并返回 err 的钩子函数
+func (r *stageRecorder) hook(name string, err error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.calls = append(r.calls, name)
+		return err
+	}
+}
+
+// list 返回已记录的钩子名称
+func (r *stageRecorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+// runStopSequence 按关闭流程的顺序执行全部关闭阶段钩子
+func runStopSequence() {
+	runStopHooks(BeforeShutdown)
+	runStopHooks(AfterServerClosed)
+	runStopHooks(AfterServicesClosed)
+}
+
+// TestStageHook_Order 测试阶段钩子的执行顺序
+//
+// 【功能点】验证不同阶段按阶段顺序执行，同一阶段按注册顺序执行，与注册的先后无关
+// 【测试流程】
+//  1. 交错注册三个启动阶段和三个关闭阶段的钩子，每个阶段两个
+//  2. 按 Start 的顺序执行启动阶段，按关闭流程的顺序执行关闭阶段
+//  3. 断言执行顺序为各阶段依次执行，阶段内为注册顺序
+func TestStageHook_Order(t *testing.T) {
+	resetStageHooks(t)
+	rec := &stageRecorder{}
+
+	require.NoError(t, OnStop(AfterServicesClosed, rec.hook("servicesClosed-1", nil)))
+	require.NoError(t, OnStart(BeforeListen, rec.hook("listen-1", nil)))
+	require.NoError(t, OnStart(AfterConfigLoaded, rec.hook("config-1", nil)))
+	require.NoError(t, OnStop(BeforeShutdown, rec.hook("shutdown-1", nil)))
+	require.NoError(t, OnStart(AfterServicesInit, rec.hook("services-1", nil)))
+	require.NoError(t, OnStop(AfterServerClosed, rec.hook("serverClosed-1", nil)))
+	require.NoError(t, OnStart(AfterConfigLoaded, rec.hook("config-2", nil)))
+	require.NoError(t, OnStart(BeforeListen, rec.hook("listen-2", nil)))
+	require.NoError(t, OnStop(AfterServerClosed, rec.hook("serverClosed-2", nil)))
+	require.NoError(t, OnStart(AfterServicesInit, rec.hook("services-2", nil)))
+	require.NoError(t, OnStop(BeforeShutdown, rec.hook("shutdown-2", nil)))
+	require.NoError(t, OnStop(AfterServicesClosed, rec.hook("servicesClosed-2", nil)))
+
+	for _, stage := range []StartStage{AfterConfigLoaded, AfterServicesInit, BeforeListen} {
+		require.NoError(t, runStartHooks(stage))
+	}
+	runStopSequence()
+
+	assert.Equal(t, []string{
+		"config-1", "config-2",
+		"services-1", "services-2",
+		"listen-1", "listen-2",
+		"shutdown-1", "shutdown-2",
+		"serverClosed-1", "serverClosed-2",
+		"servicesClosed-1", "servicesClosed-2",
+	}, rec.list())
+}
+
+// TestStageHook_Failure 测试钩子失败
+//
+// 【功能点】验证启动钩子失败时后续钩子不再执行、错误包含阶段和钩子名称并中止启动；关闭钩子失败（包括 panic）时继续执行
+// 【测试流程】
+//  1. 为 BeforeListen 注册三个钩子，第二个返回错误，断言 runStartHooks 返回包含阶段、钩子名称和原始错误的错误，第三个未执行
+//  2. 断言 abortStart 以该错误 panic
+//  3. 为 BeforeShutdown 注册返回错误、panic、正常的钩子，为 AfterServicesClosed 注册正常的钩子，断言执行关闭流程后正常的钩子都已执行
+func TestStageHook_Failure(t *testing.T) {
+	resetStageHooks(t)
+	rec := &stageRecorder{}
+	errWarmUp := errors.New("缓存预热失败")
+
+	require.NoError(t, OnStart(BeforeListen, rec.hook("listen-1", nil)))
+	require.NoError(t, OnStart(BeforeListen, rec.hook("listen-2", errWarmUp), WithHookName("cache-warmup")))
+	require.NoError(t, OnStart(BeforeListen, rec.hook("listen-3", nil)))
+
+	err := runStartHooks(BeforeListen)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errWarmUp)
+	assert.Contains(t, err.Error(), "cache-warmup")
+	assert.Contains(t, err.Error(), "stage=BeforeListen")
+	assert.Equal(t, []string{"listen-1", "listen-2"}, rec.list())
+	assert.PanicsWithError(t, err.Error(), func() { abortStart(err) })
+
+	require.NoError(t, OnStop(BeforeShutdown, rec.hook("shutdown-1", errors.New("注销失败"))))
+	require.NoError(t, OnStop(BeforeShutdown, func(ctx context.Context) error { panic("boom") }))
+	require.NoError(t, OnStop(BeforeShutdown, rec.hook("shutdown-3", nil)))
+	require.NoError(t, OnStop(AfterServicesClosed, rec.hook("servicesClosed-1", nil)))
+	runStopSequence()
+	assert.Equal(t, []string{"listen-1", "listen-2", "shutdown-1", "shutdown-3", "servicesClosed-1"}, rec.list())
+}
+
+// TestStageHook_Timeout 测试钩子超时
+//
+// 【功能点】验证钩子超过超时时间后不再等待：启动钩子返回超时错误，关闭钩子记录日志后继续执行后续钩子；钩子的 context 在超时后取消
+// 【测试流程】
+//  1. 注册一个忽略 context、一直阻塞的启动钩子，超时时间 50ms，断言 runStartHooks 在 1s 内返回 context.DeadlineExceeded 错误，后续钩子未执行
+//  2. 注册一个等待 context 取消的关闭钩子，超时时间 50ms，断言执行关闭流程后钩子收到取消且后续钩子已执行
+//  3. 断言 WithHookTimeout 传入非正数时使用默认超时时间
+func TestStageHook_Timeout(t *testing.T) {
+	resetStageHooks(t)
+	rec := &stageRecorder{}
+	block := make(chan struct{})
+	defer close(block)
+
+	require.NoError(t, OnStart(AfterServicesInit, func(ctx context.Context) error {
+		<-block
+		return nil
+	}, WithHookTimeout(50*time.Millisecond)))
+	require.NoError(t, OnStart(AfterServicesInit, rec.hook("services-2", nil)))
+
+	begin := time.Now()
+	err := runStartHooks(AfterServicesInit)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "AfterServicesInit#1")
+	assert.Less(t, time.Since(begin), time.Second)
+	assert.Empty(t, rec.list())
+
+	canceled := make(chan struct{})
+	require.NoError(t, OnStop(BeforeShutdown, func(ctx context.Context) error {
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}, WithHookTimeout(50*time.Millisecond)))
+	require.NoError(t, OnStop(BeforeShutdown, rec.hook("shutdown-2", nil)))
+	runStopSequence()
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("超时后钩子的 context 应被取消")
+	}
+	assert.Equal(t, []string{"shutdown-2"}, rec.list())
+
+	h, err := newStageHook("BeforeListen", 0, rec.hook("default", nil), []StageHookOption{WithHookTimeout(0)})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultStageHookTimeout, h.timeout)
+}
+
+// TestStageHook_StopOnce 测试关闭钩子只执行一次
+//
+// 【功能点】验证信号和 Shutdown 同时触发关闭时，每个关闭钩子只执行一次
+// 【测试流程】
+//  1. 未设置关闭函数时调用 Shutdown，断言返回 ErrServerNotStarted
+//  2. 与 Start 相同地设置关闭函数，启动在 context 取消后执行关闭流程的协程
+//  3. 模拟信号协程直接取消 context，同时多次调用 Shutdown
+//  4. 断言每个关闭阶段的钩子按顺序各执行一次，再次执行关闭流程不会重复执行
+func TestStageHook_StopOnce(t *testing.T) {
+	resetStageHooks(t)
+	rec := &stageRecorder{}
+	require.NoError(t, OnStop(BeforeShutdown, rec.hook("shutdown", nil)))
+	require.NoError(t, OnStop(AfterServerClosed, rec.hook("serverClosed", nil)))
+	require.NoError(t, OnStop(AfterServicesClosed, rec.hook("servicesClosed", nil)))
+
+	assert.ErrorIs(t, Shutdown(), ErrServerNotStarted)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	setShutdownTrigger(cancel)
+
+	// 与 Start 相同，关闭协程在 context 取消后执行关闭流程
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		runStopSequence()
+	}()
+
+	// 信号协程与显式调用同时触发关闭
+	var wg sync.WaitGroup
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		cancel()
+	}()
+	for range 3 {
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, Shutdown())
+		}()
+	}
+	wg.Wait()
+	<-shutdownDone
+	// 再次执行关闭流程（如 Start 返回前的清理）不会重复执行钩子
+	runStopSequence()
+
+	assert.Equal(t, []string{"shutdown", "serverClosed", "servicesClosed"}, rec.list())
+}
+
+// TestStageHook_ContextAndRegister 测试钩子 context 和注册校验
+//
+// 【功能点】验证钩子 context 携带 app.Env 和生效的基础配置；参数无效和 Start 之后注册返回错误
+// 【测试流程】
+//  1. 设置 app.Env 和 app.BaseConfig，注册启动钩子读取 HookEnv 和 HookConfig，断言与设置的值相同
+//  2. 断言普通 context 中 HookEnv 返回空字符串、HookConfig 返回 nil
+//  3. 断言钩子为 nil、阶段无效时返回错误
+//  4. 调用 freezeStageHooks 模拟 Start 之后，断言 OnStart、OnStop 返回错误
+func TestStageHook_ContextAndRegister(t *testing.T) {
+	resetStageHooks(t)
+	originalEnv, originalConfig := app.Env, app.BaseConfig
+	t.Cleanup(func() {
+		app.Env, app.BaseConfig = originalEnv, originalConfig
+	})
+	app.Env = "test"
+	app.BaseConfig = config.BaseConfig{Service: config.ServiceInfo{Port: 18080}}
+
+	var env string
+	var cfg *config.BaseConfig
+	require.NoError(t, OnStart(AfterConfigLoaded, func(ctx context.Context) error {
+		env, cfg = HookEnv(ctx), HookConfig(ctx)
+		return nil
+	}))
+	require.NoError(t, runStartHooks(AfterConfigLoaded))
+	assert.Equal(t, "test", env)
+	require.NotNil(t, cfg)
+	assert.Equal(t, 18080, cfg.Service.Port)
+	assert.Empty(t, HookEnv(context.Background()))
+	assert.Nil(t, HookConfig(context.Background()))
+
+	assert.Error(t, OnStart(BeforeListen, nil))
+	assert.Error(t, OnStop(BeforeShutdown, nil))
+	assert.Error(t, OnStart(StartStage(10), func(ctx context.Context) error { return nil }))
+	assert.Error(t, OnStop(StopStage(-1), func(ctx context.Context) error { return nil }))
+
+	freezeStageHooks()
+	err := OnStart(BeforeListen, func(ctx context.Context) error { return nil })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "服务已启动")
+	assert.Error(t, OnStop(AfterServerClosed, func(ctx context.Context) error { return nil }))
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zzsen/gin_core/core/lifecycle"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// 启动阶段名称，按 Start 中的执行顺序排列
+const (
+	startupPhaseLoadConfig     = "loadConfig"     // 加载和校验配置
+	startupPhaseInitMiddleware = "initMiddleware" // 注册系统中间件
+	startupPhaseInitService    = "initService"    // 初始化服务组件（各服务的耗时见 StartupTimings.Services）
+	startupPhaseBuildEngine    = "buildEngine"    // 创建引擎、设置全局选项和中间件
+	startupPhaseRegisterRoutes = "registerRoutes" // 注册内置路由、用户路由和控制器
+)
+
+// StartupPhase 单个启动阶段或服务初始化的耗时
+type StartupPhase struct {
+	Name       string  `json:"name"`       // 阶段名称或服务名称
+	DurationMs float64 `json:"durationMs"` // 耗时（毫秒）
+}
+
+// StartupTimings 启动耗时
+type StartupTimings struct {
+	StartTime time.Time      `json:"startTime"` // 开始启动的时间
+	TotalMs   float64        `json:"totalMs"`   // 从开始启动到路由注册完成的总耗时（毫秒），启动未完成时为 0
+	Phases    []StartupPhase `json:"phases"`    // 各启动阶段的耗时，按执行顺序排列
+	Services  []StartupPhase `json:"services"`  // 各服务的初始化耗时（包括服务级钩子），按服务名称排序
+}
+
+// startupRecorder 启动耗时记录器
+type startupRecorder struct {
+	mu      sync.Mutex
+	timings StartupTimings
+}
+
+// startupTimings 当前进程的启动耗时，由 Start 记录
+var startupTimings = &startupRecorder{}
+
+// reset 清空记录，从当前时间开始计时
+func (r *startupRecorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timings = StartupTimings{StartTime: time.Now(), Phases: []StartupPhase{}, Services: []StartupPhase{}}
+}
+
+// begin 开始记录一个启动阶段，调用返回的函数结束记录
+// 同名阶段重复记录时覆盖之前的耗时
+func (r *startupRecorder) begin(name string) func() {
+	start := time.Now()
+	return func() {
+		phase := StartupPhase{Name: name, DurationMs: durationMs(time.Since(start))}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for i := range r.timings.Phases {
+			if r.timings.Phases[i].Name == name {
+				r.timings.Phases[i] = phase
+				return
+			}
+		}
+		r.timings.Phases = append(r.timings.Phases, phase)
+	}
+}
+
+// recordServices 记录 registry 中按配置需要初始化且已初始化成功的服务的耗时
+func (r *startupRecorder) recordServices(registry *lifecycle.ServiceRegistry, cfg *config.BaseConfig) {
+	services := make([]StartupPhase, 0)
+	for _, service := range registry.GetServicesToInit(cfg) {
+		if duration, ok := registry.GetInitDuration(service.Name()); ok {
+			services = append(services, StartupPhase{Name: service.Name(), DurationMs: durationMs(duration)})
+		}
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timings.Services = services
+}
+
+// finish 记录总耗时
+func (r *startupRecorder) finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.timings.StartTime.IsZero() {
+		r.timings.TotalMs = durationMs(time.Since(r.timings.StartTime))
+	}
+}
+
+// snapshot 返回启动耗时的副本
+func (r *startupRecorder) snapshot() StartupTimings {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	timings := r.timings
+	timings.Phases = append([]StartupPhase{}, r.timings.Phases...)
+	timings.Services = append([]StartupPhase{}, r.timings.Services...)
+	return timings
+}
+
+// GetStartupTimings 获取当前进程的启动耗时
+// 各阶段由 Start 记录，未通过 Start 启动时为空
+//
+// 返回：
+//   - StartupTimings: 启动耗时的副本
+func GetStartupTimings() StartupTimings {
+	return startupTimings.snapshot()
+}
+
+// logStartupTimings 输出启动耗时日志
+func logStartupTimings() {
+	timings := startupTimings.snapshot()
+	logger.InfoWithFields(map[string]any{
+		"totalMs":  timings.TotalMs,
+		"phases":   timings.Phases,
+		"services": timings.Services,
+	}, "[server] 启动耗时, total: %.1fms", timings.TotalMs)
+}
+
+// durationMs 将耗时转换为毫秒
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,23 @@
+package core
+
+import "github.com/zzsen/gin_core/app"
+
+// SetTenantResolver 注册租户解析函数，将租户 ID 映射为 dbList 中的数据库别名
+// 注册后不再使用 tenant.mapping 配置，适用于租户信息保存在数据库或配置中心、需要动态增加租户的场景
+// 租户不存在时解析函数应返回 app.ErrUnknownTenant，tenantHandler 会返回 response.ResponseTenantUnknown 响应码
+//
+// 参数：
+//   - resolver: 租户解析函数，传入 nil 时恢复使用 tenant.mapping 配置
+//
+// 使用示例：
+//
+//	core.SetTenantResolver(func(ctx context.Context, tenantID string) (string, error) {
+//	  alias, ok := tenantRegistry.Lookup(tenantID)
+//	  if !ok {
+//	    return "", app.ErrUnknownTenant
+//	  }
+//	  return alias, nil
+//	})
+func SetTenantResolver(resolver app.TenantResolver) {
+	app.SetTenantResolver(resolver)
+}
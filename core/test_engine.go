@@ -0,0 +1,70 @@
+package core
+
+import (
+	"maps"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// BuildTestEngine 构建用于测试的引擎，不加载配置文件、不初始化服务组件，推荐通过 coretest 包使用
+// 构建前保存以下全局状态并替换：
+//   - app.BaseConfig 替换为 cfg
+//   - 路由选项函数列表替换为 routes，不包含通过 AddOptionFunc 注册的路由；内置路由和通过 RegisterController 注册的控制器照常注册
+//   - 注册框架内置中间件，已注册的同名中间件保持不变
+//
+// 引擎处理请求时仍读取 app.BaseConfig 等全局状态，测试结束前不能调用 restore；
+// 全局状态没有隔离，调用方需保证同一时间只有一个测试在使用 BuildTestEngine 构建的引擎
+//
+// 参数：
+//   - cfg: 基础配置
+//   - routes: 路由选项函数
+//
+// 返回：
+//   - *gin.Engine: 引擎
+//   - func(): 恢复构建前的全局状态，构建失败时已恢复，返回 nil
+//   - error: 与 initEngine 相同，如中间件未注册、存在路由冲突
+func BuildTestEngine(cfg config.BaseConfig, routes ...gin.OptionFunc) (*gin.Engine, func(), error) {
+	source := callerSource(1)
+	originalConfig := app.BaseConfig
+	originalRoutes := Routes()
+
+	optionFuncMu.Lock()
+	originalOptionFuncs := optionFuncList
+	optionFuncList = make([]optionFunc, 0, len(routes))
+	for _, fn := range routes {
+		optionFuncList = append(optionFuncList, optionFunc{fn: fn, source: source})
+	}
+	optionFuncMu.Unlock()
+
+	middlewareMutex.Lock()
+	originalMiddlewares := maps.Clone(middleWareMap)
+	originalConfigurableMiddlewares := maps.Clone(configurableMiddlewareMap)
+	for _, defaultMiddleware := range defaultMiddlewares {
+		if !middlewareRegistered(defaultMiddleware.name) {
+			middleWareMap[defaultMiddleware.name] = defaultMiddleware.handler
+		}
+	}
+	middlewareMutex.Unlock()
+
+	restore := func() {
+		app.BaseConfig = originalConfig
+		optionFuncMu.Lock()
+		optionFuncList = originalOptionFuncs
+		optionFuncMu.Unlock()
+		middlewareMutex.Lock()
+		middleWareMap, configurableMiddlewareMap = originalMiddlewares, originalConfigurableMiddlewares
+		middlewareMutex.Unlock()
+		setRoutes(originalRoutes)
+		_ = applyGlobalSettings(&app.BaseConfig)
+	}
+
+	app.BaseConfig = cfg
+	engine, err := initEngine()
+	if err != nil {
+		restore()
+		return nil, nil, err
+	}
+	return engine, restore, nil
+}
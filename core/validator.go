@@ -1,19 +1,47 @@
 package core
 
 import (
+	"fmt"
 	"reflect"
 	"sync"
 
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/zzsen/gin_core/model/types"
 )
 
+// appValidator 框架的参数校验器，由 overrideValidator 设置为 gin 的默认验证器
+var appValidator = new(defaultValidator)
+
 // overrideValidator 重写Gin框架默认的验证器
 // 将Gin的默认验证器替换为自定义的验证器实现
 // 这允许我们自定义验证规则、错误处理和验证行为
 // 必须在服务启动早期调用，确保所有后续的请求都使用自定义验证器
 func overrideValidator() {
-	binding.Validator = new(defaultValidator)
+	binding.Validator = appValidator
+}
+
+// RegisterValidation 注册自定义校验标签
+// 可以在 Start 之前或之后调用，可并发调用；注册与正在执行的校验互斥，注册后的请求立即生效。
+// 框架内置 enum 标签（binding:"enum=名称"，见 types.RegisterEnum），校验失败的消息可通过 i18n 注册 validation.<tag> 消息
+//
+// 参数：
+//   - tag: 校验标签，如 phone
+//   - fn: 校验函数
+//   - callValidationEvenIfNull: 字段为 nil 时是否仍调用校验函数，默认为 false
+//
+// 返回：
+//   - error: 标签为空或校验函数为 nil 时返回错误
+//
+// 使用示例：
+//
+//	_ = core.RegisterValidation("orderStatus", OrderStatuses.Validator())
+//
+//	type UpdateOrderRequest struct {
+//	  Status string `json:"status" binding:"required,orderStatus"`
+//	}
+func RegisterValidation(tag string, fn validator.Func, callValidationEvenIfNull ...bool) error {
+	return appValidator.registerValidation(tag, fn, callValidationEvenIfNull...)
 }
 
 // defaultValidator 自定义的结构体验证器
@@ -32,6 +60,7 @@ func overrideValidator() {
 // - 数据完整性验证
 type defaultValidator struct {
 	once     sync.Once           // 确保验证器只初始化一次
+	mu       sync.RWMutex        // 注册校验标签与执行校验互斥
 	validate *validator.Validate // validator/v10 验证器实例
 }
 
@@ -67,6 +96,8 @@ func (v *defaultValidator) ValidateStruct(obj any) error {
 		v.lazyinit()
 
 		// 执行结构体验证，检查所有带有binding标签的字段
+		v.mu.RLock()
+		defer v.mu.RUnlock()
 		if err := v.validate.Struct(obj); err != nil {
 			return err
 		}
@@ -82,14 +113,15 @@ func (v *defaultValidator) ValidateStruct(obj any) error {
 // 返回值: validator.Validate实例，可用于自定义验证逻辑
 //
 // 使用场景：
-// - 注册自定义验证函数
 // - 配置验证器的高级选项
 // - 执行复杂的跨字段验证
 //
 // 使用示例：
 //
-//	engine := validator.Engine().(*validator.Validate)
-//	engine.RegisterValidation("custom", customValidationFunc)
+//	engine := binding.Validator.Engine().(*validator.Validate)
+//	engine.RegisterStructValidation(validateDateRange, DateRangeRequest{})
+//
+// 注册自定义校验标签应使用 RegisterValidation，直接在引擎上注册与正在执行的校验不互斥
 func (v *defaultValidator) Engine() any {
 	// 确保验证器已初始化
 	v.lazyinit()
@@ -103,13 +135,10 @@ func (v *defaultValidator) Engine() any {
 // 初始化配置：
 // - 创建新的validator实例
 // - 设置验证标签名称为"binding"
-// - 预留自定义验证规则扩展点
+// - 注册内置的 enum 校验标签
 //
 // 扩展说明：
-// 在"add any custom validations etc. here"注释处可以添加：
-// - 自定义验证函数：v.validate.RegisterValidation("tagname", func)
-// - 自定义类型验证：v.validate.RegisterCustomTypeFunc(func, types...)
-// - 跨字段验证：v.validate.RegisterStructValidation(func, struct{})
+// 应用的自定义验证函数通过 RegisterValidation 注册
 func (v *defaultValidator) lazyinit() {
 	v.once.Do(func() {
 		// 创建新的validator实例
@@ -119,13 +148,22 @@ func (v *defaultValidator) lazyinit() {
 		// 这意味着结构体字段需要使用`binding:"..."`标签来定义验证规则
 		v.validate.SetTagName("binding")
 
-		// 在此处添加自定义验证规则
-		// 示例：
-		// v.validate.RegisterValidation("phone", validatePhone)
-		// v.validate.RegisterValidation("idcard", validateIDCard)
+		// 内置的自定义验证规则，应用的验证规则通过 RegisterValidation 注册
+		_ = v.validate.RegisterValidation("enum", types.ValidateEnum)
 	})
 }
 
+// registerValidation 注册校验标签，与正在执行的校验互斥
+func (v *defaultValidator) registerValidation(tag string, fn validator.Func, callValidationEvenIfNull ...bool) error {
+	if tag == "" || fn == nil {
+		return fmt.Errorf("注册校验标签失败: 标签和校验函数不能为空")
+	}
+	v.lazyinit()
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.validate.RegisterValidation(tag, fn, callValidationEvenIfNull...)
+}
+
 // kindOfData 获取数据的反射类型
 // 这是一个工具函数，用于确定传入数据的实际类型
 // 自动处理指针类型，返回指针指向的实际数据类型
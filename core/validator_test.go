@@ -0,0 +1,114 @@
+// Package core 参数校验器测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含框架参数校验器的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 内置 enum 标签校验已注册的枚举，校验失败的消息列出可选值
+// 2. RegisterValidation 注册自定义校验标签
+// 3. 并发注册校验标签与执行校验
+//
+// 运行测试：go test -v ./core/... -run Validator
+// ==================================================
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/exception"
+	"github.com/zzsen/gin_core/model/types"
+)
+
+// validatorOrderStatus 测试用枚举类型
+type validatorOrderStatus string
+
+func init() {
+	types.MustRegisterEnum("validatorOrderStatus", types.NewEnum[validatorOrderStatus]("pending", "paid", "canceled"))
+}
+
+// TestValidator_Enum 测试内置 enum 标签
+//
+// 【功能点】验证 binding:"enum=名称" 对可选值通过，未知值校验失败且消息列出全部可选值
+// 【测试流程】
+//  1. 校验 status=paid 的请求，断言通过
+//  2. 校验 status=refunded 的请求，断言返回 validator.ValidationErrors
+//  3. 转换为 InvalidParam，断言消息包含字段名和 pending, paid, canceled
+func TestValidator_Enum(t *testing.T) {
+	type updateOrderRequest struct {
+		Status string `json:"status" binding:"required,enum=validatorOrderStatus"`
+	}
+	require.NoError(t, appValidator.ValidateStruct(&updateOrderRequest{Status: "paid"}))
+
+	err := appValidator.ValidateStruct(&updateOrderRequest{Status: "refunded"})
+	require.Error(t, err)
+	var validationErrors validator.ValidationErrors
+	require.True(t, errors.As(err, &validationErrors))
+	msg := exception.NewInvalidParamFromValidator(validationErrors).Error()
+	assert.Contains(t, msg, "Status")
+	assert.Contains(t, msg, "pending, paid, canceled")
+}
+
+// TestRegisterValidation 测试注册自定义校验标签
+//
+// 【功能点】验证注册后的标签立即生效，标签为空或校验函数为 nil 时返回错误
+// 【测试流程】
+//  1. 注册 evenNumber 标签，断言偶数通过、奇数不通过
+//  2. 以空标签和 nil 校验函数注册，断言返回错误
+func TestRegisterValidation(t *testing.T) {
+	require.NoError(t, RegisterValidation("evenNumber", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	}))
+	type request struct {
+		Count int `binding:"evenNumber"`
+	}
+	assert.NoError(t, appValidator.ValidateStruct(&request{Count: 2}))
+	assert.Error(t, appValidator.ValidateStruct(&request{Count: 3}))
+
+	assert.Error(t, RegisterValidation("", func(validator.FieldLevel) bool { return true }))
+	assert.Error(t, RegisterValidation("nilFunc", nil))
+}
+
+// TestRegisterValidation_Concurrent 测试并发注册校验标签
+//
+// 【功能点】验证请求校验过程中并发注册校验标签不发生数据竞争（go test -race），注册的标签均生效
+// 【测试流程】
+//  1. 10 个协程各注册一个校验标签，同时 10 个协程执行 enum 校验
+//  2. 断言所有标签注册成功，校验结果正确
+func TestRegisterValidation_Concurrent(t *testing.T) {
+	type request struct {
+		Status string `binding:"enum=validatorOrderStatus"`
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			errs <- RegisterValidation(fmt.Sprintf("concurrentTag%d", i), func(fl validator.FieldLevel) bool {
+				return strings.HasPrefix(fl.Field().String(), "ok")
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			errs <- appValidator.ValidateStruct(&request{Status: "pending"})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	type tagged struct {
+		Value string `binding:"concurrentTag9"`
+	}
+	assert.NoError(t, appValidator.ValidateStruct(&tagged{Value: "ok"}))
+	assert.Error(t, appValidator.ValidateStruct(&tagged{Value: "bad"}))
+}
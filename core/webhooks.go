@@ -0,0 +1,66 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/idempotency"
+	"github.com/zzsen/gin_core/webhooks"
+)
+
+// RegisterWebhook 注册第三方回调（Webhook）接收接口
+// 应在 Start 之前调用，启动时挂载到 POST cfg.Path（受 service.routePrefix 影响）。
+// 接口校验请求体的 HMAC 签名和时间戳，开启去重时按签名跳过已处理成功的重复投递，
+// 响应不使用统一响应结构：成功 200，签名或时间戳校验不通过 400，处理函数返回错误 500（服务商会重新投递）
+// 开启去重且未设置 cfg.DedupStore 时，配置了 Redis 则使用 Redis 保存去重记录，否则使用内存
+//
+// 参数：
+//   - name: 回调名称，全局唯一，用于日志和去重键
+//   - cfg: 回调配置
+//
+// 返回：
+//   - error: 名称或路径重复、配置有误时返回错误
+//
+// 使用示例：
+//
+//	err := core.RegisterWebhook("stripe", webhooks.Config{
+//	  Path:            "/webhooks/stripe",
+//	  Secret:          os.Getenv("STRIPE_WEBHOOK_SECRET"),
+//	  SignatureHeader: "X-Signature",
+//	  TimestampHeader: "X-Timestamp",
+//	  Dedup:           true,
+//	  Handler: func(ctx context.Context, payload []byte, headers http.Header) error {
+//	    return payment.HandleNotify(ctx, payload)
+//	  },
+//	})
+func RegisterWebhook(name string, cfg webhooks.Config) error {
+	return webhooks.Register(name, cfg)
+}
+
+// webhookOptionFuncs 挂载已注册的回调接口
+//
+// 返回：
+//   - []optionFunc: 每个回调一个路由选项函数，未注册回调时为空
+//   - error: 回调配置有误时返回错误
+func webhookOptionFuncs() ([]optionFunc, error) {
+	registered := webhooks.Registered()
+	funcs := make([]optionFunc, 0, len(registered))
+	for _, w := range registered {
+		cfg := w.Config
+		if cfg.Dedup && cfg.DedupStore == nil && app.Redis != nil {
+			cfg.DedupStore = idempotency.NewRedisStore(app.Redis, "")
+		}
+		handler, err := webhooks.NewHandler(w.Name, cfg)
+		if err != nil {
+			return nil, err
+		}
+		path := cfg.Path
+		funcs = append(funcs, optionFunc{
+			fn:     func(e *gin.Engine) { e.Handle(http.MethodPost, path, handler) },
+			source: fmt.Sprintf("core.RegisterWebhook(%s)", w.Name),
+		})
+	}
+	return funcs, nil
+}
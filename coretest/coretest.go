@@ -0,0 +1,264 @@
+// Package coretest 提供基于 gin_core 的应用的测试工具
+//
+// NewTestEngine 使用独立的配置构建引擎，可选地接入临时 SQLite 数据库和 miniredis，
+// 测试结束时通过 t.Cleanup 自动恢复 app.BaseConfig、路由和中间件注册表、app.DB、app.Redis 等全局状态；
+// PerformJSON、DecodeEnvelope 用于发送请求和解析统一响应结构。
+//
+// 引擎依赖的全局状态在同一时间只能属于一个测试：不同顶层测试中的 NewTestEngine 串行执行，
+// 后调用的测试会阻塞到先调用的测试结束（包括调用了 t.Parallel 的测试）；
+// 同一顶层测试及其子测试中可以创建多个引擎，它们共享最后一次设置的 app.BaseConfig、app.DB 和 app.Redis，
+// 中间件和路由在构建时确定，互不影响。
+//
+// 使用示例：
+//
+//	func TestCreateUser(t *testing.T) {
+//		engine := coretest.NewTestEngine(t,
+//			coretest.WithMiddlewares("exceptionHandler"),
+//			coretest.WithSQLite(&User{}),
+//			coretest.WithRoutes(router.Register),
+//		)
+//		w := coretest.PerformJSON(t, engine, http.MethodPost, "/users", map[string]any{"name": "alice"})
+//		code, msg, data := coretest.DecodeEnvelope(t, w)
+//		...
+//	}
+package coretest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/core"
+	"github.com/zzsen/gin_core/model/config"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+var (
+	// stateMu 保护 stateOwner、stateDepth
+	stateMu   sync.Mutex
+	stateCond = sync.NewCond(&stateMu)
+	// stateOwner 持有全局状态的顶层测试名称
+	stateOwner string
+	// stateDepth stateOwner 中尚未结束的 NewTestEngine 调用数
+	stateDepth int
+)
+
+// TestOption 测试引擎选项
+type TestOption func(*testOptions)
+
+// testOptions 测试引擎选项
+type testOptions struct {
+	config      config.BaseConfig
+	middlewares config.MiddlewareList
+	routes      []gin.OptionFunc
+	sqlite      bool
+	models      []any
+	miniRedis   bool
+}
+
+// WithConfig 使用 cfg 作为 app.BaseConfig，未设置时为零值配置（不启用任何中间件和组件）
+func WithConfig(cfg config.BaseConfig) TestOption {
+	return func(o *testOptions) {
+		o.config = cfg
+	}
+}
+
+// WithMiddlewares 按名称顺序启用中间件，覆盖配置中的 service.middlewares，与 WithConfig 的先后顺序无关
+// 框架内置中间件（如 exceptionHandler、traceIdHandler）无需注册即可使用
+func WithMiddlewares(names ...string) TestOption {
+	return func(o *testOptions) {
+		o.middlewares = config.MiddlewareNames(names...)
+	}
+}
+
+// WithRoutes 注册路由选项函数，引擎只包含内置路由、这些路由和通过 core.RegisterController 注册的控制器，
+// 不包含通过 core.AddOptionFunc 注册的路由
+func WithRoutes(fns ...gin.OptionFunc) TestOption {
+	return func(o *testOptions) {
+		o.routes = append(o.routes, fns...)
+	}
+}
+
+// WithSQLite 使用临时目录中的 SQLite 数据库作为 app.DB，并对 models 执行 AutoMigrate，同时开启 system.useMysql
+func WithSQLite(models ...any) TestOption {
+	return func(o *testOptions) {
+		o.sqlite = true
+		o.models = append(o.models, models...)
+	}
+}
+
+// WithMiniRedis 启动 miniredis 并将连接它的客户端作为 app.Redis，同时开启 system.useRedis
+func WithMiniRedis() TestOption {
+	return func(o *testOptions) {
+		o.miniRedis = true
+	}
+}
+
+// NewTestEngine 构建用于测试的引擎，测试结束时自动恢复全局状态
+// 通过 core.BuildTestEngine 构建，不加载配置文件、不初始化服务组件；其他顶层测试正在使用引擎时阻塞等待
+// 参数：
+//   - t: 测试
+//   - opts: 测试引擎选项
+//
+// 返回：
+//   - *gin.Engine: 引擎，构建失败时终止测试
+func NewTestEngine(t testing.TB, opts ...TestOption) *gin.Engine {
+	t.Helper()
+	acquireState(t)
+	t.Cleanup(releaseState)
+
+	o := &testOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	cfg := o.config
+	if o.middlewares != nil {
+		cfg.Service.Middlewares = o.middlewares
+	}
+	if o.sqlite {
+		useSQLite(t, o.models)
+		cfg.System.UseMysql = true
+	}
+	if o.miniRedis {
+		useMiniRedis(t)
+		cfg.System.UseRedis = true
+	}
+
+	engine, restore, err := core.BuildTestEngine(cfg, o.routes...)
+	if err != nil {
+		t.Fatalf("构建测试引擎失败: %v", err)
+	}
+	t.Cleanup(restore)
+	return engine
+}
+
+// acquireState 获取全局状态，其他顶层测试持有时等待；同一顶层测试及其子测试可以重复获取
+func acquireState(t testing.TB) {
+	root, _, _ := strings.Cut(t.Name(), "/")
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	for stateDepth > 0 && stateOwner != root {
+		stateCond.Wait()
+	}
+	stateOwner = root
+	stateDepth++
+}
+
+// releaseState 释放一次 acquireState 获取的全局状态
+func releaseState() {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	stateDepth--
+	if stateDepth == 0 {
+		stateOwner = ""
+		stateCond.Broadcast()
+	}
+}
+
+// useSQLite 打开临时 SQLite 数据库作为 app.DB，测试结束时关闭并恢复原来的 app.DB
+func useSQLite(t testing.TB, models []any) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	if err != nil {
+		t.Fatalf("打开 SQLite 数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取 SQLite 连接失败: %v", err)
+	}
+	// 临时目录在 sqlDB 关闭后删除，Cleanup 按注册的逆序执行
+	original := app.DB
+	t.Cleanup(func() {
+		app.DB = original
+		_ = sqlDB.Close()
+	})
+	if len(models) > 0 {
+		if err := db.AutoMigrate(models...); err != nil {
+			t.Fatalf("迁移 SQLite 数据表失败: %v", err)
+		}
+	}
+	app.DB = db
+}
+
+// useMiniRedis 启动 miniredis 并将客户端作为 app.Redis，测试结束时关闭并恢复原来的 app.Redis
+func useMiniRedis(t testing.TB) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	original := app.Redis
+	t.Cleanup(func() {
+		app.Redis = original
+		_ = client.Close()
+	})
+	app.Redis = client
+}
+
+// PerformJSON 向引擎发送请求并返回响应记录
+// 参数：
+//   - t: 测试
+//   - engine: 引擎
+//   - method: 请求方法
+//   - path: 请求路径，可以包含查询参数
+//   - body: 请求体，nil 时不发送请求体，[]byte 和 string 原样发送，其他值编码为 JSON；有请求体时 Content-Type 为 application/json
+//
+// 返回：
+//   - *httptest.ResponseRecorder: 响应记录
+func PerformJSON(t testing.TB, engine http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(b)
+	case string:
+		reader = strings.NewReader(b)
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("编码请求体失败: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+// DecodeEnvelope 解析统一响应结构（response.Response）
+// 参数：
+//   - t: 测试
+//   - w: 响应记录
+//
+// 返回：
+//   - int: 响应码
+//   - string: 响应消息
+//   - json.RawMessage: 响应数据，可再解码为具体类型；响应体不是统一响应结构时终止测试
+func DecodeEnvelope(t testing.TB, w *httptest.ResponseRecorder) (code int, msg string, data json.RawMessage) {
+	t.Helper()
+	var envelope struct {
+		Code int             `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("解析响应失败: %v, 响应体: %s", err, w.Body.String())
+	}
+	return envelope.Code, envelope.Msg, envelope.Data
+}
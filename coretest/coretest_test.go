@@ -0,0 +1,196 @@
+// Package coretest 测试工具测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含测试引擎和请求辅助函数的单元测试，使用 SQLite 和 miniredis，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 同一测试中的两个引擎使用不同的中间件和路由，互不影响
+// 2. 测试结束后恢复 app.BaseConfig、路由列表、app.DB、app.Redis
+// 3. WithSQLite、WithMiniRedis 接入临时数据库和 Redis，PerformJSON、DecodeEnvelope 发送请求并解析响应
+// 4. 不同顶层测试并行时串行使用全局状态
+//
+// 运行测试：go test -v ./coretest/...
+// ==================================================
+package coretest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/core"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// note 测试数据表
+type note struct {
+	ID    uint   `gorm:"primaryKey" json:"id"`
+	Title string `json:"title"`
+}
+
+// boomRoutes 注册一个 panic 的路由
+func boomRoutes(e *gin.Engine) {
+	e.GET("/boom", func(c *gin.Context) {
+		panic(errors.New("boom"))
+	})
+}
+
+// TestNewTestEngine_IsolatedMiddlewares 测试两个引擎使用不同的中间件
+//
+// 【功能点】验证同一测试中创建的两个引擎按各自的中间件和路由处理请求
+// 【测试流程】
+//  1. 引擎 a 启用 exceptionHandler 和 traceIdHandler，引擎 b 不启用中间件，两者注册不同的路由
+//  2. 请求 a 的 /boom，断言返回统一响应结构和 X-Trace-ID 响应头
+//  3. 请求 b 的 /boom，断言由 Recovery 返回 500 且没有 X-Trace-ID 响应头
+//  4. 断言两个引擎互相不包含对方的路由
+func TestNewTestEngine_IsolatedMiddlewares(t *testing.T) {
+	a := NewTestEngine(t,
+		WithMiddlewares("exceptionHandler", "traceIdHandler"),
+		WithRoutes(boomRoutes, func(e *gin.Engine) {
+			e.GET("/a", func(c *gin.Context) { response.Ok(c) })
+		}),
+	)
+	b := NewTestEngine(t, WithRoutes(boomRoutes, func(e *gin.Engine) {
+		e.GET("/b", func(c *gin.Context) { response.Ok(c) })
+	}))
+
+	w := PerformJSON(t, a, http.MethodGet, "/boom", nil)
+	assert.NotEmpty(t, w.Header().Get("X-Trace-ID"))
+	code, _, _ := DecodeEnvelope(t, w)
+	assert.NotZero(t, code)
+
+	w = PerformJSON(t, b, http.MethodGet, "/boom", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("X-Trace-ID"))
+	assert.Empty(t, w.Body.String())
+
+	assert.Equal(t, http.StatusOK, PerformJSON(t, a, http.MethodGet, "/a", nil).Code)
+	assert.Equal(t, http.StatusNotFound, PerformJSON(t, a, http.MethodGet, "/b", nil).Code)
+	assert.Equal(t, http.StatusOK, PerformJSON(t, b, http.MethodGet, "/b", nil).Code)
+	assert.Equal(t, http.StatusNotFound, PerformJSON(t, b, http.MethodGet, "/a", nil).Code)
+}
+
+// TestNewTestEngine_RestoresGlobals 测试恢复全局状态
+//
+// 【功能点】验证子测试结束后 app.BaseConfig、路由列表、app.DB、app.Redis 恢复为创建引擎前的值
+// 【测试流程】
+//  1. 记录当前的全局状态
+//  2. 子测试中使用路由前缀、SQLite 和 miniredis 创建引擎，断言全局状态已替换
+//  3. 子测试结束后断言全局状态已恢复
+func TestNewTestEngine_RestoresGlobals(t *testing.T) {
+	originalConfig, originalDB, originalRedis := app.BaseConfig, app.DB, app.Redis
+	originalRoutes := core.Routes()
+
+	t.Run("engine", func(t *testing.T) {
+		cfg := config.BaseConfig{}
+		cfg.Service.RoutePrefix = "/api"
+		NewTestEngine(t, WithConfig(cfg), WithSQLite(), WithMiniRedis(), WithRoutes(func(e *gin.Engine) {
+			e.GET("/ping", func(c *gin.Context) { response.Ok(c) })
+		}))
+		assert.Equal(t, "/api", app.BaseConfig.Service.RoutePrefix)
+		assert.True(t, app.BaseConfig.System.UseMysql)
+		assert.True(t, app.BaseConfig.System.UseRedis)
+		assert.NotNil(t, app.DB)
+		assert.NotNil(t, app.Redis)
+		assert.Contains(t, routePaths(core.Routes()), "/api/ping")
+	})
+
+	assert.Equal(t, originalConfig.Service.RoutePrefix, app.BaseConfig.Service.RoutePrefix)
+	assert.Equal(t, originalConfig.System, app.BaseConfig.System)
+	assert.Equal(t, originalDB, app.DB)
+	assert.Equal(t, originalRedis, app.Redis)
+	assert.Equal(t, routePaths(originalRoutes), routePaths(core.Routes()))
+}
+
+// routePaths 提取路由路径
+func routePaths(routes []core.RouteInfo) []string {
+	paths := make([]string, 0, len(routes))
+	for _, route := range routes {
+		paths = append(paths, route.Path)
+	}
+	return paths
+}
+
+// TestNewTestEngine_SQLiteAndMiniRedis 测试接入 SQLite 和 miniredis
+//
+// 【功能点】验证处理函数可以通过 app.DB、app.Redis 访问临时数据库和 Redis，PerformJSON 编码请求体，DecodeEnvelope 解析响应
+// 【测试流程】
+//  1. 使用 SQLite（迁移 note 表）和 miniredis 创建引擎，注册创建笔记的路由，创建时递增 Redis 计数
+//  2. 发送 JSON 请求创建两条笔记，断言响应码为成功、响应数据包含笔记 ID
+//  3. 断言数据库中有两条记录，Redis 计数为 2
+func TestNewTestEngine_SQLiteAndMiniRedis(t *testing.T) {
+	engine := NewTestEngine(t,
+		WithMiddlewares("exceptionHandler"),
+		WithSQLite(&note{}),
+		WithMiniRedis(),
+		WithRoutes(func(e *gin.Engine) {
+			e.POST("/notes", func(c *gin.Context) {
+				var n note
+				if err := c.ShouldBindJSON(&n); err != nil {
+					response.FailWithMessage(c, err.Error())
+					return
+				}
+				if err := app.DB.Create(&n).Error; err != nil {
+					response.FailWithMessage(c, err.Error())
+					return
+				}
+				app.Redis.Incr(c, "notes:created")
+				response.OkWithData(c, n)
+			})
+		}),
+	)
+
+	for i, title := range []string{"first", "second"} {
+		w := PerformJSON(t, engine, http.MethodPost, "/notes", map[string]any{"title": title})
+		code, _, data := DecodeEnvelope(t, w)
+		assert.Equal(t, response.ResponseSuccess.GetCode(), code)
+		var created note
+		require.NoError(t, json.Unmarshal(data, &created))
+		assert.Equal(t, uint(i+1), created.ID)
+		assert.Equal(t, title, created.Title)
+	}
+
+	var count int64
+	require.NoError(t, app.DB.Model(&note{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+	created, err := app.Redis.Get(t.Context(), "notes:created").Int()
+	require.NoError(t, err)
+	assert.Equal(t, 2, created)
+}
+
+// inUse 正在使用全局状态的顶层测试数，用于验证并行测试被串行化
+var inUse atomic.Int32
+
+// serializedTest 创建使用独立路由前缀的引擎，等待一段时间后断言全局状态未被其他测试修改
+func serializedTest(t *testing.T, prefix string) {
+	t.Parallel()
+	cfg := config.BaseConfig{}
+	cfg.Service.RoutePrefix = prefix
+	NewTestEngine(t, WithConfig(cfg))
+	assert.Equal(t, int32(1), inUse.Add(1))
+	defer inUse.Add(-1)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, prefix, app.BaseConfig.Service.RoutePrefix)
+}
+
+// TestNewTestEngine_SerializedA 测试并行的顶层测试串行使用全局状态
+//
+// 【功能点】验证调用了 t.Parallel 的两个顶层测试不会同时持有全局状态
+// 【测试流程】与 TestNewTestEngine_SerializedB 并行执行，各自设置不同的路由前缀，等待后断言路由前缀未被修改且同一时间只有一个测试持有全局状态
+func TestNewTestEngine_SerializedA(t *testing.T) {
+	serializedTest(t, "/a")
+}
+
+// TestNewTestEngine_SerializedB 测试并行的顶层测试串行使用全局状态，见 TestNewTestEngine_SerializedA
+func TestNewTestEngine_SerializedB(t *testing.T) {
+	serializedTest(t, "/b")
+}
@@ -22,6 +22,9 @@ var (
 	// ErrLockAlreadyHeld 锁已被持有（用于 TryLock）
 	ErrLockAlreadyHeld = errors.New("lock already held by another client")
 
+	// ErrNotAcquired 锁被其他客户端持有，未执行函数（用于 WithLock），与 ErrLockAlreadyHeld 相同
+	ErrNotAcquired = ErrLockAlreadyHeld
+
 	// ErrClientClosed 客户端已关闭
 	ErrClientClosed = errors.New("lock client is closed")
 )
@@ -111,6 +111,24 @@ func NewRedisLocker(client redis.UniversalClient, opts ...Option) *RedisLocker {
 //	defer lock.Unlock(ctx)
 //	// 执行业务逻辑
 func (r *RedisLocker) TryLock(ctx context.Context, key string) (Lock, error) {
+	return r.TryLockWithTTL(ctx, key, r.config.DefaultTTL)
+}
+
+// TryLockWithTTL 使用指定的过期时间尝试获取锁（非阻塞）
+// 如果锁被其他客户端持有，立即返回 ErrLockAlreadyHeld；启用看门狗时每 ttl/3 续期一次
+//
+// 参数：
+//   - ctx: 上下文
+//   - key: 锁的键名
+//   - ttl: 锁的过期时间，<= 0 时使用 DefaultTTL
+//
+// 返回：
+//   - Lock: 锁对象，获取成功时返回
+//   - error: 获取失败时返回错误
+func (r *RedisLocker) TryLockWithTTL(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	if ttl <= 0 {
+		ttl = r.config.DefaultTTL
+	}
 	r.mu.Lock()
 	if r.closed {
 		r.mu.Unlock()
@@ -121,7 +139,7 @@ func (r *RedisLocker) TryLock(ctx context.Context, key string) (Lock, error) {
 	token := generateToken()
 	fullKey := r.config.KeyPrefix + key
 
-	ok, err := r.acquire(ctx, fullKey, token, r.config.DefaultTTL)
+	ok, err := r.acquire(ctx, fullKey, token, ttl)
 	if err != nil {
 		return nil, fmt.Errorf("【分布式锁】获取锁失败: %w", err)
 	}
@@ -129,7 +147,7 @@ func (r *RedisLocker) TryLock(ctx context.Context, key string) (Lock, error) {
 		return nil, ErrLockAlreadyHeld
 	}
 
-	lock := r.createLock(fullKey, token)
+	lock := r.createLock(fullKey, token, ttl)
 	return lock, nil
 }
 
@@ -192,7 +210,7 @@ func (r *RedisLocker) LockWithRetry(ctx context.Context, key string, retryCount
 			return nil, fmt.Errorf("【分布式锁】获取锁失败: %w", err)
 		}
 		if ok {
-			lock := r.createLock(fullKey, token)
+			lock := r.createLock(fullKey, token, r.config.DefaultTTL)
 			return lock, nil
 		}
 
@@ -219,11 +237,12 @@ func (r *RedisLocker) acquire(ctx context.Context, key, token string, ttl time.D
 }
 
 // createLock 创建锁对象并启动看门狗
-func (r *RedisLocker) createLock(key, token string) *redisLock {
+func (r *RedisLocker) createLock(key, token string, ttl time.Duration) *redisLock {
 	lock := &redisLock{
 		locker: r,
 		key:    key,
 		token:  token,
+		ttl:    ttl,
 	}
 
 	r.mu.Lock()
@@ -276,6 +295,7 @@ type redisLock struct {
 	locker      *RedisLocker
 	key         string
 	token       string
+	ttl         time.Duration // 获取锁时的过期时间，看门狗按此续期
 	mu          sync.Mutex
 	watchdogCtx context.Context
 	watchdogFn  context.CancelFunc
@@ -379,8 +399,13 @@ func (l *redisLock) stopWatchdog() {
 }
 
 // watchdogLoop 看门狗循环
+// 锁的过期时间与 DefaultTTL 相同时按 WatchdogInterval 续期，否则按 ttl/3 续期
 func (l *redisLock) watchdogLoop(ctx context.Context) {
-	ticker := time.NewTicker(l.locker.config.WatchdogInterval)
+	interval := l.locker.config.WatchdogInterval
+	if l.ttl != l.locker.config.DefaultTTL {
+		interval = l.ttl / 3
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -390,7 +415,7 @@ func (l *redisLock) watchdogLoop(ctx context.Context) {
 		case <-l.locker.stopChan:
 			return
 		case <-ticker.C:
-			err := l.Extend(ctx, l.locker.config.DefaultTTL)
+			err := l.Extend(ctx, l.ttl)
 			if err != nil {
 				if l.locker.config.OnWatchdogError != nil {
 					l.locker.config.OnWatchdogError(l.key, l.token, err)
@@ -0,0 +1,103 @@
+// Package distlock 提供分布式锁功能
+// 本文件提供基于 app.Redis 的便捷函数和 WithLock 执行模式
+package distlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zzsen/gin_core/app"
+)
+
+// ErrRedisNotInitialized app.Redis 未初始化
+var ErrRedisNotInitialized = errors.New("redis not initialized, please enable system.useRedis")
+
+// WithLock 获取锁后执行 fn，执行结束后释放锁
+// 锁被其他客户端持有时不等待，立即返回 ErrNotAcquired；执行期间由看门狗每 ttl/3 续期
+//
+// 参数：
+//   - ctx: 上下文，传给 fn
+//   - key: 锁的键名
+//   - ttl: 锁的过期时间，<= 0 时使用 DefaultTTL
+//   - fn: 持有锁期间执行的函数
+//
+// 返回：
+//   - error: 未获取到锁时返回 ErrNotAcquired，fn 返回错误时返回该错误，释放锁时锁已过期（执行期间续期失败）返回 ErrLockNotHeld
+//
+// 使用示例：
+//
+//	err := locker.WithLock(ctx, "report:daily", time.Minute, func(ctx context.Context) error {
+//	    return buildDailyReport(ctx)
+//	})
+//	if errors.Is(err, distlock.ErrNotAcquired) {
+//	    return nil // 其他实例正在执行
+//	}
+func (r *RedisLocker) WithLock(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	lock, err := r.TryLockWithTTL(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+	// 释放锁不受 ctx 取消的影响，避免 fn 因 ctx 取消返回后锁残留到过期
+	unlockCtx := context.WithoutCancel(ctx)
+
+	if err := fn(ctx); err != nil {
+		_ = lock.Unlock(unlockCtx)
+		return err
+	}
+	return lock.Unlock(unlockCtx)
+}
+
+// Acquire 基于 app.Redis 尝试获取锁（非阻塞），锁被其他客户端持有时立即返回 ErrLockAlreadyHeld
+// 启用看门狗（默认启用）时每 ttl/3 续期，直到调用 Unlock 释放锁
+//
+// 参数：
+//   - ctx: 上下文
+//   - key: 锁的键名
+//   - ttl: 锁的过期时间，<= 0 时使用 DefaultTTL
+//   - opts: 配置选项，如 WithKeyPrefix、WithWatchdog
+//
+// 返回：
+//   - Lock: 锁对象
+//   - error: app.Redis 未初始化时返回 ErrRedisNotInitialized，锁被持有时返回 ErrLockAlreadyHeld
+//
+// 使用示例：
+//
+//	lock, err := distlock.Acquire(ctx, "order:"+orderID, 10*time.Second)
+//	if err != nil {
+//	    return err
+//	}
+//	defer lock.Unlock(context.Background())
+func Acquire(ctx context.Context, key string, ttl time.Duration, opts ...Option) (Lock, error) {
+	locker, err := defaultLocker(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return locker.TryLockWithTTL(ctx, key, ttl)
+}
+
+// WithLock 基于 app.Redis 获取锁后执行 fn，执行结束后释放锁，规则同 RedisLocker.WithLock
+//
+// 使用示例：
+//
+//	err := distlock.WithLock(ctx, "report:daily", time.Minute, buildDailyReport)
+//	if errors.Is(err, distlock.ErrNotAcquired) {
+//	    return nil // 其他实例正在执行
+//	}
+func WithLock(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error, opts ...Option) error {
+	locker, err := defaultLocker(opts...)
+	if err != nil {
+		return err
+	}
+	return locker.WithLock(ctx, key, ttl, fn)
+}
+
+// defaultLocker 基于 app.Redis 创建分布式锁客户端
+// 客户端只保存配置，看门狗在锁释放时停止，因此不需要调用 Close
+func defaultLocker(opts ...Option) (*RedisLocker, error) {
+	if app.Redis == nil {
+		return nil, fmt.Errorf("【分布式锁】%w", ErrRedisNotInitialized)
+	}
+	return NewRedisLocker(app.Redis, opts...), nil
+}
@@ -0,0 +1,211 @@
+package distlock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zzsen/gin_core/app"
+)
+
+// ============================================================================
+// WithLock 测试用例
+// ============================================================================
+
+// TestWithLock_MutualExclusion 测试 WithLock 在多个客户端之间互斥
+//
+// 【测试功能点】
+//   - 锁被其他客户端持有时不执行函数，立即返回 ErrNotAcquired
+//   - 函数执行结束后释放锁，其他客户端可以再次获取
+//   - 函数返回的错误原样返回
+//
+// 【测试流程】
+//  1. 创建两个 Locker，模拟两个实例
+//  2. locker1 在 WithLock 中阻塞，期间 locker2 调用 WithLock
+//  3. 验证 locker2 返回 ErrNotAcquired 且未执行函数
+//  4. locker1 结束后，locker2 再次调用并返回自定义错误，验证错误原样返回且锁已释放
+func TestWithLock_MutualExclusion(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer mr.Close()
+
+	locker1 := NewRedisLocker(client, WithWatchdog(false))
+	defer locker1.Close()
+	locker2 := NewRedisLocker(client, WithWatchdog(false))
+	defer locker2.Close()
+
+	ctx := context.Background()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- locker1.WithLock(ctx, "job", time.Second, func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	called := false
+	err := locker2.WithLock(ctx, "job", time.Second, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, ErrNotAcquired) {
+		t.Errorf("期望 ErrNotAcquired，实际: %v", err)
+	}
+	if called {
+		t.Error("未获取到锁时不应执行函数")
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("locker1 执行失败: %v", err)
+	}
+
+	errJob := errors.New("job failed")
+	err = locker2.WithLock(ctx, "job", time.Second, func(ctx context.Context) error {
+		return errJob
+	})
+	if !errors.Is(err, errJob) {
+		t.Errorf("期望返回函数的错误，实际: %v", err)
+	}
+	if mr.Exists(DefaultConfig().KeyPrefix + "job") {
+		t.Error("函数返回错误后锁应被释放")
+	}
+}
+
+// TestWithLock_TokenSafeRelease 测试锁过期后不会误删其他客户端的锁
+//
+// 【测试功能点】
+//   - 执行期间锁过期并被其他客户端获取时，释放锁返回 ErrLockNotHeld
+//   - 其他客户端持有的锁不被删除
+//
+// 【测试流程】
+//  1. locker1 关闭看门狗，在 WithLock 中快进时间使锁过期
+//  2. locker2 在 locker1 执行期间获取同一把锁
+//  3. 验证 locker1 的 WithLock 返回 ErrLockNotHeld
+//  4. 验证 locker2 的锁仍然存在
+func TestWithLock_TokenSafeRelease(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer mr.Close()
+
+	locker1 := NewRedisLocker(client, WithWatchdog(false))
+	defer locker1.Close()
+	locker2 := NewRedisLocker(client, WithWatchdog(false))
+	defer locker2.Close()
+
+	ctx := context.Background()
+	var lock2 Lock
+	err := locker1.WithLock(ctx, "job", 100*time.Millisecond, func(ctx context.Context) error {
+		mr.FastForward(200 * time.Millisecond)
+		var err error
+		lock2, err = locker2.TryLock(ctx, "job")
+		return err
+	})
+	if !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("期望 ErrLockNotHeld，实际: %v", err)
+	}
+
+	if lock2 == nil {
+		t.Fatal("locker2 应获取到过期的锁")
+	}
+	got, err := mr.Get(lock2.Key())
+	if err != nil || got != lock2.Token() {
+		t.Errorf("locker2 的锁不应被删除，实际值: %q, err: %v", got, err)
+	}
+}
+
+// TestTryLockWithTTL_Watchdog 测试看门狗按锁的过期时间续期
+//
+// 【测试功能点】
+//   - TryLockWithTTL 使用指定的过期时间，而不是 DefaultTTL
+//   - 看门狗每 ttl/3 续期一次，续期时恢复为该过期时间
+//
+// 【测试流程】
+//  1. 默认 TTL 为 30s，使用 300ms 的过期时间获取锁
+//  2. 验证锁的 TTL 为 300ms
+//  3. 快进 250ms 后等待超过一个续期间隔（100ms）
+//  4. 验证 TTL 被恢复为 300ms
+func TestTryLockWithTTL_Watchdog(t *testing.T) {
+	mr, client := newTestRedis(t)
+	defer mr.Close()
+
+	locker := NewRedisLocker(client, WithWatchdog(true))
+	defer locker.Close()
+
+	ctx := context.Background()
+	lock, err := locker.TryLockWithTTL(ctx, "job", 300*time.Millisecond)
+	if err != nil {
+		t.Fatalf("获取锁失败: %v", err)
+	}
+	defer lock.Unlock(ctx)
+
+	if ttl := mr.TTL(lock.Key()); ttl != 300*time.Millisecond {
+		t.Errorf("期望 TTL 为 300ms，实际: %v", ttl)
+	}
+
+	mr.FastForward(250 * time.Millisecond)
+	time.Sleep(150 * time.Millisecond)
+
+	if ttl := mr.TTL(lock.Key()); ttl != 300*time.Millisecond {
+		t.Errorf("看门狗续期后期望 TTL 为 300ms，实际: %v", ttl)
+	}
+}
+
+// ============================================================================
+// 包级函数测试用例
+// ============================================================================
+
+// TestAcquire_AppRedis 测试基于 app.Redis 的 Acquire 和 WithLock
+//
+// 【测试功能点】
+//   - app.Redis 未初始化时返回 ErrRedisNotInitialized
+//   - Acquire 获取锁后，WithLock 返回 ErrNotAcquired
+//   - 释放锁后 WithLock 正常执行
+//
+// 【测试流程】
+//  1. app.Redis 为 nil 时调用 Acquire 和 WithLock，验证返回 ErrRedisNotInitialized
+//  2. 设置 app.Redis，调用 Acquire 获取锁
+//  3. 调用 WithLock，验证返回 ErrNotAcquired
+//  4. 释放锁后再次调用 WithLock，验证函数被执行
+func TestAcquire_AppRedis(t *testing.T) {
+	originalRedis := app.Redis
+	defer func() { app.Redis = originalRedis }()
+
+	ctx := context.Background()
+	noop := func(ctx context.Context) error { return nil }
+
+	app.Redis = nil
+	if _, err := Acquire(ctx, "job", time.Second); !errors.Is(err, ErrRedisNotInitialized) {
+		t.Errorf("期望 ErrRedisNotInitialized，实际: %v", err)
+	}
+	if err := WithLock(ctx, "job", time.Second, noop); !errors.Is(err, ErrRedisNotInitialized) {
+		t.Errorf("期望 ErrRedisNotInitialized，实际: %v", err)
+	}
+
+	mr, client := newTestRedis(t)
+	defer mr.Close()
+	app.Redis = client
+
+	lock, err := Acquire(ctx, "job", time.Second, WithWatchdog(false))
+	if err != nil {
+		t.Fatalf("获取锁失败: %v", err)
+	}
+	if err := WithLock(ctx, "job", time.Second, noop); !errors.Is(err, ErrNotAcquired) {
+		t.Errorf("期望 ErrNotAcquired，实际: %v", err)
+	}
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatalf("释放锁失败: %v", err)
+	}
+
+	called := false
+	err = WithLock(ctx, "job", time.Second, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if err != nil || !called {
+		t.Errorf("释放锁后期望执行函数，called: %v, err: %v", called, err)
+	}
+}
@@ -0,0 +1,386 @@
+// Package esindex 提供 Elasticsearch 索引生命周期辅助功能
+// 业务通过别名访问索引，修改映射时以别名当前指向的索引为源重建到带时间戳的新索引，
+// 完成后在一次 update-aliases 请求中原子地切换别名，实现不停机重建索引
+package esindex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	elasticsearch "github.com/elastic/go-elasticsearch/v9"
+	"github.com/zzsen/gin_core/logger"
+)
+
+// 默认重建参数
+const (
+	DefaultBatchSize    = 1000            // 每批读取的文档数
+	DefaultPollInterval = 5 * time.Second // 查询重建任务进度的间隔
+)
+
+// cleanupTimeout 重建失败后取消任务、删除新索引的超时时间
+const cleanupTimeout = 30 * time.Second
+
+// now 获取当前时间，用于生成新索引名称
+var now = time.Now
+
+// Manager 基于别名的索引管理器
+type Manager struct {
+	client *elasticsearch.TypedClient
+}
+
+// New 创建索引管理器
+// 参数：
+//   - client: Elasticsearch 客户端，如 app.ES 或 app.ESByName 的返回值
+//
+// 返回：
+//   - *Manager: 索引管理器
+func New(client *elasticsearch.TypedClient) *Manager {
+	return &Manager{client: client}
+}
+
+// Plan 重建索引的执行计划
+type Plan struct {
+	Alias         string   // 别名
+	SourceIndices []string // 别名当前指向的索引，即重建的数据源
+	NewIndex      string   // 新建的索引
+	Actions       []string // 依次执行的操作说明
+}
+
+// ReindexOption 重建索引选项
+type ReindexOption func(*reindexOptions)
+
+// reindexOptions 重建索引参数
+type reindexOptions struct {
+	slices       int
+	batchSize    int
+	pollInterval time.Duration
+	deleteOld    bool
+	deleteGrace  time.Duration
+	dryRun       *Plan
+}
+
+// WithSlices 设置重建任务的切片数，<= 0 时为 auto（按源索引分片数自动切片）
+func WithSlices(n int) ReindexOption {
+	return func(o *reindexOptions) {
+		o.slices = n
+	}
+}
+
+// WithBatchSize 设置每批读取的文档数，默认 DefaultBatchSize
+func WithBatchSize(n int) ReindexOption {
+	return func(o *reindexOptions) {
+		if n > 0 {
+			o.batchSize = n
+		}
+	}
+}
+
+// WithPollInterval 设置查询重建任务进度的间隔，默认 DefaultPollInterval
+func WithPollInterval(d time.Duration) ReindexOption {
+	return func(o *reindexOptions) {
+		if d > 0 {
+			o.pollInterval = d
+		}
+	}
+}
+
+// WithDeleteOldIndex 别名切换完成并等待 grace 后删除旧索引，等待期间仍在使用旧索引的查询可以完成
+func WithDeleteOldIndex(grace time.Duration) ReindexOption {
+	return func(o *reindexOptions) {
+		o.deleteOld = true
+		o.deleteGrace = max(grace, 0)
+	}
+}
+
+// WithDryRun 只生成执行计划写入 plan，不创建索引、不重建、不切换别名
+func WithDryRun(plan *Plan) ReindexOption {
+	return func(o *reindexOptions) {
+		o.dryRun = plan
+	}
+}
+
+// EnsureAlias 确保别名指向指定索引
+// 别名不存在时为该索引添加别名；别名已指向其他索引时返回错误，切换别名请使用 ReindexWithSwap
+// 参数：
+//   - ctx: context
+//   - alias: 别名
+//   - index: 索引，需已存在
+//
+// 返回：
+//   - error: 别名指向其他索引或请求失败时返回错误
+func (m *Manager) EnsureAlias(ctx context.Context, alias, index string) error {
+	targets, err := m.aliasTargets(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if len(targets) > 0 {
+		if len(targets) == 1 && targets[0] == index {
+			return nil
+		}
+		return fmt.Errorf("[es] 别名 %s 已指向 %v", alias, targets)
+	}
+	if _, err := m.client.Indices.PutAlias(index, alias).Do(ctx); err != nil {
+		return fmt.Errorf("[es] 为索引 %s 添加别名 %s 失败: %w", index, alias, err)
+	}
+	logger.Info("[es] 已为索引 %s 添加别名 %s", index, alias)
+	return nil
+}
+
+// ReindexWithSwap 以别名当前指向的索引为源重建索引，完成后原子地切换别名
+// 依次执行：
+// 1. 以 newIndexSettings（settings / mappings）创建名为 <alias>-<yyyyMMdd-HHmmss> 的新索引
+// 2. 后台执行 _reindex，按 WithPollInterval 的间隔查询并输出进度
+// 3. 刷新新索引，在一次 update-aliases 请求中移除旧索引的别名、为新索引添加别名
+// 4. 配置了 WithDeleteOldIndex 时等待后删除旧索引
+//
+// 切换别名之前任一步骤失败或 ctx 被取消时，取消重建任务并删除新索引，别名保持不变
+//
+// 使用示例：
+//
+//	err := esindex.New(app.ES).ReindexWithSwap(ctx, "products", mapping,
+//		esindex.WithSlices(4), esindex.WithDeleteOldIndex(10*time.Minute))
+//
+// 参数：
+//   - ctx: context，取消后停止等待
+//   - alias: 别名，需已指向至少一个索引
+//   - newIndexSettings: 新索引的创建请求体，为空时使用默认设置
+//   - opts: 重建选项
+//
+// 返回：
+//   - error: 别名切换之前失败时返回错误，此时别名未改变；切换后删除旧索引失败时同样返回错误
+func (m *Manager) ReindexWithSwap(ctx context.Context, alias string, newIndexSettings json.RawMessage, opts ...ReindexOption) error {
+	o := reindexOptions{batchSize: DefaultBatchSize, pollInterval: DefaultPollInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	sources, err := m.aliasTargets(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("[es] 别名 %s 不存在, 请先通过 EnsureAlias 为当前索引添加别名", alias)
+	}
+	plan := newPlan(alias, sources, o)
+	if o.dryRun != nil {
+		*o.dryRun = plan
+		return nil
+	}
+
+	newIndex := plan.NewIndex
+	if err := m.createIndex(ctx, newIndex, newIndexSettings); err != nil {
+		return err
+	}
+	if err := m.reindex(ctx, alias, newIndex, o); err != nil {
+		m.cleanup(newIndex)
+		return err
+	}
+	if _, err := m.client.Indices.Refresh().Index(newIndex).Do(ctx); err != nil {
+		m.cleanup(newIndex)
+		return fmt.Errorf("[es] 刷新索引 %s 失败: %w", newIndex, err)
+	}
+	if err := m.swapAlias(ctx, alias, sources, newIndex); err != nil {
+		m.cleanup(newIndex)
+		return err
+	}
+	logger.Info("[es] 别名 %s 已从 %v 切换到 %s", alias, sources, newIndex)
+
+	if !o.deleteOld {
+		return nil
+	}
+	select {
+	case <-time.After(o.deleteGrace):
+	case <-ctx.Done():
+		return fmt.Errorf("[es] 别名 %s 已切换, 等待删除旧索引 %v 时取消: %w", alias, sources, ctx.Err())
+	}
+	if _, err := m.client.Indices.Delete(strings.Join(sources, ",")).Do(ctx); err != nil {
+		return fmt.Errorf("[es] 别名 %s 已切换, 删除旧索引 %v 失败: %w", alias, sources, err)
+	}
+	logger.Info("[es] 已删除旧索引 %v", sources)
+	return nil
+}
+
+// newPlan 生成执行计划
+func newPlan(alias string, sources []string, o reindexOptions) Plan {
+	newIndex := alias + "-" + now().Format("20060102-150405")
+	plan := Plan{Alias: alias, SourceIndices: sources, NewIndex: newIndex}
+	plan.Actions = append(plan.Actions,
+		fmt.Sprintf("创建索引 %s", newIndex),
+		fmt.Sprintf("重建索引 %s -> %s (slices: %s, size: %d)", alias, newIndex, slicesParam(o.slices), o.batchSize),
+		fmt.Sprintf("刷新索引 %s", newIndex),
+		fmt.Sprintf("切换别名 %s: 移除 %v, 添加 %s", alias, sources, newIndex),
+	)
+	if o.deleteOld {
+		plan.Actions = append(plan.Actions, fmt.Sprintf("等待 %s 后删除旧索引 %v", o.deleteGrace, sources))
+	}
+	return plan
+}
+
+// aliasTargets 获取别名指向的索引，按名称排序，别名不存在时返回空
+func (m *Manager) aliasTargets(ctx context.Context, alias string) ([]string, error) {
+	exists, err := m.client.Indices.ExistsAlias(alias).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[es] 查询别名 %s 失败: %w", alias, err)
+	}
+	if !exists {
+		return nil, nil
+	}
+	resp, err := m.client.Indices.GetAlias().Name(alias).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[es] 查询别名 %s 失败: %w", alias, err)
+	}
+	targets := make([]string, 0, len(resp))
+	for index := range resp {
+		targets = append(targets, index)
+	}
+	slices.Sort(targets)
+	return targets, nil
+}
+
+// createIndex 创建新索引
+func (m *Manager) createIndex(ctx context.Context, index string, settings json.RawMessage) error {
+	req := m.client.Indices.Create(index)
+	if len(bytes.TrimSpace(settings)) > 0 {
+		req = req.Raw(bytes.NewReader(settings))
+	}
+	if _, err := req.Do(ctx); err != nil {
+		return fmt.Errorf("[es] 创建索引 %s 失败: %w", index, err)
+	}
+	logger.Info("[es] 已创建索引 %s", index)
+	return nil
+}
+
+// reindexStatus 重建任务的进度
+type reindexStatus struct {
+	Total   int64 `json:"total"`
+	Created int64 `json:"created"`
+	Updated int64 `json:"updated"`
+}
+
+// reindexResult 重建任务完成后的结果
+type reindexResult struct {
+	TimedOut bool              `json:"timed_out"`
+	Failures []json.RawMessage `json:"failures"`
+}
+
+// reindex 后台执行 _reindex 并等待完成，ctx 取消时返回错误，由调用方取消任务
+func (m *Manager) reindex(ctx context.Context, alias, newIndex string, o reindexOptions) error {
+	body, err := json.Marshal(map[string]any{
+		"source": map[string]any{"index": alias, "size": o.batchSize},
+		"dest":   map[string]any{"index": newIndex},
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := m.client.Reindex().Raw(bytes.NewReader(body)).WaitForCompletion(false).Slices(slicesParam(o.slices)).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("[es] 提交重建任务 %s -> %s 失败: %w", alias, newIndex, err)
+	}
+	if resp.Task == nil {
+		return fmt.Errorf("[es] 提交重建任务 %s -> %s 未返回任务 ID", alias, newIndex)
+	}
+	taskID := *resp.Task
+	logger.Info("[es] 重建任务已提交, %s -> %s, task: %s", alias, newIndex, taskID)
+
+	ticker := time.NewTicker(o.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return m.cancelTask(taskID, ctx.Err())
+		case <-ticker.C:
+		}
+		task, err := m.client.Tasks.Get(taskID).Do(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return m.cancelTask(taskID, ctx.Err())
+			}
+			return m.cancelTask(taskID, fmt.Errorf("[es] 查询重建任务 %s 失败: %w", taskID, err))
+		}
+		var status reindexStatus
+		_ = json.Unmarshal(task.Task.Status, &status)
+		if !task.Completed {
+			logger.Info("[es] 重建索引进度 %s -> %s, task: %s, %d/%d", alias, newIndex, taskID, status.Created+status.Updated, status.Total)
+			continue
+		}
+		if task.Error != nil {
+			return fmt.Errorf("[es] 重建任务 %s 失败: %s", taskID, errorReason(task.Error.Reason, task.Error.Type))
+		}
+		var result reindexResult
+		if len(task.Response) > 0 {
+			if err := json.Unmarshal(task.Response, &result); err != nil {
+				return fmt.Errorf("[es] 解析重建任务 %s 结果失败: %w", taskID, err)
+			}
+		}
+		if len(result.Failures) > 0 {
+			return fmt.Errorf("[es] 重建任务 %s 失败, 失败文档数: %d, 首个失败: %s", taskID, len(result.Failures), result.Failures[0])
+		}
+		if result.TimedOut {
+			return fmt.Errorf("[es] 重建任务 %s 超时", taskID)
+		}
+		logger.Info("[es] 重建任务已完成 %s -> %s, task: %s, 文档数: %d", alias, newIndex, taskID, status.Total)
+		return nil
+	}
+}
+
+// cancelTask 取消重建任务，返回 cause 与取消失败的错误
+func (m *Manager) cancelTask(taskID string, cause error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	if _, err := m.client.Tasks.Cancel().TaskId(taskID).Do(ctx); err != nil {
+		return errors.Join(cause, fmt.Errorf("[es] 取消重建任务 %s 失败: %w", taskID, err))
+	}
+	logger.Warn("[es] 已取消重建任务 %s", taskID)
+	return cause
+}
+
+// swapAlias 在一次 update-aliases 请求中移除旧索引的别名并为新索引添加别名
+func (m *Manager) swapAlias(ctx context.Context, alias string, sources []string, newIndex string) error {
+	actions := make([]map[string]any, 0, len(sources)+1)
+	for _, index := range sources {
+		actions = append(actions, map[string]any{"remove": map[string]string{"index": index, "alias": alias}})
+	}
+	actions = append(actions, map[string]any{"add": map[string]string{"index": newIndex, "alias": alias}})
+	body, err := json.Marshal(map[string]any{"actions": actions})
+	if err != nil {
+		return err
+	}
+	if _, err := m.client.Indices.UpdateAliases().Raw(bytes.NewReader(body)).Do(ctx); err != nil {
+		return fmt.Errorf("[es] 切换别名 %s 失败: %w", alias, err)
+	}
+	return nil
+}
+
+// cleanup 删除重建失败的新索引，失败时只记录日志
+func (m *Manager) cleanup(index string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	if _, err := m.client.Indices.Delete(index).Do(ctx); err != nil {
+		logger.Error("[es] 删除重建失败的索引 %s 失败: %v", index, err)
+		return
+	}
+	logger.Warn("[es] 已删除重建失败的索引 %s", index)
+}
+
+// slicesParam 转换切片数参数，<= 0 时为 auto
+func slicesParam(n int) string {
+	if n <= 0 {
+		return "auto"
+	}
+	return strconv.Itoa(n)
+}
+
+// errorReason 拼接 ES 错误原因
+func errorReason(reason *string, errType string) string {
+	if reason == nil {
+		return errType
+	}
+	return errType + ": " + *reason
+}
@@ -0,0 +1,83 @@
+//go:build integration
+
+// Package esindex 索引生命周期辅助功能集成测试
+//
+// ==================== 集成测试说明 ====================
+// 本文件需要真实的 Elasticsearch 服务才能运行，可通过以下命令启动：
+//
+//	docker run -d -p 9200:9200 -e discovery.type=single-node -e xpack.security.enabled=false elasticsearch:9.2.1
+//
+// 运行命令：go test -tags=integration ./esindex/...
+//
+// 测试覆盖内容：
+// 1. EnsureAlias 为初始索引添加别名
+// 2. ReindexWithSwap 重建后文档数一致，别名指向新索引并按新映射查询，旧索引被删除
+//
+// 配置说明：
+// - 连接 http://127.0.0.1:9200，使用 gin-core-it-products 前缀的索引，测试结束后删除
+// - 如果无法连接 Elasticsearch，测试将失败（而非跳过）
+// ==================================================
+package esindex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	elasticsearch "github.com/elastic/go-elasticsearch/v9"
+	"github.com/elastic/go-elasticsearch/v9/typedapi/types/enums/refresh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// integrationAlias 集成测试使用的别名
+const integrationAlias = "gin-core-it-products"
+
+// TestIntegration_ReindexWithSwap 测试真实集群上的不停机重建
+//
+// 【功能点】验证别名切换前后数据完整，切换后旧索引被删除
+// 【测试流程】
+//  1. 创建 v1 索引写入 50 条文档，通过 EnsureAlias 添加别名
+//  2. 以 name 为 keyword 的新映射调用 ReindexWithSwap（删除旧索引，宽限期 0）
+//  3. 断言别名只指向一个新索引、文档数为 50、v1 索引已删除
+func TestIntegration_ReindexWithSwap(t *testing.T) {
+	client, err := elasticsearch.NewTypedClient(elasticsearch.Config{Addresses: []string{"http://127.0.0.1:9200"}})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	t.Cleanup(func() {
+		_, _ = client.Indices.Delete(integrationAlias + "-*").Do(context.Background())
+	})
+
+	v1 := integrationAlias + "-v1"
+	_, err = client.Indices.Create(v1).Do(ctx)
+	require.NoError(t, err)
+	var bulk bytes.Buffer
+	for i := range 50 {
+		fmt.Fprintf(&bulk, "{\"index\":{\"_id\":\"%d\"}}\n{\"name\":\"product-%d\"}\n", i, i)
+	}
+	_, err = client.Bulk().Index(v1).Raw(&bulk).Refresh(refresh.True).Do(ctx)
+	require.NoError(t, err)
+
+	m := New(client)
+	require.NoError(t, m.EnsureAlias(ctx, integrationAlias, v1))
+
+	mapping := json.RawMessage(`{"mappings":{"properties":{"name":{"type":"keyword"}}}}`)
+	require.NoError(t, m.ReindexWithSwap(ctx, integrationAlias, mapping, WithPollInterval(200*time.Millisecond), WithDeleteOldIndex(0)))
+
+	targets, err := m.aliasTargets(ctx, integrationAlias)
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.NotEqual(t, v1, targets[0])
+
+	count, err := client.Count().Index(integrationAlias).Do(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), count.Count)
+
+	exists, err := client.Indices.Exists(v1).Do(ctx)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
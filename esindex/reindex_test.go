@@ -0,0 +1,339 @@
+// Package esindex 索引生命周期辅助功能测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含别名管理和不停机重建索引的单元测试，使用 httptest 模拟 Elasticsearch 的
+// 别名、索引、_reindex 和任务接口，不需要真实的 Elasticsearch。
+//
+// 测试覆盖内容：
+// 1. EnsureAlias 在别名不存在时添加别名，已指向同一索引时不做修改，指向其他索引时返回错误
+// 2. ReindexWithSwap 创建带时间戳的新索引、按切片和批大小提交重建任务、等待完成后在一次请求中切换别名并删除旧索引
+// 3. 重建任务失败或 ctx 取消时取消任务、删除新索引，别名保持不变
+// 4. dry-run 只返回执行计划，不发送写请求
+//
+// 运行测试：go test -v ./esindex/...
+// ==================================================
+package esindex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	elasticsearch "github.com/elastic/go-elasticsearch/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRequest 模拟服务收到的请求
+type stubRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// esStub 模拟 Elasticsearch 的别名、索引、重建和任务接口
+type esStub struct {
+	mu       sync.Mutex
+	indices  map[string]bool     // 已存在的索引
+	aliases  map[string][]string // 别名 -> 指向的索引
+	requests []stubRequest
+	// taskPolls 重建任务在第几次查询时完成，<= 0 时永不完成
+	taskPolls int
+	polled    int
+	// taskFailure 重建任务完成时返回的失败文档，为空表示成功
+	taskFailure string
+	canceled    bool
+}
+
+// newESStub 启动模拟服务并创建指向它的客户端
+func newESStub(t *testing.T, stub *esStub) *Manager {
+	if stub.indices == nil {
+		stub.indices = map[string]bool{}
+	}
+	if stub.aliases == nil {
+		stub.aliases = map[string][]string{}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(stub.serve))
+	t.Cleanup(srv.Close)
+	client, err := elasticsearch.NewTypedClient(elasticsearch.Config{Addresses: []string{srv.URL}})
+	require.NoError(t, err)
+	return New(client)
+}
+
+// serve 处理请求，响应格式与 Elasticsearch 一致
+func (s *esStub) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, stubRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: string(body)})
+
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	w.Header().Set("Content-Type", "application/json")
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	ok := func(v any) { _ = json.NewEncoder(w).Encode(v) }
+	fail := func(status int, reason string) {
+		w.WriteHeader(status)
+		ok(map[string]any{"error": map[string]any{"type": "stub_exception", "reason": reason}, "status": status})
+	}
+
+	switch {
+	case parts[0] == "_alias" && len(parts) == 2:
+		targets := s.aliases[parts[1]]
+		if len(targets) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				ok(map[string]any{"error": "alias [" + parts[1] + "] missing", "status": 404})
+			}
+			return
+		}
+		resp := map[string]any{}
+		for _, index := range targets {
+			resp[index] = map[string]any{"aliases": map[string]any{parts[1]: map[string]any{}}}
+		}
+		ok(resp)
+	case parts[0] == "_reindex":
+		ok(map[string]any{"task": "node-1:42"})
+	case parts[0] == "_tasks" && len(parts) == 3 && parts[2] == "_cancel":
+		s.canceled = true
+		ok(map[string]any{"nodes": map[string]any{}})
+	case parts[0] == "_tasks":
+		s.polled++
+		status := map[string]any{"total": 100, "created": min(s.polled*40, 100), "updated": 0}
+		task := map[string]any{"node": "node-1", "id": 42, "type": "transport", "action": "indices:data/write/reindex",
+			"start_time_in_millis": 0, "running_time_in_nanos": 0, "cancellable": true, "status": status}
+		if s.taskPolls <= 0 || s.polled < s.taskPolls {
+			ok(map[string]any{"completed": false, "task": task})
+			return
+		}
+		var failures []any
+		if s.taskFailure != "" {
+			failures = append(failures, map[string]any{"index": "x", "id": "1", "cause": map[string]any{"type": "mapper_parsing_exception", "reason": s.taskFailure}, "status": 400})
+		}
+		ok(map[string]any{"completed": true, "task": task, "response": map[string]any{"total": 100, "created": 100, "timed_out": false, "failures": failures}})
+	case parts[0] == "_aliases":
+		var req struct {
+			Actions []map[string]map[string]string `json:"actions"`
+		}
+		_ = json.Unmarshal(body, &req)
+		for _, action := range req.Actions {
+			if a, found := action["remove"]; found {
+				s.aliases[a["alias"]] = slices.DeleteFunc(s.aliases[a["alias"]], func(index string) bool { return index == a["index"] })
+			}
+			if a, found := action["add"]; found {
+				s.aliases[a["alias"]] = append(s.aliases[a["alias"]], a["index"])
+			}
+		}
+		ok(map[string]any{"acknowledged": true})
+	case len(parts) == 2 && parts[1] == "_refresh":
+		ok(map[string]any{"_shards": map[string]any{"total": 1, "successful": 1, "failed": 0}})
+	case len(parts) == 3 && parts[1] == "_alias":
+		if !s.indices[parts[0]] {
+			fail(http.StatusNotFound, "no such index ["+parts[0]+"]")
+			return
+		}
+		s.aliases[parts[2]] = append(s.aliases[parts[2]], parts[0])
+		ok(map[string]any{"acknowledged": true})
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		if s.indices[parts[0]] {
+			fail(http.StatusBadRequest, "index ["+parts[0]+"] already exists")
+			return
+		}
+		s.indices[parts[0]] = true
+		ok(map[string]any{"acknowledged": true, "shards_acknowledged": true, "index": parts[0]})
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		for _, index := range strings.Split(parts[0], ",") {
+			delete(s.indices, index)
+		}
+		ok(map[string]any{"acknowledged": true})
+	default:
+		fail(http.StatusBadRequest, "unexpected request "+r.Method+" "+r.URL.Path)
+	}
+}
+
+// find 返回第一个匹配方法和路径前缀的请求
+func (s *esStub) find(method, pathPrefix string) (stubRequest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, req := range s.requests {
+		if req.Method == method && strings.HasPrefix(req.Path, pathPrefix) {
+			return req, true
+		}
+	}
+	return stubRequest{}, false
+}
+
+// fixNow 固定新索引名称中的时间
+func fixNow(t *testing.T) {
+	original := now
+	now = func() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local) }
+	t.Cleanup(func() { now = original })
+}
+
+// TestEnsureAlias 测试确保别名指向索引
+//
+// 【功能点】验证别名不存在时添加，已指向同一索引时不修改，指向其他索引时返回错误
+// 【测试流程】
+//  1. 索引 products-v1 存在、别名不存在，调用 EnsureAlias，断言别名指向 products-v1
+//  2. 再次调用断言成功且没有新的添加别名请求
+//  3. 对索引 products-v2 调用，断言返回错误且别名不变
+func TestEnsureAlias(t *testing.T) {
+	stub := &esStub{indices: map[string]bool{"products-v1": true, "products-v2": true}}
+	m := newESStub(t, stub)
+	ctx := context.Background()
+
+	require.NoError(t, m.EnsureAlias(ctx, "products", "products-v1"))
+	assert.Equal(t, []string{"products-v1"}, stub.aliases["products"])
+
+	require.NoError(t, m.EnsureAlias(ctx, "products", "products-v1"))
+	puts := 0
+	for _, req := range stub.requests {
+		if req.Method == http.MethodPut {
+			puts++
+		}
+	}
+	assert.Equal(t, 1, puts)
+
+	err := m.EnsureAlias(ctx, "products", "products-v2")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "products-v1")
+	assert.Equal(t, []string{"products-v1"}, stub.aliases["products"])
+}
+
+// TestReindexWithSwap_Success 测试重建索引并切换别名
+//
+// 【功能点】验证新索引名称带时间戳、重建请求使用配置的切片和批大小、完成后原子切换别名并删除旧索引
+// 【测试流程】
+//  1. 别名 products 指向 products-v1，重建任务在第 3 次查询时完成
+//  2. 以 WithSlices(2)、WithBatchSize(500)、WithDeleteOldIndex(0) 调用 ReindexWithSwap
+//  3. 断言新索引 products-20240601-120000 以传入的设置创建，重建请求参数正确
+//  4. 断言只发送一次 update-aliases 请求且同时包含 remove 和 add，别名指向新索引，旧索引被删除
+func TestReindexWithSwap_Success(t *testing.T) {
+	fixNow(t)
+	stub := &esStub{
+		indices:   map[string]bool{"products-v1": true},
+		aliases:   map[string][]string{"products": {"products-v1"}},
+		taskPolls: 3,
+	}
+	m := newESStub(t, stub)
+	settings := json.RawMessage(`{"mappings":{"properties":{"name":{"type":"keyword"}}}}`)
+
+	err := m.ReindexWithSwap(context.Background(), "products", settings,
+		WithSlices(2), WithBatchSize(500), WithPollInterval(10*time.Millisecond), WithDeleteOldIndex(0))
+	require.NoError(t, err)
+
+	create, found := stub.find(http.MethodPut, "/products-20240601-120000")
+	require.True(t, found)
+	assert.JSONEq(t, string(settings), create.Body)
+
+	reindex, found := stub.find(http.MethodPost, "/_reindex")
+	require.True(t, found)
+	assert.Contains(t, reindex.Query, "wait_for_completion=false")
+	assert.Contains(t, reindex.Query, "slices=2")
+	assert.JSONEq(t, `{"source":{"index":"products","size":500},"dest":{"index":"products-20240601-120000"}}`, reindex.Body)
+	assert.Equal(t, 3, stub.polled)
+
+	swap, found := stub.find(http.MethodPost, "/_aliases")
+	require.True(t, found)
+	assert.JSONEq(t, `{"actions":[{"remove":{"index":"products-v1","alias":"products"}},{"add":{"index":"products-20240601-120000","alias":"products"}}]}`, swap.Body)
+	assert.Equal(t, []string{"products-20240601-120000"}, stub.aliases["products"])
+	assert.False(t, stub.indices["products-v1"])
+	assert.True(t, stub.indices["products-20240601-120000"])
+	assert.False(t, stub.canceled)
+}
+
+// TestReindexWithSwap_TaskFailure 测试重建任务失败
+//
+// 【功能点】验证重建任务返回失败文档时返回错误，新索引被删除，别名和旧索引保持不变
+// 【测试流程】模拟任务完成但包含失败文档，断言错误包含失败原因、没有 update-aliases 请求、别名仍指向旧索引、新索引已删除
+func TestReindexWithSwap_TaskFailure(t *testing.T) {
+	fixNow(t)
+	stub := &esStub{
+		indices:     map[string]bool{"products-v1": true},
+		aliases:     map[string][]string{"products": {"products-v1"}},
+		taskPolls:   1,
+		taskFailure: "failed to parse field [price]",
+	}
+	m := newESStub(t, stub)
+
+	err := m.ReindexWithSwap(context.Background(), "products", nil, WithPollInterval(10*time.Millisecond), WithDeleteOldIndex(0))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse field [price]")
+
+	_, found := stub.find(http.MethodPost, "/_aliases")
+	assert.False(t, found)
+	assert.Equal(t, []string{"products-v1"}, stub.aliases["products"])
+	assert.True(t, stub.indices["products-v1"])
+	assert.False(t, stub.indices["products-20240601-120000"])
+}
+
+// TestReindexWithSwap_ContextCanceled 测试重建过程中取消
+//
+// 【功能点】验证等待重建任务时 ctx 结束会取消任务、删除新索引，别名保持不变
+// 【测试流程】模拟永不完成的任务，使用 100ms 超时的 ctx 调用，断言返回 context.DeadlineExceeded、任务被取消、别名未切换、新索引已删除
+func TestReindexWithSwap_ContextCanceled(t *testing.T) {
+	fixNow(t)
+	stub := &esStub{
+		indices: map[string]bool{"products-v1": true},
+		aliases: map[string][]string{"products": {"products-v1"}},
+	}
+	m := newESStub(t, stub)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := m.ReindexWithSwap(ctx, "products", nil, WithPollInterval(10*time.Millisecond))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	assert.True(t, stub.canceled)
+	assert.Equal(t, []string{"products-v1"}, stub.aliases["products"])
+	assert.False(t, stub.indices["products-20240601-120000"])
+}
+
+// TestReindexWithSwap_DryRun 测试只生成执行计划
+//
+// 【功能点】验证 dry-run 返回源索引、新索引和操作列表，不发送任何写请求
+// 【测试流程】别名指向两个索引，以 WithDryRun 调用，断言计划内容正确，所有请求均为 HEAD / GET
+func TestReindexWithSwap_DryRun(t *testing.T) {
+	fixNow(t)
+	stub := &esStub{
+		indices: map[string]bool{"products-a": true, "products-b": true},
+		aliases: map[string][]string{"products": {"products-b", "products-a"}},
+	}
+	m := newESStub(t, stub)
+
+	var plan Plan
+	require.NoError(t, m.ReindexWithSwap(context.Background(), "products", nil, WithDryRun(&plan), WithDeleteOldIndex(time.Minute)))
+	assert.Equal(t, "products", plan.Alias)
+	assert.Equal(t, []string{"products-a", "products-b"}, plan.SourceIndices)
+	assert.Equal(t, "products-20240601-120000", plan.NewIndex)
+	require.Len(t, plan.Actions, 5)
+	assert.Contains(t, plan.Actions[1], "slices: auto")
+	assert.Contains(t, plan.Actions[4], "1m0s")
+
+	for _, req := range stub.requests {
+		assert.Contains(t, []string{http.MethodHead, http.MethodGet}, req.Method, req.Path)
+	}
+}
+
+// TestReindexWithSwap_AliasMissing 测试别名不存在
+//
+// 【功能点】验证别名不存在时返回错误且不创建索引
+// 【测试流程】不设置别名调用 ReindexWithSwap，断言返回错误且没有创建索引的请求
+func TestReindexWithSwap_AliasMissing(t *testing.T) {
+	stub := &esStub{}
+	m := newESStub(t, stub)
+
+	err := m.ReindexWithSwap(context.Background(), "products", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "EnsureAlias")
+	assert.Empty(t, stub.indices)
+}
@@ -0,0 +1,416 @@
+// Package events 提供进程内的事件总线
+//
+// 用于解耦模块之间的直接调用（如用户服务注册成功后发送邮件、写审计日志、清除缓存）：
+// 发布方只发布主题和数据，不依赖订阅方；订阅方按主题（支持通配符）订阅，选择同步或异步处理。
+//
+//   - 同步处理函数在 Publish 的协程中按订阅顺序依次执行，错误合并后返回给发布方
+//   - 异步处理函数投递到有界队列，由固定数量的协程执行，不保证执行顺序；队列已满时丢弃事件，计数并记录日志
+//
+// 处理函数的 panic 被捕获并记录，不影响其他处理函数；每个处理函数的 ctx 带有超时时间。
+// 应用关闭时总线停止接收异步事件，在关闭超时时间内执行完队列中的事件。
+// 事件只保存在内存中，进程异常退出时会丢失，需要可靠投递的场景请使用发件箱（outbox）或消息队列。
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zzsen/gin_core/logger"
+)
+
+var (
+	// ErrQueueFull 异步事件队列已满，事件被丢弃
+	ErrQueueFull = errors.New("异步事件队列已满")
+	// ErrBusClosed 事件总线已关闭，不再接收异步事件
+	ErrBusClosed = errors.New("事件总线已关闭")
+)
+
+// Handler 事件处理函数
+// ctx 在处理超时或总线关闭超时后取消，处理函数应据此及时退出；订阅通配符主题时可通过 Topic(ctx) 获取实际主题
+type Handler func(ctx context.Context, payload any) error
+
+// Options 事件总线配置
+type Options struct {
+	// Workers 执行异步处理函数的协程数，默认 4
+	Workers int
+	// QueueSize 异步事件队列容量，默认 1000
+	QueueSize int
+	// Timeout 处理函数的默认超时时间，默认 30s，可通过 WithTimeout 为单个处理函数设置
+	Timeout time.Duration
+}
+
+// withDefaults 补全未配置的选项
+func (o Options) withDefaults() Options {
+	if o.Workers <= 0 {
+		o.Workers = 4
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1000
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 30 * time.Second
+	}
+	return o
+}
+
+// Stats 事件总线统计
+type Stats struct {
+	// Published 发布的事件数
+	Published uint64 `json:"published"`
+	// Delivered 处理成功的次数（每个处理函数处理一次计一次）
+	Delivered uint64 `json:"delivered"`
+	// Failed 处理失败的次数，包括返回错误、panic 和超时
+	Failed uint64 `json:"failed"`
+	// Dropped 因队列已满或总线已关闭而丢弃的异步处理次数
+	Dropped uint64 `json:"dropped"`
+	// Pending 队列中尚未开始处理的异步事件数
+	Pending int `json:"pending"`
+}
+
+// subscription 订阅信息
+type subscription struct {
+	pattern topicPattern
+	handler Handler
+	name    string
+	async   bool
+	timeout time.Duration
+}
+
+// SubOption 订阅选项
+type SubOption func(*subscription)
+
+// Async 异步处理：事件投递到有界队列，由总线的协程执行，Publish 不等待处理完成
+func Async() SubOption {
+	return func(s *subscription) { s.async = true }
+}
+
+// WithTimeout 设置处理函数的超时时间，覆盖总线的默认超时时间
+func WithTimeout(timeout time.Duration) SubOption {
+	return func(s *subscription) {
+		if timeout > 0 {
+			s.timeout = timeout
+		}
+	}
+}
+
+// WithName 设置处理函数名称，用于日志，默认为处理函数的函数名
+func WithName(name string) SubOption {
+	return func(s *subscription) {
+		if name != "" {
+			s.name = name
+		}
+	}
+}
+
+// delivery 异步队列中的一次投递
+type delivery struct {
+	ctx     context.Context
+	topic   string
+	payload any
+	sub     *subscription
+}
+
+// Bus 事件总线
+// 订阅可以在总线启动前完成；执行异步处理函数的协程在第一次投递异步事件时启动
+type Bus struct {
+	subMu sync.RWMutex
+	subs  []*subscription
+
+	// queueMu 保护 opts、queue 的创建、关闭以及向 queue 发送，关闭时持写锁关闭 queue，避免向已关闭的通道发送
+	queueMu sync.RWMutex
+	opts    Options
+	queue   chan delivery
+	closed  bool
+
+	// baseCtx 关闭超时后取消，cancel 与 done 对应当前的处理协程，Configure 重新打开时重新创建
+	baseCtx context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	published atomic.Uint64
+	delivered atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// NewBus 创建事件总线
+// 参数：
+//   - opts: 总线配置，未配置的选项使用默认值
+//
+// 返回：
+//   - *Bus: 事件总线实例
+func NewBus(opts Options) *Bus {
+	baseCtx, cancel := context.WithCancel(context.Background())
+	return &Bus{opts: opts.withDefaults(), baseCtx: baseCtx, cancel: cancel}
+}
+
+// Subscribe 订阅主题
+// 主题由 . 分隔为多段，pattern 中的 * 匹配任意一段，末尾的 # 匹配剩余的零段或多段，
+// 如 user.* 匹配 user.created，不匹配 user.profile.updated；user.# 匹配两者以及 user
+// 参数：
+//   - pattern: 主题或通配符主题
+//   - handler: 处理函数
+//   - opts: 订阅选项，如 Async()、WithTimeout(5*time.Second)
+//
+// 返回：
+//   - error: 主题格式错误或处理函数为 nil 时返回错误
+func (b *Bus) Subscribe(pattern string, handler Handler, opts ...SubOption) error {
+	if handler == nil {
+		return fmt.Errorf("订阅主题 %s 失败: 处理函数不能为空", pattern)
+	}
+	p, err := parseTopicPattern(pattern)
+	if err != nil {
+		return fmt.Errorf("订阅主题 %s 失败: %w", pattern, err)
+	}
+	sub := &subscription{pattern: p, handler: handler, name: handlerName(handler)}
+	for _, opt := range opts {
+		opt(sub)
+	}
+
+	b.subMu.Lock()
+	b.subs = append(b.subs, sub)
+	b.subMu.Unlock()
+	return nil
+}
+
+// Publish 发布事件
+// 同步处理函数按订阅顺序在当前协程中依次执行，一个处理函数失败不影响后续处理函数；
+// 异步处理函数的 ctx 继承 ctx 中的值（如追踪 ID），但不继承其取消：请求结束后仍会处理
+// 参数：
+//   - ctx: 发布上下文
+//   - topic: 主题，如 user.created
+//   - payload: 事件数据，异步处理时在多个协程中共享，处理函数不应修改
+//
+// 返回：
+//   - error: 同步处理函数的错误，以及异步事件被丢弃时的 ErrQueueFull / ErrBusClosed，多个错误合并返回
+func (b *Bus) Publish(ctx context.Context, topic string, payload any) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	b.published.Add(1)
+
+	b.subMu.RLock()
+	subs := make([]*subscription, 0, len(b.subs))
+	for _, sub := range b.subs {
+		if sub.pattern.match(topic) {
+			subs = append(subs, sub)
+		}
+	}
+	b.subMu.RUnlock()
+
+	var errs []error
+	for _, sub := range subs {
+		if sub.async {
+			if err := b.enqueue(delivery{ctx: context.WithoutCancel(ctx), topic: topic, payload: payload, sub: sub}); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", sub.name, err))
+			}
+			continue
+		}
+		if err := b.handle(ctx, topic, payload, sub); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sub.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Configure 设置总线配置，总线已关闭时重新打开
+// 订阅保持不变，之后投递的异步事件使用新的协程数和队列容量
+// 参数：
+//   - opts: 总线配置，未配置的选项使用默认值
+//
+// 返回：
+//   - error: 异步处理协程已启动且总线未关闭时返回错误
+func (b *Bus) Configure(opts Options) error {
+	b.queueMu.Lock()
+	defer b.queueMu.Unlock()
+	if b.queue != nil && !b.closed {
+		return errors.New("事件总线已启动, 不能修改配置")
+	}
+	b.opts = opts.withDefaults()
+	if b.closed {
+		b.closed = false
+		b.queue = nil
+		b.done = nil
+		b.baseCtx, b.cancel = context.WithCancel(context.Background())
+	}
+	return nil
+}
+
+// Stats 获取事件总线统计
+func (b *Bus) Stats() Stats {
+	stats := Stats{
+		Published: b.published.Load(),
+		Delivered: b.delivered.Load(),
+		Failed:    b.failed.Load(),
+		Dropped:   b.dropped.Load(),
+	}
+	b.queueMu.RLock()
+	if b.queue != nil {
+		stats.Pending = len(b.queue)
+	}
+	b.queueMu.RUnlock()
+	return stats
+}
+
+// Shutdown 关闭事件总线
+// 立即停止接收异步事件（同步处理函数不受影响），等待队列中的事件处理完毕；
+// ctx 结束时取消正在执行的处理函数，放弃尚未开始的处理并返回其数量
+// 参数：
+//   - ctx: 关闭上下文，通常带有关闭超时时间
+//
+// 返回：
+//   - int: 放弃的异步处理次数
+//   - error: ctx 结束前未处理完时返回包装了 ctx.Err() 的错误
+func (b *Bus) Shutdown(ctx context.Context) (int, error) {
+	b.queueMu.Lock()
+	if !b.closed {
+		b.closed = true
+		if b.queue != nil {
+			close(b.queue)
+		}
+	}
+	queue, done, cancel := b.queue, b.done, b.cancel
+	b.queueMu.Unlock()
+
+	if done == nil {
+		return 0, nil
+	}
+	select {
+	case <-done:
+		return 0, nil
+	case <-ctx.Done():
+	}
+
+	cancel()
+	abandoned := 0
+	if queue != nil {
+		abandoned = len(queue)
+	}
+	return abandoned, fmt.Errorf("等待异步事件处理完毕超时, 放弃 %d 次处理: %w", abandoned, ctx.Err())
+}
+
+// enqueue 将异步处理投递到队列，队列已满或总线已关闭时丢弃并记录日志
+func (b *Bus) enqueue(d delivery) error {
+	b.queueMu.RLock()
+	if b.queue == nil && !b.closed {
+		b.queueMu.RUnlock()
+		b.start()
+		b.queueMu.RLock()
+	}
+	defer b.queueMu.RUnlock()
+
+	if b.closed {
+		b.drop(d, ErrBusClosed)
+		return ErrBusClosed
+	}
+	select {
+	case b.queue <- d:
+		return nil
+	default:
+		b.drop(d, ErrQueueFull)
+		return ErrQueueFull
+	}
+}
+
+// start 创建异步队列并启动处理协程，已启动或已关闭时不做任何处理
+func (b *Bus) start() {
+	b.queueMu.Lock()
+	defer b.queueMu.Unlock()
+	if b.queue != nil || b.closed {
+		return
+	}
+	b.queue = make(chan delivery, b.opts.QueueSize)
+	b.done = make(chan struct{})
+	baseCtx, queue := b.baseCtx, b.queue
+	var wg sync.WaitGroup
+	wg.Add(b.opts.Workers)
+	for i := 0; i < b.opts.Workers; i++ {
+		go func() {
+			defer wg.Done()
+			b.worker(baseCtx, queue)
+		}()
+	}
+	go func(done chan struct{}) {
+		wg.Wait()
+		close(done)
+	}(b.done)
+}
+
+// worker 从队列中取出事件处理，队列关闭且为空时退出；关闭超时后跳过剩余事件并计入丢弃数
+func (b *Bus) worker(baseCtx context.Context, queue <-chan delivery) {
+	for d := range queue {
+		if baseCtx.Err() != nil {
+			b.dropped.Add(1)
+			continue
+		}
+		ctx, cancel := context.WithCancel(d.ctx)
+		stop := context.AfterFunc(baseCtx, cancel)
+		_ = b.handle(ctx, d.topic, d.payload, d.sub)
+		stop()
+		cancel()
+	}
+}
+
+// drop 丢弃异步处理，计数并记录日志
+func (b *Bus) drop(d delivery, reason error) {
+	dropped := b.dropped.Add(1)
+	logger.FromContext(d.ctx).Warn("[事件] 主题 %s 的异步处理函数 %s 未执行: %v, 累计丢弃: %d", d.topic, d.sub.name, reason, dropped)
+}
+
+// handle 执行处理函数，捕获 panic 并转换为错误，失败时记录日志
+func (b *Bus) handle(ctx context.Context, topic string, payload any, sub *subscription) error {
+	timeout := sub.timeout
+	if timeout <= 0 {
+		b.queueMu.RLock()
+		timeout = b.opts.Timeout
+		b.queueMu.RUnlock()
+	}
+	ctx, cancel := context.WithTimeout(withTopic(ctx, topic), timeout)
+	defer cancel()
+
+	err := func() (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = fmt.Errorf("panic: %v\n%s", v, debug.Stack())
+			}
+		}()
+		return sub.handler(ctx, payload)
+	}()
+	if err == nil {
+		b.delivered.Add(1)
+		return nil
+	}
+
+	b.failed.Add(1)
+	logger.FromContext(ctx).Error("[事件] 主题 %s 的处理函数 %s 执行失败: %v", topic, sub.name, err)
+	return err
+}
+
+// topicKey 实际主题在 context.Context 中的存储键类型
+type topicKey struct{}
+
+// withTopic 将实际主题存入 context.Context
+func withTopic(ctx context.Context, topic string) context.Context {
+	return context.WithValue(ctx, topicKey{}, topic)
+}
+
+// Topic 在处理函数中获取事件的实际主题，用于订阅通配符主题的处理函数
+func Topic(ctx context.Context) string {
+	topic, _ := ctx.Value(topicKey{}).(string)
+	return topic
+}
+
+// handlerName 获取处理函数的函数名
+func handlerName(handler Handler) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return "anonymous"
+}
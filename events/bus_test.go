@@ -0,0 +1,301 @@
+// Package events 事件总线测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 Bus 的单元测试。
+//
+// 测试覆盖内容：
+// 1. 同步处理函数按订阅顺序执行，错误合并返回
+// 2. 异步处理函数由多个协程并发执行
+// 3. 通配符主题匹配（* 匹配一段，# 匹配剩余的零段或多段）与主题格式校验
+// 4. 处理函数 panic、超时被捕获，不影响其他处理函数
+// 5. 队列已满时丢弃事件并计数
+// 6. 关闭时处理完队列中的事件，关闭超时时放弃剩余事件；关闭后重新配置可继续使用
+//
+// 运行测试：go test -v ./events/...
+// ==================================================
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shutdownBus 测试结束时关闭总线
+func shutdownBus(t *testing.T, b *Bus) {
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, _ = b.Shutdown(ctx)
+	})
+}
+
+// TestBus_SyncOrder 测试同步处理顺序
+//
+// 【功能点】验证同步处理函数按订阅顺序在 Publish 中执行完毕，一个处理函数失败不影响后续处理函数，错误合并返回
+// 【测试流程】
+//  1. 依次订阅 3 个同步处理函数，第 2 个返回错误
+//  2. 发布事件，断言执行顺序为 1、2、3，返回的错误包含第 2 个处理函数的错误
+//  3. 断言统计中的发布数、成功数和失败数
+func TestBus_SyncOrder(t *testing.T) {
+	b := NewBus(Options{})
+	errSecond := errors.New("second failed")
+	var order []int
+	for i := 1; i <= 3; i++ {
+		i := i
+		require.NoError(t, b.Subscribe("user.created", func(ctx context.Context, payload any) error {
+			order = append(order, i)
+			assert.Equal(t, "alice", payload)
+			if i == 2 {
+				return errSecond
+			}
+			return nil
+		}, WithName("handler")))
+	}
+
+	err := b.Publish(context.Background(), "user.created", "alice")
+	assert.ErrorIs(t, err, errSecond)
+	assert.Equal(t, []int{1, 2, 3}, order)
+
+	stats := b.Stats()
+	assert.Equal(t, uint64(1), stats.Published)
+	assert.Equal(t, uint64(2), stats.Delivered)
+	assert.Equal(t, uint64(1), stats.Failed)
+}
+
+// TestBus_AsyncConcurrency 测试异步处理
+//
+// 【功能点】验证异步处理函数不阻塞 Publish，由多个协程并发执行，ctx 不继承发布方的取消
+// 【测试流程】
+//  1. 4 个协程的总线订阅异步处理函数，处理函数阻塞直到 release 关闭
+//  2. 使用已取消的 ctx 发布 4 个事件，断言 Publish 立即返回且 4 个处理函数同时执行
+//  3. 关闭 release 后关闭总线，断言 4 次处理均成功
+func TestBus_AsyncConcurrency(t *testing.T) {
+	b := NewBus(Options{Workers: 4})
+	shutdownBus(t, b)
+	started := make(chan struct{}, 4)
+	release := make(chan struct{})
+	require.NoError(t, b.Subscribe("order.paid", func(ctx context.Context, payload any) error {
+		started <- struct{}{}
+		select {
+		case <-release:
+			return ctx.Err()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, Async()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, b.Publish(ctx, "order.paid", i))
+	}
+	for i := 0; i < 4; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatalf("异步处理函数未并发执行, 已开始 %d 个", i)
+		}
+	}
+	close(release)
+
+	_, err := b.Shutdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), b.Stats().Delivered)
+}
+
+// TestBus_Wildcard 测试通配符主题
+//
+// 【功能点】验证 * 匹配任意一段、末尾的 # 匹配剩余的零段或多段，处理函数可通过 Topic(ctx) 获取实际主题；格式错误的主题订阅失败
+// 【测试流程】
+//  1. 订阅 user.*、user.#、*.created、user.created 四个主题，记录各自收到的实际主题
+//  2. 发布 user、user.created、user.profile.updated、order.created，断言各订阅收到的主题
+//  3. 断言空主题、空段、# 不在末尾、通配符与其他字符混用时订阅失败
+func TestBus_Wildcard(t *testing.T) {
+	b := NewBus(Options{})
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	for _, pattern := range []string{"user.*", "user.#", "*.created", "user.created"} {
+		pattern := pattern
+		require.NoError(t, b.Subscribe(pattern, func(ctx context.Context, payload any) error {
+			mu.Lock()
+			received[pattern] = append(received[pattern], Topic(ctx))
+			mu.Unlock()
+			return nil
+		}))
+	}
+	for _, topic := range []string{"user", "user.created", "user.profile.updated", "order.created"} {
+		require.NoError(t, b.Publish(context.Background(), topic, nil))
+	}
+
+	assert.Equal(t, []string{"user.created"}, received["user.*"])
+	assert.Equal(t, []string{"user", "user.created", "user.profile.updated"}, received["user.#"])
+	assert.Equal(t, []string{"user.created", "order.created"}, received["*.created"])
+	assert.Equal(t, []string{"user.created"}, received["user.created"])
+
+	noop := func(ctx context.Context, payload any) error { return nil }
+	for _, pattern := range []string{"", "user..created", "user.#.created", "user.cre*", "#user"} {
+		assert.Error(t, b.Subscribe(pattern, noop), pattern)
+	}
+	assert.Error(t, b.Subscribe("user.created", nil))
+}
+
+// TestBus_PanicAndTimeout 测试处理函数 panic 和超时
+//
+// 【功能点】验证 panic 被捕获转换为错误，超时的处理函数 ctx 被取消，均不影响后续处理函数
+// 【测试流程】
+//  1. 依次订阅 panic 的处理函数、等待 ctx 结束的处理函数（超时 20ms）和正常的处理函数
+//  2. 发布事件，断言返回包含 panic 和 context.DeadlineExceeded 的错误，正常的处理函数已执行
+//  3. 断言统计中的失败数为 2、成功数为 1
+func TestBus_PanicAndTimeout(t *testing.T) {
+	b := NewBus(Options{})
+	require.NoError(t, b.Subscribe("job.run", func(ctx context.Context, payload any) error {
+		panic("boom")
+	}))
+	require.NoError(t, b.Subscribe("job.run", func(ctx context.Context, payload any) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithTimeout(20*time.Millisecond)))
+	var called atomic.Bool
+	require.NoError(t, b.Subscribe("job.run", func(ctx context.Context, payload any) error {
+		called.Store(true)
+		return nil
+	}))
+
+	err := b.Publish(context.Background(), "job.run", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "panic: boom")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, called.Load())
+
+	stats := b.Stats()
+	assert.Equal(t, uint64(2), stats.Failed)
+	assert.Equal(t, uint64(1), stats.Delivered)
+}
+
+// TestBus_Overflow 测试队列已满
+//
+// 【功能点】验证异步队列已满时事件被丢弃，Publish 返回 ErrQueueFull，丢弃次数计入统计
+// 【测试流程】
+//  1. 1 个协程、队列容量 2 的总线，第一个事件阻塞处理协程
+//  2. 再发布 2 个事件填满队列，断言均成功，Pending 为 2
+//  3. 再发布 3 个事件，断言均返回 ErrQueueFull，Dropped 为 3
+//  4. 释放处理协程后关闭总线，断言 3 个已入队的事件均处理成功
+func TestBus_Overflow(t *testing.T) {
+	b := NewBus(Options{Workers: 1, QueueSize: 2})
+	shutdownBus(t, b)
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	require.NoError(t, b.Subscribe("report.build", func(ctx context.Context, payload any) error {
+		if payload == 0 {
+			started <- struct{}{}
+			<-release
+		}
+		return nil
+	}, Async()))
+
+	require.NoError(t, b.Publish(context.Background(), "report.build", 0))
+	<-started
+	for i := 1; i <= 2; i++ {
+		require.NoError(t, b.Publish(context.Background(), "report.build", i))
+	}
+	assert.Equal(t, 2, b.Stats().Pending)
+	for i := 3; i <= 5; i++ {
+		assert.ErrorIs(t, b.Publish(context.Background(), "report.build", i), ErrQueueFull)
+	}
+	assert.Equal(t, uint64(3), b.Stats().Dropped)
+
+	close(release)
+	_, err := b.Shutdown(context.Background())
+	require.NoError(t, err)
+	stats := b.Stats()
+	assert.Equal(t, uint64(3), stats.Delivered)
+	assert.Equal(t, uint64(3), stats.Dropped)
+	assert.Equal(t, uint64(6), stats.Published)
+}
+
+// TestBus_ShutdownDrain 测试关闭时处理完队列中的事件
+//
+// 【功能点】验证关闭时等待队列中的事件处理完毕，关闭后的异步事件返回 ErrBusClosed，同步处理函数不受影响
+// 【测试流程】
+//  1. 1 个协程的总线发布 5 个每个耗时 10ms 的异步事件
+//  2. 关闭总线，断言返回时 5 个事件均已处理
+//  3. 关闭后发布事件，断言异步处理函数返回 ErrBusClosed，同步处理函数正常执行
+func TestBus_ShutdownDrain(t *testing.T) {
+	b := NewBus(Options{Workers: 1})
+	var handled atomic.Int32
+	require.NoError(t, b.Subscribe("mail.send", func(ctx context.Context, payload any) error {
+		time.Sleep(10 * time.Millisecond)
+		handled.Add(1)
+		return nil
+	}, Async()))
+	var syncCalled atomic.Bool
+	require.NoError(t, b.Subscribe("mail.#", func(ctx context.Context, payload any) error {
+		syncCalled.Store(true)
+		return nil
+	}))
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, b.Publish(context.Background(), "mail.send", i))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	abandoned, err := b.Shutdown(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, abandoned)
+	assert.Equal(t, int32(5), handled.Load())
+
+	syncCalled.Store(false)
+	assert.ErrorIs(t, b.Publish(context.Background(), "mail.send", 6), ErrBusClosed)
+	assert.True(t, syncCalled.Load())
+}
+
+// TestBus_ShutdownTimeout 测试关闭超时
+//
+// 【功能点】验证关闭超时时取消正在执行的处理函数，放弃尚未开始的事件并返回数量；重新配置后总线可继续使用
+// 【测试流程】
+//  1. 1 个协程的总线发布 3 个等待 ctx 结束的异步事件
+//  2. 以 30ms 超时关闭，断言返回 context.DeadlineExceeded，放弃 2 次处理，正在执行的处理函数 ctx 被取消
+//  3. 调用 Configure 重新打开总线，发布事件，断言处理成功
+func TestBus_ShutdownTimeout(t *testing.T) {
+	b := NewBus(Options{Workers: 1})
+	shutdownBus(t, b)
+	started := make(chan struct{}, 3)
+	cancelled := make(chan struct{}, 3)
+	require.NoError(t, b.Subscribe("sync.all", func(ctx context.Context, payload any) error {
+		if payload == "again" {
+			return nil
+		}
+		started <- struct{}{}
+		<-ctx.Done()
+		cancelled <- struct{}{}
+		return ctx.Err()
+	}, Async()))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, b.Publish(context.Background(), "sync.all", i))
+	}
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	abandoned, err := b.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 2, abandoned)
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("正在执行的处理函数未被取消")
+	}
+
+	require.Eventually(t, func() bool { return b.Stats().Dropped == 2 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, b.Configure(Options{Workers: 1}))
+	require.NoError(t, b.Publish(context.Background(), "sync.all", "again"))
+	assert.Error(t, b.Configure(Options{}))
+	require.Eventually(t, func() bool { return b.Stats().Delivered == 1 }, time.Second, 5*time.Millisecond)
+}
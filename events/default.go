@@ -0,0 +1,22 @@
+package events
+
+import "context"
+
+// defaultBus 默认事件总线，由 core.SubscribeEvent / core.PublishEvent 使用
+// 框架启动时由事件总线服务按 events 配置调用 Configure，关闭时调用 Shutdown
+var defaultBus = NewBus(Options{})
+
+// Default 获取默认事件总线
+func Default() *Bus {
+	return defaultBus
+}
+
+// Subscribe 订阅默认事件总线的主题，参见 Bus.Subscribe
+func Subscribe(pattern string, handler Handler, opts ...SubOption) error {
+	return Default().Subscribe(pattern, handler, opts...)
+}
+
+// Publish 向默认事件总线发布事件，参见 Bus.Publish
+func Publish(ctx context.Context, topic string, payload any) error {
+	return Default().Publish(ctx, topic, payload)
+}
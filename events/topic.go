@@ -0,0 +1,51 @@
+package events
+
+import (
+	"errors"
+	"strings"
+)
+
+// topicPattern 解析后的订阅主题
+type topicPattern struct {
+	segments []string
+	// multi 末尾是否为 #，匹配剩余的零段或多段
+	multi bool
+}
+
+// parseTopicPattern 解析订阅主题
+// 主题由 . 分隔，各段不能为空；* 必须单独作为一段，# 只能作为最后一段
+func parseTopicPattern(pattern string) (topicPattern, error) {
+	if pattern == "" {
+		return topicPattern{}, errors.New("主题不能为空")
+	}
+	segments := strings.Split(pattern, ".")
+	p := topicPattern{segments: segments}
+	for i, segment := range segments {
+		switch {
+		case segment == "":
+			return topicPattern{}, errors.New("主题中不能有空段")
+		case segment == "#":
+			if i != len(segments)-1 {
+				return topicPattern{}, errors.New("# 只能作为主题的最后一段")
+			}
+			p.segments, p.multi = segments[:i], true
+		case segment != "*" && strings.ContainsAny(segment, "*#"):
+			return topicPattern{}, errors.New("通配符 * 和 # 必须单独作为一段")
+		}
+	}
+	return p, nil
+}
+
+// match 判断主题是否匹配订阅主题
+func (p topicPattern) match(topic string) bool {
+	segments := strings.Split(topic, ".")
+	if len(segments) < len(p.segments) || (!p.multi && len(segments) != len(p.segments)) {
+		return false
+	}
+	for i, segment := range p.segments {
+		if segment != "*" && segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
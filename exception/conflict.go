@@ -0,0 +1,41 @@
+package exception
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// Conflict 数据冲突异常。
+// 乐观锁更新时版本号不一致（数据已被其他请求修改）时返回此异常，框架返回数据冲突状态码（HTTP 409）。
+//
+// 使用方式：return exception.NewConflict("订单已被修改，请刷新后重试")
+type Conflict struct {
+	msg string
+}
+
+// Error 实现 error 接口，返回冲突描述，未指定时返回数据冲突状态码的默认消息
+func (e Conflict) Error() string {
+	if e.msg == "" {
+		return response.ResponseConflict.GetMsg()
+	}
+	return e.msg
+}
+
+// NewConflict 创建数据冲突异常。
+// 参数 msg 将返回给前端用户，为空时返回数据冲突状态码的默认消息。
+func NewConflict(msg string) Conflict {
+	return Conflict{msg: msg}
+}
+
+// OnException 实现 Handler 接口，返回冲突消息和数据冲突状态码
+func (e Conflict) OnException(*gin.Context) (msg string, code int) {
+	return e.Error(), response.ResponseConflict.GetCode()
+}
+
+// IsConflict 判断错误链中是否包含数据冲突异常
+func IsConflict(err error) bool {
+	var conflict Conflict
+	return errors.As(err, &conflict)
+}
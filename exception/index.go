@@ -2,11 +2,25 @@
 //
 // 所有业务异常均通过 panic 抛出，由框架的 recover 中间件统一捕获并转换为 HTTP 响应。
 // 自定义异常需实现 Handler 接口；框架内置了 CommonError（通用异常）、AuthFailed（认证失败）、
-// RpcError（RPC 调用异常）和 InvalidParam（参数校验异常）等类型。
+// RpcError（RPC 调用异常）、InvalidParam（参数校验异常）和 Conflict（数据冲突）等类型。
 package exception
 
 import "github.com/gin-gonic/gin"
 
+// Abort 将错误添加到请求上下文并中断后续处理函数，由 ExceptionHandler 中间件按与 panic 相同的规则返回错误响应
+// 等同于 c.Error(err) 加 c.Abort()，需要将非异常类型的错误消息返回给客户端时，使用 c.Error(err).SetType(gin.ErrorTypePublic)
+//
+// 使用示例：
+//
+//	if err := svc.Create(ctx, req); err != nil {
+//	    exception.Abort(c, err)
+//	    return
+//	}
+func Abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
 // Handler 异常处理器接口。
 // 所有通过 panic 抛出的异常如果实现了此接口，框架将调用 OnException 获取响应消息和状态码；
 // 未实现此接口的 panic 值将被视为未知异常（500）。
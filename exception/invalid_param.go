@@ -6,21 +6,30 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/zzsen/gin_core/i18n"
 	"github.com/zzsen/gin_core/model/response"
+	"github.com/zzsen/gin_core/model/types"
 )
 
 // InvalidParam 参数校验异常。
 // 当请求参数不符合校验规则时抛出此异常，框架将返回参数校验失败的响应码和错误详情。
-// 支持自定义错误消息，也可通过 NewInvalidParamFromValidator 自动转换 validator 校验错误。
+// 支持自定义错误消息，也可通过 NewInvalidParamFromValidator 自动转换 validator 校验错误，
+// 此时错误消息按请求的语言区域（见 i18n 包）生成。
 //
 // 使用方式：panic(exception.NewInvalidParam("用户名长度必须在 3-20 之间"))
 type InvalidParam struct {
-	msg string
+	msg    string
+	errors validator.ValidationErrors
+	// fields 字段路径到显示名称的映射，用于在错误消息中标明参数来源
+	fields map[string]string
 }
 
 // Error 实现 error 接口，返回参数校验的错误消息。
-// 如果未设置自定义消息，则返回框架默认的参数校验失败消息。
+// 如果未设置自定义消息，则返回框架默认的参数校验失败消息；validator 校验错误按默认语言区域格式化。
 func (e InvalidParam) Error() string {
+	if len(e.errors) > 0 {
+		return formatValidationErrors(i18n.GetDefaultLocale(), e.errors, e.fields)
+	}
 	if e.msg != "" {
 		return e.msg
 	}
@@ -34,31 +43,48 @@ func NewInvalidParam(msg string) InvalidParam {
 }
 
 // NewInvalidParamFromValidator 从validator校验错误创建InvalidParam异常
-// 将validator.ValidationErrors转换为InvalidParam异常
+// 将validator.ValidationErrors转换为InvalidParam异常，错误消息在 OnException 时按请求的语言区域生成
 //
 // 参数 validationErrors: validator校验错误集合
 // 返回值: InvalidParam异常实例
 func NewInvalidParamFromValidator(validationErrors validator.ValidationErrors) InvalidParam {
-	return InvalidParam{msg: formatValidationErrors(validationErrors)}
+	return InvalidParam{errors: validationErrors}
+}
+
+// NewInvalidParamFromValidatorWithFields 从validator校验错误创建InvalidParam异常，并指定字段的显示名称
+// 错误消息中的字段名按 fields 替换，未收录的字段仍使用字段路径
+//
+// 参数 validationErrors: validator校验错误集合
+// 参数 fields: 字段路径（去除顶层结构体后的命名空间，如 "TenantID"、"Filter.Status"）到显示名称（如 "header X-Tenant-Id"）的映射
+// 返回值: InvalidParam异常实例
+func NewInvalidParamFromValidatorWithFields(validationErrors validator.ValidationErrors, fields map[string]string) InvalidParam {
+	return InvalidParam{errors: validationErrors, fields: fields}
 }
 
 // OnException 实现 Handler 接口，返回参数校验失败消息和对应的业务状态码
-func (e InvalidParam) OnException(*gin.Context) (msg string, code int) {
+// validator 校验错误按请求的语言区域格式化，ctx 为 nil 时使用默认语言区域
+func (e InvalidParam) OnException(ctx *gin.Context) (msg string, code int) {
+	if len(e.errors) > 0 {
+		return formatValidationErrors(i18n.Locale(ctx), e.errors, e.fields), response.ResponseParamInvalid.GetCode()
+	}
 	return e.Error(), response.ResponseParamInvalid.GetCode()
 }
 
 // formatValidationErrors 格式化validator校验错误消息
-// 将validator.ValidationErrors转换为可读的中文错误消息
+// 将validator.ValidationErrors转换为指定语言区域的可读错误消息
 //
+// 参数 locale: 语言区域
 // 参数 validationErrors: validator校验错误集合
+// 参数 fields: 字段路径到显示名称的映射，可为 nil
 // 返回值: 格式化后的错误消息字符串
 //
 // 处理逻辑：
 // 1. 遍历所有校验错误
-// 2. 根据错误类型生成对应的中文错误消息
-// 3. 将所有错误消息用分号连接
-func formatValidationErrors(validationErrors validator.ValidationErrors) string {
-	messages := []string{"【参数校验不通过】"}
+// 2. 根据校验标签从消息目录查找 validation.<tag> 消息，未收录的标签使用 validation.default，
+//    应用可通过 i18n.Register 为自定义校验标签注册消息；enum 标签的消息可使用 {allowed} 输出可选值
+// 3. 将标题和所有错误消息用分号连接
+func formatValidationErrors(locale string, validationErrors validator.ValidationErrors, fields map[string]string) string {
+	messages := []string{i18n.Translate(locale, "validation.title", nil)}
 	for _, err := range validationErrors {
 		// 获取字段名（优先使用命名空间以保留嵌套路径）
 		// Namespace 返回完整路径如 "ApiResponseBatchRequest.Responses[0].ApiID"
@@ -69,47 +95,27 @@ func formatValidationErrors(validationErrors validator.ValidationErrors) string
 		if idx := strings.Index(namespace, "."); idx != -1 {
 			field = namespace[idx+1:]
 		}
-		// 获取校验标签
-		tag := err.Tag()
-		// 获取字段值
-		value := err.Value()
+		if name, ok := fields[field]; ok {
+			field = name
+		}
 
 		// 根据校验标签生成对应的错误消息
-		var msg string
-		switch tag {
-		case "required":
-			msg = fmt.Sprintf("%s不能为空", field)
-		case "min":
-			msg = fmt.Sprintf("%s的值不能小于%s", field, err.Param())
-		case "max":
-			msg = fmt.Sprintf("%s的值不能大于%s", field, err.Param())
-		case "len":
-			msg = fmt.Sprintf("%s的长度必须为%s", field, err.Param())
-		case "email":
-			msg = fmt.Sprintf("%s必须是有效的邮箱地址", field)
-		case "url":
-			msg = fmt.Sprintf("%s必须是有效的URL地址", field)
-		case "numeric":
-			msg = fmt.Sprintf("%s必须是数字", field)
-		case "alpha":
-			msg = fmt.Sprintf("%s只能包含字母", field)
-		case "alphanum":
-			msg = fmt.Sprintf("%s只能包含字母和数字", field)
-		case "gte":
-			msg = fmt.Sprintf("%s的值必须大于或等于%s", field, err.Param())
-		case "lte":
-			msg = fmt.Sprintf("%s的值必须小于或等于%s", field, err.Param())
-		case "gt":
-			msg = fmt.Sprintf("%s的值必须大于%s", field, err.Param())
-		case "lt":
-			msg = fmt.Sprintf("%s的值必须小于%s", field, err.Param())
-		case "oneof":
-			msg = fmt.Sprintf("%s的值必须是以下之一: %s", field, err.Param())
-		default:
-			// 默认错误消息
-			msg = fmt.Sprintf("%s校验失败(标签: %s, 值: %v)", field, tag, value)
+		key := "validation." + err.Tag()
+		if _, ok := i18n.Lookup(locale, key); !ok {
+			key = "validation.default"
+		}
+		args := map[string]string{
+			"field": field,
+			"param": err.Param(),
+			"tag":   err.Tag(),
+			"value": fmt.Sprintf("%v", err.Value()),
+		}
+		// enum 标签的参数为枚举名称，消息中列出可选值
+		if err.Tag() == "enum" {
+			values, _ := types.EnumValues(err.Param())
+			args["allowed"] = strings.Join(values, ", ")
 		}
-		messages = append(messages, msg)
+		messages = append(messages, i18n.Translate(locale, key, args))
 	}
 
 	// 将所有错误消息用分号连接
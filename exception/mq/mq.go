@@ -0,0 +1,65 @@
+// Package mq 定义消息队列消费函数可以返回的控制错误、在数据库事务中只处理一次消息的 ProcessOnce，
+// 以及将 JSON 消息反序列化为结构体后再消费的 TypedHandler
+//
+// 消费函数（MessageQueue.FunWithCtx / Fun）返回普通错误时，消息按 ConsumeConfig.MaxRetry 立即重新入队重试，
+// 超过重试次数后进入死信队列；返回本包定义的错误时改为：
+//   - ErrRetryAfter(d)：确认消息并发布到延迟队列，d 之后回到主队列重新消费，仍计入重试次数
+//   - ErrDiscard：确认并丢弃消息，不重试也不进入死信队列
+//   - ErrDeadLetter：拒绝且不重新入队，不重试，配置了死信队列时直接进入死信队列
+//   - ErrAlreadyProcessed：消息已处理过（由 ProcessOnce 返回），按处理成功确认消息
+//
+// 以上错误都可以被 fmt.Errorf("...: %w", err) 包装后返回。
+package mq
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrDiscard 确认并丢弃消息，不重试也不进入死信队列，用于消息格式错误、业务上已失效等重试无意义的情况
+var ErrDiscard = errors.New("消息已丢弃")
+
+// ErrDeadLetter 拒绝消息且不重新入队，不重试，配置了死信队列时直接进入死信队列，用于需要保留现场、人工排查的永久性错误
+var ErrDeadLetter = errors.New("消息直接进入死信队列")
+
+// RetryAfterError 延迟重试错误，由 ErrRetryAfter 创建
+type RetryAfterError struct {
+	Delay time.Duration // 重新消费前的等待时间
+}
+
+// Error 实现 error 接口
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%s 后重试", e.Delay)
+}
+
+// ErrRetryAfter 创建延迟重试错误，消息在 d 之后回到主队列重新消费，用于下游限流、暂时不可用等短暂性错误
+// 参数：
+//   - d: 重新消费前的等待时间，精确到毫秒；<= 0 时按普通错误立即重新入队
+//
+// 返回：
+//   - error: *RetryAfterError
+//
+// 使用示例：
+//
+//	if errors.Is(err, errRateLimited) {
+//		return mq.ErrRetryAfter(30 * time.Second)
+//	}
+func ErrRetryAfter(d time.Duration) error {
+	return &RetryAfterError{Delay: d}
+}
+
+// RetryAfter 获取延迟重试的等待时间
+// 参数：
+//   - err: 消费函数返回的错误
+//
+// 返回：
+//   - time.Duration: 等待时间
+//   - bool: err（或其包装的错误）是否为 *RetryAfterError
+func RetryAfter(err error) (time.Duration, bool) {
+	var retryErr *RetryAfterError
+	if errors.As(err, &retryErr) {
+		return retryErr.Delay, true
+	}
+	return 0, false
+}
@@ -0,0 +1,90 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAlreadyProcessed 消息已处理过，由 ProcessOnce 返回；消费函数返回该错误（或其包装）时消息被确认，不重试
+var ErrAlreadyProcessed = errors.New("消息已处理过")
+
+// ProcessedTableName 已处理消息表名
+const ProcessedTableName = "processed_messages"
+
+// ProcessedMessage 已处理消息记录，message_id 为主键，用于在数据库中识别重复投递的消息
+type ProcessedMessage struct {
+	MessageID   string    `gorm:"primaryKey;size:191"` // 消息 ID
+	ProcessedAt time.Time `gorm:"not null;index"`      // 处理时间，用于清理过期记录
+}
+
+// TableName 指定 GORM 表名
+func (ProcessedMessage) TableName() string {
+	return ProcessedTableName
+}
+
+// AutoMigrateProcessed 自动创建或更新已处理消息表
+func AutoMigrateProcessed(db *gorm.DB) error {
+	return db.AutoMigrate(&ProcessedMessage{})
+}
+
+// ProcessOnce 在调用方的事务中只处理一次消息，用于有数据库副作用的消费函数
+// 先在 processed_messages 表中写入消息 ID，再执行 fn：写入与 fn 的数据库操作在同一事务中提交或回滚，
+// 事务提交后即使进程在确认消息前崩溃，重新投递的消息也会因消息 ID 已存在而返回 ErrAlreadyProcessed，不会重复写入。
+// 多个消费者并发处理同一消息时，后写入的事务会等待先写入的事务结束，先写入的事务提交后返回 ErrAlreadyProcessed，回滚后照常处理
+// 参数：
+//   - ctx: 上下文
+//   - tx: 调用方的事务，必须是事务，否则写入消息 ID 后 fn 失败时消息 ID 不会被回滚
+//   - messageID: 消息 ID，通常为 AMQP 属性 MessageId
+//   - fn: 处理函数，应使用传入的 tx 执行数据库操作
+//
+// 返回：
+//   - error: 消息已处理过时返回 ErrAlreadyProcessed（消费函数直接返回即可，消息会被确认），
+//     写入消息 ID 失败或 fn 返回错误时返回对应的错误，调用方应回滚事务
+//
+// 使用示例：
+//
+//	Fun: func(ctx context.Context, msg amqp.Delivery) error {
+//		return app.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+//			return mq.ProcessOnce(ctx, tx, msg.MessageId, func(tx *gorm.DB) error {
+//				return tx.Create(&Order{...}).Error
+//			})
+//		})
+//	}
+func ProcessOnce(ctx context.Context, tx *gorm.DB, messageID string, fn func(tx *gorm.DB) error) error {
+	if tx == nil {
+		return errors.New("ProcessOnce 的事务不能为空")
+	}
+	if messageID == "" {
+		return errors.New("ProcessOnce 的消息 ID 不能为空")
+	}
+	tx = tx.WithContext(ctx)
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&ProcessedMessage{MessageID: messageID, ProcessedAt: time.Now()})
+	if result.Error != nil {
+		return fmt.Errorf("写入已处理消息记录失败, messageId: %s: %w", messageID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlreadyProcessed
+	}
+	return fn(tx)
+}
+
+// SweepProcessed 删除处理时间早于 retention 之前的已处理消息记录
+// retention 应大于消息可能被重新投递的最长间隔，记录被删除后重新投递的消息会被再次处理
+// 参数：
+//   - ctx: 上下文
+//   - db: 已处理消息表所在的数据库
+//   - retention: 记录的保留时间
+//
+// 返回：
+//   - int64: 删除的记录数
+//   - error: 删除失败时返回错误
+func SweepProcessed(ctx context.Context, db *gorm.DB, retention time.Duration) (int64, error) {
+	result := db.WithContext(ctx).Where("processed_at < ?", time.Now().Add(-retention)).Delete(&ProcessedMessage{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,197 @@
+// Package mq 只处理一次消息测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 ProcessOnce 和 SweepProcessed 的单元测试，使用 SQLite 数据库，不需要 MySQL / RabbitMQ。
+//
+// 测试覆盖内容：
+// 1. 首次处理时写入消息 ID 并执行处理函数，重复处理时返回 ErrAlreadyProcessed 且不执行处理函数
+// 2. 处理函数失败时消息 ID 随事务回滚，重新投递的消息可以再次处理
+// 3. 多个协程并发处理同一消息时只有一个执行处理函数
+// 4. 清理时只删除超过保留时间的记录
+//
+// 运行测试：go test -v ./exception/mq/...
+// ==================================================
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// order 处理函数写入的业务数据
+type order struct {
+	ID      uint
+	OrderNo string
+}
+
+// openProcessOnceDB 打开临时文件 SQLite 数据库并创建已处理消息表和订单表
+// 使用文件数据库而非内存数据库，使并发事务通过 busy_timeout 等待写锁
+func openProcessOnceDB(t *testing.T) *gorm.DB {
+	dsn := filepath.Join(t.TempDir(), "mq.db") + "?_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	require.NoError(t, AutoMigrateProcessed(db))
+	require.NoError(t, db.AutoMigrate(&order{}))
+	return db
+}
+
+// consume 模拟消费函数：在事务中通过 ProcessOnce 创建订单
+func consume(ctx context.Context, db *gorm.DB, messageID string, fn func(tx *gorm.DB) error) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return ProcessOnce(ctx, tx, messageID, fn)
+	})
+}
+
+// countOrders 返回订单数
+func countOrders(t *testing.T, db *gorm.DB) int64 {
+	var count int64
+	require.NoError(t, db.Model(&order{}).Count(&count).Error)
+	return count
+}
+
+// TestProcessOnce_Replay 测试重复投递
+//
+// 【功能点】验证首次处理成功后记录消息 ID，重复投递时返回 ErrAlreadyProcessed 且不再执行处理函数
+// 【测试流程】
+//  1. 处理消息 m-1，断言成功、创建了 1 个订单、记录了消息 ID
+//  2. 再次处理 m-1，断言返回 ErrAlreadyProcessed，处理函数未执行，订单数仍为 1
+//  3. 处理另一条消息 m-2，断言正常处理；消息 ID 为空或事务为空时返回错误
+func TestProcessOnce_Replay(t *testing.T) {
+	db := openProcessOnceDB(t)
+	ctx := context.Background()
+	calls := 0
+	create := func(tx *gorm.DB) error {
+		calls++
+		return tx.Create(&order{OrderNo: fmt.Sprintf("NO-%d", calls)}).Error
+	}
+
+	require.NoError(t, consume(ctx, db, "m-1", create))
+	assert.Equal(t, int64(1), countOrders(t, db))
+	var record ProcessedMessage
+	require.NoError(t, db.First(&record, "message_id = ?", "m-1").Error)
+	assert.WithinDuration(t, time.Now(), record.ProcessedAt, time.Minute)
+
+	err := consume(ctx, db, "m-1", create)
+	assert.ErrorIs(t, err, ErrAlreadyProcessed)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, int64(1), countOrders(t, db))
+
+	require.NoError(t, consume(ctx, db, "m-2", create))
+	assert.Equal(t, int64(2), countOrders(t, db))
+
+	assert.Error(t, consume(ctx, db, "", create))
+	assert.Error(t, ProcessOnce(ctx, nil, "m-3", create))
+}
+
+// TestProcessOnce_RollbackOnError 测试处理失败时回滚
+//
+// 【功能点】验证处理函数返回错误时消息 ID 和业务数据一起回滚，重新投递的消息可以再次处理
+// 【测试流程】
+//  1. 处理函数创建订单后返回错误，断言返回该错误，订单表和已处理消息表均为空
+//  2. 重新处理同一消息且处理函数成功，断言创建了 1 个订单
+func TestProcessOnce_RollbackOnError(t *testing.T) {
+	db := openProcessOnceDB(t)
+	ctx := context.Background()
+	errDownstream := errors.New("下游服务不可用")
+
+	err := consume(ctx, db, "m-1", func(tx *gorm.DB) error {
+		require.NoError(t, tx.Create(&order{OrderNo: "NO-1"}).Error)
+		return errDownstream
+	})
+	assert.ErrorIs(t, err, errDownstream)
+	assert.Zero(t, countOrders(t, db))
+	var processed int64
+	require.NoError(t, db.Model(&ProcessedMessage{}).Count(&processed).Error)
+	assert.Zero(t, processed)
+
+	require.NoError(t, consume(ctx, db, "m-1", func(tx *gorm.DB) error {
+		return tx.Create(&order{OrderNo: "NO-1"}).Error
+	}))
+	assert.Equal(t, int64(1), countOrders(t, db))
+}
+
+// TestProcessOnce_ConcurrentReplay 测试并发重复投递
+//
+// 【功能点】验证多个协程同时处理同一消息时只有一个执行处理函数，其余返回 ErrAlreadyProcessed
+// 【测试流程】
+//  1. 10 个协程同时处理消息 m-1，处理函数创建订单
+//  2. 断言 1 个成功、9 个返回 ErrAlreadyProcessed，没有其他错误，订单数为 1
+func TestProcessOnce_ConcurrentReplay(t *testing.T) {
+	db := openProcessOnceDB(t)
+	ctx := context.Background()
+
+	const workers = 10
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make([]error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs[i] = consume(ctx, db, "m-1", func(tx *gorm.DB) error {
+				return tx.Create(&order{OrderNo: fmt.Sprintf("NO-%d", i)}).Error
+			})
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded, duplicated := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, ErrAlreadyProcessed):
+			duplicated++
+		default:
+			t.Errorf("意外的错误: %v", err)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, workers-1, duplicated)
+	assert.Equal(t, int64(1), countOrders(t, db))
+}
+
+// TestSweepProcessed 测试清理过期记录
+//
+// 【功能点】验证只删除处理时间早于保留时间的记录，被删除的消息可以再次处理
+// 【测试流程】
+//  1. 写入处理时间为 10 天前、8 天前、1 天前和现在的记录
+//  2. 按 7 天保留时间清理，断言删除 2 条，剩余 1 天前和现在的记录
+//  3. 再次处理被删除的消息，断言正常处理
+func TestSweepProcessed(t *testing.T) {
+	db := openProcessOnceDB(t)
+	ctx := context.Background()
+	now := time.Now()
+	require.NoError(t, db.Create([]ProcessedMessage{
+		{MessageID: "old-10d", ProcessedAt: now.Add(-10 * 24 * time.Hour)},
+		{MessageID: "old-8d", ProcessedAt: now.Add(-8 * 24 * time.Hour)},
+		{MessageID: "recent-1d", ProcessedAt: now.Add(-24 * time.Hour)},
+		{MessageID: "now", ProcessedAt: now},
+	}).Error)
+
+	deleted, err := SweepProcessed(ctx, db, 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	var remaining []string
+	require.NoError(t, db.Model(&ProcessedMessage{}).Order("processed_at").Pluck("message_id", &remaining).Error)
+	assert.Equal(t, []string{"recent-1d", "now"}, remaining)
+
+	require.NoError(t, consume(ctx, db, "old-10d", func(tx *gorm.DB) error { return nil }))
+	assert.ErrorIs(t, consume(ctx, db, "recent-1d", func(tx *gorm.DB) error { return nil }), ErrAlreadyProcessed)
+}
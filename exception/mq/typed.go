@@ -0,0 +1,130 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// defaultPreviewBytes InvalidMessageError 中记录的消息内容的默认最大字节数
+const defaultPreviewBytes = 256
+
+// typedOptions TypedHandler 的选项
+type typedOptions struct {
+	validate     bool // 是否使用参数校验器校验反序列化后的消息
+	discard      bool // 格式错误的消息是否丢弃，默认进入死信队列
+	previewBytes int  // 错误中记录的消息内容的最大字节数
+}
+
+// TypedOption TypedHandler 的选项
+type TypedOption func(*typedOptions)
+
+// WithValidation 反序列化后使用参数校验器（binding.Validator，与 HTTP 请求参数校验相同）校验消息，
+// 消息结构体的 binding 标签（如 binding:"required"）生效，校验不通过的消息按格式错误处理
+func WithValidation() TypedOption {
+	return func(o *typedOptions) {
+		o.validate = true
+	}
+}
+
+// WithDiscardInvalid 格式错误的消息确认并丢弃（ErrDiscard），默认拒绝消息并直接进入死信队列（ErrDeadLetter）
+func WithDiscardInvalid() TypedOption {
+	return func(o *typedOptions) {
+		o.discard = true
+	}
+}
+
+// WithPreviewBytes 设置格式错误时错误中记录的消息内容的最大字节数，默认 256，<= 0 时不记录消息内容
+func WithPreviewBytes(n int) TypedOption {
+	return func(o *typedOptions) {
+		o.previewBytes = n
+	}
+}
+
+// InvalidMessageError 消息格式错误，由 TypedHandler 在消息无法反序列化或校验不通过时返回
+// 包装 ErrDeadLetter 或 ErrDiscard，消息不会重试
+type InvalidMessageError struct {
+	Preview string // 消息内容的前若干字节，超出部分以 ... 省略
+	Err     error  // 反序列化或校验错误
+	action  error  // ErrDeadLetter 或 ErrDiscard
+}
+
+// Error 实现 error 接口
+func (e *InvalidMessageError) Error() string {
+	return fmt.Sprintf("消息格式错误: %v, 消息内容: %s", e.Err, e.Preview)
+}
+
+// Unwrap 支持 errors.Is / errors.As 匹配处理方式（ErrDeadLetter / ErrDiscard）和原始错误
+func (e *InvalidMessageError) Unwrap() []error {
+	return []error{e.action, e.Err}
+}
+
+// TypedHandler 将 JSON 消息反序列化为 T 后调用 fn，返回值可直接作为 MessageQueue.FunWithCtx
+// 消息无法反序列化或校验不通过时不调用 fn，返回 *InvalidMessageError：默认拒绝消息并直接进入死信队列，
+// 设置 WithDiscardInvalid 时确认并丢弃，均不重试。框架默认的消费失败日志记录消息 ID 和错误，错误中包含消息内容的前若干字节。
+// fn 返回的错误原样返回，按普通错误或本包定义的控制错误处理
+// 参数：
+//   - fn: 消费函数，接收反序列化后的消息
+//   - opts: 选项，如 WithValidation()、WithDiscardInvalid()、WithPreviewBytes(n)
+//
+// 返回：
+//   - func(ctx context.Context, raw string) error: 消费函数
+//
+// 使用示例：
+//
+//	type OrderPaid struct {
+//		OrderID string `json:"orderId" binding:"required"`
+//		Amount  int64  `json:"amount" binding:"gt=0"`
+//	}
+//
+//	messageQueue := &config.MessageQueue{
+//		QueueName: "order.paid",
+//		FunWithCtx: mq.TypedHandler(func(ctx context.Context, msg OrderPaid) error {
+//			return service.MarkPaid(ctx, msg.OrderID, msg.Amount)
+//		}, mq.WithValidation()),
+//	}
+func TypedHandler[T any](fn func(ctx context.Context, msg T) error, opts ...TypedOption) func(ctx context.Context, raw string) error {
+	options := typedOptions{previewBytes: defaultPreviewBytes}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return func(ctx context.Context, raw string) error {
+		var msg T
+		if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+			return options.invalid(raw, err)
+		}
+		if options.validate && binding.Validator != nil {
+			if err := binding.Validator.ValidateStruct(msg); err != nil {
+				return options.invalid(raw, err)
+			}
+		}
+		return fn(ctx, msg)
+	}
+}
+
+// invalid 创建消息格式错误
+func (o *typedOptions) invalid(raw string, err error) error {
+	action := ErrDeadLetter
+	if o.discard {
+		action = ErrDiscard
+	}
+	return &InvalidMessageError{Preview: preview(raw, o.previewBytes), Err: err, action: action}
+}
+
+// preview 截取消息内容的前 n 个字节，不截断多字节字符，超出部分以 ... 省略
+func preview(raw string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(raw) <= n {
+		return raw
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(raw[cut]) {
+		cut--
+	}
+	return raw[:cut] + "..."
+}
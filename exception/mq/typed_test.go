@@ -0,0 +1,142 @@
+// Package mq 类型化消费函数测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 TypedHandler 的单元测试，使用 gin 默认的参数校验器，不需要 RabbitMQ。
+// 消费者对返回错误的确认、拒绝见 model/config 包的 TestMessageQueue_Retry_TypedHandler。
+//
+// 测试覆盖内容：
+// 1. 合法的 JSON 消息反序列化后交给消费函数，消费函数的错误原样返回
+// 2. 格式错误的消息不调用消费函数，默认返回 ErrDeadLetter，WithDiscardInvalid 时返回 ErrDiscard
+// 3. WithValidation 时校验 binding 标签，校验不通过按格式错误处理
+// 4. 错误中记录的消息内容按 WithPreviewBytes 截断，不截断多字节字符
+//
+// 运行测试：go test -v ./exception/mq/... -run TypedHandler
+// ==================================================
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderPaid 测试用的消息结构体
+type orderPaid struct {
+	OrderID string `json:"orderId" binding:"required"`
+	Amount  int64  `json:"amount" binding:"gt=0"`
+}
+
+// TestTypedHandler_Valid 测试合法消息
+//
+// 【功能点】验证合法的 JSON 消息反序列化后交给消费函数，消费函数的错误原样返回
+// 【测试流程】
+//  1. 消费 {"orderId":"o-1","amount":100}，断言消费函数收到的消息
+//  2. 消费函数返回 ErrRetryAfter 时断言原样返回
+//  3. T 为指针类型时同样可以反序列化和校验
+func TestTypedHandler_Valid(t *testing.T) {
+	var got orderPaid
+	handler := TypedHandler(func(ctx context.Context, msg orderPaid) error {
+		got = msg
+		return nil
+	}, WithValidation())
+	require.NoError(t, handler(context.Background(), `{"orderId":"o-1","amount":100}`))
+	assert.Equal(t, orderPaid{OrderID: "o-1", Amount: 100}, got)
+
+	retryErr := ErrRetryAfter(0)
+	handler = TypedHandler(func(ctx context.Context, msg orderPaid) error { return retryErr })
+	assert.Same(t, retryErr, handler(context.Background(), `{"orderId":"o-1"}`))
+
+	ptrHandler := TypedHandler(func(ctx context.Context, msg *orderPaid) error {
+		got = *msg
+		return nil
+	}, WithValidation())
+	require.NoError(t, ptrHandler(context.Background(), `{"orderId":"o-2","amount":1}`))
+	assert.Equal(t, "o-2", got.OrderID)
+	assert.True(t, errors.Is(ptrHandler(context.Background(), `{"amount":1}`), ErrDeadLetter))
+}
+
+// TestTypedHandler_Malformed 测试格式错误的消息
+//
+// 【功能点】验证无法反序列化的消息不调用消费函数，默认进入死信队列，WithDiscardInvalid 时丢弃
+// 【测试流程】
+//  1. 消费非 JSON 内容和字段类型不匹配的消息，断言未调用消费函数
+//  2. 断言错误为 *InvalidMessageError，匹配 ErrDeadLetter 和 *json.SyntaxError，不匹配 ErrDiscard
+//  3. 设置 WithDiscardInvalid，断言匹配 ErrDiscard，不匹配 ErrDeadLetter
+func TestTypedHandler_Malformed(t *testing.T) {
+	called := false
+	fn := func(ctx context.Context, msg orderPaid) error {
+		called = true
+		return nil
+	}
+
+	err := TypedHandler(fn)(context.Background(), "not json")
+	var invalid *InvalidMessageError
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "not json", invalid.Preview)
+	assert.ErrorIs(t, err, ErrDeadLetter)
+	assert.NotErrorIs(t, err, ErrDiscard)
+	var syntaxErr *json.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+	assert.Contains(t, err.Error(), "消息内容: not json")
+
+	err = TypedHandler(fn)(context.Background(), `{"orderId":1}`)
+	assert.ErrorIs(t, err, ErrDeadLetter)
+
+	err = TypedHandler(fn, WithDiscardInvalid())(context.Background(), "")
+	assert.ErrorIs(t, err, ErrDiscard)
+	assert.NotErrorIs(t, err, ErrDeadLetter)
+	assert.False(t, called, "格式错误的消息不应调用消费函数")
+}
+
+// TestTypedHandler_Validation 测试消息校验
+//
+// 【功能点】验证 WithValidation 时 binding 标签生效，未设置时不校验
+// 【测试流程】
+//  1. 设置 WithValidation，消费缺少 orderId 和 amount 为 0 的消息，断言返回 ErrDeadLetter 且包含 validator.ValidationErrors
+//  2. 未设置 WithValidation 时同一消息交给消费函数
+func TestTypedHandler_Validation(t *testing.T) {
+	called := 0
+	fn := func(ctx context.Context, msg orderPaid) error {
+		called++
+		return nil
+	}
+
+	for _, raw := range []string{`{"amount":100}`, `{"orderId":"o-1","amount":0}`} {
+		err := TypedHandler(fn, WithValidation())(context.Background(), raw)
+		assert.ErrorIs(t, err, ErrDeadLetter, raw)
+		var validationErrs validator.ValidationErrors
+		assert.ErrorAs(t, err, &validationErrs, raw)
+	}
+	assert.Zero(t, called, "校验不通过的消息不应调用消费函数")
+
+	require.NoError(t, TypedHandler(fn)(context.Background(), `{"amount":100}`))
+	assert.Equal(t, 1, called)
+}
+
+// TestTypedHandler_Preview 测试错误中记录的消息内容
+//
+// 【功能点】验证消息内容按 WithPreviewBytes 截断，超出部分以 ... 省略，不截断多字节字符
+// 【测试流程】
+//  1. 默认记录前 256 个字节
+//  2. WithPreviewBytes(4) 时 "消息格式" 截断为 "消..."（第 4 个字节位于第 2 个字符中间）
+//  3. WithPreviewBytes(0) 时不记录消息内容
+func TestTypedHandler_Preview(t *testing.T) {
+	fn := func(ctx context.Context, msg orderPaid) error { return nil }
+	var invalid *InvalidMessageError
+
+	long := strings.Repeat("x", 300)
+	require.ErrorAs(t, TypedHandler(fn)(context.Background(), long), &invalid)
+	assert.Equal(t, strings.Repeat("x", 256)+"...", invalid.Preview)
+
+	require.ErrorAs(t, TypedHandler(fn, WithPreviewBytes(4))(context.Background(), "消息格式"), &invalid)
+	assert.Equal(t, "消...", invalid.Preview)
+
+	require.ErrorAs(t, TypedHandler(fn, WithPreviewBytes(0))(context.Background(), long), &invalid)
+	assert.Empty(t, invalid.Preview)
+}
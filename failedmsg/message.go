@@ -0,0 +1,192 @@
+// Package failedmsg 提供 RabbitMQ 发送失败消息的持久化与重放功能
+// 发送消息重试后仍失败（包括发布确认超时）时，消息被异步写入 Redis 列表或数据表，
+// 之后可以通过 Recorder.Replay 重新发送，发送成功后删除记录
+package failedmsg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// TableName 发送失败消息表名
+const TableName = "failed_messages"
+
+// DefaultRedisKey Redis 存储默认使用的列表键
+const DefaultRedisKey = "mq:failed_messages"
+
+// Message 发送失败的消息
+type Message struct {
+	ID           string         `gorm:"primaryKey;size:36" json:"id"`             // 记录 ID（uuid）
+	MQName       string         `gorm:"size:64" json:"mqName"`                    // 消息队列配置名称，为空时使用默认配置
+	QueueName    string         `gorm:"size:255" json:"queueName"`                // 队列名称，仅用于区分生产者
+	Exchange     string         `gorm:"size:255" json:"exchange"`                 // 交换机名称
+	ExchangeType string         `gorm:"size:32" json:"exchangeType"`              // 交换机类型
+	RoutingKey   string         `gorm:"size:255" json:"routingKey"`               // 路由键
+	Body         string         `gorm:"type:text;not null" json:"body"`           // 消息内容
+	Headers      map[string]any `gorm:"serializer:json;type:text" json:"headers"` // 消息头
+	Error        string         `gorm:"size:1024" json:"error"`                   // 最近一次发送失败的错误信息
+	Attempts     int            `gorm:"not null;default:1" json:"attempts"`       // 发送失败次数，首次记录时为 1，每次重放失败加 1
+	FailedAt     time.Time      `gorm:"not null" json:"failedAt"`                 // 首次发送失败的时间
+	LastFailedAt time.Time      `gorm:"not null;index" json:"lastFailedAt"`       // 最近一次发送失败的时间
+	raw          string         // Redis 存储中的原始内容，用于删除和更新列表元素
+}
+
+// TableName 指定 GORM 表名
+func (Message) TableName() string {
+	return TableName
+}
+
+// Store 发送失败消息存储
+type Store interface {
+	// Save 保存一条发送失败的消息
+	Save(ctx context.Context, msg *Message) error
+	// List 按最近一次失败的时间从早到晚获取最多 limit 条消息
+	List(ctx context.Context, limit int) ([]Message, error)
+	// Update 更新重放失败的消息（失败次数、错误信息、最近失败时间）
+	Update(ctx context.Context, msg *Message) error
+	// Delete 删除重放成功的消息
+	Delete(ctx context.Context, msg *Message) error
+	// Count 获取当前保存的消息数
+	Count(ctx context.Context) (int64, error)
+}
+
+// RedisStore 基于 Redis 列表的发送失败消息存储，消息以 JSON 保存，多实例共享
+type RedisStore struct {
+	client redis.UniversalClient
+	key    string
+}
+
+// NewRedisStore 创建基于 Redis 列表的发送失败消息存储
+// 参数：
+//   - client: Redis 客户端
+//   - key: 列表键，为空时使用 DefaultRedisKey
+//
+// 返回：
+//   - *RedisStore: 存储实例
+func NewRedisStore(client redis.UniversalClient, key string) *RedisStore {
+	if key == "" {
+		key = DefaultRedisKey
+	}
+	return &RedisStore{client: client, key: key}
+}
+
+// Save 将消息追加到列表尾部
+func (s *RedisStore) Save(ctx context.Context, msg *Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("序列化发送失败消息失败: %w", err)
+	}
+	return s.client.RPush(ctx, s.key, data).Err()
+}
+
+// List 从列表头部获取最多 limit 条消息，无法解析的元素被跳过
+func (s *RedisStore) List(ctx context.Context, limit int) ([]Message, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	items, err := s.client.LRange(ctx, s.key, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]Message, 0, len(items))
+	for _, item := range items {
+		var msg Message
+		if err := json.Unmarshal([]byte(item), &msg); err != nil {
+			continue
+		}
+		msg.raw = item
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// Update 删除原有元素并将更新后的消息追加到列表尾部，重放失败的消息不会阻塞后续消息
+func (s *RedisStore) Update(ctx context.Context, msg *Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("序列化发送失败消息失败: %w", err)
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(ctx, s.key, 1, msg.raw)
+		pipe.RPush(ctx, s.key, data)
+		return nil
+	})
+	if err == nil {
+		msg.raw = string(data)
+	}
+	return err
+}
+
+// Delete 从列表中删除消息
+func (s *RedisStore) Delete(ctx context.Context, msg *Message) error {
+	if msg.raw == "" {
+		return errors.New("消息不是从 Redis 存储中读取的")
+	}
+	return s.client.LRem(ctx, s.key, 1, msg.raw).Err()
+}
+
+// Count 获取列表长度
+func (s *RedisStore) Count(ctx context.Context) (int64, error) {
+	return s.client.LLen(ctx, s.key).Result()
+}
+
+// DBStore 基于数据表（failed_messages）的发送失败消息存储
+type DBStore struct {
+	db *gorm.DB
+}
+
+// NewDBStore 创建基于数据表的发送失败消息存储，并自动创建或更新 failed_messages 表
+// 参数：
+//   - db: 数据库连接
+//
+// 返回：
+//   - *DBStore: 存储实例
+//   - error: 建表失败时返回错误
+func NewDBStore(db *gorm.DB) (*DBStore, error) {
+	if err := db.AutoMigrate(&Message{}); err != nil {
+		return nil, fmt.Errorf("创建发送失败消息表失败: %w", err)
+	}
+	return &DBStore{db: db}, nil
+}
+
+// Save 插入一条消息
+func (s *DBStore) Save(ctx context.Context, msg *Message) error {
+	return s.db.WithContext(ctx).Create(msg).Error
+}
+
+// List 按最近一次失败的时间获取最多 limit 条消息，重放失败的消息排在后面，不会阻塞后续消息
+func (s *DBStore) List(ctx context.Context, limit int) ([]Message, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	var msgs []Message
+	err := s.db.WithContext(ctx).Order("last_failed_at, id").Limit(limit).Find(&msgs).Error
+	return msgs, err
+}
+
+// Update 更新失败次数、错误信息和最近失败时间
+func (s *DBStore) Update(ctx context.Context, msg *Message) error {
+	return s.db.WithContext(ctx).Model(&Message{}).Where("id = ?", msg.ID).Updates(map[string]any{
+		"attempts":       msg.Attempts,
+		"error":          msg.Error,
+		"last_failed_at": msg.LastFailedAt,
+	}).Error
+}
+
+// Delete 按 ID 删除消息
+func (s *DBStore) Delete(ctx context.Context, msg *Message) error {
+	return s.db.WithContext(ctx).Where("id = ?", msg.ID).Delete(&Message{}).Error
+}
+
+// Count 获取表中的消息数
+func (s *DBStore) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&Message{}).Count(&count).Error
+	return count, err
+}
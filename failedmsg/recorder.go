@@ -0,0 +1,227 @@
+package failedmsg
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/zzsen/gin_core/logger"
+)
+
+// DefaultQueueSize 写入队列的默认容量
+const DefaultQueueSize = 1024
+
+// writeTimeout 单条消息写入存储的超时时间
+const writeTimeout = 5 * time.Second
+
+// maxErrorLength 错误信息最多保留的字节数，与 failed_messages.error 列宽度一致
+const maxErrorLength = 1024
+
+// PublishFunc 重放时发送消息的函数，返回 nil 表示发送成功
+type PublishFunc func(ctx context.Context, msg *Message) error
+
+// Report 重放结果
+type Report struct {
+	Attempted int   // 本次重放的消息数
+	Succeeded int   // 发送成功并删除记录的消息数
+	Failed    int   // 发送失败、保留记录的消息数
+	Remaining int64 // 重放后仍保存的消息数
+}
+
+// Recorder 发送失败消息记录器
+// Record 只将消息放入有界队列，由后台协程写入存储，不会阻塞发送失败的调用方；队列已满时丢弃并输出警告日志
+type Recorder struct {
+	store   Store
+	queue   chan *Message
+	done    chan struct{}
+	mu      sync.RWMutex
+	closed  bool
+	stored  atomic.Int64
+	dropped atomic.Uint64
+	// replayLock 同一时间只执行一次重放，避免并发重放重复发送同一条消息
+	replayLock sync.Mutex
+}
+
+// NewRecorder 创建发送失败消息记录器并启动后台写入协程
+// 后台协程启动时读取存储中已有的消息数，作为 Stored 的初始值
+// 参数：
+//   - store: 存储实现
+//   - queueSize: 写入队列容量，<= 0 时使用 DefaultQueueSize
+//
+// 返回：
+//   - *Recorder: 记录器实例，不再使用时调用 Close 写完队列中的消息
+func NewRecorder(store Store, queueSize int) *Recorder {
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	r := &Recorder{
+		store: store,
+		queue: make(chan *Message, queueSize),
+		done:  make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// run 后台写入协程，依次将队列中的消息写入存储，队列关闭且写完后退出
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	count, err := r.store.Count(ctx)
+	cancel()
+	if err != nil {
+		logger.Warn("[消息队列] 读取发送失败消息数失败: %v", err)
+	} else {
+		r.stored.Add(count)
+	}
+
+	for msg := range r.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+		err := r.store.Save(ctx, msg)
+		cancel()
+		if err != nil {
+			logger.Error("[消息队列] 保存发送失败消息失败, exchange: %s, routingKey: %s, error: %v", msg.Exchange, msg.RoutingKey, err)
+			continue
+		}
+		r.stored.Add(1)
+	}
+}
+
+// Record 记录一条发送失败的消息
+// 未设置的 ID、FailedAt、LastFailedAt、Attempts 被补全；消息被放入写入队列后立即返回
+// 参数：
+//   - msg: 发送失败的消息，调用后不应再修改
+//
+// 返回：
+//   - bool: 是否已放入写入队列，队列已满或记录器已关闭时返回 false
+func (r *Recorder) Record(msg *Message) bool {
+	if msg.ID == "" {
+		msg.ID = uuid.NewString()
+	}
+	now := time.Now()
+	if msg.FailedAt.IsZero() {
+		msg.FailedAt = now
+	}
+	if msg.LastFailedAt.IsZero() {
+		msg.LastFailedAt = msg.FailedAt
+	}
+	if msg.Attempts <= 0 {
+		msg.Attempts = 1
+	}
+	msg.Error = truncateError(msg.Error)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		r.dropped.Add(1)
+		logger.Warn("[消息队列] 发送失败消息记录器已关闭, 丢弃记录, exchange: %s, routingKey: %s", msg.Exchange, msg.RoutingKey)
+		return false
+	}
+	select {
+	case r.queue <- msg:
+		return true
+	default:
+		r.dropped.Add(1)
+		logger.Warn("[消息队列] 发送失败消息写入队列已满, 丢弃记录, exchange: %s, routingKey: %s", msg.Exchange, msg.RoutingKey)
+		return false
+	}
+}
+
+// Stored 获取当前保存的消息数（记录器启动时存储中已有的消息数 + 写入成功数 - 重放成功数）
+func (r *Recorder) Stored() int64 {
+	return r.stored.Load()
+}
+
+// Dropped 获取因写入队列已满或记录器已关闭而丢弃的记录数
+func (r *Recorder) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+// Replay 重放保存的消息
+// 按最近一次失败的时间从早到晚读取最多 limit 条消息，逐条调用 publish：成功时删除记录，失败时失败次数加 1 并保留记录。
+// ctx 取消后停止重放并返回已完成部分的结果
+// 参数：
+//   - ctx: context
+//   - limit: 最多重放的消息数
+//   - publish: 发送消息的函数
+//
+// 返回：
+//   - Report: 重放结果
+//   - error: 读取存储失败或 ctx 已取消时返回错误，单条消息发送失败不返回错误
+func (r *Recorder) Replay(ctx context.Context, limit int, publish PublishFunc) (Report, error) {
+	r.replayLock.Lock()
+	defer r.replayLock.Unlock()
+
+	var report Report
+	msgs, err := r.store.List(ctx, limit)
+	if err != nil {
+		report.Remaining = r.Stored()
+		return report, err
+	}
+	for i := range msgs {
+		if err := ctx.Err(); err != nil {
+			report.Remaining = r.Stored()
+			return report, err
+		}
+		msg := &msgs[i]
+		report.Attempted++
+		if publishErr := publish(ctx, msg); publishErr != nil {
+			report.Failed++
+			msg.Attempts++
+			msg.Error = truncateError(publishErr.Error())
+			msg.LastFailedAt = time.Now()
+			if err := r.store.Update(ctx, msg); err != nil {
+				logger.Error("[消息队列] 更新发送失败消息失败, id: %s, error: %v", msg.ID, err)
+			}
+			continue
+		}
+		report.Succeeded++
+		if err := r.store.Delete(ctx, msg); err != nil {
+			// 消息已发送但记录未删除，下次重放时会再次发送
+			logger.Error("[消息队列] 删除已重放的发送失败消息失败, id: %s, error: %v", msg.ID, err)
+			continue
+		}
+		r.stored.Add(-1)
+	}
+	report.Remaining = r.Stored()
+	return report, nil
+}
+
+// Close 停止接收新的记录，等待写入队列中的消息写入存储
+// 参数：
+//   - ctx: 等待的截止时间
+//
+// 返回：
+//   - error: ctx 结束时仍未写完时返回错误
+func (r *Recorder) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Join(errors.New("等待发送失败消息写入超时"), ctx.Err())
+	}
+}
+
+// truncateError 截断过长的错误信息，保证不截断多字节字符
+func truncateError(s string) string {
+	if len(s) <= maxErrorLength {
+		return s
+	}
+	s = s[:maxErrorLength]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,225 @@
+// Package failedmsg 发送失败消息持久化测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含发送失败消息存储和记录器的单元测试，Redis 存储使用 miniredis，数据表存储使用 SQLite 内存数据库，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 记录的消息由后台协程写入存储，Stored 计数同步增加
+// 2. 重放成功时删除记录，重放失败时保留记录并增加失败次数
+// 3. 写入队列已满时 Record 立即返回并丢弃记录，不阻塞调用方
+// 4. 记录器启动时读取存储中已有的消息数，Close 等待队列中的消息写完
+//
+// 运行测试：go test -v ./failedmsg/...
+// ==================================================
+package failedmsg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// newRedisStore 创建使用 miniredis 的 Redis 存储
+func newRedisStore(t *testing.T) Store {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewRedisStore(client, "")
+}
+
+// newDBStore 创建使用 SQLite 内存数据库的数据表存储
+func newDBStore(t *testing.T) Store {
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	store, err := NewDBStore(db)
+	require.NoError(t, err)
+	return store
+}
+
+// stores 按名称列出两种存储的创建函数，每个测试对两种存储分别执行
+var stores = []struct {
+	name string
+	new  func(t *testing.T) Store
+}{
+	{"redis", newRedisStore},
+	{"db", newDBStore},
+}
+
+// newTestRecorder 创建记录器，测试结束时关闭
+func newTestRecorder(t *testing.T, store Store, queueSize int) *Recorder {
+	r := NewRecorder(store, queueSize)
+	t.Cleanup(func() { _ = r.Close(context.Background()) })
+	return r
+}
+
+// waitStored 等待记录器的 Stored 计数达到 n
+func waitStored(t *testing.T, r *Recorder, n int64) {
+	t.Helper()
+	require.Eventually(t, func() bool { return r.Stored() == n }, 2*time.Second, 10*time.Millisecond)
+}
+
+// TestRecorder_RecordAndReplay 测试记录和重放成功
+//
+// 【功能点】验证记录的消息包含交换机、路由键、消息内容、消息头、错误信息和失败次数，重放成功后删除记录
+// 【测试流程】
+//  1. 记录一条消息，等待写入后从存储读取，断言各字段正确、失败次数为 1
+//  2. 重放且发送函数返回成功，断言发送函数收到该消息、结果为成功 1 条，存储和 Stored 计数为 0
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	for _, s := range stores {
+		t.Run(s.name, func(t *testing.T) {
+			store := s.new(t)
+			r := newTestRecorder(t, store, 0)
+
+			assert.True(t, r.Record(&Message{
+				MQName:     "orders",
+				Exchange:   "order-exchange",
+				RoutingKey: "order.created",
+				Body:       `{"id":1}`,
+				Headers:    map[string]any{"tenant": "a"},
+				Error:      "发布确认超时",
+			}))
+			waitStored(t, r, 1)
+
+			msgs, err := store.List(context.Background(), 10)
+			require.NoError(t, err)
+			require.Len(t, msgs, 1)
+			msg := msgs[0]
+			assert.NotEmpty(t, msg.ID)
+			assert.Equal(t, "orders", msg.MQName)
+			assert.Equal(t, "order-exchange", msg.Exchange)
+			assert.Equal(t, "order.created", msg.RoutingKey)
+			assert.Equal(t, `{"id":1}`, msg.Body)
+			assert.Equal(t, map[string]any{"tenant": "a"}, msg.Headers)
+			assert.Equal(t, "发布确认超时", msg.Error)
+			assert.Equal(t, 1, msg.Attempts)
+			assert.False(t, msg.FailedAt.IsZero())
+
+			var published []string
+			report, err := r.Replay(context.Background(), 10, func(ctx context.Context, msg *Message) error {
+				published = append(published, msg.Body)
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, Report{Attempted: 1, Succeeded: 1, Remaining: 0}, report)
+			assert.Equal(t, []string{`{"id":1}`}, published)
+			count, err := store.Count(context.Background())
+			require.NoError(t, err)
+			assert.Zero(t, count)
+			assert.Zero(t, r.Stored())
+		})
+	}
+}
+
+// TestRecorder_ReplayFailure 测试重放失败
+//
+// 【功能点】验证重放失败时保留记录、失败次数加 1 并更新错误信息，其他消息照常重放
+// 【测试流程】
+//  1. 记录 broken 和 ok 两条消息
+//  2. 重放时 broken 返回错误，断言结果为成功 1 条、失败 1 条、剩余 1 条
+//  3. 从存储读取，断言只剩 broken，失败次数为 2，错误信息为重放时的错误
+//  4. 再次重放失败，断言失败次数为 3
+func TestRecorder_ReplayFailure(t *testing.T) {
+	for _, s := range stores {
+		t.Run(s.name, func(t *testing.T) {
+			store := s.new(t)
+			r := newTestRecorder(t, store, 0)
+			r.Record(&Message{Exchange: "e", Body: "broken", Error: "首次失败"})
+			r.Record(&Message{Exchange: "e", Body: "ok", Error: "首次失败"})
+			waitStored(t, r, 2)
+
+			publish := func(ctx context.Context, msg *Message) error {
+				if msg.Body == "broken" {
+					return errors.New("交换机不存在")
+				}
+				return nil
+			}
+			report, err := r.Replay(context.Background(), 10, publish)
+			require.NoError(t, err)
+			assert.Equal(t, Report{Attempted: 2, Succeeded: 1, Failed: 1, Remaining: 1}, report)
+
+			msgs, err := store.List(context.Background(), 10)
+			require.NoError(t, err)
+			require.Len(t, msgs, 1)
+			assert.Equal(t, "broken", msgs[0].Body)
+			assert.Equal(t, 2, msgs[0].Attempts)
+			assert.Equal(t, "交换机不存在", msgs[0].Error)
+			assert.True(t, msgs[0].LastFailedAt.After(msgs[0].FailedAt))
+
+			_, err = r.Replay(context.Background(), 10, publish)
+			require.NoError(t, err)
+			msgs, err = store.List(context.Background(), 10)
+			require.NoError(t, err)
+			require.Len(t, msgs, 1)
+			assert.Equal(t, 3, msgs[0].Attempts)
+		})
+	}
+}
+
+// blockingStore 写入时阻塞直到 release 关闭的存储
+type blockingStore struct {
+	Store
+	release chan struct{}
+}
+
+func (s *blockingStore) Save(ctx context.Context, msg *Message) error {
+	<-s.release
+	return s.Store.Save(ctx, msg)
+}
+
+// TestRecorder_QueueFull 测试写入队列已满
+//
+// 【功能点】验证存储写入阻塞、写入队列已满时 Record 立即返回 false 并计入 Dropped，Close 等待已入队的消息写完
+// 【测试流程】
+//  1. 使用写入阻塞的存储和容量为 1 的队列，连续记录多条消息，断言总耗时很短且部分记录被丢弃
+//  2. 解除阻塞后 Close，断言写入的消息数等于入队数，Close 后的记录被丢弃
+func TestRecorder_QueueFull(t *testing.T) {
+	store := &blockingStore{Store: newRedisStore(t), release: make(chan struct{})}
+	r := NewRecorder(store, 1)
+
+	start := time.Now()
+	accepted := 0
+	for i := range 5 {
+		if r.Record(&Message{Exchange: "e", Body: fmt.Sprint(i)}) {
+			accepted++
+		}
+	}
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.Less(t, accepted, 5)
+	assert.Equal(t, uint64(5-accepted), r.Dropped())
+
+	close(store.release)
+	require.NoError(t, r.Close(context.Background()))
+	count, err := store.Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(accepted), count)
+
+	assert.False(t, r.Record(&Message{Exchange: "e", Body: "closed"}))
+}
+
+// TestRecorder_InitialCount 测试启动时读取已有的消息数
+//
+// 【功能点】验证新建的记录器读取存储中已有的消息数作为 Stored 的初始值
+// 【测试流程】直接向存储写入两条消息后创建记录器，断言 Stored 变为 2
+func TestRecorder_InitialCount(t *testing.T) {
+	store := newRedisStore(t)
+	require.NoError(t, store.Save(context.Background(), &Message{ID: "1", Body: "a"}))
+	require.NoError(t, store.Save(context.Background(), &Message{ID: "2", Body: "b"}))
+
+	r := newTestRecorder(t, store, 0)
+	waitStored(t, r, 2)
+}
@@ -0,0 +1,284 @@
+// Package featureflags 提供轻量的功能开关，不依赖第三方服务
+// 按 featureFlags 配置在每次请求时判断功能是否开启：支持按运行环境生效、用户白名单，
+// 以及按用户 ID 哈希的稳定灰度（同一用户的结果固定）。未知的开关按关闭处理并计数，首次出现时记录警告日志，便于发现拼写错误
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// bucketCount 灰度的分桶数，灰度比例精确到 0.01%
+const bucketCount = 10000
+
+// flagSet 已加载的功能开关及运行环境
+type flagSet struct {
+	env   string
+	flags config.FeatureFlags
+}
+
+var (
+	// current 当前的功能开关，未加载时为 nil，所有开关均按未知处理
+	current atomic.Pointer[flagSet]
+	// unknownCounts 未知开关的判断次数，key 为开关名称，value 为 *atomic.Int64
+	unknownCounts sync.Map
+	// userIDFunc 从 context.Context 中获取用户 ID 的函数，类型为 func(context.Context) string
+	userIDFunc atomic.Value
+)
+
+// userIDKey WithUserID 在 context.Context 中的存储键类型
+type userIDKey struct{}
+
+// FlagState 功能开关的配置和生效状态
+type FlagState struct {
+	// Name 开关名称
+	Name string `json:"name"`
+	// Enabled 是否开启
+	Enabled bool `json:"enabled"`
+	// Percentage 灰度比例（0-100）
+	Percentage float64 `json:"percentage"`
+	// AllowUsers 始终开启的用户 ID
+	AllowUsers []string `json:"allowUsers"`
+	// AllowEnvs 生效的运行环境，为空时所有环境均生效
+	AllowEnvs []string `json:"allowEnvs"`
+	// Attributes 自定义属性
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// Active 在当前运行环境是否生效（开启且运行环境在 allowEnvs 中），生效时按 allowUsers 和 percentage 判断单个用户
+	Active bool `json:"active"`
+}
+
+// Snapshot 功能开关的整体状态，用于管理接口
+type Snapshot struct {
+	// Env 当前运行环境
+	Env string `json:"env"`
+	// Flags 按名称排序的功能开关
+	Flags []FlagState `json:"flags"`
+	// Unknown 未知开关的判断次数，按开关名称索引
+	Unknown map[string]int64 `json:"unknown"`
+}
+
+// Load 加载功能开关配置，替换之前加载的全部开关，可并发调用
+// 框架启动时按 featureFlags 配置和当前运行环境加载
+//
+// 参数：
+//   - flags: 功能开关配置
+//   - env: 当前运行环境，用于匹配 allowEnvs
+func Load(flags config.FeatureFlags, env string) {
+	current.Store(&flagSet{env: env, flags: maps.Clone(flags)})
+	logger.Info("[功能开关] 加载功能开关 %d 个, env: %s", len(flags), env)
+}
+
+// Reload 重新加载功能开关配置，保持当前运行环境，立即对后续的判断生效，可并发调用
+// 用于配置变更后（如监听 Etcd 中的配置）更新开关，配置有变化的开关记录 info 日志
+//
+// 参数：
+//   - flags: 新的功能开关配置
+func Reload(flags config.FeatureFlags) {
+	previous := current.Load()
+	next := &flagSet{flags: maps.Clone(flags)}
+	if previous != nil {
+		next.env = previous.env
+	}
+	current.Store(next)
+	if previous == nil {
+		return
+	}
+	for _, name := range slices.Sorted(maps.Keys(flags)) {
+		if old, ok := previous.flags[name]; !ok || !reflect.DeepEqual(old, flags[name]) {
+			logger.Info("[功能开关] 功能开关已更新: %s", name)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(previous.flags)) {
+		if _, ok := flags[name]; !ok {
+			logger.Info("[功能开关] 功能开关已移除: %s", name)
+		}
+	}
+}
+
+// SetUserIDFunc 设置从 context.Context 中获取用户 ID 的函数，IsEnabled 使用该函数获取灰度和白名单判断的用户
+// 框架启动时设置为从请求上下文（ginContext.GetUserID / ginContext.FromStdContext）中获取；
+// 未设置时只识别通过 WithUserID 存入的用户 ID
+//
+// 参数：
+//   - fn: 获取用户 ID 的函数，未登录时返回空字符串
+func SetUserIDFunc(fn func(ctx context.Context) string) {
+	userIDFunc.Store(fn)
+}
+
+// WithUserID 将用户 ID 存入 context.Context，用于 MQ 消费者、定时任务等没有请求上下文的场景
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserID 获取 IsEnabled 判断时使用的用户 ID
+// 优先使用 WithUserID 存入的用户 ID，否则使用 SetUserIDFunc 设置的函数
+func UserID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if userID, ok := ctx.Value(userIDKey{}).(string); ok {
+		return userID
+	}
+	if fn, ok := userIDFunc.Load().(func(context.Context) string); ok && fn != nil {
+		return fn(ctx)
+	}
+	return ""
+}
+
+// IsEnabled 判断功能开关对当前请求是否开启，用户 ID 从 ctx 中获取（见 UserID）
+// 判断顺序：
+//  1. 未知的开关返回 false，并计数（首次出现时记录警告日志）
+//  2. 未开启，或当前运行环境不在 allowEnvs 中时返回 false
+//  3. 用户在 allowUsers 中时返回 true
+//  4. 灰度比例为 100 时返回 true，为 0 或无法获取用户 ID 时返回 false
+//  5. 否则按开关名称和用户 ID 的哈希值分桶，同一用户对同一开关的结果固定
+//
+// 参数：
+//   - ctx: 上下文，可直接传入 *gin.Context 或 c.Request.Context()
+//   - name: 开关名称
+//
+// 返回：
+//   - bool: 是否开启
+//
+// 使用示例：
+//
+//	if featureflags.IsEnabled(ctx, "newCheckout") {
+//	  return newCheckout(ctx, order)
+//	}
+func IsEnabled(ctx context.Context, name string) bool {
+	return IsEnabledFor(name, UserID(ctx))
+}
+
+// IsEnabledFor 判断功能开关对指定用户是否开启，判断规则同 IsEnabled
+//
+// 参数：
+//   - name: 开关名称
+//   - userID: 用户 ID，为空时只有灰度比例为 100 的开关返回 true
+//
+// 返回：
+//   - bool: 是否开启
+func IsEnabledFor(name, userID string) bool {
+	set := current.Load()
+	flag, ok := set.lookup(name)
+	if !ok {
+		recordUnknown(name)
+		return false
+	}
+	if !set.active(flag) {
+		return false
+	}
+	if userID != "" && slices.Contains(flag.AllowUsers, userID) {
+		return true
+	}
+	percentage := flag.GetPercentage()
+	switch {
+	case percentage >= 100:
+		return true
+	case percentage <= 0 || userID == "":
+		return false
+	}
+	return float64(bucket(name, userID)) < percentage*bucketCount/100
+}
+
+// IsActive 判断功能开关在当前运行环境是否生效（开启且运行环境在 allowEnvs 中），不考虑用户和灰度比例
+// 用于启动时决定是否挂载路由等与单个请求无关的场景，未知的开关返回 false 并计数
+//
+// 参数：
+//   - name: 开关名称
+//
+// 返回：
+//   - bool: 是否生效
+func IsActive(name string) bool {
+	set := current.Load()
+	flag, ok := set.lookup(name)
+	if !ok {
+		recordUnknown(name)
+		return false
+	}
+	return set.active(flag)
+}
+
+// Flags 获取所有功能开关的配置和生效状态，以及未知开关的判断次数
+//
+// 返回：
+//   - Snapshot: 功能开关的整体状态
+func Flags() Snapshot {
+	snapshot := Snapshot{Flags: []FlagState{}, Unknown: UnknownFlags()}
+	set := current.Load()
+	if set == nil {
+		return snapshot
+	}
+	snapshot.Env = set.env
+	for _, name := range slices.Sorted(maps.Keys(set.flags)) {
+		flag := set.flags[name]
+		snapshot.Flags = append(snapshot.Flags, FlagState{
+			Name:       name,
+			Enabled:    flag.Enabled,
+			Percentage: flag.GetPercentage(),
+			AllowUsers: flag.AllowUsers,
+			AllowEnvs:  flag.AllowEnvs,
+			Attributes: flag.Attributes,
+			Active:     set.active(flag),
+		})
+	}
+	return snapshot
+}
+
+// UnknownFlags 获取未知开关的判断次数
+//
+// 返回：
+//   - map[string]int64: 按开关名称索引的判断次数
+func UnknownFlags() map[string]int64 {
+	counts := make(map[string]int64)
+	unknownCounts.Range(func(key, value any) bool {
+		counts[key.(string)] = value.(*atomic.Int64).Load()
+		return true
+	})
+	return counts
+}
+
+// Reset 清除已加载的功能开关和未知开关的计数，用于测试
+func Reset() {
+	current.Store(nil)
+	unknownCounts.Clear()
+}
+
+// lookup 查找功能开关，未加载时均不存在
+func (s *flagSet) lookup(name string) (config.FlagConfig, bool) {
+	if s == nil {
+		return config.FlagConfig{}, false
+	}
+	flag, ok := s.flags[name]
+	return flag, ok
+}
+
+// active 判断功能开关在当前运行环境是否生效
+func (s *flagSet) active(flag config.FlagConfig) bool {
+	return flag.Enabled && (len(flag.AllowEnvs) == 0 || slices.Contains(flag.AllowEnvs, s.env))
+}
+
+// recordUnknown 记录未知开关的判断次数，首次出现时记录警告日志
+func recordUnknown(name string) {
+	counter, loaded := unknownCounts.LoadOrStore(name, new(atomic.Int64))
+	counter.(*atomic.Int64).Add(1)
+	if !loaded {
+		logger.Warn("[功能开关] 未知的功能开关: %s, 按关闭处理, 请检查开关名称是否拼写错误或 featureFlags 配置", name)
+	}
+}
+
+// bucket 按开关名称和用户 ID 的哈希值计算分桶，范围为 [0, bucketCount)
+// 哈希值包含开关名称，不同开关灰度到的用户互不相关
+func bucket(name, userID string) uint32 {
+	h := fnv.New32a()
+	_, _ = fmt.Fprintf(h, "%s:%s", name, userID)
+	return h.Sum32() % bucketCount
+}
@@ -0,0 +1,231 @@
+// Package featureflags 功能开关测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含功能开关判断的单元测试，不需要外部依赖。
+// 管理接口和请求上下文中的用户 ID 见 core 包的 TestFeatureFlags_*。
+//
+// 测试覆盖内容：
+// 1. 灰度按用户 ID 哈希，同一用户的结果固定，灰度到的用户比例接近配置的比例
+// 2. 未开启或运行环境不在 allowEnvs 中时关闭，allowUsers 中的用户不受灰度比例影响
+// 3. 未知的开关返回 false 并计数，首次出现时记录警告日志
+// 4. 重新加载后使用新的灰度比例，保持运行环境
+// 5. OptionFunc 只在开关生效时挂载路由
+//
+// 运行测试：go test -v ./featureflags/...
+// ==================================================
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// percentage 创建灰度比例配置
+func percentage(p float64) *float64 {
+	return &p
+}
+
+// setupFlags 加载功能开关，测试结束后清除
+func setupFlags(t *testing.T, flags config.FeatureFlags, env string) {
+	t.Cleanup(Reset)
+	Reset()
+	Load(flags, env)
+}
+
+// TestIsEnabled_PercentageStable 测试按用户灰度
+//
+// 【功能点】验证灰度结果对同一用户固定，灰度到的用户比例接近配置的比例，不同开关灰度到的用户不同
+// 【测试流程】
+//  1. 加载 30% 灰度的 checkout 和 search 开关
+//  2. 对 10000 个用户各判断两次，断言两次结果相同，开启比例在 28%-32% 之间
+//  3. 断言两个开关的结果不完全相同；未配置灰度比例时所有用户开启，无用户 ID 时 30% 灰度的开关关闭
+func TestIsEnabled_PercentageStable(t *testing.T) {
+	setupFlags(t, config.FeatureFlags{
+		"checkout": {Enabled: true, Percentage: percentage(30)},
+		"search":   {Enabled: true, Percentage: percentage(30)},
+		"full":     {Enabled: true},
+	}, "prod")
+
+	const users = 10000
+	enabled, differ := 0, 0
+	for i := range users {
+		userID := fmt.Sprintf("u-%d", i)
+		first := IsEnabledFor("checkout", userID)
+		assert.Equal(t, first, IsEnabledFor("checkout", userID), userID)
+		assert.Equal(t, first, IsEnabled(WithUserID(context.Background(), userID), "checkout"), userID)
+		if first {
+			enabled++
+		}
+		if first != IsEnabledFor("search", userID) {
+			differ++
+		}
+		assert.True(t, IsEnabledFor("full", userID))
+	}
+	assert.InDelta(t, 0.3, float64(enabled)/users, 0.02)
+	assert.Positive(t, differ, "不同开关灰度到的用户应互不相关")
+	assert.False(t, IsEnabledFor("checkout", ""))
+	assert.True(t, IsEnabledFor("full", ""))
+}
+
+// TestIsEnabled_EnvGating 测试按运行环境生效
+//
+// 【功能点】验证运行环境不在 allowEnvs 中或开关未开启时关闭，allowUsers 中的用户同样关闭
+// 【测试流程】
+//  1. 在 test 环境加载 allowEnvs=[prod] 的开关，断言关闭且 IsActive 为 false
+//  2. 在 prod 环境重新加载，断言开启且 IsActive 为 true
+//  3. enabled=false 的开关对 allowUsers 中的用户同样关闭
+func TestIsEnabled_EnvGating(t *testing.T) {
+	flags := config.FeatureFlags{
+		"report": {Enabled: true, AllowEnvs: []string{"prod"}},
+		"off":    {Enabled: false, AllowUsers: []string{"u-1"}},
+	}
+	setupFlags(t, flags, "test")
+	assert.False(t, IsEnabledFor("report", "u-1"))
+	assert.False(t, IsActive("report"))
+
+	Load(flags, "prod")
+	assert.True(t, IsEnabledFor("report", "u-1"))
+	assert.True(t, IsActive("report"))
+	assert.False(t, IsEnabledFor("off", "u-1"))
+	assert.False(t, IsActive("off"))
+}
+
+// TestIsEnabled_AllowUsers 测试用户白名单
+//
+// 【功能点】验证 allowUsers 中的用户不受灰度比例影响，用户 ID 可以通过 SetUserIDFunc 从上下文中获取
+// 【测试流程】
+//  1. 加载灰度比例为 0、allowUsers=[u-1] 的开关
+//  2. 断言 u-1 开启，u-2 关闭
+//  3. 设置 SetUserIDFunc 后通过 IsEnabled 判断，断言使用该函数返回的用户 ID，WithUserID 优先
+func TestIsEnabled_AllowUsers(t *testing.T) {
+	setupFlags(t, config.FeatureFlags{
+		"beta": {Enabled: true, Percentage: percentage(0), AllowUsers: []string{"u-1"}},
+	}, "prod")
+	assert.True(t, IsEnabledFor("beta", "u-1"))
+	assert.False(t, IsEnabledFor("beta", "u-2"))
+
+	type ctxKey struct{}
+	SetUserIDFunc(func(ctx context.Context) string {
+		userID, _ := ctx.Value(ctxKey{}).(string)
+		return userID
+	})
+	t.Cleanup(func() { SetUserIDFunc(nil) })
+	ctx := context.WithValue(context.Background(), ctxKey{}, "u-1")
+	assert.True(t, IsEnabled(ctx, "beta"))
+	assert.False(t, IsEnabled(WithUserID(ctx, "u-2"), "beta"))
+	assert.False(t, IsEnabled(context.Background(), "beta"))
+}
+
+// TestIsEnabled_UnknownFlag 测试未知的开关
+//
+// 【功能点】验证未知的开关返回 false 并计数，只在首次出现时记录警告日志，计数在 Flags 中展示
+// 【测试流程】
+//  1. 加载 checkout 开关，判断 3 次拼写错误的 chekout，通过 IsActive 判断 1 次
+//  2. 断言均返回 false，UnknownFlags 和 Flags().Unknown 中 chekout 的计数为 4
+//  3. 断言警告日志只记录一次，包含开关名称
+func TestIsEnabled_UnknownFlag(t *testing.T) {
+	setupFlags(t, config.FeatureFlags{"checkout": {Enabled: true}}, "prod")
+	hook := logtest.NewLocal(logger.Logger)
+	defer hook.Reset()
+
+	for range 3 {
+		assert.False(t, IsEnabledFor("chekout", "u-1"))
+	}
+	assert.False(t, IsActive("chekout"))
+	assert.Equal(t, map[string]int64{"chekout": 4}, UnknownFlags())
+	assert.Equal(t, int64(4), Flags().Unknown["chekout"])
+
+	warnings := 0
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == logrus.WarnLevel && strings.Contains(entry.Message, "未知的功能开关: chekout") {
+			warnings++
+		}
+	}
+	assert.Equal(t, 1, warnings)
+}
+
+// TestReload_Percentage 测试重新加载灰度比例
+//
+// 【功能点】验证重新加载后立即使用新的灰度比例，保持运行环境，记录更新和移除的开关
+// 【测试流程】
+//  1. 在 prod 环境加载灰度比例为 0 的 checkout 开关和 legacy 开关，断言用户均关闭
+//  2. 重新加载 checkout 为 50%，移除 legacy，断言约一半用户开启且结果与 bucket 一致，Flags 中的比例为 50、运行环境仍为 prod
+//  3. 断言日志记录 checkout 已更新、legacy 已移除
+func TestReload_Percentage(t *testing.T) {
+	setupFlags(t, config.FeatureFlags{
+		"checkout": {Enabled: true, Percentage: percentage(0), AllowEnvs: []string{"prod"}},
+		"legacy":   {Enabled: true},
+	}, "prod")
+	for i := range 100 {
+		assert.False(t, IsEnabledFor("checkout", fmt.Sprintf("u-%d", i)))
+	}
+
+	hook := logtest.NewLocal(logger.Logger)
+	defer hook.Reset()
+	Reload(config.FeatureFlags{
+		"checkout": {Enabled: true, Percentage: percentage(50), AllowEnvs: []string{"prod"}},
+	})
+	enabled := 0
+	for i := range 1000 {
+		userID := fmt.Sprintf("u-%d", i)
+		got := IsEnabledFor("checkout", userID)
+		assert.Equal(t, bucket("checkout", userID) < 5000, got, userID)
+		if got {
+			enabled++
+		}
+	}
+	assert.InDelta(t, 500, enabled, 60)
+
+	snapshot := Flags()
+	assert.Equal(t, "prod", snapshot.Env)
+	require.Len(t, snapshot.Flags, 1)
+	assert.Equal(t, 50.0, snapshot.Flags[0].Percentage)
+	assert.True(t, snapshot.Flags[0].Active)
+
+	var messages []string
+	for _, entry := range hook.AllEntries() {
+		messages = append(messages, entry.Message)
+	}
+	assert.Contains(t, messages, "[功能开关] 功能开关已更新: checkout")
+	assert.Contains(t, messages, "[功能开关] 功能开关已移除: legacy")
+}
+
+// TestOptionFunc 测试按开关挂载路由
+//
+// 【功能点】验证开关在当前运行环境生效时挂载路由，否则不挂载
+// 【测试流程】
+//  1. 加载生效的 v2 开关和只在 prod 生效的 v3 开关，当前运行环境为 test
+//  2. 分别用 OptionFunc 包装注册 /v2、/v3 的选项函数
+//  3. 断言 /v2 返回 200，/v3 返回 404
+func TestOptionFunc(t *testing.T) {
+	setupFlags(t, config.FeatureFlags{
+		"v2": {Enabled: true, Percentage: percentage(10)},
+		"v3": {Enabled: true, AllowEnvs: []string{"prod"}},
+	}, "test")
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	for _, name := range []string{"v2", "v3"} {
+		OptionFunc(name, func(e *gin.Engine) {
+			e.GET("/"+name, func(c *gin.Context) { c.Status(http.StatusOK) })
+		})(engine)
+	}
+
+	for path, status := range map[string]int{"/v2": http.StatusOK, "/v3": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, status, w.Code, path)
+	}
+}
@@ -0,0 +1,27 @@
+package featureflags
+
+import "github.com/gin-gonic/gin"
+
+// OptionFunc 包装路由选项函数，功能开关在当前运行环境生效时才挂载其中的路由
+// 在引擎初始化（注册路由）时判断一次，只考虑 enabled 和 allowEnvs；
+// 需要按用户灰度时，在处理函数中使用 ginContext.Feature 判断
+//
+// 参数：
+//   - name: 开关名称
+//   - fn: 路由选项函数
+//
+// 返回：
+//   - gin.OptionFunc: 包装后的路由选项函数，可直接传给 core.AddOptionFunc
+//
+// 使用示例：
+//
+//	core.AddOptionFunc(featureflags.OptionFunc("newCheckout", func(engine *gin.Engine) {
+//	  engine.POST("/v2/checkout", checkout.V2)
+//	}))
+func OptionFunc(name string, fn gin.OptionFunc) gin.OptionFunc {
+	return func(engine *gin.Engine) {
+		if IsActive(name) {
+			fn(engine)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package httpcache 提供 HTTP 响应缓存的存储功能
+// 本文件实现基于 Redis 的响应缓存存储
+package httpcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// purgeScanCount Purge 时每次 SCAN 的数量
+const purgeScanCount = 100
+
+// globReplacer 转义 SCAN MATCH 模式中的特殊字符
+var globReplacer = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// RedisStore Redis 响应缓存存储
+// 适用于分布式部署场景，多个实例共享缓存
+type RedisStore struct {
+	client    redis.UniversalClient
+	keyPrefix string
+}
+
+// NewRedisStore 创建 Redis 响应缓存存储
+// client: Redis 客户端
+// keyPrefix: 键前缀，如 "http_cache:"
+func NewRedisStore(client redis.UniversalClient, keyPrefix string) *RedisStore {
+	return &RedisStore{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+// Get 获取缓存
+func (rs *RedisStore) Get(ctx context.Context, key string) (*Entry, error) {
+	data, err := rs.client.Get(ctx, rs.keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("解析缓存失败: %w", err)
+	}
+	return &entry, nil
+}
+
+// Set 保存缓存
+func (rs *RedisStore) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return rs.client.Set(ctx, rs.keyPrefix+key, data, ttl).Err()
+}
+
+// Purge 使用 SCAN 查找并删除以 prefix 开头的所有缓存
+// 集群模式下 SCAN 只遍历单个节点，需要在每个主节点上执行
+func (rs *RedisStore) Purge(ctx context.Context, prefix string) error {
+	match := globReplacer.Replace(rs.keyPrefix+prefix) + "*"
+	if cluster, ok := rs.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return purgeMatched(ctx, node, match)
+		})
+	}
+	return purgeMatched(ctx, rs.client, match)
+}
+
+// purgeMatched 删除匹配 match 的所有键
+func purgeMatched(ctx context.Context, client redis.UniversalClient, match string) error {
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, match, purgeScanCount).Result()
+		if err != nil {
+			return err
+		}
+		// 逐个删除，避免集群模式下跨槽位的 DEL
+		for _, key := range keys {
+			if err := client.Del(ctx, key).Err(); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// Close 关闭存储
+// Redis 客户端由调用方管理，这里不关闭客户端
+func (rs *RedisStore) Close() error {
+	return nil
+}
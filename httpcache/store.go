@@ -0,0 +1,146 @@
+// Package httpcache 提供 HTTP 响应缓存的存储功能
+// 支持内存和 Redis 两种存储方式，适用于单机和分布式场景
+package httpcache
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry 缓存的响应
+type Entry struct {
+	Status   int         `json:"status"`   // 响应状态码
+	Header   http.Header `json:"header"`   // 响应头
+	Body     []byte      `json:"body"`     // 响应体
+	ETag     string      `json:"etag"`     // 强 ETag，响应体的哈希值
+	StoredAt time.Time   `json:"storedAt"` // 缓存时间，用于计算 Age 响应头
+}
+
+// Store 响应缓存存储接口
+type Store interface {
+	// Get 获取未过期的缓存，不存在时返回 nil, nil
+	Get(ctx context.Context, key string) (*Entry, error)
+
+	// Set 保存缓存，过期时间为 ttl
+	Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error
+
+	// Purge 删除以 prefix 开头的所有缓存
+	Purge(ctx context.Context, prefix string) error
+
+	// Close 关闭存储，释放资源
+	Close() error
+}
+
+// MemoryStore 内存响应缓存存储
+// 适用于单机部署和测试场景，后台协程定期清理过期的缓存
+type MemoryStore struct {
+	mu        sync.RWMutex
+	items     map[string]*memoryItem
+	interval  time.Duration
+	stopCh    chan struct{}
+	closeOnce sync.Once
+}
+
+// memoryItem 内存缓存条目
+type memoryItem struct {
+	entry    *Entry
+	expireAt time.Time
+}
+
+// NewMemoryStore 创建内存响应缓存存储
+// cleanupInterval: 清理过期缓存的间隔时间
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	ms := &MemoryStore{
+		items:    make(map[string]*memoryItem),
+		interval: cleanupInterval,
+		stopCh:   make(chan struct{}),
+	}
+
+	// 启动清理协程
+	go ms.cleanup()
+
+	return ms
+}
+
+// Get 获取未过期的缓存
+func (ms *MemoryStore) Get(ctx context.Context, key string) (*Entry, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	item, ok := ms.items[key]
+	if !ok || time.Now().After(item.expireAt) {
+		return nil, nil
+	}
+	return item.entry, nil
+}
+
+// Set 保存缓存
+func (ms *MemoryStore) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.items[key] = &memoryItem{
+		entry:    entry,
+		expireAt: time.Now().Add(ttl),
+	}
+	return nil
+}
+
+// Purge 删除以 prefix 开头的所有缓存
+func (ms *MemoryStore) Purge(ctx context.Context, prefix string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for key := range ms.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(ms.items, key)
+		}
+	}
+	return nil
+}
+
+// Len 返回当前存储的缓存数量（包含尚未清理的过期缓存）
+func (ms *MemoryStore) Len() int {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return len(ms.items)
+}
+
+// cleanup 定期清理过期缓存
+func (ms *MemoryStore) cleanup() {
+	ticker := time.NewTicker(ms.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ms.doCleanup()
+		case <-ms.stopCh:
+			return
+		}
+	}
+}
+
+// doCleanup 执行清理操作
+func (ms *MemoryStore) doCleanup() {
+	now := time.Now()
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for key, item := range ms.items {
+		if now.After(item.expireAt) {
+			delete(ms.items, key)
+		}
+	}
+}
+
+// Close 关闭存储，停止清理协程
+func (ms *MemoryStore) Close() error {
+	ms.closeOnce.Do(func() {
+		close(ms.stopCh)
+	})
+	return nil
+}
@@ -0,0 +1,185 @@
+// Package i18n 提供响应消息的国际化支持
+//
+// 消息目录按语言区域（如 "zh-CN"、"en-US"）组织，键为响应码（如 "20000"）或命名消息 ID（如 "exception.unknown"）。
+// 框架内置的目录以 YAML 文件嵌入（locales/*.yaml），应用可在启动前通过 Register（或 core.RegisterMessages）
+// 追加或覆盖消息。查找顺序为：当前语言区域 → 默认语言区域 → 原始字符串。
+//
+// 当前请求的语言区域由 I18nHandler 中间件根据查询参数、请求头和 Accept-Language 协商后写入 gin 上下文，
+// 通过 Locale 读取。
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// LocaleKey gin 上下文中保存语言区域的键，与 ginContext.SetLocale 写入的旧版键一致
+const LocaleKey = "locale"
+
+// DefaultLocale 框架默认语言区域
+const DefaultLocale = "zh-CN"
+
+//go:embed locales/*.yaml
+var localeFS embed.FS
+
+var (
+	mu            sync.RWMutex
+	catalogs      = map[string]map[string]string{}
+	defaultLocale = DefaultLocale
+)
+
+func init() {
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("[i18n] 读取内置消息目录失败: %v", err))
+	}
+	for _, entry := range entries {
+		data, err := localeFS.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("[i18n] 读取内置消息目录 %s 失败: %v", entry.Name(), err))
+		}
+		messages := map[string]string{}
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("[i18n] 解析内置消息目录 %s 失败: %v", entry.Name(), err))
+		}
+		Register(strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())), messages)
+	}
+}
+
+// Register 注册消息目录
+// 同一语言区域多次注册时合并，已存在的键被覆盖，可用于覆盖框架内置消息
+// 参数：
+//   - locale: 语言区域，如 "en-US"，大小写和分隔符会被规范化（en_us → en-US）
+//   - messages: 消息 ID 到消息文本的映射，响应码消息以响应码字符串为键（如 "20000"）
+func Register(locale string, messages map[string]string) {
+	locale = Normalize(locale)
+	if locale == "" {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	catalog, ok := catalogs[locale]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		catalogs[locale] = catalog
+	}
+	for key, msg := range messages {
+		catalog[key] = msg
+	}
+}
+
+// SetDefaultLocale 设置默认语言区域
+// 参数：
+//   - locale: 语言区域，为空时恢复为 DefaultLocale
+func SetDefaultLocale(locale string) {
+	locale = Normalize(locale)
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	mu.Lock()
+	defaultLocale = locale
+	mu.Unlock()
+}
+
+// GetDefaultLocale 获取默认语言区域
+func GetDefaultLocale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultLocale
+}
+
+// Locales 获取已注册消息目录的语言区域，按字母排序
+func Locales() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Lookup 查找消息
+// 依次在 locale 和默认语言区域的目录中查找
+// 参数：
+//   - locale: 语言区域，为空时只查找默认语言区域
+//   - key: 响应码字符串或消息 ID
+//
+// 返回：
+//   - string: 消息文本
+//   - bool: 是否找到
+func Lookup(locale, key string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if msg, ok := catalogs[Normalize(locale)][key]; ok {
+		return msg, true
+	}
+	msg, ok := catalogs[defaultLocale][key]
+	return msg, ok
+}
+
+// Translate 翻译消息，未找到时返回 key 本身
+// 参数：
+//   - locale: 语言区域
+//   - key: 响应码字符串或消息 ID
+//   - args: 占位符参数，消息中的 {name} 被替换为 args[name]
+//
+// 返回：
+//   - string: 翻译后的消息
+func Translate(locale, key string, args map[string]string) string {
+	msg, ok := Lookup(locale, key)
+	if !ok {
+		msg = key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	pairs := make([]string, 0, len(args)*2)
+	for name, value := range args {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+// Locale 获取当前请求的语言区域，未经 I18nHandler 协商时返回默认语言区域
+// 参数：
+//   - c: Gin上下文，可以为 nil
+//
+// 返回：
+//   - string: 语言区域
+func Locale(c *gin.Context) string {
+	if c != nil {
+		if locale := c.GetString(LocaleKey); locale != "" {
+			return locale
+		}
+	}
+	return GetDefaultLocale()
+}
+
+// Normalize 规范化语言区域标记：语言小写、地区大写、分隔符统一为 "-"（如 en_us → en-US）
+func Normalize(tag string) string {
+	tag = strings.TrimSpace(strings.ReplaceAll(tag, "_", "-"))
+	if tag == "" {
+		return ""
+	}
+	parts := strings.Split(tag, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		} else if len(parts[i]) == 4 {
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
+		} else {
+			parts[i] = strings.ToLower(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
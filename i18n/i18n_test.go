@@ -0,0 +1,193 @@
+// Package i18n 国际化消息目录测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含消息目录、Accept-Language 解析和语言协商的单元测试。
+//
+// 测试覆盖内容：
+// 1. 内置 YAML 消息目录的加载
+// 2. Accept-Language 的权重解析、排序及非法项忽略
+// 3. 语言协商：精确匹配、按语言部分匹配、回退默认语言区域
+// 4. 消息查找的回退链：当前语言区域 → 默认语言区域 → 原始字符串
+// 5. 注册的消息覆盖内置消息
+//
+// 运行测试：go test -v ./i18n/...
+// ==================================================
+package i18n
+
+import (
+	"maps"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshot 保存消息目录和默认语言区域，测试结束时恢复
+func snapshot(t *testing.T) {
+	t.Helper()
+	mu.RLock()
+	saved := make(map[string]map[string]string, len(catalogs))
+	for locale, catalog := range catalogs {
+		saved[locale] = maps.Clone(catalog)
+	}
+	savedDefault := defaultLocale
+	mu.RUnlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		catalogs = saved
+		defaultLocale = savedDefault
+		mu.Unlock()
+	})
+}
+
+// TestBuiltinCatalogs 测试内置消息目录
+//
+// 【功能点】验证内置的 zh-CN、en-US 消息目录已加载，响应码与消息 ID 均可查找
+// 【测试流程】断言 Locales 包含两种语言，查找 "20000" 和 "exception.unknown"
+func TestBuiltinCatalogs(t *testing.T) {
+	assert.Equal(t, []string{"en-US", "zh-CN"}, Locales())
+
+	msg, ok := Lookup("zh-CN", "20000")
+	require.True(t, ok)
+	assert.Equal(t, "操作成功", msg)
+
+	msg, ok = Lookup("en-US", "exception.unknown")
+	require.True(t, ok)
+	assert.Equal(t, "Internal server error", msg)
+}
+
+// TestParseAcceptLanguage 测试 Accept-Language 解析
+//
+// 【功能点】验证按权重从高到低排序、权重相同保持原顺序、q=0 和非法权重被忽略、标记被规范化
+// 【测试流程】解析多种请求头，断言结果
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []LanguageRange
+	}{
+		{"空请求头", "", nil},
+		{"单个语言", "en-US", []LanguageRange{{"en-US", 1}}},
+		{
+			"按权重排序",
+			"zh;q=0.5, en-us;q=0.9, fr",
+			[]LanguageRange{{"fr", 1}, {"en-US", 0.9}, {"zh", 0.5}},
+		},
+		{
+			"权重相同保持原顺序",
+			"de;q=0.8,ja;q=0.8,en",
+			[]LanguageRange{{"en", 1}, {"de", 0.8}, {"ja", 0.8}},
+		},
+		{
+			"忽略 q=0 和非法权重",
+			"en;q=0, zh_cn;q=abc, ja;q=1.5, ko;q=0.3, *;q=0.1",
+			[]LanguageRange{{"ko", 0.3}, {"*", 0.1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseAcceptLanguage(tt.header))
+		})
+	}
+}
+
+// TestNegotiate 测试语言协商
+//
+// 【功能点】验证精确匹配、按语言部分匹配（en-GB → en-US）、跳过未注册的语言、无法匹配时回退默认语言区域
+// 【测试流程】以多种 Accept-Language 调用 Negotiate，断言协商结果
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "zh-CN"},
+		{"en-US", "en-US"},
+		{"en-GB,en;q=0.9", "en-US"},
+		{"fr-FR,en;q=0.5", "en-US"},
+		{"zh-TW;q=0.9,en;q=0.5", "zh-CN"},
+		{"fr;q=0.9,de;q=0.8", "zh-CN"},
+		{"*", "zh-CN"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Negotiate(tt.header), "Accept-Language: %q", tt.header)
+	}
+}
+
+// TestLookup_Fallback 测试消息查找的回退链
+//
+// 【功能点】验证当前语言区域缺少消息时回退默认语言区域，均缺少时 Translate 返回原始字符串并替换占位符
+// 【测试流程】
+//  1. 仅为 zh-CN 注册 demo.only_default，以 en-US 查找，断言返回中文
+//  2. 以未注册的语言区域查找内置消息，断言返回默认语言区域的消息
+//  3. 修改默认语言区域为 en-US 后查找，断言回退到英文
+//  4. Translate 查找不存在的键，断言返回键本身
+func TestLookup_Fallback(t *testing.T) {
+	snapshot(t)
+	Register("zh-CN", map[string]string{"demo.only_default": "仅默认语言"})
+
+	msg, ok := Lookup("en-US", "demo.only_default")
+	require.True(t, ok)
+	assert.Equal(t, "仅默认语言", msg)
+
+	msg, ok = Lookup("fr-FR", "20000")
+	require.True(t, ok)
+	assert.Equal(t, "操作成功", msg)
+
+	SetDefaultLocale("en_us")
+	assert.Equal(t, "en-US", GetDefaultLocale())
+	msg, ok = Lookup("fr-FR", "20000")
+	require.True(t, ok)
+	assert.Equal(t, "Success", msg)
+
+	_, ok = Lookup("en-US", "demo.missing")
+	assert.False(t, ok)
+	assert.Equal(t, "demo.missing", Translate("en-US", "demo.missing", nil))
+	assert.Equal(t, "name is required", Translate("en-US", "validation.required", map[string]string{"field": "name"}))
+}
+
+// TestRegister_Override 测试注册消息覆盖内置消息
+//
+// 【功能点】验证 Register 覆盖同名内置消息并保留其余消息，可注册新的语言区域并参与协商
+// 【测试流程】
+//  1. 覆盖 en-US 的 "20000"，断言返回新消息，其余内置消息不变
+//  2. 注册 ja-JP，断言 Accept-Language: ja 协商为 ja-JP
+func TestRegister_Override(t *testing.T) {
+	snapshot(t)
+	Register("en-US", map[string]string{"20000": "OK"})
+
+	msg, _ := Lookup("en-US", "20000")
+	assert.Equal(t, "OK", msg)
+	msg, _ = Lookup("en-US", "50000")
+	assert.Equal(t, "Operation failed", msg)
+
+	Register("ja_jp", map[string]string{"20000": "成功しました"})
+	assert.Equal(t, "ja-JP", Negotiate("ja"))
+	msg, _ = Lookup("ja-JP", "20000")
+	assert.Equal(t, "成功しました", msg)
+}
+
+// TestLocale 测试读取请求的语言区域
+//
+// 【功能点】验证读取 gin 上下文中的语言区域，未设置或上下文为 nil 时返回默认语言区域
+// 【测试流程】分别以 nil、未设置、已设置的上下文调用 Locale
+func TestLocale(t *testing.T) {
+	assert.Equal(t, "zh-CN", Locale(nil))
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Equal(t, "zh-CN", Locale(c))
+
+	c.Set(LocaleKey, "en-US")
+	assert.Equal(t, "en-US", Locale(c))
+}
+
+// TestNormalize 测试语言标记规范化
+//
+// 【功能点】验证语言小写、地区大写、文字首字母大写，分隔符统一为 "-"
+// 【测试流程】断言多种写法的规范化结果
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "en-US", Normalize("EN_us"))
+	assert.Equal(t, "zh-Hans-CN", Normalize("zh-hans-cn"))
+	assert.Equal(t, "", Normalize("  "))
+}
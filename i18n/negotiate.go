@@ -0,0 +1,104 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LanguageRange Accept-Language 中的一项
+type LanguageRange struct {
+	Tag     string  // 语言标记（已规范化），"*" 表示任意语言
+	Quality float64 // 权重，取值 0 ~ 1
+}
+
+// ParseAcceptLanguage 解析 Accept-Language 请求头
+// 按权重从高到低排序，权重相同时保持原顺序；权重为 0 或格式错误的项被忽略
+// 参数：
+//   - header: Accept-Language 请求头，如 "en-US,en;q=0.9,zh;q=0.8"
+//
+// 返回：
+//   - []LanguageRange: 解析结果
+func ParseAcceptLanguage(header string) []LanguageRange {
+	var ranges []LanguageRange
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		tag, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			v, err := strconv.ParseFloat(strings.TrimSpace(q), 64)
+			if err != nil || v < 0 || v > 1 {
+				continue
+			}
+			quality = v
+		}
+		if quality == 0 {
+			continue
+		}
+		tag = strings.TrimSpace(tag)
+		if tag != "*" {
+			tag = Normalize(tag)
+		}
+		if tag == "" {
+			continue
+		}
+		ranges = append(ranges, LanguageRange{Tag: tag, Quality: quality})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].Quality > ranges[j].Quality })
+	return ranges
+}
+
+// Negotiate 根据 Accept-Language 请求头选择已注册的语言区域
+// 按权重依次匹配，每项先精确匹配，再按语言部分匹配（en-GB 匹配 en-US，zh 匹配 zh-CN）；均未匹配时返回默认语言区域
+// 参数：
+//   - acceptLanguage: Accept-Language 请求头
+//
+// 返回：
+//   - string: 语言区域
+func Negotiate(acceptLanguage string) string {
+	for _, r := range ParseAcceptLanguage(acceptLanguage) {
+		if r.Tag == "*" {
+			break
+		}
+		if locale, ok := Match(r.Tag); ok {
+			return locale
+		}
+	}
+	return GetDefaultLocale()
+}
+
+// Match 将语言标记匹配到已注册的语言区域
+// 先精确匹配，再按语言部分匹配，语言部分相同的多个区域中优先默认语言区域，其次按字母顺序
+// 参数：
+//   - tag: 语言标记，如 "en"、"en-GB"
+//
+// 返回：
+//   - string: 匹配到的语言区域
+//   - bool: 是否匹配成功
+func Match(tag string) (string, bool) {
+	tag = Normalize(tag)
+	if tag == "" {
+		return "", false
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	if _, ok := catalogs[tag]; ok {
+		return tag, true
+	}
+	lang, _, _ := strings.Cut(tag, "-")
+	if base, _, _ := strings.Cut(defaultLocale, "-"); base == lang {
+		if _, ok := catalogs[defaultLocale]; ok {
+			return defaultLocale, true
+		}
+	}
+	var matched string
+	for locale := range catalogs {
+		if base, _, _ := strings.Cut(locale, "-"); base == lang && (matched == "" || locale < matched) {
+			matched = locale
+		}
+	}
+	return matched, matched != ""
+}
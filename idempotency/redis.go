@@ -0,0 +1,83 @@
+// Package idempotency 提供幂等键（Idempotency-Key）的存储功能
+// 本文件实现基于 Redis 的幂等键存储
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// claimRetries Claim 时幂等键恰好在 SETNX 与 GET 之间过期的重试次数
+const claimRetries = 3
+
+// pendingRecord 处理中记录的编码
+var pendingRecord, _ = json.Marshal(Record{})
+
+// RedisStore Redis 幂等键存储
+// 适用于分布式部署场景，多个实例共享幂等键，通过 SETNX 保证只有一个实例占用成功
+type RedisStore struct {
+	client    redis.UniversalClient
+	keyPrefix string
+}
+
+// NewRedisStore 创建 Redis 幂等键存储
+// client: Redis 客户端
+// keyPrefix: 键前缀，如 "idempotency:"
+func NewRedisStore(client redis.UniversalClient, keyPrefix string) *RedisStore {
+	return &RedisStore{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+// Claim 使用 SETNX 占用幂等键，已存在时读取已保存的记录
+func (rs *RedisStore) Claim(ctx context.Context, key string, ttl time.Duration) (*Record, error) {
+	for i := 0; i < claimRetries; i++ {
+		ok, err := rs.client.SetNX(ctx, rs.keyPrefix+key, pendingRecord, ttl).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return nil, nil
+		}
+
+		data, err := rs.client.Get(ctx, rs.keyPrefix+key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var record Record
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("解析幂等键记录失败: %w", err)
+		}
+		return &record, nil
+	}
+	return nil, fmt.Errorf("占用幂等键失败: %s", key)
+}
+
+// Complete 保存首次请求的响应
+func (rs *RedisStore) Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return rs.client.Set(ctx, rs.keyPrefix+key, data, ttl).Err()
+}
+
+// Release 释放幂等键
+func (rs *RedisStore) Release(ctx context.Context, key string) error {
+	return rs.client.Del(ctx, rs.keyPrefix+key).Err()
+}
+
+// Close 关闭存储
+// Redis 客户端由调用方管理，这里不关闭客户端
+func (rs *RedisStore) Close() error {
+	return nil
+}
@@ -0,0 +1,142 @@
+// Package idempotency 提供幂等键（Idempotency-Key）的存储功能
+// 支持内存和 Redis 两种存储方式，适用于单机和分布式场景
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Record 幂等键记录
+// 首次请求占用幂等键时写入处理中记录（Completed 为 false），处理完成后保存响应
+type Record struct {
+	Completed   bool   `json:"completed"`             // 首次请求是否已处理完成
+	Status      int    `json:"status,omitempty"`      // 响应状态码
+	ContentType string `json:"contentType,omitempty"` // 响应的 Content-Type
+	Body        []byte `json:"body,omitempty"`        // 响应体
+}
+
+// Store 幂等键存储接口
+type Store interface {
+	// Claim 原子地占用幂等键，过期时间为 ttl
+	// 占用成功时返回 nil, nil；幂等键已存在时返回已保存的记录，并发的 Claim 只有一个能占用成功
+	Claim(ctx context.Context, key string, ttl time.Duration) (*Record, error)
+
+	// Complete 保存首次请求的响应，过期时间为 ttl
+	Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error
+
+	// Release 释放幂等键，首次请求失败后允许使用同一个幂等键重试
+	Release(ctx context.Context, key string) error
+
+	// Close 关闭存储，释放资源
+	Close() error
+}
+
+// MemoryStore 内存幂等键存储
+// 适用于单机部署和测试场景，后台协程定期清理过期的幂等键
+type MemoryStore struct {
+	mu        sync.Mutex
+	items     map[string]*memoryItem
+	interval  time.Duration
+	stopCh    chan struct{}
+	closeOnce sync.Once
+}
+
+// memoryItem 内存幂等键条目
+type memoryItem struct {
+	record   Record
+	expireAt time.Time
+}
+
+// NewMemoryStore 创建内存幂等键存储
+// cleanupInterval: 清理过期幂等键的间隔时间
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	ms := &MemoryStore{
+		items:    make(map[string]*memoryItem),
+		interval: cleanupInterval,
+		stopCh:   make(chan struct{}),
+	}
+
+	// 启动清理协程
+	go ms.cleanup()
+
+	return ms
+}
+
+// Claim 占用幂等键
+func (ms *MemoryStore) Claim(ctx context.Context, key string, ttl time.Duration) (*Record, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if item, ok := ms.items[key]; ok && time.Now().Before(item.expireAt) {
+		record := item.record
+		return &record, nil
+	}
+	ms.items[key] = &memoryItem{expireAt: time.Now().Add(ttl)}
+	return nil, nil
+}
+
+// Complete 保存首次请求的响应
+func (ms *MemoryStore) Complete(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.items[key] = &memoryItem{
+		record:   *record,
+		expireAt: time.Now().Add(ttl),
+	}
+	return nil
+}
+
+// Release 释放幂等键
+func (ms *MemoryStore) Release(ctx context.Context, key string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.items, key)
+	return nil
+}
+
+// Len 返回当前存储的幂等键数量（包含尚未清理的过期幂等键）
+func (ms *MemoryStore) Len() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return len(ms.items)
+}
+
+// cleanup 定期清理过期幂等键
+func (ms *MemoryStore) cleanup() {
+	ticker := time.NewTicker(ms.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ms.doCleanup()
+		case <-ms.stopCh:
+			return
+		}
+	}
+}
+
+// doCleanup 执行清理操作
+func (ms *MemoryStore) doCleanup() {
+	now := time.Now()
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for key, item := range ms.items {
+		if now.After(item.expireAt) {
+			delete(ms.items, key)
+		}
+	}
+}
+
+// Close 关闭存储，停止清理协程
+func (ms *MemoryStore) Close() error {
+	ms.closeOnce.Do(func() {
+		close(ms.stopCh)
+	})
+	return nil
+}
@@ -3,7 +3,6 @@
 package initialize
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/zzsen/gin_core/app"
@@ -16,54 +15,40 @@ import (
 // InitElasticsearch 初始化Elasticsearch客户端
 // 该函数会：
 // 1. 检查配置是否存在
-// 2. 创建ES客户端连接
-// 3. 测试连接并获取服务器信息
-// 4. 将客户端实例存储到全局app.ES中
+// 2. 按别名添加单个集群（es）和多集群（esList）的客户端，并尝试连接
+// 3. 单个集群连接成功时存储到全局app.ES中
+//
+// 集群暂时不可达时只记录警告，不中断启动，之后通过 app.ESByName 访问时按退避时间重新连接
 func InitElasticsearch() {
 	// 检查ES配置是否存在，如果为空则记录错误并返回
-	if app.BaseConfig.Es == nil {
+	if app.BaseConfig.Es == nil && len(app.BaseConfig.EsList) == 0 {
 		panic(exception.NewInitError("es", "检查配置", fmt.Errorf("未找到Elasticsearch配置, 请检查配置")))
 	}
 
-	// 构建ES客户端配置
-	esConfig := elasticsearch.Config{
-		Addresses: app.BaseConfig.Es.Addresses, // ES服务器地址列表
-		Password:  app.BaseConfig.Es.Password,  // ES访问密码
-		Username:  app.BaseConfig.Es.Username,  // ES访问用户名
-	}
-
-	// 创建类型化的ES客户端实例
-	es, err := elasticsearch.NewTypedClient(esConfig)
-
-	// 如果创建客户端失败，则抛出panic
-	if err != nil {
-		panic(exception.NewInitError("es", "创建客户端", err))
-	} else {
+	if app.BaseConfig.Es != nil {
 		// 将ES客户端实例存储到全局变量中，供其他模块使用
-		app.ES = es
+		app.ES = addElasticsearch(app.BaseConfig.Es.GetAliasName(), func() (*elasticsearch.TypedClient, error) {
+			return app.AddES(*app.BaseConfig.Es)
+		})
 	}
-
-	// 测试ES连接并获取服务器信息，如果失败则抛出panic
-	if err = info(); err != nil {
-		panic(exception.NewInitError("es", "获取服务器信息", err))
+	for _, esInfo := range app.BaseConfig.EsList {
+		if esInfo.AliasName == "" {
+			panic(exception.NewInitError("es", "检查配置", fmt.Errorf("esList 中的集群必须设置 aliasName")))
+		}
+		addElasticsearch(esInfo.AliasName, func() (*elasticsearch.TypedClient, error) {
+			return app.AddES(esInfo)
+		})
 	}
+	logger.Info("[es] Client: %s", elasticsearch.Version)
 }
 
-// info 获取Elasticsearch服务器信息并测试连接
-// 该函数会：
-// 1. 调用ES的Info API获取服务器信息
-// 2. 记录客户端和服务器版本信息
-// 3. 返回错误信息（如果有的话）
-func info() error {
-	// 调用ES Info API获取服务器信息
-	res, err := app.ES.Info().Do(context.Background())
-
+// addElasticsearch 添加单个集群，连接失败时记录警告
+func addElasticsearch(alias string, add func() (*elasticsearch.TypedClient, error)) *elasticsearch.TypedClient {
+	client, err := add()
 	if err != nil {
-		return err
+		logger.Warn("[es] 集群 %s 暂不可用, 访问时将重新连接: %v", alias, err)
+		return nil
 	}
-
-	// 记录客户端和服务器版本信息，用于调试和监控
-	logger.Info("[es] Client: %s", elasticsearch.Version)
-	logger.Info("[es] Server: %s", res.Version.Int)
-	return nil
+	logger.Info("[es] 集群 %s 已连接", alias)
+	return client
 }
@@ -0,0 +1,215 @@
+// Package initialize 提供各种服务的初始化功能
+// 本文件实现了写入框架日志的 GORM 日志记录器，支持慢查询日志、SQL 参数脱敏和慢查询回调
+package initialize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/tracing"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// SlowQueryHook 慢查询回调
+// 参数：
+//   - sql: 执行的 SQL，开启 redactSQLValues 时参数以 ? 占位
+//   - elapsed: 执行耗时
+//   - rows: 影响行数，无法获取时为 -1
+type SlowQueryHook func(sql string, elapsed time.Duration, rows int64)
+
+var (
+	// slowQueryHooksMu 保护 slowQueryHooks 的并发访问
+	slowQueryHooksMu sync.RWMutex
+	// slowQueryHooks 已注册的慢查询回调
+	slowQueryHooks []SlowQueryHook
+)
+
+// AddSlowQueryHook 注册慢查询回调
+// 执行耗时超过 slowThreshold 的 SQL 都会触发回调，不受 logLevel 影响，可用于慢查询指标统计。
+// 回调在执行 SQL 的协程中同步调用，应避免耗时操作。
+// 参数：
+//   - hook: 慢查询回调
+func AddSlowQueryHook(hook SlowQueryHook) {
+	if hook == nil {
+		return
+	}
+	slowQueryHooksMu.Lock()
+	defer slowQueryHooksMu.Unlock()
+	slowQueryHooks = append(slowQueryHooks, hook)
+}
+
+// fireSlowQueryHooks 触发所有慢查询回调，单个回调 panic 不影响其他回调和 SQL 执行
+func fireSlowQueryHooks(sql string, elapsed time.Duration, rows int64) {
+	slowQueryHooksMu.RLock()
+	hooks := slowQueryHooks
+	slowQueryHooksMu.RUnlock()
+
+	for _, hook := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("[db] 慢查询回调执行异常: %v", r)
+				}
+			}()
+			hook(sql, elapsed, rows)
+		}()
+	}
+}
+
+// gormSQLLogger 写入框架日志的 GORM 日志记录器
+// 日志级别对应关系：SQL 执行失败记录为 Error，慢查询记录为 Warn，logLevel 为 4 时所有 SQL 记录为 Info。
+// 日志字段包含 traceId（通过 db.WithContext(c) 传入请求上下文时）、sql、rows、elapsed 和 sqlCaller（执行 SQL 的代码位置）
+type gormSQLLogger struct {
+	target                    *logrus.Logger      // 日志写入目标
+	level                     gormLogger.LogLevel // 日志级别
+	slowThreshold             time.Duration       // 慢查询阈值，为 0 时不记录慢查询
+	ignoreRecordNotFoundError bool                // 是否忽略记录未找到错误
+	redactSQLValues           bool                // 是否隐藏 SQL 中的参数值
+}
+
+// newGormSQLLogger 根据数据库配置创建 GORM 日志记录器
+// 参数：
+//   - target: 日志写入目标
+//   - dbConfig: 数据库配置
+//
+// 返回：
+//   - *gormSQLLogger: GORM 日志记录器
+func newGormSQLLogger(target *logrus.Logger, dbConfig config.DbInfo) *gormSQLLogger {
+	loggerConfig := initGormLoggerConfig(dbConfig)
+	return &gormSQLLogger{
+		target:                    target,
+		level:                     loggerConfig.LogLevel,
+		slowThreshold:             loggerConfig.SlowThreshold,
+		ignoreRecordNotFoundError: loggerConfig.IgnoreRecordNotFoundError,
+		redactSQLValues:           dbConfig.RedactSQLValues,
+	}
+}
+
+// LogMode 返回指定日志级别的副本，实现 gormLogger.Interface
+func (l *gormSQLLogger) LogMode(level gormLogger.LogLevel) gormLogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// Info 记录 Info 日志，实现 gormLogger.Interface
+func (l *gormSQLLogger) Info(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormLogger.Info {
+		l.entry(ctx).Info(logger.SanitizeMessage(fmt.Sprintf(msg, data...)))
+	}
+}
+
+// Warn 记录 Warn 日志，实现 gormLogger.Interface
+func (l *gormSQLLogger) Warn(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormLogger.Warn {
+		l.entry(ctx).Warn(logger.SanitizeMessage(fmt.Sprintf(msg, data...)))
+	}
+}
+
+// Error 记录 Error 日志，实现 gormLogger.Interface
+func (l *gormSQLLogger) Error(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormLogger.Error {
+		l.entry(ctx).Error(logger.SanitizeMessage(fmt.Sprintf(msg, data...)))
+	}
+}
+
+// Trace 记录 SQL 执行结果，实现 gormLogger.Interface
+func (l *gormSQLLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+
+	switch {
+	case err != nil && l.level >= gormLogger.Error && (!errors.Is(err, gorm.ErrRecordNotFound) || !l.ignoreRecordNotFoundError):
+		sql, rows := fc()
+		l.sqlEntry(ctx, sql, rows, elapsed).Errorf("[db] SQL执行失败: %v", err)
+	case slow && l.level >= gormLogger.Warn:
+		sql, rows := fc()
+		l.sqlEntry(ctx, sql, rows, elapsed).Warnf("[db] 慢查询 >= %v", l.slowThreshold)
+	case l.level >= gormLogger.Info:
+		sql, rows := fc()
+		l.sqlEntry(ctx, sql, rows, elapsed).Info("[db] SQL")
+	}
+
+	if slow {
+		sql, rows := fc()
+		fireSlowQueryHooks(sql, elapsed, rows)
+	}
+}
+
+// ParamsFilter 开启 redactSQLValues 时去掉 SQL 参数，日志中参数以 ? 占位
+func (l *gormSQLLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	if l.redactSQLValues {
+		return sql, nil
+	}
+	return sql, params
+}
+
+// entry 创建带 traceId 的日志条目
+func (l *gormSQLLogger) entry(ctx context.Context) *logrus.Entry {
+	entry := logrus.NewEntry(l.target)
+	if traceID := traceIDFromContext(ctx); traceID != "" {
+		entry = entry.WithField("traceId", traceID)
+	}
+	return entry
+}
+
+// sqlEntry 创建包含 SQL 信息的日志条目
+func (l *gormSQLLogger) sqlEntry(ctx context.Context, sql string, rows int64, elapsed time.Duration) *logrus.Entry {
+	return l.entry(ctx).WithFields(logrus.Fields{
+		"sql":       logger.SanitizeMessage(sql),
+		"rows":      rows,
+		"elapsed":   fmt.Sprintf("%.3fms", float64(elapsed.Nanoseconds())/1e6),
+		"sqlCaller": sqlCaller(),
+	})
+}
+
+// gormLoggerFile 本文件路径，获取调用位置时跳过
+var gormLoggerFile = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return file
+}()
+
+// sqlCaller 获取执行 SQL 的业务代码位置（文件:行号），跳过 GORM、数据库驱动和本文件的调用栈
+func sqlCaller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if frame.File != gormLoggerFile && !strings.Contains(frame.File, "gorm.io/") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// traceIDFromContext 从 SQL 执行上下文中获取追踪 ID
+// 依次从 RequestContext、gin.Context 旧版键和 OpenTelemetry Span 中获取
+func traceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if rc, ok := ginContext.FromStdContext(ctx); ok {
+		if traceID := rc.TraceID(); traceID != "" {
+			return traceID
+		}
+	}
+	if c, ok := ctx.(*gin.Context); ok {
+		if traceID := ginContext.GetTraceID(c); traceID != "" {
+			return traceID
+		}
+	}
+	return tracing.GetTraceID(ctx)
+}
@@ -0,0 +1,203 @@
+// Package initialize GORM 日志记录器测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 GORM 日志记录器的单元测试，使用 SQLite 内存数据库，不需要 MySQL。
+//
+// 测试覆盖内容：
+// 1. 慢查询记录为 Warn 日志，包含 SQL、影响行数、耗时、调用位置和请求上下文中的 traceId
+// 2. 慢查询回调被调用
+// 3. redactSQLValues 开启时 SQL 参数以 ? 占位
+// 4. SQL 执行失败记录为 Error 日志，记录未找到错误按配置忽略
+//
+// 运行测试：go test -v ./initialize/... -run GormSQLLogger
+// ==================================================
+package initialize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/config"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// gormLoggerUser 测试用数据表
+type gormLoggerUser struct {
+	ID   uint
+	Name string
+}
+
+// newLogTarget 创建输出 JSON 到缓冲区的日志记录器
+func newLogTarget() (*logrus.Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	target := logrus.New()
+	target.SetOutput(buf)
+	target.SetLevel(logrus.TraceLevel)
+	target.SetFormatter(&logrus.JSONFormatter{})
+	return target, buf
+}
+
+// parseLogLines 解析缓冲区中的 JSON 日志
+func parseLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+// openTestDB 使用指定的日志记录器打开 SQLite 内存数据库并建表（建表语句不记录日志，但会触发慢查询回调）
+func openTestDB(t *testing.T, l *gormSQLLogger) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: l})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	require.NoError(t, db.Session(&gorm.Session{Logger: l.LogMode(0)}).AutoMigrate(&gormLoggerUser{}))
+	return db
+}
+
+// resetSlowQueryHooks 测试结束后恢复慢查询回调列表
+func resetSlowQueryHooks(t *testing.T) {
+	slowQueryHooksMu.Lock()
+	original := slowQueryHooks
+	slowQueryHooks = nil
+	slowQueryHooksMu.Unlock()
+	t.Cleanup(func() {
+		slowQueryHooksMu.Lock()
+		slowQueryHooks = original
+		slowQueryHooksMu.Unlock()
+	})
+}
+
+// TestGormSQLLogger_SlowQuery 测试慢查询日志与回调
+//
+// 【功能点】验证慢查询记录为 Warn 日志且包含 traceId，慢查询回调收到 SQL、耗时和影响行数
+// 【测试流程】
+//  1. 慢查询阈值设为 1 纳秒，使所有 SQL 都成为慢查询
+//  2. 在设置了 traceId 的 gin.Context 中通过 db.WithContext(c) 插入数据
+//  3. 断言 Warn 日志包含 sql、rows、elapsed、sqlCaller、traceId，SQL 中包含参数值
+//  4. 断言慢查询回调被调用，SQL 与日志一致
+func TestGormSQLLogger_SlowQuery(t *testing.T) {
+	resetSlowQueryHooks(t)
+	type slowQuery struct {
+		sql     string
+		elapsed time.Duration
+		rows    int64
+	}
+	target, buf := newLogTarget()
+	l := newGormSQLLogger(target, config.DbInfo{})
+	l.slowThreshold = time.Nanosecond
+	db := openTestDB(t, l)
+
+	var mu sync.Mutex
+	var hooked []slowQuery
+	AddSlowQueryHook(func(sql string, elapsed time.Duration, rows int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		hooked = append(hooked, slowQuery{sql, elapsed, rows})
+	})
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	ginContext.SetTraceID(c, "trace-slow-1")
+	require.NoError(t, db.WithContext(c).Create(&gormLoggerUser{Name: "alice"}).Error)
+
+	lines := parseLogLines(t, buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "warning", lines[0]["level"])
+	assert.Contains(t, lines[0]["msg"], "慢查询")
+	assert.Equal(t, "trace-slow-1", lines[0]["traceId"])
+	assert.Contains(t, lines[0]["sql"], "INSERT INTO")
+	assert.Contains(t, lines[0]["sql"], `"alice"`)
+	assert.Equal(t, float64(1), lines[0]["rows"])
+	assert.NotEmpty(t, lines[0]["elapsed"])
+	assert.Contains(t, lines[0]["sqlCaller"], "gorm_logger_test.go:")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, hooked, 1)
+	assert.Equal(t, lines[0]["sql"], hooked[0].sql)
+	assert.Equal(t, int64(1), hooked[0].rows)
+	assert.Positive(t, hooked[0].elapsed)
+}
+
+// TestGormSQLLogger_RedactAndThreshold 测试参数脱敏与慢查询阈值
+//
+// 【功能点】验证 redactSQLValues 开启时 SQL 参数以 ? 占位；未超过阈值的查询不记录日志、不触发回调
+// 【测试流程】
+//  1. 开启 redactSQLValues、阈值 1 纳秒，插入数据，断言日志 SQL 不含参数值
+//  2. 阈值改为 1 小时，再次查询，断言没有新日志且回调次数不变
+func TestGormSQLLogger_RedactAndThreshold(t *testing.T) {
+	resetSlowQueryHooks(t)
+	target, buf := newLogTarget()
+	l := newGormSQLLogger(target, config.DbInfo{RedactSQLValues: true})
+	l.slowThreshold = time.Nanosecond
+	db := openTestDB(t, l)
+
+	var count int
+	AddSlowQueryHook(func(string, time.Duration, int64) { count++ })
+
+	require.NoError(t, db.Create(&gormLoggerUser{Name: "secret-name"}).Error)
+	lines := parseLogLines(t, buf)
+	require.Len(t, lines, 1)
+	assert.NotContains(t, lines[0]["sql"], "secret-name")
+	assert.Contains(t, lines[0]["sql"], "?")
+	assert.NotContains(t, lines[0], "traceId")
+	assert.Equal(t, 1, count)
+
+	buf.Reset()
+	l.slowThreshold = time.Hour
+	var users []gormLoggerUser
+	require.NoError(t, db.Find(&users).Error)
+	assert.Empty(t, buf.String())
+	assert.Equal(t, 1, count)
+}
+
+// TestGormSQLLogger_Errors 测试错误日志
+//
+// 【功能点】验证 SQL 执行失败记录为 Error 日志；记录未找到错误默认忽略，配置不忽略时记录
+// 【测试流程】
+//  1. 查询不存在的表，断言记录 Error 日志
+//  2. 查询不存在的记录，断言默认不记录日志
+//  3. 配置 ignoreRecordNotFoundError=false，断言记录 Error 日志
+func TestGormSQLLogger_Errors(t *testing.T) {
+	resetSlowQueryHooks(t)
+	target, buf := newLogTarget()
+	db := openTestDB(t, newGormSQLLogger(target, config.DbInfo{}))
+
+	_ = db.Table("not_exists").Find(&[]gormLoggerUser{}).Error
+	lines := parseLogLines(t, buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "error", lines[0]["level"])
+	assert.Contains(t, lines[0]["msg"], "SQL执行失败")
+
+	buf.Reset()
+	var user gormLoggerUser
+	assert.ErrorIs(t, db.First(&user, 999).Error, gorm.ErrRecordNotFound)
+	assert.Empty(t, buf.String())
+
+	notIgnore := false
+	db = db.Session(&gorm.Session{Logger: newGormSQLLogger(target, config.DbInfo{IgnoreRecordNotFoundError: &notIgnore})})
+	assert.ErrorIs(t, db.First(&user, 999).Error, gorm.ErrRecordNotFound)
+	lines = parseLogLines(t, buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "error", lines[0]["level"])
+}
@@ -0,0 +1,105 @@
+// Package initialize 提供各种服务的初始化功能
+// 本文件实现记录数据库查询耗时的 GORM 插件，开启 service.serverTiming.dbSpans 时将查询耗时计入请求耗时分解
+package initialize
+
+import (
+	"fmt"
+
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"gorm.io/gorm"
+)
+
+const (
+	// serverTimingPluginName 插件名称
+	serverTimingPluginName = "gin_core:server_timing"
+	// serverTimingEndKey 阶段结束函数在 GORM 实例中的存储键
+	serverTimingEndKey = "gin_core:server_timing_end"
+	// serverTimingSpanName 数据库查询阶段的名称
+	serverTimingSpanName = "db"
+)
+
+// serverTimingPlugin 将数据库操作耗时记录为请求阶段的 GORM 插件
+// 耗时记录从会话的 context.Context 中读取（app.DBWithContext 绑定请求上下文），请求未开启耗时记录时不记录
+type serverTimingPlugin struct{}
+
+// Name 返回插件名称
+// 实现 gorm.Plugin 接口
+func (p *serverTimingPlugin) Name() string {
+	return serverTimingPluginName
+}
+
+// Initialize 初始化插件，在创建、查询、更新、删除和原生 SQL 执行前后注册开始、结束阶段的回调
+// 实现 gorm.Plugin 接口
+func (p *serverTimingPlugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("server_timing:before_create", startServerTimingSpan); err != nil {
+		return fmt.Errorf("注册 server_timing:before_create 回调失败: %w", err)
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("server_timing:before_query", startServerTimingSpan); err != nil {
+		return fmt.Errorf("注册 server_timing:before_query 回调失败: %w", err)
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("server_timing:before_update", startServerTimingSpan); err != nil {
+		return fmt.Errorf("注册 server_timing:before_update 回调失败: %w", err)
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("server_timing:before_delete", startServerTimingSpan); err != nil {
+		return fmt.Errorf("注册 server_timing:before_delete 回调失败: %w", err)
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("server_timing:before_row", startServerTimingSpan); err != nil {
+		return fmt.Errorf("注册 server_timing:before_row 回调失败: %w", err)
+	}
+	if err := db.Callback().Raw().Before("gorm:raw").Register("server_timing:before_raw", startServerTimingSpan); err != nil {
+		return fmt.Errorf("注册 server_timing:before_raw 回调失败: %w", err)
+	}
+
+	if err := db.Callback().Create().After("gorm:create").Register("server_timing:after_create", endServerTimingSpan); err != nil {
+		return fmt.Errorf("注册 server_timing:after_create 回调失败: %w", err)
+	}
+	if err := db.Callback().Query().After("gorm:query").Register("server_timing:after_query", endServerTimingSpan); err != nil {
+		return fmt.Errorf("注册 server_timing:after_query 回调失败: %w", err)
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("server_timing:after_update", endServerTimingSpan); err != nil {
+		return fmt.Errorf("注册 server_timing:after_update 回调失败: %w", err)
+	}
+	if err := db.Callback().Delete().After("gorm:delete").Register("server_timing:after_delete", endServerTimingSpan); err != nil {
+		return fmt.Errorf("注册 server_timing:after_delete 回调失败: %w", err)
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("server_timing:after_row", endServerTimingSpan); err != nil {
+		return fmt.Errorf("注册 server_timing:after_row 回调失败: %w", err)
+	}
+	if err := db.Callback().Raw().After("gorm:raw").Register("server_timing:after_raw", endServerTimingSpan); err != nil {
+		return fmt.Errorf("注册 server_timing:after_raw 回调失败: %w", err)
+	}
+	return nil
+}
+
+// startServerTimingSpan 请求开启耗时记录时开始数据库查询阶段
+func startServerTimingSpan(db *gorm.DB) {
+	if db.Statement.Context == nil {
+		return
+	}
+	timings, ok := ginContext.TimingsFromContext(db.Statement.Context)
+	if !ok {
+		return
+	}
+	db.InstanceSet(serverTimingEndKey, timings.Start(serverTimingSpanName))
+}
+
+// endServerTimingSpan 结束数据库查询阶段
+func endServerTimingSpan(db *gorm.DB) {
+	if end, ok := db.InstanceGet(serverTimingEndKey); ok {
+		if fn, ok := end.(func()); ok {
+			fn()
+		}
+	}
+}
+
+// applyServerTiming 开启 service.enableServerTiming 和 service.serverTiming.dbSpans 时为数据库实例添加查询耗时插件
+func applyServerTiming(db *gorm.DB, serviceConfig config.ServiceInfo) {
+	if !serviceConfig.EnableServerTiming || !serviceConfig.ServerTiming.DBSpans {
+		return
+	}
+	if err := db.Use(&serverTimingPlugin{}); err != nil {
+		logger.Warn("[db] 添加查询耗时插件失败: %v", err)
+	}
+}
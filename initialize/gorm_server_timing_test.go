@@ -0,0 +1,91 @@
+// Package initialize 查询耗时插件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含记录数据库查询耗时的 GORM 插件的单元测试，使用 SQLite 内存数据库，不需要 MySQL。
+//
+// 测试覆盖内容：
+// 1. 绑定开启耗时记录的请求上下文时，每次数据库操作记录一个 db 阶段，嵌套在外层阶段中
+// 2. 上下文未开启耗时记录，或未开启 service.serverTiming.dbSpans 时不记录
+//
+// 运行测试：go test -v ./initialize/... -run ServerTiming
+// ==================================================
+package initialize
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/config"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// serverTimingUser 测试用数据表
+type serverTimingUser struct {
+	ID   uint
+	Name string
+}
+
+// openServerTimingDB 打开 SQLite 内存数据库并建表，按配置启用查询耗时插件
+func openServerTimingDB(t *testing.T, serviceConfig config.ServiceInfo) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&serverTimingUser{}))
+	applyServerTiming(db, serviceConfig)
+	return db
+}
+
+// TestServerTimingPlugin_DBSpans 测试记录数据库查询阶段
+//
+// 【功能点】验证创建、查询、更新、删除、原生 SQL 各记录一个 db 阶段，嵌套在开始查询时的外层阶段中
+// 【测试流程】
+//  1. 开启 enableServerTiming 和 serverTiming.dbSpans，创建耗时记录并开始 handler 阶段
+//  2. 使用绑定耗时记录的上下文执行 5 次数据库操作后结束 handler
+//  3. 断言共 6 个阶段，后 5 个名称为 db、路径为 handler/db 且均已结束
+func TestServerTimingPlugin_DBSpans(t *testing.T) {
+	db := openServerTimingDB(t, config.ServiceInfo{EnableServerTiming: true, ServerTiming: config.ServerTimingConfig{DBSpans: true}})
+	timings := ginContext.NewTimings()
+	ctx := ginContext.WithTimings(context.Background(), timings)
+	endHandler := timings.Start("handler")
+
+	session := db.WithContext(ctx)
+	require.NoError(t, session.Create(&serverTimingUser{Name: "a"}).Error)
+	require.NoError(t, session.Find(&[]serverTimingUser{}).Error)
+	require.NoError(t, session.Model(&serverTimingUser{ID: 1}).Update("name", "b").Error)
+	require.NoError(t, session.Raw("SELECT 1").Scan(&[]int{}).Error)
+	require.NoError(t, session.Delete(&serverTimingUser{ID: 1}).Error)
+	endHandler()
+
+	spans := timings.Spans()
+	require.Len(t, spans, 6)
+	for _, span := range spans[1:] {
+		assert.Equal(t, "db", span.Name)
+		assert.Equal(t, "handler/db", span.Path)
+		assert.LessOrEqual(t, span.Start+span.Duration, spans[0].Start+spans[0].Duration)
+	}
+}
+
+// TestServerTimingPlugin_Skip 测试不记录查询阶段的场景
+//
+// 【功能点】验证上下文未开启耗时记录时查询正常执行，未开启 dbSpans 时不记录阶段
+// 【测试流程】
+//  1. 开启 dbSpans，使用不含耗时记录的上下文查询，断言查询成功
+//  2. 只开启 enableServerTiming，使用绑定耗时记录的上下文查询，断言没有阶段
+func TestServerTimingPlugin_Skip(t *testing.T) {
+	db := openServerTimingDB(t, config.ServiceInfo{EnableServerTiming: true, ServerTiming: config.ServerTimingConfig{DBSpans: true}})
+	assert.NoError(t, db.WithContext(context.Background()).Find(&[]serverTimingUser{}).Error)
+
+	db = openServerTimingDB(t, config.ServiceInfo{EnableServerTiming: true})
+	timings := ginContext.NewTimings()
+	ctx := ginContext.WithTimings(context.Background(), timings)
+	require.NoError(t, db.WithContext(ctx).Find(&[]serverTimingUser{}).Error)
+	assert.Empty(t, timings.Spans())
+}
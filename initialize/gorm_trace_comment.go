@@ -0,0 +1,146 @@
+// Package initialize 提供各种服务的初始化功能
+// 本文件实现了在 SQL 中附加 traceId 注释的 GORM 插件，便于在数据库慢日志、processlist 中关联请求
+package initialize
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// traceCommentPluginName 插件名称
+	traceCommentPluginName = "gin_core:trace_comment"
+	// maxTraceCommentLength 注释中 traceId 的最大长度，超出部分被截断
+	maxTraceCommentLength = 64
+)
+
+// traceCommentPlugin 在 SQL 前附加 /* trace:<traceId> */ 注释的 GORM 插件
+// traceId 从会话的 context.Context 中读取（与 SQL 日志相同，见 traceIDFromContext），没有 traceId 时不附加注释
+type traceCommentPlugin struct{}
+
+// traceComment SQL 注释表达式
+type traceComment string
+
+// Build 实现 clause.Expression 接口，写入注释内容
+func (c traceComment) Build(builder clause.Builder) {
+	builder.WriteString("/* trace:")
+	builder.WriteString(string(c))
+	builder.WriteString(" */")
+}
+
+// Name 返回插件名称
+// 实现 gorm.Plugin 接口
+func (p *traceCommentPlugin) Name() string {
+	return traceCommentPluginName
+}
+
+// Initialize 初始化插件，在创建、查询、更新、删除和原生 SQL 执行前注册添加注释的回调
+// 实现 gorm.Plugin 接口
+// 部分驱动为子句注册了自定义构建函数（如 SQLite 的 INSERT），这些函数不会写入前置表达式，因此同时包装这些构建函数
+func (p *traceCommentPlugin) Initialize(db *gorm.DB) error {
+	for _, name := range []string{"INSERT", "SELECT", "UPDATE", "DELETE"} {
+		if builder, ok := db.ClauseBuilders[name]; ok {
+			db.ClauseBuilders[name] = wrapTraceCommentBuilder(builder)
+		}
+	}
+
+	if err := db.Callback().Create().Before("gorm:create").Register("trace_comment:create", addTraceComment("INSERT")); err != nil {
+		return fmt.Errorf("注册 trace_comment:create 回调失败: %w", err)
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("trace_comment:query", addTraceComment("SELECT")); err != nil {
+		return fmt.Errorf("注册 trace_comment:query 回调失败: %w", err)
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("trace_comment:update", addTraceComment("UPDATE")); err != nil {
+		return fmt.Errorf("注册 trace_comment:update 回调失败: %w", err)
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("trace_comment:delete", addTraceComment("DELETE")); err != nil {
+		return fmt.Errorf("注册 trace_comment:delete 回调失败: %w", err)
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("trace_comment:row", addTraceComment("SELECT")); err != nil {
+		return fmt.Errorf("注册 trace_comment:row 回调失败: %w", err)
+	}
+	if err := db.Callback().Raw().Before("gorm:raw").Register("trace_comment:raw", addTraceComment("")); err != nil {
+		return fmt.Errorf("注册 trace_comment:raw 回调失败: %w", err)
+	}
+	return nil
+}
+
+// addTraceComment 返回添加 traceId 注释的回调
+// 已有 SQL（Raw、Exec）时直接在 SQL 前添加注释；否则作为 clauseName 子句的前置表达式，由 GORM 构建 SQL 时写入。
+// 子句已有前置表达式（如使用了 gorm hints）时不覆盖
+func addTraceComment(clauseName string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.Statement.Context == nil {
+			return
+		}
+		traceID := sanitizeTraceComment(traceIDFromContext(db.Statement.Context))
+		if traceID == "" {
+			return
+		}
+		comment := traceComment(traceID)
+
+		if db.Statement.SQL.Len() > 0 {
+			sql := db.Statement.SQL.String()
+			if strings.HasPrefix(sql, "/* trace:") {
+				return
+			}
+			db.Statement.SQL.Reset()
+			comment.Build(db.Statement)
+			db.Statement.SQL.WriteByte(' ')
+			db.Statement.SQL.WriteString(sql)
+			return
+		}
+		if clauseName == "" {
+			return
+		}
+		c := db.Statement.Clauses[clauseName]
+		if c.BeforeExpression != nil {
+			return
+		}
+		c.BeforeExpression = comment
+		db.Statement.Clauses[clauseName] = c
+	}
+}
+
+// wrapTraceCommentBuilder 包装驱动的子句构建函数，先写入 traceId 注释再调用原构建函数
+func wrapTraceCommentBuilder(builder clause.ClauseBuilder) clause.ClauseBuilder {
+	return func(c clause.Clause, b clause.Builder) {
+		if comment, ok := c.BeforeExpression.(traceComment); ok {
+			comment.Build(b)
+			b.WriteByte(' ')
+			c.BeforeExpression = nil
+		}
+		builder(c, b)
+	}
+}
+
+// sanitizeTraceComment 过滤 traceId 中的字符，只保留字母、数字和 - _ . : 并截断到 maxTraceCommentLength
+// traceId 可能来自上游请求头，过滤后才能安全地写入 SQL 注释（避免 */ 提前结束注释）
+func sanitizeTraceComment(traceID string) string {
+	var b strings.Builder
+	for _, r := range traceID {
+		if b.Len() >= maxTraceCommentLength {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// applyTraceComment 按配置为数据库连接启用 traceId 注释插件，未开启 traceComment 时不做任何处理
+func applyTraceComment(db *gorm.DB, dbConfig config.DbInfo) {
+	if !dbConfig.TraceComment {
+		return
+	}
+	if err := db.Use(&traceCommentPlugin{}); err != nil {
+		logger.Warn("[db] 添加 traceId 注释插件失败: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+// Package initialize traceId 注释插件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 traceId 注释插件的单元测试，使用 SQLite 内存数据库和 DryRun 模式，不需要 MySQL。
+//
+// 测试覆盖内容：
+// 1. 创建、查询、更新、删除、原生 SQL 生成的 SQL 以 /* trace:<traceId> */ 开头
+// 2. context 中没有 traceId 或未开启 traceComment 时不附加注释
+// 3. traceId 中的非法字符被过滤，过长时被截断
+//
+// 运行测试：go test -v ./initialize/... -run TraceComment
+// ==================================================
+package initialize
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/config"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// traceCommentUser 测试用数据表
+type traceCommentUser struct {
+	ID   uint
+	Name string
+}
+
+// openTraceCommentDB 打开 SQLite 内存数据库，按 traceComment 配置启用注释插件
+func openTraceCommentDB(t *testing.T, traceComment bool) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	applyTraceComment(db, config.DbInfo{TraceComment: traceComment})
+	return db
+}
+
+// traceContext 返回携带 traceId 的请求上下文
+func traceContext(traceID string) context.Context {
+	rc := ginContext.NewRequestContext()
+	rc.SetTraceID(traceID)
+	return ginContext.WithRequestContext(context.Background(), rc)
+}
+
+// TestTraceComment_SQL 测试生成的 SQL 附带 traceId 注释
+//
+// 【功能点】验证开启 traceComment 后各类操作生成的 SQL 以 /* trace:<traceId> */ 开头
+// 【测试流程】以 DryRun 会话分别执行创建、查询、更新、删除、原生查询和 Exec，断言 SQL 前缀
+func TestTraceComment_SQL(t *testing.T) {
+	db := openTraceCommentDB(t, true)
+	session := db.Session(&gorm.Session{DryRun: true}).WithContext(traceContext("abc123"))
+
+	statements := map[string]*gorm.Statement{
+		"create": session.Create(&traceCommentUser{Name: "a"}).Statement,
+		"query":  session.Where("name = ?", "a").Find(&[]traceCommentUser{}).Statement,
+		"update": session.Model(&traceCommentUser{ID: 1}).Update("name", "b").Statement,
+		"delete": session.Delete(&traceCommentUser{ID: 1}).Statement,
+		"raw":    session.Raw("SELECT 1").Scan(&[]int{}).Statement,
+		"exec":   session.Exec("DELETE FROM trace_comment_users").Statement,
+	}
+	for name, stmt := range statements {
+		assert.True(t, strings.HasPrefix(stmt.SQL.String(), "/* trace:abc123 */ "), "%s: %s", name, stmt.SQL.String())
+	}
+	assert.True(t, strings.HasPrefix(statements["query"].SQL.String(), "/* trace:abc123 */ SELECT"))
+}
+
+// TestTraceComment_Skip 测试不附加注释的场景
+//
+// 【功能点】验证 context 中没有 traceId，或未开启 traceComment 时 SQL 保持不变
+// 【测试流程】
+//  1. 开启 traceComment，使用不含 traceId 的 context 查询，断言 SQL 以 SELECT 开头
+//  2. 未开启 traceComment，使用含 traceId 的 context 查询，断言 SQL 以 SELECT 开头
+func TestTraceComment_Skip(t *testing.T) {
+	db := openTraceCommentDB(t, true)
+	stmt := db.Session(&gorm.Session{DryRun: true}).WithContext(context.Background()).Find(&[]traceCommentUser{}).Statement
+	assert.True(t, strings.HasPrefix(stmt.SQL.String(), "SELECT"), stmt.SQL.String())
+
+	db = openTraceCommentDB(t, false)
+	stmt = db.Session(&gorm.Session{DryRun: true}).WithContext(traceContext("abc123")).Find(&[]traceCommentUser{}).Statement
+	assert.True(t, strings.HasPrefix(stmt.SQL.String(), "SELECT"), stmt.SQL.String())
+}
+
+// TestTraceComment_Sanitize 测试 traceId 的过滤
+//
+// 【功能点】验证来自请求头的 traceId 不能提前结束注释或注入 SQL，过长时被截断
+// 【测试流程】
+//  1. traceId 为 "abc */ DROP TABLE users; --"，断言注释中只保留合法字符
+//  2. traceId 长度为 100，断言截断为 64 个字符
+func TestTraceComment_Sanitize(t *testing.T) {
+	db := openTraceCommentDB(t, true)
+	stmt := db.Session(&gorm.Session{DryRun: true}).WithContext(traceContext("abc */ DROP TABLE users; --")).Find(&[]traceCommentUser{}).Statement
+	assert.True(t, strings.HasPrefix(stmt.SQL.String(), "/* trace:abcDROPTABLEusers-- */ SELECT"), stmt.SQL.String())
+
+	assert.Equal(t, strings.Repeat("a", maxTraceCommentLength), sanitizeTraceComment(strings.Repeat("a", 100)))
+}
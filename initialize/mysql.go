@@ -43,14 +43,20 @@ func InitDB() {
 // InitDBList 初始化多个数据库连接列表
 // 该函数会：
 // 1. 创建数据库实例映射表
-// 2. 遍历所有数据库配置并初始化连接
+// 2. 遍历所有数据库配置并初始化连接，配置了 lazy 的数据库跳过，由 app.TenantDB 在首次使用时通过 app.DBOpener 连接
 // 3. 将数据库实例按别名存储到全局app.DBList中
 func InitDBList() {
 	// 初始化数据库实例映射表
 	app.DBList = make(map[string]*gorm.DB)
+	if app.DBOpener == nil {
+		app.DBOpener = initSingleDB
+	}
 
 	// 遍历所有数据库配置并初始化连接
 	for _, dbConfig := range app.BaseConfig.DbList {
+		if dbConfig.Lazy {
+			continue
+		}
 		dbClient, err := initSingleDB(dbConfig)
 		if err != nil {
 			panic(exception.NewInitErrorWithConfig("db", "初始化连接", dbConfig.AliasName, err))
@@ -86,6 +92,15 @@ func initSingleDB(dbConfig config.DbInfo) (*gorm.DB, error) {
 	// 初始化数据库回调函数（如自动时间字段填充等）
 	initDBCallbacks(DB)
 
+	// 开启 traceComment 时在 SQL 前附加 traceId 注释
+	applyTraceComment(DB, dbConfig)
+
+	// 开启 service.serverTiming.dbSpans 时将查询耗时计入请求耗时分解
+	applyServerTiming(DB, app.BaseConfig.Service)
+
+	// 为通过 app.EnableQueryCache 开启查询缓存的模型提供缓存
+	applyQueryCache(DB, dbConfig)
+
 	// 添加 OpenTelemetry 链路追踪插件
 	if tracing.IsDBTracingEnabled() {
 		dbName := dbConfig.AliasName
@@ -100,7 +115,10 @@ func initSingleDB(dbConfig config.DbInfo) (*gorm.DB, error) {
 	}
 
 	// 配置数据库连接池参数
-	initDBConnConfig(DB, dbConfig)
+	checkDBPoolConfig(dbConfig)
+	if err := ApplyDBPoolConfig(DB, dbConfig); err != nil {
+		return nil, err
+	}
 
 	// 执行数据库迁移（根据配置的迁移模式）
 	Migrate(DB, dbConfig.Migrate)
@@ -66,6 +66,26 @@ func initDBCallbacks(gormDB *gorm.DB) {
 	})
 }
 
+// applyQueryCache 为数据库实例添加查询缓存插件，通过 app.EnableQueryCache 开启查询缓存的模型才会使用缓存
+// 没有模型开启查询缓存或 Redis 未初始化时不添加插件，查询回调和连接池保持不变
+// 数据库名称（aliasName，未配置时为 dbName）作为缓存键的一部分，不同数据库的同名表互不影响
+func applyQueryCache(db *gorm.DB, dbConfig config.DbInfo) {
+	if !app.QueryCacheEnabled() {
+		return
+	}
+	if app.Redis == nil {
+		logger.Warn("[db] 已有模型开启查询缓存，但 Redis 未初始化，不添加查询缓存插件")
+		return
+	}
+	dbName := dbConfig.AliasName
+	if dbName == "" {
+		dbName = dbConfig.DBName
+	}
+	if err := db.Use(app.NewQueryCachePlugin(dbName)); err != nil {
+		logger.Warn("[db] 添加查询缓存插件失败: %v", err)
+	}
+}
+
 // 使用sync.Once确保数据库日志记录器只初始化一次
 var initOnce sync.Once
 var dbLogger *logrus.Logger
@@ -115,7 +135,7 @@ func initGormLoggerConfig(dbConfig config.DbInfo) gormLogger.Config {
 // 该函数会：
 // 1. 设置数据库迁移时禁用外键约束
 // 2. 配置命名策略（单数表名、表前缀等）
-// 3. 设置GORM日志记录器
+// 3. 设置GORM日志记录器（写入框架的数据库日志，支持慢查询日志和慢查询回调）
 func initGormConfig(dbConfig config.DbInfo) *gorm.Config {
 	// 设置单数表名，默认使用单数表名
 	singularTable := true
@@ -133,21 +153,26 @@ func initGormConfig(dbConfig config.DbInfo) *gorm.Config {
 	}
 
 	// 设置GORM日志记录器
-	gormConfig.Logger = gormLogger.New(
-		initDBLogger(),
-		initGormLoggerConfig(dbConfig),
-	)
+	gormConfig.Logger = newGormSQLLogger(initDBLogger(), dbConfig)
 	return gormConfig
 }
 
-// initDBConnConfig 初始化数据库连接池配置
+// ApplyDBPoolConfig 设置数据库连接池参数
+// 直接修改现有 sql.DB 的参数，不会重建连接池，配置变更后可再次调用使新参数生效
 // 该函数会：
 // 1. 获取底层sql.DB实例
 // 2. 设置最大空闲连接数
 // 3. 设置最大打开连接数
 // 4. 设置连接最大空闲时间
 // 5. 设置连接最大生命周期
-func initDBConnConfig(gormDB *gorm.DB, dbConfig config.DbInfo) error {
+//
+// 参数：
+//   - gormDB: 数据库实例
+//   - dbConfig: 数据库配置，未配置的参数使用默认值
+//
+// 返回：
+//   - error: 获取底层sql.DB失败时返回错误
+func ApplyDBPoolConfig(gormDB *gorm.DB, dbConfig config.DbInfo) error {
 	// 获取底层的sql.DB实例以配置连接池
 	SqlDB, err := gormDB.DB()
 	if err != nil {
@@ -155,9 +180,24 @@ func initDBConnConfig(gormDB *gorm.DB, dbConfig config.DbInfo) error {
 	}
 
 	// 设置连接池参数，使用配置值或默认值
-	SqlDB.SetMaxIdleConns(max(dbConfig.MaxIdleConns, 10))                                    // 最大空闲连接数，默认10
-	SqlDB.SetMaxOpenConns(max(dbConfig.MaxOpenConns, 100))                                   // 最大打开连接数，默认100
-	SqlDB.SetConnMaxIdleTime(time.Duration(max(dbConfig.ConnMaxIdleTime, 60)) * time.Second) // 连接最大空闲时间，默认60秒
-	SqlDB.SetConnMaxLifetime(time.Duration(max(dbConfig.ConnMaxLifetime, 60)) * time.Second) // 连接最大生命周期，默认60秒
+	SqlDB.SetMaxOpenConns(dbConfig.GetMaxOpenConns())       // 最大打开连接数，默认100
+	SqlDB.SetMaxIdleConns(dbConfig.GetMaxIdleConns())       // 最大空闲连接数，默认10
+	SqlDB.SetConnMaxIdleTime(dbConfig.GetConnMaxIdleTime()) // 连接最大空闲时间，默认60秒
+	SqlDB.SetConnMaxLifetime(dbConfig.GetConnMaxLifetime()) // 连接最大生命周期，默认60秒
 	return nil
 }
+
+// checkDBPoolConfig 检查连接池参数，不合理时记录警告日志
+// 1. 最大空闲连接数大于最大打开连接数时，sql.DB 会将最大空闲连接数限制为最大打开连接数
+// 2. 生产环境不限制连接最大生命周期时，连接可能因数据库或负载均衡的空闲超时失效
+func checkDBPoolConfig(dbConfig config.DbInfo) {
+	aliasName := dbConfig.GetAliasName()
+	maxOpenConns, maxIdleConns := dbConfig.GetMaxOpenConns(), dbConfig.GetMaxIdleConns()
+	if maxOpenConns > 0 && maxIdleConns > maxOpenConns {
+		logger.Warn("[db] 数据库 `%s` 的最大空闲连接数(%d)大于最大打开连接数(%d), 将被限制为 %d",
+			aliasName, maxIdleConns, maxOpenConns, maxOpenConns)
+	}
+	if app.Env == constant.ProdEnv && dbConfig.GetConnMaxLifetime() == 0 {
+		logger.Warn("[db] 数据库 `%s` 未限制连接最大生命周期(connMaxLifetime), 连接可能因数据库或负载均衡的空闲超时失效", aliasName)
+	}
+}
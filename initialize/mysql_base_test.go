@@ -0,0 +1,168 @@
+// Package initialize 数据库连接池配置测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含数据库连接池参数的单元测试，使用 SQLite 内存数据库，不需要 MySQL。
+//
+// 测试覆盖内容：
+// 1. 配置的连接池参数应用到底层 sql.DB，小于默认值的配置同样生效
+// 2. 未配置的参数使用默认值，小于0时不限制
+// 3. 再次调用时直接修改现有连接池的参数，不重建连接池
+// 4. 只有模型开启了查询缓存且 Redis 已初始化时才添加查询缓存插件
+//
+// 运行测试：go test -v ./initialize/... -run DBPoolConfig
+// ==================================================
+package initialize
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// openPoolTestDB 打开 SQLite 内存数据库
+func openPoolTestDB(t *testing.T) (*gorm.DB, *sql.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db, sqlDB
+}
+
+// sqlDBDuration 通过反射读取 sql.DB 未导出的时间参数（maxLifetime、maxIdleTime）
+func sqlDBDuration(sqlDB *sql.DB, field string) time.Duration {
+	return time.Duration(reflect.ValueOf(sqlDB).Elem().FieldByName(field).Int())
+}
+
+// idleAfterRelease 同时占用 n 个连接后释放，返回释放后的空闲连接数
+func idleAfterRelease(t *testing.T, sqlDB *sql.DB, n int) int {
+	ctx := context.Background()
+	conns := make([]*sql.Conn, 0, n)
+	for i := 0; i < n; i++ {
+		conn, err := sqlDB.Conn(ctx)
+		require.NoError(t, err)
+		conns = append(conns, conn)
+	}
+	for _, conn := range conns {
+		require.NoError(t, conn.Close())
+	}
+	return sqlDB.Stats().Idle
+}
+
+// TestApplyDBPoolConfig 测试连接池参数
+//
+// 【功能点】验证配置的连接池参数应用到底层 sql.DB，小于默认值的配置同样生效
+// 【测试流程】
+//  1. 配置 maxOpenConns=5、maxIdleConns=2、connMaxIdleTime=30、connMaxLifetime=120
+//  2. 断言最大打开连接数为 5，占用 4 个连接后释放只保留 2 个空闲连接
+//  3. 通过反射断言连接最大空闲时间和最大生命周期
+func TestApplyDBPoolConfig(t *testing.T) {
+	db, sqlDB := openPoolTestDB(t)
+	require.NoError(t, ApplyDBPoolConfig(db, config.DbInfo{
+		MaxOpenConns:    5,
+		MaxIdleConns:    2,
+		ConnMaxIdleTime: 30,
+		ConnMaxLifetime: 120,
+	}))
+
+	assert.Equal(t, 5, sqlDB.Stats().MaxOpenConnections)
+	assert.Equal(t, 2, idleAfterRelease(t, sqlDB, 4))
+	assert.Equal(t, 30*time.Second, sqlDBDuration(sqlDB, "maxIdleTime"))
+	assert.Equal(t, 120*time.Second, sqlDBDuration(sqlDB, "maxLifetime"))
+}
+
+// TestApplyDBPoolConfig_Defaults 测试连接池参数的默认值
+//
+// 【功能点】验证未配置的参数使用默认值，小于0时不限制
+// 【测试流程】
+//  1. 使用空配置，断言各参数为默认值
+//  2. 各参数配置为 -1，断言最大打开连接数、最大空闲时间、最大生命周期为 0（不限制），不保留空闲连接
+func TestApplyDBPoolConfig_Defaults(t *testing.T) {
+	db, sqlDB := openPoolTestDB(t)
+	require.NoError(t, ApplyDBPoolConfig(db, config.DbInfo{}))
+	assert.Equal(t, config.DefaultDbMaxOpenConns, sqlDB.Stats().MaxOpenConnections)
+	assert.Equal(t, config.DefaultDbConnMaxIdleTime*time.Second, sqlDBDuration(sqlDB, "maxIdleTime"))
+	assert.Equal(t, config.DefaultDbConnMaxLifetime*time.Second, sqlDBDuration(sqlDB, "maxLifetime"))
+
+	db, sqlDB = openPoolTestDB(t)
+	require.NoError(t, ApplyDBPoolConfig(db, config.DbInfo{
+		MaxOpenConns:    -1,
+		MaxIdleConns:    -1,
+		ConnMaxIdleTime: -1,
+		ConnMaxLifetime: -1,
+	}))
+	assert.Equal(t, 0, sqlDB.Stats().MaxOpenConnections)
+	assert.Equal(t, 0, idleAfterRelease(t, sqlDB, 2))
+	assert.Equal(t, time.Duration(0), sqlDBDuration(sqlDB, "maxIdleTime"))
+	assert.Equal(t, time.Duration(0), sqlDBDuration(sqlDB, "maxLifetime"))
+}
+
+// TestApplyDBPoolConfig_Reapply 测试重新设置连接池参数
+//
+// 【功能点】验证再次调用时直接修改现有连接池的参数，已有的空闲连接按新参数关闭，不重建连接池
+// 【测试流程】
+//  1. 以 maxIdleConns=4 设置参数，占用 4 个连接后释放，断言保留 4 个空闲连接
+//  2. 以 maxOpenConns=2、maxIdleConns=1 重新设置，断言底层 sql.DB 不变，最大打开连接数为 2，空闲连接减少到 1
+func TestApplyDBPoolConfig_Reapply(t *testing.T) {
+	db, sqlDB := openPoolTestDB(t)
+	require.NoError(t, ApplyDBPoolConfig(db, config.DbInfo{MaxOpenConns: 10, MaxIdleConns: 4}))
+	assert.Equal(t, 4, idleAfterRelease(t, sqlDB, 4))
+
+	require.NoError(t, ApplyDBPoolConfig(db, config.DbInfo{MaxOpenConns: 2, MaxIdleConns: 1}))
+	current, err := db.DB()
+	require.NoError(t, err)
+	assert.Same(t, sqlDB, current)
+	assert.Equal(t, 2, sqlDB.Stats().MaxOpenConnections)
+	assert.Equal(t, 1, sqlDB.Stats().Idle)
+}
+
+// queryCacheTestModel 开启查询缓存的测试模型
+type queryCacheTestModel struct {
+	ID uint
+}
+
+// TestApplyQueryCache 测试按需添加查询缓存插件
+//
+// 【功能点】验证没有模型开启查询缓存、或 Redis 未初始化时不添加插件，连接池保持为 *sql.DB；两者都满足时添加插件
+// 【测试流程】
+//  1. 没有模型开启查询缓存时调用 applyQueryCache，断言未添加插件且连接池为 *sql.DB
+//  2. 开启模型的查询缓存、Redis 未初始化时调用，断言未添加插件
+//  3. 设置 miniredis 为 app.Redis 后调用，断言添加了插件
+func TestApplyQueryCache(t *testing.T) {
+	originalRedis := app.Redis
+	t.Cleanup(func() {
+		app.Redis = originalRedis
+		app.EnableQueryCache(&queryCacheTestModel{}, 0)
+	})
+	dbConfig := config.DbInfo{DBName: "test"}
+
+	app.Redis = nil
+	db, _ := openPoolTestDB(t)
+	applyQueryCache(db, dbConfig)
+	assert.NotContains(t, db.Plugins, "gin_core:query_cache")
+	assert.IsType(t, &sql.DB{}, db.ConnPool)
+
+	app.EnableQueryCache(&queryCacheTestModel{}, time.Minute)
+	applyQueryCache(db, dbConfig)
+	assert.NotContains(t, db.Plugins, "gin_core:query_cache")
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	app.Redis = client
+	db, _ = openPoolTestDB(t)
+	applyQueryCache(db, dbConfig)
+	assert.Contains(t, db.Plugins, "gin_core:query_cache")
+}
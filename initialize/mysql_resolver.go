@@ -4,7 +4,6 @@ package initialize
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/zzsen/gin_core/app"
 	"github.com/zzsen/gin_core/exception"
@@ -76,18 +75,33 @@ func initMultiDB(dbResolvers config.DbResolvers) (*gorm.DB, error) {
 	}
 
 	// 设置连接池参数，使用配置值或默认值
-	resolverPlugin.SetMaxIdleConns(max(defaultDBConfig.MaxIdleConns, 10))                                    // 最大空闲连接数，默认10
-	resolverPlugin.SetMaxOpenConns(max(defaultDBConfig.MaxOpenConns, 100))                                   // 最大打开连接数，默认100
-	resolverPlugin.SetConnMaxIdleTime(time.Duration(max(defaultDBConfig.ConnMaxIdleTime, 60)) * time.Second) // 连接最大空闲时间，默认60秒
-	resolverPlugin.SetConnMaxLifetime(time.Duration(max(defaultDBConfig.ConnMaxLifetime, 60)) * time.Second) // 连接最大生命周期，默认60秒
+	checkDBPoolConfig(defaultDBConfig)
+	resolverPlugin.SetMaxIdleConns(defaultDBConfig.GetMaxIdleConns())       // 最大空闲连接数，默认10
+	resolverPlugin.SetMaxOpenConns(defaultDBConfig.GetMaxOpenConns())       // 最大打开连接数，默认100
+	resolverPlugin.SetConnMaxIdleTime(defaultDBConfig.GetConnMaxIdleTime()) // 连接最大空闲时间，默认60秒
+	resolverPlugin.SetConnMaxLifetime(defaultDBConfig.GetConnMaxLifetime()) // 连接最大生命周期，默认60秒
 
 	// 将解析器插件应用到数据库连接
 	if err := DB.Use(resolverPlugin); err != nil {
 		return nil, fmt.Errorf("启用db resolver plugin失败: %w", err)
 	}
 
+	// 解析器插件只设置 Sources、Replicas 的连接池，默认连接同样需要设置
+	if err := ApplyDBPoolConfig(DB, defaultDBConfig); err != nil {
+		return nil, err
+	}
+
 	// 初始化数据库回调函数（如自动时间字段填充等）
 	initDBCallbacks(DB)
 
+	// 开启 traceComment 时在 SQL 前附加 traceId 注释
+	applyTraceComment(DB, defaultDBConfig)
+
+	// 开启 service.serverTiming.dbSpans 时将查询耗时计入请求耗时分解
+	applyServerTiming(DB, app.BaseConfig.Service)
+
+	// 为通过 app.EnableQueryCache 开启查询缓存的模型提供缓存
+	applyQueryCache(DB, defaultDBConfig)
+
 	return DB, nil
 }
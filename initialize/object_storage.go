@@ -0,0 +1,31 @@
+// Package initialize 提供各种服务的初始化功能
+// 本文件专门负责对象存储客户端的初始化配置
+package initialize
+
+import (
+	"fmt"
+
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/exception"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/objectstorage"
+)
+
+// InitObjectStorage 初始化对象存储客户端
+// 该函数会：
+// 1. 检查 objectStorage.enabled 是否开启
+// 2. 根据配置创建客户端（不发送请求，存储桶的可用性由健康检查探测）
+// 3. 将客户端实例存储到全局app.Storage中
+func InitObjectStorage() {
+	cfg := app.BaseConfig.ObjectStorage
+	if !cfg.Enabled {
+		panic(exception.NewInitError("objectStorage", "检查配置", fmt.Errorf("system.useObjectStorage 已开启，但 objectStorage.enabled 未开启")))
+	}
+
+	client, err := objectstorage.New(cfg)
+	if err != nil {
+		panic(exception.NewInitError("objectStorage", "创建客户端", err))
+	}
+	app.Storage = client
+	logger.Info("[objectStorage] 对象存储已初始化, provider: %s, endpoint: %s, bucket: %s", cfg.GetProvider(), cfg.GetEndpoint(), cfg.Bucket)
+}
@@ -4,10 +4,12 @@ package initialize
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
 	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/exception/mq"
 	"github.com/zzsen/gin_core/logger"
 	"github.com/zzsen/gin_core/model/config"
 )
@@ -49,6 +51,8 @@ func InitialRabbitMqWithContext(ctx context.Context, messageQueueList ...*config
 	for _, mq := range messageQueueList {
 		// 将消息队列配置存储到映射表中，键为队列信息
 		messageQueueMap[mq.GetInfo()] = mq
+		// 登记到 app.RabbitMQConsumerList，供 app.RabbitMQStats 汇总消费统计
+		app.RabbitMQConsumerList.Store(mq.GetInfo(), mq)
 		// 为每个消息队列启动独立的消费者协程
 		go startMqConsumeWithContext(ctx, mq)
 	}
@@ -85,35 +89,49 @@ func InitialRabbitMqWithContext(ctx context.Context, messageQueueList ...*config
 
 // startMqConsumeWithContext 启动单个消息队列消费者（支持 context）
 // 该函数会：
-// 1. 获取消息队列连接字符串
-// 2. 根据配置选择对应的RabbitMQ实例
+// 1. 根据配置选择对应的RabbitMQ实例
+// 2. 获取消息队列连接字符串和 TLS 配置
 // 3. 启动消费者并处理消息
 // 4. 如果出错，将队列名称发送到重启通道
 // 参数：
 //   - ctx: 用于控制消费者生命周期的 context
 //   - messageQueue: 消息队列配置信息
 func startMqConsumeWithContext(ctx context.Context, messageQueue *config.MessageQueue) {
-	// 获取消息队列连接字符串，默认使用基础配置
-	mqConnStr := app.BaseConfig.RabbitMQ.Url()
+	// 获取消息队列实例配置，默认使用基础配置
+	mqInfo := &app.BaseConfig.RabbitMQ
 
 	// 如果配置了特定的消息队列名称，则使用对应的消息队列配置
 	if messageQueue.MQName != "" {
-		mqConnStr = app.BaseConfig.RabbitMQList.Url(messageQueue.MQName)
+		mqInfo = app.BaseConfig.RabbitMQList.Find(messageQueue.MQName)
 	}
 
-	// 检查是否找到有效的连接字符串
-	if mqConnStr == "" {
+	// 检查是否找到有效的实例配置
+	if mqInfo == nil {
 		logger.Error("[消息队列] 未找到对应的消息队列配置, MQName: %s", messageQueue.MQName)
 		return
 	}
+	tlsConfig, err := mqInfo.TLSConfig()
+	if err != nil {
+		logger.Error("[消息队列] 生成 TLS 配置失败, queueInfo: %s, error: %v", messageQueue.GetInfo(), err)
+		return
+	}
 
-	// 设置消息队列连接字符串
-	messageQueue.MqConnStr = mqConnStr
+	// 设置消息队列连接字符串和 TLS 配置
+	messageQueue.MqConnStr = mqInfo.Url()
+	messageQueue.TLSConfig = tlsConfig
+
+	if messageQueue.Dedup.Enabled {
+		initMqDedup(messageQueue)
+	}
+	initMqRetryDecision(messageQueue)
 
 	// 创建子 context 用于单个消费者
 	consumerCtx, cancel := context.WithCancel(ctx)
 	queueInfo := messageQueue.GetInfo()
 
+	// 消费函数收到的 context 携带绑定了队列信息的子日志记录器，通过 logger.FromContext(ctx) 获取
+	consumerCtx = logger.NewContext(consumerCtx, logger.With(map[string]any{"queueInfo": queueInfo}))
+
 	// 存储取消函数
 	consumerCancelLock.Lock()
 	consumerCancelFuncs[queueInfo] = cancel
@@ -129,7 +147,7 @@ func startMqConsumeWithContext(ctx context.Context, messageQueue *config.Message
 	logger.Info("[消息队列] 消费者启动, queueInfo: %s", queueInfo)
 
 	// 启动消费者并开始处理消息
-	err := messageQueue.ConsumeWithContext(consumerCtx)
+	err = messageQueue.ConsumeWithContext(consumerCtx)
 	if err != nil {
 		// 检查是否是因为 context 取消
 		select {
@@ -145,6 +163,80 @@ func startMqConsumeWithContext(ctx context.Context, messageQueue *config.Message
 	}
 }
 
+// initMqDedup 初始化消息队列的消费去重
+// 未设置 Backend 且存储类型为 redis 时使用 app.Redis 创建存储，Redis 未初始化时回退为进程内存储；
+// 未设置回调时，跳过重复消息输出 debug 日志，存储读写失败输出 warn 日志
+// 参数：
+//   - messageQueue: 消息队列配置信息
+func initMqDedup(messageQueue *config.MessageQueue) {
+	dedup := &messageQueue.Dedup
+	queueInfo := messageQueue.GetInfo()
+
+	if dedup.Backend == nil {
+		switch dedup.GetStore() {
+		case config.DedupStoreRedis:
+			if app.Redis != nil {
+				dedup.Backend = config.NewRedisDedupStore(app.Redis, dedup.GetKeyPrefix())
+			} else {
+				logger.Warn("[消息队列] 消费去重使用 redis 存储但 Redis 未初始化，回退为进程内存储, queueInfo: %s", queueInfo)
+			}
+		case config.DedupStoreMemory:
+		default:
+			logger.Warn("[消息队列] 未知的消费去重存储类型: %s，使用进程内存储, queueInfo: %s", dedup.Store, queueInfo)
+		}
+	}
+
+	if dedup.OnDuplicate == nil {
+		dedup.OnDuplicate = func(messageID string, firstSeen time.Time) {
+			logger.Debug("[消息队列] 跳过重复消息, queueInfo: %s, messageId: %s, 首次消费时间: %s",
+				queueInfo, messageID, firstSeen.Format(time.RFC3339Nano))
+		}
+	}
+	if dedup.OnStoreError == nil {
+		dedup.OnStoreError = func(messageID string, err error) {
+			logger.Warn("[消息队列] 消费去重存储读写失败，消息将照常处理, queueInfo: %s, messageId: %s, err: %v",
+				queueInfo, messageID, err)
+		}
+	}
+}
+
+// initMqRetryDecision 设置消费失败处理决定的默认回调
+// 未设置 OnRetryDecision 时输出日志：立即重新入队为 debug，延迟重试为 info，丢弃、不重试和超过重试次数为 warn
+// 参数：
+//   - messageQueue: 消息队列配置信息
+func initMqRetryDecision(messageQueue *config.MessageQueue) {
+	if messageQueue.ConsumeConfig.OnRetryDecision != nil {
+		return
+	}
+	messageQueue.ConsumeConfig.OnRetryDecision = func(decision config.RetryDecision) {
+		fields := map[string]any{
+			"queue":     decision.Queue,     // 队列标识
+			"messageId": decision.MessageID, // 消息 ID
+			"action":    decision.Action,    // 处理决定
+			"attempts":  decision.Attempts,  // 已重试次数
+			"maxRetry":  decision.MaxRetry,  // 最大重试次数
+		}
+		if decision.Err != nil {
+			fields["errStr"] = decision.Err.Error()
+		}
+		switch decision.Action {
+		case config.RetryActionRequeue:
+			logger.DebugWithFields(fields, "[消息队列] 消费失败，重新入队")
+		case config.RetryActionDelay:
+			fields["delay"] = decision.Delay.String()
+			logger.InfoWithFields(fields, "[消息队列] 消费失败，延迟重试")
+		case config.RetryActionDiscard:
+			logger.WarnWithFields(fields, "[消息队列] 消费失败，丢弃消息")
+		default:
+			if errors.Is(decision.Err, mq.ErrDeadLetter) {
+				logger.WarnWithFields(fields, "[消息队列] 消费失败，不重试，拒绝消息")
+				return
+			}
+			logger.WarnWithFields(fields, "[消息队列] 消费失败，超过最大重试次数")
+		}
+	}
+}
+
 // StopConsumer 停止指定的消费者
 // 参数：
 //   - queueInfo: 队列信息（由 MessageQueue.GetInfo() 返回）
@@ -3,6 +3,9 @@
 package initialize
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/zzsen/gin_core/app"
 	"github.com/zzsen/gin_core/logger"
 	"github.com/zzsen/gin_core/model/config"
@@ -19,44 +22,80 @@ func InitialRabbitMqProducer(messageQueueList ...*config.MessageQueue) {
 	// 遍历所有消息队列配置并初始化发送者
 	for _, mq := range messageQueueList {
 		// 初始化单个消息队列发送者
-		initMqProducer(mq)
+		_ = initMqProducer(mq)
 	}
 }
 
+// ConnectRabbitMqProducer 初始化尚未初始化成功的RabbitMQ消息队列发送者，并返回连接错误
+// 与 InitialRabbitMqProducer 相同，但已存储到全局映射表中的发送者会被跳过，可以重复调用，用于启动时等待RabbitMQ就绪
+// 参数：
+//   - messageQueueList: 消息队列配置列表，支持可变参数
+//
+// 返回：
+//   - error: 初始化连接和通道失败的发送者的错误；未找到实例配置的发送者只记录日志，不返回错误
+func ConnectRabbitMqProducer(messageQueueList ...*config.MessageQueue) error {
+	var errs []error
+	for _, mq := range messageQueueList {
+		if _, ok := app.RabbitMQProducerList.Load(mq.GetInfo()); ok {
+			continue
+		}
+		if err := initMqProducer(mq); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // initMqProducer 初始化单个消息队列发送者
 // 该函数会：
-// 1. 获取消息队列连接字符串
-// 2. 根据配置选择对应的RabbitMQ实例
+// 1. 根据配置选择对应的RabbitMQ实例
+// 2. 设置连接字符串和发布通道池容量
 // 3. 初始化连接和通道
 // 4. 将初始化好的发送者存储到全局映射表中
 // 参数：
 //   - messageQueue: 消息队列配置信息
-func initMqProducer(messageQueue *config.MessageQueue) {
-	// 获取消息队列连接字符串，默认使用基础配置
-	mqConnStr := app.BaseConfig.RabbitMQ.Url()
+//
+// 返回：
+//   - error: 初始化连接和通道失败时返回错误
+func initMqProducer(messageQueue *config.MessageQueue) error {
+	// 获取消息队列实例配置，默认使用基础配置
+	mqInfo := &app.BaseConfig.RabbitMQ
 
 	// 如果配置了特定的消息队列名称，则使用对应的消息队列配置
 	if messageQueue.MQName != "" {
-		mqConnStr = app.BaseConfig.RabbitMQList.Url(messageQueue.MQName)
+		mqInfo = app.BaseConfig.RabbitMQList.Find(messageQueue.MQName)
 	}
 
-	// 检查是否找到有效的连接字符串
-	if mqConnStr == "" {
+	// 检查是否找到有效的实例配置
+	if mqInfo == nil {
 		logger.Error("[消息队列] 未找到对应的消息队列配置, MQName: %s", messageQueue.MQName)
-		return
+		return nil
 	}
 
-	// 设置消息队列连接字符串
-	messageQueue.MqConnStr = mqConnStr
+	// 设置消息队列连接字符串、TLS 配置、发布通道池容量和发布限流器（未显式指定时使用实例配置）
+	tlsConfig, err := mqInfo.TLSConfig()
+	if err != nil {
+		logger.Error("[消息队列] 生成 TLS 配置失败, queueInfo: %s, error: %v", messageQueue.GetInfo(), err)
+		return fmt.Errorf("生成 TLS 配置失败, queueInfo: %s: %w", messageQueue.GetInfo(), err)
+	}
+	messageQueue.MqConnStr = mqInfo.Url()
+	messageQueue.TLSConfig = tlsConfig
+	if messageQueue.PublisherChannelPoolSize <= 0 {
+		messageQueue.PublisherChannelPoolSize = mqInfo.GetPublisherChannelPoolSize()
+	}
+	if messageQueue.PublishThrottle == nil {
+		messageQueue.PublishThrottle = app.RabbitMQPublishThrottle(messageQueue.MQName)
+	}
 
 	// 初始化连接和通道（不立即使用，只是预初始化）
-	err := messageQueue.InitChannelForProducer()
+	err = messageQueue.InitChannelForProducer()
 	if err != nil {
 		logger.Error("[消息队列] 初始化发送者失败, queueInfo: %s, error: %v", messageQueue.GetInfo(), err)
-		return
+		return fmt.Errorf("初始化发送者失败, queueInfo: %s: %w", messageQueue.GetInfo(), err)
 	}
 
 	// 将初始化好的发送者存储到全局映射表中（使用 sync.Map）
 	app.RabbitMQProducerList.Store(messageQueue.GetInfo(), messageQueue)
 	logger.Info("[消息队列] 发送者初始化成功, queueInfo: %s", messageQueue.GetInfo())
+	return nil
 }
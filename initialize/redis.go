@@ -1,5 +1,5 @@
 // Package initialize 提供各种服务的初始化功能
-// 本文件专门负责Redis客户端的初始化，支持单实例和集群模式，以及多实例列表配置
+// 本文件专门负责Redis客户端的初始化，支持单实例、哨兵和集群模式，以及多实例列表配置
 package initialize
 
 import (
@@ -17,21 +17,27 @@ import (
 	"github.com/zzsen/gin_core/logger"
 )
 
-// initRedisClient 初始化单个Redis客户端
-// 该函数会：
-// 1. 根据配置选择Redis模式（集群模式或单实例模式）
-// 2. 创建对应的Redis客户端实例
-// 3. 添加链路追踪钩子（如果已启用）
-// 4. 测试连接并返回客户端
+// Redis 客户端构造函数，单元测试中可替换，用于验证部署模式的选择
+var (
+	newRedisClient         = func(opt *redis.Options) redis.UniversalClient { return redis.NewClient(opt) }
+	newRedisFailoverClient = func(opt *redis.FailoverOptions) redis.UniversalClient { return redis.NewFailoverClient(opt) }
+	newRedisClusterClient  = func(opt *redis.ClusterOptions) redis.UniversalClient { return redis.NewClusterClient(opt) }
+)
+
+// newRedisUniversalClient 按部署模式创建Redis客户端，不测试连接
+// 三种模式均返回 redis.UniversalClient，调用方无需区分：
+//   - standalone: redis.NewClient，连接 addr
+//   - sentinel: redis.NewFailoverClient，通过 sentinelAddrs 发现 masterName 对应的主节点，主节点切换后自动重连
+//   - cluster: redis.NewClusterClient，连接 clusterAddrs，不支持 db
+//
 // 参数：
 //   - redisCfg: Redis配置信息
 //
 // 返回：
 //   - redis.UniversalClient: Redis客户端实例
-//   - error: 错误信息
-func initRedisClient(redisCfg config.RedisInfo) (redis.UniversalClient, error) {
-	var client redis.UniversalClient
-
+//   - string: 用于日志和链路追踪的地址（单实例地址、哨兵主节点名称或第一个集群节点地址）
+//   - error: 部署模式无法识别时返回错误
+func newRedisUniversalClient(redisCfg config.RedisInfo) (redis.UniversalClient, string, error) {
 	// 获取连接池配置，使用默认值
 	poolSize := redisCfg.PoolSize
 	if poolSize <= 0 {
@@ -41,57 +47,83 @@ func initRedisClient(redisCfg config.RedisInfo) (redis.UniversalClient, error) {
 	if minIdleConns <= 0 {
 		minIdleConns = constant.DefaultRedisMinIdleConns
 	}
-
-	// 根据配置选择Redis模式
-	if redisCfg.UseCluster {
-		// 使用集群模式，支持Redis Cluster
-		clusterClient := redis.NewClusterClient(&redis.ClusterOptions{
-			Addrs:        redisCfg.ClusterAddrs,                                         // 集群节点地址列表
-			Password:     redisCfg.Password,                                             // 集群访问密码
-			PoolSize:     poolSize,                                                      // 连接池大小
-			MinIdleConns: minIdleConns,                                                  // 最小空闲连接数
-			PoolTimeout:  time.Duration(constant.DefaultRedisPoolTimeout) * time.Second, // 获取连接超时时间
-		})
-
-		// 添加 OpenTelemetry 链路追踪钩子
-		if tracing.IsRedisTracingEnabled() {
-			addr := "cluster"
-			if len(redisCfg.ClusterAddrs) > 0 {
-				addr = redisCfg.ClusterAddrs[0]
-			}
-			clusterClient.AddHook(tracing.NewRedisTracingHook(addr, redisCfg.AliasName, 0))
-			logger.Info("[redis] 链路追踪钩子已添加, 别名: %s (集群模式)", redisCfg.AliasName)
+	poolTimeout := time.Duration(constant.DefaultRedisPoolTimeout) * time.Second
+
+	switch mode := redisCfg.GetMode(); mode {
+	case config.RedisModeStandalone:
+		return newRedisClient(&redis.Options{
+			Addr:         redisCfg.Addr,     // Redis服务器地址
+			Password:     redisCfg.Password, // Redis访问密码
+			DB:           redisCfg.DB,       // 数据库编号
+			PoolSize:     poolSize,          // 连接池大小
+			MinIdleConns: minIdleConns,      // 最小空闲连接数
+			PoolTimeout:  poolTimeout,       // 获取连接超时时间
+		}), redisCfg.Addr, nil
+	case config.RedisModeSentinel:
+		return newRedisFailoverClient(&redis.FailoverOptions{
+			MasterName:    redisCfg.MasterName,    // 哨兵监控的主节点名称
+			SentinelAddrs: redisCfg.SentinelAddrs, // 哨兵节点地址列表
+			Password:      redisCfg.Password,      // 主从节点访问密码
+			DB:            redisCfg.DB,            // 数据库编号
+			PoolSize:      poolSize,               // 连接池大小
+			MinIdleConns:  minIdleConns,           // 最小空闲连接数
+			PoolTimeout:   poolTimeout,            // 获取连接超时时间
+		}), redisCfg.MasterName, nil
+	case config.RedisModeCluster:
+		addr := "cluster"
+		if len(redisCfg.ClusterAddrs) > 0 {
+			addr = redisCfg.ClusterAddrs[0]
 		}
+		return newRedisClusterClient(&redis.ClusterOptions{
+			Addrs:        redisCfg.ClusterAddrs, // 集群节点地址列表
+			Password:     redisCfg.Password,     // 集群访问密码
+			PoolSize:     poolSize,              // 连接池大小
+			MinIdleConns: minIdleConns,          // 最小空闲连接数
+			PoolTimeout:  poolTimeout,           // 获取连接超时时间
+		}), addr, nil
+	default:
+		return nil, "", fmt.Errorf("无法识别的部署模式: %s（可选 standalone、sentinel、cluster）", mode)
+	}
+}
 
-		client = clusterClient
-	} else {
-		// 使用单实例模式，连接单个Redis服务器
-		singleClient := redis.NewClient(&redis.Options{
-			Addr:         redisCfg.Addr,                                                 // Redis服务器地址
-			Password:     redisCfg.Password,                                             // Redis访问密码
-			DB:           redisCfg.DB,                                                   // 数据库编号
-			PoolSize:     poolSize,                                                      // 连接池大小
-			MinIdleConns: minIdleConns,                                                  // 最小空闲连接数
-			PoolTimeout:  time.Duration(constant.DefaultRedisPoolTimeout) * time.Second, // 获取连接超时时间
-		})
-
-		// 添加 OpenTelemetry 链路追踪钩子
-		if tracing.IsRedisTracingEnabled() {
-			singleClient.AddHook(tracing.NewRedisTracingHook(redisCfg.Addr, redisCfg.AliasName, redisCfg.DB))
-			logger.Info("[redis] 链路追踪钩子已添加, 别名: %s, 地址: %s", redisCfg.AliasName, redisCfg.Addr)
-		}
+// initRedisClient 初始化单个Redis客户端
+// 该函数会：
+// 1. 根据配置的部署模式（单实例、哨兵或集群）创建对应的Redis客户端实例
+// 2. 添加链路追踪钩子（如果已启用）
+// 3. 测试连接并返回客户端，连接失败时关闭客户端
+// 参数：
+//   - ctx: 上下文，控制连接测试
+//   - redisCfg: Redis配置信息
+//
+// 返回：
+//   - redis.UniversalClient: Redis客户端实例
+//   - error: 错误信息
+func initRedisClient(ctx context.Context, redisCfg config.RedisInfo) (redis.UniversalClient, error) {
+	client, addr, err := newRedisUniversalClient(redisCfg)
+	if err != nil {
+		return nil, err
+	}
+	mode := redisCfg.GetMode()
 
-		client = singleClient
+	// 添加 OpenTelemetry 链路追踪钩子，集群模式不区分数据库
+	if tracing.IsRedisTracingEnabled() {
+		db := redisCfg.DB
+		if mode == config.RedisModeCluster {
+			db = 0
+		}
+		client.AddHook(tracing.NewRedisTracingHook(addr, redisCfg.AliasName, db))
+		logger.Info("[redis] 链路追踪钩子已添加, 别名: %s, 模式: %s, 地址: %s", redisCfg.AliasName, mode, addr)
 	}
 
 	// 测试Redis连接，使用Ping命令验证连通性
-	pong, err := client.Ping(context.Background()).Result()
+	pong, err := client.Ping(ctx).Result()
 	if err != nil {
+		_ = client.Close()
 		return nil, fmt.Errorf("连接失败, ping failed: %w", err)
 	}
 
-	// 记录连接成功日志，包含别名和Ping响应
-	logger.Info("[redis] redis aliasName: %s, connect ping response: %s", redisCfg.AliasName, pong)
+	// 记录连接成功日志，包含别名、部署模式和Ping响应
+	logger.Info("[redis] redis aliasName: %s, mode: %s, connect ping response: %s", redisCfg.AliasName, mode, pong)
 	return client, nil
 }
 
@@ -101,13 +133,20 @@ func initRedisClient(redisCfg config.RedisInfo) (redis.UniversalClient, error) {
 // 2. 初始化Redis客户端连接
 // 3. 将客户端实例存储到全局app.Redis中
 func InitRedis() {
+	InitRedisWithContext(context.Background())
+}
+
+// InitRedisWithContext 初始化单个Redis客户端，上下文取消时连接测试立即结束
+// 参数：
+//   - ctx: 上下文，控制连接测试
+func InitRedisWithContext(ctx context.Context) {
 	// 检查Redis配置是否存在
 	if app.BaseConfig.Redis == nil {
 		panic(exception.NewInitError("redis", "检查配置", fmt.Errorf("未找到Redis配置, 请检查配置")))
 	}
 
 	// 初始化Redis客户端
-	redisClient, err := initRedisClient(*app.BaseConfig.Redis)
+	redisClient, err := initRedisClient(ctx, *app.BaseConfig.Redis)
 	if err != nil {
 		panic(exception.NewInitError("redis", "初始化连接", err))
 	}
@@ -122,13 +161,24 @@ func InitRedis() {
 // 2. 遍历所有Redis配置并初始化连接
 // 3. 将客户端实例按别名存储到全局app.RedisList中
 func InitRedisList() {
+	InitRedisListWithContext(context.Background())
+}
+
+// InitRedisListWithContext 初始化多个Redis客户端列表，上下文取消时连接测试立即结束
+// 任一实例连接失败时关闭已创建的客户端
+// 参数：
+//   - ctx: 上下文，控制连接测试
+func InitRedisListWithContext(ctx context.Context) {
 	// 初始化Redis客户端映射表
 	redisMap := make(map[string]redis.UniversalClient)
 
 	// 遍历所有Redis配置并初始化连接
 	for _, redisCfg := range app.BaseConfig.RedisList {
-		client, err := initRedisClient(redisCfg)
+		client, err := initRedisClient(ctx, redisCfg)
 		if err != nil {
+			for _, created := range redisMap {
+				_ = created.Close()
+			}
 			panic(exception.NewInitErrorWithConfig("redis", "初始化连接", redisCfg.AliasName, err))
 		}
 		// 将Redis客户端实例按别名存储到映射表中
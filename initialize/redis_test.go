@@ -0,0 +1,159 @@
+// Package initialize Redis 客户端初始化测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 Redis 客户端初始化的单元测试，单实例模式使用 miniredis，哨兵和集群模式通过替换客户端构造函数验证，
+// 不需要真实的 Redis 哨兵或集群。
+//
+// 测试覆盖内容：
+// 1. 单实例模式连接 miniredis，读写正常；连接失败时返回错误
+// 2. 部署模式的选择：mode 优先，未配置时兼容 useCluster，连接池参数使用默认值
+// 3. 无法识别的部署模式返回错误
+//
+// 运行测试：go test -v ./initialize/... -run Redis
+// ==================================================
+package initialize
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/constant"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// redisConstructorCalls 记录被替换的 Redis 客户端构造函数收到的参数
+type redisConstructorCalls struct {
+	standalone *redis.Options
+	sentinel   *redis.FailoverOptions
+	cluster    *redis.ClusterOptions
+}
+
+// stubRedisConstructors 替换 Redis 客户端构造函数，记录收到的参数，并统一返回连接 addr 的单实例客户端
+// 测试结束后恢复原构造函数
+func stubRedisConstructors(t *testing.T, addr string) *redisConstructorCalls {
+	calls := &redisConstructorCalls{}
+	origStandalone, origSentinel, origCluster := newRedisClient, newRedisFailoverClient, newRedisClusterClient
+	t.Cleanup(func() {
+		newRedisClient, newRedisFailoverClient, newRedisClusterClient = origStandalone, origSentinel, origCluster
+	})
+
+	stub := func() redis.UniversalClient {
+		client := redis.NewClient(&redis.Options{Addr: addr})
+		t.Cleanup(func() { _ = client.Close() })
+		return client
+	}
+	newRedisClient = func(opt *redis.Options) redis.UniversalClient {
+		calls.standalone = opt
+		return stub()
+	}
+	newRedisFailoverClient = func(opt *redis.FailoverOptions) redis.UniversalClient {
+		calls.sentinel = opt
+		return stub()
+	}
+	newRedisClusterClient = func(opt *redis.ClusterOptions) redis.UniversalClient {
+		calls.cluster = opt
+		return stub()
+	}
+	return calls
+}
+
+// TestInitRedisClient_Standalone 测试单实例模式
+//
+// 【功能点】验证单实例模式连接 miniredis 后可以正常读写，地址不可用时返回连接错误
+// 【测试流程】
+//  1. 以 miniredis 地址初始化客户端（未配置 mode），写入并读取一个键
+//  2. 关闭 miniredis 后再次初始化，断言返回 ping failed 错误
+func TestInitRedisClient_Standalone(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client, err := initRedisClient(context.Background(), config.RedisInfo{AliasName: "default", Addr: mr.Addr()})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx := context.Background()
+	require.NoError(t, client.Set(ctx, "greeting", "hello", time.Minute).Err())
+	assert.Equal(t, "hello", client.Get(ctx, "greeting").Val())
+	assert.True(t, mr.Exists("greeting"))
+
+	addr := mr.Addr()
+	mr.Close()
+	_, err = initRedisClient(context.Background(), config.RedisInfo{Addr: addr})
+	assert.ErrorContains(t, err, "ping failed")
+}
+
+// TestNewRedisUniversalClient_Mode 测试部署模式的选择
+//
+// 【功能点】验证按 mode 选择单实例、哨兵或集群客户端，mode 为空时兼容 useCluster
+// 【测试流程】
+//  1. 替换三种客户端构造函数，逐个模式创建客户端
+//  2. 断言只调用了对应模式的构造函数，地址、主节点名称、数据库等参数正确传递
+//  3. 断言未配置的连接池参数使用默认值，mode 显式配置时忽略 useCluster
+func TestNewRedisUniversalClient_Mode(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	t.Run("standalone", func(t *testing.T) {
+		calls := stubRedisConstructors(t, mr.Addr())
+		_, addr, err := newRedisUniversalClient(config.RedisInfo{Addr: "10.0.0.1:6379", DB: 3, Password: "secret"})
+		require.NoError(t, err)
+		require.NotNil(t, calls.standalone)
+		assert.Nil(t, calls.sentinel)
+		assert.Nil(t, calls.cluster)
+		assert.Equal(t, "10.0.0.1:6379", addr)
+		assert.Equal(t, 3, calls.standalone.DB)
+		assert.Equal(t, "secret", calls.standalone.Password)
+		assert.Equal(t, constant.DefaultRedisPoolSize, calls.standalone.PoolSize)
+		assert.Equal(t, constant.DefaultRedisMinIdleConns, calls.standalone.MinIdleConns)
+	})
+
+	t.Run("sentinel", func(t *testing.T) {
+		calls := stubRedisConstructors(t, mr.Addr())
+		_, addr, err := newRedisUniversalClient(config.RedisInfo{
+			Mode:          config.RedisModeSentinel,
+			MasterName:    "mymaster",
+			SentinelAddrs: []string{"10.0.0.1:26379", "10.0.0.2:26379"},
+			DB:            2,
+			PoolSize:      20,
+			UseCluster:    true,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, calls.sentinel)
+		assert.Nil(t, calls.standalone)
+		assert.Nil(t, calls.cluster)
+		assert.Equal(t, "mymaster", addr)
+		assert.Equal(t, "mymaster", calls.sentinel.MasterName)
+		assert.Equal(t, []string{"10.0.0.1:26379", "10.0.0.2:26379"}, calls.sentinel.SentinelAddrs)
+		assert.Equal(t, 2, calls.sentinel.DB)
+		assert.Equal(t, 20, calls.sentinel.PoolSize)
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		for _, cfg := range []config.RedisInfo{
+			{Mode: config.RedisModeCluster, ClusterAddrs: []string{"10.0.0.1:7000", "10.0.0.2:7000"}},
+			{UseCluster: true, ClusterAddrs: []string{"10.0.0.1:7000", "10.0.0.2:7000"}},
+		} {
+			calls := stubRedisConstructors(t, mr.Addr())
+			_, addr, err := newRedisUniversalClient(cfg)
+			require.NoError(t, err)
+			require.NotNil(t, calls.cluster)
+			assert.Nil(t, calls.standalone)
+			assert.Nil(t, calls.sentinel)
+			assert.Equal(t, "10.0.0.1:7000", addr)
+			assert.Equal(t, cfg.ClusterAddrs, calls.cluster.Addrs)
+		}
+	})
+}
+
+// TestNewRedisUniversalClient_UnknownMode 测试无法识别的部署模式
+//
+// 【功能点】验证部署模式无法识别时返回错误，不创建任何客户端
+// 【测试流程】以 mode: replica 创建客户端，断言错误信息，且三种构造函数均未被调用
+func TestNewRedisUniversalClient_UnknownMode(t *testing.T) {
+	calls := stubRedisConstructors(t, "127.0.0.1:0")
+	_, err := initRedisClient(context.Background(), config.RedisInfo{Mode: "replica", Addr: "127.0.0.1:6379"})
+	assert.ErrorContains(t, err, "无法识别的部署模式: replica")
+	assert.Equal(t, &redisConstructorCalls{}, calls)
+}
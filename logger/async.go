@@ -0,0 +1,213 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// asyncRecord 缓冲区中的一条日志
+type asyncRecord struct {
+	entry *logrus.Entry // 已记录调用位置、时间和结构化字段的日志条目
+	level logrus.Level  // 日志级别
+	msg   string        // 已格式化、尚未脱敏的消息
+}
+
+// asyncWriter 异步日志写入器
+// 日志进入有界缓冲区，由一个写入协程按进入顺序脱敏并写入，同一协程记录的日志保持先后顺序
+type asyncWriter struct {
+	cfg     config.AsyncLogConfig
+	records chan asyncRecord
+	flushes chan chan struct{} // Flush 请求，写入协程写完请求时缓冲区中的日志后关闭该通道
+	quit    chan struct{}      // 关闭后写入协程写完缓冲区中的日志并退出
+	exited  chan struct{}      // 写入协程退出后关闭
+	dropped atomic.Uint64      // 累计丢弃的日志条数
+}
+
+var (
+	// async 当前的异步写入器，为 nil 时同步写入
+	async atomic.Pointer[asyncWriter]
+	// asyncMu 串行化 SetAsync
+	asyncMu sync.Mutex
+)
+
+// SetAsync 按配置开启或关闭异步写入
+// 已开启时先写完原缓冲区中的日志并停止原写入协程，再按新配置启动；cfg.Enabled 为 false 时恢复同步写入。
+// 一般由日志服务在初始化时根据 log.async 调用
+// 参数：
+//   - cfg: 异步日志配置
+func SetAsync(cfg config.AsyncLogConfig) {
+	asyncMu.Lock()
+	defer asyncMu.Unlock()
+
+	var w *asyncWriter
+	if cfg.Enabled {
+		w = &asyncWriter{
+			cfg:     cfg,
+			records: make(chan asyncRecord, cfg.GetBufferSize()),
+			flushes: make(chan chan struct{}),
+			quit:    make(chan struct{}),
+			exited:  make(chan struct{}),
+		}
+		go w.run()
+	}
+	if previous := async.Swap(w); previous != nil {
+		close(previous.quit)
+		<-previous.exited
+	}
+}
+
+// Flush 等待调用前进入缓冲区的日志全部写入，未开启异步写入时直接返回
+// 优雅关闭时调用，保证正常退出时不丢失日志
+// 参数：
+//   - ctx: 上下文，取消或超时时停止等待
+//
+// 返回：
+//   - error: 等待被取消时返回 ctx.Err()
+func Flush(ctx context.Context) error {
+	w := async.Load()
+	if w == nil {
+		return nil
+	}
+	done := make(chan struct{})
+	select {
+	case w.flushes <- done:
+	case <-w.exited:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DroppedCount 返回当前异步写入器因缓冲区已满累计丢弃的日志条数，未开启异步写入时返回 0
+func DroppedCount() uint64 {
+	if w := async.Load(); w != nil {
+		return w.dropped.Load()
+	}
+	return 0
+}
+
+// logEntry 记录一条日志
+// 未开启异步写入，或开启了 syncErrorLevel 且为 Error 及更严重级别时直接写入，否则放入缓冲区
+// 参数：
+//   - entry: 日志条目，由 withCallerFields 创建，不会被其他日志共用
+//   - level: 日志级别
+//   - msg: 已格式化、尚未脱敏的消息
+func logEntry(entry *logrus.Entry, level logrus.Level, msg string) {
+	w := async.Load()
+	if w == nil || (w.cfg.SyncErrorLevel && level <= logrus.ErrorLevel) {
+		entry.Log(level, SanitizeMessage(msg))
+		return
+	}
+	// 记录调用时的时间，避免写入时间晚于实际发生时间
+	entry.Time = time.Now()
+	if !w.enqueue(asyncRecord{entry: entry, level: level, msg: msg}) {
+		entry.Log(level, SanitizeMessage(msg))
+	}
+}
+
+// enqueue 按缓冲区已满处理方式放入日志
+// 返回：
+//   - bool: 写入器已停止时返回 false，由调用方直接写入；放入或丢弃时返回 true
+func (w *asyncWriter) enqueue(r asyncRecord) bool {
+	select {
+	case <-w.quit:
+		return false
+	default:
+	}
+	switch w.cfg.GetDropPolicy() {
+	case config.LogDropNew:
+		select {
+		case w.records <- r:
+		default:
+			w.dropped.Add(1)
+		}
+		return true
+	case config.LogDropOldest:
+		for {
+			select {
+			case w.records <- r:
+				return true
+			default:
+			}
+			select {
+			case <-w.records:
+				w.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case w.records <- r:
+			return true
+		case <-w.quit:
+			return false
+		}
+	}
+}
+
+// run 写入协程，按顺序写入缓冲区中的日志，定期输出丢弃条数
+func (w *asyncWriter) run() {
+	defer close(w.exited)
+	ticker := time.NewTicker(w.cfg.GetReportInterval())
+	defer ticker.Stop()
+
+	var reported uint64
+	for {
+		select {
+		case r := <-w.records:
+			w.write(r)
+		case done := <-w.flushes:
+			w.drain()
+			close(done)
+		case <-ticker.C:
+			reported = w.reportDropped(reported)
+		case <-w.quit:
+			w.drain()
+			w.reportDropped(reported)
+			return
+		}
+	}
+}
+
+// drain 写入缓冲区中当前的所有日志
+func (w *asyncWriter) drain() {
+	for n := len(w.records); n > 0; n-- {
+		select {
+		case r := <-w.records:
+			w.write(r)
+		default:
+			// drop-oldest 的调用方同时在取出日志
+			return
+		}
+	}
+}
+
+// write 脱敏并写入一条日志
+func (w *asyncWriter) write(r asyncRecord) {
+	r.entry.Log(r.level, SanitizeMessage(r.msg))
+}
+
+// reportDropped 上次输出后有新的丢弃时输出丢弃条数
+// 参数：
+//   - reported: 上次输出时的累计丢弃条数
+//
+// 返回：
+//   - uint64: 本次输出后的累计丢弃条数
+func (w *asyncWriter) reportDropped(reported uint64) uint64 {
+	dropped := w.dropped.Load()
+	if dropped > reported {
+		Logger.Warnf("[logger] 异步日志缓冲区已满，丢弃 %d 条日志（累计 %d 条）", dropped-reported, dropped)
+	}
+	return dropped
+}
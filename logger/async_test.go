@@ -0,0 +1,265 @@
+// Package logger 异步日志测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含异步日志写入的单元测试和基准测试，使用记录日志消息的 Hook 代替文件输出。
+//
+// 测试覆盖内容：
+// 1. 缓冲区已满时 drop-new、drop-oldest 按策略丢弃日志，统计并定期输出丢弃条数
+// 2. Flush 和关闭异步写入时写完缓冲区中的所有日志，Flush 等待可被取消
+// 3. 同一协程记录的日志按记录顺序写入
+// 4. 开启 syncErrorLevel 时 Error 日志跳过缓冲区直接写入
+// 5. 同步与异步写入的单次调用耗时对比（基准测试）
+//
+// 运行测试：go test -v ./logger/... -run Async
+// 运行基准测试：go test -bench Logger -run ^$ ./logger/...
+// ==================================================
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// captureHook 记录写入的日志消息，消息等于 blockOn 时阻塞到 release 关闭
+type captureHook struct {
+	mu       sync.Mutex
+	messages []string
+	blockOn  string
+	blocked  chan struct{} // 开始阻塞时关闭
+	release  chan struct{}
+}
+
+// newCaptureHook 创建记录日志消息的 Hook，blockOn 为空时不阻塞
+func newCaptureHook(blockOn string) *captureHook {
+	return &captureHook{blockOn: blockOn, blocked: make(chan struct{}), release: make(chan struct{})}
+}
+
+// Levels 接收所有级别
+func (h *captureHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire 记录日志消息
+func (h *captureHook) Fire(entry *logrus.Entry) error {
+	if h.blockOn != "" && entry.Message == h.blockOn {
+		close(h.blocked)
+		<-h.release
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, entry.Message)
+	return nil
+}
+
+// written 返回以 prefix 开头的日志消息
+func (h *captureHook) written(prefix string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var result []string
+	for _, msg := range h.messages {
+		if strings.HasPrefix(msg, prefix) {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+// useAsync 使用只写入 hook 的日志记录器并按 cfg 开启异步写入，测试结束后关闭异步写入并恢复日志记录器
+func useAsync(t *testing.T, cfg config.AsyncLogConfig, hook *captureHook) {
+	originalLogger, originalPrintCaller, originalOutputCaller := Logger, printCaller, outputCaller
+	Logger = logrus.New()
+	Logger.SetOutput(io.Discard)
+	Logger.SetLevel(logrus.TraceLevel)
+	Logger.AddHook(hook)
+	printCaller, outputCaller = false, false
+	cfg.Enabled = true
+	SetAsync(cfg)
+	t.Cleanup(func() {
+		SetAsync(config.AsyncLogConfig{})
+		Logger, printCaller, outputCaller = originalLogger, originalPrintCaller, originalOutputCaller
+	})
+}
+
+// TestAsync_DropAccounting 测试缓冲区已满时的丢弃统计
+//
+// 【功能点】验证 drop-new 丢弃新日志、drop-oldest 丢弃缓冲区中最早的日志，丢弃条数被统计并定期输出
+// 【测试流程】
+//  1. 缓冲区容量为 2，写入协程阻塞在第一条日志 m0 上
+//  2. 继续记录 m1-m9，断言丢弃 7 条，此时 Flush 等待超时
+//  3. 放行写入协程后 Flush，断言 drop-new 写入 m0-m2，drop-oldest 写入 m0、m8、m9
+//  4. 断言输出了"丢弃 7 条日志"
+func TestAsync_DropAccounting(t *testing.T) {
+	for policy, expected := range map[string][]string{
+		config.LogDropNew:    {"m0", "m1", "m2"},
+		config.LogDropOldest: {"m0", "m8", "m9"},
+	} {
+		t.Run(policy, func(t *testing.T) {
+			hook := newCaptureHook("m0")
+			useAsync(t, config.AsyncLogConfig{BufferSize: 2, DropPolicy: policy, ReportInterval: 20 * time.Millisecond}, hook)
+
+			Info("m0")
+			<-hook.blocked
+			for i := 1; i < 10; i++ {
+				Info("m%d", i)
+			}
+			assert.Equal(t, uint64(7), DroppedCount())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			assert.ErrorIs(t, Flush(ctx), context.DeadlineExceeded)
+
+			close(hook.release)
+			require.NoError(t, Flush(context.Background()))
+			assert.Equal(t, expected, hook.written("m"))
+			assert.Eventually(t, func() bool {
+				reports := hook.written("[logger] 异步日志缓冲区已满")
+				return len(reports) == 1 && strings.Contains(reports[0], "丢弃 7 条日志")
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+// TestAsync_FlushCompleteness 测试 Flush 和关闭时写完所有日志
+//
+// 【功能点】验证阻塞策略下多个协程记录的日志在 Flush 返回时全部写入，关闭异步写入时同样写完缓冲区，且不丢弃日志
+// 【测试流程】
+//  1. 缓冲区容量为 8，4 个协程各记录 500 条日志后 Flush，断言写入 2000 条
+//  2. 再记录 100 条日志后关闭异步写入，断言共写入 2100 条，丢弃条数为 0
+func TestAsync_FlushCompleteness(t *testing.T) {
+	hook := newCaptureHook("")
+	useAsync(t, config.AsyncLogConfig{BufferSize: 8}, hook)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				Info("g%d-%d", g, i)
+			}
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, Flush(context.Background()))
+	assert.Len(t, hook.written("g"), 2000)
+
+	for i := 0; i < 100; i++ {
+		Debug("g-after-%d", i)
+	}
+	assert.Zero(t, DroppedCount())
+	SetAsync(config.AsyncLogConfig{})
+	assert.Len(t, hook.written("g"), 2100)
+}
+
+// TestAsync_Ordering 测试同一协程的日志顺序
+//
+// 【功能点】验证同一协程记录的不同级别日志按记录顺序写入，多个协程并发记录时各自的顺序不变
+// 【测试流程】
+//  1. 3 个协程并发，各自交替使用 Info、Warn、Debug、InfoWithFields 记录 300 条带序号的日志
+//  2. Flush 后按协程分组，断言每组序号严格递增
+func TestAsync_Ordering(t *testing.T) {
+	hook := newCaptureHook("")
+	useAsync(t, config.AsyncLogConfig{BufferSize: 16}, hook)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 3; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 300; i++ {
+				switch i % 4 {
+				case 0:
+					Info("g%d:%d", g, i)
+				case 1:
+					Warn("g%d:%d", g, i)
+				case 2:
+					Debug("g%d:%d", g, i)
+				default:
+					InfoWithFields(map[string]any{"i": i}, "g%d:%d", g, i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, Flush(context.Background()))
+
+	for g := 0; g < 3; g++ {
+		messages := hook.written(fmt.Sprintf("g%d:", g))
+		require.Len(t, messages, 300)
+		for i, msg := range messages {
+			assert.Equal(t, fmt.Sprintf("g%d:%d", g, i), msg)
+		}
+	}
+}
+
+// TestAsync_SyncErrorLevel 测试 Error 日志跳过缓冲区
+//
+// 【功能点】验证开启 syncErrorLevel 后，写入协程阻塞时 Error 日志仍立即写入，且消息照常脱敏
+// 【测试流程】
+//  1. 写入协程阻塞在第一条日志上
+//  2. 记录一条包含 password 的 Error 日志，断言调用返回时已写入且密码被脱敏
+//  3. 放行写入协程后 Flush，断言 Info 日志也被写入
+func TestAsync_SyncErrorLevel(t *testing.T) {
+	hook := newCaptureHook("blocked")
+	useAsync(t, config.AsyncLogConfig{SyncErrorLevel: true}, hook)
+
+	Info("blocked")
+	<-hook.blocked
+	Error("crash password=secret123")
+	assert.Equal(t, []string{"crash password=se****23"}, hook.written("crash"))
+
+	close(hook.release)
+	require.NoError(t, Flush(context.Background()))
+	assert.Equal(t, []string{"blocked"}, hook.written("blocked"))
+}
+
+// benchmarkLogger 使用文件输出的日志记录器记录日志，async 为 nil 时同步写入
+func benchmarkLogger(b *testing.B, async *config.AsyncLogConfig) {
+	originalLogger, originalPrintCaller, originalOutputCaller := Logger, printCaller, outputCaller
+	Logger = InitLogger(config.LoggersConfig{Outputs: []config.LogOutput{
+		{Type: config.LogOutputFile, Path: filepath.Join(b.TempDir(), "app.log")},
+	}})
+	if async != nil {
+		async.Enabled = true
+		SetAsync(*async)
+	}
+	b.Cleanup(func() {
+		SetAsync(config.AsyncLogConfig{})
+		closeOutputs(Logger)
+		Logger, printCaller, outputCaller = originalLogger, originalPrintCaller, originalOutputCaller
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Info("[server] Status: %d, Times(ms): %d, Ip: %s, Method: %s, Uri: %s", 200, 3, "127.0.0.1", "GET", "/api/users")
+	}
+	b.StopTimer()
+}
+
+// BenchmarkLogger_Sync 基准测试同步写入的单次调用耗时
+func BenchmarkLogger_Sync(b *testing.B) {
+	benchmarkLogger(b, nil)
+}
+
+// BenchmarkLogger_Async 基准测试异步写入的单次调用耗时
+// block 策略下持续写入的耗时受写入协程的速度限制，drop-new 为缓冲区已满时不等待的耗时
+func BenchmarkLogger_Async(b *testing.B) {
+	b.Run("block", func(b *testing.B) {
+		benchmarkLogger(b, &config.AsyncLogConfig{})
+	})
+	b.Run("drop-new", func(b *testing.B) {
+		benchmarkLogger(b, &config.AsyncLogConfig{DropPolicy: config.LogDropNew})
+	})
+}
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Entry 绑定了结构化字段的子日志记录器
+// 通过 With / WithField 创建，绑定的字段会输出到该记录器写入的每一条日志中（文本和 JSON 格式均输出）。
+// Entry 创建后不会被修改，With / WithField 返回新的 Entry，可在多个协程中并发使用
+type Entry struct {
+	fields logrus.Fields // 已脱敏的绑定字段
+}
+
+// root 根日志记录器，不绑定任何字段，包级日志函数均通过它写入
+var root = &Entry{}
+
+// entryContextKey Entry 在 context.Context 中的存储键类型
+type entryContextKey struct{}
+
+// With 创建绑定指定字段的子日志记录器（字段自动脱敏）
+//
+// 使用示例：
+//
+//	log := logger.With(map[string]any{"orderId": orderID}).WithField("userId", userID)
+//	log.Info("订单创建成功, 金额: %.2f", amount)
+//
+// 参数：
+//   - fields: 绑定的字段
+//
+// 返回：
+//   - *Entry: 子日志记录器
+func With(fields map[string]any) *Entry {
+	return root.With(fields)
+}
+
+// With 在当前记录器的字段基础上绑定更多字段，同名字段以新值为准，当前记录器不受影响
+func (e *Entry) With(fields map[string]any) *Entry {
+	merged := make(logrus.Fields, len(e.fields)+len(fields))
+	for k, v := range e.fields {
+		merged[k] = v
+	}
+	for k, v := range SanitizeFields(fields) {
+		merged[k] = v
+	}
+	return &Entry{fields: merged}
+}
+
+// WithField 在当前记录器的字段基础上绑定一个字段，当前记录器不受影响
+func (e *Entry) WithField(key string, value any) *Entry {
+	return e.With(map[string]any{key: value})
+}
+
+// Fields 获取绑定的字段（副本）
+func (e *Entry) Fields() map[string]any {
+	fields := make(map[string]any, len(e.fields))
+	for k, v := range e.fields {
+		fields[k] = v
+	}
+	return fields
+}
+
+// Info 记录Info级别的日志，附带绑定的字段
+func (e *Entry) Info(msg string, arg ...any) {
+	e.log(logrus.InfoLevel, nil, msg, arg...)
+}
+
+// Error 记录Error级别的日志，附带绑定的字段
+func (e *Entry) Error(msg string, arg ...any) {
+	e.log(logrus.ErrorLevel, nil, msg, arg...)
+}
+
+// Warn 记录Warn级别的日志，附带绑定的字段
+func (e *Entry) Warn(msg string, arg ...any) {
+	e.log(logrus.WarnLevel, nil, msg, arg...)
+}
+
+// Debug 记录Debug级别的日志，附带绑定的字段
+func (e *Entry) Debug(msg string, arg ...any) {
+	e.log(logrus.DebugLevel, nil, msg, arg...)
+}
+
+// Trace 记录Trace级别的日志，附带绑定的字段
+func (e *Entry) Trace(msg string, arg ...any) {
+	e.log(logrus.TraceLevel, nil, msg, arg...)
+}
+
+// log 写入一条附带绑定字段和本条日志字段的日志
+// 只能由导出的日志方法 / 函数直接调用，以保证调用者信息的栈层数正确
+func (e *Entry) log(level logrus.Level, fields map[string]any, msg string, arg ...any) {
+	entry := withCallerFields(4)
+	if len(e.fields) > 0 {
+		entry = entry.WithFields(e.fields)
+	}
+	if len(fields) > 0 {
+		entry = entry.WithFields(SanitizeFields(fields))
+	}
+	logEntry(entry, level, formatLog(msg, arg...))
+}
+
+// NewContext 返回携带子日志记录器的 context，下游通过 FromContext 取出
+//
+// 使用示例：
+//
+//	ctx = logger.NewContext(ctx, logger.With(map[string]any{"jobId": jobID}))
+//	logger.FromContext(ctx).Info("任务开始")
+//
+// 参数：
+//   - ctx: 父 context
+//   - entry: 子日志记录器
+//
+// 返回：
+//   - context.Context: 携带子日志记录器的 context
+func NewContext(ctx context.Context, entry *Entry) context.Context {
+	return context.WithValue(ctx, entryContextKey{}, entry)
+}
+
+// FromContext 获取 context 中的子日志记录器，未设置时返回不绑定字段的根记录器
+// HTTP 请求中由 traceLogHandler 中间件设置，可通过 c.Request.Context() 获取；
+// MQ 消费函数收到的 context 中绑定了队列信息
+func FromContext(ctx context.Context) *Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(entryContextKey{}).(*Entry); ok && entry != nil {
+			return entry
+		}
+	}
+	return root
+}
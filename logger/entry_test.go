@@ -0,0 +1,146 @@
+// Package logger 子日志记录器测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含绑定字段的子日志记录器（Entry）的单元测试，日志写入临时目录中的文件输出。
+//
+// 测试覆盖内容：
+// 1. 绑定的字段在文本和 JSON 输出中均出现，WithField 可链式追加且不影响父记录器
+// 2. 绑定的敏感字段被脱敏
+// 3. 子日志记录器通过 context 传递，未设置时返回根记录器
+// 4. 并发使用的多个子日志记录器之间字段互不串扰
+//
+// 运行测试：go test -v ./logger/... -run Entry
+// ==================================================
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// useTextAndJSONOutputs 配置文本和 JSON 两个文件输出，返回两个文件的路径
+func useTextAndJSONOutputs(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	textPath := filepath.Join(dir, "app.log")
+	jsonPath := filepath.Join(dir, "app.json.log")
+	useLogger(t, config.LoggersConfig{Outputs: []config.LogOutput{
+		{Type: config.LogOutputFile, Format: config.LogFormatText, Path: textPath},
+		{Type: config.LogOutputFile, Format: config.LogFormatJSON, Path: jsonPath},
+	}})
+	return textPath, jsonPath
+}
+
+// parseJSONLines 解析 JSON 输出的每一行
+func parseJSONLines(t *testing.T, path string) []map[string]any {
+	lines := readLines(t, path)
+	result := make([]map[string]any, 0, len(lines))
+	for _, line := range lines {
+		var m map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &m))
+		result = append(result, m)
+	}
+	return result
+}
+
+// TestEntry_BoundFields 测试绑定字段的输出
+//
+// 【功能点】验证 With / WithField 绑定的字段出现在文本和 JSON 输出中，敏感字段被脱敏，父记录器不受子记录器影响
+// 【测试流程】
+//  1. 创建绑定 orderId、token 的记录器，再通过 WithField 派生绑定 step 的子记录器
+//  2. 父、子记录器各写一条日志
+//  3. 断言文本输出包含 orderId=、step=，JSON 输出中子记录器日志包含全部字段、父记录器日志不含 step，caller 指向本文件
+func TestEntry_BoundFields(t *testing.T) {
+	textPath, jsonPath := useTextAndJSONOutputs(t)
+
+	parent := With(map[string]any{"orderId": "ORD-001", "token": "abc123xyz789"})
+	child := parent.WithField("step", "pay")
+	parent.Info("订单创建")
+	child.Warn("支付处理中: %d%%", 50)
+
+	textLines := readLines(t, textPath)
+	require.Len(t, textLines, 2)
+	assert.Contains(t, textLines[0], "orderId=ORD-001")
+	assert.NotContains(t, textLines[0], "step=")
+	assert.Contains(t, textLines[1], "orderId=ORD-001")
+	assert.Contains(t, textLines[1], "step=pay")
+	assert.NotContains(t, textLines[1], "abc123xyz789")
+
+	jsonLines := parseJSONLines(t, jsonPath)
+	require.Len(t, jsonLines, 2)
+	assert.Equal(t, "订单创建", jsonLines[0]["message"])
+	assert.Equal(t, "ORD-001", jsonLines[0]["orderId"])
+	assert.NotContains(t, jsonLines[0], "step")
+	assert.Equal(t, "支付处理中: 50%", jsonLines[1]["message"])
+	assert.Equal(t, "ORD-001", jsonLines[1]["orderId"])
+	assert.Equal(t, "pay", jsonLines[1]["step"])
+	assert.Equal(t, "ab****89", jsonLines[1]["token"])
+	assert.Contains(t, jsonLines[1]["caller"], "entry_test.go:")
+}
+
+// TestEntry_Context 测试通过 context 传递子日志记录器
+//
+// 【功能点】验证 NewContext 存入的记录器可由 FromContext 取出，未设置时返回不绑定字段的根记录器
+// 【测试流程】
+//  1. 断言 FromContext(context.Background()) 和 FromContext(nil) 均为根记录器且没有绑定字段
+//  2. 存入绑定 jobId 的记录器并派生子 context，断言取出的记录器绑定 jobId，写入的 JSON 日志包含 jobId
+func TestEntry_Context(t *testing.T) {
+	_, jsonPath := useTextAndJSONOutputs(t)
+
+	var nilCtx context.Context
+	assert.Same(t, root, FromContext(context.Background()))
+	assert.Same(t, root, FromContext(nilCtx))
+	assert.Empty(t, FromContext(context.Background()).Fields())
+
+	ctx := NewContext(context.Background(), With(map[string]any{"jobId": 7}))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	assert.Equal(t, map[string]any{"jobId": 7}, FromContext(ctx).Fields())
+
+	FromContext(ctx).Error("任务失败")
+	jsonLines := parseJSONLines(t, jsonPath)
+	require.Len(t, jsonLines, 1)
+	assert.Equal(t, "error", jsonLines[0]["level"])
+	assert.Equal(t, float64(7), jsonLines[0]["jobId"])
+}
+
+// TestEntry_ConcurrentNoLeak 测试并发使用时字段互不串扰
+//
+// 【功能点】验证从同一父记录器并发派生的子记录器只输出各自绑定的字段
+// 【测试流程】
+//  1. 20 个协程各自通过 WithField 派生绑定 worker=i 的子记录器，每个写 10 条带 seq 参数的日志
+//  2. 解析 JSON 输出，断言共 200 行，每行的 worker 字段与消息中的协程编号一致，且都包含父记录器的 service 字段
+func TestEntry_ConcurrentNoLeak(t *testing.T) {
+	_, jsonPath := useTextAndJSONOutputs(t)
+
+	parent := With(map[string]any{"service": "order"})
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry := parent.WithField("worker", i)
+			for seq := range 10 {
+				entry.Debug("worker-%d seq-%d", i, seq)
+			}
+		}()
+	}
+	wg.Wait()
+
+	jsonLines := parseJSONLines(t, jsonPath)
+	require.Len(t, jsonLines, 200)
+	for _, line := range jsonLines {
+		worker, ok := line["worker"].(float64)
+		require.True(t, ok)
+		assert.Contains(t, line["message"], fmt.Sprintf("worker-%d ", int(worker)))
+		assert.Equal(t, "order", line["service"])
+	}
+	assert.Equal(t, map[string]any{"service": "order"}, parent.Fields())
+}
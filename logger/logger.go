@@ -4,6 +4,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"path"
 	"regexp"
 	"runtime"
@@ -67,6 +68,7 @@ func init() {
 // 2. 设置日志级别为Trace（最高级别）
 // 3. 为每个日志级别配置对应的输出
 // 4. 支持自定义日志配置和默认配置回退
+// 5. 配置了 outputs 时改为按输出写入（控制台 / 按大小轮转的文件，文本 / JSON 格式），忽略 loggers 配置
 // 参数：
 //   - loggersConfig: 日志配置信息
 //
@@ -79,6 +81,15 @@ func InitLogger(loggersConfig config.LoggersConfig) *logrus.Logger {
 	// 设置日志级别为 Trace（最高级别，记录所有日志）
 	Logger.SetLevel(logrus.TraceLevel)
 
+	// 配置了输出列表时，日志只写入各输出，由输出自行控制级别和格式
+	if len(loggersConfig.Outputs) > 0 {
+		addOutputs(Logger, loggersConfig.Outputs)
+		Logger.SetOutput(io.Discard)
+		printCaller = loggersConfig.PrintCaller
+		outputCaller = hasJSONOutput(loggersConfig.Outputs)
+		return Logger
+	}
+
 	// 为每个日志级别配置对应的输出
 	for _, logLevel := range logrus.AllLevels {
 		// 配置 lfshook
@@ -115,6 +126,7 @@ func InitLogger(loggersConfig config.LoggersConfig) *logrus.Logger {
 
 	// 保存是否打印调用者信息的配置（由包装函数使用）
 	printCaller = loggersConfig.PrintCaller
+	outputCaller = false
 	return Logger
 }
 
@@ -215,18 +227,18 @@ func Add(requestId, info string, err error) {
 	entry := withCallerFields(2)
 	if err != nil {
 		// 如果有错误，记录 Error 级别的日志
-		entry.WithFields(logrus.Fields{
+		logEntry(entry.WithFields(logrus.Fields{
 			"request_id": requestId,
 			"info":       info,
 			"error":      err.Error(),
-		}).Error()
+		}), logrus.ErrorLevel, "")
 	} else {
 		// 如果没有错误，记录 Info 级别的日志
-		entry.WithFields(logrus.Fields{
+		logEntry(entry.WithFields(logrus.Fields{
 			"request_id": requestId,
 			"info":       info,
 			"error":      "",
-		}).Info()
+		}), logrus.InfoLevel, "")
 	}
 }
 
@@ -268,6 +280,10 @@ func getCaller(skip int) callerInfo {
 //   - *logrus.Entry: 带调用者信息的日志条目
 func withCallerFields(skip int) *logrus.Entry {
 	if !printCaller {
+		// 未开启 printCaller 时，仅为 JSON 输出记录调用位置
+		if outputCaller {
+			return Logger.WithField(callerFieldKey, getCaller(skip).File)
+		}
 		return logrus.NewEntry(Logger)
 	}
 	caller := getCaller(skip)
@@ -277,39 +293,32 @@ func withCallerFields(skip int) *logrus.Entry {
 	})
 }
 
-// sanitizeLog 对日志消息进行脱敏处理
-func sanitizeLog(msg string, arg ...any) string {
-	var result string
+// formatLog 格式化日志消息，脱敏在写入时由 logEntry 完成
+func formatLog(msg string, arg ...any) string {
 	if len(arg) > 0 {
-		result = fmt.Sprintf(msg, arg...)
-	} else {
-		result = msg
+		return fmt.Sprintf(msg, arg...)
 	}
-	return SanitizeMessage(result)
+	return msg
 }
 
 // Info 记录Info级别的日志，支持格式化字符串（自动脱敏）
 func Info(msg string, arg ...any) {
-	entry := withCallerFields(3)
-	entry.Info(sanitizeLog(msg, arg...))
+	root.log(logrus.InfoLevel, nil, msg, arg...)
 }
 
 // Error 记录Error级别的日志，支持格式化字符串（自动脱敏）
 func Error(msg string, arg ...any) {
-	entry := withCallerFields(3)
-	entry.Error(sanitizeLog(msg, arg...))
+	root.log(logrus.ErrorLevel, nil, msg, arg...)
 }
 
 // Warn 记录Warn级别的日志，支持格式化字符串（自动脱敏）
 func Warn(msg string, arg ...any) {
-	entry := withCallerFields(3)
-	entry.Warn(sanitizeLog(msg, arg...))
+	root.log(logrus.WarnLevel, nil, msg, arg...)
 }
 
 // Debug 记录Debug级别的日志，支持格式化字符串（自动脱敏）
 func Debug(msg string, arg ...any) {
-	entry := withCallerFields(3)
-	entry.Debug(sanitizeLog(msg, arg...))
+	root.log(logrus.DebugLevel, nil, msg, arg...)
 }
 
 // --- 结构化日志和脱敏功能 ---
@@ -399,36 +408,30 @@ func SanitizeFields(fields map[string]any) logrus.Fields {
 
 // InfoWithFields 带结构化字段的Info日志（字段和消息自动脱敏）
 func InfoWithFields(fields map[string]any, msg string, arg ...any) {
-	entry := withCallerFields(3).WithFields(SanitizeFields(fields))
-	entry.Info(sanitizeLog(msg, arg...))
+	root.log(logrus.InfoLevel, fields, msg, arg...)
 }
 
 // ErrorWithFields 带结构化字段的Error日志（字段和消息自动脱敏）
 func ErrorWithFields(fields map[string]any, msg string, arg ...any) {
-	entry := withCallerFields(3).WithFields(SanitizeFields(fields))
-	entry.Error(sanitizeLog(msg, arg...))
+	root.log(logrus.ErrorLevel, fields, msg, arg...)
 }
 
 // WarnWithFields 带结构化字段的Warn日志（字段和消息自动脱敏）
 func WarnWithFields(fields map[string]any, msg string, arg ...any) {
-	entry := withCallerFields(3).WithFields(SanitizeFields(fields))
-	entry.Warn(sanitizeLog(msg, arg...))
+	root.log(logrus.WarnLevel, fields, msg, arg...)
 }
 
 // DebugWithFields 带结构化字段的Debug日志（字段和消息自动脱敏）
 func DebugWithFields(fields map[string]any, msg string, arg ...any) {
-	entry := withCallerFields(3).WithFields(SanitizeFields(fields))
-	entry.Debug(sanitizeLog(msg, arg...))
+	root.log(logrus.DebugLevel, fields, msg, arg...)
 }
 
 // Trace 记录Trace级别的日志（自动脱敏）
 func Trace(msg string, arg ...any) {
-	entry := withCallerFields(3)
-	entry.Trace(sanitizeLog(msg, arg...))
+	root.log(logrus.TraceLevel, nil, msg, arg...)
 }
 
 // TraceWithFields 带结构化字段的Trace日志（字段和消息自动脱敏）
 func TraceWithFields(fields map[string]any, msg string, arg ...any) {
-	entry := withCallerFields(3).WithFields(SanitizeFields(fields))
-	entry.Trace(sanitizeLog(msg, arg...))
+	root.log(logrus.TraceLevel, fields, msg, arg...)
 }
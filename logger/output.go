@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// callerFieldKey JSON 输出中的调用者字段，未开启 printCaller 时也会记录
+const callerFieldKey = "caller"
+
+// traceIDFieldKey 追踪 ID 字段，值为空时不输出
+const traceIDFieldKey = "traceId"
+
+// outputCaller 是否存在 JSON 输出，存在时即使未开启 printCaller 也记录调用者信息
+var outputCaller bool
+
+// outputHook 单个日志输出
+// 将不低于指定级别的日志按输出格式写入控制台或文件
+type outputHook struct {
+	mu        sync.Mutex
+	writer    io.Writer
+	formatter logrus.Formatter
+	levels    []logrus.Level
+}
+
+// Levels 返回该输出接收的日志级别
+func (h *outputHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Fire 格式化并写入日志
+func (h *outputHook) Fire(entry *logrus.Entry) error {
+	line, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.writer.Write(line)
+	return err
+}
+
+// newOutputHook 根据输出配置创建日志输出
+// 参数：
+//   - output: 输出配置
+//
+// 返回：
+//   - *outputHook: 日志输出
+//   - error: 输出类型、格式或级别无法识别时返回错误
+func newOutputHook(output config.LogOutput) (*outputHook, error) {
+	minLevel, err := logrus.ParseLevel(output.GetLevel())
+	if err != nil {
+		return nil, fmt.Errorf("日志级别无法识别: %s", output.GetLevel())
+	}
+
+	var formatter logrus.Formatter
+	switch output.GetFormat() {
+	case config.LogFormatText:
+		formatter = &textFormatter{TextFormatter: logrus.TextFormatter{
+			FullTimestamp:          true,
+			TimestampFormat:        "2006-01-02 15:04:05",
+			DisableLevelTruncation: true,
+		}}
+	case config.LogFormatJSON:
+		formatter = &jsonFormatter{JSONFormatter: logrus.JSONFormatter{
+			TimestampFormat: "2006-01-02T15:04:05.000Z07:00",
+			FieldMap: logrus.FieldMap{
+				logrus.FieldKeyTime: "timestamp",
+				logrus.FieldKeyMsg:  "message",
+			},
+		}}
+	default:
+		return nil, fmt.Errorf("日志输出格式无法识别: %s", output.GetFormat())
+	}
+
+	var writer io.Writer
+	switch output.GetType() {
+	case config.LogOutputConsole:
+		writer = os.Stdout
+	case config.LogOutputFile:
+		writer = newRotateWriter(output.GetPath(), output.GetMaxSizeMB(), output.MaxBackups, output.MaxAgeDays, output.Compress)
+	default:
+		return nil, fmt.Errorf("日志输出类型无法识别: %s", output.GetType())
+	}
+
+	// logrus 级别数值越小越严重，接收不高于 minLevel 的所有级别
+	var levels []logrus.Level
+	for _, level := range logrus.AllLevels {
+		if level <= minLevel {
+			levels = append(levels, level)
+		}
+	}
+	return &outputHook{writer: writer, formatter: formatter, levels: levels}, nil
+}
+
+// textFormatter 文本格式，去掉仅供 JSON 输出使用的调用者字段
+type textFormatter struct {
+	logrus.TextFormatter
+}
+
+// Format 格式化日志条目
+func (f *textFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if _, ok := entry.Data[callerFieldKey]; ok {
+		entry = withData(entry, func(data logrus.Fields) {
+			delete(data, callerFieldKey)
+		})
+	}
+	return f.TextFormatter.Format(entry)
+}
+
+// jsonFormatter JSON 格式
+// 输出 timestamp、level、message、caller 以及结构化字段，traceId 为空时不输出
+type jsonFormatter struct {
+	logrus.JSONFormatter
+}
+
+// Format 格式化日志条目
+func (f *jsonFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	entry = withData(entry, func(data logrus.Fields) {
+		// 开启 printCaller 时调用者信息记录在 file 字段中
+		if _, ok := data[callerFieldKey]; !ok {
+			if file, ok := data["file"]; ok {
+				data[callerFieldKey] = file
+			}
+		}
+		if traceID, ok := data[traceIDFieldKey]; ok && traceID == "" {
+			delete(data, traceIDFieldKey)
+		}
+	})
+	return f.JSONFormatter.Format(entry)
+}
+
+// withData 复制日志条目并修改字段
+// 同一条目会被多个输出格式化，不能直接修改原条目的字段
+func withData(entry *logrus.Entry, modify func(data logrus.Fields)) *logrus.Entry {
+	data := make(logrus.Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	modify(data)
+	copied := *entry
+	copied.Data = data
+	return &copied
+}
+
+// addOutputs 为日志记录器添加配置的输出，无法创建的输出记录错误后跳过
+// 参数：
+//   - logger: 日志记录器
+//   - outputs: 输出配置列表
+func addOutputs(logger *logrus.Logger, outputs []config.LogOutput) {
+	for i, output := range outputs {
+		hook, err := newOutputHook(output)
+		if err != nil {
+			logger.Errorf("[logger] 初始化日志输出失败 [outputs[%d]]: %v", i, err)
+			continue
+		}
+		logger.AddHook(hook)
+	}
+}
+
+// hasJSONOutput 判断是否配置了 JSON 格式的输出
+func hasJSONOutput(outputs []config.LogOutput) bool {
+	for _, output := range outputs {
+		if output.GetFormat() == config.LogFormatJSON {
+			return true
+		}
+	}
+	return false
+}
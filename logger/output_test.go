@@ -0,0 +1,219 @@
+// Package logger 日志输出测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含日志多输出与按大小轮转的单元测试，日志文件写入临时目录。
+//
+// 测试覆盖内容：
+// 1. 文件输出超过大小限制后轮转，生成历史文件
+// 2. 历史文件按数量清理，并按需 gzip 压缩
+// 3. JSON 输出可解析，包含 timestamp、level、caller、traceId、message 及结构化字段
+// 4. 各输出按各自的最低级别过滤日志
+// 5. 输出类型、格式、级别无法识别时跳过该输出
+//
+// 运行测试：go test -v ./logger/...
+// ==================================================
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// useLogger 临时替换全局日志记录器，测试结束后恢复
+func useLogger(t *testing.T, cfg config.LoggersConfig) {
+	originalLogger, originalPrintCaller, originalOutputCaller := Logger, printCaller, outputCaller
+	Logger = InitLogger(cfg)
+	t.Cleanup(func() {
+		closeOutputs(Logger)
+		Logger, printCaller, outputCaller = originalLogger, originalPrintCaller, originalOutputCaller
+	})
+}
+
+// closeOutputs 关闭日志记录器中的文件输出
+func closeOutputs(l *logrus.Logger) {
+	for _, hooks := range l.Hooks {
+		for _, hook := range hooks {
+			if h, ok := hook.(*outputHook); ok {
+				if w, ok := h.writer.(*rotateWriter); ok {
+					_ = w.Close()
+				}
+			}
+		}
+	}
+}
+
+// readLines 读取文件的所有行
+func readLines(t *testing.T, path string) []string {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+// TestOutput_FileRotation 测试文件输出按大小轮转
+//
+// 【功能点】验证写入超过 maxSizeMB 后当前文件被重命名为历史文件，并重新创建日志文件
+// 【测试流程】
+//  1. 配置 maxSizeMB=1 的文件输出
+//  2. 写入约 1.5MB 日志
+//  3. 断言目录下出现 app-<时间>.log 历史文件，且当前文件小于 1MB
+func TestOutput_FileRotation(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	useLogger(t, config.LoggersConfig{Outputs: []config.LogOutput{
+		{Type: config.LogOutputFile, Path: logPath, MaxSizeMB: 1},
+	}})
+
+	line := strings.Repeat("x", 1024)
+	for i := 0; i < 1500; i++ {
+		Info("第 %d 条日志 %s", i, line)
+	}
+
+	backups, err := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, backups, "超过大小限制后应生成历史文件")
+
+	info, err := os.Stat(logPath)
+	require.NoError(t, err)
+	assert.Less(t, info.Size(), int64(1024*1024))
+	for _, backup := range backups {
+		info, err := os.Stat(backup)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(1024*1024))
+	}
+}
+
+// TestRotateWriter_BackupsAndCompress 测试历史文件清理与压缩
+//
+// 【功能点】验证历史文件数量超过 maxBackups 时删除最旧的文件，compress 开启时历史文件被压缩
+// 【测试流程】
+//  1. 创建 maxBackups=2、compress=true 的写入器，将大小限制调小为 100 字节
+//  2. 写入 6 次，每次 80 字节，触发 5 次轮转
+//  3. 断言只保留 2 个 .gz 历史文件，同目录下名称相近的其他文件不受影响
+func TestRotateWriter_BackupsAndCompress(t *testing.T) {
+	dir := t.TempDir()
+	other := filepath.Join(dir, "app-error.log")
+	require.NoError(t, os.WriteFile(other, []byte("other"), 0644))
+
+	w := newRotateWriter(filepath.Join(dir, "app.log"), 1, 2, 0, true)
+	w.maxSize = 100
+	defer w.Close()
+
+	for i := 0; i < 6; i++ {
+		_, err := w.Write([]byte(strings.Repeat("a", 80)))
+		require.NoError(t, err)
+	}
+
+	compressed, err := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+	require.NoError(t, err)
+	assert.Len(t, compressed, 2)
+	assert.FileExists(t, other)
+}
+
+// TestOutput_JSONFormat 测试 JSON 输出
+//
+// 【功能点】验证 JSON 输出每行可解析，包含 timestamp、level、caller、message、traceId 及结构化字段
+// 【测试流程】
+//  1. 配置 JSON 文件输出，未开启 printCaller
+//  2. 分别写入带 traceId 字段和不带字段的日志
+//  3. 解析每行 JSON，断言字段存在，caller 指向本测试文件，敏感字段被脱敏
+func TestOutput_JSONFormat(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.json.log")
+	useLogger(t, config.LoggersConfig{Outputs: []config.LogOutput{
+		{Type: config.LogOutputFile, Format: config.LogFormatJSON, Path: logPath},
+	}})
+
+	InfoWithFields(map[string]any{"traceId": "trace-123", "userId": 42, "password": "secret123"}, "用户登录")
+	Warn("连接池使用率过高: %d%%", 85)
+
+	lines := readLines(t, logPath)
+	require.Len(t, lines, 2)
+
+	var first map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, "info", first["level"])
+	assert.Equal(t, "用户登录", first["message"])
+	assert.Equal(t, "trace-123", first["traceId"])
+	assert.Equal(t, float64(42), first["userId"])
+	assert.Equal(t, "se****23", first["password"])
+	assert.NotEmpty(t, first["timestamp"])
+	assert.Contains(t, first["caller"], "output_test.go:")
+
+	var second map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, "warning", second["level"])
+	assert.Equal(t, "连接池使用率过高: 85%", second["message"])
+	assert.NotContains(t, second, "traceId")
+	assert.Contains(t, second, "caller")
+}
+
+// TestOutput_PerOutputLevel 测试各输出的级别过滤
+//
+// 【功能点】验证多个输出同时生效，且每个输出只写入不低于自身级别的日志
+// 【测试流程】
+//  1. 配置 debug 级别文本输出和 error 级别 JSON 输出
+//  2. 写入 debug、info、error 日志
+//  3. 断言文本输出 3 行且不含 caller 字段，JSON 输出只有 error 1 行
+func TestOutput_PerOutputLevel(t *testing.T) {
+	dir := t.TempDir()
+	textPath := filepath.Join(dir, "all.log")
+	jsonPath := filepath.Join(dir, "error.log")
+	useLogger(t, config.LoggersConfig{Outputs: []config.LogOutput{
+		{Type: config.LogOutputFile, Format: config.LogFormatText, Level: "debug", Path: textPath},
+		{Type: config.LogOutputFile, Format: config.LogFormatJSON, Level: "error", Path: jsonPath},
+	}})
+
+	Debug("debug 日志")
+	Info("info 日志")
+	Error("error 日志")
+	Trace("trace 日志")
+
+	textLines := readLines(t, textPath)
+	require.Len(t, textLines, 3)
+	for _, line := range textLines {
+		assert.NotContains(t, line, "caller=")
+	}
+
+	jsonLines := readLines(t, jsonPath)
+	require.Len(t, jsonLines, 1)
+	assert.Contains(t, jsonLines[0], `"message":"error 日志"`)
+}
+
+// TestOutput_InvalidOutput 测试无法识别的输出配置
+//
+// 【功能点】验证类型、格式或级别无法识别的输出被跳过，其余输出正常工作
+// 【测试流程】分别构造非法类型、格式、级别的输出，断言 newOutputHook 返回错误；与合法输出一起初始化后合法输出正常写入
+func TestOutput_InvalidOutput(t *testing.T) {
+	for _, output := range []config.LogOutput{
+		{Type: "kafka"},
+		{Format: "xml"},
+		{Level: "verbose"},
+	} {
+		_, err := newOutputHook(output)
+		assert.Error(t, err)
+	}
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	useLogger(t, config.LoggersConfig{Outputs: []config.LogOutput{
+		{Type: "kafka"},
+		{Type: config.LogOutputFile, Path: logPath},
+	}})
+	Info("正常输出")
+	assert.Len(t, readLines(t, logPath), 1)
+}
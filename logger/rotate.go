@@ -0,0 +1,244 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat 历史文件名中的时间格式
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// compressSuffix 压缩后的历史文件后缀
+const compressSuffix = ".gz"
+
+// rotateWriter 按大小轮转的日志文件写入器
+// 当前文件写入后超过 maxSize 时，将其重命名为带时间戳的历史文件（如 app-2024-01-15T10-30-00.000.log），
+// 并重新创建日志文件；随后按 maxBackups、maxAge 清理历史文件，按需压缩
+type rotateWriter struct {
+	mu         sync.Mutex
+	filename   string        // 日志文件路径
+	maxSize    int64         // 单个文件最大字节数
+	maxBackups int           // 保留的历史文件数量，0 表示不限制
+	maxAge     time.Duration // 历史文件最大保存时间，0 表示不限制
+	compress   bool          // 是否压缩历史文件
+
+	file *os.File
+	size int64
+}
+
+// newRotateWriter 创建按大小轮转的日志文件写入器
+// 参数：
+//   - filename: 日志文件路径，目录不存在时自动创建
+//   - maxSizeMB: 单个文件最大大小（MB）
+//   - maxBackups: 保留的历史文件数量，0 表示不限制
+//   - maxAgeDays: 历史文件最大保存天数，0 表示不限制
+//   - compress: 是否使用 gzip 压缩历史文件
+//
+// 返回：
+//   - *rotateWriter: 写入器，首次写入时打开文件
+func newRotateWriter(filename string, maxSizeMB, maxBackups, maxAgeDays int, compress bool) *rotateWriter {
+	return &rotateWriter{
+		filename:   filename,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+		maxAge:     time.Duration(maxAgeDays) * 24 * time.Hour,
+		compress:   compress,
+	}
+}
+
+// Write 写入日志，写入后超过大小限制时先轮转
+// 单条日志超过大小限制时仍完整写入当前文件，不会被截断
+func (w *rotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.openExisting(); err != nil {
+			return 0, err
+		}
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close 关闭当前日志文件
+func (w *rotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// openExisting 以追加方式打开日志文件，文件已存在时沿用其大小
+func (w *rotateWriter) openExisting() error {
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0755); err != nil {
+		return fmt.Errorf("创建日志目录失败: %w", err)
+	}
+	file, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("读取日志文件信息失败: %w", err)
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// rotate 将当前文件重命名为历史文件并重新创建日志文件
+func (w *rotateWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("关闭日志文件失败: %w", err)
+	}
+	w.file = nil
+	if err := os.Rename(w.filename, w.backupName(time.Now())); err != nil {
+		return fmt.Errorf("重命名日志文件失败: %w", err)
+	}
+	if err := w.openExisting(); err != nil {
+		return err
+	}
+	w.cleanup()
+	return nil
+}
+
+// backupName 生成历史文件名，同一毫秒内多次轮转时追加序号避免覆盖
+func (w *rotateWriter) backupName(t time.Time) string {
+	prefix, ext := w.backupPrefixAndExt()
+	name := prefix + t.Format(backupTimeFormat)
+	backup := name + ext
+	for i := 1; fileExists(backup) || fileExists(backup+compressSuffix); i++ {
+		backup = fmt.Sprintf("%s.%d%s", name, i, ext)
+	}
+	return backup
+}
+
+// backupPrefixAndExt 返回历史文件名前缀（含路径）和扩展名，如 ./log/app- 和 .log
+func (w *rotateWriter) backupPrefixAndExt() (string, string) {
+	ext := filepath.Ext(w.filename)
+	return strings.TrimSuffix(w.filename, ext) + "-", ext
+}
+
+// backupFile 历史文件信息
+type backupFile struct {
+	path    string
+	modTime time.Time
+}
+
+// listBackups 列出历史文件，按修改时间从新到旧排序
+func (w *rotateWriter) listBackups() []backupFile {
+	prefix, ext := w.backupPrefixAndExt()
+	entries, err := os.ReadDir(filepath.Dir(w.filename))
+	if err != nil {
+		return nil
+	}
+	base := filepath.Base(prefix)
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, base) {
+			continue
+		}
+		if !strings.HasSuffix(name, ext) && !strings.HasSuffix(name, ext+compressSuffix) {
+			continue
+		}
+		// 前缀后必须是轮转时间，避免误删同目录下名称相近的其他日志文件（如 app-error.log）
+		stamp := strings.TrimSuffix(strings.TrimSuffix(name, compressSuffix), ext)[len(base):]
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, stamp[:len(backupTimeFormat)]); err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(filepath.Dir(w.filename), name), modTime: info.ModTime()})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].modTime.Equal(backups[j].modTime) {
+			return backups[i].path > backups[j].path
+		}
+		return backups[i].modTime.After(backups[j].modTime)
+	})
+	return backups
+}
+
+// cleanup 按数量和保存时间删除历史文件，并压缩剩余的未压缩历史文件
+// 清理失败不影响日志写入，只输出到标准错误
+func (w *rotateWriter) cleanup() {
+	backups := w.listBackups()
+	cutoff := time.Now().Add(-w.maxAge)
+	for i, backup := range backups {
+		expired := w.maxAge > 0 && backup.modTime.Before(cutoff)
+		if (w.maxBackups > 0 && i >= w.maxBackups) || expired {
+			if err := os.Remove(backup.path); err != nil {
+				fmt.Fprintf(os.Stderr, "[logger] 删除历史日志文件失败: %v\n", err)
+			}
+			continue
+		}
+		if w.compress && !strings.HasSuffix(backup.path, compressSuffix) {
+			if err := compressFile(backup.path); err != nil {
+				fmt.Fprintf(os.Stderr, "[logger] 压缩历史日志文件失败: %v\n", err)
+			}
+		}
+	}
+}
+
+// compressFile 使用 gzip 压缩文件，成功后删除原文件
+func compressFile(src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(src+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		_ = gz.Close()
+		_ = out.Close()
+		_ = os.Remove(src + compressSuffix)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		_ = out.Close()
+		_ = os.Remove(src + compressSuffix)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	_ = in.Close()
+	return os.Remove(src)
+}
+
+// fileExists 判断文件是否存在
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
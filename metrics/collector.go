@@ -22,6 +22,7 @@ func StartCollector(ctx context.Context, interval time.Duration) {
 			case <-ticker.C:
 				collectDBStats()
 				collectRedisStats()
+				collectMQStats()
 			}
 		}
 	}()
@@ -58,3 +59,8 @@ func collectRedisStats() {
 	RedisPoolTotalConns.Set(float64(stats.TotalConns))
 	RedisPoolIdleConns.Set(float64(stats.IdleConns))
 }
+
+// collectMQStats 收集消息队列统计
+func collectMQStats() {
+	MqFailedMessagesStored.Set(float64(app.FailedMessageCount()))
+}
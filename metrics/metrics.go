@@ -34,6 +34,24 @@ var (
 			Help: "Number of HTTP requests currently being processed",
 		},
 	)
+
+	// HttpConcurrencyInFlight 并发限制中间件中持有许可的请求数，rule 为规则路径，未匹配规则的请求为 global
+	HttpConcurrencyInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "http_concurrency_in_flight",
+			Help: "Number of HTTP requests holding a concurrency limit permit",
+		},
+		[]string{"rule"},
+	)
+
+	// HttpConcurrencyQueued 并发限制中间件中排队等待许可的请求数
+	HttpConcurrencyQueued = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "http_concurrency_queued",
+			Help: "Number of HTTP requests waiting for a concurrency limit permit",
+		},
+		[]string{"rule"},
+	)
 )
 
 // 数据库连接池指标
@@ -106,6 +124,17 @@ var (
 	)
 )
 
+// 消息队列指标
+var (
+	// MqFailedMessagesStored 当前保存的发送失败消息数（rabbitMQ.failedMessageStore）
+	MqFailedMessagesStored = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mq_failed_messages_stored",
+			Help: "Number of failed RabbitMQ publishes currently stored for replay",
+		},
+	)
+)
+
 // NewCounter 创建自定义 Prometheus Counter（计数器）。
 // Counter 是一种只增不减的指标，适用于请求总数、错误总数等累计统计场景。
 // 通过 promauto 自动注册到默认 Registry，无需手动注册。
@@ -0,0 +1,146 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现 API Key 认证中间件和按权限范围授权的中间件
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// apiKey 预先计算哈希的 API Key
+type apiKey struct {
+	digest []byte
+	entry  *config.APIKeyEntry
+}
+
+// APIKeyHandler API Key 认证中间件
+// 用于服务间调用的认证，配置项通过 app.BaseConfig.APIKey 进行设置
+//
+// 功能特性：
+// - 从 apiKey.headerName 请求头（默认 X-API-Key）读取 API Key，未携带时从 apiKey.queryParam 查询参数读取
+// - 按 SHA-256 哈希进行常量时间比较，配置文件中可以只保存哈希（hashedKey）
+// - 缺少、无效或已过期的 API Key 返回 response.ResponseUnauthorized 响应码（开启 service.useHTTPStatus 时 HTTP 状态码为 401）
+// - 认证通过后将调用方名称作为用户 ID、将 scopes 作为权限范围存入请求上下文（ginContext.GetUserID / ginContext.GetScopes）
+// - rateLimitHandler 的 "user" 限流维度按调用方名称限流，RequireScope 按权限范围授权
+// - 匹配 skipPaths 的请求不需要认证
+//
+// 注意：需要按调用方限流时，应在 service.middlewares 中将 apiKeyHandler 放在 rateLimitHandler 之前
+//
+// 使用示例：
+//
+//	在配置文件中启用：
+//	apiKey:
+//	  enabled: true
+//	  queryParam: "api_key"
+//	  skipPaths:
+//	    - "/health"
+//	  keys:
+//	    - name: "billing-service"
+//	      hashedKey: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+//	      scopes: ["orders:read"]
+//	      expiresAt: 2027-01-01T00:00:00+08:00
+//	service:
+//	  middlewares:
+//	    - "apiKeyHandler"
+func APIKeyHandler() gin.HandlerFunc {
+	cfg := app.BaseConfig.APIKey
+	keys := newAPIKeys(cfg.Keys)
+	return func(c *gin.Context) {
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+		handleAPIKey(c, &cfg, keys, time.Now())
+	}
+}
+
+// newAPIKeys 计算每个 API Key 的哈希，配置有误的 API Key 记录日志后忽略
+func newAPIKeys(entries []config.APIKeyEntry) []apiKey {
+	keys := make([]apiKey, 0, len(entries))
+	for i := range entries {
+		digest, err := entries[i].Digest()
+		if err != nil {
+			logger.Error("[API Key] apiKey.keys[%d] 配置有误, 已忽略: %v", i, err)
+			continue
+		}
+		keys = append(keys, apiKey{digest: digest, entry: &entries[i]})
+	}
+	return keys
+}
+
+// handleAPIKey 校验请求携带的 API Key，认证通过时将调用方信息存入请求上下文
+func handleAPIKey(c *gin.Context, cfg *config.APIKeyConfig, keys []apiKey, now time.Time) {
+	if matchAuditPath(c.Request.URL.Path, cfg.SkipPaths) {
+		c.Next()
+		return
+	}
+
+	provided := c.GetHeader(cfg.GetHeaderName())
+	if provided == "" && cfg.QueryParam != "" {
+		provided = c.Query(cfg.QueryParam)
+	}
+	if provided == "" {
+		abortUnauthorized(c, "缺少 API Key")
+		return
+	}
+
+	entry := matchAPIKey(keys, provided)
+	if entry == nil {
+		logger.Warn("[API Key] 无效的 API Key, path: %s, ip: %s", c.Request.URL.Path, c.ClientIP())
+		abortUnauthorized(c, "API Key 无效")
+		return
+	}
+	if entry.IsExpired(now) {
+		logger.Warn("[API Key] API Key 已过期, name: %s, path: %s", entry.Name, c.Request.URL.Path)
+		abortUnauthorized(c, "API Key 已过期")
+		return
+	}
+
+	ginContext.SetUserID(c, entry.Name)
+	ginContext.SetScopes(c, entry.Scopes)
+	c.Next()
+}
+
+// matchAPIKey 按哈希查找 API Key，与所有 API Key 进行常量时间比较，避免通过响应时间推断匹配位置
+func matchAPIKey(keys []apiKey, provided string) *config.APIKeyEntry {
+	sum := sha256.Sum256([]byte(provided))
+	var matched *config.APIKeyEntry
+	for i := range keys {
+		if subtle.ConstantTimeCompare(sum[:], keys[i].digest) == 1 && matched == nil {
+			matched = keys[i].entry
+		}
+	}
+	return matched
+}
+
+// abortUnauthorized 返回 response.ResponseUnauthorized 响应码，开启 service.useHTTPStatus 时 HTTP 状态码为 401
+func abortUnauthorized(c *gin.Context, msg string) {
+	response.WriteError(c, response.ResponseUnauthorized, response.WithMessage(msg))
+}
+
+// RequireScope 权限范围授权中间件
+// 请求上下文中的权限范围（由 APIKeyHandler 等认证中间件设置）不包含 scope 时返回 response.ResponseAuthFailed 响应码（开启 service.useHTTPStatus 时 HTTP 状态码为 403）
+//
+// 参数：
+//   - scope: 需要的权限范围
+//
+// 使用示例：
+//
+//	orders := engine.Group("/api/orders", middleware.RequireScope("orders:read"))
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ginContext.HasScope(c, scope) {
+			c.Next()
+			return
+		}
+		response.WriteError(c, response.ResponseAuthFailed)
+	}
+}
@@ -0,0 +1,218 @@
+// Package middleware API Key 认证中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 API Key 认证中间件和 RequireScope 的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 有效的明文 API Key 和哈希 API Key 认证通过，调用方名称和权限范围存入请求上下文
+// 2. 缺少、无效、已过期的 API Key 返回 401 和 ResponseUnauthorized 响应码
+// 3. 请求头优先，未携带时从查询参数读取
+// 4. RequireScope 按权限范围放行或返回 403
+// 5. 匹配 skipPaths 的请求不需要认证，未启用时不校验
+//
+// 运行测试：go test -v ./middleware/... -run APIKey
+// ==================================================
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// apiKeyTestNow 测试使用的当前时间
+var apiKeyTestNow = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// newAPIKeyTestConfig 创建测试用的 API Key 配置
+// billing 使用明文 Key，reporting 使用哈希 Key，legacy 已过期
+func newAPIKeyTestConfig() *config.APIKeyConfig {
+	hashed := sha256.Sum256([]byte("reporting-secret"))
+	return &config.APIKeyConfig{
+		Enabled:    true,
+		QueryParam: "api_key",
+		SkipPaths:  []string{"/health", "/public/*"},
+		Keys: []config.APIKeyEntry{
+			{Key: "billing-secret", Name: "billing", Scopes: []string{"orders:read", "orders:write"}},
+			{HashedKey: hex.EncodeToString(hashed[:]), Name: "reporting", Scopes: []string{"orders:read"}},
+			{Key: "legacy-secret", Name: "legacy", ExpiresAt: apiKeyTestNow.Add(-time.Hour)},
+		},
+	}
+}
+
+// createAPIKeyTestRouter 创建 API Key 测试路由
+// /api/orders 返回调用方名称和权限范围，/api/orders/write 需要 orders:write 权限
+func createAPIKeyTestRouter(cfg *config.APIKeyConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	keys := newAPIKeys(cfg.Keys)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		handleAPIKey(c, cfg, keys, apiKeyTestNow)
+	})
+	caller := func(c *gin.Context) {
+		userID, _ := ginContext.GetUserID(c)
+		c.JSON(http.StatusOK, gin.H{"name": userID, "scopes": ginContext.GetScopes(c)})
+	}
+	router.GET("/api/orders", caller)
+	router.POST("/api/orders/write", RequireScope("orders:write"), caller)
+	router.GET("/health", caller)
+	router.GET("/public/docs", caller)
+	return router
+}
+
+// doAPIKeyRequest 发送测试请求，key 非空时通过 X-API-Key 请求头携带
+func doAPIKeyRequest(router *gin.Engine, method, target, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decodeAPIKeyResponse 解析响应体
+func decodeAPIKeyResponse(t *testing.T, w *httptest.ResponseRecorder) response.Response {
+	t.Helper()
+	var resp response.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+// ==================== 测试用例 ====================
+
+// TestAPIKey_ValidKey 测试有效的 API Key
+//
+// 【功能点】验证明文和哈希配置的 API Key 认证通过，调用方名称和权限范围存入请求上下文
+// 【测试流程】
+//  1. 携带明文配置的 billing Key 请求，断言 200，响应中的调用方名称和权限范围正确
+//  2. 携带哈希配置的 reporting Key 请求，断言 200，调用方为 reporting
+func TestAPIKey_ValidKey(t *testing.T) {
+	router := createAPIKeyTestRouter(newAPIKeyTestConfig())
+
+	w := doAPIKeyRequest(router, http.MethodGet, "/api/orders", "billing-secret")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name":"billing","scopes":["orders:read","orders:write"]}`, w.Body.String())
+
+	w = doAPIKeyRequest(router, http.MethodGet, "/api/orders", "reporting-secret")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name":"reporting","scopes":["orders:read"]}`, w.Body.String())
+}
+
+// TestAPIKey_Rejected 测试拒绝的 API Key
+//
+// 【功能点】验证缺少、无效、已过期的 API Key 返回 401 和 ResponseUnauthorized 响应码
+// 【测试流程】
+//  1. 不携带 API Key，断言 401、响应码 41003、消息为缺少 API Key
+//  2. 携带未配置的 API Key 和哈希值本身，断言 401、消息为 API Key 无效
+//  3. 携带已过期的 legacy Key，断言 401、消息为 API Key 已过期
+func TestAPIKey_Rejected(t *testing.T) {
+	response.SetUseHTTPStatus(true)
+	defer response.SetUseHTTPStatus(false)
+	cfg := newAPIKeyTestConfig()
+	router := createAPIKeyTestRouter(cfg)
+
+	tests := []struct {
+		name string
+		key  string
+		msg  string
+	}{
+		{"missing", "", "缺少 API Key"},
+		{"unknown", "unknown-secret", "API Key 无效"},
+		{"hash as key", cfg.Keys[1].HashedKey, "API Key 无效"},
+		{"expired", "legacy-secret", "API Key 已过期"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doAPIKeyRequest(router, http.MethodGet, "/api/orders", tt.key)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			resp := decodeAPIKeyResponse(t, w)
+			assert.Equal(t, response.ResponseUnauthorized.GetCode(), resp.Code)
+			assert.Equal(t, tt.msg, resp.Msg)
+		})
+	}
+}
+
+// TestAPIKey_QueryParam 测试从查询参数读取 API Key
+//
+// 【功能点】验证未携带请求头时从查询参数读取，同时携带时请求头优先，未配置 queryParam 时不读取查询参数
+// 【测试流程】
+//  1. 只通过查询参数携带 billing Key，断言认证通过
+//  2. 请求头携带 reporting Key、查询参数携带 billing Key，断言调用方为 reporting
+//  3. 清空 queryParam 配置后只通过查询参数携带，断言 401
+func TestAPIKey_QueryParam(t *testing.T) {
+	response.SetUseHTTPStatus(true)
+	defer response.SetUseHTTPStatus(false)
+	cfg := newAPIKeyTestConfig()
+	router := createAPIKeyTestRouter(cfg)
+
+	w := doAPIKeyRequest(router, http.MethodGet, "/api/orders?api_key=billing-secret", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"billing"`)
+
+	w = doAPIKeyRequest(router, http.MethodGet, "/api/orders?api_key=billing-secret", "reporting-secret")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"reporting"`)
+
+	cfg.QueryParam = ""
+	router = createAPIKeyTestRouter(cfg)
+	w = doAPIKeyRequest(router, http.MethodGet, "/api/orders?api_key=billing-secret", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// TestAPIKey_RequireScope 测试按权限范围授权
+//
+// 【功能点】验证 RequireScope 在拥有权限范围时放行，否则返回 403 和 ResponseAuthFailed 响应码
+// 【测试流程】
+//  1. billing（拥有 orders:write）请求 /api/orders/write，断言 200
+//  2. reporting（只有 orders:read）请求，断言 403、响应码 41010
+//  3. 未经过认证中间件、请求上下文中没有权限范围时，断言 403
+func TestAPIKey_RequireScope(t *testing.T) {
+	response.SetUseHTTPStatus(true)
+	defer response.SetUseHTTPStatus(false)
+	router := createAPIKeyTestRouter(newAPIKeyTestConfig())
+
+	w := doAPIKeyRequest(router, http.MethodPost, "/api/orders/write", "billing-secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doAPIKeyRequest(router, http.MethodPost, "/api/orders/write", "reporting-secret")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, response.ResponseAuthFailed.GetCode(), decodeAPIKeyResponse(t, w).Code)
+
+	bare := gin.New()
+	bare.GET("/admin", RequireScope("admin"), func(c *gin.Context) { c.Status(http.StatusOK) })
+	w = doAPIKeyRequest(bare, http.MethodGet, "/admin", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+// TestAPIKey_SkipPaths 测试跳过认证的路径
+//
+// 【功能点】验证匹配 skipPaths（精确匹配和通配符）的请求不需要 API Key，未启用时不校验
+// 【测试流程】
+//  1. 不携带 API Key 请求 /health 和 /public/docs，断言 200
+//  2. 未启用时通过 APIKeyHandler 不携带 API Key 请求，断言 200
+func TestAPIKey_SkipPaths(t *testing.T) {
+	router := createAPIKeyTestRouter(newAPIKeyTestConfig())
+	for _, target := range []string{"/health", "/public/docs"} {
+		w := doAPIKeyRequest(router, http.MethodGet, target, "")
+		assert.Equal(t, http.StatusOK, w.Code, target)
+	}
+
+	disabled := gin.New()
+	disabled.Use(APIKeyHandler())
+	disabled.GET("/api/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+	w := doAPIKeyRequest(disabled, http.MethodGet, "/api/orders", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
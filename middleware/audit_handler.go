@@ -0,0 +1,375 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现审计日志中间件，记录指定路径的请求体和响应体
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/entity"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"github.com/zzsen/gin_core/utils/jsoncodec"
+	"github.com/zzsen/gin_core/utils/netutil"
+	"gorm.io/gorm"
+)
+
+const (
+	// auditTruncatedMarker 请求体、响应体被截断时追加的标记
+	auditTruncatedMarker = "...[truncated]"
+	// auditMaskedValue 脱敏字段的替换值
+	auditMaskedValue = "****"
+)
+
+var (
+	// auditMigrateMu 保护 auditMigratedDB 的并发访问
+	auditMigrateMu sync.Mutex
+	// auditMigratedDB 已创建审计表的数据库，数据库实例变化时重新创建
+	auditMigratedDB *gorm.DB
+	// auditMaskPatterns 文本模式脱敏的正则缓存，key: 字段名
+	auditMaskPatterns sync.Map
+)
+
+// auditBodyWriter 响应体旁路记录器
+// 响应照常写给客户端，同时记录前 limit 字节，并统计响应体总字节数
+type auditBodyWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+	size  int64
+}
+
+// Write 写入响应并记录
+func (w *auditBodyWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString 写入字符串响应并记录
+func (w *auditBodyWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture 记录响应体，超过 limit 的部分只统计字节数
+func (w *auditBodyWriter) capture(b []byte) {
+	w.size += int64(len(b))
+	if remain := w.limit - w.body.Len(); remain > 0 {
+		w.body.Write(b[:min(len(b), remain)])
+	}
+}
+
+// AuditLogHandler 审计日志中间件
+// 对匹配 audit.paths 的请求记录请求体和响应体，写入框架日志或 audit_logs 数据表
+// 配置项通过 app.BaseConfig.Audit 进行设置
+//
+// 功能特性：
+// - 路径匹配规则与限流规则一致，支持 /api/admin/* 通配符
+// - 读取请求体后重新写回，后续处理器仍可调用 ShouldBindJSON 等方法
+// - 请求体、响应体按 maxBodyBytes 截断，截断时追加 "...[truncated]" 标记
+// - JSON 和表单请求体按 maskFields 脱敏，支持嵌套字段
+// - 二进制内容（文件上传、图片下载等）只记录类型和大小
+// - 记录 traceId、userId、状态码和耗时
+//
+// 使用示例：
+//
+//	在配置文件中启用：
+//	audit:
+//	  enabled: true
+//	  paths:
+//	    - "/api/admin/*"
+//	  maxBodyBytes: 65536
+//	  maskFields: ["password", "idCard", "user.phone"]
+//	  sink: "db"
+func AuditLogHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := app.BaseConfig.Audit
+		if !cfg.Enabled || !matchAuditPath(c.Request.URL.Path, cfg.Paths) {
+			c.Next()
+			return
+		}
+
+		startTime := time.Now()
+		limit := cfg.GetMaxBodyBytes()
+		maskFields := cfg.GetMaskFields()
+
+		record := &entity.AuditLog{
+			Method:   c.Request.Method,
+			Path:     c.Request.URL.Path,
+			Query:    maskQuery(c.Request.URL.RawQuery, maskFields),
+			ClientIP: netutil.ClientIP(c),
+			ReqType:  c.ContentType(),
+			ReqSize:  c.Request.ContentLength,
+		}
+		if c.Request.Body != nil && !isBinaryContentType(record.ReqType) {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				logger.Warn("[审计] 读取请求体失败: %v", err)
+			}
+			// 重新写回请求体，后续处理器仍可读取
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			record.ReqSize = int64(len(body))
+			record.ReqBody = auditBody(body, false, record.ReqType, maskFields, limit)
+		}
+
+		writer := &auditBodyWriter{ResponseWriter: c.Writer, limit: limit}
+		c.Writer = writer
+		c.Next()
+
+		record.Status = c.Writer.Status()
+		record.LatencyMs = time.Since(startTime).Milliseconds()
+		record.TraceID = ginContext.GetTraceID(c)
+		record.UserID, _ = ginContext.GetUserID(c)
+		record.RespType = c.Writer.Header().Get("Content-Type")
+		record.RespSize = writer.size
+		if !isBinaryContentType(record.RespType) {
+			truncated := writer.size > int64(writer.body.Len())
+			record.RespBody = auditBody(writer.body.Bytes(), truncated, record.RespType, maskFields, limit)
+		}
+		record.CreateTime = time.Now()
+
+		writeAuditLog(record, cfg)
+	}
+}
+
+// matchAuditPath 判断请求路径是否需要审计
+// 支持精确匹配、/* 后缀通配符（匹配该前缀下的所有路径）和 path.Match 模式
+func matchAuditPath(requestPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if pattern == requestPath {
+			return true
+		}
+		if strings.HasSuffix(pattern, "/*") {
+			prefix := strings.TrimSuffix(pattern, "/*")
+			if requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/") {
+				return true
+			}
+		}
+		if matched, _ := path.Match(pattern, requestPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isBinaryContentType 判断内容类型是否为二进制，二进制内容只记录类型和大小
+// 未设置内容类型时按文本处理
+func isBinaryContentType(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return true
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "json"),
+		strings.HasSuffix(mediaType, "xml"),
+		mediaType == "application/x-www-form-urlencoded",
+		mediaType == "application/javascript":
+		return false
+	}
+	return true
+}
+
+// auditBody 对请求体或响应体脱敏并截断
+// 完整的 JSON 和表单内容解析后按字段脱敏；已被截断或无法解析的内容按 "字段":"值" 文本模式脱敏
+// 参数：
+//   - body: 原始内容
+//   - truncated: 内容在记录时是否已被截断
+//   - contentType: 内容类型
+//   - maskFields: 需要脱敏的字段
+//   - limit: 最大记录字节数
+//
+// 返回：
+//   - string: 脱敏、截断后的内容
+func auditBody(body []byte, truncated bool, contentType string, maskFields []string, limit int) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	result := ""
+	if !truncated {
+		if masked, ok := maskJSONBody(body, maskFields); ok {
+			result = masked
+		} else if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/x-www-form-urlencoded" {
+			result = maskQuery(string(body), maskFields)
+		}
+	}
+	if result == "" {
+		result = maskJSONText(string(body), maskFields)
+	}
+
+	if len(result) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(result[cut]) {
+			cut--
+		}
+		result, truncated = result[:cut], true
+	}
+	if truncated {
+		result += auditTruncatedMarker
+	}
+	return result
+}
+
+// maskJSONBody 解析 JSON 并对匹配的字段脱敏，内容不是 JSON 时返回 false
+func maskJSONBody(body []byte, maskFields []string) (string, bool) {
+	decoder := jsoncodec.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	encoder := jsoncodec.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(maskJSONValue(value, "", maskFields)); err != nil {
+		return "", false
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), true
+}
+
+// maskJSONValue 递归脱敏 JSON 值，prefix 为当前值的字段路径（数组元素不占路径层级）
+func maskJSONValue(value any, prefix string, maskFields []string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			fieldPath := key
+			if prefix != "" {
+				fieldPath = prefix + "." + key
+			}
+			if isMaskField(key, fieldPath, maskFields) {
+				v[key] = auditMaskedValue
+				continue
+			}
+			v[key] = maskJSONValue(child, fieldPath, maskFields)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = maskJSONValue(child, prefix, maskFields)
+		}
+	}
+	return value
+}
+
+// isMaskField 判断字段是否需要脱敏（不区分大小写）
+// 配置不含 "." 时匹配任意层级的同名字段，含 "." 时匹配完整路径
+func isMaskField(key, fieldPath string, maskFields []string) bool {
+	for _, field := range maskFields {
+		if strings.Contains(field, ".") {
+			if strings.EqualFold(field, fieldPath) {
+				return true
+			}
+		} else if strings.EqualFold(field, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskJSONText 按 "字段": 值 文本模式脱敏，用于被截断或无法解析的内容
+// 路径形式的配置取最后一级字段名匹配
+func maskJSONText(text string, maskFields []string) string {
+	for _, field := range maskFields {
+		name := field[strings.LastIndex(field, ".")+1:]
+		cached, ok := auditMaskPatterns.Load(name)
+		if !ok {
+			cached, _ = auditMaskPatterns.LoadOrStore(name, regexp.MustCompile(`(?i)("`+regexp.QuoteMeta(name)+`"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`))
+		}
+		text = cached.(*regexp.Regexp).ReplaceAllString(text, `${1}"`+auditMaskedValue+`"`)
+	}
+	return text
+}
+
+// maskQuery 对查询参数或表单内容中的脱敏字段替换值，无法解析时原样返回
+func maskQuery(rawQuery string, maskFields []string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	masked := false
+	for key := range values {
+		if isMaskField(key, key, maskFields) {
+			values[key] = []string{auditMaskedValue}
+			masked = true
+		}
+	}
+	if !masked {
+		return rawQuery
+	}
+	return values.Encode()
+}
+
+// writeAuditLog 写入审计日志
+// sink 为 db 时写入 audit_logs 表（首次写入时自动建表），写入失败时改为写入框架日志，避免丢失审计记录
+func writeAuditLog(record *entity.AuditLog, cfg config.AuditConfig) {
+	if cfg.GetSink() == config.AuditSinkDB {
+		db, err := auditDB(cfg.DbAliasName)
+		if err == nil {
+			err = db.Create(record).Error
+		}
+		if err == nil {
+			return
+		}
+		logger.Error("[审计] 写入审计日志表失败，改为写入日志: %v", err)
+	}
+
+	logger.InfoWithFields(map[string]any{
+		"traceId":   record.TraceID,
+		"userId":    record.UserID,
+		"method":    record.Method,
+		"path":      record.Path,
+		"query":     record.Query,
+		"clientIp":  record.ClientIP,
+		"status":    record.Status,
+		"latencyMs": record.LatencyMs,
+		"reqType":   record.ReqType,
+		"reqSize":   record.ReqSize,
+		"reqBody":   record.ReqBody,
+		"respType":  record.RespType,
+		"respSize":  record.RespSize,
+		"respBody":  record.RespBody,
+	}, "审计日志")
+}
+
+// auditDB 获取审计表所在的数据库，别名为空时使用主数据库
+func auditDB(aliasName string) (*gorm.DB, error) {
+	db := app.DB
+	if aliasName != "" {
+		var err error
+		if db, err = app.GetDbByName(aliasName); err != nil {
+			return nil, err
+		}
+	}
+	if db == nil {
+		return nil, errors.New("数据库未初始化")
+	}
+
+	auditMigrateMu.Lock()
+	defer auditMigrateMu.Unlock()
+	if auditMigratedDB != db {
+		if err := db.AutoMigrate(&entity.AuditLog{}); err != nil {
+			return nil, err
+		}
+		auditMigratedDB = db
+	}
+	return db, nil
+}
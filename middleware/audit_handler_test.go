@@ -0,0 +1,249 @@
+// Package middleware 审计日志中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含审计日志中间件的单元测试，使用 SQLite 内存数据库作为审计表，不需要 MySQL。
+//
+// 测试覆盖内容：
+// 1. 路径匹配：精确、/* 通配符、path.Match 模式
+// 2. 嵌套字段、数组元素、路径形式字段的脱敏
+// 3. 超过 maxBodyBytes 时截断并追加标记，截断后的内容仍按文本模式脱敏
+// 4. 请求体被记录后下游仍可通过 ShouldBindJSON 绑定
+// 5. sink 为 db 时写入 audit_logs 表的字段内容
+// 6. 二进制内容只记录类型和大小
+//
+// 运行测试：go test -v ./middleware/... -run Audit
+// ==================================================
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/entity"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// setupAuditTest 设置审计配置并使用 SQLite 内存数据库作为主数据库，测试结束后恢复
+func setupAuditTest(t *testing.T, cfg config.AuditConfig) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())),
+		&gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+
+	originalConfig, originalDB := app.BaseConfig, app.DB
+	app.BaseConfig = config.BaseConfig{Audit: cfg}
+	app.DB = db
+	t.Cleanup(func() {
+		app.BaseConfig, app.DB = originalConfig, originalDB
+		_ = sqlDB.Close()
+	})
+	return db
+}
+
+// createAuditTestRouter 创建审计测试路由，handler 注册在 /api/admin/user 和 /api/public/ping 上
+func createAuditTestRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		ginContext.SetTraceID(c, "trace-audit-1")
+		c.Next()
+	})
+	router.Use(AuditLogHandler())
+	router.POST("/api/admin/user", handler)
+	router.POST("/api/public/ping", handler)
+	return router
+}
+
+// lastAuditLog 查询最后一条审计记录
+func lastAuditLog(t *testing.T, db *gorm.DB) entity.AuditLog {
+	var record entity.AuditLog
+	require.NoError(t, db.Order("id desc").First(&record).Error)
+	return record
+}
+
+// ==================== 测试用例 ====================
+
+// TestMatchAuditPath 测试审计路径匹配
+//
+// 【功能点】验证精确匹配、/* 通配符（按路径段匹配）和 path.Match 模式
+// 【测试流程】构造多组路径与规则，断言匹配结果
+func TestMatchAuditPath(t *testing.T) {
+	patterns := []string{"/api/admin/*", "/api/user/password", "/api/*/export"}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/api/admin", true},
+		{"/api/admin/user/1", true},
+		{"/api/administrator", false},
+		{"/api/user/password", true},
+		{"/api/user/profile", false},
+		{"/api/order/export", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchAuditPath(tt.path, patterns), tt.path)
+	}
+}
+
+// TestAuditBody_MaskNestedFields 测试嵌套字段脱敏
+//
+// 【功能点】验证字段名匹配任意层级（含数组元素），路径形式只匹配完整路径，非 JSON 内容按文本模式脱敏
+// 【测试流程】
+//  1. maskFields 为 password、idCard、contact.phone
+//  2. 对包含嵌套对象和数组的 JSON 脱敏，断言对应字段被替换，同名但路径不同的 phone 保留
+//  3. 对表单内容和无法解析的文本脱敏，断言敏感值不出现
+func TestAuditBody_MaskNestedFields(t *testing.T) {
+	maskFields := []string{"password", "IDCard", "contact.phone"}
+	body := `{"name":"alice","password":"p@ss","profile":{"idCard":"110101199001011234","tags":["<a>"]},` +
+		`"members":[{"password":"m1"},{"password":"m2"}],"contact":{"phone":"13800000000"},"phone":"010-1234"}`
+
+	got := auditBody([]byte(body), false, "application/json", maskFields, 4096)
+
+	assert.NotContains(t, got, "p@ss")
+	assert.NotContains(t, got, "110101199001011234")
+	assert.NotContains(t, got, "m1")
+	assert.NotContains(t, got, "13800000000")
+	assert.Contains(t, got, `"idCard":"****"`)
+	assert.Contains(t, got, `"members":[{"password":"****"},{"password":"****"}]`)
+	assert.Contains(t, got, `"phone":"010-1234"`, "顶层 phone 不匹配 contact.phone")
+	assert.Contains(t, got, `"tags":["<a>"]`, "不应转义 HTML 字符")
+	assert.Contains(t, got, `"name":"alice"`)
+
+	form := auditBody([]byte("username=alice&password=secret"), false, "application/x-www-form-urlencoded", maskFields, 4096)
+	assert.NotContains(t, form, "secret")
+	assert.Contains(t, form, "username=alice")
+
+	text := auditBody([]byte(`broken {"password": "secret", "idCard": 1234`), false, "text/plain", maskFields, 4096)
+	assert.NotContains(t, text, "secret")
+	assert.NotContains(t, text, "1234")
+}
+
+// TestAuditBody_Truncate 测试截断
+//
+// 【功能点】验证超过 maxBodyBytes 时截断并追加标记，截断位置不破坏 UTF-8 字符，已截断内容仍脱敏
+// 【测试流程】
+//  1. 中文内容超过限制，断言结果以截断标记结尾，且截断部分为合法 UTF-8
+//  2. 内容在记录时已截断（truncated=true），断言按文本模式脱敏并追加标记
+//  3. 未超过限制的内容不追加标记
+func TestAuditBody_Truncate(t *testing.T) {
+	got := auditBody([]byte(strings.Repeat("审计", 100)), false, "text/plain", nil, 10)
+	require.True(t, strings.HasSuffix(got, auditTruncatedMarker))
+	kept := strings.TrimSuffix(got, auditTruncatedMarker)
+	assert.LessOrEqual(t, len(kept), 10)
+	assert.Equal(t, "审计审", kept)
+
+	partial := auditBody([]byte(`{"user":{"password":"sec`), true, "application/json", []string{"password"}, 1024)
+	assert.Equal(t, `{"user":{"password":"****"`+auditTruncatedMarker, partial)
+
+	assert.Equal(t, `{"a":1}`, auditBody([]byte(`{"a":1}`), false, "application/json", nil, 1024))
+}
+
+// TestAuditLogHandler_DBSink 测试写入审计表
+//
+// 【功能点】验证匹配路径的请求写入 audit_logs 表，下游仍可绑定请求体，记录包含脱敏后的请求/响应体及上下文信息
+// 【测试流程】
+//  1. 配置 sink=db、maxBodyBytes=64
+//  2. 处理器通过 ShouldBindJSON 绑定请求体并设置用户 ID，返回超过 64 字节的 JSON 响应
+//  3. 断言处理器绑定到原始密码，客户端收到完整响应
+//  4. 断言审计记录的 traceId、userId、状态码、请求体脱敏、响应体截断标记和大小
+//  5. 请求不匹配的路径，断言不新增记录
+func TestAuditLogHandler_DBSink(t *testing.T) {
+	db := setupAuditTest(t, config.AuditConfig{
+		Enabled:      true,
+		Paths:        []string{"/api/admin/*"},
+		MaxBodyBytes: 64,
+		Sink:         config.AuditSinkDB,
+	})
+
+	var bound struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	router := createAuditTestRouter(func(c *gin.Context) {
+		if err := c.ShouldBindJSON(&bound); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		ginContext.SetUserID(c, "admin-1")
+		c.JSON(http.StatusCreated, gin.H{"name": bound.Name, "idCard": "110101199001011234", "remark": strings.Repeat("r", 100)})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/user?token=abc&password=q", strings.NewReader(`{"name":"alice","password":"p@ss"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "p@ss", bound.Password, "下游应能绑定原始请求体")
+	assert.Contains(t, w.Body.String(), strings.Repeat("r", 100), "客户端应收到完整响应")
+
+	record := lastAuditLog(t, db)
+	assert.Equal(t, "trace-audit-1", record.TraceID)
+	assert.Equal(t, "admin-1", record.UserID)
+	assert.Equal(t, http.MethodPost, record.Method)
+	assert.Equal(t, "/api/admin/user", record.Path)
+	assert.Equal(t, "password=%2A%2A%2A%2A&token=abc", record.Query)
+	assert.Equal(t, http.StatusCreated, record.Status)
+	assert.GreaterOrEqual(t, record.LatencyMs, int64(0))
+	assert.Equal(t, "application/json", record.ReqType)
+	assert.Equal(t, `{"name":"alice","password":"****"}`, record.ReqBody)
+	assert.Equal(t, int64(w.Body.Len()), record.RespSize)
+	assert.True(t, strings.HasSuffix(record.RespBody, auditTruncatedMarker))
+	assert.NotContains(t, record.RespBody, "110101199001011234")
+	assert.False(t, record.CreateTime.IsZero())
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/public/ping", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	var count int64
+	require.NoError(t, db.Model(&entity.AuditLog{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count, "未匹配的路径不应记录")
+}
+
+// TestAuditLogHandler_BinaryContent 测试二进制内容
+//
+// 【功能点】验证二进制请求体和响应体只记录类型和大小，下游仍可读取请求体
+// 【测试流程】
+//  1. 以 application/octet-stream 上传 1KB 数据，处理器读取后返回 image/png
+//  2. 断言审计记录的请求体、响应体为空，类型和大小正确
+func TestAuditLogHandler_BinaryContent(t *testing.T) {
+	db := setupAuditTest(t, config.AuditConfig{Enabled: true, Paths: []string{"/api/admin/user"}, Sink: config.AuditSinkDB})
+
+	payload := bytes.Repeat([]byte{0xff}, 1024)
+	router := createAuditTestRouter(func(c *gin.Context) {
+		data, err := c.GetRawData()
+		require.NoError(t, err)
+		c.Data(http.StatusOK, "image/png", data[:512])
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/user", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 512, w.Body.Len())
+
+	record := lastAuditLog(t, db)
+	assert.Equal(t, "application/octet-stream", record.ReqType)
+	assert.Equal(t, int64(1024), record.ReqSize)
+	assert.Empty(t, record.ReqBody)
+	assert.Equal(t, "image/png", record.RespType)
+	assert.Equal(t, int64(512), record.RespSize)
+	assert.Empty(t, record.RespBody)
+}
@@ -0,0 +1,187 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现故障注入中间件，为匹配的请求注入延迟、错误响应或断开连接，用于韧性测试
+package middleware
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// chaosSeedHeader 指定随机数种子的请求头，相同种子的请求注入结果相同，用于可复现的测试
+const chaosSeedHeader = "X-Chaos-Seed"
+
+// chaosFault 单个请求的故障注入结果
+type chaosFault struct {
+	latency time.Duration // 注入的延迟
+	fail    bool          // 是否返回错误响应
+	abort   bool          // 是否断开连接
+}
+
+// ChaosHandler 故障注入中间件
+// 对匹配 chaos.rules 的请求按规则注入故障，未启用或当前运行环境为 chaos.prodEnv（默认 prod）时直接放行
+// 配置项通过 app.BaseConfig.Chaos 进行设置
+//
+// 功能特性：
+// - 按 percentage 决定是否对请求应用规则，应用时先注入 latencyMs + [0, latencyJitterMs] 的延迟
+// - abortConnection 为 true 时直接断开连接，否则按 errorRate 的概率返回 errorCode 对应的统一响应
+// - 请求携带 X-Chaos-Seed 请求头（无符号整数）时使用该种子生成随机数，相同种子的注入结果相同
+// - 每次注入都会记录规则、注入内容和 traceId
+//
+// 使用示例：
+//
+//	在配置文件中启用：
+//	chaos:
+//	  enabled: true
+//	  rules:
+//	    - path: "/api/orders/*"
+//	      latencyMs: 200
+//	      latencyJitterMs: 100
+//	      errorRate: 0.1
+//	      errorCode: 90001
+func ChaosHandler() gin.HandlerFunc {
+	cfg := app.BaseConfig.Chaos
+	if cfg.Enabled && app.Env == cfg.GetProdEnv() {
+		logger.Warn("[chaos] 当前运行环境为 %s，故障注入不会生效", app.Env)
+	}
+
+	return func(c *gin.Context) {
+		if !cfg.Enabled || app.Env == cfg.GetProdEnv() {
+			c.Next()
+			return
+		}
+		rule := findChaosRule(c.Request.Method, c.Request.URL.Path, cfg.Rules)
+		if rule == nil {
+			c.Next()
+			return
+		}
+		fault, ok := decideChaosFault(rule, chaosRand(c))
+		if !ok {
+			c.Next()
+			return
+		}
+		applyChaosFault(c, rule, fault)
+	}
+}
+
+// chaosRand 获取请求使用的随机数生成器，携带合法的 X-Chaos-Seed 请求头时使用该种子
+func chaosRand(c *gin.Context) *rand.Rand {
+	if raw := c.GetHeader(chaosSeedHeader); raw != "" {
+		if seed, err := strconv.ParseUint(raw, 10, 64); err == nil {
+			return rand.New(rand.NewPCG(seed, seed))
+		}
+	}
+	return rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+}
+
+// decideChaosFault 按规则决定请求的故障注入结果
+// 随机数的使用顺序固定（应用比例、延迟抖动、错误概率），保证相同种子的结果相同
+//
+// 返回：
+//   - chaosFault: 注入结果
+//   - bool: 是否对该请求应用规则，未应用或规则没有配置任何故障时为 false
+func decideChaosFault(rule *config.ChaosRule, rng *rand.Rand) (chaosFault, bool) {
+	if rng.Float64()*100 >= rule.GetPercentage() {
+		return chaosFault{}, false
+	}
+	var fault chaosFault
+	fault.latency = time.Duration(rule.LatencyMs) * time.Millisecond
+	if rule.LatencyJitterMs > 0 {
+		fault.latency += time.Duration(rng.IntN(rule.LatencyJitterMs+1)) * time.Millisecond
+	}
+	fault.abort = rule.AbortConnection
+	fault.fail = !fault.abort && rng.Float64() < rule.ErrorRate
+	return fault, fault.latency > 0 || fault.fail || fault.abort
+}
+
+// applyChaosFault 注入故障：先等待延迟（请求取消时提前结束），再断开连接、返回错误响应或继续处理
+func applyChaosFault(c *gin.Context, rule *config.ChaosRule, fault chaosFault) {
+	logger.Warn("[chaos] 注入故障, rule: %s %s, latency: %dms, error: %v, abort: %v, traceId: %s",
+		rule.Method, rule.Path, fault.latency.Milliseconds(), fault.fail, fault.abort, ginContext.GetTraceID(c))
+
+	if fault.latency > 0 {
+		timer := time.NewTimer(fault.latency)
+		select {
+		case <-timer.C:
+		case <-c.Request.Context().Done():
+			timer.Stop()
+			c.Abort()
+			return
+		}
+	}
+
+	switch {
+	case fault.abort:
+		abortConnection(c)
+	case fault.fail:
+		code := rule.ErrorCode
+		if code == 0 {
+			code = response.ResponseFail.GetCode()
+		}
+		response.WriteError(c, response.Of(code))
+	default:
+		c.Next()
+	}
+}
+
+// abortConnection 断开客户端连接，不返回任何响应
+// 通过 Hijack 获取底层连接并关闭；不支持 Hijack（如 HTTP/2）时返回 503 状态码
+func abortConnection(c *gin.Context) {
+	c.Abort()
+	if !hijackAndClose(c.Writer) {
+		c.Status(http.StatusServiceUnavailable)
+	}
+}
+
+// hijackAndClose 接管并关闭底层连接，不支持 Hijack 时返回 false
+// gin 的 ResponseWriter 对不支持 Hijack 的底层 Writer 直接断言会 panic，因此先逐层 Unwrap 检查最底层的 Writer，
+// 无法 Unwrap 的包装层仍可能 panic，这里同时恢复 panic
+func hijackAndClose(w gin.ResponseWriter) (ok bool) {
+	inner := http.ResponseWriter(w)
+	for {
+		u, isWrapper := inner.(interface{ Unwrap() http.ResponseWriter })
+		if !isWrapper {
+			break
+		}
+		inner = u.Unwrap()
+	}
+	if _, isHijacker := inner.(http.Hijacker); !isHijacker {
+		return false
+	}
+
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	conn, _, err := w.Hijack()
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// findChaosRule 按顺序查找第一个匹配请求方法和路径的故障注入规则
+// 路径支持精确匹配、/* 后缀通配符（匹配该前缀下的所有路径）和 path.Match 模式，空 Method 表示匹配所有方法
+func findChaosRule(method, requestPath string, rules []config.ChaosRule) *config.ChaosRule {
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Method != "" && !strings.EqualFold(rule.Method, method) {
+			continue
+		}
+		if matchAuditPath(requestPath, []string{rule.Path}) {
+			return rule
+		}
+	}
+	return nil
+}
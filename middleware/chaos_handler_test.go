@@ -0,0 +1,202 @@
+// Package middleware 故障注入中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含故障注入中间件的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 未启用、未匹配规则时直接放行
+// 2. 运行环境为生产环境（默认 prod，可配置）时不注入故障
+// 3. 固定种子下错误响应的比例符合 errorRate 和 percentage，相同种子的结果可复现
+// 4. 延迟注入可测量，处理函数在延迟后执行
+// 5. abortConnection 时断开连接，处理函数不执行；不支持 Hijack 时返回 503
+//
+// 运行测试：go test -v ./middleware/... -run Chaos
+// ==================================================
+package middleware
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// chaosTestRouter 故障注入测试路由，calls 记录处理函数的执行次数
+type chaosTestRouter struct {
+	router *gin.Engine
+	calls  atomic.Int32
+}
+
+// newChaosTestRouter 以指定配置和运行环境创建故障注入测试路由，测试结束后恢复配置和运行环境
+func newChaosTestRouter(t *testing.T, cfg config.ChaosConfig, env string) *chaosTestRouter {
+	originalCfg, originalEnv := app.BaseConfig.Chaos, app.Env
+	t.Cleanup(func() { app.BaseConfig.Chaos, app.Env = originalCfg, originalEnv })
+	app.BaseConfig.Chaos, app.Env = cfg, env
+
+	tr := &chaosTestRouter{}
+	gin.SetMode(gin.TestMode)
+	tr.router = gin.New()
+	tr.router.Use(ChaosHandler())
+	tr.router.GET("/api/orders/:id", func(c *gin.Context) {
+		tr.calls.Add(1)
+		response.Ok(c)
+	})
+	tr.router.GET("/api/users", func(c *gin.Context) {
+		tr.calls.Add(1)
+		response.Ok(c)
+	})
+	return tr
+}
+
+// get 发送 GET 请求，seed 不为空时携带 X-Chaos-Seed 请求头，返回响应体中的响应码
+func (tr *chaosTestRouter) get(t *testing.T, path, seed string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if seed != "" {
+		req.Header.Set(chaosSeedHeader, seed)
+	}
+	w := httptest.NewRecorder()
+	tr.router.ServeHTTP(w, req)
+	var body response.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+	return body.Code
+}
+
+// errorRule 对 /api/orders/* 以 errorRate 的概率返回 rpc 异常响应码的规则
+func errorRule(errorRate, percentage float64) config.ChaosConfig {
+	return config.ChaosConfig{Enabled: true, Rules: []config.ChaosRule{{
+		Path:       "/api/orders/*",
+		ErrorRate:  errorRate,
+		ErrorCode:  response.ResponseExceptionRpc.GetCode(),
+		Percentage: percentage,
+	}}}
+}
+
+// ==================== 测试用例 ====================
+
+// TestChaosHandler_PassThrough 测试放行的请求
+//
+// 【功能点】验证未启用或请求未匹配任何规则时不注入故障
+// 【测试流程】
+//  1. errorRate 为 1 的规则在未启用时，断言请求正常返回
+//  2. 启用后请求未匹配规则的路径，断言正常返回；匹配规则的路径返回注入的响应码
+func TestChaosHandler_PassThrough(t *testing.T) {
+	cfg := errorRule(1, 0)
+	cfg.Enabled = false
+	tr := newChaosTestRouter(t, cfg, "test")
+	assert.Equal(t, response.ResponseSuccess.GetCode(), tr.get(t, "/api/orders/1", ""))
+
+	tr = newChaosTestRouter(t, errorRule(1, 0), "test")
+	assert.Equal(t, response.ResponseSuccess.GetCode(), tr.get(t, "/api/users", ""))
+	assert.Equal(t, response.ResponseExceptionRpc.GetCode(), tr.get(t, "/api/orders/1", ""))
+	assert.Equal(t, int32(1), tr.calls.Load())
+}
+
+// TestChaosHandler_ProdGuard 测试生产环境保护
+//
+// 【功能点】验证运行环境为生产环境时即使启用也不注入故障，生产环境名称可配置
+// 【测试流程】
+//  1. 运行环境为 prod（默认生产环境名称），断言请求正常返回
+//  2. 配置 prodEnv 为 production，运行环境为 production 时正常返回，运行环境为 prod 时注入故障
+func TestChaosHandler_ProdGuard(t *testing.T) {
+	tr := newChaosTestRouter(t, errorRule(1, 0), "prod")
+	assert.Equal(t, response.ResponseSuccess.GetCode(), tr.get(t, "/api/orders/1", ""))
+
+	cfg := errorRule(1, 0)
+	cfg.ProdEnv = "production"
+	tr = newChaosTestRouter(t, cfg, "production")
+	assert.Equal(t, response.ResponseSuccess.GetCode(), tr.get(t, "/api/orders/1", ""))
+
+	tr = newChaosTestRouter(t, cfg, "prod")
+	assert.Equal(t, response.ResponseExceptionRpc.GetCode(), tr.get(t, "/api/orders/1", ""))
+}
+
+// TestChaosHandler_ErrorRate 测试错误注入的比例和可复现性
+//
+// 【功能点】验证固定种子下错误响应的比例接近 errorRate × percentage，相同种子的结果完全相同
+// 【测试流程】
+//  1. errorRate 为 0.3，以种子 0-1999 发送 2000 个请求，断言错误比例在 0.3 ± 0.04 之间
+//  2. 以相同种子再次发送，断言每个请求的结果与第一次相同
+//  3. errorRate 为 1、percentage 为 50，断言错误比例在 0.5 ± 0.04 之间
+func TestChaosHandler_ErrorRate(t *testing.T) {
+	const total = 2000
+	run := func(tr *chaosTestRouter) []bool {
+		failed := make([]bool, total)
+		for i := range failed {
+			failed[i] = tr.get(t, "/api/orders/1", strconv.Itoa(i)) == response.ResponseExceptionRpc.GetCode()
+		}
+		return failed
+	}
+	ratio := func(failed []bool) float64 {
+		n := 0
+		for _, f := range failed {
+			if f {
+				n++
+			}
+		}
+		return float64(n) / float64(len(failed))
+	}
+
+	tr := newChaosTestRouter(t, errorRule(0.3, 0), "test")
+	first := run(tr)
+	assert.LessOrEqual(t, math.Abs(ratio(first)-0.3), 0.04, "ratio: %v", ratio(first))
+	assert.Equal(t, first, run(tr))
+
+	tr = newChaosTestRouter(t, errorRule(1, 50), "test")
+	assert.LessOrEqual(t, math.Abs(ratio(run(tr))-0.5), 0.04)
+}
+
+// TestChaosHandler_Latency 测试延迟注入
+//
+// 【功能点】验证匹配的请求被延迟 latencyMs + [0, latencyJitterMs]，延迟后处理函数正常执行
+// 【测试流程】配置 80ms 固定延迟和 20ms 抖动，断言请求耗时不少于 80ms 且正常返回
+func TestChaosHandler_Latency(t *testing.T) {
+	tr := newChaosTestRouter(t, config.ChaosConfig{Enabled: true, Rules: []config.ChaosRule{
+		{Path: "/api/orders/*", LatencyMs: 80, LatencyJitterMs: 20},
+	}}, "test")
+
+	start := time.Now()
+	assert.Equal(t, response.ResponseSuccess.GetCode(), tr.get(t, "/api/orders/1", "7"))
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 80*time.Millisecond)
+	assert.Less(t, elapsed, time.Second)
+	assert.Equal(t, int32(1), tr.calls.Load())
+}
+
+// TestChaosHandler_AbortConnection 测试断开连接
+//
+// 【功能点】验证 abortConnection 时客户端收到连接错误，处理函数不执行
+// 【测试流程】
+//  1. 启动真实的 HTTP 服务，请求匹配规则的路径，断言请求返回错误且处理函数未执行
+//  2. ResponseWriter 不支持 Hijack 时，断言返回 503 状态码
+func TestChaosHandler_AbortConnection(t *testing.T) {
+	tr := newChaosTestRouter(t, config.ChaosConfig{Enabled: true, Rules: []config.ChaosRule{
+		{Path: "/api/orders/*", AbortConnection: true},
+	}}, "test")
+	server := httptest.NewServer(tr.router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/orders/1")
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+	assert.Error(t, err)
+	assert.Equal(t, int32(0), tr.calls.Load())
+
+	w := httptest.NewRecorder()
+	tr.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/orders/1", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, int32(0), tr.calls.Load())
+}
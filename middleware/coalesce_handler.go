@@ -0,0 +1,196 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现请求合并中间件，并发的相同 GET 请求只执行一次处理函数
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"golang.org/x/sync/singleflight"
+)
+
+// coalescedHeader 标记响应来源的响应头：leader 表示执行了处理函数，hit 表示共享了其他请求的响应
+const coalescedHeader = "X-Coalesced"
+
+var (
+	coalescerOnce    sync.Once
+	defaultCoalescer *coalescer
+)
+
+// coalescedResponse 合并请求共享的响应
+type coalescedResponse struct {
+	status    int
+	header    http.Header
+	body      []byte
+	shareable bool      // 响应体未超过 MaxBodyBytes，可以共享给等待的请求
+	expiresAt time.Time // 缓存过期时间
+}
+
+// coalescer 合并进行中的相同请求，并按 TTL 缓存可缓存的响应
+type coalescer struct {
+	group   singleflight.Group
+	mu      sync.Mutex
+	cache   map[string]*coalescedResponse
+	ttl     time.Duration
+	maxBody int
+	perUser bool
+}
+
+// newCoalescer 创建请求合并器
+func newCoalescer(cfg *config.CoalesceConfig) *coalescer {
+	return &coalescer{
+		cache:   make(map[string]*coalescedResponse),
+		ttl:     time.Duration(cfg.TTL) * time.Millisecond,
+		maxBody: cfg.GetMaxBodyBytes(),
+		perUser: cfg.PerUser,
+	}
+}
+
+// cached 获取未过期的缓存响应
+func (co *coalescer) cached(key string) *coalescedResponse {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	resp, ok := co.cache[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(resp.expiresAt) {
+		delete(co.cache, key)
+		return nil
+	}
+	return resp
+}
+
+// store 缓存响应，同时清理已过期的缓存
+func (co *coalescer) store(key string, resp *coalescedResponse) {
+	now := time.Now()
+	resp.expiresAt = now.Add(co.ttl)
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	for k, v := range co.cache {
+		if now.After(v.expiresAt) {
+			delete(co.cache, k)
+		}
+	}
+	co.cache[key] = resp
+}
+
+// CoalesceHandler 请求合并中间件
+// 对匹配 coalesce.paths 的 GET 请求，并发的相同请求（方法、路径、排序后的查询参数相同）只执行一次处理函数，
+// 其余请求等待并共享其响应（状态码、响应头、响应体）
+// 配置项通过 app.BaseConfig.Coalesce 进行设置
+//
+// 功能特性：
+// - 执行处理函数的请求带 X-Coalesced: leader 响应头，共享响应的请求带 X-Coalesced: hit
+// - 配置 ttl 时缓存响应，缓存期内的相同请求直接返回缓存的响应
+// - 状态码 >= 400 或带 Set-Cookie 的响应只共享给进行中的请求，不缓存；Set-Cookie 不会复制给等待的请求
+// - 响应体超过 maxBodyBytes 时不共享，等待的请求各自执行处理函数
+// - 开启 perUser 时按用户（ginContext.GetUserID）区分请求，需在认证中间件之后注册
+//
+// 使用示例：
+//
+//	在配置文件中启用：
+//	coalesce:
+//	  enabled: true
+//	  ttl: 500
+//	  paths:
+//	    - "/api/stats/*"
+func CoalesceHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := app.BaseConfig.Coalesce
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+
+		coalescerOnce.Do(func() {
+			defaultCoalescer = newCoalescer(&cfg)
+		})
+		handleCoalesce(c, defaultCoalescer, cfg.Paths)
+	}
+}
+
+// handleCoalesce 合并相同的请求
+func handleCoalesce(c *gin.Context, co *coalescer, paths []string) {
+	if c.Request.Method != http.MethodGet || !matchAuditPath(c.Request.URL.Path, paths) {
+		c.Next()
+		return
+	}
+
+	key := coalesceKey(c, co.perUser)
+	if resp := co.cached(key); resp != nil {
+		writeCoalescedResponse(c, resp)
+		return
+	}
+
+	leader := false
+	result, _, _ := co.group.Do(key, func() (any, error) {
+		leader = true
+		return co.execute(c, key), nil
+	})
+	if leader {
+		return
+	}
+
+	resp := result.(*coalescedResponse)
+	if !resp.shareable {
+		c.Next()
+		return
+	}
+	writeCoalescedResponse(c, resp)
+}
+
+// execute 执行处理函数并记录响应，可缓存时写入缓存
+func (co *coalescer) execute(c *gin.Context, key string) *coalescedResponse {
+	c.Header(coalescedHeader, "leader")
+	writer := &auditBodyWriter{ResponseWriter: c.Writer, limit: co.maxBody}
+	c.Writer = writer
+	c.Next()
+
+	resp := &coalescedResponse{
+		status:    writer.Status(),
+		header:    writer.Header().Clone(),
+		shareable: writer.size <= int64(co.maxBody),
+	}
+	if resp.shareable {
+		resp.body = writer.body.Bytes()
+	}
+	cacheable := resp.shareable && resp.status < http.StatusBadRequest && resp.header.Get("Set-Cookie") == ""
+	if cacheable && co.ttl > 0 {
+		co.store(key, resp)
+	}
+	return resp
+}
+
+// coalesceKey 生成合并键，格式 "{method} {path}?{排序后的查询参数}"，开启 perUser 时追加 "#{userID}"
+func coalesceKey(c *gin.Context, perUser bool) string {
+	key := c.Request.Method + " " + c.Request.URL.Path + "?" + c.Request.URL.Query().Encode()
+	if perUser {
+		userID, _ := ginContext.GetUserID(c)
+		key += "#" + userID
+	}
+	return key
+}
+
+// writeCoalescedResponse 输出共享的响应
+// 只复制当前请求未设置的响应头，保留当前请求自身的 X-Trace-ID 等响应头，不复制 Set-Cookie
+func writeCoalescedResponse(c *gin.Context, resp *coalescedResponse) {
+	header := c.Writer.Header()
+	for name, values := range resp.header {
+		if name == "Set-Cookie" || len(header.Values(name)) > 0 {
+			continue
+		}
+		header[name] = slices.Clone(values)
+	}
+	header.Set(coalescedHeader, "hit")
+	c.Status(resp.status)
+	c.Writer.WriteHeaderNow()
+	_, _ = c.Writer.Write(resp.body)
+	c.Abort()
+}
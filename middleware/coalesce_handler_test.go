@@ -0,0 +1,208 @@
+// Package middleware 请求合并中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含请求合并中间件的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 并发的相同请求只执行一次处理函数，其余请求共享响应
+// 2. 查询参数不同的请求不合并，参数顺序不同的请求合并
+// 3. 5xx 响应共享给进行中的请求，但不缓存
+// 4. 配置 ttl 时缓存响应，带 Set-Cookie 的响应不缓存
+//
+// 运行测试：go test -v ./middleware/... -run Coalesce
+// ==================================================
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// coalesceTestRouter 请求合并测试路由
+// /api/stats 在 release 关闭前阻塞，返回 status 状态码和 "stats:{range}" 响应体
+type coalesceTestRouter struct {
+	router  *gin.Engine
+	calls   atomic.Int32
+	release chan struct{}
+	status  int
+	cookie  bool
+}
+
+// newCoalesceTestRouter 创建请求合并测试路由，release 为 nil 时处理函数不阻塞
+func newCoalesceTestRouter(cfg *config.CoalesceConfig, release chan struct{}) *coalesceTestRouter {
+	tr := &coalesceTestRouter{release: release, status: http.StatusOK}
+	co := newCoalescer(cfg)
+	gin.SetMode(gin.TestMode)
+	tr.router = gin.New()
+	tr.router.Use(func(c *gin.Context) {
+		handleCoalesce(c, co, cfg.Paths)
+	})
+	tr.router.GET("/api/stats", func(c *gin.Context) {
+		tr.calls.Add(1)
+		if tr.release != nil {
+			<-tr.release
+		}
+		if tr.cookie {
+			c.SetCookie("session", "abc", 60, "/", "", false, true)
+		}
+		c.String(tr.status, "stats:"+c.Query("range"))
+	})
+	return tr
+}
+
+// get 发送 GET 请求
+func (tr *coalesceTestRouter) get(path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	tr.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+// getConcurrently 并发发送请求，所有请求开始后等待 100ms 再放行处理函数
+func (tr *coalesceTestRouter) getConcurrently(paths []string) []*httptest.ResponseRecorder {
+	results := make([]*httptest.ResponseRecorder, len(paths))
+	var started, done sync.WaitGroup
+	for i, path := range paths {
+		started.Add(1)
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			started.Done()
+			results[i] = tr.get(path)
+		}()
+	}
+	started.Wait()
+	time.Sleep(100 * time.Millisecond)
+	close(tr.release)
+	done.Wait()
+	return results
+}
+
+// repeat 返回 n 个相同的路径
+func repeat(path string, n int) []string {
+	paths := make([]string, n)
+	for i := range paths {
+		paths[i] = path
+	}
+	return paths
+}
+
+// ==================== 测试用例 ====================
+
+// TestCoalesce_ConcurrentIdenticalRequests 测试并发的相同请求
+//
+// 【功能点】验证并发的相同请求只执行一次处理函数，其余请求共享响应
+// 【测试流程】
+//  1. 并发发送 50 个相同的请求，处理函数阻塞直到所有请求开始
+//  2. 断言处理函数只执行 1 次
+//  3. 断言所有响应相同，1 个带 X-Coalesced: leader，49 个带 X-Coalesced: hit
+func TestCoalesce_ConcurrentIdenticalRequests(t *testing.T) {
+	cfg := &config.CoalesceConfig{Enabled: true, Paths: []string{"/api/stats"}}
+	tr := newCoalesceTestRouter(cfg, make(chan struct{}))
+
+	results := tr.getConcurrently(repeat("/api/stats?range=7d", 50))
+
+	assert.Equal(t, int32(1), tr.calls.Load(), "处理函数应只执行一次")
+	headers := map[string]int{}
+	for _, w := range results {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "stats:7d", w.Body.String())
+		headers[w.Header().Get("X-Coalesced")]++
+	}
+	assert.Equal(t, map[string]int{"leader": 1, "hit": 49}, headers)
+
+	// TTL 为 0 时不缓存
+	w := tr.get("/api/stats?range=7d")
+	assert.Equal(t, int32(2), tr.calls.Load(), "未配置 ttl 时不应缓存响应")
+	assert.Equal(t, "leader", w.Header().Get("X-Coalesced"))
+}
+
+// TestCoalesce_DifferentQuery 测试查询参数不同的请求
+//
+// 【功能点】验证查询参数不同的请求不合并，参数顺序不同的请求视为相同请求
+// 【测试流程】
+//  1. 并发发送 range=1d、range=7d 各一个请求，以及参数顺序不同的 a=1&b=2、b=2&a=1
+//  2. 断言处理函数执行 3 次，各请求收到自己的响应
+//  3. 断言不匹配的路径直接放行
+func TestCoalesce_DifferentQuery(t *testing.T) {
+	cfg := &config.CoalesceConfig{Enabled: true, Paths: []string{"/api/stats"}}
+	tr := newCoalesceTestRouter(cfg, make(chan struct{}))
+
+	results := tr.getConcurrently([]string{
+		"/api/stats?range=1d",
+		"/api/stats?range=7d",
+		"/api/stats?a=1&b=2",
+		"/api/stats?b=2&a=1",
+	})
+
+	assert.Equal(t, int32(3), tr.calls.Load(), "查询参数不同的请求不应合并")
+	assert.Equal(t, "stats:1d", results[0].Body.String())
+	assert.Equal(t, "stats:7d", results[1].Body.String())
+
+	other := newCoalesceTestRouter(&config.CoalesceConfig{Enabled: true, Paths: []string{"/api/other"}}, nil)
+	w := other.get("/api/stats?range=1d")
+	assert.Empty(t, w.Header().Get("X-Coalesced"), "不匹配的路径不应合并")
+}
+
+// TestCoalesce_ErrorNotCached 测试 5xx 响应
+//
+// 【功能点】验证 5xx 响应共享给进行中的请求，但不缓存
+// 【测试流程】
+//  1. 配置 ttl 为 1 分钟，处理函数返回 500，并发发送 10 个相同的请求
+//  2. 断言处理函数执行 1 次，所有请求都收到 500
+//  3. 再次请求，断言处理函数重新执行
+func TestCoalesce_ErrorNotCached(t *testing.T) {
+	cfg := &config.CoalesceConfig{Enabled: true, Paths: []string{"/api/stats"}, TTL: 60000}
+	tr := newCoalesceTestRouter(cfg, make(chan struct{}))
+	tr.status = http.StatusInternalServerError
+
+	results := tr.getConcurrently(repeat("/api/stats?range=7d", 10))
+
+	assert.Equal(t, int32(1), tr.calls.Load())
+	for _, w := range results {
+		assert.Equal(t, http.StatusInternalServerError, w.Code, "5xx 应共享给所有等待的请求")
+	}
+
+	tr.status = http.StatusOK
+	w := tr.get("/api/stats?range=7d")
+	assert.Equal(t, int32(2), tr.calls.Load(), "5xx 响应不应缓存")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestCoalesce_TTLCache 测试响应缓存
+//
+// 【功能点】验证配置 ttl 时缓存响应，过期后重新执行；带 Set-Cookie 的响应不缓存
+// 【测试流程】
+//  1. 配置 ttl 为 100ms，连续请求两次，断言处理函数执行 1 次，第二次带 X-Coalesced: hit
+//  2. 等待缓存过期后再次请求，断言处理函数重新执行
+//  3. 处理函数设置 Cookie，连续请求两次，断言每次都执行处理函数
+func TestCoalesce_TTLCache(t *testing.T) {
+	cfg := &config.CoalesceConfig{Enabled: true, Paths: []string{"/api/stats"}, TTL: 100}
+	tr := newCoalesceTestRouter(cfg, nil)
+
+	require.Equal(t, "leader", tr.get("/api/stats?range=7d").Header().Get("X-Coalesced"))
+	w := tr.get("/api/stats?range=7d")
+	assert.Equal(t, int32(1), tr.calls.Load(), "缓存期内不应重新执行")
+	assert.Equal(t, "hit", w.Header().Get("X-Coalesced"))
+	assert.Equal(t, "stats:7d", w.Body.String())
+
+	time.Sleep(150 * time.Millisecond)
+	tr.get("/api/stats?range=7d")
+	assert.Equal(t, int32(2), tr.calls.Load(), "缓存过期后应重新执行")
+
+	tr.cookie = true
+	tr.get("/api/stats?range=1d")
+	w = tr.get("/api/stats?range=1d")
+	assert.Equal(t, int32(4), tr.calls.Load(), "带 Set-Cookie 的响应不应缓存")
+	assert.NotEmpty(t, w.Header().Get("Set-Cookie"))
+}
@@ -0,0 +1,199 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现并发限制中间件，限制同时处理中的请求数，超出限制的请求有限排队
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/metrics"
+	"github.com/zzsen/gin_core/model/response"
+	"golang.org/x/sync/semaphore"
+)
+
+// concurrencyGlobalName 全局并发限制在统计信息和指标中的名称
+const concurrencyGlobalName = "global"
+
+// ConcurrencyStat 并发限制的统计信息
+type ConcurrencyStat struct {
+	Name          string `json:"name"`           // 规则路径，全局并发限制为 global
+	MaxConcurrent int    `json:"max_concurrent"` // 最大并发数
+	MaxQueue      int    `json:"max_queue"`      // 排队请求数上限
+	InFlight      int64  `json:"in_flight"`      // 当前持有许可（处理中）的请求数
+	Queued        int64  `json:"queued"`         // 当前排队等待的请求数
+}
+
+// concurrencyLimiter 基于信号量的并发限制器，超出并发数的请求在 maxQueue 范围内排队等待许可
+type concurrencyLimiter struct {
+	name     string
+	limit    int
+	maxQueue int
+	sem      *semaphore.Weighted
+	inFlight atomic.Int64
+	queued   atomic.Int64
+}
+
+// concurrencyLimiters 最近一次创建的并发限制中间件使用的限制器（全局在前，其后为各规则），供 ConcurrencyStats 读取
+var concurrencyLimiters atomic.Pointer[[]*concurrencyLimiter]
+
+// newConcurrencyLimiter 创建并发限制器
+func newConcurrencyLimiter(name string, limit, maxQueue int) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		name:     name,
+		limit:    limit,
+		maxQueue: maxQueue,
+		sem:      semaphore.NewWeighted(int64(limit)),
+	}
+}
+
+// acquire 获取许可，没有空闲许可时排队等待，最长等待 timeout
+// 信号量按先进先出的顺序分配许可，有请求排队时新请求不会插队
+//
+// 返回：
+//   - bool: 是否获取到许可，排队已满、等待超时或请求被取消时为 false
+func (l *concurrencyLimiter) acquire(ctx context.Context, timeout time.Duration) bool {
+	if l.sem.TryAcquire(1) {
+		l.addInFlight(1)
+		return true
+	}
+	if l.queued.Add(1) > int64(l.maxQueue) {
+		l.queued.Add(-1)
+		return false
+	}
+	metrics.HttpConcurrencyQueued.WithLabelValues(l.name).Inc()
+	defer func() {
+		l.queued.Add(-1)
+		metrics.HttpConcurrencyQueued.WithLabelValues(l.name).Dec()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := l.sem.Acquire(ctx, 1); err != nil {
+		return false
+	}
+	l.addInFlight(1)
+	return true
+}
+
+// release 归还许可
+func (l *concurrencyLimiter) release() {
+	l.addInFlight(-1)
+	l.sem.Release(1)
+}
+
+// addInFlight 更新持有许可的请求数和对应的指标
+func (l *concurrencyLimiter) addInFlight(delta int64) {
+	l.inFlight.Add(delta)
+	metrics.HttpConcurrencyInFlight.WithLabelValues(l.name).Add(float64(delta))
+}
+
+// stat 获取限制器的统计信息
+func (l *concurrencyLimiter) stat() ConcurrencyStat {
+	return ConcurrencyStat{
+		Name:          l.name,
+		MaxConcurrent: l.limit,
+		MaxQueue:      l.maxQueue,
+		InFlight:      l.inFlight.Load(),
+		Queued:        l.queued.Load(),
+	}
+}
+
+// ConcurrencyLimitHandler 并发限制中间件
+// 限制同时处理中的请求数，与限流（rateLimitHandler）互补：限流控制请求速率，并发限制防止慢请求占满处理能力
+// 配置项通过 app.BaseConfig.Concurrency 进行设置，在创建中间件时读取
+//
+// 功能特性：
+// - 匹配 concurrency.rules 的请求只受该规则的并发数限制，其余请求共享全局并发数（maxConcurrent 小于等于 0 时不限制）
+// - 没有空闲许可时在 maxQueue 范围内排队，最长等待 queueTimeout 毫秒
+// - 排队已满或等待超时时返回 Retry-After 响应头和 response.ResponseServiceBusy 响应码（开启 service.useHTTPStatus 时 HTTP 状态码为 503）
+// - 许可在处理函数返回或 panic 时归还
+// - 处理中和排队的请求数可通过 ConcurrencyStats 获取，同时记录到 Prometheus 指标
+//   http_concurrency_in_flight、http_concurrency_queued
+//
+// 使用示例：
+//
+//	在配置文件中启用：
+//	concurrency:
+//	  enabled: true
+//	  maxConcurrent: 200
+//	  maxQueue: 100
+//	  queueTimeout: 2000
+//	  rules:
+//	    - path: "/api/upload/*"
+//	      maxConcurrent: 10
+func ConcurrencyLimitHandler() gin.HandlerFunc {
+	cfg := app.BaseConfig.Concurrency
+	if !cfg.Enabled {
+		concurrencyLimiters.Store(nil)
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	var global *concurrencyLimiter
+	limiters := make([]*concurrencyLimiter, 0, len(cfg.Rules)+1)
+	if cfg.MaxConcurrent > 0 {
+		global = newConcurrencyLimiter(concurrencyGlobalName, cfg.MaxConcurrent, cfg.GetMaxQueue())
+		limiters = append(limiters, global)
+	}
+	ruleLimiters := make([]*concurrencyLimiter, len(cfg.Rules))
+	for i := range cfg.Rules {
+		rule := &cfg.Rules[i]
+		if rule.MaxConcurrent <= 0 {
+			logger.Warn("[并发限制] 规则 %s 的最大并发数未配置，已忽略", rule.Path)
+			continue
+		}
+		ruleLimiters[i] = newConcurrencyLimiter(rule.Path, rule.MaxConcurrent, rule.GetMaxQueue(&cfg))
+		limiters = append(limiters, ruleLimiters[i])
+	}
+	concurrencyLimiters.Store(&limiters)
+
+	timeout := time.Duration(cfg.GetQueueTimeout()) * time.Millisecond
+	retryAfter := strconv.Itoa(max(ceilSeconds(timeout), 1))
+
+	return func(c *gin.Context) {
+		limiter := global
+		for i := range cfg.Rules {
+			if ruleLimiters[i] != nil && matchAuditPath(c.Request.URL.Path, []string{cfg.Rules[i].Path}) {
+				limiter = ruleLimiters[i]
+				break
+			}
+		}
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		if !limiter.acquire(c.Request.Context(), timeout) {
+			logger.Warn("[并发限制] 请求被拒绝, rule: %s, path: %s, inFlight: %d, queued: %d",
+				limiter.name, c.Request.URL.Path, limiter.inFlight.Load(), limiter.queued.Load())
+			c.Header("Retry-After", retryAfter)
+			response.WriteError(c, response.ResponseServiceBusy)
+			return
+		}
+		defer limiter.release()
+		c.Next()
+	}
+}
+
+// ConcurrencyStats 获取并发限制的统计信息，全局并发限制在前，其后按配置顺序为各规则
+// 未启用并发限制或尚未创建中间件时返回 nil
+//
+// 返回：
+//   - []ConcurrencyStat: 各限制器的最大并发数、排队上限、处理中和排队的请求数
+func ConcurrencyStats() []ConcurrencyStat {
+	limiters := concurrencyLimiters.Load()
+	if limiters == nil {
+		return nil
+	}
+	stats := make([]ConcurrencyStat, 0, len(*limiters))
+	for _, l := range *limiters {
+		stats = append(stats, l.stat())
+	}
+	return stats
+}
@@ -0,0 +1,205 @@
+// Package middleware 并发限制中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含并发限制中间件的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 超出并发数的请求排队，许可归还后继续处理并成功返回
+// 2. 排队已满时立即拒绝、排队超时后拒绝，返回 503、Retry-After 和 50503 响应码
+// 3. 处理函数 panic 后许可被归还
+// 4. 匹配规则的请求使用规则的并发数，不占用全局并发数
+//
+// 运行测试：go test -v ./middleware/... -run ConcurrencyLimit
+// ==================================================
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// concurrencyTestRouter 并发限制测试路由，/api/slow 和 /api/upload/* 阻塞到 gate 关闭，/api/panic 直接 panic
+type concurrencyTestRouter struct {
+	router    *gin.Engine
+	gate      chan struct{}
+	closeGate sync.Once
+}
+
+// newConcurrencyTestRouter 以指定配置创建并发限制测试路由并开启 service.useHTTPStatus，测试结束后恢复配置并放行所有阻塞的请求
+func newConcurrencyTestRouter(t *testing.T, cfg config.ConcurrencyConfig) *concurrencyTestRouter {
+	originalCfg := app.BaseConfig.Concurrency
+	t.Cleanup(func() {
+		app.BaseConfig.Concurrency = originalCfg
+		response.SetUseHTTPStatus(false)
+	})
+	app.BaseConfig.Concurrency = cfg
+	response.SetUseHTTPStatus(true)
+
+	tr := &concurrencyTestRouter{gate: make(chan struct{})}
+	t.Cleanup(tr.openGate)
+
+	gin.SetMode(gin.TestMode)
+	tr.router = gin.New()
+	tr.router.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	tr.router.Use(ConcurrencyLimitHandler())
+	block := func(c *gin.Context) {
+		<-tr.gate
+		response.Ok(c)
+	}
+	tr.router.GET("/api/slow", block)
+	tr.router.GET("/api/upload/:name", block)
+	tr.router.GET("/api/panic", func(c *gin.Context) {
+		panic("handler crashed")
+	})
+	tr.router.GET("/api/fast", func(c *gin.Context) {
+		response.Ok(c)
+	})
+	return tr
+}
+
+// openGate 放行所有阻塞的请求
+func (tr *concurrencyTestRouter) openGate() {
+	tr.closeGate.Do(func() { close(tr.gate) })
+}
+
+// serve 同步发送 GET 请求
+func (tr *concurrencyTestRouter) serve(path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	tr.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+// serveAsync 异步发送 GET 请求，返回接收响应的通道
+func (tr *concurrencyTestRouter) serveAsync(path string) <-chan *httptest.ResponseRecorder {
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() { done <- tr.serve(path) }()
+	return done
+}
+
+// waitConcurrencyStat 等待指定名称的限制器达到期望的处理中和排队请求数
+func waitConcurrencyStat(t *testing.T, name string, inFlight, queued int64) {
+	require.Eventually(t, func() bool {
+		for _, s := range ConcurrencyStats() {
+			if s.Name == name {
+				return s.InFlight == inFlight && s.Queued == queued
+			}
+		}
+		return false
+	}, 2*time.Second, 5*time.Millisecond, "%s 未达到 inFlight=%d queued=%d: %+v", name, inFlight, queued, ConcurrencyStats())
+}
+
+// assertServiceBusy 断言响应为 503、携带 Retry-After 和 50503 响应码
+func assertServiceBusy(t *testing.T, w *httptest.ResponseRecorder) {
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	var body response.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, response.ResponseServiceBusy.GetCode(), body.Code)
+}
+
+// ==================== 测试用例 ====================
+
+// TestConcurrencyLimit_QueueThenSucceed 测试排队后成功
+//
+// 【功能点】验证超出并发数的请求排队等待，许可归还后继续处理并成功返回
+// 【测试流程】
+//  1. 最大并发数 2、排队上限 1，发送 2 个阻塞请求，等待处理中请求数为 2
+//  2. 发送第 3 个请求，等待排队请求数为 1
+//  3. 放行所有请求，断言 3 个请求均返回 200，处理中和排队请求数归零
+func TestConcurrencyLimit_QueueThenSucceed(t *testing.T) {
+	tr := newConcurrencyTestRouter(t, config.ConcurrencyConfig{Enabled: true, MaxConcurrent: 2, MaxQueue: 1, QueueTimeout: 2000})
+
+	first, second := tr.serveAsync("/api/slow"), tr.serveAsync("/api/slow")
+	waitConcurrencyStat(t, concurrencyGlobalName, 2, 0)
+	third := tr.serveAsync("/api/slow")
+	waitConcurrencyStat(t, concurrencyGlobalName, 2, 1)
+
+	tr.openGate()
+	for _, done := range []<-chan *httptest.ResponseRecorder{first, second, third} {
+		assert.Equal(t, http.StatusOK, (<-done).Code)
+	}
+	waitConcurrencyStat(t, concurrencyGlobalName, 0, 0)
+}
+
+// TestConcurrencyLimit_Reject 测试拒绝请求
+//
+// 【功能点】验证排队已满时立即拒绝，排队超过 queueTimeout 后拒绝
+// 【测试流程】
+//  1. 最大并发数 1、排队上限 1、排队超时 100ms，发送 1 个阻塞请求
+//  2. 发送第 2 个请求进入排队，再发送第 3 个请求，断言立即返回 503
+//  3. 断言第 2 个请求在排队约 100ms 后返回 503
+func TestConcurrencyLimit_Reject(t *testing.T) {
+	tr := newConcurrencyTestRouter(t, config.ConcurrencyConfig{Enabled: true, MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: 100})
+
+	tr.serveAsync("/api/slow")
+	waitConcurrencyStat(t, concurrencyGlobalName, 1, 0)
+	start := time.Now()
+	queued := tr.serveAsync("/api/slow")
+	waitConcurrencyStat(t, concurrencyGlobalName, 1, 1)
+
+	assertServiceBusy(t, tr.serve("/api/slow"))
+
+	w := <-queued
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assertServiceBusy(t, w)
+	waitConcurrencyStat(t, concurrencyGlobalName, 1, 0)
+}
+
+// TestConcurrencyLimit_PanicReleasesPermit 测试 panic 后归还许可
+//
+// 【功能点】验证处理函数 panic 时许可仍被归还，不会耗尽并发数
+// 【测试流程】最大并发数 1、不排队，连续发送 3 个 panic 请求，断言之后的正常请求返回 200 且处理中请求数为 0
+func TestConcurrencyLimit_PanicReleasesPermit(t *testing.T) {
+	tr := newConcurrencyTestRouter(t, config.ConcurrencyConfig{Enabled: true, MaxConcurrent: 1})
+
+	for range 3 {
+		assert.Equal(t, http.StatusInternalServerError, tr.serve("/api/panic").Code)
+	}
+	assert.Equal(t, http.StatusOK, tr.serve("/api/fast").Code)
+	waitConcurrencyStat(t, concurrencyGlobalName, 0, 0)
+}
+
+// TestConcurrencyLimit_RuleOverride 测试规则覆盖全局并发数
+//
+// 【功能点】验证匹配规则的请求使用规则的并发数，且不占用全局并发数
+// 【测试流程】
+//  1. 全局最大并发数 1，/api/upload/* 规则最大并发数 3，均不排队
+//  2. 发送 1 个全局阻塞请求和 3 个上传请求，等待处理中请求数分别为 1 和 3
+//  3. 断言再发送的全局请求和上传请求均返回 503；放行后所有阻塞请求返回 200
+func TestConcurrencyLimit_RuleOverride(t *testing.T) {
+	tr := newConcurrencyTestRouter(t, config.ConcurrencyConfig{
+		Enabled:       true,
+		MaxConcurrent: 1,
+		Rules:         []config.ConcurrencyRule{{Path: "/api/upload/*", MaxConcurrent: 3}},
+	})
+
+	pending := []<-chan *httptest.ResponseRecorder{tr.serveAsync("/api/slow")}
+	for range 3 {
+		pending = append(pending, tr.serveAsync("/api/upload/a.bin"))
+	}
+	waitConcurrencyStat(t, concurrencyGlobalName, 1, 0)
+	waitConcurrencyStat(t, "/api/upload/*", 3, 0)
+
+	assertServiceBusy(t, tr.serve("/api/fast"))
+	assertServiceBusy(t, tr.serve("/api/upload/b.bin"))
+
+	tr.openGate()
+	for _, done := range pending {
+		assert.Equal(t, http.StatusOK, (<-done).Code)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/response"
 )
 
 // CORSHandler 跨域资源共享中间件
@@ -23,6 +24,7 @@ import (
 // - 支持配置暴露的响应头
 // - 支持配置是否允许携带凭证（Cookie）
 // - 支持配置预检请求缓存时间
+// - 来源不被允许的预检请求返回 response.ResponseOriginDenied 响应码（开启 service.useHTTPStatus 时 HTTP 状态码为 403）
 //
 // 使用示例：
 //
@@ -52,8 +54,12 @@ func CORSHandler() gin.HandlerFunc {
 			return
 		}
 
-		// 检查来源是否被允许
+		// 检查来源是否被允许，不被允许的预检请求直接拒绝
 		if !isOriginAllowed(origin, cfg.AllowOrigins) {
+			if isPreflightRequest(c.Request) {
+				response.WriteError(c, response.ResponseOriginDenied)
+				return
+			}
 			c.Next()
 			return
 		}
@@ -107,6 +113,23 @@ func CORSHandler() gin.HandlerFunc {
 	}
 }
 
+// isPreflightRequest 判断是否为预检请求（携带 Access-Control-Request-Method 请求头的 OPTIONS 请求）
+func isPreflightRequest(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// IsOriginAllowed 检查来源是否在允许列表中，匹配规则与 CORS 中间件一致
+// 供 WebSocket 等需要复用 cors.allowOrigins 配置的场景使用
+// 参数：
+//   - origin: 请求头 Origin 的值
+//   - allowOrigins: 允许的来源列表，为空时允许所有来源
+//
+// 返回：
+//   - bool: 来源是否被允许
+func IsOriginAllowed(origin string, allowOrigins []string) bool {
+	return isOriginAllowed(origin, allowOrigins)
+}
+
 // isOriginAllowed 检查来源是否被允许
 //
 // 对通配符规则（如 *.example.com），解析 origin URL 提取 hostname 后
@@ -8,7 +8,7 @@
 // 2. 允许所有来源（*）的配置
 // 3. 特定来源白名单的配置
 // 4. 通配符来源匹配（如 *.example.com）
-// 5. 预检请求（OPTIONS）的处理
+// 5. 预检请求（OPTIONS）的处理，来源不被允许的预检请求返回 ResponseOriginDenied
 // 6. 允许携带凭证的配置
 // 7. 自定义响应头的配置
 // 8. isOriginAllowed 辅助函数测试
@@ -18,6 +18,7 @@
 package middleware
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -25,6 +26,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/zzsen/gin_core/app"
 	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
 )
 
 // ==================== 测试辅助函数 ====================
@@ -269,6 +271,53 @@ func TestCORSHandler_PreflightRequest(t *testing.T) {
 	}
 }
 
+// TestCORSHandler_PreflightOriginDenied 测试来源不被允许的预检请求
+//
+// 【功能点】验证来源不被允许的预检请求返回 ResponseOriginDenied 响应码且不设置 CORS 头，非预检请求仍然放行
+// 【测试流程】
+//  1. 开启 HTTP 状态码映射，只允许 http://localhost:3000
+//  2. 以 http://evil.com 发送预检请求，验证返回 403、41030 响应码且无 Access-Control-Allow-Origin 头
+//  3. 以 http://evil.com 发送 GET 请求，验证返回 200
+func TestCORSHandler_PreflightOriginDenied(t *testing.T) {
+	cleanup := setupCORSTestConfig(config.CORSConfig{
+		Enabled:      true,
+		AllowOrigins: []string{"http://localhost:3000"},
+	})
+	defer cleanup()
+	response.SetUseHTTPStatus(true)
+	defer response.SetUseHTTPStatus(false)
+
+	router := createCORSTestRouter(CORSHandler())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("OPTIONS", "/api/data", nil)
+	req.Header.Set("Origin", "http://evil.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("期望状态码 403, 实际 %d", w.Code)
+	}
+	var resp response.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if resp.Code != response.ResponseOriginDenied.GetCode() {
+		t.Errorf("期望 code=%d, 实际 %d", response.ResponseOriginDenied.GetCode(), resp.Code)
+	}
+	if origin := w.Header().Get("Access-Control-Allow-Origin"); origin != "" {
+		t.Errorf("不允许的来源不应设置 Access-Control-Allow-Origin, 实际 %s", origin)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/test", nil)
+	req.Header.Set("Origin", "http://evil.com")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("非预检请求期望状态码 200, 实际 %d", w.Code)
+	}
+}
+
 // TestCORSHandler_DefaultMethods 测试默认允许的方法
 //
 // 【功能点】验证未配置 AllowMethods 时使用默认值
@@ -0,0 +1,204 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现 CSRF（跨站请求伪造）防护中间件
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	"github.com/zzsen/gin_core/session"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"github.com/zzsen/gin_core/utils/netutil"
+)
+
+const (
+	// csrfSessionTokenKey 启用会话时令牌在会话中的存储键
+	csrfSessionTokenKey = "_ginCore_csrfToken"
+	// csrfSessionIDKey 签发令牌时的会话 ID 在会话中的存储键，会话 ID 变化后令牌失效
+	csrfSessionIDKey = "_ginCore_csrfSessionID"
+)
+
+// CSRFHandler CSRF 防护中间件
+// 校验写请求（POST、PUT、PATCH、DELETE 等非安全方法）携带的 CSRF 令牌和请求来源，
+// 用于基于 Cookie 或会话认证、面向浏览器的表单和接口。配置项通过 app.BaseConfig.CSRF 进行设置
+//
+// 功能特性：
+// - 未启用会话时使用双重提交模式：令牌保存在 Cookie 中（可被前端读取），缺少令牌 Cookie 时签发新令牌
+// - 启用会话（sessionHandler 需注册在该中间件之前）时令牌保存在会话中，在首次调用 ginContext.CSRFToken 时生成，会话 ID 重新生成（如登录）后原令牌失效
+// - 令牌通过 headerName 请求头或 fieldName 表单字段提交，使用常量时间比较
+// - 写请求的 Origin（缺少时为 Referer）必须与请求的 Host 同源或在 trustedOrigins 中，两者均缺少时只校验令牌
+// - GET、HEAD、OPTIONS、TRACE 请求和匹配 excludePaths 的请求不校验
+// - 校验失败时返回 response.ResponseCSRFInvalid 响应码（开启 service.useHTTPStatus 时 HTTP 状态码为 403）
+//
+// 使用示例：
+//
+//	在配置文件中启用：
+//	csrf:
+//	  enabled: true
+//	  trustedOrigins:
+//	    - "https://admin.example.com"
+//	  excludePaths:
+//	    - "/webhooks/*"
+//	service:
+//	  middlewares:
+//	    - "sessionHandler"
+//	    - "csrfHandler"
+func CSRFHandler() gin.HandlerFunc {
+	cfg := app.BaseConfig.CSRF
+
+	return func(c *gin.Context) {
+		if !cfg.Enabled || matchAuditPath(c.Request.URL.Path, cfg.ExcludePaths) {
+			c.Next()
+			return
+		}
+
+		state := newCSRFState(c, &cfg)
+		ginContext.SetCSRFTokenSource(c, state.token)
+		if isCSRFSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if !isCSRFOriginTrusted(c.Request, cfg.TrustedOrigins) {
+			logger.Warn("[CSRF] 请求来源不受信任, origin: %s, referer: %s, path: %s", c.GetHeader("Origin"), c.GetHeader("Referer"), c.Request.URL.Path)
+			response.WriteError(c, response.ResponseCSRFInvalid)
+			return
+		}
+
+		submitted := c.GetHeader(cfg.GetHeaderName())
+		if submitted == "" {
+			submitted = c.PostForm(cfg.GetFieldName())
+		}
+		expected := state.current()
+		if expected == "" || submitted == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(submitted)) != 1 {
+			logger.Warn("[CSRF] CSRF 令牌缺失或不匹配, path: %s, ip: %s", c.Request.URL.Path, c.ClientIP())
+			response.WriteError(c, response.ResponseCSRFInvalid)
+			return
+		}
+		c.Next()
+	}
+}
+
+// csrfState 当前请求的 CSRF 令牌状态
+type csrfState struct {
+	c           *gin.Context
+	cfg         *config.CSRFConfig
+	sess        *session.Session // 启用会话时的会话对象，为 nil 时使用 Cookie 保存令牌
+	cookieToken string           // 请求携带的令牌 Cookie
+	issued      string           // 本次请求签发的令牌
+}
+
+// newCSRFState 创建当前请求的令牌状态
+// 未启用会话且请求未携带有效的令牌 Cookie 时立即签发新令牌，使前端在第一次请求后即可读取
+func newCSRFState(c *gin.Context, cfg *config.CSRFConfig) *csrfState {
+	state := &csrfState{c: c, cfg: cfg, sess: ginContext.GetSession(c)}
+	if state.sess != nil {
+		return state
+	}
+	if cookie, err := c.Request.Cookie(cfg.GetCookieName()); err == nil &&
+		len(cookie.Value) == base64.RawURLEncoding.EncodedLen(cfg.GetTokenLength()) {
+		state.cookieToken = cookie.Value
+	} else {
+		state.token()
+	}
+	return state
+}
+
+// current 获取请求已有的令牌，用于校验；本次请求签发的令牌不参与校验
+func (s *csrfState) current() string {
+	if s.sess == nil {
+		return s.cookieToken
+	}
+	token, _ := s.sess.Get(csrfSessionTokenKey)
+	sessionID, _ := s.sess.Get(csrfSessionIDKey)
+	if value, ok := token.(string); ok && value != "" && sessionID == s.sess.ID() {
+		return value
+	}
+	return ""
+}
+
+// token 获取当前请求的令牌，没有有效的令牌时签发新令牌
+// 启用会话时将令牌和当前会话 ID 保存到会话中，否则写入令牌 Cookie，需在写出响应前调用
+func (s *csrfState) token() string {
+	if token := s.current(); token != "" {
+		return token
+	}
+	if s.issued != "" {
+		return s.issued
+	}
+
+	s.issued = generateCSRFToken(s.cfg.GetTokenLength())
+	if s.sess != nil {
+		s.sess.Set(csrfSessionTokenKey, s.issued)
+		s.sess.Set(csrfSessionIDKey, s.sess.ID())
+		if err := s.sess.Save(); err != nil {
+			logger.Warn("[CSRF] 保存 CSRF 令牌到会话失败: %v", err)
+		}
+		return s.issued
+	}
+	http.SetCookie(s.c.Writer, &http.Cookie{
+		Name:     s.cfg.GetCookieName(),
+		Value:    s.issued,
+		Path:     "/",
+		Secure:   s.cfg.CookieSecure,
+		HttpOnly: false, // 双重提交模式下前端需要读取 Cookie 中的令牌放入请求头
+		SameSite: s.cfg.GetCookieSameSite(),
+	})
+	return s.issued
+}
+
+// generateCSRFToken 生成指定随机字节数的令牌
+func generateCSRFToken(length int) string {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("[CSRF] 生成 CSRF 令牌失败: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// isCSRFSafeMethod 判断是否为不需要校验令牌的安全方法
+func isCSRFSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// isCSRFOriginTrusted 判断写请求的来源是否受信任
+// 优先使用 Origin 请求头，缺少时使用 Referer 的来源部分；与请求同源（协议和 Host 均一致），或在 trustedOrigins 中（匹配规则与 CORS 一致）时受信任。
+// 请求的协议由 netutil.RequestScheme 判断：TLS 连接为 https，来自受信任代理（service.trustedProxies）的请求按 X-Forwarded-Proto 判断。
+// 两者均缺少时（如非浏览器客户端）视为受信任，只校验令牌；Origin 为 "null"（如沙箱 iframe）时不受信任
+func isCSRFOriginTrusted(r *http.Request, trustedOrigins []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		referer := r.Header.Get("Referer")
+		if referer == "" {
+			return true
+		}
+		u, err := url.Parse(referer)
+		if err != nil || u.Host == "" {
+			return false
+		}
+		origin = u.Scheme + "://" + u.Host
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) && strings.EqualFold(u.Scheme, netutil.RequestScheme(r)) {
+		return true
+	}
+	return len(trustedOrigins) > 0 && isOriginAllowed(origin, trustedOrigins)
+}
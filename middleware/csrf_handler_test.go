@@ -0,0 +1,396 @@
+// Package middleware CSRF 防护中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 CSRF 防护中间件的单元测试，会话使用内存存储，不需要外部依赖。
+// httptest 请求的 Host 为 example.com，同源请求的 Origin 为 http://example.com。
+//
+// 测试覆盖内容：
+// 1. 未启用会话时签发令牌 Cookie，ginContext.CSRFToken 返回同一令牌
+// 2. 请求头或表单字段携带正确令牌的写请求通过
+// 3. 缺少令牌、令牌不匹配的写请求返回 ResponseCSRFInvalid
+// 4. 安全方法和 excludePaths 匹配的路径不校验
+// 5. Origin / Referer 不同源（包括协议不同）且不在 trustedOrigins 中时拒绝，请求协议按 TLS 和受信任代理的 X-Forwarded-Proto 判断
+// 6. 启用会话时令牌保存在会话中，会话 ID 重新生成后令牌更换
+//
+// 运行测试：go test -v ./middleware/... -run CSRF
+// ==================================================
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	"github.com/zzsen/gin_core/session"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"github.com/zzsen/gin_core/utils/netutil"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// setupCSRFTestConfig 设置 CSRF 测试配置
+func setupCSRFTestConfig(cfg config.CSRFConfig) func() {
+	originalConfig := app.BaseConfig
+	app.BaseConfig = config.BaseConfig{
+		CSRF: cfg,
+	}
+	return func() {
+		app.BaseConfig = originalConfig
+	}
+}
+
+// createCSRFTestRouter 创建 CSRF 测试路由
+// manager 不为 nil 时在 CSRF 中间件之前加载会话；/token 返回当前令牌，/login 重新生成会话 ID 后返回新令牌
+func createCSRFTestRouter(manager *session.Manager) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if manager != nil {
+		router.Use(func(c *gin.Context) {
+			loadSession(c, manager)
+			c.Next()
+		})
+	}
+	router.Use(CSRFHandler())
+	router.GET("/token", func(c *gin.Context) {
+		c.String(http.StatusOK, ginContext.CSRFToken(c))
+	})
+	router.POST("/login", func(c *gin.Context) {
+		if err := ginContext.GetSession(c).RegenerateID(); err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.String(http.StatusOK, ginContext.CSRFToken(c))
+	})
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPost, http.MethodPut, http.MethodDelete} {
+		router.Handle(method, "/api/data", func(c *gin.Context) {
+			c.String(http.StatusOK, "ok")
+		})
+	}
+	router.POST("/webhooks/pay", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return router
+}
+
+// doCSRFRequest 发送请求，携带指定的 Cookie 和请求头
+func doCSRFRequest(router *gin.Engine, method, target string, cookies []*http.Cookie, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// findCookie 查找响应中指定名称的 Cookie
+func findCookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// assertCSRFRejected 断言请求被拒绝：HTTP 403，响应码为 ResponseCSRFInvalid
+func assertCSRFRejected(t *testing.T, w *httptest.ResponseRecorder, scene string) {
+	t.Helper()
+	if w.Code != http.StatusForbidden {
+		t.Errorf("%s: 期望状态码 403, 实际 %d", scene, w.Code)
+		return
+	}
+	var resp response.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s: 解析响应失败: %v", scene, err)
+	}
+	if resp.Code != response.ResponseCSRFInvalid.GetCode() {
+		t.Errorf("%s: 期望 code=%d, 实际 %d", scene, response.ResponseCSRFInvalid.GetCode(), resp.Code)
+	}
+}
+
+// issueCSRFCookie 请求 /token 获取令牌 Cookie
+func issueCSRFCookie(t *testing.T, router *gin.Engine) *http.Cookie {
+	t.Helper()
+	cookie := findCookie(doCSRFRequest(router, http.MethodGet, "/token", nil, nil), "csrf_token")
+	if cookie == nil {
+		t.Fatal("期望签发 csrf_token Cookie")
+	}
+	return cookie
+}
+
+// ==================== CSRFHandler 单元测试 ====================
+
+// TestCSRFHandler_IssueToken 测试签发令牌
+//
+// 【功能点】验证未携带令牌 Cookie 时签发令牌，Cookie 可被前端读取，ginContext.CSRFToken 返回同一令牌；已携带时不重复签发
+// 【测试流程】
+//  1. 不带 Cookie 请求 /token，断言响应 Set-Cookie 包含 csrf_token、非 HttpOnly、SameSite=Lax，且与响应体的令牌相同
+//  2. 携带该 Cookie 再次请求，断言不再下发 Cookie，响应体的令牌不变
+//  3. 未启用中间件时 ginContext.CSRFToken 返回空字符串
+func TestCSRFHandler_IssueToken(t *testing.T) {
+	cleanup := setupCSRFTestConfig(config.CSRFConfig{Enabled: true})
+	defer cleanup()
+	router := createCSRFTestRouter(nil)
+
+	w := doCSRFRequest(router, http.MethodGet, "/token", nil, nil)
+	cookie := findCookie(w, "csrf_token")
+	if cookie == nil {
+		t.Fatal("期望签发 csrf_token Cookie")
+	}
+	if cookie.Value == "" || cookie.Value != w.Body.String() {
+		t.Errorf("期望 Cookie 与 CSRFToken 相同, Cookie: %s, CSRFToken: %s", cookie.Value, w.Body.String())
+	}
+	if cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/" {
+		t.Errorf("Cookie 属性不符合预期: %+v", cookie)
+	}
+
+	w = doCSRFRequest(router, http.MethodGet, "/token", []*http.Cookie{cookie}, nil)
+	if findCookie(w, "csrf_token") != nil {
+		t.Error("已携带令牌 Cookie 时不应重复签发")
+	}
+	if w.Body.String() != cookie.Value {
+		t.Errorf("期望令牌不变, 实际 %s", w.Body.String())
+	}
+
+	app.BaseConfig.CSRF.Enabled = false
+	w = doCSRFRequest(createCSRFTestRouter(nil), http.MethodGet, "/token", nil, nil)
+	if w.Body.String() != "" || findCookie(w, "csrf_token") != nil {
+		t.Error("未启用时 CSRFToken 应返回空字符串且不签发 Cookie")
+	}
+}
+
+// TestCSRFHandler_ValidToken 测试携带正确令牌的写请求
+//
+// 【功能点】验证请求头或表单字段携带与 Cookie 相同的令牌时写请求通过，同源的 Origin 不影响
+// 【测试流程】
+//  1. 获取令牌 Cookie 后，以 X-CSRF-Token 请求头发送 POST、PUT、DELETE，断言均返回 200
+//  2. 以 _csrf 表单字段发送 POST，断言返回 200
+//  3. 配置自定义请求头名称，断言按新名称读取
+func TestCSRFHandler_ValidToken(t *testing.T) {
+	cleanup := setupCSRFTestConfig(config.CSRFConfig{Enabled: true})
+	defer cleanup()
+	router := createCSRFTestRouter(nil)
+	cookie := issueCSRFCookie(t, router)
+
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		w := doCSRFRequest(router, method, "/api/data", []*http.Cookie{cookie}, map[string]string{
+			"X-CSRF-Token": cookie.Value,
+			"Origin":       "http://example.com",
+		})
+		if w.Code != http.StatusOK {
+			t.Errorf("%s 期望状态码 200, 实际 %d", method, w.Code)
+		}
+	}
+
+	form := url.Values{"_csrf": {cookie.Value}}
+	req := httptest.NewRequest(http.MethodPost, "/api/data", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("表单字段期望状态码 200, 实际 %d", w.Code)
+	}
+
+	app.BaseConfig.CSRF.HeaderName = "X-XSRF-Token"
+	router = createCSRFTestRouter(nil)
+	w = doCSRFRequest(router, http.MethodPost, "/api/data", []*http.Cookie{cookie}, map[string]string{"X-XSRF-Token": cookie.Value})
+	if w.Code != http.StatusOK {
+		t.Errorf("自定义请求头期望状态码 200, 实际 %d", w.Code)
+	}
+}
+
+// TestCSRFHandler_InvalidToken 测试缺少令牌或令牌不匹配
+//
+// 【功能点】验证缺少令牌 Cookie、缺少提交的令牌、令牌不匹配时拒绝写请求；开关关闭时 HTTP 状态码为 200，响应码不变
+// 【测试流程】
+//  1. 开启 useHTTPStatus，分别发送不带 Cookie、不带请求头、请求头令牌错误的 POST，断言均返回 403 和 ResponseCSRFInvalid
+//  2. 不带 Cookie 的请求同时签发新令牌 Cookie
+//  3. 关闭 useHTTPStatus，断言令牌错误时返回 200 和 ResponseCSRFInvalid
+func TestCSRFHandler_InvalidToken(t *testing.T) {
+	cleanup := setupCSRFTestConfig(config.CSRFConfig{Enabled: true})
+	defer cleanup()
+	response.SetUseHTTPStatus(true)
+	defer response.SetUseHTTPStatus(false)
+	router := createCSRFTestRouter(nil)
+	cookie := issueCSRFCookie(t, router)
+
+	w := doCSRFRequest(router, http.MethodPost, "/api/data", nil, map[string]string{"X-CSRF-Token": cookie.Value})
+	assertCSRFRejected(t, w, "缺少令牌 Cookie")
+	if findCookie(w, "csrf_token") == nil {
+		t.Error("缺少令牌 Cookie 时期望签发新令牌")
+	}
+	assertCSRFRejected(t, doCSRFRequest(router, http.MethodPost, "/api/data", []*http.Cookie{cookie}, nil), "缺少提交的令牌")
+	assertCSRFRejected(t, doCSRFRequest(router, http.MethodPost, "/api/data", []*http.Cookie{cookie}, map[string]string{
+		"X-CSRF-Token": cookie.Value[1:] + "x",
+	}), "令牌不匹配")
+
+	response.SetUseHTTPStatus(false)
+	w = doCSRFRequest(router, http.MethodDelete, "/api/data", []*http.Cookie{cookie}, map[string]string{"X-CSRF-Token": "wrong"})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"code":41031`) {
+		t.Errorf("开关关闭时期望 200 和 41031, 实际 %d %s", w.Code, w.Body.String())
+	}
+}
+
+// TestCSRFHandler_SafeMethodsAndExcludePaths 测试不校验的请求
+//
+// 【功能点】验证 GET、HEAD、OPTIONS 请求和匹配 excludePaths 的写请求不校验令牌
+// 【测试流程】
+//  1. 配置 excludePaths=/webhooks/*
+//  2. 不带令牌发送 GET、HEAD、OPTIONS /api/data，断言均返回 200
+//  3. 不带令牌、Origin 为其他站点发送 POST /webhooks/pay，断言返回 200 且不签发令牌 Cookie
+func TestCSRFHandler_SafeMethodsAndExcludePaths(t *testing.T) {
+	cleanup := setupCSRFTestConfig(config.CSRFConfig{Enabled: true, ExcludePaths: []string{"/webhooks/*"}})
+	defer cleanup()
+	router := createCSRFTestRouter(nil)
+
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodOptions} {
+		if w := doCSRFRequest(router, method, "/api/data", nil, nil); w.Code != http.StatusOK {
+			t.Errorf("%s 期望状态码 200, 实际 %d", method, w.Code)
+		}
+	}
+
+	w := doCSRFRequest(router, http.MethodPost, "/webhooks/pay", nil, map[string]string{"Origin": "https://pay.example.net"})
+	if w.Code != http.StatusOK {
+		t.Errorf("排除的路径期望状态码 200, 实际 %d", w.Code)
+	}
+	if findCookie(w, "csrf_token") != nil {
+		t.Error("排除的路径不应签发令牌")
+	}
+}
+
+// TestCSRFHandler_Origin 测试来源校验
+//
+// 【功能点】验证令牌正确时，Origin 或 Referer 与请求不同源且不在 trustedOrigins 中的写请求被拒绝
+// 【测试流程】
+//  1. 配置 trustedOrigins=[https://admin.example.org, *.trusted.com]，获取令牌 Cookie
+//  2. 断言 Origin 为 http://evil.com、"null"，Referer 为 http://evil.com/page 时拒绝
+//  3. 断言同源 Origin、同源 Referer、trustedOrigins 精确匹配和通配符匹配，以及两者均缺少时通过
+func TestCSRFHandler_Origin(t *testing.T) {
+	cleanup := setupCSRFTestConfig(config.CSRFConfig{
+		Enabled:        true,
+		TrustedOrigins: []string{"https://admin.example.org", "*.trusted.com"},
+	})
+	defer cleanup()
+	router := createCSRFTestRouter(nil)
+	cookie := issueCSRFCookie(t, router)
+
+	post := func(header, value string) *httptest.ResponseRecorder {
+		headers := map[string]string{"X-CSRF-Token": cookie.Value}
+		if header != "" {
+			headers[header] = value
+		}
+		return doCSRFRequest(router, http.MethodPost, "/api/data", []*http.Cookie{cookie}, headers)
+	}
+
+	response.SetUseHTTPStatus(true)
+	defer response.SetUseHTTPStatus(false)
+	assertCSRFRejected(t, post("Origin", "http://evil.com"), "不受信任的 Origin")
+	assertCSRFRejected(t, post("Origin", "null"), "Origin 为 null")
+	assertCSRFRejected(t, post("Referer", "http://evil.com/page"), "不受信任的 Referer")
+
+	for _, tc := range []struct{ header, value string }{
+		{"Origin", "http://example.com"},
+		{"Referer", "http://example.com/form"},
+		{"Origin", "https://admin.example.org"},
+		{"Origin", "https://app.trusted.com"},
+		{"", ""},
+	} {
+		if w := post(tc.header, tc.value); w.Code != http.StatusOK {
+			t.Errorf("%s=%s 期望状态码 200, 实际 %d", tc.header, tc.value, w.Code)
+		}
+	}
+}
+
+// TestCSRFHandler_OriginScheme 测试同源判断比较协议
+//
+// 【功能点】验证 Host 相同但协议不同的 Origin 不视为同源；受信任代理通过 X-Forwarded-Proto 声明的协议参与比较，不受信任的直连地址伪造的无效
+// 【测试流程】
+//  1. 普通 HTTP 请求携带 Origin https://example.com，断言被拒绝
+//  2. 信任 httptest 的直连地址 192.0.2.1，X-Forwarded-Proto: https 时 Origin http://example.com 被拒绝、https://example.com 通过
+//  3. 取消信任后伪造 X-Forwarded-Proto: https，断言 Origin https://example.com 被拒绝
+func TestCSRFHandler_OriginScheme(t *testing.T) {
+	cleanup := setupCSRFTestConfig(config.CSRFConfig{Enabled: true})
+	defer cleanup()
+	router := createCSRFTestRouter(nil)
+	cookie := issueCSRFCookie(t, router)
+
+	post := func(origin, proto string) *httptest.ResponseRecorder {
+		headers := map[string]string{"X-CSRF-Token": cookie.Value, "Origin": origin}
+		if proto != "" {
+			headers["X-Forwarded-Proto"] = proto
+		}
+		return doCSRFRequest(router, http.MethodPost, "/api/data", []*http.Cookie{cookie}, headers)
+	}
+
+	response.SetUseHTTPStatus(true)
+	defer response.SetUseHTTPStatus(false)
+	assertCSRFRejected(t, post("https://example.com", ""), "HTTP 请求的 https Origin")
+
+	if err := netutil.SetTrustedProxies([]string{"192.0.2.1"}); err != nil {
+		t.Fatalf("设置受信任代理失败: %v", err)
+	}
+	defer func() { _ = netutil.SetTrustedProxies(nil) }()
+	assertCSRFRejected(t, post("http://example.com", "https"), "HTTPS 部署的 http Origin")
+	if w := post("https://example.com", "https"); w.Code != http.StatusOK {
+		t.Errorf("受信任代理声明 https 时同源 Origin 期望状态码 200, 实际 %d", w.Code)
+	}
+
+	_ = netutil.SetTrustedProxies(nil)
+	assertCSRFRejected(t, post("https://example.com", "https"), "伪造的 X-Forwarded-Proto")
+}
+
+// TestCSRFHandler_SessionRotation 测试启用会话时的令牌
+//
+// 【功能点】验证启用会话时令牌保存在会话中而不签发令牌 Cookie，会话 ID 重新生成后原令牌失效、返回新令牌
+// 【测试流程】
+//  1. 使用内存会话存储，请求 /token 获取令牌 T1 和会话 Cookie，断言未下发 csrf_token Cookie
+//  2. 携带会话 Cookie 和 T1 请求 /login（重新生成会话 ID），断言通过并返回不同的令牌 T2
+//  3. 使用新的会话 Cookie 携带 T1 发送 POST，断言被拒绝；携带 T2 时通过
+func TestCSRFHandler_SessionRotation(t *testing.T) {
+	cleanup := setupCSRFTestConfig(config.CSRFConfig{Enabled: true})
+	defer cleanup()
+	store := session.NewMemoryStore(time.Minute)
+	defer store.Close()
+	manager := session.NewManager(store, session.Options{CookieName: "sid", Path: "/", MaxAge: 3600, HttpOnly: true})
+	router := createCSRFTestRouter(manager)
+
+	w := doCSRFRequest(router, http.MethodGet, "/token", nil, nil)
+	t1 := w.Body.String()
+	sid := findCookie(w, "sid")
+	if t1 == "" || sid == nil {
+		t.Fatalf("期望返回令牌并下发会话 Cookie, 令牌: %q", t1)
+	}
+	if findCookie(w, "csrf_token") != nil {
+		t.Error("启用会话时不应签发令牌 Cookie")
+	}
+
+	w = doCSRFRequest(router, http.MethodPost, "/login", []*http.Cookie{sid}, map[string]string{"X-CSRF-Token": t1})
+	if w.Code != http.StatusOK {
+		t.Fatalf("登录期望状态码 200, 实际 %d", w.Code)
+	}
+	t2 := w.Body.String()
+	newSID := findCookie(w, "sid")
+	if t2 == "" || t2 == t1 || newSID == nil || newSID.Value == sid.Value {
+		t.Fatalf("期望重新生成会话 ID 后返回新令牌, T1: %s, T2: %s", t1, t2)
+	}
+
+	response.SetUseHTTPStatus(true)
+	defer response.SetUseHTTPStatus(false)
+	assertCSRFRejected(t, doCSRFRequest(router, http.MethodPost, "/api/data", []*http.Cookie{newSID}, map[string]string{"X-CSRF-Token": t1}), "会话 ID 重新生成前的令牌")
+	if w := doCSRFRequest(router, http.MethodPost, "/api/data", []*http.Cookie{newSID}, map[string]string{"X-CSRF-Token": t2}); w.Code != http.StatusOK {
+		t.Errorf("新令牌期望状态码 200, 实际 %d", w.Code)
+	}
+}
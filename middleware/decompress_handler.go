@@ -0,0 +1,100 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现请求体解压中间件，解压 Content-Encoding 为 gzip / deflate 的请求体
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// errDecompressedTooLarge 解压后的请求体超过大小限制
+var errDecompressedTooLarge = errors.New("解压后的请求体超过大小限制")
+
+// DecompressHandler 请求体解压中间件
+// 请求头 Content-Encoding 为 gzip / deflate 时解压请求体，后续的参数绑定、ginContext.Get 读取到的是解压后的内容
+// 配置项通过 app.BaseConfig.Decompress 进行设置
+//
+// 功能特性：
+// - 解压后移除 Content-Encoding 请求头，并将 Content-Length 更新为解压后的长度
+// - 解压后的请求体超过 decompress.maxBytes 时返回 response.ResponsePayloadTooLarge 响应码（开启 service.useHTTPStatus 时 HTTP 状态码为 413），防止压缩炸弹
+// - 压缩数据损坏时返回参数校验不通过
+// - 没有 Content-Encoding、为 identity 或其他编码时直接放行，不修改请求
+//
+// 使用示例：
+//
+//	在配置文件中启用：
+//	service:
+//	  middlewares:
+//	    - "decompressHandler"
+//	decompress:
+//	  maxBytes: 10485760
+func DecompressHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handleDecompress(c, app.BaseConfig.Decompress.GetMaxBytes())
+	}
+}
+
+// handleDecompress 解压请求体，maxBytes 为解压后的最大字节数
+func handleDecompress(c *gin.Context, maxBytes int64) {
+	encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+	if encoding != "gzip" && encoding != "x-gzip" && encoding != "deflate" {
+		c.Next()
+		return
+	}
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		c.Request.Header.Del("Content-Encoding")
+		c.Next()
+		return
+	}
+
+	body, err := decompressBody(c.Request.Body, encoding, maxBytes)
+	_ = c.Request.Body.Close()
+	if errors.Is(err, errDecompressedTooLarge) {
+		response.WriteError(c, response.ResponsePayloadTooLarge)
+		return
+	}
+	if err != nil {
+		response.WriteError(c, response.ResponseParamInvalid, response.WithMessage("请求体解压失败"))
+		return
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	c.Request.Header.Del("Content-Encoding")
+	c.Request.Header.Del("Content-Length")
+	c.Next()
+}
+
+// decompressBody 读取并解压请求体，解压后超过 maxBytes 时返回 errDecompressedTooLarge
+// 只读取到 maxBytes+1 字节即停止，不会把整个压缩炸弹解压到内存
+func decompressBody(body io.Reader, encoding string, maxBytes int64) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	if encoding == "deflate" {
+		reader, err = zlib.NewReader(body)
+	} else {
+		reader, err = gzip.NewReader(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, errDecompressedTooLarge
+	}
+	return data, nil
+}
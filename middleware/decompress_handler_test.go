@@ -0,0 +1,182 @@
+// Package middleware 请求体解压中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含请求体解压中间件的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. gzip / deflate 压缩的 JSON 请求体能正常绑定，ginContext.Get 可以读取字段
+// 2. 压缩数据损坏时返回参数校验不通过
+// 3. 解压后超过大小限制的请求体（压缩炸弹）返回 413
+// 4. 没有 Content-Encoding 的请求不受影响
+//
+// 运行测试：go test -v ./middleware/... -run Decompress
+// ==================================================
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// createDecompressTestRouter 创建请求体解压测试路由
+// /api/orders 绑定 JSON 请求体，返回绑定的 name、ginContext.Get 读取的 name 和请求头中的 Content-Encoding
+func createDecompressTestRouter(maxBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		handleDecompress(c, maxBytes)
+	})
+	router.POST("/api/orders", func(c *gin.Context) {
+		name := ginContext.Get(c, "name")
+		var req struct {
+			Name string `json:"name" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.FailWithMessage(c, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"bound":    req.Name,
+			"get":      name,
+			"encoding": c.GetHeader("Content-Encoding"),
+		})
+	})
+	return router
+}
+
+// compress 按 encoding 压缩数据
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	if encoding == "deflate" {
+		w = zlib.NewWriter(&buf)
+	} else {
+		w = gzip.NewWriter(&buf)
+	}
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// postEncoded 发送带 Content-Encoding 的 POST 请求，encoding 为空时不设置
+func postEncoded(router *gin.Engine, encoding string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/orders", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// ==================== 测试用例 ====================
+
+// TestDecompress_BindsCompressedBody 测试压缩的请求体
+//
+// 【功能点】验证 gzip / deflate 压缩的请求体解压后能正常绑定
+// 【测试流程】
+//  1. 分别发送 gzip、deflate 压缩的 JSON 请求体
+//  2. 断言 ShouldBindJSON 和 ginContext.Get 都读取到 name，Content-Encoding 请求头已移除
+func TestDecompress_BindsCompressedBody(t *testing.T) {
+	router := createDecompressTestRouter(1 << 20)
+	for _, encoding := range []string{"gzip", "deflate"} {
+		t.Run(encoding, func(t *testing.T) {
+			w := postEncoded(router, encoding, compress(t, encoding, []byte(`{"name":"alice"}`)))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var result map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Equal(t, "alice", result["bound"])
+			assert.Equal(t, "alice", result["get"])
+			assert.Empty(t, result["encoding"], "解压后应移除 Content-Encoding 请求头")
+		})
+	}
+}
+
+// TestDecompress_CorruptBody 测试损坏的压缩数据
+//
+// 【功能点】验证压缩数据损坏时返回参数校验不通过，不执行处理函数
+// 【测试流程】
+//  1. 发送不是 gzip 格式的请求体，断言返回 53001
+//  2. 发送被截断的 gzip 数据，断言返回 53001
+func TestDecompress_CorruptBody(t *testing.T) {
+	router := createDecompressTestRouter(1 << 20)
+	compressed := compress(t, "gzip", []byte(`{"name":"alice"}`))
+
+	for name, body := range map[string][]byte{
+		"非 gzip 格式": []byte(`{"name":"alice"}`),
+		"数据被截断":     compressed[:len(compressed)-6],
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := postEncoded(router, "gzip", body)
+			var resp response.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, response.ResponseParamInvalid.GetCode(), resp.Code)
+		})
+	}
+}
+
+// TestDecompress_TooLarge 测试压缩炸弹
+//
+// 【功能点】验证解压后超过大小限制的请求体返回 413，且不会被完整解压
+// 【测试流程】
+//  1. 构造解压后为 100MB 的 gzip 请求体（压缩后约 100KB），限制为 1MB
+//  2. 断言返回 413 和 50413 响应码
+//  3. 解压后恰好等于限制的请求体正常放行
+func TestDecompress_TooLarge(t *testing.T) {
+	response.SetUseHTTPStatus(true)
+	defer response.SetUseHTTPStatus(false)
+	router := createDecompressTestRouter(1 << 20)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	chunk := make([]byte, 1<<20)
+	for i := 0; i < 100; i++ {
+		_, err := gz.Write(chunk)
+		require.NoError(t, err)
+	}
+	require.NoError(t, gz.Close())
+
+	w := postEncoded(router, "gzip", buf.Bytes())
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	var resp response.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, response.ResponsePayloadTooLarge.GetCode(), resp.Code)
+
+	body := []byte(`{"name":"` + strings.Repeat("a", 100-11) + `"}`)
+	require.Len(t, body, 100)
+	w = postEncoded(createDecompressTestRouter(100), "gzip", compress(t, "gzip", body))
+	assert.Equal(t, http.StatusOK, w.Code, "解压后等于限制时应放行")
+}
+
+// TestDecompress_Identity 测试未压缩的请求
+//
+// 【功能点】验证没有 Content-Encoding 或为 identity 的请求不受影响
+// 【测试流程】
+//  1. 分别发送不带 Content-Encoding、Content-Encoding 为 identity 的请求
+//  2. 断言正常绑定
+func TestDecompress_Identity(t *testing.T) {
+	router := createDecompressTestRouter(10)
+	for _, encoding := range []string{"", "identity"} {
+		w := postEncoded(router, encoding, []byte(`{"name":"alice"}`))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"bound":"alice"`)
+	}
+}
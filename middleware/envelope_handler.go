@@ -0,0 +1,179 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现统一响应结构检查中间件，用于在开发、测试环境中发现绕过统一响应结构的处理函数
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/constant"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"github.com/zzsen/gin_core/utils/jsoncodec"
+)
+
+// envelopeSnippetBytes 错误日志中记录的响应体最大字节数
+const envelopeSnippetBytes = 256
+
+// EnvelopeHandler 统一响应结构检查中间件
+// 开启 service.enforceEnvelope 时由框架注册在用户配置的中间件之后，检查路由前缀下处理函数输出的 JSON 响应
+// 是否恰好包含 service.envelope.keys（默认 code、msg、data）这些顶层字段
+//
+// 功能特性：
+// - 不符合时输出错误日志，包含路由、处理函数名称、原因和响应体片段（最多 256 字节）
+// - 开启 service.envelope.strictMode 时将响应替换为 502 和 response.ResponseEnvelopeInvalid 响应码的统一响应
+// - 非 JSON 响应（文件下载、SSE 等）、调用 Flush 的流式响应和没有响应体的响应不检查
+// - service.envelope.allowlist 中的路径和调用 ginContext.SkipEnvelope 的请求不检查
+//
+// 检查需要缓冲 JSON 响应体，仅建议在开发、测试环境开启，生产环境开启时启动时输出警告
+//
+// 使用示例：
+//
+//	service:
+//	  enforceEnvelope: true
+//	  envelope:
+//	    strictMode: true
+//	    allowlist: ["/api/files/*", "/api/webhooks/*"]
+func EnvelopeHandler() gin.HandlerFunc {
+	serviceCfg := app.BaseConfig.Service
+	cfg := serviceCfg.Envelope
+	keys := cfg.GetKeys()
+	prefix := strings.TrimSuffix(path.Join("/", serviceCfg.RoutePrefix), "/")
+	if app.Env == constant.ProdEnv {
+		logger.Warn("[envelope] 当前运行环境为 %s，统一响应结构检查会缓冲 JSON 响应体，建议只在开发、测试环境开启", app.Env)
+	}
+
+	return func(c *gin.Context) {
+		requestPath := c.Request.URL.Path
+		if prefix != "" && requestPath != prefix && !strings.HasPrefix(requestPath, prefix+"/") {
+			c.Next()
+			return
+		}
+		if matchAuditPath(requestPath, cfg.Allowlist) {
+			c.Next()
+			return
+		}
+
+		writer := &envelopeWriter{cacheBodyWriter: cacheBodyWriter{ResponseWriter: c.Writer, limit: math.MaxInt, status: c.Writer.Status()}}
+		c.Writer = writer
+		defer func() {
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Next()
+
+		if writer.passthrough || writer.buf.Len() == 0 || ginContext.IsSkipEnvelope(c) {
+			_ = writer.flush()
+			return
+		}
+		body := writer.buf.Bytes()
+		reason := envelopeViolation(body, keys)
+		if reason == "" {
+			_ = writer.flush()
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = requestPath
+		}
+		logger.Error("[envelope] 响应不符合统一响应结构（%s）, route: %s %s, handler: %s, body: %s",
+			reason, c.Request.Method, route, c.HandlerName(), envelopeSnippet(body))
+		if cfg.StrictMode {
+			replaced, err := jsoncodec.Marshal(response.Response{
+				Code: response.ResponseEnvelopeInvalid.GetCode(),
+				Data: map[string]any{"route": route, "handler": c.HandlerName(), "reason": reason},
+				Msg:  response.Localize(c, response.ResponseEnvelopeInvalid.GetCode(), response.ResponseEnvelopeInvalid.GetMsg()),
+			})
+			if err == nil {
+				writer.buf.Reset()
+				writer.buf.Write(replaced)
+				writer.status = http.StatusBadGateway
+				writer.Header().Set("Content-Type", gin.MIMEJSON+"; charset=utf-8")
+			}
+		}
+		_ = writer.flush()
+	}
+}
+
+// envelopeWriter 统一响应结构检查的响应缓冲写入器
+// 首次写入响应体时 Content-Type 为 JSON 则缓冲到处理函数结束，否则直接写给客户端；调用 Flush 后也直接写给客户端
+type envelopeWriter struct {
+	cacheBodyWriter
+	decided bool // 是否已按 Content-Type 决定是否缓冲
+}
+
+// Write 首次写入时按 Content-Type 决定是否缓冲
+func (w *envelopeWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		if !isJSONContentType(w.Header().Get("Content-Type")) {
+			if err := w.flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return w.cacheBodyWriter.Write(b)
+}
+
+// WriteString 写入字符串
+func (w *envelopeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// isJSONContentType 判断内容类型是否为 JSON（application/json 或 +json 后缀）
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == gin.MIMEJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+// envelopeViolation 检查响应体是否为恰好包含 keys 的 JSON 对象
+// 返回：
+//   - string: 不符合的原因，符合时为空
+func envelopeViolation(body []byte, keys []string) string {
+	var fields map[string]json.RawMessage
+	if err := jsoncodec.Unmarshal(body, &fields); err != nil || fields == nil {
+		return "响应体不是 JSON 对象"
+	}
+	var missing, extra []string
+	for _, key := range keys {
+		if _, ok := fields[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	for key := range fields {
+		if !slices.Contains(keys, key) {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+
+	var reasons []string
+	if len(missing) > 0 {
+		reasons = append(reasons, fmt.Sprintf("缺少字段 %v", missing))
+	}
+	if len(extra) > 0 {
+		reasons = append(reasons, fmt.Sprintf("多余字段 %v", extra))
+	}
+	return strings.Join(reasons, "，")
+}
+
+// envelopeSnippet 截取响应体的前 envelopeSnippetBytes 字节用于日志，去掉被截断的不完整字符
+func envelopeSnippet(body []byte) string {
+	if len(body) <= envelopeSnippetBytes {
+		return string(body)
+	}
+	return strings.ToValidUTF8(string(body[:envelopeSnippetBytes]), "") + "..."
+}
@@ -0,0 +1,207 @@
+// Package middleware 统一响应结构检查中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含统一响应结构检查中间件的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 使用统一响应结构的处理函数不记录日志，响应不变
+// 2. 返回 gin.H{"message": ...} 的处理函数记录包含路由、处理函数名称和响应体片段的错误日志
+// 3. allowlist 中的路径、ginContext.SkipEnvelope 标记的请求、路由前缀以外的路径、非 JSON 响应和流式响应不检查
+// 4. strictMode 时将不符合的响应替换为 502 统一响应
+// 5. 自定义顶层字段
+//
+// 运行测试：go test -v ./middleware/... -run Envelope
+// ==================================================
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// newEnvelopeTestRouter 以指定配置创建统一响应结构检查测试路由，测试结束后恢复配置
+func newEnvelopeTestRouter(t *testing.T, cfg config.EnvelopeConfig) *gin.Engine {
+	originalCfg := app.BaseConfig.Service
+	t.Cleanup(func() { app.BaseConfig.Service = originalCfg })
+	app.BaseConfig.Service.RoutePrefix = "/api"
+	app.BaseConfig.Service.EnforceEnvelope = true
+	app.BaseConfig.Service.Envelope = cfg
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(EnvelopeHandler())
+	router.GET("/api/orders/:id", func(c *gin.Context) {
+		response.OkWithData(c, gin.H{"id": c.Param("id")})
+	})
+	router.GET("/api/raw", envelopeRawHandler)
+	router.GET("/api/webhooks/pay", envelopeRawHandler)
+	router.GET("/health", envelopeRawHandler)
+	router.GET("/api/notify", func(c *gin.Context) {
+		ginContext.SkipEnvelope(c)
+		envelopeRawHandler(c)
+	})
+	router.GET("/api/files/report.csv", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/csv", []byte("id,name\n1,a\n"))
+	})
+	router.GET("/api/events", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Writer.WriteHeaderNow()
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString("data: {\"message\":\"tick\"}\n\n")
+	})
+	router.GET("/api/stream", func(c *gin.Context) {
+		c.Header("Content-Type", gin.MIMEJSON)
+		_, _ = c.Writer.WriteString(`{"message":`)
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString(`"tick"}`)
+	})
+	return router
+}
+
+// envelopeRawHandler 绕过统一响应结构的处理函数
+func envelopeRawHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// serveEnvelope 发送 GET 请求并返回响应和统一响应结构检查的错误日志
+func serveEnvelope(router *gin.Engine, path string) (*httptest.ResponseRecorder, []string) {
+	hook := logtest.NewLocal(logger.Logger)
+	defer hook.Reset()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	router.ServeHTTP(w, req)
+
+	var logs []string
+	for _, entry := range hook.AllEntries() {
+		if strings.HasPrefix(entry.Message, "[envelope]") {
+			logs = append(logs, entry.Message)
+		}
+	}
+	return w, logs
+}
+
+// ==================== 测试用例 ====================
+
+// TestEnvelopeHandler_Compliant 测试符合统一响应结构的响应
+//
+// 【功能点】验证使用 response 包输出的响应不记录日志，响应不变
+// 【测试流程】请求 /api/orders/42，断言状态码 200、响应体为统一响应结构且没有错误日志
+func TestEnvelopeHandler_Compliant(t *testing.T) {
+	router := newEnvelopeTestRouter(t, config.EnvelopeConfig{})
+
+	w, logs := serveEnvelope(router, "/api/orders/42")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"code":20000,"msg":"操作成功","data":{"id":"42"}}`, w.Body.String())
+	assert.Empty(t, logs)
+}
+
+// TestEnvelopeHandler_Violation 测试不符合统一响应结构的响应
+//
+// 【功能点】验证返回 gin.H{"message": ...} 时记录错误日志，非严格模式下响应不变
+// 【测试流程】请求 /api/raw，断言响应体不变，错误日志包含缺少和多余的字段、路由、处理函数名称和响应体
+func TestEnvelopeHandler_Violation(t *testing.T) {
+	router := newEnvelopeTestRouter(t, config.EnvelopeConfig{})
+
+	w, logs := serveEnvelope(router, "/api/raw")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"message":"ok"}`, w.Body.String())
+	require.Len(t, logs, 1)
+	assert.Contains(t, logs[0], "缺少字段 [code msg data]")
+	assert.Contains(t, logs[0], "多余字段 [message]")
+	assert.Contains(t, logs[0], "route: GET /api/raw")
+	assert.Contains(t, logs[0], "envelopeRawHandler")
+	assert.Contains(t, logs[0], `body: {"message":"ok"}`)
+}
+
+// TestEnvelopeHandler_Exempt 测试不检查的响应
+//
+// 【功能点】验证 allowlist 中的路径、ginContext.SkipEnvelope 标记的请求、路由前缀以外的路径、非 JSON 响应和流式响应不检查
+// 【测试流程】
+//  1. 配置 allowlist 为 /api/webhooks/*，请求 /api/webhooks/pay、/health 和调用 ginContext.SkipEnvelope 的 /api/notify，断言响应不变且没有错误日志
+//  2. 请求返回 CSV 的 /api/files/report.csv，断言响应不变且没有错误日志
+//  3. 请求 SSE 接口和调用 Flush 的 JSON 流式接口，断言响应完整输出且没有错误日志
+func TestEnvelopeHandler_Exempt(t *testing.T) {
+	router := newEnvelopeTestRouter(t, config.EnvelopeConfig{StrictMode: true, Allowlist: []string{"/api/webhooks/*"}})
+
+	for _, path := range []string{"/api/webhooks/pay", "/health", "/api/notify"} {
+		w, logs := serveEnvelope(router, path)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.JSONEq(t, `{"message":"ok"}`, w.Body.String(), path)
+		assert.Empty(t, logs, path)
+	}
+
+	w, logs := serveEnvelope(router, "/api/files/report.csv")
+	assert.Equal(t, "id,name\n1,a\n", w.Body.String())
+	assert.Empty(t, logs)
+
+	w, logs = serveEnvelope(router, "/api/events")
+	assert.Equal(t, "data: {\"message\":\"tick\"}\n\n", w.Body.String())
+	assert.Empty(t, logs)
+
+	w, logs = serveEnvelope(router, "/api/stream")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"message":"tick"}`, w.Body.String())
+	assert.Empty(t, logs)
+}
+
+// TestEnvelopeHandler_StrictMode 测试严格模式
+//
+// 【功能点】验证 strictMode 时将不符合的响应替换为 502 统一响应，符合的响应不变
+// 【测试流程】
+//  1. 请求 /api/raw，断言状态码 502，响应体为 90003 响应码的统一响应，data 包含路由、处理函数名称和原因
+//  2. 请求 /api/orders/42，断言响应不变
+func TestEnvelopeHandler_StrictMode(t *testing.T) {
+	router := newEnvelopeTestRouter(t, config.EnvelopeConfig{StrictMode: true})
+
+	w, logs := serveEnvelope(router, "/api/raw")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Len(t, logs, 1)
+	var resp struct {
+		Code int            `json:"code"`
+		Msg  string         `json:"msg"`
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, response.ResponseEnvelopeInvalid.GetCode(), resp.Code)
+	assert.Equal(t, "响应格式不符合统一响应结构", resp.Msg)
+	assert.Equal(t, "/api/raw", resp.Data["route"])
+	assert.Contains(t, resp.Data["handler"], "envelopeRawHandler")
+	assert.Contains(t, resp.Data["reason"], "多余字段 [message]")
+	assert.NotContains(t, w.Body.String(), `"message":"ok"`)
+
+	w, logs = serveEnvelope(router, "/api/orders/42")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"code":20000,"msg":"操作成功","data":{"id":"42"}}`, w.Body.String())
+	assert.Empty(t, logs)
+}
+
+// TestEnvelopeHandler_CustomKeys 测试自定义顶层字段
+//
+// 【功能点】验证配置 keys 后按配置的字段检查
+// 【测试流程】配置 keys 为 ["message"]，请求 /api/raw 断言没有错误日志，请求 /api/orders/42 断言记录错误日志
+func TestEnvelopeHandler_CustomKeys(t *testing.T) {
+	router := newEnvelopeTestRouter(t, config.EnvelopeConfig{Keys: []string{"message"}})
+
+	_, logs := serveEnvelope(router, "/api/raw")
+	assert.Empty(t, logs)
+
+	_, logs = serveEnvelope(router, "/api/orders/42")
+	require.Len(t, logs, 1)
+	assert.Contains(t, logs[0], "缺少字段 [message]")
+	assert.Contains(t, logs[0], "多余字段 [code data msg]")
+}
@@ -3,25 +3,67 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
-	"net/http"
+	"runtime"
 	"runtime/debug"
+	"strings"
+	"sync"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/zzsen/gin_core/app"
 	"github.com/zzsen/gin_core/exception"
 	"github.com/zzsen/gin_core/logger"
 	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
 
 	"github.com/gin-gonic/gin"
 )
 
+// PanicHook 未处理异常的回调
+// 参数：
+//   - ctx: 请求上下文的副本（gin.Context.Copy），可在回调中安全读取请求信息和上下文数据
+//   - recovered: recover 得到的原始 panic 值
+//   - stack: 发生异常的协程的完整堆栈（runtime/debug.Stack）
+type PanicHook func(ctx *gin.Context, recovered any, stack []byte)
+
+var (
+	// panicHooksMu 保护 panicHooks 的并发访问
+	panicHooksMu sync.RWMutex
+	// panicHooks 已注册的未处理异常回调
+	panicHooks []PanicHook
+)
+
+// panicSkipFramePrefixes 记录异常日志时过滤的堆栈帧（按函数全名前缀匹配）
+var panicSkipFramePrefixes = []string{
+	"runtime.",
+	"github.com/gin-gonic/gin.",
+	"net/http.",
+}
+
+// OnPanic 注册未处理异常的回调，用于将异常转发到 Sentry 或内部的异常跟踪系统
+// 回调只对未实现 exception.Handler 的异常（即记录"未处理的异常"日志的异常）触发，
+// 在响应写出后于独立协程中按注册顺序执行，不增加请求耗时；回调 panic 时只记录日志，不影响其他回调和响应
+//
+// 参数：
+//   - hook: 回调函数
+func OnPanic(hook PanicHook) {
+	panicHooksMu.Lock()
+	defer panicHooksMu.Unlock()
+	panicHooks = append(panicHooks, hook)
+}
+
 // ExceptionHandler 全局异常处理器中间件
 // 该中间件会：
 // 1. 使用defer和recover机制捕获所有panic异常
 // 2. 根据异常类型选择不同的处理策略
-// 3. 记录异常信息和堆栈跟踪
-// 4. 返回统一的错误响应格式
+// 3. 对未处理的异常记录结构化日志（traceId、路由、请求方法、异常值、过滤后的堆栈），
+//    堆栈帧数由 service.panicStackDepth 控制，并在响应写出后执行 OnPanic 注册的回调
+// 4. 通过 response.NewError / response.WriteError 返回统一的错误响应格式，开启 service.useHTTPStatus 时按响应码输出映射的 HTTP 状态码，
+//    否则为 200，错误消息通过 response.Localize 按请求的语言区域解析
 // 5. 中断请求处理流程
+// 6. 请求处理完成后检查通过 ctx.Error（或 exception.Abort）添加的错误：响应尚未写出时按与 panic 相同的规则分类并返回统一的错误响应，
+//    优先使用第一个 gin.ErrorTypePublic 类型的错误，否则使用第一个错误；响应已写出时只记录带 traceId 的日志
 // 返回：
 //   - gin.HandlerFunc: Gin中间件函数
 func ExceptionHandler() gin.HandlerFunc {
@@ -30,46 +72,197 @@ func ExceptionHandler() gin.HandlerFunc {
 		defer func() {
 			// 捕获panic异常
 			if err := recover(); err != nil {
-				// 设置默认的错误消息和错误码
-				message := "服务端异常"
-				code := response.ResponseExceptionUnknown.GetCode()
-
-				// 如果是error类型且是validator校验异常，转换为InvalidParam异常
-				if errValue, ok := err.(error); ok {
-					if validationErrors, ok := errValue.(validator.ValidationErrors); ok {
-						err = exception.NewInvalidParamFromValidator(validationErrors)
-					}
-				}
+				recovered := err
+				// 未处理异常的完整堆栈，供回调使用
+				var stack []byte
 
-				// 检查异常是否实现了自定义异常处理接口
-				if handler, ok := err.(exception.Handler); ok {
-					// 如果实现了自定义异常处理接口，调用其处理方法
-					message, code = handler.OnException(ctx)
-				} else {
+				// 按异常类型获取错误消息和错误码
+				message, code, handled := classifyException(ctx, err)
+				if !handled {
 					// 如果未实现自定义异常处理接口，记录异常信息和堆栈跟踪
-					logger.ErrorWithFields(map[string]any{
-						"error":     err,                   // 异常信息
-						"stackInfo": string(debug.Stack()), // 堆栈跟踪信息
-					}, "未处理的异常")
+					stack = debug.Stack()
+					logPanic(ctx, recovered, panicStack(app.BaseConfig.Service.GetPanicStackDepth()))
 				}
 
+				// 按统一的 HTTP 状态码规则生成错误响应，错误消息按请求的语言区域解析
+				status, body := response.NewError(ctx, response.Of(code), response.WithMessage(message))
+
 				// 将错误信息添加到Gin上下文的错误列表中
-				_ = ctx.Error(fmt.Errorf("%d : %s", code, message))
+				_ = ctx.Error(fmt.Errorf("%d : %s", code, body.Msg))
 
-				// 返回统一的错误响应格式
-				ctx.JSON(http.StatusOK, gin.H{
-					"code": code,    // 错误码
-					"msg":  message, // 错误消息
-					"data": "",      // 数据字段（异常时为空）
-				})
+				// 返回统一的错误响应格式，并中断请求处理流程，不再执行后续的中间件和处理器
+				ctx.AbortWithStatusJSON(status, body)
 
-				// 中断请求处理流程，不再执行后续的中间件和处理器
-				ctx.Abort()
+				// 响应写出后执行未处理异常的回调
+				if stack != nil {
+					runPanicHooks(ctx, recovered, stack)
+				}
 				return
 			}
 		}()
 
 		// 继续执行下一个中间件或处理器
 		ctx.Next()
+
+		// 处理通过 ctx.Error 添加的错误
+		if len(ctx.Errors) > 0 {
+			handleContextErrors(ctx)
+		}
+	}
+}
+
+// classifyException 按异常类型获取响应消息和响应码
+// validator 校验异常转换为 InvalidParam 异常，实现 exception.Handler 的异常调用 OnException，其余为未知异常
+//
+// 返回：
+//   - message: 错误消息或消息 ID
+//   - code: 响应码
+//   - handled: 是否实现了 exception.Handler，为 false 时为未知异常
+func classifyException(ctx *gin.Context, err any) (message string, code int, handled bool) {
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		err = exception.NewInvalidParamFromValidator(validationErrors)
+	}
+	if handler, ok := err.(exception.Handler); ok {
+		message, code = handler.OnException(ctx)
+		return message, code, true
+	}
+	return "exception.unknown", response.ResponseExceptionUnknown.GetCode(), false
+}
+
+// handleContextErrors 处理通过 ctx.Error 添加的错误
+// 响应已写出时只记录日志：全部为已处理的参数绑定错误（gin.ErrorTypeBind，如 BindAndValidate 校验失败）时记录调试日志，否则记录告警日志；
+// 响应未写出时选出返回给客户端的错误，按 classifyException 分类后返回统一的错误响应
+func handleContextErrors(ctx *gin.Context) {
+	if ctx.Writer.Written() {
+		if len(ctx.Errors.ByType(gin.ErrorTypeAny&^gin.ErrorTypeBind)) == 0 {
+			logger.DebugWithFields(contextErrorFields(ctx), "请求错误（响应已写出）")
+			return
+		}
+		logger.WarnWithFields(contextErrorFields(ctx), "请求错误（响应已写出）")
+		return
+	}
+
+	selected := selectContextError(ctx.Errors)
+	message, code, handled := classifyException(ctx, unwrapException(selected.Err))
+	if !handled {
+		// 公开类型的错误消息可以返回给客户端
+		if selected.IsType(gin.ErrorTypePublic) {
+			message = selected.Error()
+		}
+		logger.ErrorWithFields(contextErrorFields(ctx), "未处理的错误")
+	}
+	response.WriteError(ctx, response.Of(code), response.WithMessage(message))
+}
+
+// selectContextError 选出返回给客户端的错误：第一个 gin.ErrorTypePublic 类型的错误，没有时为第一个错误
+func selectContextError(errs []*gin.Error) *gin.Error {
+	for _, e := range errs {
+		if e.IsType(gin.ErrorTypePublic) {
+			return e
+		}
 	}
+	return errs[0]
+}
+
+// unwrapException 从错误链中取出 validator 校验异常或实现 exception.Handler 的异常，都没有时返回原错误
+// 使处理函数包装后的错误（如 fmt.Errorf("...: %w", err)）仍按原异常类型处理
+func unwrapException(err error) any {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return validationErrors
+	}
+	var handler exception.Handler
+	if errors.As(err, &handler) {
+		return handler
+	}
+	return err
+}
+
+// contextErrorFields 获取记录通过 ctx.Error 添加的错误时的日志字段
+func contextErrorFields(ctx *gin.Context) map[string]any {
+	route := ctx.FullPath()
+	if route == "" {
+		route = ctx.Request.URL.Path
+	}
+	return map[string]any{
+		"traceId": ginContext.GetTraceID(ctx), // 追踪 ID
+		"method":  ctx.Request.Method,         // 请求方法
+		"route":   route,                      // 路由模板，未匹配路由时为请求路径
+		"status":  ctx.Writer.Status(),        // 响应状态码
+		"errors":  ctx.Errors.String(),        // 所有错误
+	}
+}
+
+// logPanic 记录未处理异常的结构化日志
+func logPanic(ctx *gin.Context, recovered any, stack string) {
+	route := ctx.FullPath()
+	if route == "" {
+		route = ctx.Request.URL.Path
+	}
+	logger.ErrorWithFields(map[string]any{
+		"traceId":   ginContext.GetTraceID(ctx), // 追踪 ID
+		"method":    ctx.Request.Method,         // 请求方法
+		"route":     route,                      // 路由模板，未匹配路由时为请求路径
+		"error":     fmt.Sprint(recovered),      // 异常信息
+		"stackInfo": stack,                      // 过滤后的堆栈跟踪信息
+	}, "未处理的异常")
+}
+
+// panicStack 获取发生 panic 处的堆栈，在 recover 所在的 defer 函数中调用
+// 过滤 runtime、gin、net/http 的帧，最多保留 depth 帧，每帧格式为 "函数名\n\t文件:行号"
+func panicStack(depth int) string {
+	// 跳过 runtime.Callers、panicStack 和调用方的 defer 函数
+	pcs := make([]uintptr, depth+64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+
+	var b strings.Builder
+	for n := 0; n < depth; {
+		frame, more := frames.Next()
+		if !isSkippedPanicFrame(frame.Function) {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+			n++
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// isSkippedPanicFrame 判断堆栈帧是否需要过滤
+func isSkippedPanicFrame(function string) bool {
+	for _, prefix := range panicSkipFramePrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// runPanicHooks 在独立协程中执行未处理异常的回调
+// 在 ExceptionHandler 写出响应后调用，回调收到请求上下文的副本
+func runPanicHooks(ctx *gin.Context, recovered any, stack []byte) {
+	panicHooksMu.RLock()
+	hooks := append([]PanicHook(nil), panicHooks...)
+	panicHooksMu.RUnlock()
+	if len(hooks) == 0 {
+		return
+	}
+
+	c := ctx.Copy()
+	go func() {
+		for _, hook := range hooks {
+			runPanicHook(hook, c, recovered, stack)
+		}
+	}()
+}
+
+// runPanicHook 执行单个回调，回调 panic 时记录日志
+func runPanicHook(hook PanicHook, ctx *gin.Context, recovered any, stack []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("[异常处理] 未处理异常的回调执行失败: %v", r)
+		}
+	}()
+	hook(ctx, recovered, stack)
 }
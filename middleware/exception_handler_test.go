@@ -9,6 +9,11 @@
 // 3. validator 校验异常的转换
 // 4. 未知异常的兜底处理
 // 5. 正常请求的透传
+// 6. 开启 HTTP 状态码映射时按响应码输出状态码，响应体不变
+// 7. 未处理异常的结构化日志（traceId、路由、过滤后的堆栈）与 OnPanic 回调
+// 8. 通过 ctx.Error / exception.Abort 添加的错误：分类规则与 panic 一致、优先使用公开类型的错误、不覆盖已写出的响应，
+//    已处理的参数绑定错误不记录告警日志
+// 9. 异常处理、限流、API Key 认证、跨域预检拒绝的错误响应遵循同一 HTTP 状态码规则
 //
 // 运行测试：go test -v ./middleware/... -run ExceptionHandler
 // ==================================================
@@ -16,16 +21,24 @@ package middleware
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/zzsen/gin_core/exception"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
 	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
 )
 
 // ==================== 测试辅助结构 ====================
@@ -1092,6 +1105,511 @@ func TestExceptionHandler_InvalidJSON(t *testing.T) {
 	}
 }
 
+// TestExceptionHandler_UseHTTPStatus 测试 HTTP 状态码映射
+//
+// 【功能点】验证开启 service.useHTTPStatus 后按异常返回的响应码输出映射的 HTTP 状态码，响应体格式不变
+// 【测试流程】
+//  1. 注册自定义响应码 60901 -> 404，开启映射开关
+//  2. 分别抛出参数校验异常、认证失败异常、数据冲突异常、返回注册响应码的自定义异常、未知 panic
+//  3. 断言 HTTP 状态码依次为 400、403、409、404、500，响应体包含 code/msg/data
+//  4. 关闭映射开关后同一异常返回 200
+func TestExceptionHandler_UseHTTPStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// 重复执行测试时响应码已注册，忽略重复注册错误
+	_ = response.Register(60901, "资源不存在", http.StatusNotFound)
+	response.SetUseHTTPStatus(true)
+	defer response.SetUseHTTPStatus(false)
+
+	router := gin.New()
+	router.Use(ExceptionHandler())
+	router.GET("/param", func(c *gin.Context) { panic(exception.NewInvalidParam("id 不能为空")) })
+	router.GET("/auth", func(c *gin.Context) { panic(exception.AuthFailed{}) })
+	router.GET("/conflict", func(c *gin.Context) { panic(exception.NewConflict("")) })
+	router.GET("/custom", func(c *gin.Context) { panic(customException{message: "订单不存在", code: 60901}) })
+	router.GET("/unknown", func(c *gin.Context) { panic("boom") })
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantCode   int
+	}{
+		{"/param", http.StatusBadRequest, response.ResponseParamInvalid.GetCode()},
+		{"/auth", http.StatusForbidden, response.ResponseAuthFailed.GetCode()},
+		{"/conflict", http.StatusConflict, response.ResponseConflict.GetCode()},
+		{"/custom", http.StatusNotFound, 60901},
+		{"/unknown", http.StatusInternalServerError, response.ResponseExceptionUnknown.GetCode()},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: 期望状态码 %d, 实际 %d", tt.path, tt.wantStatus, w.Code)
+		}
+		var resp map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: 解析响应失败: %v", tt.path, err)
+		}
+		if int(resp["code"].(float64)) != tt.wantCode {
+			t.Errorf("%s: 期望 code=%d, 实际 %v", tt.path, tt.wantCode, resp["code"])
+		}
+		for _, key := range []string{"msg", "data"} {
+			if _, ok := resp[key]; !ok {
+				t.Errorf("%s: 期望响应包含 %s 字段", tt.path, key)
+			}
+		}
+	}
+
+	response.SetUseHTTPStatus(false)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/param", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("关闭映射后期望状态码 200, 实际 %d", w.Code)
+	}
+}
+
+// TestErrorStatusPolicy 测试各中间件错误响应的 HTTP 状态码规则
+//
+// 【功能点】验证异常处理、限流、API Key 认证、跨域预检拒绝均通过 response.WriteError 输出：
+// 关闭 service.useHTTPStatus 时始终返回 200，开启时按响应码返回对应的 HTTP 状态码，两种情况下响应体完全相同
+// 【测试流程】
+//  1. 配置 burst=1 的限流和只允许 https://app.example.com 的跨域，分别创建各中间件的测试路由
+//  2. 关闭映射开关，依次请求参数校验异常、未知 panic、被限流、缺少 API Key、来源不被允许的预检请求，断言均为 200 且响应体包含 code/msg/data
+//  3. 开启映射开关后重复请求，断言依次为 400、500、429、401、403，响应体与关闭时相同
+func TestErrorStatusPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	originalConfig := app.BaseConfig
+	app.BaseConfig = config.BaseConfig{
+		RateLimit: config.RateLimitConfig{Enabled: true, DefaultRate: 1, DefaultBurst: 1, Store: "memory", Message: "请求过于频繁"},
+		CORS:      config.CORSConfig{Enabled: true, AllowOrigins: []string{"https://app.example.com"}},
+	}
+	defer func() {
+		app.BaseConfig = originalConfig
+		response.SetUseHTTPStatus(false)
+	}()
+
+	exceptionRouter := gin.New()
+	exceptionRouter.Use(ExceptionHandler())
+	exceptionRouter.GET("/param", func(c *gin.Context) { panic(exception.NewInvalidParam("id 不能为空")) })
+	exceptionRouter.GET("/unknown", func(c *gin.Context) { panic("boom") })
+	rateLimitRouter := createTestRouter(RateLimitHandler())
+	apiKeyRouter := createAPIKeyTestRouter(newAPIKeyTestConfig())
+	corsRouter := createCORSTestRouter(CORSHandler())
+	get := func(router *gin.Engine, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name       string
+		do         func() *httptest.ResponseRecorder
+		wantStatus int
+		wantCode   int
+	}{
+		{"参数校验异常", func() *httptest.ResponseRecorder {
+			return get(exceptionRouter, "/param")
+		}, http.StatusBadRequest, response.ResponseParamInvalid.GetCode()},
+		{"未知 panic", func() *httptest.ResponseRecorder {
+			return get(exceptionRouter, "/unknown")
+		}, http.StatusInternalServerError, response.ResponseExceptionUnknown.GetCode()},
+		{"被限流", func() *httptest.ResponseRecorder {
+			// 第一个请求消耗唯一的令牌，第二个请求被限流
+			var w *httptest.ResponseRecorder
+			for range 2 {
+				w = httptest.NewRecorder()
+				req, _ := http.NewRequest(http.MethodGet, "/api/test", nil)
+				req.RemoteAddr = "192.168.1.100:12345"
+				rateLimitRouter.ServeHTTP(w, req)
+			}
+			return w
+		}, http.StatusTooManyRequests, http.StatusTooManyRequests},
+		{"缺少 API Key", func() *httptest.ResponseRecorder {
+			return doAPIKeyRequest(apiKeyRouter, http.MethodGet, "/api/orders", "")
+		}, http.StatusUnauthorized, response.ResponseUnauthorized.GetCode()},
+		{"跨域预检拒绝", func() *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodOptions, "/api/test", nil)
+			req.Header.Set("Origin", "https://evil.example.com")
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			corsRouter.ServeHTTP(w, req)
+			return w
+		}, http.StatusForbidden, response.ResponseOriginDenied.GetCode()},
+	}
+
+	bodies := make(map[string]map[string]any)
+	for _, useHTTPStatus := range []bool{false, true} {
+		response.SetUseHTTPStatus(useHTTPStatus)
+		for _, tt := range tests {
+			w := tt.do()
+			wantStatus := http.StatusOK
+			if useHTTPStatus {
+				wantStatus = tt.wantStatus
+			}
+			if w.Code != wantStatus {
+				t.Errorf("%s（useHTTPStatus=%v）: 期望状态码 %d, 实际 %d", tt.name, useHTTPStatus, wantStatus, w.Code)
+			}
+			var resp map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("%s: 解析响应失败: %v, body: %s", tt.name, err, w.Body.String())
+			}
+			if len(resp) != 3 {
+				t.Errorf("%s: 期望响应只包含 code/msg/data, 实际 %v", tt.name, resp)
+			}
+			if code, _ := resp["code"].(float64); int(code) != tt.wantCode {
+				t.Errorf("%s: 期望 code=%d, 实际 %v", tt.name, tt.wantCode, resp["code"])
+			}
+			if msg, _ := resp["msg"].(string); msg == "" {
+				t.Errorf("%s: 期望 msg 非空", tt.name)
+			}
+			if first, ok := bodies[tt.name]; ok && fmt.Sprint(first) != fmt.Sprint(resp) {
+				t.Errorf("%s: 开启映射后响应体不一致, 关闭时 %v, 开启时 %v", tt.name, first, resp)
+			}
+			bodies[tt.name] = resp
+		}
+	}
+}
+
+// resetPanicHooks 清空已注册的未处理异常回调，测试结束后恢复
+func resetPanicHooks(t *testing.T) {
+	panicHooksMu.Lock()
+	original := panicHooks
+	panicHooks = nil
+	panicHooksMu.Unlock()
+	t.Cleanup(func() {
+		panicHooksMu.Lock()
+		panicHooks = original
+		panicHooksMu.Unlock()
+	})
+}
+
+// newPanicRouter 创建设置追踪 ID 并在 /api/orders/:id 抛出 panic 的路由
+func newPanicRouter(recovered any) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		ginContext.SetTraceID(c, "trace-panic-1")
+		c.Next()
+	})
+	router.Use(ExceptionHandler())
+	router.GET("/api/orders/:id", func(c *gin.Context) {
+		panic(recovered)
+	})
+	return router
+}
+
+// TestExceptionHandler_PanicLog 测试未处理异常的结构化日志
+//
+// 【功能点】验证未处理异常的日志包含追踪 ID、路由模板、请求方法和过滤后的堆栈
+// 【测试流程】
+//  1. 在 /api/orders/:id 路由中 panic，请求 /api/orders/42
+//  2. 断言记录了一条 "未处理的异常" 日志，traceId、route、method、error 字段正确
+//  3. 断言堆栈包含处理函数所在的测试文件，不包含 gin 和 runtime 的帧
+func TestExceptionHandler_PanicLog(t *testing.T) {
+	resetPanicHooks(t)
+	hook := logtest.NewLocal(logger.Logger)
+	defer hook.Reset()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/orders/42", nil)
+	newPanicRouter("order not found").ServeHTTP(w, req)
+
+	var entry map[string]any
+	for _, e := range hook.AllEntries() {
+		if e.Message == "未处理的异常" {
+			entry = e.Data
+		}
+	}
+	if entry == nil {
+		t.Fatal("期望记录未处理异常的日志")
+	}
+	want := map[string]string{"traceId": "trace-panic-1", "route": "/api/orders/:id", "method": "GET", "error": "order not found"}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("期望 %s=%s, 实际 %v", key, value, entry[key])
+		}
+	}
+	stack, _ := entry["stackInfo"].(string)
+	if !strings.Contains(stack, "exception_handler_test.go") {
+		t.Errorf("堆栈应包含处理函数所在文件, 实际:\n%s", stack)
+	}
+	if strings.Contains(stack, "github.com/gin-gonic/gin.") || strings.Contains(stack, "runtime.gopanic") {
+		t.Errorf("堆栈不应包含 gin 和 runtime 的帧, 实际:\n%s", stack)
+	}
+}
+
+// TestExceptionHandler_OnPanic 测试未处理异常的回调
+//
+// 【功能点】验证回调收到原始 panic 值、完整堆栈和请求上下文，自定义异常不触发回调
+// 【测试流程】
+//  1. 注册回调，在路由中 panic 一个 error 值
+//  2. 断言回调收到的 panic 值为原始 error，堆栈非空，上下文中的追踪 ID 和路由正确
+//  3. 抛出实现 Handler 接口的自定义异常，断言回调未被调用
+func TestExceptionHandler_OnPanic(t *testing.T) {
+	resetPanicHooks(t)
+	type report struct {
+		recovered any
+		stack     []byte
+		traceID   string
+		route     string
+	}
+	reports := make(chan report, 2)
+	OnPanic(func(ctx *gin.Context, recovered any, stack []byte) {
+		reports <- report{recovered, stack, ginContext.GetTraceID(ctx), ctx.FullPath()}
+	})
+
+	original := fmt.Errorf("db connection lost")
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/orders/42", nil)
+	newPanicRouter(original).ServeHTTP(w, req)
+
+	select {
+	case r := <-reports:
+		if r.recovered != original {
+			t.Errorf("回调应收到原始 panic 值, 实际 %v", r.recovered)
+		}
+		if len(r.stack) == 0 {
+			t.Error("回调应收到堆栈")
+		}
+		if r.traceID != "trace-panic-1" || r.route != "/api/orders/:id" {
+			t.Errorf("回调上下文不正确: traceId=%s, route=%s", r.traceID, r.route)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("回调未被调用")
+	}
+
+	w = httptest.NewRecorder()
+	newPanicRouter(customException{message: "订单不存在", code: 60902}).ServeHTTP(w, req)
+	select {
+	case r := <-reports:
+		t.Errorf("自定义异常不应触发回调, 实际收到 %v", r.recovered)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestExceptionHandler_OnPanic_HookPanics 测试回调 panic
+//
+// 【功能点】验证回调 panic 时不影响响应和后续回调
+// 【测试流程】
+//  1. 不注册回调时请求 panic 路由，记录响应
+//  2. 注册一个会 panic 的回调和一个正常回调后再次请求
+//  3. 断言两次响应的状态码和响应体相同，正常回调仍被调用
+func TestExceptionHandler_OnPanic_HookPanics(t *testing.T) {
+	resetPanicHooks(t)
+	router := newPanicRouter("boom")
+	req, _ := http.NewRequest("GET", "/api/orders/42", nil)
+
+	baseline := httptest.NewRecorder()
+	router.ServeHTTP(baseline, req)
+
+	called := make(chan struct{}, 1)
+	OnPanic(func(ctx *gin.Context, recovered any, stack []byte) { panic("hook failed") })
+	OnPanic(func(ctx *gin.Context, recovered any, stack []byte) { called <- struct{}{} })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != baseline.Code || w.Body.String() != baseline.Body.String() {
+		t.Errorf("回调 panic 不应影响响应: 期望 %d %s, 实际 %d %s", baseline.Code, baseline.Body, w.Code, w.Body)
+	}
+
+	select {
+	case <-called:
+	case <-time.After(2 * time.Second):
+		t.Fatal("前一个回调 panic 后，后续回调应继续执行")
+	}
+}
+
+// ==================== ctx.Error 错误处理测试 ====================
+
+// serveContextError 创建设置追踪 ID 的路由，以 handler 处理 /api/orders/:id 并返回响应和解析后的响应体
+func serveContextError(t *testing.T, handler gin.HandlerFunc) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		ginContext.SetTraceID(c, "trace-error-1")
+		c.Next()
+	})
+	router.Use(ExceptionHandler())
+	router.GET("/api/orders/:id", handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/orders/42", nil)
+	router.ServeHTTP(w, req)
+
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("解析响应失败: %v, body: %s", err, w.Body.String())
+	}
+	return w, resp
+}
+
+// assertCodeMsg 断言响应体的 code 和 msg
+func assertCodeMsg(t *testing.T, resp map[string]any, code int, msg string) {
+	t.Helper()
+	if resp["code"] != float64(code) {
+		t.Errorf("期望 code=%d, 实际 %v", code, resp["code"])
+	}
+	if resp["msg"] != msg {
+		t.Errorf("期望 msg=%s, 实际 %v", msg, resp["msg"])
+	}
+}
+
+// findLogEntry 查找指定消息的日志字段，未找到时返回 nil
+func findLogEntry(hook *logtest.Hook, message string) map[string]any {
+	for _, e := range hook.AllEntries() {
+		if e.Message == message {
+			return e.Data
+		}
+	}
+	return nil
+}
+
+// TestExceptionHandler_ContextError 测试通过 ctx.Error 添加的错误
+//
+// 【功能点】验证 ctx.Error 添加的错误在响应未写出时按与 panic 相同的规则返回统一的错误响应
+// 【测试流程】
+//  1. ctx.Error 添加 CommonError 后 Abort，断言返回 CommonError 的 code 和 msg
+//  2. exception.Abort 添加包装后的 CommonError，断言按原异常类型处理且后续处理函数未执行
+//  3. exception.Abort 添加包装后的 validator 校验错误，断言返回参数校验失败的 code
+//  4. ctx.Error 添加普通错误，断言返回未知异常，并记录带 traceId 的 "未处理的错误" 日志
+func TestExceptionHandler_ContextError(t *testing.T) {
+	_, resp := serveContextError(t, func(c *gin.Context) {
+		_ = c.Error(exception.NewCommonError("库存不足"))
+		c.Abort()
+	})
+	assertCodeMsg(t, resp, response.ResponseExceptionCommon.GetCode(), "库存不足")
+
+	executed := false
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ExceptionHandler())
+	router.GET("/abort", func(c *gin.Context) {
+		exception.Abort(c, fmt.Errorf("创建订单: %w", exception.NewCommonError("库存不足")))
+	}, func(c *gin.Context) {
+		executed = true
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/abort", nil)
+	router.ServeHTTP(w, req)
+	if executed {
+		t.Error("exception.Abort 后不应执行后续处理函数")
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	assertCodeMsg(t, resp, response.ResponseExceptionCommon.GetCode(), "库存不足")
+
+	_, resp = serveContextError(t, func(c *gin.Context) {
+		exception.Abort(c, fmt.Errorf("绑定参数: %w", validator.ValidationErrors{}))
+	})
+	if resp["code"] != float64(response.ResponseParamInvalid.GetCode()) {
+		t.Errorf("期望 code=%d, 实际 %v", response.ResponseParamInvalid.GetCode(), resp["code"])
+	}
+
+	hook := logtest.NewLocal(logger.Logger)
+	defer hook.Reset()
+	_, resp = serveContextError(t, func(c *gin.Context) {
+		exception.Abort(c, errors.New("db timeout"))
+	})
+	assertCodeMsg(t, resp, response.ResponseExceptionUnknown.GetCode(), "服务端异常")
+	entry := findLogEntry(hook, "未处理的错误")
+	if entry == nil {
+		t.Fatal("期望记录未处理错误的日志")
+	}
+	if entry["traceId"] != "trace-error-1" || entry["route"] != "/api/orders/:id" || !strings.Contains(fmt.Sprint(entry["errors"]), "db timeout") {
+		t.Errorf("日志字段不正确: %v", entry)
+	}
+}
+
+// TestExceptionHandler_ContextError_Multiple 测试添加了多个错误时的选择
+//
+// 【功能点】验证第一个公开类型的错误优先，其消息返回给客户端；没有公开类型的错误时使用第一个错误
+// 【测试流程】
+//  1. 依次添加私有错误、两个公开错误，断言返回第一个公开错误的消息
+//  2. 依次添加两个 CommonError，断言返回第一个的消息
+func TestExceptionHandler_ContextError_Multiple(t *testing.T) {
+	_, resp := serveContextError(t, func(c *gin.Context) {
+		_ = c.Error(errors.New("db timeout"))
+		_ = c.Error(errors.New("订单不存在")).SetType(gin.ErrorTypePublic)
+		_ = c.Error(errors.New("订单已关闭")).SetType(gin.ErrorTypePublic)
+		c.Abort()
+	})
+	assertCodeMsg(t, resp, response.ResponseExceptionUnknown.GetCode(), "订单不存在")
+
+	_, resp = serveContextError(t, func(c *gin.Context) {
+		_ = c.Error(exception.NewCommonError("第一个错误"))
+		exception.Abort(c, exception.NewCommonError("第二个错误"))
+	})
+	assertCodeMsg(t, resp, response.ResponseExceptionCommon.GetCode(), "第一个错误")
+}
+
+// TestExceptionHandler_ContextError_Written 测试响应已写出时的错误
+//
+// 【功能点】验证响应已写出时不覆盖响应，只记录带 traceId 的日志
+// 【测试流程】写出 201 响应后添加错误，断言状态码和响应体不变，并记录 "请求错误（响应已写出）" 日志
+func TestExceptionHandler_ContextError_Written(t *testing.T) {
+	hook := logtest.NewLocal(logger.Logger)
+	defer hook.Reset()
+
+	w, resp := serveContextError(t, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"message": "created"})
+		_ = c.Error(exception.NewCommonError("发送通知失败"))
+	})
+	if w.Code != http.StatusCreated || len(resp) != 1 || resp["message"] != "created" {
+		t.Errorf("响应不应被覆盖, 实际 %d %s", w.Code, w.Body.String())
+	}
+	entry := findLogEntry(hook, "请求错误（响应已写出）")
+	if entry == nil {
+		t.Fatal("期望记录响应已写出的错误日志")
+	}
+	if entry["traceId"] != "trace-error-1" || !strings.Contains(fmt.Sprint(entry["errors"]), "发送通知失败") {
+		t.Errorf("日志字段不正确: %v", entry)
+	}
+}
+
+// TestExceptionHandler_ContextError_BindWritten 测试参数绑定失败后的错误
+//
+// 【功能点】验证 BindAndValidate 校验失败写出参数错误响应后，ExceptionHandler 不覆盖响应，也不记录告警日志
+// 【测试流程】缺少必填查询参数时调用 BindAndValidate，断言返回参数校验失败的 code，且没有 Warn 及以上级别的 "请求错误（响应已写出）" 日志
+func TestExceptionHandler_ContextError_BindWritten(t *testing.T) {
+	hook := logtest.NewLocal(logger.Logger)
+	defer hook.Reset()
+
+	type query struct {
+		Keyword string `form:"keyword" binding:"required"`
+	}
+	_, resp := serveContextError(t, func(c *gin.Context) {
+		if _, ok := ginContext.BindAndValidate[query](c); !ok {
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+	if code, ok := resp["code"].(float64); !ok || code != float64(response.ResponseParamInvalid.GetCode()) {
+		t.Errorf("期望 code=%d, 实际 %v", response.ResponseParamInvalid.GetCode(), resp["code"])
+	}
+	for _, e := range hook.AllEntries() {
+		if e.Message == "请求错误（响应已写出）" && e.Level <= logrus.WarnLevel {
+			t.Errorf("参数绑定失败不应记录 %s 级别的日志", e.Level)
+		}
+	}
+}
+
+// TestExceptionHandler_ContextErrorThenPanic 测试添加错误后 panic
+//
+// 【功能点】验证添加错误后 panic 时仍按 panic 处理，只写出一次响应
+// 【测试流程】添加 CommonError 后 panic 自定义异常，断言响应为自定义异常的 code 和 msg
+func TestExceptionHandler_ContextErrorThenPanic(t *testing.T) {
+	_, resp := serveContextError(t, func(c *gin.Context) {
+		_ = c.Error(exception.NewCommonError("库存不足"))
+		panic(customException{message: "自定义错误消息", code: 40001})
+	})
+	assertCodeMsg(t, resp, 40001, "自定义错误消息")
+}
+
 // ==================== 基准测试 ====================
 
 // BenchmarkExceptionHandler_NoPanic 基准测试无异常场景
@@ -0,0 +1,168 @@
+// Package middleware 提供Gin框架的中间件功能
+// 本文件实现了 gRPC 服务的拦截器，对应 HTTP 服务的 exceptionHandler、traceIdHandler、traceLogHandler
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// GrpcUnaryInterceptors 返回 gRPC 一元调用的默认拦截器，按顺序为追踪 ID、请求日志、异常恢复
+func GrpcUnaryInterceptors() []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{GrpcTraceIdUnaryInterceptor(), GrpcLogUnaryInterceptor(), GrpcRecoveryUnaryInterceptor()}
+}
+
+// GrpcStreamInterceptors 返回 gRPC 流式调用的默认拦截器，按顺序为追踪 ID、请求日志、异常恢复
+func GrpcStreamInterceptors() []grpc.StreamServerInterceptor {
+	return []grpc.StreamServerInterceptor{GrpcTraceIdStreamInterceptor(), GrpcLogStreamInterceptor(), GrpcRecoveryStreamInterceptor()}
+}
+
+// GrpcRecoveryUnaryInterceptor gRPC 一元调用的异常恢复拦截器
+// 捕获处理函数的 panic，记录与 exceptionHandler 相同格式的结构化日志，并返回 codes.Internal
+func GrpcRecoveryUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = recoverGrpcPanic(ctx, info.FullMethod, recovered)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// GrpcRecoveryStreamInterceptor gRPC 流式调用的异常恢复拦截器
+func GrpcRecoveryStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = recoverGrpcPanic(ss.Context(), info.FullMethod, recovered)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recoverGrpcPanic 记录 gRPC 处理函数的 panic 并转换为 codes.Internal 错误
+func recoverGrpcPanic(ctx context.Context, method string, recovered any) error {
+	logger.ErrorWithFields(map[string]any{
+		"traceId":   grpcTraceID(ctx),                                        // 追踪 ID
+		"method":    "gRPC",                                                  // 请求方法
+		"route":     method,                                                  // gRPC 方法全名
+		"error":     fmt.Sprint(recovered),                                   // 异常信息
+		"stackInfo": panicStack(app.BaseConfig.Service.GetPanicStackDepth()), // 过滤后的堆栈跟踪信息
+	}, "未处理的异常")
+	return status.Error(codes.Internal, "服务端异常")
+}
+
+// GrpcTraceIdUnaryInterceptor gRPC 一元调用的追踪 ID 拦截器
+// 与 traceIdHandler 一致：优先读取上游的 x-trace-id、x-request-id 元数据，未传递时生成 UUID，
+// 写入 RequestContext（处理函数通过 ginContext.FromStdContext 获取），并通过响应头元数据 x-trace-id 返回
+func GrpcTraceIdUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withGrpcTraceID(ctx), req)
+	}
+}
+
+// GrpcTraceIdStreamInterceptor gRPC 流式调用的追踪 ID 拦截器
+func GrpcTraceIdStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &grpcContextStream{ServerStream: ss, ctx: withGrpcTraceID(ss.Context())})
+	}
+}
+
+// grpcContextStream 替换了上下文的 grpc.ServerStream
+type grpcContextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回替换后的上下文
+func (s *grpcContextStream) Context() context.Context {
+	return s.ctx
+}
+
+// withGrpcTraceID 读取或生成追踪 ID，写入 RequestContext 和响应头元数据
+func withGrpcTraceID(ctx context.Context) context.Context {
+	traceID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, header := range traceHeaders {
+			if values := md.Get(header); len(values) > 0 && strings.TrimSpace(values[0]) != "" {
+				traceID = strings.TrimSpace(values[0])
+				break
+			}
+		}
+	}
+	if traceID == "" {
+		traceID = uuid.New().String()
+	}
+
+	rc, ok := ginContext.FromStdContext(ctx)
+	if !ok {
+		rc = ginContext.NewRequestContext()
+		ctx = ginContext.WithRequestContext(ctx, rc)
+	}
+	rc.SetTraceID(traceID)
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-trace-id", traceID))
+	return ctx
+}
+
+// grpcTraceID 获取上下文中的追踪 ID
+func grpcTraceID(ctx context.Context) string {
+	if rc, ok := ginContext.FromStdContext(ctx); ok {
+		return rc.TraceID()
+	}
+	return ""
+}
+
+// GrpcLogUnaryInterceptor gRPC 一元调用的请求日志拦截器，记录方法、状态码、耗时、客户端地址
+func GrpcLogUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		startTime := time.Now()
+		resp, err := handler(ctx, req)
+		logGrpcRequest(ctx, info.FullMethod, startTime, err)
+		return resp, err
+	}
+}
+
+// GrpcLogStreamInterceptor gRPC 流式调用的请求日志拦截器，在流结束时记录
+func GrpcLogStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		startTime := time.Now()
+		err := handler(srv, ss)
+		logGrpcRequest(ss.Context(), info.FullMethod, startTime, err)
+		return err
+	}
+}
+
+// logGrpcRequest 记录 gRPC 请求日志，字段与 traceLogHandler 保持一致
+func logGrpcRequest(ctx context.Context, method string, startTime time.Time, err error) {
+	clientIP := ""
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		clientIP = p.Addr.String()
+	}
+	errStr := ""
+	if err != nil {
+		errStr = err.Error()
+	}
+	logger.TraceWithFields(map[string]any{
+		"traceId":      grpcTraceID(ctx),          // 追踪ID，用于分布式追踪
+		"statusCode":   status.Code(err).String(), // gRPC 状态码
+		"responseTime": time.Since(startTime),     // 响应时间，用于性能监控
+		"clientIp":     clientIP,                  // 客户端地址
+		"reqMethod":    "gRPC",                    // 请求方法
+		"reqUri":       method,                    // gRPC 方法全名
+		"errStr":       errStr,                    // 错误信息，用于问题排查
+	}, "请求日志")
+}
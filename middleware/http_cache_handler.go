@@ -0,0 +1,396 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现 HTTP 响应缓存中间件，支持 ETag 协商缓存和 Cache-Control
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/httpcache"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// cacheStatusHeader 标记响应来源的响应头：MISS 表示执行了处理函数并写入缓存，HIT 表示返回缓存的响应
+const cacheStatusHeader = "X-Cache"
+
+var (
+	httpCacheOnce  sync.Once
+	httpCacheStore httpcache.Store
+)
+
+// initHTTPCacheStore 初始化响应缓存存储（单例）
+func initHTTPCacheStore() {
+	httpCacheOnce.Do(func() {
+		cfg := app.BaseConfig.HTTPCache
+
+		switch cfg.GetStore() {
+		case "memory":
+			httpCacheStore = httpcache.NewMemoryStore(time.Duration(cfg.GetCleanupInterval()) * time.Second)
+			logger.Info("[响应缓存] 使用内存存储")
+		default:
+			if app.Redis != nil {
+				httpCacheStore = httpcache.NewRedisStore(app.Redis, cfg.GetKeyPrefix())
+				logger.Info("[响应缓存] 使用 Redis 存储")
+			} else {
+				logger.Warn("[响应缓存] Redis 未初始化，降级为内存存储")
+				httpCacheStore = httpcache.NewMemoryStore(time.Duration(cfg.GetCleanupInterval()) * time.Second)
+			}
+		}
+	})
+}
+
+// CacheHandler HTTP 响应缓存中间件
+// 对匹配 httpCache.rules 的 GET 请求缓存响应（状态码、响应头、响应体），缓存期内的相同请求直接返回缓存的响应
+// 配置项通过 app.BaseConfig.HTTPCache 进行设置
+//
+// 功能特性：
+// - 缓存键由路径、排序后的查询参数和 varyHeaders 中的请求头组成，开启 privatePerUser 时追加用户 ID
+// - 响应带强 ETag（响应体的哈希值）和 Cache-Control: public / private, max-age={ttl}，命中缓存时带 Age 响应头
+// - 请求的 If-None-Match 与 ETag 一致时返回 304，命中缓存时不执行处理函数
+// - 只缓存状态码为 200、不带 Set-Cookie、不超过 maxBodyBytes 的响应；处理函数调用 ginContext.NoCache 时不缓存
+// - 同一路径的 POST / PUT / PATCH / DELETE 请求处理完成后删除该路径的所有缓存
+// - 存储出错时记录日志并执行处理函数，不影响业务
+//
+// 注意：privatePerUser 依赖认证中间件先设置用户 ID，应在认证中间件之后注册，
+// 如在路由组上 group.Use(auth, middleware.CacheHandler())；没有用户 ID 的请求不使用缓存
+//
+// 使用示例：
+//
+//	在配置文件中启用：
+//	httpCache:
+//	  enabled: true
+//	  store: "redis"
+//	  rules:
+//	    - path: "/api/articles/*"
+//	      ttl: 60
+//	      varyHeaders: ["Accept-Language"]
+//	    - path: "/api/profile"
+//	      ttl: 30
+//	      privatePerUser: true
+func CacheHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := app.BaseConfig.HTTPCache
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+
+		initHTTPCacheStore()
+		handleHTTPCache(c, httpCacheStore, &cfg)
+	}
+}
+
+// handleHTTPCache 按缓存规则处理请求
+func handleHTTPCache(c *gin.Context, store httpcache.Store, cfg *config.HTTPCacheConfig) {
+	requestPath := c.Request.URL.Path
+	rule := matchCacheRule(requestPath, cfg.Rules)
+	if rule == nil {
+		c.Next()
+		return
+	}
+
+	ctx := context.WithoutCancel(c.Request.Context())
+	switch c.Request.Method {
+	case http.MethodGet:
+		serveHTTPCache(c, ctx, store, rule, cfg.GetMaxBodyBytes())
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		c.Next()
+		if err := store.Purge(ctx, httpCachePathPrefix(requestPath)); err != nil {
+			logger.Error("[响应缓存] 删除缓存失败, path: %s, error: %v", requestPath, err)
+		}
+	default:
+		c.Next()
+	}
+}
+
+// serveHTTPCache 返回缓存的响应，未命中时执行处理函数并写入缓存
+// 开启 privatePerUser 但请求中没有用户 ID 时不读取、不写入缓存，避免不同用户共享同一份个人数据
+func serveHTTPCache(c *gin.Context, ctx context.Context, store httpcache.Store, rule *config.CacheRule, maxBody int) {
+	if ginContext.IsNoCache(c) {
+		c.Next()
+		return
+	}
+	if rule.PrivatePerUser {
+		if _, ok := ginContext.GetUserID(c); !ok {
+			c.Next()
+			return
+		}
+	}
+
+	key := httpCacheKey(c, rule)
+	entry, err := store.Get(ctx, key)
+	if err != nil {
+		logger.Error("[响应缓存] 读取缓存失败, key: %s, error: %v", key, err)
+	}
+	if entry != nil {
+		writeHTTPCacheEntry(c, entry, rule)
+		return
+	}
+
+	// 处理函数之前的中间件已设置的响应头（如跨域的 Access-Control-Allow-Origin）与请求相关，不写入缓存
+	before := c.Writer.Header().Clone()
+	writer := &cacheBodyWriter{ResponseWriter: c.Writer, limit: maxBody, status: c.Writer.Status()}
+	c.Writer = writer
+	defer func() {
+		c.Writer = writer.ResponseWriter
+	}()
+	c.Next()
+
+	if writer.passthrough {
+		return
+	}
+	header := writer.Header()
+	if writer.status != http.StatusOK || ginContext.IsNoCache(c) || header.Get("Set-Cookie") != "" {
+		_ = writer.flush()
+		return
+	}
+
+	body := writer.buf.Bytes()
+	etag := strongETag(body)
+	entryHeader := httpCacheEntryHeader(before, header)
+	setHTTPCacheHeaders(header, rule, etag)
+	entry = &httpcache.Entry{
+		Status:   writer.status,
+		Header:   entryHeader,
+		Body:     slices.Clone(body),
+		ETag:     etag,
+		StoredAt: time.Now(),
+	}
+	if err := store.Set(ctx, key, entry, time.Duration(rule.GetTTL())*time.Second); err != nil {
+		logger.Error("[响应缓存] 写入缓存失败, key: %s, error: %v", key, err)
+	}
+	header.Set(cacheStatusHeader, "MISS")
+
+	if etagMatch(c.GetHeader("If-None-Match"), etag) {
+		writer.status = http.StatusNotModified
+		writer.buf.Reset()
+		writer.written = true
+	}
+	_ = writer.flush()
+}
+
+// httpCacheEntryHeader 选出写入缓存的响应头：Content-Type、Content-Encoding，以及处理函数设置或修改的响应头
+// before 为执行处理函数之前的响应头，其中未被处理函数修改的响应头由之前的中间件为当前请求设置，不写入缓存
+func httpCacheEntryHeader(before, after http.Header) http.Header {
+	header := make(http.Header)
+	for name, values := range after {
+		if name != "Content-Type" && name != "Content-Encoding" && slices.Equal(before[name], values) {
+			continue
+		}
+		header[name] = slices.Clone(values)
+	}
+	return header
+}
+
+// writeHTTPCacheEntry 输出缓存的响应，If-None-Match 与 ETag 一致时返回 304
+// 只复制当前请求未设置的响应头，保留当前请求自身的 X-Trace-ID 等响应头
+func writeHTTPCacheEntry(c *gin.Context, entry *httpcache.Entry, rule *config.CacheRule) {
+	header := c.Writer.Header()
+	setHTTPCacheHeaders(header, rule, entry.ETag)
+	header.Set("Age", strconv.Itoa(max(int(time.Since(entry.StoredAt).Seconds()), 0)))
+	header.Set(cacheStatusHeader, "HIT")
+
+	if etagMatch(c.GetHeader("If-None-Match"), entry.ETag) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		c.Abort()
+		return
+	}
+
+	for name, values := range entry.Header {
+		if len(header.Values(name)) > 0 {
+			continue
+		}
+		header[name] = slices.Clone(values)
+	}
+	c.Status(entry.Status)
+	c.Writer.WriteHeaderNow()
+	_, _ = c.Writer.Write(entry.Body)
+	c.Abort()
+}
+
+// setHTTPCacheHeaders 设置 ETag、Cache-Control 和 Vary 响应头
+func setHTTPCacheHeaders(header http.Header, rule *config.CacheRule, etag string) {
+	visibility := "public"
+	if rule.PrivatePerUser {
+		visibility = "private"
+	}
+	header.Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", visibility, rule.GetTTL()))
+	header.Set("ETag", etag)
+	if len(rule.VaryHeaders) > 0 {
+		header.Set("Vary", strings.Join(rule.VaryHeaders, ", "))
+	}
+}
+
+// matchCacheRule 获取第一条匹配路径的缓存规则，没有匹配的规则时返回 nil
+func matchCacheRule(requestPath string, rules []config.CacheRule) *config.CacheRule {
+	for i := range rules {
+		if matchAuditPath(requestPath, []string{rules[i].Path}) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// httpCacheKey 生成缓存键，格式 "{path}?{排序后的查询参数}|{请求头}={值}"，开启 privatePerUser 时追加 "#{userID}"
+func httpCacheKey(c *gin.Context, rule *config.CacheRule) string {
+	var key strings.Builder
+	key.WriteString(httpCachePathPrefix(c.Request.URL.Path))
+	key.WriteString(c.Request.URL.Query().Encode())
+	for _, name := range rule.VaryHeaders {
+		key.WriteString("|" + http.CanonicalHeaderKey(name) + "=" + c.GetHeader(name))
+	}
+	if rule.PrivatePerUser {
+		userID, _ := ginContext.GetUserID(c)
+		key.WriteString("#" + userID)
+	}
+	return key.String()
+}
+
+// httpCachePathPrefix 路径对应的缓存键前缀，删除该路径的缓存时使用
+func httpCachePathPrefix(requestPath string) string {
+	return requestPath + "?"
+}
+
+// strongETag 根据响应体生成强 ETag
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatch 判断 If-None-Match 请求头是否与 ETag 一致，按弱比较忽略 W/ 前缀
+func etagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheBodyWriter 响应缓冲写入器
+// 响应体不超过 limit 时先写入缓冲区，处理函数结束后再输出，以便在响应头中设置 ETag；
+// 超过 limit 或处理函数调用 Flush 时输出已缓冲的内容，之后直接写给客户端
+type cacheBodyWriter struct {
+	gin.ResponseWriter
+	buf         bytes.Buffer
+	limit       int
+	status      int
+	written     bool
+	passthrough bool
+}
+
+// WriteHeader 记录状态码
+func (w *cacheBodyWriter) WriteHeader(code int) {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if code > 0 {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow 标记响应头已写入
+func (w *cacheBodyWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.written = true
+}
+
+// Write 写入缓冲区，超过 limit 时输出已缓冲的内容并直接写给客户端
+func (w *cacheBodyWriter) Write(b []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	w.written = true
+	if w.buf.Len()+len(b) > w.limit {
+		if err := w.flush(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+// WriteString 写入字符串
+func (w *cacheBodyWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Status 返回状态码
+func (w *cacheBodyWriter) Status() int {
+	if w.passthrough {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+// Size 返回已写入的响应体字节数，未写入时返回 -1
+func (w *cacheBodyWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.buf.Len()
+}
+
+// Written 返回响应是否已写入
+func (w *cacheBodyWriter) Written() bool {
+	if w.passthrough {
+		return w.ResponseWriter.Written()
+	}
+	return w.written
+}
+
+// Flush 输出已缓冲的内容，之后直接写给客户端
+func (w *cacheBodyWriter) Flush() {
+	_ = w.flush()
+	w.ResponseWriter.Flush()
+}
+
+// flush 输出状态码和已缓冲的响应体
+func (w *cacheBodyWriter) flush() error {
+	if w.passthrough {
+		return nil
+	}
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() > 0 {
+		_, err := w.ResponseWriter.Write(w.buf.Bytes())
+		return err
+	}
+	if w.written {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+	return nil
+}
+
+// CloseHTTPCacheStore 关闭响应缓存存储
+func CloseHTTPCacheStore() error {
+	if httpCacheStore != nil {
+		return httpCacheStore.Close()
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+// Package middleware 响应缓存中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含响应缓存中间件的单元测试，使用内存存储和 miniredis，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 首次请求未命中并写入缓存，之后的请求命中缓存，不执行处理函数，带 Age 响应头
+// 2. If-None-Match 与 ETag 一致时返回 304
+// 3. privatePerUser 按用户区分缓存，Cache-Control 为 private；varyHeaders 按请求头区分缓存
+// 4. privatePerUser 的请求中没有用户 ID（在缓存中间件之后才设置）时不使用缓存
+// 5. 同一路径的 POST 请求删除该路径的缓存
+// 6. 非 200 响应、ginContext.NoCache、超过 maxBodyBytes 的响应不缓存
+// 7. Redis 存储的读写和按路径删除
+// 8. 只缓存内容响应头和处理函数设置的响应头，不缓存之前的中间件设置的跨域等响应头
+//
+// 运行测试：go test -v ./middleware/... -run HTTPCache
+// ==================================================
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/httpcache"
+	"github.com/zzsen/gin_core/model/config"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// createHTTPCacheTestRouter 创建响应缓存测试路由
+// GET /api/articles/:id 返回递增的版本号，X-User 请求头设置用户 ID，?nocache=1 时调用 ginContext.NoCache，?status=404 时返回 404
+func createHTTPCacheTestRouter(t *testing.T, store httpcache.Store, cfg *config.HTTPCacheConfig) (*gin.Engine, *atomic.Int32) {
+	t.Cleanup(func() { _ = store.Close() })
+	var calls atomic.Int32
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			ginContext.SetUserID(c, user)
+		}
+		handleHTTPCache(c, store, cfg)
+	})
+	article := func(c *gin.Context) {
+		n := calls.Add(1)
+		if c.Query("nocache") == "1" {
+			ginContext.NoCache(c)
+		}
+		if c.Query("status") == "404" {
+			c.JSON(http.StatusNotFound, gin.H{"version": n})
+			return
+		}
+		userID, _ := ginContext.GetUserID(c)
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "version": n, "user": userID, "lang": c.GetHeader("Accept-Language")})
+	}
+	router.GET("/api/articles/:id", article)
+	router.GET("/api/profile", article)
+	router.GET("/api/large", func(c *gin.Context) {
+		calls.Add(1)
+		c.String(http.StatusOK, strings.Repeat("x", 100))
+	})
+	router.POST("/api/articles/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router, &calls
+}
+
+// newHTTPCacheConfig 创建测试用的缓存配置
+func newHTTPCacheConfig() *config.HTTPCacheConfig {
+	return &config.HTTPCacheConfig{
+		Enabled:      true,
+		MaxBodyBytes: 64,
+		Rules: []config.CacheRule{
+			{Path: "/api/articles/*", TTL: 60, VaryHeaders: []string{"Accept-Language"}},
+			{Path: "/api/profile", TTL: 30, PrivatePerUser: true},
+			{Path: "/api/large", TTL: 60},
+		},
+	}
+}
+
+// doHTTPCacheRequest 发送测试请求，headers 为请求头键值对
+func doHTTPCacheRequest(router *gin.Engine, method, path string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// ==================== 测试用例 ====================
+
+// TestHTTPCache_MissThenHit 测试缓存未命中与命中
+//
+// 【功能点】验证首次请求执行处理函数并写入缓存，之后的请求返回相同的响应且不执行处理函数
+// 【测试流程】
+//  1. 首次请求，断言 X-Cache 为 MISS，带 ETag 和 Cache-Control: public, max-age=60、Vary 响应头
+//  2. 再次请求，断言 X-Cache 为 HIT，响应体、ETag、Content-Type 与首次一致，带 Age 响应头，处理函数只执行一次
+//  3. 查询参数顺序不同的请求命中同一缓存，查询参数不同的请求未命中
+func TestHTTPCache_MissThenHit(t *testing.T) {
+	router, calls := createHTTPCacheTestRouter(t, httpcache.NewMemoryStore(time.Minute), newHTTPCacheConfig())
+
+	first := doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1?a=1&b=2")
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "MISS", first.Header().Get(cacheStatusHeader))
+	assert.Equal(t, "public, max-age=60", first.Header().Get("Cache-Control"))
+	assert.Equal(t, "Accept-Language", first.Header().Get("Vary"))
+	etag := first.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+
+	second := doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1?b=2&a=1")
+	require.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, "HIT", second.Header().Get(cacheStatusHeader))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, etag, second.Header().Get("ETag"))
+	assert.Equal(t, first.Header().Get("Content-Type"), second.Header().Get("Content-Type"))
+	assert.Equal(t, "0", second.Header().Get("Age"))
+	assert.Equal(t, int32(1), calls.Load())
+
+	third := doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1?a=2")
+	assert.Equal(t, "MISS", third.Header().Get(cacheStatusHeader))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// TestHTTPCache_NotModified 测试 ETag 协商缓存
+//
+// 【功能点】验证 If-None-Match 与 ETag 一致时返回 304 且不执行处理函数，不一致时返回完整响应
+// 【测试流程】
+//  1. 首次请求获取 ETag
+//  2. 携带该 ETag（含 W/ 前缀和多个候选值）请求，断言返回 304、响应体为空、带 ETag，处理函数只执行一次
+//  3. 携带其他 ETag 请求，断言返回 200 和完整响应体
+//  4. 缓存删除后携带该 ETag 请求，处理函数重新执行，响应体变化后返回 200 和新的 ETag
+func TestHTTPCache_NotModified(t *testing.T) {
+	store := httpcache.NewMemoryStore(time.Minute)
+	router, calls := createHTTPCacheTestRouter(t, store, newHTTPCacheConfig())
+
+	first := doHTTPCacheRequest(router, http.MethodGet, "/api/profile", "X-User", "alice")
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag} {
+		w := doHTTPCacheRequest(router, http.MethodGet, "/api/profile", "X-User", "alice", "If-None-Match", ifNoneMatch)
+		assert.Equal(t, http.StatusNotModified, w.Code, ifNoneMatch)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+	}
+	assert.Equal(t, int32(1), calls.Load())
+
+	w := doHTTPCacheRequest(router, http.MethodGet, "/api/profile", "X-User", "alice", "If-None-Match", `"other"`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, first.Body.String(), w.Body.String())
+
+	// 处理函数每次返回递增的版本号，重新执行后 ETag 变化，返回 200
+	require.NoError(t, store.Purge(context.Background(), "/api/profile?"))
+	w = doHTTPCacheRequest(router, http.MethodGet, "/api/profile", "X-User", "alice", "If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// TestHTTPCache_PrivatePerUser 测试按用户区分缓存
+//
+// 【功能点】验证 privatePerUser 规则按用户区分缓存，Cache-Control 为 private；varyHeaders 按请求头区分缓存
+// 【测试流程】
+//  1. alice、bob 依次请求 /api/profile，断言各自执行处理函数，响应中的用户不同，Cache-Control 为 private
+//  2. alice 再次请求，断言命中自己的缓存
+//  3. 不同 Accept-Language 请求同一篇文章，断言分别缓存
+func TestHTTPCache_PrivatePerUser(t *testing.T) {
+	router, calls := createHTTPCacheTestRouter(t, httpcache.NewMemoryStore(time.Minute), newHTTPCacheConfig())
+
+	alice := doHTTPCacheRequest(router, http.MethodGet, "/api/profile", "X-User", "alice")
+	bob := doHTTPCacheRequest(router, http.MethodGet, "/api/profile", "X-User", "bob")
+	assert.Equal(t, "MISS", bob.Header().Get(cacheStatusHeader))
+	assert.Contains(t, alice.Body.String(), `"user":"alice"`)
+	assert.Contains(t, bob.Body.String(), `"user":"bob"`)
+	assert.Equal(t, "private, max-age=30", alice.Header().Get("Cache-Control"))
+	assert.Equal(t, int32(2), calls.Load())
+
+	again := doHTTPCacheRequest(router, http.MethodGet, "/api/profile", "X-User", "alice")
+	assert.Equal(t, "HIT", again.Header().Get(cacheStatusHeader))
+	assert.Equal(t, alice.Body.String(), again.Body.String())
+	assert.Equal(t, int32(2), calls.Load())
+
+	zh := doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1", "Accept-Language", "zh")
+	en := doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1", "Accept-Language", "en")
+	assert.Equal(t, "MISS", en.Header().Get(cacheStatusHeader))
+	assert.Contains(t, zh.Body.String(), `"lang":"zh"`)
+	assert.Contains(t, en.Body.String(), `"lang":"en"`)
+	assert.Equal(t, "HIT", doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1", "Accept-Language", "zh").Header().Get(cacheStatusHeader))
+}
+
+// TestHTTPCache_PrivatePerUserWithoutUser 测试没有用户 ID 时不使用按用户区分的缓存
+//
+// 【功能点】验证缓存中间件在认证之前执行、请求中还没有用户 ID 时，privatePerUser 规则不读取也不写入缓存
+// 【测试流程】
+//  1. 缓存中间件注册为全局中间件，用户 ID 在路由的处理链中才设置
+//  2. alice、bob 依次请求 /api/profile，断言每次都执行处理函数、没有 X-Cache 响应头、响应中的用户各自正确
+//  3. 断言存储中没有以空用户 ID 为键的缓存
+func TestHTTPCache_PrivatePerUserWithoutUser(t *testing.T) {
+	store := httpcache.NewMemoryStore(time.Minute)
+	t.Cleanup(func() { _ = store.Close() })
+	cfg := newHTTPCacheConfig()
+	var calls atomic.Int32
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { handleHTTPCache(c, store, cfg) })
+	router.GET("/api/profile", func(c *gin.Context) {
+		ginContext.SetUserID(c, c.GetHeader("X-User"))
+		calls.Add(1)
+		userID, _ := ginContext.GetUserID(c)
+		c.JSON(http.StatusOK, gin.H{"user": userID})
+	})
+
+	alice := doHTTPCacheRequest(router, http.MethodGet, "/api/profile", "X-User", "alice")
+	bob := doHTTPCacheRequest(router, http.MethodGet, "/api/profile", "X-User", "bob")
+	assert.Contains(t, alice.Body.String(), `"user":"alice"`)
+	assert.Contains(t, bob.Body.String(), `"user":"bob"`)
+	assert.Empty(t, bob.Header().Get(cacheStatusHeader))
+	assert.Equal(t, int32(2), calls.Load())
+
+	entry, err := store.Get(context.Background(), httpCachePathPrefix("/api/profile")+"#")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+}
+
+// TestHTTPCache_PurgeOnPost 测试写请求删除缓存
+//
+// 【功能点】验证同一路径的 POST 请求删除该路径的所有缓存，其他路径的缓存不受影响
+// 【测试流程】
+//  1. 缓存 /api/articles/1 的两个查询参数版本和 /api/articles/2
+//  2. POST /api/articles/1，断言 /api/articles/1 的请求重新执行处理函数，/api/articles/2 仍命中缓存
+func TestHTTPCache_PurgeOnPost(t *testing.T) {
+	router, calls := createHTTPCacheTestRouter(t, httpcache.NewMemoryStore(time.Minute), newHTTPCacheConfig())
+
+	doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1")
+	doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1?page=2")
+	doHTTPCacheRequest(router, http.MethodGet, "/api/articles/2")
+	require.Equal(t, int32(3), calls.Load())
+
+	w := doHTTPCacheRequest(router, http.MethodPost, "/api/articles/1")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	assert.Equal(t, "MISS", doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1").Header().Get(cacheStatusHeader))
+	assert.Equal(t, "MISS", doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1?page=2").Header().Get(cacheStatusHeader))
+	assert.Equal(t, "HIT", doHTTPCacheRequest(router, http.MethodGet, "/api/articles/2").Header().Get(cacheStatusHeader))
+	assert.Equal(t, int32(5), calls.Load())
+}
+
+// TestHTTPCache_NotCacheable 测试不缓存的响应
+//
+// 【功能点】验证非 200 响应、调用 ginContext.NoCache 的请求、超过 maxBodyBytes 的响应不缓存且正常返回
+// 【测试流程】
+//  1. 返回 404 的请求重复两次，断言都执行处理函数，状态码为 404，不带 ETag
+//  2. 调用 NoCache 的请求重复两次，断言都执行处理函数
+//  3. 响应体 100 字节超过 maxBodyBytes（64），断言响应体完整，重复请求仍执行处理函数
+func TestHTTPCache_NotCacheable(t *testing.T) {
+	router, calls := createHTTPCacheTestRouter(t, httpcache.NewMemoryStore(time.Minute), newHTTPCacheConfig())
+
+	for i := 0; i < 2; i++ {
+		w := doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1?status=404")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), `"version"`)
+	}
+	assert.Equal(t, int32(2), calls.Load())
+
+	for i := 0; i < 2; i++ {
+		w := doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1?nocache=1")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(cacheStatusHeader))
+	}
+	assert.Equal(t, int32(4), calls.Load())
+
+	for i := 0; i < 2; i++ {
+		w := doHTTPCacheRequest(router, http.MethodGet, "/api/large")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, strings.Repeat("x", 100), w.Body.String())
+	}
+	assert.Equal(t, int32(6), calls.Load())
+}
+
+// TestHTTPCache_EntryHeaders 测试写入缓存的响应头
+//
+// 【功能点】验证缓存只保存 Content-Type 和处理函数设置的响应头，之前的中间件按请求设置的 Access-Control-Allow-Origin 不会复制到其他请求
+// 【测试流程】
+//  1. 缓存中间件之前的中间件只为 https://a.example 设置 Access-Control-Allow-Origin，处理函数设置 X-Article-Version
+//  2. Origin 为 https://a.example 的请求写入缓存，断言缓存中的响应头包含 Content-Type、X-Article-Version，不包含 Access-Control-Allow-Origin
+//  3. Origin 为 https://b.example 的请求命中缓存，断言没有 Access-Control-Allow-Origin，Content-Type、X-Article-Version 与首次一致
+func TestHTTPCache_EntryHeaders(t *testing.T) {
+	store := httpcache.NewMemoryStore(time.Minute)
+	t.Cleanup(func() { _ = store.Close() })
+	cfg := newHTTPCacheConfig()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if origin := c.GetHeader("Origin"); origin == "https://a.example" {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		c.Next()
+	})
+	router.Use(func(c *gin.Context) {
+		handleHTTPCache(c, store, cfg)
+	})
+	router.GET("/api/articles/:id", func(c *gin.Context) {
+		c.Header("X-Article-Version", "3")
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+
+	first := doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1", "Origin", "https://a.example")
+	require.Equal(t, "MISS", first.Header().Get(cacheStatusHeader))
+	assert.Equal(t, "https://a.example", first.Header().Get("Access-Control-Allow-Origin"))
+
+	entry, err := store.Get(context.Background(), "/api/articles/1?|Accept-Language=")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, "application/json; charset=utf-8", entry.Header.Get("Content-Type"))
+	assert.Equal(t, "3", entry.Header.Get("X-Article-Version"))
+	assert.Empty(t, entry.Header.Values("Access-Control-Allow-Origin"))
+
+	second := doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1", "Origin", "https://b.example")
+	require.Equal(t, "HIT", second.Header().Get(cacheStatusHeader))
+	assert.Empty(t, second.Header().Values("Access-Control-Allow-Origin"))
+	assert.Equal(t, "application/json; charset=utf-8", second.Header().Get("Content-Type"))
+	assert.Equal(t, "3", second.Header().Get("X-Article-Version"))
+}
+
+// TestHTTPCache_RedisStore 测试 Redis 存储
+//
+// 【功能点】验证 Redis 存储的缓存命中和按路径删除，路径中的通配符被转义
+// 【测试流程】
+//  1. 使用 miniredis 创建 Redis 存储，请求两次，断言第二次命中缓存
+//  2. 写入路径包含 * 的缓存，删除 /api/articles/1 的缓存，断言其他键不受影响
+func TestHTTPCache_RedisStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	store := httpcache.NewRedisStore(client, "http_cache:")
+	router, calls := createHTTPCacheTestRouter(t, store, newHTTPCacheConfig())
+
+	first := doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1")
+	second := doHTTPCacheRequest(router, http.MethodGet, "/api/articles/1")
+	assert.Equal(t, "HIT", second.Header().Get(cacheStatusHeader))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, int32(1), calls.Load())
+
+	ctx := context.Background()
+	require.NoError(t, store.Set(ctx, "/api/articles/1*?", &httpcache.Entry{Status: http.StatusOK}, time.Minute))
+	require.NoError(t, store.Purge(ctx, "/api/articles/1?"))
+	assert.False(t, mr.Exists("http_cache:/api/articles/1?"))
+	assert.True(t, mr.Exists("http_cache:/api/articles/1*?"))
+}
@@ -0,0 +1,54 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现国际化语言协商中间件
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/i18n"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// I18nHandler 国际化语言协商中间件
+// 为每个请求确定语言区域，写入请求上下文（ginContext.GetLocale / i18n.Locale 可读取），
+// 并设置 Content-Language 响应头；response 包和 ExceptionHandler 据此解析响应消息
+// 配置项通过 app.BaseConfig.I18n 进行设置
+//
+// 语言区域按以下优先级确定，指定的语言未注册消息目录时继续尝试下一项：
+// 1. 查询参数（默认 lang），如 ?lang=en-US
+// 2. 请求头（默认 X-Locale）
+// 3. Accept-Language 请求头，按权重协商
+// 4. 默认语言区域（i18n.defaultLocale，默认 zh-CN）
+//
+// 使用示例：
+//
+//	service:
+//	  middlewares:
+//	    - "exceptionHandler"
+//	    - "i18nHandler"
+//	i18n:
+//	  defaultLocale: "zh-CN"
+func I18nHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := negotiateLocale(c)
+		ginContext.SetLocale(c, locale)
+		c.Header("Content-Language", locale)
+		c.Next()
+	}
+}
+
+// negotiateLocale 按查询参数、请求头、Accept-Language 的优先级确定请求的语言区域
+func negotiateLocale(c *gin.Context) string {
+	cfg := app.BaseConfig.I18n
+	if tag := c.Query(cfg.GetQueryParam()); tag != "" {
+		if locale, ok := i18n.Match(tag); ok {
+			return locale
+		}
+	}
+	if tag := c.GetHeader(cfg.GetHeader()); tag != "" {
+		if locale, ok := i18n.Match(tag); ok {
+			return locale
+		}
+	}
+	return i18n.Negotiate(c.GetHeader("Accept-Language"))
+}
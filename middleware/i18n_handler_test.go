@@ -0,0 +1,169 @@
+// Package middleware 国际化中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 I18nHandler 语言协商中间件的单元测试。
+//
+// 测试覆盖内容：
+// 1. 按 Accept-Language 协商语言区域，响应消息和 Content-Language 随之变化
+// 2. 查询参数、自定义请求头覆盖 Accept-Language，未注册的语言被忽略
+// 3. 异常处理中间件的默认消息和 validator 校验消息按协商的语言区域输出
+//
+// 运行测试：go test -v ./middleware/... -run I18n
+// ==================================================
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// setupI18nTestRouter 创建注册了 ExceptionHandler 和 I18nHandler 的路由
+func setupI18nTestRouter(t *testing.T, cfg config.I18nConfig) *gin.Engine {
+	t.Helper()
+	originalConfig := app.BaseConfig
+	app.BaseConfig = config.BaseConfig{I18n: cfg}
+	t.Cleanup(func() { app.BaseConfig = originalConfig })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ExceptionHandler(), I18nHandler())
+	router.GET("/ok", func(c *gin.Context) {
+		response.Ok(c)
+	})
+	router.GET("/locale", func(c *gin.Context) {
+		c.String(http.StatusOK, ginContext.GetLocale(c))
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	router.POST("/validate", func(c *gin.Context) {
+		var req struct {
+			Name string `json:"name" binding:"required"`
+			Age  int    `json:"age" binding:"gte=18"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			panic(err)
+		}
+		response.Ok(c)
+	})
+	return router
+}
+
+// doI18nRequest 发送请求，返回响应记录
+func doI18nRequest(router *gin.Engine, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decodeI18nMsg 解析响应体中的 msg 字段
+func decodeI18nMsg(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Msg string `json:"msg"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("解析响应失败: %v, body: %s", err, w.Body.String())
+	}
+	return body.Msg
+}
+
+// ==================== 测试用例 ====================
+
+// TestI18nHandler_AcceptLanguage 测试按 Accept-Language 协商
+//
+// 【功能点】验证响应消息按 Accept-Language 协商的语言输出，并设置 Content-Language
+// 【测试流程】
+//  1. 不带 Accept-Language 请求，断言返回默认语言 zh-CN 的消息
+//  2. Accept-Language: fr;q=0.9, en-GB;q=0.8，断言协商为 en-US，返回英文消息
+func TestI18nHandler_AcceptLanguage(t *testing.T) {
+	router := setupI18nTestRouter(t, config.I18nConfig{})
+
+	w := doI18nRequest(router, http.MethodGet, "/ok", "", nil)
+	if msg := decodeI18nMsg(t, w); msg != "操作成功" {
+		t.Errorf("期望消息 操作成功, 实际 %s", msg)
+	}
+	if lang := w.Header().Get("Content-Language"); lang != "zh-CN" {
+		t.Errorf("期望 Content-Language zh-CN, 实际 %s", lang)
+	}
+
+	w = doI18nRequest(router, http.MethodGet, "/ok", "", map[string]string{"Accept-Language": "fr;q=0.9, en-GB;q=0.8"})
+	if msg := decodeI18nMsg(t, w); msg != "Success" {
+		t.Errorf("期望消息 Success, 实际 %s", msg)
+	}
+	if lang := w.Header().Get("Content-Language"); lang != "en-US" {
+		t.Errorf("期望 Content-Language en-US, 实际 %s", lang)
+	}
+}
+
+// TestI18nHandler_Override 测试查询参数和请求头覆盖
+//
+// 【功能点】验证查询参数优先于请求头，请求头优先于 Accept-Language，未注册的语言被忽略，支持自定义参数名
+// 【测试流程】以不同组合请求 /locale，断言写入请求上下文的语言区域
+func TestI18nHandler_Override(t *testing.T) {
+	router := setupI18nTestRouter(t, config.I18nConfig{QueryParam: "locale", Header: "X-Lang"})
+
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		want    string
+	}{
+		{"查询参数优先", "/locale?locale=en", map[string]string{"X-Lang": "zh-CN", "Accept-Language": "zh-CN"}, "en-US"},
+		{"请求头优先于 Accept-Language", "/locale", map[string]string{"X-Lang": "en_us", "Accept-Language": "zh-CN"}, "en-US"},
+		{"未注册的查询参数被忽略", "/locale?locale=fr", map[string]string{"Accept-Language": "en"}, "en-US"},
+		{"默认参数名不生效", "/locale?lang=en", nil, "zh-CN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doI18nRequest(router, http.MethodGet, tt.path, "", tt.headers)
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("期望语言区域 %s, 实际 %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestI18nHandler_Exception 测试异常消息的本地化
+//
+// 【功能点】验证 ExceptionHandler 的默认异常消息和 validator 校验消息按协商的语言输出
+// 【测试流程】
+//  1. 以 en-US 请求触发 panic 的接口，断言返回 Internal server error
+//  2. 以 en-US、zh-CN 分别提交不合法参数，断言校验消息的语言
+func TestI18nHandler_Exception(t *testing.T) {
+	router := setupI18nTestRouter(t, config.I18nConfig{})
+	en := map[string]string{"Accept-Language": "en-US"}
+
+	w := doI18nRequest(router, http.MethodGet, "/panic", "", en)
+	if msg := decodeI18nMsg(t, w); msg != "Internal server error" {
+		t.Errorf("期望消息 Internal server error, 实际 %s", msg)
+	}
+
+	w = doI18nRequest(router, http.MethodPost, "/validate", `{"age":1}`, en)
+	want := "[Invalid parameters]; Name is required; Age must be greater than or equal to 18"
+	if msg := decodeI18nMsg(t, w); msg != want {
+		t.Errorf("期望消息 %s, 实际 %s", want, msg)
+	}
+
+	w = doI18nRequest(router, http.MethodPost, "/validate", `{"age":1}`, map[string]string{"Accept-Language": "zh-CN"})
+	want = "【参数校验不通过】; Name不能为空; Age的值必须大于或等于18"
+	if msg := decodeI18nMsg(t, w); msg != want {
+		t.Errorf("期望消息 %s, 实际 %s", want, msg)
+	}
+}
@@ -0,0 +1,193 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现幂等键（Idempotency-Key）中间件，防止客户端重试导致写请求重复执行
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/idempotency"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// maxIdempotencyKeyLength 幂等键的最大长度
+const maxIdempotencyKeyLength = 255
+
+var (
+	idempotencyOnce  sync.Once
+	idempotencyStore idempotency.Store
+)
+
+// initIdempotencyStore 初始化幂等键存储（单例）
+func initIdempotencyStore() {
+	idempotencyOnce.Do(func() {
+		cfg := app.BaseConfig.Idempotency
+
+		switch cfg.GetStore() {
+		case "memory":
+			idempotencyStore = idempotency.NewMemoryStore(time.Duration(cfg.GetCleanupInterval()) * time.Second)
+			logger.Info("[幂等键] 使用内存存储")
+		default:
+			if app.Redis != nil {
+				idempotencyStore = idempotency.NewRedisStore(app.Redis, cfg.GetKeyPrefix())
+				logger.Info("[幂等键] 使用 Redis 存储")
+			} else {
+				logger.Warn("[幂等键] Redis 未初始化，降级为内存存储")
+				idempotencyStore = idempotency.NewMemoryStore(time.Duration(cfg.GetCleanupInterval()) * time.Second)
+			}
+		}
+	})
+}
+
+// IdempotencyHandler 幂等键中间件
+// 对匹配 idempotency.paths 的 POST / PUT / PATCH 请求，按请求头中的幂等键保证处理函数只执行一次
+// 配置项通过 app.BaseConfig.Idempotency 进行设置
+//
+// 功能特性：
+// - 幂等键按用户（ginContext.GetUserID）和请求路径隔离，不同用户使用相同的幂等键互不影响
+// - 首次请求处理完成后保存状态码和响应体，重复请求直接重放并带上 Idempotency-Replayed: true 响应头
+// - 首次请求仍在处理中时，重复请求返回 response.ResponseRequestInFlight 响应码（开启 service.useHTTPStatus 时 HTTP 状态码为 409）
+// - 首次请求返回 5xx 或发生 panic 时释放幂等键，客户端可以使用同一个幂等键重试
+// - 存储出错时记录日志并放行请求，不影响业务
+//
+// 注意：按用户隔离依赖认证中间件先设置用户 ID，需要按用户隔离时应在认证中间件之后注册，
+// 如在路由组上 group.Use(auth, middleware.IdempotencyHandler())
+//
+// 使用示例：
+//
+//	在配置文件中启用：
+//	idempotency:
+//	  enabled: true
+//	  required: true
+//	  ttl: 86400
+//	  paths:
+//	    - "/api/orders"
+//	    - "/api/payments/*"
+//	  store: "redis"
+func IdempotencyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := app.BaseConfig.Idempotency
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+
+		initIdempotencyStore()
+		handleIdempotency(c, idempotencyStore, &cfg)
+	}
+}
+
+// handleIdempotency 按幂等键处理请求
+func handleIdempotency(c *gin.Context, store idempotency.Store, cfg *config.IdempotencyConfig) {
+	if !isIdempotentMethod(c.Request.Method) || !matchAuditPath(c.Request.URL.Path, cfg.Paths) {
+		c.Next()
+		return
+	}
+
+	headerName := cfg.GetHeaderName()
+	key := strings.TrimSpace(c.GetHeader(headerName))
+	switch {
+	case key == "" && !cfg.Required:
+		c.Next()
+		return
+	case key == "":
+		response.WriteError(c, response.ResponseParamInvalid, response.WithMessage("缺少幂等键请求头: "+headerName))
+		return
+	case len(key) > maxIdempotencyKeyLength:
+		response.WriteError(c, response.ResponseParamInvalid, response.WithMessage("幂等键长度不能超过 255"))
+		return
+	}
+
+	storeKey := idempotencyStoreKey(c, key)
+	ttl := time.Duration(cfg.GetTTL()) * time.Second
+	ctx := context.WithoutCancel(c.Request.Context())
+	record, err := store.Claim(ctx, storeKey, ttl)
+	if err != nil {
+		logger.Error("[幂等键] 占用幂等键失败, key: %s, error: %v", storeKey, err)
+		c.Next()
+		return
+	}
+	if record != nil {
+		if !record.Completed {
+			response.WriteError(c, response.ResponseRequestInFlight)
+			return
+		}
+		replayIdempotentResponse(c, record)
+		return
+	}
+
+	limit := cfg.GetMaxBodyBytes()
+	writer := &auditBodyWriter{ResponseWriter: c.Writer, limit: limit}
+	c.Writer = writer
+	completed := false
+	defer func() {
+		// 处理函数 panic、返回 5xx 或保存响应失败时释放幂等键，允许客户端重试
+		if !completed {
+			if err := store.Release(ctx, storeKey); err != nil {
+				logger.Error("[幂等键] 释放幂等键失败, key: %s, error: %v", storeKey, err)
+			}
+		}
+	}()
+
+	c.Next()
+
+	if writer.Status() >= http.StatusInternalServerError {
+		return
+	}
+	result := &idempotency.Record{
+		Completed:   true,
+		Status:      writer.Status(),
+		ContentType: writer.Header().Get("Content-Type"),
+	}
+	if writer.size <= int64(limit) {
+		result.Body = writer.body.Bytes()
+	} else {
+		logger.Warn("[幂等键] 响应体超过 %d 字节，只保存状态码, key: %s", limit, storeKey)
+	}
+	if err := store.Complete(ctx, storeKey, result, ttl); err != nil {
+		logger.Error("[幂等键] 保存响应失败, key: %s, error: %v", storeKey, err)
+		return
+	}
+	completed = true
+}
+
+// isIdempotentMethod 判断请求方法是否需要幂等处理
+func isIdempotentMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+// idempotencyStoreKey 生成存储键，格式 "{userID}:{path}:{key}"，未登录的请求用户部分为 "anonymous"
+func idempotencyStoreKey(c *gin.Context, key string) string {
+	userID, ok := ginContext.GetUserID(c)
+	if !ok || userID == "" {
+		userID = "anonymous"
+	}
+	return userID + ":" + c.Request.URL.Path + ":" + key
+}
+
+// replayIdempotentResponse 重放首次请求保存的响应
+func replayIdempotentResponse(c *gin.Context, record *idempotency.Record) {
+	c.Header("Idempotency-Replayed", "true")
+	if record.ContentType != "" {
+		c.Header("Content-Type", record.ContentType)
+	}
+	c.Status(record.Status)
+	_, _ = c.Writer.Write(record.Body)
+	c.Abort()
+}
+
+// CloseIdempotencyStore 关闭幂等键存储
+func CloseIdempotencyStore() error {
+	if idempotencyStore != nil {
+		return idempotencyStore.Close()
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Package middleware 幂等键中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含幂等键中间件的单元测试，使用内存存储和 miniredis，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 并发的重复请求只执行一次处理函数，其余请求返回 409 或重放的响应
+// 2. 重复请求重放的响应与首次响应一致，并带上 Idempotency-Replayed 响应头
+// 3. 幂等键过期后可以再次使用
+// 4. 缺少幂等键、不匹配的路径和方法、5xx 响应、不同用户的处理
+//
+// 运行测试：go test -v ./middleware/... -run Idempotency
+// ==================================================
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/idempotency"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// createIdempotencyTestRouter 创建幂等键测试路由
+// /api/orders 每次执行返回递增的订单号，handler 在处理前调用（可为 nil）
+func createIdempotencyTestRouter(store idempotency.Store, cfg *config.IdempotencyConfig, handler func(c *gin.Context)) (*gin.Engine, *atomic.Int32) {
+	var calls atomic.Int32
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			ginContext.SetUserID(c, user)
+		}
+		handleIdempotency(c, store, cfg)
+	})
+	order := func(c *gin.Context) {
+		n := calls.Add(1)
+		if handler != nil {
+			handler(c)
+			if c.Writer.Written() {
+				return
+			}
+		}
+		c.JSON(http.StatusCreated, gin.H{"orderId": n})
+	}
+	router.POST("/api/orders", order)
+	router.GET("/api/orders", order)
+	router.POST("/api/other", order)
+	return router, &calls
+}
+
+// doIdempotencyRequest 发送带幂等键的请求
+func doIdempotencyRequest(router *gin.Engine, method, path, key, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// ==================== 测试用例 ====================
+
+// TestIdempotency_ConcurrentDuplicates 测试并发的重复请求
+//
+// 【功能点】验证使用相同幂等键的并发请求只执行一次处理函数
+// 【测试流程】
+//  1. 处理函数阻塞直到所有请求都已发出
+//  2. 并发发送 10 个相同幂等键的请求
+//  3. 断言处理函数只执行 1 次，1 个请求返回 201，其余请求返回 409 和专用响应码
+func TestIdempotency_ConcurrentDuplicates(t *testing.T) {
+	response.SetUseHTTPStatus(true)
+	defer response.SetUseHTTPStatus(false)
+	store := idempotency.NewMemoryStore(time.Minute)
+	defer store.Close()
+	release := make(chan struct{})
+	router, calls := createIdempotencyTestRouter(store, &config.IdempotencyConfig{Paths: []string{"/api/orders"}}, func(c *gin.Context) {
+		<-release
+	})
+
+	const n = 10
+	codes := make(chan *httptest.ResponseRecorder, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- doIdempotencyRequest(router, http.MethodPost, "/api/orders", "order-1", "")
+		}()
+	}
+	// 等待其余请求都返回 409 后再放行首次请求
+	for i := 0; i < n-1; i++ {
+		w := <-codes
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var resp response.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, response.ResponseRequestInFlight.GetCode(), resp.Code)
+	}
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, http.StatusCreated, (<-codes).Code)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+// TestIdempotency_Replay 测试重放首次响应
+//
+// 【功能点】验证首次请求完成后，重复请求不执行处理函数，重放的响应与首次一致
+// 【测试流程】
+//  1. 发送首次请求，记录状态码、响应体和 Content-Type
+//  2. 使用相同幂等键再次请求，断言状态码、响应体、Content-Type 相同，带 Idempotency-Replayed 响应头
+//  3. 断言处理函数只执行 1 次；不同用户使用相同幂等键时处理函数再次执行
+func TestIdempotency_Replay(t *testing.T) {
+	store := idempotency.NewMemoryStore(time.Minute)
+	defer store.Close()
+	router, calls := createIdempotencyTestRouter(store, &config.IdempotencyConfig{Paths: []string{"/api/*"}}, nil)
+
+	first := doIdempotencyRequest(router, http.MethodPost, "/api/orders", "order-1", "alice")
+	require.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get("Idempotency-Replayed"))
+
+	replay := doIdempotencyRequest(router, http.MethodPost, "/api/orders", "order-1", "alice")
+	assert.Equal(t, first.Code, replay.Code)
+	assert.Equal(t, first.Body.String(), replay.Body.String())
+	assert.Equal(t, first.Header().Get("Content-Type"), replay.Header().Get("Content-Type"))
+	assert.Equal(t, "true", replay.Header().Get("Idempotency-Replayed"))
+	assert.Equal(t, int32(1), calls.Load())
+
+	other := doIdempotencyRequest(router, http.MethodPost, "/api/orders", "order-1", "bob")
+	assert.Equal(t, http.StatusCreated, other.Code)
+	assert.NotEqual(t, first.Body.String(), other.Body.String())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// TestIdempotency_Expiry 测试幂等键过期
+//
+// 【功能点】验证幂等键超过 TTL 后可以再次使用，处理函数重新执行
+// 【测试流程】
+//  1. 使用 miniredis 作为存储，TTL 为 60 秒，发送首次请求
+//  2. 时间前进 30 秒，断言重复请求仍重放首次响应
+//  3. 时间前进到 TTL 之后，断言处理函数再次执行并返回新的响应
+func TestIdempotency_Expiry(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	store := idempotency.NewRedisStore(client, "idempotency:")
+	router, calls := createIdempotencyTestRouter(store, &config.IdempotencyConfig{TTL: 60, Paths: []string{"/api/orders"}}, nil)
+
+	first := doIdempotencyRequest(router, http.MethodPost, "/api/orders", "order-1", "")
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	mr.FastForward(30 * time.Second)
+	replay := doIdempotencyRequest(router, http.MethodPost, "/api/orders", "order-1", "")
+	assert.Equal(t, "true", replay.Header().Get("Idempotency-Replayed"))
+	assert.Equal(t, first.Body.String(), replay.Body.String())
+
+	mr.FastForward(31 * time.Second)
+	again := doIdempotencyRequest(router, http.MethodPost, "/api/orders", "order-1", "")
+	assert.Equal(t, http.StatusCreated, again.Code)
+	assert.Empty(t, again.Header().Get("Idempotency-Replayed"))
+	assert.NotEqual(t, first.Body.String(), again.Body.String())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// TestIdempotency_Bypass 测试不需要幂等处理的请求
+//
+// 【功能点】验证缺少幂等键、不匹配的路径和方法、5xx 响应的处理
+// 【测试流程】
+//  1. required 为 false 时缺少幂等键的请求直接放行；为 true 时返回参数校验不通过
+//  2. GET 请求和不匹配的路径即使携带幂等键也每次执行
+//  3. 处理函数返回 500 后释放幂等键，使用相同幂等键重试时再次执行
+func TestIdempotency_Bypass(t *testing.T) {
+	store := idempotency.NewMemoryStore(time.Minute)
+	defer store.Close()
+	cfg := &config.IdempotencyConfig{Paths: []string{"/api/orders"}}
+	var fail atomic.Bool
+	router, calls := createIdempotencyTestRouter(store, cfg, func(c *gin.Context) {
+		if fail.Load() {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db down"})
+		}
+	})
+
+	assert.Equal(t, http.StatusCreated, doIdempotencyRequest(router, http.MethodPost, "/api/orders", "", "").Code)
+	cfg.Required = true
+	w := doIdempotencyRequest(router, http.MethodPost, "/api/orders", "", "")
+	var resp response.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, response.ResponseParamInvalid.GetCode(), resp.Code)
+	assert.Equal(t, int32(1), calls.Load())
+
+	for i := 0; i < 2; i++ {
+		doIdempotencyRequest(router, http.MethodGet, "/api/orders", "key", "")
+		doIdempotencyRequest(router, http.MethodPost, "/api/other", "key", "")
+	}
+	assert.Equal(t, int32(5), calls.Load())
+
+	fail.Store(true)
+	assert.Equal(t, http.StatusInternalServerError, doIdempotencyRequest(router, http.MethodPost, "/api/orders", "retry", "").Code)
+	fail.Store(false)
+	retry := doIdempotencyRequest(router, http.MethodPost, "/api/orders", "retry", "")
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Empty(t, retry.Header().Get("Idempotency-Replayed"))
+	assert.Equal(t, int32(7), calls.Load())
+}
@@ -0,0 +1,116 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现维护模式中间件，维护期间对健康检查接口和放行的请求以外的请求返回维护中响应码
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/netip"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/response"
+	"github.com/zzsen/gin_core/utils/netutil"
+)
+
+// MaintenanceAdminPath 维护模式管理接口的路径，位于 service.routePrefix 之下，维护期间始终放行
+const MaintenanceAdminPath = "/admin/maintenance"
+
+// maintenanceGate 维护模式中间件的放行规则
+type maintenanceGate struct {
+	healthPath  string
+	adminPath   string
+	allowPaths  []string
+	allowIPs    []netip.Prefix
+	token       string
+	tokenHeader string
+	retryAfter  string
+}
+
+// activeMaintenanceGate 最近一次创建的维护模式中间件的放行规则，限流中间件据此跳过将被拒绝的请求
+var activeMaintenanceGate atomic.Pointer[maintenanceGate]
+
+// MaintenanceHandler 维护模式中间件
+// 维护模式开启（maintenance.enabled 或运行时通过 app.SetMaintenanceMode 切换）时，
+// 对健康检查接口和放行的请求以外的请求返回 Retry-After 响应头和 response.ResponseMaintenance 响应码（开启 service.useHTTPStatus 时 HTTP 状态码为 503）。
+// 配置项通过 app.BaseConfig.Maintenance 进行设置，在创建中间件时读取；维护状态在每个请求中读取，切换后立即生效
+//
+// 功能特性：
+// - 健康检查接口（/healthy、/healthy/*）和维护模式管理接口（/admin/maintenance）始终放行，探针和运维操作不受影响
+// - maintenance.allowPaths 匹配的路径、maintenance.allowIPs 中的客户端 IP、携带 maintenance.allowHeaderToken 令牌的请求放行
+// - 被拒绝的请求不计入限流配额，与 rateLimitHandler 的注册顺序无关
+// - 响应消息为维护状态中的消息，为空时使用按语言区域解析的 51503 响应码消息
+//
+// 使用示例：
+//
+//	在配置文件中配置：
+//	maintenance:
+//	  retryAfterSeconds: 600
+//	  allowIPs: ["10.0.0.0/8"]
+//	  allowHeaderToken: "${MAINTENANCE_TOKEN}"
+//	  middleware: adminAuth
+//	service:
+//	  middlewares:
+//	    - maintenanceHandler
+func MaintenanceHandler() gin.HandlerFunc {
+	cfg := app.BaseConfig.Maintenance
+	allowIPs, err := netutil.ParseProxies(cfg.AllowIPs)
+	if err != nil {
+		logger.Error("[维护模式] maintenance.allowIPs 配置有误，已忽略无法解析的地址: %v", err)
+	}
+	prefix := app.BaseConfig.Service.RoutePrefix
+	gate := &maintenanceGate{
+		healthPath:  path.Join("/", prefix, "healthy"),
+		adminPath:   path.Join("/", prefix, MaintenanceAdminPath),
+		allowPaths:  cfg.AllowPaths,
+		allowIPs:    allowIPs,
+		token:       cfg.AllowHeaderToken,
+		tokenHeader: cfg.GetAllowHeader(),
+		retryAfter:  strconv.Itoa(cfg.GetRetryAfterSeconds()),
+	}
+	activeMaintenanceGate.Store(gate)
+
+	return func(c *gin.Context) {
+		state := app.MaintenanceStatus()
+		if !state.Enabled || gate.allowed(c) {
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", gate.retryAfter)
+		response.WriteError(c, response.ResponseMaintenance, response.WithMessage(state.Message))
+	}
+}
+
+// allowed 判断请求在维护期间是否放行：健康检查接口、管理接口、放行的路径、IP 和携带放行令牌的请求
+func (g *maintenanceGate) allowed(c *gin.Context) bool {
+	requestPath := c.Request.URL.Path
+	if requestPath == g.healthPath || strings.HasPrefix(requestPath, g.healthPath+"/") || requestPath == g.adminPath {
+		return true
+	}
+	if matchAuditPath(requestPath, g.allowPaths) {
+		return true
+	}
+	if g.token != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader(g.tokenHeader)), []byte(g.token)) == 1 {
+		return true
+	}
+	if len(g.allowIPs) > 0 {
+		if addr, ok := netutil.ParseIP(netutil.ClientIP(c)); ok {
+			for _, prefix := range g.allowIPs {
+				if prefix.Contains(addr) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// maintenanceRejects 判断请求是否会被维护模式中间件拒绝，未创建维护模式中间件时为 false
+func maintenanceRejects(c *gin.Context) bool {
+	gate := activeMaintenanceGate.Load()
+	return gate != nil && app.MaintenanceStatus().Enabled && !gate.allowed(c)
+}
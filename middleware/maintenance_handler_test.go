@@ -0,0 +1,156 @@
+// Package middleware 维护模式中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含维护模式中间件的单元测试，使用内存限流器，不需要外部依赖。
+// 管理接口的切换见 core 包的 TestMaintenance_AdminToggle。
+//
+// 测试覆盖内容：
+// 1. 维护模式关闭时放行所有请求，开启后返回 503、Retry-After 和 51503 响应码
+// 2. 健康检查接口、放行的路径、IP 和携带放行令牌的请求在维护期间放行
+// 3. 维护期间被拒绝的请求不计入限流配额
+//
+// 运行测试：go test -v ./middleware/... -run Maintenance
+// ==================================================
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// newMaintenanceTestRouter 以指定配置创建维护模式测试路由并开启 service.useHTTPStatus，测试结束后恢复配置和维护状态
+// 路由前缀为 /api，注册 /api/healthy、/api/healthy/ready、/api/orders、/api/status 四个接口
+func newMaintenanceTestRouter(t *testing.T, cfg config.MaintenanceConfig, handlers ...gin.HandlerFunc) *gin.Engine {
+	originalConfig := app.BaseConfig
+	t.Cleanup(func() {
+		app.BaseConfig = originalConfig
+		app.ResetMaintenanceMode()
+		activeMaintenanceGate.Store(nil)
+		response.SetUseHTTPStatus(false)
+	})
+	app.BaseConfig = config.BaseConfig{Service: config.ServiceInfo{RoutePrefix: "/api"}, Maintenance: cfg}
+	app.ResetMaintenanceMode()
+	response.SetUseHTTPStatus(true)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handlers...)
+	router.Use(MaintenanceHandler())
+	api := router.Group("/api")
+	for _, p := range []string{"/healthy", "/healthy/ready", "/orders", "/status"} {
+		api.GET(p, func(c *gin.Context) { response.Ok(c) })
+	}
+	return router
+}
+
+// serveMaintenance 以指定客户端 IP 和请求头发送 GET 请求
+func serveMaintenance(router *gin.Engine, path, ip string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = ip + ":12345"
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// ==================== MaintenanceHandler 单元测试 ====================
+
+// TestMaintenanceHandler_Toggle 测试维护模式的开启和关闭
+//
+// 【功能点】验证维护模式关闭时放行，开启后返回 503、Retry-After 和 51503 响应码，关闭后立即恢复
+// 【测试流程】
+//  1. 维护模式关闭时请求 /api/orders，断言 200
+//  2. 通过 app.SetMaintenanceMode 开启（消息为空），断言 503、默认 Retry-After 为 300、响应码为 51503、消息为 maintenance.message
+//  3. 关闭后断言 200
+//  4. 配置 enabled=true 且未在运行时切换时，断言直接返回 503
+func TestMaintenanceHandler_Toggle(t *testing.T) {
+	router := newMaintenanceTestRouter(t, config.MaintenanceConfig{Message: "系统升级中"})
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/api/orders", "192.0.2.1", nil).Code)
+
+	app.SetMaintenanceMode(true, "")
+	w := serveMaintenance(router, "/api/orders", "192.0.2.1", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "300", w.Header().Get("Retry-After"))
+	var body response.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, response.ResponseMaintenance.GetCode(), body.Code)
+	assert.Equal(t, "系统升级中", body.Msg)
+	assert.Equal(t, map[string]any{}, body.Data)
+
+	app.SetMaintenanceMode(false, "")
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/api/orders", "192.0.2.1", nil).Code)
+
+	router = newMaintenanceTestRouter(t, config.MaintenanceConfig{Enabled: true})
+	w = serveMaintenance(router, "/api/orders", "192.0.2.1", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, response.ResponseMaintenance.GetMsg(), body.Msg)
+}
+
+// TestMaintenanceHandler_Bypass 测试维护期间放行的请求
+//
+// 【功能点】验证健康检查接口、放行的路径、IP（含 CIDR）和携带放行令牌的请求在维护期间放行
+// 【测试流程】
+//  1. 配置 allowPaths=/api/status、allowIPs=10.1.0.0/16 和 203.0.113.7、allowHeaderToken=letmein，开启维护模式
+//  2. 断言 /api/healthy、/api/healthy/ready、/api/status 返回 200
+//  3. 断言 10.1.2.3、203.0.113.7 的请求返回 200，其他 IP 返回 503
+//  4. 断言携带正确令牌的请求返回 200，令牌错误的请求返回 503；自定义令牌请求头生效
+func TestMaintenanceHandler_Bypass(t *testing.T) {
+	router := newMaintenanceTestRouter(t, config.MaintenanceConfig{
+		AllowPaths:       []string{"/api/status"},
+		AllowIPs:         []string{"10.1.0.0/16", "203.0.113.7"},
+		AllowHeaderToken: "letmein",
+	})
+	app.SetMaintenanceMode(true, "维护中")
+
+	for _, p := range []string{"/api/healthy", "/api/healthy/ready", "/api/status"} {
+		assert.Equal(t, http.StatusOK, serveMaintenance(router, p, "192.0.2.1", nil).Code, p)
+	}
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/api/orders", "10.1.2.3", nil).Code)
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/api/orders", "203.0.113.7", nil).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serveMaintenance(router, "/api/orders", "10.2.0.1", nil).Code)
+
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/api/orders", "192.0.2.1", map[string]string{"X-Maintenance-Token": "letmein"}).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serveMaintenance(router, "/api/orders", "192.0.2.1", map[string]string{"X-Maintenance-Token": "wrong"}).Code)
+
+	router = newMaintenanceTestRouter(t, config.MaintenanceConfig{AllowHeaderToken: "letmein", AllowHeader: "X-Ops-Token"})
+	app.SetMaintenanceMode(true, "")
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/api/orders", "192.0.2.1", map[string]string{"X-Ops-Token": "letmein"}).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serveMaintenance(router, "/api/orders", "192.0.2.1", map[string]string{"X-Maintenance-Token": "letmein"}).Code)
+}
+
+// TestMaintenanceHandler_NotCountedByRateLimit 测试维护期间被拒绝的请求不计入限流配额
+//
+// 【功能点】验证限流中间件注册在维护模式中间件之前时，维护期间被拒绝的请求不消耗配额
+// 【测试流程】
+//  1. 启用突发容量为 1 的内存限流，在维护模式中间件之前注册限流中间件
+//  2. 开启维护模式，同一 IP 发送 5 个请求，断言均返回 503 且没有 X-RateLimit-Limit 响应头
+//  3. 关闭维护模式，断言第 1 个请求返回 200，第 2 个请求返回 429
+func TestMaintenanceHandler_NotCountedByRateLimit(t *testing.T) {
+	router := newMaintenanceTestRouter(t, config.MaintenanceConfig{}, RateLimitHandler())
+	app.BaseConfig.RateLimit = config.RateLimitConfig{Enabled: true, DefaultRate: 1, DefaultBurst: 1, Store: "memory"}
+
+	app.SetMaintenanceMode(true, "")
+	for i := 0; i < 5; i++ {
+		w := serveMaintenance(router, "/api/orders", "192.0.2.210", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	}
+
+	app.SetMaintenanceMode(false, "")
+	assert.Equal(t, http.StatusOK, serveMaintenance(router, "/api/orders", "192.0.2.210", nil).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serveMaintenance(router, "/api/orders", "192.0.2.210", nil).Code)
+}
@@ -0,0 +1,345 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现流量镜像中间件，将匹配的请求异步复制一份发送到影子服务，用于新版本的对比验证
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// MirroredHeader 镜像请求携带的请求头，值为 true；携带该请求头的请求不会被再次镜像
+const MirroredHeader = "X-Mirrored"
+
+// hopByHopHeaders 逐跳请求头，只对单个连接有效，不复制到镜像请求
+var hopByHopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// MirrorResult 镜像请求的结果，包含原请求的状态码和耗时，用于对比影子服务与当前服务的行为
+type MirrorResult struct {
+	Rule           config.MirrorRule // 匹配的镜像规则
+	TraceID        string            // 原请求的 traceId
+	Method         string            // 请求方法
+	Path           string            // 请求路径
+	Query          string            // 原始查询参数
+	PrimaryStatus  int               // 原请求的 HTTP 状态码
+	PrimaryLatency time.Duration     // 原请求的处理耗时
+	StatusCode     int               // 镜像请求的 HTTP 状态码，请求失败时为 0
+	Latency        time.Duration     // 镜像请求的耗时
+	Err            error             // 镜像请求失败的原因，如连接失败、超时
+}
+
+// MirrorResultHook 镜像请求结果的回调
+type MirrorResultHook func(result MirrorResult)
+
+var (
+	// mirrorHooksMu 保护 mirrorHooks 的并发访问
+	mirrorHooksMu sync.RWMutex
+	// mirrorHooks 已注册的镜像请求结果回调
+	mirrorHooks []MirrorResultHook
+	// mirrorDropped 镜像请求队列已满时丢弃的镜像请求数
+	mirrorDropped atomic.Uint64
+	// newMirrorRand 创建决定是否镜像请求的随机数生成器，测试中可替换为固定种子
+	newMirrorRand = func() *rand.Rand {
+		return rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+)
+
+// OnMirrorResult 注册镜像请求结果的回调，用于对比影子服务与当前服务的状态码和耗时
+// 回调在发送镜像请求的协程中按注册顺序执行，不影响原请求；回调 panic 时只记录日志，不影响其他回调
+func OnMirrorResult(hook MirrorResultHook) {
+	mirrorHooksMu.Lock()
+	defer mirrorHooksMu.Unlock()
+	mirrorHooks = append(mirrorHooks, hook)
+}
+
+// MirrorDroppedCount 返回镜像请求队列已满时累计丢弃的镜像请求数
+func MirrorDroppedCount() uint64 {
+	return mirrorDropped.Load()
+}
+
+// mirrorJob 等待发送的镜像请求
+type mirrorJob struct {
+	rule           *config.MirrorRule
+	traceID        string
+	method         string
+	path           string
+	query          string
+	header         http.Header
+	body           []byte
+	primaryStatus  int
+	primaryLatency time.Duration
+}
+
+// mirrorSender 发送镜像请求的协程池，队列已满时丢弃新的镜像请求
+type mirrorSender struct {
+	queue  chan mirrorJob
+	client *http.Client
+}
+
+// MirrorHandler 流量镜像中间件
+// 对匹配 mirror.rules 的请求正常处理，处理完成后将请求复制一份放入队列，由独立的协程发送到规则的 targetBaseURL，
+// 镜像请求的响应只用于记录和对比，不会返回给客户端
+// 配置项通过 app.BaseConfig.Mirror 进行设置
+//
+// 功能特性：
+// - 按 percentage 决定是否镜像请求，镜像请求的方法、路径、查询参数与原请求相同
+// - copyBody 为 true 时复制请求体，超过 maxBodyBytes 的请求不镜像；原请求的请求体不受影响
+// - 去掉逐跳请求头（Connection 及其列出的请求头、Keep-Alive、Transfer-Encoding 等），可通过 headers 只复制指定的请求头，并添加 X-Mirrored: true
+// - 携带 X-Mirrored 请求头的请求不会被再次镜像，避免影子服务之间循环镜像
+// - 队列已满时直接丢弃镜像请求并计数，不阻塞原请求
+// - 每个镜像请求的状态码和耗时会记录日志，并传给 core.OnMirrorResult 注册的回调
+//
+// 使用示例：
+//
+//	在配置文件中启用：
+//	mirror:
+//	  enabled: true
+//	  rules:
+//	    - path: "/api/orders/*"
+//	      method: "GET"
+//	      targetBaseURL: "http://orders-v2.internal:8080"
+//	      percentage: 10
+func MirrorHandler() gin.HandlerFunc {
+	cfg := app.BaseConfig.Mirror
+	if !cfg.Enabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	sender := newMirrorSender(cfg)
+	maxBodyBytes := cfg.GetMaxBodyBytes()
+	var rngMu sync.Mutex
+	rng := newMirrorRand()
+
+	return func(c *gin.Context) {
+		if c.GetHeader(MirroredHeader) != "" {
+			c.Next()
+			return
+		}
+		rule := findMirrorRule(c.Request.Method, c.Request.URL.Path, cfg.Rules)
+		if rule == nil {
+			c.Next()
+			return
+		}
+		rngMu.Lock()
+		sampled := rng.Float64()*100 < rule.GetPercentage()
+		rngMu.Unlock()
+		if !sampled {
+			c.Next()
+			return
+		}
+
+		job := mirrorJob{
+			rule:    rule,
+			traceID: ginContext.GetTraceID(c),
+			method:  c.Request.Method,
+			path:    c.Request.URL.Path,
+			query:   c.Request.URL.RawQuery,
+			header:  mirrorHeader(c.Request.Header, cfg.Headers),
+		}
+		if rule.CopyBody {
+			body, ok := copyMirrorBody(c.Request, maxBodyBytes)
+			if !ok {
+				logger.Debug("[mirror] 请求体超过 %d 字节，不镜像, path: %s, traceId: %s", maxBodyBytes, job.path, job.traceID)
+				c.Next()
+				return
+			}
+			job.body = body
+		}
+
+		start := time.Now()
+		c.Next()
+		job.primaryStatus = c.Writer.Status()
+		job.primaryLatency = time.Since(start)
+		sender.enqueue(job)
+	}
+}
+
+// newMirrorSender 创建发送镜像请求的协程池并启动 workers 个协程
+// 镜像请求不跟随重定向，重定向响应的状态码直接作为镜像结果
+func newMirrorSender(cfg config.MirrorConfig) *mirrorSender {
+	s := &mirrorSender{
+		queue: make(chan mirrorJob, cfg.GetQueueSize()),
+		client: &http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+	for i := 0; i < cfg.GetWorkers(); i++ {
+		go func() {
+			for job := range s.queue {
+				s.send(job)
+			}
+		}()
+	}
+	return s
+}
+
+// enqueue 将镜像请求放入队列，队列已满时丢弃并计数
+func (s *mirrorSender) enqueue(job mirrorJob) {
+	select {
+	case s.queue <- job:
+	default:
+		dropped := mirrorDropped.Add(1)
+		logger.Debug("[mirror] 镜像请求队列已满，丢弃镜像请求（累计 %d 条）, path: %s, traceId: %s", dropped, job.path, job.traceID)
+	}
+}
+
+// send 发送镜像请求并丢弃响应体，记录结果后执行回调
+func (s *mirrorSender) send(job mirrorJob) {
+	result := MirrorResult{
+		Rule:           *job.rule,
+		TraceID:        job.traceID,
+		Method:         job.method,
+		Path:           job.path,
+		Query:          job.query,
+		PrimaryStatus:  job.primaryStatus,
+		PrimaryLatency: job.primaryLatency,
+	}
+
+	target := strings.TrimRight(job.rule.TargetBaseURL, "/") + job.path
+	if job.query != "" {
+		target += "?" + job.query
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), job.rule.GetTimeout())
+	defer cancel()
+
+	start := time.Now()
+	req, err := http.NewRequestWithContext(ctx, job.method, target, bytes.NewReader(job.body))
+	if err == nil {
+		req.Header = job.header
+		var resp *http.Response
+		if resp, err = s.client.Do(req); err == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+			result.StatusCode = resp.StatusCode
+		}
+	}
+	result.Latency = time.Since(start)
+	result.Err = err
+
+	if err != nil {
+		logger.Warn("[mirror] 镜像请求失败, %s %s, target: %s, latency: %dms, error: %v, traceId: %s",
+			job.method, job.path, job.rule.TargetBaseURL, result.Latency.Milliseconds(), err, job.traceID)
+	} else {
+		logger.Info("[mirror] %s %s, target: %s, status: %d / %d, latency: %dms / %dms, traceId: %s",
+			job.method, job.path, job.rule.TargetBaseURL, result.PrimaryStatus, result.StatusCode,
+			result.PrimaryLatency.Milliseconds(), result.Latency.Milliseconds(), job.traceID)
+	}
+	runMirrorHooks(result)
+}
+
+// runMirrorHooks 按注册顺序执行镜像请求结果的回调
+func runMirrorHooks(result MirrorResult) {
+	mirrorHooksMu.RLock()
+	hooks := append([]MirrorResultHook(nil), mirrorHooks...)
+	mirrorHooksMu.RUnlock()
+	for _, hook := range hooks {
+		runMirrorHook(hook, result)
+	}
+}
+
+// runMirrorHook 执行单个回调，回调 panic 时只记录日志
+func runMirrorHook(hook MirrorResultHook, result MirrorResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("[mirror] 镜像请求结果的回调执行失败: %v", r)
+		}
+	}()
+	hook(result)
+}
+
+// copyMirrorBody 读取不超过 maxBytes 字节的请求体并放回原请求，原请求仍可完整读取请求体
+//
+// 返回：
+//   - []byte: 请求体的副本
+//   - bool: 请求体是否未超过 maxBytes，超过时不应镜像
+func copyMirrorBody(req *http.Request, maxBytes int64) ([]byte, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, true
+	}
+	original := req.Body
+	body, err := io.ReadAll(io.LimitReader(original, maxBytes+1))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), original), original}
+	if err != nil || int64(len(body)) > maxBytes {
+		return nil, false
+	}
+	return body, true
+}
+
+// mirrorHeader 复制镜像请求的请求头：去掉逐跳请求头和 Connection 中列出的请求头，allow 不为空时只保留其中的请求头，并添加 X-Mirrored: true
+// Content-Length 由镜像请求的请求体重新计算，不复制
+func mirrorHeader(src http.Header, allow []string) http.Header {
+	header := src.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				header.Del(name)
+			}
+		}
+	}
+	for _, name := range hopByHopHeaders {
+		header.Del(name)
+	}
+	header.Del("Content-Length")
+
+	if len(allow) > 0 {
+		allowed := make(map[string]bool, len(allow))
+		for _, name := range allow {
+			allowed[textproto.CanonicalMIMEHeaderKey(name)] = true
+		}
+		for name := range header {
+			if !allowed[name] {
+				delete(header, name)
+			}
+		}
+	}
+	header.Set(MirroredHeader, "true")
+	return header
+}
+
+// findMirrorRule 按顺序查找第一个匹配请求方法和路径的镜像规则
+// 路径支持精确匹配、/* 后缀通配符（匹配该前缀下的所有路径）和 path.Match 模式，空 Method 表示匹配所有方法
+func findMirrorRule(method, requestPath string, rules []config.MirrorRule) *config.MirrorRule {
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Method != "" && !strings.EqualFold(rule.Method, method) {
+			continue
+		}
+		if matchAuditPath(requestPath, []string{rule.Path}) {
+			return rule
+		}
+	}
+	return nil
+}
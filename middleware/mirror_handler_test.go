@@ -0,0 +1,293 @@
+// Package middleware 流量镜像中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含流量镜像中间件的单元测试，影子服务使用 httptest.Server，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 固定种子下镜像的请求数与 percentage 的抽样结果一致
+// 2. 影子服务阻塞时原请求不受影响，镜像结果在影子服务响应后通过回调返回
+// 3. 镜像请求的方法、路径、查询参数、请求体与原请求相同，逐跳请求头被去掉并添加 X-Mirrored；超过大小上限的请求不镜像
+// 4. 队列已满时丢弃镜像请求并计数
+//
+// 运行测试：go test -v ./middleware/... -run Mirror
+// ==================================================
+package middleware
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// mirrorTarget 影子服务，记录收到的请求，release 不为 nil 时阻塞到 release 关闭
+type mirrorTarget struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	arrived  chan struct{} // 每收到一个请求写入一次
+	release  chan struct{}
+}
+
+// newMirrorTarget 创建影子服务，block 为 true 时请求阻塞到 release 关闭，测试结束后关闭
+func newMirrorTarget(t *testing.T, block bool) *mirrorTarget {
+	target := &mirrorTarget{arrived: make(chan struct{}, 100)}
+	if block {
+		target.release = make(chan struct{})
+	}
+	target.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		target.mu.Lock()
+		target.requests = append(target.requests, r)
+		target.bodies = append(target.bodies, string(body))
+		target.mu.Unlock()
+		target.arrived <- struct{}{}
+		if target.release != nil {
+			<-target.release
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("from shadow"))
+	}))
+	t.Cleanup(func() {
+		if target.release != nil {
+			select {
+			case <-target.release:
+			default:
+				close(target.release)
+			}
+		}
+		target.server.Close()
+	})
+	return target
+}
+
+// count 返回收到的请求数
+func (target *mirrorTarget) count() int {
+	target.mu.Lock()
+	defer target.mu.Unlock()
+	return len(target.requests)
+}
+
+// mirrorResults 记录回调收到的镜像结果
+type mirrorResults struct {
+	mu      sync.Mutex
+	results []MirrorResult
+}
+
+// list 返回已收到的镜像结果
+func (r *mirrorResults) list() []MirrorResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]MirrorResult(nil), r.results...)
+}
+
+// newMirrorTestRouter 以指定配置创建流量镜像测试路由，并注册记录镜像结果的回调，测试结束后恢复配置和回调
+// 路由 /api/orders/:id 读取请求体并原样返回
+func newMirrorTestRouter(t *testing.T, cfg config.MirrorConfig) (*gin.Engine, *mirrorResults) {
+	originalCfg := app.BaseConfig.Mirror
+	mirrorHooksMu.Lock()
+	originalHooks := mirrorHooks
+	mirrorHooks = nil
+	mirrorHooksMu.Unlock()
+	t.Cleanup(func() {
+		app.BaseConfig.Mirror = originalCfg
+		mirrorHooksMu.Lock()
+		mirrorHooks = originalHooks
+		mirrorHooksMu.Unlock()
+	})
+	app.BaseConfig.Mirror = cfg
+
+	results := &mirrorResults{}
+	OnMirrorResult(func(result MirrorResult) {
+		results.mu.Lock()
+		defer results.mu.Unlock()
+		results.results = append(results.results, result)
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(MirrorHandler())
+	handler := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "primary:"+string(body))
+	}
+	router.GET("/api/orders/:id", handler)
+	router.POST("/api/orders/:id", handler)
+	return router, results
+}
+
+// useMirrorSeed 使用固定种子创建决定是否镜像的随机数生成器，测试结束后恢复
+func useMirrorSeed(t *testing.T, seed uint64) {
+	original := newMirrorRand
+	newMirrorRand = func() *rand.Rand { return rand.New(rand.NewPCG(seed, seed)) }
+	t.Cleanup(func() { newMirrorRand = original })
+}
+
+// ==================== 测试用例 ====================
+
+// TestMirror_PercentageSampling 测试按比例抽样
+//
+// 【功能点】验证固定种子下镜像的请求数与相同种子的抽样结果完全一致，未匹配规则的请求不镜像
+// 【测试流程】
+//  1. 使用种子 42，对 /api/orders/* 配置 25% 的镜像规则，发送 400 个请求
+//  2. 使用相同种子计算应镜像的请求数，断言影子服务和回调收到的数量与之相等，且约为 100 个
+//  3. 断言镜像结果中的原请求状态码为 200、镜像状态码为 202
+func TestMirror_PercentageSampling(t *testing.T) {
+	useMirrorSeed(t, 42)
+	target := newMirrorTarget(t, false)
+	router, results := newMirrorTestRouter(t, config.MirrorConfig{Enabled: true, Rules: []config.MirrorRule{
+		{Path: "/api/orders/*", TargetBaseURL: target.server.URL, Percentage: 25},
+	}})
+
+	const total = 400
+	for i := 0; i < total; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/orders/1", nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users", nil))
+
+	rng := rand.New(rand.NewPCG(42, 42))
+	expected := 0
+	for i := 0; i < total; i++ {
+		if rng.Float64()*100 < 25 {
+			expected++
+		}
+	}
+	assert.InDelta(t, total/4, expected, total/10)
+
+	require.Eventually(t, func() bool { return len(results.list()) == expected }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, expected, target.count())
+	for _, result := range results.list() {
+		require.NoError(t, result.Err)
+		assert.Equal(t, http.StatusOK, result.PrimaryStatus)
+		assert.Equal(t, http.StatusAccepted, result.StatusCode)
+		assert.Equal(t, "/api/orders/1", result.Path)
+	}
+}
+
+// TestMirror_NonBlocking 测试镜像请求不阻塞原请求
+//
+// 【功能点】验证影子服务阻塞时原请求立即返回原服务的响应，镜像结果在影子服务响应后通过回调返回并包含耗时
+// 【测试流程】
+//  1. 影子服务收到请求后阻塞，发送请求并断言在 100ms 内返回原服务的响应
+//  2. 等待影子服务收到请求，断言此时回调尚未收到结果
+//  3. 等待 100ms 后放行影子服务，断言回调收到状态码 202、耗时不小于 100ms 的结果
+func TestMirror_NonBlocking(t *testing.T) {
+	target := newMirrorTarget(t, true)
+	router, results := newMirrorTestRouter(t, config.MirrorConfig{Enabled: true, Rules: []config.MirrorRule{
+		{Path: "/api/orders/*", TargetBaseURL: target.server.URL},
+	}})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/orders/1", nil))
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "primary:", w.Body.String())
+
+	<-target.arrived
+	assert.Empty(t, results.list())
+	time.Sleep(100 * time.Millisecond)
+	close(target.release)
+
+	require.Eventually(t, func() bool { return len(results.list()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	result := results.list()[0]
+	assert.Equal(t, http.StatusAccepted, result.StatusCode)
+	assert.GreaterOrEqual(t, result.Latency, 100*time.Millisecond)
+}
+
+// TestMirror_RequestCopy 测试镜像请求的内容
+//
+// 【功能点】验证镜像请求与原请求的方法、路径、查询参数、请求体相同，逐跳请求头和 Connection 列出的请求头被去掉，
+// 添加 X-Mirrored: true，原请求仍能读取完整的请求体；请求体超过上限时不镜像，携带 X-Mirrored 的请求不再镜像
+// 【测试流程】
+//  1. 发送携带查询参数、JSON 请求体和逐跳请求头的 POST 请求，断言原服务的响应包含完整请求体
+//  2. 断言影子服务收到的请求内容和请求头
+//  3. 发送超过 maxBodyBytes 的请求和携带 X-Mirrored 的请求，断言原服务正常响应且影子服务未收到
+func TestMirror_RequestCopy(t *testing.T) {
+	target := newMirrorTarget(t, false)
+	router, results := newMirrorTestRouter(t, config.MirrorConfig{Enabled: true, MaxBodyBytes: 64, Rules: []config.MirrorRule{
+		{Path: "/api/orders/*", Method: "POST", TargetBaseURL: target.server.URL + "/", CopyBody: true},
+	}})
+
+	body := `{"sku":"A-1","quantity":2}`
+	req := httptest.NewRequest(http.MethodPost, "/api/orders/7?dryRun=true&tag=a%20b", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-Id", "req-1")
+	req.Header.Set("Connection", "keep-alive, X-Hop")
+	req.Header.Set("X-Hop", "secret")
+	req.Header.Set("Keep-Alive", "timeout=5")
+	req.Header.Set("Proxy-Authorization", "Basic abc")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "primary:"+body, w.Body.String())
+
+	require.Eventually(t, func() bool { return len(results.list()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	target.mu.Lock()
+	mirrored, mirroredBody := target.requests[0], target.bodies[0]
+	target.mu.Unlock()
+	assert.Equal(t, http.MethodPost, mirrored.Method)
+	assert.Equal(t, "/api/orders/7", mirrored.URL.Path)
+	assert.Equal(t, "dryRun=true&tag=a%20b", mirrored.URL.RawQuery)
+	assert.Equal(t, body, mirroredBody)
+	assert.Equal(t, "true", mirrored.Header.Get(MirroredHeader))
+	assert.Equal(t, "application/json", mirrored.Header.Get("Content-Type"))
+	assert.Equal(t, "req-1", mirrored.Header.Get("X-Request-Id"))
+	for _, name := range []string{"X-Hop", "Keep-Alive", "Proxy-Authorization"} {
+		assert.Empty(t, mirrored.Header.Get(name), name)
+	}
+
+	large := strings.Repeat("x", 100)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/orders/8", strings.NewReader(large)))
+	assert.Equal(t, "primary:"+large, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/api/orders/9", strings.NewReader(body))
+	req.Header.Set(MirroredHeader, "true")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 1, target.count())
+}
+
+// TestMirror_QueueOverflow 测试队列已满时丢弃镜像请求
+//
+// 【功能点】验证发送协程和队列都被占满时新的镜像请求被丢弃并计数，原请求不受影响
+// 【测试流程】
+//  1. 配置 1 个发送协程、队列长度 1，影子服务阻塞
+//  2. 发送第一个请求并等待影子服务收到（发送协程被占用），再发送 4 个请求
+//  3. 断言 4 个请求均正常响应，1 个进入队列、3 个被丢弃
+//  4. 放行影子服务后断言共收到 2 个镜像请求
+func TestMirror_QueueOverflow(t *testing.T) {
+	target := newMirrorTarget(t, true)
+	router, results := newMirrorTestRouter(t, config.MirrorConfig{Enabled: true, Workers: 1, QueueSize: 1, Rules: []config.MirrorRule{
+		{Path: "/api/orders/*", TargetBaseURL: target.server.URL},
+	}})
+	droppedBefore := MirrorDroppedCount()
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/orders/1", nil))
+	<-target.arrived
+	for i := 0; i < 4; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/orders/2", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	assert.Equal(t, uint64(3), MirrorDroppedCount()-droppedBefore)
+
+	close(target.release)
+	require.Eventually(t, func() bool { return len(results.list()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, target.count())
+}
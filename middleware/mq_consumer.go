@@ -0,0 +1,99 @@
+// Package middleware 提供Gin框架的中间件功能
+// 本文件实现了 RabbitMQ 消费中间件，对应 HTTP 服务的 exceptionHandler、traceLogHandler、timeoutHandler
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// ConsumerRecoveryMiddleware 消费异常恢复中间件
+// 捕获消费函数的 panic，记录与 exceptionHandler 相同格式的结构化日志，并转换为错误返回，
+// 消息按重试规则拒绝，超过重试次数后进入死信队列
+func ConsumerRecoveryMiddleware(next config.ConsumerHandlerFunc) config.ConsumerHandlerFunc {
+	return func(ctx context.Context, msg amqp.Delivery) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.ErrorWithFields(map[string]any{
+					"queue":     config.ConsumerQueueName(ctx),                           // 队列名称
+					"messageId": msg.MessageId,                                           // 消息 ID
+					"error":     fmt.Sprint(recovered),                                   // 异常信息
+					"stackInfo": panicStack(app.BaseConfig.Service.GetPanicStackDepth()), // 过滤后的堆栈跟踪信息
+				}, "[消息队列] 消费函数未处理的异常")
+				err = fmt.Errorf("消费函数 panic: %v", recovered)
+			}
+		}()
+		return next(ctx, msg)
+	}
+}
+
+// ConsumerLoggingMiddleware 消费日志中间件，记录队列名称、消息 ID、耗时和处理结果
+// 处理成功输出 info 日志，失败输出 warn 日志
+func ConsumerLoggingMiddleware(next config.ConsumerHandlerFunc) config.ConsumerHandlerFunc {
+	return func(ctx context.Context, msg amqp.Delivery) error {
+		startTime := time.Now()
+		err := next(ctx, msg)
+		fields := map[string]any{
+			"queue":        config.ConsumerQueueName(ctx), // 队列名称
+			"messageId":    msg.MessageId,                 // 消息 ID
+			"responseTime": time.Since(startTime),         // 处理耗时
+			"redelivered":  msg.Redelivered,               // 是否为重新投递的消息
+		}
+		if err != nil {
+			fields["errStr"] = err.Error()
+			logger.WarnWithFields(fields, "[消息队列] 消费失败")
+		} else {
+			logger.InfoWithFields(fields, "[消息队列] 消费成功")
+		}
+		return err
+	}
+}
+
+// ConsumerTimeoutMiddleware 消费超时中间件
+// 消费函数超过 timeout 未返回时取消其上下文并返回包装了 context.DeadlineExceeded 的错误，消息按重试规则拒绝；
+// 消费函数需要响应上下文取消，否则会在后台继续执行直到返回。消费函数的 panic 会传递给外层的中间件
+// 参数：
+//   - timeout: 超时时间，<= 0 时不限制
+//
+// 返回：
+//   - config.ConsumerMiddleware: 消费中间件
+func ConsumerTimeoutMiddleware(timeout time.Duration) config.ConsumerMiddleware {
+	return func(next config.ConsumerHandlerFunc) config.ConsumerHandlerFunc {
+		if timeout <= 0 {
+			return next
+		}
+		return func(ctx context.Context, msg amqp.Delivery) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			done := make(chan error, 1)
+			panicChan := make(chan any, 1)
+			go func() {
+				defer func() {
+					if recovered := recover(); recovered != nil {
+						panicChan <- recovered
+					}
+				}()
+				done <- next(ctx, msg)
+			}()
+
+			select {
+			case err := <-done:
+				return err
+			case recovered := <-panicChan:
+				panic(recovered)
+			case <-ctx.Done():
+				if ctx.Err() == context.DeadlineExceeded {
+					return fmt.Errorf("消费超时（%s）: %w", timeout, context.DeadlineExceeded)
+				}
+				return ctx.Err()
+			}
+		}
+	}
+}
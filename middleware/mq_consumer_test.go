@@ -0,0 +1,134 @@
+// Package middleware 消息队列消费中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 RabbitMQ 消费中间件的单元测试，使用构造的消息投递，不需要 RabbitMQ 连接。
+// 中间件返回错误后的重试流程见 model/config 的 TestMessageQueue_Middleware_ErrorRetry。
+//
+// 测试覆盖内容：
+// 1. 异常恢复中间件将 panic 转换为错误
+// 2. 超时中间件取消慢消费函数并返回 DeadlineExceeded，未超时时返回消费函数的结果
+// 3. 超时中间件将消费函数的 panic 传递给外层的异常恢复中间件
+// 4. 日志中间件透传消费函数的结果
+//
+// 运行测试：go test -v ./middleware/... -run Consumer
+// ==================================================
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// chainConsumer 按顺序组合消费中间件和处理函数，与 MessageQueue 的组合顺序一致
+func chainConsumer(handler config.ConsumerHandlerFunc, mw ...config.ConsumerMiddleware) config.ConsumerHandlerFunc {
+	for i := len(mw) - 1; i >= 0; i-- {
+		handler = mw[i](handler)
+	}
+	return handler
+}
+
+// TestConsumerRecoveryMiddleware 测试异常恢复中间件
+//
+// 【功能点】验证消费函数 panic 时返回错误而不是崩溃，正常返回时透传结果
+// 【测试流程】
+//  1. 处理函数 panic，断言返回包含 panic 信息的错误
+//  2. 处理函数返回错误，断言原样返回
+func TestConsumerRecoveryMiddleware(t *testing.T) {
+	handler := chainConsumer(func(ctx context.Context, msg amqp.Delivery) error {
+		panic("boom")
+	}, ConsumerRecoveryMiddleware)
+
+	err := handler(context.Background(), amqp.Delivery{MessageId: "m1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+
+	errFailed := errors.New("failed")
+	handler = chainConsumer(func(ctx context.Context, msg amqp.Delivery) error {
+		return errFailed
+	}, ConsumerRecoveryMiddleware)
+	assert.ErrorIs(t, handler(context.Background(), amqp.Delivery{}), errFailed)
+}
+
+// TestConsumerTimeoutMiddleware 测试超时中间件
+//
+// 【功能点】验证超时后取消消费函数的上下文并返回 DeadlineExceeded，未超时时返回消费函数的结果
+// 【测试流程】
+//  1. 超时 20ms，处理函数等待上下文取消，断言返回 DeadlineExceeded 且处理函数收到取消
+//  2. 处理函数忽略上下文，断言超时后立即返回，不等待处理函数结束
+//  3. 处理函数快速返回，断言返回 nil
+//  4. 超时为 0 时不包装处理函数
+func TestConsumerTimeoutMiddleware(t *testing.T) {
+	cancelled := make(chan struct{})
+	handler := chainConsumer(func(ctx context.Context, msg amqp.Delivery) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}, ConsumerTimeoutMiddleware(20*time.Millisecond))
+	err := handler(context.Background(), amqp.Delivery{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("超时后应取消消费函数的上下文")
+	}
+
+	start := time.Now()
+	handler = chainConsumer(func(ctx context.Context, msg amqp.Delivery) error {
+		time.Sleep(300 * time.Millisecond)
+		return nil
+	}, ConsumerTimeoutMiddleware(20*time.Millisecond))
+	assert.ErrorIs(t, handler(context.Background(), amqp.Delivery{}), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 200*time.Millisecond, "忽略上下文的消费函数不应阻塞超时返回")
+
+	handler = chainConsumer(func(ctx context.Context, msg amqp.Delivery) error {
+		return nil
+	}, ConsumerTimeoutMiddleware(time.Second))
+	assert.NoError(t, handler(context.Background(), amqp.Delivery{}))
+
+	called := false
+	handler = chainConsumer(func(ctx context.Context, msg amqp.Delivery) error {
+		_, hasDeadline := ctx.Deadline()
+		called = !hasDeadline
+		return nil
+	}, ConsumerTimeoutMiddleware(0))
+	assert.NoError(t, handler(context.Background(), amqp.Delivery{}))
+	assert.True(t, called, "超时为 0 时上下文不应有截止时间")
+}
+
+// TestConsumerTimeoutMiddleware_Panic 测试超时中间件的 panic 传递
+//
+// 【功能点】验证消费函数在超时中间件的协程中 panic 时，由外层的异常恢复中间件转换为错误
+// 【测试流程】按恢复、日志、超时的顺序组合中间件，处理函数 panic，断言返回包含 panic 信息的错误
+func TestConsumerTimeoutMiddleware_Panic(t *testing.T) {
+	handler := chainConsumer(func(ctx context.Context, msg amqp.Delivery) error {
+		panic("boom in goroutine")
+	}, ConsumerRecoveryMiddleware, ConsumerLoggingMiddleware, ConsumerTimeoutMiddleware(time.Second))
+
+	err := handler(context.Background(), amqp.Delivery{MessageId: "m1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom in goroutine")
+}
+
+// TestConsumerLoggingMiddleware 测试日志中间件
+//
+// 【功能点】验证日志中间件透传消费函数的结果
+// 【测试流程】分别处理成功、失败的消息，断言返回值与处理函数一致
+func TestConsumerLoggingMiddleware(t *testing.T) {
+	handler := chainConsumer(func(ctx context.Context, msg amqp.Delivery) error {
+		return nil
+	}, ConsumerLoggingMiddleware)
+	assert.NoError(t, handler(context.Background(), amqp.Delivery{MessageId: "m1"}))
+
+	errFailed := errors.New("failed")
+	handler = chainConsumer(func(ctx context.Context, msg amqp.Delivery) error {
+		return errFailed
+	}, ConsumerLoggingMiddleware)
+	assert.ErrorIs(t, handler(context.Background(), amqp.Delivery{MessageId: "m2"}), errFailed)
+}
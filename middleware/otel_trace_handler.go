@@ -12,6 +12,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/zzsen/gin_core/tracing"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
 )
 
 // OtelTraceHandler OpenTelemetry 链路追踪中间件
@@ -59,7 +60,7 @@ func OtelTraceHandler() gin.HandlerFunc {
 				semconv.NetHostName(c.Request.Host),
 				// 自定义属性
 				attribute.String("http.user_agent", c.Request.UserAgent()),
-				attribute.String("http.request_id", c.GetString("requestId")),
+				attribute.String("http.request_id", ginContext.GetRequestID(c)),
 			),
 		)
 		defer span.End()
@@ -71,10 +72,10 @@ func OtelTraceHandler() gin.HandlerFunc {
 		traceID := tracing.GetTraceID(ctx)
 		spanID := tracing.GetSpanID(ctx)
 
-		// 向后兼容：将追踪信息存储到 Gin 上下文中
-		// 这样其他中间件和处理器可以通过 c.Get("traceId") 获取
-		c.Set("traceId", traceID)
-		c.Set("spanId", spanID)
+		// 将追踪信息存入 RequestContext
+		// 向后兼容：同时写入旧版键，其他中间件和处理器仍可通过 c.Get("traceId") 获取
+		ginContext.SetTraceID(c, traceID)
+		ginContext.SetSpanID(c, spanID)
 
 		// 设置响应头，方便客户端进行请求追踪
 		c.Writer.Header().Set("X-Trace-ID", traceID)
@@ -3,7 +3,10 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"path"
 	"strconv"
@@ -17,6 +20,8 @@ import (
 	"github.com/zzsen/gin_core/model/config"
 	"github.com/zzsen/gin_core/model/response"
 	"github.com/zzsen/gin_core/ratelimit"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"github.com/zzsen/gin_core/utils/netutil"
 )
 
 var (
@@ -37,17 +42,37 @@ func initLimiter() {
 				logger.Info("[限流] 使用 Redis 限流器")
 			} else {
 				logger.Warn("[限流] Redis 未初始化，降级为内存限流器")
-				globalLimiter = ratelimit.NewMemoryLimiter(time.Duration(cfg.GetCleanupInterval()) * time.Second)
+				globalLimiter = newMemoryLimiter(cfg)
 			}
 		default:
-			globalLimiter = ratelimit.NewMemoryLimiter(time.Duration(cfg.GetCleanupInterval()) * time.Second)
+			globalLimiter = newMemoryLimiter(cfg)
 			logger.Info("[限流] 使用内存限流器")
 		}
 	})
 }
 
+// newMemoryLimiter 按 rateLimit.memoryStore 配置创建内存限流器
+func newMemoryLimiter(cfg config.RateLimitConfig) *ratelimit.MemoryLimiter {
+	return ratelimit.NewMemoryLimiterWithOptions(ratelimit.MemoryOptions{
+		Shards:          cfg.MemoryStore.GetShards(),
+		IdleTTL:         time.Duration(cfg.MemoryStore.GetIdleTTL()) * time.Second,
+		CleanupInterval: time.Duration(cfg.GetCleanupInterval()) * time.Second,
+		MaxEntries:      cfg.MemoryStore.GetMaxEntries(),
+	})
+}
+
 // RateLimitHandler 限流中间件
-// 根据配置的规则对请求进行限流
+// 根据配置的规则对请求进行限流，匹配豁免规则的请求直接放行。
+// 运行信息接口（runtimeInfo.path）默认不限流，配置了匹配该路径的规则时按规则限流。
+// 维护模式开启时，将被 maintenanceHandler 拒绝的请求不计入限流配额。
+// 经过限流的响应都会带上 X-RateLimit-Limit、X-RateLimit-Remaining、X-RateLimit-Reset 响应头，
+// 被限流时额外返回 Retry-After，时间单位均为秒
+//
+// 每次请求消耗规则中 cost 个配额（默认 1）。采用"预先获取、事后调整"的方式：
+//   - 处理函数执行前按 cost 获取配额，之前的中间件调用过 ginContext.SetRateLimitCost 时按其设置的值获取
+//   - 处理函数中调用 ginContext.SetRateLimitCost 时，处理函数结束后按差值额外扣除（不拒绝当前请求，之后的请求需等待配额恢复）或归还配额
+//   - X-RateLimit-Remaining 为预先获取后的剩余配额，不包含事后调整
+//   - 消耗超过突发容量的请求无论等待多久都无法被允许，直接拒绝且不返回 Retry-After
 func RateLimitHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		cfg := app.BaseConfig.RateLimit
@@ -64,18 +89,35 @@ func RateLimitHandler() gin.HandlerFunc {
 			return
 		}
 
+		// 维护期间将被维护模式中间件拒绝的请求不计入限流配额
+		if maintenanceRejects(c) {
+			c.Next()
+			return
+		}
+
 		// 查找匹配的规则
 		rule := findMatchingRule(c.Request.Method, c.Request.URL.Path, cfg.Rules)
+		if rule != nil && rule.Exempt || rule == nil && isRuntimeInfoPath(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
 
 		// 确定限流参数
 		var rateLimit, burst int
 		var keyType, message string
+		responseCode := http.StatusTooManyRequests
+		cost := 1
 
 		if rule != nil {
 			rateLimit = rule.GetRate()
 			burst = rule.GetBurst()
 			keyType = rule.GetKeyType()
 			message = rule.Message
+			responseCode = rule.GetResponseCode()
+			cost = rule.GetCost()
+		}
+		if n, ok := ginContext.GetRateLimitCost(c); ok {
+			cost = n
 		}
 
 		// 使用默认值
@@ -96,26 +138,55 @@ func RateLimitHandler() gin.HandlerFunc {
 		key := generateRateLimitKey(c, keyType, c.Request.URL.Path)
 
 		// 检查是否允许
-		allowed, err := globalLimiter.Allow(c.Request.Context(), key, rateLimit, burst)
+		result, err := globalLimiter.TakeN(c.Request.Context(), key, rateLimit, burst, cost)
+		if errors.Is(err, ratelimit.ErrCostExceedsLimit) {
+			logger.Warn("[限流] 请求消耗 %d 超过配额上限 %d, key: %s, path: %s", cost, result.Limit, key, c.Request.URL.Path)
+			setRateLimitHeaders(c, result)
+			response.WriteError(c, response.Of(responseCode),
+				response.WithMessage(fmt.Sprintf("%s（请求消耗 %d 超过配额上限 %d）", message, cost, result.Limit)),
+				response.WithStatus(http.StatusTooManyRequests))
+			return
+		}
 		if err != nil {
 			logger.Error("[限流] 检查失败: %v", err)
 			c.Next()
 			return
 		}
+		setRateLimitHeaders(c, result)
 
-		if !allowed {
+		if !result.Allowed {
 			logger.Warn("[限流] 请求被限流, key: %s, path: %s", key, c.Request.URL.Path)
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, response.Response{
-				Code: http.StatusTooManyRequests,
-				Msg:  message,
-			})
+			c.Header("Retry-After", strconv.Itoa(max(ceilSeconds(result.RetryAfter), 1)))
+			response.WriteError(c, response.Of(responseCode), response.WithMessage(message), response.WithStatus(http.StatusTooManyRequests))
 			return
 		}
 
 		c.Next()
+
+		// 处理函数设置的实际消耗与预先获取的不同时，按差值扣除或归还配额
+		if n, ok := ginContext.GetRateLimitCost(c); ok && n != cost {
+			if err := globalLimiter.Adjust(context.WithoutCancel(c.Request.Context()), key, rateLimit, burst, n-cost); err != nil {
+				logger.Error("[限流] 调整请求消耗失败, key: %s, 预先获取: %d, 实际消耗: %d, error: %v", key, cost, n, err)
+			}
+		}
 	}
 }
 
+// setRateLimitHeaders 设置限流配额响应头
+//   - X-RateLimit-Limit: 配额上限
+//   - X-RateLimit-Remaining: 本次请求后剩余的可用配额
+//   - X-RateLimit-Reset: 距离配额完全恢复的秒数
+func setRateLimitHeaders(c *gin.Context, result ratelimit.Result) {
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+}
+
+// ceilSeconds 将时长向上取整为秒
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
 // findMatchingRule 根据 HTTP 方法和请求路径查找最佳匹配的限流规则。
 //
 // 匹配优先级（从高到低）：
@@ -163,50 +234,35 @@ func findMatchingRule(method, requestPath string, rules []config.RateLimitRule)
 	return wildcardMatch
 }
 
+// isRuntimeInfoPath 判断是否为运行信息接口的路径（runtimeInfo.path，位于 service.routePrefix 之下）
+func isRuntimeInfoPath(requestPath string) bool {
+	cfg := app.BaseConfig.RuntimeInfo
+	return cfg.GetEnabled() && requestPath == path.Join("/", app.BaseConfig.Service.RoutePrefix, cfg.GetPath())
+}
+
 // generateRateLimitKey 根据限流键类型生成唯一的限流键。
 //
 // 支持的 keyType：
-//   - "ip"：按客户端 IP 限流，格式 "ip:{clientIP}:{path}"
-//   - "user"：按用户 ID 限流，格式 "user:{userID}:{path}"（从上下文 userID/user_id 字段获取，获取失败时降级为 IP 限流）
+//   - "ip"：按客户端 IP 限流，格式 "ip:{clientIP}:{path}"（通过 netutil.ClientIP 获取，只信任 service.trustedProxies 中代理的转发请求头）
+//   - "user"：按用户 ID 限流，格式 "user:{userID}:{path}"（通过 ginContext.GetUserID 获取，兼容旧版 userID/user_id 键，获取失败时降级为 IP 限流）
 //   - "global"：全局限流（不区分客户端），格式 "global:{path}"
 //
 // 默认使用 IP 限流策略。
 func generateRateLimitKey(c *gin.Context, keyType, requestPath string) string {
 	switch keyType {
 	case "ip":
-		return "ip:" + c.ClientIP() + ":" + requestPath
+		return "ip:" + netutil.ClientIP(c) + ":" + requestPath
 	case "user":
-		// 尝试从上下文获取用户 ID
-		if userID, exists := c.Get("userID"); exists {
-			return "user:" + toString(userID) + ":" + requestPath
-		}
-		if userID, exists := c.Get("user_id"); exists {
-			return "user:" + toString(userID) + ":" + requestPath
+		// 尝试从 RequestContext 获取用户 ID（兼容旧版 userID/user_id 键）
+		if userID, exists := ginContext.GetUserID(c); exists {
+			return "user:" + userID + ":" + requestPath
 		}
 		// 降级为 IP 限流
-		return "ip:" + c.ClientIP() + ":" + requestPath
+		return "ip:" + netutil.ClientIP(c) + ":" + requestPath
 	case "global":
 		return "global:" + requestPath
 	default:
-		return "ip:" + c.ClientIP() + ":" + requestPath
-	}
-}
-
-// toString 将任意类型转为字符串
-func toString(v interface{}) string {
-	switch val := v.(type) {
-	case string:
-		return val
-	case int:
-		return strconv.Itoa(val)
-	case int64:
-		return strconv.FormatInt(val, 10)
-	case uint:
-		return strconv.FormatUint(uint64(val), 10)
-	case uint64:
-		return strconv.FormatUint(val, 10)
-	default:
-		return fmt.Sprintf("%v", val)
+		return "ip:" + netutil.ClientIP(c) + ":" + requestPath
 	}
 }
 
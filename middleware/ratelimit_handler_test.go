@@ -9,35 +9,46 @@
 // 3. 不同 IP 独立限流
 // 4. 路径规则匹配（精确匹配、通配符）
 // 5. 全局限流键类型
-// 6. 代理场景下的 IP 获取（X-Forwarded-For、X-Real-IP）
+// 6. 代理场景下的 IP 获取（X-Forwarded-For、X-Real-IP），不受信任的直连地址伪造的请求头被忽略
 // 7. 辅助函数测试（findMatchingRule、generateRateLimitKey）
 // 8. 性能基准测试
+// 9. 限流响应头（X-RateLimit-*、Retry-After）与规则自定义响应 code
+// 10. 豁免规则
+// 11. 按请求消耗限流（规则 cost、ginContext.SetRateLimitCost、消耗超过突发容量）
 //
 // 运行测试：go test -v ./middleware/... -run RateLimit
 // ==================================================
 package middleware
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/zzsen/gin_core/app"
 	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"github.com/zzsen/gin_core/utils/netutil"
 )
 
 // ==================== 测试辅助函数 ====================
 
 // setupRateLimitTestConfig 设置测试配置
-// 备份原始配置，设置测试配置，返回清理函数
+// 备份原始配置，设置测试配置并开启 service.useHTTPStatus（被限流时返回 429），返回清理函数
 func setupRateLimitTestConfig(cfg config.RateLimitConfig) func() {
 	originalConfig := app.BaseConfig
 	app.BaseConfig = config.BaseConfig{
 		RateLimit: cfg,
 	}
+	response.SetUseHTTPStatus(true)
 	return func() {
 		app.BaseConfig = originalConfig
+		response.SetUseHTTPStatus(false)
 	}
 }
 
@@ -316,10 +327,19 @@ func TestRateLimitHandler_WildcardPath(t *testing.T) {
 	}
 }
 
+// trustRateLimitProxies 设置受信任的代理，测试结束后恢复为不信任任何代理
+func trustRateLimitProxies(t *testing.T, proxies ...string) {
+	t.Helper()
+	if err := netutil.SetTrustedProxies(proxies); err != nil {
+		t.Fatalf("设置受信任代理失败: %v", err)
+	}
+	t.Cleanup(func() { _ = netutil.SetTrustedProxies(nil) })
+}
+
 // TestRateLimitHandler_XForwardedFor 测试 X-Forwarded-For 头的 IP 获取
 //
-// 【功能点】验证代理场景下从 X-Forwarded-For 头获取真实 IP 进行限流
-// 【测试流程】设置 X-Forwarded-For 头发送请求，验证按该 IP 限流
+// 【功能点】验证请求来自受信任代理时，从 X-Forwarded-For 头获取真实 IP 进行限流
+// 【测试流程】信任代理 192.168.1.1，设置 X-Forwarded-For 头发送请求，验证按该 IP 限流
 func TestRateLimitHandler_XForwardedFor(t *testing.T) {
 	cleanup := setupRateLimitTestConfig(config.RateLimitConfig{
 		Enabled:      true,
@@ -328,6 +348,7 @@ func TestRateLimitHandler_XForwardedFor(t *testing.T) {
 		Store:        "memory",
 	})
 	defer cleanup()
+	trustRateLimitProxies(t, "192.168.1.1")
 
 	router := createTestRouter(RateLimitHandler())
 
@@ -356,10 +377,43 @@ func TestRateLimitHandler_XForwardedFor(t *testing.T) {
 	}
 }
 
+// TestRateLimitHandler_SpoofedXForwardedFor 测试伪造的 X-Forwarded-For
+//
+// 【功能点】验证直连地址不是受信任代理时忽略 X-Forwarded-For，客户端无法通过伪造 IP 绕过限流
+// 【测试流程】
+//  1. 不配置受信任代理，同一直连地址每次请求携带不同的 X-Forwarded-For
+//  2. 断言前 3 次通过，第 4 次被限流
+func TestRateLimitHandler_SpoofedXForwardedFor(t *testing.T) {
+	cleanup := setupRateLimitTestConfig(config.RateLimitConfig{
+		Enabled:      true,
+		DefaultRate:  3,
+		DefaultBurst: 3,
+		Store:        "memory",
+	})
+	defer cleanup()
+
+	router := createTestRouter(RateLimitHandler())
+	for i := 0; i < 4; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/test", nil)
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("10.0.1.%d", i))
+		req.RemoteAddr = "203.0.113.9:12345"
+		router.ServeHTTP(w, req)
+
+		want := http.StatusOK
+		if i == 3 {
+			want = http.StatusTooManyRequests
+		}
+		if w.Code != want {
+			t.Errorf("请求 %d 应返回 %d, 实际返回 %d", i+1, want, w.Code)
+		}
+	}
+}
+
 // TestRateLimitHandler_XRealIP 测试 X-Real-IP 头的 IP 获取
 //
-// 【功能点】验证从 X-Real-IP 头获取真实 IP 进行限流
-// 【测试流程】设置 X-Real-IP 头发送请求，验证按该 IP 限流
+// 【功能点】验证请求来自受信任代理时，从 X-Real-IP 头获取真实 IP 进行限流
+// 【测试流程】信任代理 192.168.1.1，设置 X-Real-IP 头发送请求，验证按该 IP 限流
 func TestRateLimitHandler_XRealIP(t *testing.T) {
 	cleanup := setupRateLimitTestConfig(config.RateLimitConfig{
 		Enabled:      true,
@@ -368,6 +422,7 @@ func TestRateLimitHandler_XRealIP(t *testing.T) {
 		Store:        "memory",
 	})
 	defer cleanup()
+	trustRateLimitProxies(t, "192.168.1.1")
 
 	router := createTestRouter(RateLimitHandler())
 
@@ -396,6 +451,338 @@ func TestRateLimitHandler_XRealIP(t *testing.T) {
 	}
 }
 
+// TestRateLimitHandler_Headers 测试限流响应头
+//
+// 【功能点】验证每个经过限流的响应都带有配额响应头，剩余请求数逐次递减；被限流时返回 Retry-After 和规则的响应 code、消息
+// 【测试流程】
+//  1. /api/public 规则 rate=1、burst=3、responseCode=42901
+//  2. 连续请求 3 次，断言 X-RateLimit-Limit 为 3，X-RateLimit-Remaining 依次为 2、1、0，X-RateLimit-Reset 依次为 1、2、3，没有 Retry-After
+//  3. 第 4 次请求返回 429，Retry-After 为 1，响应体 code 为 42901、msg 为规则消息
+func TestRateLimitHandler_Headers(t *testing.T) {
+	cleanup := setupRateLimitTestConfig(config.RateLimitConfig{
+		Enabled:      true,
+		DefaultRate:  100,
+		DefaultBurst: 100,
+		Store:        "memory",
+		Rules: []config.RateLimitRule{
+			{Path: "/api/public", Rate: 1, Burst: 3, ResponseCode: 42901, Message: "公共接口请求过于频繁"},
+		},
+	})
+	defer cleanup()
+
+	router := createTestRouter(RateLimitHandler())
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/public", nil)
+		req.RemoteAddr = "192.168.9.1:12345"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	wantRemaining := []string{"2", "1", "0"}
+	wantReset := []string{"1", "2", "3"}
+	for i := 0; i < 3; i++ {
+		w := send()
+		if w.Code != http.StatusOK {
+			t.Fatalf("请求 %d 应返回 200, 实际返回 %d", i+1, w.Code)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "3" {
+			t.Errorf("请求 %d: X-RateLimit-Limit = %s, want 3", i+1, got)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != wantRemaining[i] {
+			t.Errorf("请求 %d: X-RateLimit-Remaining = %s, want %s", i+1, got, wantRemaining[i])
+		}
+		if got := w.Header().Get("X-RateLimit-Reset"); got != wantReset[i] {
+			t.Errorf("请求 %d: X-RateLimit-Reset = %s, want %s", i+1, got, wantReset[i])
+		}
+		if got := w.Header().Get("Retry-After"); got != "" {
+			t.Errorf("请求 %d: 未被限流时不应返回 Retry-After, 实际 %s", i+1, got)
+		}
+	}
+
+	w := send()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("第 4 次请求应返回 429, 实际返回 %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %s, want 1", got)
+	}
+	if got := w.Header().Get("X-RateLimit-Remaining"); got != "0" {
+		t.Errorf("X-RateLimit-Remaining = %s, want 0", got)
+	}
+	var body struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("解析响应体失败: %v", err)
+	}
+	if body.Code != 42901 || body.Msg != "公共接口请求过于频繁" {
+		t.Errorf("响应体 = %+v, 期望 code=42901、msg=公共接口请求过于频繁", body)
+	}
+}
+
+// TestRateLimitHandler_ExemptRule 测试豁免规则
+//
+// 【功能点】验证匹配豁免规则的请求不受默认限流影响，也不返回限流响应头
+// 【测试流程】
+//  1. 默认 rate=1、burst=1，/api/public 配置 exempt: true
+//  2. 同一 IP 请求 /api/public 10 次，断言全部成功且没有 X-RateLimit-Limit
+//  3. 请求 /api/test 2 次，断言第 2 次返回 429
+func TestRateLimitHandler_ExemptRule(t *testing.T) {
+	cleanup := setupRateLimitTestConfig(config.RateLimitConfig{
+		Enabled:      true,
+		DefaultRate:  1,
+		DefaultBurst: 1,
+		Store:        "memory",
+		Rules: []config.RateLimitRule{
+			{Path: "/api/public", Exempt: true},
+		},
+	})
+	defer cleanup()
+
+	router := createTestRouter(RateLimitHandler())
+	send := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		req.RemoteAddr = "192.168.9.2:12345"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 10; i++ {
+		w := send("/api/public")
+		if w.Code != http.StatusOK {
+			t.Errorf("豁免路径请求 %d 应返回 200, 实际返回 %d", i+1, w.Code)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "" {
+			t.Errorf("豁免路径不应返回限流响应头, 实际 X-RateLimit-Limit = %s", got)
+		}
+	}
+
+	if w := send("/api/test"); w.Code != http.StatusOK {
+		t.Errorf("/api/test 第 1 次请求应返回 200, 实际返回 %d", w.Code)
+	}
+	if w := send("/api/test"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("/api/test 第 2 次请求应返回 429, 实际返回 %d", w.Code)
+	}
+}
+
+// TestRateLimitHandler_Cost 测试按请求消耗限流
+//
+// 【功能点】验证规则的 cost 决定每次请求消耗的配额，X-RateLimit-Remaining 按消耗扣减
+// 【测试流程】
+//  1. /api/export 配置 rate=1、burst=10、cost=5
+//  2. 同一 IP 请求 2 次，断言都成功且 X-RateLimit-Remaining 依次为 5、0
+//  3. 第 3 次请求返回 429，Retry-After 为 5
+func TestRateLimitHandler_Cost(t *testing.T) {
+	cleanup := setupRateLimitTestConfig(config.RateLimitConfig{
+		Enabled:      true,
+		DefaultRate:  100,
+		DefaultBurst: 100,
+		Store:        "memory",
+		Rules: []config.RateLimitRule{
+			{Path: "/api/export", Rate: 1, Burst: 10, Cost: 5},
+		},
+	})
+	defer cleanup()
+
+	router := createTestRouter(RateLimitHandler())
+	router.GET("/api/export", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "export"})
+	})
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/export", nil)
+		req.RemoteAddr = "192.168.9.10:12345"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, want := range []string{"5", "0"} {
+		w := send()
+		if w.Code != http.StatusOK {
+			t.Fatalf("请求 %d 应返回 200, 实际返回 %d", i+1, w.Code)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != want {
+			t.Errorf("请求 %d: X-RateLimit-Remaining = %s, want %s", i+1, got, want)
+		}
+	}
+
+	w := send()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("第 3 次请求应返回 429, 实际返回 %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "5" {
+		t.Errorf("Retry-After = %s, want 5", got)
+	}
+}
+
+// TestRateLimitHandler_DynamicCost 测试通过 ginContext.SetRateLimitCost 设置请求消耗
+//
+// 【功能点】验证之前的中间件设置的消耗在获取配额时使用，处理函数设置的消耗在处理函数结束后按差值调整
+// 【测试流程】
+//  1. 默认 rate=1、burst=10；/api/report 之前的中间件设置消耗 3，/api/heavy 的处理函数设置消耗 4，/api/light 的处理函数设置消耗 0
+//  2. 请求 /api/report，断言 X-RateLimit-Remaining 为 7
+//  3. 请求 /api/heavy，断言 X-RateLimit-Remaining 为 9（预先按 1 获取），再次请求断言为 5（事后额外扣除 3）
+//  4. 请求 /api/light 两次，断言 X-RateLimit-Remaining 均为 9（事后归还 1）
+func TestRateLimitHandler_DynamicCost(t *testing.T) {
+	cleanup := setupRateLimitTestConfig(config.RateLimitConfig{
+		Enabled:      true,
+		DefaultRate:  1,
+		DefaultBurst: 10,
+		Store:        "memory",
+	})
+	defer cleanup()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if c.Request.URL.Path == "/api/report" {
+			ginContext.SetRateLimitCost(c, 3)
+		}
+		c.Next()
+	}, RateLimitHandler())
+	router.GET("/api/report", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "report"})
+	})
+	router.GET("/api/heavy", func(c *gin.Context) {
+		ginContext.SetRateLimitCost(c, 4)
+		c.JSON(http.StatusOK, gin.H{"message": "heavy"})
+	})
+	router.GET("/api/light", func(c *gin.Context) {
+		ginContext.SetRateLimitCost(c, 0)
+		c.JSON(http.StatusOK, gin.H{"message": "light"})
+	})
+	remaining := func(path string) string {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		req.RemoteAddr = "192.168.9.11:12345"
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s 应返回 200, 实际返回 %d", path, w.Code)
+		}
+		return w.Header().Get("X-RateLimit-Remaining")
+	}
+
+	if got := remaining("/api/report"); got != "7" {
+		t.Errorf("/api/report: X-RateLimit-Remaining = %s, want 7", got)
+	}
+	if got := remaining("/api/heavy"); got != "9" {
+		t.Errorf("/api/heavy 第 1 次: X-RateLimit-Remaining = %s, want 9", got)
+	}
+	if got := remaining("/api/heavy"); got != "5" {
+		t.Errorf("/api/heavy 第 2 次: X-RateLimit-Remaining = %s, want 5", got)
+	}
+	for i := 0; i < 2; i++ {
+		if got := remaining("/api/light"); got != "9" {
+			t.Errorf("/api/light 第 %d 次: X-RateLimit-Remaining = %s, want 9", i+1, got)
+		}
+	}
+}
+
+// TestRateLimitHandler_CostExceedsBurst 测试请求消耗超过突发容量
+//
+// 【功能点】验证消耗超过突发容量的请求立即被拒绝，响应消息说明原因，不返回 Retry-After
+// 【测试流程】/api/export 配置 burst=10、cost=20，请求断言返回 429、消息包含消耗和配额上限、没有 Retry-After
+func TestRateLimitHandler_CostExceedsBurst(t *testing.T) {
+	cleanup := setupRateLimitTestConfig(config.RateLimitConfig{
+		Enabled: true,
+		Store:   "memory",
+		Message: "导出过于频繁",
+		Rules: []config.RateLimitRule{
+			{Path: "/api/export", Rate: 1, Burst: 10, Cost: 20},
+		},
+	})
+	defer cleanup()
+
+	router := createTestRouter(RateLimitHandler())
+	router.GET("/api/export", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "export"})
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/export", nil)
+	req.RemoteAddr = "192.168.9.12:12345"
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("应返回 429, 实际返回 %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "" {
+		t.Errorf("消耗超过突发容量时不应返回 Retry-After, 实际 %s", got)
+	}
+	var body struct {
+		Msg string `json:"msg"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("解析响应体失败: %v", err)
+	}
+	if !strings.Contains(body.Msg, "导出过于频繁") || !strings.Contains(body.Msg, "请求消耗 20 超过配额上限 10") {
+		t.Errorf("响应消息 = %s, 期望说明请求消耗超过配额上限", body.Msg)
+	}
+}
+
+// TestRateLimitHandler_RuntimeInfoExempt 测试运行信息接口不限流
+//
+// 【功能点】验证运行信息接口默认不限流，配置了匹配该路径的规则时按规则限流
+// 【测试流程】
+//  1. 默认 rate=1、burst=1，请求 /system/info 5 次，断言全部成功
+//  2. 关闭 runtimeInfo 后第 2 次请求返回 429
+//  3. 配置 /system/info 的规则（burst=1）后第 2 次请求返回 429
+func TestRateLimitHandler_RuntimeInfoExempt(t *testing.T) {
+	send := func(router *gin.Engine, ip string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/system/info", nil)
+		req.RemoteAddr = ip + ":12345"
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	newRouter := func() *gin.Engine {
+		router := createTestRouter(RateLimitHandler())
+		router.GET("/system/info", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "info"})
+		})
+		return router
+	}
+	rateLimitCfg := config.RateLimitConfig{Enabled: true, DefaultRate: 1, DefaultBurst: 1, Store: "memory"}
+
+	t.Run("默认不限流", func(t *testing.T) {
+		cleanup := setupRateLimitTestConfig(rateLimitCfg)
+		defer cleanup()
+		router := newRouter()
+		for i := 0; i < 5; i++ {
+			if code := send(router, "192.168.10.1"); code != http.StatusOK {
+				t.Errorf("运行信息接口请求 %d 应返回 200, 实际返回 %d", i+1, code)
+			}
+		}
+	})
+
+	t.Run("关闭后按默认限流", func(t *testing.T) {
+		cleanup := setupRateLimitTestConfig(rateLimitCfg)
+		defer cleanup()
+		disabled := false
+		app.BaseConfig.RuntimeInfo.Enabled = &disabled
+		router := newRouter()
+		send(router, "192.168.10.2")
+		if code := send(router, "192.168.10.2"); code != http.StatusTooManyRequests {
+			t.Errorf("关闭 runtimeInfo 后第 2 次请求应返回 429, 实际返回 %d", code)
+		}
+	})
+
+	t.Run("配置规则后按规则限流", func(t *testing.T) {
+		cfg := rateLimitCfg
+		cfg.DefaultBurst = 100
+		cfg.Rules = []config.RateLimitRule{{Path: "/system/info", Rate: 1, Burst: 1}}
+		cleanup := setupRateLimitTestConfig(cfg)
+		defer cleanup()
+		router := newRouter()
+		send(router, "192.168.10.3")
+		if code := send(router, "192.168.10.3"); code != http.StatusTooManyRequests {
+			t.Errorf("配置规则后第 2 次请求应返回 429, 实际返回 %d", code)
+		}
+	})
+}
+
 // ==================== 辅助函数测试 ====================
 
 // TestFindMatchingRule 测试规则匹配函数
@@ -450,7 +837,7 @@ func TestFindMatchingRule(t *testing.T) {
 // 【功能点】验证不同 keyType 生成正确格式的限流键
 // 【测试流程】
 //  1. 测试 keyType="ip" → "ip:{IP}:{path}"
-//  2. 测试 keyType="user" → "user:{userID}:{path}"
+//  2. 测试 keyType="user" → "user:{userID}:{path}"，用户 ID 来自 RequestContext 或旧版 userID/user_id 键
 //  3. 测试 keyType="global" → "global:{path}"
 //  4. 测试默认类型降级为 IP
 func TestGenerateRateLimitKey(t *testing.T) {
@@ -461,14 +848,19 @@ func TestGenerateRateLimitKey(t *testing.T) {
 		path        string
 		remoteAddr  string
 		userID      interface{}
+		legacyKey   string
+		rcUserID    string
 		keyType     string
 		expectedKey string
 	}{
-		{"IP 类型", "/api/test", "192.168.1.1:12345", nil, "ip", "ip:192.168.1.1:/api/test"},
-		{"用户类型", "/api/test", "192.168.1.1:12345", "user123", "user", "user:user123:/api/test"},
-		{"用户类型无用户", "/api/test", "192.168.1.1:12345", nil, "user", "ip:192.168.1.1:/api/test"},
-		{"全局类型", "/api/test", "192.168.1.1:12345", nil, "global", "global:/api/test"},
-		{"默认类型", "/api/test", "192.168.1.1:12345", nil, "", "ip:192.168.1.1:/api/test"},
+		{"IP 类型", "/api/test", "192.168.1.1:12345", nil, "", "", "ip", "ip:192.168.1.1:/api/test"},
+		{"用户类型", "/api/test", "192.168.1.1:12345", "user123", "userID", "", "user", "user:user123:/api/test"},
+		{"用户类型旧版 user_id 键", "/api/test", "192.168.1.1:12345", 42, "user_id", "", "user", "user:42:/api/test"},
+		{"用户类型 RequestContext", "/api/test", "192.168.1.1:12345", nil, "", "rc-user", "user", "user:rc-user:/api/test"},
+		{"用户类型 RequestContext 优先", "/api/test", "192.168.1.1:12345", "legacy", "userID", "rc-user", "user", "user:rc-user:/api/test"},
+		{"用户类型无用户", "/api/test", "192.168.1.1:12345", nil, "", "", "user", "ip:192.168.1.1:/api/test"},
+		{"全局类型", "/api/test", "192.168.1.1:12345", nil, "", "", "global", "global:/api/test"},
+		{"默认类型", "/api/test", "192.168.1.1:12345", nil, "", "", "", "ip:192.168.1.1:/api/test"},
 	}
 
 	for _, tt := range tests {
@@ -481,7 +873,10 @@ func TestGenerateRateLimitKey(t *testing.T) {
 
 			// 设置用户 ID
 			if tt.userID != nil {
-				c.Set("userID", tt.userID)
+				c.Set(tt.legacyKey, tt.userID)
+			}
+			if tt.rcUserID != "" {
+				ginContext.GetRequestContext(c).SetUserID(tt.rcUserID)
 			}
 
 			key := generateRateLimitKey(c, tt.keyType, tt.path)
@@ -0,0 +1,125 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现安全响应头中间件
+package middleware
+
+import (
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// secureHeader 安全响应头
+type secureHeader struct {
+	name  string
+	value string
+}
+
+// SecureHeadersHandler 安全响应头中间件
+// 为响应设置 HSTS、Content-Security-Policy、X-Frame-Options 等安全响应头，
+// 用于不经过 nginx 等网关直接访问服务的部署场景。配置项通过 app.BaseConfig.SecureHeaders 进行设置
+//
+// 功能特性：
+// - 未配置的响应头使用安全的默认值，配置为 "-" 时不输出
+// - Strict-Transport-Security 只对 HTTPS 请求输出：TLS 连接，或受信任代理通过 X-Forwarded-Proto: https 声明
+// - 匹配 excludePaths 的请求不设置任何安全响应头（如需要被嵌入 iframe 的文档页面）
+// - 在调用后续处理函数之前设置：前面的中间件已设置的同名响应头不会被覆盖，处理函数通过 c.Header 设置的值优先
+//
+// 使用示例：
+//
+//	在配置文件中启用：
+//	secureHeaders:
+//	  enabled: true
+//	  hstsIncludeSubdomains: true
+//	  excludePaths:
+//	    - "/swagger/*"
+//	service:
+//	  middlewares:
+//	    - "secureHeadersHandler"
+func SecureHeadersHandler() gin.HandlerFunc {
+	cfg := app.BaseConfig.SecureHeaders
+	headers := secureHeaderValues(&cfg)
+	hsts := hstsValue(&cfg)
+	proxies, err := cfg.ParseTrustedProxies()
+	if err != nil {
+		logger.Error("[安全响应头] secureHeaders.trustedProxies 配置有误, 已忽略: %v", err)
+	}
+
+	return func(c *gin.Context) {
+		if !cfg.Enabled || matchAuditPath(c.Request.URL.Path, cfg.ExcludePaths) {
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		for _, header := range headers {
+			if h.Get(header.name) == "" {
+				h.Set(header.name, header.value)
+			}
+		}
+		if hsts != "" && h.Get("Strict-Transport-Security") == "" && isHTTPSRequest(c, proxies) {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
+
+// secureHeaderValues 根据配置生成 HSTS 以外的安全响应头，配置为 "-" 的响应头不输出
+func secureHeaderValues(cfg *config.SecureHeadersConfig) []secureHeader {
+	candidates := []secureHeader{
+		{"Content-Security-Policy", cfg.GetContentSecurityPolicy()},
+		{"X-Frame-Options", cfg.GetFrameOptions()},
+		{"Referrer-Policy", cfg.GetReferrerPolicy()},
+		{"Permissions-Policy", cfg.GetPermissionsPolicy()},
+	}
+	if cfg.GetContentTypeNosniff() {
+		candidates = append(candidates, secureHeader{"X-Content-Type-Options", "nosniff"})
+	}
+
+	headers := make([]secureHeader, 0, len(candidates))
+	for _, header := range candidates {
+		if header.value != config.SecureHeaderDisabled {
+			headers = append(headers, header)
+		}
+	}
+	return headers
+}
+
+// hstsValue 生成 Strict-Transport-Security 的值，max-age 为负数时返回空字符串
+func hstsValue(cfg *config.SecureHeadersConfig) string {
+	maxAge := cfg.GetHSTSMaxAge()
+	if maxAge < 0 {
+		return ""
+	}
+	value := "max-age=" + strconv.Itoa(maxAge)
+	if cfg.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	return value
+}
+
+// isHTTPSRequest 判断请求是否通过 HTTPS 发起
+// TLS 连接直接判定为 HTTPS；否则只有来自受信任代理的请求才读取 X-Forwarded-Proto，避免客户端伪造
+func isHTTPSRequest(c *gin.Context, proxies []netip.Prefix) bool {
+	if c.Request.TLS != nil {
+		return true
+	}
+	if len(proxies) == 0 || !strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https") {
+		return false
+	}
+	addr, err := netip.ParseAddr(c.RemoteIP())
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,254 @@
+// Package middleware 安全响应头中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含安全响应头中间件的单元测试。
+//
+// 测试覆盖内容：
+// 1. 未启用时不设置任何安全响应头
+// 2. 未配置时使用默认值，普通 HTTP 请求不输出 HSTS
+// 3. TLS 连接输出 HSTS，受信任代理的 X-Forwarded-Proto 才被采信
+// 4. excludePaths 匹配的路径不设置安全响应头
+// 5. 处理函数设置的响应头优先，配置为 "-" 的响应头不输出
+//
+// 运行测试：go test -v ./middleware/... -run SecureHeaders
+// ==================================================
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// setupSecureHeadersTestConfig 设置安全响应头测试配置
+func setupSecureHeadersTestConfig(cfg config.SecureHeadersConfig) func() {
+	originalConfig := app.BaseConfig
+	app.BaseConfig = config.BaseConfig{
+		SecureHeaders: cfg,
+	}
+	return func() {
+		app.BaseConfig = originalConfig
+	}
+}
+
+// createSecureHeadersTestRouter 创建安全响应头测试路由
+// /api/frame 的处理函数自行设置 X-Frame-Options
+func createSecureHeadersTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(SecureHeadersHandler())
+	router.GET("/api/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+	router.GET("/api/frame", func(c *gin.Context) {
+		c.Header("X-Frame-Options", "SAMEORIGIN")
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+	router.GET("/docs/index.html", func(c *gin.Context) {
+		c.String(http.StatusOK, "docs")
+	})
+	return router
+}
+
+// ==================== SecureHeadersHandler 单元测试 ====================
+
+// TestSecureHeadersHandler_Disabled 测试未启用安全响应头
+//
+// 【功能点】验证 enabled=false 时不设置任何安全响应头
+// 【测试流程】设置 Enabled=false，发送 TLS 请求，验证无安全响应头
+func TestSecureHeadersHandler_Disabled(t *testing.T) {
+	cleanup := setupSecureHeadersTestConfig(config.SecureHeadersConfig{})
+	defer cleanup()
+
+	router := createSecureHeadersTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/test", nil)
+	req.TLS = &tls.ConnectionState{}
+	router.ServeHTTP(w, req)
+
+	for _, name := range []string{"Strict-Transport-Security", "Content-Security-Policy", "X-Frame-Options", "X-Content-Type-Options"} {
+		if w.Header().Get(name) != "" {
+			t.Errorf("禁用时不应设置 %s 头", name)
+		}
+	}
+}
+
+// TestSecureHeadersHandler_Defaults 测试默认值
+//
+// 【功能点】验证仅启用、未配置其他字段时各响应头使用默认值
+// 【测试流程】设置 Enabled=true，发送普通 HTTP 请求，验证默认响应头且不输出 HSTS
+func TestSecureHeadersHandler_Defaults(t *testing.T) {
+	cleanup := setupSecureHeadersTestConfig(config.SecureHeadersConfig{
+		Enabled: true,
+	})
+	defer cleanup()
+
+	router := createSecureHeadersTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/test", nil)
+	router.ServeHTTP(w, req)
+
+	expected := map[string]string{
+		"Content-Security-Policy": "default-src 'self'",
+		"X-Frame-Options":         "DENY",
+		"X-Content-Type-Options":  "nosniff",
+		"Referrer-Policy":         "strict-origin-when-cross-origin",
+		"Permissions-Policy":      "camera=(), microphone=(), geolocation=()",
+	}
+	for name, value := range expected {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("期望 %s 为 %q, 实际 %q", name, value, got)
+		}
+	}
+	if w.Header().Get("Strict-Transport-Security") != "" {
+		t.Error("普通 HTTP 请求不应设置 Strict-Transport-Security 头")
+	}
+}
+
+// TestSecureHeadersHandler_HSTSOverTLS 测试 TLS 连接输出 HSTS
+//
+// 【功能点】验证 TLS 连接的请求输出 HSTS，并按配置追加 includeSubDomains
+// 【测试流程】设置 HSTSMaxAge 和 HSTSIncludeSubdomains，发送带 TLS 状态的请求，验证 HSTS 值
+func TestSecureHeadersHandler_HSTSOverTLS(t *testing.T) {
+	cleanup := setupSecureHeadersTestConfig(config.SecureHeadersConfig{
+		Enabled:               true,
+		HSTSMaxAge:            600,
+		HSTSIncludeSubdomains: true,
+	})
+	defer cleanup()
+
+	router := createSecureHeadersTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/test", nil)
+	req.TLS = &tls.ConnectionState{}
+	router.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=600; includeSubDomains" {
+		t.Errorf("期望 Strict-Transport-Security 为 max-age=600; includeSubDomains, 实际 %q", got)
+	}
+}
+
+// TestSecureHeadersHandler_ForwardedProto 测试代理声明的 HTTPS
+//
+// 【功能点】验证只有来自受信任代理的 X-Forwarded-Proto: https 才输出 HSTS
+// 【测试流程】
+//  1. 未配置受信任代理时发送带 X-Forwarded-Proto 的请求，验证不输出 HSTS
+//  2. 将请求来源地址配置为受信任代理，验证输出 HSTS
+func TestSecureHeadersHandler_ForwardedProto(t *testing.T) {
+	send := func() string {
+		router := createSecureHeadersTestRouter()
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/test", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("X-Forwarded-Proto", "https")
+		router.ServeHTTP(w, req)
+		return w.Header().Get("Strict-Transport-Security")
+	}
+
+	cleanup := setupSecureHeadersTestConfig(config.SecureHeadersConfig{Enabled: true})
+	if got := send(); got != "" {
+		t.Errorf("未受信任的代理不应输出 Strict-Transport-Security, 实际 %q", got)
+	}
+	cleanup()
+
+	cleanup = setupSecureHeadersTestConfig(config.SecureHeadersConfig{
+		Enabled:        true,
+		TrustedProxies: []string{"192.0.2.0/24"},
+	})
+	defer cleanup()
+	if got := send(); got != "max-age=31536000" {
+		t.Errorf("期望 Strict-Transport-Security 为 max-age=31536000, 实际 %q", got)
+	}
+}
+
+// TestSecureHeadersHandler_ExcludePaths 测试排除路径
+//
+// 【功能点】验证 excludePaths 匹配的路径不设置安全响应头，其他路径不受影响
+// 【测试流程】配置 /docs/*，分别请求 /docs/index.html 和 /api/test，验证响应头
+func TestSecureHeadersHandler_ExcludePaths(t *testing.T) {
+	cleanup := setupSecureHeadersTestConfig(config.SecureHeadersConfig{
+		Enabled:      true,
+		ExcludePaths: []string{"/docs/*"},
+	})
+	defer cleanup()
+
+	router := createSecureHeadersTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/docs/index.html", nil)
+	req.TLS = &tls.ConnectionState{}
+	router.ServeHTTP(w, req)
+	if w.Header().Get("X-Frame-Options") != "" || w.Header().Get("Strict-Transport-Security") != "" {
+		t.Error("排除路径不应设置安全响应头")
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/test", nil)
+	router.ServeHTTP(w, req)
+	if w.Header().Get("X-Frame-Options") != "DENY" {
+		t.Errorf("非排除路径应设置 X-Frame-Options, 实际 %q", w.Header().Get("X-Frame-Options"))
+	}
+}
+
+// TestSecureHeadersHandler_HandlerOverride 测试处理函数设置的响应头优先
+//
+// 【功能点】验证处理函数设置的同名响应头覆盖中间件的值，且只有一个值
+// 【测试流程】请求自行设置 X-Frame-Options 的路由，验证响应头为处理函数设置的值
+func TestSecureHeadersHandler_HandlerOverride(t *testing.T) {
+	cleanup := setupSecureHeadersTestConfig(config.SecureHeadersConfig{
+		Enabled: true,
+	})
+	defer cleanup()
+
+	router := createSecureHeadersTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/frame", nil)
+	router.ServeHTTP(w, req)
+
+	values := w.Header().Values("X-Frame-Options")
+	if len(values) != 1 || values[0] != "SAMEORIGIN" {
+		t.Errorf("期望 X-Frame-Options 为 [SAMEORIGIN], 实际 %v", values)
+	}
+}
+
+// TestSecureHeadersHandler_DisabledHeader 测试禁用单个响应头
+//
+// 【功能点】验证配置为 "-" 的响应头、contentTypeNosniff=false 和负数 hstsMaxAge 不输出
+// 【测试流程】禁用 CSP、nosniff 和 HSTS，发送 TLS 请求，验证对应响应头不存在而其他响应头仍然输出
+func TestSecureHeadersHandler_DisabledHeader(t *testing.T) {
+	nosniff := false
+	cleanup := setupSecureHeadersTestConfig(config.SecureHeadersConfig{
+		Enabled:               true,
+		HSTSMaxAge:            -1,
+		ContentSecurityPolicy: config.SecureHeaderDisabled,
+		ContentTypeNosniff:    &nosniff,
+	})
+	defer cleanup()
+
+	router := createSecureHeadersTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/test", nil)
+	req.TLS = &tls.ConnectionState{}
+	router.ServeHTTP(w, req)
+
+	for _, name := range []string{"Strict-Transport-Security", "Content-Security-Policy", "X-Content-Type-Options"} {
+		if w.Header().Get(name) != "" {
+			t.Errorf("%s 已禁用, 不应输出, 实际 %q", name, w.Header().Get(name))
+		}
+	}
+	if w.Header().Get("X-Frame-Options") != "DENY" {
+		t.Errorf("期望 X-Frame-Options 为 DENY, 实际 %q", w.Header().Get("X-Frame-Options"))
+	}
+}
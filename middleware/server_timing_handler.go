@@ -0,0 +1,119 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现请求耗时分解中间件，记录中间件、处理函数等阶段的耗时并通过 Server-Timing 响应头输出
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// serverTimingHeader Server-Timing 响应头名称
+const serverTimingHeader = "Server-Timing"
+
+// serverTimingWriter 在响应头写出前设置 Server-Timing 响应头
+// 响应头随第一次写入响应体输出，此时处理函数中已结束的阶段按实际耗时输出，外层中间件按已执行的部分输出
+type serverTimingWriter struct {
+	gin.ResponseWriter
+	timings *ginContext.Timings
+	topN    int
+	done    bool
+}
+
+// setHeader 设置 Server-Timing 响应头，只设置一次，响应头已写出时跳过
+func (w *serverTimingWriter) setHeader() {
+	if w.done {
+		return
+	}
+	w.done = true
+	if !w.ResponseWriter.Written() {
+		w.ResponseWriter.Header().Set(serverTimingHeader, w.timings.ServerTimingHeader(w.topN))
+	}
+}
+
+// WriteHeaderNow 设置 Server-Timing 响应头后写出响应头
+func (w *serverTimingWriter) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Write 设置 Server-Timing 响应头后写入响应
+func (w *serverTimingWriter) Write(b []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString 设置 Server-Timing 响应头后写入字符串响应
+func (w *serverTimingWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush 设置 Server-Timing 响应头后刷新响应
+func (w *serverTimingWriter) Flush() {
+	w.setHeader()
+	w.ResponseWriter.Flush()
+}
+
+// ServerTimingHandler 请求耗时分解中间件
+// 为请求创建耗时记录（ginContext.EnableTimings），在响应头写出前输出 Server-Timing 响应头；
+// 请求耗时达到 slowThreshold 时在 warn 日志中输出全部阶段的耗时
+// 由框架在开启 service.enableServerTiming 时注册在所有中间件之前，各中间件的耗时由 TimedMiddleware 记录
+//
+// 参数：
+//   - cfg: 请求耗时分解配置
+//
+// 返回：
+//   - gin.HandlerFunc: 中间件
+func ServerTimingHandler(cfg config.ServerTimingConfig) gin.HandlerFunc {
+	topN := cfg.GetTopN()
+	return func(c *gin.Context) {
+		timings := ginContext.EnableTimings(c)
+		writer := &serverTimingWriter{ResponseWriter: c.Writer, timings: timings, topN: topN}
+		c.Writer = writer
+
+		c.Next()
+
+		// 没有响应体的响应在处理完成后由 gin 写出响应头，此时所有阶段均已结束
+		writer.setHeader()
+		if cfg.SlowThreshold > 0 {
+			if elapsed := timings.Elapsed(); elapsed >= cfg.SlowThreshold {
+				logger.FromContext(c.Request.Context()).Warn("[ServerTiming] 慢请求 %s %s, 状态码: %d, 耗时: %s, 各阶段耗时: %s",
+					c.Request.Method, c.Request.URL.Path, writer.Status(), elapsed.Round(time.Microsecond), formatTimingSpans(timings.Spans()))
+			}
+		}
+	}
+}
+
+// TimedMiddleware 包装中间件，记录中间件自身的耗时（不含后续的中间件和处理函数），阶段名称为 name
+// 请求未开启耗时记录时直接调用原中间件
+func TimedMiddleware(name string, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		end := ginContext.StartMiddlewareSpan(c, name)
+		handler(c)
+		end()
+	}
+}
+
+// HandlerTiming 记录处理函数耗时的中间件，阶段名称为 handler
+// 由框架注册在所有中间件之后，阶段包含路由分组中间件和处理函数的耗时
+func HandlerTiming() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		end := ginContext.StartSpan(c, "handler")
+		c.Next()
+		end()
+	}
+}
+
+// formatTimingSpans 将阶段耗时格式化为 "路径=耗时" 列表，如 auth=1.2ms, handler=35ms, handler/db=20ms
+func formatTimingSpans(spans []ginContext.Span) string {
+	parts := make([]string, 0, len(spans))
+	for _, span := range spans {
+		parts = append(parts, span.Path+"="+span.Duration.Round(time.Microsecond).String())
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,140 @@
+// Package middleware 请求耗时分解中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 ServerTimingHandler、TimedMiddleware、HandlerTiming 的单元测试。
+//
+// 测试覆盖内容：
+// 1. 响应头 Server-Timing 包含中间件、处理函数中记录的阶段和 total，响应体写出前已设置
+// 2. 没有响应体的响应也输出 Server-Timing
+// 3. 请求耗时达到 slowThreshold 时记录包含各阶段耗时的 warn 日志
+// 4. 未注册 ServerTimingHandler 时 TimedMiddleware、HandlerTiming 不影响请求，不输出响应头
+//
+// 运行测试：go test -v ./middleware/... -run ServerTiming
+// ==================================================
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// newServerTimingTestRouter 创建按框架顺序注册耗时分解中间件的路由，enabled 为 false 时不注册 ServerTimingHandler
+func newServerTimingTestRouter(enabled bool, cfg config.ServerTimingConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if enabled {
+		router.Use(ServerTimingHandler(cfg))
+	}
+	router.Use(TimedMiddleware("auth", func(c *gin.Context) {
+		time.Sleep(2 * time.Millisecond)
+		c.Next()
+	}))
+	router.Use(HandlerTiming())
+	router.GET("/orders", func(c *gin.Context) {
+		end := ginContext.StartSpan(c, "loadOrders")
+		time.Sleep(10 * time.Millisecond)
+		end()
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+	router.DELETE("/orders", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+// TestServerTimingHandler_Header 测试 Server-Timing 响应头
+//
+// 【功能点】验证响应头包含处理函数中记录的阶段、中间件阶段、handler 阶段和 total
+// 【测试流程】
+//  1. 请求 GET /orders，处理函数中记录 sleep 10ms 的 loadOrders 阶段后输出 JSON
+//  2. 断言响应头包含 loadOrders、handler、auth、total，且 loadOrders 的耗时不小于 10ms
+func TestServerTimingHandler_Header(t *testing.T) {
+	router := newServerTimingTestRouter(true, config.ServerTimingConfig{})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	header := w.Header().Get("Server-Timing")
+	for _, name := range []string{"loadOrders;dur=", "handler;dur=", "auth;dur=", "total;dur="} {
+		assert.Contains(t, header, name)
+	}
+	for _, metric := range strings.Split(header, ", ") {
+		if strings.HasPrefix(metric, "loadOrders;dur=") {
+			millis, err := strconv.ParseFloat(strings.TrimPrefix(metric, "loadOrders;dur="), 64)
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, millis, 10.0)
+		}
+	}
+}
+
+// TestServerTimingHandler_NoBody 测试没有响应体的响应
+//
+// 【功能点】验证处理函数只设置状态码时，处理完成后输出 Server-Timing 响应头
+// 【测试流程】请求 DELETE /orders，断言状态码 204 且响应头包含 handler 和 total
+func TestServerTimingHandler_NoBody(t *testing.T) {
+	router := newServerTimingTestRouter(true, config.ServerTimingConfig{})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/orders", nil))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	header := w.Header().Get("Server-Timing")
+	assert.Contains(t, header, "handler;dur=")
+	assert.Contains(t, header, "total;dur=")
+}
+
+// TestServerTimingHandler_SlowRequest 测试慢请求日志
+//
+// 【功能点】验证请求耗时达到 slowThreshold 时记录 warn 日志，日志包含各阶段的路径和耗时；未达到时不记录
+// 【测试流程】
+//  1. slowThreshold 为 5ms，请求 GET /orders，断言记录一条包含 auth/handler/loadOrders 的 warn 日志
+//  2. slowThreshold 为 1 分钟，断言没有慢请求日志
+func TestServerTimingHandler_SlowRequest(t *testing.T) {
+	slowLogs := func(threshold time.Duration) []*logrus.Entry {
+		hook := logtest.NewLocal(logger.Logger)
+		defer hook.Reset()
+		router := newServerTimingTestRouter(true, config.ServerTimingConfig{SlowThreshold: threshold})
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+		var entries []*logrus.Entry
+		for _, entry := range hook.AllEntries() {
+			if strings.HasPrefix(entry.Message, "[ServerTiming]") {
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	}
+
+	entries := slowLogs(5 * time.Millisecond)
+	require.Len(t, entries, 1)
+	assert.Equal(t, logrus.WarnLevel, entries[0].Level)
+	assert.Contains(t, entries[0].Message, "GET /orders")
+	assert.Contains(t, entries[0].Message, "auth/handler/loadOrders=")
+
+	assert.Empty(t, slowLogs(time.Minute))
+}
+
+// TestServerTimingHandler_Disabled 测试未开启耗时分解
+//
+// 【功能点】验证未注册 ServerTimingHandler 时 TimedMiddleware、HandlerTiming、StartSpan 不影响请求
+// 【测试流程】请求 GET /orders，断言状态码 200 且没有 Server-Timing 响应头
+func TestServerTimingHandler_Disabled(t *testing.T) {
+	router := newServerTimingTestRouter(false, config.ServerTimingConfig{})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Server-Timing"))
+}
@@ -0,0 +1,106 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现基于 Cookie 的服务端会话中间件
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/session"
+)
+
+var (
+	sessionOnce    sync.Once
+	sessionManager *session.Manager
+)
+
+// initSessionManager 初始化会话管理器（单例）
+func initSessionManager() {
+	sessionOnce.Do(func() {
+		cfg := app.BaseConfig.Session
+
+		var store session.Store
+		switch cfg.GetStore() {
+		case "memory":
+			store = session.NewMemoryStore(time.Duration(cfg.GetCleanupInterval()) * time.Second)
+			logger.Info("[会话] 使用内存会话存储")
+		default:
+			if app.Redis != nil {
+				store = session.NewRedisStore(app.Redis, cfg.GetKeyPrefix())
+				logger.Info("[会话] 使用 Redis 会话存储")
+			} else {
+				logger.Warn("[会话] Redis 未初始化，降级为内存会话存储")
+				store = session.NewMemoryStore(time.Duration(cfg.GetCleanupInterval()) * time.Second)
+			}
+		}
+
+		sessionManager = session.NewManager(store, sessionOptions(&cfg))
+	})
+}
+
+// sessionOptions 根据会话配置生成会话管理器的 Cookie 选项，未配置的项使用默认值
+func sessionOptions(cfg *config.SessionConfig) session.Options {
+	return session.Options{
+		CookieName: cfg.GetCookieName(),
+		Domain:     cfg.Domain,
+		Path:       cfg.GetPath(),
+		MaxAge:     cfg.GetMaxAge(),
+		Secure:     cfg.Secure,
+		HttpOnly:   cfg.GetHttpOnly(),
+		SameSite:   cfg.GetSameSite(),
+	}
+}
+
+// SessionHandler 会话中间件
+// 根据会话 Cookie 加载服务端会话，并存入 gin.Context 供后续处理器通过 ginContext.GetSession 获取
+// 配置项通过 app.BaseConfig.Session 进行设置
+//
+// 功能特性：
+// - 支持 Redis（分布式）和内存（单机）两种存储方式
+// - 每次访问会话时顺延过期时间（滑动过期）
+// - 新会话在首次调用 Save 时才会写入存储并下发 Cookie
+// - 支持重新生成会话 ID，防止会话固定攻击
+//
+// 使用示例：
+//
+//	在配置文件中启用：
+//	session:
+//	  enabled: true
+//	  cookieName: "console_session"
+//	  maxAge: 7200
+//	  httpOnly: true
+//	  store: "redis"
+func SessionHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !app.BaseConfig.Session.Enabled {
+			c.Next()
+			return
+		}
+
+		initSessionManager()
+		loadSession(c, sessionManager)
+		c.Next()
+	}
+}
+
+// loadSession 加载会话并存入 gin.Context
+// 加载失败时使用新会话继续处理请求，不中断请求
+func loadSession(c *gin.Context, manager *session.Manager) {
+	sess, err := manager.Load(c.Writer, c.Request)
+	if err != nil {
+		logger.Warn("[会话] 加载会话失败，已创建新会话: %v", err)
+	}
+	c.Set(session.ContextKey, sess)
+}
+
+// CloseSessionStore 关闭会话存储
+func CloseSessionStore() error {
+	if sessionManager != nil {
+		return sessionManager.Store().Close()
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+// Package middleware 会话中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含会话中间件的单元测试，使用 miniredis 模拟 Redis，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 会话功能禁用时的行为
+// 2. 首次 Save 时下发 Cookie
+// 3. 会话数据跨请求持久化
+// 4. Destroy 清除 Cookie 和 Redis 键
+// 5. 重新生成会话 ID 后旧 ID 失效
+// 6. 会话 Cookie 默认 HttpOnly，显式配置 httpOnly: false 时关闭
+//
+// 运行测试：go test -v ./middleware/... -run Session
+// ==================================================
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/session"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// createSessionTestRouter 创建会话测试路由
+// 使用 miniredis 作为会话存储，包含登录、读取、登出三个端点
+func createSessionTestRouter(t *testing.T) (*gin.Engine, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	manager := session.NewManager(session.NewRedisStore(client, "session:"), session.Options{
+		CookieName: "sid",
+		Path:       "/",
+		MaxAge:     3600,
+		HttpOnly:   true,
+		SameSite:   http.SameSiteLaxMode,
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		loadSession(c, manager)
+		c.Next()
+	})
+	router.POST("/login", func(c *gin.Context) {
+		sess := ginContext.GetSession(c)
+		if err := sess.RegenerateID(); err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		sess.Set("user", c.Query("user"))
+		if err := sess.Save(); err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.String(http.StatusOK, sess.ID())
+	})
+	router.GET("/me", func(c *gin.Context) {
+		user, _ := ginContext.GetSession(c).Get("user")
+		c.JSON(http.StatusOK, gin.H{"user": user})
+	})
+	router.POST("/logout", func(c *gin.Context) {
+		if err := ginContext.GetSession(c).Destroy(); err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	return router, mr
+}
+
+// doSessionRequest 发送请求，可选携带会话 Cookie
+func doSessionRequest(router *gin.Engine, method, target string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// sessionCookie 从响应中获取会话 Cookie
+func sessionCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "sid" {
+			return c
+		}
+	}
+	return nil
+}
+
+// ==================== 单元测试 ====================
+
+// TestSessionHandler_Disabled 测试会话功能禁用时的行为
+//
+// 【功能点】验证 session.enabled=false 时中间件直接放行，GetSession 返回 nil
+// 【测试流程】禁用会话功能，发送请求，断言未下发 Cookie 且上下文中无会话
+func TestSessionHandler_Disabled(t *testing.T) {
+	originalConfig := app.BaseConfig
+	app.BaseConfig = config.BaseConfig{Session: config.SessionConfig{Enabled: false}}
+	defer func() { app.BaseConfig = originalConfig }()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(SessionHandler())
+	var hasSession bool
+	router.GET("/", func(c *gin.Context) {
+		hasSession = ginContext.GetSession(c) != nil
+		c.Status(http.StatusOK)
+	})
+
+	w := doSessionRequest(router, http.MethodGet, "/", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, hasSession)
+	assert.Empty(t, w.Header().Values("Set-Cookie"))
+}
+
+// TestSessionOptions_HttpOnlyDefault 测试会话 Cookie 的 HttpOnly 默认值
+//
+// 【功能点】验证未配置 httpOnly 时会话 Cookie 为 HttpOnly，显式配置为 false 时不是
+// 【测试流程】
+//  1. 使用空配置生成 Cookie 选项，断言 HttpOnly 为 true，其他项为默认值
+//  2. 配置 httpOnly: false 生成 Cookie 选项，断言 HttpOnly 为 false
+func TestSessionOptions_HttpOnlyDefault(t *testing.T) {
+	opts := sessionOptions(&config.SessionConfig{})
+	assert.True(t, opts.HttpOnly)
+	assert.Equal(t, "gin_session", opts.CookieName)
+	assert.Equal(t, http.SameSiteLaxMode, opts.SameSite)
+
+	httpOnly := false
+	opts = sessionOptions(&config.SessionConfig{HttpOnly: &httpOnly})
+	assert.False(t, opts.HttpOnly)
+}
+
+// TestSessionHandler_Lifecycle 测试会话完整生命周期
+//
+// 【功能点】验证 Cookie 下发、跨请求持久化、登录时 ID 重新生成以及登出销毁
+// 【测试流程】
+//  1. 未保存会话的请求不下发 Cookie
+//  2. 匿名会话保存后下发 Cookie；登录时重新生成 ID，旧 ID 失效
+//  3. 携带新 Cookie 读取用户信息
+//  4. 登出后 Cookie 被清除，Redis 键被删除，再次读取无数据
+func TestSessionHandler_Lifecycle(t *testing.T) {
+	router, mr := createSessionTestRouter(t)
+
+	// 1. 只读请求不下发 Cookie
+	w := doSessionRequest(router, http.MethodGet, "/me", nil)
+	assert.Nil(t, sessionCookie(w))
+
+	// 2. 第一次登录，下发 Cookie
+	w = doSessionRequest(router, http.MethodPost, "/login?user=anonymous", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	firstCookie := sessionCookie(w)
+	require.NotNil(t, firstCookie)
+	assert.True(t, firstCookie.HttpOnly)
+	assert.True(t, mr.Exists("session:"+firstCookie.Value))
+
+	// 携带已有会话再次登录，ID 重新生成，旧 ID 失效
+	w = doSessionRequest(router, http.MethodPost, "/login?user=alice", firstCookie)
+	require.Equal(t, http.StatusOK, w.Code)
+	loginCookie := sessionCookie(w)
+	require.NotNil(t, loginCookie)
+	assert.NotEqual(t, firstCookie.Value, loginCookie.Value)
+	assert.False(t, mr.Exists("session:"+firstCookie.Value))
+
+	w = doSessionRequest(router, http.MethodGet, "/me", firstCookie)
+	assert.JSONEq(t, `{"user":null}`, w.Body.String())
+
+	// 3. 新 Cookie 可读取用户信息
+	w = doSessionRequest(router, http.MethodGet, "/me", loginCookie)
+	assert.JSONEq(t, `{"user":"alice"}`, w.Body.String())
+
+	// 4. 登出
+	w = doSessionRequest(router, http.MethodPost, "/logout", loginCookie)
+	require.Equal(t, http.StatusOK, w.Code)
+	cleared := sessionCookie(w)
+	require.NotNil(t, cleared)
+	assert.Empty(t, cleared.Value)
+	assert.Less(t, cleared.MaxAge, 0)
+	assert.False(t, mr.Exists("session:"+loginCookie.Value))
+
+	w = doSessionRequest(router, http.MethodGet, "/me", loginCookie)
+	assert.JSONEq(t, `{"user":null}`, w.Body.String())
+}
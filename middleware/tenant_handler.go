@@ -0,0 +1,130 @@
+// Package middleware 提供 HTTP 中间件
+// 本文件实现多租户识别中间件，将请求路由到租户对应的数据库
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// TenantHandler 多租户识别中间件
+// 从请求中识别租户 ID 并存入请求上下文，业务代码通过 app.TenantDB(c) 获取租户对应的数据库，配置项通过 app.BaseConfig.Tenant 进行设置
+//
+// 功能特性：
+// - 依次从 tenant.header 请求头（默认 X-Tenant-Id）、认证信息（ginContext.GetClaims）的 tenant.claim 字段（默认 tenantId）读取租户 ID
+// - 都没有时使用 tenant.defaultTenant，用于未登录的公开接口；未配置默认租户时返回 response.ResponseTenantMissing 响应码
+// - 按 tenant.mapping 或 core.SetTenantResolver 注册的解析函数映射数据库别名，租户不存在时返回 response.ResponseTenantUnknown 响应码
+// - 在中间件中完成租户数据库的延迟连接，连接失败时返回 response.ResponseFail 响应码，后续 handler 中 app.TenantDB(c) 不会返回 nil
+//
+// 注意：从认证信息读取租户 ID 时，应在 service.middlewares 中将 tenantHandler 放在 JWT 等认证中间件之后
+//
+// 使用示例：
+//
+//	在配置文件中启用：
+//	tenant:
+//	  enabled: true
+//	  header: "X-Tenant-Id"
+//	  claim: "tenantId"
+//	  defaultTenant: "public"
+//	  mapping:
+//	    public: "tenant_public"
+//	    acme: "tenant_acme"
+//	service:
+//	  middlewares:
+//	    - "tenantHandler"
+func TenantHandler() gin.HandlerFunc {
+	cfg := app.BaseConfig.Tenant
+	return func(c *gin.Context) {
+		if !cfg.Enabled {
+			c.Next()
+			return
+		}
+		handleTenant(c, &cfg)
+	}
+}
+
+// handleTenant 识别租户 ID 并连接租户数据库，失败时中止请求
+func handleTenant(c *gin.Context, cfg *config.TenantConfig) {
+	tenantID := c.GetHeader(cfg.GetHeader())
+	if tenantID == "" {
+		tenantID = tenantFromClaims(c, cfg.GetClaim())
+	}
+	if tenantID == "" {
+		tenantID = cfg.DefaultTenant
+	}
+	if tenantID == "" {
+		response.WriteError(c, response.ResponseTenantMissing)
+		return
+	}
+
+	if _, err := app.GetTenantDB(c.Request.Context(), tenantID); err != nil {
+		if errors.Is(err, app.ErrUnknownTenant) {
+			logger.Warn("[tenant] 租户不存在, tenant: %s, path: %s", tenantID, c.Request.URL.Path)
+			response.WriteError(c, response.ResponseTenantUnknown)
+			return
+		}
+		logger.Error("[tenant] 获取租户数据库失败, tenant: %s, err: %v", tenantID, err)
+		response.WriteError(c, response.ResponseFail)
+		return
+	}
+
+	ginContext.SetTenantID(c, tenantID)
+	c.Next()
+}
+
+// tenantFromClaims 从认证信息中读取租户 ID
+// 支持以字符串为键的 map（如 jwt.MapClaims）和结构体（按 json 标签或字段名匹配，忽略大小写）
+func tenantFromClaims(c *gin.Context, claim string) string {
+	claims, ok := ginContext.GetClaims(c)
+	if !ok || claims == nil {
+		return ""
+	}
+	v := reflect.ValueOf(claims)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	var field reflect.Value
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return ""
+		}
+		field = v.MapIndex(reflect.ValueOf(claim).Convert(v.Type().Key()))
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+			if sf.IsExported() && (name == claim || (name == "" && strings.EqualFold(sf.Name, claim))) {
+				field = v.Field(i)
+				break
+			}
+		}
+	}
+	for field.IsValid() && (field.Kind() == reflect.Interface || field.Kind() == reflect.Pointer) {
+		if field.IsNil() {
+			return ""
+		}
+		field = field.Elem()
+	}
+	if !field.IsValid() {
+		return ""
+	}
+	if field.Kind() == reflect.String {
+		return field.String()
+	}
+	return fmt.Sprint(field.Interface())
+}
@@ -0,0 +1,245 @@
+// Package middleware 多租户识别中间件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含多租户识别中间件和 app.TenantDB 的单元测试，使用两个 SQLite 数据库文件模拟两个租户库，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 不同租户请求头的请求读写各自的数据库，数据互相隔离，每个数据库只在首次使用时连接一次
+// 2. 缺少租户 ID 返回 ResponseTenantMissing，配置默认租户后使用默认租户
+// 3. 租户不存在返回 ResponseTenantUnknown，请求头未携带时从认证信息读取租户 ID
+// 4. 注册解析函数后按解析函数映射数据库，不再使用 tenant.mapping
+//
+// 运行测试：go test -v ./middleware/... -run Tenant
+// ==================================================
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// ==================== 测试辅助函数 ====================
+
+// tenantNote 租户库中的测试表
+type tenantNote struct {
+	ID      uint `gorm:"primaryKey"`
+	Content string
+}
+
+// setupTenantTest 设置多租户测试环境，开启 service.useHTTPStatus
+// dbList 配置 tenant_a、tenant_b 两个延迟连接的 SQLite 数据库文件，acme → tenant_a、globex → tenant_b
+//
+// 返回：
+//   - *atomic.Int32: DBOpener 的调用次数
+func setupTenantTest(t *testing.T) *atomic.Int32 {
+	originalConfig, originalList, originalOpener := app.BaseConfig, app.DBList, app.DBOpener
+	t.Cleanup(func() {
+		_ = app.CloseAllDB()
+		app.BaseConfig, app.DBList, app.DBOpener = originalConfig, originalList, originalOpener
+		app.SetTenantResolver(nil)
+		response.SetUseHTTPStatus(false)
+	})
+	response.SetUseHTTPStatus(true)
+
+	dir := t.TempDir()
+	app.DBList = nil
+	app.BaseConfig.DbList = []config.DbInfo{
+		{AliasName: "tenant_a", DBName: filepath.Join(dir, "tenant_a.db"), Lazy: true},
+		{AliasName: "tenant_b", DBName: filepath.Join(dir, "tenant_b.db"), Lazy: true},
+	}
+	app.BaseConfig.Tenant = config.TenantConfig{
+		Enabled: true,
+		Mapping: map[string]string{"acme": "tenant_a", "globex": "tenant_b"},
+	}
+
+	var opened atomic.Int32
+	app.DBOpener = func(dbInfo config.DbInfo) (*gorm.DB, error) {
+		opened.Add(1)
+		db, err := gorm.Open(sqlite.Open(dbInfo.DBName), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+		if err != nil {
+			return nil, err
+		}
+		return db, db.AutoMigrate(&tenantNote{})
+	}
+	return &opened
+}
+
+// createTenantTestRouter 创建多租户测试路由
+// POST /notes 写入一条记录，GET /notes 返回当前租户 ID 和租户库中的所有记录
+func createTenantTestRouter(before ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(before...)
+	router.Use(TenantHandler())
+	router.POST("/notes", func(c *gin.Context) {
+		app.TenantDB(c).Create(&tenantNote{Content: c.Query("content")})
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/notes", func(c *gin.Context) {
+		var notes []tenantNote
+		app.TenantDB(c).Order("id").Find(&notes)
+		contents := make([]string, 0, len(notes))
+		for _, note := range notes {
+			contents = append(contents, note.Content)
+		}
+		c.JSON(http.StatusOK, gin.H{"tenant": ginContext.GetTenantID(c), "notes": contents})
+	})
+	return router
+}
+
+// doTenantRequest 发送测试请求，tenant 非空时通过 X-Tenant-Id 请求头携带
+func doTenantRequest(router *gin.Engine, method, target, tenant string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if tenant != "" {
+		req.Header.Set("X-Tenant-Id", tenant)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// tenantNotesResult GET /notes 的响应
+type tenantNotesResult struct {
+	Tenant string   `json:"tenant"`
+	Notes  []string `json:"notes"`
+}
+
+// getTenantNotes 请求 GET /notes 并解析响应
+func getTenantNotes(t *testing.T, router *gin.Engine, tenant string) tenantNotesResult {
+	w := doTenantRequest(router, http.MethodGet, "/notes", tenant)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result tenantNotesResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+// decodeTenantError 解析错误响应的响应码
+func decodeTenantError(t *testing.T, w *httptest.ResponseRecorder) int {
+	var resp response.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Code
+}
+
+// ==================== 测试用例 ====================
+
+// TestTenantHandler_Isolation 测试租户数据隔离
+//
+// 【功能点】验证不同租户的请求读写各自的数据库，数据库在首次使用时连接且只连接一次
+// 【测试流程】
+//  1. 断言启动时没有连接任何数据库
+//  2. acme 写入 a1、a2，globex 写入 b1
+//  3. 断言 acme 只读到 a1、a2，globex 只读到 b1，租户 ID 存入请求上下文
+//  4. 断言 DBOpener 共调用 2 次，两个别名均缓存在 DBList 中
+func TestTenantHandler_Isolation(t *testing.T) {
+	opened := setupTenantTest(t)
+	router := createTenantTestRouter()
+	assert.Equal(t, int32(0), opened.Load())
+
+	for _, req := range []struct{ tenant, content string }{{"acme", "a1"}, {"globex", "b1"}, {"acme", "a2"}} {
+		w := doTenantRequest(router, http.MethodPost, "/notes?content="+req.content, req.tenant)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	}
+
+	assert.Equal(t, tenantNotesResult{Tenant: "acme", Notes: []string{"a1", "a2"}}, getTenantNotes(t, router, "acme"))
+	assert.Equal(t, tenantNotesResult{Tenant: "globex", Notes: []string{"b1"}}, getTenantNotes(t, router, "globex"))
+	assert.Equal(t, int32(2), opened.Load())
+	assert.Len(t, app.DBStats("tenant_a", "tenant_b"), 2)
+}
+
+// TestTenantHandler_Rejected 测试拒绝无法识别租户的请求
+//
+// 【功能点】验证缺少租户 ID 和租户不存在时返回对应的响应码，不调用后续 handler
+// 【测试流程】
+//  1. 不携带租户请求头，断言 400、响应码 41020
+//  2. 携带未映射的租户 initech，断言 403、响应码 41021，且没有连接任何数据库
+//  3. 配置默认租户 globex 后不携带请求头，断言使用 globex 的数据库
+func TestTenantHandler_Rejected(t *testing.T) {
+	opened := setupTenantTest(t)
+	router := createTenantTestRouter()
+
+	w := doTenantRequest(router, http.MethodGet, "/notes", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, response.ResponseTenantMissing.GetCode(), decodeTenantError(t, w))
+
+	w = doTenantRequest(router, http.MethodPost, "/notes?content=x", "initech")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, response.ResponseTenantUnknown.GetCode(), decodeTenantError(t, w))
+	assert.Equal(t, int32(0), opened.Load())
+
+	app.BaseConfig.Tenant.DefaultTenant = "globex"
+	router = createTenantTestRouter()
+	assert.Equal(t, "globex", getTenantNotes(t, router, "").Tenant)
+}
+
+// TestTenantHandler_Claims 测试从认证信息读取租户 ID
+//
+// 【功能点】验证请求头未携带租户 ID 时从认证信息（map 或结构体）的 tenant.claim 字段读取，请求头优先
+// 【测试流程】
+//  1. 认证信息为 map[string]any{"tenantId": "globex"}，断言使用 globex
+//  2. 请求头携带 acme，断言请求头优先
+//  3. 认证信息为带 json 标签的结构体，断言按标签读取
+func TestTenantHandler_Claims(t *testing.T) {
+	setupTenantTest(t)
+	router := createTenantTestRouter(func(c *gin.Context) {
+		ginContext.SetClaims(c, map[string]any{"tenantId": "globex"})
+	})
+	assert.Equal(t, "globex", getTenantNotes(t, router, "").Tenant)
+	assert.Equal(t, "acme", getTenantNotes(t, router, "acme").Tenant)
+
+	type claims struct {
+		Subject string `json:"sub"`
+		Tenant  string `json:"tenantId"`
+	}
+	router = createTenantTestRouter(func(c *gin.Context) {
+		ginContext.SetClaims(c, &claims{Subject: "alice", Tenant: "acme"})
+	})
+	assert.Equal(t, "acme", getTenantNotes(t, router, "").Tenant)
+}
+
+// TestTenantHandler_Resolver 测试租户解析函数
+//
+// 【功能点】验证注册解析函数后按解析函数映射数据库，不再使用 tenant.mapping，解析函数返回 ErrUnknownTenant 时拒绝请求
+// 【测试流程】
+//  1. 注册解析函数：以 b- 开头的租户映射到 tenant_b，其余返回 ErrUnknownTenant
+//  2. b-1 写入记录，断言 globex（tenant_b）能读到，说明两者使用同一数据库
+//  3. 断言 mapping 中存在的 acme 被拒绝，返回响应码 41021
+func TestTenantHandler_Resolver(t *testing.T) {
+	setupTenantTest(t)
+	app.SetTenantResolver(func(ctx context.Context, tenantID string) (string, error) {
+		if strings.HasPrefix(tenantID, "b-") {
+			return "tenant_b", nil
+		}
+		return "", app.ErrUnknownTenant
+	})
+	router := createTenantTestRouter()
+
+	w := doTenantRequest(router, http.MethodPost, "/notes?content=from-resolver", "b-1")
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	app.SetTenantResolver(nil)
+	assert.Equal(t, []string{"from-resolver"}, getTenantNotes(t, router, "globex").Notes)
+
+	app.SetTenantResolver(func(ctx context.Context, tenantID string) (string, error) {
+		return "", app.ErrUnknownTenant
+	})
+	w = doTenantRequest(router, http.MethodGet, "/notes", "acme")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, response.ResponseTenantUnknown.GetCode(), decodeTenantError(t, w))
+}
@@ -3,8 +3,15 @@
 package middleware
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,23 +21,27 @@ import (
 )
 
 // TimeoutHandler 创建一个同时处理超时和记录请求响应时长的中间件
-// 该中间件使用 context.WithTimeout 实现协作式超时控制，避免在 goroutine 中操作
-// gin.Context 导致的并发安全问题。
+// 处理链在独立的 goroutine 中执行，响应先写入有大小上限的缓冲区，超时后客户端不会收到
+// 部分响应体与超时响应混杂的内容。
 //
 // 超时上下文会传播到下游的 DB 查询、HTTP 调用等 context-aware 操作，
 // 处理函数应通过 c.Request.Context() 获取上下文并传递给 I/O 操作，以实现超时自动中断。
 //
 // 执行流程：
 // 1. 从配置中获取 API 超时时间，若为 0 则跳过超时控制
-// 2. 通过 context.WithTimeout 为请求设置截止时间
-// 3. 同步调用 c.Next() 执行后续处理链
-// 4. 检查上下文是否超时，未写入响应时返回 408
-// 5. 记录响应时长，对接近超时的请求发出警告
+// 2. 通过 context.WithTimeout 为请求设置截止时间，将 c.Writer 替换为缓冲响应的 timeoutWriter
+// 3. 在独立的 goroutine 中调用 c.Next() 执行后续处理链
+// 4. 处理链先完成时，将缓冲的状态码、响应头和响应体一次性写出；处理链中的 panic 在当前 goroutine 中重新抛出
+// 5. 先超时时输出 response.ResponseTimeout 统一响应并取消上下文，之后处理函数的写入被丢弃；
+// 响应已改为直接写出（超过 service.apiTimeoutMaxBufferBytes 或调用了 Flush / Hijack）时只记录警告
+// 6. 等待处理链退出后返回，避免 gin.Context 被回收后仍被处理函数使用
+// 7. 记录响应时长，对接近超时的请求发出警告
 //
 // 返回：
 //   - gin.HandlerFunc: Gin中间件函数
 func TimeoutHandler() gin.HandlerFunc {
 	timeout := time.Duration(app.BaseConfig.Service.ApiTimeout) * time.Second
+	maxBufferBytes := app.BaseConfig.Service.GetApiTimeoutMaxBufferBytes()
 
 	return func(c *gin.Context) {
 		// 1. 超时时间无效时跳过超时控制，直接执行后续处理链
@@ -39,33 +50,298 @@ func TimeoutHandler() gin.HandlerFunc {
 			return
 		}
 
-		// 2. 通过 context.WithTimeout 为请求设置截止时间
+		// 2. 通过 context.WithTimeout 为请求设置截止时间，替换响应写入器
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 		defer cancel()
 		c.Request = c.Request.WithContext(ctx)
+		path := c.Request.URL.Path
+		// 超时响应在处理链运行期间输出，此时不能读取 gin.Context，提前生成响应体
+		timeoutStatus, timeoutBody := timeoutResponse(c)
+
+		original := c.Writer
+		tw := newTimeoutWriter(original, maxBufferBytes)
+		c.Writer = tw
 
 		startTime := time.Now()
 
-		// 3. 同步调用 c.Next()，在同一 goroutine 中执行，避免并发访问 gin.Context
-		c.Next()
+		// 3. 在独立的 goroutine 中执行处理链，recover 的 panic 交给当前 goroutine 重新抛出
+		done := make(chan any, 1)
+		go func() {
+			defer func() { done <- recover() }()
+			c.Next()
+		}()
 
-		duration := time.Since(startTime)
+		var panicValue any
+		timedOut := false
+		select {
+		case panicValue = <-done:
+		case <-ctx.Done():
+			// 5. 截止时间已到（客户端断开时 ctx 也会结束，此时不输出超时响应）
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				if tw.timeout() {
+					timedOut = true
+					logger.Error("[timeout] Request to %s timeout (%v)", path, timeout)
+					tw.writeTimeoutResponse(timeoutStatus, timeoutBody)
+				} else {
+					logger.Warn("[timeout] Request to %s timeout (%v), but response is being written directly, timeout response skipped", path, timeout)
+				}
+			}
+			// 6. 等待处理链退出，gin.Context 在中间件返回后会被回收复用
+			panicValue = <-done
+		}
+		c.Writer = original
 
-		// 4. 检查上下文是否超时
-		if ctx.Err() == context.DeadlineExceeded {
-			if !c.Writer.Written() {
-				c.Abort()
-				logger.Error("[timeout] Request to %s timeout (%v)", c.Request.URL.Path, timeout)
-				response.Result(c, http.StatusRequestTimeout, nil, "Request timed out")
-			} else {
-				logger.Error("[timeout] Request to %s timeout (%v), but response already written", c.Request.URL.Path, timeout)
+		if panicValue != nil {
+			if timedOut {
+				logger.Error("[timeout] Request to %s panicked after timeout: %v", path, panicValue)
+				return
 			}
+			// 丢弃缓冲的响应，由上层的异常处理中间件输出错误响应
+			panic(panicValue)
+		}
+		if timedOut {
+			c.Abort()
 			return
 		}
+		// 4. 处理链先完成，写出缓冲的响应
+		tw.finish()
 
-		// 5. 响应时长超过 80% 的超时时间，记录警告日志
+		// 7. 响应时长超过 80% 的超时时间，记录警告日志
+		duration := time.Since(startTime)
 		if duration > timeout*8/10 {
-			logger.Warn("[timeout] Request to %s took %d ms, which is more than 80%% of the timeout (%v)", c.Request.URL.Path, duration.Milliseconds(), timeout)
+			logger.Warn("[timeout] Request to %s took %d ms, which is more than 80%% of the timeout (%v)", path, duration.Milliseconds(), timeout)
+		}
+	}
+}
+
+// timeoutResponse 按统一的 HTTP 状态码规则生成超时响应的状态码和响应体
+func timeoutResponse(c *gin.Context) (int, []byte) {
+	status, resp := response.NewError(c, response.ResponseTimeout)
+	body, _ := json.Marshal(resp)
+	return status, body
+}
+
+// timeoutWriter 的状态
+const (
+	timeoutWriterBuffering   = iota // 缓冲处理函数的响应
+	timeoutWriterPassthrough        // 超过缓冲上限或调用了 Flush / Hijack，直接写入原始响应
+	timeoutWriterTimedOut           // 已输出超时响应，之后的写入被丢弃
+	timeoutWriterDone               // 处理链已完成，缓冲的响应已写出
+)
+
+// timeoutWriter 有大小上限的响应缓冲区
+// 处理链完成前响应头、状态码和响应体只写入缓冲区，完成后一次性写出；响应体按原样缓冲，
+// 处理函数设置的 Content-Encoding、Content-Length 与缓冲的压缩内容保持一致。
+// 状态由互斥锁保护，超时后处理函数所在的 goroutine 继续写入不会 panic，写入内容被丢弃
+type timeoutWriter struct {
+	gin.ResponseWriter // 原始响应写入器
+
+	mu       sync.Mutex
+	state    int
+	maxBytes int64
+	header   http.Header
+	status   int
+	written  bool
+	buf      bytes.Buffer
+}
+
+// newTimeoutWriter 创建响应缓冲区，响应头从原始响应复制，保留前序中间件设置的响应头
+func newTimeoutWriter(w gin.ResponseWriter, maxBytes int64) *timeoutWriter {
+	return &timeoutWriter{ResponseWriter: w, maxBytes: maxBytes, header: w.Header().Clone()}
+}
+
+// Header 返回响应头，直接写出后返回原始响应的响应头
+func (w *timeoutWriter) Header() http.Header {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.state == timeoutWriterPassthrough {
+		return w.ResponseWriter.Header()
+	}
+	return w.header
+}
+
+// WriteHeader 记录状态码，与 gin 一致在写入响应体或调用 WriteHeaderNow 时才生效
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch w.state {
+	case timeoutWriterBuffering:
+		if code > 0 && !w.written {
+			w.status = code
+		}
+	case timeoutWriterPassthrough:
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+// WriteHeaderNow 标记响应头已写入
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch w.state {
+	case timeoutWriterBuffering:
+		w.written = true
+	case timeoutWriterPassthrough:
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Write 写入响应体，超过缓冲上限时改为直接写出；超时后返回 http.ErrHandlerTimeout
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch w.state {
+	case timeoutWriterBuffering:
+		w.written = true
+		if int64(w.buf.Len()+len(data)) <= w.maxBytes {
+			return w.buf.Write(data)
+		}
+		if err := w.passthrough(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(data)
+	case timeoutWriterPassthrough:
+		return w.ResponseWriter.Write(data)
+	default:
+		return 0, http.ErrHandlerTimeout
+	}
+}
+
+// WriteString 写入字符串响应体
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Status 返回状态码
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.state == timeoutWriterPassthrough {
+		return w.ResponseWriter.Status()
+	}
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Size 返回已写入的响应体字节数，未写入响应头时为 -1
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.state == timeoutWriterPassthrough {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.buf.Len()
+}
+
+// Written 是否已写入响应头
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.state == timeoutWriterPassthrough {
+		return w.ResponseWriter.Written()
+	}
+	return w.written
+}
+
+// Flush 处理函数需要流式输出，写出已缓冲的内容并改为直接写出
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.state == timeoutWriterBuffering {
+		if err := w.passthrough(); err != nil {
+			return
 		}
 	}
+	if w.state == timeoutWriterPassthrough {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// Hijack 接管连接（如 WebSocket 升级），改为直接写出后交给原始响应处理
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.state == timeoutWriterBuffering {
+		w.state = timeoutWriterPassthrough
+		copyHeader(w.ResponseWriter.Header(), w.header)
+	}
+	if w.state != timeoutWriterPassthrough {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	return w.ResponseWriter.Hijack()
+}
+
+// passthrough 写出已缓冲的响应头和响应体，之后的写入直接写入原始响应，调用方需持有锁
+func (w *timeoutWriter) passthrough() error {
+	w.state = timeoutWriterPassthrough
+	return w.flushBuffer()
+}
+
+// flushBuffer 将缓冲的响应头、状态码和响应体写入原始响应，调用方需持有锁
+func (w *timeoutWriter) flushBuffer() error {
+	copyHeader(w.ResponseWriter.Header(), w.header)
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if !w.written {
+		return nil
+	}
+	if w.buf.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// timeout 截止时间已到，仍在缓冲时切换为超时状态并返回 true；已直接写出时返回 false
+func (w *timeoutWriter) timeout() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.state != timeoutWriterBuffering {
+		return false
+	}
+	w.state = timeoutWriterTimedOut
+	w.buf.Reset()
+	return true
+}
+
+// writeTimeoutResponse 向原始响应写出超时响应并立即刷新
+// 中间件在处理链退出前不会返回，设置 Content-Length 使客户端收到完整响应后即可结束读取
+func (w *timeoutWriter) writeTimeoutResponse(status int, body []byte) {
+	h := w.ResponseWriter.Header()
+	h.Set("Content-Type", "application/json; charset=utf-8")
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(status)
+	_, _ = w.ResponseWriter.Write(body)
+	w.ResponseWriter.Flush()
+}
+
+// finish 处理链已完成，在锁内一次性写出缓冲的响应
+func (w *timeoutWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.state != timeoutWriterBuffering {
+		return
+	}
+	w.state = timeoutWriterDone
+	_ = w.flushBuffer()
+}
+
+// copyHeader 用 src 替换 dst 中的全部响应头
+func copyHeader(dst, src http.Header) {
+	for k := range dst {
+		if _, ok := src[k]; !ok {
+			delete(dst, k)
+		}
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
 }
@@ -12,8 +12,12 @@
 // 6. 并发请求的独立超时处理
 // 7. 零超时配置跳过超时控制
 // 8. 超时上下文传播验证
+// 9. 超时前已缓冲部分响应时只输出超时响应
+// 10. 处理器先完成时完整写出状态码、响应头和响应体
+// 11. 调用 Flush 或超过缓冲上限后改为直接写出，超时不再覆盖响应
+// 12. 按响应码输出 HTTP 状态码时超时返回 408
 //
-// 9. 高并发压力测试（100 goroutine 混合场景）
+// 13. 高并发压力测试（100 goroutine 混合场景）
 //
 // 运行测试：go test -v ./middleware/... -run TimeoutHandler
 // ==================================================
@@ -23,6 +27,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -30,6 +35,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/zzsen/gin_core/app"
 	"github.com/zzsen/gin_core/model/config"
+	"github.com/zzsen/gin_core/model/response"
 )
 
 // ==================== 测试辅助函数 ====================
@@ -125,19 +131,19 @@ func TestTimeoutHandler_CooperativeTimeout(t *testing.T) {
 	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 		t.Errorf("解析响应失败: %v", err)
 	}
-	if resp["msg"] != "Request timed out" {
+	if resp["msg"] != response.ResponseTimeout.GetMsg() {
 		t.Errorf("期望超时响应消息，实际 %v", resp["msg"])
 	}
 }
 
 // TestTimeoutHandler_NonCooperativeTimeout 测试非协作式超时（处理器忽略 context）
 //
-// 【功能点】验证不检查 context 的处理器在超时后仍能正常执行完毕，
-// 由于响应已写入，中间件仅记录超时日志而不覆盖响应
+// 【功能点】验证不检查 context 的处理器超时后，客户端收到超时响应，
+// 处理器在超时后的写入被丢弃且不会 panic，中间件等待处理器执行完毕后返回
 // 【测试流程】
 // 1. 设置 1 秒超时
-// 2. 处理器使用 time.Sleep 阻塞（不监听 context）
-// 3. 验证处理器的响应被保留（200），但总耗时超过超时时间
+// 2. 处理器使用 time.Sleep 阻塞（不监听 context），之后写入响应
+// 3. 验证响应为超时响应，不包含处理器的响应内容，总耗时不少于处理器的执行时间
 func TestTimeoutHandler_NonCooperativeTimeout(t *testing.T) {
 	cleanup := setupTimeoutTestConfig(1) // 1 秒超时
 	defer cleanup()
@@ -155,12 +161,19 @@ func TestTimeoutHandler_NonCooperativeTimeout(t *testing.T) {
 	router.ServeHTTP(w, req)
 	duration := time.Since(start)
 
-	if duration < 1*time.Second {
+	if duration < 1500*time.Millisecond {
 		t.Errorf("非协作式处理器应阻塞至完成，实际耗时 %v", duration)
 	}
 
 	if w.Code != http.StatusOK {
-		t.Errorf("处理器已写入响应，期望状态码 200, 实际 %d", w.Code)
+		t.Errorf("期望状态码 200, 实际 %d", w.Code)
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("超时后的写入应被丢弃，响应应为完整的超时响应: %v, body: %s", err, w.Body.String())
+	}
+	if resp["code"] != float64(response.ResponseTimeout.GetCode()) || resp["msg"] != response.ResponseTimeout.GetMsg() {
+		t.Errorf("期望超时响应，实际 %v", resp)
 	}
 }
 
@@ -300,7 +313,7 @@ func TestTimeoutHandler_MultipleRequests(t *testing.T) {
 				t.Errorf("快速请求期望 message=fast, 实际 %s", r.msg)
 			}
 		case "/slow":
-			if r.msg != "Request timed out" {
+			if r.msg != response.ResponseTimeout.GetMsg() {
 				t.Errorf("慢速请求期望超时消息，实际 %s", r.msg)
 			}
 		}
@@ -394,6 +407,163 @@ func TestTimeoutHandler_ContextPropagation(t *testing.T) {
 	}
 }
 
+// TestTimeoutHandler_TimeoutAfterPartialWrite 测试超时前已缓冲部分响应
+//
+// 【功能点】验证超时前写入的部分响应体留在缓冲区中，客户端只收到超时响应
+// 【测试流程】
+// 1. 处理器写入部分响应体后等待 context 取消
+// 2. 验证响应体为完整的超时响应，不包含部分响应体
+func TestTimeoutHandler_TimeoutAfterPartialWrite(t *testing.T) {
+	cleanup := setupTimeoutTestConfig(1) // 1 秒超时
+	defer cleanup()
+
+	router := createTimeoutTestRouter(TimeoutHandler())
+	router.GET("/partial", func(c *gin.Context) {
+		c.Header("X-Partial", "1")
+		c.Writer.WriteString(`{"items":[`)
+		<-c.Request.Context().Done()
+		c.Writer.WriteString(`]}`)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/partial", nil)
+	router.ServeHTTP(w, req)
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("响应应为完整的超时响应: %v, body: %s", err, w.Body.String())
+	}
+	if resp["code"] != float64(response.ResponseTimeout.GetCode()) {
+		t.Errorf("期望超时响应码 %d, 实际 %v", response.ResponseTimeout.GetCode(), resp["code"])
+	}
+	if w.Header().Get("X-Partial") != "" {
+		t.Error("超时响应不应包含处理器缓冲的响应头")
+	}
+}
+
+// TestTimeoutHandler_CompleteBeforeTimeout 测试处理器先完成
+//
+// 【功能点】验证处理器先完成时，缓冲的状态码、响应头和响应体完整写出，前序中间件设置的响应头保留
+// 【测试流程】
+// 1. 前序中间件设置响应头，处理器设置响应头并返回 201
+// 2. 验证状态码、两个响应头和响应体
+func TestTimeoutHandler_CompleteBeforeTimeout(t *testing.T) {
+	cleanup := setupTimeoutTestConfig(5) // 5 秒超时
+	defer cleanup()
+
+	router := createTimeoutTestRouter(func(c *gin.Context) {
+		c.Header("X-Request-Id", "req-1")
+		c.Next()
+	})
+	router.Use(TimeoutHandler())
+	router.POST("/create", func(c *gin.Context) {
+		c.Header("Content-Encoding", "identity")
+		c.String(http.StatusCreated, "created")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/create", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("期望状态码 201, 实际 %d", w.Code)
+	}
+	if w.Header().Get("X-Request-Id") != "req-1" || w.Header().Get("Content-Encoding") != "identity" {
+		t.Errorf("响应头未完整写出: %v", w.Header())
+	}
+	if w.Body.String() != "created" {
+		t.Errorf("期望响应体 created, 实际 %s", w.Body.String())
+	}
+}
+
+// TestTimeoutHandler_StreamingPassthrough 测试流式输出
+//
+// 【功能点】验证处理器调用 Flush 后改为直接写出，超时后不输出超时响应，超时后的写入仍写出
+// 【测试流程】
+// 1. 处理器写入第一段数据并 Flush，等待 context 取消后写入第二段数据
+// 2. 验证响应体为两段数据，不包含超时响应
+func TestTimeoutHandler_StreamingPassthrough(t *testing.T) {
+	cleanup := setupTimeoutTestConfig(1) // 1 秒超时
+	defer cleanup()
+
+	router := createTimeoutTestRouter(TimeoutHandler())
+	router.GET("/stream", func(c *gin.Context) {
+		c.Writer.WriteString("data: 1\n\n")
+		c.Writer.Flush()
+		<-c.Request.Context().Done()
+		c.Writer.WriteString("data: 2\n\n")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/stream", nil)
+	router.ServeHTTP(w, req)
+
+	if !w.Flushed {
+		t.Error("调用 Flush 后应刷新原始响应")
+	}
+	if w.Body.String() != "data: 1\n\ndata: 2\n\n" {
+		t.Errorf("流式输出不应被超时响应覆盖，实际 %q", w.Body.String())
+	}
+}
+
+// TestTimeoutHandler_BufferOverflow 测试响应超过缓冲上限
+//
+// 【功能点】验证响应体超过 apiTimeoutMaxBufferBytes 时改为直接写出，超时后不输出超时响应
+// 【测试流程】
+// 1. 缓冲上限设为 8 字节，处理器写入 4 字节和 12 字节后等待 context 取消
+// 2. 验证响应体为完整的 16 字节，不包含超时响应
+func TestTimeoutHandler_BufferOverflow(t *testing.T) {
+	cleanup := setupTimeoutTestConfig(1) // 1 秒超时
+	defer cleanup()
+	app.BaseConfig.Service.ApiTimeoutMaxBufferBytes = 8
+
+	router := createTimeoutTestRouter(TimeoutHandler())
+	router.GET("/large", func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+		c.Writer.WriteString("head")
+		c.Writer.WriteString("-large-body")
+		<-c.Request.Context().Done()
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/large", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("期望状态码 202, 实际 %d", w.Code)
+	}
+	if w.Body.String() != "head-large-body" {
+		t.Errorf("超过缓冲上限后应直接写出，实际 %q", w.Body.String())
+	}
+}
+
+// TestTimeoutHandler_HTTPStatus 测试按响应码输出 HTTP 状态码
+//
+// 【功能点】验证开启 useHTTPStatus 时超时响应的 HTTP 状态码为 408
+// 【测试流程】开启 useHTTPStatus，处理器等待 context 取消，验证状态码和 Content-Length
+func TestTimeoutHandler_HTTPStatus(t *testing.T) {
+	cleanup := setupTimeoutTestConfig(1) // 1 秒超时
+	defer cleanup()
+	response.SetUseHTTPStatus(true)
+	defer response.SetUseHTTPStatus(false)
+
+	router := createTimeoutTestRouter(TimeoutHandler())
+	router.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/slow", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestTimeout {
+		t.Errorf("期望状态码 408, 实际 %d", w.Code)
+	}
+	if w.Header().Get("Content-Length") != strconv.Itoa(w.Body.Len()) {
+		t.Errorf("超时响应应设置 Content-Length, 实际 %q", w.Header().Get("Content-Length"))
+	}
+}
+
 // ==================== 并发安全测试 ====================
 
 // TestTimeoutHandler_ConcurrentStress 高并发压力测试
@@ -488,7 +658,7 @@ func TestTimeoutHandler_ConcurrentStress(t *testing.T) {
 				mediumOk++
 			}
 		case "/slow":
-			if r.msg == "Request timed out" {
+			if r.msg == response.ResponseTimeout.GetMsg() {
 				slowTimeout++
 			}
 		}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
 )
 
 // traceHeaders 定义了从上游请求中读取 trace ID 时依次检查的请求头，按优先级排序
@@ -33,8 +34,8 @@ func TraceIdHandler() gin.HandlerFunc {
 			traceID = uuid.New().String()
 		}
 
-		// 3. 将 Trace ID 存储在 gin.Context 中，供后续中间件和处理器访问
-		c.Set("traceId", traceID)
+		// 3. 将 Trace ID 存入 RequestContext（同时写入旧版 "traceId" 键），供后续中间件和处理器访问
+		ginContext.SetTraceID(c, traceID)
 
 		// 4. 将 Trace ID 添加到响应头中，方便客户端跟踪和调试
 		c.Writer.Header().Set("X-Trace-ID", traceID)
@@ -3,12 +3,14 @@
 package middleware
 
 import (
-	"encoding/json"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/zzsen/gin_core/logger"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+	"github.com/zzsen/gin_core/utils/jsoncodec"
+	"github.com/zzsen/gin_core/utils/netutil"
 )
 
 // maskToken 对 token 进行脱敏处理，保留前 3 位和后 3 位，中间用 *** 替代。
@@ -33,6 +35,8 @@ func maskToken(token string) string {
 // 6. 获取追踪ID和请求ID
 // 7. 收集错误信息
 // 8. 使用结构化日志记录所有信息
+// 请求处理前会将绑定了 traceId、requestId 的子日志记录器存入请求 context，
+// 处理器中通过 logger.FromContext(c.Request.Context()) 获取，输出的日志自动附带这两个字段
 // 返回：
 //   - gin.HandlerFunc: Gin中间件函数
 func TraceLogHandler() gin.HandlerFunc {
@@ -40,6 +44,15 @@ func TraceLogHandler() gin.HandlerFunc {
 		// 记录请求开始时间，用于计算响应时长
 		startTime := time.Now()
 
+		// 获取请求中的 requestId 和 traceId（分别用于关联同一请求的不同操作和分布式追踪），绑定到请求级子日志记录器
+		requestId := ginContext.GetRequestID(c)
+		traceId := ginContext.GetTraceID(c)
+		reqLogger := logger.With(map[string]any{
+			"traceId":   traceId,
+			"requestId": requestId,
+		})
+		c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), reqLogger))
+
 		// 执行请求处理流程，调用后续的中间件和处理器
 		c.Next()
 
@@ -53,7 +66,7 @@ func TraceLogHandler() gin.HandlerFunc {
 		reqMethod := c.Request.Method                                     // 请求方式（GET、POST等）
 		reqUrl := c.Request.RequestURI                                    // 请求路由路径
 		statusCode := c.Writer.Status()                                   // HTTP响应状态码
-		clientIP := c.ClientIP()                                          // 客户端IP地址
+		clientIP := netutil.ClientIP(c)                                   // 客户端IP地址
 		header := c.GetHeader("User-Agent") + "@@" + maskToken(c.GetHeader("token")) // 用户代理和脱敏后的认证令牌
 
 		// 解析多部分表单数据，限制内存使用为128MB
@@ -63,29 +76,18 @@ func TraceLogHandler() gin.HandlerFunc {
 
 		// 将请求表单数据转换为 JSON 字符串，便于日志记录和分析
 		if len(reqForm) > 0 {
-			reqJsonByte, _ := json.Marshal(reqForm)
+			reqJsonByte, _ := jsoncodec.Marshal(reqForm)
 			reqJsonStr = string(reqJsonByte)
 		}
 
-		// 获取请求中的 requestId，用于关联同一请求的不同操作
-		requestId := c.GetString("requestId")
-
-		// 获取请求中的 traceId，用于分布式追踪
-		traceId, exists := c.Get("traceId")
-		if !exists {
-			traceId = ""
-		}
-
 		// 获取 Gin 中间件中的错误信息，收集所有中间件产生的错误
 		var errorsStr string
 		for _, err := range c.Errors.Errors() {
 			errorsStr += err + "; "
 		}
 
-		// 使用结构化日志记录所有请求信息，便于日志分析和问题排查
-		logger.TraceWithFields(map[string]any{
-			"traceId":      traceId,      // 追踪ID，用于分布式追踪
-			"requestId":    requestId,    // 请求ID，用于关联同一请求的不同操作
+		// 使用结构化日志记录所有请求信息（traceId、requestId 由子日志记录器附带），便于日志分析和问题排查
+		reqLogger.With(map[string]any{
 			"statusCode":   statusCode,   // HTTP状态码，用于判断请求处理结果
 			"responseTime": responseTime, // 响应时间，用于性能监控
 			"clientIp":     clientIP,     // 客户端IP，用于访问控制和问题排查
@@ -94,6 +96,6 @@ func TraceLogHandler() gin.HandlerFunc {
 			"reqUri":       reqUrl,       // 请求URI，用于路由分析
 			"body":         reqJsonStr,   // 请求体数据，用于调试和审计
 			"errStr":       errorsStr,    // 错误信息，用于问题排查
-		}, "请求日志")
+		}).Trace("请求日志")
 	}
 }
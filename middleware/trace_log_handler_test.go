@@ -11,7 +11,8 @@
 // 5. 响应时间的计算
 // 6. TraceID 和 RequestID 的获取
 // 7. 错误信息的收集
-// 8. maskToken 脱敏函数（空字符串、短 token、边界值、长 token、日志无泄露）
+// 8. 请求 context 中的子日志记录器绑定 traceId、requestId
+// 9. maskToken 脱敏函数（空字符串、短 token、边界值、长 token、日志无泄露）
 //
 // 运行测试：go test -v ./middleware/... -run "TraceLogHandler|MaskToken"
 // ==================================================
@@ -26,6 +27,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/logger"
 )
 
 // ==================== TraceLogHandler 单元测试 ====================
@@ -375,6 +377,39 @@ func TestTraceLogHandler_MultipleRequests(t *testing.T) {
 	}
 }
 
+// TestTraceLogHandler_RequestLogger 测试请求级子日志记录器
+//
+// 【功能点】验证处理器可从请求 context 中获取绑定了当前请求 traceId、requestId 的子日志记录器，请求之间互不影响
+// 【测试流程】
+//  1. 前置中间件按请求头设置 traceId 和 requestId
+//  2. 处理器通过 logger.FromContext(c.Request.Context()) 获取记录器并返回其绑定的字段
+//  3. 发送两个不同 traceId 的请求，断言各自返回的字段与请求一致
+func TestTraceLogHandler_RequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("traceId", c.GetHeader("X-Trace-ID"))
+		c.Set("requestId", "req-"+c.GetHeader("X-Trace-ID"))
+		c.Next()
+	})
+	router.Use(TraceLogHandler())
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, logger.FromContext(c.Request.Context()).Fields())
+	})
+
+	for _, traceID := range []string{"trace-a", "trace-b"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/test", nil)
+		req.Header.Set("X-Trace-ID", traceID)
+		router.ServeHTTP(w, req)
+
+		expected := `{"requestId":"req-` + traceID + `","traceId":"` + traceID + `"}`
+		if w.Body.String() != expected {
+			t.Errorf("期望字段 %s, 实际 %s", expected, w.Body.String())
+		}
+	}
+}
+
 // ==================== 基准测试 ====================
 
 // BenchmarkTraceLogHandler 基准测试 TraceLog 中间件性能
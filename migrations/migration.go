@@ -0,0 +1,68 @@
+// Package migrations 提供按版本管理的数据库迁移功能
+//
+// 迁移在启动前通过 Register（或 core.RegisterMigration）注册，按注册顺序执行，
+// 执行记录保存在 schema_migrations 表中，已执行的迁移不会重复执行。
+// 支持 DDL 事务的数据库（如 SQLite、PostgreSQL）在同一事务中执行迁移并写入记录；
+// MySQL 的 DDL 会隐式提交事务，迁移开始前先写入未完成（dirty）记录，执行成功后再标记为完成，
+// 执行失败时保留未完成记录，需人工确认数据库状态并删除记录后才能继续执行迁移。
+package migrations
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TableName 迁移记录表名
+const TableName = "schema_migrations"
+
+// Func 迁移函数，在支持 DDL 事务的数据库上 db 为事务
+type Func func(db *gorm.DB) error
+
+// Migration 数据库迁移
+type Migration struct {
+	// ID 迁移标识，全局唯一，建议使用 "20240101_create_users" 形式，便于识别执行顺序
+	ID string
+	// Up 执行迁移
+	Up Func
+	// Down 回滚迁移，为 nil 时该迁移不能回滚
+	Down Func
+}
+
+// Record 迁移执行记录
+type Record struct {
+	ID        string    `gorm:"primaryKey;size:255"`
+	Dirty     bool      `gorm:"not null;default:false"` // 是否未执行完成，仅在不支持 DDL 事务的数据库上出现
+	AppliedAt time.Time `gorm:"not null"`               // 执行时间
+}
+
+// TableName 指定 GORM 表名
+func (Record) TableName() string {
+	return TableName
+}
+
+var (
+	mu         sync.Mutex
+	registered []Migration
+)
+
+// Register 注册数据库迁移
+// 应在 Start 之前调用（如 main 函数或 init 函数中），迁移按注册顺序执行。
+// 重复的 ID 不在注册时报错，而是在执行迁移时报告
+// 参数：
+//   - id: 迁移标识，全局唯一
+//   - up: 执行迁移的函数
+//   - down: 回滚迁移的函数，为 nil 时该迁移不能回滚
+func Register(id string, up, down Func) {
+	mu.Lock()
+	defer mu.Unlock()
+	registered = append(registered, Migration{ID: id, Up: up, Down: down})
+}
+
+// Registered 获取已注册的迁移，按注册顺序返回
+func Registered() []Migration {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Migration(nil), registered...)
+}
@@ -0,0 +1,212 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zzsen/gin_core/logger"
+	"gorm.io/gorm"
+)
+
+// ErrDirty 存在未执行完成的迁移
+var ErrDirty = errors.New("存在未执行完成的迁移")
+
+// Runner 迁移执行器
+type Runner struct {
+	db            *gorm.DB
+	migrations    []Migration
+	transactional bool // 是否在事务中执行迁移
+}
+
+// NewRunner 创建迁移执行器
+// 参数：
+//   - db: 数据库连接
+//   - migrations: 按执行顺序排列的迁移
+//
+// 返回：
+//   - *Runner: 迁移执行器
+//   - error: 迁移 ID 为空或重复、Up 为 nil 时返回错误
+func NewRunner(db *gorm.DB, migrations []Migration) (*Runner, error) {
+	seen := make(map[string]struct{}, len(migrations))
+	for _, m := range migrations {
+		if m.ID == "" {
+			return nil, fmt.Errorf("迁移 ID 不能为空")
+		}
+		if _, ok := seen[m.ID]; ok {
+			return nil, fmt.Errorf("迁移 ID 重复: %s", m.ID)
+		}
+		if m.Up == nil {
+			return nil, fmt.Errorf("迁移 %s 未设置 Up 函数", m.ID)
+		}
+		seen[m.ID] = struct{}{}
+	}
+	return &Runner{
+		db:            db,
+		migrations:    migrations,
+		transactional: supportsTransactionalDDL(db),
+	}, nil
+}
+
+// supportsTransactionalDDL 判断数据库是否支持在事务中执行 DDL，MySQL 的 DDL 会隐式提交事务
+func supportsTransactionalDDL(db *gorm.DB) bool {
+	return db.Dialector.Name() != "mysql"
+}
+
+// Pending 获取待执行的迁移 ID，按注册顺序返回
+// 返回：
+//   - []string: 待执行的迁移 ID
+//   - error: 读取迁移记录失败、存在未完成的迁移或已执行的迁移未注册时返回错误
+func (r *Runner) Pending() ([]string, error) {
+	applied, err := r.loadApplied()
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, m := range r.migrations {
+		if _, ok := applied[m.ID]; !ok {
+			pending = append(pending, m.ID)
+		}
+	}
+	return pending, nil
+}
+
+// Up 按注册顺序执行所有待执行的迁移
+// 某个迁移失败时停止执行，已成功的迁移保留
+// 返回：
+//   - []string: 本次执行成功的迁移 ID
+//   - error: 迁移记录校验失败或执行迁移失败时返回错误
+func (r *Runner) Up() ([]string, error) {
+	applied, err := r.loadApplied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []string
+	for _, m := range r.migrations {
+		if _, ok := applied[m.ID]; ok {
+			continue
+		}
+		start := time.Now()
+		if err := r.apply(m); err != nil {
+			logger.Error("[数据库迁移] 执行迁移 %s 失败: %v", m.ID, err)
+			return done, fmt.Errorf("执行迁移 %s 失败: %w", m.ID, err)
+		}
+		logger.Info("[数据库迁移] 已执行迁移 %s, 耗时: %v", m.ID, time.Since(start))
+		done = append(done, m.ID)
+	}
+	return done, nil
+}
+
+// Rollback 按注册顺序的逆序回滚最近 n 个已执行的迁移
+// 参数：
+//   - n: 回滚数量，超过已执行的数量时回滚全部
+//
+// 返回：
+//   - []string: 本次回滚成功的迁移 ID
+//   - error: 迁移记录校验失败、待回滚的迁移未设置 Down 函数或回滚失败时返回错误
+func (r *Runner) Rollback(n int) ([]string, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("回滚数量必须大于 0: %d", n)
+	}
+	applied, err := r.loadApplied()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []Migration
+	for i := len(r.migrations) - 1; i >= 0 && len(targets) < n; i-- {
+		if _, ok := applied[r.migrations[i].ID]; ok {
+			targets = append(targets, r.migrations[i])
+		}
+	}
+	for _, m := range targets {
+		if m.Down == nil {
+			return nil, fmt.Errorf("迁移 %s 未设置 Down 函数, 不能回滚", m.ID)
+		}
+	}
+
+	var done []string
+	for _, m := range targets {
+		if err := r.revert(m); err != nil {
+			logger.Error("[数据库迁移] 回滚迁移 %s 失败: %v", m.ID, err)
+			return done, fmt.Errorf("回滚迁移 %s 失败: %w", m.ID, err)
+		}
+		logger.Info("[数据库迁移] 已回滚迁移 %s", m.ID)
+		done = append(done, m.ID)
+	}
+	return done, nil
+}
+
+// loadApplied 创建迁移记录表并读取已执行的迁移
+// 存在未完成的记录，或已执行的迁移不在注册列表中（迁移被删除或改名）时返回错误
+func (r *Runner) loadApplied() (map[string]Record, error) {
+	if err := r.db.AutoMigrate(&Record{}); err != nil {
+		return nil, fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+	var records []Record
+	if err := r.db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+
+	registered := make(map[string]struct{}, len(r.migrations))
+	for _, m := range r.migrations {
+		registered[m.ID] = struct{}{}
+	}
+	applied := make(map[string]Record, len(records))
+	var unknown []string
+	for _, record := range records {
+		if record.Dirty {
+			return nil, fmt.Errorf("%w: %s, 请确认数据库状态后删除 %s 表中的该记录", ErrDirty, record.ID, TableName)
+		}
+		if _, ok := registered[record.ID]; !ok {
+			unknown = append(unknown, record.ID)
+		}
+		applied[record.ID] = record
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("已执行的迁移未注册: %v", unknown)
+	}
+	return applied, nil
+}
+
+// apply 执行单个迁移并写入记录
+func (r *Runner) apply(m Migration) error {
+	if r.transactional {
+		return r.db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&Record{ID: m.ID, AppliedAt: time.Now()}).Error
+		})
+	}
+
+	// DDL 无法回滚：先写入未完成记录，执行成功后再标记为完成，失败时保留记录供人工处理
+	if err := r.db.Create(&Record{ID: m.ID, Dirty: true, AppliedAt: time.Now()}).Error; err != nil {
+		return fmt.Errorf("写入迁移记录失败: %w", err)
+	}
+	if err := m.Up(r.db); err != nil {
+		return err
+	}
+	return r.db.Model(&Record{ID: m.ID}).Updates(map[string]any{"dirty": false, "applied_at": time.Now()}).Error
+}
+
+// revert 回滚单个迁移并删除记录
+func (r *Runner) revert(m Migration) error {
+	if r.transactional {
+		return r.db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&Record{ID: m.ID}).Error
+		})
+	}
+
+	if err := r.db.Model(&Record{ID: m.ID}).Update("dirty", true).Error; err != nil {
+		return fmt.Errorf("更新迁移记录失败: %w", err)
+	}
+	if err := m.Down(r.db); err != nil {
+		return err
+	}
+	return r.db.Delete(&Record{ID: m.ID}).Error
+}
@@ -0,0 +1,231 @@
+// Package migrations 数据库迁移测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含迁移执行器的单元测试，使用 SQLite 内存数据库，不需要 MySQL。
+//
+// 测试覆盖内容：
+// 1. 按注册顺序执行迁移并写入记录，重复执行时不再执行已执行的迁移
+// 2. 按逆序回滚最近 N 个迁移，未设置 Down 函数时拒绝回滚
+// 3. 迁移中途失败时，事务模式回滚该迁移且不写入记录，非事务模式保留未完成记录并阻止后续执行
+// 4. 迁移 ID 重复、已执行的迁移未注册时返回错误
+//
+// 运行测试：go test -v ./migrations/...
+// ==================================================
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// newTestDB 创建 SQLite 内存数据库
+func newTestDB(t *testing.T) *gorm.DB {
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db
+}
+
+// createTable 返回创建指定表的迁移，calls 记录执行次数
+func createTable(name string, calls *int) Migration {
+	return Migration{
+		ID: "create_" + name,
+		Up: func(db *gorm.DB) error {
+			*calls++
+			return db.Exec(fmt.Sprintf("CREATE TABLE %s (id INTEGER PRIMARY KEY)", name)).Error
+		},
+		Down: func(db *gorm.DB) error {
+			return db.Exec(fmt.Sprintf("DROP TABLE %s", name)).Error
+		},
+	}
+}
+
+// appliedIDs 读取迁移记录中的 ID
+func appliedIDs(t *testing.T, db *gorm.DB) []string {
+	var ids []string
+	require.NoError(t, db.Model(&Record{}).Order("id").Pluck("id", &ids).Error)
+	return ids
+}
+
+// TestRunner_UpIdempotent 测试执行迁移与重复执行
+//
+// 【功能点】验证按注册顺序执行迁移并写入记录，再次执行时不重复执行
+// 【测试流程】
+//  1. 注册两个建表迁移并执行，断言两张表存在、记录完整、Pending 为空
+//  2. 新建执行器再次执行，断言没有执行任何迁移
+func TestRunner_UpIdempotent(t *testing.T) {
+	db := newTestDB(t)
+	var usersCalls, ordersCalls int
+	ms := []Migration{createTable("users", &usersCalls), createTable("orders", &ordersCalls)}
+
+	runner, err := NewRunner(db, ms)
+	require.NoError(t, err)
+	done, err := runner.Up()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"create_users", "create_orders"}, done)
+	assert.True(t, db.Migrator().HasTable("users"))
+	assert.True(t, db.Migrator().HasTable("orders"))
+	assert.Equal(t, []string{"create_orders", "create_users"}, appliedIDs(t, db))
+
+	pending, err := runner.Pending()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	runner, err = NewRunner(db, ms)
+	require.NoError(t, err)
+	done, err = runner.Up()
+	require.NoError(t, err)
+	assert.Empty(t, done)
+	assert.Equal(t, 1, usersCalls)
+	assert.Equal(t, 1, ordersCalls)
+}
+
+// TestRunner_Rollback 测试回滚迁移
+//
+// 【功能点】验证按注册顺序的逆序回滚最近 N 个迁移并删除记录，未设置 Down 函数的迁移不能回滚
+// 【测试流程】
+//  1. 执行三个迁移后回滚 2 个，断言后两张表被删除、只剩第一条记录
+//  2. 再次执行迁移，断言被回滚的迁移重新执行
+//  3. 将最后一个迁移的 Down 置为 nil，断言回滚返回错误且不回滚任何迁移
+func TestRunner_Rollback(t *testing.T) {
+	db := newTestDB(t)
+	var calls int
+	ms := []Migration{createTable("a", &calls), createTable("b", &calls), createTable("c", &calls)}
+	runner, err := NewRunner(db, ms)
+	require.NoError(t, err)
+	_, err = runner.Up()
+	require.NoError(t, err)
+
+	done, err := runner.Rollback(2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"create_c", "create_b"}, done)
+	assert.True(t, db.Migrator().HasTable("a"))
+	assert.False(t, db.Migrator().HasTable("b"))
+	assert.False(t, db.Migrator().HasTable("c"))
+	assert.Equal(t, []string{"create_a"}, appliedIDs(t, db))
+
+	done, err = runner.Up()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"create_b", "create_c"}, done)
+
+	ms[2].Down = nil
+	runner, err = NewRunner(db, ms)
+	require.NoError(t, err)
+	_, err = runner.Rollback(1)
+	assert.ErrorContains(t, err, "迁移 create_c 未设置 Down 函数")
+	assert.Len(t, appliedIDs(t, db), 3)
+}
+
+// TestRunner_FailureTransactional 测试事务模式下迁移中途失败
+//
+// 【功能点】验证迁移失败时该迁移的修改和记录一起回滚，之前成功的迁移保留，修复后可以继续执行
+// 【测试流程】
+//  1. 第二个迁移建表后返回错误，断言返回错误、第一个迁移已执行、第二个迁移的表和记录都不存在
+//  2. 修复第二个迁移后再次执行，断言只执行第二个迁移
+func TestRunner_FailureTransactional(t *testing.T) {
+	db := newTestDB(t)
+	var calls int
+	failing := Migration{
+		ID: "create_b",
+		Up: func(db *gorm.DB) error {
+			if err := db.Exec("CREATE TABLE b (id INTEGER PRIMARY KEY)").Error; err != nil {
+				return err
+			}
+			return errors.New("boom")
+		},
+	}
+	runner, err := NewRunner(db, []Migration{createTable("a", &calls), failing})
+	require.NoError(t, err)
+	require.True(t, runner.transactional)
+
+	done, err := runner.Up()
+	assert.ErrorContains(t, err, "执行迁移 create_b 失败: boom")
+	assert.Equal(t, []string{"create_a"}, done)
+	assert.False(t, db.Migrator().HasTable("b"))
+	assert.Equal(t, []string{"create_a"}, appliedIDs(t, db))
+
+	runner, err = NewRunner(db, []Migration{createTable("a", &calls), createTable("b", &calls)})
+	require.NoError(t, err)
+	done, err = runner.Up()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"create_b"}, done)
+	assert.Equal(t, 2, calls)
+}
+
+// TestRunner_FailureNonTransactional 测试非事务模式下迁移中途失败
+//
+// 【功能点】验证不支持 DDL 事务时，失败的迁移保留未完成记录，后续执行返回 ErrDirty，删除记录后可以继续
+// 【测试流程】
+//  1. 关闭事务模式，第二个迁移返回错误，断言记录表中该迁移为未完成状态
+//  2. 再次执行，断言返回 ErrDirty 且不执行任何迁移
+//  3. 删除未完成记录后以修复后的迁移执行，断言执行成功
+func TestRunner_FailureNonTransactional(t *testing.T) {
+	db := newTestDB(t)
+	var calls int
+	failing := Migration{ID: "create_b", Up: func(db *gorm.DB) error { return errors.New("boom") }}
+	runner, err := NewRunner(db, []Migration{createTable("a", &calls), failing})
+	require.NoError(t, err)
+	runner.transactional = false
+
+	_, err = runner.Up()
+	assert.ErrorContains(t, err, "boom")
+	var failed, applied Record
+	require.NoError(t, db.First(&failed, "id = ?", "create_b").Error)
+	assert.True(t, failed.Dirty)
+	require.NoError(t, db.First(&applied, "id = ?", "create_a").Error)
+	assert.False(t, applied.Dirty)
+
+	_, err = runner.Up()
+	assert.ErrorIs(t, err, ErrDirty)
+	assert.Equal(t, 1, calls)
+
+	require.NoError(t, db.Delete(&Record{ID: "create_b"}).Error)
+	runner, err = NewRunner(db, []Migration{createTable("a", &calls), createTable("b", &calls)})
+	require.NoError(t, err)
+	runner.transactional = false
+	done, err := runner.Up()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"create_b"}, done)
+	assert.Equal(t, []string{"create_a", "create_b"}, appliedIDs(t, db))
+}
+
+// TestRunner_Validation 测试迁移校验
+//
+// 【功能点】验证迁移 ID 重复、Up 为空、已执行的迁移未注册时返回错误
+// 【测试流程】
+//  1. 以重复 ID 和缺少 Up 的迁移创建执行器，断言返回错误
+//  2. 执行迁移后移除其中一个迁移，断言执行、回滚和 Pending 都报告未注册的迁移
+func TestRunner_Validation(t *testing.T) {
+	db := newTestDB(t)
+	var calls int
+	_, err := NewRunner(db, []Migration{createTable("a", &calls), createTable("a", &calls)})
+	assert.ErrorContains(t, err, "迁移 ID 重复: create_a")
+	_, err = NewRunner(db, []Migration{{ID: "empty"}})
+	assert.ErrorContains(t, err, "迁移 empty 未设置 Up 函数")
+
+	runner, err := NewRunner(db, []Migration{createTable("a", &calls), createTable("b", &calls)})
+	require.NoError(t, err)
+	_, err = runner.Up()
+	require.NoError(t, err)
+
+	runner, err = NewRunner(db, []Migration{createTable("b", &calls), createTable("c", &calls)})
+	require.NoError(t, err)
+	_, err = runner.Up()
+	assert.ErrorContains(t, err, "已执行的迁移未注册: [create_a]")
+	_, err = runner.Rollback(1)
+	assert.ErrorContains(t, err, "已执行的迁移未注册")
+	_, err = runner.Pending()
+	assert.ErrorContains(t, err, "已执行的迁移未注册")
+	assert.False(t, db.Migrator().HasTable("c"))
+}
@@ -0,0 +1,74 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了 API Key 认证中间件的配置结构
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIKeyConfig API Key 认证配置
+// 用于 apiKeyHandler 中间件：服务间调用方通过请求头或查询参数携带 API Key 完成认证
+type APIKeyConfig struct {
+	// Enabled 是否启用 API Key 认证中间件
+	Enabled bool `yaml:"enabled"`
+	// HeaderName 携带 API Key 的请求头名称，默认 "X-API-Key"
+	HeaderName string `yaml:"headerName"`
+	// QueryParam 携带 API Key 的查询参数名称，为空时不从查询参数读取；请求头优先
+	QueryParam string `yaml:"queryParam"`
+	// Keys 允许访问的 API Key 列表
+	Keys []APIKeyEntry `yaml:"keys"`
+	// SkipPaths 不需要认证的路径，支持精确匹配、/* 后缀通配符和 path.Match 模式
+	SkipPaths []string `yaml:"skipPaths"`
+}
+
+// APIKeyEntry API Key 配置项
+type APIKeyEntry struct {
+	// Key API Key 明文，与 HashedKey 二选一
+	Key string `yaml:"key" mask:"true"`
+	// HashedKey API Key 的 SHA-256 哈希（64 位十六进制），避免在配置文件中保存明文
+	HashedKey string `yaml:"hashedKey" mask:"true"`
+	// Name 调用方名称，认证通过后作为用户 ID 存入请求上下文
+	Name string `yaml:"name"`
+	// Scopes 授予的权限范围，配合 middleware.RequireScope 使用
+	Scopes []string `yaml:"scopes"`
+	// ExpiresAt 过期时间（如 2027-01-01T00:00:00+08:00），为空时永不过期
+	ExpiresAt time.Time `yaml:"expiresAt"`
+}
+
+// GetHeaderName 获取 API Key 请求头名称，如果未配置则返回 "X-API-Key"
+func (c *APIKeyConfig) GetHeaderName() string {
+	if c.HeaderName == "" {
+		return "X-API-Key"
+	}
+	return c.HeaderName
+}
+
+// Digest 获取 API Key 的 SHA-256 哈希
+// 配置 HashedKey 时解析 HashedKey，否则计算 Key 的哈希
+//
+// 返回：
+//   - []byte: 32 字节的哈希值
+//   - error: Key 和 HashedKey 均未配置，或 HashedKey 不是 64 位十六进制时返回错误
+func (e *APIKeyEntry) Digest() ([]byte, error) {
+	if e.HashedKey != "" {
+		digest, err := hex.DecodeString(strings.TrimSpace(e.HashedKey))
+		if err != nil || len(digest) != sha256.Size {
+			return nil, fmt.Errorf("hashedKey 必须是 64 位十六进制的 SHA-256 哈希")
+		}
+		return digest, nil
+	}
+	if e.Key == "" {
+		return nil, fmt.Errorf("key 和 hashedKey 不能同时为空")
+	}
+	sum := sha256.Sum256([]byte(e.Key))
+	return sum[:], nil
+}
+
+// IsExpired 判断 API Key 在 now 时是否已过期，未配置过期时间时返回 false
+func (e *APIKeyEntry) IsExpired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
@@ -0,0 +1,52 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了审计日志（请求/响应体记录）的配置结构
+package config
+
+// 审计日志写入目标
+const (
+	AuditSinkLog = "log" // 写入框架日志
+	AuditSinkDB  = "db"  // 写入 audit_logs 数据表
+)
+
+// AuditConfig 审计日志配置
+// 用于配置审计日志中间件，记录匹配路径的完整请求体和响应体
+type AuditConfig struct {
+	// Enabled 是否启用审计日志中间件
+	Enabled bool `yaml:"enabled"`
+	// Paths 需要审计的路径列表，匹配规则与限流规则一致：
+	// 精确匹配（/api/admin/user）、前缀通配符（/api/admin/*）、path.Match 模式（/api/*/delete）
+	Paths []string `yaml:"paths"`
+	// MaxBodyBytes 请求体、响应体各自记录的最大字节数，超出部分截断，默认 65536
+	MaxBodyBytes int `yaml:"maxBodyBytes"`
+	// MaskFields 需要脱敏的 JSON 字段，默认 ["password", "idCard"]
+	// 不含 "." 时匹配任意层级的同名字段；含 "." 时按路径匹配（如 "user.idCard"），数组元素不占路径层级
+	MaskFields []string `yaml:"maskFields"`
+	// Sink 写入目标: log（框架日志）/ db（audit_logs 数据表），默认 log
+	Sink string `yaml:"sink"`
+	// DbAliasName sink 为 db 时审计表所在的数据库别名（dbList 中的 aliasName），为空时使用主数据库
+	DbAliasName string `yaml:"dbAliasName"`
+}
+
+// GetMaxBodyBytes 获取请求体、响应体记录的最大字节数，如果未配置则返回 65536
+func (c *AuditConfig) GetMaxBodyBytes() int {
+	if c.MaxBodyBytes <= 0 {
+		return 65536
+	}
+	return c.MaxBodyBytes
+}
+
+// GetMaskFields 获取需要脱敏的 JSON 字段，如果未配置则返回 ["password", "idCard"]
+func (c *AuditConfig) GetMaskFields() []string {
+	if len(c.MaskFields) == 0 {
+		return []string{"password", "idCard"}
+	}
+	return c.MaskFields
+}
+
+// GetSink 获取写入目标，如果未配置则返回 log
+func (c *AuditConfig) GetSink() string {
+	if c.Sink == "" {
+		return AuditSinkLog
+	}
+	return c.Sink
+}
@@ -0,0 +1,52 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了故障注入中间件的配置结构
+package config
+
+import "github.com/zzsen/gin_core/constant"
+
+// ChaosConfig 故障注入配置
+// 用于 chaosHandler 中间件：为匹配的请求注入延迟、错误响应或断开连接，用于发布前的韧性测试
+type ChaosConfig struct {
+	// Enabled 是否启用故障注入
+	Enabled bool `yaml:"enabled"`
+	// ProdEnv 生产环境名称，当前运行环境为该名称时即使启用也不注入故障，默认 prod
+	ProdEnv string `yaml:"prodEnv"`
+	// Rules 故障注入规则列表，按顺序匹配，第一个匹配的规则生效
+	Rules []ChaosRule `yaml:"rules"`
+}
+
+// ChaosRule 故障注入规则
+type ChaosRule struct {
+	// Path 路径匹配，支持精确匹配、/* 后缀通配符和 path.Match 模式
+	Path string `yaml:"path"`
+	// Method HTTP 方法，空表示所有方法
+	Method string `yaml:"method"`
+	// LatencyMs 注入的固定延迟（毫秒）
+	LatencyMs int `yaml:"latencyMs"`
+	// LatencyJitterMs 在固定延迟之上增加的随机延迟上限（毫秒）
+	LatencyJitterMs int `yaml:"latencyJitterMs"`
+	// ErrorRate 注入错误响应的概率，取值 0-1
+	ErrorRate float64 `yaml:"errorRate"`
+	// ErrorCode 错误响应的响应码，默认 50000（操作失败），HTTP 状态码按 service.useHTTPStatus 映射
+	ErrorCode int `yaml:"errorCode"`
+	// AbortConnection 是否直接断开连接（不返回任何响应），优先于 ErrorRate
+	AbortConnection bool `yaml:"abortConnection"`
+	// Percentage 匹配的请求中应用该规则的百分比，取值 0-100，默认 100
+	Percentage float64 `yaml:"percentage"`
+}
+
+// GetProdEnv 获取生产环境名称，默认为 prod
+func (c *ChaosConfig) GetProdEnv() string {
+	if c.ProdEnv == "" {
+		return constant.ProdEnv
+	}
+	return c.ProdEnv
+}
+
+// GetPercentage 获取应用规则的百分比，未配置时为 100
+func (r *ChaosRule) GetPercentage() float64 {
+	if r.Percentage <= 0 {
+		return 100
+	}
+	return r.Percentage
+}
@@ -0,0 +1,50 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了熔断器状态变更通知的配置结构
+package config
+
+// CircuitBreakerConfig 熔断器配置
+// 用于熔断器状态变更事件的日志记录和 Webhook 通知，熔断阈值等参数仍通过 circuitbreaker.Config 设置
+type CircuitBreakerConfig struct {
+	// LogEvents 是否通过框架日志记录状态变更（打开时记录 Warn，其他记录 Info），默认 true
+	LogEvents *bool `yaml:"logEvents"`
+	// WebhookURL 状态变更时以 POST JSON 通知的地址，为空时不通知
+	WebhookURL string `yaml:"webhookUrl" mask:"true"`
+	// WebhookTimeout 单次通知的超时时间（秒），默认 5
+	WebhookTimeout int `yaml:"webhookTimeout"`
+	// WebhookMaxRetries 通知失败（网络错误或 5xx 响应）后的最大重试次数，默认 3，负数时不重试
+	WebhookMaxRetries int `yaml:"webhookMaxRetries"`
+	// WebhookRetryInterval 重试间隔（毫秒），默认 1000
+	WebhookRetryInterval int `yaml:"webhookRetryInterval"`
+}
+
+// GetLogEvents 获取是否记录状态变更日志，如果未配置则返回 true
+func (c *CircuitBreakerConfig) GetLogEvents() bool {
+	if c.LogEvents == nil {
+		return true
+	}
+	return *c.LogEvents
+}
+
+// GetWebhookTimeout 获取单次通知的超时时间（秒），如果未配置则返回 5
+func (c *CircuitBreakerConfig) GetWebhookTimeout() int {
+	if c.WebhookTimeout <= 0 {
+		return 5
+	}
+	return c.WebhookTimeout
+}
+
+// GetWebhookMaxRetries 获取通知失败后的最大重试次数，如果未配置则返回 3，负数时返回 0
+func (c *CircuitBreakerConfig) GetWebhookMaxRetries() int {
+	if c.WebhookMaxRetries == 0 {
+		return 3
+	}
+	return max(c.WebhookMaxRetries, 0)
+}
+
+// GetWebhookRetryInterval 获取重试间隔（毫秒），如果未配置则返回 1000
+func (c *CircuitBreakerConfig) GetWebhookRetryInterval() int {
+	if c.WebhookRetryInterval <= 0 {
+		return 1000
+	}
+	return c.WebhookRetryInterval
+}
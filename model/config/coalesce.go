@@ -0,0 +1,26 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了请求合并中间件的配置结构
+package config
+
+// CoalesceConfig 请求合并配置
+// 用于 coalesceHandler 中间件：并发的相同 GET 请求只执行一次处理函数，响应共享给所有等待的请求
+type CoalesceConfig struct {
+	// Enabled 是否启用请求合并中间件
+	Enabled bool `yaml:"enabled"`
+	// Paths 需要合并的路径列表，支持精确匹配、/* 后缀通配符和 path.Match 模式，只对 GET 请求生效
+	Paths []string `yaml:"paths"`
+	// TTL 响应的缓存时间（毫秒），默认 0 表示只在进行中的请求之间共享，不缓存
+	TTL int `yaml:"ttl"`
+	// MaxBodyBytes 可共享的响应体最大字节数，默认 1048576（1MB）；超过时等待的请求各自执行处理函数
+	MaxBodyBytes int `yaml:"maxBodyBytes"`
+	// PerUser 是否按用户（ginContext.GetUserID）区分请求，开启后不同用户的相同请求不会合并
+	PerUser bool `yaml:"perUser"`
+}
+
+// GetMaxBodyBytes 获取可共享的响应体最大字节数，默认为 1048576
+func (c *CoalesceConfig) GetMaxBodyBytes() int {
+	if c.MaxBodyBytes <= 0 {
+		return 1024 * 1024
+	}
+	return c.MaxBodyBytes
+}
@@ -0,0 +1,61 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了并发限制中间件的配置结构
+package config
+
+// ConcurrencyConfig 并发限制配置
+// 用于 concurrencyLimitHandler 中间件：限制同时处理中的请求数，超出限制的请求排队等待，排队已满或等待超时时拒绝。
+// 与限流（rateLimit）不同，并发限制不关心请求速率，用于防止慢请求（如大文件上传）占满所有处理能力
+type ConcurrencyConfig struct {
+	// Enabled 是否启用并发限制
+	Enabled bool `yaml:"enabled"`
+	// MaxConcurrent 全局最大并发请求数，未匹配任何规则的请求共享该限制，小于等于 0 时不限制
+	MaxConcurrent int `yaml:"maxConcurrent"`
+	// MaxQueue 全局排队请求数上限，默认 0（超出并发限制时直接拒绝）
+	MaxQueue int `yaml:"maxQueue"`
+	// QueueTimeout 排队等待的最长时间（毫秒），默认 1000
+	QueueTimeout int `yaml:"queueTimeout"`
+	// Rules 按路径配置的并发限制规则，按顺序匹配，第一个匹配的规则生效；
+	// 匹配规则的请求只受该规则限制，不占用全局并发数
+	Rules []ConcurrencyRule `yaml:"rules"`
+}
+
+// ConcurrencyRule 并发限制规则
+type ConcurrencyRule struct {
+	// Path 路径匹配，支持精确匹配、/* 后缀通配符和 path.Match 模式
+	Path string `yaml:"path"`
+	// MaxConcurrent 匹配该规则的请求的最大并发数，必须大于 0
+	MaxConcurrent int `yaml:"maxConcurrent"`
+	// MaxQueue 匹配该规则的请求的排队数上限，0 表示使用全局 maxQueue，小于 0 表示不排队
+	MaxQueue int `yaml:"maxQueue"`
+}
+
+// GetQueueTimeout 获取排队等待的最长时间（毫秒），默认为 1000
+func (c *ConcurrencyConfig) GetQueueTimeout() int {
+	if c.QueueTimeout <= 0 {
+		return 1000
+	}
+	return c.QueueTimeout
+}
+
+// GetMaxQueue 获取全局排队请求数上限，小于 0 时为 0
+func (c *ConcurrencyConfig) GetMaxQueue() int {
+	return max(c.MaxQueue, 0)
+}
+
+// GetMaxQueue 获取规则的排队请求数上限，未配置时使用全局排队请求数上限
+//
+// 参数：
+//   - global: 全局并发限制配置
+//
+// 返回：
+//   - int: 排队请求数上限，0 表示不排队
+func (r *ConcurrencyRule) GetMaxQueue(global *ConcurrencyConfig) int {
+	switch {
+	case r.MaxQueue < 0:
+		return 0
+	case r.MaxQueue == 0:
+		return global.GetMaxQueue()
+	default:
+		return r.MaxQueue
+	}
+}
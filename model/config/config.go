@@ -5,21 +5,53 @@ package config
 // BaseConfig 应用程序基础配置结构
 // 该结构体包含了应用程序运行所需的所有配置信息，支持YAML格式的配置文件
 type BaseConfig struct {
-	System       SystemInfo       `yaml:"system"`       // 系统基础配置，控制各组件是否启用
-	Service      ServiceInfo      `yaml:"service"`      // 服务配置，包含端口、超时时间等
-	Log          LoggersConfig    `yaml:"log"`          // 日志配置，包含文件路径、轮转策略等
-	Metrics      MetricsConfig    `yaml:"metrics"`      // Prometheus 指标监控配置
-	Tracing      *TracingConfig   `yaml:"tracing"`      // OpenTelemetry 链路追踪配置
-	RateLimit    RateLimitConfig  `yaml:"rateLimit"`    // 限流配置，用于控制API请求速率
-	CORS         CORSConfig       `yaml:"cors"`         // CORS 跨域配置
-	Db           *DbInfo          `yaml:"db"`           // 单数据库配置，指向单个数据库实例
-	Etcd         *EtcdInfo        `yaml:"etcd"`         // Etcd配置，用于服务发现和配置管理
-	DbList       []DbInfo         `yaml:"dbList"`       // 多数据库列表配置，支持分库分表
-	DbResolvers  DbResolvers      `yaml:"dbResolvers"`  // 数据库解析器配置，支持读写分离
-	Redis        *RedisInfo       `yaml:"redis"`        // 单Redis配置，指向单个Redis实例
-	RedisList    []RedisInfo      `yaml:"redisList"`    // 多Redis列表配置，支持多实例部署
-	RabbitMQ     RabbitMQInfo     `yaml:"rabbitMQ"`     // RabbitMQ配置，用于消息队列
-	RabbitMQList RabbitMqListInfo `yaml:"rabbitMQList"` // RabbitMQ列表配置，支持多实例部署
-	Es           *EsInfo          `yaml:"es"`           // Elasticsearch配置，用于搜索引擎
-	Smtp         SmtpInfo         `yaml:"smtp"`         // SMTP配置，用于邮件发送
+	System            SystemInfo              `yaml:"system"`            // 系统基础配置，控制各组件是否启用
+	Service           ServiceInfo             `yaml:"service"`           // 服务配置，包含端口、超时时间等
+	Log               LoggersConfig           `yaml:"log"`               // 日志配置，包含文件路径、轮转策略等
+	Metrics           MetricsConfig           `yaml:"metrics"`           // Prometheus 指标监控配置
+	Tracing           *TracingConfig          `yaml:"tracing"`           // OpenTelemetry 链路追踪配置
+	RateLimit         RateLimitConfig         `yaml:"rateLimit"`         // 限流配置，用于控制API请求速率
+	Concurrency       ConcurrencyConfig       `yaml:"concurrency"`       // 并发限制配置，用于限制同时处理中的请求数
+	Maintenance       MaintenanceConfig       `yaml:"maintenance"`       // 维护模式配置，用于在运行时将接口切换为 503 维护状态
+	FeatureFlags      FeatureFlags            `yaml:"featureFlags"`      // 功能开关配置，用于按运行环境、用户和灰度比例开启功能
+	FeatureFlagsAdmin FeatureFlagsAdminConfig `yaml:"featureFlagsAdmin"` // 功能开关管理接口配置，用于查看各功能开关的生效状态
+	CORS              CORSConfig              `yaml:"cors"`              // CORS 跨域配置
+	SecureHeaders     SecureHeadersConfig     `yaml:"secureHeaders"`     // 安全响应头配置，用于 HSTS、CSP、X-Frame-Options 等响应头
+	Session           SessionConfig           `yaml:"session"`           // 会话配置，用于基于 Cookie 的服务端会话
+	CSRF              CSRFConfig              `yaml:"csrf"`              // CSRF 防护配置，用于校验写请求的令牌和来源
+	Idempotency       IdempotencyConfig       `yaml:"idempotency"`       // 幂等键配置，用于防止客户端重试导致写请求重复执行
+	Coalesce          CoalesceConfig          `yaml:"coalesce"`          // 请求合并配置，用于合并并发的相同 GET 请求
+	HTTPCache         HTTPCacheConfig         `yaml:"httpCache"`         // HTTP 响应缓存配置，用于缓存 GET 请求的响应和 ETag 协商缓存
+	APIKey            APIKeyConfig            `yaml:"apiKey"`            // API Key 认证配置，用于服务间调用的认证
+	Decompress        DecompressConfig        `yaml:"decompress"`        // 请求体解压配置，用于解压 gzip / deflate 压缩的请求体
+	Audit             AuditConfig             `yaml:"audit"`             // 审计日志配置，用于记录指定路径的请求体和响应体
+	Chaos             ChaosConfig             `yaml:"chaos"`             // 故障注入配置，用于在测试环境中注入延迟、错误响应或断开连接
+	Mirror            MirrorConfig            `yaml:"mirror"`            // 流量镜像配置，用于将请求异步复制到影子服务进行对比验证
+	Db                *DbInfo                 `yaml:"db"`                // 单数据库配置，指向单个数据库实例
+	Etcd              *EtcdInfo               `yaml:"etcd"`              // Etcd配置，用于服务发现和配置管理
+	DbList            []DbInfo                `yaml:"dbList"`            // 多数据库列表配置，支持分库分表
+	DbResolvers       DbResolvers             `yaml:"dbResolvers"`       // 数据库解析器配置，支持读写分离
+	Tenant            TenantConfig            `yaml:"tenant"`            // 多租户配置，用于按租户将请求路由到 dbList 中的数据库
+	Redis             *RedisInfo              `yaml:"redis"`             // 单Redis配置，指向单个Redis实例
+	RedisList         []RedisInfo             `yaml:"redisList"`         // 多Redis列表配置，支持多实例部署
+	RabbitMQ          RabbitMQInfo            `yaml:"rabbitMQ"`          // RabbitMQ配置，用于消息队列
+	RabbitMQList      RabbitMqListInfo        `yaml:"rabbitMQList"`      // RabbitMQ列表配置，支持多实例部署
+	Es                *EsInfo                 `yaml:"es"`                // Elasticsearch配置，用于搜索引擎
+	EsList            EsListInfo              `yaml:"esList"`            // Elasticsearch多集群配置，按别名区分
+	Smtp              SmtpInfo                `yaml:"smtp"`              // SMTP配置，用于邮件发送
+	Outbox            OutboxConfig            `yaml:"outbox"`            // 发件箱配置，用于数据库事务与消息发布的一致性
+	ProcessedMessage  ProcessedMessageConfig  `yaml:"processedMessage"`  // 已处理消息表配置，用于 mq.ProcessOnce 的表创建和过期记录清理
+	Upload            UploadConfig            `yaml:"upload"`            // 文件上传存储配置
+	ObjectStorage     ObjectStorageConfig     `yaml:"objectStorage"`     // 对象存储配置，用于 S3、OSS、MinIO 等 S3 兼容服务
+	I18n              I18nConfig              `yaml:"i18n"`              // 国际化配置，用于响应消息的语言协商
+	Tasks             TaskRunnerConfig        `yaml:"tasks"`             // 后台任务执行器配置
+	Events            EventBusConfig          `yaml:"events"`            // 事件总线配置，用于进程内事件的异步处理
+	MQAdmin           MQAdminConfig           `yaml:"mqAdmin"`           // 消息队列管理接口配置，用于死信队列的统计和重放
+	ResilienceAdmin   ResilienceAdminConfig   `yaml:"resilienceAdmin"`   // 熔断器和限流管理接口配置，用于在运行时重置熔断器、清除限流键
+	CircuitBreaker    CircuitBreakerConfig    `yaml:"circuitBreaker"`    // 熔断器配置，用于状态变更的日志记录和 Webhook 通知
+	Grpc              GrpcConfig              `yaml:"grpc"`              // gRPC 服务配置，与 HTTP 服务在同一进程中运行
+	RuntimeInfo       RuntimeInfoConfig       `yaml:"runtimeInfo"`       // 运行信息接口配置，用于查询当前运行的版本和构建信息
+	ConfigInspect     ConfigInspectConfig     `yaml:"configInspect"`     // 配置查看接口配置，用于查询脱敏后的生效配置和配置来源
+	Debug             DebugConfig             `yaml:"debug"`             // 调试接口配置，用于 pprof 性能分析和启动耗时诊断
+	OpenAPI           OpenAPIConfig           `yaml:"openapi"`           // OpenAPI 文档接口配置，用于根据路由和请求、响应结构体生成接口文档
 }
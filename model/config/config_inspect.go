@@ -0,0 +1,23 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了配置查看接口的配置结构
+package config
+
+// ConfigInspectConfig 配置查看接口配置
+// 启用后注册 GET {path}（挂载在 service.routePrefix 下），返回脱敏后的生效配置（BaseConfig）
+// 和每个顶层配置项来自的配置文件，用于排查默认配置与环境配置合并后的结果
+type ConfigInspectConfig struct {
+	// Enabled 是否启用配置查看接口，默认 false
+	Enabled bool `yaml:"enabled"`
+	// Path 接口路径，默认 /system/config
+	Path string `yaml:"path"`
+	// Middleware 保护接口的中间件名称（如鉴权中间件），启用时必须配置
+	Middleware string `yaml:"middleware"`
+}
+
+// GetPath 获取接口路径，如果未配置则返回 /system/config
+func (c *ConfigInspectConfig) GetPath() string {
+	if c.Path == "" {
+		return "/system/config"
+	}
+	return c.Path
+}
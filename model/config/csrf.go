@@ -0,0 +1,73 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了 CSRF（跨站请求伪造）防护中间件的配置结构
+package config
+
+import "net/http"
+
+// CSRFConfig CSRF 防护配置
+// 用于 csrfHandler 中间件，保护基于 Cookie 或会话认证的浏览器表单、接口
+type CSRFConfig struct {
+	// Enabled 是否启用 CSRF 防护中间件
+	Enabled bool `yaml:"enabled"`
+	// CookieName 未启用会话时保存令牌的 Cookie 名称（双重提交），默认 "csrf_token"
+	CookieName string `yaml:"cookieName"`
+	// HeaderName 提交令牌的请求头名称，默认 "X-CSRF-Token"
+	HeaderName string `yaml:"headerName"`
+	// FieldName 提交令牌的表单字段名称，请求头中没有令牌时读取，默认 "_csrf"
+	FieldName string `yaml:"fieldName"`
+	// TokenLength 令牌的随机字节数，默认 32，不能小于 16
+	TokenLength int `yaml:"tokenLength"`
+	// CookieSecure 令牌 Cookie 是否仅在 HTTPS 下发送
+	CookieSecure bool `yaml:"cookieSecure"`
+	// CookieSameSite 令牌 Cookie 的 SameSite 属性: lax / strict / none，默认 lax
+	CookieSameSite string `yaml:"cookieSameSite"`
+	// ExcludePaths 不校验令牌的路径（如接收第三方回调的接口），匹配规则与审计路径一致：
+	// 精确匹配（/webhooks/pay）、前缀通配符（/webhooks/*）、path.Match 模式
+	ExcludePaths []string `yaml:"excludePaths"`
+	// TrustedOrigins 除同源以外允许提交写请求的来源，匹配规则与 cors.allowOrigins 一致（支持 *.example.com）
+	TrustedOrigins []string `yaml:"trustedOrigins"`
+}
+
+// GetCookieName 获取令牌 Cookie 名称，如果未配置则返回 "csrf_token"
+func (c *CSRFConfig) GetCookieName() string {
+	if c.CookieName == "" {
+		return "csrf_token"
+	}
+	return c.CookieName
+}
+
+// GetHeaderName 获取提交令牌的请求头名称，如果未配置则返回 "X-CSRF-Token"
+func (c *CSRFConfig) GetHeaderName() string {
+	if c.HeaderName == "" {
+		return "X-CSRF-Token"
+	}
+	return c.HeaderName
+}
+
+// GetFieldName 获取提交令牌的表单字段名称，如果未配置则返回 "_csrf"
+func (c *CSRFConfig) GetFieldName() string {
+	if c.FieldName == "" {
+		return "_csrf"
+	}
+	return c.FieldName
+}
+
+// GetTokenLength 获取令牌的随机字节数，如果未配置则返回 32
+func (c *CSRFConfig) GetTokenLength() int {
+	if c.TokenLength <= 0 {
+		return 32
+	}
+	return c.TokenLength
+}
+
+// GetCookieSameSite 获取令牌 Cookie 的 SameSite 属性，未配置或无法识别时返回 http.SameSiteLaxMode
+func (c *CSRFConfig) GetCookieSameSite() http.SameSite {
+	switch c.CookieSameSite {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
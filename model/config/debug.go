@@ -0,0 +1,55 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了调试接口（pprof、启动耗时）的配置结构
+package config
+
+import (
+	"strings"
+
+	"github.com/zzsen/gin_core/constant"
+)
+
+// DebugConfig 调试接口配置
+// 启用后在主服务上注册 pprof 性能分析接口和启动耗时接口（挂载在 service.routePrefix 下），均由 ProtectMiddleware 保护
+type DebugConfig struct {
+	// EnablePprof 是否注册 pprof 接口，默认 false；运行环境为 ProductionEnv 时需同时开启 AllowInProduction
+	EnablePprof bool `yaml:"enablePprof"`
+	// PprofPrefix pprof 接口的路径前缀，默认 /debug/pprof
+	PprofPrefix string `yaml:"pprofPrefix"`
+	// ProtectMiddleware 保护调试接口的中间件名称（如鉴权中间件），启用任一调试接口时必须配置
+	ProtectMiddleware string `yaml:"protectMiddleware"`
+	// EnableStartupTimings 是否输出启动耗时日志并注册 GET /debug/startup 接口，默认 false
+	EnableStartupTimings bool `yaml:"enableStartupTimings"`
+	// AllowInProduction 是否允许在生产环境注册 pprof 接口，默认 false
+	AllowInProduction bool `yaml:"allowInProduction"`
+	// ProductionEnv 生产环境的运行环境标识，默认 prod
+	ProductionEnv string `yaml:"productionEnv"`
+}
+
+// GetPprofPrefix 获取 pprof 接口的路径前缀，如果未配置则返回 /debug/pprof，去掉末尾的 /
+func (c *DebugConfig) GetPprofPrefix() string {
+	if c.PprofPrefix == "" {
+		return "/debug/pprof"
+	}
+	return strings.TrimRight(c.PprofPrefix, "/")
+}
+
+// GetProductionEnv 获取生产环境的运行环境标识，如果未配置则返回 prod
+func (c *DebugConfig) GetProductionEnv() string {
+	if c.ProductionEnv == "" {
+		return constant.ProdEnv
+	}
+	return c.ProductionEnv
+}
+
+// PprofAllowed 判断在指定运行环境下是否允许注册 pprof 接口
+// 参数：
+//   - env: 当前运行环境
+//
+// 返回：
+//   - bool: 未启用 pprof，或运行环境为生产环境且未开启 AllowInProduction 时返回 false
+func (c *DebugConfig) PprofAllowed(env string) bool {
+	if !c.EnablePprof {
+		return false
+	}
+	return env != c.GetProductionEnv() || c.AllowInProduction
+}
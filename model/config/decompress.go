@@ -0,0 +1,18 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了请求体解压中间件的配置结构
+package config
+
+// DecompressConfig 请求体解压配置
+// 用于 decompressHandler 中间件：解压 Content-Encoding 为 gzip / deflate 的请求体
+type DecompressConfig struct {
+	// MaxBytes 解压后的请求体最大字节数，默认 10485760（10MB）；超过时返回 response.ResponsePayloadTooLarge 响应码，防止压缩炸弹
+	MaxBytes int64 `yaml:"maxBytes"`
+}
+
+// GetMaxBytes 获取解压后的请求体最大字节数，如果未配置则返回 10MB
+func (c *DecompressConfig) GetMaxBytes() int64 {
+	if c.MaxBytes <= 0 {
+		return 10 << 20
+	}
+	return c.MaxBytes
+}
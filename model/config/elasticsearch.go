@@ -2,10 +2,36 @@
 // 本文件定义了Elasticsearch搜索引擎的配置结构
 package config
 
+// DefaultEsAliasName 单个 Elasticsearch 配置（es）未设置别名时使用的别名
+const DefaultEsAliasName = "default"
+
 // EsInfo Elasticsearch配置信息
 // 该结构体包含了连接Elasticsearch集群所需的基本配置参数
 type EsInfo struct {
-	Addresses []string `yaml:"addresses"` // Elasticsearch集群节点地址列表，支持多节点配置
-	Username  string   `yaml:"username"`  // Elasticsearch访问用户名，用于身份认证
-	Password  string   `yaml:"password"`  // Elasticsearch访问密码，用于身份认证
+	AliasName string   `yaml:"aliasName"`            // 集群别名，多集群配置（esList）中必须设置，通过 app.ESByName 获取客户端
+	Addresses []string `yaml:"addresses"`            // Elasticsearch集群节点地址列表，支持多节点配置
+	Username  string   `yaml:"username"`             // Elasticsearch访问用户名，用于身份认证
+	Password  string   `yaml:"password" mask:"true"` // Elasticsearch访问密码，用于身份认证
+	CACert    string   `yaml:"caCert"`               // CA 证书文件路径（PEM 格式），集群使用自签名证书时配置
+}
+
+// GetAliasName 获取集群别名，如果未配置则返回 DefaultEsAliasName
+func (esInfo *EsInfo) GetAliasName() string {
+	if esInfo.AliasName == "" {
+		return DefaultEsAliasName
+	}
+	return esInfo.AliasName
+}
+
+// EsListInfo Elasticsearch 多集群配置列表
+type EsListInfo []EsInfo
+
+// Find 根据别名查找集群配置，未找到时返回 nil
+func (esListInfo *EsListInfo) Find(aliasName string) *EsInfo {
+	for i := range *esListInfo {
+		if (*esListInfo)[i].AliasName == aliasName {
+			return &(*esListInfo)[i]
+		}
+	}
+	return nil
 }
@@ -0,0 +1,38 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了事件总线的配置结构
+package config
+
+// EventBusConfig 事件总线配置
+// 用于 core.SubscribeEvent / core.PublishEvent 的进程内事件，异步处理函数在有界队列中排队，由固定数量的协程执行
+type EventBusConfig struct {
+	// Workers 执行异步处理函数的协程数，默认 4
+	Workers int `yaml:"workers"`
+	// QueueSize 异步事件队列容量，默认 1000
+	QueueSize int `yaml:"queueSize"`
+	// Timeout 处理函数的默认超时时间（秒），默认 30
+	Timeout int `yaml:"timeout"`
+}
+
+// GetWorkers 获取执行异步处理函数的协程数，如果未配置则返回 4
+func (c *EventBusConfig) GetWorkers() int {
+	if c.Workers <= 0 {
+		return 4
+	}
+	return c.Workers
+}
+
+// GetQueueSize 获取异步事件队列容量，如果未配置则返回 1000
+func (c *EventBusConfig) GetQueueSize() int {
+	if c.QueueSize <= 0 {
+		return 1000
+	}
+	return c.QueueSize
+}
+
+// GetTimeout 获取处理函数的默认超时时间（秒），如果未配置则返回 30
+func (c *EventBusConfig) GetTimeout() int {
+	if c.Timeout <= 0 {
+		return 30
+	}
+	return c.Timeout
+}
@@ -0,0 +1,45 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了功能开关的配置结构
+package config
+
+// FeatureFlags 功能开关配置，按开关名称索引
+// 用于 featureflags 包在运行时判断功能是否对当前请求开启，配置示例：
+//
+//	featureFlags:
+//	  newCheckout:
+//	    enabled: true
+//	    percentage: 10
+//	    allowUsers: ["u-1001"]
+//	    allowEnvs: ["test", "prod"]
+type FeatureFlags map[string]FlagConfig
+
+// FlagConfig 单个功能开关配置
+// 判断顺序：未开启或当前运行环境不在 allowEnvs 中时关闭；用户在 allowUsers 中时开启；否则按用户 ID 的哈希值灰度 percentage 比例的用户
+type FlagConfig struct {
+	// Enabled 是否开启，默认 false；关闭时对所有用户（包括 allowUsers）关闭
+	Enabled bool `yaml:"enabled"`
+	// Percentage 灰度比例（0-100），按用户 ID 的哈希值选择，同一用户的结果固定；未配置时为 100，即对所有用户开启
+	Percentage *float64 `yaml:"percentage"`
+	// AllowUsers 始终开启的用户 ID，不受灰度比例影响
+	AllowUsers []string `yaml:"allowUsers"`
+	// AllowEnvs 生效的运行环境（如 test、prod），为空时所有环境均生效
+	AllowEnvs []string `yaml:"allowEnvs"`
+	// Attributes 自定义属性（如负责人、需求链接），不参与判断，在管理接口中展示
+	Attributes map[string]string `yaml:"attributes"`
+}
+
+// GetPercentage 获取灰度比例，如果未配置则返回 100
+func (c *FlagConfig) GetPercentage() float64 {
+	if c.Percentage == nil {
+		return 100
+	}
+	return *c.Percentage
+}
+
+// FeatureFlagsAdminConfig 功能开关管理接口配置
+// 配置 middleware 后注册以下接口（挂载在 service.routePrefix 下）：
+//   - GET /admin/feature-flags
+type FeatureFlagsAdminConfig struct {
+	// Middleware 保护功能开关管理接口的中间件名称（如鉴权中间件），为空时不注册管理接口
+	Middleware string `yaml:"middleware"`
+}
@@ -0,0 +1,45 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了 gRPC 服务的配置结构
+package config
+
+// GrpcConfig gRPC 服务配置
+// 启用后 gRPC 服务与 HTTP 服务在同一进程中运行，共用启动和优雅关闭流程
+type GrpcConfig struct {
+	// Enabled 是否启用 gRPC 服务
+	Enabled bool `yaml:"enabled"`
+	// Port gRPC 监听端口，为 0 或与 service.port 相同时与 HTTP 服务共用端口（按 HTTP/2 与 Content-Type 分流）
+	Port int `yaml:"port"`
+	// MaxRecvMsgSize 单条消息的最大接收字节数，默认 4MB
+	MaxRecvMsgSize int `yaml:"maxRecvMsgSize"`
+	// EnableReflection 是否注册 gRPC 反射服务，便于 grpcurl 等工具调试
+	EnableReflection bool `yaml:"enableReflection"`
+	// TLS 证书配置，只在独立端口时生效
+	TLS GrpcTLSConfig `yaml:"tls"`
+}
+
+// GrpcTLSConfig gRPC 服务的 TLS 证书配置，证书和私钥都为空时不启用 TLS
+type GrpcTLSConfig struct {
+	CertFile string `yaml:"certFile"` // 证书文件路径
+	KeyFile  string `yaml:"keyFile"`  // 私钥文件路径
+}
+
+// Enabled 是否配置了 TLS 证书
+func (c *GrpcTLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// GetPort 获取 gRPC 监听端口，如果未配置则返回 HTTP 端口
+func (c *GrpcConfig) GetPort(httpPort int) int {
+	if c.Port <= 0 {
+		return httpPort
+	}
+	return c.Port
+}
+
+// GetMaxRecvMsgSize 获取单条消息的最大接收字节数，如果未配置则返回 4MB
+func (c *GrpcConfig) GetMaxRecvMsgSize() int {
+	if c.MaxRecvMsgSize <= 0 {
+		return 4 * 1024 * 1024
+	}
+	return c.MaxRecvMsgSize
+}
@@ -0,0 +1,72 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了 HTTP 响应缓存中间件的配置结构
+package config
+
+// HTTPCacheConfig HTTP 响应缓存配置
+// 用于 cacheHandler 中间件：缓存匹配规则的 GET 请求的响应，支持 ETag 协商缓存和 Cache-Control
+type HTTPCacheConfig struct {
+	// Enabled 是否启用响应缓存中间件
+	Enabled bool `yaml:"enabled"`
+	// Rules 缓存规则，按顺序匹配，使用第一条匹配的规则
+	Rules []CacheRule `yaml:"rules"`
+	// Store 存储类型: memory（单机）/ redis（分布式），默认 redis
+	Store string `yaml:"store"`
+	// KeyPrefix Redis 存储的键前缀，默认 "http_cache:"
+	KeyPrefix string `yaml:"keyPrefix"`
+	// MaxBodyBytes 可缓存的响应体最大字节数，默认 1048576（1MB）；超过时不缓存
+	MaxBodyBytes int `yaml:"maxBodyBytes"`
+	// CleanupInterval 内存存储清理过期缓存的间隔（秒），默认 60
+	CleanupInterval int `yaml:"cleanupInterval"`
+}
+
+// CacheRule 响应缓存规则
+type CacheRule struct {
+	// Path 路径，支持精确匹配、/* 后缀通配符和 path.Match 模式
+	Path string `yaml:"path"`
+	// TTL 缓存时间（秒），同时作为 Cache-Control 的 max-age，默认 60
+	TTL int `yaml:"ttl"`
+	// VaryHeaders 区分缓存的请求头，如 Accept-Language，同时写入 Vary 响应头
+	VaryHeaders []string `yaml:"varyHeaders"`
+	// PrivatePerUser 是否按用户（ginContext.GetUserID）区分缓存，开启后 Cache-Control 为 private
+	PrivatePerUser bool `yaml:"privatePerUser"`
+}
+
+// GetTTL 获取缓存时间（秒），如果未配置则返回 60
+func (r *CacheRule) GetTTL() int {
+	if r.TTL <= 0 {
+		return 60
+	}
+	return r.TTL
+}
+
+// GetStore 获取存储类型，默认为 redis
+func (c *HTTPCacheConfig) GetStore() string {
+	if c.Store == "" {
+		return "redis"
+	}
+	return c.Store
+}
+
+// GetKeyPrefix 获取 Redis 键前缀，默认为 "http_cache:"
+func (c *HTTPCacheConfig) GetKeyPrefix() string {
+	if c.KeyPrefix == "" {
+		return "http_cache:"
+	}
+	return c.KeyPrefix
+}
+
+// GetMaxBodyBytes 获取可缓存的响应体最大字节数，默认为 1048576
+func (c *HTTPCacheConfig) GetMaxBodyBytes() int {
+	if c.MaxBodyBytes <= 0 {
+		return 1024 * 1024
+	}
+	return c.MaxBodyBytes
+}
+
+// GetCleanupInterval 获取内存存储清理间隔（秒），默认为 60
+func (c *HTTPCacheConfig) GetCleanupInterval() int {
+	if c.CleanupInterval <= 0 {
+		return 60
+	}
+	return c.CleanupInterval
+}
@@ -0,0 +1,38 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了国际化（i18n）的配置结构
+package config
+
+// I18nConfig 国际化配置
+// 用于 I18nHandler 中间件协商请求的语言区域，以及响应消息的默认语言
+type I18nConfig struct {
+	// DefaultLocale 默认语言区域，请求未指定或无法匹配已注册的语言时使用，默认 zh-CN
+	DefaultLocale string `yaml:"defaultLocale"`
+	// QueryParam 指定语言区域的查询参数名，优先级最高，默认 lang
+	QueryParam string `yaml:"queryParam"`
+	// Header 指定语言区域的请求头名，优先级高于 Accept-Language，默认 X-Locale
+	Header string `yaml:"header"`
+}
+
+// GetDefaultLocale 获取默认语言区域，如果未配置则返回 zh-CN
+func (c *I18nConfig) GetDefaultLocale() string {
+	if c.DefaultLocale == "" {
+		return "zh-CN"
+	}
+	return c.DefaultLocale
+}
+
+// GetQueryParam 获取指定语言区域的查询参数名，如果未配置则返回 lang
+func (c *I18nConfig) GetQueryParam() string {
+	if c.QueryParam == "" {
+		return "lang"
+	}
+	return c.QueryParam
+}
+
+// GetHeader 获取指定语言区域的请求头名，如果未配置则返回 X-Locale
+func (c *I18nConfig) GetHeader() string {
+	if c.Header == "" {
+		return "X-Locale"
+	}
+	return c.Header
+}
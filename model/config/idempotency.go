@@ -0,0 +1,74 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了幂等键（Idempotency-Key）中间件的配置结构
+package config
+
+// IdempotencyConfig 幂等键配置
+// 用于 idempotencyHandler 中间件：客户端超时重试同一个写请求时，只执行一次处理函数，重复请求返回首次的响应
+type IdempotencyConfig struct {
+	// Enabled 是否启用幂等键中间件
+	Enabled bool `yaml:"enabled"`
+	// HeaderName 幂等键请求头名称，默认 "Idempotency-Key"
+	HeaderName string `yaml:"headerName"`
+	// Required 匹配的请求是否必须携带幂等键，未携带时返回参数校验不通过；为 false 时未携带的请求直接放行
+	Required bool `yaml:"required"`
+	// TTL 幂等键及其响应的保存时间（秒），默认 86400（24小时）
+	TTL int `yaml:"ttl"`
+	// Paths 需要幂等处理的路径列表，支持精确匹配、/* 后缀通配符和 path.Match 模式，只对 POST / PUT / PATCH 请求生效
+	Paths []string `yaml:"paths"`
+	// Store 存储类型: memory（单机）/ redis（分布式），默认 redis
+	Store string `yaml:"store"`
+	// KeyPrefix Redis 存储的键前缀，默认 "idempotency:"
+	KeyPrefix string `yaml:"keyPrefix"`
+	// MaxBodyBytes 保存的响应体最大字节数，默认 65536；超过时只保存状态码，重放时响应体为空
+	MaxBodyBytes int `yaml:"maxBodyBytes"`
+	// CleanupInterval 内存存储清理过期幂等键的间隔（秒），默认 60
+	CleanupInterval int `yaml:"cleanupInterval"`
+}
+
+// GetHeaderName 获取幂等键请求头名称，如果未配置则返回 "Idempotency-Key"
+func (c *IdempotencyConfig) GetHeaderName() string {
+	if c.HeaderName == "" {
+		return "Idempotency-Key"
+	}
+	return c.HeaderName
+}
+
+// GetTTL 获取幂等键保存时间（秒），如果未配置则返回 86400
+func (c *IdempotencyConfig) GetTTL() int {
+	if c.TTL <= 0 {
+		return 86400
+	}
+	return c.TTL
+}
+
+// GetStore 获取存储类型，默认为 redis
+func (c *IdempotencyConfig) GetStore() string {
+	if c.Store == "" {
+		return "redis"
+	}
+	return c.Store
+}
+
+// GetKeyPrefix 获取 Redis 键前缀，默认为 "idempotency:"
+func (c *IdempotencyConfig) GetKeyPrefix() string {
+	if c.KeyPrefix == "" {
+		return "idempotency:"
+	}
+	return c.KeyPrefix
+}
+
+// GetMaxBodyBytes 获取保存的响应体最大字节数，默认为 65536
+func (c *IdempotencyConfig) GetMaxBodyBytes() int {
+	if c.MaxBodyBytes <= 0 {
+		return 65536
+	}
+	return c.MaxBodyBytes
+}
+
+// GetCleanupInterval 获取内存存储清理间隔（秒），默认为 60
+func (c *IdempotencyConfig) GetCleanupInterval() int {
+	if c.CleanupInterval <= 0 {
+		return 60
+	}
+	return c.CleanupInterval
+}
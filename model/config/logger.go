@@ -2,6 +2,12 @@
 // 本文件定义了日志系统的配置结构，支持多级别日志配置和日志轮转策略
 package config
 
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
 // LoggersConfig 日志系统全局配置
 // 该结构体包含了日志系统的基础配置，如文件路径、轮转策略等
 type LoggersConfig struct {
@@ -11,6 +17,114 @@ type LoggersConfig struct {
 	RotationSize int            `yaml:"rotationSize"` // 日志轮转大小限制（KB），当日志文件达到指定大小时进行轮转
 	Loggers      []LoggerConfig `yaml:"loggers"`      // 日志级别配置列表，支持为不同级别配置不同的输出策略
 	PrintCaller  bool           `yaml:"printCaller"`  // 是否在日志中打印调用者信息（文件名和行号）
+	Outputs      []LogOutput    `yaml:"outputs"`      // 日志输出列表，配置后按输出写入日志，忽略 loggers 及轮转配置
+	Async        AsyncLogConfig `yaml:"async"`        // 异步写入配置，开启后日志由独立协程格式化和写入
+}
+
+// 异步日志缓冲区已满时的处理方式
+const (
+	LogDropBlock  = "block"       // 阻塞等待缓冲区有空位
+	LogDropOldest = "drop-oldest" // 丢弃缓冲区中最早的日志
+	LogDropNew    = "drop-new"    // 丢弃当前日志
+)
+
+// AsyncLogConfig 异步日志配置
+// 开启后 logger.Info、logger.Error 等函数只记录调用位置并格式化消息，脱敏、格式化输出和写入由独立协程完成
+type AsyncLogConfig struct {
+	Enabled        bool          `yaml:"enabled"`        // 是否开启异步写入，默认 false
+	BufferSize     int           `yaml:"bufferSize"`     // 缓冲区可容纳的日志条数，默认 8192
+	DropPolicy     string        `yaml:"dropPolicy"`     // 缓冲区已满时的处理方式：block / drop-oldest / drop-new，默认 block
+	SyncErrorLevel bool          `yaml:"syncErrorLevel"` // Error 及更严重级别的日志是否跳过缓冲区直接写入，保证崩溃前的错误日志不丢失
+	ReportInterval time.Duration `yaml:"reportInterval"` // 输出丢弃条数的间隔，默认 30s，期间没有丢弃时不输出
+}
+
+// GetBufferSize 获取缓冲区可容纳的日志条数，未配置时返回 8192
+func (c AsyncLogConfig) GetBufferSize() int {
+	if c.BufferSize <= 0 {
+		return 8192
+	}
+	return c.BufferSize
+}
+
+// GetDropPolicy 获取缓冲区已满时的处理方式，未配置时返回 block
+func (c AsyncLogConfig) GetDropPolicy() string {
+	if c.DropPolicy == "" {
+		return LogDropBlock
+	}
+	return c.DropPolicy
+}
+
+// GetReportInterval 获取输出丢弃条数的间隔，未配置时返回 30s
+func (c AsyncLogConfig) GetReportInterval() time.Duration {
+	if c.ReportInterval <= 0 {
+		return 30 * time.Second
+	}
+	return c.ReportInterval
+}
+
+// 日志输出类型
+const (
+	LogOutputConsole = "console" // 输出到标准输出
+	LogOutputFile    = "file"    // 输出到文件，按大小轮转
+)
+
+// 日志输出格式
+const (
+	LogFormatText = "text" // 文本格式
+	LogFormatJSON = "json" // JSON 格式
+)
+
+// LogOutput 单个日志输出配置
+// 每个输出拥有独立的格式和最低级别，可同时配置多个控制台、文件输出
+type LogOutput struct {
+	Type       string `yaml:"type"`       // 输出类型：console、file，默认 console
+	Format     string `yaml:"format"`     // 输出格式：text、json，默认 text
+	Level      string `yaml:"level"`      // 最低日志级别，该级别及更严重的日志会写入此输出，默认 trace
+	Path       string `yaml:"path"`       // 日志文件路径，仅 file 类型有效，默认 ./log/app.log
+	MaxSizeMB  int    `yaml:"maxSizeMB"`  // 单个日志文件最大大小（MB），超过后轮转，默认 100
+	MaxBackups int    `yaml:"maxBackups"` // 保留的历史文件数量，0 表示不限制
+	MaxAgeDays int    `yaml:"maxAgeDays"` // 历史文件最大保存天数，0 表示不限制
+	Compress   bool   `yaml:"compress"`   // 是否使用 gzip 压缩历史文件
+}
+
+// GetType 获取输出类型，未配置时返回 console
+func (o LogOutput) GetType() string {
+	if o.Type == "" {
+		return LogOutputConsole
+	}
+	return o.Type
+}
+
+// GetFormat 获取输出格式，未配置时返回 text
+func (o LogOutput) GetFormat() string {
+	if o.Format == "" {
+		return LogFormatText
+	}
+	return o.Format
+}
+
+// GetLevel 获取最低日志级别，未配置时返回 trace
+func (o LogOutput) GetLevel() string {
+	if o.Level == "" {
+		return "trace"
+	}
+	return o.Level
+}
+
+// GetPath 获取日志文件路径，未配置时返回 ./log/app.log
+func (o LogOutput) GetPath() string {
+	if o.Path == "" {
+		return "./log/app.log"
+	}
+	return o.Path
+}
+
+// GetMaxSizeMB 获取单个日志文件最大大小（MB），未配置时返回 100
+func (o LogOutput) GetMaxSizeMB() int {
+	if o.MaxSizeMB <= 0 {
+		return 100
+	}
+	return o.MaxSizeMB
 }
 
 // LoggerConfig 单个日志级别配置
@@ -46,5 +160,16 @@ func (loggersConfig LoggersConfig) ToDbLoggerConfig() LoggersConfig {
 			loggersConfig.Loggers[i].FilePath = loggersConfig.Loggers[i].FilePath + logSubfix
 		}
 	}
+
+	// 文件输出在文件名后添加DB后缀，如 ./log/app.log -> ./log/appDB.log
+	// 复制切片，避免修改原配置
+	loggersConfig.Outputs = append([]LogOutput(nil), loggersConfig.Outputs...)
+	for i := range loggersConfig.Outputs {
+		if loggersConfig.Outputs[i].GetType() == LogOutputFile {
+			outputPath := loggersConfig.Outputs[i].GetPath()
+			ext := filepath.Ext(outputPath)
+			loggersConfig.Outputs[i].Path = strings.TrimSuffix(outputPath, ext) + logSubfix + ext
+		}
+	}
 	return loggersConfig
 }
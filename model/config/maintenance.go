@@ -0,0 +1,43 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了维护模式的配置结构
+package config
+
+// MaintenanceConfig 维护模式配置
+// 用于 maintenanceHandler 中间件：维护模式开启时，除健康检查接口和放行的请求外均返回 51503 响应码和 Retry-After 响应头。
+// 运行时可通过 app.SetMaintenanceMode 或管理接口切换，无需重新部署；配置 middleware 后注册以下接口（挂载在 service.routePrefix 下）：
+//   - GET  /admin/maintenance
+//   - POST /admin/maintenance
+type MaintenanceConfig struct {
+	// Enabled 启动时是否处于维护模式，默认 false；运行时切换后以切换结果为准
+	Enabled bool `yaml:"enabled"`
+	// Message 维护提示消息，为空时使用 51503 响应码的默认消息
+	Message string `yaml:"message"`
+	// RetryAfterSeconds Retry-After 响应头的秒数，默认 300
+	RetryAfterSeconds int `yaml:"retryAfterSeconds"`
+	// AllowPaths 维护期间放行的路径（包含 service.routePrefix），支持精确匹配、/* 后缀通配符和 path.Match 模式
+	AllowPaths []string `yaml:"allowPaths"`
+	// AllowIPs 维护期间放行的客户端 IP，支持 IP 和 CIDR
+	AllowIPs []string `yaml:"allowIPs"`
+	// AllowHeaderToken 维护期间放行的令牌，请求头 allowHeader 与之相同的请求放行，为空时不按令牌放行
+	AllowHeaderToken string `yaml:"allowHeaderToken" mask:"true"`
+	// AllowHeader 携带放行令牌的请求头，默认 X-Maintenance-Token
+	AllowHeader string `yaml:"allowHeader"`
+	// Middleware 保护维护模式管理接口的中间件名称（如鉴权中间件），为空时不注册管理接口
+	Middleware string `yaml:"middleware"`
+}
+
+// GetRetryAfterSeconds 获取 Retry-After 响应头的秒数，如果未配置则返回 300
+func (c *MaintenanceConfig) GetRetryAfterSeconds() int {
+	if c.RetryAfterSeconds <= 0 {
+		return 300
+	}
+	return c.RetryAfterSeconds
+}
+
+// GetAllowHeader 获取携带放行令牌的请求头，如果未配置则返回 X-Maintenance-Token
+func (c *MaintenanceConfig) GetAllowHeader() string {
+	if c.AllowHeader == "" {
+		return "X-Maintenance-Token"
+	}
+	return c.AllowHeader
+}
@@ -0,0 +1,78 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了流量镜像中间件的配置结构
+package config
+
+import "time"
+
+// MirrorConfig 流量镜像配置
+// 用于 mirrorHandler 中间件：将匹配的请求异步复制一份发送到影子服务，用于新版本的对比验证，镜像的响应不会返回给客户端
+type MirrorConfig struct {
+	// Enabled 是否启用流量镜像
+	Enabled bool `yaml:"enabled"`
+	// Workers 发送镜像请求的协程数，默认 4
+	Workers int `yaml:"workers"`
+	// QueueSize 等待发送的镜像请求队列长度，队列已满时丢弃新的镜像请求，默认 1000
+	QueueSize int `yaml:"queueSize"`
+	// MaxBodyBytes 复制请求体的大小上限（字节），超过上限的请求不镜像，默认 1MB
+	MaxBodyBytes int64 `yaml:"maxBodyBytes"`
+	// Headers 复制到镜像请求的请求头，为空时复制除逐跳请求头外的所有请求头
+	Headers []string `yaml:"headers"`
+	// Rules 镜像规则列表，按顺序匹配，第一个匹配的规则生效
+	Rules []MirrorRule `yaml:"rules"`
+}
+
+// MirrorRule 流量镜像规则
+type MirrorRule struct {
+	// Path 路径匹配，支持精确匹配、/* 后缀通配符和 path.Match 模式
+	Path string `yaml:"path"`
+	// Method HTTP 方法，空表示所有方法
+	Method string `yaml:"method"`
+	// TargetBaseURL 影子服务地址，如 http://shadow.internal:8080，镜像请求的路径和查询参数与原请求相同
+	TargetBaseURL string `yaml:"targetBaseURL"`
+	// Percentage 匹配的请求中镜像的百分比，取值 0-100，默认 100
+	Percentage float64 `yaml:"percentage"`
+	// TimeoutMs 镜像请求的超时时间（毫秒），默认 3000
+	TimeoutMs int `yaml:"timeoutMs"`
+	// CopyBody 是否复制请求体，为 false 时镜像请求不带请求体
+	CopyBody bool `yaml:"copyBody"`
+}
+
+// GetWorkers 获取发送镜像请求的协程数，默认为 4
+func (c *MirrorConfig) GetWorkers() int {
+	if c.Workers <= 0 {
+		return 4
+	}
+	return c.Workers
+}
+
+// GetQueueSize 获取镜像请求队列长度，默认为 1000
+func (c *MirrorConfig) GetQueueSize() int {
+	if c.QueueSize <= 0 {
+		return 1000
+	}
+	return c.QueueSize
+}
+
+// GetMaxBodyBytes 获取复制请求体的大小上限，默认为 1MB
+func (c *MirrorConfig) GetMaxBodyBytes() int64 {
+	if c.MaxBodyBytes <= 0 {
+		return 1 << 20
+	}
+	return c.MaxBodyBytes
+}
+
+// GetPercentage 获取镜像的百分比，未配置时为 100
+func (r *MirrorRule) GetPercentage() float64 {
+	if r.Percentage <= 0 {
+		return 100
+	}
+	return r.Percentage
+}
+
+// GetTimeout 获取镜像请求的超时时间，默认为 3 秒
+func (r *MirrorRule) GetTimeout() time.Duration {
+	if r.TimeoutMs <= 0 {
+		return 3 * time.Second
+	}
+	return time.Duration(r.TimeoutMs) * time.Millisecond
+}
@@ -0,0 +1,38 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了消息队列管理接口的配置结构
+package config
+
+// MQAdminConfig 消息队列管理接口配置
+// 启用后注册死信队列的统计和重放接口、消费者统计和暂停 / 恢复接口（挂载在 service.routePrefix 下）：
+//   - GET  /admin/mq/dlq/:queue/stats
+//   - POST /admin/mq/dlq/:queue/replay?limit=
+//   - GET  /admin/mq/consumers/stats
+//   - GET  /admin/mq/consumers/states
+//   - POST /admin/mq/consumers/:queue/pause
+//   - POST /admin/mq/consumers/:queue/resume
+type MQAdminConfig struct {
+	// Enabled 是否启用管理接口，默认 false
+	Enabled bool `yaml:"enabled"`
+	// Middleware 保护管理接口的中间件名称（如鉴权中间件），启用时必须配置
+	Middleware string `yaml:"middleware"`
+	// DefaultReplayLimit 未指定 limit 时单次重放的消息数，默认 100
+	DefaultReplayLimit int `yaml:"defaultReplayLimit"`
+	// MaxReplayLimit 单次重放的最大消息数，默认 1000
+	MaxReplayLimit int `yaml:"maxReplayLimit"`
+}
+
+// GetDefaultReplayLimit 获取未指定 limit 时单次重放的消息数，如果未配置则返回 100
+func (c *MQAdminConfig) GetDefaultReplayLimit() int {
+	if c.DefaultReplayLimit <= 0 {
+		return 100
+	}
+	return c.DefaultReplayLimit
+}
+
+// GetMaxReplayLimit 获取单次重放的最大消息数，如果未配置则返回 1000
+func (c *MQAdminConfig) GetMaxReplayLimit() int {
+	if c.MaxReplayLimit <= 0 {
+		return 1000
+	}
+	return c.MaxReplayLimit
+}
@@ -2,7 +2,23 @@
 // 本文件定义了MySQL数据库的配置结构，包含连接参数、连接池配置和数据库迁移策略
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultDbAliasName 单个数据库配置（db）未设置别名时使用的别名
+	DefaultDbAliasName = "default"
+	// DefaultDbMaxIdleConns 默认的最大空闲连接数
+	DefaultDbMaxIdleConns = 10
+	// DefaultDbMaxOpenConns 默认的最大打开连接数
+	DefaultDbMaxOpenConns = 100
+	// DefaultDbConnMaxIdleTime 默认的连接最大空闲时间，单位：秒
+	DefaultDbConnMaxIdleTime = 60
+	// DefaultDbConnMaxLifetime 默认的连接最大存活时间，单位：秒
+	DefaultDbConnMaxLifetime = 60
+)
 
 // DbInfo MySQL数据库配置信息
 // 该结构体包含了连接MySQL数据库所需的所有配置参数，支持连接池和迁移策略配置
@@ -12,20 +28,63 @@ type DbInfo struct {
 	Port                      int      `yaml:"port"`                      // 数据库服务器端口，MySQL默认端口为3306
 	DBName                    string   `yaml:"dbName"`                    // 数据库名称，指定要连接的数据库
 	Username                  string   `yaml:"username"`                  // 数据库访问用户名，用于身份认证
-	Password                  string   `yaml:"password"`                  // 数据库访问密码，用于身份认证
+	Password                  string   `yaml:"password" mask:"true"`      // 数据库访问密码，用于身份认证
 	Charset                   string   `yaml:"charset"`                   // 数据库字符集，用于确保数据编码正确
 	Loc                       string   `yaml:"loc"`                       // 数据库时区设置，影响时间字段的处理
-	MaxIdleConns              int      `yaml:"maxIdleConns"`              // 空闲中的最大连接数，用于设置连接池中允许保持空闲状态的最大连接数。当连接池中的空闲连接数量超过这个值时，多余的空闲连接会被关闭。
-	MaxOpenConns              int      `yaml:"maxOpenConns"`              // 打开到数据库的最大连接数，用于设置连接池中允许同时打开的最大连接数。当打开的连接数量达到这个值时，新的连接请求会被阻塞，直到有连接被释放
-	ConnMaxIdleTime           int      `yaml:"connMaxIdleTime"`           // 最大空闲时间，单位：秒，用于设置连接在连接池中保持空闲状态的最大时间。当一个空闲连接的存活时间超过这个值时，该连接会被关闭并从连接池中移除
-	ConnMaxLifetime           int      `yaml:"connMaxLifetime"`           // 最大连接存活时间，单位：秒，用于设置连接在连接池中可以存活的最大时间。当一个连接的存活时间超过这个值时，无论该连接是否处于空闲状态，都会被关闭并从连接池中移除
+	MaxIdleConns              int      `yaml:"maxIdleConns"`              // 空闲中的最大连接数，用于设置连接池中允许保持空闲状态的最大连接数。当连接池中的空闲连接数量超过这个值时，多余的空闲连接会被关闭。默认10，小于0时不保留空闲连接
+	MaxOpenConns              int      `yaml:"maxOpenConns"`              // 打开到数据库的最大连接数，用于设置连接池中允许同时打开的最大连接数。当打开的连接数量达到这个值时，新的连接请求会被阻塞，直到有连接被释放。默认100，小于0时不限制
+	ConnMaxIdleTime           int      `yaml:"connMaxIdleTime"`           // 最大空闲时间，单位：秒，用于设置连接在连接池中保持空闲状态的最大时间。当一个空闲连接的存活时间超过这个值时，该连接会被关闭并从连接池中移除。默认60，小于0时不限制
+	ConnMaxLifetime           int      `yaml:"connMaxLifetime"`           // 最大连接存活时间，单位：秒，用于设置连接在连接池中可以存活的最大时间。当一个连接的存活时间超过这个值时，无论该连接是否处于空闲状态，都会被关闭并从连接池中移除。默认60，小于0时不限制，应小于数据库和负载均衡的空闲超时
 	Migrate                   string   `yaml:"migrate"`                   // 每次启动时更新数据库表的方式，update:增量更新表，create:删除所有表再重新建表，其他则不执行任何动作
+	AutoMigrate               bool     `yaml:"autoMigrate"`               // 启动时是否执行通过 core.RegisterMigration 注册的待执行迁移，仅对主数据库（db）生效
 	LogLevel                  *int     `yaml:"logLevel"`                  // 日志级别（1-关闭所有日志，2-仅输出错误日志，3-输出错误日志和慢查询，4-输出错误日志和慢查询日志和所有sql）
 	SlowThreshold             *int     `yaml:"slowThreshold"`             // 慢查询阈值（单位：毫秒），超过此时间的SQL查询会被记录为慢查询
 	IgnoreRecordNotFoundError *bool    `yaml:"ignoreRecordNotFoundError"` // 忽略记录未找到错误，当查询结果为空时是否记录错误日志
 	Tables                    []string `yaml:"tables"`                    // 走该库查询的数据表，用于分库分表场景下的表路由
 	TablePrefix               string   `yaml:"tablePrefix"`               // 表名前缀，所有表名都会自动添加此前缀
 	SingularTable             *bool    `yaml:"singularTable"`             // 是否使用单数表名，true时User表为user，false时User表为users
+	RedactSQLValues           bool     `yaml:"redactSQLValues"`           // 是否在SQL日志中隐藏参数值（以?占位），避免敏感数据写入日志
+	Lazy                      bool     `yaml:"lazy"`                      // 是否延迟连接，仅对 dbList 生效：启动时不连接，首次通过 app.TenantDB 使用时再连接
+	TraceComment              bool     `yaml:"traceComment"`              // 是否在SQL前附加 /* trace:<traceId> */ 注释，traceId 从会话的 context 中读取（如 app.DBWithContext），便于在数据库慢日志中关联请求
+}
+
+// GetAliasName 获取数据库别名，如果未配置则返回 DefaultDbAliasName
+func (dbInfo *DbInfo) GetAliasName() string {
+	if dbInfo.AliasName == "" {
+		return DefaultDbAliasName
+	}
+	return dbInfo.AliasName
+}
+
+// GetMaxIdleConns 获取最大空闲连接数，未配置时返回 DefaultDbMaxIdleConns，小于0时返回0（不保留空闲连接）
+func (dbInfo *DbInfo) GetMaxIdleConns() int {
+	return poolSetting(dbInfo.MaxIdleConns, DefaultDbMaxIdleConns)
+}
+
+// GetMaxOpenConns 获取最大打开连接数，未配置时返回 DefaultDbMaxOpenConns，小于0时返回0（不限制）
+func (dbInfo *DbInfo) GetMaxOpenConns() int {
+	return poolSetting(dbInfo.MaxOpenConns, DefaultDbMaxOpenConns)
+}
+
+// GetConnMaxIdleTime 获取连接最大空闲时间，未配置时返回 DefaultDbConnMaxIdleTime 秒，小于0时返回0（不限制）
+func (dbInfo *DbInfo) GetConnMaxIdleTime() time.Duration {
+	return time.Duration(poolSetting(dbInfo.ConnMaxIdleTime, DefaultDbConnMaxIdleTime)) * time.Second
+}
+
+// GetConnMaxLifetime 获取连接最大存活时间，未配置时返回 DefaultDbConnMaxLifetime 秒，小于0时返回0（不限制）
+func (dbInfo *DbInfo) GetConnMaxLifetime() time.Duration {
+	return time.Duration(poolSetting(dbInfo.ConnMaxLifetime, DefaultDbConnMaxLifetime)) * time.Second
+}
+
+// poolSetting 连接池参数：0 表示未配置，使用默认值；小于0时返回0，由 sql.DB 解释为不限制或不保留
+func poolSetting(value, defaultValue int) int {
+	switch {
+	case value == 0:
+		return defaultValue
+	case value < 0:
+		return 0
+	}
+	return value
 }
 
 // Dsn 生成数据库连接字符串
@@ -0,0 +1,65 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了对象存储（AWS S3、阿里云 OSS、MinIO 等 S3 兼容服务）的配置结构
+package config
+
+import "strings"
+
+// 对象存储服务商
+const (
+	ObjectStorageS3    = "s3"    // AWS S3
+	ObjectStorageOSS   = "oss"   // 阿里云 OSS（S3 兼容接口）
+	ObjectStorageMinIO = "minio" // MinIO
+)
+
+// ObjectStorageConfig 对象存储配置
+// 需要同时开启 system.useObjectStorage，初始化后通过 app.Storage 访问
+type ObjectStorageConfig struct {
+	// Enabled 是否启用对象存储
+	Enabled bool `yaml:"enabled"`
+	// Provider 服务商: s3 / oss / minio，默认 s3
+	Provider string `yaml:"provider"`
+	// Endpoint 服务地址（host[:port]，不包含协议），如 oss-cn-hangzhou.aliyuncs.com、minio:9000；
+	// s3 未配置时为 s3.<region>.amazonaws.com
+	Endpoint string `yaml:"endpoint"`
+	// Region 区域，默认 us-east-1；oss 为去掉 oss- 前缀的区域，如 cn-hangzhou
+	Region string `yaml:"region"`
+	// Bucket 存储桶
+	Bucket string `yaml:"bucket"`
+	// AccessKey 访问密钥 ID
+	AccessKey string `yaml:"accessKey"`
+	// SecretKey 访问密钥，支持 CIPHER(...) 加密
+	SecretKey string `yaml:"secretKey" mask:"true"`
+	// UseSSL 是否使用 https
+	UseSSL bool `yaml:"useSSL"`
+	// PathStyle 是否使用路径风格（endpoint/bucket/key），否则为虚拟主机风格（bucket.endpoint/key）；minio 始终使用路径风格
+	PathStyle bool `yaml:"pathStyle"`
+}
+
+// GetProvider 获取服务商，如果未配置则返回 s3
+func (c *ObjectStorageConfig) GetProvider() string {
+	if c.Provider == "" {
+		return ObjectStorageS3
+	}
+	return strings.ToLower(c.Provider)
+}
+
+// GetRegion 获取区域，如果未配置则返回 us-east-1
+func (c *ObjectStorageConfig) GetRegion() string {
+	if c.Region == "" {
+		return "us-east-1"
+	}
+	return c.Region
+}
+
+// GetEndpoint 获取服务地址，s3 未配置时返回 s3.<region>.amazonaws.com
+func (c *ObjectStorageConfig) GetEndpoint() string {
+	if c.Endpoint == "" && c.GetProvider() == ObjectStorageS3 {
+		return "s3." + c.GetRegion() + ".amazonaws.com"
+	}
+	return c.Endpoint
+}
+
+// UsePathStyle 是否使用路径风格访问存储桶，minio 始终使用路径风格
+func (c *ObjectStorageConfig) UsePathStyle() bool {
+	return c.PathStyle || c.GetProvider() == ObjectStorageMinIO
+}
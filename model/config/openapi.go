@@ -0,0 +1,66 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了 OpenAPI 文档接口的配置结构
+package config
+
+// OpenAPIConfig OpenAPI 文档接口配置
+// 启用后根据已注册的路由和 core.DescribeRoute 描述的请求、响应结构体生成 OpenAPI 3 文档，
+// 注册 GET {path} 返回 JSON 文档，以及 GET {uiPath} 返回 Swagger UI 页面（均挂载在 service.routePrefix 下）
+type OpenAPIConfig struct {
+	// Enabled 是否启用 OpenAPI 文档接口，默认 false
+	Enabled bool `yaml:"enabled"`
+	// Path 文档接口路径，默认 /openapi.json
+	Path string `yaml:"path"`
+	// UI 是否启用 Swagger UI 页面，默认 true
+	UI *bool `yaml:"ui"`
+	// UIPath Swagger UI 页面路径，默认 /swagger
+	UIPath string `yaml:"uiPath"`
+	// UIAssetsURL Swagger UI 静态资源（swagger-ui.css、swagger-ui-bundle.js）的地址，默认 https://unpkg.com/swagger-ui-dist@5
+	// 内网环境可部署 swagger-ui-dist 后指向内网地址
+	UIAssetsURL string `yaml:"uiAssetsURL"`
+	// Title 文档标题，默认 API
+	Title string `yaml:"title"`
+	// Version 文档版本，为空时使用构建时注入的版本号（version.Version）
+	Version string `yaml:"version"`
+	// Middleware 保护文档接口和 Swagger UI 页面的中间件名称，为空时不加中间件
+	Middleware string `yaml:"middleware"`
+}
+
+// GetPath 获取文档接口路径，如果未配置则返回 /openapi.json
+func (c *OpenAPIConfig) GetPath() string {
+	if c.Path == "" {
+		return "/openapi.json"
+	}
+	return c.Path
+}
+
+// GetUI 获取是否启用 Swagger UI 页面，如果未配置则返回 true
+func (c *OpenAPIConfig) GetUI() bool {
+	if c.UI == nil {
+		return true
+	}
+	return *c.UI
+}
+
+// GetUIPath 获取 Swagger UI 页面路径，如果未配置则返回 /swagger
+func (c *OpenAPIConfig) GetUIPath() string {
+	if c.UIPath == "" {
+		return "/swagger"
+	}
+	return c.UIPath
+}
+
+// GetUIAssetsURL 获取 Swagger UI 静态资源地址，如果未配置则返回 https://unpkg.com/swagger-ui-dist@5
+func (c *OpenAPIConfig) GetUIAssetsURL() string {
+	if c.UIAssetsURL == "" {
+		return "https://unpkg.com/swagger-ui-dist@5"
+	}
+	return c.UIAssetsURL
+}
+
+// GetTitle 获取文档标题，如果未配置则返回 API
+func (c *OpenAPIConfig) GetTitle() string {
+	if c.Title == "" {
+		return "API"
+	}
+	return c.Title
+}
@@ -0,0 +1,62 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了发件箱（Outbox）可靠消息发布的配置结构
+package config
+
+// OutboxConfig 发件箱配置
+// 启用后框架会自动创建 outbox_messages 表，并启动后台中继将事务中写入的消息投递到 RabbitMQ
+type OutboxConfig struct {
+	// Enabled 是否启用发件箱中继，需同时开启 system.useMysql 和 system.useRabbitMQ
+	Enabled bool `yaml:"enabled"`
+	// DbAliasName 发件箱表所在的数据库别名（dbList 中的 aliasName），为空时使用主数据库
+	DbAliasName string `yaml:"dbAliasName"`
+	// PollInterval 轮询间隔（毫秒），默认 1000
+	PollInterval int `yaml:"pollInterval"`
+	// BatchSize 每次轮询处理的最大消息数，默认 100
+	BatchSize int `yaml:"batchSize"`
+	// MaxRetries 最大失败次数，达到后消息标记为 dead，默认 10
+	MaxRetries int `yaml:"maxRetries"`
+	// MaxBackoff 重试等待时间上限（秒），重试间隔从 1 秒开始翻倍，默认 300
+	MaxBackoff int `yaml:"maxBackoff"`
+	// ConfirmTimeout 发布确认超时时间（秒），默认 5
+	ConfirmTimeout int `yaml:"confirmTimeout"`
+}
+
+// GetPollInterval 获取轮询间隔（毫秒），如果未配置则返回 1000
+func (c *OutboxConfig) GetPollInterval() int {
+	if c.PollInterval <= 0 {
+		return 1000
+	}
+	return c.PollInterval
+}
+
+// GetBatchSize 获取每批处理的消息数，如果未配置则返回 100
+func (c *OutboxConfig) GetBatchSize() int {
+	if c.BatchSize <= 0 {
+		return 100
+	}
+	return c.BatchSize
+}
+
+// GetMaxRetries 获取最大失败次数，如果未配置则返回 10
+func (c *OutboxConfig) GetMaxRetries() int {
+	if c.MaxRetries <= 0 {
+		return 10
+	}
+	return c.MaxRetries
+}
+
+// GetMaxBackoff 获取重试等待时间上限（秒），如果未配置则返回 300
+func (c *OutboxConfig) GetMaxBackoff() int {
+	if c.MaxBackoff <= 0 {
+		return 300
+	}
+	return c.MaxBackoff
+}
+
+// GetConfirmTimeout 获取发布确认超时时间（秒），如果未配置则返回 5
+func (c *OutboxConfig) GetConfirmTimeout() int {
+	if c.ConfirmTimeout <= 0 {
+		return 5
+	}
+	return c.ConfirmTimeout
+}
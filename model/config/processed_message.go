@@ -0,0 +1,32 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了已处理消息表（mq.ProcessOnce 使用）的配置结构
+package config
+
+import "time"
+
+// ProcessedMessageConfig 已处理消息表配置
+// mq.ProcessOnce 在 processed_messages 表中记录已处理的消息 ID，本配置控制该表的自动创建和过期记录的清理
+type ProcessedMessageConfig struct {
+	// AutoMigrate 是否在启动时自动创建 processed_messages 表，需开启 system.useMysql
+	AutoMigrate bool `yaml:"autoMigrate"`
+	// DbAliasName 表所在的数据库别名（dbList 中的 aliasName），为空时使用主数据库
+	DbAliasName string `yaml:"dbAliasName"`
+	// RetentionDays 记录的保留天数，大于 0 时注册清理过期记录的定时任务（需开启 system.useSchedule），
+	// 应大于消息可能被重新投递的最长间隔
+	RetentionDays int `yaml:"retentionDays"`
+	// SweepCron 清理定时任务的 cron 表达式，默认每天 3:30（"30 3 * * *"）
+	SweepCron string `yaml:"sweepCron"`
+}
+
+// GetRetention 获取记录的保留时间
+func (c *ProcessedMessageConfig) GetRetention() time.Duration {
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// GetSweepCron 获取清理定时任务的 cron 表达式，如果未配置则返回 "30 3 * * *"
+func (c *ProcessedMessageConfig) GetSweepCron() string {
+	if c.SweepCron == "" {
+		return "30 3 * * *"
+	}
+	return c.SweepCron
+}
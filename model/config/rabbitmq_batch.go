@@ -0,0 +1,173 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// BatchErrors 批量消费中每条消息的处理结果，与 BatchFun 的输入按下标对应，nil 表示处理成功
+// 开启 ConsumeConfig.PartialAck 时，BatchFun 返回等长的 BatchErrors 可按消息分别确认与拒绝
+//
+// 使用示例：
+//
+//	BatchFun: func(ctx context.Context, msgs []string) error {
+//	    errs := make(config.BatchErrors, len(msgs))
+//	    for i, msg := range msgs {
+//	        errs[i] = insertRow(ctx, msg)
+//	    }
+//	    return errs.OrNil()
+//	}
+type BatchErrors []error
+
+// Error 汇总失败消息的数量和错误信息
+func (e BatchErrors) Error() string {
+	var failed []string
+	for i, err := range e {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("[%d] %v", i, err))
+		}
+	}
+	return fmt.Sprintf("批量消费失败 %d/%d 条: %s", len(failed), len(e), strings.Join(failed, "; "))
+}
+
+// Unwrap 返回各条消息的错误，支持 errors.Is / errors.As
+func (e BatchErrors) Unwrap() []error {
+	return e
+}
+
+// OrNil 全部成功时返回 nil，否则返回自身
+func (e BatchErrors) OrNil() error {
+	for _, err := range e {
+		if err != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// GetBatchSize 获取批量消费每批的最大消息数，如果未配置则返回 100
+func (c *ConsumeConfig) GetBatchSize() int {
+	if c.BatchSize <= 0 {
+		return 100
+	}
+	return c.BatchSize
+}
+
+// GetBatchTimeout 获取批量消费的最长等待时间，如果未配置则返回 1s
+func (c *ConsumeConfig) GetBatchTimeout() time.Duration {
+	if c.BatchTimeout <= 0 {
+		return time.Second
+	}
+	return c.BatchTimeout
+}
+
+// consumeBatches 批量消费循环
+// 收到的消息攒到 BatchSize 条，或从收到第一条消息起超过 BatchTimeout 时调用一次 BatchFun。
+// context 取消时先处理已攒的消息再返回（处理时使用不会被取消的 context），
+// 通道关闭时已攒的消息无法再确认，由 RabbitMQ 重新投递；因暂停取消消费者时通道仍可用，处理已攒的消息后返回
+func (m *MessageQueue) consumeBatches(ctx context.Context, msgs <-chan amqp.Delivery, notifyClose <-chan *amqp.Error) error {
+	queueInfo := m.GetInfo()
+	batchSize := m.ConsumeConfig.GetBatchSize()
+	batchTimeout := m.ConsumeConfig.GetBatchTimeout()
+
+	batch := make([]amqp.Delivery, 0, batchSize)
+	timer := time.NewTimer(batchTimeout)
+	timer.Stop()
+	defer timer.Stop()
+	// timeout 只在批次非空时有效，批次为空时为 nil，不会触发
+	var timeout <-chan time.Time
+
+	flush := func(ctx context.Context) {
+		timer.Stop()
+		timeout = nil
+		m.handleBatch(ctx, batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// context 被取消，处理已攒的消息后优雅关闭
+			flush(context.WithoutCancel(ctx))
+			return nil
+		case msg, ok := <-msgs:
+			if !ok {
+				err := m.deliveriesClosed()
+				if errors.Is(err, errConsumerCanceled) {
+					flush(ctx)
+				}
+				return err
+			}
+			m.stats.received(1, time.Now())
+			if m.Dedup.Enabled {
+				if messageID := m.dedupMessageID(msg); messageID != "" && m.isDuplicate(ctx, messageID) {
+					msg.Ack(false)
+					continue
+				}
+			}
+			batch = append(batch, msg)
+			if len(batch) == 1 {
+				timer.Reset(batchTimeout)
+				timeout = timer.C
+			}
+			if len(batch) >= batchSize {
+				flush(ctx)
+			}
+		case <-timeout:
+			flush(ctx)
+		case <-notifyClose:
+			return fmt.Errorf("连接失败, queueInfo: %s", queueInfo)
+		}
+	}
+}
+
+// handleBatch 调用 BatchFun 处理一批消息并确认
+// 成功时全部确认；失败时整批按重试规则拒绝，开启 PartialAck 且返回等长的 BatchErrors 时按消息分别处理
+func (m *MessageQueue) handleBatch(ctx context.Context, batch []amqp.Delivery) {
+	if len(batch) == 0 {
+		return
+	}
+
+	bodies := make([]string, len(batch))
+	for i, msg := range batch {
+		bodies[i] = string(msg.Body)
+	}
+	m.stats.inFlight.Add(int64(len(batch)))
+	start := time.Now()
+	err := m.BatchFun(ctx, bodies)
+	m.stats.observe(m.ConsumeConfig.GetStatsWindow(), time.Since(start))
+	m.stats.inFlight.Add(-int64(len(batch)))
+	m.counters.processed.Add(uint64(len(batch)))
+
+	var batchErrs BatchErrors
+	if err != nil && m.ConsumeConfig.PartialAck && errors.As(err, &batchErrs) && len(batchErrs) == len(batch) {
+		for i, msg := range batch {
+			m.settleBatchMessage(ctx, msg, batchErrs[i])
+		}
+		return
+	}
+	for _, msg := range batch {
+		m.settleBatchMessage(ctx, msg, err)
+	}
+}
+
+// settleBatchMessage 确认或拒绝批量消费中的单条消息，成功时先标记已处理再确认
+func (m *MessageQueue) settleBatchMessage(ctx context.Context, msg amqp.Delivery, err error) {
+	if err != nil {
+		m.stats.failed.Add(1)
+		m.nackWithRetry(msg, err)
+		return
+	}
+	m.stats.succeeded.Add(1)
+	if m.Dedup.Enabled {
+		if messageID := m.dedupMessageID(msg); messageID != "" {
+			m.markProcessed(ctx, messageID)
+		}
+	}
+	msg.Ack(false)
+}
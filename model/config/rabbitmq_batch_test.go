@@ -0,0 +1,269 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ==================== 单元测试文件（不需要 RabbitMQ 连接） ====================
+//
+// 本文件测试批量消费，使用模拟的消息通道和 Acknowledger 驱动 consumeBatches。
+// 这些测试主要验证：
+// - 攒满 BatchSize 条时触发处理
+// - 未攒满时 BatchTimeout 到时触发处理
+// - BatchFun 失败时整批按重试规则拒绝
+// - 开启 PartialAck 时按 BatchErrors 分别确认与拒绝
+// - context 取消时先处理已攒的消息再退出
+
+// tagAcknowledger 模拟的消息确认器，按 DeliveryTag 记录确认与拒绝的消息
+type tagAcknowledger struct {
+	mu     sync.Mutex
+	acked  []uint64
+	nacked []uint64
+}
+
+func (a *tagAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.acked = append(a.acked, tag)
+	return nil
+}
+
+func (a *tagAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nacked = append(a.nacked, tag)
+	return nil
+}
+
+func (a *tagAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+// settled 返回已确认和已拒绝的消息总数
+func (a *tagAcknowledger) settled() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.acked) + len(a.nacked)
+}
+
+// batchRecorder 记录 BatchFun 每次收到的消息
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]string
+	result  func(msgs []string) error
+}
+
+func (r *batchRecorder) fun(ctx context.Context, msgs []string) error {
+	r.mu.Lock()
+	r.batches = append(r.batches, append([]string(nil), msgs...))
+	r.mu.Unlock()
+	if r.result != nil {
+		return r.result(msgs)
+	}
+	return nil
+}
+
+func (r *batchRecorder) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sizes := make([]int, len(r.batches))
+	for i, b := range r.batches {
+		sizes[i] = len(b)
+	}
+	return sizes
+}
+
+// startBatchConsumer 使用模拟的消息通道启动批量消费循环，返回投递函数和消费循环的退出结果
+func startBatchConsumer(ctx context.Context, m *MessageQueue, ack amqp.Acknowledger) (func(n int), <-chan error) {
+	msgs := make(chan amqp.Delivery, 100)
+	done := make(chan error, 1)
+	go func() {
+		done <- m.consumeBatches(ctx, msgs, make(chan *amqp.Error))
+	}()
+	var tag uint64
+	deliver := func(n int) {
+		for i := 0; i < n; i++ {
+			tag++
+			msgs <- amqp.Delivery{Acknowledger: ack, DeliveryTag: tag, Body: []byte{byte('a' + tag - 1)}}
+		}
+	}
+	return deliver, done
+}
+
+// waitSettled 等待指定数量的消息被确认或拒绝
+func waitSettled(t *testing.T, ack *tagAcknowledger, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for ack.settled() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待 %d 条消息确认超时，实际 %d 条", n, ack.settled())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// ==================== 单元测试：批量消费（不需要 RabbitMQ 连接） ====================
+// 测试点：验证 consumeBatches 的攒批、确认与关闭逻辑
+
+// TestMessageQueue_Batch_SizeFlush 测试按数量触发处理
+//
+// 【功能点】验证攒满 BatchSize 条消息时立即调用 BatchFun，成功后全部确认
+// 【测试流程】
+//  1. BatchSize 为 3、BatchTimeout 为 1 小时，投递 6 条消息
+//  2. 断言 BatchFun 被调用 2 次，每次 3 条，消息按顺序传入
+//  3. 断言 6 条消息均被确认，处理计数为 6
+func TestMessageQueue_Batch_SizeFlush(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rec := &batchRecorder{}
+	ack := &tagAcknowledger{}
+	m := &MessageQueue{QueueName: "batch-size", BatchFun: rec.fun, ConsumeConfig: ConsumeConfig{BatchSize: 3, BatchTimeout: time.Hour}}
+
+	deliver, _ := startBatchConsumer(ctx, m, ack)
+	deliver(6)
+	waitSettled(t, ack, 6)
+
+	if sizes := rec.sizes(); len(sizes) != 2 || sizes[0] != 3 || sizes[1] != 3 {
+		t.Fatalf("期望 2 批各 3 条，实际 %v", sizes)
+	}
+	if got := rec.batches[0]; got[0] != "a" || got[2] != "c" {
+		t.Errorf("消息顺序不正确: %v", got)
+	}
+	if len(ack.acked) != 6 || len(ack.nacked) != 0 {
+		t.Errorf("期望确认 6 条、拒绝 0 条，实际确认 %d 条、拒绝 %d 条", len(ack.acked), len(ack.nacked))
+	}
+	if stats := m.ConsumeStats(); stats.Processed != 6 {
+		t.Errorf("处理计数应为 6，实际 %d", stats.Processed)
+	}
+}
+
+// TestMessageQueue_Batch_TimeoutFlush 测试按超时触发处理
+//
+// 【功能点】验证未攒满 BatchSize 时，从收到第一条消息起 BatchTimeout 到时调用 BatchFun
+// 【测试流程】
+//  1. BatchSize 为 10、BatchTimeout 为 50ms，投递 2 条消息
+//  2. 断言超时前未调用 BatchFun，超时后调用 1 次、包含 2 条消息
+func TestMessageQueue_Batch_TimeoutFlush(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rec := &batchRecorder{}
+	ack := &tagAcknowledger{}
+	m := &MessageQueue{QueueName: "batch-timeout", BatchFun: rec.fun, ConsumeConfig: ConsumeConfig{BatchSize: 10, BatchTimeout: 50 * time.Millisecond}}
+
+	deliver, _ := startBatchConsumer(ctx, m, ack)
+	deliver(2)
+	time.Sleep(10 * time.Millisecond)
+	if len(rec.sizes()) != 0 {
+		t.Fatal("超时前不应调用 BatchFun")
+	}
+
+	waitSettled(t, ack, 2)
+	if sizes := rec.sizes(); len(sizes) != 1 || sizes[0] != 2 {
+		t.Errorf("期望 1 批 2 条，实际 %v", sizes)
+	}
+}
+
+// TestMessageQueue_Batch_FailureNack 测试批量处理失败
+//
+// 【功能点】验证 BatchFun 返回错误时整批拒绝；未开启 PartialAck 时 BatchErrors 也按整批拒绝
+// 【测试流程】
+//  1. BatchFun 返回 BatchErrors，其中只有一条失败，投递 3 条消息
+//  2. 断言 3 条消息均被拒绝，没有确认
+func TestMessageQueue_Batch_FailureNack(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rec := &batchRecorder{result: func(msgs []string) error {
+		return BatchErrors{nil, errors.New("insert failed"), nil}.OrNil()
+	}}
+	ack := &tagAcknowledger{}
+	m := &MessageQueue{QueueName: "batch-fail", BatchFun: rec.fun, ConsumeConfig: ConsumeConfig{BatchSize: 3, BatchTimeout: time.Hour}}
+
+	deliver, _ := startBatchConsumer(ctx, m, ack)
+	deliver(3)
+	waitSettled(t, ack, 3)
+
+	if len(ack.acked) != 0 || len(ack.nacked) != 3 {
+		t.Errorf("期望整批拒绝，实际确认 %v、拒绝 %v", ack.acked, ack.nacked)
+	}
+}
+
+// TestMessageQueue_Batch_PartialAck 测试按消息分别确认
+//
+// 【功能点】验证开启 PartialAck 时按 BatchErrors 分别确认与拒绝，长度不一致时整批拒绝
+// 【测试流程】
+//  1. 第一批返回 {nil, err, nil}，断言第 1、3 条确认、第 2 条拒绝
+//  2. 第二批返回长度不一致的 BatchErrors，断言整批拒绝
+func TestMessageQueue_Batch_PartialAck(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	rec := &batchRecorder{result: func(msgs []string) error {
+		calls++
+		if calls == 1 {
+			return BatchErrors{nil, errors.New("bad row"), nil}
+		}
+		return BatchErrors{errors.New("bad row")}
+	}}
+	ack := &tagAcknowledger{}
+	m := &MessageQueue{QueueName: "batch-partial", BatchFun: rec.fun, ConsumeConfig: ConsumeConfig{BatchSize: 3, BatchTimeout: time.Hour, PartialAck: true}}
+
+	deliver, _ := startBatchConsumer(ctx, m, ack)
+	deliver(3)
+	waitSettled(t, ack, 3)
+	if len(ack.acked) != 2 || ack.acked[0] != 1 || ack.acked[1] != 3 || len(ack.nacked) != 1 || ack.nacked[0] != 2 {
+		t.Fatalf("期望确认 [1 3]、拒绝 [2]，实际确认 %v、拒绝 %v", ack.acked, ack.nacked)
+	}
+
+	deliver(3)
+	waitSettled(t, ack, 6)
+	if len(ack.nacked) != 4 {
+		t.Errorf("BatchErrors 长度不一致时应整批拒绝，实际拒绝 %v", ack.nacked)
+	}
+}
+
+// TestMessageQueue_Batch_ShutdownFlush 测试关闭时处理已攒的消息
+//
+// 【功能点】验证 context 取消时先调用 BatchFun 处理未攒满的批次并确认，再正常退出
+// 【测试流程】
+//  1. BatchSize 为 10、BatchTimeout 为 1 小时，投递 2 条消息后取消 context
+//  2. 断言消费循环返回 nil，BatchFun 收到的 context 未被取消，2 条消息均被确认
+func TestMessageQueue_Batch_ShutdownFlush(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var flushCtxErr error
+	rec := &batchRecorder{}
+	ack := &tagAcknowledger{}
+	m := &MessageQueue{QueueName: "batch-shutdown", ConsumeConfig: ConsumeConfig{BatchSize: 10, BatchTimeout: time.Hour}}
+	m.BatchFun = func(ctx context.Context, msgs []string) error {
+		flushCtxErr = ctx.Err()
+		return rec.fun(ctx, msgs)
+	}
+
+	deliver, done := startBatchConsumer(ctx, m, ack)
+	deliver(2)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("优雅关闭应返回 nil，实际: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("消费循环未退出")
+	}
+	if sizes := rec.sizes(); len(sizes) != 1 || sizes[0] != 2 {
+		t.Errorf("期望关闭时处理 1 批 2 条，实际 %v", sizes)
+	}
+	if flushCtxErr != nil {
+		t.Errorf("关闭时处理使用的 context 不应被取消，实际: %v", flushCtxErr)
+	}
+	if len(ack.acked) != 2 {
+		t.Errorf("期望确认 2 条，实际 %v", ack.acked)
+	}
+}
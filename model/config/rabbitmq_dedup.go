@@ -0,0 +1,289 @@
+package config
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
+)
+
+// 消费去重存储类型
+const (
+	DedupStoreMemory = "memory" // 进程内 LRU，仅对当前实例有效
+	DedupStoreRedis  = "redis"  // Redis，多实例共享
+)
+
+// DedupStore 消费去重存储
+// 记录已处理成功的消息 ID，用于跳过重复投递的消息
+type DedupStore interface {
+	// Seen 查询消息是否已处理过
+	// 返回：
+	//   - time.Time: 首次处理完成的时间
+	//   - bool: 是否已处理过
+	//   - error: 查询失败时返回错误
+	Seen(ctx context.Context, key string) (time.Time, bool, error)
+	// Mark 标记消息已处理，已存在时保留首次处理时间，ttl 后过期
+	Mark(ctx context.Context, key string, processedAt time.Time, ttl time.Duration) error
+}
+
+// DedupConfig 消费去重配置
+// 开启后消费者在调用处理函数前检查消息 ID，已处理过的消息直接确认并跳过。
+//
+// 处理顺序为"查询 -> 处理 -> 标记 -> 确认"：处理成功后先标记再确认，
+// 进程在标记与确认之间崩溃时，重新投递的消息会被识别为重复并确认；
+// 在处理与标记之间崩溃时消息会被再次处理。因此最坏情况是重复处理，不会丢失消息。
+type DedupConfig struct {
+	// Enabled 是否启用消费去重
+	Enabled bool
+	// TTL 去重记录的保留时间，默认 24h，应大于消息可能被重新投递的最长间隔
+	TTL time.Duration
+	// Store 存储类型: memory（单实例）/ redis（多实例共享，使用 app.Redis），默认 memory
+	Store string
+	// KeyHeader 从指定消息头读取消息 ID，为空或消息头不存在时使用 AMQP 属性 MessageId
+	KeyHeader string
+	// KeyPrefix Redis 键前缀，默认 "mq:dedup:"，完整键为 前缀 + 队列名 + ":" + 消息 ID
+	KeyPrefix string
+	// MaxEntries memory 存储最多保留的记录数，超过后淘汰最久未访问的记录，默认 100000
+	MaxEntries int
+	// Backend 存储实现，为空时根据 Store 创建：memory 在首次消费时创建，redis 由框架启动消费者时使用 app.Redis 创建
+	Backend DedupStore
+	// OnDuplicate 跳过重复消息时的回调，框架启动消费者时默认输出 debug 日志
+	OnDuplicate func(messageID string, firstSeen time.Time)
+	// OnStoreError 去重存储读写失败时的回调，存储失败时消息照常处理（可能重复处理）
+	OnStoreError func(messageID string, err error)
+}
+
+// GetTTL 获取去重记录的保留时间，如果未配置则返回 24h
+func (c *DedupConfig) GetTTL() time.Duration {
+	if c.TTL <= 0 {
+		return 24 * time.Hour
+	}
+	return c.TTL
+}
+
+// GetStore 获取存储类型，如果未配置则返回 memory
+func (c *DedupConfig) GetStore() string {
+	if c.Store == "" {
+		return DedupStoreMemory
+	}
+	return c.Store
+}
+
+// GetKeyPrefix 获取 Redis 键前缀，如果未配置则返回 "mq:dedup:"
+func (c *DedupConfig) GetKeyPrefix() string {
+	if c.KeyPrefix == "" {
+		return "mq:dedup:"
+	}
+	return c.KeyPrefix
+}
+
+// GetMaxEntries 获取 memory 存储最多保留的记录数，如果未配置则返回 100000
+func (c *DedupConfig) GetMaxEntries() int {
+	if c.MaxEntries <= 0 {
+		return 100000
+	}
+	return c.MaxEntries
+}
+
+// ConsumeStats 消费统计
+type ConsumeStats struct {
+	Processed  uint64 // 交给处理函数的消息数
+	Duplicates uint64 // 因重复而跳过的消息数
+}
+
+// consumeCounters 消费计数器
+type consumeCounters struct {
+	processed  atomic.Uint64
+	duplicates atomic.Uint64
+}
+
+// ConsumeStats 获取当前队列的消费统计
+func (m *MessageQueue) ConsumeStats() ConsumeStats {
+	return ConsumeStats{
+		Processed:  m.counters.processed.Load(),
+		Duplicates: m.counters.duplicates.Load(),
+	}
+}
+
+// dedupMessageID 获取用于去重的消息 ID，优先读取 KeyHeader 指定的消息头
+func (m *MessageQueue) dedupMessageID(msg amqp.Delivery) string {
+	if m.Dedup.KeyHeader != "" && msg.Headers != nil {
+		if value, ok := msg.Headers[m.Dedup.KeyHeader]; ok && value != nil {
+			if s := fmt.Sprint(value); s != "" {
+				return s
+			}
+		}
+	}
+	return msg.MessageId
+}
+
+// dedupKey 生成去重存储的键，按队列区分，同一消息在不同队列中分别去重
+func (m *MessageQueue) dedupKey(messageID string) string {
+	return m.QueueName + ":" + messageID
+}
+
+// getDedupStore 获取去重存储，未设置 Backend 时创建进程内存储
+func (m *MessageQueue) getDedupStore() DedupStore {
+	if m.Dedup.Backend != nil {
+		return m.Dedup.Backend
+	}
+	m.dedupLock.Lock()
+	defer m.dedupLock.Unlock()
+	if m.dedupStore == nil {
+		m.dedupStore = NewMemoryDedupStore(m.Dedup.GetMaxEntries())
+	}
+	return m.dedupStore
+}
+
+// isDuplicate 查询消息是否已处理过，查询失败时视为未处理
+func (m *MessageQueue) isDuplicate(ctx context.Context, messageID string) bool {
+	firstSeen, seen, err := m.getDedupStore().Seen(ctx, m.dedupKey(messageID))
+	if err != nil {
+		if m.Dedup.OnStoreError != nil {
+			m.Dedup.OnStoreError(messageID, err)
+		}
+		return false
+	}
+	if !seen {
+		return false
+	}
+	m.counters.duplicates.Add(1)
+	if m.Dedup.OnDuplicate != nil {
+		m.Dedup.OnDuplicate(messageID, firstSeen)
+	}
+	return true
+}
+
+// markProcessed 标记消息已处理
+func (m *MessageQueue) markProcessed(ctx context.Context, messageID string) {
+	err := m.getDedupStore().Mark(ctx, m.dedupKey(messageID), time.Now(), m.Dedup.GetTTL())
+	if err != nil && m.Dedup.OnStoreError != nil {
+		m.Dedup.OnStoreError(messageID, err)
+	}
+}
+
+// MemoryDedupStore 进程内消费去重存储
+// 使用 LRU 淘汰和 TTL 过期，只对当前实例有效，多实例部署时请使用 RedisDedupStore
+type MemoryDedupStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+// memoryDedupEntry 进程内去重记录
+type memoryDedupEntry struct {
+	key       string
+	firstSeen time.Time
+	expireAt  time.Time
+}
+
+// NewMemoryDedupStore 创建进程内消费去重存储
+// 参数：
+//   - maxEntries: 最多保留的记录数，超过后淘汰最久未访问的记录
+//
+// 返回：
+//   - *MemoryDedupStore: 存储实例
+func NewMemoryDedupStore(maxEntries int) *MemoryDedupStore {
+	return &MemoryDedupStore{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Seen 查询消息是否已处理过，过期记录视为未处理
+func (s *MemoryDedupStore) Seen(_ context.Context, key string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[key]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	entry := elem.Value.(*memoryDedupEntry)
+	if time.Now().After(entry.expireAt) {
+		s.ll.Remove(elem)
+		delete(s.items, key)
+		return time.Time{}, false, nil
+	}
+	s.ll.MoveToFront(elem)
+	return entry.firstSeen, true, nil
+}
+
+// Mark 标记消息已处理，未过期的记录保留首次处理时间
+func (s *MemoryDedupStore) Mark(_ context.Context, key string, processedAt time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		entry := elem.Value.(*memoryDedupEntry)
+		if time.Now().Before(entry.expireAt) {
+			s.ll.MoveToFront(elem)
+			return nil
+		}
+		s.ll.Remove(elem)
+		delete(s.items, key)
+	}
+
+	s.items[key] = s.ll.PushFront(&memoryDedupEntry{key: key, firstSeen: processedAt, expireAt: processedAt.Add(ttl)})
+	for s.maxEntries > 0 && s.ll.Len() > s.maxEntries {
+		oldest := s.ll.Back()
+		s.ll.Remove(oldest)
+		delete(s.items, oldest.Value.(*memoryDedupEntry).key)
+	}
+	return nil
+}
+
+// Len 获取当前记录数
+func (s *MemoryDedupStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
+
+// RedisDedupStore 基于 Redis 的消费去重存储
+// 使用 SET NX 写入首次处理时间（毫秒时间戳）并设置过期时间，多个实例共享去重记录
+type RedisDedupStore struct {
+	client    redis.UniversalClient
+	keyPrefix string
+}
+
+// NewRedisDedupStore 创建基于 Redis 的消费去重存储
+// 参数：
+//   - client: Redis 客户端
+//   - keyPrefix: 键前缀，如 "mq:dedup:"
+//
+// 返回：
+//   - *RedisDedupStore: 存储实例
+func NewRedisDedupStore(client redis.UniversalClient, keyPrefix string) *RedisDedupStore {
+	return &RedisDedupStore{client: client, keyPrefix: keyPrefix}
+}
+
+// Seen 查询消息是否已处理过
+func (s *RedisDedupStore) Seen(ctx context.Context, key string) (time.Time, bool, error) {
+	value, err := s.client.Get(ctx, s.keyPrefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, true, nil
+	}
+	return time.UnixMilli(millis), true, nil
+}
+
+// Mark 标记消息已处理，键已存在时保留首次处理时间
+func (s *RedisDedupStore) Mark(ctx context.Context, key string, processedAt time.Time, ttl time.Duration) error {
+	return s.client.SetNX(ctx, s.keyPrefix+key, processedAt.UnixMilli(), ttl).Err()
+}
@@ -0,0 +1,289 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
+)
+
+// ==================== 单元测试文件（不需要 RabbitMQ 连接） ====================
+//
+// 本文件测试消费去重，使用模拟的 Acknowledger 构造消息投递，使用 miniredis 模拟 Redis。
+// 这些测试主要验证：
+// - 同一 MessageId 重新投递时跳过处理函数并确认消息，计数器正确
+// - 处理失败的消息不标记，重新投递时仍会处理
+// - KeyHeader 指定的消息头优先于 MessageId
+// - 进程内存储的 TTL 过期与 LRU 淘汰
+// - 两个实例共享 Redis 存储时跨实例去重
+// - 发布的消息自动带有 MessageId
+
+// fakeAcknowledger 模拟的消息确认器，记录确认与拒绝的次数
+type fakeAcknowledger struct {
+	mu      sync.Mutex
+	acks    int
+	nacks   int
+	requeue int
+}
+
+func (a *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.acks++
+	return nil
+}
+
+func (a *fakeAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nacks++
+	if requeue {
+		a.requeue++
+	}
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+// newDelivery 构造指定 MessageId 的消息投递
+func newDelivery(ack amqp.Acknowledger, messageID, body string) amqp.Delivery {
+	return amqp.Delivery{Acknowledger: ack, MessageId: messageID, Body: []byte(body)}
+}
+
+// ==================== 单元测试：消费去重（不需要 RabbitMQ 连接） ====================
+// 测试点：验证 handleMessage 的去重逻辑
+
+// TestMessageQueue_Dedup_Redelivery 测试重复投递被跳过
+//
+// 【功能点】验证同一 MessageId 的消息只处理一次，重复消息被确认并回调 OnDuplicate
+// 【测试流程】
+//  1. 启用 memory 去重，投递 MessageId 为 m1 的消息两次，再投递 m2
+//  2. 断言处理函数只被调用 2 次，3 条消息均被确认
+//  3. 断言 OnDuplicate 收到 m1 及首次处理时间，统计为 processed=2、duplicates=1
+func TestMessageQueue_Dedup_Redelivery(t *testing.T) {
+	var handled []string
+	var dupID string
+	var dupFirstSeen time.Time
+	mq := &MessageQueue{
+		QueueName: "order",
+		FunWithCtx: func(ctx context.Context, msg string) error {
+			handled = append(handled, msg)
+			return nil
+		},
+		Dedup: DedupConfig{
+			Enabled: true,
+			OnDuplicate: func(messageID string, firstSeen time.Time) {
+				dupID, dupFirstSeen = messageID, firstSeen
+			},
+		},
+	}
+	ack := &fakeAcknowledger{}
+	ctx := context.Background()
+	before := time.Now()
+
+	mq.handleMessage(ctx, newDelivery(ack, "m1", "first"))
+	mq.handleMessage(ctx, newDelivery(ack, "m1", "redelivered"))
+	mq.handleMessage(ctx, newDelivery(ack, "m2", "second"))
+
+	if len(handled) != 2 || handled[0] != "first" || handled[1] != "second" {
+		t.Errorf("handled = %v, want [first second]", handled)
+	}
+	if ack.acks != 3 || ack.nacks != 0 {
+		t.Errorf("acks = %d, nacks = %d, want 3, 0", ack.acks, ack.nacks)
+	}
+	if dupID != "m1" {
+		t.Errorf("OnDuplicate messageID = %q, want m1", dupID)
+	}
+	if dupFirstSeen.Before(before) {
+		t.Errorf("OnDuplicate firstSeen = %v, 应不早于 %v", dupFirstSeen, before)
+	}
+	if stats := mq.ConsumeStats(); stats != (ConsumeStats{Processed: 2, Duplicates: 1}) {
+		t.Errorf("ConsumeStats() = %+v, want {Processed:2 Duplicates:1}", stats)
+	}
+}
+
+// TestMessageQueue_Dedup_FailedNotMarked 测试处理失败的消息不标记
+//
+// 【功能点】验证处理失败时不写入去重记录，重新投递的消息仍会被处理；没有 MessageId 的消息不去重
+// 【测试流程】
+//  1. 处理函数首次返回错误，断言消息被 Nack 并重新入队
+//  2. 重新投递同一消息，断言处理函数再次被调用且消息被确认
+//  3. 投递两次没有 MessageId 的消息，断言都被处理
+func TestMessageQueue_Dedup_FailedNotMarked(t *testing.T) {
+	calls := 0
+	mq := &MessageQueue{
+		QueueName: "order",
+		FunWithCtx: func(ctx context.Context, msg string) error {
+			calls++
+			if calls == 1 {
+				return errors.New("处理失败")
+			}
+			return nil
+		},
+		Dedup: DedupConfig{Enabled: true},
+	}
+	ack := &fakeAcknowledger{}
+	ctx := context.Background()
+
+	mq.handleMessage(ctx, newDelivery(ack, "m1", "body"))
+	if ack.requeue != 1 {
+		t.Fatalf("requeue = %d, want 1", ack.requeue)
+	}
+	mq.handleMessage(ctx, newDelivery(ack, "m1", "body"))
+	if calls != 2 || ack.acks != 1 {
+		t.Errorf("calls = %d, acks = %d, want 2, 1", calls, ack.acks)
+	}
+
+	mq.handleMessage(ctx, newDelivery(ack, "", "no-id"))
+	mq.handleMessage(ctx, newDelivery(ack, "", "no-id"))
+	if calls != 4 {
+		t.Errorf("calls = %d, want 4", calls)
+	}
+	if stats := mq.ConsumeStats(); stats.Duplicates != 0 {
+		t.Errorf("Duplicates = %d, want 0", stats.Duplicates)
+	}
+}
+
+// TestMessageQueue_Dedup_KeyHeader 测试从消息头读取消息 ID
+//
+// 【功能点】验证配置 KeyHeader 时优先使用消息头的值，消息头不存在时回退到 MessageId
+// 【测试流程】构造带 x-biz-id 消息头和不带消息头的投递，断言提取的 ID
+func TestMessageQueue_Dedup_KeyHeader(t *testing.T) {
+	mq := &MessageQueue{Dedup: DedupConfig{Enabled: true, KeyHeader: "x-biz-id"}}
+
+	withHeader := amqp.Delivery{MessageId: "uuid-1", Headers: amqp.Table{"x-biz-id": int64(1001)}}
+	if got := mq.dedupMessageID(withHeader); got != "1001" {
+		t.Errorf("dedupMessageID() = %q, want 1001", got)
+	}
+	withoutHeader := amqp.Delivery{MessageId: "uuid-2"}
+	if got := mq.dedupMessageID(withoutHeader); got != "uuid-2" {
+		t.Errorf("dedupMessageID() = %q, want uuid-2", got)
+	}
+}
+
+// TestMemoryDedupStore_TTLAndLRU 测试进程内存储的过期与淘汰
+//
+// 【功能点】验证记录过期后视为未处理，超过容量时淘汰最久未访问的记录，重复标记保留首次处理时间
+// 【测试流程】
+//  1. 以 50ms TTL 标记 a，断言可查到；等待过期后断言查不到
+//  2. 容量为 2，依次标记 a、b，访问 a 后标记 c，断言 b 被淘汰、a 和 c 保留
+//  3. 再次标记 a，断言首次处理时间不变
+func TestMemoryDedupStore_TTLAndLRU(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryDedupStore(2)
+
+	_ = store.Mark(ctx, "a", time.Now(), 50*time.Millisecond)
+	if _, seen, _ := store.Seen(ctx, "a"); !seen {
+		t.Fatal("a 应已标记")
+	}
+	time.Sleep(80 * time.Millisecond)
+	if _, seen, _ := store.Seen(ctx, "a"); seen {
+		t.Fatal("a 应已过期")
+	}
+
+	first := time.Now()
+	_ = store.Mark(ctx, "a", first, time.Minute)
+	_ = store.Mark(ctx, "b", time.Now(), time.Minute)
+	_, _, _ = store.Seen(ctx, "a")
+	_ = store.Mark(ctx, "c", time.Now(), time.Minute)
+
+	if _, seen, _ := store.Seen(ctx, "b"); seen {
+		t.Error("b 应被淘汰")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, seen, _ := store.Seen(ctx, key); !seen {
+			t.Errorf("%s 应保留", key)
+		}
+	}
+	if store.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", store.Len())
+	}
+
+	_ = store.Mark(ctx, "a", first.Add(time.Hour), time.Minute)
+	if firstSeen, _, _ := store.Seen(ctx, "a"); !firstSeen.Equal(first) {
+		t.Errorf("firstSeen = %v, want %v", firstSeen, first)
+	}
+}
+
+// TestRedisDedupStore_CrossInstance 测试跨实例去重
+//
+// 【功能点】验证两个消费同一队列的实例共享 Redis 存储时，同一消息只处理一次，记录带有 TTL
+// 【测试流程】
+//  1. 启动 miniredis，两个 MessageQueue 各自使用独立客户端创建 RedisDedupStore
+//  2. 实例 A 处理 m1，实例 B 收到重新投递的 m1
+//  3. 断言只有 A 调用了处理函数，B 的 duplicates 为 1，Redis 键带有 TTL
+//  4. Redis 不可用时，断言消息照常处理并回调 OnStoreError
+func TestRedisDedupStore_CrossInstance(t *testing.T) {
+	mr := miniredis.RunT(t)
+	newInstance := func(calls *int, onStoreError func(string, error)) *MessageQueue {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+		t.Cleanup(func() { _ = client.Close() })
+		return &MessageQueue{
+			QueueName: "order",
+			FunWithCtx: func(ctx context.Context, msg string) error {
+				*calls++
+				return nil
+			},
+			Dedup: DedupConfig{
+				Enabled:      true,
+				TTL:          time.Hour,
+				Backend:      NewRedisDedupStore(client, "mq:dedup:"),
+				OnStoreError: onStoreError,
+			},
+		}
+	}
+
+	var callsA, callsB int
+	instanceA := newInstance(&callsA, nil)
+	instanceB := newInstance(&callsB, nil)
+	ack := &fakeAcknowledger{}
+
+	instanceA.handleMessage(context.Background(), newDelivery(ack, "m1", "body"))
+	instanceB.handleMessage(context.Background(), newDelivery(ack, "m1", "body"))
+
+	if callsA != 1 || callsB != 0 {
+		t.Errorf("callsA = %d, callsB = %d, want 1, 0", callsA, callsB)
+	}
+	if ack.acks != 2 {
+		t.Errorf("acks = %d, want 2", ack.acks)
+	}
+	if stats := instanceB.ConsumeStats(); stats.Duplicates != 1 || stats.Processed != 0 {
+		t.Errorf("instanceB ConsumeStats() = %+v, want {Processed:0 Duplicates:1}", stats)
+	}
+	if ttl := mr.TTL("mq:dedup:order:m1"); ttl != time.Hour {
+		t.Errorf("TTL = %v, want 1h", ttl)
+	}
+
+	var storeErrors int
+	var callsC int
+	instanceC := newInstance(&callsC, func(string, error) { storeErrors++ })
+	mr.Close()
+	instanceC.handleMessage(context.Background(), newDelivery(ack, "m2", "body"))
+	if callsC != 1 || storeErrors != 2 {
+		t.Errorf("callsC = %d, storeErrors = %d, want 1, 2", callsC, storeErrors)
+	}
+}
+
+// TestNewPublishing_MessageID 测试发布消息的 MessageId
+//
+// 【功能点】验证未指定 MessageId 时自动生成 uuid，指定时使用指定值
+// 【测试流程】分别构造未指定和指定 MessageId 的消息，断言结果
+func TestNewPublishing_MessageID(t *testing.T) {
+	first, second := newPublishing("a", ""), newPublishing("a", "")
+	if first.MessageId == "" || first.MessageId == second.MessageId {
+		t.Errorf("自动生成的 MessageId 应非空且唯一, got %q, %q", first.MessageId, second.MessageId)
+	}
+	if first.DeliveryMode != amqp.Persistent {
+		t.Errorf("DeliveryMode = %d, want Persistent", first.DeliveryMode)
+	}
+	if got := newPublishing("a", "outbox-1").MessageId; got != "outbox-1" {
+		t.Errorf("MessageId = %q, want outbox-1", got)
+	}
+}
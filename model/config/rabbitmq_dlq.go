@@ -0,0 +1,241 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ErrDeadLetterDisabled 队列未启用死信队列
+var ErrDeadLetterDisabled = errors.New("队列未启用死信队列")
+
+// deathHeaders 死信相关的消息头，重放时移除，使消息的重试计数重新开始
+var deathHeaders = []string{
+	"x-death",
+	"x-first-death-exchange",
+	"x-first-death-queue",
+	"x-first-death-reason",
+	"x-last-death-exchange",
+	"x-last-death-queue",
+	"x-last-death-reason",
+}
+
+// DeadLetterStats 死信队列统计信息
+type DeadLetterStats struct {
+	Queue           string `json:"queue"`           // 主队列名称
+	DeadLetterQueue string `json:"deadLetterQueue"` // 死信队列名称
+	Messages        int    `json:"messages"`        // 死信队列中待处理的消息数
+	Consumers       int    `json:"consumers"`       // 死信队列的消费者数
+}
+
+// ReplayReport 死信重放结果
+type ReplayReport struct {
+	Queue           string   `json:"queue"`            // 主队列名称
+	DeadLetterQueue string   `json:"deadLetterQueue"`  // 死信队列名称
+	Replayed        int      `json:"replayed"`         // 重新发布成功的消息数，已从死信队列中移除
+	Failed          int      `json:"failed"`           // 重新发布失败的消息数，仍保留在死信队列中
+	Errors          []string `json:"errors,omitempty"` // 重新发布失败的原因
+}
+
+// DeadLetterQueueName 获取死信队列名称，未配置 DeadLetter.QueueName 时为主队列名称 + ".dlq"
+func (m *MessageQueue) DeadLetterQueueName() string {
+	return m.getDeadLetterQueue()
+}
+
+// DeadLetterStats 获取死信队列统计信息
+// 参数：
+//   - ctx: context，结束时放弃等待
+//
+// 返回：
+//   - DeadLetterStats: 死信队列中的消息数和消费者数
+//   - error: 未启用死信队列、死信队列不存在或连接失败时返回错误
+func (m *MessageQueue) DeadLetterStats(ctx context.Context) (DeadLetterStats, error) {
+	stats := DeadLetterStats{Queue: m.QueueName, DeadLetterQueue: m.getDeadLetterQueue()}
+	if !m.DeadLetter.Enabled {
+		return stats, fmt.Errorf("%w, queueInfo: %s", ErrDeadLetterDisabled, m.GetInfo())
+	}
+	ch, err := m.openAdminChannel()
+	if err != nil {
+		return stats, err
+	}
+	defer ch.Close()
+
+	if err := ctx.Err(); err != nil {
+		return stats, err
+	}
+	// 被动声明只检查队列是否存在，不会创建队列；队列不存在时通道会被服务端关闭，因此使用独立通道
+	q, err := ch.QueueDeclarePassive(stats.DeadLetterQueue, true, false, false, false, nil)
+	if err != nil {
+		return stats, fmt.Errorf("获取死信队列信息失败, queueInfo: %s, error: %w", m.GetInfo(), err)
+	}
+	stats.Messages = q.Messages
+	stats.Consumers = q.Consumers
+	return stats, nil
+}
+
+// ReplayDeadLetters 重放死信队列中的消息
+// 从死信队列逐条取出最多 limit 条消息，重新发布到消息原来的交换机和路由键
+// （优先读取 x-death 消息头，不存在时使用队列配置的 ExchangeName、RoutingKey），
+// 保留其他消息头和属性，移除 x-death 等死信消息头使消费重试计数重新开始。
+// 重新发布得到服务端确认后才从死信队列中移除；失败的消息在结束时退回死信队列，不会在本次重放中被重复取出。
+//
+// 参数：
+//   - ctx: context，结束时停止取出新消息
+//   - limit: 最多重放的消息数，<= 0 时不重放
+//
+// 返回：
+//   - ReplayReport: 重放成功、失败的消息数
+//   - error: 未启用死信队列、连接失败或从死信队列取消息失败时返回错误，已重放的消息计入 ReplayReport
+func (m *MessageQueue) ReplayDeadLetters(ctx context.Context, limit int) (ReplayReport, error) {
+	report := ReplayReport{Queue: m.QueueName, DeadLetterQueue: m.getDeadLetterQueue()}
+	if !m.DeadLetter.Enabled {
+		return report, fmt.Errorf("%w, queueInfo: %s", ErrDeadLetterDisabled, m.GetInfo())
+	}
+	if limit <= 0 {
+		return report, nil
+	}
+	ch, err := m.openAdminChannel()
+	if err != nil {
+		return report, err
+	}
+	defer ch.Close()
+	if err := ch.Confirm(false); err != nil {
+		return report, fmt.Errorf("开启发布确认失败, queueInfo: %s, error: %w", m.GetInfo(), err)
+	}
+
+	timeout := 5 * time.Second
+	if m.PublishConfirm.Timeout > 0 {
+		timeout = m.PublishConfirm.Timeout
+	}
+
+	// failed 重新发布失败的消息，结束时统一退回死信队列，避免在本次重放中被再次取出
+	var failed []amqp.Delivery
+	defer func() {
+		for _, msg := range failed {
+			_ = msg.Nack(false, true)
+		}
+	}()
+
+	for report.Replayed+report.Failed < limit {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		msg, ok, err := ch.Get(report.DeadLetterQueue, false)
+		if err != nil {
+			return report, fmt.Errorf("从死信队列获取消息失败, queueInfo: %s, error: %w", m.GetInfo(), err)
+		}
+		if !ok {
+			break
+		}
+
+		exchange, routingKey := m.replayTarget(msg.Headers)
+		if err := m.republish(ctx, ch, exchange, routingKey, msg, timeout); err != nil {
+			failed = append(failed, msg)
+			report.Failed++
+			report.Errors = append(report.Errors, fmt.Sprintf("messageId: %s, error: %v", msg.MessageId, err))
+			continue
+		}
+		if err := msg.Ack(false); err != nil {
+			// 确认失败时消息会在通道关闭后退回死信队列，已重新发布的消息可能被重复消费
+			report.Failed++
+			report.Errors = append(report.Errors, fmt.Sprintf("messageId: %s, 已重新发布但从死信队列移除失败: %v", msg.MessageId, err))
+			continue
+		}
+		report.Replayed++
+	}
+	return report, nil
+}
+
+// openAdminChannel 在当前连接上创建独立通道，用于死信队列的查询和重放，不影响消费通道
+func (m *MessageQueue) openAdminChannel() (*amqp.Channel, error) {
+	if err := m.initConn(); err != nil {
+		return nil, err
+	}
+	ch, err := m.Conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("开启通道失败: queueInfo: %s, error: %w", m.GetInfo(), err)
+	}
+	return ch, nil
+}
+
+// republish 将死信消息重新发布到指定交换机，并等待服务端确认
+func (m *MessageQueue) republish(ctx context.Context, ch *amqp.Channel, exchange, routingKey string, msg amqp.Delivery, timeout time.Duration) error {
+	pubCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(pubCtx, exchange, routingKey, false, false, replayPublishing(msg))
+	if err != nil {
+		return fmt.Errorf("消息发布失败: %w", err)
+	}
+	acked, err := confirm.WaitContext(pubCtx)
+	if err != nil {
+		return fmt.Errorf("等待确认超时: %w", err)
+	}
+	if !acked {
+		return fmt.Errorf("消息未被确认, deliveryTag: %d", confirm.DeliveryTag)
+	}
+	return nil
+}
+
+// replayTarget 获取死信消息原来的交换机和路由键
+// 读取 x-death 中死于主队列的记录（不存在时使用第一条记录），记录缺失时使用队列配置的 ExchangeName、RoutingKey
+func (m *MessageQueue) replayTarget(headers amqp.Table) (exchange, routingKey string) {
+	deaths, _ := headers["x-death"].([]interface{})
+	var death amqp.Table
+	for _, item := range deaths {
+		table, ok := item.(amqp.Table)
+		if !ok {
+			continue
+		}
+		if death == nil {
+			death = table
+		}
+		if queue, _ := table["queue"].(string); queue == m.QueueName {
+			death = table
+			break
+		}
+	}
+
+	exchange, hasExchange := death["exchange"].(string)
+	keys, _ := death["routing-keys"].([]interface{})
+	if len(keys) > 0 {
+		routingKey, _ = keys[0].(string)
+	}
+	if !hasExchange || len(keys) == 0 {
+		return m.ExchangeName, m.RoutingKey
+	}
+	return exchange, routingKey
+}
+
+// replayPublishing 根据死信消息构造重新发布的消息，保留消息属性和消息头，移除死信消息头
+// UserId 需要与连接的用户一致，否则会被服务端拒绝，因此不保留
+func replayPublishing(msg amqp.Delivery) amqp.Publishing {
+	var headers amqp.Table
+	if len(msg.Headers) > 0 {
+		headers = make(amqp.Table, len(msg.Headers))
+		for k, v := range msg.Headers {
+			headers[k] = v
+		}
+		for _, k := range deathHeaders {
+			delete(headers, k)
+		}
+	}
+	return amqp.Publishing{
+		Headers:         headers,
+		ContentType:     msg.ContentType,
+		ContentEncoding: msg.ContentEncoding,
+		DeliveryMode:    msg.DeliveryMode,
+		Priority:        msg.Priority,
+		CorrelationId:   msg.CorrelationId,
+		ReplyTo:         msg.ReplyTo,
+		Expiration:      msg.Expiration,
+		MessageId:       msg.MessageId,
+		Timestamp:       msg.Timestamp,
+		Type:            msg.Type,
+		AppId:           msg.AppId,
+		Body:            msg.Body,
+	}
+}
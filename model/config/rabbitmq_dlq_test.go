@@ -0,0 +1,132 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ==================== 单元测试文件（不需要 RabbitMQ 连接） ====================
+//
+// 本文件测试死信重放中与连接无关的部分，连接 RabbitMQ 的完整流程见集成测试 TestIntegration_ReplayDeadLetters。
+// 这些测试主要验证：
+// - 重放目标优先取 x-death 中死于主队列的记录，记录缺失时使用队列配置
+// - 重放的消息保留属性和业务消息头，移除死信消息头
+// - 未启用死信队列时返回 ErrDeadLetterDisabled，不建立连接
+
+// TestReplayTarget 测试重放目标
+//
+// 【功能点】验证重放目标的交换机和路由键来源
+// 【测试流程】分别以死于主队列的记录、其他队列的记录、无 x-death 的消息头调用 replayTarget，断言返回值
+func TestReplayTarget(t *testing.T) {
+	mq := &MessageQueue{QueueName: "order", ExchangeName: "order-exchange", RoutingKey: "paid"}
+
+	tests := []struct {
+		name                 string
+		headers              amqp.Table
+		exchange, routingKey string
+	}{
+		{
+			name: "优先使用死于主队列的记录",
+			headers: amqp.Table{"x-death": []interface{}{
+				amqp.Table{"queue": "order.retry", "exchange": "retry-exchange", "routing-keys": []interface{}{"retry"}},
+				amqp.Table{"queue": "order", "exchange": "origin-exchange", "routing-keys": []interface{}{"origin", "other"}},
+			}},
+			exchange:   "origin-exchange",
+			routingKey: "origin",
+		},
+		{
+			name: "没有主队列记录时使用第一条记录",
+			headers: amqp.Table{"x-death": []interface{}{
+				amqp.Table{"queue": "order.retry", "exchange": "", "routing-keys": []interface{}{"order.retry"}},
+			}},
+			exchange:   "",
+			routingKey: "order.retry",
+		},
+		{
+			name:       "没有 x-death 时使用队列配置",
+			headers:    amqp.Table{"traceId": "t-1"},
+			exchange:   "order-exchange",
+			routingKey: "paid",
+		},
+		{
+			name:       "记录缺少路由键时使用队列配置",
+			headers:    amqp.Table{"x-death": []interface{}{amqp.Table{"queue": "order", "exchange": "origin-exchange"}}},
+			exchange:   "order-exchange",
+			routingKey: "paid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotExchange, gotRoutingKey := mq.replayTarget(tt.headers)
+			if gotExchange != tt.exchange || gotRoutingKey != tt.routingKey {
+				t.Errorf("期望 (%q, %q), 实际 (%q, %q)", tt.exchange, tt.routingKey, gotExchange, gotRoutingKey)
+			}
+		})
+	}
+}
+
+// TestReplayPublishing 测试重放消息的构造
+//
+// 【功能点】验证重放的消息保留消息属性、消息体和业务消息头，移除 x-death 等死信消息头，且不修改原消息头
+// 【测试流程】构造带死信消息头的 Delivery，调用 replayPublishing，断言消息头和属性
+func TestReplayPublishing(t *testing.T) {
+	msg := amqp.Delivery{
+		Headers: amqp.Table{
+			"traceId":                "t-1",
+			"x-death":                []interface{}{amqp.Table{"count": int64(3)}},
+			"x-first-death-queue":    "order",
+			"x-first-death-reason":   "rejected",
+			"x-first-death-exchange": "order-exchange",
+			"x-last-death-queue":     "order",
+		},
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    "m-1",
+		UserId:       "guest",
+		Body:         []byte(`{"id":1}`),
+	}
+
+	pub := replayPublishing(msg)
+	if want := (amqp.Table{"traceId": "t-1"}); !reflect.DeepEqual(pub.Headers, want) {
+		t.Errorf("期望消息头 %v, 实际 %v", want, pub.Headers)
+	}
+	if pub.ContentType != "application/json" || pub.DeliveryMode != amqp.Persistent || pub.MessageId != "m-1" || string(pub.Body) != `{"id":1}` {
+		t.Errorf("消息属性未保留: %+v", pub)
+	}
+	if pub.UserId != "" {
+		t.Errorf("UserId 不应保留, 实际 %q", pub.UserId)
+	}
+	if _, ok := msg.Headers["x-death"]; !ok {
+		t.Error("不应修改原消息头")
+	}
+
+	if pub := replayPublishing(amqp.Delivery{}); pub.Headers != nil {
+		t.Errorf("原消息没有消息头时期望 nil, 实际 %v", pub.Headers)
+	}
+}
+
+// TestReplayDeadLetters_Disabled 测试未启用死信队列
+//
+// 【功能点】验证未启用死信队列时统计和重放均返回 ErrDeadLetterDisabled，且不建立连接
+// 【测试流程】以无法连接的地址调用 DeadLetterStats、ReplayDeadLetters，断言错误类型和报告中的队列名称
+func TestReplayDeadLetters_Disabled(t *testing.T) {
+	mq := &MessageQueue{QueueName: "order", MqConnStr: "amqp://invalid:1/"}
+
+	if _, err := mq.DeadLetterStats(context.Background()); !errors.Is(err, ErrDeadLetterDisabled) {
+		t.Errorf("期望 ErrDeadLetterDisabled, 实际: %v", err)
+	}
+	report, err := mq.ReplayDeadLetters(context.Background(), 10)
+	if !errors.Is(err, ErrDeadLetterDisabled) {
+		t.Errorf("期望 ErrDeadLetterDisabled, 实际: %v", err)
+	}
+	if report.Queue != "order" || report.DeadLetterQueue != "order.dlq" {
+		t.Errorf("报告中的队列名称错误: %+v", report)
+	}
+	if mq.Conn != nil {
+		t.Error("未启用死信队列时不应建立连接")
+	}
+}
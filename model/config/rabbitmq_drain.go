@@ -0,0 +1,105 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrProducerDraining 生产者正在关闭，不再接受新的发布
+var ErrProducerDraining = errors.New("生产者正在关闭，不再接受新的发布")
+
+// publishTracker 跟踪进行中的发布和等待确认的消息，用于优雅关闭
+type publishTracker struct {
+	mu       sync.Mutex
+	draining bool
+	inflight int
+	// idle Drain 等待时创建，进行中的发布全部结束时关闭
+	idle chan struct{}
+
+	// pendingConfirms 已发布但尚未收到确认的消息数（仅启用 Publisher Confirms 时统计）
+	pendingConfirms atomic.Int64
+	// unconfirmedAtClose 最近一次关闭时仍未收到确认的消息数
+	unconfirmedAtClose atomic.Int64
+}
+
+// beginPublish 登记一次发布，正在关闭时返回 ErrProducerDraining
+func (m *MessageQueue) beginPublish() error {
+	m.tracker.mu.Lock()
+	defer m.tracker.mu.Unlock()
+	if m.tracker.draining {
+		return fmt.Errorf("%w, queueInfo: %s", ErrProducerDraining, m.GetInfo())
+	}
+	m.tracker.inflight++
+	return nil
+}
+
+// endPublish 结束一次发布，最后一个进行中的发布结束时唤醒 Drain
+func (m *MessageQueue) endPublish() {
+	m.tracker.mu.Lock()
+	defer m.tracker.mu.Unlock()
+	m.tracker.inflight--
+	if m.tracker.inflight == 0 && m.tracker.idle != nil {
+		close(m.tracker.idle)
+		m.tracker.idle = nil
+	}
+}
+
+// Drain 优雅关闭生产者
+// 执行流程：
+//  1. 停止接受新的发布，之后调用 Publish* 返回 ErrProducerDraining
+//  2. 等待进行中的发布完成（启用 Publisher Confirms 时即等待其确认到达），直到 ctx 结束
+//  3. 调用 Close 关闭发布通道池、通道和连接，并记录此时仍未确认的消息数
+//
+// 参数：
+//   - ctx: 等待期限，通常为服务的关闭超时时间
+//
+// 返回：
+//   - error: ctx 结束时仍有发布未完成则返回错误，包含未确认的消息数；此时连接仍会被关闭
+func (m *MessageQueue) Drain(ctx context.Context) error {
+	m.tracker.mu.Lock()
+	m.tracker.draining = true
+	var idle chan struct{}
+	if m.tracker.inflight > 0 {
+		if m.tracker.idle == nil {
+			m.tracker.idle = make(chan struct{})
+		}
+		idle = m.tracker.idle
+	}
+	m.tracker.mu.Unlock()
+
+	var waitErr error
+	if idle != nil {
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			waitErr = ctx.Err()
+		}
+	}
+
+	m.Close()
+	if waitErr != nil {
+		return fmt.Errorf("等待发布确认超时, queueInfo: %s, 未确认消息数: %d: %w", m.GetInfo(), m.UnconfirmedAtClose(), waitErr)
+	}
+	return nil
+}
+
+// IsDraining 是否已开始优雅关闭
+func (m *MessageQueue) IsDraining() bool {
+	m.tracker.mu.Lock()
+	defer m.tracker.mu.Unlock()
+	return m.tracker.draining
+}
+
+// PendingConfirms 获取已发布但尚未收到确认的消息数
+func (m *MessageQueue) PendingConfirms() int64 {
+	return m.tracker.pendingConfirms.Load()
+}
+
+// UnconfirmedAtClose 获取最近一次关闭时仍未收到确认的消息数
+// 大于 0 表示这些消息的发布结果未知，可能已丢失
+func (m *MessageQueue) UnconfirmedAtClose() int64 {
+	return m.tracker.unconfirmedAtClose.Load()
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ==================== 单元测试文件（不需要 RabbitMQ 连接） ====================
+//
+// 本文件测试生产者优雅关闭（Drain），使用模拟的 publishChannel 替代真实的 AMQP 通道。
+// 这些测试主要验证：
+// - Drain 等待进行中的发布收到确认后再关闭，未确认消息数为 0
+// - Drain 开始后新的发布返回 ErrProducerDraining
+// - 确认未在期限内到达时 Drain 返回错误，并记录关闭时未确认的消息数
+// - 没有进行中的发布时 Drain 立即关闭
+
+// publishBurst 启动 n 个协程各发布一条消息，返回等待全部结束的函数
+func publishBurst(mq *MessageQueue, n int) func() []error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			if err := mq.Publish(fmt.Sprintf("message %d", id)); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(i)
+	}
+	return func() []error {
+		wg.Wait()
+		return errs
+	}
+}
+
+// waitPendingConfirms 等待已发布未确认的消息数达到 n
+func waitPendingConfirms(t *testing.T, mq *MessageQueue, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for mq.PendingConfirms() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待未确认消息数达到 %d 超时, 当前: %d", n, mq.PendingConfirms())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestDrain_WaitsForConfirms 测试 Drain 等待确认
+//
+// 【功能点】验证 Drain 等待进行中的发布收到确认后才关闭通道池，关闭后拒绝新的发布
+// 【测试流程】
+//  1. 确认延迟 50ms，4 个池化通道并发发布 4 条消息
+//  2. 所有消息发布后立即调用 Drain，断言返回 nil
+//  3. 断言所有发布成功、未确认消息数为 0、通道已关闭
+//  4. 再次发布，断言返回 ErrProducerDraining
+func TestDrain_WaitsForConfirms(t *testing.T) {
+	factory := &fakeChannelFactory{confirmDelay: 50 * time.Millisecond}
+	mq := newFakeProducer(4, true, factory)
+
+	wait := publishBurst(mq, 4)
+	waitPendingConfirms(t, mq, 4)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := mq.Drain(ctx); err != nil {
+		t.Fatalf("Drain 失败: %v", err)
+	}
+
+	if errs := wait(); len(errs) > 0 {
+		t.Errorf("Drain 期间发布失败: %v", errs)
+	}
+	if n := mq.UnconfirmedAtClose(); n != 0 {
+		t.Errorf("期望关闭时未确认消息数为 0, 实际: %d", n)
+	}
+	if !mq.IsDraining() {
+		t.Error("Drain 后 IsDraining 应为 true")
+	}
+	for i, ch := range factory.channels {
+		if !ch.IsClosed() {
+			t.Errorf("Drain 后通道 %d 应被关闭", i)
+		}
+	}
+
+	if err := mq.Publish("late"); !errors.Is(err, ErrProducerDraining) {
+		t.Errorf("期望 ErrProducerDraining, 实际: %v", err)
+	}
+	if err := mq.PublishBatch([]string{"late"}); !errors.Is(err, ErrProducerDraining) {
+		t.Errorf("批量发布期望 ErrProducerDraining, 实际: %v", err)
+	}
+}
+
+// TestDrain_Timeout 测试 Drain 超时
+//
+// 【功能点】验证确认未在期限内到达时 Drain 返回错误，并记录关闭时未确认的消息数
+// 【测试流程】
+//  1. 确认延迟 1s，并发发布 3 条消息
+//  2. 使用 50ms 期限调用 Drain，断言返回 context.DeadlineExceeded
+//  3. 断言 UnconfirmedAtClose 为 3，发布方返回错误
+func TestDrain_Timeout(t *testing.T) {
+	factory := &fakeChannelFactory{confirmDelay: time.Second}
+	mq := newFakeProducer(3, true, factory)
+	mq.PublishConfirm.Timeout = 200 * time.Millisecond
+
+	wait := publishBurst(mq, 3)
+	waitPendingConfirms(t, mq, 3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := mq.Drain(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("期望超时错误, 实际: %v", err)
+	}
+	if n := mq.UnconfirmedAtClose(); n != 3 {
+		t.Errorf("期望关闭时未确认消息数为 3, 实际: %d", n)
+	}
+
+	if errs := wait(); len(errs) != 3 {
+		t.Errorf("期望 3 条发布失败, 实际: %d", len(errs))
+	}
+	if n := mq.PendingConfirms(); n != 0 {
+		t.Errorf("发布结束后未确认消息数应为 0, 实际: %d", n)
+	}
+}
+
+// TestDrain_Idle 测试没有进行中发布时的 Drain
+//
+// 【功能点】验证没有进行中的发布时 Drain 立即关闭，即使 ctx 已结束也返回 nil
+// 【测试流程】发布一条消息后使用已取消的 ctx 调用 Drain，断言返回 nil 且通道已关闭
+func TestDrain_Idle(t *testing.T) {
+	factory := &fakeChannelFactory{}
+	mq := newFakeProducer(1, true, factory)
+	if err := mq.Publish("msg"); err != nil {
+		t.Fatalf("发布失败: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := mq.Drain(ctx); err != nil {
+		t.Errorf("Drain 失败: %v", err)
+	}
+	if !factory.channels[0].IsClosed() {
+		t.Error("Drain 后通道应被关闭")
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ExchangeBinding 交换机到交换机的绑定
+// 将 MessageQueue.ExchangeName（目标交换机）绑定到 SourceExchange，发布到源交换机的消息按 RoutingKey / Args 转发到目标交换机
+type ExchangeBinding struct {
+	// SourceExchange 源交换机名称
+	SourceExchange string
+	// SourceExchangeType 源交换机类型，为空时与 MessageQueue.ExchangeType 相同
+	SourceExchangeType string
+	// RoutingKey 绑定的路由键
+	RoutingKey string
+	// Args 绑定参数，源交换机为 headers 类型时用于匹配消息头，如 {"x-match": "all", "format": "pdf"}
+	Args amqp.Table
+}
+
+// declareExchangeBindings 声明源交换机，并将目标交换机绑定到源交换机
+// 在 ExchangeName 声明之后调用，未配置 ExchangeBindings 时不执行任何操作
+func (m *MessageQueue) declareExchangeBindings(ch *amqp.Channel) error {
+	if len(m.ExchangeBindings) == 0 {
+		return nil
+	}
+	queueInfo := m.GetInfo()
+	if m.ExchangeName == "" {
+		return fmt.Errorf("配置交换机绑定时 ExchangeName 不能为空, queueInfo: %s", queueInfo)
+	}
+
+	for _, binding := range m.ExchangeBindings {
+		sourceType := binding.SourceExchangeType
+		if sourceType == "" {
+			sourceType = m.ExchangeType
+		}
+		err := ch.ExchangeDeclare(
+			binding.SourceExchange, // name
+			sourceType,             // type
+			true,                   // durable
+			false,                  // auto-deleted
+			false,                  // internal
+			false,                  // no-wait
+			nil,                    // arguments
+		)
+		if err != nil {
+			return fmt.Errorf("声明源交换机失败: queueInfo: %s, source: %s, error: %w", queueInfo, binding.SourceExchange, err)
+		}
+
+		err = ch.ExchangeBind(
+			m.ExchangeName,         // destination
+			binding.RoutingKey,     // routing key
+			binding.SourceExchange, // source
+			false,                  // no-wait
+			binding.Args,           // arguments
+		)
+		if err != nil {
+			return fmt.Errorf("交换机绑定失败: queueInfo: %s, source: %s, error: %w", queueInfo, binding.SourceExchange, err)
+		}
+	}
+	return nil
+}
+
+// formatTable 将 amqp.Table 按键排序格式化为 "k1=v1,k2=v2"，用于日志和队列标识
+func formatTable(table amqp.Table) string {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var b strings.Builder
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%v", key, table[key])
+	}
+	return b.String()
+}
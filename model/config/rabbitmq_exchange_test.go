@@ -0,0 +1,73 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ==================== 单元测试文件（不需要 RabbitMQ 连接） ====================
+//
+// 本文件测试 headers 交换机绑定参数和交换机到交换机的绑定。
+// 这些测试主要验证：
+// - 配置 BindingArgs 时 GetInfo 追加按键排序的绑定参数，区分同一 headers 交换机上的不同队列
+// - 未配置 ExchangeBindings 时不执行任何操作，配置了但 ExchangeName 为空时返回错误
+//
+// 真实的 headers 路由和交换机链路由集成测试覆盖：
+// go test -tags=integration -v ./model/config/... -run "HeadersExchange|ExchangeBinding"
+
+// TestMessageQueue_GetInfo_BindingArgs 测试配置 BindingArgs 时的队列标识
+//
+// 【功能点】验证 GetInfo 追加按键排序的 BindingArgs，键的插入顺序不影响结果
+// 【测试流程】
+//  1. 构造两个 BindingArgs 键顺序不同的队列，断言 GetInfo 一致且包含排序后的参数
+//  2. 修改 x-match 后断言 GetInfo 不同
+func TestMessageQueue_GetInfo_BindingArgs(t *testing.T) {
+	newMQ := func(args amqp.Table) MessageQueue {
+		return MessageQueue{
+			MQName:       "mq",
+			QueueName:    "pdf-queue",
+			ExchangeName: "docs",
+			ExchangeType: "headers",
+			BindingArgs:  args,
+		}
+	}
+
+	a := newMQ(amqp.Table{"x-match": "all", "format": "pdf", "type": "report"})
+	b := newMQ(amqp.Table{"type": "report", "format": "pdf", "x-match": "all"})
+	expected := "mq_pdf-queue_docs_headers__{format=pdf,type=report,x-match=all}"
+	if got := a.GetInfo(); got != expected {
+		t.Errorf("GetInfo() = %v, want %v", got, expected)
+	}
+	if a.GetInfo() != b.GetInfo() {
+		t.Errorf("键顺序不同时 GetInfo 应一致: %s != %s", a.GetInfo(), b.GetInfo())
+	}
+
+	c := newMQ(amqp.Table{"x-match": "any", "format": "pdf", "type": "report"})
+	if a.GetInfo() == c.GetInfo() {
+		t.Errorf("x-match 不同时 GetInfo 应不同: %s", c.GetInfo())
+	}
+}
+
+// TestMessageQueue_DeclareExchangeBindings_Validate 测试交换机绑定的前置校验
+//
+// 【功能点】验证未配置 ExchangeBindings 时直接返回，ExchangeName 为空时返回错误（均不访问通道）
+// 【测试流程】
+//  1. 未配置 ExchangeBindings，传入 nil 通道，断言返回 nil
+//  2. 配置 ExchangeBindings 但 ExchangeName 为空，断言返回包含 ExchangeName 的错误
+func TestMessageQueue_DeclareExchangeBindings_Validate(t *testing.T) {
+	mq := MessageQueue{QueueName: "q", ExchangeName: "dest"}
+	if err := mq.declareExchangeBindings(nil); err != nil {
+		t.Errorf("未配置 ExchangeBindings 时应返回 nil, got %v", err)
+	}
+
+	mq = MessageQueue{
+		QueueName:        "q",
+		ExchangeBindings: []ExchangeBinding{{SourceExchange: "source", RoutingKey: "#"}},
+	}
+	err := mq.declareExchangeBindings(nil)
+	if err == nil || !strings.Contains(err.Error(), "ExchangeName") {
+		t.Errorf("ExchangeName 为空时应返回错误, got %v", err)
+	}
+}
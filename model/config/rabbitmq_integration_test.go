@@ -17,11 +17,19 @@ package config
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/zzsen/gin_core/exception/mq"
 )
 
 // ==================== 测试辅助函数 ====================
@@ -340,6 +348,83 @@ func TestIntegration_Consume_MultipleMessages(t *testing.T) {
 	}
 }
 
+// TestIntegration_BatchConsume_NoLoss 测试批量消费不丢消息
+// 需要 RabbitMQ 连接：涉及真实的消息发送和批量消费
+func TestIntegration_BatchConsume_NoLoss(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	queueName := generateQueueName("test-consume-batch")
+	numMessages := 25
+
+	var mu sync.Mutex
+	received := make(map[string]bool)
+	var failedOnce atomic.Bool
+
+	consumer := MessageQueue{
+		QueueName:    queueName,
+		ExchangeName: queueName + "-exchange",
+		ExchangeType: "direct",
+		RoutingKey:   queueName + "-key",
+		MqConnStr:    url,
+		ConsumeConfig: ConsumeConfig{
+			BatchSize:    10,
+			BatchTimeout: 200 * time.Millisecond,
+		},
+		BatchFun: func(ctx context.Context, msgs []string) error {
+			// 第一批失败一次，验证整批重新入队后不丢失
+			if failedOnce.CompareAndSwap(false, true) {
+				return errors.New("模拟批量写入失败")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, msg := range msgs {
+				received[msg] = true
+			}
+			return nil
+		},
+	}
+	defer consumer.Close()
+
+	producer := MessageQueue{
+		QueueName:    queueName,
+		ExchangeName: queueName + "-exchange",
+		ExchangeType: "direct",
+		RoutingKey:   queueName + "-key",
+		MqConnStr:    url,
+	}
+	defer producer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	go func() {
+		_ = consumer.ConsumeWithContext(ctx)
+	}()
+	time.Sleep(500 * time.Millisecond)
+
+	messages := make([]string, numMessages)
+	for i := range messages {
+		messages[i] = fmt.Sprintf("Batch message %d - %d", i+1, time.Now().UnixNano())
+	}
+	if err := producer.PublishBatch(messages); err != nil {
+		t.Fatalf("批量发送消息失败: %v", err)
+	}
+
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) && count() < numMessages {
+		time.Sleep(100 * time.Millisecond)
+	}
+	cancel()
+
+	if got := count(); got != numMessages {
+		t.Errorf("批量消费丢失消息: got %d, want %d", got, numMessages)
+	}
+}
+
 // TestIntegration_Consume_GracefulShutdown 测试消费者优雅关闭
 // 需要 RabbitMQ 连接：涉及真实的消息发送和消费
 func TestIntegration_Consume_GracefulShutdown(t *testing.T) {
@@ -499,6 +584,721 @@ func TestIntegration_DeadLetterQueue(t *testing.T) {
 	}
 }
 
+// TestIntegration_ReplayDeadLetters 测试死信重放
+// 需要 RabbitMQ 连接：消息进入死信队列后，修复处理函数并重放，消息被重新消费
+func TestIntegration_ReplayDeadLetters(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	queueName := generateQueueName("test-dlq-replay")
+	var fixed atomic.Bool
+	received := make(chan string, 1)
+	mq := &MessageQueue{
+		QueueName:    queueName,
+		ExchangeName: queueName + "-exchange",
+		ExchangeType: "direct",
+		RoutingKey:   queueName + "-key",
+		MqConnStr:    url,
+		DeadLetter:   DeadLetterConfig{Enabled: true},
+		FunWithCtx: func(ctx context.Context, msg string) error {
+			if !fixed.Load() {
+				return fmt.Errorf("模拟处理失败")
+			}
+			received <- msg
+			return nil
+		},
+	}
+	defer mq.Close()
+
+	// 声明主队列、死信交换机和死信队列
+	if err := mq.initChannel(); err != nil {
+		t.Fatalf("初始化通道失败: %v", err)
+	}
+	ch, err := mq.Conn.Channel()
+	if err != nil {
+		t.Fatalf("开启通道失败: %v", err)
+	}
+	defer ch.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	err = ch.PublishWithContext(ctx, mq.ExchangeName, mq.RoutingKey, false, false, amqp.Publishing{
+		Headers:   amqp.Table{"traceId": "trace-1"},
+		Body:      []byte("replay test message"),
+		MessageId: "replay-1",
+	})
+	if err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+
+	// 处理失败且重试次数耗尽时，消费者以 Nack(requeue=false) 拒绝消息，消息进入死信队列
+	msg := waitGet(t, ch, queueName)
+	if err := mq.FunWithCtx(ctx, string(msg.Body)); err == nil {
+		t.Fatal("期望处理失败")
+	}
+	if err := msg.Nack(false, false); err != nil {
+		t.Fatalf("拒绝消息失败: %v", err)
+	}
+	waitDeadLetters(t, mq, 1)
+
+	// 重放后消息回到主队列，保留业务消息头，移除 x-death
+	report, err := mq.ReplayDeadLetters(ctx, 10)
+	if err != nil {
+		t.Fatalf("重放失败: %v", err)
+	}
+	if report.Replayed != 1 || report.Failed != 0 {
+		t.Fatalf("期望重放成功 1 条, 实际: %+v", report)
+	}
+	waitDeadLetters(t, mq, 0)
+
+	msg = waitGet(t, ch, queueName)
+	if msg.Headers["traceId"] != "trace-1" || msg.MessageId != "replay-1" {
+		t.Errorf("消息头或属性未保留: headers: %v, messageId: %s", msg.Headers, msg.MessageId)
+	}
+	if _, ok := msg.Headers["x-death"]; ok {
+		t.Errorf("重放的消息不应包含 x-death: %v", msg.Headers)
+	}
+	if err := msg.Nack(false, true); err != nil {
+		t.Fatalf("退回消息失败: %v", err)
+	}
+
+	// 修复处理函数后启动消费者，重放的消息被成功消费
+	fixed.Store(true)
+	go func() {
+		_ = mq.ConsumeWithContext(ctx)
+	}()
+	select {
+	case body := <-received:
+		if body != "replay test message" {
+			t.Errorf("期望消息 replay test message, 实际: %s", body)
+		}
+	case <-ctx.Done():
+		t.Fatal("等待重放的消息被消费超时")
+	}
+}
+
+// waitGet 从队列中获取一条消息（不自动确认），超时则测试失败
+func waitGet(t *testing.T, ch *amqp.Channel, queue string) amqp.Delivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		msg, ok, err := ch.Get(queue, false)
+		if err != nil {
+			t.Fatalf("获取消息失败: %v", err)
+		}
+		if ok {
+			return msg
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("等待队列 %s 中的消息超时", queue)
+	return amqp.Delivery{}
+}
+
+// waitDeadLetters 等待死信队列中的消息数达到 n，超时则测试失败
+func waitDeadLetters(t *testing.T, mq *MessageQueue, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats, err := mq.DeadLetterStats(context.Background())
+		if err != nil {
+			t.Fatalf("获取死信队列统计失败: %v", err)
+		}
+		if stats.Messages == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("等待死信队列消息数达到 %d 超时, 当前: %d", n, stats.Messages)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// TestIntegration_TypedHandler_DeadLetter 测试类型化消费函数收到格式错误的消息
+// 需要 RabbitMQ 连接：FunWithCtx 为 mq.TypedHandler，MaxRetry 为 3，格式错误的消息不重试、直接进入死信队列，
+// 随后的合法消息被正常消费
+func TestIntegration_TypedHandler_DeadLetter(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	type orderPaid struct {
+		OrderID string `json:"orderId" binding:"required"`
+	}
+	queueName := generateQueueName("test-typed-dlq")
+	received := make(chan orderPaid, 1)
+	var decisions []RetryDecision
+	var decisionsLock sync.Mutex
+	consumer := &MessageQueue{
+		QueueName:    queueName,
+		ExchangeName: queueName + "-exchange",
+		ExchangeType: "direct",
+		RoutingKey:   queueName + "-key",
+		MqConnStr:    url,
+		DeadLetter:   DeadLetterConfig{Enabled: true},
+		ConsumeConfig: ConsumeConfig{
+			MaxRetry: 3,
+			OnRetryDecision: func(decision RetryDecision) {
+				decisionsLock.Lock()
+				defer decisionsLock.Unlock()
+				decisions = append(decisions, decision)
+			},
+		},
+		FunWithCtx: mq.TypedHandler(func(ctx context.Context, msg orderPaid) error {
+			received <- msg
+			return nil
+		}, mq.WithValidation()),
+	}
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	go func() {
+		_ = consumer.ConsumeWithContext(ctx)
+	}()
+	time.Sleep(1 * time.Second)
+
+	producer := &MessageQueue{
+		QueueName:    queueName,
+		ExchangeName: queueName + "-exchange",
+		ExchangeType: "direct",
+		RoutingKey:   queueName + "-key",
+		MqConnStr:    url,
+	}
+	defer producer.Close()
+	for _, body := range []string{`{"orderId":`, `{"amount":1}`, `{"orderId":"o-1"}`} {
+		if err := producer.Publish(body); err != nil {
+			t.Fatalf("发送消息失败: %v", err)
+		}
+	}
+
+	select {
+	case msg := <-received:
+		if msg.OrderID != "o-1" {
+			t.Errorf("期望消费 o-1, 实际: %+v", msg)
+		}
+	case <-ctx.Done():
+		t.Fatal("等待合法消息被消费超时")
+	}
+	waitDeadLetters(t, consumer, 2)
+
+	decisionsLock.Lock()
+	defer decisionsLock.Unlock()
+	if len(decisions) != 2 {
+		t.Fatalf("期望 2 次处理决定（格式错误和校验不通过各 1 次，不重试）, 实际: %+v", decisions)
+	}
+	for _, d := range decisions {
+		if d.Action != RetryActionDeadLetter || d.Attempts != 0 || !errors.Is(d.Err, mq.ErrDeadLetter) {
+			t.Errorf("处理决定不符合预期: %+v", d)
+		}
+	}
+	if stats := consumer.Stats(); stats.Retried != 0 || stats.DeadLettered != 2 {
+		t.Errorf("期望重试 0、进入死信队列 2, 实际: %+v", stats)
+	}
+}
+
+// TestIntegration_RetryAfter 测试延迟重试
+// 需要 RabbitMQ 连接：消费函数前两次返回 mq.ErrRetryAfter(1s)，验证消息经延迟队列约 1 秒后重新投递，
+// 重新投递时携带重试次数消息头，第三次消费成功
+func TestIntegration_RetryAfter(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	queueName := generateQueueName("test-retry-after")
+	type delivery struct {
+		at       time.Time
+		attempts any
+	}
+	deliveries := make(chan delivery, 3)
+	consumer := &MessageQueue{
+		QueueName:    queueName,
+		ExchangeName: queueName + "-exchange",
+		ExchangeType: "direct",
+		RoutingKey:   queueName + "-key",
+		MqConnStr:    url,
+		FunWithCtx: func(ctx context.Context, msg string) error {
+			return nil
+		},
+	}
+	var calls atomic.Int32
+	consumer.Use(func(next ConsumerHandlerFunc) ConsumerHandlerFunc {
+		return func(ctx context.Context, msg amqp.Delivery) error {
+			deliveries <- delivery{at: time.Now(), attempts: msg.Headers[RetryAttemptsHeader]}
+			if calls.Add(1) <= 2 {
+				return mq.ErrRetryAfter(time.Second)
+			}
+			return next(ctx, msg)
+		}
+	})
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	go func() {
+		_ = consumer.ConsumeWithContext(ctx)
+	}()
+	time.Sleep(1 * time.Second)
+
+	if err := consumer.Publish("retry after test message"); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+
+	var got []delivery
+	for len(got) < 3 {
+		select {
+		case d := <-deliveries:
+			got = append(got, d)
+		case <-ctx.Done():
+			t.Fatalf("等待重新投递超时, 已投递 %d 次", len(got))
+		}
+	}
+	for i := 1; i < len(got); i++ {
+		if gap := got[i].at.Sub(got[i-1].at); gap < 900*time.Millisecond || gap > 5*time.Second {
+			t.Errorf("第 %d 次投递与上一次间隔 %s，期望约 1s", i+1, gap)
+		}
+	}
+	if got[0].attempts != nil || got[1].attempts != int32(1) || got[2].attempts != int32(2) {
+		t.Errorf("重试次数消息头依次期望 <nil>、1、2，实际 %v、%v、%v", got[0].attempts, got[1].attempts, got[2].attempts)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats := consumer.Stats()
+		if stats.Succeeded == 1 && stats.Delayed == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("等待统计更新超时: %+v", stats)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// TestIntegration_RetryAfter_MaxRetry 测试延迟重试超过最大重试次数
+// 需要 RabbitMQ 连接：MaxRetry 为 1，消费函数始终返回 mq.ErrRetryAfter，验证延迟重试 1 次后消息进入死信队列
+func TestIntegration_RetryAfter_MaxRetry(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	queueName := generateQueueName("test-retry-after-max")
+	consumer := &MessageQueue{
+		QueueName:     queueName,
+		ExchangeName:  queueName + "-exchange",
+		ExchangeType:  "direct",
+		RoutingKey:    queueName + "-key",
+		MqConnStr:     url,
+		DeadLetter:    DeadLetterConfig{Enabled: true},
+		ConsumeConfig: ConsumeConfig{MaxRetry: 1},
+		FunWithCtx: func(ctx context.Context, msg string) error {
+			return mq.ErrRetryAfter(500 * time.Millisecond)
+		},
+	}
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	go func() {
+		_ = consumer.ConsumeWithContext(ctx)
+	}()
+	time.Sleep(1 * time.Second)
+
+	if err := consumer.Publish("retry after max test message"); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	waitDeadLetters(t, consumer, 1)
+	if stats := consumer.Stats(); stats.Delayed != 1 || stats.DeadLettered != 1 {
+		t.Errorf("期望延迟 1、超过重试次数 1，实际 %+v", stats)
+	}
+}
+
+// ==================== 集成测试：headers 交换机与交换机绑定（需要 RabbitMQ 连接） ====================
+// 测试点：验证 BindingArgs 按消息头路由、ExchangeBindings 交换机链路由和发布选项
+
+// TestIntegration_HeadersExchange 测试 headers 交换机按消息头路由
+// 需要 RabbitMQ 连接：两个队列分别以 x-match all / any 绑定到同一 headers 交换机，
+// 验证消息只投递到消息头匹配的队列
+func TestIntegration_HeadersExchange(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	exchangeName := generateQueueName("test-headers") + "-exchange"
+	newConsumer := func(queueName string, args amqp.Table) *MessageQueue {
+		mq := &MessageQueue{
+			QueueName:    queueName,
+			ExchangeName: exchangeName,
+			ExchangeType: "headers",
+			BindingArgs:  args,
+			MqConnStr:    url,
+		}
+		if err := mq.initChannel(); err != nil {
+			t.Fatalf("初始化消费者通道失败: %v", err)
+		}
+		t.Cleanup(mq.Close)
+		return mq
+	}
+	pdfQueue := generateQueueName("test-headers-pdf")
+	cnQueue := generateQueueName("test-headers-cn")
+	pdfConsumer := newConsumer(pdfQueue, amqp.Table{"x-match": "all", "format": "pdf", "type": "report"})
+	cnConsumer := newConsumer(cnQueue, amqp.Table{"x-match": "any", "region": "cn", "lang": "zh"})
+
+	producer := MessageQueue{ExchangeName: exchangeName, ExchangeType: "headers", MqConnStr: url}
+	defer producer.Close()
+
+	// 只匹配 pdf 队列（all），不匹配 cn 队列（any）
+	if err := producer.Publish("pdf-report", WithHeaders(amqp.Table{"format": "pdf", "type": "report", "region": "us"})); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	// 只匹配 cn 队列（any），缺少 type 不匹配 pdf 队列（all）
+	if err := producer.Publish("pdf-cn", WithHeaders(amqp.Table{"format": "pdf", "lang": "zh"})); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	// 两个队列都不匹配
+	if err := producer.Publish("none", WithHeaders(amqp.Table{"format": "doc"})); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+
+	if msg := waitGet(t, pdfConsumer.Channel, pdfQueue); string(msg.Body) != "pdf-report" {
+		t.Errorf("pdf 队列收到的消息 = %s, want pdf-report", msg.Body)
+	}
+	if msg := waitGet(t, cnConsumer.Channel, cnQueue); string(msg.Body) != "pdf-cn" {
+		t.Errorf("cn 队列收到的消息 = %s, want pdf-cn", msg.Body)
+	}
+
+	// 等待可能的错误投递到达后确认两个队列都已为空
+	time.Sleep(300 * time.Millisecond)
+	for _, c := range []struct {
+		mq    *MessageQueue
+		queue string
+	}{{pdfConsumer, pdfQueue}, {cnConsumer, cnQueue}} {
+		msg, ok, err := c.mq.Channel.Get(c.queue, true)
+		if err != nil {
+			t.Fatalf("获取消息失败: %v", err)
+		}
+		if ok {
+			t.Errorf("队列 %s 收到不匹配的消息: %s", c.queue, msg.Body)
+		}
+	}
+}
+
+// TestIntegration_ExchangeBinding 测试交换机到交换机的绑定
+// 需要 RabbitMQ 连接：消息发布到 topic 源交换机，经 ExchangeBindings 转发到 direct 目标交换机，
+// 由绑定在目标交换机上的消费者收到；源交换机上路由键不匹配的消息不会转发
+func TestIntegration_ExchangeBinding(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	queueName := generateQueueName("test-e2e-binding")
+	sourceExchange := queueName + "-source"
+	receivedChan := make(chan string, 2)
+
+	consumer := MessageQueue{
+		QueueName:    queueName,
+		ExchangeName: queueName + "-dest",
+		ExchangeType: "direct",
+		RoutingKey:   "order.created",
+		ExchangeBindings: []ExchangeBinding{
+			{SourceExchange: sourceExchange, SourceExchangeType: "topic", RoutingKey: "order.*"},
+		},
+		MqConnStr: url,
+		FunWithCtx: func(ctx context.Context, msg string) error {
+			receivedChan <- msg
+			return nil
+		},
+	}
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go func() {
+		_ = consumer.ConsumeWithContext(ctx)
+	}()
+	time.Sleep(500 * time.Millisecond)
+
+	newProducer := func(routingKey string) *MessageQueue {
+		mq := &MessageQueue{ExchangeName: sourceExchange, ExchangeType: "topic", RoutingKey: routingKey, MqConnStr: url}
+		t.Cleanup(mq.Close)
+		return mq
+	}
+	if err := newProducer("user.created").Publish("user"); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	if err := newProducer("order.created").Publish("order"); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+
+	select {
+	case received := <-receivedChan:
+		if received != "order" {
+			t.Errorf("接收到的消息 = %s, want order", received)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("接收消息超时")
+	}
+	select {
+	case received := <-receivedChan:
+		t.Errorf("不应收到路由键不匹配的消息: %s", received)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+// TestIntegration_PublishOptions 测试发布选项
+// 需要 RabbitMQ 连接：验证 WithHeaders、WithPriority、WithExpirationMs 设置的属性出现在收到的 Delivery 上
+func TestIntegration_PublishOptions(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	queueName := generateQueueName("test-publish-options")
+	mq := MessageQueue{
+		QueueName:    queueName,
+		ExchangeName: queueName + "-exchange",
+		ExchangeType: "direct",
+		RoutingKey:   queueName + "-key",
+		MqConnStr:    url,
+	}
+	defer mq.Close()
+	if err := mq.initChannel(); err != nil {
+		t.Fatalf("初始化通道失败: %v", err)
+	}
+
+	err := mq.PublishWithContext(context.Background(), "with-options",
+		WithHeaders(amqp.Table{"tenant": "t1", "attempt": int32(2)}),
+		WithPriority(5),
+		WithExpirationMs(60000),
+	)
+	if err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+
+	msg := waitGet(t, mq.Channel, queueName)
+	_ = msg.Ack(false)
+	if msg.Headers["tenant"] != "t1" || msg.Headers["attempt"] != int32(2) {
+		t.Errorf("Headers = %v, want tenant=t1, attempt=2", msg.Headers)
+	}
+	if msg.Priority != 5 {
+		t.Errorf("Priority = %d, want 5", msg.Priority)
+	}
+	if msg.Expiration != "60000" {
+		t.Errorf("Expiration = %q, want 60000", msg.Expiration)
+	}
+}
+
+// TestIntegration_MessageTTL 测试消息过期时间
+// 需要 RabbitMQ 连接：设置 WithTTL 的消息超时未被消费时从队列中移除，未设置的消息保留
+func TestIntegration_MessageTTL(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	queueName := generateQueueName("test-message-ttl")
+	mq := MessageQueue{
+		QueueName:    queueName,
+		ExchangeName: queueName + "-exchange",
+		ExchangeType: "direct",
+		RoutingKey:   queueName + "-key",
+		MqConnStr:    url,
+	}
+	defer mq.Close()
+	if err := mq.initChannel(); err != nil {
+		t.Fatalf("初始化通道失败: %v", err)
+	}
+
+	if err := mq.Publish("stale", WithTTL(200*time.Millisecond), WithPersistent(false)); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	if err := mq.Publish("durable"); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	msg := waitGet(t, mq.Channel, queueName)
+	_ = msg.Ack(false)
+	if string(msg.Body) != "durable" {
+		t.Errorf("收到的消息 = %q, want durable（过期的消息应已被移除）", msg.Body)
+	}
+	if _, ok, err := mq.Channel.Get(queueName, false); err != nil || ok {
+		t.Errorf("队列中不应还有消息, ok=%v, err=%v", ok, err)
+	}
+}
+
+// TestIntegration_PriorityQueue 测试优先级队列
+// 需要 RabbitMQ 连接：设置 MaxPriority 的队列按优先级投递积压的消息，批量发布时选项作用于每条消息，确认模式不受影响
+func TestIntegration_PriorityQueue(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	queueName := generateQueueName("test-priority")
+	mq := MessageQueue{
+		QueueName:      queueName,
+		ExchangeName:   queueName + "-exchange",
+		ExchangeType:   "direct",
+		RoutingKey:     queueName + "-key",
+		MqConnStr:      url,
+		MaxPriority:    10,
+		PublishConfirm: PublishConfirmConfig{Enabled: true, Timeout: 5 * time.Second},
+	}
+	defer mq.Close()
+	if err := mq.initChannel(); err != nil {
+		t.Fatalf("初始化通道失败: %v", err)
+	}
+
+	if err := mq.PublishBatch([]string{"low-1", "low-2"}, WithPriority(1)); err != nil {
+		t.Fatalf("批量发送消息失败: %v", err)
+	}
+	if err := mq.Publish("high", WithPriority(9), WithContentType("application/json"), WithMessageID("high-1")); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	if err := mq.Publish("medium", WithPriority(5)); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+
+	var order []string
+	for range 4 {
+		msg := waitGet(t, mq.Channel, queueName)
+		_ = msg.Ack(false)
+		order = append(order, string(msg.Body))
+		if string(msg.Body) == "high" && (msg.ContentType != "application/json" || msg.MessageId != "high-1") {
+			t.Errorf("ContentType = %q, MessageId = %q, want application/json, high-1", msg.ContentType, msg.MessageId)
+		}
+		if strings.HasPrefix(string(msg.Body), "low") && msg.Priority != 1 {
+			t.Errorf("批量发布的消息 %s 优先级 = %d, want 1", msg.Body, msg.Priority)
+		}
+	}
+	if want := []string{"high", "medium", "low-1", "low-2"}; !slices.Equal(order, want) {
+		t.Errorf("投递顺序 = %v, want %v", order, want)
+	}
+}
+
+// TestIntegration_ConsumerStats 测试消费者统计中的队列深度
+// 需要 RabbitMQ 连接：验证 RefreshQueueDepth 返回积压的消息数，消费期间定期查询的深度随消费降为 0
+func TestIntegration_ConsumerStats(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	queueName := generateQueueName("test-consumer-stats")
+	consumer := MessageQueue{
+		QueueName:     queueName,
+		ExchangeName:  queueName + "-exchange",
+		ExchangeType:  "direct",
+		RoutingKey:    queueName + "-key",
+		MqConnStr:     url,
+		ConsumeConfig: ConsumeConfig{QueueDepthInterval: 100 * time.Millisecond},
+		FunWithCtx: func(ctx context.Context, msg string) error {
+			return nil
+		},
+	}
+	defer consumer.Close()
+	if err := consumer.initChannel(); err != nil {
+		t.Fatalf("初始化通道失败: %v", err)
+	}
+
+	producer := MessageQueue{
+		QueueName:      queueName,
+		ExchangeName:   queueName + "-exchange",
+		ExchangeType:   "direct",
+		RoutingKey:     queueName + "-key",
+		MqConnStr:      url,
+		PublishConfirm: PublishConfirmConfig{Enabled: true, Timeout: 5 * time.Second},
+	}
+	defer producer.Close()
+	if err := producer.PublishBatch([]string{"a", "b", "c"}); err != nil {
+		t.Fatalf("批量发送消息失败: %v", err)
+	}
+
+	depth, err := consumer.RefreshQueueDepth(context.Background())
+	if err != nil {
+		t.Fatalf("获取队列深度失败: %v", err)
+	}
+	if depth != 3 {
+		t.Errorf("队列深度 = %d, want 3", depth)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go consumer.ConsumeWithContext(ctx)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats := consumer.Stats()
+		if stats.Succeeded == 3 && stats.QueueDepth == 0 && stats.QueueDepthAt != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("等待消费完成和队列深度更新超时: %+v", stats)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// TestIntegration_PauseResume 测试暂停和恢复消费者
+// 需要 RabbitMQ 连接：验证暂停期间新消息积压在队列中，恢复后积压的消息被消费完毕
+func TestIntegration_PauseResume(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	queueName := generateQueueName("test-pause-resume")
+	var consumed atomic.Int64
+	consumer := MessageQueue{
+		QueueName:    queueName,
+		ExchangeName: queueName + "-exchange",
+		ExchangeType: "direct",
+		RoutingKey:   queueName + "-key",
+		MqConnStr:    url,
+		FunWithCtx: func(ctx context.Context, msg string) error {
+			consumed.Add(1)
+			return nil
+		},
+	}
+	defer consumer.Close()
+
+	producer := MessageQueue{
+		QueueName:      queueName,
+		ExchangeName:   queueName + "-exchange",
+		ExchangeType:   "direct",
+		RoutingKey:     queueName + "-key",
+		MqConnStr:      url,
+		PublishConfirm: PublishConfirmConfig{Enabled: true, Timeout: 5 * time.Second},
+	}
+	defer producer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go consumer.ConsumeWithContext(ctx)
+
+	waitDepth := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			depth, err := consumer.RefreshQueueDepth(context.Background())
+			if err == nil && depth == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("等待队列深度为 %d 超时，实际 %d, error: %v", want, depth, err)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
+	if err := producer.Publish("before-pause"); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for consumed.Load() < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("等待消费暂停前的消息超时")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := consumer.Pause(); err != nil {
+		t.Fatalf("暂停消费者失败: %v", err)
+	}
+	if err := producer.PublishBatch([]string{"a", "b", "c", "d", "e"}); err != nil {
+		t.Fatalf("批量发送消息失败: %v", err)
+	}
+	waitDepth(5)
+	if got := consumed.Load(); got != 1 {
+		t.Errorf("暂停期间不应消费消息，实际消费 %d 条", got)
+	}
+
+	consumer.Resume()
+	waitDepth(0)
+	deadline = time.Now().Add(5 * time.Second)
+	for consumed.Load() < 6 {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待恢复后消费超时，实际消费 %d 条", consumed.Load())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
 // ==================== 集成测试：JSON 消息（需要 RabbitMQ 连接） ====================
 // 测试点：验证 JSON 格式消息的发送和解析
 
@@ -635,6 +1435,194 @@ func TestIntegration_ConcurrentPublish(t *testing.T) {
 	}
 }
 
+// TestIntegration_ConcurrentPublish_ChannelPool 测试发布通道池下的高并发发送
+// 需要 RabbitMQ 连接：100 个协程共享同一发送者，启用 Publisher Confirms
+func TestIntegration_ConcurrentPublish_ChannelPool(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	queueName := generateQueueName("test-channel-pool")
+	numGoroutines := 100
+	messagesPerGoroutine := 10
+
+	newProducer := func(poolSize int) *MessageQueue {
+		return &MessageQueue{
+			QueueName:                queueName,
+			ExchangeName:             queueName + "-exchange",
+			ExchangeType:             "direct",
+			RoutingKey:               queueName + "-key",
+			MqConnStr:                url,
+			PublisherChannelPoolSize: poolSize,
+			PublishConfirm: PublishConfirmConfig{
+				Enabled: true,
+				Timeout: 10 * time.Second,
+			},
+		}
+	}
+
+	publish := func(producer *MessageQueue) (time.Duration, []error) {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var errs []error
+		start := time.Now()
+		for i := 0; i < numGoroutines; i++ {
+			wg.Add(1)
+			go func(goroutineID int) {
+				defer wg.Done()
+				for j := 0; j < messagesPerGoroutine; j++ {
+					if err := producer.Publish(fmt.Sprintf("Goroutine %d, Message %d", goroutineID, j)); err != nil {
+						mu.Lock()
+						errs = append(errs, err)
+						mu.Unlock()
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+		return time.Since(start), errs
+	}
+
+	single := newProducer(1)
+	defer single.Close()
+	singleCost, errs := publish(single)
+	if len(errs) > 0 {
+		t.Fatalf("单通道发送出现 %d 个错误, 第一个错误: %v", len(errs), errs[0])
+	}
+
+	pooled := newProducer(8)
+	defer pooled.Close()
+	pooledCost, errs := publish(pooled)
+	if len(errs) > 0 {
+		t.Fatalf("通道池发送出现 %d 个错误, 第一个错误: %v", len(errs), errs[0])
+	}
+
+	stats := pooled.PublisherPoolStats()
+	t.Logf("单通道耗时: %v, 通道池耗时: %v, 通道池统计: %+v", singleCost, pooledCost, stats)
+	if stats.Open > 8 {
+		t.Errorf("通道数 %d 超过池容量 8", stats.Open)
+	}
+	if pooledCost >= singleCost {
+		t.Errorf("通道池发送耗时 %v 未低于单通道 %v", pooledCost, singleCost)
+	}
+}
+
+// TestIntegration_Drain 测试生产者优雅关闭
+// 需要 RabbitMQ 连接：启用 Publisher Confirms 并发发布一批消息后立即 Drain，
+// 要么所有确认都已到达，要么返回错误并记录关闭时未确认的消息数
+func TestIntegration_Drain(t *testing.T) {
+	url := requireRabbitMQ(t)
+
+	queueName := generateQueueName("test-drain")
+	producer := &MessageQueue{
+		QueueName:                queueName,
+		ExchangeName:             queueName + "-exchange",
+		ExchangeType:             "direct",
+		RoutingKey:               queueName + "-key",
+		MqConnStr:                url,
+		PublisherChannelPoolSize: 8,
+		PublishConfirm: PublishConfirmConfig{
+			Enabled: true,
+			Timeout: 10 * time.Second,
+		},
+	}
+	if err := producer.Publish("warm up"); err != nil {
+		t.Fatalf("发布失败: %v", err)
+	}
+
+	numMessages := 200
+	var wg sync.WaitGroup
+	var succeeded, rejected int32
+	for i := 0; i < numMessages; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			err := producer.Publish(fmt.Sprintf("Drain Message %d", id))
+			switch {
+			case err == nil:
+				atomic.AddInt32(&succeeded, 1)
+			case errors.Is(err, ErrProducerDraining):
+				atomic.AddInt32(&rejected, 1)
+			}
+		}(i)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	drainErr := producer.Drain(ctx)
+	wg.Wait()
+
+	unconfirmed := producer.UnconfirmedAtClose()
+	t.Logf("成功: %d, 被拒绝: %d, 关闭时未确认: %d, Drain 错误: %v", succeeded, rejected, unconfirmed, drainErr)
+	if drainErr == nil {
+		if unconfirmed != 0 {
+			t.Errorf("Drain 成功时未确认消息数应为 0, 实际: %d", unconfirmed)
+		}
+		if int(succeeded+rejected) != numMessages {
+			t.Errorf("Drain 成功时所有发布应已确认或被拒绝, 成功: %d, 被拒绝: %d", succeeded, rejected)
+		}
+	} else if unconfirmed == 0 {
+		t.Errorf("Drain 超时时应记录未确认消息数, error: %v", drainErr)
+	}
+
+	if err := producer.Publish("after drain"); !errors.Is(err, ErrProducerDraining) {
+		t.Errorf("期望 ErrProducerDraining, 实际: %v", err)
+	}
+}
+
+// ==================== 集成测试：TLS 连接（需要开启 TLS 的 RabbitMQ） ====================
+// 测试点：验证通过 amqps 和客户端证书连接到指定虚拟主机并收发消息
+
+// TestIntegration_TLS 测试 TLS 连接
+// 需要开启 TLS 的 RabbitMQ：通过环境变量 RABBITMQ_TLS_HOST、RABBITMQ_TLS_PORT（默认 5671）、RABBITMQ_TLS_VHOST、
+// RABBITMQ_TLS_CA_CERT、RABBITMQ_TLS_CERT、RABBITMQ_TLS_KEY 指定，用户名和密码与普通连接相同；未设置 RABBITMQ_TLS_HOST 时跳过
+func TestIntegration_TLS(t *testing.T) {
+	host := os.Getenv("RABBITMQ_TLS_HOST")
+	if host == "" {
+		t.Skip("未设置 RABBITMQ_TLS_HOST, 跳过 TLS 连接测试")
+	}
+	port := 5671
+	if p, err := strconv.Atoi(os.Getenv("RABBITMQ_TLS_PORT")); err == nil {
+		port = p
+	}
+	info := RabbitMQInfo{
+		Host:       host,
+		Port:       port,
+		Username:   configTestRabbitMQUsername,
+		Password:   configTestRabbitMQPassword,
+		Vhost:      os.Getenv("RABBITMQ_TLS_VHOST"),
+		UseTLS:     true,
+		CACertFile: os.Getenv("RABBITMQ_TLS_CA_CERT"),
+		CertFile:   os.Getenv("RABBITMQ_TLS_CERT"),
+		KeyFile:    os.Getenv("RABBITMQ_TLS_KEY"),
+	}
+	tlsConfig, err := info.TLSConfig()
+	if err != nil {
+		t.Fatalf("生成 TLS 配置失败: %v", err)
+	}
+
+	queueName := generateQueueName("test-tls")
+	mq := &MessageQueue{
+		QueueName:    queueName,
+		ExchangeName: queueName + "-exchange",
+		ExchangeType: "direct",
+		RoutingKey:   queueName + "-key",
+		MqConnStr:    info.Url(),
+		TLSConfig:    tlsConfig,
+	}
+	defer mq.Close()
+	if err := mq.initChannel(); err != nil {
+		t.Fatalf("TLS 连接失败 (%s): %v", info.Url(), err)
+	}
+	defer mq.Channel.QueueDelete(queueName, false, false, false)
+
+	if err := mq.Publish("TLS Message"); err != nil {
+		t.Fatalf("发布失败: %v", err)
+	}
+	msg := waitGet(t, mq.Channel, queueName)
+	if string(msg.Body) != "TLS Message" {
+		t.Errorf("期望消息内容 %q, 实际 %q", "TLS Message", string(msg.Body))
+	}
+}
+
 // ==================== 集成测试：基准测试（需要 RabbitMQ 连接） ====================
 // 测试点：验证消息发送的性能
 
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"context"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ConsumerHandlerFunc 消息消费处理函数，返回错误时消息按重试规则拒绝（超过重试次数后进入死信队列）
+type ConsumerHandlerFunc func(ctx context.Context, msg amqp.Delivery) error
+
+// ConsumerMiddleware 消息消费中间件，包装下一个处理函数，用于统一处理日志、指标、异常恢复等
+//
+// 使用示例：
+//
+//	func metricsMiddleware(next config.ConsumerHandlerFunc) config.ConsumerHandlerFunc {
+//		return func(ctx context.Context, msg amqp.Delivery) error {
+//			start := time.Now()
+//			err := next(ctx, msg)
+//			observe(config.ConsumerQueueName(ctx), time.Since(start), err)
+//			return err
+//		}
+//	}
+type ConsumerMiddleware func(next ConsumerHandlerFunc) ConsumerHandlerFunc
+
+// consumerQueueKey 消费上下文中队列名称的键
+type consumerQueueKey struct{}
+
+// ConsumerQueueName 获取消费上下文中的队列名称，不是消费上下文时返回空字符串
+// 参数：
+//   - ctx: 消费中间件或处理函数收到的上下文
+//
+// 返回：
+//   - string: 队列名称
+func ConsumerQueueName(ctx context.Context) string {
+	name, _ := ctx.Value(consumerQueueKey{}).(string)
+	return name
+}
+
+// Use 添加消费中间件，按添加顺序执行（先添加的在外层）
+// 中间件同时作用于 FunWithCtx 和 Fun，不作用于 BatchFun；
+// 通过 core.UseConsumerMiddleware 注册的全局中间件在启动时加到最前面
+// 参数：
+//   - mw: 消费中间件
+func (m *MessageQueue) Use(mw ...ConsumerMiddleware) {
+	m.Middlewares = append(m.Middlewares, mw...)
+}
+
+// consumerHandler 组合消费中间件和消费函数，优先使用 FunWithCtx
+// 返回：
+//   - ConsumerHandlerFunc: 组合后的处理函数，未设置消费函数时为 nil
+func (m *MessageQueue) consumerHandler() ConsumerHandlerFunc {
+	var handler ConsumerHandlerFunc
+	if m.FunWithCtx != nil {
+		fun := m.FunWithCtx
+		handler = func(ctx context.Context, msg amqp.Delivery) error {
+			return fun(ctx, string(msg.Body))
+		}
+	} else if m.Fun != nil {
+		fun := m.Fun
+		handler = func(ctx context.Context, msg amqp.Delivery) error {
+			return fun(string(msg.Body))
+		}
+	} else {
+		return nil
+	}
+
+	for i := len(m.Middlewares) - 1; i >= 0; i-- {
+		handler = m.Middlewares[i](handler)
+	}
+	queueName := m.QueueName
+	next := handler
+	return func(ctx context.Context, msg amqp.Delivery) error {
+		return next(context.WithValue(ctx, consumerQueueKey{}, queueName), msg)
+	}
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ==================== 单元测试文件（不需要 RabbitMQ 连接） ====================
+//
+// 本文件测试消费中间件，使用模拟的 Acknowledger 构造消息投递驱动 handleMessage。
+// 这些测试主要验证：
+// - 中间件按添加顺序执行，Middlewares 字段与 Use 添加的中间件合并
+// - 中间件同时作用于 FunWithCtx 和 Fun
+// - 中间件返回错误时消息按重试规则拒绝，中间件可以短路消费函数
+// - 中间件中可以通过 ConsumerQueueName 获取队列名称
+
+// recordMiddleware 记录执行顺序的消费中间件
+func recordMiddleware(name string, seq *[]string) ConsumerMiddleware {
+	return func(next ConsumerHandlerFunc) ConsumerHandlerFunc {
+		return func(ctx context.Context, msg amqp.Delivery) error {
+			*seq = append(*seq, name+":before")
+			err := next(ctx, msg)
+			*seq = append(*seq, name+":after")
+			return err
+		}
+	}
+}
+
+// TestMessageQueue_Middleware_Order 测试中间件执行顺序
+//
+// 【功能点】验证中间件按添加顺序包装消费函数，FunWithCtx 和 Fun 都经过中间件
+// 【测试流程】
+//  1. Middlewares 字段设置 a，再通过 Use 添加 b、c
+//  2. 分别以 FunWithCtx、Fun 作为消费函数处理一条消息
+//  3. 断言执行顺序为 a、b、c、消费函数、c、b、a，消息被确认
+func TestMessageQueue_Middleware_Order(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(mq *MessageQueue, seq *[]string)
+	}{
+		{"FunWithCtx", func(mq *MessageQueue, seq *[]string) {
+			mq.FunWithCtx = func(ctx context.Context, msg string) error {
+				*seq = append(*seq, "handler:"+msg)
+				return nil
+			}
+		}},
+		{"Fun", func(mq *MessageQueue, seq *[]string) {
+			mq.Fun = func(msg string) error {
+				*seq = append(*seq, "handler:"+msg)
+				return nil
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seq []string
+			mq := &MessageQueue{QueueName: "order", Middlewares: []ConsumerMiddleware{recordMiddleware("a", &seq)}}
+			mq.Use(recordMiddleware("b", &seq), recordMiddleware("c", &seq))
+			tt.setup(mq, &seq)
+
+			ack := &fakeAcknowledger{}
+			mq.handleMessage(context.Background(), newDelivery(ack, "m1", "hello"))
+
+			want := []string{"a:before", "b:before", "c:before", "handler:hello", "c:after", "b:after", "a:after"}
+			if len(seq) != len(want) {
+				t.Fatalf("执行顺序 = %v, 期望 %v", seq, want)
+			}
+			for i := range want {
+				if seq[i] != want[i] {
+					t.Fatalf("执行顺序 = %v, 期望 %v", seq, want)
+				}
+			}
+			if ack.acks != 1 || ack.nacks != 0 {
+				t.Errorf("acks = %d, nacks = %d, 期望 1, 0", ack.acks, ack.nacks)
+			}
+		})
+	}
+}
+
+// TestMessageQueue_Middleware_ErrorRetry 测试中间件返回错误
+//
+// 【功能点】验证中间件超时取消消费函数并返回错误时，消息按重试规则重新入队
+// 【测试流程】
+//  1. 添加超时为 20ms 的中间件，消费函数等待上下文取消
+//  2. 处理一条消息，断言消费函数收到 DeadlineExceeded，消息被拒绝并重新入队
+//  3. 添加直接返回错误的中间件，断言消费函数未执行，消息被拒绝
+func TestMessageQueue_Middleware_ErrorRetry(t *testing.T) {
+	var handlerErr error
+	mq := &MessageQueue{
+		QueueName: "order",
+		FunWithCtx: func(ctx context.Context, msg string) error {
+			select {
+			case <-ctx.Done():
+				handlerErr = ctx.Err()
+				return handlerErr
+			case <-time.After(time.Second):
+				return nil
+			}
+		},
+	}
+	mq.Use(func(next ConsumerHandlerFunc) ConsumerHandlerFunc {
+		return func(ctx context.Context, msg amqp.Delivery) error {
+			ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			return next(ctx, msg)
+		}
+	})
+
+	ack := &fakeAcknowledger{}
+	mq.handleMessage(context.Background(), newDelivery(ack, "m1", "slow"))
+	if !errors.Is(handlerErr, context.DeadlineExceeded) {
+		t.Errorf("消费函数收到的错误 = %v, 期望 DeadlineExceeded", handlerErr)
+	}
+	if ack.nacks != 1 || ack.requeue != 1 {
+		t.Errorf("nacks = %d, requeue = %d, 期望 1, 1", ack.nacks, ack.requeue)
+	}
+
+	called := false
+	short := &MessageQueue{
+		QueueName:  "order",
+		FunWithCtx: func(ctx context.Context, msg string) error { called = true; return nil },
+		Middlewares: []ConsumerMiddleware{func(next ConsumerHandlerFunc) ConsumerHandlerFunc {
+			return func(ctx context.Context, msg amqp.Delivery) error { return errors.New("rejected") }
+		}},
+	}
+	ack = &fakeAcknowledger{}
+	short.handleMessage(context.Background(), newDelivery(ack, "m2", "x"))
+	if called {
+		t.Error("中间件短路时不应执行消费函数")
+	}
+	if ack.nacks != 1 {
+		t.Errorf("nacks = %d, 期望 1", ack.nacks)
+	}
+}
+
+// TestMessageQueue_Middleware_QueueName 测试获取队列名称
+//
+// 【功能点】验证中间件和消费函数可以通过 ConsumerQueueName 获取队列名称，非消费上下文返回空字符串
+// 【测试流程】处理一条消息，断言中间件和消费函数获取到的队列名称为 order
+func TestMessageQueue_Middleware_QueueName(t *testing.T) {
+	var fromMiddleware, fromHandler string
+	mq := &MessageQueue{
+		QueueName: "order",
+		FunWithCtx: func(ctx context.Context, msg string) error {
+			fromHandler = ConsumerQueueName(ctx)
+			return nil
+		},
+	}
+	mq.Use(func(next ConsumerHandlerFunc) ConsumerHandlerFunc {
+		return func(ctx context.Context, msg amqp.Delivery) error {
+			fromMiddleware = ConsumerQueueName(ctx)
+			return next(ctx, msg)
+		}
+	})
+
+	mq.handleMessage(context.Background(), newDelivery(&fakeAcknowledger{}, "m1", "x"))
+	if fromMiddleware != "order" || fromHandler != "order" {
+		t.Errorf("队列名称 = %q, %q, 期望 order", fromMiddleware, fromHandler)
+	}
+	if got := ConsumerQueueName(context.Background()); got != "" {
+		t.Errorf("非消费上下文的队列名称 = %q, 期望空字符串", got)
+	}
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// 消费者状态
+const (
+	ConsumerStateRunning = "running" // 正在消费（或正在连接）
+	ConsumerStatePaused  = "paused"  // 已暂停
+)
+
+// ConsumerState 消费者的暂停状态
+type ConsumerState struct {
+	QueueInfo string     `json:"queueInfo"`          // 队列唯一标识，格式同 GetInfo
+	State     string     `json:"state"`              // running / paused
+	InFlight  int64      `json:"inFlight"`           // 正在处理的消息数，暂停后降为 0 表示已取出的消息处理完毕
+	PausedAt  *time.Time `json:"pausedAt,omitempty"` // 暂停时间，运行中为空
+}
+
+// errConsumerCanceled 消费者因暂停被取消，消费循环处理完已取出的消息后等待恢复
+var errConsumerCanceled = errors.New("消费者已暂停")
+
+// consumeChannel 消费通道接口
+// 抽象 *amqp.Channel 中注册和取消消费者的能力，便于在单元测试中替换为模拟实现
+type consumeChannel interface {
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Cancel(consumer string, noWait bool) error
+}
+
+// consumerControl 消费者的暂停 / 恢复状态
+// 保存在 MessageQueue 中，消费循环因断线退出并重连后状态不变，已暂停的消费者重连后仍保持暂停
+type consumerControl struct {
+	mu       sync.Mutex
+	paused   bool
+	pausedAt time.Time
+	// resumed 恢复时关闭，唤醒等待恢复的消费循环
+	resumed chan struct{}
+	// active 当前注册了消费者的通道，未注册时为 nil
+	active consumeChannel
+	// tag 当前注册的消费者标签
+	tag string
+	// canceled 当前注册的消费者已因暂停被取消
+	canceled bool
+}
+
+// Pause 暂停消费
+// 取消通道上的消费者（basic.cancel），连接和通道保持不变；已取出的消息继续处理并正常确认或拒绝，
+// 未取出的消息留在队列中。消费者未在运行时只记录状态，启动或重连后保持暂停
+//
+// 返回：
+//   - error: 取消消费者失败时返回错误（通常是通道已关闭，消费者重连后保持暂停），状态仍为已暂停
+func (m *MessageQueue) Pause() error {
+	c := &m.control
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		return nil
+	}
+	c.paused, c.pausedAt = true, time.Now()
+	if c.active == nil || c.canceled {
+		return nil
+	}
+	if err := c.active.Cancel(c.tag, false); err != nil {
+		return fmt.Errorf("取消消费者失败, queueInfo: %s, error: %w", m.GetInfo(), err)
+	}
+	c.canceled = true
+	return nil
+}
+
+// Resume 恢复消费，在同一通道上重新注册消费者
+func (m *MessageQueue) Resume() {
+	c := &m.control
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		return
+	}
+	c.paused, c.pausedAt = false, time.Time{}
+	if c.resumed != nil {
+		close(c.resumed)
+		c.resumed = nil
+	}
+}
+
+// Paused 是否已暂停
+func (m *MessageQueue) Paused() bool {
+	m.control.mu.Lock()
+	defer m.control.mu.Unlock()
+	return m.control.paused
+}
+
+// ConsumerState 获取消费者的暂停状态
+func (m *MessageQueue) ConsumerState() ConsumerState {
+	state := ConsumerState{
+		QueueInfo: m.GetInfo(),
+		State:     ConsumerStateRunning,
+		InFlight:  m.stats.inFlight.Load(),
+	}
+	m.control.mu.Lock()
+	defer m.control.mu.Unlock()
+	if m.control.paused {
+		pausedAt := m.control.pausedAt
+		state.State, state.PausedAt = ConsumerStatePaused, &pausedAt
+	}
+	return state
+}
+
+// consumeLoop 注册消费者并调用 consume 消费，暂停时等待恢复后重新注册
+// 参数：
+//   - ctx: context，取消后返回 nil
+//   - ch: 消费通道
+//   - notifyClose: 通道关闭通知
+//   - consume: 消费循环（逐条或批量），消费者因暂停被取消时返回 errConsumerCanceled
+//
+// 返回：
+//   - error: 注册消费者失败、通道关闭时返回错误，由调用方重连
+func (m *MessageQueue) consumeLoop(ctx context.Context, ch consumeChannel, notifyClose <-chan *amqp.Error,
+	consume func(ctx context.Context, msgs <-chan amqp.Delivery, notifyClose <-chan *amqp.Error) error) error {
+	queueInfo := m.GetInfo()
+	for {
+		msgs, resumed, err := m.registerConsumer(ch)
+		if err != nil {
+			return fmt.Errorf("注册消费者失败: queueInfo: %s, error: %w", queueInfo, err)
+		}
+		if resumed != nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-resumed:
+				continue
+			case <-notifyClose:
+				return fmt.Errorf("连接失败, queueInfo: %s", queueInfo)
+			}
+		}
+
+		err = consume(ctx, msgs, notifyClose)
+		m.deactivateConsumer()
+		if !errors.Is(err, errConsumerCanceled) {
+			return err
+		}
+	}
+}
+
+// registerConsumer 未暂停时以新的消费者标签注册消费者；已暂停时不注册，返回恢复时关闭的通道
+func (m *MessageQueue) registerConsumer(ch consumeChannel) (<-chan amqp.Delivery, <-chan struct{}, error) {
+	c := &m.control
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		if c.resumed == nil {
+			c.resumed = make(chan struct{})
+		}
+		return nil, c.resumed, nil
+	}
+	tag := "gin_core-" + uuid.NewString()
+	msgs, err := ch.Consume(
+		m.QueueName, // queue
+		tag,         // consumer
+		false,       // auto-ack
+		false,       // exclusive
+		false,       // no-local
+		false,       // no-wait
+		nil,         // args
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.active, c.tag, c.canceled = ch, tag, false
+	return msgs, nil, nil
+}
+
+// deactivateConsumer 消费循环退出后清除当前注册的消费者
+func (m *MessageQueue) deactivateConsumer() {
+	c := &m.control
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active, c.tag, c.canceled = nil, "", false
+}
+
+// deliveriesClosed 消息通道关闭时的返回值：因暂停取消消费者时为 errConsumerCanceled，否则为通道关闭错误
+func (m *MessageQueue) deliveriesClosed() error {
+	m.control.mu.Lock()
+	defer m.control.mu.Unlock()
+	if m.control.canceled {
+		return errConsumerCanceled
+	}
+	return fmt.Errorf("消息通道已关闭, queueInfo: %s", m.GetInfo())
+}
+
+// consumeMessages 逐条消费循环，context 取消时返回 nil
+func (m *MessageQueue) consumeMessages(ctx context.Context, msgs <-chan amqp.Delivery, notifyClose <-chan *amqp.Error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			// context 被取消，优雅关闭
+			return nil
+		case msg, ok := <-msgs:
+			if !ok {
+				return m.deliveriesClosed()
+			}
+			m.handleMessage(ctx, msg)
+		case <-notifyClose:
+			return fmt.Errorf("连接失败, queueInfo: %s", m.GetInfo())
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ==================== 单元测试文件（不需要 RabbitMQ 连接） ====================
+//
+// 本文件测试消费者的暂停与恢复，使用模拟的消费通道驱动 consumeLoop。
+// 这些测试主要验证：
+// - 暂停时取消消费者，已取出的消息处理完毕并确认，不再注册消费者
+// - 恢复时以新的消费者标签重新注册
+// - 启动前或断线重连后保持暂停状态
+// - 批量消费暂停时先处理已攒的消息
+// - 取消消费者失败时仍记录暂停状态
+
+// fakeConsumeChannel 模拟的消费通道，Cancel 时关闭对应的消息通道，已缓冲的消息仍可读取
+type fakeConsumeChannel struct {
+	mu        sync.Mutex
+	tags      []string
+	deliver   chan amqp.Delivery
+	cancelErr error
+}
+
+func (f *fakeConsumeChannel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tags = append(f.tags, consumer)
+	f.deliver = make(chan amqp.Delivery, 100)
+	return f.deliver, nil
+}
+
+func (f *fakeConsumeChannel) Cancel(consumer string, noWait bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cancelErr != nil {
+		return f.cancelErr
+	}
+	if len(f.tags) > 0 && f.tags[len(f.tags)-1] == consumer && f.deliver != nil {
+		close(f.deliver)
+		f.deliver = nil
+	}
+	return nil
+}
+
+// consumed 返回已注册的消费者标签
+func (f *fakeConsumeChannel) consumed() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.tags...)
+}
+
+// send 向当前注册的消费者投递消息
+func (f *fakeConsumeChannel) send(ack amqp.Acknowledger, tags ...uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, tag := range tags {
+		f.deliver <- amqp.Delivery{Acknowledger: ack, DeliveryTag: tag}
+	}
+}
+
+// startConsumeLoop 使用模拟的消费通道启动消费循环，返回消费循环的退出结果
+func startConsumeLoop(ctx context.Context, m *MessageQueue, ch *fakeConsumeChannel, notifyClose <-chan *amqp.Error) <-chan error {
+	consume := m.consumeMessages
+	if m.BatchFun != nil {
+		consume = m.consumeBatches
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- m.consumeLoop(ctx, ch, notifyClose, consume)
+	}()
+	return done
+}
+
+// waitConsumed 等待注册指定数量的消费者
+func waitConsumed(t *testing.T, ch *fakeConsumeChannel, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(ch.consumed()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待注册 %d 个消费者超时，实际 %d 个", n, len(ch.consumed()))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// ==================== 单元测试：暂停与恢复（不需要 RabbitMQ 连接） ====================
+// 测试点：验证 consumeLoop 的暂停、恢复和重连状态
+
+// TestMessageQueue_Pause_DrainsAndResumes 测试暂停后处理完已取出的消息，恢复后重新注册
+//
+// 【功能点】验证暂停时取消消费者，正在处理和已缓冲的消息均被确认，恢复后以新标签注册消费者
+// 【测试流程】
+//  1. 启动消费循环，投递 3 条消息，第一条处理中时暂停
+//  2. 断言状态为 paused 且记录暂停时间，放行处理后 3 条消息均被确认、InFlight 为 0
+//  3. 断言暂停期间没有重新注册消费者
+//  4. 恢复后断言注册了新的消费者标签，新投递的消息被确认
+func TestMessageQueue_Pause_DrainsAndResumes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started, release := make(chan struct{}, 3), make(chan struct{})
+	m := &MessageQueue{QueueName: "pause", FunWithCtx: func(ctx context.Context, msg string) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}}
+	ch := &fakeConsumeChannel{}
+	ack := &tagAcknowledger{}
+
+	done := startConsumeLoop(ctx, m, ch, make(chan *amqp.Error))
+	waitConsumed(t, ch, 1)
+	ch.send(ack, 1, 2, 3)
+	<-started
+
+	if err := m.Pause(); err != nil {
+		t.Fatalf("暂停不应返回错误: %v", err)
+	}
+	state := m.ConsumerState()
+	if state.State != ConsumerStatePaused || state.PausedAt == nil || state.InFlight != 1 {
+		t.Errorf("暂停后状态不正确: %+v", state)
+	}
+	close(release)
+	waitSettled(t, ack, 3)
+	if len(ack.acked) != 3 {
+		t.Errorf("已取出的 3 条消息应全部确认，实际确认 %d 条", len(ack.acked))
+	}
+	time.Sleep(20 * time.Millisecond)
+	if tags := ch.consumed(); len(tags) != 1 {
+		t.Fatalf("暂停期间不应重新注册消费者，实际注册 %d 次", len(tags))
+	}
+	if state := m.ConsumerState(); state.InFlight != 0 {
+		t.Errorf("消息处理完毕后 InFlight 应为 0，实际 %d", state.InFlight)
+	}
+
+	m.Resume()
+	waitConsumed(t, ch, 2)
+	if tags := ch.consumed(); tags[0] == tags[1] {
+		t.Errorf("恢复后应使用新的消费者标签，实际 %v", tags)
+	}
+	ch.send(ack, 4)
+	waitSettled(t, ack, 4)
+	if state := m.ConsumerState(); state.State != ConsumerStateRunning || state.PausedAt != nil {
+		t.Errorf("恢复后状态不正确: %+v", state)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("context 取消后应返回 nil，实际 %v", err)
+	}
+}
+
+// TestMessageQueue_Pause_BeforeStart 测试启动前暂停
+//
+// 【功能点】验证启动前暂停的消费者不注册消费者，恢复后注册
+// 【测试流程】
+//  1. 暂停后启动消费循环，断言未注册消费者
+//  2. 恢复后断言注册了消费者；context 取消后消费循环返回 nil
+func TestMessageQueue_Pause_BeforeStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := &MessageQueue{QueueName: "pause-before-start"}
+	ch := &fakeConsumeChannel{}
+
+	if err := m.Pause(); err != nil {
+		t.Fatalf("未运行时暂停不应返回错误: %v", err)
+	}
+	done := startConsumeLoop(ctx, m, ch, make(chan *amqp.Error))
+	time.Sleep(20 * time.Millisecond)
+	if tags := ch.consumed(); len(tags) != 0 {
+		t.Fatalf("已暂停时不应注册消费者，实际注册 %d 次", len(tags))
+	}
+
+	m.Resume()
+	waitConsumed(t, ch, 1)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("context 取消后应返回 nil，实际 %v", err)
+	}
+}
+
+// TestMessageQueue_Pause_SurvivesReconnect 测试断线重连后保持暂停
+//
+// 【功能点】验证暂停期间通道关闭时消费循环返回错误，重连后仍保持暂停、不注册消费者
+// 【测试流程】
+//  1. 启动消费循环后暂停，关闭 notifyClose，断言消费循环返回错误
+//  2. 以新的通道重新启动消费循环，断言未注册消费者且状态为 paused
+//  3. 恢复后断言在新的通道上注册了消费者
+func TestMessageQueue_Pause_SurvivesReconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := &MessageQueue{QueueName: "pause-reconnect"}
+	ch := &fakeConsumeChannel{}
+	notifyClose := make(chan *amqp.Error)
+
+	done := startConsumeLoop(ctx, m, ch, notifyClose)
+	waitConsumed(t, ch, 1)
+	if err := m.Pause(); err != nil {
+		t.Fatalf("暂停不应返回错误: %v", err)
+	}
+	close(notifyClose)
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("通道关闭时应返回错误")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("等待消费循环退出超时")
+	}
+
+	reconnected := &fakeConsumeChannel{}
+	done = startConsumeLoop(ctx, m, reconnected, make(chan *amqp.Error))
+	time.Sleep(20 * time.Millisecond)
+	if tags := reconnected.consumed(); len(tags) != 0 {
+		t.Fatalf("重连后应保持暂停，实际注册 %d 次", len(tags))
+	}
+	if !m.Paused() {
+		t.Error("重连后状态应为 paused")
+	}
+
+	m.Resume()
+	waitConsumed(t, reconnected, 1)
+	cancel()
+	<-done
+}
+
+// TestMessageQueue_Pause_BatchFlush 测试批量消费暂停时处理已攒的消息
+//
+// 【功能点】验证批量消费暂停时，未攒满的批次立即交给 BatchFun 处理并确认
+// 【测试流程】
+//  1. BatchSize 为 10、BatchTimeout 为 1 小时，投递 2 条消息后暂停
+//  2. 断言 BatchFun 收到 1 批 2 条，消息均被确认
+func TestMessageQueue_Pause_BatchFlush(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rec := &batchRecorder{}
+	m := &MessageQueue{QueueName: "pause-batch", BatchFun: rec.fun, ConsumeConfig: ConsumeConfig{BatchSize: 10, BatchTimeout: time.Hour}}
+	ch := &fakeConsumeChannel{}
+	ack := &tagAcknowledger{}
+
+	startConsumeLoop(ctx, m, ch, make(chan *amqp.Error))
+	waitConsumed(t, ch, 1)
+	ch.send(ack, 1, 2)
+	if err := m.Pause(); err != nil {
+		t.Fatalf("暂停不应返回错误: %v", err)
+	}
+	waitSettled(t, ack, 2)
+
+	if sizes := rec.sizes(); len(sizes) != 1 || sizes[0] != 2 {
+		t.Errorf("期望 1 批 2 条，实际 %v", sizes)
+	}
+	if len(ack.acked) != 2 {
+		t.Errorf("期望确认 2 条，实际 %d 条", len(ack.acked))
+	}
+}
+
+// TestMessageQueue_Pause_CancelError 测试取消消费者失败
+//
+// 【功能点】验证取消消费者失败时返回错误，状态仍为已暂停，重复暂停和恢复幂等
+// 【测试流程】
+//  1. 模拟通道的 Cancel 返回错误，暂停后断言返回错误且状态为 paused
+//  2. 再次暂停断言返回 nil；两次恢复后断言状态为 running
+func TestMessageQueue_Pause_CancelError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := &MessageQueue{QueueName: "pause-cancel-error"}
+	ch := &fakeConsumeChannel{cancelErr: errors.New("channel closed")}
+
+	startConsumeLoop(ctx, m, ch, make(chan *amqp.Error))
+	waitConsumed(t, ch, 1)
+
+	if err := m.Pause(); err == nil {
+		t.Error("取消消费者失败时应返回错误")
+	}
+	if !m.Paused() {
+		t.Error("取消消费者失败时状态仍应为 paused")
+	}
+	if err := m.Pause(); err != nil {
+		t.Errorf("重复暂停应返回 nil，实际 %v", err)
+	}
+
+	m.Resume()
+	m.Resume()
+	if m.Paused() {
+		t.Error("恢复后状态应为 running")
+	}
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DefaultPublisherChannelPoolSize 发布通道池默认容量
+const DefaultPublisherChannelPoolSize = 4
+
+// confirmBufferSize 每个发布通道确认队列的缓冲大小
+const confirmBufferSize = 16
+
+// errChannelPoolClosed 通道池已关闭
+var errChannelPoolClosed = errors.New("发布通道池已关闭")
+
+// publishChannel 发布通道接口
+// 抽象 *amqp.Channel 中发布相关的能力，便于在单元测试中替换为模拟实现
+type publishChannel interface {
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	Confirm(noWait bool) error
+	NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation
+	IsClosed() bool
+	Close() error
+}
+
+// pooledChannel 池化的发布通道
+type pooledChannel struct {
+	ch       publishChannel
+	confirms chan amqp.Confirmation // 发布确认通道，未启用 Publisher Confirms 时为 nil
+}
+
+// ChannelPoolStats 发布通道池统计信息
+type ChannelPoolStats struct {
+	Size      int   `json:"size"`       // 池容量
+	Open      int   `json:"open"`       // 当前已创建的通道数（含借出）
+	Idle      int   `json:"idle"`       // 空闲通道数
+	InUse     int   `json:"in_use"`     // 借出中的通道数
+	Created   int64 `json:"created"`    // 累计创建的通道数
+	Discarded int64 `json:"discarded"`  // 累计因损坏而丢弃的通道数
+	WaitCount int64 `json:"wait_count"` // 因池中无可用通道而等待的次数
+}
+
+// channelPool 发布通道池
+// amqp091 的 Channel 不支持并发发布，池中每个通道同一时刻只会借给一个调用方。
+// 通道按需创建，总数不超过 size；损坏的通道在归还或借出时被丢弃，下次借用时重新创建。
+type channelPool struct {
+	size    int
+	confirm bool
+	factory func() (publishChannel, error)
+
+	idle  chan *pooledChannel
+	freed chan struct{} // 有通道被丢弃时通知等待者重新尝试创建
+
+	mu     sync.Mutex
+	open   int
+	closed bool
+
+	created   int64
+	discarded int64
+	waitCount int64
+}
+
+// newChannelPool 创建发布通道池
+// 参数：
+//   - size: 池容量，<= 0 时使用 DefaultPublisherChannelPoolSize
+//   - confirm: 是否在创建通道时启用 Publisher Confirms
+//   - factory: 通道创建函数
+func newChannelPool(size int, confirm bool, factory func() (publishChannel, error)) *channelPool {
+	if size <= 0 {
+		size = DefaultPublisherChannelPoolSize
+	}
+	return &channelPool{
+		size:    size,
+		confirm: confirm,
+		factory: factory,
+		idle:    make(chan *pooledChannel, size),
+		freed:   make(chan struct{}, size),
+	}
+}
+
+// get 借用一个发布通道
+//
+// 执行流程：
+// 1. 优先复用空闲通道，跳过已关闭的通道
+// 2. 无空闲通道且未达到容量上限时创建新通道
+// 3. 达到容量上限时等待其他调用方归还，直到 ctx 结束
+func (p *channelPool) get(ctx context.Context) (*pooledChannel, error) {
+	for {
+		// 1. 复用空闲通道
+		select {
+		case pc := <-p.idle:
+			if pc.ch.IsClosed() {
+				p.discard(pc)
+				continue
+			}
+			return pc, nil
+		default:
+		}
+
+		// 2. 创建新通道
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, errChannelPoolClosed
+		}
+		if p.open < p.size {
+			p.open++
+			p.mu.Unlock()
+			pc, err := p.create()
+			if err != nil {
+				p.mu.Lock()
+				p.open--
+				p.mu.Unlock()
+				return nil, err
+			}
+			return pc, nil
+		}
+		p.mu.Unlock()
+
+		// 3. 等待归还
+		atomic.AddInt64(&p.waitCount, 1)
+		select {
+		case pc := <-p.idle:
+			if pc.ch.IsClosed() {
+				p.discard(pc)
+				continue
+			}
+			return pc, nil
+		case <-p.freed:
+			continue
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// put 归还发布通道
+// broken 为 true、通道已关闭或通道池已关闭时直接丢弃，不再放回池中。
+// 检查通道池是否关闭和放回空闲队列在同一把锁内完成，避免与 close 并发时通道被放入已清空的池中而未被关闭
+func (p *channelPool) put(pc *pooledChannel, broken bool) {
+	if broken || pc.ch.IsClosed() {
+		p.discard(pc)
+		return
+	}
+
+	p.mu.Lock()
+	returned := false
+	if !p.closed {
+		select {
+		case p.idle <- pc:
+			returned = true
+		default:
+		}
+	}
+	p.mu.Unlock()
+
+	if !returned {
+		p.discard(pc)
+	}
+}
+
+// create 创建新的发布通道，启用 Publisher Confirms 时同时设置确认模式
+func (p *channelPool) create() (*pooledChannel, error) {
+	ch, err := p.factory()
+	if err != nil {
+		return nil, err
+	}
+	pc := &pooledChannel{ch: ch}
+	if p.confirm {
+		if err := ch.Confirm(false); err != nil {
+			_ = ch.Close()
+			return nil, err
+		}
+		pc.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, confirmBufferSize))
+	}
+	atomic.AddInt64(&p.created, 1)
+	return pc, nil
+}
+
+// discard 丢弃发布通道并释放占用的容量
+func (p *channelPool) discard(pc *pooledChannel) {
+	if !pc.ch.IsClosed() {
+		_ = pc.ch.Close()
+	}
+	atomic.AddInt64(&p.discarded, 1)
+
+	p.mu.Lock()
+	p.open--
+	p.mu.Unlock()
+
+	select {
+	case p.freed <- struct{}{}:
+	default:
+	}
+}
+
+// close 关闭通道池及其中的空闲通道，借出中的通道在归还时关闭
+func (p *channelPool) close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	for {
+		select {
+		case pc := <-p.idle:
+			if !pc.ch.IsClosed() {
+				_ = pc.ch.Close()
+			}
+			p.mu.Lock()
+			p.open--
+			p.mu.Unlock()
+		default:
+			return
+		}
+	}
+}
+
+// stats 获取通道池统计信息
+func (p *channelPool) stats() ChannelPoolStats {
+	p.mu.Lock()
+	open := p.open
+	p.mu.Unlock()
+
+	idle := len(p.idle)
+	inUse := open - idle
+	if inUse < 0 {
+		inUse = 0
+	}
+	return ChannelPoolStats{
+		Size:      p.size,
+		Open:      open,
+		Idle:      idle,
+		InUse:     inUse,
+		Created:   atomic.LoadInt64(&p.created),
+		Discarded: atomic.LoadInt64(&p.discarded),
+		WaitCount: atomic.LoadInt64(&p.waitCount),
+	}
+}
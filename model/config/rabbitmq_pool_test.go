@@ -0,0 +1,417 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ==================== 单元测试文件（不需要 RabbitMQ 连接） ====================
+//
+// 本文件测试发布通道池，使用模拟的 publishChannel 替代真实的 AMQP 通道。
+// 这些测试主要验证：
+// - 100 个协程并发发布时不会出现通道被并发使用或通道已关闭的错误
+// - 通道池相对单通道的吞吐提升
+// - 损坏通道的丢弃与惰性重建
+// - 通道池关闭与归还并发时通道被关闭
+// - 每个池化通道独立启用 Publisher Confirms
+// - 通道池统计信息
+
+// fakeChannel 模拟的发布通道
+// 同一通道被并发发布时返回错误，模拟 amqp091 通道不支持并发发布的限制
+type fakeChannel struct {
+	latency      time.Duration
+	failAfter    int32         // 发布次数达到该值后通道关闭，0 表示不会关闭
+	confirmDelay time.Duration // 确认延迟发送的时间，0 表示发布时立即确认；通道关闭后不再发送确认
+
+	publishing int32
+	published  int32
+	closed     int32
+	confirmed  int32
+
+	mu       sync.Mutex
+	confirms chan amqp.Confirmation
+	tag      uint64
+
+	onIsClosed func() // 检查通道状态时调用，用于在指定时机插入其他操作
+}
+
+func (f *fakeChannel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	if atomic.LoadInt32(&f.closed) == 1 {
+		return amqp.ErrClosed
+	}
+	if !atomic.CompareAndSwapInt32(&f.publishing, 0, 1) {
+		return errors.New("channel used concurrently")
+	}
+	defer atomic.StoreInt32(&f.publishing, 0)
+
+	time.Sleep(f.latency)
+	n := atomic.AddInt32(&f.published, 1)
+	if f.failAfter > 0 && n >= f.failAfter {
+		atomic.StoreInt32(&f.closed, 1)
+		return amqp.ErrClosed
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.confirms != nil {
+		f.tag++
+		confirm := amqp.Confirmation{DeliveryTag: f.tag, Ack: true}
+		if f.confirmDelay == 0 {
+			f.confirms <- confirm
+			return nil
+		}
+		confirms := f.confirms
+		time.AfterFunc(f.confirmDelay, func() {
+			if !f.IsClosed() {
+				confirms <- confirm
+			}
+		})
+	}
+	return nil
+}
+
+func (f *fakeChannel) Confirm(noWait bool) error {
+	atomic.AddInt32(&f.confirmed, 1)
+	return nil
+}
+
+func (f *fakeChannel) NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.confirms = confirm
+	return confirm
+}
+
+func (f *fakeChannel) IsClosed() bool {
+	if f.onIsClosed != nil {
+		f.onIsClosed()
+	}
+	return atomic.LoadInt32(&f.closed) == 1
+}
+
+func (f *fakeChannel) Close() error {
+	atomic.StoreInt32(&f.closed, 1)
+	return nil
+}
+
+// fakeChannelFactory 记录所有创建的模拟通道
+type fakeChannelFactory struct {
+	latency      time.Duration
+	failAfter    int32
+	confirmDelay time.Duration
+
+	mu       sync.Mutex
+	channels []*fakeChannel
+}
+
+func (f *fakeChannelFactory) create() (publishChannel, error) {
+	ch := &fakeChannel{latency: f.latency, failAfter: f.failAfter, confirmDelay: f.confirmDelay}
+	f.mu.Lock()
+	f.channels = append(f.channels, ch)
+	f.mu.Unlock()
+	return ch, nil
+}
+
+// newFakeProducer 创建使用模拟通道的发送者
+func newFakeProducer(poolSize int, confirm bool, factory *fakeChannelFactory) *MessageQueue {
+	return &MessageQueue{
+		QueueName:                "test-queue",
+		ExchangeName:             "test-exchange",
+		ExchangeType:             "direct",
+		RoutingKey:               "test-key",
+		PublisherChannelPoolSize: poolSize,
+		PublishConfirm:           PublishConfirmConfig{Enabled: confirm, Timeout: 5 * time.Second},
+		channelFactory:           factory.create,
+	}
+}
+
+// publishConcurrently 启动 goroutines 个协程，每个协程发布 perGoroutine 条消息，返回错误列表和耗时
+func publishConcurrently(mq *MessageQueue, goroutines, perGoroutine int) ([]error, time.Duration) {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	start := time.Now()
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				if err := mq.Publish(fmt.Sprintf("goroutine %d, message %d", id, j)); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	return errs, time.Since(start)
+}
+
+// ==================== 单元测试：并发发布（不需要 RabbitMQ 连接） ====================
+// 测试点：验证通道池在高并发下的正确性和吞吐
+
+// TestChannelPool_ConcurrentPublish 测试 100 个协程并发发布
+//
+// 【功能点】验证通道池保证每个通道同一时刻只被一个协程使用，且通道数不超过池容量
+// 【测试流程】100 个协程各发布 10 条消息，断言无错误、所有消息均已发布、创建的通道数不超过池容量
+func TestChannelPool_ConcurrentPublish(t *testing.T) {
+	factory := &fakeChannelFactory{latency: 100 * time.Microsecond}
+	mq := newFakeProducer(4, true, factory)
+	defer mq.Close()
+
+	errs, _ := publishConcurrently(mq, 100, 10)
+	if len(errs) > 0 {
+		t.Fatalf("并发发布出现 %d 个错误, 第一个错误: %v", len(errs), errs[0])
+	}
+
+	var total int32
+	for _, ch := range factory.channels {
+		total += atomic.LoadInt32(&ch.published)
+	}
+	if total != 1000 {
+		t.Errorf("发布消息数 = %d, 期望 1000", total)
+	}
+	if len(factory.channels) > 4 {
+		t.Errorf("创建通道数 = %d, 不应超过池容量 4", len(factory.channels))
+	}
+
+	stats := mq.PublisherPoolStats()
+	if stats.InUse != 0 {
+		t.Errorf("发布完成后借出通道数 = %d, 期望 0", stats.InUse)
+	}
+	if stats.Open != stats.Idle {
+		t.Errorf("发布完成后 Open = %d, Idle = %d, 期望相等", stats.Open, stats.Idle)
+	}
+}
+
+// TestChannelPool_ThroughputScaling 测试通道池相对单通道的吞吐提升
+//
+// 【功能点】验证多通道并发发布的耗时明显低于单通道串行发布
+// 【测试流程】分别以容量 1 和 4 的通道池执行相同的并发发布，比较耗时
+func TestChannelPool_ThroughputScaling(t *testing.T) {
+	single := newFakeProducer(1, false, &fakeChannelFactory{latency: time.Millisecond})
+	defer single.Close()
+	pooled := newFakeProducer(4, false, &fakeChannelFactory{latency: time.Millisecond})
+	defer pooled.Close()
+
+	errs, singleCost := publishConcurrently(single, 100, 5)
+	if len(errs) > 0 {
+		t.Fatalf("单通道发布出现错误: %v", errs[0])
+	}
+	errs, pooledCost := publishConcurrently(pooled, 100, 5)
+	if len(errs) > 0 {
+		t.Fatalf("通道池发布出现错误: %v", errs[0])
+	}
+
+	t.Logf("单通道耗时: %v, 通道池耗时: %v", singleCost, pooledCost)
+	if pooledCost*2 > singleCost {
+		t.Errorf("通道池吞吐提升不足: 单通道 %v, 通道池 %v", singleCost, pooledCost)
+	}
+}
+
+// ==================== 单元测试：损坏通道处理（不需要 RabbitMQ 连接） ====================
+// 测试点：验证损坏通道被丢弃并惰性重建
+
+// TestChannelPool_BrokenChannelReplaced 测试损坏通道被丢弃并重建
+//
+// 【功能点】验证发布失败的通道不会被放回池中，后续发布使用新建的通道
+// 【测试流程】模拟通道发布 3 次后关闭，连续发布多条消息，断言失败后的发布成功且丢弃计数增加
+func TestChannelPool_BrokenChannelReplaced(t *testing.T) {
+	factory := &fakeChannelFactory{failAfter: 3}
+	mq := newFakeProducer(1, false, factory)
+	defer mq.Close()
+
+	var failed int
+	for i := 0; i < 10; i++ {
+		if err := mq.Publish("msg"); err != nil {
+			failed++
+		}
+	}
+
+	stats := mq.PublisherPoolStats()
+	if stats.Discarded != int64(failed) {
+		t.Errorf("丢弃通道数 = %d, 期望等于失败次数 %d", stats.Discarded, failed)
+	}
+	if stats.Created != int64(len(factory.channels)) || len(factory.channels) < 2 {
+		t.Errorf("创建通道数 = %d, 期望损坏后重新创建", len(factory.channels))
+	}
+	if stats.Open > 1 {
+		t.Errorf("当前通道数 = %d, 不应超过池容量 1", stats.Open)
+	}
+}
+
+// TestChannelPool_ClosedIdleChannelSkipped 测试空闲期间被关闭的通道在借用时被跳过
+//
+// 【功能点】验证连接断开等原因导致空闲通道关闭后，借用时会丢弃并重新创建
+// 【测试流程】发布一次后手动关闭空闲通道，再次发布，断言成功且创建了新通道
+func TestChannelPool_ClosedIdleChannelSkipped(t *testing.T) {
+	factory := &fakeChannelFactory{}
+	mq := newFakeProducer(2, false, factory)
+	defer mq.Close()
+
+	if err := mq.Publish("first"); err != nil {
+		t.Fatalf("发布失败: %v", err)
+	}
+	_ = factory.channels[0].Close()
+
+	if err := mq.Publish("second"); err != nil {
+		t.Fatalf("通道关闭后发布失败: %v", err)
+	}
+	if len(factory.channels) != 2 {
+		t.Errorf("创建通道数 = %d, 期望 2", len(factory.channels))
+	}
+	if stats := mq.PublisherPoolStats(); stats.Discarded != 1 {
+		t.Errorf("丢弃通道数 = %d, 期望 1", stats.Discarded)
+	}
+}
+
+// ==================== 单元测试：Publisher Confirms（不需要 RabbitMQ 连接） ====================
+// 测试点：验证每个池化通道独立启用确认模式
+
+// TestChannelPool_ConfirmPerChannel 测试每个池化通道独立启用确认模式
+//
+// 【功能点】验证通道创建时启用确认模式，发布后在该通道的确认队列上等待确认
+// 【测试流程】启用 Publisher Confirms 并发发布和批量发布，断言每个通道都只调用一次 Confirm
+func TestChannelPool_ConfirmPerChannel(t *testing.T) {
+	factory := &fakeChannelFactory{latency: 100 * time.Microsecond}
+	mq := newFakeProducer(3, true, factory)
+	defer mq.Close()
+
+	errs, _ := publishConcurrently(mq, 20, 5)
+	if len(errs) > 0 {
+		t.Fatalf("并发发布出现错误: %v", errs[0])
+	}
+	if err := mq.PublishBatch([]string{"a", "b", "c"}); err != nil {
+		t.Fatalf("批量发布失败: %v", err)
+	}
+
+	for i, ch := range factory.channels {
+		if n := atomic.LoadInt32(&ch.confirmed); n != 1 {
+			t.Errorf("通道 %d 调用 Confirm 次数 = %d, 期望 1", i, n)
+		}
+	}
+}
+
+// ==================== 单元测试：通道池生命周期（不需要 RabbitMQ 连接） ====================
+// 测试点：验证等待、关闭与默认容量
+
+// TestChannelPool_WaitTimeout 测试通道池耗尽时等待超时
+//
+// 【功能点】验证所有通道被借出时借用方会等待，ctx 结束后返回错误
+// 【测试流程】借出唯一的通道后再次借用，断言超时返回错误且等待计数增加
+func TestChannelPool_WaitTimeout(t *testing.T) {
+	factory := &fakeChannelFactory{}
+	pool := newChannelPool(1, false, factory.create)
+
+	pc, err := pool.get(context.Background())
+	if err != nil {
+		t.Fatalf("借用通道失败: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望超时错误, 实际: %v", err)
+	}
+	if stats := pool.stats(); stats.WaitCount != 1 || stats.InUse != 1 {
+		t.Errorf("统计信息不符合预期: %+v", stats)
+	}
+
+	pool.put(pc, false)
+	if stats := pool.stats(); stats.Idle != 1 || stats.InUse != 0 {
+		t.Errorf("归还后统计信息不符合预期: %+v", stats)
+	}
+}
+
+// TestChannelPool_Close 测试关闭通道池
+//
+// 【功能点】验证关闭后空闲通道被关闭、借用返回错误，Close 后再次发布会重建通道池
+// 【测试流程】发布后关闭发送者，检查通道状态，再次发布验证可以继续使用
+func TestChannelPool_Close(t *testing.T) {
+	factory := &fakeChannelFactory{}
+	mq := newFakeProducer(2, false, factory)
+
+	if err := mq.Publish("msg"); err != nil {
+		t.Fatalf("发布失败: %v", err)
+	}
+	pool := mq.publisherPool()
+	mq.Close()
+
+	if !factory.channels[0].IsClosed() {
+		t.Error("关闭后空闲通道应被关闭")
+	}
+	if _, err := pool.get(context.Background()); !errors.Is(err, errChannelPoolClosed) {
+		t.Errorf("期望通道池已关闭错误, 实际: %v", err)
+	}
+
+	if err := mq.Publish("msg"); err != nil {
+		t.Fatalf("Close 后再次发布失败: %v", err)
+	}
+	mq.Close()
+}
+
+// TestChannelPool_PutDuringClose 测试归还与关闭并发
+//
+// 【功能点】验证归还通道期间通道池被关闭时，归还的通道被关闭并释放容量，不会被放入已关闭的池中
+// 【测试流程】
+//  1. 借出一个通道，设置该通道在归还检查状态时关闭通道池，模拟 close 插入到归还过程中
+//  2. 归还通道，断言通道已被关闭、已创建的通道数和空闲通道数均为 0
+func TestChannelPool_PutDuringClose(t *testing.T) {
+	factory := &fakeChannelFactory{}
+	pool := newChannelPool(2, false, factory.create)
+
+	pc, err := pool.get(context.Background())
+	if err != nil {
+		t.Fatalf("借用通道失败: %v", err)
+	}
+	var once sync.Once
+	pc.ch.(*fakeChannel).onIsClosed = func() { once.Do(pool.close) }
+
+	pool.put(pc, false)
+
+	if !factory.channels[0].IsClosed() {
+		t.Error("通道池关闭期间归还的通道应被关闭")
+	}
+	if stats := pool.stats(); stats.Open != 0 || stats.Idle != 0 {
+		t.Errorf("Open = %d, Idle = %d, 期望均为 0", stats.Open, stats.Idle)
+	}
+}
+
+// TestRabbitMQInfo_GetPublisherChannelPoolSize 测试发布通道池容量默认值
+//
+// 【功能点】验证未配置时使用默认容量，配置后使用配置值
+// 【测试流程】分别检查未配置、负数和正常配置的返回值
+func TestRabbitMQInfo_GetPublisherChannelPoolSize(t *testing.T) {
+	tests := []struct {
+		size int
+		want int
+	}{
+		{0, DefaultPublisherChannelPoolSize},
+		{-1, DefaultPublisherChannelPoolSize},
+		{8, 8},
+	}
+	for _, tt := range tests {
+		info := RabbitMQInfo{PublisherChannelPoolSize: tt.size}
+		if got := info.GetPublisherChannelPoolSize(); got != tt.want {
+			t.Errorf("GetPublisherChannelPoolSize(%d) = %d, 期望 %d", tt.size, got, tt.want)
+		}
+	}
+
+	list := RabbitMqListInfo{{AliasName: "a", PublisherChannelPoolSize: 6}}
+	if found := list.Find("a"); found == nil || found.GetPublisherChannelPoolSize() != 6 {
+		t.Errorf("Find(\"a\") 返回值不符合预期: %+v", found)
+	}
+	if found := list.Find("missing"); found != nil {
+		t.Errorf("Find(\"missing\") 应返回 nil, 实际: %+v", found)
+	}
+}
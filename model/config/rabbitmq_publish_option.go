@@ -0,0 +1,81 @@
+package config
+
+import (
+	"maps"
+	"strconv"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// PublishOption 发布选项，用于设置消息头、优先级、过期时间等消息属性
+type PublishOption func(*amqp.Publishing)
+
+// WithHeaders 设置消息头，多次调用时合并，相同的键以后设置的为准
+// 发布到 headers 交换机时，消息头用于匹配队列的 BindingArgs
+func WithHeaders(headers amqp.Table) PublishOption {
+	return func(p *amqp.Publishing) {
+		if len(headers) == 0 {
+			return
+		}
+		if p.Headers == nil {
+			p.Headers = make(amqp.Table, len(headers))
+		}
+		maps.Copy(p.Headers, headers)
+	}
+}
+
+// WithPriority 设置消息优先级，队列需设置 MessageQueue.MaxPriority（声明 x-max-priority 参数）才会按优先级投递
+// 大于队列 MaxPriority 的优先级按 MaxPriority 处理
+func WithPriority(priority uint8) PublishOption {
+	return func(p *amqp.Publishing) {
+		p.Priority = priority
+	}
+}
+
+// WithExpirationMs 设置消息的过期时间（毫秒），超时未被消费的消息被丢弃或转入死信队列；ms < 0 时不设置
+func WithExpirationMs(ms int) PublishOption {
+	return func(p *amqp.Publishing) {
+		if ms < 0 {
+			return
+		}
+		p.Expiration = strconv.Itoa(ms)
+	}
+}
+
+// WithTTL 设置消息的过期时间，超时未被消费的消息被丢弃或转入死信队列；d < 0 时不设置
+// 精确到毫秒，不足 1 毫秒的部分被舍去
+func WithTTL(d time.Duration) PublishOption {
+	return WithExpirationMs(int(d.Milliseconds()))
+}
+
+// WithPersistent 设置消息是否持久化，默认持久化
+// 非持久化消息不写入磁盘，吞吐更高，但 RabbitMQ 重启后丢失，适用于可丢失的通知类消息
+func WithPersistent(persistent bool) PublishOption {
+	return func(p *amqp.Publishing) {
+		if persistent {
+			p.DeliveryMode = amqp.Persistent
+		} else {
+			p.DeliveryMode = amqp.Transient
+		}
+	}
+}
+
+// WithMessageID 设置消息 ID，覆盖自动生成的 uuid；id 为空时不设置
+// 批量发布时所有消息使用同一个 ID，消费端启用去重时只会处理其中一条，批量发布不应使用该选项
+func WithMessageID(id string) PublishOption {
+	return func(p *amqp.Publishing) {
+		if id != "" {
+			p.MessageId = id
+		}
+	}
+}
+
+// WithContentType 设置消息的内容类型，默认 text/plain，如 application/json
+func WithContentType(contentType string) PublishOption {
+	return func(p *amqp.Publishing) {
+		if contentType != "" {
+			p.ContentType = contentType
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ==================== 单元测试文件（不需要 RabbitMQ 连接） ====================
+//
+// 本文件测试发布选项（PublishOption）。
+// 这些测试主要验证：
+// - WithHeaders 多次调用时合并消息头，不修改传入的 amqp.Table
+// - WithPriority、WithExpirationMs 设置对应的消息属性，ms < 0 时不设置过期时间
+// - 不传选项时消息属性与之前一致
+// - WithTTL、WithPersistent、WithMessageID、WithContentType 设置对应的消息属性，空值不覆盖默认值
+
+// TestNewPublishing_Options 测试发布选项
+//
+// 【功能点】验证 newPublishing 依次应用发布选项
+// 【测试流程】
+//  1. 不传选项，断言消息头为空、优先级为 0、未设置过期时间，MessageId 自动生成
+//  2. 传入两次 WithHeaders、WithPriority、WithExpirationMs，断言消息头合并、后设置的值覆盖先设置的值
+//  3. 断言传入的 amqp.Table 未被修改
+//  4. WithExpirationMs(-1) 不设置过期时间
+func TestNewPublishing_Options(t *testing.T) {
+	plain := newPublishing("hello", "")
+	if plain.Headers != nil || plain.Priority != 0 || plain.Expiration != "" {
+		t.Errorf("不传选项时不应设置消息头、优先级和过期时间: %+v", plain)
+	}
+	if plain.MessageId == "" || plain.DeliveryMode != amqp.Persistent {
+		t.Errorf("应自动生成 MessageId 并持久化: %+v", plain)
+	}
+
+	first := amqp.Table{"format": "pdf", "region": "cn"}
+	p := newPublishing("hello", "id-1",
+		WithHeaders(first),
+		WithHeaders(amqp.Table{"region": "us", "tenant": "t1"}),
+		WithPriority(7),
+		WithExpirationMs(60000),
+	)
+	expected := amqp.Table{"format": "pdf", "region": "us", "tenant": "t1"}
+	if len(p.Headers) != len(expected) {
+		t.Fatalf("消息头数量 = %d, want %d: %v", len(p.Headers), len(expected), p.Headers)
+	}
+	for k, v := range expected {
+		if p.Headers[k] != v {
+			t.Errorf("消息头 %s = %v, want %v", k, p.Headers[k], v)
+		}
+	}
+	if p.Priority != 7 {
+		t.Errorf("Priority = %d, want 7", p.Priority)
+	}
+	if p.Expiration != "60000" {
+		t.Errorf("Expiration = %q, want 60000", p.Expiration)
+	}
+	if p.MessageId != "id-1" {
+		t.Errorf("MessageId = %q, want id-1", p.MessageId)
+	}
+	if first["region"] != "cn" || len(first) != 2 {
+		t.Errorf("传入的消息头不应被修改: %v", first)
+	}
+
+	if got := newPublishing("hello", "", WithExpirationMs(-1)).Expiration; got != "" {
+		t.Errorf("WithExpirationMs(-1) 不应设置过期时间, got %q", got)
+	}
+}
+
+// TestNewPublishing_MessageProperties 测试消息属性选项
+//
+// 【功能点】验证 WithTTL、WithPersistent、WithMessageID、WithContentType 设置对应的消息属性
+// 【测试流程】
+//  1. 传入 WithTTL(1500ms)、WithPersistent(false)、WithMessageID、WithContentType，断言对应的属性
+//  2. WithMessageID 覆盖 PublishWithMessageID 传入的 ID
+//  3. WithMessageID("")、WithContentType("") 不覆盖默认值，WithTTL 负数不设置过期时间、不足 1 毫秒的部分被舍去
+func TestNewPublishing_MessageProperties(t *testing.T) {
+	p := newPublishing("hello", "",
+		WithTTL(1500*time.Millisecond),
+		WithPersistent(false),
+		WithMessageID("order-1"),
+		WithContentType("application/json"),
+	)
+	if p.Expiration != "1500" {
+		t.Errorf("Expiration = %q, want 1500", p.Expiration)
+	}
+	if p.DeliveryMode != amqp.Transient {
+		t.Errorf("DeliveryMode = %d, want Transient", p.DeliveryMode)
+	}
+	if p.MessageId != "order-1" || p.ContentType != "application/json" {
+		t.Errorf("MessageId = %q, ContentType = %q, want order-1, application/json", p.MessageId, p.ContentType)
+	}
+
+	if got := newPublishing("hello", "id-1", WithMessageID("id-2")).MessageId; got != "id-2" {
+		t.Errorf("WithMessageID 应覆盖传入的 ID, got %q", got)
+	}
+
+	p = newPublishing("hello", "id-1", WithMessageID(""), WithContentType(""), WithTTL(-time.Second), WithPersistent(true))
+	if p.MessageId != "id-1" || p.ContentType != "text/plain" || p.Expiration != "" || p.DeliveryMode != amqp.Persistent {
+		t.Errorf("空值不应覆盖默认值: %+v", p)
+	}
+	if got := newPublishing("hello", "", WithTTL(1500*time.Microsecond)).Expiration; got != "1" {
+		t.Errorf("WithTTL(1.5ms) Expiration = %q, want 1", got)
+	}
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/zzsen/gin_core/exception/mq"
+)
+
+// RetryAttemptsHeader 记录延迟重试次数的消息头
+// 延迟重试时消息被重新发布，原 x-death 不再保留，重试次数由该消息头传递，与 x-death 计数取较大值后和 MaxRetry 比较
+const RetryAttemptsHeader = "gin-core-retry-attempts"
+
+// 消费失败后的处理决定
+const (
+	RetryActionRequeue    = "requeue"    // 立即重新入队
+	RetryActionDelay      = "delay"      // 发布到延迟队列，到期后回到主队列
+	RetryActionDeadLetter = "deadLetter" // 超过最大重试次数或返回 mq.ErrDeadLetter，拒绝且不重新入队，配置了死信队列时进入死信队列
+	RetryActionDiscard    = "discard"    // 确认并丢弃，不进入死信队列
+)
+
+// RetryDecision 消费失败后的处理决定，通过 ConsumeConfig.OnRetryDecision 回调
+type RetryDecision struct {
+	Queue     string        // 队列标识（GetInfo）
+	MessageID string        // 消息 ID
+	Action    string        // 处理决定，RetryAction* 常量之一
+	Attempts  int           // 本次消费前已重试的次数
+	MaxRetry  int           // 最大重试次数
+	Delay     time.Duration // 延迟重试的等待时间，仅 Action 为 delay 时有值
+	Err       error         // 消费函数返回的错误，发布到延迟队列失败时同时包含发布错误
+}
+
+// DelayQueueName 获取延迟队列名称，为主队列名称 + ".delay"
+// 延迟队列没有消费者，消息过期后通过默认交换机投递回主队列
+func (m *MessageQueue) DelayQueueName() string {
+	return m.QueueName + ".delay"
+}
+
+// settleFailed 处理消费失败的消息
+//   - mq.ErrDiscard：确认并丢弃
+//   - mq.ErrDeadLetter：不重试，拒绝且不重新入队（配置了死信队列时进入死信队列）
+//   - mq.ErrRetryAfter(d)，d > 0：未超过最大重试次数时发布到延迟队列后确认，超过时拒绝（配置了死信队列时进入死信队列）；
+//     发布到延迟队列失败时按普通错误处理
+//   - 其他错误：按 nackWithRetry 的规则立即重新入队或拒绝
+func (m *MessageQueue) settleFailed(ctx context.Context, msg amqp.Delivery, err error) {
+	if errors.Is(err, mq.ErrDiscard) {
+		m.stats.discarded.Add(1)
+		m.reportRetryDecision(msg, RetryActionDiscard, m.getRetryCount(msg), 0, err)
+		msg.Ack(false)
+		return
+	}
+	if errors.Is(err, mq.ErrDeadLetter) {
+		m.stats.deadLettered.Add(1)
+		m.reportRetryDecision(msg, RetryActionDeadLetter, m.getRetryCount(msg), 0, err)
+		msg.Nack(false, false)
+		return
+	}
+
+	delay, ok := mq.RetryAfter(err)
+	if !ok || delay <= 0 {
+		m.nackWithRetry(msg, err)
+		return
+	}
+
+	attempts := m.getRetryCount(msg)
+	if attempts >= m.ConsumeConfig.GetMaxRetry() {
+		m.stats.deadLettered.Add(1)
+		m.reportRetryDecision(msg, RetryActionDeadLetter, attempts, 0, err)
+		msg.Nack(false, false)
+		return
+	}
+	if pubErr := m.publishDelayed(ctx, msg, delay, attempts+1); pubErr != nil {
+		m.nackWithRetry(msg, fmt.Errorf("发布到延迟队列失败: %w, 消费错误: %w", pubErr, err))
+		return
+	}
+	m.stats.retried.Add(1)
+	m.stats.delayed.Add(1)
+	m.reportRetryDecision(msg, RetryActionDelay, attempts, delay, err)
+	msg.Ack(false)
+}
+
+// publishDelayed 将消息发布到延迟队列，过期时间为 delay，并在 RetryAttemptsHeader 中记录重试次数
+// 保留消息属性和业务消息头，移除 x-death 等死信消息头
+func (m *MessageQueue) publishDelayed(ctx context.Context, msg amqp.Delivery, delay time.Duration, attempts int) error {
+	if err := m.declareDelayQueue(); err != nil {
+		return err
+	}
+	publishing := replayPublishing(msg)
+	WithHeaders(amqp.Table{RetryAttemptsHeader: int32(attempts)})(&publishing)
+	WithTTL(delay)(&publishing)
+	// 通过默认交换机直接投递到延迟队列
+	return m.publishOne(ctx, "", m.DelayQueueName(), publishing)
+}
+
+// declareDelayQueue 声明延迟队列，已声明时直接返回
+// 延迟队列的死信交换机为默认交换机、死信路由键为主队列名称，消息过期后回到主队列
+func (m *MessageQueue) declareDelayQueue() error {
+	m.delayLock.Lock()
+	defer m.delayLock.Unlock()
+	if m.delayQueueDeclared {
+		return nil
+	}
+
+	ch, err := m.openAdminChannel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	_, err = ch.QueueDeclare(
+		m.DelayQueueName(), // name
+		true,               // durable
+		false,              // delete when unused
+		false,              // exclusive
+		false,              // no-wait
+		amqp.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": m.QueueName,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("创建延迟队列失败: queueInfo: %s, error: %w", m.GetInfo(), err)
+	}
+	m.delayQueueDeclared = true
+	return nil
+}
+
+// reportRetryDecision 回调 ConsumeConfig.OnRetryDecision
+func (m *MessageQueue) reportRetryDecision(msg amqp.Delivery, action string, attempts int, delay time.Duration, err error) {
+	if m.ConsumeConfig.OnRetryDecision == nil {
+		return
+	}
+	m.ConsumeConfig.OnRetryDecision(RetryDecision{
+		Queue:     m.GetInfo(),
+		MessageID: msg.MessageId,
+		Action:    action,
+		Attempts:  attempts,
+		MaxRetry:  m.ConsumeConfig.GetMaxRetry(),
+		Delay:     delay,
+		Err:       err,
+	})
+}
+
+// headerInt 将消息头中的整数值转换为 int，AMQP 表中的整数可能被解码为不同宽度的类型
+func headerInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int8:
+		return int(n), true
+	case int16:
+		return int(n), true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case uint8:
+		return int(n), true
+	case uint16:
+		return int(n), true
+	case uint32:
+		return int(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,430 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/zzsen/gin_core/exception/mq"
+)
+
+// ==================== 单元测试文件（不需要 RabbitMQ 连接） ====================
+//
+// 本文件测试消费失败后的重试控制，使用模拟的 Acknowledger 和发布通道驱动 handleMessage，
+// 连接 RabbitMQ 的延迟重新投递见集成测试 TestIntegration_RetryAfter。
+// 这些测试主要验证：
+// - 返回 mq.ErrDiscard 时确认并丢弃消息
+// - mq.TypedHandler 收到格式错误的消息时返回 mq.ErrDeadLetter，不重试，拒绝且不重新入队
+// - 返回 mq.ErrAlreadyProcessed 时按处理成功确认消息
+// - 返回 mq.ErrRetryAfter 时发布到延迟队列（过期时间、重试次数消息头、保留的消息属性）后确认
+// - 多次延迟重试后重试次数累加，超过 MaxRetry 时拒绝消息
+// - 发布到延迟队列失败时按普通错误重新入队，普通错误的处理不变
+
+// settlement 模拟确认器记录的一次确认或拒绝
+type settlement struct {
+	tag     uint64
+	ack     bool
+	requeue bool
+}
+
+// settleAcknowledger 模拟的消息确认器，记录确认、拒绝以及是否重新入队
+type settleAcknowledger struct {
+	mu      sync.Mutex
+	settled []settlement
+}
+
+func (a *settleAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.settled = append(a.settled, settlement{tag: tag, ack: true})
+	return nil
+}
+
+func (a *settleAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.settled = append(a.settled, settlement{tag: tag, requeue: requeue})
+	return nil
+}
+
+func (a *settleAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+// last 返回最近一次确认或拒绝
+func (a *settleAcknowledger) last() settlement {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.settled) == 0 {
+		return settlement{}
+	}
+	return a.settled[len(a.settled)-1]
+}
+
+// publishedMessage 模拟发布通道记录的消息
+type publishedMessage struct {
+	exchange   string
+	routingKey string
+	msg        amqp.Publishing
+}
+
+// recordingChannel 记录发布的消息，err 不为空时发布失败
+type recordingChannel struct {
+	mu        sync.Mutex
+	err       error
+	published []publishedMessage
+}
+
+func (c *recordingChannel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	c.published = append(c.published, publishedMessage{exchange: exchange, routingKey: key, msg: msg})
+	return nil
+}
+
+func (c *recordingChannel) Confirm(noWait bool) error { return nil }
+
+func (c *recordingChannel) NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation {
+	return confirm
+}
+
+func (c *recordingChannel) IsClosed() bool { return false }
+
+func (c *recordingChannel) Close() error { return nil }
+
+// newRetryConsumer 创建使用模拟发布通道的消费者，延迟队列视为已声明，返回记录的处理决定
+func newRetryConsumer(handlerErr func(msg string) error, maxRetry int, ch *recordingChannel) (*MessageQueue, *[]RetryDecision) {
+	var decisions []RetryDecision
+	m := &MessageQueue{
+		MQName:    "default",
+		QueueName: "order.paid",
+		FunWithCtx: func(ctx context.Context, msg string) error {
+			return handlerErr(msg)
+		},
+		ConsumeConfig: ConsumeConfig{
+			MaxRetry: maxRetry,
+			OnRetryDecision: func(decision RetryDecision) {
+				decisions = append(decisions, decision)
+			},
+		},
+		channelFactory:     func() (publishChannel, error) { return ch, nil },
+		delayQueueDeclared: true,
+	}
+	return m, &decisions
+}
+
+// redeliver 将发布到延迟队列的消息转换为过期后回到主队列的 Delivery
+// RabbitMQ 在过期时添加一条死于延迟队列的 x-death 记录，重新发布时移除了原 x-death，因此计数始终为 1
+func redeliver(pub publishedMessage, ack amqp.Acknowledger, tag uint64) amqp.Delivery {
+	headers := amqp.Table{}
+	for k, v := range pub.msg.Headers {
+		headers[k] = v
+	}
+	headers["x-death"] = []interface{}{amqp.Table{"count": int64(1), "queue": pub.routingKey, "reason": "expired"}}
+	return amqp.Delivery{
+		Acknowledger: ack,
+		DeliveryTag:  tag,
+		Headers:      headers,
+		MessageId:    pub.msg.MessageId,
+		Body:         pub.msg.Body,
+	}
+}
+
+// TestMessageQueue_Retry_Discard 测试丢弃消息
+//
+// 【功能点】验证消费函数返回（包装的）mq.ErrDiscard 时确认消息，不发布到延迟队列，不进入死信队列
+// 【测试流程】
+//  1. 消费函数返回 fmt.Errorf("...: %w", mq.ErrDiscard)
+//  2. 断言消息被确认、没有发布消息
+//  3. 断言 Discarded、Failed 计数和回调的处理决定
+func TestMessageQueue_Retry_Discard(t *testing.T) {
+	ch := &recordingChannel{}
+	m, decisions := newRetryConsumer(func(string) error {
+		return fmt.Errorf("消息格式错误: %w", mq.ErrDiscard)
+	}, 3, ch)
+	ack := &settleAcknowledger{}
+
+	m.handleMessage(context.Background(), amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, MessageId: "m-1", Body: []byte("bad")})
+
+	if got := ack.last(); !got.ack {
+		t.Errorf("期望确认消息，实际 %+v", got)
+	}
+	if len(ch.published) != 0 {
+		t.Errorf("丢弃的消息不应发布，实际发布 %d 条", len(ch.published))
+	}
+	stats := m.Stats()
+	if stats.Discarded != 1 || stats.Failed != 1 || stats.Retried != 0 || stats.DeadLettered != 0 {
+		t.Errorf("期望丢弃 1、失败 1、重试 0、超过重试次数 0，实际 %+v", stats)
+	}
+	if len(*decisions) != 1 {
+		t.Fatalf("期望 1 次处理决定，实际 %d", len(*decisions))
+	}
+	d := (*decisions)[0]
+	if d.Action != RetryActionDiscard || d.Queue != m.GetInfo() || d.MessageID != "m-1" || !errors.Is(d.Err, mq.ErrDiscard) {
+		t.Errorf("处理决定不符合预期: %+v", d)
+	}
+}
+
+// TestMessageQueue_Retry_AlreadyProcessed 测试已处理过的消息
+//
+// 【功能点】验证消费函数返回（包装的）mq.ErrAlreadyProcessed 时按处理成功确认消息，不重试、不计入失败
+// 【测试流程】
+//  1. 消费函数返回 fmt.Errorf("...: %w", mq.ErrAlreadyProcessed)
+//  2. 断言消息被确认、没有发布消息、没有处理决定
+//  3. 断言 Succeeded 为 1、Failed 为 0
+func TestMessageQueue_Retry_AlreadyProcessed(t *testing.T) {
+	ch := &recordingChannel{}
+	m, decisions := newRetryConsumer(func(string) error {
+		return fmt.Errorf("订单已创建: %w", mq.ErrAlreadyProcessed)
+	}, 3, ch)
+	ack := &settleAcknowledger{}
+
+	m.handleMessage(context.Background(), amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, MessageId: "m-1", Body: []byte("order")})
+
+	if got := ack.last(); !got.ack {
+		t.Errorf("期望确认消息，实际 %+v", got)
+	}
+	if len(ch.published) != 0 || len(*decisions) != 0 {
+		t.Errorf("已处理过的消息不应重试，实际发布 %d 条、处理决定 %d 次", len(ch.published), len(*decisions))
+	}
+	if stats := m.Stats(); stats.Succeeded != 1 || stats.Failed != 0 {
+		t.Errorf("期望成功 1、失败 0，实际 %+v", stats)
+	}
+}
+
+// TestMessageQueue_Retry_After 测试延迟重试
+//
+// 【功能点】验证消费函数返回 mq.ErrRetryAfter 时消息发布到延迟队列后确认
+// 【测试流程】
+//  1. 消费函数返回 mq.ErrRetryAfter(5s)，投递带业务消息头、x-death 和优先级的消息
+//  2. 断言通过默认交换机发布到 DelayQueueName，过期时间为 5000 毫秒
+//  3. 断言保留 MessageId、消息体、优先级和业务消息头，移除 x-death，RetryAttemptsHeader 为 1
+//  4. 断言原消息被确认，Retried、Delayed 计数和回调的处理决定
+func TestMessageQueue_Retry_After(t *testing.T) {
+	ch := &recordingChannel{}
+	m, decisions := newRetryConsumer(func(string) error {
+		return mq.ErrRetryAfter(5 * time.Second)
+	}, 3, ch)
+	ack := &settleAcknowledger{}
+
+	m.handleMessage(context.Background(), amqp.Delivery{
+		Acknowledger: ack,
+		DeliveryTag:  1,
+		MessageId:    "m-1",
+		Priority:     5,
+		Body:         []byte("payload"),
+		Headers: amqp.Table{
+			"tenant":  "t1",
+			"x-death": []interface{}{amqp.Table{"count": int64(0), "queue": "order.paid", "reason": "rejected"}},
+		},
+	})
+
+	if len(ch.published) != 1 {
+		t.Fatalf("期望发布 1 条消息，实际 %d", len(ch.published))
+	}
+	pub := ch.published[0]
+	if pub.exchange != "" || pub.routingKey != "order.paid.delay" || pub.routingKey != m.DelayQueueName() {
+		t.Errorf("期望通过默认交换机发布到 order.paid.delay，实际 exchange=%q routingKey=%q", pub.exchange, pub.routingKey)
+	}
+	if pub.msg.Expiration != "5000" {
+		t.Errorf("期望过期时间 5000 毫秒，实际 %q", pub.msg.Expiration)
+	}
+	if pub.msg.MessageId != "m-1" || string(pub.msg.Body) != "payload" || pub.msg.Priority != 5 {
+		t.Errorf("消息属性未保留: %+v", pub.msg)
+	}
+	wantHeaders := amqp.Table{"tenant": "t1", RetryAttemptsHeader: int32(1)}
+	if !reflect.DeepEqual(pub.msg.Headers, wantHeaders) {
+		t.Errorf("期望消息头 %v，实际 %v", wantHeaders, pub.msg.Headers)
+	}
+	if got := ack.last(); !got.ack {
+		t.Errorf("发布到延迟队列后期望确认原消息，实际 %+v", got)
+	}
+
+	stats := m.Stats()
+	if stats.Retried != 1 || stats.Delayed != 1 || stats.DeadLettered != 0 {
+		t.Errorf("期望重试 1、延迟 1、超过重试次数 0，实际 %+v", stats)
+	}
+	want := RetryDecision{Queue: m.GetInfo(), MessageID: "m-1", Action: RetryActionDelay, Attempts: 0, MaxRetry: 3, Delay: 5 * time.Second}
+	if len(*decisions) != 1 {
+		t.Fatalf("期望 1 次处理决定，实际 %d", len(*decisions))
+	}
+	got := (*decisions)[0]
+	got.Err = nil
+	if got != want {
+		t.Errorf("期望处理决定 %+v，实际 %+v", want, got)
+	}
+}
+
+// TestMessageQueue_Retry_MaxRetryAcrossDelay 测试多次延迟重试后的最大重试次数
+//
+// 【功能点】验证延迟重试的消息回到主队列后重试次数累加，超过 MaxRetry 后拒绝且不重新入队
+// 【测试流程】
+//  1. MaxRetry 为 3，消费函数始终返回 mq.ErrRetryAfter(time.Second)
+//  2. 将每次发布到延迟队列的消息模拟为过期后回到主队列（x-death 计数为 1）再次投递
+//  3. 断言前 3 次发布到延迟队列，消息头中的重试次数依次为 1、2、3
+//  4. 断言第 4 次消费后拒绝且不重新入队，处理决定依次为 delay×3、deadLetter
+func TestMessageQueue_Retry_MaxRetryAcrossDelay(t *testing.T) {
+	ch := &recordingChannel{}
+	m, decisions := newRetryConsumer(func(string) error {
+		return mq.ErrRetryAfter(time.Second)
+	}, 3, ch)
+	ack := &settleAcknowledger{}
+
+	msg := amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, MessageId: "m-1", Body: []byte("payload")}
+	for i := 1; i <= 4; i++ {
+		m.handleMessage(context.Background(), msg)
+		if i <= 3 {
+			if len(ch.published) != i {
+				t.Fatalf("第 %d 次消费后期望共发布 %d 条消息，实际 %d", i, i, len(ch.published))
+			}
+			pub := ch.published[i-1]
+			if got := pub.msg.Headers[RetryAttemptsHeader]; got != int32(i) {
+				t.Errorf("第 %d 次发布的重试次数期望 %d，实际 %v", i, i, got)
+			}
+			msg = redeliver(pub, ack, uint64(i+1))
+		}
+	}
+
+	if len(ch.published) != 3 {
+		t.Errorf("超过最大重试次数后不应再发布，实际共发布 %d 条", len(ch.published))
+	}
+	if got := ack.last(); got.ack || got.requeue || got.tag != 4 {
+		t.Errorf("第 4 次消费后期望拒绝且不重新入队，实际 %+v", got)
+	}
+	var actions []string
+	for _, d := range *decisions {
+		actions = append(actions, d.Action)
+	}
+	wantActions := []string{RetryActionDelay, RetryActionDelay, RetryActionDelay, RetryActionDeadLetter}
+	if !reflect.DeepEqual(actions, wantActions) {
+		t.Errorf("期望处理决定 %v，实际 %v", wantActions, actions)
+	}
+	if last := (*decisions)[3]; last.Attempts != 3 || last.MaxRetry != 3 {
+		t.Errorf("超过重试次数时期望 Attempts=3、MaxRetry=3，实际 %+v", last)
+	}
+	stats := m.Stats()
+	if stats.Delayed != 3 || stats.DeadLettered != 1 {
+		t.Errorf("期望延迟 3、超过重试次数 1，实际 %+v", stats)
+	}
+}
+
+// TestMessageQueue_Retry_PublishFailure 测试发布到延迟队列失败
+//
+// 【功能点】验证发布到延迟队列失败时按普通错误处理：立即重新入队，错误同时包含发布错误和消费错误
+// 【测试流程】
+//  1. 模拟发布通道返回错误，消费函数返回 mq.ErrRetryAfter(time.Second)
+//  2. 断言消息被拒绝并重新入队，Delayed 为 0、Retried 为 1
+//  3. 断言处理决定为 requeue，错误可通过 errors.Is / mq.RetryAfter 识别
+func TestMessageQueue_Retry_PublishFailure(t *testing.T) {
+	publishErr := errors.New("channel closed")
+	ch := &recordingChannel{err: publishErr}
+	m, decisions := newRetryConsumer(func(string) error {
+		return mq.ErrRetryAfter(time.Second)
+	}, 3, ch)
+	ack := &settleAcknowledger{}
+
+	m.handleMessage(context.Background(), amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, MessageId: "m-1"})
+
+	if got := ack.last(); got.ack || !got.requeue {
+		t.Errorf("期望拒绝并重新入队，实际 %+v", got)
+	}
+	stats := m.Stats()
+	if stats.Delayed != 0 || stats.Retried != 1 {
+		t.Errorf("期望延迟 0、重试 1，实际 %+v", stats)
+	}
+	if len(*decisions) != 1 || (*decisions)[0].Action != RetryActionRequeue {
+		t.Fatalf("期望处理决定为 requeue，实际 %+v", *decisions)
+	}
+	err := (*decisions)[0].Err
+	if _, ok := mq.RetryAfter(err); !ok || !errors.Is(err, publishErr) {
+		t.Errorf("错误应同时包含消费错误和发布错误，实际 %v", err)
+	}
+}
+
+// TestMessageQueue_Retry_OrdinaryError 测试普通错误的处理不变
+//
+// 【功能点】验证普通错误未超过重试次数时立即重新入队，超过时拒绝，不发布到延迟队列
+// 【测试流程】
+//  1. 消费函数返回普通错误，投递不带消息头的消息，断言重新入队、处理决定为 requeue
+//  2. 投递重试次数消息头达到 MaxRetry 的消息，断言拒绝且不重新入队、处理决定为 deadLetter
+//  3. 断言没有发布消息
+func TestMessageQueue_Retry_OrdinaryError(t *testing.T) {
+	ch := &recordingChannel{}
+	m, decisions := newRetryConsumer(func(string) error {
+		return errors.New("db timeout")
+	}, 2, ch)
+	ack := &settleAcknowledger{}
+
+	m.handleMessage(context.Background(), amqp.Delivery{Acknowledger: ack, DeliveryTag: 1})
+	if got := ack.last(); got.ack || !got.requeue {
+		t.Errorf("未超过重试次数时期望重新入队，实际 %+v", got)
+	}
+
+	m.handleMessage(context.Background(), amqp.Delivery{Acknowledger: ack, DeliveryTag: 2, Headers: amqp.Table{RetryAttemptsHeader: int32(2)}})
+	if got := ack.last(); got.ack || got.requeue {
+		t.Errorf("超过重试次数时期望拒绝且不重新入队，实际 %+v", got)
+	}
+
+	if len(ch.published) != 0 {
+		t.Errorf("普通错误不应发布到延迟队列，实际发布 %d 条", len(ch.published))
+	}
+	if len(*decisions) != 2 || (*decisions)[0].Action != RetryActionRequeue || (*decisions)[1].Action != RetryActionDeadLetter {
+		t.Errorf("期望处理决定依次为 requeue、deadLetter，实际 %+v", *decisions)
+	}
+}
+
+// TestMessageQueue_Retry_TypedHandler 测试类型化消费函数的格式错误消息
+//
+// 【功能点】验证 mq.TypedHandler 收到格式错误的消息时不重试，拒绝且不重新入队（进入死信队列），WithDiscardInvalid 时确认并丢弃
+// 【测试流程】
+//  1. FunWithCtx 为 mq.TypedHandler，投递合法消息，断言消息被确认、Succeeded 为 1
+//  2. 投递格式错误的消息（MaxRetry 为 3、未重试过），断言拒绝且不重新入队，处理决定为 deadLetter、已重试次数为 0
+//  3. 断言 DeadLettered 为 1、Retried 为 0，没有发布消息
+//  4. 设置 WithDiscardInvalid，断言格式错误的消息被确认，处理决定为 discard
+func TestMessageQueue_Retry_TypedHandler(t *testing.T) {
+	type orderPaid struct {
+		OrderID string `json:"orderId"`
+	}
+	var received []string
+	ch := &recordingChannel{}
+	m, decisions := newRetryConsumer(nil, 3, ch)
+	m.FunWithCtx = mq.TypedHandler(func(ctx context.Context, msg orderPaid) error {
+		received = append(received, msg.OrderID)
+		return nil
+	})
+	ack := &settleAcknowledger{}
+
+	m.handleMessage(context.Background(), amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, MessageId: "m-1", Body: []byte(`{"orderId":"o-1"}`)})
+	if got := ack.last(); !got.ack || !reflect.DeepEqual(received, []string{"o-1"}) {
+		t.Errorf("合法消息期望确认并交给消费函数，实际 %+v, received: %v", got, received)
+	}
+
+	m.handleMessage(context.Background(), amqp.Delivery{Acknowledger: ack, DeliveryTag: 2, MessageId: "m-2", Body: []byte(`{"orderId":`)})
+	if got := ack.last(); got.ack || got.requeue {
+		t.Errorf("格式错误的消息期望拒绝且不重新入队，实际 %+v", got)
+	}
+	if len(*decisions) != 1 {
+		t.Fatalf("期望 1 次处理决定，实际 %d", len(*decisions))
+	}
+	d := (*decisions)[0]
+	if d.Action != RetryActionDeadLetter || d.Attempts != 0 || d.MessageID != "m-2" || !errors.Is(d.Err, mq.ErrDeadLetter) {
+		t.Errorf("处理决定不符合预期: %+v", d)
+	}
+	if stats := m.Stats(); stats.Succeeded != 1 || stats.DeadLettered != 1 || stats.Retried != 0 || len(ch.published) != 0 {
+		t.Errorf("期望成功 1、进入死信队列 1、重试 0、未发布消息，实际 %+v, 发布 %d 条", stats, len(ch.published))
+	}
+
+	m.FunWithCtx = mq.TypedHandler(func(ctx context.Context, msg orderPaid) error { return nil }, mq.WithDiscardInvalid())
+	m.handleMessage(context.Background(), amqp.Delivery{Acknowledger: ack, DeliveryTag: 3, MessageId: "m-3", Body: []byte("bad")})
+	if got := ack.last(); !got.ack || (*decisions)[1].Action != RetryActionDiscard {
+		t.Errorf("WithDiscardInvalid 时期望确认并丢弃，实际 %+v, 处理决定 %+v", got, (*decisions)[1])
+	}
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConsumerStats 消费者统计信息，可直接序列化为 JSON，供监控接口和管理接口输出
+type ConsumerStats struct {
+	Queue         string     `json:"queue"`                   // 队列标识（GetInfo）
+	Consumed      uint64     `json:"consumed"`                // 收到的消息数，包含因重复而跳过的消息
+	Succeeded     uint64     `json:"succeeded"`               // 处理成功的消息数
+	Failed        uint64     `json:"failed"`                  // 处理失败的消息数
+	Retried       uint64     `json:"retried"`                 // 失败后重新入队的消息数，包含延迟重试的消息
+	Delayed       uint64     `json:"delayed"`                 // 消费函数返回 mq.ErrRetryAfter 后发布到延迟队列的消息数
+	DeadLettered  uint64     `json:"deadLettered"`            // 超过最大重试次数或返回 mq.ErrDeadLetter 被拒绝的消息数，配置了死信队列时进入死信队列
+	Discarded     uint64     `json:"discarded"`               // 消费函数返回 mq.ErrDiscard 后确认并丢弃的消息数
+	Duplicates    uint64     `json:"duplicates"`              // 因重复而跳过的消息数
+	InFlight      int64      `json:"inFlight"`                // 正在处理的消息数
+	LastMessageAt *time.Time `json:"lastMessageAt,omitempty"` // 最近一次收到消息的时间
+	AvgDurationMs float64    `json:"avgDurationMs"`           // 滑动窗口内处理函数的平均耗时（毫秒），批量消费时为每批的耗时
+	P95DurationMs float64    `json:"p95DurationMs"`           // 滑动窗口内处理函数耗时的 95 分位（毫秒）
+	QueueDepth    int        `json:"queueDepth"`              // 队列中待消费的消息数，即消费积压量
+	QueueDepthAt  *time.Time `json:"queueDepthAt,omitempty"`  // 最近一次获取队列深度的时间，为空表示尚未获取
+	QueueDepthErr string     `json:"queueDepthErr,omitempty"` // 最近一次获取队列深度失败的原因
+}
+
+// consumerStats 消费者统计计数器
+// 计数器使用原子操作，处理耗时的滑动窗口由互斥锁保护
+type consumerStats struct {
+	consumed      atomic.Uint64
+	succeeded     atomic.Uint64
+	failed        atomic.Uint64
+	retried       atomic.Uint64
+	delayed       atomic.Uint64
+	deadLettered  atomic.Uint64
+	discarded     atomic.Uint64
+	inFlight      atomic.Int64
+	lastMessageAt atomic.Int64 // UnixNano，0 表示尚未收到消息
+
+	depthMu    sync.Mutex
+	depth      int
+	depthAt    time.Time
+	depthError string
+
+	windowMu  sync.Mutex
+	durations []time.Duration // 环形缓冲区，首次记录时按 StatsWindow 分配
+	next      int
+	full      bool
+}
+
+// GetStatsWindow 获取统计处理耗时的滑动窗口大小（最近的处理次数），如果未配置则返回 1000
+func (c *ConsumeConfig) GetStatsWindow() int {
+	if c.StatsWindow <= 0 {
+		return 1000
+	}
+	return c.StatsWindow
+}
+
+// GetQueueDepthInterval 获取查询队列深度的间隔，如果未配置则返回 30s
+func (c *ConsumeConfig) GetQueueDepthInterval() time.Duration {
+	if c.QueueDepthInterval <= 0 {
+		return 30 * time.Second
+	}
+	return c.QueueDepthInterval
+}
+
+// received 记录收到 n 条消息
+func (s *consumerStats) received(n int, now time.Time) {
+	s.consumed.Add(uint64(n))
+	s.lastMessageAt.Store(now.UnixNano())
+}
+
+// observe 记录一次处理函数的耗时
+func (s *consumerStats) observe(window int, d time.Duration) {
+	s.windowMu.Lock()
+	defer s.windowMu.Unlock()
+	if s.durations == nil {
+		s.durations = make([]time.Duration, window)
+	}
+	s.durations[s.next] = d
+	s.next++
+	if s.next == len(s.durations) {
+		s.next = 0
+		s.full = true
+	}
+}
+
+// durationSummary 计算滑动窗口内处理耗时的平均值和 95 分位
+func (s *consumerStats) durationSummary() (avg, p95 time.Duration) {
+	s.windowMu.Lock()
+	n := s.next
+	if s.full {
+		n = len(s.durations)
+	}
+	window := slices.Clone(s.durations[:n])
+	s.windowMu.Unlock()
+
+	if len(window) == 0 {
+		return 0, 0
+	}
+	var total time.Duration
+	for _, d := range window {
+		total += d
+	}
+	slices.Sort(window)
+	return total / time.Duration(len(window)), percentile(window, 0.95)
+}
+
+// setQueueDepth 记录队列深度的查询结果，查询失败时保留上一次的深度
+func (s *consumerStats) setQueueDepth(depth int, at time.Time, err error) {
+	s.depthMu.Lock()
+	defer s.depthMu.Unlock()
+	if err != nil {
+		s.depthError = err.Error()
+		return
+	}
+	s.depth = depth
+	s.depthAt = at
+	s.depthError = ""
+}
+
+// percentile 按最近秩法计算已排序数据的 p 分位（0 < p <= 1）
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// durationMs 将耗时转换为毫秒
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Stats 获取消费者统计信息，并发安全
+func (m *MessageQueue) Stats() ConsumerStats {
+	stats := ConsumerStats{
+		Queue:        m.GetInfo(),
+		Consumed:     m.stats.consumed.Load(),
+		Succeeded:    m.stats.succeeded.Load(),
+		Failed:       m.stats.failed.Load(),
+		Retried:      m.stats.retried.Load(),
+		Delayed:      m.stats.delayed.Load(),
+		DeadLettered: m.stats.deadLettered.Load(),
+		Discarded:    m.stats.discarded.Load(),
+		Duplicates:   m.counters.duplicates.Load(),
+		InFlight:     m.stats.inFlight.Load(),
+	}
+	if nanos := m.stats.lastMessageAt.Load(); nanos > 0 {
+		at := time.Unix(0, nanos)
+		stats.LastMessageAt = &at
+	}
+	avg, p95 := m.stats.durationSummary()
+	stats.AvgDurationMs = durationMs(avg)
+	stats.P95DurationMs = durationMs(p95)
+
+	m.stats.depthMu.Lock()
+	stats.QueueDepth = m.stats.depth
+	if !m.stats.depthAt.IsZero() {
+		at := m.stats.depthAt
+		stats.QueueDepthAt = &at
+	}
+	stats.QueueDepthErr = m.stats.depthError
+	m.stats.depthMu.Unlock()
+	return stats
+}
+
+// RefreshQueueDepth 查询队列中待消费的消息数并计入统计信息
+// 使用独立通道被动声明队列，队列不存在时只关闭该通道，不影响消费通道
+//
+// 返回：
+//   - int: 队列中待消费的消息数
+//   - error: 连接失败或队列不存在时返回错误
+func (m *MessageQueue) RefreshQueueDepth(ctx context.Context) (int, error) {
+	depth, err := m.queryQueueDepth(ctx)
+	m.stats.setQueueDepth(depth, time.Now(), err)
+	return depth, err
+}
+
+// queryQueueDepth 通过被动声明获取队列中待消费的消息数
+func (m *MessageQueue) queryQueueDepth(ctx context.Context) (int, error) {
+	ch, err := m.openAdminChannel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	q, err := ch.QueueDeclarePassive(m.QueueName, true, false, false, false, nil)
+	if err != nil {
+		return 0, fmt.Errorf("获取队列信息失败, queueInfo: %s, error: %w", m.GetInfo(), err)
+	}
+	return q.Messages, nil
+}
+
+// pollQueueDepth 按 ConsumeConfig.QueueDepthInterval 定期查询队列深度，直到 ctx 结束
+func (m *MessageQueue) pollQueueDepth(ctx context.Context) {
+	ticker := time.NewTicker(m.ConsumeConfig.GetQueueDepthInterval())
+	defer ticker.Stop()
+	for {
+		m.RefreshQueueDepth(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrPublishThrottled 发布被限流：令牌不足且预计等待时间超过 PublishRateLimit.MaxWait
+// 调用方可视为可重试的错误，稍后重新发布
+var ErrPublishThrottled = errors.New("消息发布被限流")
+
+// DefaultPublishMaxWait 发布限流的默认最长等待时间
+const DefaultPublishMaxWait = time.Second
+
+// PublishRateLimitConfig 发布限流配置
+// 同一 RabbitMQ 实例（按别名区分）的所有发送者共享一个令牌桶，每条消息消耗一个令牌
+type PublishRateLimitConfig struct {
+	// Enabled 是否启用发布限流
+	Enabled bool `yaml:"enabled"`
+	// MessagesPerSecond 每秒允许发布的消息数，即令牌的生成速率，不大于 0 时不限流
+	MessagesPerSecond float64 `yaml:"messagesPerSecond"`
+	// Burst 令牌桶容量（允许的突发消息数），同时也是批量发布时每批消耗令牌的最大消息数，默认为 MessagesPerSecond 向上取整（至少为 1）
+	Burst int `yaml:"burst"`
+	// MaxWait 令牌不足时的最长等待时间，预计等待时间超过该值时返回 ErrPublishThrottled，默认 1s，小于 0 时不等待
+	MaxWait time.Duration `yaml:"maxWait"`
+}
+
+// GetBurst 获取令牌桶容量，如果未配置则返回 MessagesPerSecond 向上取整（至少为 1）
+func (c PublishRateLimitConfig) GetBurst() int {
+	if c.Burst > 0 {
+		return c.Burst
+	}
+	return max(1, int(math.Ceil(c.MessagesPerSecond)))
+}
+
+// GetMaxWait 获取最长等待时间，如果未配置则返回 DefaultPublishMaxWait，小于 0 时返回 0（不等待）
+func (c PublishRateLimitConfig) GetMaxWait() time.Duration {
+	if c.MaxWait == 0 {
+		return DefaultPublishMaxWait
+	}
+	return max(c.MaxWait, 0)
+}
+
+// PublishThrottleStats 发布限流统计信息
+type PublishThrottleStats struct {
+	Delayed   int64   `json:"delayed"`     // 因令牌不足等待后发布的次数
+	Throttled int64   `json:"throttled"`   // 因预计等待时间超过 maxWait 返回 ErrPublishThrottled 的次数
+	AvgWaitMs float64 `json:"avg_wait_ms"` // 等待后发布的平均等待时间（毫秒）
+}
+
+// PublishThrottle 发布限流器（令牌桶）
+// 令牌按 MessagesPerSecond 的速率生成，最多积累 Burst 个；令牌不足时预占令牌并等待到令牌生成，
+// 先到的发布先获得令牌，等待期间 context 结束时归还预占的令牌。并发安全
+type PublishThrottle struct {
+	rate    float64       // 每秒生成的令牌数
+	burst   int           // 令牌桶容量
+	maxWait time.Duration // 最长等待时间
+
+	mu     sync.Mutex
+	tokens float64   // 当前令牌数，有预占时为负数
+	last   time.Time // 上次计算令牌数的时间
+
+	delayed   atomic.Int64
+	throttled atomic.Int64
+	waitNanos atomic.Int64 // 等待后发布的累计等待时间
+
+	// now 获取当前时间，单元测试中可替换
+	now func() time.Time
+	// newTimer 创建等待令牌的定时器，返回到期通道和停止函数，单元测试中可替换
+	newTimer func(d time.Duration) (<-chan time.Time, func() bool)
+}
+
+// NewPublishThrottle 根据配置创建发布限流器
+// 参数：
+//   - cfg: 发布限流配置
+//
+// 返回：
+//   - *PublishThrottle: 未启用或 MessagesPerSecond 不大于 0 时返回 nil（不限流）
+func NewPublishThrottle(cfg PublishRateLimitConfig) *PublishThrottle {
+	if !cfg.Enabled || cfg.MessagesPerSecond <= 0 {
+		return nil
+	}
+	t := &PublishThrottle{
+		rate:    cfg.MessagesPerSecond,
+		burst:   cfg.GetBurst(),
+		maxWait: cfg.GetMaxWait(),
+		now:     time.Now,
+		newTimer: func(d time.Duration) (<-chan time.Time, func() bool) {
+			timer := time.NewTimer(d)
+			return timer.C, timer.Stop
+		},
+	}
+	t.tokens = float64(t.burst)
+	t.last = t.now()
+	return t
+}
+
+// Stats 获取发布限流统计信息，t 为 nil 时返回零值
+func (t *PublishThrottle) Stats() PublishThrottleStats {
+	if t == nil {
+		return PublishThrottleStats{}
+	}
+	stats := PublishThrottleStats{
+		Delayed:   t.delayed.Load(),
+		Throttled: t.throttled.Load(),
+	}
+	if stats.Delayed > 0 {
+		stats.AvgWaitMs = float64(t.waitNanos.Load()) / float64(stats.Delayed) / float64(time.Millisecond)
+	}
+	return stats
+}
+
+// chunkSize 批量发布时每批消耗令牌的最大消息数，t 为 nil 时不分批
+func (t *PublishThrottle) chunkSize(total int) int {
+	if t == nil {
+		return total
+	}
+	return min(t.burst, total)
+}
+
+// acquire 获取 n 个令牌（n 不应超过 burst），t 为 nil 时直接返回
+// 令牌充足时立即返回；不足时预占令牌并等待，预计等待时间超过 maxWait 时不预占并返回 ErrPublishThrottled，
+// 等待期间 ctx 结束时归还令牌并返回 ctx 的错误
+func (t *PublishThrottle) acquire(ctx context.Context, n int) error {
+	if t == nil {
+		return nil
+	}
+
+	wait, ok := t.reserve(n)
+	if !ok {
+		t.throttled.Add(1)
+		return fmt.Errorf("%w, 预计等待: %s, 最长等待: %s", ErrPublishThrottled, wait, t.maxWait)
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	c, stop := t.newTimer(wait)
+	defer stop()
+	select {
+	case <-c:
+		t.delayed.Add(1)
+		t.waitNanos.Add(int64(wait))
+		return nil
+	case <-ctx.Done():
+		t.release(n)
+		return fmt.Errorf("等待发布令牌时取消: %w", ctx.Err())
+	}
+}
+
+// reserve 预占 n 个令牌，返回需要等待的时间；等待时间超过 maxWait 时不预占并返回 false
+func (t *PublishThrottle) reserve(n int) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if elapsed := now.Sub(t.last); elapsed > 0 {
+		t.tokens = math.Min(float64(t.burst), t.tokens+elapsed.Seconds()*t.rate)
+		t.last = now
+	}
+
+	remaining := t.tokens - float64(n)
+	if remaining >= 0 {
+		t.tokens = remaining
+		return 0, true
+	}
+	wait := time.Duration(math.Ceil(-remaining / t.rate * float64(time.Second)))
+	if wait > t.maxWait {
+		return wait, false
+	}
+	t.tokens = remaining
+	return wait, true
+}
+
+// release 归还预占的 n 个令牌
+func (t *PublishThrottle) release(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tokens = math.Min(float64(t.burst), t.tokens+float64(n))
+}
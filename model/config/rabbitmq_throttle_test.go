@@ -0,0 +1,340 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ==================== 单元测试文件（不需要 RabbitMQ 连接） ====================
+//
+// 本文件测试发布限流器，使用模拟时钟替代真实时间，批量发布使用模拟的 publishChannel。
+// 这些测试主要验证：
+// - 令牌不足时按速率等待后发布
+// - 预计等待时间超过 maxWait 时立即返回 ErrPublishThrottled，且不消耗令牌
+// - 等待期间 context 取消时返回错误并归还令牌
+// - 批量发布按令牌桶容量分批消耗令牌
+// - 未启用限流时发布路径不分配内存
+// - 限流配置的默认值和统计信息
+
+// fakeClock 模拟时钟
+// auto 为 true 时创建定时器即推进时间到期并触发，否则定时器在 advance 推进到期后触发
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	auto    bool
+	timers  []fakeTimer
+	waiting chan struct{} // 创建定时器（开始等待）时发送通知
+}
+
+// fakeTimer 模拟定时器
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func newFakeClock(auto bool) *fakeClock {
+	return &fakeClock{now: time.Unix(1700000000, 0), auto: auto, waiting: make(chan struct{}, 16)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) newTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if c.auto {
+		c.now = c.now.Add(d)
+		ch <- c.now
+	} else {
+		c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), c: ch})
+	}
+	c.waiting <- struct{}{}
+	return ch, func() bool { return true }
+}
+
+// advance 推进时间并触发到期的定时器
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.c <- c.now
+	}
+	c.timers = pending
+}
+
+// newTestThrottle 创建使用模拟时钟的发布限流器
+func newTestThrottle(cfg PublishRateLimitConfig, clock *fakeClock) *PublishThrottle {
+	cfg.Enabled = true
+	t := NewPublishThrottle(cfg)
+	t.now = clock.Now
+	t.newTimer = clock.newTimer
+	t.last = clock.Now()
+	return t
+}
+
+// ==================== 单元测试：令牌桶（不需要 RabbitMQ 连接） ====================
+// 测试点：验证等待、限流和取消时的令牌计算
+
+// TestPublishThrottle_Pacing 测试按速率发布
+//
+// 【功能点】验证令牌用完后每次发布等待 1/rate 秒，统计信息记录等待次数和平均等待时间
+// 【测试流程】速率 5/s、容量 1，连续获取 6 个令牌，断言模拟时间经过 1s、等待 5 次、平均等待 200ms
+func TestPublishThrottle_Pacing(t *testing.T) {
+	clock := newFakeClock(true)
+	throttle := newTestThrottle(PublishRateLimitConfig{MessagesPerSecond: 5, Burst: 1, MaxWait: time.Second}, clock)
+	start := clock.Now()
+
+	for i := 0; i < 6; i++ {
+		if err := throttle.acquire(context.Background(), 1); err != nil {
+			t.Fatalf("第 %d 次获取令牌失败: %v", i+1, err)
+		}
+	}
+
+	if elapsed := clock.Now().Sub(start); elapsed != time.Second {
+		t.Errorf("模拟时间经过 %v, 期望 1s", elapsed)
+	}
+	stats := throttle.Stats()
+	if stats.Delayed != 5 || stats.Throttled != 0 {
+		t.Errorf("Delayed = %d, Throttled = %d, 期望 5, 0", stats.Delayed, stats.Throttled)
+	}
+	if stats.AvgWaitMs != 200 {
+		t.Errorf("AvgWaitMs = %v, 期望 200", stats.AvgWaitMs)
+	}
+}
+
+// TestPublishThrottle_MaxWaitExceeded 测试超过最长等待时间
+//
+// 【功能点】验证预计等待时间超过 maxWait 时立即返回 ErrPublishThrottled，且不预占令牌
+// 【测试流程】
+//  1. 速率 1/s、容量 1、maxWait 500ms，获取第一个令牌成功
+//  2. 再次获取需要等待 1s，断言返回 ErrPublishThrottled、没有创建定时器、Throttled 为 1
+//  3. 推进 1s 后获取令牌立即成功（被限流的请求没有消耗令牌）
+func TestPublishThrottle_MaxWaitExceeded(t *testing.T) {
+	clock := newFakeClock(false)
+	throttle := newTestThrottle(PublishRateLimitConfig{MessagesPerSecond: 1, Burst: 1, MaxWait: 500 * time.Millisecond}, clock)
+
+	if err := throttle.acquire(context.Background(), 1); err != nil {
+		t.Fatalf("获取第一个令牌失败: %v", err)
+	}
+	err := throttle.acquire(context.Background(), 1)
+	if !errors.Is(err, ErrPublishThrottled) {
+		t.Fatalf("错误 = %v, 期望 ErrPublishThrottled", err)
+	}
+	if len(clock.waiting) != 0 {
+		t.Error("被限流时不应等待")
+	}
+	if stats := throttle.Stats(); stats.Throttled != 1 || stats.Delayed != 0 {
+		t.Errorf("Throttled = %d, Delayed = %d, 期望 1, 0", stats.Throttled, stats.Delayed)
+	}
+
+	clock.advance(time.Second)
+	if err := throttle.acquire(context.Background(), 1); err != nil {
+		t.Errorf("令牌恢复后获取失败: %v", err)
+	}
+	if len(clock.waiting) != 0 {
+		t.Error("令牌充足时不应等待")
+	}
+}
+
+// TestPublishThrottle_ContextCanceled 测试等待期间取消
+//
+// 【功能点】验证等待令牌期间 context 取消时返回 context.Canceled，并归还预占的令牌
+// 【测试流程】
+//  1. 速率 1/s、容量 1、maxWait 2s，获取第一个令牌后在协程中获取第二个令牌进入等待
+//  2. 等待开始后取消 context，断言返回 context.Canceled 且不计入 Delayed
+//  3. 推进 1s 后获取令牌立即成功（取消的请求已归还令牌）
+func TestPublishThrottle_ContextCanceled(t *testing.T) {
+	clock := newFakeClock(false)
+	throttle := newTestThrottle(PublishRateLimitConfig{MessagesPerSecond: 1, Burst: 1, MaxWait: 2 * time.Second}, clock)
+	if err := throttle.acquire(context.Background(), 1); err != nil {
+		t.Fatalf("获取第一个令牌失败: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- throttle.acquire(ctx, 1) }()
+
+	<-clock.waiting
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("错误 = %v, 期望 context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("取消后 acquire 未返回")
+	}
+	if stats := throttle.Stats(); stats.Delayed != 0 {
+		t.Errorf("Delayed = %d, 期望 0", stats.Delayed)
+	}
+
+	clock.advance(time.Second)
+	if err := throttle.acquire(context.Background(), 1); err != nil {
+		t.Errorf("令牌恢复后获取失败: %v", err)
+	}
+	if len(clock.waiting) != 0 {
+		t.Error("取消的请求应归还令牌，令牌恢复后不应等待")
+	}
+}
+
+// ==================== 单元测试：批量发布（不需要 RabbitMQ 连接） ====================
+// 测试点：验证批量发布按令牌桶容量分批消耗令牌
+
+// TestPublishThrottle_BatchChunks 测试批量发布分批
+//
+// 【功能点】验证批量发布按容量分批，每批消耗与消息数相同的令牌，所有消息均被发布
+// 【测试流程】速率 100/s、容量 3，批量发布 10 条消息（3+3+3+1），断言全部发布、等待 3 次、模拟时间经过 70ms
+func TestPublishThrottle_BatchChunks(t *testing.T) {
+	clock := newFakeClock(true)
+	factory := &fakeChannelFactory{}
+	mq := newFakeProducer(1, true, factory)
+	mq.PublishThrottle = newTestThrottle(PublishRateLimitConfig{MessagesPerSecond: 100, Burst: 3, MaxWait: time.Second}, clock)
+	defer mq.Close()
+	start := clock.Now()
+
+	messages := make([]string, 10)
+	for i := range messages {
+		messages[i] = "msg"
+	}
+	if err := mq.PublishBatch(messages); err != nil {
+		t.Fatalf("批量发布失败: %v", err)
+	}
+
+	var published int32
+	for _, ch := range factory.channels {
+		published += atomic.LoadInt32(&ch.published)
+	}
+	if published != 10 {
+		t.Errorf("发布消息数 = %d, 期望 10", published)
+	}
+	if stats := mq.PublishThrottle.Stats(); stats.Delayed != 3 {
+		t.Errorf("Delayed = %d, 期望 3", stats.Delayed)
+	}
+	if elapsed := clock.Now().Sub(start); elapsed != 70*time.Millisecond {
+		t.Errorf("模拟时间经过 %v, 期望 70ms", elapsed)
+	}
+}
+
+// TestPublishThrottle_BatchThrottled 测试批量发布中途被限流
+//
+// 【功能点】验证某一批被限流时返回 ErrPublishThrottled，之前的批次已发布，错误信息包含已发布数量
+// 【测试流程】速率 1/s、容量 3、maxWait 500ms，批量发布 10 条消息，断言第一批发布后返回限流错误
+func TestPublishThrottle_BatchThrottled(t *testing.T) {
+	clock := newFakeClock(false)
+	factory := &fakeChannelFactory{}
+	mq := newFakeProducer(1, false, factory)
+	mq.PublishThrottle = newTestThrottle(PublishRateLimitConfig{MessagesPerSecond: 1, Burst: 3, MaxWait: 500 * time.Millisecond}, clock)
+	defer mq.Close()
+
+	err := mq.PublishBatch(make([]string, 10))
+	if !errors.Is(err, ErrPublishThrottled) {
+		t.Fatalf("错误 = %v, 期望 ErrPublishThrottled", err)
+	}
+	if !strings.Contains(err.Error(), "已发布: 3/10") {
+		t.Errorf("错误信息应包含已发布数量: %v", err)
+	}
+	var published int32
+	for _, ch := range factory.channels {
+		published += atomic.LoadInt32(&ch.published)
+	}
+	if published != 3 {
+		t.Errorf("发布消息数 = %d, 期望 3", published)
+	}
+}
+
+// ==================== 单元测试：未启用限流（不需要 RabbitMQ 连接） ====================
+// 测试点：验证未启用时不创建限流器，发布路径不分配内存
+
+// TestPublishThrottle_Disabled 测试未启用限流
+//
+// 【功能点】验证未启用或速率不大于 0 时返回 nil，nil 限流器的获取令牌和分批不分配内存
+// 【测试流程】
+//  1. 断言未启用、速率为 0 的配置返回 nil
+//  2. 使用 testing.AllocsPerRun 断言 acquire、chunkSize、Stats 的内存分配次数为 0
+func TestPublishThrottle_Disabled(t *testing.T) {
+	if NewPublishThrottle(PublishRateLimitConfig{MessagesPerSecond: 100}) != nil {
+		t.Error("未启用时应返回 nil")
+	}
+	if NewPublishThrottle(PublishRateLimitConfig{Enabled: true}) != nil {
+		t.Error("速率为 0 时应返回 nil")
+	}
+
+	var throttle *PublishThrottle
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := throttle.acquire(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		_ = throttle.chunkSize(100)
+		_ = throttle.Stats()
+	})
+	if allocs != 0 {
+		t.Errorf("未启用限流时分配次数 = %v, 期望 0", allocs)
+	}
+	if size := throttle.chunkSize(100); size != 100 {
+		t.Errorf("chunkSize = %d, 期望 100（不分批）", size)
+	}
+}
+
+// TestPublishRateLimitConfig_Defaults 测试配置默认值
+//
+// 【功能点】验证 GetBurst、GetMaxWait 的默认值
+// 【测试流程】分别测试未配置、显式配置和非法取值
+func TestPublishRateLimitConfig_Defaults(t *testing.T) {
+	tests := []struct {
+		cfg     PublishRateLimitConfig
+		burst   int
+		maxWait time.Duration
+	}{
+		{PublishRateLimitConfig{MessagesPerSecond: 100}, 100, DefaultPublishMaxWait},
+		{PublishRateLimitConfig{MessagesPerSecond: 2.5}, 3, DefaultPublishMaxWait},
+		{PublishRateLimitConfig{MessagesPerSecond: 0.5}, 1, DefaultPublishMaxWait},
+		{PublishRateLimitConfig{MessagesPerSecond: 100, Burst: 20, MaxWait: 5 * time.Second}, 20, 5 * time.Second},
+		{PublishRateLimitConfig{MessagesPerSecond: 100, MaxWait: -1}, 100, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.GetBurst(); got != tt.burst {
+			t.Errorf("%+v: GetBurst() = %d, 期望 %d", tt.cfg, got, tt.burst)
+		}
+		if got := tt.cfg.GetMaxWait(); got != tt.maxWait {
+			t.Errorf("%+v: GetMaxWait() = %v, 期望 %v", tt.cfg, got, tt.maxWait)
+		}
+	}
+}
+
+// ==================== 基准测试 ====================
+
+// BenchmarkPublishThrottle_Disabled 未启用限流时获取令牌的开销，应为 0 allocs/op
+func BenchmarkPublishThrottle_Disabled(b *testing.B) {
+	var throttle *PublishThrottle
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = throttle.acquire(ctx, 1)
+	}
+}
+
+// BenchmarkPublishThrottle_Enabled 启用限流且令牌充足时获取令牌的开销
+func BenchmarkPublishThrottle_Enabled(b *testing.B) {
+	throttle := NewPublishThrottle(PublishRateLimitConfig{Enabled: true, MessagesPerSecond: 1e12, Burst: 1 << 30})
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = throttle.acquire(ctx, 1)
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSConfig 根据证书配置生成 TLS 连接配置
+// 服务端名称（SNI）使用 Host，最低 TLS 版本为 1.2；配置了 CACertFile 时只信任该 CA 签发的服务端证书，
+// 配置了 CertFile 和 KeyFile 时发送客户端证书
+// 返回：
+//   - *tls.Config: TLS 连接配置，未开启 UseTLS 时返回 nil
+//   - error: 证书文件无法读取或解析、客户端证书和私钥未同时配置时返回错误
+func (rabbitMQInfo *RabbitMQInfo) TLSConfig() (*tls.Config, error) {
+	if !rabbitMQInfo.UseTLS {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         rabbitMQInfo.Host,
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: rabbitMQInfo.InsecureSkipVerify,
+	}
+
+	if rabbitMQInfo.CACertFile != "" {
+		caCert, err := os.ReadFile(rabbitMQInfo.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书文件失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("CA 证书文件 %s 中没有有效的 PEM 证书", rabbitMQInfo.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (rabbitMQInfo.CertFile == "") != (rabbitMQInfo.KeyFile == "") {
+		return nil, fmt.Errorf("客户端证书 certFile 和私钥 keyFile 必须同时配置")
+	}
+	if rabbitMQInfo.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(rabbitMQInfo.CertFile, rabbitMQInfo.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
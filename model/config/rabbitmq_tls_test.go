@@ -0,0 +1,174 @@
+// Package config RabbitMQ TLS 配置测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 RabbitMQInfo.TLSConfig 的单元测试，证书在临时目录中生成，不需要 RabbitMQ 连接。
+//
+// 测试覆盖内容：
+// 1. 未开启 UseTLS 时返回 nil
+// 2. 根据 CA 证书、客户端证书和私钥生成 TLS 配置，设置 SNI 和最低 TLS 版本
+// 3. 证书文件不存在、内容不是 PEM 证书、客户端证书和私钥未同时配置时返回错误
+// 4. 配置校验报告 TLS 证书配置的问题
+//
+// 运行测试：go test -v ./model/config/... -run TLS
+// ==================================================
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCertFiles 测试使用的证书文件路径
+type testCertFiles struct {
+	caCert     string
+	clientCert string
+	clientKey  string
+}
+
+// writeTestCerts 在临时目录中生成自签名 CA 证书，以及由该 CA 签发的客户端证书和私钥
+func writeTestCerts(t *testing.T) testCertFiles {
+	dir := t.TempDir()
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+		return path
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, clientTemplate, caTemplate, &clientKey.PublicKey, caKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	require.NoError(t, err)
+
+	return testCertFiles{
+		caCert:     writePEM("ca.pem", "CERTIFICATE", caDER),
+		clientCert: writePEM("client.pem", "CERTIFICATE", clientDER),
+		clientKey:  writePEM("client-key.pem", "EC PRIVATE KEY", keyDER),
+	}
+}
+
+// TestRabbitMQInfo_TLSConfig 测试生成 TLS 连接配置
+//
+// 【功能点】验证根据证书文件生成的 TLS 配置包含 CA、客户端证书、SNI、最低 TLS 版本和跳过校验设置
+// 【测试流程】
+//  1. 未开启 UseTLS，断言返回 nil
+//  2. 只配置 CA 证书，断言 RootCAs 已设置、没有客户端证书，ServerName 为 Host，MinVersion 为 TLS 1.2
+//  3. 同时配置客户端证书和私钥并开启 InsecureSkipVerify，断言包含一张客户端证书且跳过校验
+func TestRabbitMQInfo_TLSConfig(t *testing.T) {
+	certs := writeTestCerts(t)
+
+	info := RabbitMQInfo{Host: "mq.example.com", Port: 5671, CACertFile: certs.caCert}
+	tlsConfig, err := info.TLSConfig()
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	info.UseTLS = true
+	tlsConfig, err = info.TLSConfig()
+	require.NoError(t, err)
+	require.NotNil(t, tlsConfig)
+	assert.NotNil(t, tlsConfig.RootCAs)
+	assert.Empty(t, tlsConfig.Certificates)
+	assert.Equal(t, "mq.example.com", tlsConfig.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.False(t, tlsConfig.InsecureSkipVerify)
+
+	info.CertFile = certs.clientCert
+	info.KeyFile = certs.clientKey
+	info.InsecureSkipVerify = true
+	tlsConfig, err = info.TLSConfig()
+	require.NoError(t, err)
+	assert.Len(t, tlsConfig.Certificates, 1)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+}
+
+// TestRabbitMQInfo_TLSConfig_Errors 测试生成 TLS 连接配置的错误情况
+//
+// 【功能点】验证证书文件不存在、CA 文件不是 PEM 证书、客户端证书和私钥未同时配置或不匹配时返回错误
+// 【测试流程】分别构造非法的证书配置，断言 TLSConfig 返回包含原因的错误
+func TestRabbitMQInfo_TLSConfig_Errors(t *testing.T) {
+	certs := writeTestCerts(t)
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+
+	tests := []struct {
+		name    string
+		info    RabbitMQInfo
+		wantErr string
+	}{
+		{"ca missing", RabbitMQInfo{UseTLS: true, CACertFile: missing}, "读取 CA 证书文件失败"},
+		{"ca invalid", RabbitMQInfo{UseTLS: true, CACertFile: certs.clientKey}, "没有有效的 PEM 证书"},
+		{"key only", RabbitMQInfo{UseTLS: true, KeyFile: certs.clientKey}, "必须同时配置"},
+		{"cert missing", RabbitMQInfo{UseTLS: true, CertFile: missing, KeyFile: certs.clientKey}, "加载客户端证书失败"},
+		{"key mismatch", RabbitMQInfo{UseTLS: true, CertFile: certs.caCert, KeyFile: certs.clientKey}, "加载客户端证书失败"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.info.TLSConfig()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// TestValidate_RabbitMQTLS 测试 RabbitMQ TLS 配置校验
+//
+// 【功能点】验证开启 TLS 时报告客户端证书和私钥未同时配置、证书文件无法读取、证书无法解析的问题，未开启 TLS 时不检查
+// 【测试流程】
+//  1. 默认实例只配置私钥，多实例配置的 CA 文件不存在，断言报告 rabbitMQ.certFile 和 rabbitMQList[0].caCertFile
+//  2. 多实例配置的 CA 文件不是证书，断言报告 rabbitMQList[0].useTLS
+//  3. 修正证书配置，断言没有问题；关闭 TLS 后即使证书文件不存在也没有问题
+func TestValidate_RabbitMQTLS(t *testing.T) {
+	certs := writeTestCerts(t)
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+	cfg := &BaseConfig{
+		System:       SystemInfo{UseRabbitMQ: true},
+		RabbitMQ:     RabbitMQInfo{Host: "127.0.0.1", UseTLS: true, KeyFile: certs.clientKey},
+		RabbitMQList: RabbitMqListInfo{{AliasName: "orders", Host: "127.0.0.1", UseTLS: true, CACertFile: missing}},
+	}
+	assert.Equal(t, []string{"rabbitMQ.certFile", "rabbitMQList[0].caCertFile"}, issueFields(Validate(cfg)))
+
+	cfg.RabbitMQ.CertFile = certs.clientCert
+	cfg.RabbitMQList[0].CACertFile = certs.clientKey
+	assert.Equal(t, []string{"rabbitMQList[0].useTLS"}, issueFields(Validate(cfg)))
+
+	cfg.RabbitMQList[0].CACertFile = certs.caCert
+	assert.Empty(t, Validate(cfg))
+
+	cfg.RabbitMQList[0] = RabbitMQInfo{AliasName: "orders", Host: "127.0.0.1", CACertFile: missing}
+	assert.Empty(t, Validate(cfg))
+}
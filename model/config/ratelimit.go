@@ -17,8 +17,47 @@ type RateLimitConfig struct {
 	Rules []RateLimitRule `yaml:"rules"`
 	// Message 默认限流提示消息
 	Message string `yaml:"message"`
-	// CleanupInterval 内存限流器清理过期条目的间隔（秒），默认 60
+	// CleanupInterval 内存限流器清理过期条目的间隔（秒），默认 60；配置了 memoryStore.cleanupInterval 时以其为准
 	CleanupInterval int `yaml:"cleanupInterval"`
+	// MemoryStore 内存限流器的分片、空闲清理和数量上限配置，仅 store 为 memory 时有效
+	MemoryStore RateLimitMemoryStoreConfig `yaml:"memoryStore"`
+}
+
+// RateLimitMemoryStoreConfig 内存限流器配置
+// 限流键（如 ip:path）在扫描或攻击时会大量产生，内存限流器按空闲时间清理限流键，并限制限流键的总数
+type RateLimitMemoryStoreConfig struct {
+	// Shards 分片数，每个分片使用独立的锁，默认 32
+	Shards int `yaml:"shards"`
+	// IdleTTL 限流键的空闲时间（秒），超过该时间未访问的限流键被清理，默认 600
+	IdleTTL int `yaml:"idleTTL"`
+	// CleanupInterval 清理空闲限流键的间隔（秒），默认使用 rateLimit.cleanupInterval
+	CleanupInterval int `yaml:"cleanupInterval"`
+	// MaxEntries 限流键数量上限，达到上限时淘汰最久未访问的限流键并记录警告日志，默认 100000
+	MaxEntries int `yaml:"maxEntries"`
+}
+
+// GetShards 获取分片数，如果未配置则返回 32
+func (c *RateLimitMemoryStoreConfig) GetShards() int {
+	if c.Shards <= 0 {
+		return 32
+	}
+	return c.Shards
+}
+
+// GetIdleTTL 获取限流键的空闲时间（秒），如果未配置则返回 600
+func (c *RateLimitMemoryStoreConfig) GetIdleTTL() int {
+	if c.IdleTTL <= 0 {
+		return 600
+	}
+	return c.IdleTTL
+}
+
+// GetMaxEntries 获取限流键数量上限，如果未配置则返回 100000
+func (c *RateLimitMemoryStoreConfig) GetMaxEntries() int {
+	if c.MaxEntries <= 0 {
+		return 100000
+	}
+	return c.MaxEntries
 }
 
 // RateLimitRule 限流规则
@@ -32,6 +71,8 @@ type RateLimitRule struct {
 	Rate int `yaml:"rate"`
 	// Burst 突发容量
 	Burst int `yaml:"burst"`
+	// Cost 每次请求消耗的配额，默认 1；处理函数可通过 ginContext.SetRateLimitCost 按实际开销覆盖
+	Cost int `yaml:"cost"`
 	// KeyType 限流维度: ip / user / global
 	// - ip: 按客户端 IP 限流
 	// - user: 按用户 ID 限流（需要认证）
@@ -39,6 +80,10 @@ type RateLimitRule struct {
 	KeyType string `yaml:"keyType"`
 	// Message 自定义限流提示消息
 	Message string `yaml:"message"`
+	// ResponseCode 被限流时统一响应结构中的 code，默认 429；开启 service.useHTTPStatus 时 HTTP 状态码始终为 429，否则为 200
+	ResponseCode int `yaml:"responseCode"`
+	// Exempt 是否豁免限流，为 true 时匹配的请求不经过任何限流（包括默认限流）
+	Exempt bool `yaml:"exempt"`
 }
 
 // GetDefaultRate 获取默认速率，如果未配置则返回 100
@@ -73,8 +118,11 @@ func (c *RateLimitConfig) GetMessage() string {
 	return c.Message
 }
 
-// GetCleanupInterval 获取清理间隔（秒）
+// GetCleanupInterval 获取清理间隔（秒），优先使用 memoryStore.cleanupInterval，都未配置时返回 60
 func (c *RateLimitConfig) GetCleanupInterval() int {
+	if c.MemoryStore.CleanupInterval > 0 {
+		return c.MemoryStore.CleanupInterval
+	}
 	if c.CleanupInterval <= 0 {
 		return 60
 	}
@@ -101,6 +149,22 @@ func (r *RateLimitRule) GetBurst() int {
 	return r.Burst
 }
 
+// GetCost 获取每次请求消耗的配额，默认为 1
+func (r *RateLimitRule) GetCost() int {
+	if r.Cost <= 0 {
+		return 1
+	}
+	return r.Cost
+}
+
+// GetResponseCode 获取被限流时的响应 code，默认为 429
+func (r *RateLimitRule) GetResponseCode() int {
+	if r.ResponseCode == 0 {
+		return 429
+	}
+	return r.ResponseCode
+}
+
 // GetKeyType 获取限流维度，默认为 ip
 func (r *RateLimitRule) GetKeyType() string {
 	if r.KeyType == "" {
@@ -1,18 +1,43 @@
 // Package config 提供应用程序的配置结构定义
-// 本文件定义了Redis缓存数据库的配置结构，支持单实例和集群模式
+// 本文件定义了Redis缓存数据库的配置结构，支持单实例、哨兵和集群模式
 package config
 
+// Redis 部署模式
+const (
+	RedisModeStandalone = "standalone" // 单实例模式
+	RedisModeSentinel   = "sentinel"   // 哨兵模式，通过哨兵发现主节点并自动故障转移
+	RedisModeCluster    = "cluster"    // 集群模式，Redis Cluster
+)
+
 // RedisInfo Redis配置信息
-// 该结构体包含了连接Redis数据库所需的基本配置参数，支持单实例和集群两种部署模式
+// 该结构体包含了连接Redis数据库所需的基本配置参数，支持单实例、哨兵和集群三种部署模式
 type RedisInfo struct {
-	AliasName    string   `yaml:"aliasName"`    // 代表当前实例的名字，用于多Redis实例环境下的标识
-	Addr         string   `yaml:"addr"`         // 服务器地址:端口，单实例模式下的Redis服务器地址
-	ClusterAddrs []string `yaml:"clusterAddrs"` // 集群模式下的节点地址列表，支持多节点Redis Cluster
-	UseCluster   bool     `yaml:"useCluster"`   // 是否使用集群模式，true为集群模式，false为单实例模式
-	DB           int      `yaml:"db"`           // 单实例模式下redis的哪个数据库，Redis支持0-15共16个数据库
-	Password     string   `yaml:"password"`     // 密码，用于Redis身份认证，支持空密码
+	AliasName     string   `yaml:"aliasName"`            // 代表当前实例的名字，用于多Redis实例环境下的标识
+	Mode          string   `yaml:"mode"`                 // 部署模式：standalone / sentinel / cluster，为空时按 useCluster 判断
+	Addr          string   `yaml:"addr"`                 // 服务器地址:端口，单实例模式下的Redis服务器地址
+	MasterName    string   `yaml:"masterName"`           // 哨兵模式下的主节点名称
+	SentinelAddrs []string `yaml:"sentinelAddrs"`        // 哨兵模式下的哨兵节点地址列表
+	ClusterAddrs  []string `yaml:"clusterAddrs"`         // 集群模式下的节点地址列表，支持多节点Redis Cluster
+	UseCluster    bool     `yaml:"useCluster"`           // 是否使用集群模式，已由 mode: cluster 取代，仅在 mode 为空时生效
+	DB            int      `yaml:"db"`                   // 单实例和哨兵模式下redis的哪个数据库，Redis支持0-15共16个数据库
+	Password      string   `yaml:"password" mask:"true"` // 密码，用于Redis身份认证，支持空密码
 
 	// 连接池配置
 	PoolSize     int `yaml:"poolSize"`     // 连接池大小，默认10
 	MinIdleConns int `yaml:"minIdleConns"` // 最小空闲连接数，默认5
 }
+
+// GetMode 获取部署模式
+// 未配置 mode 时兼容旧配置：useCluster 为 true 时为集群模式，否则为单实例模式
+//
+// 返回：
+//   - string: standalone / sentinel / cluster，配置了无法识别的模式时原样返回
+func (r RedisInfo) GetMode() string {
+	if r.Mode != "" {
+		return r.Mode
+	}
+	if r.UseCluster {
+		return RedisModeCluster
+	}
+	return RedisModeStandalone
+}
@@ -0,0 +1,27 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了熔断器和限流管理接口的配置结构
+package config
+
+// ResilienceAdminConfig 熔断器和限流管理接口配置
+// 启用后注册以下接口（挂载在 service.routePrefix 下），用于在运行时关闭熔断器、清除被限流的键：
+//   - GET    /admin/breakers
+//   - POST   /admin/breakers/:name/reset
+//   - POST   /admin/breakers/reset-all
+//   - GET    /admin/ratelimit/keys?prefix=&limit=
+//   - DELETE /admin/ratelimit/keys/*key
+type ResilienceAdminConfig struct {
+	// Enabled 是否启用管理接口，默认 false
+	Enabled bool `yaml:"enabled"`
+	// Middleware 保护管理接口的中间件名称（如鉴权中间件），启用时必须配置
+	Middleware string `yaml:"middleware"`
+	// MaxListKeys 单次列出的最大限流键数，默认 1000
+	MaxListKeys int `yaml:"maxListKeys"`
+}
+
+// GetMaxListKeys 获取单次列出的最大限流键数，如果未配置则返回 1000
+func (c *ResilienceAdminConfig) GetMaxListKeys() int {
+	if c.MaxListKeys <= 0 {
+		return 1000
+	}
+	return c.MaxListKeys
+}
@@ -0,0 +1,30 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了运行信息接口的配置结构
+package config
+
+// RuntimeInfoConfig 运行信息接口配置
+// 启用后注册 GET {path}（挂载在 service.routePrefix 下），返回版本号、Git 提交、构建时间、运行环境、运行时长等信息
+type RuntimeInfoConfig struct {
+	// Enabled 是否启用运行信息接口，默认 true
+	Enabled *bool `yaml:"enabled"`
+	// Path 接口路径，默认 /system/info
+	Path string `yaml:"path"`
+	// Middleware 保护接口的中间件名称（如 IP 过滤或鉴权中间件），为空时不加中间件
+	Middleware string `yaml:"middleware"`
+}
+
+// GetEnabled 获取是否启用运行信息接口，如果未配置则返回 true
+func (c *RuntimeInfoConfig) GetEnabled() bool {
+	if c.Enabled == nil {
+		return true
+	}
+	return *c.Enabled
+}
+
+// GetPath 获取接口路径，如果未配置则返回 /system/info
+func (c *RuntimeInfoConfig) GetPath() string {
+	if c.Path == "" {
+		return "/system/info"
+	}
+	return c.Path
+}
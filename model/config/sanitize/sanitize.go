@@ -0,0 +1,168 @@
+// Package sanitize 提供配置结构体的脱敏功能
+// 将配置转换为按 yaml 字段名组织的 map / slice，带 mask:"true" 标签的字段替换为掩码，
+// 用于在运行时接口、日志中输出生效的配置而不泄露密码、密钥等敏感信息
+package sanitize
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// MaskedValue 敏感字段脱敏后的值
+const MaskedValue = "******"
+
+// maskTag 标记敏感字段的结构体标签，如 `yaml:"password" mask:"true"`
+const maskTag = "mask"
+
+var (
+	durationType      = reflect.TypeOf(time.Duration(0))
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Value 返回配置的脱敏副本，可直接序列化为 JSON
+// 结构体转换为以 yaml 字段名为键的 map（忽略 yaml:"-" 的字段，展开 ,inline 的字段），
+// 递归处理嵌套结构体、指针、切片和 map；带 mask:"true" 标签的字段中非空的值替换为 MaskedValue，
+// 字段为切片或 map 时逐个元素替换。time.Duration 输出为 "1m30s" 格式，函数和通道输出为 nil
+//
+// 使用示例：
+//
+//	data, _ := json.Marshal(sanitize.Value(app.BaseConfig))
+//
+// 参数：
+//   - v: 配置值，通常为配置结构体或其指针
+//
+// 返回：
+//   - any: 由 map[string]any、[]any 和标量组成的脱敏副本
+func Value(v any) any {
+	if v == nil {
+		return nil
+	}
+	return convert(reflect.ValueOf(v), false)
+}
+
+// convert 递归转换值，masked 为 true 时非空的标量替换为 MaskedValue
+func convert(v reflect.Value, masked bool) any {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return convert(v.Elem(), masked)
+	}
+
+	if masked {
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+		default:
+			if v.IsZero() {
+				return zeroValue(v)
+			}
+			return MaskedValue
+		}
+	}
+
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	// time.Time 等自定义序列化的结构体保持原值
+	if v.Kind() == reflect.Struct && (v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType)) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		out := make(map[string]any)
+		convertStruct(v, out)
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && !masked {
+			return v.Interface()
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = convert(v.Index(i), masked)
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = convert(iter.Value(), masked)
+		}
+		return out
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+	default:
+		return v.Interface()
+	}
+}
+
+// convertStruct 将结构体的导出字段按 yaml 字段名写入 out，,inline 的字段展开到 out 中
+func convertStruct(v reflect.Value, out map[string]any) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, inline, skip := yamlFieldName(field)
+		// 与 yaml.v3 一致，未导出的字段只有内嵌且 inline 时才处理
+		if skip || (!field.IsExported() && !(field.Anonymous && inline)) {
+			continue
+		}
+		value := v.Field(i)
+		if inline {
+			for value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					break
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				convertStruct(value, out)
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+		}
+		out[name] = convert(value, field.Tag.Get(maskTag) == "true")
+	}
+}
+
+// yamlFieldName 按 yaml.v3 的规则获取字段名：未设置标签时为小写的字段名
+func yamlFieldName(field reflect.StructField) (name string, inline, skip bool) {
+	tag := field.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "inline" {
+			inline = true
+		}
+	}
+	name = parts[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, inline, false
+}
+
+// zeroValue 脱敏字段为零值时的输出：字符串为空串，其他类型保持原值，便于区分未配置和已配置
+func zeroValue(v reflect.Value) any {
+	if v.Kind() == reflect.String {
+		return ""
+	}
+	return v.Interface()
+}
@@ -0,0 +1,150 @@
+// Package sanitize 配置脱敏测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 Value 的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 嵌套结构体、结构体切片、map、指针、inline 字段和 time.Duration 的转换
+// 2. 带 mask 标签的标量、切片和 map 脱敏，空值保持为空
+// 3. BaseConfig 中所有密码、密钥类字段都带有 mask 标签，新增字段未标记时测试失败
+// 4. BaseConfig 中填写的密码、密钥不出现在脱敏结果中
+//
+// 运行测试：go test -v ./model/config/sanitize/...
+// ==================================================
+package sanitize
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zzsen/gin_core/model/config"
+)
+
+type sanitizeTestAccount struct {
+	User     string `yaml:"user"`
+	Password string `yaml:"password" mask:"true"`
+}
+
+type sanitizeTestBase struct {
+	Region string `yaml:"region"`
+}
+
+type sanitizeTestConfig struct {
+	sanitizeTestBase `yaml:",inline"`
+	Name             string                         `yaml:"name"`
+	Timeout          time.Duration                  `yaml:"timeout"`
+	Primary          *sanitizeTestAccount           `yaml:"primary"`
+	Missing          *sanitizeTestAccount           `yaml:"missing"`
+	Replicas         []sanitizeTestAccount          `yaml:"replicas"`
+	Tenants          map[string]sanitizeTestAccount `yaml:"tenants"`
+	Tokens           []string                       `yaml:"tokens" mask:"true"`
+	Headers          map[string]string              `yaml:"headers" mask:"true"`
+	Ignored          string                         `yaml:"-"`
+	NoTag            int
+	unexported       string
+}
+
+// TestValue_Structure 测试结构转换和脱敏
+//
+// 【功能点】验证嵌套结构体、结构体切片、map 中的敏感字段均被脱敏，其余字段按 yaml 字段名原样输出
+// 【测试流程】
+//  1. 构造包含 inline、Duration、指针、切片、map 和忽略字段的配置
+//  2. 断言 JSON 结果与期望一致，未配置的密码输出为空串
+func TestValue_Structure(t *testing.T) {
+	cfg := sanitizeTestConfig{
+		sanitizeTestBase: sanitizeTestBase{Region: "cn"},
+		Name:             "app",
+		Timeout:          90 * time.Second,
+		Primary:          &sanitizeTestAccount{User: "root", Password: "p1"},
+		Replicas:         []sanitizeTestAccount{{User: "r1", Password: "p2"}, {User: "r2"}},
+		Tenants:          map[string]sanitizeTestAccount{"t1": {User: "u1", Password: "p3"}},
+		Tokens:           []string{"tk1", "tk2"},
+		Headers:          map[string]string{"Authorization": "Bearer x"},
+		Ignored:          "ignored",
+		NoTag:            1,
+		unexported:       "unexported",
+	}
+
+	data, err := json.Marshal(Value(&cfg))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"region": "cn",
+		"name": "app",
+		"timeout": "1m30s",
+		"primary": {"user": "root", "password": "******"},
+		"missing": null,
+		"replicas": [{"user": "r1", "password": "******"}, {"user": "r2", "password": ""}],
+		"tenants": {"t1": {"user": "u1", "password": "******"}},
+		"tokens": ["******", "******"],
+		"headers": {"Authorization": "******"},
+		"notag": 1
+	}`, string(data))
+	assert.Nil(t, Value(nil))
+}
+
+// sensitiveFieldPattern 需要脱敏的字段名
+var sensitiveFieldPattern = regexp.MustCompile(`(?i)password|secret|token`)
+
+// TestValue_BaseConfigMaskTags 测试 BaseConfig 的敏感字段都带有 mask 标签
+//
+// 【功能点】验证 BaseConfig 及其嵌套的配置结构体中，字段名包含 Password、Secret、Token 的字段都带有 mask:"true"（布尔、整数类型的开关和长度除外，如 csrf.tokenLength）
+// 【测试流程】递归遍历 BaseConfig 的字段类型，断言匹配的字段都带有 mask 标签
+func TestValue_BaseConfigMaskTags(t *testing.T) {
+	visited := map[reflect.Type]bool{}
+	var walk func(t reflect.Type, path string)
+	walk = func(typ reflect.Type, path string) {
+		for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || visited[typ] {
+			return
+		}
+		visited[typ] = true
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPath := path + "." + field.Name
+			if sensitiveFieldPattern.MatchString(field.Name) && field.Type.Kind() != reflect.Bool && field.Type.Kind() != reflect.Int {
+				assert.Equal(t, "true", field.Tag.Get(maskTag), "%s 应带有 mask:\"true\" 标签", fieldPath)
+			}
+			walk(field.Type, fieldPath)
+		}
+	}
+	walk(reflect.TypeOf(config.BaseConfig{}), "BaseConfig")
+}
+
+// TestValue_BaseConfigSecrets 测试 BaseConfig 中的密码、密钥被脱敏
+//
+// 【功能点】验证各组件配置中填写的密码、密钥不出现在脱敏结果中，其余字段保留
+// 【测试流程】
+//  1. 填写数据库、Redis、RabbitMQ、Elasticsearch、Etcd、SMTP、对象存储、上传、API Key 的密码和密钥
+//  2. 断言 JSON 结果中不包含任何明文，包含数据库地址
+func TestValue_BaseConfigSecrets(t *testing.T) {
+	cfg := config.BaseConfig{
+		Db:             &config.DbInfo{Host: "db-host", Password: "secret-db"},
+		DbList:         []config.DbInfo{{Password: "secret-db-list"}},
+		Redis:          &config.RedisInfo{Password: "secret-redis"},
+		RedisList:      []config.RedisInfo{{Password: "secret-redis-list"}},
+		RabbitMQ:       config.RabbitMQInfo{Password: "secret-mq"},
+		Es:             &config.EsInfo{Password: "secret-es"},
+		Etcd:           &config.EtcdInfo{Password: "secret-etcd"},
+		Smtp:           config.SmtpInfo{Password: "secret-smtp"},
+		ObjectStorage:  config.ObjectStorageConfig{SecretKey: "secret-oss"},
+		Upload:         config.UploadConfig{S3: config.S3Config{SecretKey: "secret-upload"}},
+		APIKey:         config.APIKeyConfig{Keys: []config.APIKeyEntry{{Name: "caller", Key: "secret-api-key", HashedKey: "secret-hashed"}}},
+		CircuitBreaker: config.CircuitBreakerConfig{WebhookURL: "https://hooks.example.com/secret-webhook"},
+	}
+
+	data, err := json.Marshal(Value(cfg))
+	require.NoError(t, err)
+	assert.NotRegexp(t, `secret-`, string(data))
+	assert.Contains(t, string(data), "db-host")
+}
@@ -3,17 +3,94 @@
 package config
 
 import (
+	"fmt"
 	"reflect"
 	"runtime"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	// standardCronParser 标准 5 段 cron 表达式解析器（分 时 日 月 周）
+	standardCronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	// secondsCronParser 6 段 cron 表达式解析器（秒 分 时 日 月 周）
+	secondsCronParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
 )
 
 // ScheduleInfo 定时任务配置信息
 // 该结构体定义了定时任务的执行策略和要执行的函数
 type ScheduleInfo struct {
 	Name                 string `yaml:"name"`                   // 定时任务名称
-	Cron                 string `yaml:"cron"`                   // cron表达式，定义定时任务的执行时间规则
+	Cron                 string `yaml:"cron"`                   // cron表达式，定义定时任务的执行时间规则，支持 5 段（分 时 日 月 周）和 6 段（秒 分 时 日 月 周）
 	Cmd                  func() `yaml:"cmd"`                    // 定时任务执行的函数，无参数无返回值的函数类型
 	ShouldRunImmediately bool   `yaml:"should_run_immediately"` // 是否在服务启动后立即执行
+	// Singleton 是否只在一个实例上执行：每次触发时先获取 Redis 分布式锁，未获取到锁的实例跳过本次执行，需开启 system.useRedis
+	Singleton bool `yaml:"singleton"`
+	// LockKey Singleton 任务的锁键名，为空时使用 "schedule:" + Name（Name 也为空时使用函数名）
+	LockKey string `yaml:"lock_key"`
+	// Timezone cron 表达式使用的时区（IANA 时区名，如 Asia/Shanghai），为空时使用服务器本地时区
+	Timezone string `yaml:"timezone"`
+	// WithSeconds 是否按 6 段（秒 分 时 日 月 周）解析 cron 表达式，未设置时表达式为 6 段也会按秒级解析
+	WithSeconds bool `yaml:"with_seconds"`
+}
+
+// GetName 获取定时任务名称，未配置时使用函数名
+func (s *ScheduleInfo) GetName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.GetFuncInfo()
+}
+
+// ParseCron 按 Timezone、WithSeconds 解析 cron 表达式
+// 表达式为 6 段或设置了 WithSeconds 时按秒级解析，支持 @every、@daily 等描述符；
+// 设置了 Timezone 时覆盖表达式中的 CRON_TZ= 前缀
+//
+// 返回：
+//   - cron.Schedule: 解析后的调度规则，可通过 Next 计算下一次执行时间
+//   - error: 表达式或时区无效时返回包含任务名称的错误
+func (s *ScheduleInfo) ParseCron() (cron.Schedule, error) {
+	spec := strings.TrimSpace(s.Cron)
+	if spec == "" {
+		return nil, fmt.Errorf("定时任务 %s 的 cron 表达式为空", s.GetName())
+	}
+	var loc *time.Location
+	if s.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(s.Timezone); err != nil {
+			return nil, fmt.Errorf("定时任务 %s 的时区 %q 无效: %w", s.GetName(), s.Timezone, err)
+		}
+	}
+
+	fields := strings.Fields(spec)
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		fields = fields[1:]
+	}
+	parser := standardCronParser
+	if s.WithSeconds || len(fields) == 6 {
+		parser = secondsCronParser
+	}
+	schedule, err := parser.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("定时任务 %s 的 cron 表达式 %q 无效: %w", s.GetName(), s.Cron, err)
+	}
+	if spec, ok := schedule.(*cron.SpecSchedule); ok && loc != nil {
+		spec.Location = loc
+	}
+	return schedule, nil
+}
+
+// GetLockKey 获取 Singleton 任务的锁键名
+func (s *ScheduleInfo) GetLockKey() string {
+	if s.LockKey != "" {
+		return s.LockKey
+	}
+	if s.Name != "" {
+		return "schedule:" + s.Name
+	}
+	return "schedule:" + s.GetFuncInfo()
 }
 
 // GetFuncInfo 获取定时任务函数的详细信息
@@ -0,0 +1,96 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了安全响应头中间件的配置结构
+package config
+
+import (
+	"net/netip"
+
+	"github.com/zzsen/gin_core/utils/netutil"
+)
+
+// SecureHeaderDisabled 字符串类型的响应头配置为该值时不输出对应响应头
+const SecureHeaderDisabled = "-"
+
+// SecureHeadersConfig 安全响应头配置
+// 用于 secureHeadersHandler 中间件，为响应设置 HSTS、CSP、X-Frame-Options 等安全响应头。
+// 字符串类型的配置项为空时使用默认值，配置为 "-" 时不输出对应响应头
+type SecureHeadersConfig struct {
+	// Enabled 是否启用安全响应头中间件
+	Enabled bool `yaml:"enabled"`
+	// HSTSMaxAge Strict-Transport-Security 的 max-age（秒），默认 31536000（一年），负数时不输出
+	// 只对 HTTPS 请求（TLS 连接，或受信任代理通过 X-Forwarded-Proto: https 声明）输出
+	HSTSMaxAge int `yaml:"hstsMaxAge"`
+	// HSTSIncludeSubdomains Strict-Transport-Security 是否包含 includeSubDomains
+	HSTSIncludeSubdomains bool `yaml:"hstsIncludeSubdomains"`
+	// ContentSecurityPolicy Content-Security-Policy，默认 "default-src 'self'"
+	ContentSecurityPolicy string `yaml:"contentSecurityPolicy"`
+	// FrameOptions X-Frame-Options，默认 DENY
+	FrameOptions string `yaml:"frameOptions"`
+	// ContentTypeNosniff 是否输出 X-Content-Type-Options: nosniff，默认 true
+	ContentTypeNosniff *bool `yaml:"contentTypeNosniff"`
+	// ReferrerPolicy Referrer-Policy，默认 strict-origin-when-cross-origin
+	ReferrerPolicy string `yaml:"referrerPolicy"`
+	// PermissionsPolicy Permissions-Policy，默认 "camera=(), microphone=(), geolocation=()"
+	PermissionsPolicy string `yaml:"permissionsPolicy"`
+	// ExcludePaths 不设置安全响应头的路径，匹配规则与审计路径一致：
+	// 精确匹配（/swagger/index.html）、前缀通配符（/swagger/*）、path.Match 模式
+	ExcludePaths []string `yaml:"excludePaths"`
+	// TrustedProxies 受信任的代理地址（IP 或 CIDR），来自这些地址的请求按 X-Forwarded-Proto 判断是否为 HTTPS
+	TrustedProxies []string `yaml:"trustedProxies"`
+}
+
+// GetHSTSMaxAge 获取 Strict-Transport-Security 的 max-age（秒），如果未配置则返回 31536000
+func (c *SecureHeadersConfig) GetHSTSMaxAge() int {
+	if c.HSTSMaxAge == 0 {
+		return 31536000
+	}
+	return c.HSTSMaxAge
+}
+
+// GetContentSecurityPolicy 获取 Content-Security-Policy，如果未配置则返回 "default-src 'self'"
+func (c *SecureHeadersConfig) GetContentSecurityPolicy() string {
+	if c.ContentSecurityPolicy == "" {
+		return "default-src 'self'"
+	}
+	return c.ContentSecurityPolicy
+}
+
+// GetFrameOptions 获取 X-Frame-Options，如果未配置则返回 DENY
+func (c *SecureHeadersConfig) GetFrameOptions() string {
+	if c.FrameOptions == "" {
+		return "DENY"
+	}
+	return c.FrameOptions
+}
+
+// GetContentTypeNosniff 获取是否输出 X-Content-Type-Options: nosniff，如果未配置则返回 true
+func (c *SecureHeadersConfig) GetContentTypeNosniff() bool {
+	if c.ContentTypeNosniff == nil {
+		return true
+	}
+	return *c.ContentTypeNosniff
+}
+
+// GetReferrerPolicy 获取 Referrer-Policy，如果未配置则返回 strict-origin-when-cross-origin
+func (c *SecureHeadersConfig) GetReferrerPolicy() string {
+	if c.ReferrerPolicy == "" {
+		return "strict-origin-when-cross-origin"
+	}
+	return c.ReferrerPolicy
+}
+
+// GetPermissionsPolicy 获取 Permissions-Policy，如果未配置则返回 "camera=(), microphone=(), geolocation=()"
+func (c *SecureHeadersConfig) GetPermissionsPolicy() string {
+	if c.PermissionsPolicy == "" {
+		return "camera=(), microphone=(), geolocation=()"
+	}
+	return c.PermissionsPolicy
+}
+
+// ParseTrustedProxies 解析受信任的代理地址，单个 IP 按单地址网段处理
+// 返回：
+//   - []netip.Prefix: 解析成功的网段
+//   - error: 存在无法解析的地址时返回错误，错误中包含该地址
+func (c *SecureHeadersConfig) ParseTrustedProxies() ([]netip.Prefix, error) {
+	return netutil.ParseProxies(c.TrustedProxies)
+}
@@ -2,20 +2,168 @@
 // 本文件定义了HTTP服务的配置结构，包含网络、会话、中间件和性能相关配置
 package config
 
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
 // ServiceInfo HTTP服务配置信息
 // 该结构体包含了HTTP服务器运行所需的所有配置参数，支持中间件配置和性能调优
 type ServiceInfo struct {
-	Ip              string   `yaml:"ip"`              // 服务绑定的IP地址，支持0.0.0.0表示监听所有网络接口
-	Port            int      `yaml:"port"`            // 服务监听的端口号，用于客户端连接
-	RoutePrefix     string   `yaml:"routePrefix"`     // 路由前缀，所有API路由都会自动添加此前缀
-	SessionExpire   int      `yaml:"sessionExpire"`   // 缓存的有效时长（秒），控制会话数据的过期时间
-	SessionPrefix   string   `yaml:"sessionPrefix"`   // redis中缓存前缀，用于区分不同类型的会话数据
-	Middlewares     []string `yaml:"middlewares"`     // 中间件列表，顺序对应中间件调用顺序，影响请求处理流程
-	ApiTimeout      int      `yaml:"apiTimeout"`      // API超时时间（秒），超过此时间的请求会被自动终止
-	ReadTimeout     int      `yaml:"readTimeout"`     // 读取超时时间（秒），控制HTTP请求体的读取超时
-	WriteTimeout    int      `yaml:"writeTimeout"`    // 写入超时时间（秒），控制HTTP响应体的写入超时
-	PprofPort       *int     `yaml:"pprofPort"`       // pprof服务端口，用于性能分析和调试，指针类型支持配置文件中不设置该字段
-	ShutdownTimeout int      `yaml:"shutdownTimeout"` // 优雅关闭超时时间（秒），默认 5 秒
+	Ip              string         `yaml:"ip"`              // 服务绑定的IP地址，支持0.0.0.0表示监听所有网络接口
+	Port            int            `yaml:"port"`            // 服务监听的端口号，用于客户端连接
+	RoutePrefix     string         `yaml:"routePrefix"`     // 路由前缀，所有API路由都会自动添加此前缀
+	SessionExpire   int            `yaml:"sessionExpire"`   // 缓存的有效时长（秒），控制会话数据的过期时间
+	SessionPrefix   string         `yaml:"sessionPrefix"`   // redis中缓存前缀，用于区分不同类型的会话数据
+	Middlewares     MiddlewareList `yaml:"middlewares"`     // 中间件列表，每项为中间件名称或 {name, envs, order, config} 对象，按 order（默认为列表位置）决定调用顺序
+	ApiTimeout      int            `yaml:"apiTimeout"`      // API超时时间（秒），超过此时间的请求会被自动终止
+	ReadTimeout     int            `yaml:"readTimeout"`     // 读取超时时间（秒），控制HTTP请求体的读取超时
+	WriteTimeout    int            `yaml:"writeTimeout"`    // 写入超时时间（秒），控制HTTP响应体的写入超时
+	PprofPort       *int           `yaml:"pprofPort"`       // pprof服务端口，用于性能分析和调试，指针类型支持配置文件中不设置该字段
+	ShutdownTimeout int            `yaml:"shutdownTimeout"` // 优雅关闭超时时间（秒），默认 5 秒
+	UseHTTPStatus   bool           `yaml:"useHTTPStatus"`   // 是否按响应码输出对应的 HTTP 状态码（如参数校验失败返回 400），默认 false 始终返回 200
+	// RouteConflictPolicy 路由冲突（重复注册、超出路由前缀）的处理方式: error（启动失败）/ warn（输出错误日志后继续启动），默认 error
+	RouteConflictPolicy string `yaml:"routeConflictPolicy"`
+	// PanicStackDepth 未处理异常日志中记录的最大堆栈帧数（已过滤 runtime、gin、net/http 的帧），默认 32
+	PanicStackDepth int `yaml:"panicStackDepth"`
+	// TrustedProxies 受信任的代理地址（IP 或 CIDR），只有来自这些地址的请求才读取 X-Forwarded-For、X-Real-IP 获取客户端 IP；
+	// 未配置时不信任任何代理，客户端 IP 为直连地址
+	TrustedProxies []string `yaml:"trustedProxies"`
+	// ApiTimeoutMaxBufferBytes timeoutHandler 缓冲响应体的大小上限（字节），超过时改为直接写出响应、不再输出超时响应，默认 1048576（1MB）
+	ApiTimeoutMaxBufferBytes int64 `yaml:"apiTimeoutMaxBufferBytes"`
+	// MaxParseBodyBytes ginContext.Get 解析 JSON 请求体的大小上限（字节），超过时只从查询参数、表单和路径参数中获取值，默认 1048576（1MB）
+	MaxParseBodyBytes int64 `yaml:"maxParseBodyBytes"`
+	// NotFound 路由不存在（404）和请求方法不允许（405）时的响应方式
+	NotFound NotFoundConfig `yaml:"notFound"`
+	// JSONTime response.LocalTime 类型字段在 JSON 中的时间格式和时区
+	JSONTime JSONTimeConfig `yaml:"jsonTime"`
+	// EnforceEnvelope 是否检查路由前缀下的 JSON 响应符合统一响应结构，用于开发、测试环境发现绕过统一响应的处理函数
+	EnforceEnvelope bool `yaml:"enforceEnvelope"`
+	// Envelope 统一响应结构检查的配置，开启 enforceEnvelope 时生效
+	Envelope EnvelopeConfig `yaml:"envelope"`
+	// EnableServerTiming 是否记录请求各阶段（中间件、处理函数、数据库查询、ginContext.StartSpan）的耗时，并通过 Server-Timing 响应头输出
+	EnableServerTiming bool `yaml:"enableServerTiming"`
+	// ServerTiming 请求耗时分解的配置，开启 enableServerTiming 时生效
+	ServerTiming ServerTimingConfig `yaml:"serverTiming"`
+}
+
+// ServerTimingConfig 请求耗时分解配置
+type ServerTimingConfig struct {
+	// TopN Server-Timing 响应头中输出的耗时最长的阶段数量（同名阶段合并），默认 5
+	TopN int `yaml:"topN"`
+	// SlowThreshold 请求耗时达到该值时在 warn 日志中输出全部阶段的耗时，默认 0 不输出
+	SlowThreshold time.Duration `yaml:"slowThreshold"`
+	// DBSpans 是否记录 app.DBWithContext 等绑定请求上下文的数据库查询耗时（阶段名为 db），默认 false
+	DBSpans bool `yaml:"dbSpans"`
+}
+
+// GetTopN 获取响应头中输出的阶段数量，如果未配置则返回 5
+func (c ServerTimingConfig) GetTopN() int {
+	if c.TopN <= 0 {
+		return 5
+	}
+	return c.TopN
+}
+
+// EnvelopeConfig 统一响应结构检查配置
+type EnvelopeConfig struct {
+	// StrictMode 是否将不符合统一响应结构的响应替换为 502 错误响应，默认 false 只输出错误日志
+	StrictMode bool `yaml:"strictMode"`
+	// Keys 统一响应结构的顶层字段，响应体必须恰好包含这些字段，默认 ["code", "msg", "data"]
+	Keys []string `yaml:"keys"`
+	// Allowlist 不检查的路径（如文件下载、Webhook），支持精确匹配、/* 后缀通配符和 path.Match 模式
+	Allowlist []string `yaml:"allowlist"`
+}
+
+// GetKeys 获取统一响应结构的顶层字段，如果未配置则返回 ["code", "msg", "data"]
+func (c EnvelopeConfig) GetKeys() []string {
+	if len(c.Keys) == 0 {
+		return []string{"code", "msg", "data"}
+	}
+	return c.Keys
+}
+
+// JSONTimeConfig response.LocalTime 的 JSON 序列化配置
+// 修改配置即可改变所有 LocalTime 字段的输出格式，无需修改模型结构体
+type JSONTimeConfig struct {
+	Layout   string `yaml:"layout"`   // Go 时间格式，如 "2006-01-02 15:04:05"，默认 time.RFC3339
+	Timezone string `yaml:"timezone"` // IANA 时区名称，如 "Asia/Shanghai"，默认为服务器本地时区
+}
+
+// GetLayout 获取时间格式，未配置时返回 time.RFC3339
+func (c JSONTimeConfig) GetLayout() string {
+	if c.Layout == "" {
+		return time.RFC3339
+	}
+	return c.Layout
+}
+
+// GetLocation 获取时区，未配置时返回 time.Local
+// 返回：
+//   - *time.Location: 时区
+//   - error: 时区名称无法识别
+func (c JSONTimeConfig) GetLocation() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(c.Timezone)
+}
+
+// 404/405 响应方式
+const (
+	NotFoundModeJSON     = "json"     // 返回统一响应结构
+	NotFoundModeHTML     = "html"     // 返回 htmlPath 指定的页面
+	NotFoundModeRedirect = "redirect" // 302 重定向到 redirectTo
+)
+
+// NotFoundConfig 404/405 响应配置
+// 路径以 service.routePrefix 开头或 Accept 请求头包含 application/json 的请求始终返回统一响应结构，
+// 其他请求按 Mode 处理；Mode 未配置时返回纯文本的状态描述（如 "Not Found"）
+type NotFoundConfig struct {
+	Mode       string `yaml:"mode"`       // 响应方式: json / html / redirect，默认返回纯文本
+	HTMLPath   string `yaml:"htmlPath"`   // html 模式返回的页面文件路径，状态码保持 404/405
+	RedirectTo string `yaml:"redirectTo"` // redirect 模式重定向的地址
+}
+
+// 路由冲突处理方式
+const (
+	RouteConflictError = "error" // 启动失败
+	RouteConflictWarn  = "warn"  // 输出错误日志后继续启动
+)
+
+// GetRouteConflictPolicy 获取路由冲突处理方式，如果未配置则返回 error
+func (s *ServiceInfo) GetRouteConflictPolicy() string {
+	if s.RouteConflictPolicy == "" {
+		return RouteConflictError
+	}
+	return s.RouteConflictPolicy
+}
+
+// GetPanicStackDepth 获取未处理异常日志中记录的最大堆栈帧数，如果未配置则返回 32
+func (s *ServiceInfo) GetPanicStackDepth() int {
+	if s.PanicStackDepth <= 0 {
+		return 32
+	}
+	return s.PanicStackDepth
+}
+
+// GetApiTimeoutMaxBufferBytes 获取 timeoutHandler 缓冲响应体的大小上限，如果未配置则返回 1048576
+func (s *ServiceInfo) GetApiTimeoutMaxBufferBytes() int64 {
+	if s.ApiTimeoutMaxBufferBytes <= 0 {
+		return 1 << 20
+	}
+	return s.ApiTimeoutMaxBufferBytes
+}
+
+// GetMaxParseBodyBytes 获取 ginContext.Get 解析 JSON 请求体的大小上限，如果未配置则返回 1048576
+func (s *ServiceInfo) GetMaxParseBodyBytes() int64 {
+	if s.MaxParseBodyBytes <= 0 {
+		return 1 << 20
+	}
+	return s.MaxParseBodyBytes
 }
 
 // GetShutdownTimeout 获取优雅关闭超时时间（秒）
@@ -26,3 +174,81 @@ func (s *ServiceInfo) GetShutdownTimeout() int {
 	}
 	return s.ShutdownTimeout
 }
+
+// MiddlewareEntry 中间件配置项
+// 配置文件中可以是中间件名称字符串（所有环境启用，按列表位置排序），也可以是对象：
+//
+//	middlewares:
+//	  - traceIdHandler
+//	  - name: chaosHandler
+//	    envs: ["test"]
+//	    order: 100
+//	    config:
+//	      header: X-Debug
+type MiddlewareEntry struct {
+	Name   string         `yaml:"name"`   // 中间件名称，通过 core.RegisterMiddleware 注册
+	Envs   []string       `yaml:"envs"`   // 启用该中间件的运行环境，为空时所有环境启用
+	Order  *int           `yaml:"order"`  // 排序值，越小越先执行，未配置时为该项在列表中的位置（从 0 开始），相同时保持列表顺序
+	Config map[string]any `yaml:"config"` // 传给中间件工厂函数的配置，只对注册为 func(map[string]any) gin.HandlerFunc 的中间件生效
+}
+
+// UnmarshalYAML 实现 yaml.Unmarshaler 接口，支持字符串和对象两种格式
+func (e *MiddlewareEntry) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*e = MiddlewareEntry{}
+		return value.Decode(&e.Name)
+	}
+	type plain MiddlewareEntry
+	return value.Decode((*plain)(e))
+}
+
+// EnabledIn 判断中间件是否在指定运行环境中启用
+func (e *MiddlewareEntry) EnabledIn(env string) bool {
+	return len(e.Envs) == 0 || slices.Contains(e.Envs, env)
+}
+
+// MiddlewareList 中间件配置列表
+type MiddlewareList []MiddlewareEntry
+
+// MiddlewareNames 由中间件名称创建中间件配置列表，所有环境启用，按名称顺序执行
+func MiddlewareNames(names ...string) MiddlewareList {
+	list := make(MiddlewareList, 0, len(names))
+	for _, name := range names {
+		list = append(list, MiddlewareEntry{Name: name})
+	}
+	return list
+}
+
+// IndexedMiddleware 按运行环境筛选、排序后的中间件配置项
+type IndexedMiddleware struct {
+	Index int // 在 service.middlewares 中的位置，用于错误信息
+	MiddlewareEntry
+}
+
+// ForEnv 获取指定运行环境中启用的中间件，按排序值稳定排序
+//
+// 参数：
+//   - env: 运行环境
+//
+// 返回：
+//   - []IndexedMiddleware: 启用的中间件，越靠前越先执行
+func (l MiddlewareList) ForEnv(env string) []IndexedMiddleware {
+	entries := make([]IndexedMiddleware, 0, len(l))
+	for i, entry := range l {
+		if entry.EnabledIn(env) {
+			entries = append(entries, IndexedMiddleware{Index: i, MiddlewareEntry: entry})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].order() < entries[j].order()
+	})
+	return entries
+}
+
+// order 获取排序值，未配置时为列表位置
+func (m *IndexedMiddleware) order() int {
+	if m.Order != nil {
+		return *m.Order
+	}
+	return m.Index
+}
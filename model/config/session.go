@@ -0,0 +1,100 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了会话（Session）相关的配置结构
+package config
+
+import "net/http"
+
+// SessionConfig 会话配置
+// 用于配置基于 Cookie 的服务端会话中间件
+type SessionConfig struct {
+	// Enabled 是否启用会话中间件
+	Enabled bool `yaml:"enabled"`
+	// CookieName 会话 Cookie 名称，默认 "gin_session"
+	CookieName string `yaml:"cookieName"`
+	// Domain Cookie 作用域名，为空时仅当前域名有效
+	Domain string `yaml:"domain"`
+	// Path Cookie 作用路径，默认 "/"
+	Path string `yaml:"path"`
+	// MaxAge 会话有效期（秒），每次访问会话时顺延，默认 86400（24小时）
+	MaxAge int `yaml:"maxAge"`
+	// Secure 是否仅在 HTTPS 下发送 Cookie
+	Secure bool `yaml:"secure"`
+	// HttpOnly 是否禁止 JavaScript 访问 Cookie，默认 true
+	HttpOnly *bool `yaml:"httpOnly"`
+	// SameSite Cookie 的 SameSite 属性: lax / strict / none，默认 lax
+	SameSite string `yaml:"sameSite"`
+	// Store 存储类型: memory（单机）/ redis（分布式），默认 redis
+	Store string `yaml:"store"`
+	// KeyPrefix Redis 存储的键前缀，默认 "session:"
+	KeyPrefix string `yaml:"keyPrefix"`
+	// CleanupInterval 内存存储清理过期会话的间隔（秒），默认 60
+	CleanupInterval int `yaml:"cleanupInterval"`
+}
+
+// GetCookieName 获取会话 Cookie 名称，如果未配置则返回 "gin_session"
+func (c *SessionConfig) GetCookieName() string {
+	if c.CookieName == "" {
+		return "gin_session"
+	}
+	return c.CookieName
+}
+
+// GetPath 获取 Cookie 作用路径，如果未配置则返回 "/"
+func (c *SessionConfig) GetPath() string {
+	if c.Path == "" {
+		return "/"
+	}
+	return c.Path
+}
+
+// GetMaxAge 获取会话有效期（秒），如果未配置则返回 86400
+func (c *SessionConfig) GetMaxAge() int {
+	if c.MaxAge <= 0 {
+		return 86400
+	}
+	return c.MaxAge
+}
+
+// GetHttpOnly 获取是否禁止 JavaScript 访问 Cookie，如果未配置则返回 true
+func (c *SessionConfig) GetHttpOnly() bool {
+	if c.HttpOnly == nil {
+		return true
+	}
+	return *c.HttpOnly
+}
+
+// GetSameSite 获取 Cookie 的 SameSite 属性，未配置或无法识别时返回 http.SameSiteLaxMode
+func (c *SessionConfig) GetSameSite() http.SameSite {
+	switch c.SameSite {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// GetStore 获取存储类型，默认为 redis
+func (c *SessionConfig) GetStore() string {
+	if c.Store == "" {
+		return "redis"
+	}
+	return c.Store
+}
+
+// GetKeyPrefix 获取 Redis 键前缀，默认为 "session:"
+func (c *SessionConfig) GetKeyPrefix() string {
+	if c.KeyPrefix == "" {
+		return "session:"
+	}
+	return c.KeyPrefix
+}
+
+// GetCleanupInterval 获取内存存储清理间隔（秒），默认为 60
+func (c *SessionConfig) GetCleanupInterval() int {
+	if c.CleanupInterval <= 0 {
+		return 60
+	}
+	return c.CleanupInterval
+}
@@ -0,0 +1,80 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了启动时等待依赖服务就绪的重试策略配置
+package config
+
+import (
+	"slices"
+	"time"
+)
+
+// StartupRetryConfig 启动重试策略配置
+// 启用后 MySQL、Redis、RabbitMQ、Elasticsearch 服务初始化时，首次连接失败不会立即退出，
+// 而是按 Interval 和 Backoff 重试，直到连接成功或超过 MaxWait；
+// 超过 MaxWait 时，Optional 中的服务降级启动（就绪检查报告为不可用），其他服务启动失败
+type StartupRetryConfig struct {
+	// Enabled 是否启用启动重试，默认 false（连接失败时立即启动失败）
+	Enabled bool `yaml:"enabled"`
+	// MaxWait 单个服务的最长等待时间，如 "2m"，默认 60s
+	MaxWait time.Duration `yaml:"maxWait"`
+	// Interval 首次重试的等待时间，如 "500ms"，默认 1s
+	Interval time.Duration `yaml:"interval"`
+	// Backoff 每次重试后等待时间的倍数，默认 2；为 1 时按固定间隔重试
+	Backoff float64 `yaml:"backoff"`
+	// MaxInterval 单次等待时间的上限，默认 30s
+	MaxInterval time.Duration `yaml:"maxInterval"`
+	// Optional 允许降级启动的服务名称，可选 mysql、redis、rabbitmq、elasticsearch
+	Optional []string `yaml:"optional"`
+}
+
+// GetMaxWait 获取单个服务的最长等待时间，如果未配置则返回 60s
+func (c *StartupRetryConfig) GetMaxWait() time.Duration {
+	if c.MaxWait <= 0 {
+		return 60 * time.Second
+	}
+	return c.MaxWait
+}
+
+// GetInterval 获取首次重试的等待时间，如果未配置则返回 1s
+func (c *StartupRetryConfig) GetInterval() time.Duration {
+	if c.Interval <= 0 {
+		return time.Second
+	}
+	return c.Interval
+}
+
+// GetBackoff 获取等待时间的倍数，如果未配置或小于 1 则返回 2
+func (c *StartupRetryConfig) GetBackoff() float64 {
+	if c.Backoff < 1 {
+		return 2
+	}
+	return c.Backoff
+}
+
+// GetMaxInterval 获取单次等待时间的上限，如果未配置则返回 30s
+func (c *StartupRetryConfig) GetMaxInterval() time.Duration {
+	if c.MaxInterval <= 0 {
+		return 30 * time.Second
+	}
+	return c.MaxInterval
+}
+
+// NextInterval 根据本次等待时间计算下次重试的等待时间，不超过 MaxInterval
+func (c *StartupRetryConfig) NextInterval(interval time.Duration) time.Duration {
+	return min(time.Duration(float64(interval)*c.GetBackoff()), c.GetMaxInterval())
+}
+
+// MaxAttempts 估算 MaxWait 内的最大尝试次数，用于日志中的 "第 n/m 次"
+// 不计连接本身的耗时，实际尝试次数可能更少
+func (c *StartupRetryConfig) MaxAttempts() int {
+	attempts, waited := 1, time.Duration(0)
+	for interval := min(c.GetInterval(), c.GetMaxInterval()); waited+interval <= c.GetMaxWait(); interval = c.NextInterval(interval) {
+		waited += interval
+		attempts++
+	}
+	return attempts
+}
+
+// IsOptional 判断服务超过最长等待时间后是否降级启动
+func (c *StartupRetryConfig) IsOptional(service string) bool {
+	return slices.Contains(c.Optional, service)
+}
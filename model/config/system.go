@@ -12,4 +12,30 @@ type SystemInfo struct {
 	UseEtcd     bool `yaml:"useEtcd"`     // 是否启用Etcd分布式键值存储，控制服务发现和配置管理功能
 	UseRabbitMQ bool `yaml:"useRabbitMQ"` // 是否启用RabbitMQ消息队列，控制异步消息处理功能
 	UseSchedule bool `yaml:"useSchedule"` // 是否启用定时任务功能，控制定时任务调度器的可用性
+
+	// UseObjectStorage 是否启用对象存储服务（需同时配置 objectStorage.enabled），初始化后通过 app.Storage 访问
+	UseObjectStorage bool `yaml:"useObjectStorage"`
+
+	// StrictConfigSections 是否严格解析通过 core.RegisterConfigSection 注册的配置段
+	// 开启后配置段中存在未知字段（如拼写错误）时配置加载失败
+	StrictConfigSections bool `yaml:"strictConfigSections"`
+
+	// CipherFallbackKeys 解密 CIPHER(...) / CIPHERV2(...) 时在 -cipherKey 之后依次尝试的备用密钥，用于轮换密钥期间兼容旧密钥加密的内容
+	// 在解密前从默认配置文件和环境配置文件中读取（不读取 include 引用的文件），建议通过 {{ENV_VAR_NAME}} 占位符配置
+	CipherFallbackKeys []string `yaml:"cipherFallbackKeys" mask:"true"`
+
+	// EnvOverridePrefix 覆盖配置项的环境变量前缀，默认 GIN_CORE_
+	// 加载配置文件后，名称以该前缀开头的环境变量按路径覆盖配置项，如 GIN_CORE_SERVICE__PORT=9000 覆盖 service.port
+	EnvOverridePrefix string `yaml:"envOverridePrefix"`
+
+	// StartupRetry 启动时等待 MySQL、Redis、RabbitMQ、Elasticsearch 就绪的重试策略
+	StartupRetry StartupRetryConfig `yaml:"startupRetry"`
+}
+
+// GetEnvOverridePrefix 获取覆盖配置项的环境变量前缀，未配置时返回 GIN_CORE_
+func (s *SystemInfo) GetEnvOverridePrefix() string {
+	if s.EnvOverridePrefix == "" {
+		return "GIN_CORE_"
+	}
+	return s.EnvOverridePrefix
 }
@@ -0,0 +1,62 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了后台任务执行器的配置结构
+package config
+
+// TaskRunnerConfig 后台任务执行器配置
+// 用于 tasks.Submit / ginContext.Async 提交的后台任务，任务在有界队列中排队，由固定数量的协程执行
+type TaskRunnerConfig struct {
+	// Workers 执行任务的协程数，默认 4
+	Workers int `yaml:"workers"`
+	// QueueSize 队列容量，默认 1000
+	QueueSize int `yaml:"queueSize"`
+	// Timeout 单次执行的超时时间（秒），默认 60
+	Timeout int `yaml:"timeout"`
+	// MaxRetries 失败后的最大重试次数，默认 0（不重试）
+	MaxRetries int `yaml:"maxRetries"`
+	// RetryInterval 重试间隔（毫秒），默认 1000
+	RetryInterval int `yaml:"retryInterval"`
+	// BlockOnFull 队列已满时是否等待，默认 false（立即返回 tasks.ErrQueueFull）
+	BlockOnFull bool `yaml:"blockOnFull"`
+	// BlockTimeout 队列已满时的最长等待时间（毫秒），仅 BlockOnFull 为 true 时生效，默认 1000
+	BlockTimeout int `yaml:"blockTimeout"`
+}
+
+// GetWorkers 获取执行任务的协程数，如果未配置则返回 4
+func (c *TaskRunnerConfig) GetWorkers() int {
+	if c.Workers <= 0 {
+		return 4
+	}
+	return c.Workers
+}
+
+// GetQueueSize 获取队列容量，如果未配置则返回 1000
+func (c *TaskRunnerConfig) GetQueueSize() int {
+	if c.QueueSize <= 0 {
+		return 1000
+	}
+	return c.QueueSize
+}
+
+// GetTimeout 获取单次执行的超时时间（秒），如果未配置则返回 60
+func (c *TaskRunnerConfig) GetTimeout() int {
+	if c.Timeout <= 0 {
+		return 60
+	}
+	return c.Timeout
+}
+
+// GetRetryInterval 获取重试间隔（毫秒），如果未配置则返回 1000
+func (c *TaskRunnerConfig) GetRetryInterval() int {
+	if c.RetryInterval <= 0 {
+		return 1000
+	}
+	return c.RetryInterval
+}
+
+// GetBlockTimeout 获取队列已满时的最长等待时间（毫秒），如果未配置则返回 1000
+func (c *TaskRunnerConfig) GetBlockTimeout() int {
+	if c.BlockTimeout <= 0 {
+		return 1000
+	}
+	return c.BlockTimeout
+}
@@ -0,0 +1,35 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了多租户数据库路由的配置结构
+package config
+
+// TenantConfig 多租户配置
+// 用于 tenantHandler 中间件和 app.TenantDB：中间件从请求中识别租户 ID 存入请求上下文，
+// app.TenantDB 按 Mapping（或 core.SetTenantResolver 注册的解析函数）将租户映射到 dbList 中的数据库别名
+type TenantConfig struct {
+	// Enabled 是否启用租户识别中间件
+	Enabled bool `yaml:"enabled"`
+	// Header 携带租户 ID 的请求头名称，默认 "X-Tenant-Id"
+	Header string `yaml:"header"`
+	// Claim 请求头未携带租户 ID 时，从认证信息（ginContext.GetClaims）中读取租户 ID 的字段名，默认 "tenantId"
+	Claim string `yaml:"claim"`
+	// DefaultTenant 请求头和认证信息中都没有租户 ID 时使用的租户，用于未登录的公开接口；为空时拒绝请求
+	DefaultTenant string `yaml:"defaultTenant"`
+	// Mapping 租户 ID 到 dbList 数据库别名的映射
+	Mapping map[string]string `yaml:"mapping"`
+}
+
+// GetHeader 获取租户 ID 请求头名称，如果未配置则返回 "X-Tenant-Id"
+func (c *TenantConfig) GetHeader() string {
+	if c.Header == "" {
+		return "X-Tenant-Id"
+	}
+	return c.Header
+}
+
+// GetClaim 获取认证信息中租户 ID 的字段名，如果未配置则返回 "tenantId"
+func (c *TenantConfig) GetClaim() string {
+	if c.Claim == "" {
+		return "tenantId"
+	}
+	return c.Claim
+}
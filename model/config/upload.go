@@ -0,0 +1,66 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件定义了文件上传存储的配置结构
+package config
+
+// 上传文件存储类型
+const (
+	UploadStorageLocal = "local" // 本地磁盘
+	UploadStorageS3    = "s3"    // S3 兼容的对象存储
+)
+
+// UploadConfig 文件上传存储配置
+// 用于 utils/upload.NewStorage 创建上传文件的存储
+type UploadConfig struct {
+	// Storage 存储类型: local / s3，默认 local
+	Storage string `yaml:"storage"`
+	// PathTemplate 存储目录模板，支持 {yyyy}、{mm}、{dd} 占位符，默认 {yyyy}/{mm}/{dd}
+	PathTemplate string `yaml:"pathTemplate"`
+	// BaseURL 返回的文件访问地址前缀，local 默认 /uploads，s3 默认为 endpoint/bucket
+	BaseURL string `yaml:"baseUrl"`
+	// LocalDir local 存储的根目录，默认 ./uploads
+	LocalDir string `yaml:"localDir"`
+	// S3 s3 存储的连接配置
+	S3 S3Config `yaml:"s3"`
+}
+
+// S3Config S3 兼容对象存储的连接配置
+type S3Config struct {
+	Endpoint     string `yaml:"endpoint"`              // 服务地址，如 https://s3.amazonaws.com、http://minio:9000
+	Region       string `yaml:"region"`                // 区域，默认 us-east-1
+	Bucket       string `yaml:"bucket"`                // 存储桶
+	AccessKey    string `yaml:"accessKey"`             // 访问密钥 ID
+	SecretKey    string `yaml:"secretKey" mask:"true"` // 访问密钥
+	UsePathStyle bool   `yaml:"usePathStyle"`          // 是否使用路径风格（endpoint/bucket/key），MinIO 等通常需要开启
+}
+
+// GetStorage 获取存储类型，如果未配置则返回 local
+func (c *UploadConfig) GetStorage() string {
+	if c.Storage == "" {
+		return UploadStorageLocal
+	}
+	return c.Storage
+}
+
+// GetPathTemplate 获取存储目录模板，如果未配置则返回 {yyyy}/{mm}/{dd}
+func (c *UploadConfig) GetPathTemplate() string {
+	if c.PathTemplate == "" {
+		return "{yyyy}/{mm}/{dd}"
+	}
+	return c.PathTemplate
+}
+
+// GetLocalDir 获取本地存储根目录，如果未配置则返回 ./uploads
+func (c *UploadConfig) GetLocalDir() string {
+	if c.LocalDir == "" {
+		return "./uploads"
+	}
+	return c.LocalDir
+}
+
+// GetRegion 获取区域，如果未配置则返回 us-east-1
+func (c *S3Config) GetRegion() string {
+	if c.Region == "" {
+		return "us-east-1"
+	}
+	return c.Region
+}
@@ -0,0 +1,679 @@
+// Package config 提供应用程序的配置结构定义
+// 本文件实现配置校验，根据系统开关检查各组件所需的配置是否完整、取值是否合法
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/zzsen/gin_core/utils/netutil"
+)
+
+// ValidationIssue 配置校验问题
+type ValidationIssue struct {
+	Field   string // 问题所在的配置项路径，如 "rabbitMQ.host"
+	Message string // 问题描述
+}
+
+// String 格式化为 "配置项: 问题描述"
+func (issue ValidationIssue) String() string {
+	return fmt.Sprintf("%s: %s", issue.Field, issue.Message)
+}
+
+// Validate 校验基础配置
+// 只检查配置本身，不会连接任何外部服务。检查内容：
+//   - system 中开启的组件（Redis、MySQL、RabbitMQ、Elasticsearch、Etcd）是否配置了连接信息，多实例配置的别名是否设置、Elasticsearch 集群别名是否重复
+//   - RabbitMQ 开启 TLS 时客户端证书和私钥是否成对配置，证书文件是否可读取和解析
+//   - RabbitMQ 发送失败消息的持久化方式是否可识别，使用 redis / db 时是否开启了 Redis / MySQL
+//   - RabbitMQ 启用发布限流时速率是否大于 0，突发容量是否为负数
+//   - Redis 部署模式是否可识别，哨兵模式是否配置了主节点名称和哨兵地址
+//   - 限流规则的速率、突发容量是否为负数
+//   - 启用并发限制时规则是否配置了路径和大于 0 的最大并发数，全局最大并发数和排队数是否为负数
+//   - 限流、会话、幂等键使用 Redis 存储时是否开启了 Redis（否则运行时会静默降级为内存存储）
+//   - 幂等键的存储类型是否可识别
+//   - CORS 允许携带凭证时来源是否包含 "*"
+//   - 服务和安全响应头的受信任代理地址是否可解析
+//   - 功能开关的灰度比例是否在 0-100 之间
+//   - 启用发件箱时是否同时开启了 MySQL 和 RabbitMQ
+//   - 审计日志的写入目标是否可识别，写入数据表时是否开启了 MySQL
+//   - 文件上传的存储类型是否可识别，使用 s3 时是否配置了地址和存储桶
+//   - 开启对象存储时是否启用了 objectStorage，服务商是否可识别，是否配置了存储桶、不含协议的服务地址和成对的访问密钥
+//   - 后台任务的重试次数是否为负数
+//   - 启用消息队列管理接口时是否配置了保护中间件
+//   - 启用熔断器和限流管理接口时是否配置了保护中间件
+//   - 熔断器状态变更通知的 Webhook 地址是否为 http / https 地址
+//   - 路由冲突处理方式是否可识别
+//   - 启用 gRPC 时端口是否合法、TLS 证书和私钥是否成对配置、与 HTTP 共用端口时是否配置了 TLS
+//   - 日志输出的类型、格式、级别是否可识别
+//   - 启用 API Key 认证时是否配置了 API Key，哈希是否合法，调用方名称是否为空或重复
+//   - 启用多租户时是否开启了 MySQL，租户映射的数据库别名是否存在于 dbList 中
+//   - 启用故障注入时规则是否配置了路径，延迟是否为负数，错误概率和应用比例是否在取值范围内
+//
+// 参数：
+//   - cfg: 基础配置
+//
+// 返回：
+//   - []ValidationIssue: 发现的问题列表，配置合法时返回空列表
+func Validate(cfg *BaseConfig) []ValidationIssue {
+	var issues []ValidationIssue
+	add := func(field, format string, args ...any) {
+		issues = append(issues, ValidationIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if cfg.System.UseRedis {
+		validateRedis(cfg, add)
+	}
+	if cfg.System.UseMysql {
+		validateMysql(cfg, add)
+	}
+	if cfg.System.UseRabbitMQ {
+		validateRabbitMQ(cfg, add)
+	}
+	if cfg.System.UseEs {
+		validateEs(cfg, add)
+	}
+	if cfg.System.UseEtcd && (cfg.Etcd == nil || len(cfg.Etcd.Addresses) == 0) {
+		add("etcd.addresses", "system.useEtcd 已开启，但未配置 Etcd 地址")
+	}
+	if cfg.System.UseObjectStorage {
+		validateObjectStorage(cfg, add)
+	}
+
+	validateLogOutputs(cfg, add)
+	if cfg.RateLimit.Enabled {
+		validateRateLimit(cfg, add)
+	}
+	if cfg.Session.Enabled && cfg.Session.GetStore() == "redis" && !cfg.System.UseRedis {
+		add("session.store", "会话使用 Redis 存储，但 system.useRedis 未开启，运行时将降级为内存存储")
+	}
+	if cfg.Idempotency.Enabled {
+		switch store := cfg.Idempotency.GetStore(); {
+		case store != "redis" && store != "memory":
+			add("idempotency.store", "无法识别的存储类型: %s（可选 redis、memory）", store)
+		case store == "redis" && !cfg.System.UseRedis:
+			add("idempotency.store", "幂等键使用 Redis 存储，但 system.useRedis 未开启，运行时将降级为内存存储")
+		}
+	}
+	if cfg.CORS.Enabled && cfg.CORS.AllowCredentials && slices.Contains(cfg.CORS.GetAllowOrigins(), "*") {
+		add("cors.allowOrigins", "allowCredentials 为 true 时 allowOrigins 不能包含 \"*\"，浏览器会拒绝携带凭证的跨域响应")
+	}
+	if _, err := netutil.ParseProxies(cfg.Service.TrustedProxies); err != nil {
+		add("service.trustedProxies", "%v", err)
+	}
+	if cfg.SecureHeaders.Enabled {
+		if _, err := cfg.SecureHeaders.ParseTrustedProxies(); err != nil {
+			add("secureHeaders.trustedProxies", "%v", err)
+		}
+	}
+	if cfg.CSRF.Enabled {
+		if cfg.CSRF.TokenLength != 0 && cfg.CSRF.TokenLength < 16 {
+			add("csrf.tokenLength", "令牌的随机字节数不能小于 16，当前为 %d", cfg.CSRF.TokenLength)
+		}
+		switch cfg.CSRF.CookieSameSite {
+		case "", "lax", "strict":
+		case "none":
+			if !cfg.CSRF.CookieSecure {
+				add("csrf.cookieSameSite", "cookieSameSite 为 none 时需开启 cookieSecure，浏览器会拒绝未设置 Secure 的 SameSite=None Cookie")
+			}
+		default:
+			add("csrf.cookieSameSite", "无法识别的 SameSite 属性: %s（可选 lax、strict、none）", cfg.CSRF.CookieSameSite)
+		}
+	}
+	if cfg.Concurrency.Enabled {
+		validateConcurrency(cfg, add)
+	}
+	if _, err := netutil.ParseProxies(cfg.Maintenance.AllowIPs); err != nil {
+		add("maintenance.allowIPs", "%v", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.FeatureFlags)) {
+		flag := cfg.FeatureFlags[name]
+		if percentage := flag.GetPercentage(); percentage < 0 || percentage > 100 {
+			add("featureFlags."+name+".percentage", "灰度比例必须在 0-100 之间: %v", percentage)
+		}
+	}
+	if cfg.Outbox.Enabled && (!cfg.System.UseMysql || !cfg.System.UseRabbitMQ) {
+		add("outbox.enabled", "发件箱需要同时开启 system.useMysql 和 system.useRabbitMQ")
+	}
+	if cfg.Audit.Enabled {
+		validateAudit(cfg, add)
+	}
+	validateUpload(cfg, add)
+	if cfg.Tasks.MaxRetries < 0 {
+		add("tasks.maxRetries", "重试次数不能为负数: %d", cfg.Tasks.MaxRetries)
+	}
+	if cfg.MQAdmin.Enabled && cfg.MQAdmin.Middleware == "" {
+		add("mqAdmin.middleware", "启用消息队列管理接口时必须配置保护中间件")
+	}
+	if cfg.ResilienceAdmin.Enabled && cfg.ResilienceAdmin.Middleware == "" {
+		add("resilienceAdmin.middleware", "启用熔断器和限流管理接口时必须配置保护中间件")
+	}
+	if cfg.ConfigInspect.Enabled && cfg.ConfigInspect.Middleware == "" {
+		add("configInspect.middleware", "启用配置查看接口时必须配置保护中间件")
+	}
+	if (cfg.Debug.EnablePprof || cfg.Debug.EnableStartupTimings) && cfg.Debug.ProtectMiddleware == "" {
+		add("debug.protectMiddleware", "启用调试接口时必须配置保护中间件")
+	}
+	if prefix := cfg.Debug.PprofPrefix; prefix != "" && !strings.HasPrefix(prefix, "/") {
+		add("debug.pprofPrefix", "pprof 接口路径前缀 %q 必须以 / 开头", prefix)
+	}
+	if webhookURL := cfg.CircuitBreaker.WebhookURL; webhookURL != "" {
+		if u, err := url.Parse(webhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("circuitBreaker.webhookUrl", "无效的 Webhook 地址 %q，需要 http 或 https 地址", webhookURL)
+		}
+	}
+	switch cfg.Service.GetRouteConflictPolicy() {
+	case RouteConflictError, RouteConflictWarn:
+	default:
+		add("service.routeConflictPolicy", "无法识别的路由冲突处理方式 %q，可选值: error、warn", cfg.Service.RouteConflictPolicy)
+	}
+	validateNotFound(cfg, add)
+	if _, err := cfg.Service.JSONTime.GetLocation(); err != nil {
+		add("service.jsonTime.timezone", "无法识别的时区 %q: %v", cfg.Service.JSONTime.Timezone, err)
+	}
+	if cfg.Grpc.Enabled {
+		validateGrpc(cfg, add)
+	}
+	if cfg.APIKey.Enabled {
+		validateAPIKey(cfg, add)
+	}
+	if cfg.Tenant.Enabled {
+		validateTenant(cfg, add)
+	}
+	if cfg.Chaos.Enabled {
+		validateChaos(cfg, add)
+	}
+	if cfg.Mirror.Enabled {
+		validateMirror(cfg, add)
+	}
+	validateProcessedMessage(cfg, add)
+	if cfg.System.StartupRetry.Enabled {
+		validateStartupRetry(cfg, add)
+	}
+	return issues
+}
+
+// validateNotFound 校验 404/405 响应配置：响应方式是否可识别，html 模式是否配置了页面文件，redirect 模式是否配置了重定向地址
+func validateNotFound(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	notFound := cfg.Service.NotFound
+	switch notFound.Mode {
+	case "", NotFoundModeJSON:
+	case NotFoundModeHTML:
+		if notFound.HTMLPath == "" {
+			add("service.notFound.htmlPath", "html 模式必须配置页面文件路径")
+		}
+	case NotFoundModeRedirect:
+		if notFound.RedirectTo == "" {
+			add("service.notFound.redirectTo", "redirect 模式必须配置重定向地址")
+		}
+	default:
+		add("service.notFound.mode", "无法识别的 404/405 响应方式 %q，可选值: json、html、redirect", notFound.Mode)
+	}
+}
+
+// validateConcurrency 校验并发限制配置：全局最大并发数和排队数是否为负数，规则是否配置了路径和大于 0 的最大并发数
+func validateConcurrency(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	if cfg.Concurrency.MaxConcurrent < 0 {
+		add("concurrency.maxConcurrent", "最大并发数不能为负数: %d", cfg.Concurrency.MaxConcurrent)
+	}
+	if cfg.Concurrency.MaxQueue < 0 {
+		add("concurrency.maxQueue", "排队数不能为负数: %d", cfg.Concurrency.MaxQueue)
+	}
+	for i, rule := range cfg.Concurrency.Rules {
+		field := fmt.Sprintf("concurrency.rules[%d]", i)
+		if rule.Path == "" {
+			add(field+".path", "未配置匹配路径")
+		}
+		if rule.MaxConcurrent <= 0 {
+			add(field+".maxConcurrent", "最大并发数必须大于 0: %d（路径 %s）", rule.MaxConcurrent, rule.Path)
+		}
+	}
+}
+
+// validateChaos 校验故障注入规则：路径是否配置，延迟是否为负数，错误概率和应用比例是否在取值范围内
+func validateChaos(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	for i, rule := range cfg.Chaos.Rules {
+		field := fmt.Sprintf("chaos.rules[%d]", i)
+		if rule.Path == "" {
+			add(field+".path", "未配置匹配路径")
+		}
+		if rule.LatencyMs < 0 || rule.LatencyJitterMs < 0 {
+			add(field+".latencyMs", "延迟不能为负数: %d / %d（路径 %s）", rule.LatencyMs, rule.LatencyJitterMs, rule.Path)
+		}
+		if rule.ErrorRate < 0 || rule.ErrorRate > 1 {
+			add(field+".errorRate", "错误概率必须在 0-1 之间: %v（路径 %s）", rule.ErrorRate, rule.Path)
+		}
+		if rule.Percentage < 0 || rule.Percentage > 100 {
+			add(field+".percentage", "应用比例必须在 0-100 之间: %v（路径 %s）", rule.Percentage, rule.Path)
+		}
+	}
+}
+
+// validateMirror 校验流量镜像规则：路径是否配置，影子服务地址是否为 http 或 https 地址，镜像比例是否在取值范围内，超时时间是否为负数
+func validateMirror(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	for i, rule := range cfg.Mirror.Rules {
+		field := fmt.Sprintf("mirror.rules[%d]", i)
+		if rule.Path == "" {
+			add(field+".path", "未配置匹配路径")
+		}
+		if u, err := url.Parse(rule.TargetBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add(field+".targetBaseURL", "无效的影子服务地址 %q，需要 http 或 https 地址（路径 %s）", rule.TargetBaseURL, rule.Path)
+		}
+		if rule.Percentage < 0 || rule.Percentage > 100 {
+			add(field+".percentage", "镜像比例必须在 0-100 之间: %v（路径 %s）", rule.Percentage, rule.Path)
+		}
+		if rule.TimeoutMs < 0 {
+			add(field+".timeoutMs", "超时时间不能为负数: %d（路径 %s）", rule.TimeoutMs, rule.Path)
+		}
+	}
+}
+
+// validateProcessedMessage 校验已处理消息表配置：自动建表或清理时是否开启了 MySQL，保留天数是否为负数，清理任务的 cron 表达式是否有效
+func validateProcessedMessage(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	processed := cfg.ProcessedMessage
+	if (processed.AutoMigrate || processed.RetentionDays > 0) && !cfg.System.UseMysql {
+		add("processedMessage", "自动创建已处理消息表或清理过期记录需要开启 system.useMysql")
+	}
+	if processed.RetentionDays < 0 {
+		add("processedMessage.retentionDays", "保留天数不能为负数: %d", processed.RetentionDays)
+	}
+	if processed.RetentionDays > 0 {
+		schedule := ScheduleInfo{Name: "processedMessageSweeper", Cron: processed.GetSweepCron()}
+		if _, err := schedule.ParseCron(); err != nil {
+			add("processedMessage.sweepCron", "%v", err)
+		}
+		if !cfg.System.UseSchedule {
+			add("processedMessage.retentionDays", "清理过期记录需要开启 system.useSchedule，否则清理任务不会执行")
+		}
+	}
+}
+
+// validateStartupRetry 校验启动重试策略：等待时间是否为负数，倍数是否小于 1，降级启动的服务名称是否可以识别
+func validateStartupRetry(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	retry := cfg.System.StartupRetry
+	if retry.MaxWait < 0 || retry.Interval < 0 || retry.MaxInterval < 0 {
+		add("system.startupRetry.maxWait", "等待时间不能为负数: maxWait %s, interval %s, maxInterval %s", retry.MaxWait, retry.Interval, retry.MaxInterval)
+	}
+	if retry.Backoff != 0 && retry.Backoff < 1 {
+		add("system.startupRetry.backoff", "等待时间的倍数不能小于 1: %v，将使用默认值 2", retry.Backoff)
+	}
+	for i, name := range retry.Optional {
+		switch name {
+		case "mysql", "redis", "rabbitmq", "elasticsearch":
+		default:
+			add(fmt.Sprintf("system.startupRetry.optional[%d]", i), "无法识别的服务名称 %q，可选值: mysql、redis、rabbitmq、elasticsearch", name)
+		}
+	}
+}
+
+// validateTenant 校验多租户配置：是否开启了 MySQL，租户映射的数据库别名是否存在于 dbList 中
+// 使用 core.SetTenantResolver 注册解析函数时 mapping 可以为空
+func validateTenant(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	if !cfg.System.UseMysql {
+		add("tenant.enabled", "多租户需要开启 system.useMysql")
+	}
+	aliases := make(map[string]bool, len(cfg.DbList))
+	for _, db := range cfg.DbList {
+		aliases[db.AliasName] = true
+	}
+	for _, tenant := range slices.Sorted(maps.Keys(cfg.Tenant.Mapping)) {
+		if alias := cfg.Tenant.Mapping[tenant]; !aliases[alias] {
+			add("tenant.mapping."+tenant, "数据库别名 %s 不存在于 dbList 中", alias)
+		}
+	}
+}
+
+// validateAPIKey 校验 API Key 配置：是否配置了 Key、HashedKey 是否合法、名称是否为空或重复
+func validateAPIKey(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	if len(cfg.APIKey.Keys) == 0 {
+		add("apiKey.keys", "已启用 API Key 认证，但未配置任何 API Key，所有请求都将被拒绝")
+	}
+	names := make(map[string]bool, len(cfg.APIKey.Keys))
+	for i := range cfg.APIKey.Keys {
+		entry := &cfg.APIKey.Keys[i]
+		field := fmt.Sprintf("apiKey.keys[%d]", i)
+		if _, err := entry.Digest(); err != nil {
+			add(field, "%v", err)
+		}
+		if entry.Key != "" && entry.HashedKey != "" {
+			add(field, "同时配置了 key 和 hashedKey，将使用 hashedKey")
+		}
+		switch {
+		case entry.Name == "":
+			add(field+".name", "调用方名称不能为空")
+		case names[entry.Name]:
+			add(field+".name", "调用方名称重复: %s", entry.Name)
+		}
+		names[entry.Name] = true
+	}
+}
+
+// validateGrpc 校验 gRPC 服务配置
+func validateGrpc(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	if cfg.Grpc.Port < 0 || cfg.Grpc.Port > 65535 {
+		add("grpc.port", "端口超出范围: %d", cfg.Grpc.Port)
+	}
+	tls := cfg.Grpc.TLS
+	if tls.Enabled() && (tls.CertFile == "" || tls.KeyFile == "") {
+		add("grpc.tls", "证书 certFile 和私钥 keyFile 需要同时配置")
+	}
+	if tls.Enabled() && cfg.Grpc.GetPort(cfg.Service.Port) == cfg.Service.Port {
+		add("grpc.tls", "与 HTTP 服务共用端口时不支持 TLS，请为 grpc.port 配置独立端口")
+	}
+}
+
+// validateRedis 校验 Redis 连接配置
+func validateRedis(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	if cfg.Redis == nil && len(cfg.RedisList) == 0 {
+		add("redis", "system.useRedis 已开启，但未配置 redis 或 redisList")
+		return
+	}
+	if cfg.Redis != nil {
+		validateRedisInfo("redis", cfg.Redis, add)
+	}
+	for i := range cfg.RedisList {
+		field := fmt.Sprintf("redisList[%d]", i)
+		if cfg.RedisList[i].AliasName == "" {
+			add(field+".aliasName", "多实例配置必须设置 aliasName")
+		}
+		validateRedisInfo(field, &cfg.RedisList[i], add)
+	}
+}
+
+// validateEs 校验 Elasticsearch 连接配置
+func validateEs(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	if cfg.Es == nil && len(cfg.EsList) == 0 {
+		add("es.addresses", "system.useEs 已开启，但未配置 Elasticsearch 地址")
+		return
+	}
+	aliases := make(map[string]bool)
+	if cfg.Es != nil {
+		if len(cfg.Es.Addresses) == 0 {
+			add("es.addresses", "system.useEs 已开启，但未配置 Elasticsearch 地址")
+		}
+		aliases[cfg.Es.GetAliasName()] = true
+	}
+	for i, es := range cfg.EsList {
+		field := fmt.Sprintf("esList[%d]", i)
+		if es.AliasName == "" {
+			add(field+".aliasName", "多实例配置必须设置 aliasName")
+		} else if aliases[es.AliasName] {
+			add(field+".aliasName", "集群别名重复: %s", es.AliasName)
+		}
+		aliases[es.AliasName] = true
+		if len(es.Addresses) == 0 {
+			add(field+".addresses", "未配置 Elasticsearch 地址")
+		}
+	}
+}
+
+// validateRedisInfo 校验单个 Redis 实例的部署模式和对应的地址配置
+func validateRedisInfo(field string, info *RedisInfo, add func(field, format string, args ...any)) {
+	switch mode := info.GetMode(); mode {
+	case RedisModeStandalone:
+		if info.Addr == "" {
+			add(field+".addr", "未配置 Redis 地址")
+		}
+	case RedisModeSentinel:
+		if info.MasterName == "" {
+			add(field+".masterName", "哨兵模式下未配置主节点名称")
+		}
+		if len(info.SentinelAddrs) == 0 {
+			add(field+".sentinelAddrs", "哨兵模式下未配置哨兵节点地址")
+		}
+	case RedisModeCluster:
+		if len(info.ClusterAddrs) == 0 {
+			add(field+".clusterAddrs", "集群模式下未配置节点地址")
+		}
+	default:
+		add(field+".mode", "无法识别的部署模式: %s（可选 standalone、sentinel、cluster）", mode)
+	}
+}
+
+// validateMysql 校验 MySQL 连接配置
+func validateMysql(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	if cfg.Db == nil && len(cfg.DbList) == 0 && len(cfg.DbResolvers) == 0 {
+		add("db", "system.useMysql 已开启，但未配置 db、dbList 或 dbResolvers")
+		return
+	}
+	if cfg.Db != nil && cfg.Db.Host == "" {
+		add("db.host", "未配置数据库地址")
+	}
+	for i, db := range cfg.DbList {
+		if db.Host == "" {
+			add(fmt.Sprintf("dbList[%d].host", i), "未配置数据库地址")
+		}
+	}
+}
+
+// validateRabbitMQ 校验 RabbitMQ 连接配置
+func validateRabbitMQ(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	if cfg.RabbitMQ.Host == "" && len(cfg.RabbitMQList) == 0 {
+		add("rabbitMQ.host", "system.useRabbitMQ 已开启，但未配置 RabbitMQ 地址")
+		return
+	}
+	if cfg.RabbitMQ.Host != "" {
+		validateRabbitMQTLS("rabbitMQ", &cfg.RabbitMQ, add)
+		validateFailedMessageStore(cfg, "rabbitMQ", &cfg.RabbitMQ, add)
+		validatePublishRateLimit("rabbitMQ", &cfg.RabbitMQ, add)
+	}
+	for i, mq := range cfg.RabbitMQList {
+		field := fmt.Sprintf("rabbitMQList[%d]", i)
+		if mq.AliasName == "" {
+			add(field+".aliasName", "多实例配置必须设置 aliasName")
+		}
+		if mq.Host == "" {
+			add(field+".host", "未配置 RabbitMQ 地址")
+		}
+		validateRabbitMQTLS(field, &cfg.RabbitMQList[i], add)
+		validateFailedMessageStore(cfg, field, &cfg.RabbitMQList[i], add)
+		validatePublishRateLimit(field, &cfg.RabbitMQList[i], add)
+	}
+}
+
+// validatePublishRateLimit 校验启用发布限流时速率是否大于 0、突发容量是否为负数
+func validatePublishRateLimit(field string, info *RabbitMQInfo, add func(field, format string, args ...any)) {
+	limit := info.PublishRateLimit
+	if !limit.Enabled {
+		return
+	}
+	field += ".publishRateLimit"
+	if limit.MessagesPerSecond <= 0 {
+		add(field+".messagesPerSecond", "已启用发布限流，但 messagesPerSecond 必须大于 0")
+	}
+	if limit.Burst < 0 {
+		add(field+".burst", "突发容量不能为负数: %d", limit.Burst)
+	}
+}
+
+// validateFailedMessageStore 校验发送失败消息的持久化方式是否可识别，使用 redis / db 时是否开启了对应的组件
+func validateFailedMessageStore(cfg *BaseConfig, field string, info *RabbitMQInfo, add func(field, format string, args ...any)) {
+	field += ".failedMessageStore"
+	switch info.GetFailedMessageStore() {
+	case FailedMessageStoreNone:
+	case FailedMessageStoreRedis:
+		if !cfg.System.UseRedis {
+			add(field, "发送失败消息保存到 Redis，但 system.useRedis 未开启")
+		}
+	case FailedMessageStoreDB:
+		if !cfg.System.UseMysql {
+			add(field, "发送失败消息保存到数据表，但 system.useMysql 未开启")
+		}
+	default:
+		add(field, "无法识别的持久化方式 %q，可选值: none、redis、db", info.FailedMessageStore)
+	}
+}
+
+// validateRabbitMQTLS 校验 RabbitMQ 的 TLS 证书配置：客户端证书和私钥是否同时配置、证书文件是否可读、能否生成 TLS 连接配置
+func validateRabbitMQTLS(field string, info *RabbitMQInfo, add func(field, format string, args ...any)) {
+	if !info.UseTLS {
+		return
+	}
+	valid := true
+	if info.CertFile != "" && info.KeyFile == "" {
+		add(field+".keyFile", "已配置客户端证书 certFile，但未配置私钥 keyFile")
+		valid = false
+	}
+	if info.KeyFile != "" && info.CertFile == "" {
+		add(field+".certFile", "已配置私钥 keyFile，但未配置客户端证书 certFile")
+		valid = false
+	}
+	files := []struct{ name, path string }{
+		{"caCertFile", info.CACertFile},
+		{"certFile", info.CertFile},
+		{"keyFile", info.KeyFile},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		if _, err := os.ReadFile(file.path); err != nil {
+			add(field+"."+file.name, "无法读取证书文件: %v", err)
+			valid = false
+		}
+	}
+	if !valid {
+		return
+	}
+	if _, err := info.TLSConfig(); err != nil {
+		add(field+".useTLS", "%v", err)
+	}
+}
+
+// validateAudit 校验审计日志配置
+func validateAudit(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	switch cfg.Audit.GetSink() {
+	case AuditSinkLog:
+	case AuditSinkDB:
+		if !cfg.System.UseMysql {
+			add("audit.sink", "审计日志写入数据表，但 system.useMysql 未开启")
+		}
+	default:
+		add("audit.sink", "无法识别的写入目标 %q，可选值: log、db", cfg.Audit.Sink)
+	}
+}
+
+// validateUpload 校验文件上传存储配置
+func validateUpload(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	switch cfg.Upload.GetStorage() {
+	case UploadStorageLocal:
+	case UploadStorageS3:
+		if cfg.Upload.S3.Endpoint == "" {
+			add("upload.s3.endpoint", "上传文件使用 s3 存储，但未配置服务地址")
+		}
+		if cfg.Upload.S3.Bucket == "" {
+			add("upload.s3.bucket", "上传文件使用 s3 存储，但未配置存储桶")
+		}
+	default:
+		add("upload.storage", "无法识别的存储类型 %q，可选值: local、s3", cfg.Upload.Storage)
+	}
+}
+
+// validateObjectStorage 校验对象存储配置
+func validateObjectStorage(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	storage := &cfg.ObjectStorage
+	if !storage.Enabled {
+		add("objectStorage.enabled", "system.useObjectStorage 已开启，但 objectStorage.enabled 未开启")
+	}
+	switch provider := storage.GetProvider(); provider {
+	case ObjectStorageS3, ObjectStorageOSS, ObjectStorageMinIO:
+	default:
+		add("objectStorage.provider", "无法识别的服务商 %q，可选值: s3、oss、minio", storage.Provider)
+	}
+	switch endpoint := storage.GetEndpoint(); {
+	case endpoint == "":
+		add("objectStorage.endpoint", "未配置服务地址")
+	case strings.Contains(endpoint, "://"):
+		add("objectStorage.endpoint", "服务地址 %q 不能包含协议，通过 useSSL 指定是否使用 https", endpoint)
+	}
+	if storage.Bucket == "" {
+		add("objectStorage.bucket", "未配置存储桶")
+	}
+	if (storage.AccessKey == "") != (storage.SecretKey == "") {
+		add("objectStorage.secretKey", "accessKey 和 secretKey 需要同时配置")
+	}
+}
+
+// validateLogOutputs 校验日志输出配置和异步写入配置
+func validateLogOutputs(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	switch cfg.Log.Async.GetDropPolicy() {
+	case LogDropBlock, LogDropOldest, LogDropNew:
+	default:
+		add("log.async.dropPolicy", "不支持的缓冲区已满处理方式: %s，可选值 block / drop-oldest / drop-new", cfg.Log.Async.DropPolicy)
+	}
+	for i, output := range cfg.Log.Outputs {
+		field := fmt.Sprintf("log.outputs[%d]", i)
+		switch output.GetType() {
+		case LogOutputConsole, LogOutputFile:
+		default:
+			add(field+".type", "不支持的日志输出类型: %s，可选值 console / file", output.Type)
+		}
+		switch output.GetFormat() {
+		case LogFormatText, LogFormatJSON:
+		default:
+			add(field+".format", "不支持的日志输出格式: %s，可选值 text / json", output.Format)
+		}
+		switch strings.ToLower(output.GetLevel()) {
+		case "trace", "debug", "info", "warn", "warning", "error", "fatal", "panic":
+		default:
+			add(field+".level", "不支持的日志级别: %s", output.Level)
+		}
+	}
+}
+
+// rateLimitRuleBurst 规则实际使用的突发容量，与限流中间件的取值方式一致
+func rateLimitRuleBurst(cfg RateLimitConfig, rule RateLimitRule) int {
+	if burst := rule.GetBurst(); burst > 0 {
+		return burst
+	}
+	return cfg.GetDefaultBurst()
+}
+
+// validateRateLimit 校验限流配置
+// 速率、突发容量为 0 表示使用默认值，只有负数视为非法
+func validateRateLimit(cfg *BaseConfig, add func(field, format string, args ...any)) {
+	if cfg.RateLimit.DefaultRate < 0 {
+		add("rateLimit.defaultRate", "默认速率不能为负数: %d", cfg.RateLimit.DefaultRate)
+	}
+	if cfg.RateLimit.DefaultBurst < 0 {
+		add("rateLimit.defaultBurst", "默认突发容量不能为负数: %d", cfg.RateLimit.DefaultBurst)
+	}
+	if cfg.RateLimit.GetStore() == "redis" && !cfg.System.UseRedis {
+		add("rateLimit.store", "限流使用 Redis 存储，但 system.useRedis 未开启，运行时将降级为内存限流器")
+	}
+	memoryStore := cfg.RateLimit.MemoryStore
+	if memoryStore.Shards < 0 {
+		add("rateLimit.memoryStore.shards", "分片数不能为负数: %d", memoryStore.Shards)
+	}
+	if memoryStore.IdleTTL < 0 {
+		add("rateLimit.memoryStore.idleTTL", "空闲时间不能为负数: %d", memoryStore.IdleTTL)
+	}
+	if memoryStore.MaxEntries < 0 {
+		add("rateLimit.memoryStore.maxEntries", "限流键数量上限不能为负数: %d", memoryStore.MaxEntries)
+	}
+	for i, rule := range cfg.RateLimit.Rules {
+		field := fmt.Sprintf("rateLimit.rules[%d]", i)
+		if rule.Path == "" {
+			add(field+".path", "未配置匹配路径")
+		}
+		if rule.Rate < 0 {
+			add(field+".rate", "速率不能为负数: %d（路径 %s）", rule.Rate, rule.Path)
+		}
+		if rule.Burst < 0 {
+			add(field+".burst", "突发容量不能为负数: %d（路径 %s）", rule.Burst, rule.Path)
+		}
+		if rule.Cost < 0 {
+			add(field+".cost", "请求消耗不能为负数: %d（路径 %s）", rule.Cost, rule.Path)
+		} else if burst := rateLimitRuleBurst(cfg.RateLimit, rule); rule.Cost > burst {
+			add(field+".cost", "请求消耗 %d 超过突发容量 %d，匹配的请求将始终被拒绝（路径 %s）", rule.Cost, burst, rule.Path)
+		}
+		switch rule.GetKeyType() {
+		case "ip", "user", "global":
+		default:
+			add(field+".keyType", "不支持的限流维度: %s，可选值 ip / user / global", rule.KeyType)
+		}
+	}
+}
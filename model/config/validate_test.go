@@ -0,0 +1,660 @@
+// Package config 配置校验测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 Validate 的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 合法配置不产生问题
+// 2. 系统开关开启但缺少连接配置（Redis、MySQL、RabbitMQ、Elasticsearch、Etcd），Redis 部署模式无法识别或哨兵配置不完整
+// 3. 限流规则取值非法、限流/会话使用 Redis 存储但未开启 Redis
+// 4. CORS 允许携带凭证时来源包含 "*"、启用发件箱但未开启 MySQL/RabbitMQ
+// 5. 多个问题一次性全部报告
+// 6. 日志输出的类型、格式、级别无法识别，异步写入的缓冲区已满处理方式无法识别
+// 7. API Key 未配置、哈希非法、调用方名称为空或重复
+// 8. 多租户未开启 MySQL、租户映射的数据库别名不存在
+// 9. 故障注入规则缺少路径、延迟为负数、错误概率或应用比例超出取值范围
+// 10. 并发限制的全局最大并发数、排队数为负数，规则缺少路径或最大并发数
+// 11. 对象存储未启用、服务商无法识别、缺少存储桶、服务地址包含协议、访问密钥不成对
+// 12. 启动重试的等待时间为负数、倍数小于 1、降级启动的服务名称无法识别
+// 13. 404/405 响应方式无法识别，html 模式缺少页面文件，redirect 模式缺少重定向地址
+// 14. JSON 时间的时区无法识别
+// 15. 流量镜像规则缺少路径、影子服务地址非法、镜像比例超出取值范围、超时时间为负数
+// 16. 已处理消息表未开启 MySQL、保留天数为负数、清理任务的 cron 表达式无效或未开启定时任务
+// 17. RabbitMQ 发送失败消息的持久化方式无法识别、使用 redis / db 但未开启 Redis / MySQL
+// 18. 功能开关的灰度比例超出取值范围
+//
+// 运行测试：go test -v ./model/config/... -run Validate
+// ==================================================
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// issueFields 提取问题列表中的配置项路径
+func issueFields(issues []ValidationIssue) []string {
+	fields := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		fields = append(fields, issue.Field)
+	}
+	return fields
+}
+
+// TestValidate_Valid 测试合法配置
+//
+// 【功能点】验证开启的组件均配置完整时不产生问题
+// 【测试流程】构造开启 Redis、RabbitMQ、限流、CORS 的完整配置，断言问题列表为空
+func TestValidate_Valid(t *testing.T) {
+	cfg := &BaseConfig{
+		System:   SystemInfo{UseRedis: true, UseRabbitMQ: true},
+		Redis:    &RedisInfo{Addr: "127.0.0.1:6379"},
+		RabbitMQ: RabbitMQInfo{Host: "127.0.0.1", Port: 5672},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Store:   "redis",
+			Rules:   []RateLimitRule{{Path: "/api/*", Rate: 10, Burst: 20, KeyType: "user"}},
+		},
+		CORS: CORSConfig{
+			Enabled:          true,
+			AllowOrigins:     []string{"https://example.com"},
+			AllowCredentials: true,
+		},
+	}
+	assert.Empty(t, Validate(cfg))
+
+	// 未开启任何组件的空配置同样合法
+	assert.Empty(t, Validate(&BaseConfig{}))
+}
+
+// TestValidate_SystemToggles 测试系统开关与连接配置的一致性
+//
+// 【功能点】验证开启组件但缺少连接配置时报告对应的配置项
+// 【测试流程】逐个构造缺失配置的场景，断言问题列表中的配置项路径
+func TestValidate_SystemToggles(t *testing.T) {
+	overPercentage, negativePercentage := 120.0, -1.0
+	tests := []struct {
+		name   string
+		cfg    BaseConfig
+		fields []string
+	}{
+		{
+			name:   "Redis 未配置",
+			cfg:    BaseConfig{System: SystemInfo{UseRedis: true}},
+			fields: []string{"redis"},
+		},
+		{
+			name:   "Redis 地址为空",
+			cfg:    BaseConfig{System: SystemInfo{UseRedis: true}, Redis: &RedisInfo{}},
+			fields: []string{"redis.addr"},
+		},
+		{
+			name:   "Redis 集群无节点",
+			cfg:    BaseConfig{System: SystemInfo{UseRedis: true}, Redis: &RedisInfo{UseCluster: true}},
+			fields: []string{"redis.clusterAddrs"},
+		},
+		{
+			name:   "Redis 哨兵模式缺少主节点名称和哨兵地址",
+			cfg:    BaseConfig{System: SystemInfo{UseRedis: true}, Redis: &RedisInfo{Mode: RedisModeSentinel, Addr: "127.0.0.1:6379"}},
+			fields: []string{"redis.masterName", "redis.sentinelAddrs"},
+		},
+		{
+			name: "Redis 部署模式无法识别",
+			cfg: BaseConfig{System: SystemInfo{UseRedis: true}, RedisList: []RedisInfo{
+				{AliasName: "cache", Mode: RedisModeCluster, ClusterAddrs: []string{"127.0.0.1:7000"}},
+				{AliasName: "queue", Mode: "replica"},
+			}},
+			fields: []string{"redisList[1].mode"},
+		},
+		{
+			name: "Redis 多实例缺少别名和地址",
+			cfg: BaseConfig{System: SystemInfo{UseRedis: true}, RedisList: []RedisInfo{
+				{AliasName: "cache", Addr: "127.0.0.1:6379"},
+				{},
+			}},
+			fields: []string{"redisList[1].aliasName", "redisList[1].addr"},
+		},
+		{
+			name:   "MySQL 未配置",
+			cfg:    BaseConfig{System: SystemInfo{UseMysql: true}},
+			fields: []string{"db"},
+		},
+		{
+			name:   "MySQL 地址为空",
+			cfg:    BaseConfig{System: SystemInfo{UseMysql: true}, Db: &DbInfo{}, DbList: []DbInfo{{Host: "db"}, {}}},
+			fields: []string{"db.host", "dbList[1].host"},
+		},
+		{
+			name:   "RabbitMQ 地址为空",
+			cfg:    BaseConfig{System: SystemInfo{UseRabbitMQ: true}, RabbitMQ: RabbitMQInfo{Port: 5672}},
+			fields: []string{"rabbitMQ.host"},
+		},
+		{
+			name: "RabbitMQ 多实例地址为空",
+			cfg: BaseConfig{System: SystemInfo{UseRabbitMQ: true}, RabbitMQList: RabbitMqListInfo{
+				{AliasName: "order"},
+			}},
+			fields: []string{"rabbitMQList[0].host"},
+		},
+		{
+			name:   "Elasticsearch 与 Etcd 未配置",
+			cfg:    BaseConfig{System: SystemInfo{UseEs: true, UseEtcd: true}, Etcd: &EtcdInfo{}},
+			fields: []string{"es.addresses", "etcd.addresses"},
+		},
+		{
+			name: "Elasticsearch 多集群别名缺失与重复",
+			cfg: BaseConfig{System: SystemInfo{UseEs: true}, Es: &EsInfo{Addresses: []string{"es:9200"}}, EsList: EsListInfo{
+				{AliasName: "default", Addresses: []string{"es2:9200"}},
+				{},
+			}},
+			fields: []string{"esList[0].aliasName", "esList[1].aliasName", "esList[1].addresses"},
+		},
+		{
+			name:   "发件箱缺少 MySQL",
+			cfg:    BaseConfig{System: SystemInfo{UseRabbitMQ: true}, RabbitMQ: RabbitMQInfo{Host: "mq"}, Outbox: OutboxConfig{Enabled: true}},
+			fields: []string{"outbox.enabled"},
+		},
+		{
+			name:   "审计日志写入目标非法",
+			cfg:    BaseConfig{Audit: AuditConfig{Enabled: true, Sink: "kafka"}},
+			fields: []string{"audit.sink"},
+		},
+		{
+			name:   "审计日志写入数据表缺少 MySQL",
+			cfg:    BaseConfig{Audit: AuditConfig{Enabled: true, Sink: AuditSinkDB}},
+			fields: []string{"audit.sink"},
+		},
+		{
+			name:   "上传存储类型非法",
+			cfg:    BaseConfig{Upload: UploadConfig{Storage: "oss"}},
+			fields: []string{"upload.storage"},
+		},
+		{
+			name:   "上传 s3 存储缺少地址和存储桶",
+			cfg:    BaseConfig{Upload: UploadConfig{Storage: UploadStorageS3}},
+			fields: []string{"upload.s3.endpoint", "upload.s3.bucket"},
+		},
+		{
+			name:   "熔断器 Webhook 地址无效",
+			cfg:    BaseConfig{CircuitBreaker: CircuitBreakerConfig{WebhookURL: "alerts.local/hook"}},
+			fields: []string{"circuitBreaker.webhookUrl"},
+		},
+		{
+			name:   "安全响应头代理地址无法解析",
+			cfg:    BaseConfig{SecureHeaders: SecureHeadersConfig{Enabled: true, TrustedProxies: []string{"10.0.0.0/8", "proxy.local"}}},
+			fields: []string{"secureHeaders.trustedProxies"},
+		},
+		{
+			name:   "CSRF 令牌长度过短、SameSite 非法",
+			cfg:    BaseConfig{CSRF: CSRFConfig{Enabled: true, TokenLength: 8, CookieSameSite: "always"}},
+			fields: []string{"csrf.tokenLength", "csrf.cookieSameSite"},
+		},
+		{
+			name:   "CSRF SameSite=None 未开启 Secure",
+			cfg:    BaseConfig{CSRF: CSRFConfig{Enabled: true, CookieSameSite: "none"}},
+			fields: []string{"csrf.cookieSameSite"},
+		},
+		{
+			name:   "服务受信任代理地址无法解析",
+			cfg:    BaseConfig{Service: ServiceInfo{TrustedProxies: []string{"10.0.0.0/33"}}},
+			fields: []string{"service.trustedProxies"},
+		},
+		{
+			name:   "维护模式放行 IP 无法解析",
+			cfg:    BaseConfig{Maintenance: MaintenanceConfig{AllowIPs: []string{"10.0.0.1", "ops.local"}}},
+			fields: []string{"maintenance.allowIPs"},
+		},
+		{
+			name:   "功能开关灰度比例超出范围",
+			cfg:    BaseConfig{FeatureFlags: FeatureFlags{"a": {Percentage: &overPercentage}, "b": {Percentage: &negativePercentage}, "c": {}}},
+			fields: []string{"featureFlags.a.percentage", "featureFlags.b.percentage"},
+		},
+		{
+			name:   "幂等键存储类型非法",
+			cfg:    BaseConfig{Idempotency: IdempotencyConfig{Enabled: true, Store: "file"}},
+			fields: []string{"idempotency.store"},
+		},
+		{
+			name:   "幂等键使用 Redis 存储但未开启 Redis",
+			cfg:    BaseConfig{Idempotency: IdempotencyConfig{Enabled: true}},
+			fields: []string{"idempotency.store"},
+		},
+		{
+			name:   "后台任务重试次数为负数",
+			cfg:    BaseConfig{Tasks: TaskRunnerConfig{MaxRetries: -1}},
+			fields: []string{"tasks.maxRetries"},
+		},
+		{
+			name:   "消息队列管理接口未配置保护中间件",
+			cfg:    BaseConfig{MQAdmin: MQAdminConfig{Enabled: true}},
+			fields: []string{"mqAdmin.middleware"},
+		},
+		{
+			name:   "熔断器和限流管理接口未配置保护中间件",
+			cfg:    BaseConfig{ResilienceAdmin: ResilienceAdminConfig{Enabled: true}},
+			fields: []string{"resilienceAdmin.middleware"},
+		},
+		{
+			name:   "配置查看接口未配置保护中间件",
+			cfg:    BaseConfig{ConfigInspect: ConfigInspectConfig{Enabled: true}},
+			fields: []string{"configInspect.middleware"},
+		},
+		{
+			name:   "调试接口未配置保护中间件",
+			cfg:    BaseConfig{Debug: DebugConfig{EnableStartupTimings: true}},
+			fields: []string{"debug.protectMiddleware"},
+		},
+		{
+			name:   "pprof 路径前缀不以斜杠开头",
+			cfg:    BaseConfig{Debug: DebugConfig{PprofPrefix: "debug/pprof"}},
+			fields: []string{"debug.pprofPrefix"},
+		},
+		{
+			name:   "路由冲突处理方式非法",
+			cfg:    BaseConfig{Service: ServiceInfo{RouteConflictPolicy: "ignore"}},
+			fields: []string{"service.routeConflictPolicy"},
+		},
+		{
+			name:   "404/405 响应方式非法",
+			cfg:    BaseConfig{Service: ServiceInfo{NotFound: NotFoundConfig{Mode: "text"}}},
+			fields: []string{"service.notFound.mode"},
+		},
+		{
+			name:   "404/405 html 模式未配置页面文件",
+			cfg:    BaseConfig{Service: ServiceInfo{NotFound: NotFoundConfig{Mode: NotFoundModeHTML}}},
+			fields: []string{"service.notFound.htmlPath"},
+		},
+		{
+			name:   "JSON 时间时区无法识别",
+			cfg:    BaseConfig{Service: ServiceInfo{JSONTime: JSONTimeConfig{Timezone: "Mars/Olympus"}}},
+			fields: []string{"service.jsonTime.timezone"},
+		},
+		{
+			name:   "404/405 redirect 模式未配置重定向地址",
+			cfg:    BaseConfig{Service: ServiceInfo{NotFound: NotFoundConfig{Mode: NotFoundModeRedirect}}},
+			fields: []string{"service.notFound.redirectTo"},
+		},
+		{
+			name: "gRPC 端口越界与 TLS 配置不完整",
+			cfg: BaseConfig{Grpc: GrpcConfig{Enabled: true, Port: 70000,
+				TLS: GrpcTLSConfig{CertFile: "server.crt"}}},
+			fields: []string{"grpc.port", "grpc.tls"},
+		},
+		{
+			name: "gRPC 共用 HTTP 端口时配置 TLS",
+			cfg: BaseConfig{Service: ServiceInfo{Port: 8080}, Grpc: GrpcConfig{Enabled: true,
+				TLS: GrpcTLSConfig{CertFile: "server.crt", KeyFile: "server.key"}}},
+			fields: []string{"grpc.tls"},
+		},
+		{
+			name:   "组件未开启时不检查",
+			cfg:    BaseConfig{Redis: &RedisInfo{}, Db: &DbInfo{}},
+			fields: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.fields, issueFields(Validate(&tt.cfg)))
+		})
+	}
+}
+
+// TestValidate_RateLimit 测试限流配置校验
+//
+// 【功能点】验证负数速率/突发容量、缺少路径、非法限流维度、Redis 存储未开启 Redis、负数或超过突发容量的请求消耗、
+// 负数的内存限流器分片数和空闲时间均被报告
+// 【测试流程】构造包含多条非法规则的限流配置，断言问题列表；限流未启用时不检查
+func TestValidate_RateLimit(t *testing.T) {
+	cfg := &BaseConfig{
+		RateLimit: RateLimitConfig{
+			Enabled:      true,
+			Store:        "redis",
+			DefaultBurst: -1,
+			MemoryStore:  RateLimitMemoryStoreConfig{Shards: -1, IdleTTL: -60, MaxEntries: 1000},
+			Rules: []RateLimitRule{
+				{Path: "/api/ok", Rate: 10},
+				{Path: "/api/bad", Rate: 10, Burst: -5},
+				{Rate: -1, KeyType: "tenant"},
+				{Path: "/api/cost", Rate: 5, Burst: 10, Cost: -1},
+				{Path: "/api/export", Rate: 1, Burst: 10, Cost: 20},
+				{Path: "/api/report", Rate: 1, Burst: 10, Cost: 10},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		"rateLimit.defaultBurst",
+		"rateLimit.store",
+		"rateLimit.memoryStore.shards",
+		"rateLimit.memoryStore.idleTTL",
+		"rateLimit.rules[1].burst",
+		"rateLimit.rules[2].path",
+		"rateLimit.rules[2].rate",
+		"rateLimit.rules[2].keyType",
+		"rateLimit.rules[3].cost",
+		"rateLimit.rules[4].cost",
+	}, issueFields(Validate(cfg)))
+
+	cfg.RateLimit.Enabled = false
+	assert.Empty(t, Validate(cfg))
+}
+
+// TestValidate_Chaos 测试故障注入配置校验
+//
+// 【功能点】验证缺少路径、负数延迟、超出取值范围的错误概率和应用比例均被报告
+// 【测试流程】构造包含多条非法规则的故障注入配置，断言问题列表；故障注入未启用时不检查
+func TestValidate_Chaos(t *testing.T) {
+	cfg := &BaseConfig{
+		Chaos: ChaosConfig{
+			Enabled: true,
+			Rules: []ChaosRule{
+				{Path: "/api/ok", LatencyMs: 100, ErrorRate: 0.5, Percentage: 50},
+				{Path: "/api/bad", LatencyJitterMs: -1, ErrorRate: 1.5},
+				{Percentage: 120},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		"chaos.rules[1].latencyMs",
+		"chaos.rules[1].errorRate",
+		"chaos.rules[2].path",
+		"chaos.rules[2].percentage",
+	}, issueFields(Validate(cfg)))
+
+	cfg.Chaos.Enabled = false
+	assert.Empty(t, Validate(cfg))
+}
+
+// TestValidate_Mirror 测试流量镜像配置校验
+//
+// 【功能点】验证缺少路径、非 http(s) 的影子服务地址、超出取值范围的镜像比例和负数超时时间均被报告
+// 【测试流程】构造包含多条非法规则的流量镜像配置，断言问题列表；流量镜像未启用时不检查
+func TestValidate_Mirror(t *testing.T) {
+	cfg := &BaseConfig{
+		Mirror: MirrorConfig{
+			Enabled: true,
+			Rules: []MirrorRule{
+				{Path: "/api/ok", TargetBaseURL: "http://shadow:8080", Percentage: 10},
+				{Path: "/api/bad", TargetBaseURL: "shadow:8080", TimeoutMs: -1},
+				{TargetBaseURL: "https://shadow", Percentage: 101},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		"mirror.rules[1].targetBaseURL",
+		"mirror.rules[1].timeoutMs",
+		"mirror.rules[2].path",
+		"mirror.rules[2].percentage",
+	}, issueFields(Validate(cfg)))
+
+	cfg.Mirror.Enabled = false
+	assert.Empty(t, Validate(cfg))
+}
+
+// TestValidate_ProcessedMessage 测试已处理消息表配置校验
+//
+// 【功能点】验证自动建表未开启 MySQL、保留天数为负数、清理任务的 cron 表达式无效、清理时未开启定时任务均被报告
+// 【测试流程】
+//  1. 开启自动建表但未开启 MySQL，断言报告 processedMessage
+//  2. 开启 MySQL，保留天数为负数，断言报告 retentionDays
+//  3. 保留天数为正数，cron 表达式无效且未开启定时任务，断言报告 sweepCron 和 retentionDays；修正后无问题
+func TestValidate_ProcessedMessage(t *testing.T) {
+	cfg := &BaseConfig{ProcessedMessage: ProcessedMessageConfig{AutoMigrate: true}}
+	assert.Equal(t, []string{"processedMessage"}, issueFields(Validate(cfg)))
+
+	cfg.System.UseMysql = true
+	cfg.Db = &DbInfo{Host: "127.0.0.1", Port: 3306, DBName: "test", Username: "root"}
+	cfg.ProcessedMessage.RetentionDays = -1
+	assert.Equal(t, []string{"processedMessage.retentionDays"}, issueFields(Validate(cfg)))
+
+	cfg.ProcessedMessage.RetentionDays = 7
+	cfg.ProcessedMessage.SweepCron = "every day"
+	assert.Equal(t, []string{"processedMessage.sweepCron", "processedMessage.retentionDays"}, issueFields(Validate(cfg)))
+
+	cfg.ProcessedMessage.SweepCron = ""
+	cfg.System.UseSchedule = true
+	assert.Empty(t, Validate(cfg))
+}
+
+// TestValidate_Concurrency 测试并发限制配置校验
+//
+// 【功能点】验证全局最大并发数、排队数为负数，规则缺少路径或最大并发数不大于 0 时被报告
+// 【测试流程】构造包含非法全局配置和规则的并发限制配置，断言问题列表；并发限制未启用时不检查
+func TestValidate_Concurrency(t *testing.T) {
+	cfg := &BaseConfig{
+		Concurrency: ConcurrencyConfig{
+			Enabled:       true,
+			MaxConcurrent: -1,
+			MaxQueue:      -5,
+			Rules: []ConcurrencyRule{
+				{Path: "/api/upload", MaxConcurrent: 4, MaxQueue: -1},
+				{Path: "/api/export"},
+				{MaxConcurrent: 2},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		"concurrency.maxConcurrent",
+		"concurrency.maxQueue",
+		"concurrency.rules[1].maxConcurrent",
+		"concurrency.rules[2].path",
+	}, issueFields(Validate(cfg)))
+
+	cfg.Concurrency.Enabled = false
+	assert.Empty(t, Validate(cfg))
+}
+
+// TestValidate_ObjectStorage 测试对象存储配置校验
+//
+// 【功能点】验证开启 system.useObjectStorage 时对象存储配置的完整性和取值被检查
+// 【测试流程】
+//  1. 未启用 objectStorage、服务商无法识别、未配置存储桶、服务地址包含协议、只配置 accessKey，断言全部报告
+//  2. s3 未配置服务地址时使用默认地址，配置完整时不报告问题
+//  3. oss 未配置服务地址时报告问题；关闭 system.useObjectStorage 后不检查
+func TestValidate_ObjectStorage(t *testing.T) {
+	cfg := &BaseConfig{
+		System: SystemInfo{UseObjectStorage: true},
+		ObjectStorage: ObjectStorageConfig{
+			Provider:  "cos",
+			Endpoint:  "https://minio:9000",
+			AccessKey: "ak",
+		},
+	}
+	assert.Equal(t, []string{
+		"objectStorage.enabled",
+		"objectStorage.provider",
+		"objectStorage.endpoint",
+		"objectStorage.bucket",
+		"objectStorage.secretKey",
+	}, issueFields(Validate(cfg)))
+
+	cfg.ObjectStorage = ObjectStorageConfig{Enabled: true, Bucket: "assets", AccessKey: "ak", SecretKey: "sk"}
+	assert.Empty(t, Validate(cfg))
+
+	cfg.ObjectStorage.Provider = ObjectStorageOSS
+	assert.Equal(t, []string{"objectStorage.endpoint"}, issueFields(Validate(cfg)))
+
+	cfg.System.UseObjectStorage = false
+	assert.Empty(t, Validate(cfg))
+}
+
+// TestValidate_CORSAndSession 测试 CORS 与会话配置校验
+//
+// 【功能点】验证 allowCredentials 与 "*" 来源同时配置、会话使用 Redis 存储但未开启 Redis 时被报告
+// 【测试流程】
+//  1. 显式配置 "*" 和未配置来源（默认 "*"）均报告问题
+//  2. 会话默认使用 Redis 存储，未开启 Redis 时报告问题；改为 memory 后不报告
+func TestValidate_CORSAndSession(t *testing.T) {
+	cfg := &BaseConfig{CORS: CORSConfig{Enabled: true, AllowCredentials: true, AllowOrigins: []string{"https://a.com", "*"}}}
+	assert.Equal(t, []string{"cors.allowOrigins"}, issueFields(Validate(cfg)))
+
+	cfg.CORS.AllowOrigins = nil
+	assert.Equal(t, []string{"cors.allowOrigins"}, issueFields(Validate(cfg)))
+
+	cfg = &BaseConfig{Session: SessionConfig{Enabled: true}}
+	assert.Equal(t, []string{"session.store"}, issueFields(Validate(cfg)))
+
+	cfg.Session.Store = "memory"
+	assert.Empty(t, Validate(cfg))
+}
+
+// TestValidate_LogOutputs 测试日志输出配置校验
+//
+// 【功能点】验证日志输出的类型、格式、级别以及异步写入的缓冲区已满处理方式无法识别时被报告，未配置时使用默认值不报告
+// 【测试流程】
+//  1. 配置一个全部使用默认值的输出和一个类型、格式、级别均非法的输出
+//  2. 断言只报告第二个输出的三个问题
+//  3. 配置无法识别的 dropPolicy，断言报告 log.async.dropPolicy
+func TestValidate_LogOutputs(t *testing.T) {
+	cfg := &BaseConfig{Log: LoggersConfig{Outputs: []LogOutput{
+		{},
+		{Type: "kafka", Format: "xml", Level: "verbose"},
+	}}}
+	assert.Equal(t, []string{"log.outputs[1].type", "log.outputs[1].format", "log.outputs[1].level"}, issueFields(Validate(cfg)))
+
+	cfg.Log.Outputs[1] = LogOutput{Type: LogOutputFile, Format: LogFormatJSON, Level: "WARN"}
+	assert.Empty(t, Validate(cfg))
+
+	cfg.Log.Async = AsyncLogConfig{Enabled: true, DropPolicy: "drop-all"}
+	assert.Equal(t, []string{"log.async.dropPolicy"}, issueFields(Validate(cfg)))
+	cfg.Log.Async.DropPolicy = LogDropOldest
+	assert.Empty(t, Validate(cfg))
+}
+
+// TestValidate_APIKey 测试 API Key 配置校验
+//
+// 【功能点】验证启用 API Key 认证时报告缺少 API Key、哈希非法、调用方名称为空或重复的问题
+// 【测试流程】
+//  1. 启用但未配置 API Key，断言报告 apiKey.keys
+//  2. 配置哈希非法、key 和 hashedKey 均为空、名称为空、名称重复的 API Key，断言对应的配置项路径
+//  3. 配置合法的明文和哈希 API Key，断言没有问题
+func TestValidate_APIKey(t *testing.T) {
+	cfg := &BaseConfig{APIKey: APIKeyConfig{Enabled: true}}
+	assert.Equal(t, []string{"apiKey.keys"}, issueFields(Validate(cfg)))
+
+	cfg.APIKey.Keys = []APIKeyEntry{
+		{HashedKey: "not-hex", Name: "a"},
+		{Name: "b"},
+		{Key: "secret"},
+		{Key: "secret-2", Name: "a"},
+	}
+	assert.Equal(t, []string{"apiKey.keys[0]", "apiKey.keys[1]", "apiKey.keys[2].name", "apiKey.keys[3].name"}, issueFields(Validate(cfg)))
+
+	cfg.APIKey.Keys = []APIKeyEntry{
+		{Key: "secret", Name: "a"},
+		{HashedKey: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", Name: "b"},
+	}
+	assert.Empty(t, Validate(cfg))
+}
+
+// TestValidate_Tenant 测试多租户配置校验
+//
+// 【功能点】验证启用多租户时报告未开启 MySQL 和映射到不存在的数据库别名的问题
+// 【测试流程】
+//  1. 未开启 MySQL，租户 b 映射到不存在的别名，断言报告 tenant.enabled 和 tenant.mapping.b
+//  2. 开启 MySQL 并修正映射，断言没有问题
+func TestValidate_Tenant(t *testing.T) {
+	cfg := &BaseConfig{
+		DbList: []DbInfo{{AliasName: "tenant_a", Host: "127.0.0.1"}},
+		Tenant: TenantConfig{Enabled: true, Mapping: map[string]string{"a": "tenant_a", "b": "tenant_b"}},
+	}
+	assert.Equal(t, []string{"tenant.enabled", "tenant.mapping.b"}, issueFields(Validate(cfg)))
+
+	cfg.System.UseMysql = true
+	cfg.Tenant.Mapping["b"] = "tenant_a"
+	assert.Empty(t, Validate(cfg))
+}
+
+// TestValidate_StartupRetry 测试启动重试策略校验
+//
+// 【功能点】验证启用启动重试时报告负数等待时间、小于 1 的倍数和无法识别的降级服务名称
+// 【测试流程】
+//  1. 构造非法的启动重试配置，断言问题列表
+//  2. 修正配置后断言没有问题；未启用时不检查
+func TestValidate_StartupRetry(t *testing.T) {
+	cfg := &BaseConfig{}
+	cfg.System.StartupRetry = StartupRetryConfig{
+		Enabled:  true,
+		Interval: -time.Second,
+		Backoff:  0.5,
+		Optional: []string{"redis", "es"},
+	}
+	assert.Equal(t, []string{
+		"system.startupRetry.maxWait",
+		"system.startupRetry.backoff",
+		"system.startupRetry.optional[1]",
+	}, issueFields(Validate(cfg)))
+
+	cfg.System.StartupRetry.Enabled = false
+	assert.Empty(t, Validate(cfg))
+
+	cfg.System.StartupRetry = StartupRetryConfig{Enabled: true, Backoff: 1, Optional: []string{"elasticsearch"}}
+	assert.Empty(t, Validate(cfg))
+}
+
+// TestValidate_FailedMessageStore 测试发送失败消息持久化方式校验
+//
+// 【功能点】验证持久化方式无法识别、使用 redis / db 但未开启 Redis / MySQL 时报告问题
+// 【测试流程】
+//  1. 默认实例使用 redis，多实例分别使用 db 和无法识别的 kafka，断言报告三个问题
+//  2. 开启 Redis 和 MySQL 并将 kafka 改为 none，断言没有问题
+func TestValidate_FailedMessageStore(t *testing.T) {
+	cfg := &BaseConfig{
+		System:   SystemInfo{UseRabbitMQ: true},
+		RabbitMQ: RabbitMQInfo{Host: "127.0.0.1", FailedMessageStore: FailedMessageStoreRedis},
+		RabbitMQList: RabbitMqListInfo{
+			{AliasName: "a", Host: "127.0.0.1", FailedMessageStore: FailedMessageStoreDB},
+			{AliasName: "b", Host: "127.0.0.1", FailedMessageStore: "kafka"},
+		},
+	}
+	assert.Equal(t, []string{
+		"rabbitMQ.failedMessageStore",
+		"rabbitMQList[0].failedMessageStore",
+		"rabbitMQList[1].failedMessageStore",
+	}, issueFields(Validate(cfg)))
+
+	cfg.System.UseRedis = true
+	cfg.System.UseMysql = true
+	cfg.Redis = &RedisInfo{Addr: "127.0.0.1:6379"}
+	cfg.Db = &DbInfo{Host: "127.0.0.1"}
+	cfg.RabbitMQList[1].FailedMessageStore = FailedMessageStoreNone
+	assert.Empty(t, Validate(cfg))
+}
+
+// TestValidate_PublishRateLimit 测试发布限流配置校验
+//
+// 【功能点】验证启用发布限流时速率不大于 0、突发容量为负数时报告问题，未启用时不校验
+// 【测试流程】
+//  1. 默认实例启用限流但未配置速率，多实例的突发容量为负数，断言报告两个问题
+//  2. 修正配置后断言没有问题，关闭限流后非法取值也不报告问题
+func TestValidate_PublishRateLimit(t *testing.T) {
+	cfg := &BaseConfig{
+		System:   SystemInfo{UseRabbitMQ: true},
+		RabbitMQ: RabbitMQInfo{Host: "127.0.0.1", PublishRateLimit: PublishRateLimitConfig{Enabled: true}},
+		RabbitMQList: RabbitMqListInfo{
+			{AliasName: "a", Host: "127.0.0.1", PublishRateLimit: PublishRateLimitConfig{Enabled: true, MessagesPerSecond: 100, Burst: -1}},
+		},
+	}
+	assert.Equal(t, []string{
+		"rabbitMQ.publishRateLimit.messagesPerSecond",
+		"rabbitMQList[0].publishRateLimit.burst",
+	}, issueFields(Validate(cfg)))
+
+	cfg.RabbitMQ.PublishRateLimit.MessagesPerSecond = 500
+	cfg.RabbitMQList[0].PublishRateLimit.Burst = 0
+	assert.Empty(t, Validate(cfg))
+
+	cfg.RabbitMQList[0].PublishRateLimit = PublishRateLimitConfig{MessagesPerSecond: -1, Burst: -1}
+	assert.Empty(t, Validate(cfg))
+}
+
+// TestValidationIssue_String 测试问题格式化
+//
+// 【功能点】验证 String() 输出 "配置项: 问题描述"
+// 【测试流程】格式化一个问题并断言输出
+func TestValidationIssue_String(t *testing.T) {
+	issue := ValidationIssue{Field: "redis.addr", Message: "未配置 Redis 地址"}
+	assert.Equal(t, "redis.addr: 未配置 Redis 地址", issue.String())
+}
@@ -0,0 +1,31 @@
+// Package entity 提供数据实体的基础结构定义
+// 本文件定义了审计日志实体，对应审计日志中间件写入的 audit_logs 表
+package entity
+
+import "time"
+
+// AuditLog 审计日志
+// 记录一次请求的请求体、响应体及上下文信息，请求体和响应体已按配置脱敏和截断
+type AuditLog struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	TraceID    string    `gorm:"size:64;index;column:trace_id;comment:追踪ID" json:"traceId"`
+	UserID     string    `gorm:"size:64;index;column:user_id;comment:用户ID" json:"userId"`
+	Method     string    `gorm:"size:16;not null;column:method;comment:请求方法" json:"method"`
+	Path       string    `gorm:"size:512;not null;column:path;comment:请求路径" json:"path"`
+	Query      string    `gorm:"size:2048;column:query;comment:查询参数" json:"query"`
+	ClientIP   string    `gorm:"size:64;column:client_ip;comment:客户端IP" json:"clientIp"`
+	Status     int       `gorm:"not null;column:status;comment:HTTP状态码" json:"status"`
+	LatencyMs  int64     `gorm:"not null;column:latency_ms;comment:耗时（毫秒）" json:"latencyMs"`
+	ReqType    string    `gorm:"size:128;column:req_type;comment:请求体类型" json:"reqType"`
+	ReqSize    int64     `gorm:"not null;column:req_size;comment:请求体字节数" json:"reqSize"`
+	ReqBody    string    `gorm:"type:text;column:req_body;comment:请求体" json:"reqBody"`
+	RespType   string    `gorm:"size:128;column:resp_type;comment:响应体类型" json:"respType"`
+	RespSize   int64     `gorm:"not null;column:resp_size;comment:响应体字节数" json:"respSize"`
+	RespBody   string    `gorm:"type:text;column:resp_body;comment:响应体" json:"respBody"`
+	CreateTime time.Time `gorm:"not null;index;column:create_time;comment:创建时间" json:"createTime"`
+}
+
+// TableName 指定 GORM 表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
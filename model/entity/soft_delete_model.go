@@ -0,0 +1,19 @@
+// Package entity 提供数据实体的基础结构定义
+// 本文件定义了基于 gorm.DeletedAt 的软删除基础模型
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SoftDeleteModel 基于 gorm.DeletedAt 的软删除基础模型
+// 嵌入该结构体的实体在 Delete 时只设置 deleted_at，查询时自动过滤已删除的数据；
+// 已删除数据的恢复及"删除后重新创建"见 softdelete 包
+type SoftDeleteModel struct {
+	ID        uint64         `gorm:"primaryKey;autoIncrement;column:id" json:"id"`             // 主键ID
+	CreatedAt time.Time      `gorm:"not null;column:created_at;comment:创建时间" json:"createdAt"` // 创建时间，由 GORM 在创建时自动填充
+	UpdatedAt time.Time      `gorm:"not null;column:updated_at;comment:更新时间" json:"updatedAt"` // 更新时间，由 GORM 在创建和更新时自动填充
+	DeletedAt gorm.DeletedAt `gorm:"index;column:deleted_at;comment:删除时间" json:"deletedAt"`    // 删除时间，为 NULL 表示未删除
+}
@@ -2,43 +2,60 @@
 // 本文件定义了统一的响应码常量和响应消息，用于标准化API响应格式
 package response
 
-// responseCode 响应码结构体
-// 该结构体定义了响应码和对应的消息文本，用于统一管理API响应状态
-type responseCode struct {
-	code int    // 响应状态码，用于标识请求处理结果
-	msg  string // 响应消息文本，用于描述响应状态
+import "net/http"
+
+// ResponseCode 响应码结构体
+// 该结构体定义了响应码、对应的消息文本和 HTTP 状态码，用于统一管理API响应状态
+type ResponseCode struct {
+	code       int    // 响应状态码，用于标识请求处理结果
+	msg        string // 响应消息文本，用于描述响应状态
+	httpStatus int    // 对应的 HTTP 状态码，仅在开启 service.useHTTPStatus 时生效
 }
 
 // 预定义的响应码常量，按照功能模块和错误类型进行分类
 var (
 	// 特殊响应码
-	ResponseNull = responseCode{code: -1} // 空回复，该回复不写入到responseBody中，一般用于文件下载等特殊场景
+	ResponseNull = ResponseCode{code: -1} // 空回复，该回复不写入到responseBody中，一般用于文件下载等特殊场景
 
 	// 成功响应码
-	ResponseSuccess = responseCode{code: 20000, msg: "操作成功"} // 标准成功响应
+	ResponseSuccess = ResponseCode{code: 20000, msg: "操作成功", httpStatus: http.StatusOK} // 标准成功响应
 
 	// 认证相关响应码（41xxx系列）
-	ResponseLoginNotLogin  = responseCode{code: 41000, msg: "未登录"}   // 用户未登录状态
-	ResponseLoginButUnAuth = responseCode{code: 41001, msg: "未认证"}   // 未通过双因子认证
-	ResponseLoginInvalid   = responseCode{code: 41002, msg: "登录失效"}  // 登录会话已过期
-	ResponseAuthFailed     = responseCode{code: 41010, msg: "无权限访问"} // 权限不足，拒绝访问
+	ResponseLoginNotLogin  = ResponseCode{code: 41000, msg: "未登录", httpStatus: http.StatusUnauthorized}    // 用户未登录状态
+	ResponseLoginButUnAuth = ResponseCode{code: 41001, msg: "未认证", httpStatus: http.StatusUnauthorized}    // 未通过双因子认证
+	ResponseLoginInvalid   = ResponseCode{code: 41002, msg: "登录失效", httpStatus: http.StatusUnauthorized}   // 登录会话已过期
+	ResponseUnauthorized   = ResponseCode{code: 41003, msg: "认证失败", httpStatus: http.StatusUnauthorized}   // API Key 等凭证缺失、无效或已过期
+	ResponseAuthFailed     = ResponseCode{code: 41010, msg: "无权限访问", httpStatus: http.StatusForbidden}     // 权限不足，拒绝访问
+	ResponseTenantMissing  = ResponseCode{code: 41020, msg: "缺少租户标识", httpStatus: http.StatusBadRequest}   // 请求未携带租户 ID 且未配置默认租户
+	ResponseTenantUnknown  = ResponseCode{code: 41021, msg: "租户不存在", httpStatus: http.StatusForbidden}     // 租户 ID 无法映射到数据库
+	ResponseOriginDenied   = ResponseCode{code: 41030, msg: "跨域请求来源不允许", httpStatus: http.StatusForbidden} // 开启 CORS 时预检请求的来源不在 allowOrigins 中
+	ResponseCSRFInvalid    = ResponseCode{code: 41031, msg: "CSRF 校验失败", httpStatus: http.StatusForbidden} // 写请求缺少 CSRF 令牌、令牌不匹配或来源不受信任
 
 	// 业务逻辑响应码（50xxx系列）
-	ResponseFail           = responseCode{code: 50000, msg: "操作失败"}    // 通用操作失败
-	ResponseParamInvalid   = responseCode{code: 53001, msg: "参数校验不通过"} // 请求参数验证失败
-	ResponseParamTypeError = responseCode{code: 50002, msg: "参数类型错误"}  // 请求参数类型不匹配
+	ResponseFail             = ResponseCode{code: 50000, msg: "操作失败", httpStatus: http.StatusInternalServerError}       // 通用操作失败
+	ResponseParamInvalid     = ResponseCode{code: 53001, msg: "参数校验不通过", httpStatus: http.StatusBadRequest}             // 请求参数验证失败
+	ResponseParamTypeError   = ResponseCode{code: 50002, msg: "参数类型错误", httpStatus: http.StatusBadRequest}              // 请求参数类型不匹配
+	ResponseNotFound         = ResponseCode{code: 50404, msg: "请求的资源不存在", httpStatus: http.StatusNotFound}              // 路由不存在
+	ResponseMethodNotAllowed = ResponseCode{code: 50405, msg: "请求方法不允许", httpStatus: http.StatusMethodNotAllowed}       // 路由存在但不支持该请求方法
+	ResponseTimeout          = ResponseCode{code: 50408, msg: "请求超时", httpStatus: http.StatusRequestTimeout}            // 处理时间超过 service.apiTimeout
+	ResponseRequestInFlight  = ResponseCode{code: 50409, msg: "请求正在处理中，请勿重复提交", httpStatus: http.StatusConflict}        // 相同幂等键的请求仍在处理中
+	ResponseConflict         = ResponseCode{code: 51409, msg: "数据已被修改，请刷新后重试", httpStatus: http.StatusConflict}         // 乐观锁版本号不一致，数据已被其他请求修改
+	ResponsePayloadTooLarge  = ResponseCode{code: 50413, msg: "请求体过大", httpStatus: http.StatusRequestEntityTooLarge}    // 请求体（解压后）超过大小限制
+	ResponseServiceBusy      = ResponseCode{code: 50503, msg: "服务繁忙，请稍后再试", httpStatus: http.StatusServiceUnavailable}  // 并发请求数超过限制且排队已满或等待超时
+	ResponseMaintenance      = ResponseCode{code: 51503, msg: "系统维护中，请稍后再试", httpStatus: http.StatusServiceUnavailable} // 维护模式开启期间的请求
 
 	// 系统异常响应码（90xxx系列）
-	ResponseExceptionCommon  = responseCode{code: 90000, msg: "服务端异常"}     // 通用服务端异常
-	ResponseExceptionRpc     = responseCode{code: 90001, msg: "调用rpc服务异常"} // RPC服务调用异常
-	ResponseExceptionUnknown = responseCode{code: 90002, msg: "未知异常"}      // 未分类的系统异常
+	ResponseExceptionCommon  = ResponseCode{code: 90000, msg: "服务端异常", httpStatus: http.StatusInternalServerError} // 通用服务端异常
+	ResponseExceptionRpc     = ResponseCode{code: 90001, msg: "调用rpc服务异常", httpStatus: http.StatusBadGateway}      // RPC服务调用异常
+	ResponseExceptionUnknown = ResponseCode{code: 90002, msg: "未知异常", httpStatus: http.StatusInternalServerError}  // 未分类的系统异常
+	ResponseEnvelopeInvalid  = ResponseCode{code: 90003, msg: "响应格式不符合统一响应结构", httpStatus: http.StatusBadGateway}  // 开启 service.envelope.strictMode 时处理函数的响应不符合统一响应结构
 )
 
 // GetCode 获取响应状态码
 // 该方法返回响应码结构体中的状态码值
 // 返回：
 //   - int: 响应状态码
-func (r *responseCode) GetCode() int {
+func (r ResponseCode) GetCode() int {
 	return r.code
 }
 
@@ -46,6 +63,13 @@ func (r *responseCode) GetCode() int {
 // 该方法返回响应码结构体中的消息文本
 // 返回：
 //   - string: 响应消息文本
-func (r *responseCode) GetMsg() string {
+func (r ResponseCode) GetMsg() string {
 	return r.msg
 }
+
+// GetHTTPStatus 获取响应码对应的 HTTP 状态码
+// 返回：
+//   - int: HTTP 状态码
+func (r ResponseCode) GetHTTPStatus() int {
+	return r.httpStatus
+}
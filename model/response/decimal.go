@@ -0,0 +1,226 @@
+// Package response 提供HTTP响应数据的数据结构定义
+// 本文件实现了定点小数类型 Decimal，用于金额等不能有浮点误差的字段
+package response
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal 定点小数，值为 unscaled × 10^(-scale)
+// 序列化为 JSON 字符串（如 "12.50"）以避免前端按浮点数解析丢失精度，反序列化时同时接受字符串和数字；
+// 实现了 driver.Valuer 和 sql.Scanner，可直接作为 GORM 模型字段扫描 DECIMAL 列，建表时需通过标签指定精度：
+//
+//	type Order struct {
+//	    ID     uint
+//	    Amount response.Decimal `gorm:"type:decimal(20,2)"`
+//	}
+//
+// 零值表示 0；保留解析时的小数位数，"12.50" 序列化后仍为 "12.50"
+type Decimal struct {
+	unscaled *big.Int // 去掉小数点后的整数，nil 表示 0
+	scale    int32    // 小数位数，不小于 0
+}
+
+// NewDecimal 由整数和小数位数创建 Decimal，如 NewDecimal(1250, 2) 表示 12.50
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{unscaled: new(big.Int).Mul(big.NewInt(unscaled), pow10(-scale))}
+	}
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+// ParseDecimal 解析十进制字符串，支持正负号和小数点，不支持科学计数法
+// 参数：
+//   - s: 十进制字符串，如 "-12.50"
+//
+// 返回：
+//   - Decimal: 解析结果，保留小数位数
+//   - error: 格式错误
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	digits := strings.TrimLeft(s, "+-")
+	if len(s)-len(digits) > 1 {
+		return Decimal{}, fmt.Errorf("无效的小数: %q", s)
+	}
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	if intPart == "" && fracPart == "" {
+		return Decimal{}, fmt.Errorf("无效的小数: %q", s)
+	}
+	for _, c := range intPart + fracPart {
+		if c < '0' || c > '9' {
+			return Decimal{}, fmt.Errorf("无效的小数: %q", s)
+		}
+	}
+	unscaled, _ := new(big.Int).SetString(intPart+fracPart, 10)
+	if strings.HasPrefix(s, "-") {
+		unscaled.Neg(unscaled)
+	}
+	return Decimal{unscaled: unscaled, scale: int32(len(fracPart))}, nil
+}
+
+// MustParseDecimal 解析十进制字符串，格式错误时 panic，用于常量
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// pow10 返回 10^n
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// value 返回 unscaled，零值返回 0
+func (d Decimal) value() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// rescale 返回小数位数扩大到 scale 后的 unscaled，scale 不小于 d.scale
+func (d Decimal) rescale(scale int32) *big.Int {
+	return new(big.Int).Mul(d.value(), pow10(scale-d.scale))
+}
+
+// Add 返回 d + other，小数位数取两者中较大的
+func (d Decimal) Add(other Decimal) Decimal {
+	scale := max(d.scale, other.scale)
+	return Decimal{unscaled: new(big.Int).Add(d.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+// Sub 返回 d - other，小数位数取两者中较大的
+func (d Decimal) Sub(other Decimal) Decimal {
+	scale := max(d.scale, other.scale)
+	return Decimal{unscaled: new(big.Int).Sub(d.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+// Mul 返回 d × other，小数位数为两者之和，可通过 Round 保留指定位数
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.value(), other.value()), scale: d.scale + other.scale}
+}
+
+// Round 四舍五入（远离零）保留 places 位小数，位数不足时补 0
+func (d Decimal) Round(places int32) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if places >= d.scale {
+		return Decimal{unscaled: d.rescale(places), scale: places}
+	}
+	divisor := pow10(d.scale - places)
+	quotient, remainder := new(big.Int).QuoRem(d.value(), divisor, new(big.Int))
+	// |remainder| × 2 >= divisor 时进位
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(divisor) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(d.value().Sign())))
+	}
+	return Decimal{unscaled: quotient, scale: places}
+}
+
+// Cmp 比较大小，d < other 返回 -1，相等返回 0，d > other 返回 1；"1.0" 与 "1.00" 相等
+func (d Decimal) Cmp(other Decimal) int {
+	scale := max(d.scale, other.scale)
+	return d.rescale(scale).Cmp(other.rescale(scale))
+}
+
+// Sign 返回符号：负数 -1，零 0，正数 1
+func (d Decimal) Sign() int {
+	return d.value().Sign()
+}
+
+// IsZero 是否为 0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Scale 返回小数位数
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// String 返回十进制字符串，保留小数位数，如 "12.50"、"-0.05"
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.value()).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		point := len(digits) - int(d.scale)
+		digits = digits[:point] + "." + digits[point:]
+	}
+	if d.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Float64 转换为浮点数，可能丢失精度，仅用于展示或统计
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// MarshalJSON 序列化为 JSON 字符串
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON 解析 JSON 字符串或数字，null 解析为 0
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*d = Decimal{}
+		return nil
+	}
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value 实现 driver.Valuer 接口，以字符串写入，避免经过浮点数
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan 实现 sql.Scanner 接口，支持 DECIMAL 列返回的字符串以及整数、浮点数，NULL 扫描为 0
+func (d *Decimal) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*d = Decimal{}
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		*d = NewDecimal(v, 0)
+		return nil
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Errorf("无法将 %T 扫描为 Decimal", src)
+	}
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,141 @@
+// Package response 定点小数测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 Decimal 的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 解析与格式化，保留小数位数，非法输入返回错误
+// 2. 加、减、乘、四舍五入和比较没有浮点误差
+// 3. 序列化为 JSON 字符串，反序列化同时接受字符串、数字和 null
+// 4. 扫描数据库驱动返回的字符串、整数、浮点数和 NULL
+//
+// 运行测试：go test -v ./model/response/... -run Decimal
+// ==================================================
+package response
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDecimal_ParseAndString 测试解析与格式化
+//
+// 【功能点】验证解析后格式化保留原有的小数位数，非法输入返回错误
+// 【测试流程】
+//  1. 解析正负数、纯小数、带前导零和正号的输入，断言格式化结果
+//  2. 断言 NewDecimal 和零值的格式化结果
+//  3. 解析空字符串、多个小数点、科学计数法、多个符号，断言返回错误
+func TestDecimal_ParseAndString(t *testing.T) {
+	for input, expected := range map[string]string{
+		"12.50":  "12.50",
+		"-0.05":  "-0.05",
+		".5":     "0.5",
+		"+007":   "7",
+		"-0.00":  "0.00",
+		"100.":   "100",
+		" 3.14 ": "3.14",
+	} {
+		d, err := ParseDecimal(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, d.String(), input)
+	}
+	assert.Equal(t, "12.50", NewDecimal(1250, 2).String())
+	assert.Equal(t, "1200", NewDecimal(12, -2).String())
+	assert.Equal(t, "0", Decimal{}.String())
+
+	for _, input := range []string{"", "-", ".", "1.2.3", "1e5", "--1", "12a", "0x10"} {
+		_, err := ParseDecimal(input)
+		assert.Error(t, err, input)
+	}
+}
+
+// TestDecimal_Arithmetic 测试运算
+//
+// 【功能点】验证加减乘、四舍五入和比较的结果精确，不受浮点误差影响
+// 【测试流程】
+//  1. 断言 0.1 + 0.2 = 0.3，1.00 - 0.01 = 0.99，19.99 × 3 = 59.97
+//  2. 断言 Round 对正负数远离零进位、位数不足时补 0
+//  3. 断言 Cmp 忽略小数位数差异，Sign、IsZero 正确
+func TestDecimal_Arithmetic(t *testing.T) {
+	assert.Equal(t, "0.3", MustParseDecimal("0.1").Add(MustParseDecimal("0.2")).String())
+	assert.Equal(t, "0.99", MustParseDecimal("1.00").Sub(MustParseDecimal("0.01")).String())
+	assert.Equal(t, "59.97", MustParseDecimal("19.99").Mul(NewDecimal(3, 0)).String())
+	assert.Equal(t, "-1.5", Decimal{}.Sub(MustParseDecimal("1.5")).String())
+
+	assert.Equal(t, "1.13", MustParseDecimal("1.125").Round(2).String())
+	assert.Equal(t, "-1.13", MustParseDecimal("-1.125").Round(2).String())
+	assert.Equal(t, "1.12", MustParseDecimal("1.1249").Round(2).String())
+	assert.Equal(t, "2.500", MustParseDecimal("2.5").Round(3).String())
+	assert.Equal(t, "3", MustParseDecimal("2.5").Round(0).String())
+
+	assert.Zero(t, MustParseDecimal("1.0").Cmp(MustParseDecimal("1.00")))
+	assert.Equal(t, -1, MustParseDecimal("-2").Cmp(MustParseDecimal("1.5")))
+	assert.Equal(t, 1, MustParseDecimal("0.01").Cmp(Decimal{}))
+	assert.Equal(t, -1, MustParseDecimal("-0.01").Sign())
+	assert.True(t, MustParseDecimal("0.00").IsZero())
+	assert.True(t, Decimal{}.IsZero())
+}
+
+// TestDecimal_JSON 测试 JSON 序列化
+//
+// 【功能点】验证序列化为字符串，反序列化同时接受字符串和数字，null 解析为 0，非法值返回错误
+// 【测试流程】
+//  1. 序列化包含 Decimal 字段的结构体，断言金额为字符串
+//  2. 反序列化字符串、数字和 null，断言结果
+//  3. 反序列化非法字符串和布尔值，断言返回错误
+func TestDecimal_JSON(t *testing.T) {
+	data, err := json.Marshal(map[string]Decimal{"amount": MustParseDecimal("12.50"), "zero": {}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"12.50","zero":"0"}`, string(data))
+
+	for input, expected := range map[string]string{
+		`"12.50"`:              "12.50",
+		`12.5`:                 "12.5",
+		`-3`:                   "-3",
+		`null`:                 "0",
+		`"99999999999999.99"`:  "99999999999999.99",
+		`123456789012345.6789`: "123456789012345.6789",
+	} {
+		var d Decimal
+		require.NoError(t, json.Unmarshal([]byte(input), &d), input)
+		assert.Equal(t, expected, d.String(), input)
+	}
+
+	var d Decimal
+	assert.Error(t, json.Unmarshal([]byte(`"abc"`), &d))
+	assert.Error(t, json.Unmarshal([]byte(`true`), &d))
+}
+
+// TestDecimal_Scan 测试数据库扫描
+//
+// 【功能点】验证扫描 DECIMAL 列返回的 []byte、字符串以及整数、浮点数，NULL 扫描为 0，Value 以字符串写入
+// 【测试流程】
+//  1. 扫描各类型的值，断言结果
+//  2. 扫描不支持的类型，断言返回错误
+//  3. 断言 Value 返回字符串
+func TestDecimal_Scan(t *testing.T) {
+	for _, tc := range []struct {
+		src      any
+		expected string
+	}{
+		{[]byte("1234.50"), "1234.50"},
+		{"-0.01", "-0.01"},
+		{int64(42), "42"},
+		{float64(12.5), "12.5"},
+		{nil, "0"},
+	} {
+		d := MustParseDecimal("7")
+		require.NoError(t, d.Scan(tc.src), tc.src)
+		assert.Equal(t, tc.expected, d.String(), tc.src)
+	}
+
+	var d Decimal
+	assert.Error(t, d.Scan(true))
+
+	value, err := MustParseDecimal("12.50").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "12.50", value)
+}
@@ -0,0 +1,110 @@
+// Package response 提供HTTP响应数据的数据结构定义
+// 本文件实现了错误响应的统一输出，异常处理、限流、认证、跨域、超时等中间件的错误响应均通过 WriteError 输出
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorOptions 错误响应的选项
+type errorOptions struct {
+	msg     string // 响应消息或消息 ID，为空时使用响应码的注册消息
+	data    any    // 响应数据，默认为空对象
+	status  int    // 开启 service.useHTTPStatus 时输出的 HTTP 状态码，为 0 时使用响应码注册的状态码
+	hasData bool   // 是否设置了响应数据
+}
+
+// ErrorOption 错误响应的选项
+type ErrorOption func(*errorOptions)
+
+// WithMessage 设置响应消息，为空时使用响应码的注册消息
+// 消息通过 Localize 按请求的语言区域解析，可以是消息 ID（如 "exception.unknown"）
+func WithMessage(msg string) ErrorOption {
+	return func(o *errorOptions) {
+		o.msg = msg
+	}
+}
+
+// WithData 设置响应数据（如参数校验的错误详情），默认为空对象
+func WithData(data any) ErrorOption {
+	return func(o *errorOptions) {
+		o.data = data
+		o.hasData = true
+	}
+}
+
+// WithStatus 设置开启 service.useHTTPStatus 时输出的 HTTP 状态码，覆盖响应码注册的状态码
+// 用于响应码可由配置指定、但 HTTP 状态码语义固定的场景（如限流的 responseCode 配置，HTTP 状态码始终为 429）；
+// 未开启 service.useHTTPStatus 时仍输出 200
+func WithStatus(status int) ErrorOption {
+	return func(o *errorOptions) {
+		o.status = status
+	}
+}
+
+// NewError 按统一的 HTTP 状态码规则生成错误响应
+// HTTP 状态码：未开启 service.useHTTPStatus 时为 200；开启时为 WithStatus 设置的状态码，
+// 未设置时为响应码注册的状态码（未注册的响应码见 Of 的兜底规则）。响应体始终为统一响应结构
+// 用于需要自行写出响应的场景（如超时中间件），一般使用 WriteError
+// 参数：
+//   - c: Gin上下文，用于解析响应消息的语言区域，可以为 nil
+//   - rc: 响应码，如 ResponseParamInvalid、Of(code)
+//   - opts: 选项，如 WithMessage、WithData、WithStatus
+//
+// 返回：
+//   - int: HTTP 状态码
+//   - Response: 响应体，消息已按请求的语言区域解析
+func NewError(c *gin.Context, rc ResponseCode, opts ...ErrorOption) (int, Response) {
+	var options errorOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.msg == "" {
+		options.msg = rc.msg
+	}
+	if !options.hasData {
+		options.data = map[string]any{}
+	}
+
+	status := http.StatusOK
+	if UseHTTPStatus() {
+		status = options.status
+		if status == 0 {
+			status = rc.httpStatus
+		}
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+	}
+	return status, Response{
+		Code: rc.code,
+		Data: options.data,
+		Msg:  Localize(c, rc.code, options.msg),
+	}
+}
+
+// WriteError 输出错误响应并中止后续的中间件和处理函数
+// 框架中间件（异常处理、限流、认证、跨域、超时等）的错误响应均通过该函数输出，
+// HTTP 状态码规则见 NewError：未开启 service.useHTTPStatus 时始终为 200，开启时按响应码输出，响应体格式一致
+// 参数：
+//   - c: Gin上下文
+//   - rc: 响应码，如 ResponseUnauthorized、Of(code)
+//   - opts: 选项，如 WithMessage、WithData、WithStatus
+//
+// 使用示例：
+//
+//	func AuthHandler() gin.HandlerFunc {
+//	  return func(c *gin.Context) {
+//	    if c.GetHeader("Authorization") == "" {
+//	      response.WriteError(c, response.ResponseLoginNotLogin)
+//	      return
+//	    }
+//	    c.Next()
+//	  }
+//	}
+func WriteError(c *gin.Context, rc ResponseCode, opts ...ErrorOption) {
+	status, body := NewError(c, rc, opts...)
+	c.AbortWithStatusJSON(status, body)
+}
@@ -0,0 +1,109 @@
+// Package response 错误响应测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 NewError、WriteError 的单元测试，不需要外部依赖。
+// 各中间件的错误响应见 middleware 包的 TestErrorStatusPolicy。
+//
+// 测试覆盖内容：
+// 1. HTTP 状态码规则 - 开关关闭时始终为 200，开启时为 WithStatus 或响应码注册的状态码
+// 2. 选项 - WithMessage、WithData 覆盖响应码的消息和默认的空对象
+// 3. WriteError - 写出响应并中止后续处理
+//
+// 运行测试：go test -v ./model/response/... -run Error
+// ==================================================
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewError_StatusPolicy 测试错误响应的 HTTP 状态码规则
+//
+// 【功能点】验证开关关闭时 HTTP 状态码始终为 200，开启时 WithStatus 优先于响应码注册的状态码，响应体不受开关影响
+// 【测试流程】
+//  1. 开关关闭时对 ResponseParamInvalid、ResponseUnauthorized 和带 WithStatus(429) 的自定义响应码生成错误响应，断言均为 200
+//  2. 开关开启时断言分别为 400、401、429，未注册的响应码为 500
+//  3. 断言两次生成的响应体相同
+func TestNewError_StatusPolicy(t *testing.T) {
+	tests := []struct {
+		rc   ResponseCode
+		opts []ErrorOption
+		want int
+	}{
+		{ResponseParamInvalid, nil, http.StatusBadRequest},
+		{ResponseUnauthorized, nil, http.StatusUnauthorized},
+		{Of(60429), []ErrorOption{WithStatus(http.StatusTooManyRequests)}, http.StatusTooManyRequests},
+		{Of(60500), nil, http.StatusInternalServerError},
+	}
+
+	withHTTPStatus(t, false)
+	bodies := make([]Response, len(tests))
+	for i, tt := range tests {
+		status, body := NewError(nil, tt.rc, tt.opts...)
+		assert.Equal(t, http.StatusOK, status, "响应码 %d", tt.rc.GetCode())
+		bodies[i] = body
+	}
+
+	SetUseHTTPStatus(true)
+	for i, tt := range tests {
+		status, body := NewError(nil, tt.rc, tt.opts...)
+		assert.Equal(t, tt.want, status, "响应码 %d", tt.rc.GetCode())
+		assert.Equal(t, bodies[i], body, "响应码 %d", tt.rc.GetCode())
+	}
+}
+
+// TestNewError_Options 测试错误响应的选项
+//
+// 【功能点】验证默认使用响应码的注册消息和空对象，WithMessage、WithData 覆盖消息和数据
+// 【测试流程】
+//  1. 不传选项生成 ResponseParamInvalid 错误响应，断言 code、msg 为注册值，data 为空对象
+//  2. 传入 WithMessage、WithData，断言 msg、data 为传入值
+//  3. WithMessage 传入空字符串时仍使用注册消息
+func TestNewError_Options(t *testing.T) {
+	_, body := NewError(nil, ResponseParamInvalid)
+	assert.Equal(t, ResponseParamInvalid.GetCode(), body.Code)
+	assert.Equal(t, ResponseParamInvalid.GetMsg(), body.Msg)
+	assert.Equal(t, map[string]any{}, body.Data)
+
+	data := map[string]string{"name": "不能为空"}
+	_, body = NewError(nil, ResponseParamInvalid, WithMessage("name 不能为空"), WithData(data))
+	assert.Equal(t, "name 不能为空", body.Msg)
+	assert.Equal(t, data, body.Data)
+
+	_, body = NewError(nil, ResponseParamInvalid, WithMessage(""))
+	assert.Equal(t, ResponseParamInvalid.GetMsg(), body.Msg)
+}
+
+// TestWriteError 测试写出错误响应
+//
+// 【功能点】验证 WriteError 写出统一响应结构并中止后续处理，开关开启时输出响应码注册的状态码
+// 【测试流程】
+//  1. 开关开启，中间件调用 WriteError(ResponseAuthFailed)，其后注册处理函数
+//  2. 断言 HTTP 403，处理函数未执行，响应体包含 code、msg 和空对象 data
+func TestWriteError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	withHTTPStatus(t, true)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { WriteError(c, ResponseAuthFailed) })
+	handled := false
+	r.GET("/", func(c *gin.Context) { handled = true })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.False(t, handled)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, map[string]any{
+		"code": float64(ResponseAuthFailed.GetCode()),
+		"msg":  ResponseAuthFailed.GetMsg(),
+		"data": map[string]any{},
+	}, body)
+}
@@ -0,0 +1,40 @@
+// Package response 提供HTTP响应数据的数据结构定义
+// 本文件实现了响应消息的国际化，按请求的语言区域从 i18n 消息目录中解析响应消息
+package response
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/i18n"
+)
+
+// Localize 按请求的语言区域解析响应消息
+// 解析规则：
+//  1. msg 为空或等于响应码的注册消息时，以响应码（如 "20000"）为键查找
+//  2. 否则以 msg 为消息 ID（如 "exception.unknown"）查找
+//  3. 均未找到时返回原始 msg
+//
+// 查找时依次使用当前语言区域和默认语言区域的消息目录，语言区域由 I18nHandler 中间件协商
+// 参数：
+//   - c: Gin上下文，可以为 nil（使用默认语言区域）
+//   - code: 响应码
+//   - msg: 响应消息或消息 ID
+//
+// 返回：
+//   - string: 解析后的消息
+func Localize(c *gin.Context, code int, msg string) string {
+	locale := i18n.Locale(c)
+	if msg == "" || msg == Of(code).msg {
+		if localized, ok := i18n.Lookup(locale, strconv.Itoa(code)); ok {
+			return localized
+		}
+	}
+	if msg == "" {
+		return msg
+	}
+	if localized, ok := i18n.Lookup(locale, msg); ok {
+		return localized
+	}
+	return msg
+}
@@ -0,0 +1,149 @@
+// Package response 提供HTTP响应数据的数据结构定义
+// 本文件实现了按配置格式序列化的时间类型 LocalTime，可直接用于 GORM 模型
+package response
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// jsonTimeFormat LocalTime 的 JSON 时间格式和时区
+type jsonTimeFormat struct {
+	layout   string
+	location *time.Location
+}
+
+// jsonTime 当前的 JSON 时间格式，框架启动时根据 service.jsonTime 配置设置
+var jsonTime atomic.Pointer[jsonTimeFormat]
+
+func init() {
+	jsonTime.Store(&jsonTimeFormat{layout: time.RFC3339, location: time.Local})
+}
+
+// SetJSONTime 设置 LocalTime 的 JSON 时间格式和时区
+// 框架启动时根据 service.jsonTime 配置设置，一般无需手动调用
+// 参数：
+//   - layout: Go 时间格式，为空时使用 time.RFC3339
+//   - location: 时区，为 nil 时使用 time.Local
+func SetJSONTime(layout string, location *time.Location) {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	if location == nil {
+		location = time.Local
+	}
+	jsonTime.Store(&jsonTimeFormat{layout: layout, location: location})
+}
+
+// LocalTime 按 service.jsonTime 配置序列化的时间
+// 序列化为 JSON 时转换到配置的时区并按配置的格式输出，零值输出 null；
+// 反序列化时依次尝试配置的格式（按配置的时区解析）和 RFC3339，空字符串和 null 解析为零值。
+// 实现了 driver.Valuer 和 sql.Scanner，可直接作为 GORM 模型字段，数据库中按 datetime 存储：
+//
+//	type Order struct {
+//	    ID        uint
+//	    PaidAt    response.LocalTime
+//	    CreatedAt response.LocalTime `gorm:"autoCreateTime"`
+//	}
+type LocalTime struct {
+	time.Time
+}
+
+// NewLocalTime 由 time.Time 创建 LocalTime
+func NewLocalTime(t time.Time) LocalTime {
+	return LocalTime{Time: t}
+}
+
+// MarshalJSON 按配置的格式和时区序列化，零值输出 null
+func (t LocalTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	format := jsonTime.Load()
+	return json.Marshal(t.In(format.location).Format(format.layout))
+}
+
+// UnmarshalJSON 依次按配置的格式和 RFC3339 解析，null 和空字符串解析为零值
+func (t *LocalTime) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		t.Time = time.Time{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("时间必须是字符串: %w", err)
+	}
+	if s == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	format := jsonTime.Load()
+	if parsed, err := time.ParseInLocation(format.layout, s, format.location); err == nil {
+		t.Time = parsed
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return fmt.Errorf("时间 %q 既不符合格式 %q 也不符合 RFC3339", s, format.layout)
+	}
+	t.Time = parsed
+	return nil
+}
+
+// Value 实现 driver.Valuer 接口，零值写入 NULL
+func (t LocalTime) Value() (driver.Value, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return t.Time, nil
+}
+
+// Scan 实现 sql.Scanner 接口，支持 time.Time 和数据库驱动返回的时间字符串，NULL 扫描为零值
+func (t *LocalTime) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		t.Time = time.Time{}
+		return nil
+	case time.Time:
+		t.Time = v
+		return nil
+	case []byte:
+		return t.scanString(string(v))
+	case string:
+		return t.scanString(v)
+	default:
+		return fmt.Errorf("无法将 %T 扫描为 LocalTime", src)
+	}
+}
+
+// dbTimeLayouts 数据库驱动返回字符串时可能使用的时间格式
+var dbTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02",
+}
+
+// scanString 解析数据库驱动返回的时间字符串，不含时区的时间按 UTC 解析
+func (t *LocalTime) scanString(s string) error {
+	for _, layout := range dbTimeLayouts {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("无法解析时间: %q", s)
+}
+
+// GormDataType 实现 schema.GormDataTypeInterface 接口，按时间类型建表
+func (LocalTime) GormDataType() string {
+	return string(schema.Time)
+}
@@ -0,0 +1,155 @@
+// Package response 时间类型测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 LocalTime 的单元测试，数据库读写使用内存 SQLite，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 不同时区之间的 JSON 序列化、反序列化往返，零值与 null
+// 2. 反序列化同时接受配置的格式和 RFC3339
+// 3. 修改 JSON 时间配置后，使用 LocalTime 的结构体输出随之改变
+// 4. 作为 GORM 模型字段写入和读取 SQLite
+//
+// 运行测试：go test -v ./model/response/... -run LocalTime
+// ==================================================
+package response
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+var (
+	// shanghai 东八区
+	shanghai = time.FixedZone("CST", 8*3600)
+	// newYork 西五区
+	newYork = time.FixedZone("EST", -5*3600)
+)
+
+// setJSONTime 设置 JSON 时间格式，测试结束后恢复
+func setJSONTime(t *testing.T, layout string, location *time.Location) {
+	original := *jsonTime.Load()
+	SetJSONTime(layout, location)
+	t.Cleanup(func() { SetJSONTime(original.layout, original.location) })
+}
+
+// order 使用 LocalTime 和 Decimal 的示例模型
+type order struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Amount    Decimal   `gorm:"type:decimal(20,2)" json:"amount"`
+	PaidAt    LocalTime `json:"paidAt"`
+	DeletedAt LocalTime `json:"deletedAt"`
+}
+
+// TestLocalTime_JSONRoundTrip 测试不同时区之间的 JSON 往返
+//
+// 【功能点】验证序列化时转换到配置的时区并按配置的格式输出，反序列化后表示同一时刻，零值输出 null
+// 【测试流程】
+//  1. 配置格式 "2006-01-02 15:04:05"、东八区，序列化一个纽约时间，断言输出东八区时间
+//  2. 反序列化输出，断言与原时间表示同一时刻
+//  3. 配置西五区后重复，断言输出纽约时间且往返后时刻不变
+//  4. 断言零值序列化为 null，null 和空字符串反序列化为零值
+func TestLocalTime_JSONRoundTrip(t *testing.T) {
+	original := NewLocalTime(time.Date(2024, 1, 15, 20, 30, 0, 0, newYork))
+
+	for _, tc := range []struct {
+		location *time.Location
+		expected string
+	}{
+		{shanghai, `"2024-01-16 09:30:00"`},
+		{newYork, `"2024-01-15 20:30:00"`},
+	} {
+		setJSONTime(t, "2006-01-02 15:04:05", tc.location)
+		data, err := json.Marshal(original)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, string(data))
+
+		var decoded LocalTime
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.True(t, original.Equal(decoded.Time), "%s != %s", original, decoded)
+	}
+
+	data, err := json.Marshal(LocalTime{})
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(data))
+	for _, input := range []string{`null`, `""`} {
+		decoded := NewLocalTime(time.Now())
+		require.NoError(t, json.Unmarshal([]byte(input), &decoded))
+		assert.True(t, decoded.IsZero(), input)
+	}
+}
+
+// TestLocalTime_UnmarshalRFC3339 测试兼容 RFC3339
+//
+// 【功能点】验证配置了自定义格式时，仍可解析前端按 RFC3339 提交的时间，无法解析时返回错误
+// 【测试流程】
+//  1. 配置格式 "2006/01/02 15:04"、东八区
+//  2. 分别反序列化自定义格式和带时区偏移的 RFC3339 时间，断言表示同一时刻
+//  3. 反序列化无法识别的时间和数字，断言返回错误
+func TestLocalTime_UnmarshalRFC3339(t *testing.T) {
+	setJSONTime(t, "2006/01/02 15:04", shanghai)
+	expected := time.Date(2024, 1, 16, 9, 30, 0, 0, shanghai)
+
+	for _, input := range []string{`"2024/01/16 09:30"`, `"2024-01-15T20:30:00-05:00"`, `"2024-01-16T01:30:00Z"`} {
+		var decoded LocalTime
+		require.NoError(t, json.Unmarshal([]byte(input), &decoded), input)
+		assert.True(t, expected.Equal(decoded.Time), input)
+	}
+
+	var decoded LocalTime
+	assert.Error(t, json.Unmarshal([]byte(`"16.01.2024"`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`1705368600`), &decoded))
+}
+
+// TestLocalTime_LayoutChange 测试修改配置后输出改变
+//
+// 【功能点】验证修改 JSON 时间配置后，同一个使用 LocalTime 的结构体无需修改代码即按新格式输出
+// 【测试流程】
+//  1. 默认配置（RFC3339）下序列化示例模型，断言 paidAt 为 RFC3339 格式、未设置的 deletedAt 为 null
+//  2. 修改为 "2006年01月02日 15:04" 和东八区后再次序列化，断言 paidAt 按新格式输出
+func TestLocalTime_LayoutChange(t *testing.T) {
+	setJSONTime(t, "", time.UTC)
+	o := order{ID: 1, Amount: MustParseDecimal("12.50"), PaidAt: NewLocalTime(time.Date(2024, 1, 16, 1, 30, 0, 0, time.UTC))}
+
+	data, err := json.Marshal(o)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"amount":"12.50","paidAt":"2024-01-16T01:30:00Z","deletedAt":null}`, string(data))
+
+	SetJSONTime("2006年01月02日 15:04", shanghai)
+	data, err = json.Marshal(o)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"amount":"12.50","paidAt":"2024年01月16日 09:30","deletedAt":null}`, string(data))
+}
+
+// TestLocalTime_DBRoundTrip 测试数据库读写
+//
+// 【功能点】验证 LocalTime 和 Decimal 作为 GORM 模型字段可以自动建表、写入和读取，零值 LocalTime 写入 NULL
+// 【测试流程】
+//  1. 在内存 SQLite 中迁移示例模型，写入纽约时间和金额 "1234567890123.45"
+//  2. 读取后断言时间表示同一时刻，金额与写入值相等
+//  3. 断言未设置的 deletedAt 在数据库中为 NULL，读取为零值
+func TestLocalTime_DBRoundTrip(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&order{}))
+
+	paidAt := time.Date(2024, 1, 15, 20, 30, 15, 123000000, newYork)
+	created := order{Amount: MustParseDecimal("1234567890123.45"), PaidAt: NewLocalTime(paidAt)}
+	require.NoError(t, db.Create(&created).Error)
+
+	var loaded order
+	require.NoError(t, db.First(&loaded, created.ID).Error)
+	assert.True(t, paidAt.Equal(loaded.PaidAt.Time), "%s != %s", paidAt, loaded.PaidAt)
+	assert.Zero(t, created.Amount.Cmp(loaded.Amount), "%s != %s", created.Amount, loaded.Amount)
+	assert.True(t, loaded.DeletedAt.IsZero())
+
+	var nullCount int64
+	require.NoError(t, db.Model(&order{}).Where("deleted_at IS NULL").Count(&nullCount).Error)
+	assert.Equal(t, int64(1), nullCount)
+}
@@ -0,0 +1,136 @@
+// Package response 提供HTTP响应数据的数据结构定义
+// 本文件实现了响应码注册表，支持应用注册自定义业务响应码及其对应的 HTTP 状态码
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// registryMu 保护 registry 的并发访问
+	registryMu sync.RWMutex
+	// registry 已注册的响应码，key: 响应码
+	registry = map[int]ResponseCode{}
+	// useHTTPStatus 是否按响应码输出对应的 HTTP 状态码，关闭时始终输出 200
+	useHTTPStatus atomic.Bool
+)
+
+// init 注册框架内置的响应码
+func init() {
+	for _, rc := range []ResponseCode{
+		ResponseSuccess,
+		ResponseLoginNotLogin,
+		ResponseLoginButUnAuth,
+		ResponseLoginInvalid,
+		ResponseUnauthorized,
+		ResponseAuthFailed,
+		ResponseTenantMissing,
+		ResponseTenantUnknown,
+		ResponseOriginDenied,
+		ResponseCSRFInvalid,
+		ResponseFail,
+		ResponseParamInvalid,
+		ResponseParamTypeError,
+		ResponseNotFound,
+		ResponseMethodNotAllowed,
+		ResponseTimeout,
+		ResponseRequestInFlight,
+		ResponseConflict,
+		ResponsePayloadTooLarge,
+		ResponseServiceBusy,
+		ResponseMaintenance,
+		ResponseExceptionCommon,
+		ResponseExceptionRpc,
+		ResponseExceptionUnknown,
+		ResponseEnvelopeInvalid,
+	} {
+		registry[rc.code] = rc
+	}
+}
+
+// Register 注册业务响应码
+// 注册后可通过 Of 查询，FailWithCode 和异常处理中间件会使用注册的消息和 HTTP 状态码。
+// 建议在应用启动时（如 init 函数或 AppBeforeInit 钩子中）注册。
+//
+// 使用示例：
+//
+//	if err := response.Register(60001, "订单不存在", http.StatusNotFound); err != nil {
+//	    panic(err)
+//	}
+//
+// 参数：
+//   - code: 响应码，不能与已注册的响应码（含框架内置响应码）重复
+//   - msg: 默认响应消息
+//   - httpStatus: 开启 service.useHTTPStatus 时输出的 HTTP 状态码，取值范围 100-599
+//
+// 返回：
+//   - error: 响应码已注册或 HTTP 状态码非法时返回错误
+func Register(code int, msg string, httpStatus int) error {
+	if code == ResponseNull.code {
+		return fmt.Errorf("响应码 %d 为框架保留的空回复响应码", code)
+	}
+	if httpStatus < 100 || httpStatus > 599 {
+		return fmt.Errorf("响应码 %d 的 HTTP 状态码非法: %d", code, httpStatus)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if existing, ok := registry[code]; ok {
+		return fmt.Errorf("响应码 %d 已注册: %s", code, existing.msg)
+	}
+	registry[code] = ResponseCode{code: code, msg: msg, httpStatus: httpStatus}
+	return nil
+}
+
+// Of 查询响应码
+// 未注册的响应码返回兜底结果：消息为"操作失败"；响应码本身在 100-599 之间时
+// （部分中间件直接使用 HTTP 状态码作为响应码，如超时、限流）HTTP 状态码与响应码相同，否则为 500
+// 参数：
+//   - code: 响应码
+//
+// 返回：
+//   - ResponseCode: 响应码信息
+func Of(code int) ResponseCode {
+	registryMu.RLock()
+	rc, ok := registry[code]
+	registryMu.RUnlock()
+	if ok {
+		return rc
+	}
+
+	httpStatus := http.StatusInternalServerError
+	if code >= 100 && code <= 599 {
+		httpStatus = code
+	}
+	return ResponseCode{code: code, msg: ResponseFail.msg, httpStatus: httpStatus}
+}
+
+// SetUseHTTPStatus 设置是否按响应码输出对应的 HTTP 状态码
+// 框架启动时根据 service.useHTTPStatus 配置设置，一般无需手动调用
+// 参数：
+//   - enabled: true 时输出映射的 HTTP 状态码，false 时始终输出 200
+func SetUseHTTPStatus(enabled bool) {
+	useHTTPStatus.Store(enabled)
+}
+
+// UseHTTPStatus 是否按响应码输出对应的 HTTP 状态码
+func UseHTTPStatus() bool {
+	return useHTTPStatus.Load()
+}
+
+// HTTPStatus 获取响应码应输出的 HTTP 状态码
+// 未开启 service.useHTTPStatus 时始终返回 200，响应体格式不受影响
+// 参数：
+//   - code: 响应码
+//
+// 返回：
+//   - int: HTTP 状态码
+func HTTPStatus(code int) int {
+	if !UseHTTPStatus() {
+		return http.StatusOK
+	}
+	return Of(code).httpStatus
+}
@@ -0,0 +1,157 @@
+// Package response 响应码注册表测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含响应码注册、查询以及 HTTP 状态码映射的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. Register - 注册自定义响应码，重复注册（含内置响应码）、非法 HTTP 状态码、保留响应码返回错误
+// 2. Of - 查询已注册响应码，未注册响应码的兜底结果
+// 3. HTTPStatus - 开关关闭时始终返回 200，开启时返回映射的状态码
+// 4. FailWithCode / Result - 开关开启和关闭时的 HTTP 状态码与响应体
+//
+// 运行测试：go test -v ./model/response/...
+// ==================================================
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerForTest 注册测试用响应码，测试结束后移除
+func registerForTest(t *testing.T, code int, msg string, httpStatus int) {
+	require.NoError(t, Register(code, msg, httpStatus))
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, code)
+		registryMu.Unlock()
+	})
+}
+
+// withHTTPStatus 临时设置 HTTP 状态码映射开关，测试结束后恢复
+func withHTTPStatus(t *testing.T, enabled bool) {
+	original := UseHTTPStatus()
+	SetUseHTTPStatus(enabled)
+	t.Cleanup(func() { SetUseHTTPStatus(original) })
+}
+
+// TestRegister_Conflicts 测试重复注册与非法参数
+//
+// 【功能点】验证重复注册、与内置响应码冲突、HTTP 状态码非法、使用保留响应码时返回错误
+// 【测试流程】
+//  1. 注册自定义响应码成功，再次注册同一响应码返回错误且原注册不变
+//  2. 注册与内置响应码相同的响应码返回错误
+//  3. HTTP 状态码超出 100-599、使用空回复响应码 -1 时返回错误
+func TestRegister_Conflicts(t *testing.T) {
+	registerForTest(t, 60001, "订单不存在", http.StatusNotFound)
+
+	err := Register(60001, "订单已取消", http.StatusConflict)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "订单不存在")
+	assert.Equal(t, "订单不存在", Of(60001).GetMsg())
+
+	assert.Error(t, Register(ResponseParamInvalid.GetCode(), "参数错误", http.StatusBadRequest))
+	assert.Error(t, Register(60002, "状态码非法", 0))
+	assert.Error(t, Register(60003, "状态码非法", 600))
+	assert.Error(t, Register(ResponseNull.GetCode(), "空回复", http.StatusOK))
+}
+
+// TestOf 测试响应码查询
+//
+// 【功能点】验证已注册响应码返回注册信息，未注册响应码返回兜底结果
+// 【测试流程】
+//  1. 查询内置和自定义响应码，断言消息和 HTTP 状态码
+//  2. 查询未注册响应码，断言消息为"操作失败"，HTTP 状态码为 500
+//  3. 查询未注册但在 HTTP 状态码范围内的响应码（如 408），断言 HTTP 状态码与响应码相同
+func TestOf(t *testing.T) {
+	registerForTest(t, 60010, "库存不足", http.StatusConflict)
+
+	rc := Of(60010)
+	assert.Equal(t, 60010, rc.GetCode())
+	assert.Equal(t, "库存不足", rc.GetMsg())
+	assert.Equal(t, http.StatusConflict, rc.GetHTTPStatus())
+
+	assert.Equal(t, http.StatusBadRequest, Of(ResponseParamInvalid.GetCode()).GetHTTPStatus())
+	assert.Equal(t, http.StatusForbidden, Of(ResponseAuthFailed.GetCode()).GetHTTPStatus())
+
+	unknown := Of(77777)
+	assert.Equal(t, 77777, unknown.GetCode())
+	assert.Equal(t, ResponseFail.GetMsg(), unknown.GetMsg())
+	assert.Equal(t, http.StatusInternalServerError, unknown.GetHTTPStatus())
+
+	assert.Equal(t, http.StatusRequestTimeout, Of(http.StatusRequestTimeout).GetHTTPStatus())
+}
+
+// TestHTTPStatus 测试 HTTP 状态码映射开关
+//
+// 【功能点】验证开关关闭时所有响应码均返回 200，开启时返回映射的状态码
+// 【测试流程】分别在开关关闭和开启时查询成功、参数校验失败、未知异常、限流响应码的 HTTP 状态码
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code int
+		want int
+	}{
+		{ResponseSuccess.GetCode(), http.StatusOK},
+		{ResponseParamInvalid.GetCode(), http.StatusBadRequest},
+		{ResponseLoginNotLogin.GetCode(), http.StatusUnauthorized},
+		{ResponseExceptionUnknown.GetCode(), http.StatusInternalServerError},
+		{http.StatusTooManyRequests, http.StatusTooManyRequests},
+	}
+
+	withHTTPStatus(t, false)
+	for _, tt := range tests {
+		assert.Equal(t, http.StatusOK, HTTPStatus(tt.code), "响应码 %d", tt.code)
+	}
+
+	SetUseHTTPStatus(true)
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, HTTPStatus(tt.code), "响应码 %d", tt.code)
+	}
+}
+
+// TestFailWithCode 测试指定响应码的失败响应
+//
+// 【功能点】验证 FailWithCode 使用注册的消息，开关开启时输出映射的 HTTP 状态码，响应体格式不变
+// 【测试流程】
+//  1. 注册响应码 60020 -> 404
+//  2. 开关关闭时调用 FailWithCode，断言 HTTP 200，响应体 code/msg 为注册值
+//  3. 开关开启时调用 FailWithCode 和 Fail，断言 HTTP 状态码分别为 404 和 500，响应体不变
+func TestFailWithCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registerForTest(t, 60020, "用户不存在", http.StatusNotFound)
+
+	call := func(handler gin.HandlerFunc) (int, Response) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		handler(c)
+		var resp Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return w.Code, resp
+	}
+
+	withHTTPStatus(t, false)
+	status, resp := call(func(c *gin.Context) { FailWithCode(c, 60020) })
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 60020, resp.Code)
+	assert.Equal(t, "用户不存在", resp.Msg)
+
+	SetUseHTTPStatus(true)
+	status, resp = call(func(c *gin.Context) { FailWithCode(c, 60020) })
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, 60020, resp.Code)
+	assert.Equal(t, "用户不存在", resp.Msg)
+
+	status, resp = call(Fail)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, ResponseFail.GetCode(), resp.Code)
+
+	status, _ = call(Ok)
+	assert.Equal(t, http.StatusOK, status)
+}
@@ -18,16 +18,18 @@ type Response struct {
 
 // Result 通用响应方法
 // 该方法用于构建和返回标准的HTTP响应，支持自定义状态码、数据和消息
+// 开启 service.useHTTPStatus 时按响应码输出映射的 HTTP 状态码（见 Register），否则始终为 200
+// 响应消息通过 Localize 按请求的语言区域解析，msg 可以是消息 ID（如 "exception.unknown"）
 // 参数：
 //   - c: Gin上下文，用于HTTP响应
 //   - code: 响应状态码
 //   - data: 响应数据
-//   - msg: 响应消息
+//   - msg: 响应消息或消息 ID
 func Result(c *gin.Context, code int, data any, msg string) {
-	c.JSON(http.StatusOK, Response{
+	c.JSON(HTTPStatus(code), Response{
 		code,
 		data,
-		msg,
+		Localize(c, code, msg),
 	})
 }
 
@@ -94,15 +96,21 @@ func FailWithDetail(c *gin.Context, message string, data any) {
 	Result(c, ResponseFail.code, data, message)
 }
 
-// NoAuth 返回未授权响应
-// 该方法返回HTTP 401状态码的未授权响应，用于认证失败场景
+// FailWithCode 返回指定响应码的失败响应
+// 该方法通过 Of 查询响应码，使用注册的消息；开启 service.useHTTPStatus 时输出注册的 HTTP 状态码
+// 参数：
+//   - c: Gin上下文，用于HTTP响应
+//   - code: 响应码，建议先通过 Register 注册
+func FailWithCode(c *gin.Context, code int) {
+	rc := Of(code)
+	Result(c, rc.code, map[string]any{}, rc.msg)
+}
+
+// NoAuth 返回未授权响应并中止后续处理，用于认证失败场景
+// 响应码为 7，开启 service.useHTTPStatus 时 HTTP 状态码为 401，否则为 200（见 WriteError）
 // 参数：
 //   - c: Gin上下文，用于HTTP响应
 //   - message: 未授权原因说明
 func NoAuth(c *gin.Context, message string) {
-	c.JSON(http.StatusUnauthorized, Response{
-		7,       // 使用特殊的未授权状态码
-		nil,     // 无数据
-		message, // 未授权原因
-	})
+	WriteError(c, Of(7), WithMessage(message), WithStatus(http.StatusUnauthorized))
 }
@@ -0,0 +1,273 @@
+// Package response 提供HTTP响应数据的数据结构定义
+// 本文件定义了大数据量导出的流式响应方法，逐行写出 CSV / JSON，内存占用与数据总量无关
+package response
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/utils/jsoncodec"
+	"gorm.io/gorm"
+)
+
+// ErrClientGone 客户端已断开连接
+// 流式响应的 yield 在客户端断开（请求 context 结束）或写出失败时返回该错误（可用 errors.Is 判断），
+// 数据源收到后应停止读取（如关闭数据库游标）并原样返回
+var ErrClientGone = errors.New("客户端已断开连接")
+
+// utf8BOM UTF-8 字节顺序标记，Excel 依赖它识别 UTF-8 编码的 CSV
+const utf8BOM = "\xEF\xBB\xBF"
+
+// defaultStreamFlushEvery 流式响应默认每写出多少行刷新一次
+const defaultStreamFlushEvery = 100
+
+// streamOptions 流式响应选项
+type streamOptions struct {
+	bom        bool // 是否在 CSV 开头写出 UTF-8 BOM
+	flushEvery int  // 每写出多少行刷新一次
+	ndjson     bool // 是否按 NDJSON 格式输出
+}
+
+// StreamOption 流式响应选项函数
+type StreamOption func(*streamOptions)
+
+// WithBOM 设置是否在 CSV 开头写出 UTF-8 BOM，默认写出（Excel 打开中文不乱码）
+// 参数：
+//   - enabled: 是否写出 BOM
+func WithBOM(enabled bool) StreamOption {
+	return func(o *streamOptions) {
+		o.bom = enabled
+	}
+}
+
+// WithFlushEvery 设置每写出多少行刷新一次响应，默认 100 行
+// 参数：
+//   - n: 行数，小于等于 0 时使用默认值
+func WithFlushEvery(n int) StreamOption {
+	return func(o *streamOptions) {
+		if n > 0 {
+			o.flushEvery = n
+		}
+	}
+}
+
+// WithNDJSON 设置 StreamJSONArray 按 NDJSON（每行一个 JSON 对象）输出，默认输出 JSON 数组
+func WithNDJSON() StreamOption {
+	return func(o *streamOptions) {
+		o.ndjson = true
+	}
+}
+
+// newStreamOptions 合并流式响应选项
+func newStreamOptions(opts []StreamOption) streamOptions {
+	o := streamOptions{bom: true, flushEvery: defaultStreamFlushEvery}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// streamWriter 带缓冲的流式响应写出器，负责刷新和客户端断开检测
+type streamWriter struct {
+	c          *gin.Context
+	buf        *bufio.Writer
+	flushEvery int
+	rows       int
+}
+
+// newStreamWriter 设置响应头并创建流式响应写出器
+// filename 不为空时设置 Content-Disposition 为附件下载，非 ASCII 文件名按 RFC 2231 编码
+func newStreamWriter(c *gin.Context, contentType, filename string, flushEvery int) *streamWriter {
+	header := c.Writer.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Cache-Control", "no-cache")
+	if filename != "" {
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+	c.Status(http.StatusOK)
+	return &streamWriter{c: c, buf: bufio.NewWriter(c.Writer), flushEvery: flushEvery}
+}
+
+// alive 检查客户端是否仍然连接
+func (w *streamWriter) alive() error {
+	if w.c.Request.Context().Err() != nil {
+		return ErrClientGone
+	}
+	return nil
+}
+
+// rowDone 记录写出一行，达到刷新间隔时刷新响应
+func (w *streamWriter) rowDone() error {
+	w.rows++
+	if w.rows%w.flushEvery == 0 {
+		return w.flush()
+	}
+	return nil
+}
+
+// flush 将缓冲的内容写给客户端，写出失败时返回 ErrClientGone
+func (w *streamWriter) flush() error {
+	if err := w.buf.Flush(); err != nil {
+		return fmt.Errorf("%w: %w", ErrClientGone, err)
+	}
+	w.c.Writer.Flush()
+	return nil
+}
+
+// finish 结束流式响应
+// 成功时刷新剩余内容；客户端断开时中止后续处理函数；生产者出错且尚未向客户端写出任何内容时，
+// 丢弃已缓冲的数据并撤销下载响应头，调用方仍可返回失败响应
+func (w *streamWriter) finish(err error) error {
+	if err == nil {
+		err = w.flush()
+	}
+	switch {
+	case errors.Is(err, ErrClientGone):
+		w.c.Abort()
+	case err != nil && !w.c.Writer.Written():
+		w.buf.Reset(w.c.Writer)
+		header := w.c.Writer.Header()
+		header.Del("Content-Type")
+		header.Del("Content-Disposition")
+		header.Del("Cache-Control")
+	}
+	return err
+}
+
+// StreamCSV 以 CSV 附件的形式流式输出数据
+// 依次写出 UTF-8 BOM（可通过 WithBOM(false) 关闭）、表头和 rows 产生的每一行，每 WithFlushEvery 行刷新一次；
+// 字段中的逗号、引号和换行按 RFC 4180 转义。
+// rows 出错时，若尚未刷新过（不足 WithFlushEvery 行），调用方仍可返回失败响应；否则响应被截断，由调用方记录返回的错误。
+//
+// 客户端断开后 yield 返回 ErrClientGone，rows 应停止读取数据源并返回该错误，StreamCSV 中止后续处理函数并原样返回。
+//
+// 参数：
+//   - c: Gin上下文，用于HTTP响应
+//   - filename: 下载文件名，如 "订单.csv"，为空时不设置 Content-Disposition
+//   - header: 表头，为空时不写出表头
+//   - rows: 数据生产函数，通过 yield 逐行写出
+//   - opts: 流式响应选项
+//
+// 返回：
+//   - error: rows 返回的错误，或客户端断开时的 ErrClientGone
+//
+// 使用示例：
+//
+//	err := response.StreamCSV(c, "users.csv", []string{"ID", "姓名"}, func(yield func([]string) error) error {
+//		return response.ScanRows(app.DB.Model(&User{}), func(u User) error {
+//			return yield([]string{strconv.Itoa(u.ID), u.Name})
+//		})
+//	})
+func StreamCSV(c *gin.Context, filename string, header []string, rows func(yield func([]string) error) error, opts ...StreamOption) error {
+	o := newStreamOptions(opts)
+	w := newStreamWriter(c, "text/csv; charset=utf-8", filename, o.flushEvery)
+	if o.bom {
+		_, _ = w.buf.WriteString(utf8BOM)
+	}
+	// w.buf 已是 bufio.Writer，csv.Writer 直接复用它，由 streamWriter 统一刷新
+	cw := csv.NewWriter(w.buf)
+	if len(header) > 0 {
+		_ = cw.Write(header)
+	}
+
+	err := rows(func(record []string) error {
+		if err := w.alive(); err != nil {
+			return err
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("%w: %w", ErrClientGone, err)
+		}
+		return w.rowDone()
+	})
+	return w.finish(err)
+}
+
+// StreamJSONArray 流式输出 JSON 数组或 NDJSON
+// 默认输出 JSON 数组（Content-Type: application/json），WithNDJSON 时每行输出一个 JSON 对象（Content-Type: application/x-ndjson）；
+// 每个元素使用 jsoncodec.Marshal 序列化，每 WithFlushEvery 个元素刷新一次。
+// items 出错时与 StreamCSV 相同：尚未刷新过时调用方仍可返回失败响应，否则 JSON 数组不会闭合，客户端解析失败即可感知导出中断。
+//
+// 客户端断开后 yield 返回 ErrClientGone，items 应停止读取数据源并返回该错误。
+//
+// 参数：
+//   - c: Gin上下文，用于HTTP响应
+//   - filename: 下载文件名，为空时不设置 Content-Disposition
+//   - items: 数据生产函数，通过 yield 逐个写出元素
+//   - opts: 流式响应选项
+//
+// 返回：
+//   - error: items 返回的错误、元素序列化失败的错误，或客户端断开时的 ErrClientGone
+func StreamJSONArray(c *gin.Context, filename string, items func(yield func(any) error) error, opts ...StreamOption) error {
+	o := newStreamOptions(opts)
+	contentType := "application/json; charset=utf-8"
+	if o.ndjson {
+		contentType = "application/x-ndjson; charset=utf-8"
+	}
+	w := newStreamWriter(c, contentType, filename, o.flushEvery)
+	if !o.ndjson {
+		_ = w.buf.WriteByte('[')
+	}
+
+	err := items(func(item any) error {
+		if err := w.alive(); err != nil {
+			return err
+		}
+		b, err := jsoncodec.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if err := writeJSONItem(w.buf, b, w.rows, o.ndjson); err != nil {
+			return fmt.Errorf("%w: %w", ErrClientGone, err)
+		}
+		return w.rowDone()
+	})
+	if err == nil && !o.ndjson {
+		_ = w.buf.WriteByte(']')
+	}
+	return w.finish(err)
+}
+
+// writeJSONItem 写出一个 JSON 元素：数组模式下第 2 个元素起前置逗号，NDJSON 模式下后置换行
+func writeJSONItem(buf *bufio.Writer, b []byte, index int, ndjson bool) error {
+	if !ndjson && index > 0 {
+		_ = buf.WriteByte(',')
+	}
+	_, err := buf.Write(b)
+	if ndjson && err == nil {
+		err = buf.WriteByte('\n')
+	}
+	return err
+}
+
+// ScanRows 逐行扫描 GORM 查询结果，配合 StreamCSV / StreamJSONArray 导出大表
+// 通过 db.Rows() 使用数据库游标逐行读取，不会一次性加载全部结果；fn 返回错误（如 ErrClientGone）时立即停止并关闭游标
+//
+// 参数：
+//   - db: 已设置好 Model / Where / Order 等条件的查询
+//   - fn: 每行的处理函数
+//
+// 返回：
+//   - error: 查询、扫描失败的错误或 fn 返回的错误
+func ScanRows[T any](db *gorm.DB, fn func(T) error) error {
+	rows, err := db.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item T
+		if err := db.ScanRows(rows, &item); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,308 @@
+// Package response 流式响应测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 StreamCSV、StreamJSONArray 和 ScanRows 的单元测试，ScanRows 使用内存 SQLite，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. StreamCSV - 响应头、BOM 开关、字段中逗号/引号/换行的转义
+// 2. 客户端断开 - 生产者收到 ErrClientGone 后停止，写出失败同样返回 ErrClientGone
+// 3. 生产者出错 - 未刷新时撤销下载响应头，调用方可返回失败响应
+// 4. 内存占用 - 10 万行数据流式输出时堆内存不随行数增长
+// 5. StreamJSONArray - JSON 数组与 NDJSON 两种格式
+// 6. ScanRows - 逐行扫描 GORM 查询结果，处理函数出错时停止
+//
+// 运行测试：go test -v ./model/response/... -run Stream
+// ==================================================
+package response
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// newStreamContext 创建用于流式响应测试的 Gin 上下文
+func newStreamContext(w http.ResponseWriter, ctx context.Context) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/export", nil).WithContext(ctx)
+	return c
+}
+
+// discardWriter 丢弃写入内容的 ResponseWriter，记录写入字节数，可在写入指定字节数后返回错误
+type discardWriter struct {
+	header  http.Header
+	written int
+	failAt  int // 大于 0 时，累计写入超过该字节数后返回错误
+}
+
+func (w *discardWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = http.Header{}
+	}
+	return w.header
+}
+
+func (w *discardWriter) Write(b []byte) (int, error) {
+	if w.failAt > 0 && w.written+len(b) > w.failAt {
+		return 0, errors.New("broken pipe")
+	}
+	w.written += len(b)
+	return len(b), nil
+}
+
+func (w *discardWriter) WriteHeader(int) {}
+
+func (w *discardWriter) Flush() {}
+
+// TestStreamCSV 测试 CSV 流式输出的格式
+//
+// 【功能点】验证响应头、默认写出 BOM、WithBOM(false) 关闭 BOM，以及特殊字符的转义
+// 【测试流程】
+//  1. 输出包含逗号、引号、换行和中文的行，断言 Content-Type、Content-Disposition 和 BOM
+//  2. 使用 csv.Reader 解析响应体，断言与原始数据一致，并断言引号被转义为两个引号
+//  3. WithBOM(false) 时断言响应体以表头开头
+func TestStreamCSV(t *testing.T) {
+	header := []string{"ID", "备注"}
+	records := [][]string{
+		{"1", "a,b"},
+		{"2", `他说"你好"`},
+		{"3", "第一行\n第二行"},
+		{"4", "plain"},
+	}
+	produce := func(yield func([]string) error) error {
+		for _, r := range records {
+			if err := yield(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	require.NoError(t, StreamCSV(newStreamContext(w, context.Background()), "订单.csv", header, produce, WithFlushEvery(2)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename*=utf-8''%E8%AE%A2%E5%8D%95.csv", w.Header().Get("Content-Disposition"))
+
+	body := w.Body.String()
+	require.True(t, strings.HasPrefix(body, utf8BOM))
+	assert.Contains(t, body, `"他说""你好"""`)
+	got, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(body, utf8BOM))).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, append([][]string{header}, records...), got)
+
+	w = httptest.NewRecorder()
+	require.NoError(t, StreamCSV(newStreamContext(w, context.Background()), "orders.csv", header, produce, WithBOM(false)))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "ID,备注\n"))
+	assert.Equal(t, `attachment; filename=orders.csv`, w.Header().Get("Content-Disposition"))
+}
+
+// TestStreamCSV_ClientGone 测试客户端断开时停止生产数据
+//
+// 【功能点】验证客户端断开后 yield 返回 ErrClientGone，生产者随即停止，后续处理函数被中止
+// 【测试流程】
+//  1. 生产者每产生一行计数一次，产生第 10 行后取消请求 context
+//  2. 断言 StreamCSV 返回 ErrClientGone，计数为 11（第 11 行的 yield 返回错误），上下文已中止
+//  3. ResponseWriter 在写入 1KB 后返回错误，断言同样返回 ErrClientGone 且生产者停止
+func TestStreamCSV_ClientGone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := newStreamContext(&discardWriter{}, ctx)
+
+	produced := 0
+	err := StreamCSV(c, "", nil, func(yield func([]string) error) error {
+		for i := 0; i < 1_000_000; i++ {
+			produced++
+			if err := yield([]string{strconv.Itoa(i)}); err != nil {
+				return err
+			}
+			if produced == 10 {
+				cancel()
+			}
+		}
+		return nil
+	}, WithFlushEvery(1))
+	assert.ErrorIs(t, err, ErrClientGone)
+	assert.Equal(t, 11, produced)
+	assert.True(t, c.IsAborted())
+
+	produced = 0
+	c = newStreamContext(&discardWriter{failAt: 1024}, context.Background())
+	err = StreamCSV(c, "", nil, func(yield func([]string) error) error {
+		for i := 0; i < 1_000_000; i++ {
+			produced++
+			if err := yield([]string{strings.Repeat("x", 100)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, WithFlushEvery(1))
+	assert.ErrorIs(t, err, ErrClientGone)
+	assert.Less(t, produced, 20)
+}
+
+// TestStreamCSV_ProducerError 测试生产者出错时的响应
+//
+// 【功能点】验证尚未刷新时生产者出错，下载响应头被撤销，调用方可以返回失败响应
+// 【测试流程】
+//  1. 生产者写出 1 行后返回错误，断言 StreamCSV 原样返回该错误
+//  2. 调用方返回失败响应，断言响应为 JSON 且不包含 Content-Disposition 和 CSV 内容
+func TestStreamCSV_ProducerError(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newStreamContext(w, context.Background())
+	queryErr := errors.New("查询超时")
+
+	err := StreamCSV(c, "orders.csv", []string{"ID"}, func(yield func([]string) error) error {
+		_ = yield([]string{"1"})
+		return queryErr
+	})
+	require.ErrorIs(t, err, queryErr)
+	FailWithMessage(c, err.Error())
+
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	var body Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), w.Body.String())
+	assert.Equal(t, "查询超时", body.Msg)
+}
+
+// TestStreamCSV_BoundedMemory 测试大数据量流式输出的内存占用
+//
+// 【功能点】验证 10 万行数据流式输出时，堆内存不随已输出的行数增长
+// 【测试流程】
+//  1. 生产 10 万行数据，分别在第 1 万行和第 10 万行时 GC 并记录堆内存
+//  2. 断言两次堆内存的差值小于 1MB（10 万行数据总量约 4MB），且全部数据已写出
+func TestStreamCSV_BoundedMemory(t *testing.T) {
+	const total = 100_000
+	w := &discardWriter{}
+	c := newStreamContext(w, context.Background())
+
+	heapAt := func() uint64 {
+		runtime.GC()
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return m.HeapAlloc
+	}
+	var early, late uint64
+	err := StreamCSV(c, "big.csv", []string{"id", "name", "email"}, func(yield func([]string) error) error {
+		for i := 1; i <= total; i++ {
+			if err := yield([]string{strconv.Itoa(i), fmt.Sprintf("用户%d", i), fmt.Sprintf("user%d@example.com", i)}); err != nil {
+				return err
+			}
+			switch i {
+			case total / 10:
+				early = heapAt()
+			case total:
+				late = heapAt()
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Greater(t, w.written, 3<<20)
+	assert.Less(t, int64(late)-int64(early), int64(1<<20), "early=%d late=%d", early, late)
+}
+
+// TestStreamJSONArray 测试 JSON 流式输出
+//
+// 【功能点】验证默认输出合法的 JSON 数组，WithNDJSON 时每行输出一个 JSON 对象
+// 【测试流程】
+//  1. 输出 250 个元素（跨越多次刷新），断言 Content-Type 并解析为数组，断言元素一致
+//  2. 没有元素时断言输出空数组 []
+//  3. WithNDJSON 时断言 Content-Type，按行解析，断言元素一致
+func TestStreamJSONArray(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	produce := func(n int) func(yield func(any) error) error {
+		return func(yield func(any) error) error {
+			for i := 0; i < n; i++ {
+				if err := yield(item{ID: i, Name: fmt.Sprintf("n,\"%d\"", i)}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	w := httptest.NewRecorder()
+	require.NoError(t, StreamJSONArray(newStreamContext(w, context.Background()), "items.json", produce(250)))
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	var items []item
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &items))
+	require.Len(t, items, 250)
+	assert.Equal(t, item{ID: 249, Name: `n,"249"`}, items[249])
+
+	w = httptest.NewRecorder()
+	require.NoError(t, StreamJSONArray(newStreamContext(w, context.Background()), "", produce(0)))
+	assert.Equal(t, "[]", w.Body.String())
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+
+	w = httptest.NewRecorder()
+	require.NoError(t, StreamJSONArray(newStreamContext(w, context.Background()), "", produce(3), WithNDJSON()))
+	assert.Equal(t, "application/x-ndjson; charset=utf-8", w.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	for i, line := range lines {
+		var got item
+		require.NoError(t, json.Unmarshal([]byte(line), &got))
+		assert.Equal(t, i, got.ID)
+	}
+}
+
+// TestScanRows 测试逐行扫描 GORM 查询结果
+//
+// 【功能点】验证 ScanRows 按查询条件逐行扫描并交给处理函数，处理函数出错时停止扫描
+// 【测试流程】
+//  1. 在内存 SQLite 中写入 5 个用户，结合 StreamCSV 导出 id > 1 的用户，断言 CSV 内容
+//  2. 处理函数在第 2 行返回 ErrClientGone，断言返回该错误且只处理了 2 行
+func TestScanRows(t *testing.T) {
+	type exportUser struct {
+		ID   int
+		Name string
+	}
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&exportUser{}))
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, db.Create(&exportUser{ID: i, Name: fmt.Sprintf("用户%d", i)}).Error)
+	}
+	query := func() *gorm.DB { return db.Model(&exportUser{}).Where("id > ?", 1).Order("id") }
+
+	w := httptest.NewRecorder()
+	err = StreamCSV(newStreamContext(w, context.Background()), "users.csv", []string{"ID", "姓名"}, func(yield func([]string) error) error {
+		return ScanRows(query(), func(u exportUser) error {
+			return yield([]string{strconv.Itoa(u.ID), u.Name})
+		})
+	}, WithBOM(false))
+	require.NoError(t, err)
+	assert.Equal(t, "ID,姓名\n2,用户2\n3,用户3\n4,用户4\n5,用户5\n", w.Body.String())
+
+	handled := 0
+	err = ScanRows(query(), func(exportUser) error {
+		handled++
+		if handled == 2 {
+			return ErrClientGone
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrClientGone)
+	assert.Equal(t, 2, handled)
+}
@@ -0,0 +1,333 @@
+// Package types 提供可直接用于 GORM 模型字段的数据类型
+// 本文件实现了字符串枚举：枚举值集合 EnumSet、binding:"enum=名称" 校验，以及写入数据库和 JSON 时校验取值的 EnumField
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// EnumOption 枚举选项，用于在接口中返回下拉框等的可选值，JSON 为 {"value": "paid", "label": "已支付"}
+type EnumOption struct {
+	Value string `json:"value"` // 枚举值
+	Label string `json:"label"` // 显示名称，未设置时与枚举值相同
+}
+
+// EnumSet 字符串枚举的可选值集合，按声明顺序保存
+// 创建后只读，可并发使用；通过 RegisterEnum 注册名称后可用于 binding:"enum=名称" 校验和 EnumField：
+//
+//	type OrderStatus string
+//
+//	const (
+//	    OrderPending OrderStatus = "pending"
+//	    OrderPaid    OrderStatus = "paid"
+//	)
+//
+//	var OrderStatuses = types.NewEnum(OrderPending, OrderPaid).
+//	    WithLabels(map[OrderStatus]string{OrderPending: "待支付", OrderPaid: "已支付"})
+//
+//	func init() {
+//	    types.MustRegisterEnum("orderStatus", OrderStatuses)
+//	}
+type EnumSet[T ~string] struct {
+	values []T
+	index  map[T]struct{}
+	labels map[T]string
+}
+
+// NewEnum 由可选值创建枚举集合，重复的值只保留第一个
+func NewEnum[T ~string](values ...T) *EnumSet[T] {
+	e := &EnumSet[T]{index: make(map[T]struct{}, len(values))}
+	for _, v := range values {
+		if _, ok := e.index[v]; ok {
+			continue
+		}
+		e.index[v] = struct{}{}
+		e.values = append(e.values, v)
+	}
+	return e
+}
+
+// WithLabels 设置可选值的显示名称，返回新的枚举集合，用于 Options 输出
+func (e *EnumSet[T]) WithLabels(labels map[T]string) *EnumSet[T] {
+	copied := *e
+	copied.labels = make(map[T]string, len(labels))
+	for v, label := range labels {
+		copied.labels[v] = label
+	}
+	return &copied
+}
+
+// Contains 判断 v 是否为可选值
+func (e *EnumSet[T]) Contains(v T) bool {
+	_, ok := e.index[v]
+	return ok
+}
+
+// Values 返回按声明顺序排列的可选值副本
+func (e *EnumSet[T]) Values() []T {
+	return append([]T(nil), e.values...)
+}
+
+// Strings 返回按声明顺序排列的可选值字符串
+func (e *EnumSet[T]) Strings() []string {
+	result := make([]string, len(e.values))
+	for i, v := range e.values {
+		result[i] = string(v)
+	}
+	return result
+}
+
+// Options 返回按声明顺序排列的枚举选项，用于在接口中返回可选值
+func (e *EnumSet[T]) Options() []EnumOption {
+	result := make([]EnumOption, len(e.values))
+	for i, v := range e.values {
+		label, ok := e.labels[v]
+		if !ok {
+			label = string(v)
+		}
+		result[i] = EnumOption{Value: string(v), Label: label}
+	}
+	return result
+}
+
+// Validator 返回校验字段值为可选值的校验函数，字段须为字符串类型
+// 可通过 core.RegisterValidation 注册为单独的校验标签，如 binding:"orderStatus"
+func (e *EnumSet[T]) Validator() validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		field := fl.Field()
+		return field.Kind() == reflect.String && e.containsString(field.String())
+	}
+}
+
+// containsString 判断字符串 s 是否为可选值
+func (e *EnumSet[T]) containsString(s string) bool {
+	return e.Contains(T(s))
+}
+
+// enumSet 注册表中保存的枚举集合
+type enumSet interface {
+	containsString(s string) bool
+	Strings() []string
+	Options() []EnumOption
+}
+
+// registeredEnum 已注册的枚举
+type registeredEnum struct {
+	name string
+	set  enumSet
+}
+
+// 枚举注册表，按名称和枚举类型索引
+var (
+	enumMu     sync.RWMutex
+	enumByName = make(map[string]registeredEnum)
+	enumByType = make(map[reflect.Type]registeredEnum)
+	enumNames  []string
+)
+
+// RegisterEnum 按名称注册枚举集合
+// 注册后可使用 binding:"enum=名称" 校验字段，EnumField[T] 按 T 使用该枚举集合校验取值；
+// 每个名称、每个枚举类型 T 只能注册一次，可并发调用
+//
+// 参数：
+//   - name: 枚举名称，如 orderStatus
+//   - set: 枚举集合
+//
+// 返回：
+//   - error: 名称为空、集合为空、名称或类型已注册时返回错误
+func RegisterEnum[T ~string](name string, set *EnumSet[T]) error {
+	if name == "" {
+		return errors.New("枚举名称不能为空")
+	}
+	if set == nil || len(set.values) == 0 {
+		return fmt.Errorf("枚举 %s 没有可选值", name)
+	}
+	typ := reflect.TypeFor[T]()
+	enumMu.Lock()
+	defer enumMu.Unlock()
+	if _, ok := enumByName[name]; ok {
+		return fmt.Errorf("枚举 %s 已注册", name)
+	}
+	if existing, ok := enumByType[typ]; ok {
+		return fmt.Errorf("枚举类型 %s 已注册为 %s", typ, existing.name)
+	}
+	entry := registeredEnum{name: name, set: set}
+	enumByName[name] = entry
+	enumByType[typ] = entry
+	enumNames = append(enumNames, name)
+	return nil
+}
+
+// MustRegisterEnum 与 RegisterEnum 相同，注册失败时 panic，用于包初始化
+func MustRegisterEnum[T ~string](name string, set *EnumSet[T]) {
+	if err := RegisterEnum(name, set); err != nil {
+		panic(err)
+	}
+}
+
+// EnumValues 获取已注册枚举的可选值，用于生成错误消息
+//
+// 返回：
+//   - []string: 按声明顺序排列的可选值
+//   - bool: 枚举是否已注册
+func EnumValues(name string) ([]string, bool) {
+	enumMu.RLock()
+	entry, ok := enumByName[name]
+	enumMu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return entry.set.Strings(), true
+}
+
+// EnumOptions 获取已注册枚举的选项，按枚举名称索引，用于在接口中返回下拉框等的可选值
+//
+// 参数：
+//   - names: 枚举名称，为空时返回全部已注册的枚举；未注册的名称被忽略
+//
+// 返回：
+//   - map[string][]EnumOption: 枚举名称到选项列表的映射，选项按声明顺序排列
+//
+// 使用示例：
+//
+//	response.OkWithData(c, types.EnumOptions("orderStatus", "payChannel"))
+//	// {"orderStatus": [{"value": "pending", "label": "待支付"}, ...], "payChannel": [...]}
+func EnumOptions(names ...string) map[string][]EnumOption {
+	enumMu.RLock()
+	defer enumMu.RUnlock()
+	if len(names) == 0 {
+		names = enumNames
+	}
+	result := make(map[string][]EnumOption, len(names))
+	for _, name := range names {
+		if entry, ok := enumByName[name]; ok {
+			result[name] = entry.set.Options()
+		}
+	}
+	return result
+}
+
+// ValidateEnum 校验标签 enum 的校验函数，由框架注册到参数校验器
+// 参数为 RegisterEnum 注册的名称，字段须为字符串类型，如 binding:"required,enum=orderStatus"；
+// 枚举未注册时校验不通过
+func ValidateEnum(fl validator.FieldLevel) bool {
+	enumMu.RLock()
+	entry, ok := enumByName[fl.Param()]
+	enumMu.RUnlock()
+	field := fl.Field()
+	return ok && field.Kind() == reflect.String && entry.set.containsString(field.String())
+}
+
+// lookupEnumType 获取枚举类型 T 注册的枚举
+func lookupEnumType[T ~string]() (registeredEnum, error) {
+	typ := reflect.TypeFor[T]()
+	enumMu.RLock()
+	entry, ok := enumByType[typ]
+	enumMu.RUnlock()
+	if !ok {
+		return registeredEnum{}, fmt.Errorf("枚举类型 %s 未通过 types.RegisterEnum 注册", typ)
+	}
+	return entry, nil
+}
+
+// checkEnumValue 校验 v 为 T 注册的枚举的可选值，空字符串视为未设置
+func checkEnumValue[T ~string](v T) error {
+	if v == "" {
+		return nil
+	}
+	entry, err := lookupEnumType[T]()
+	if err != nil {
+		return err
+	}
+	if !entry.set.containsString(string(v)) {
+		return fmt.Errorf("枚举 %s 不支持的值 %q，可选值: %s", entry.name, string(v), strings.Join(entry.set.Strings(), ", "))
+	}
+	return nil
+}
+
+// EnumField 存储为字符串的枚举字段，T 须通过 RegisterEnum 注册
+// 写入数据库、从数据库扫描和 JSON 解析时校验取值，不是可选值时返回包含枚举名称和可选值的错误；
+// 空字符串表示未设置，写入 NULL，数据库中的 NULL 扫描为空字符串，JSON 中的 null 和 "" 解析为空字符串：
+//
+//	type Order struct {
+//	    ID     uint
+//	    Status types.EnumField[OrderStatus] `gorm:"size:32"`
+//	}
+type EnumField[T ~string] struct {
+	Data T
+}
+
+// NewEnumField 由 v 创建 EnumField，不校验取值
+func NewEnumField[T ~string](v T) EnumField[T] {
+	return EnumField[T]{Data: v}
+}
+
+// String 返回枚举值
+func (f EnumField[T]) String() string {
+	return string(f.Data)
+}
+
+// Value 实现 driver.Valuer 接口，空字符串写入 NULL，不是可选值时返回错误
+func (f EnumField[T]) Value() (driver.Value, error) {
+	if f.Data == "" {
+		return nil, nil
+	}
+	if err := checkEnumValue(f.Data); err != nil {
+		return nil, err
+	}
+	return string(f.Data), nil
+}
+
+// Scan 实现 sql.Scanner 接口，NULL 扫描为空字符串，不是可选值时返回错误
+func (f *EnumField[T]) Scan(src any) error {
+	var v T
+	switch s := src.(type) {
+	case nil:
+	case string:
+		v = T(s)
+	case []byte:
+		v = T(s)
+	default:
+		return fmt.Errorf("EnumField 不支持从 %T 扫描", src)
+	}
+	if err := checkEnumValue(v); err != nil {
+		return err
+	}
+	f.Data = v
+	return nil
+}
+
+// MarshalJSON 序列化为字符串
+func (f EnumField[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(f.Data))
+}
+
+// UnmarshalJSON 解析字符串，null 解析为空字符串，不是可选值时返回错误
+func (f *EnumField[T]) UnmarshalJSON(data []byte) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	var v T
+	if s != nil {
+		v = T(*s)
+	}
+	if err := checkEnumValue(v); err != nil {
+		return err
+	}
+	f.Data = v
+	return nil
+}
+
+// GormDataType 实现 schema.GormDataTypeInterface 接口
+func (EnumField[T]) GormDataType() string {
+	return "string"
+}
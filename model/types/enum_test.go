@@ -0,0 +1,255 @@
+// Package types 枚举类型测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 EnumSet、RegisterEnum、ValidateEnum、EnumField 的单元测试，数据库读写使用内存 SQLite，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 可选值按声明顺序保存并去重，Options 输出稳定的 value / label 结构
+// 2. 注册时校验名称和类型，EnumOptions 按名称返回选项
+// 3. EnumField 写入 SQLite 后读取结果一致，空值写入 NULL；数据库中的未知值读取时返回包含枚举名称和可选值的错误
+// 4. JSON 解析未知值返回错误，null 和空字符串解析为未设置
+// 5. 并发注册枚举和读取选项
+//
+// 运行测试：go test -v ./model/types/... -run Enum
+// ==================================================
+package types
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// orderStatus 测试用枚举类型
+type orderStatus string
+
+const (
+	orderPending  orderStatus = "pending"
+	orderPaid     orderStatus = "paid"
+	orderCanceled orderStatus = "canceled"
+)
+
+// orderStatuses 测试用枚举集合，在 init 中注册为 testOrderStatus
+var orderStatuses = NewEnum(orderPending, orderPaid, orderCanceled, orderPaid).
+	WithLabels(map[orderStatus]string{orderPending: "待支付", orderPaid: "已支付"})
+
+func init() {
+	MustRegisterEnum("testOrderStatus", orderStatuses)
+}
+
+// enumOrder 使用枚举字段的示例模型
+type enumOrder struct {
+	ID     uint
+	Status EnumField[orderStatus] `gorm:"size:32"`
+}
+
+// TestEnumSet 测试枚举集合
+//
+// 【功能点】验证可选值按声明顺序去重保存，Contains、Strings、Options 的输出
+// 【测试流程】
+//  1. 断言 Values 为 pending、paid、canceled（重复的 paid 被忽略）
+//  2. 断言 Contains 对可选值返回 true，对未知值和空字符串返回 false
+//  3. 断言 Options 的 JSON 为按声明顺序排列的 value / label，未设置显示名称时与值相同
+func TestEnumSet(t *testing.T) {
+	assert.Equal(t, []orderStatus{orderPending, orderPaid, orderCanceled}, orderStatuses.Values())
+	assert.Equal(t, []string{"pending", "paid", "canceled"}, orderStatuses.Strings())
+	assert.True(t, orderStatuses.Contains(orderPaid))
+	assert.False(t, orderStatuses.Contains("refunded"))
+	assert.False(t, orderStatuses.Contains(""))
+
+	data, err := json.Marshal(orderStatuses.Options())
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"value": "pending", "label": "待支付"},
+		{"value": "paid", "label": "已支付"},
+		{"value": "canceled", "label": "canceled"}
+	]`, string(data))
+
+	values, ok := EnumValues("testOrderStatus")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"pending", "paid", "canceled"}, values)
+	options := EnumOptions("testOrderStatus", "notRegistered")
+	assert.Len(t, options, 1)
+	assert.Equal(t, orderStatuses.Options(), options["testOrderStatus"])
+}
+
+// TestRegisterEnum_Errors 测试注册枚举的参数校验
+//
+// 【功能点】验证名称为空、集合为空、名称重复、同一类型重复注册时返回错误
+// 【测试流程】
+//  1. 分别以空名称、空集合、已注册的名称、已注册的类型注册，断言返回错误
+func TestRegisterEnum_Errors(t *testing.T) {
+	type color string
+	assert.Error(t, RegisterEnum("", NewEnum[color]("red")))
+	assert.Error(t, RegisterEnum("emptyColor", NewEnum[color]()))
+	assert.Error(t, RegisterEnum("testOrderStatus", NewEnum[color]("red")))
+	assert.Error(t, RegisterEnum("anotherOrderStatus", NewEnum(orderPaid)))
+	assert.Panics(t, func() { MustRegisterEnum("", NewEnum[color]("red")) })
+}
+
+// TestValidateEnum 测试 enum 校验标签
+//
+// 【功能点】验证 binding:"enum=名称" 对可选值通过，对未知值、未注册的枚举和非字符串字段不通过；Validator 可注册为单独的标签
+// 【测试流程】
+//  1. 创建使用 binding 标签的校验器，注册 enum 标签和 orderStatus 标签
+//  2. 断言可选值校验通过，未知值、未注册的枚举名称、int 字段校验失败，omitempty 时空值通过
+func TestValidateEnum(t *testing.T) {
+	v := validator.New()
+	v.SetTagName("binding")
+	require.NoError(t, v.RegisterValidation("enum", ValidateEnum))
+	require.NoError(t, v.RegisterValidation("orderStatus", orderStatuses.Validator()))
+
+	type request struct {
+		Status   string      `binding:"enum=testOrderStatus"`
+		Typed    orderStatus `binding:"orderStatus"`
+		Optional string      `binding:"omitempty,enum=testOrderStatus"`
+	}
+	assert.NoError(t, v.Struct(request{Status: "paid", Typed: orderCanceled}))
+	assert.Error(t, v.Struct(request{Status: "refunded", Typed: orderPaid}))
+	assert.Error(t, v.Struct(request{Status: "paid", Typed: "refunded"}))
+
+	type unknownEnum struct {
+		Status string `binding:"enum=notRegistered"`
+	}
+	assert.Error(t, v.Struct(unknownEnum{Status: "paid"}))
+	type intField struct {
+		Status int `binding:"enum=testOrderStatus"`
+	}
+	assert.Error(t, v.Struct(intField{Status: 1}))
+}
+
+// TestEnumField_DBRoundTrip 测试枚举字段的数据库读写
+//
+// 【功能点】验证 EnumField 写入 SQLite 后读取结果一致，空值写入 NULL，写入和读取未知值时返回错误
+// 【测试流程】
+//  1. 写入 status=paid 和未设置 status 的两条数据，读取后断言值一致，未设置的列为 NULL
+//  2. 写入未知值，断言返回包含可选值的错误
+//  3. 通过原生 SQL 写入未知值后读取，断言错误包含列名、枚举名称和可选值
+func TestEnumField_DBRoundTrip(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&enumOrder{}))
+
+	paid := enumOrder{Status: NewEnumField(orderPaid)}
+	require.NoError(t, db.Create(&paid).Error)
+	empty := enumOrder{}
+	require.NoError(t, db.Create(&empty).Error)
+
+	var loadedPaid, loadedEmpty enumOrder
+	require.NoError(t, db.First(&loadedPaid, paid.ID).Error)
+	assert.Equal(t, orderPaid, loadedPaid.Status.Data)
+	require.NoError(t, db.First(&loadedEmpty, empty.ID).Error)
+	assert.Equal(t, orderStatus(""), loadedEmpty.Status.Data)
+	var nullCount int64
+	require.NoError(t, db.Model(&enumOrder{}).Where("status IS NULL").Count(&nullCount).Error)
+	assert.Equal(t, int64(1), nullCount)
+
+	err = db.Create(&enumOrder{Status: NewEnumField[orderStatus]("refunded")}).Error
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pending, paid, canceled")
+
+	require.NoError(t, db.Exec("INSERT INTO enum_orders (id, status) VALUES (?, ?)", 100, "refunded").Error)
+	var loaded enumOrder
+	err = db.First(&loaded, 100).Error
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status")
+	assert.Contains(t, err.Error(), `枚举 testOrderStatus 不支持的值 "refunded"，可选值: pending, paid, canceled`)
+}
+
+// TestEnumField_JSON 测试枚举字段的 JSON 序列化
+//
+// 【功能点】验证 EnumField 序列化为字符串，解析未知值返回错误，null 和空字符串解析为未设置，未注册的类型返回错误
+// 【测试流程】
+//  1. 序列化 status=paid，断言输出 {"status":"paid"}，解析后值一致
+//  2. 解析 refunded，断言错误包含可选值
+//  3. 解析 null、空字符串，断言为空值；解析数字断言返回错误
+//  4. 未注册的枚举类型解析非空值，断言返回未注册错误
+func TestEnumField_JSON(t *testing.T) {
+	type payload struct {
+		Status EnumField[orderStatus] `json:"status"`
+	}
+	data, err := json.Marshal(payload{Status: NewEnumField(orderPaid)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":"paid"}`, string(data))
+
+	var parsed payload
+	require.NoError(t, json.Unmarshal(data, &parsed))
+	assert.Equal(t, orderPaid, parsed.Status.Data)
+
+	err = json.Unmarshal([]byte(`{"status":"refunded"}`), &parsed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "可选值: pending, paid, canceled")
+
+	for _, input := range []string{`{"status":null}`, `{"status":""}`} {
+		parsed = payload{Status: NewEnumField(orderPaid)}
+		require.NoError(t, json.Unmarshal([]byte(input), &parsed), input)
+		assert.Equal(t, orderStatus(""), parsed.Status.Data, input)
+	}
+	assert.Error(t, json.Unmarshal([]byte(`{"status":1}`), &parsed))
+
+	type unregistered string
+	var field EnumField[unregistered]
+	err = json.Unmarshal([]byte(`"x"`), &field)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "未通过 types.RegisterEnum 注册")
+}
+
+// TestRegisterEnum_Concurrent 测试并发注册枚举
+//
+// 【功能点】验证并发注册枚举、读取选项和校验时不发生数据竞争（go test -race），同一名称只有一次注册成功
+// 【测试流程】
+//  1. 对 4 个枚举类型，每个类型 10 个协程并发以同一名称注册，同时读取 EnumOptions、EnumValues 并执行 ValidateEnum 校验
+//  2. 断言每个名称只有一次注册成功，所有名称均已注册
+func TestRegisterEnum_Concurrent(t *testing.T) {
+	type levelA string
+	type levelB string
+	type levelC string
+	type levelD string
+	registers := map[string]func() error{
+		"concurrentLevelA": func() error { return RegisterEnum("concurrentLevelA", NewEnum[levelA]("low", "high")) },
+		"concurrentLevelB": func() error { return RegisterEnum("concurrentLevelB", NewEnum[levelB]("low", "high")) },
+		"concurrentLevelC": func() error { return RegisterEnum("concurrentLevelC", NewEnum[levelC]("low", "high")) },
+		"concurrentLevelD": func() error { return RegisterEnum("concurrentLevelD", NewEnum[levelD]("low", "high")) },
+	}
+	v := validator.New()
+	require.NoError(t, v.RegisterValidation("enum", ValidateEnum))
+	type request struct {
+		Level string `validate:"enum=concurrentLevelA"`
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := make(map[string]int)
+	for name, register := range registers {
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if register() == nil {
+					mu.Lock()
+					succeeded[name]++
+					mu.Unlock()
+				}
+				_ = EnumOptions()
+				_, _ = EnumValues(name)
+				_ = v.Struct(request{Level: "low"})
+			}()
+		}
+	}
+	wg.Wait()
+
+	for name := range registers {
+		assert.Equal(t, 1, succeeded[name], name)
+		values, ok := EnumValues(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, []string{"low", "high"}, values, name)
+	}
+	assert.NoError(t, v.Struct(request{Level: "high"}))
+}
@@ -0,0 +1,237 @@
+// Package types 提供可直接用于 GORM 模型字段的数据类型
+// 本文件实现了存储为 JSON 列的 JSONMap、JSONSlice 和 JSONField，MySQL 中建为 JSON 列，SQLite 中建为 TEXT 列
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// JSONMap 存储为 JSON 对象的键值对，用于结构不固定的扩展属性
+// 数据库中的 NULL 扫描为 nil，nil 写入 NULL：
+//
+//	type Product struct {
+//	    ID    uint
+//	    Attrs types.JSONMap
+//	}
+type JSONMap map[string]any
+
+// JSONSlice 存储为 JSON 数组的切片，元素类型为 T
+// 数据库中的 NULL 扫描为 nil，nil 写入 NULL：
+//
+//	type Product struct {
+//	    ID   uint
+//	    Tags types.JSONSlice[string]
+//	}
+type JSONSlice[T any] []T
+
+// JSONField 存储为 JSON 的任意类型 T，通常为结构体
+// 数据库中的 NULL 扫描为零值；Data 序列化为 null（如 T 为指针且为 nil）时写入 NULL：
+//
+//	type Address struct {
+//	    City   string `json:"city"`
+//	    Street string `json:"street"`
+//	}
+//
+//	type User struct {
+//	    ID      uint
+//	    Address types.JSONField[Address]
+//	}
+type JSONField[T any] struct {
+	Data T
+}
+
+// NewJSONField 由 data 创建 JSONField
+func NewJSONField[T any](data T) JSONField[T] {
+	return JSONField[T]{Data: data}
+}
+
+// jsonValue 将 v 序列化为写入数据库的值，序列化结果为 null 时写入 NULL
+func jsonValue(v any) (driver.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	return string(data), nil
+}
+
+// scanJSON 将数据库返回的 JSON 解析到 dest
+// NULL 和空字符串不修改 dest，dest 保持调用方传入的零值
+// 解析失败时错误信息只包含列内容的长度和出错位置，不包含列内容本身，避免泄露数据
+func scanJSON(src any, dest any, typeName string) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法将 %T 扫描为 %s", src, typeName)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("%s 列内容不是有效的 JSON（长度 %d 字节）: %s", typeName, len(data), describeJSONError(err))
+	}
+	return nil
+}
+
+// describeJSONError 描述 JSON 解析错误，不包含原始内容
+func describeJSONError(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("语法错误，位置 %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return fmt.Sprintf("字段 %s 的类型不匹配，需要 %s，实际为 %s", typeErr.Field, typeErr.Type, typeErr.Value)
+		}
+		return fmt.Sprintf("类型不匹配，需要 %s，实际为 %s", typeErr.Type, typeErr.Value)
+	default:
+		return "格式错误"
+	}
+}
+
+// jsonDBDataType 按数据库类型返回 JSON 字段的列类型：MySQL 为 JSON，PostgreSQL 为 JSONB，其他为 TEXT
+func jsonDBDataType(db *gorm.DB) string {
+	switch db.Dialector.Name() {
+	case "mysql":
+		return "JSON"
+	case "postgres":
+		return "JSONB"
+	default:
+		return "TEXT"
+	}
+}
+
+// Value 实现 driver.Valuer 接口，nil 写入 NULL
+func (m JSONMap) Value() (driver.Value, error) {
+	return jsonValue(map[string]any(m))
+}
+
+// Scan 实现 sql.Scanner 接口，NULL 扫描为 nil
+func (m *JSONMap) Scan(src any) error {
+	var data map[string]any
+	if err := scanJSON(src, &data, "JSONMap"); err != nil {
+		return err
+	}
+	*m = data
+	return nil
+}
+
+// MarshalJSON 序列化为 JSON 对象，nil 输出 null
+func (m JSONMap) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any(m))
+}
+
+// UnmarshalJSON 解析 JSON 对象，null 解析为 nil
+func (m *JSONMap) UnmarshalJSON(data []byte) error {
+	var parsed map[string]any
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// GormDataType 实现 schema.GormDataTypeInterface 接口
+func (JSONMap) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType 实现 migrator.GormDBDataTypeInterface 接口，按数据库类型建表
+func (JSONMap) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	return jsonDBDataType(db)
+}
+
+// Value 实现 driver.Valuer 接口，nil 写入 NULL
+func (s JSONSlice[T]) Value() (driver.Value, error) {
+	return jsonValue([]T(s))
+}
+
+// Scan 实现 sql.Scanner 接口，NULL 扫描为 nil
+func (s *JSONSlice[T]) Scan(src any) error {
+	var data []T
+	if err := scanJSON(src, &data, "JSONSlice"); err != nil {
+		return err
+	}
+	*s = data
+	return nil
+}
+
+// MarshalJSON 序列化为 JSON 数组，nil 输出 null
+func (s JSONSlice[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]T(s))
+}
+
+// UnmarshalJSON 解析 JSON 数组，null 解析为 nil
+func (s *JSONSlice[T]) UnmarshalJSON(data []byte) error {
+	var parsed []T
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// GormDataType 实现 schema.GormDataTypeInterface 接口
+func (JSONSlice[T]) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType 实现 migrator.GormDBDataTypeInterface 接口，按数据库类型建表
+func (JSONSlice[T]) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	return jsonDBDataType(db)
+}
+
+// Value 实现 driver.Valuer 接口，Data 序列化为 null 时写入 NULL
+func (f JSONField[T]) Value() (driver.Value, error) {
+	return jsonValue(f.Data)
+}
+
+// Scan 实现 sql.Scanner 接口，NULL 扫描为零值
+func (f *JSONField[T]) Scan(src any) error {
+	var data T
+	if err := scanJSON(src, &data, "JSONField"); err != nil {
+		return err
+	}
+	f.Data = data
+	return nil
+}
+
+// MarshalJSON 序列化 Data，与直接使用 T 的输出相同
+func (f JSONField[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.Data)
+}
+
+// UnmarshalJSON 解析到 Data，null 解析为零值
+func (f *JSONField[T]) UnmarshalJSON(data []byte) error {
+	var parsed T
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	f.Data = parsed
+	return nil
+}
+
+// GormDataType 实现 schema.GormDataTypeInterface 接口
+func (JSONField[T]) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType 实现 migrator.GormDBDataTypeInterface 接口，按数据库类型建表
+func (JSONField[T]) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	return jsonDBDataType(db)
+}
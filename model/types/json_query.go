@@ -0,0 +1,171 @@
+// Package types 提供可直接用于 GORM 模型字段的数据类型
+// 本文件实现了 JSON 列的查询条件，按数据库类型生成 MySQL 或 SQLite 的 SQL
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// jsonColumnPattern 列名格式，支持 "column" 和 "table.column"
+// 列名直接拼接到 SQL 中，只接受字母、数字和下划线，避免 SQL 注入
+var jsonColumnPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// JSONContains 添加"JSON 列在 path 处包含 value"的查询条件
+// MySQL 使用 JSON_CONTAINS；SQLite 没有 JSON_CONTAINS，通过 json_each / json_extract 实现：
+// path 处为数组时判断数组中是否有等于 value 的元素，为标量时判断是否相等；value 为切片时要求每个元素都被包含
+// 参数：
+//   - db: 查询
+//   - column: 列名，如 "tags" 或 "products.tags"
+//   - path: JSON 路径，如 "$.colors"，为空时表示整列（"$"）
+//   - value: 要包含的值；SQLite 只支持标量或标量的切片
+//
+// 返回：
+//   - *gorm.DB: 添加了条件的查询，列名非法、数据库类型不支持或 value 类型不支持时查询返回错误
+//
+// 使用示例：
+//
+//	var products []Product
+//	types.JSONContains(db, "tags", "$", "sale").Find(&products)
+//	types.JSONContains(db, "attrs", "$.sizes", []string{"M", "L"}).Find(&products)
+func JSONContains(db *gorm.DB, column, path string, value any) *gorm.DB {
+	column, path, err := jsonQueryArgs(db, column, path)
+	if err != nil {
+		return withError(db, err)
+	}
+
+	switch db.Dialector.Name() {
+	case "mysql":
+		candidate, err := json.Marshal(value)
+		if err != nil {
+			return withError(db, fmt.Errorf("JSONContains 序列化查询值失败: %w", err))
+		}
+		return db.Where(fmt.Sprintf("JSON_CONTAINS(%s, ?, ?)", column), string(candidate), path)
+	case "sqlite":
+		elements, err := sqliteJSONScalars(value)
+		if err != nil {
+			return withError(db, fmt.Errorf("JSONContains: %w", err))
+		}
+		conditions := make([]string, 0, len(elements))
+		args := make([]any, 0, len(elements)*5)
+		for _, element := range elements {
+			conditions = append(conditions, fmt.Sprintf(
+				"(CASE json_type(%[1]s, ?) WHEN 'array' THEN EXISTS (SELECT 1 FROM json_each(%[1]s, ?) WHERE json_each.value = ?) WHEN 'object' THEN 0 ELSE json_extract(%[1]s, ?) = ? END)",
+				column))
+			args = append(args, path, path, element, path, element)
+		}
+		if len(conditions) == 0 {
+			return db
+		}
+		return db.Where(strings.Join(conditions, " AND "), args...)
+	default:
+		return withError(db, fmt.Errorf("JSONContains 不支持 %s 数据库", db.Dialector.Name()))
+	}
+}
+
+// JSONExtractEq 添加"JSON 列在 path 处的值等于 value"的查询条件
+// MySQL 使用 JSON_EXTRACT 并将 value 转换为 JSON 比较，类型必须相同（字符串 "1" 不等于数字 1）；
+// SQLite 使用 json_extract，value 为对象或数组时按序列化后的文本比较
+// 参数：
+//   - db: 查询
+//   - column: 列名，如 "attrs" 或 "products.attrs"
+//   - path: JSON 路径，如 "$.color"，为空时表示整列（"$"）
+//   - value: 期望的值，nil 匹配 JSON null
+//
+// 返回：
+//   - *gorm.DB: 添加了条件的查询，列名非法或数据库类型不支持时查询返回错误
+//
+// 使用示例：
+//
+//	var products []Product
+//	types.JSONExtractEq(db, "attrs", "$.color", "red").Find(&products)
+func JSONExtractEq(db *gorm.DB, column, path string, value any) *gorm.DB {
+	column, path, err := jsonQueryArgs(db, column, path)
+	if err != nil {
+		return withError(db, err)
+	}
+
+	switch db.Dialector.Name() {
+	case "mysql":
+		expected, err := json.Marshal(value)
+		if err != nil {
+			return withError(db, fmt.Errorf("JSONExtractEq 序列化查询值失败: %w", err))
+		}
+		return db.Where(fmt.Sprintf("JSON_EXTRACT(%s, ?) = CAST(? AS JSON)", column), path, string(expected))
+	case "sqlite":
+		if value == nil {
+			return db.Where(fmt.Sprintf("json_type(%s, ?) = 'null'", column), path)
+		}
+		if scalar, ok := sqliteJSONScalar(value); ok {
+			return db.Where(fmt.Sprintf("json_extract(%s, ?) = ?", column), path, scalar)
+		}
+		expected, err := json.Marshal(value)
+		if err != nil {
+			return withError(db, fmt.Errorf("JSONExtractEq 序列化查询值失败: %w", err))
+		}
+		return db.Where(fmt.Sprintf("json_extract(%s, ?) = json(?)", column), path, string(expected))
+	default:
+		return withError(db, fmt.Errorf("JSONExtractEq 不支持 %s 数据库", db.Dialector.Name()))
+	}
+}
+
+// withError 返回带有错误的新会话，不影响传入的 db（db 可能是全局共享的连接）
+func withError(db *gorm.DB, err error) *gorm.DB {
+	tx := db.Session(&gorm.Session{})
+	_ = tx.AddError(err)
+	return tx
+}
+
+// jsonQueryArgs 校验并引用列名，path 为空时使用 "$"
+func jsonQueryArgs(db *gorm.DB, column, path string) (string, string, error) {
+	if !jsonColumnPattern.MatchString(column) {
+		return "", "", fmt.Errorf("无效的 JSON 列名: %q", column)
+	}
+	if path == "" {
+		path = "$"
+	}
+	return db.Statement.Quote(column), path, nil
+}
+
+// sqliteJSONScalar 将标量转换为与 SQLite json_extract 结果可比较的值：布尔值转换为 1 / 0
+// value 不是标量（对象、数组、切片、映射）时返回 false
+func sqliteJSONScalar(value any) (any, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			return 1, true
+		}
+		return 0, true
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return value, true
+	default:
+		return nil, false
+	}
+}
+
+// sqliteJSONScalars 将 JSONContains 的查询值展开为标量列表：标量返回自身，标量的切片返回每个元素
+func sqliteJSONScalars(value any) ([]any, error) {
+	if scalar, ok := sqliteJSONScalar(value); ok {
+		return []any{scalar}, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("SQLite 只支持标量或标量的切片，不支持 %T", value)
+	}
+	elements := make([]any, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		scalar, ok := sqliteJSONScalar(rv.Index(i).Interface())
+		if !ok {
+			return nil, fmt.Errorf("SQLite 只支持标量或标量的切片，不支持元素类型 %s", rv.Index(i).Type())
+		}
+		elements = append(elements, scalar)
+	}
+	return elements, nil
+}
@@ -0,0 +1,124 @@
+// Package types JSON 查询条件测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 JSONContains、JSONExtractEq 的单元测试，过滤结果使用内存 SQLite 验证，MySQL 使用 DryRun 只检查生成的 SQL。
+//
+// 测试覆盖内容：
+// 1. JSONContains 在 SQLite 中按数组元素、嵌套路径和多个值过滤，对象不被当作包含
+// 2. JSONExtractEq 在 SQLite 中按字符串、数字、布尔值、null 和对象过滤
+// 3. MySQL 生成 JSON_CONTAINS / JSON_EXTRACT 条件
+// 4. 列名非法、查询值类型不支持时返回错误，且不影响原查询
+//
+// 运行测试：go test -v ./model/types/... -run JSONQuery
+// ==================================================
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// seedJSONQueryProducts 写入查询测试使用的商品
+func seedJSONQueryProducts(t *testing.T, db *gorm.DB) {
+	products := []product{
+		{Name: "shirt", Tags: JSONSlice[string]{"sale", "new"}, Sizes: JSONSlice[int]{38, 40},
+			Attrs: JSONMap{"color": "red", "stock": 3, "active": true, "dims": map[string]any{"w": 1}, "sizes": []any{"M", "L"}}},
+		{Name: "pants", Tags: JSONSlice[string]{"new"}, Sizes: JSONSlice[int]{40},
+			Attrs: JSONMap{"color": "blue", "stock": 0, "active": false, "sizes": []any{"S"}, "note": nil}},
+		{Name: "hat", Tags: JSONSlice[string]{"sale"},
+			Attrs: JSONMap{"color": "red", "stock": "3", "sizes": "M", "dims": map[string]any{"sale": "x"}}},
+		{Name: "empty"},
+	}
+	require.NoError(t, db.Create(&products).Error)
+}
+
+// productNames 执行查询并返回商品名称
+func productNames(t *testing.T, query *gorm.DB) []string {
+	var names []string
+	require.NoError(t, query.Model(&product{}).Order("id").Pluck("name", &names).Error)
+	return names
+}
+
+// TestJSONQuery_ContainsSQLite 测试 SQLite 中的 JSONContains
+//
+// 【功能点】验证按数组元素、嵌套路径和多个值过滤，路径处为标量时按相等判断，为对象时不匹配
+// 【测试流程】
+//  1. 写入 4 个商品，按整列的 tags 包含 "sale"、sizes 包含 40 查询
+//  2. 按 $.sizes 包含 "M"（数组和标量）、同时包含 ["M", "L"] 查询
+//  3. 按 $.dims 包含 "x" 查询，断言对象的值不被当作包含；与其他条件组合查询
+func TestJSONQuery_ContainsSQLite(t *testing.T) {
+	db := openJSONTestDB(t)
+	seedJSONQueryProducts(t, db)
+
+	assert.Equal(t, []string{"shirt", "hat"}, productNames(t, JSONContains(db, "tags", "", "sale")))
+	assert.Equal(t, []string{"shirt", "pants"}, productNames(t, JSONContains(db, "sizes", "$", 40)))
+	assert.Equal(t, []string{"shirt", "hat"}, productNames(t, JSONContains(db, "attrs", "$.sizes", "M")))
+	assert.Equal(t, []string{"shirt"}, productNames(t, JSONContains(db, "attrs", "$.sizes", []string{"M", "L"})))
+	assert.Equal(t, []string{"shirt"}, productNames(t, JSONContains(db, "products.tags", "$", JSONSlice[string]{"new", "sale"})))
+	assert.Empty(t, productNames(t, JSONContains(db, "attrs", "$.dims", "x")))
+	assert.Equal(t, []string{"pants"}, productNames(t, JSONContains(db.Where("name <> ?", "shirt"), "tags", "$", "new")))
+}
+
+// TestJSONQuery_ExtractEqSQLite 测试 SQLite 中的 JSONExtractEq
+//
+// 【功能点】验证按字符串、数字、布尔值、null 和对象过滤，字符串 "3" 与数字 3 不相等
+// 【测试流程】
+//  1. 写入 4 个商品，按 $.color = "red"、$.stock = 3、$.stock = "3" 查询
+//  2. 按 $.active = true / false、$.note = null、$.dims = {"w": 1} 查询
+func TestJSONQuery_ExtractEqSQLite(t *testing.T) {
+	db := openJSONTestDB(t)
+	seedJSONQueryProducts(t, db)
+
+	assert.Equal(t, []string{"shirt", "hat"}, productNames(t, JSONExtractEq(db, "attrs", "$.color", "red")))
+	assert.Equal(t, []string{"shirt"}, productNames(t, JSONExtractEq(db, "attrs", "$.stock", 3)))
+	assert.Equal(t, []string{"hat"}, productNames(t, JSONExtractEq(db, "attrs", "$.stock", "3")))
+	assert.Equal(t, []string{"shirt"}, productNames(t, JSONExtractEq(db, "attrs", "$.active", true)))
+	assert.Equal(t, []string{"pants"}, productNames(t, JSONExtractEq(db, "attrs", "$.active", false)))
+	assert.Equal(t, []string{"pants"}, productNames(t, JSONExtractEq(db, "attrs", "$.note", nil)))
+	assert.Equal(t, []string{"shirt"}, productNames(t, JSONExtractEq(db, "attrs", "$.dims", map[string]any{"w": 1})))
+}
+
+// TestJSONQuery_MySQL 测试 MySQL 生成的 SQL
+//
+// 【功能点】验证 MySQL 使用 JSON_CONTAINS 和 JSON_EXTRACT，查询值序列化为 JSON 作为参数
+// 【测试流程】使用 MySQL 方言的 DryRun 会话分别生成两种查询，断言 SQL 和参数
+func TestJSONQuery_MySQL(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:1)/test", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+
+	stmt := JSONContains(db, "tags", "$", []string{"sale"}).Find(&[]product{}).Statement
+	assert.Equal(t, "SELECT * FROM `products` WHERE JSON_CONTAINS(`tags`, ?, ?)", stmt.SQL.String())
+	assert.Equal(t, []any{`["sale"]`, "$"}, stmt.Vars)
+
+	stmt = JSONExtractEq(db, "products.attrs", "$.color", "red").Find(&[]product{}).Statement
+	assert.Equal(t, "SELECT * FROM `products` WHERE JSON_EXTRACT(`products`.`attrs`, ?) = CAST(? AS JSON)", stmt.SQL.String())
+	assert.Equal(t, []any{"$.color", `"red"`}, stmt.Vars)
+}
+
+// TestJSONQuery_Errors 测试非法参数
+//
+// 【功能点】验证列名包含非法字符、SQLite 中查询值为对象时返回错误，且错误不影响传入的 db
+// 【测试流程】
+//  1. 使用 "tags; DROP TABLE products" 作为列名查询，断言返回错误
+//  2. SQLite 中以对象作为 JSONContains 的查询值，断言返回错误
+//  3. 断言传入的 db 仍可正常查询
+func TestJSONQuery_Errors(t *testing.T) {
+	db := openJSONTestDB(t)
+	seedJSONQueryProducts(t, db)
+
+	err := JSONContains(db, "tags; DROP TABLE products", "$", "sale").Find(&[]product{}).Error
+	assert.ErrorContains(t, err, "无效的 JSON 列名")
+	err = JSONExtractEq(db, "attrs->'$.a'", "$", 1).Find(&[]product{}).Error
+	assert.ErrorContains(t, err, "无效的 JSON 列名")
+	err = JSONContains(db, "attrs", "$.dims", map[string]any{"w": 1}).Find(&[]product{}).Error
+	assert.ErrorContains(t, err, "SQLite 只支持标量或标量的切片")
+
+	require.NoError(t, db.Error)
+	assert.Len(t, productNames(t, db), 4)
+}
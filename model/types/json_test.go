@@ -0,0 +1,240 @@
+// Package types JSON 字段类型测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 JSONMap、JSONSlice、JSONField 的单元测试，数据库读写使用内存 SQLite，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 嵌套结构写入 SQLite 后读取结果一致，JSON 序列化与普通 map / 切片相同
+// 2. 数据库中的 NULL 读取为零值，零值写入 NULL
+// 3. 泛型 JSONField 使用结构体，JSON 序列化与直接使用结构体相同
+// 4. 数据库中的内容不是有效 JSON 时返回包含长度、不包含内容的错误
+// 5. 按数据库类型建表：SQLite 为 TEXT，MySQL 为 JSON
+//
+// 运行测试：go test -v ./model/types/...
+// ==================================================
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// address 测试 JSONField 使用的结构体
+type address struct {
+	City   string   `json:"city"`
+	Street string   `json:"street"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// product 使用 JSON 字段类型的示例模型
+type product struct {
+	ID      uint
+	Name    string
+	Attrs   JSONMap
+	Tags    JSONSlice[string]
+	Sizes   JSONSlice[int]
+	Address JSONField[address]
+	Owner   JSONField[*address]
+}
+
+// openJSONTestDB 打开内存 SQLite 并迁移示例模型
+func openJSONTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&product{}))
+	return db
+}
+
+// TestJSONTypes_RoundTrip 测试嵌套结构的读写
+//
+// 【功能点】验证嵌套的对象、数组和泛型结构体写入数据库后读取结果一致，JSON 序列化与普通类型相同
+// 【测试流程】
+//  1. 写入包含嵌套对象和数组的 Attrs、Tags、Sizes、Address、Owner
+//  2. 读取后断言各字段与写入值相等（JSON 数字读取为 float64）
+//  3. 序列化读取的模型，断言 JSON 字段输出为普通对象和数组
+func TestJSONTypes_RoundTrip(t *testing.T) {
+	db := openJSONTestDB(t)
+	created := product{
+		Name: "shirt",
+		Attrs: JSONMap{
+			"color": "red",
+			"size":  map[string]any{"chest": 100.5, "options": []any{"M", "L"}},
+			"stock": 3,
+		},
+		Tags:    JSONSlice[string]{"sale", "new"},
+		Sizes:   JSONSlice[int]{38, 40},
+		Address: NewJSONField(address{City: "上海", Street: "南京路", Tags: []string{"office"}}),
+		Owner:   NewJSONField(&address{City: "北京"}),
+	}
+	require.NoError(t, db.Create(&created).Error)
+
+	var loaded product
+	require.NoError(t, db.First(&loaded, created.ID).Error)
+	assert.Equal(t, JSONMap{
+		"color": "red",
+		"size":  map[string]any{"chest": 100.5, "options": []any{"M", "L"}},
+		"stock": float64(3),
+	}, loaded.Attrs)
+	assert.Equal(t, created.Tags, loaded.Tags)
+	assert.Equal(t, created.Sizes, loaded.Sizes)
+	assert.Equal(t, created.Address, loaded.Address)
+	assert.Equal(t, created.Owner.Data, loaded.Owner.Data)
+
+	data, err := json.Marshal(loaded)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"ID": 1, "Name": "shirt",
+		"Attrs": {"color": "red", "size": {"chest": 100.5, "options": ["M", "L"]}, "stock": 3},
+		"Tags": ["sale", "new"], "Sizes": [38, 40],
+		"Address": {"city": "上海", "street": "南京路", "tags": ["office"]},
+		"Owner": {"city": "北京", "street": ""}
+	}`, string(data))
+
+	var decoded product
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, loaded.Address, decoded.Address)
+	assert.Equal(t, loaded.Tags, decoded.Tags)
+}
+
+// TestJSONTypes_Null 测试 NULL 处理
+//
+// 【功能点】验证零值写入 NULL，NULL 读取为零值，JSON 序列化输出 null，扫描 NULL 时覆盖原有的值
+// 【测试流程】
+//  1. 写入只设置 Name 的模型，断言各 JSON 列在数据库中为 NULL
+//  2. 读取后断言各字段为零值
+//  3. 序列化零值，断言输出 null；对已有值的字段扫描 nil，断言变为零值
+func TestJSONTypes_Null(t *testing.T) {
+	db := openJSONTestDB(t)
+	created := product{Name: "empty"}
+	require.NoError(t, db.Create(&created).Error)
+
+	var nullCount int64
+	require.NoError(t, db.Model(&product{}).
+		Where("attrs IS NULL AND tags IS NULL AND sizes IS NULL AND owner IS NULL").Count(&nullCount).Error)
+	assert.Equal(t, int64(1), nullCount)
+
+	var loaded product
+	require.NoError(t, db.First(&loaded, created.ID).Error)
+	assert.Nil(t, loaded.Attrs)
+	assert.Nil(t, loaded.Tags)
+	assert.Nil(t, loaded.Owner.Data)
+	assert.Equal(t, address{}, loaded.Address.Data)
+
+	data, err := json.Marshal(struct {
+		Attrs JSONMap
+		Tags  JSONSlice[string]
+		Owner JSONField[*address]
+	}{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Attrs": null, "Tags": null, "Owner": null}`, string(data))
+
+	attrs := JSONMap{"a": 1}
+	require.NoError(t, attrs.Scan(nil))
+	assert.Nil(t, attrs)
+	field := NewJSONField(address{City: "上海"})
+	require.NoError(t, field.Scan(nil))
+	assert.Equal(t, address{}, field.Data)
+}
+
+// TestJSONField_Struct 测试泛型 JSONField 使用结构体
+//
+// 【功能点】验证 JSONField 的 JSON 序列化与直接使用结构体相同，可从数据库驱动返回的字符串和 []byte 扫描
+// 【测试流程】
+//  1. 序列化 JSONField[address]，断言与序列化 address 的结果相同
+//  2. 反序列化对象和 null，断言 Data 正确
+//  3. 分别扫描字符串和 []byte，断言 Data 正确；Value 返回 JSON 字符串
+func TestJSONField_Struct(t *testing.T) {
+	value := address{City: "杭州", Street: "文三路", Tags: []string{"home"}}
+	fieldJSON, err := json.Marshal(NewJSONField(value))
+	require.NoError(t, err)
+	plainJSON, err := json.Marshal(value)
+	require.NoError(t, err)
+	assert.Equal(t, string(plainJSON), string(fieldJSON))
+
+	var decoded JSONField[address]
+	require.NoError(t, json.Unmarshal(fieldJSON, &decoded))
+	assert.Equal(t, value, decoded.Data)
+	require.NoError(t, json.Unmarshal([]byte("null"), &decoded))
+	assert.Equal(t, address{}, decoded.Data)
+
+	for _, src := range []any{string(plainJSON), plainJSON} {
+		var scanned JSONField[address]
+		require.NoError(t, scanned.Scan(src))
+		assert.Equal(t, value, scanned.Data)
+	}
+	stored, err := NewJSONField(value).Value()
+	require.NoError(t, err)
+	assert.Equal(t, string(plainJSON), stored)
+}
+
+// TestJSONTypes_InvalidStoredJSON 测试无效的 JSON 内容
+//
+// 【功能点】验证数据库中的内容不是有效 JSON 或类型不匹配时返回错误，错误信息包含内容长度，不包含内容本身
+// 【测试流程】
+//  1. 直接向 tags 列写入包含敏感信息的非 JSON 内容，读取模型，断言错误包含长度且不包含内容
+//  2. 对 JSONMap 扫描数组、对 JSONField[address] 扫描字段类型错误的对象，断言错误说明类型不匹配
+//  3. 扫描不支持的类型，断言返回错误
+func TestJSONTypes_InvalidStoredJSON(t *testing.T) {
+	db := openJSONTestDB(t)
+	require.NoError(t, db.Create(&product{Name: "broken"}).Error)
+	secret := "password=secret123"
+	require.NoError(t, db.Exec("UPDATE products SET tags = ?", secret).Error)
+
+	var loaded product
+	err := db.First(&loaded).Error
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "长度 18 字节")
+	assert.NotContains(t, err.Error(), "secret")
+
+	var attrs JSONMap
+	err = attrs.Scan(`["a"]`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "类型不匹配")
+	assert.NotContains(t, err.Error(), `"a"`)
+
+	var field JSONField[address]
+	err = field.Scan(`{"city": 123}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "字段 city")
+
+	assert.Error(t, attrs.Scan(42))
+}
+
+// TestJSONTypes_DBDataType 测试按数据库类型建表
+//
+// 【功能点】验证 SQLite 中建为 TEXT 列，MySQL 中建为 JSON 列
+// 【测试流程】
+//  1. 读取 SQLite 中 products 表的列类型，断言 JSON 字段为 TEXT
+//  2. 使用 MySQL 方言（不连接数据库）解析模型，断言 JSON 字段的列类型为 JSON
+func TestJSONTypes_DBDataType(t *testing.T) {
+	db := openJSONTestDB(t)
+	columns, err := db.Migrator().ColumnTypes(&product{})
+	require.NoError(t, err)
+	columnTypes := map[string]string{}
+	for _, column := range columns {
+		columnTypes[column.Name()] = column.DatabaseTypeName()
+	}
+	for _, name := range []string{"attrs", "tags", "sizes", "address", "owner"} {
+		assert.Equal(t, "TEXT", columnTypes[name], name)
+	}
+
+	mysqlDB, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:1)/test", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	stmt := &gorm.Statement{DB: mysqlDB}
+	require.NoError(t, stmt.Parse(&product{}))
+	for _, name := range []string{"Attrs", "Tags", "Address"} {
+		field := stmt.Schema.LookUpField(name)
+		require.NotNil(t, field, name)
+		assert.Equal(t, schema.DataType("json"), field.DataType, name)
+		assert.Equal(t, "JSON", mysqlDB.Migrator().FullDataTypeOf(field).SQL, name)
+	}
+}
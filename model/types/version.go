@@ -0,0 +1,13 @@
+// Package types 提供可直接用于 GORM 模型字段的数据类型
+// 本文件定义了乐观锁的版本号类型
+package types
+
+// Version 乐观锁版本号，配合 app.UpdateWithVersion 使用
+// 模型中只能有一个 Version 类型的字段，创建时为 0，每次通过 app.UpdateWithVersion 更新成功后加 1：
+//
+//	type Order struct {
+//	    ID      uint
+//	    Status  string
+//	    Version types.Version `gorm:"not null;default:0"`
+//	}
+type Version int64
@@ -0,0 +1,324 @@
+// Package objectstorage 提供 S3 兼容对象存储的客户端
+// 支持 AWS S3、阿里云 OSS（S3 兼容接口）和 MinIO，请求使用 AWS Signature V4 签名，不依赖服务商 SDK
+package objectstorage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zzsen/gin_core/model/config"
+)
+
+const (
+	// maxPresignTTL 预签名地址的最长有效期
+	maxPresignTTL = 7 * 24 * time.Hour
+	// listPageSize List 每次请求返回的最大对象数
+	listPageSize = 1000
+)
+
+// ObjectInfo 对象信息
+type ObjectInfo struct {
+	Key          string    // 对象键
+	Size         int64     // 大小（字节）
+	ContentType  string    // 内容类型，List 返回的对象信息中为空
+	ETag         string    // ETag，不含引号
+	LastModified time.Time // 最后修改时间
+}
+
+// Client S3 兼容对象存储客户端
+// 只访问配置的存储桶，可被多个协程并发使用
+type Client struct {
+	bucket     string
+	scheme     string
+	endpoint   string
+	pathStyle  bool
+	signer     signer
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// New 根据配置创建对象存储客户端，不会发送请求
+// 参数：
+//   - cfg: 对象存储配置
+//
+// 返回：
+//   - *Client: 客户端
+//   - error: 服务商无法识别，或未配置存储桶、服务地址时返回错误
+func New(cfg config.ObjectStorageConfig) (*Client, error) {
+	switch provider := cfg.GetProvider(); provider {
+	case config.ObjectStorageS3, config.ObjectStorageOSS, config.ObjectStorageMinIO:
+	default:
+		return nil, fmt.Errorf("[objectStorage] 无法识别的服务商: %s，可选值 s3 / oss / minio", provider)
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("[objectStorage] 未配置存储桶")
+	}
+	endpoint := cfg.GetEndpoint()
+	if endpoint == "" || strings.Contains(endpoint, "://") {
+		return nil, fmt.Errorf("[objectStorage] 服务地址无效: %q，应为 host[:port]，通过 useSSL 指定是否使用 https", endpoint)
+	}
+
+	scheme := "http"
+	if cfg.UseSSL {
+		scheme = "https"
+	}
+	return &Client{
+		bucket:     cfg.Bucket,
+		scheme:     scheme,
+		endpoint:   endpoint,
+		pathStyle:  cfg.UsePathStyle(),
+		signer:     signer{accessKey: cfg.AccessKey, secretKey: cfg.SecretKey, region: cfg.GetRegion()},
+		httpClient: &http.Client{},
+		now:        time.Now,
+	}, nil
+}
+
+// Bucket 获取存储桶名称
+func (c *Client) Bucket() string {
+	return c.bucket
+}
+
+// Put 上传对象，同名对象被覆盖
+// 参数：
+//   - ctx: context
+//   - key: 对象键
+//   - r: 对象内容
+//   - size: 内容长度，小于 0 时先将内容读入内存以确定长度
+//   - contentType: 内容类型，为空时由服务端决定
+//
+// 返回：
+//   - error: 上传失败时返回错误，服务端的错误响应为 *Error
+func (c *Client) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("[objectStorage] 读取对象 %q 的内容失败: %w", key, err)
+		}
+		r, size = bytes.NewReader(data), int64(len(data))
+	}
+	body := io.NopCloser(r)
+	if size == 0 {
+		body = http.NoBody
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPut, key, nil, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.do(req, "Put", key)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get 下载对象
+// 参数：
+//   - ctx: context，取消后读取内容将失败
+//   - key: 对象键
+//
+// 返回：
+//   - io.ReadCloser: 对象内容，调用方负责关闭
+//   - ObjectInfo: 对象信息
+//   - error: 下载失败时返回错误，对象不存在时 errors.Is(err, ErrNotFound) 为 true
+func (c *Client) Get(ctx context.Context, key string) (io.ReadCloser, ObjectInfo, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	resp, err := c.do(req, "Get", key)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	info := ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+	}
+	info.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return resp.Body, info, nil
+}
+
+// Delete 删除对象，对象不存在时不返回错误
+func (c *Client) Delete(ctx context.Context, key string) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(req, "Delete", key)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PresignGet 生成下载对象的预签名地址，持有地址的客户端无需密钥即可在有效期内下载
+// 参数：
+//   - ctx: context，预签名在本地计算，不发送请求
+//   - key: 对象键
+//   - ttl: 有效期，1 秒到 7 天
+//
+// 返回：
+//   - string: 预签名地址
+//   - error: 有效期超出范围时返回错误
+func (c *Client) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return c.presign(http.MethodGet, key, ttl)
+}
+
+// PresignPut 生成上传对象的预签名地址，持有地址的客户端无需密钥即可在有效期内通过 PUT 请求上传
+// 参数与返回值同 PresignGet
+func (c *Client) PresignPut(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return c.presign(http.MethodPut, key, ttl)
+}
+
+// presign 生成预签名地址
+func (c *Client) presign(method, key string, ttl time.Duration) (string, error) {
+	if ttl < time.Second || ttl > maxPresignTTL {
+		return "", fmt.Errorf("[objectStorage] 预签名地址的有效期 %s 超出范围，应为 1s 到 %s", ttl, maxPresignTTL)
+	}
+	return c.signer.presign(method, c.objectURL(key, nil), c.now().UTC(), ttl), nil
+}
+
+// List 按前缀列出对象，按对象键的字典序返回，自动翻页
+// 请求失败时返回一次错误后结束，提前结束遍历时不再请求后续页
+//
+// 使用示例：
+//
+//	for obj, err := range app.Storage.List(ctx, "avatars/") {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(obj.Key, obj.Size)
+//	}
+func (c *Client) List(ctx context.Context, prefix string) iter.Seq2[ObjectInfo, error] {
+	return func(yield func(ObjectInfo, error) bool) {
+		token := ""
+		for {
+			page, err := c.listPage(ctx, prefix, token)
+			if err != nil {
+				yield(ObjectInfo{}, err)
+				return
+			}
+			for _, obj := range page.Contents {
+				info := ObjectInfo{Key: obj.Key, Size: obj.Size, ETag: strings.Trim(obj.ETag, `"`)}
+				info.LastModified, _ = time.Parse(time.RFC3339, obj.LastModified)
+				if !yield(info, nil) {
+					return
+				}
+			}
+			if !page.IsTruncated || page.NextContinuationToken == "" {
+				return
+			}
+			token = page.NextContinuationToken
+		}
+	}
+}
+
+// listBucketResult ListObjectsV2 的响应
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string `xml:"Key"`
+		Size         int64  `xml:"Size"`
+		ETag         string `xml:"ETag"`
+		LastModified string `xml:"LastModified"`
+	} `xml:"Contents"`
+}
+
+// listPage 请求一页对象列表
+func (c *Client) listPage(ctx context.Context, prefix, token string) (*listBucketResult, error) {
+	query := url.Values{"list-type": {"2"}, "max-keys": {strconv.Itoa(listPageSize)}}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if token != "" {
+		query.Set("continuation-token", token)
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, "", query, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(req, "List", "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var page listBucketResult
+	if err := xml.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("[objectStorage] 解析对象列表失败: %w", err)
+	}
+	return &page, nil
+}
+
+// HeadBucket 检查存储桶是否存在且有访问权限
+// 返回：
+//   - error: 存储桶不存在时 errors.Is(err, ErrBucketNotFound) 为 true，没有权限时 errors.Is(err, ErrAccessDenied) 为 true
+func (c *Client) HeadBucket(ctx context.Context) error {
+	req, err := c.newRequest(ctx, http.MethodHead, "", nil, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(req, "HeadBucket", "")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// objectURL 构造对象地址，key 为空时为存储桶地址
+// 路径风格为 endpoint/bucket/key，否则为 bucket.endpoint/key
+func (c *Client) objectURL(key string, query url.Values) *url.URL {
+	u := &url.URL{Scheme: c.scheme, Host: c.endpoint, Path: "/" + strings.TrimPrefix(key, "/")}
+	if c.pathStyle {
+		u.Path = "/" + c.bucket + u.Path
+	} else {
+		u.Host = c.bucket + "." + c.endpoint
+	}
+	u.RawPath = escape(u.Path, false)
+	if len(query) > 0 {
+		u.RawQuery = canonicalQuery(query)
+	}
+	return u
+}
+
+// newRequest 创建请求
+func (c *Client) newRequest(ctx context.Context, method, key string, query url.Values, body io.ReadCloser) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.objectURL(key, query).String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("[objectStorage] 创建请求失败: %w", err)
+	}
+	return req, nil
+}
+
+// do 签名并发送请求，非 2xx 响应解析为 *Error 并关闭响应体
+func (c *Client) do(req *http.Request, op, key string) (*http.Response, error) {
+	c.signer.signRequest(req, c.now().UTC())
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("[objectStorage] %s %q 请求失败: %w", op, key, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, parseError(op, key, resp)
+	}
+	return resp, nil
+}
@@ -0,0 +1,156 @@
+//go:build integration
+
+// Package objectstorage 对象存储客户端集成测试
+//
+// ==================== 集成测试说明 ====================
+// 本文件需要真实的 MinIO 服务才能运行，可通过以下命令启动：
+//
+//	docker run -d -p 9000:9000 minio/minio server /data
+//
+// 运行命令：go test -tags=integration ./objectstorage/...
+//
+// 测试覆盖内容：
+// 1. 创建测试存储桶后 HeadBucket 通过
+// 2. 上传、下载、列出、删除对象
+// 3. 通过预签名地址上传和下载对象
+// 4. 对象不存在、存储桶不存在、密钥错误时的错误映射
+//
+// 配置说明：
+// - 使用 MinIO 默认的 minioadmin / minioadmin 账号和 gin-core-test 存储桶
+// - 测试结束后自动删除测试对象
+// - 如果无法连接 MinIO，测试将失败（而非跳过）
+// ==================================================
+package objectstorage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// 硬编码的 MinIO 测试配置
+var integrationTestConfig = config.ObjectStorageConfig{
+	Enabled:   true,
+	Provider:  config.ObjectStorageMinIO,
+	Endpoint:  "localhost:9000",
+	Bucket:    "gin-core-test",
+	AccessKey: "minioadmin",
+	SecretKey: "minioadmin",
+}
+
+// newIntegrationClient 创建连接 MinIO 的客户端，测试存储桶不存在时创建
+func newIntegrationClient(t *testing.T) *Client {
+	client, err := New(integrationTestConfig)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	if err := client.HeadBucket(ctx); err != nil {
+		require.ErrorIs(t, err, ErrBucketNotFound)
+		req, err := client.newRequest(ctx, http.MethodPut, "", nil, http.NoBody)
+		require.NoError(t, err)
+		resp, err := client.do(req, "CreateBucket", "")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	return client
+}
+
+// TestIntegration_PutGetListDelete 测试对象的上传、下载、列出和删除
+//
+// 【功能点】验证客户端的签名被 MinIO 接受，各操作结果正确
+// 【测试流程】
+//  1. 上传 3 个对象，下载其中一个，断言内容、大小和内容类型
+//  2. 按前缀列出对象，断言返回 3 个对象
+//  3. 删除所有对象，断言下载返回 ErrNotFound
+func TestIntegration_PutGetListDelete(t *testing.T) {
+	client := newIntegrationClient(t)
+	ctx := context.Background()
+	prefix := "it-" + time.Now().Format("150405.000000") + "/"
+	keys := []string{prefix + "a.txt", prefix + "b 中文.txt", prefix + "c/d.txt"}
+	t.Cleanup(func() {
+		for _, key := range keys {
+			_ = client.Delete(ctx, key)
+		}
+	})
+
+	for _, key := range keys {
+		require.NoError(t, client.Put(ctx, key, strings.NewReader("content of "+key), -1, "text/plain"))
+	}
+	body, info, err := client.Get(ctx, keys[1])
+	require.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "content of "+keys[1], string(data))
+	assert.Equal(t, int64(len(data)), info.Size)
+	assert.Equal(t, "text/plain", info.ContentType)
+
+	var listed []string
+	for obj, err := range client.List(ctx, prefix) {
+		require.NoError(t, err)
+		listed = append(listed, obj.Key)
+	}
+	assert.ElementsMatch(t, keys, listed)
+
+	for _, key := range keys {
+		require.NoError(t, client.Delete(ctx, key))
+	}
+	_, _, err = client.Get(ctx, keys[0])
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// TestIntegration_Presign 测试预签名地址
+//
+// 【功能点】验证不携带密钥时可以通过预签名地址上传和下载对象
+// 【测试流程】通过 PresignPut 地址上传对象，再通过 PresignGet 地址下载，断言内容一致
+func TestIntegration_Presign(t *testing.T) {
+	client := newIntegrationClient(t)
+	ctx := context.Background()
+	key := "it-presign-" + time.Now().Format("150405.000000") + ".txt"
+	t.Cleanup(func() { _ = client.Delete(ctx, key) })
+
+	putURL, err := client.PresignPut(ctx, key, time.Minute)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPut, putURL, strings.NewReader("presigned"))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	getURL, err := client.PresignGet(ctx, key, time.Minute)
+	require.NoError(t, err)
+	resp, err = http.Get(getURL)
+	require.NoError(t, err)
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "presigned", string(data))
+}
+
+// TestIntegration_Errors 测试错误映射
+//
+// 【功能点】验证 MinIO 返回的错误被映射为预定义错误
+// 【测试流程】
+//  1. 存储桶不存在时 HeadBucket 断言 ErrBucketNotFound
+//  2. 密钥错误时 HeadBucket 和 Put 断言 ErrAccessDenied
+func TestIntegration_Errors(t *testing.T) {
+	ctx := context.Background()
+	cfg := integrationTestConfig
+	cfg.Bucket = "gin-core-missing"
+	client, err := New(cfg)
+	require.NoError(t, err)
+	assert.ErrorIs(t, client.HeadBucket(ctx), ErrBucketNotFound)
+
+	cfg = integrationTestConfig
+	cfg.SecretKey = "wrong-secret"
+	client, err = New(cfg)
+	require.NoError(t, err)
+	assert.ErrorIs(t, client.HeadBucket(ctx), ErrAccessDenied)
+	assert.ErrorIs(t, client.Put(ctx, "a.txt", strings.NewReader("a"), 1, ""), ErrAccessDenied)
+}
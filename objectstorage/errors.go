@@ -0,0 +1,82 @@
+// Package objectstorage 提供 S3 兼容对象存储的客户端
+// 本文件定义对象存储的错误类型
+package objectstorage
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// 预定义错误，可通过 errors.Is 判断 *Error 的类型
+var (
+	// ErrNotFound 对象不存在
+	ErrNotFound = errors.New("object not found")
+
+	// ErrBucketNotFound 存储桶不存在
+	ErrBucketNotFound = errors.New("bucket not found")
+
+	// ErrAccessDenied 没有访问权限，或访问密钥、签名无效
+	ErrAccessDenied = errors.New("access denied")
+)
+
+// Error 对象存储返回的错误响应
+type Error struct {
+	Op         string // 操作，如 Put、Get
+	Key        string // 对象键，存储桶操作时为空
+	StatusCode int    // HTTP 状态码
+	Code       string // 错误码，如 NoSuchKey、AccessDenied
+	Message    string // 错误信息
+	RequestID  string // 请求 ID，用于向服务商排查问题
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	return fmt.Sprintf("[objectStorage] %s %q 失败, status: %d, code: %s, message: %s, requestId: %s",
+		e.Op, e.Key, e.StatusCode, e.Code, e.Message, e.RequestID)
+}
+
+// Is 将错误码映射到 ErrNotFound、ErrBucketNotFound、ErrAccessDenied
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Code == "NoSuchKey"
+	case ErrBucketNotFound:
+		return e.Code == "NoSuchBucket"
+	case ErrAccessDenied:
+		return e.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+// parseError 解析错误响应
+// HEAD 请求的错误响应没有响应体，按状态码补全错误码
+func parseError(op, key string, resp *http.Response) error {
+	e := &Error{Op: op, Key: key, StatusCode: resp.StatusCode, RequestID: resp.Header.Get("X-Amz-Request-Id")}
+	var body struct {
+		Code      string `xml:"Code"`
+		Message   string `xml:"Message"`
+		RequestID string `xml:"RequestId"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body); err == nil {
+		e.Code, e.Message = body.Code, body.Message
+		if body.RequestID != "" {
+			e.RequestID = body.RequestID
+		}
+	}
+	if e.Code == "" {
+		switch {
+		case resp.StatusCode == http.StatusNotFound && key != "":
+			e.Code = "NoSuchKey"
+		case resp.StatusCode == http.StatusNotFound:
+			e.Code = "NoSuchBucket"
+		case resp.StatusCode == http.StatusForbidden:
+			e.Code = "AccessDenied"
+		default:
+			e.Code = http.StatusText(resp.StatusCode)
+		}
+	}
+	return e
+}
@@ -0,0 +1,443 @@
+// Package openapi 根据路由和请求、响应结构体生成 OpenAPI 3 文档
+//
+// 生成规则：
+//   - 路径：gin 的 :id、*path 转换为 {id}、{path}，路径中的参数自动生成为必填的路径参数
+//   - 请求参数：uri 标签为路径参数，header 标签为请求头，form 标签为查询参数；
+//     GET 等没有请求体的方法中，未声明 uri、header 标签的字段按 gin 的规则（form 标签或字段名）作为查询参数
+//   - 请求体：POST、PUT、PATCH 中未声明 uri、header、form 标签的字段按 json 标签组成请求体
+//   - 响应：data 的类型包装在统一响应结构 {code, data, msg} 中
+//   - 约束：binding 标签的 required、oneof、min、max 等映射为 required、enum、minimum、maximum 等，见 applyBinding
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// methodsWithBody 生成请求体的请求方法
+var methodsWithBody = map[string]bool{
+	http.MethodPost:  true,
+	http.MethodPut:   true,
+	http.MethodPatch: true,
+}
+
+// allowedMethods OpenAPI 支持的请求方法，其他方法（如 CONNECT）的路由不生成文档
+var allowedMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// pathParamPattern 匹配 OpenAPI 路径模板中的参数，如 {id}
+var pathParamPattern = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// New 创建空的 OpenAPI 文档
+// 参数：
+//   - info: 文档的基本信息
+//
+// 返回：
+//   - *Document: 通过 AddRoute 添加接口
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+		schemas: newSchemaGenerator(),
+	}
+}
+
+// SchemaOf 生成类型的 Schema，命名结构体保存到 components.schemas 并返回 $ref
+func (d *Document) SchemaOf(t reflect.Type) *Schema {
+	s := d.schemas.schemaOf(t)
+	d.syncComponents()
+	return s
+}
+
+// AddRoute 添加一个接口
+// 参数：
+//   - method: 请求方法，OpenAPI 不支持的方法（如 CONNECT）被忽略
+//   - path: gin 格式的路径，如 /users/:id
+//   - doc: 接口描述，为 nil 时只生成路径参数和默认的 200 响应
+func (d *Document) AddRoute(method, path string, doc *RouteDoc) {
+	method = strings.ToLower(method)
+	if !allowedMethods[method] {
+		return
+	}
+	if doc == nil {
+		doc = &RouteDoc{}
+	}
+	template, pathParams := convertPath(path)
+
+	op := &Operation{
+		Summary:     doc.Summary,
+		Tags:        doc.Tags,
+		OperationID: operationID(method, template),
+		Responses:   make(map[string]*Response),
+	}
+
+	declared := make(map[string]bool)
+	if doc.Request != nil {
+		params, body := d.requestParts(doc.Request, methodsWithBody[strings.ToUpper(method)])
+		for _, p := range params {
+			if p.In == "path" {
+				declared[p.Name] = true
+			}
+		}
+		op.Parameters = params
+		if body != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]*MediaType{"application/json": {Schema: body}},
+			}
+		}
+	}
+	// 路径中未通过 uri 标签声明的参数按字符串生成
+	var undeclared []*Parameter
+	for _, name := range pathParams {
+		if !declared[name] {
+			undeclared = append(undeclared, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	op.Parameters = append(undeclared, op.Parameters...)
+
+	responses := doc.Responses
+	if len(responses) == 0 {
+		responses = []ResponseDoc{{Status: http.StatusOK}}
+	}
+	for _, r := range responses {
+		desc := http.StatusText(r.Status)
+		if desc == "" {
+			desc = strconv.Itoa(r.Status)
+		}
+		op.Responses[strconv.Itoa(r.Status)] = &Response{
+			Description: desc,
+			Content:     map[string]*MediaType{"application/json": {Schema: envelope(d.schemas.schemaOf(r.Type))}},
+		}
+	}
+
+	item, ok := d.Paths[template]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[template] = item
+	}
+	(*item)[method] = op
+	d.syncComponents()
+}
+
+// syncComponents 将生成器中的组件写入文档
+func (d *Document) syncComponents() {
+	if len(d.schemas.components) == 0 {
+		return
+	}
+	if d.Components == nil {
+		d.Components = &Components{}
+	}
+	d.Components.Schemas = d.schemas.components
+}
+
+// envelope 将 data 的 Schema 包装在统一响应结构中，与 response.Response 的 JSON 字段一致
+func envelope(data *Schema) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code": {Type: "integer", Format: "int64", Description: "响应码"},
+			"data": data,
+			"msg":  {Type: "string", Description: "响应消息"},
+		},
+		Required: []string{"code", "data", "msg"},
+	}
+}
+
+// convertPath 将 gin 格式的路径转换为 OpenAPI 路径模板，返回模板和路径参数名称
+func convertPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, seg := range segments {
+		if len(seg) > 1 && (seg[0] == ':' || seg[0] == '*') {
+			params = append(params, seg[1:])
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	template := strings.Join(segments, "/")
+	if template == "" {
+		template = "/"
+	}
+	return template, params
+}
+
+// operationID 生成接口标识，如 get_api_users_id
+func operationID(method, template string) string {
+	id := invalidNameChars.ReplaceAllString(strings.NewReplacer("{", "", "}", "").Replace(template), "_")
+	return method + "_" + strings.Trim(id, "_")
+}
+
+// requestParts 将请求结构体拆分为参数和请求体
+// 没有 uri、header、form 标签的请求体方法直接引用整个结构体作为请求体
+//
+// 参数：
+//   - t: 请求结构体类型
+//   - hasBody: 请求方法是否有请求体
+//
+// 返回：
+//   - []*Parameter: 路径参数、请求头和查询参数
+//   - *Schema: 请求体，没有请求体字段时为 nil
+func (d *Document) requestParts(t reflect.Type, hasBody bool) ([]*Parameter, *Schema) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		if hasBody {
+			return nil, d.schemas.schemaOf(t)
+		}
+		return nil, nil
+	}
+	if hasBody && !hasParamTags(t) {
+		return nil, d.schemas.schemaOf(t)
+	}
+
+	var params []*Parameter
+	body := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	d.collectRequestFields(t, hasBody, &params, body)
+	if !hasBody || len(body.Properties) == 0 {
+		return params, nil
+	}
+	return params, body
+}
+
+// collectRequestFields 递归收集请求结构体的参数和请求体字段
+// 未声明任何来源标签、且包含参数字段的嵌套结构体展开到外层，与 gin 的绑定规则一致
+func (d *Document) collectRequestFields(t reflect.Type, hasBody bool, params *[]*Parameter, body *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		uri, header, form := tagName(field, "uri"), tagName(field, "header"), tagName(field, "form")
+		jsonName := tagName(field, "json")
+
+		switch {
+		case uri != "":
+			*params = append(*params, d.parameter(field, uri, "path"))
+			continue
+		case header != "":
+			*params = append(*params, d.parameter(field, header, "header"))
+			continue
+		case form != "":
+			*params = append(*params, d.parameter(field, form, "query"))
+			continue
+		case field.Tag.Get("form") == "-" || field.Tag.Get("json") == "-":
+			continue
+		}
+
+		nested := field.Type
+		if nested.Kind() == reflect.Ptr {
+			nested = nested.Elem()
+		}
+		if jsonName == "" && nested.Kind() == reflect.Struct && nested != timeType && hasParamTags(nested) {
+			d.collectRequestFields(nested, hasBody, params, body)
+			continue
+		}
+
+		if !hasBody {
+			// 没有请求体时 gin 按字段名从查询参数绑定未声明 form 标签的字段
+			*params = append(*params, d.parameter(field, field.Name, "query"))
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		prop, required := d.schemas.fieldSchema(field)
+		body.Properties[jsonName] = prop
+		if required {
+			body.Required = append(body.Required, jsonName)
+		}
+	}
+}
+
+// parameter 生成参数，路径参数始终必填
+func (d *Document) parameter(field reflect.StructField, name, in string) *Parameter {
+	s, required := d.schemas.fieldSchema(field)
+	return &Parameter{Name: name, In: in, Required: required || in == "path", Schema: s}
+}
+
+// hasParamTags 结构体（含未声明来源标签的嵌套结构体）是否包含 uri、header、form 标签的字段
+func hasParamTags(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if tagName(field, "uri") != "" || tagName(field, "header") != "" || tagName(field, "form") != "" {
+			return true
+		}
+		nested := field.Type
+		if nested.Kind() == reflect.Ptr {
+			nested = nested.Elem()
+		}
+		if tagName(field, "json") == "" && nested.Kind() == reflect.Struct && nested != timeType && hasParamTags(nested) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagName 获取标签中的名称（逗号前的部分），"-" 视为未声明
+func tagName(field reflect.StructField, tag string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// Validate 对文档做基本的结构校验，对应 OpenAPI 3.0 元模式中的必填字段和引用关系
+// 校验内容：
+//   - openapi 版本为 3.x，info.title 和 info.version 不为空
+//   - 路径以 / 开头，请求方法为 OpenAPI 支持的方法，每个接口至少有一个响应且响应描述不为空
+//   - 响应状态码为 100 ~ 599 或 default
+//   - 路径模板中的参数与 in 为 path 的参数一一对应且必填，参数位置有效，同一位置的参数不重复
+//   - 所有 $ref 都能在 components.schemas 中找到
+//
+// 返回值: 所有不符合的项合并为一个错误，符合时返回 nil
+func (d *Document) Validate() error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if !strings.HasPrefix(d.OpenAPI, "3.") {
+		add("openapi 版本应为 3.x，实际为 %q", d.OpenAPI)
+	}
+	if d.Info.Title == "" {
+		add("info.title 不能为空")
+	}
+	if d.Info.Version == "" {
+		add("info.version 不能为空")
+	}
+	if d.Paths == nil {
+		add("paths 不能为空")
+	}
+
+	var schemas []*Schema
+	paths := make([]string, 0, len(d.Paths))
+	for p := range d.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if !strings.HasPrefix(p, "/") {
+			add("路径 %s 应以 / 开头", p)
+		}
+		var templateParams []string
+		for _, m := range pathParamPattern.FindAllStringSubmatch(p, -1) {
+			templateParams = append(templateParams, m[1])
+		}
+		for method, op := range *d.Paths[p] {
+			where := strings.ToUpper(method) + " " + p
+			if !allowedMethods[method] {
+				add("%s: 不支持的请求方法", where)
+			}
+			if len(op.Responses) == 0 {
+				add("%s: responses 不能为空", where)
+			}
+			for status, r := range op.Responses {
+				if code, err := strconv.Atoi(status); status != "default" && (err != nil || code < 100 || code > 599) {
+					add("%s: 无效的响应状态码 %s", where, status)
+				}
+				if r.Description == "" {
+					add("%s: 响应 %s 的 description 不能为空", where, status)
+				}
+				for _, media := range r.Content {
+					schemas = append(schemas, media.Schema)
+				}
+			}
+			if op.RequestBody != nil {
+				if len(op.RequestBody.Content) == 0 {
+					add("%s: requestBody.content 不能为空", where)
+				}
+				for _, media := range op.RequestBody.Content {
+					schemas = append(schemas, media.Schema)
+				}
+			}
+			errs = append(errs, validateParameters(where, op.Parameters, templateParams)...)
+			for _, param := range op.Parameters {
+				schemas = append(schemas, param.Schema)
+			}
+		}
+	}
+
+	var components map[string]*Schema
+	if d.Components != nil {
+		components = d.Components.Schemas
+		for _, s := range components {
+			schemas = append(schemas, s)
+		}
+	}
+	for _, s := range schemas {
+		for _, ref := range collectRefs(s, nil) {
+			name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+			if _, exists := components[name]; !ok || !exists {
+				add("无法解析的引用 %s", ref)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateParameters 校验接口的参数
+func validateParameters(where string, params []*Parameter, templateParams []string) []error {
+	var errs []error
+	inTemplate := make(map[string]bool, len(templateParams))
+	for _, name := range templateParams {
+		inTemplate[name] = true
+	}
+	seen := make(map[string]bool)
+	for _, p := range params {
+		key := p.In + ":" + p.Name
+		if seen[key] {
+			errs = append(errs, fmt.Errorf("%s: 重复的参数 %s", where, key))
+		}
+		seen[key] = true
+		switch p.In {
+		case "path":
+			if !inTemplate[p.Name] {
+				errs = append(errs, fmt.Errorf("%s: 路径参数 %s 不在路径模板中", where, p.Name))
+			}
+			if !p.Required {
+				errs = append(errs, fmt.Errorf("%s: 路径参数 %s 必须为必填", where, p.Name))
+			}
+		case "query", "header", "cookie":
+		default:
+			errs = append(errs, fmt.Errorf("%s: 参数 %s 的位置 %q 无效", where, p.Name, p.In))
+		}
+		if p.Schema == nil {
+			errs = append(errs, fmt.Errorf("%s: 参数 %s 缺少 schema", where, p.Name))
+		}
+	}
+	for _, name := range templateParams {
+		if !seen["path:"+name] {
+			errs = append(errs, fmt.Errorf("%s: 路径模板中的参数 %s 未声明", where, name))
+		}
+	}
+	return errs
+}
+
+// collectRefs 递归收集 Schema 中的 $ref
+func collectRefs(s *Schema, refs []string) []string {
+	if s == nil {
+		return refs
+	}
+	if s.Ref != "" {
+		refs = append(refs, s.Ref)
+	}
+	for _, p := range s.Properties {
+		refs = collectRefs(p, refs)
+	}
+	refs = collectRefs(s.Items, refs)
+	return collectRefs(s.AdditionalProperties, refs)
+}
@@ -0,0 +1,238 @@
+// Package openapi OpenAPI 文档生成测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 OpenAPI 文档生成的单元测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 嵌套结构体生成为组件并通过 $ref 引用，递归类型不会无限展开
+// 2. binding 标签映射为 required、enum、minimum、maxLength 等约束，default 标签映射为 default
+// 3. uri、header、form 标签生成为路径参数、请求头和查询参数，其余字段生成为请求体
+// 4. 响应包装在统一响应结构 {code, data, msg} 中
+// 5. Validate 的结构校验：生成的文档通过校验，缺少必填字段、路径参数不匹配、无法解析的引用时返回错误
+//
+// 运行测试：go test -v ./openapi/...
+// ==================================================
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createOrderReq 混合参数和请求体的测试请求
+type createOrderReq struct {
+	ShopID   string    `uri:"shopId" binding:"required,uuid4"`
+	TenantID string    `header:"X-Tenant-Id" binding:"required"`
+	DryRun   bool      `form:"dryRun"`
+	Status   string    `json:"status" binding:"required,oneof=pending paid"`
+	Amount   int       `json:"amount" binding:"min=1,max=10000"`
+	Remark   string    `json:"remark,omitempty" binding:"omitempty,max=200"`
+	Address  address   `json:"address" binding:"required"`
+	Items    []item    `json:"items" binding:"required,min=1,dive"`
+	PaidAt   time.Time `json:"paidAt"`
+	internal string
+}
+
+// address 嵌套结构体
+type address struct {
+	City   string   `json:"city" binding:"required"`
+	Detail string   `json:"detail"`
+	Parent *address `json:"parent,omitempty"`
+}
+
+// item 切片元素结构体
+type item struct {
+	SKU      string `json:"sku" binding:"required"`
+	Quantity int    `json:"quantity" binding:"gt=0"`
+}
+
+// listOrderReq 只有查询参数的测试请求
+type listOrderReq struct {
+	Status   string     `form:"status" default:"pending" binding:"oneof=pending paid"`
+	Tags     []string   `form:"tag"`
+	Since    *time.Time `form:"since" time_format:"2006-01-02"`
+	PageSize int        `form:"pageSize" default:"20" binding:"max=100"`
+}
+
+// orderResp 响应结构体
+type orderResp struct {
+	ID      int64    `json:"id"`
+	Address *address `json:"address"`
+}
+
+// toMap 将值序列化为 JSON 后解析为 map，便于按 JSON 结构断言
+func toMap(t *testing.T, v any) map[string]any {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(data, &m))
+	return m
+}
+
+// TestSchemaOf_NestedStruct 测试嵌套结构体的 Schema
+//
+// 【功能点】验证命名结构体生成为组件，字段按 json 标签命名，binding 标签映射为约束，递归引用生成 $ref
+// 【测试流程】
+//  1. 生成 createOrderReq 的 Schema，断言返回 $ref
+//  2. 断言组件中 status 的 enum、amount 的取值范围、items 的 minItems 和元素引用、paidAt 的格式
+//  3. 断言 required 数组只包含声明了 required 的字段，未声明 json 标签的字段使用字段名，未导出字段不出现
+//  4. 断言 address 组件的 parent 字段引用自身
+func TestSchemaOf_NestedStruct(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "v1"})
+	s := doc.SchemaOf(reflect.TypeOf(createOrderReq{}))
+	assert.Equal(t, "#/components/schemas/createOrderReq", s.Ref)
+
+	components := toMap(t, doc.Components)["schemas"].(map[string]any)
+	order := components["createOrderReq"].(map[string]any)
+	props := order["properties"].(map[string]any)
+
+	assert.Equal(t, map[string]any{"type": "string", "enum": []any{"pending", "paid"}}, props["status"])
+	assert.Equal(t, map[string]any{"type": "integer", "format": "int64", "minimum": float64(1), "maximum": float64(10000)}, props["amount"])
+	assert.Equal(t, map[string]any{"type": "string", "maxLength": float64(200)}, props["remark"])
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/address"}, props["address"])
+	assert.Equal(t, map[string]any{
+		"type":     "array",
+		"minItems": float64(1),
+		"items":    map[string]any{"$ref": "#/components/schemas/item"},
+	}, props["items"])
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, props["paidAt"])
+	assert.NotContains(t, props, "internal")
+	// SchemaOf 按 encoding/json 的规则生成，未声明 json 标签的字段使用字段名
+	assert.Equal(t, []any{"ShopID", "TenantID", "status", "address", "items"}, order["required"])
+
+	addr := components["address"].(map[string]any)
+	assert.Equal(t, []any{"city"}, addr["required"])
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/address"}, addr["properties"].(map[string]any)["parent"])
+
+	quantity := components["item"].(map[string]any)["properties"].(map[string]any)["quantity"].(map[string]any)
+	assert.Equal(t, float64(0), quantity["minimum"])
+	assert.Equal(t, true, quantity["exclusiveMinimum"])
+}
+
+// TestAddRoute_RequestParts 测试请求参数和请求体的拆分
+//
+// 【功能点】验证 uri、header、form 标签生成为参数，其余字段生成为请求体，路径转换为 OpenAPI 模板
+// 【测试流程】
+//  1. 添加 POST /shops/:shopId/orders，断言路径为 /shops/{shopId}/orders
+//  2. 断言 shopId 为必填的 uuid 路径参数，X-Tenant-Id 为必填请求头，dryRun 为查询参数
+//  3. 断言请求体为内联对象，包含 status、address 等字段，不包含参数字段
+func TestAddRoute_RequestParts(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "v1"})
+	doc.AddRoute(http.MethodPost, "/shops/:shopId/orders", &RouteDoc{Request: reflect.TypeOf(createOrderReq{})})
+
+	op := (*doc.Paths["/shops/{shopId}/orders"])["post"]
+	require.NotNil(t, op)
+	assert.Equal(t, "post_shops_shopId_orders", op.OperationID)
+
+	params := toMap(t, map[string]any{"p": op.Parameters})["p"].([]any)
+	assert.Equal(t, []any{
+		map[string]any{"name": "shopId", "in": "path", "required": true, "schema": map[string]any{"type": "string", "format": "uuid"}},
+		map[string]any{"name": "X-Tenant-Id", "in": "header", "required": true, "schema": map[string]any{"type": "string"}},
+		map[string]any{"name": "dryRun", "in": "query", "schema": map[string]any{"type": "boolean"}},
+	}, params)
+
+	require.NotNil(t, op.RequestBody)
+	body := op.RequestBody.Content["application/json"].Schema
+	assert.Equal(t, "object", body.Type)
+	assert.Contains(t, body.Properties, "status")
+	assert.Contains(t, body.Properties, "address")
+	assert.NotContains(t, body.Properties, "ShopID")
+	assert.Equal(t, []string{"status", "address", "items"}, body.Required)
+}
+
+// TestAddRoute_QueryAndResponse 测试查询参数和统一响应结构
+//
+// 【功能点】验证 GET 请求的查询参数包含 enum、default、切片类型，响应包装在统一响应结构中，未描述的路由生成默认响应
+// 【测试流程】
+//  1. 添加 GET /orders，请求为 listOrderReq，响应为 []orderResp
+//  2. 断言 status 参数的 enum 和 default、tag 参数为数组、since 格式为 date、没有请求体
+//  3. 断言 200 响应为 {code, data, msg}，data 为 orderResp 数组
+//  4. 添加未描述的 GET /orders/:id，断言生成路径参数和 data 为任意值的 200 响应
+func TestAddRoute_QueryAndResponse(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "v1"})
+	doc.AddRoute(http.MethodGet, "/orders", &RouteDoc{
+		Summary:   "订单列表",
+		Tags:      []string{"订单"},
+		Request:   reflect.TypeOf(listOrderReq{}),
+		Responses: []ResponseDoc{{Status: http.StatusOK, Type: reflect.TypeOf([]orderResp{})}},
+	})
+
+	op := (*doc.Paths["/orders"])["get"]
+	require.NotNil(t, op)
+	assert.Equal(t, "订单列表", op.Summary)
+	assert.Equal(t, []string{"订单"}, op.Tags)
+	assert.Nil(t, op.RequestBody)
+
+	params := make(map[string]*Parameter)
+	for _, p := range op.Parameters {
+		assert.Equal(t, "query", p.In)
+		params[p.Name] = p
+	}
+	assert.Equal(t, []any{"pending", "paid"}, params["status"].Schema.Enum)
+	assert.Equal(t, "pending", params["status"].Schema.Default)
+	assert.Equal(t, int64(20), params["pageSize"].Schema.Default)
+	assert.Equal(t, "array", params["tag"].Schema.Type)
+	assert.Equal(t, "string", params["tag"].Schema.Items.Type)
+	assert.Equal(t, "date", params["since"].Schema.Format)
+
+	resp := toMap(t, op.Responses["200"])
+	assert.Equal(t, "OK", resp["description"])
+	schema := resp["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+	assert.Equal(t, []any{"code", "data", "msg"}, schema["required"])
+	assert.Equal(t, map[string]any{
+		"type":  "array",
+		"items": map[string]any{"$ref": "#/components/schemas/orderResp"},
+	}, schema["properties"].(map[string]any)["data"])
+
+	doc.AddRoute(http.MethodGet, "/orders/:id", nil)
+	detail := (*doc.Paths["/orders/{id}"])["get"]
+	require.Len(t, detail.Parameters, 1)
+	assert.Equal(t, &Parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}}, detail.Parameters[0])
+	assert.Equal(t, &Schema{}, detail.Responses["200"].Content["application/json"].Schema.Properties["data"])
+}
+
+// TestDocument_Validate 测试文档的结构校验
+//
+// 【功能点】验证生成的文档通过校验，不符合 OpenAPI 结构要求时返回错误
+// 【测试流程】
+//  1. 添加多个接口后序列化再反序列化，断言 Validate 通过，顶层字段符合 OpenAPI 3.0
+//  2. 清空 info.title、删除组件、添加未声明的路径参数，断言 Validate 返回对应错误
+func TestDocument_Validate(t *testing.T) {
+	doc := New(Info{Title: "test", Version: "v1"})
+	doc.AddRoute(http.MethodPost, "/shops/:shopId/orders", &RouteDoc{
+		Request:   reflect.TypeOf(createOrderReq{}),
+		Responses: []ResponseDoc{{Status: http.StatusOK, Type: reflect.TypeOf(orderResp{})}, {Status: http.StatusBadRequest}},
+	})
+	doc.AddRoute(http.MethodGet, "/orders", &RouteDoc{Request: reflect.TypeOf(listOrderReq{})})
+	doc.AddRoute(http.MethodGet, "/static/*filepath", nil)
+	doc.AddRoute("CONNECT", "/orders", nil)
+	require.NoError(t, doc.Validate())
+
+	m := toMap(t, doc)
+	assert.Equal(t, "3.0.3", m["openapi"])
+	assert.Equal(t, map[string]any{"title": "test", "version": "v1"}, m["info"])
+	assert.NotContains(t, m["paths"].(map[string]any)["/orders"], "connect")
+	assert.Contains(t, m["paths"], "/static/{filepath}")
+
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	var decoded Document
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.NoError(t, decoded.Validate())
+
+	decoded.Info.Title = ""
+	decoded.Components = nil
+	(*decoded.Paths["/orders"])["get"].Parameters = append((*decoded.Paths["/orders"])["get"].Parameters,
+		&Parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	err = decoded.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "info.title 不能为空")
+	assert.Contains(t, err.Error(), "无法解析的引用 #/components/schemas/orderResp")
+	assert.Contains(t, err.Error(), "路径参数 id 不在路径模板中")
+}
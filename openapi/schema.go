@@ -0,0 +1,297 @@
+// Package openapi 根据路由和请求、响应结构体生成 OpenAPI 3 文档
+// 本文件通过反射生成结构体的 Schema，并将 binding、default 标签映射为 Schema 约束
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// invalidNameChars 组件名称中不允许的字符，OpenAPI 要求组件名称匹配 ^[a-zA-Z0-9.\-_]+$
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// schemaGenerator Schema 生成器，命名结构体生成为组件并通过 $ref 引用，支持递归类型
+type schemaGenerator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+// newSchemaGenerator 创建 Schema 生成器
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// schemaOf 生成类型的 Schema
+// 命名结构体返回 $ref，匿名结构体内联展开；指针按指向的类型处理；time.Time 为 date-time 字符串
+func (g *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+			g.fillStruct(s, t)
+			return s
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.component(t)}
+	}
+	// interface 等无法确定结构的类型允许任意值
+	return &Schema{}
+}
+
+// component 获取命名结构体的组件名称，首次遇到时生成组件
+// 先登记名称再展开字段，递归引用自身的结构体生成 $ref 而不会无限展开
+func (g *schemaGenerator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := g.uniqueName(t)
+	g.names[t] = name
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.components[name] = s
+	g.fillStruct(s, t)
+	return name
+}
+
+// uniqueName 生成组件名称：默认为类型名，与其他包的同名类型冲突时加包名前缀，仍冲突时加序号
+func (g *schemaGenerator) uniqueName(t reflect.Type) string {
+	name := invalidNameChars.ReplaceAllString(t.Name(), "_")
+	if _, taken := g.components[name]; !taken {
+		return name
+	}
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	name = invalidNameChars.ReplaceAllString(pkg, "_") + "." + name
+	for i, candidate := 2, name; ; i++ {
+		if _, taken := g.components[candidate]; !taken {
+			return candidate
+		}
+		candidate = name + strconv.Itoa(i)
+	}
+}
+
+// fillStruct 将结构体的字段写入对象 Schema 的 properties 和 required
+// 字段名称与 encoding/json 一致：使用 json 标签，未声明时使用字段名，"-" 表示忽略；未声明 json 标签的嵌入结构体展开到外层
+func (g *schemaGenerator) fillStruct(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.fillStruct(s, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		prop, required := g.fieldSchema(field)
+		s.Properties[name] = prop
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// fieldSchema 生成字段的 Schema，并按 binding、default、time_format 标签补充约束
+//
+// 返回：
+//   - *Schema: 字段的 Schema
+//   - bool: binding 标签是否包含 required
+func (g *schemaGenerator) fieldSchema(field reflect.StructField) (*Schema, bool) {
+	s := g.schemaOf(field.Type)
+	required := applyBinding(s, field.Type, field.Tag.Get("binding"))
+	if s.Ref != "" {
+		// OpenAPI 3.0 中 $ref 的同级字段会被忽略，引用类型只保留是否必填
+		return s, required
+	}
+	if layout := field.Tag.Get("time_format"); layout == "2006-01-02" && s.Format == "date-time" {
+		s.Format = "date"
+	}
+	if def, ok := field.Tag.Lookup("default"); ok {
+		s.Default = parseDefault(s, def)
+	}
+	return s, required
+}
+
+// applyBinding 将 binding 标签中的校验规则映射为 Schema 约束，dive 之后的规则作用于元素，不再处理
+// 支持的规则：
+//   - required: 必填
+//   - oneof: 枚举值
+//   - min / max / len / gte / lte / gt / lt: 数值为取值范围，字符串为长度范围，切片为元素个数范围
+//   - email / url / uri / uuid / uuid4: 字符串格式
+//
+// 返回值: 是否包含 required
+func applyBinding(s *Schema, t reflect.Type, tag string) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if name == "dive" {
+			break
+		}
+		if name == "required" {
+			required = true
+			continue
+		}
+		if s.Ref != "" {
+			continue
+		}
+		switch name {
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, parseValue(s.Type, v))
+			}
+		case "min", "gte", "gt":
+			setBound(s, t, param, true, name == "gt")
+		case "max", "lte", "lt":
+			setBound(s, t, param, false, name == "lt")
+		case "len":
+			setBound(s, t, param, true, false)
+			setBound(s, t, param, false, false)
+		case "email":
+			s.Format = "email"
+		case "url", "uri":
+			s.Format = "uri"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		}
+	}
+	return required
+}
+
+// setBound 设置取值范围或长度范围
+// 参数：
+//   - s: 字段的 Schema
+//   - t: 字段类型（已去除指针）
+//   - param: 规则参数
+//   - lower: 是否为下限
+//   - exclusive: 是否不包含边界（gt / lt），只对数值生效，字符串和切片按整数长度换算
+func setBound(s *Schema, t reflect.Type, param string, lower, exclusive bool) {
+	switch s.Type {
+	case "integer", "number":
+		v, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		if lower {
+			s.Minimum, s.ExclusiveMinimum = &v, exclusive
+		} else {
+			s.Maximum, s.ExclusiveMaximum = &v, exclusive
+		}
+	case "string", "array":
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		if exclusive && lower {
+			n++
+		} else if exclusive {
+			n--
+		}
+		switch {
+		case s.Type == "array" && lower:
+			s.MinItems = &n
+		case s.Type == "array":
+			s.MaxItems = &n
+		case t == timeType:
+			// time.Time 的 min / max 不是长度约束
+		case lower:
+			s.MinLength = &n
+		default:
+			s.MaxLength = &n
+		}
+	}
+}
+
+// parseDefault 将 default 标签的值按 Schema 类型转换，切片按逗号分隔
+func parseDefault(s *Schema, def string) any {
+	if s.Type == "array" && s.Items != nil {
+		parts := strings.Split(def, ",")
+		values := make([]any, 0, len(parts))
+		for _, part := range parts {
+			values = append(values, parseValue(s.Items.Type, strings.TrimSpace(part)))
+		}
+		return values
+	}
+	return parseValue(s.Type, def)
+}
+
+// parseValue 将字符串按 Schema 类型转换为对应的 JSON 值，转换失败时保留字符串
+func parseValue(schemaType, v string) any {
+	switch schemaType {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
@@ -0,0 +1,105 @@
+// Package openapi 根据路由和请求、响应结构体生成 OpenAPI 3 文档
+// 本文件定义 OpenAPI 3.0 文档的数据结构，只包含框架生成文档时用到的字段
+package openapi
+
+import "reflect"
+
+// Version 生成的文档遵循的 OpenAPI 版本
+const Version = "3.0.3"
+
+// Document OpenAPI 文档
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components *Components          `json:"components,omitempty"`
+
+	schemas *schemaGenerator
+}
+
+// Info 文档的基本信息
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem 单个路径下的操作，键为小写的请求方法，如 get、post
+type PathItem map[string]*Operation
+
+// Operation 单个接口（请求方法 + 路径）
+type Operation struct {
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationID string               `json:"operationId,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter 路径参数、查询参数或请求头
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"` // path、query、header
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody 请求体
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response 响应
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType 请求体或响应的内容
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components 可复用的组件，命名结构体的 Schema 保存在 Schemas 中，通过 $ref 引用
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Schema 数据结构描述
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+// RouteDoc 接口描述，由 core.DescribeRoute 的选项填充
+type RouteDoc struct {
+	Summary   string        // 接口摘要
+	Tags      []string      // 接口分组标签
+	Request   reflect.Type  // 请求结构体类型，为 nil 时只生成路径参数
+	Responses []ResponseDoc // 响应，为空时生成 200 响应，data 为任意值
+}
+
+// ResponseDoc 响应描述
+type ResponseDoc struct {
+	Status int          // HTTP 状态码
+	Type   reflect.Type // data 字段的类型，为 nil 时 data 为任意值
+}
@@ -0,0 +1,46 @@
+// Package openapi 根据路由和请求、响应结构体生成 OpenAPI 3 文档
+// 本文件提供加载 OpenAPI 文档的 Swagger UI 页面
+package openapi
+
+import (
+	"encoding/json"
+	"html"
+	"strings"
+)
+
+// swaggerUITemplate Swagger UI 页面模板，静态资源从 {{assets}} 加载
+const swaggerUITemplate = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{title}}</title>
+  <link rel="stylesheet" href="{{assets}}/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="{{assets}}/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: {{specURL}}, dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// SwaggerUIHTML 生成 Swagger UI 页面
+// 参数：
+//   - title: 页面标题
+//   - specURL: OpenAPI 文档的地址，如 /api/openapi.json
+//   - assetsURL: swagger-ui-dist 静态资源的地址，如 https://unpkg.com/swagger-ui-dist@5
+//
+// 返回：
+//   - []byte: HTML 页面
+func SwaggerUIHTML(title, specURL, assetsURL string) []byte {
+	// json.Marshal 转义 <、>、&，可以安全地嵌入 <script>
+	url, _ := json.Marshal(specURL)
+	return []byte(strings.NewReplacer(
+		"{{title}}", html.EscapeString(title),
+		"{{assets}}", html.EscapeString(strings.TrimSuffix(assetsURL, "/")),
+		"{{specURL}}", string(url),
+	).Replace(swaggerUITemplate))
+}
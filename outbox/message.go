@@ -0,0 +1,77 @@
+// Package outbox 提供发件箱模式（Transactional Outbox）的可靠消息发布功能
+// 业务在数据库事务中写入待发送消息，由后台中继协程轮询并投递到消息队列，
+// 保证"数据库提交成功则消息最终一定被发送"（至少一次投递）
+package outbox
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 发件箱消息状态
+const (
+	StatusPending    = "pending"    // 待发送（含等待重试）
+	StatusProcessing = "processing" // 已被中继协程领取，正在发送
+	StatusSent       = "sent"       // 已发送
+	StatusDead       = "dead"       // 超过最大重试次数，不再发送
+)
+
+// TableName 发件箱消息表名
+const TableName = "outbox_messages"
+
+// Message 发件箱消息
+type Message struct {
+	ID           uint64     `gorm:"primaryKey;autoIncrement"`
+	MQName       string     `gorm:"size:64"`                                                  // 消息队列配置名称，为空时使用默认配置
+	Exchange     string     `gorm:"size:255;not null"`                                        // 交换机名称
+	ExchangeType string     `gorm:"size:32;not null"`                                         // 交换机类型，为空时默认 direct
+	RoutingKey   string     `gorm:"size:255"`                                                 // 路由键
+	QueueName    string     `gorm:"size:255"`                                                 // 队列名称，仅用于区分生产者
+	Body         string     `gorm:"type:text;not null"`                                       // 消息内容
+	Status       string     `gorm:"size:16;not null;index:idx_outbox_status_next,priority:1"` // 消息状态
+	Retries      int        `gorm:"not null;default:0"`                                       // 已失败次数
+	LastError    string     `gorm:"size:1024"`                                                // 最近一次发送失败的错误信息
+	NextRetryAt  time.Time  `gorm:"not null;index:idx_outbox_status_next,priority:2"`         // 下次可发送时间
+	SentAt       *time.Time // 发送成功时间
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// TableName 指定 GORM 表名
+func (Message) TableName() string {
+	return TableName
+}
+
+// AutoMigrate 自动创建或更新发件箱消息表
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Message{})
+}
+
+// Insert 在调用方的事务中写入一条待发送消息
+// 消息与业务数据在同一事务中提交或回滚，事务提交后由中继协程负责投递
+// 参数：
+//   - tx: 调用方的事务（也可以是普通的 *gorm.DB，此时写入立即生效）
+//   - msg: 待发送消息，只需设置 MQName、Exchange、ExchangeType、RoutingKey、QueueName、Body
+//
+// 返回：
+//   - error: 参数非法或写入失败时返回错误
+func Insert(tx *gorm.DB, msg *Message) error {
+	if tx == nil {
+		return errors.New("【发件箱】事务不能为空")
+	}
+	if msg.Exchange == "" {
+		return errors.New("【发件箱】交换机名称不能为空")
+	}
+	if msg.ExchangeType == "" {
+		msg.ExchangeType = "direct"
+	}
+	msg.ID = 0
+	msg.Status = StatusPending
+	msg.Retries = 0
+	msg.LastError = ""
+	msg.SentAt = nil
+	msg.NextRetryAt = time.Now()
+	return tx.Create(msg).Error
+}
@@ -0,0 +1,279 @@
+// Package outbox 提供发件箱模式（Transactional Outbox）的可靠消息发布功能
+// 本文件实现后台中继：轮询待发送消息、投递并更新状态
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zzsen/gin_core/logger"
+	"gorm.io/gorm"
+)
+
+// Publisher 消息发布者
+// Publish 返回 nil 表示消息已被消息队列确认接收
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// ErrDeferred 暂缓投递：消息未发送，但不是投递失败（如发布被限流）
+// Publisher 返回包装了 ErrDeferred 的错误时，消息在 BaseBackoff 后重新投递，不计入失败次数
+var ErrDeferred = errors.New("暂缓投递")
+
+// Options 中继配置
+type Options struct {
+	// PollInterval 轮询间隔，默认 1s
+	PollInterval time.Duration
+	// BatchSize 每次轮询处理的最大消息数，默认 100
+	BatchSize int
+	// MaxRetries 最大失败次数，达到后消息标记为 dead，默认 10
+	MaxRetries int
+	// BaseBackoff 首次重试的等待时间，之后每次翻倍，默认 1s
+	BaseBackoff time.Duration
+	// MaxBackoff 重试等待时间上限，默认 5min
+	MaxBackoff time.Duration
+	// LeaseTimeout 消息被领取后的租约时长，中继异常退出时超过该时长的消息会被重新领取，默认 1min
+	LeaseTimeout time.Duration
+}
+
+// withDefaults 补全未配置的选项
+func (o Options) withDefaults() Options {
+	if o.PollInterval <= 0 {
+		o.PollInterval = time.Second
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.MaxRetries <= 0 {
+		o.MaxRetries = 10
+	}
+	if o.BaseBackoff <= 0 {
+		o.BaseBackoff = time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 5 * time.Minute
+	}
+	if o.LeaseTimeout <= 0 {
+		o.LeaseTimeout = time.Minute
+	}
+	return o
+}
+
+// Relay 发件箱中继
+// 定期从发件箱表中领取到期的待发送消息并投递，投递成功标记为 sent，
+// 失败时按指数退避安排重试，超过最大重试次数标记为 dead。
+//
+// 多个实例可同时运行中继：消息通过条件更新领取，同一时刻只会被一个中继发送。
+// 投递语义为至少一次，消费者需要保证幂等。
+type Relay struct {
+	db        *gorm.DB
+	publisher Publisher
+	opts      Options
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRelay 创建发件箱中继
+// 参数：
+//   - db: 发件箱表所在的数据库
+//   - publisher: 消息发布者
+//   - opts: 中继配置
+//
+// 返回：
+//   - *Relay: 中继实例，需调用 Start 启动
+func NewRelay(db *gorm.DB, publisher Publisher, opts Options) *Relay {
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		opts:      opts.withDefaults(),
+	}
+}
+
+// Start 启动后台轮询协程，重复调用无效
+func (r *Relay) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go r.run(ctx, r.done)
+}
+
+// Stop 停止后台轮询协程，等待正在处理的批次完成
+func (r *Relay) Stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// run 轮询循环，一批消息处理满时立即处理下一批
+func (r *Relay) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(r.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := r.ProcessBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("[发件箱] 处理待发送消息失败: %v", err)
+		}
+		if n >= r.opts.BatchSize && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch 处理一批到期的待发送消息
+// 通常由后台协程调用，也可在测试或手动补偿时直接调用
+//
+// 返回：
+//   - int: 本批次领取的消息数量
+//   - error: 查询发件箱表失败时返回错误，单条消息发送失败不会返回错误
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	var messages []Message
+	err := r.db.WithContext(ctx).
+		Where("status IN ? AND next_retry_at <= ?", []string{StatusPending, StatusProcessing}, time.Now()).
+		Order("id").
+		Limit(r.opts.BatchSize).
+		Find(&messages).Error
+	if err != nil {
+		return 0, fmt.Errorf("查询待发送消息失败: %w", err)
+	}
+
+	claimed := 0
+	for i := range messages {
+		if ctx.Err() != nil {
+			return claimed, ctx.Err()
+		}
+		msg := &messages[i]
+		ok, err := r.claim(ctx, msg)
+		if err != nil {
+			return claimed, err
+		}
+		if !ok {
+			continue
+		}
+		claimed++
+		r.deliver(ctx, msg)
+	}
+	return claimed, nil
+}
+
+// claim 领取消息
+// 通过条件更新将 next_retry_at 推迟一个租约时长，更新成功才视为领取成功，避免多个中继重复发送
+func (r *Relay) claim(ctx context.Context, msg *Message) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&Message{}).
+		Where("id = ? AND status IN ? AND next_retry_at <= ?", msg.ID, []string{StatusPending, StatusProcessing}, now).
+		Updates(map[string]any{
+			"status":        StatusProcessing,
+			"next_retry_at": now.Add(r.opts.LeaseTimeout),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("领取消息失败, id: %d, error: %w", msg.ID, result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// deliver 投递单条消息并记录结果
+func (r *Relay) deliver(ctx context.Context, msg *Message) {
+	publishErr := r.publisher.Publish(ctx, msg)
+	// 停止中继导致的失败不计入重试次数，租约到期后会被重新领取
+	if publishErr != nil && errors.Is(publishErr, context.Canceled) && ctx.Err() != nil {
+		return
+	}
+
+	// 使用独立的上下文记录结果，避免停止中继时已发送的消息无法标记
+	db := r.db.WithContext(context.WithoutCancel(ctx)).Model(&Message{}).Where("id = ?", msg.ID)
+	now := time.Now()
+
+	if publishErr == nil {
+		if err := db.Updates(map[string]any{"status": StatusSent, "sent_at": now, "last_error": ""}).Error; err != nil {
+			logger.Error("[发件箱] 标记消息已发送失败, id: %d, error: %v", msg.ID, err)
+		}
+		return
+	}
+
+	// 暂缓投递不计入失败次数，BaseBackoff 后重新投递
+	if errors.Is(publishErr, ErrDeferred) {
+		logger.Warn("[发件箱] 消息暂缓投递, id: %d, %s 后重新投递, error: %v", msg.ID, r.opts.BaseBackoff, publishErr)
+		if err := db.Updates(map[string]any{
+			"status":        StatusPending,
+			"next_retry_at": now.Add(r.opts.BaseBackoff),
+			"last_error":    truncateError(publishErr.Error()),
+		}).Error; err != nil {
+			logger.Error("[发件箱] 记录消息暂缓投递状态出错, id: %d, error: %v", msg.ID, err)
+		}
+		return
+	}
+
+	retries := msg.Retries + 1
+	updates := map[string]any{
+		"retries":    retries,
+		"last_error": truncateError(publishErr.Error()),
+	}
+	if retries >= r.opts.MaxRetries {
+		updates["status"] = StatusDead
+		logger.Error("[发件箱] 消息发送失败且超过最大重试次数, 标记为 dead, id: %d, exchange: %s, routingKey: %s, error: %v",
+			msg.ID, msg.Exchange, msg.RoutingKey, publishErr)
+	} else {
+		backoff := r.backoff(retries)
+		updates["status"] = StatusPending
+		updates["next_retry_at"] = now.Add(backoff)
+		logger.Warn("[发件箱] 消息发送失败, id: %d, 第 %d 次失败, %s 后重试, error: %v", msg.ID, retries, backoff, publishErr)
+	}
+	if err := db.Updates(updates).Error; err != nil {
+		logger.Error("[发件箱] 记录消息发送失败状态出错, id: %d, error: %v", msg.ID, err)
+	}
+}
+
+// backoff 计算第 retries 次失败后的重试等待时间：BaseBackoff * 2^(retries-1)，不超过 MaxBackoff
+func (r *Relay) backoff(retries int) time.Duration {
+	d := r.opts.BaseBackoff
+	for i := 1; i < retries; i++ {
+		d *= 2
+		if d >= r.opts.MaxBackoff {
+			return r.opts.MaxBackoff
+		}
+	}
+	if d > r.opts.MaxBackoff {
+		return r.opts.MaxBackoff
+	}
+	return d
+}
+
+// truncateError 截断错误信息，避免超过 last_error 字段长度
+func truncateError(s string) string {
+	const maxLen = 1024
+	if len(s) <= maxLen {
+		return s
+	}
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
@@ -0,0 +1,340 @@
+// Package outbox 发件箱测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含发件箱写入与中继投递的单元测试，使用 SQLite 内存数据库和模拟发布者，不需要 MySQL / RabbitMQ。
+//
+// 测试覆盖内容：
+// 1. 事务提交后中继投递消息并标记为 sent
+// 2. 事务回滚时不写入消息，中继不发布
+// 3. 发送失败按指数退避重试，超过最大重试次数标记为 dead
+// 4. 重试等待时间的上限
+// 5. 多个中继并发处理时同一消息只发送一次
+// 6. 后台协程的启动与停止
+// 7. 暂缓投递（ErrDeferred）不计入失败次数
+//
+// 运行测试：go test -v ./outbox/...
+// ==================================================
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// fakePublisher 模拟发布者，记录已发布的消息，可配置失败
+type fakePublisher struct {
+	mu        sync.Mutex
+	published []Message
+	failures  atomic.Int32 // 剩余失败次数，<0 表示一直失败
+	err       error        // 失败时返回的错误，为 nil 时返回 "broker unavailable"
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, msg *Message) error {
+	if n := p.failures.Load(); n != 0 {
+		if n > 0 {
+			p.failures.Add(-1)
+		}
+		if p.err != nil {
+			return p.err
+		}
+		return errors.New("broker unavailable")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.published = append(p.published, *msg)
+	return nil
+}
+
+func (p *fakePublisher) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.published)
+}
+
+// newTestDB 创建 SQLite 内存数据库并建表
+func newTestDB(t *testing.T) *gorm.DB {
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared&_busy_timeout=5000", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	require.NoError(t, AutoMigrate(db))
+	return db
+}
+
+// order 测试用业务表
+type order struct {
+	ID   uint64 `gorm:"primaryKey"`
+	Name string
+}
+
+// loadMessage 读取指定 ID 的消息
+func loadMessage(t *testing.T, db *gorm.DB, id uint64) Message {
+	var msg Message
+	require.NoError(t, db.First(&msg, id).Error)
+	return msg
+}
+
+// TestRelay_CommitThenRelay 测试事务提交后中继投递
+//
+// 【功能点】验证事务中写入的消息在提交后被中继发布并标记为 sent
+// 【测试流程】
+//  1. 在同一事务中写入业务数据和发件箱消息并提交
+//  2. 调用 ProcessBatch，断言消息被发布、状态为 sent、SentAt 已设置
+//  3. 再次调用 ProcessBatch 不会重复发布
+func TestRelay_CommitThenRelay(t *testing.T) {
+	db := newTestDB(t)
+	require.NoError(t, db.AutoMigrate(&order{}))
+
+	var msg Message
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&order{Name: "o-1"}).Error; err != nil {
+			return err
+		}
+		msg = Message{Exchange: "order", RoutingKey: "order.created", Body: `{"id":1}`}
+		return Insert(tx, &msg)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "direct", msg.ExchangeType)
+
+	pub := &fakePublisher{}
+	relay := NewRelay(db, pub, Options{})
+	n, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Equal(t, 1, pub.count())
+	assert.Equal(t, `{"id":1}`, pub.published[0].Body)
+	assert.Equal(t, "order.created", pub.published[0].RoutingKey)
+
+	stored := loadMessage(t, db, msg.ID)
+	assert.Equal(t, StatusSent, stored.Status)
+	assert.NotNil(t, stored.SentAt)
+
+	n, err = relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 1, pub.count())
+}
+
+// TestRelay_RollbackNoPublish 测试事务回滚不发布
+//
+// 【功能点】验证事务回滚时消息与业务数据一起回滚，中继不会发布任何消息
+// 【测试流程】事务中写入消息后返回错误，断言表中无消息且 ProcessBatch 不发布
+func TestRelay_RollbackNoPublish(t *testing.T) {
+	db := newTestDB(t)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := Insert(tx, &Message{Exchange: "order", Body: "x"}); err != nil {
+			return err
+		}
+		return errors.New("业务校验失败")
+	})
+	require.Error(t, err)
+
+	var count int64
+	require.NoError(t, db.Model(&Message{}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	pub := &fakePublisher{}
+	n, err := NewRelay(db, pub, Options{}).ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Zero(t, pub.count())
+}
+
+// TestRelay_RetryExhaustion 测试重试与 dead 状态
+//
+// 【功能点】验证发送失败时按退避时间重试，失败次数达到上限后标记为 dead 且不再发送
+// 【测试流程】
+//  1. 发布者一直失败，MaxRetries=3，BaseBackoff 极短
+//  2. 第一次失败后消息回到 pending，NextRetryAt 推迟，立即再次处理时不会被领取
+//  3. 等待退避时间后继续处理，第 3 次失败后状态为 dead，Retries=3，LastError 已记录
+//  4. dead 消息不再被领取
+func TestRelay_RetryExhaustion(t *testing.T) {
+	db := newTestDB(t)
+	msg := Message{Exchange: "order", Body: "x"}
+	require.NoError(t, Insert(db, &msg))
+
+	pub := &fakePublisher{}
+	pub.failures.Store(-1)
+	relay := NewRelay(db, pub, Options{MaxRetries: 3, BaseBackoff: 20 * time.Millisecond, MaxBackoff: 50 * time.Millisecond})
+	ctx := context.Background()
+
+	n, err := relay.ProcessBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	stored := loadMessage(t, db, msg.ID)
+	assert.Equal(t, StatusPending, stored.Status)
+	assert.Equal(t, 1, stored.Retries)
+	assert.True(t, stored.NextRetryAt.After(time.Now()))
+
+	n, err = relay.ProcessBatch(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "退避时间内不应被再次领取")
+
+	require.Eventually(t, func() bool {
+		_, err := relay.ProcessBatch(ctx)
+		require.NoError(t, err)
+		return loadMessage(t, db, msg.ID).Status == StatusDead
+	}, 2*time.Second, 10*time.Millisecond)
+
+	stored = loadMessage(t, db, msg.ID)
+	assert.Equal(t, 3, stored.Retries)
+	assert.Equal(t, "broker unavailable", stored.LastError)
+	assert.Zero(t, pub.count())
+
+	time.Sleep(60 * time.Millisecond)
+	n, err = relay.ProcessBatch(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+// TestRelay_RecoverAfterFailure 测试失败后恢复发送
+//
+// 【功能点】验证发送失败一次后，下次重试成功时消息被标记为 sent
+// 【测试流程】发布者失败一次，等待退避后再次处理，断言状态为 sent 且只发布一次
+func TestRelay_RecoverAfterFailure(t *testing.T) {
+	db := newTestDB(t)
+	msg := Message{Exchange: "order", Body: "x"}
+	require.NoError(t, Insert(db, &msg))
+
+	pub := &fakePublisher{}
+	pub.failures.Store(1)
+	relay := NewRelay(db, pub, Options{BaseBackoff: 10 * time.Millisecond})
+
+	_, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, _ = relay.ProcessBatch(context.Background())
+		return loadMessage(t, db, msg.ID).Status == StatusSent
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, pub.count())
+	assert.Equal(t, 1, loadMessage(t, db, msg.ID).Retries)
+}
+
+// TestRelay_Deferred 测试暂缓投递
+//
+// 【功能点】验证发布者返回 ErrDeferred 时消息在 BaseBackoff 后重新投递，不计入失败次数
+// 【测试流程】
+//  1. 发布者返回包装了 ErrDeferred 的错误 5 次，MaxRetries=2
+//  2. 第一次处理后消息回到 pending，Retries 为 0，NextRetryAt 推迟，LastError 已记录
+//  3. 持续处理直到发送成功，断言状态为 sent 且 Retries 仍为 0（未因超过 MaxRetries 标记为 dead）
+func TestRelay_Deferred(t *testing.T) {
+	db := newTestDB(t)
+	msg := Message{Exchange: "order", Body: "x"}
+	require.NoError(t, Insert(db, &msg))
+
+	pub := &fakePublisher{err: fmt.Errorf("%w: 消息发布被限流", ErrDeferred)}
+	pub.failures.Store(5)
+	relay := NewRelay(db, pub, Options{MaxRetries: 2, BaseBackoff: 10 * time.Millisecond})
+
+	_, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	stored := loadMessage(t, db, msg.ID)
+	assert.Equal(t, StatusPending, stored.Status)
+	assert.Zero(t, stored.Retries)
+	assert.True(t, stored.NextRetryAt.After(time.Now()))
+	assert.Contains(t, stored.LastError, "消息发布被限流")
+
+	require.Eventually(t, func() bool {
+		_, _ = relay.ProcessBatch(context.Background())
+		return loadMessage(t, db, msg.ID).Status == StatusSent
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, pub.count())
+	assert.Zero(t, loadMessage(t, db, msg.ID).Retries)
+}
+
+// TestRelay_Backoff 测试重试等待时间
+//
+// 【功能点】验证等待时间按 2 的幂增长并以 MaxBackoff 为上限
+// 【测试流程】BaseBackoff=1s、MaxBackoff=10s，依次计算第 1~6 次失败后的等待时间
+func TestRelay_Backoff(t *testing.T) {
+	relay := NewRelay(nil, nil, Options{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second})
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, want := range expected {
+		assert.Equal(t, want, relay.backoff(i+1), "第 %d 次失败", i+1)
+	}
+}
+
+// TestRelay_ConcurrentRelays 测试多个中继并发处理
+//
+// 【功能点】验证多个中继同时处理同一张表时，每条消息只被发布一次
+// 【测试流程】写入 50 条消息，4 个中继并发循环处理直到全部发送，断言发布总数为 50 且无重复
+func TestRelay_ConcurrentRelays(t *testing.T) {
+	db := newTestDB(t)
+	for i := 0; i < 50; i++ {
+		require.NoError(t, Insert(db, &Message{Exchange: "order", Body: fmt.Sprintf("m-%d", i)}))
+	}
+
+	pub := &fakePublisher{}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			relay := NewRelay(db, pub, Options{BatchSize: 10})
+			for {
+				n, err := relay.ProcessBatch(context.Background())
+				if err != nil {
+					t.Errorf("处理失败: %v", err)
+					return
+				}
+				if n == 0 {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 50, pub.count())
+	seen := make(map[uint64]bool)
+	for _, m := range pub.published {
+		assert.False(t, seen[m.ID], "消息 %d 被重复发布", m.ID)
+		seen[m.ID] = true
+	}
+}
+
+// TestRelay_StartStop 测试后台协程
+//
+// 【功能点】验证 Start 后消息被自动投递，Stop 可正常返回且可重复调用
+// 【测试流程】启动中继后写入消息，等待状态变为 sent，然后停止两次
+func TestRelay_StartStop(t *testing.T) {
+	db := newTestDB(t)
+	pub := &fakePublisher{}
+	relay := NewRelay(db, pub, Options{PollInterval: 10 * time.Millisecond})
+	relay.Start()
+	relay.Start()
+
+	msg := Message{Exchange: "order", Body: "x"}
+	require.NoError(t, Insert(db, &msg))
+	assert.Eventually(t, func() bool {
+		return loadMessage(t, db, msg.ID).Status == StatusSent
+	}, time.Second, 10*time.Millisecond)
+
+	relay.Stop()
+	relay.Stop()
+	assert.Equal(t, 1, pub.count())
+}
+
+// TestInsert_Validate 测试写入参数校验
+//
+// 【功能点】验证事务为空或交换机为空时返回错误
+// 【测试流程】分别传入 nil 事务和空交换机，断言返回错误
+func TestInsert_Validate(t *testing.T) {
+	assert.Error(t, Insert(nil, &Message{Exchange: "order"}))
+	assert.Error(t, Insert(newTestDB(t), &Message{Body: "x"}))
+}
@@ -4,10 +4,8 @@ package ratelimit
 
 import (
 	"context"
-	"sync"
+	"errors"
 	"time"
-
-	"golang.org/x/time/rate"
 )
 
 // Limiter 限流器接口
@@ -20,146 +18,49 @@ type Limiter interface {
 	// 返回: 是否允许请求，错误信息
 	Allow(ctx context.Context, key string, ratePerSecond int, burst int) (bool, error)
 
-	// Close 关闭限流器，释放资源
-	Close() error
-}
-
-// MemoryLimiter 内存限流器
-// 使用 golang.org/x/time/rate 实现令牌桶算法
-// 适用于单机部署场景
-type MemoryLimiter struct {
-	limiters sync.Map      // 存储每个 key 的限流器
-	mu       sync.Mutex    // 用于创建限流器时的互斥锁
-	stopCh   chan struct{} // 停止清理协程的信号
-	interval time.Duration // 清理间隔
-}
-
-// limiterEntry 限流器条目
-type limiterEntry struct {
-	limiter    *rate.Limiter
-	lastAccess time.Time
-	rate       int
-	burst      int
-}
-
-// NewMemoryLimiter 创建内存限流器
-// cleanupInterval: 清理过期条目的间隔时间
-func NewMemoryLimiter(cleanupInterval time.Duration) *MemoryLimiter {
-	ml := &MemoryLimiter{
-		stopCh:   make(chan struct{}),
-		interval: cleanupInterval,
-	}
-
-	// 启动清理协程
-	go ml.cleanup()
-
-	return ml
-}
-
-// Allow 检查是否允许请求
-func (ml *MemoryLimiter) Allow(ctx context.Context, key string, ratePerSecond int, burst int) (bool, error) {
-	// 获取或创建限流器
-	entry := ml.getOrCreate(key, ratePerSecond, burst)
-
-	// 更新最后访问时间
-	entry.lastAccess = time.Now()
-
-	// 检查是否允许
-	return entry.limiter.Allow(), nil
-}
-
-// getOrCreate 获取或创建限流器
-func (ml *MemoryLimiter) getOrCreate(key string, ratePerSecond int, burst int) *limiterEntry {
-	// 先尝试读取
-	if v, ok := ml.limiters.Load(key); ok {
-		entry := v.(*limiterEntry)
-		// 如果速率配置发生变化，重新创建限流器
-		if entry.rate != ratePerSecond || entry.burst != burst {
-			ml.mu.Lock()
-			defer ml.mu.Unlock()
-			// 双重检查
-			if v, ok := ml.limiters.Load(key); ok {
-				entry = v.(*limiterEntry)
-				if entry.rate != ratePerSecond || entry.burst != burst {
-					newEntry := &limiterEntry{
-						limiter:    rate.NewLimiter(rate.Limit(ratePerSecond), burst),
-						lastAccess: time.Now(),
-						rate:       ratePerSecond,
-						burst:      burst,
-					}
-					ml.limiters.Store(key, newEntry)
-					return newEntry
-				}
-			}
-		}
-		return entry
-	}
+	// Take 检查是否允许请求，并返回剩余配额和恢复时间
+	// 参数与 Allow 相同，用于输出 X-RateLimit-* 和 Retry-After 响应头
+	Take(ctx context.Context, key string, ratePerSecond int, burst int) (Result, error)
 
-	// 创建新的限流器
-	ml.mu.Lock()
-	defer ml.mu.Unlock()
+	// TakeN 原子地获取 n 个配额，用于按请求消耗计算的限流，Take 等同于 n 为 1
+	// n 超过配额上限时不获取配额，返回 ErrCostExceedsLimit
+	TakeN(ctx context.Context, key string, ratePerSecond int, burst int, n int) (Result, error)
 
-	// 双重检查
-	if v, ok := ml.limiters.Load(key); ok {
-		return v.(*limiterEntry)
-	}
+	// Adjust 调整已获取的配额：delta 大于 0 时额外扣除（不拒绝，配额可透支到下一周期），小于 0 时归还
+	// 用于请求处理过程中才确定实际消耗的场景
+	Adjust(ctx context.Context, key string, ratePerSecond int, burst int, delta int) error
 
-	entry := &limiterEntry{
-		limiter:    rate.NewLimiter(rate.Limit(ratePerSecond), burst),
-		lastAccess: time.Now(),
-		rate:       ratePerSecond,
-		burst:      burst,
-	}
-	ml.limiters.Store(key, entry)
-	return entry
+	// Close 关闭限流器，释放资源
+	Close() error
 }
 
-// cleanup 定期清理过期的限流器
-func (ml *MemoryLimiter) cleanup() {
-	ticker := time.NewTicker(ml.interval)
-	defer ticker.Stop()
+// ErrCostExceedsLimit 单次请求消耗的配额超过配额上限，无论等待多久都无法被允许
+var ErrCostExceedsLimit = errors.New("请求消耗的配额超过配额上限")
 
-	for {
-		select {
-		case <-ticker.C:
-			ml.doCleanup()
-		case <-ml.stopCh:
-			return
-		}
-	}
+// Result 限流检查结果
+type Result struct {
+	Allowed    bool          // 是否允许请求
+	Limit      int           // 配额上限（令牌桶容量或窗口内最大请求数）
+	Remaining  int           // 本次请求后剩余的可用配额
+	RetryAfter time.Duration // 被拒绝时距离下一个可用配额的时间，允许时为 0
+	ResetAfter time.Duration // 距离配额完全恢复的时间
 }
 
-// doCleanup 执行清理操作
-func (ml *MemoryLimiter) doCleanup() {
-	// 清理超过 10 分钟未访问的限流器
-	expireTime := time.Now().Add(-10 * time.Minute)
+// KeyStore 可枚举和清除限流键的限流器
+// MemoryLimiter 和 RedisLimiter 均实现了该接口，供管理接口查看热点键和清除被限流的键
+type KeyStore interface {
+	// Keys 列出以 prefix 开头的活跃限流键及其剩余配额，按键名排序
+	// limit 大于 0 时最多返回 limit 个键
+	Keys(ctx context.Context, prefix string, limit int) ([]KeyInfo, error)
 
-	ml.limiters.Range(func(key, value interface{}) bool {
-		entry := value.(*limiterEntry)
-		if entry.lastAccess.Before(expireTime) {
-			ml.limiters.Delete(key)
-		}
-		return true
-	})
+	// Delete 清除限流键，清除后该键的配额立即恢复
+	// 返回: 键是否存在，错误信息
+	Delete(ctx context.Context, key string) (bool, error)
 }
 
-// Close 关闭限流器
-func (ml *MemoryLimiter) Close() error {
-	close(ml.stopCh)
-	return nil
-}
-
-// Stats 获取当前限流器统计信息
-func (ml *MemoryLimiter) Stats() map[string]interface{} {
-	count := 0
-	ml.limiters.Range(func(key, value interface{}) bool {
-		count++
-		return true
-	})
-
-	return map[string]interface{}{
-		"type":     "memory",
-		"count":    count,
-		"interval": ml.interval.String(),
-	}
+// KeyInfo 限流键信息
+type KeyInfo struct {
+	Key       string `json:"key"`       // 限流键（不含存储前缀）
+	Limit     int    `json:"limit"`     // 配额上限，无法确定时为 0
+	Remaining int    `json:"remaining"` // 当前剩余的可用请求数
 }
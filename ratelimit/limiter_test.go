@@ -12,6 +12,10 @@
 // 6. 速率动态变更
 // 7. 统计信息
 // 8. 资源清理
+// 9. 剩余配额与恢复时间（Take）
+// 10. 枚举和清除限流键（Keys、Delete）
+// 11. 按请求消耗获取配额（TakeN）与调整配额（Adjust）
+// 12. 超过空闲时间的限流键被清理，限流键数量达到上限时淘汰并记录警告日志
 //
 // 运行测试：go test -v ./ratelimit/...
 // ==================================================
@@ -19,10 +23,18 @@ package ratelimit
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/zzsen/gin_core/logger"
 )
 
 // ==================== MemoryLimiter 单元测试 ====================
@@ -82,6 +94,147 @@ func TestMemoryLimiter_Allow_Basic(t *testing.T) {
 	}
 }
 
+// TestMemoryLimiter_Take 测试剩余配额与恢复时间
+//
+// 【功能点】验证 Take 返回的剩余请求数逐次递减，被拒绝时返回距离下一个令牌的时间
+// 【测试流程】
+//  1. rate=1、burst=3，连续请求 3 次，断言 Remaining 依次为 2、1、0，Limit 为 3，ResetAfter 依次约为 1s、2s、3s
+//  2. 第 4 次请求被拒绝，断言 RetryAfter 约为 1s
+func TestMemoryLimiter_Take(t *testing.T) {
+	limiter := NewMemoryLimiter(time.Minute)
+	defer limiter.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Take(ctx, "take-key", 1, 3)
+		if err != nil {
+			t.Fatalf("Take 返回错误: %v", err)
+		}
+		if !result.Allowed || result.Limit != 3 || result.Remaining != 2-i {
+			t.Errorf("请求 %d: %+v, 期望允许、Limit=3、Remaining=%d", i+1, result, 2-i)
+		}
+		wantReset := time.Duration(i+1) * time.Second
+		if result.ResetAfter < wantReset-100*time.Millisecond || result.ResetAfter > wantReset {
+			t.Errorf("请求 %d: ResetAfter = %v, 期望约 %v", i+1, result.ResetAfter, wantReset)
+		}
+		if result.RetryAfter != 0 {
+			t.Errorf("请求 %d: 允许时 RetryAfter 应为 0, 实际 %v", i+1, result.RetryAfter)
+		}
+	}
+
+	result, err := limiter.Take(ctx, "take-key", 1, 3)
+	if err != nil {
+		t.Fatalf("Take 返回错误: %v", err)
+	}
+	if result.Allowed || result.Remaining != 0 {
+		t.Errorf("第 4 次请求应被拒绝且 Remaining=0, 实际 %+v", result)
+	}
+	if result.RetryAfter < 900*time.Millisecond || result.RetryAfter > time.Second {
+		t.Errorf("RetryAfter = %v, 期望约 1s", result.RetryAfter)
+	}
+}
+
+// TestMemoryLimiter_TakeN 测试按请求消耗获取配额
+//
+// 【功能点】验证 TakeN 原子地获取 n 个令牌，令牌不足时不扣除，n 超过突发容量时返回 ErrCostExceedsLimit
+// 【测试流程】
+//  1. burst=10，消耗 5 的请求连续 2 次，断言 Remaining 依次为 5、0，第 3 次被拒绝且 RetryAfter 约 5s
+//  2. 另一个键混合消耗 3、4、4、3：第 3 个请求剩余 3 个令牌不足被拒绝且不扣除，第 4 个请求允许，剩余 0
+//  3. 消耗 11 的请求返回 ErrCostExceedsLimit，不扣除令牌
+func TestMemoryLimiter_TakeN(t *testing.T) {
+	limiter := NewMemoryLimiter(time.Minute)
+	defer limiter.Close()
+
+	ctx := context.Background()
+	for i, want := range []int{5, 0} {
+		result, err := limiter.TakeN(ctx, "cost-key", 1, 10, 5)
+		if err != nil {
+			t.Fatalf("TakeN 返回错误: %v", err)
+		}
+		if !result.Allowed || result.Limit != 10 || result.Remaining != want {
+			t.Errorf("请求 %d: %+v, 期望允许、Limit=10、Remaining=%d", i+1, result, want)
+		}
+	}
+	result, err := limiter.TakeN(ctx, "cost-key", 1, 10, 5)
+	if err != nil {
+		t.Fatalf("TakeN 返回错误: %v", err)
+	}
+	if result.Allowed || result.Remaining != 0 {
+		t.Errorf("第 3 次请求应被拒绝且 Remaining=0, 实际 %+v", result)
+	}
+	if result.RetryAfter < 4900*time.Millisecond || result.RetryAfter > 5*time.Second {
+		t.Errorf("RetryAfter = %v, 期望约 5s", result.RetryAfter)
+	}
+
+	mixed := []struct {
+		cost      int
+		allowed   bool
+		remaining int
+	}{
+		{3, true, 7},
+		{4, true, 3},
+		{4, false, 3},
+		{3, true, 0},
+	}
+	for i, step := range mixed {
+		result, err := limiter.TakeN(ctx, "mixed-key", 1, 10, step.cost)
+		if err != nil {
+			t.Fatalf("TakeN 返回错误: %v", err)
+		}
+		if result.Allowed != step.allowed || result.Remaining != step.remaining {
+			t.Errorf("请求 %d（消耗 %d）: %+v, 期望 Allowed=%v、Remaining=%d", i+1, step.cost, result, step.allowed, step.remaining)
+		}
+	}
+
+	result, err = limiter.TakeN(ctx, "big-key", 1, 10, 11)
+	if !errors.Is(err, ErrCostExceedsLimit) {
+		t.Fatalf("消耗超过突发容量时应返回 ErrCostExceedsLimit, 实际 %v", err)
+	}
+	if result.Allowed || result.Limit != 10 || result.Remaining != 10 {
+		t.Errorf("消耗超过突发容量时不应扣除令牌, 实际 %+v", result)
+	}
+}
+
+// TestMemoryLimiter_Adjust 测试调整已获取的配额
+//
+// 【功能点】验证 Adjust 额外扣除时令牌可透支、归还时不超过突发容量
+// 【测试流程】
+//  1. burst=10，获取 1 个令牌后额外扣除 9 个，断言剩余 0；再额外扣除 5 个，断言消耗 1 的请求被拒绝且 RetryAfter 约 6s
+//  2. 归还 20 个令牌，断言消耗 10 的请求允许、剩余 0（归还不超过容量）
+func TestMemoryLimiter_Adjust(t *testing.T) {
+	limiter := NewMemoryLimiter(time.Minute)
+	defer limiter.Close()
+
+	ctx := context.Background()
+	if _, err := limiter.TakeN(ctx, "adjust-key", 1, 10, 1); err != nil {
+		t.Fatalf("TakeN 返回错误: %v", err)
+	}
+	if err := limiter.Adjust(ctx, "adjust-key", 1, 10, 9); err != nil {
+		t.Fatalf("Adjust 返回错误: %v", err)
+	}
+	if keys, _ := limiter.Keys(ctx, "adjust-key", 0); len(keys) != 1 || keys[0].Remaining != 0 {
+		t.Errorf("额外扣除后剩余应为 0, 实际 %+v", keys)
+	}
+	if err := limiter.Adjust(ctx, "adjust-key", 1, 10, 5); err != nil {
+		t.Fatalf("Adjust 返回错误: %v", err)
+	}
+	result, _ := limiter.TakeN(ctx, "adjust-key", 1, 10, 1)
+	if result.Allowed {
+		t.Fatalf("透支后请求应被拒绝, 实际 %+v", result)
+	}
+	if result.RetryAfter < 5900*time.Millisecond || result.RetryAfter > 6*time.Second {
+		t.Errorf("RetryAfter = %v, 期望约 6s", result.RetryAfter)
+	}
+
+	if err := limiter.Adjust(ctx, "adjust-key", 1, 10, -20); err != nil {
+		t.Fatalf("Adjust 返回错误: %v", err)
+	}
+	result, _ = limiter.TakeN(ctx, "adjust-key", 1, 10, 10)
+	if !result.Allowed || result.Remaining != 0 {
+		t.Errorf("归还后消耗 10 的请求应允许且剩余 0, 实际 %+v", result)
+	}
+}
+
 // TestMemoryLimiter_Allow_DifferentKeys 测试不同 key 的独立限流
 //
 // 【功能点】验证每个 key 有独立的令牌桶，互不影响
@@ -290,6 +443,155 @@ func TestMemoryLimiter_Cleanup(t *testing.T) {
 	time.Sleep(time.Millisecond * 100)
 }
 
+// TestMemoryLimiter_KeysAndDelete 测试枚举和清除限流键
+//
+// 【功能点】验证 Keys 按前缀返回排序后的键和剩余令牌数，Delete 后配额立即恢复
+// 【测试流程】
+//  1. ip:a 消耗 2 个令牌（burst=2），ip:b 消耗 1 个，global:x 消耗 1 个
+//  2. 以前缀 ip: 枚举，断言返回 ip:a（剩余 0）、ip:b（剩余 1），limit=1 时只返回 ip:a
+//  3. ip:a 被限流，Delete 后断言请求立即放行，删除不存在的键返回 false
+func TestMemoryLimiter_KeysAndDelete(t *testing.T) {
+	limiter := NewMemoryLimiter(time.Minute)
+	defer limiter.Close()
+
+	ctx := context.Background()
+	for _, key := range []string{"ip:a", "ip:a", "ip:b", "global:x"} {
+		limiter.Allow(ctx, key, 1, 2)
+	}
+
+	keys, err := limiter.Keys(ctx, "ip:", 0)
+	if err != nil {
+		t.Fatalf("Keys 返回错误: %v", err)
+	}
+	want := []KeyInfo{{Key: "ip:a", Limit: 2, Remaining: 0}, {Key: "ip:b", Limit: 2, Remaining: 1}}
+	if len(keys) != len(want) || keys[0] != want[0] || keys[1] != want[1] {
+		t.Errorf("Keys = %+v, want %+v", keys, want)
+	}
+	if keys, _ := limiter.Keys(ctx, "ip:", 1); len(keys) != 1 || keys[0].Key != "ip:a" {
+		t.Errorf("limit=1 时 Keys = %+v, want [ip:a]", keys)
+	}
+
+	if allowed, _ := limiter.Allow(ctx, "ip:a", 1, 2); allowed {
+		t.Fatal("ip:a 令牌已耗尽，应被限流")
+	}
+	if deleted, err := limiter.Delete(ctx, "ip:a"); err != nil || !deleted {
+		t.Fatalf("Delete = %v, %v, want true, nil", deleted, err)
+	}
+	if allowed, _ := limiter.Allow(ctx, "ip:a", 1, 2); !allowed {
+		t.Error("Delete 后配额应立即恢复")
+	}
+	if deleted, _ := limiter.Delete(ctx, "ip:missing"); deleted {
+		t.Error("删除不存在的键应返回 false")
+	}
+}
+
+// TestMemoryLimiter_IdleEviction 测试清理空闲的限流键
+//
+// 【功能点】验证超过 IdleTTL 未访问的限流键被清理并计数，期间访问过的限流键保留，清理后以满令牌桶重新创建
+// 【测试流程】
+//  1. IdleTTL=1 分钟，idle-a 耗尽令牌，idle-b 消耗 1 个令牌
+//  2. 以当前时间清理，断言两个键均保留
+//  3. 以 2 分钟后清理前访问 idle-b（最后访问时间晚于清理时间减去 IdleTTL），断言只清理 idle-a，Evictions().Idle 为 1
+//  4. idle-a 重新请求时立即放行
+//  5. 清理协程按 CleanupInterval 运行：IdleTTL=20ms 时断言限流键在 1 秒内被清理
+func TestMemoryLimiter_IdleEviction(t *testing.T) {
+	limiter := NewMemoryLimiterWithOptions(MemoryOptions{IdleTTL: time.Minute, CleanupInterval: time.Hour})
+	defer limiter.Close()
+
+	ctx := context.Background()
+	limiter.Allow(ctx, "idle-a", 1, 1)
+	limiter.Allow(ctx, "idle-b", 1, 2)
+	limiter.doCleanup(time.Now())
+	if got := limiter.Len(); got != 2 {
+		t.Fatalf("未超过空闲时间时 Len = %d, want 2", got)
+	}
+
+	later := time.Now().Add(2 * time.Minute)
+	limiter.getOrCreate("idle-b", 1, 2, later.Add(-time.Second))
+	limiter.doCleanup(later)
+	if got := limiter.Len(); got != 1 {
+		t.Errorf("清理后 Len = %d, want 1", got)
+	}
+	if keys, _ := limiter.Keys(ctx, "idle-", 0); len(keys) != 1 || keys[0].Key != "idle-b" {
+		t.Errorf("清理后 Keys = %+v, want [idle-b]", keys)
+	}
+	if evictions := limiter.Evictions(); evictions != (EvictionStats{Idle: 1}) {
+		t.Errorf("Evictions = %+v, want {Idle:1}", evictions)
+	}
+	if allowed, _ := limiter.Allow(ctx, "idle-a", 1, 1); !allowed {
+		t.Error("清理后的限流键应以满令牌桶重新创建")
+	}
+
+	janitor := NewMemoryLimiterWithOptions(MemoryOptions{IdleTTL: 20 * time.Millisecond, CleanupInterval: 10 * time.Millisecond})
+	defer janitor.Close()
+	janitor.Allow(ctx, "janitor", 1, 1)
+	deadline := time.Now().Add(time.Second)
+	for janitor.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := janitor.Len(); got != 0 {
+		t.Errorf("清理协程运行后 Len = %d, want 0", got)
+	}
+}
+
+// TestMemoryLimiter_MaxEntries 测试限流键数量上限
+//
+// 【功能点】验证大量不同的限流键（模拟扫描）不会使限流键数量超过 MaxEntries，超出的部分按近似 LRU 淘汰并计数，
+// 每个清理周期内只记录一次警告日志
+// 【测试流程】
+//  1. Shards=4、MaxEntries=100，8 个 goroutine 并发请求共 10000 个不同的限流键
+//  2. 断言 Len 不超过 100，Evictions().Capacity 等于 10000 减去 Len
+//  3. 断言达到上限的警告日志只记录一次；清理后再次达到上限时重新记录
+func TestMemoryLimiter_MaxEntries(t *testing.T) {
+	hook := logtest.NewLocal(logger.Logger)
+	defer hook.Reset()
+	limiter := NewMemoryLimiterWithOptions(MemoryOptions{Shards: 4, MaxEntries: 100, IdleTTL: time.Hour, CleanupInterval: time.Hour})
+	defer limiter.Close()
+
+	ctx := context.Background()
+	const workers, keysPerWorker = 8, 1250
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range keysPerWorker {
+				limiter.Allow(ctx, fmt.Sprintf("ip:10.%d.%d.%d:/api/orders", w, i/256, i%256), 10, 10)
+			}
+		}()
+	}
+	wg.Wait()
+
+	count := limiter.Len()
+	if count > 100 {
+		t.Errorf("Len = %d, 不应超过 MaxEntries 100", count)
+	}
+	if evicted := limiter.Evictions().Capacity; evicted != uint64(workers*keysPerWorker-count) {
+		t.Errorf("Evictions().Capacity = %d, want %d", evicted, workers*keysPerWorker-count)
+	}
+
+	countWarnings := func() int {
+		warnings := 0
+		for _, entry := range hook.AllEntries() {
+			if entry.Level == logrus.WarnLevel && strings.Contains(entry.Message, "限流键数量达到上限 100") {
+				warnings++
+			}
+		}
+		return warnings
+	}
+	if got := countWarnings(); got != 1 {
+		t.Errorf("警告日志记录 %d 次, want 1", got)
+	}
+
+	limiter.doCleanup(time.Now())
+	for i := range 200 {
+		limiter.Allow(ctx, fmt.Sprintf("ip:192.168.0.%d", i), 10, 10)
+	}
+	if got := countWarnings(); got != 2 {
+		t.Errorf("清理后再次达到上限时警告日志共记录 %d 次, want 2", got)
+	}
+}
+
 // ==================== 基准测试 ====================
 // 用于测试限流器的性能表现
 
@@ -329,6 +631,41 @@ func BenchmarkMemoryLimiter_Allow_Parallel(b *testing.B) {
 	})
 }
 
+// BenchmarkMemoryLimiter_Contention 基准测试分片对锁竞争的影响
+// 测试场景：32 个 goroutine 各自请求不同的限流键，shards=1 时所有限流键共用一把锁（分片前的情况），
+// 与默认的 32 个分片对比每次请求的耗时
+func BenchmarkMemoryLimiter_Contention(b *testing.B) {
+	const goroutines, keysPerGoroutine = 32, 256
+	for _, shards := range []int{1, defaultMemoryShards} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			limiter := NewMemoryLimiterWithOptions(MemoryOptions{Shards: shards, CleanupInterval: time.Minute})
+			defer limiter.Close()
+
+			ctx := context.Background()
+			keys := make([][]string, goroutines)
+			for g := range keys {
+				keys[g] = make([]string, keysPerGoroutine)
+				for i := range keys[g] {
+					keys[g][i] = fmt.Sprintf("ip:10.0.%d.%d:/api/orders", g, i)
+				}
+			}
+
+			b.ResetTimer()
+			var wg sync.WaitGroup
+			for g := range goroutines {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := g; i < b.N; i += goroutines {
+						limiter.Allow(ctx, keys[g][i%keysPerGoroutine], 1000000, 1000000)
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
 // BenchmarkMemoryLimiter_Allow_DifferentKeys 基准测试多 key 访问性能
 // 测试场景：不同 key 的请求处理速度（模拟多 IP 场景）
 func BenchmarkMemoryLimiter_Allow_DifferentKeys(b *testing.B) {
@@ -0,0 +1,374 @@
+// Package ratelimit 提供限流功能
+// 本文件实现基于令牌桶的分片内存限流器
+package ratelimit
+
+import (
+	"context"
+	"hash/maphash"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/zzsen/gin_core/logger"
+)
+
+const (
+	// defaultMemoryShards 默认分片数
+	defaultMemoryShards = 32
+	// defaultMemoryIdleTTL 默认空闲时间
+	defaultMemoryIdleTTL = 10 * time.Minute
+	// defaultMemoryCleanupInterval 默认清理间隔
+	defaultMemoryCleanupInterval = time.Minute
+	// defaultMemoryMaxEntries 默认限流键数量上限
+	defaultMemoryMaxEntries = 100000
+	// evictionSamples 达到容量上限时从分片中抽样的键数，淘汰其中最久未访问的键
+	evictionSamples = 8
+)
+
+// MemoryOptions 内存限流器配置
+type MemoryOptions struct {
+	// Shards 分片数，每个分片使用独立的锁，不同限流键的请求分散到不同分片以减少锁竞争，默认 32
+	Shards int
+	// IdleTTL 限流键的空闲时间，超过该时间未访问的限流键由清理协程删除，默认 10 分钟
+	IdleTTL time.Duration
+	// CleanupInterval 清理空闲限流键的间隔，默认 1 分钟
+	CleanupInterval time.Duration
+	// MaxEntries 限流键数量上限，达到上限时淘汰最久未访问的限流键（近似 LRU），默认 100000
+	MaxEntries int
+}
+
+// withDefaults 补全未配置的选项
+func (o MemoryOptions) withDefaults() MemoryOptions {
+	if o.Shards <= 0 {
+		o.Shards = defaultMemoryShards
+	}
+	if o.IdleTTL <= 0 {
+		o.IdleTTL = defaultMemoryIdleTTL
+	}
+	if o.CleanupInterval <= 0 {
+		o.CleanupInterval = defaultMemoryCleanupInterval
+	}
+	if o.MaxEntries <= 0 {
+		o.MaxEntries = defaultMemoryMaxEntries
+	}
+	// 每个分片至少容纳一个限流键，保证总数不超过上限
+	o.Shards = min(o.Shards, o.MaxEntries)
+	return o
+}
+
+// EvictionStats 内存限流器淘汰的限流键数
+type EvictionStats struct {
+	Idle     uint64 `json:"idle"`     // 超过空闲时间被清理的限流键数
+	Capacity uint64 `json:"capacity"` // 达到数量上限被淘汰的限流键数
+}
+
+// MemoryLimiter 内存限流器
+// 使用 golang.org/x/time/rate 实现令牌桶算法，适用于单机部署场景
+// 限流键按哈希值分散到多个分片，每个分片使用独立的锁；
+// 超过空闲时间的限流键由清理协程删除，限流键数量达到上限时淘汰最久未访问的键，
+// 避免扫描或攻击产生的大量 ip:path 限流键使内存无限增长
+type MemoryLimiter struct {
+	shards   []*memoryShard
+	seed     maphash.Seed
+	shardCap int           // 每个分片的限流键数量上限
+	opts     MemoryOptions // 限流器配置
+	stopCh   chan struct{} // 停止清理协程的信号
+	interval time.Duration // 清理间隔
+
+	evictedIdle     atomic.Uint64 // 超过空闲时间被清理的限流键数
+	evictedCapacity atomic.Uint64 // 达到数量上限被淘汰的限流键数
+	capacityWarned  atomic.Bool   // 当前清理周期内是否已记录达到数量上限的警告日志
+}
+
+// memoryShard 内存限流器的分片
+type memoryShard struct {
+	mu       sync.RWMutex
+	limiters map[string]*limiterEntry
+}
+
+// limiterEntry 限流器条目
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen atomic.Int64 // 最后访问时间（UnixNano）
+	rate     int
+	burst    int
+}
+
+// NewMemoryLimiter 创建内存限流器，分片数、空闲时间和数量上限使用默认值
+// cleanupInterval: 清理空闲限流键的间隔时间
+func NewMemoryLimiter(cleanupInterval time.Duration) *MemoryLimiter {
+	return NewMemoryLimiterWithOptions(MemoryOptions{CleanupInterval: cleanupInterval})
+}
+
+// NewMemoryLimiterWithOptions 按配置创建内存限流器，并启动清理协程
+// 参数：
+//   - opts: 限流器配置，未配置的选项使用默认值
+//
+// 返回：
+//   - *MemoryLimiter: 内存限流器
+func NewMemoryLimiterWithOptions(opts MemoryOptions) *MemoryLimiter {
+	opts = opts.withDefaults()
+	ml := &MemoryLimiter{
+		shards:   make([]*memoryShard, opts.Shards),
+		seed:     maphash.MakeSeed(),
+		shardCap: opts.MaxEntries / opts.Shards,
+		opts:     opts,
+		stopCh:   make(chan struct{}),
+		interval: opts.CleanupInterval,
+	}
+	for i := range ml.shards {
+		ml.shards[i] = &memoryShard{limiters: make(map[string]*limiterEntry)}
+	}
+
+	// 启动清理协程
+	go ml.cleanup()
+
+	return ml
+}
+
+// Allow 检查是否允许请求
+func (ml *MemoryLimiter) Allow(ctx context.Context, key string, ratePerSecond int, burst int) (bool, error) {
+	result, err := ml.Take(ctx, key, ratePerSecond, burst)
+	return result.Allowed, err
+}
+
+// Take 检查是否允许请求，并根据令牌桶剩余令牌计算剩余配额和恢复时间
+func (ml *MemoryLimiter) Take(ctx context.Context, key string, ratePerSecond int, burst int) (Result, error) {
+	return ml.TakeN(ctx, key, ratePerSecond, burst, 1)
+}
+
+// TakeN 从令牌桶中原子地获取 n 个令牌，令牌不足时不扣除
+func (ml *MemoryLimiter) TakeN(ctx context.Context, key string, ratePerSecond int, burst int, n int) (Result, error) {
+	// 获取或创建限流器，同时更新最后访问时间
+	now := time.Now()
+	entry := ml.getOrCreate(key, ratePerSecond, burst, now)
+
+	if n > burst {
+		tokens := max(entry.limiter.TokensAt(now), 0)
+		return Result{Limit: burst, Remaining: int(math.Floor(tokens))}, ErrCostExceedsLimit
+	}
+
+	// 检查是否允许
+	allowed := entry.limiter.AllowN(now, n)
+	// 通过 Adjust 透支后令牌数可能为负数，恢复时间需包含透支的部分
+	tokens := entry.limiter.TokensAt(now)
+
+	result := Result{
+		Allowed:    allowed,
+		Limit:      burst,
+		Remaining:  int(math.Floor(max(tokens, 0))),
+		ResetAfter: tokenDuration(float64(burst)-tokens, ratePerSecond),
+	}
+	if !allowed {
+		result.RetryAfter = tokenDuration(float64(n)-tokens, ratePerSecond)
+	}
+	return result, nil
+}
+
+// Adjust 调整令牌桶中的令牌：delta 大于 0 时预约扣除（令牌可为负数，之后的请求需等待补足），小于 0 时归还
+// 单次扣除最多 burst 个令牌，归还的令牌不会超过令牌桶容量
+func (ml *MemoryLimiter) Adjust(ctx context.Context, key string, ratePerSecond int, burst int, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	now := time.Now()
+	entry := ml.getOrCreate(key, ratePerSecond, burst, now)
+	if delta > 0 {
+		entry.limiter.ReserveN(now, min(delta, burst))
+		return nil
+	}
+	// n 为负数时 AllowN 总是成功并增加令牌，超出容量的部分在下次计算时截断
+	entry.limiter.AllowN(now, delta)
+	return nil
+}
+
+// tokenDuration 计算以 ratePerSecond 的速率生成 tokens 个令牌所需的时间
+func tokenDuration(tokens float64, ratePerSecond int) time.Duration {
+	if tokens <= 0 || ratePerSecond <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / float64(ratePerSecond) * float64(time.Second))
+}
+
+// shard 获取限流键所在的分片
+func (ml *MemoryLimiter) shard(key string) *memoryShard {
+	return ml.shards[maphash.String(ml.seed, key)%uint64(len(ml.shards))]
+}
+
+// getOrCreate 获取或创建限流器，并更新最后访问时间
+// 速率配置发生变化时重新创建限流器；分片已满时先淘汰最久未访问的限流键
+func (ml *MemoryLimiter) getOrCreate(key string, ratePerSecond int, burst int, now time.Time) *limiterEntry {
+	shard := ml.shard(key)
+
+	// 先在读锁下查找，已存在的限流键不阻塞同一分片的其他请求
+	shard.mu.RLock()
+	entry, ok := shard.limiters[key]
+	shard.mu.RUnlock()
+	if ok && entry.rate == ratePerSecond && entry.burst == burst {
+		entry.lastSeen.Store(now.UnixNano())
+		return entry
+	}
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	// 双重检查
+	entry, ok = shard.limiters[key]
+	if ok && entry.rate == ratePerSecond && entry.burst == burst {
+		entry.lastSeen.Store(now.UnixNano())
+		return entry
+	}
+	if !ok && len(shard.limiters) >= ml.shardCap {
+		ml.evictOldest(shard)
+	}
+
+	entry = &limiterEntry{
+		limiter: rate.NewLimiter(rate.Limit(ratePerSecond), burst),
+		rate:    ratePerSecond,
+		burst:   burst,
+	}
+	entry.lastSeen.Store(now.UnixNano())
+	shard.limiters[key] = entry
+	return entry
+}
+
+// evictOldest 淘汰分片中最久未访问的限流键，调用方需持有分片的写锁
+// 从分片中抽样 evictionSamples 个限流键，淘汰其中最久未访问的一个（近似 LRU），
+// 每个清理周期内首次淘汰时记录警告日志
+func (ml *MemoryLimiter) evictOldest(shard *memoryShard) {
+	var (
+		oldestKey  string
+		oldestSeen int64 = math.MaxInt64
+		sampled    int
+	)
+	for key, entry := range shard.limiters {
+		if seen := entry.lastSeen.Load(); seen < oldestSeen {
+			oldestKey, oldestSeen = key, seen
+		}
+		sampled++
+		if sampled >= evictionSamples {
+			break
+		}
+	}
+	if sampled == 0 {
+		return
+	}
+	delete(shard.limiters, oldestKey)
+	ml.evictedCapacity.Add(1)
+	if ml.capacityWarned.CompareAndSwap(false, true) {
+		logger.Warn("[限流] 内存限流器的限流键数量达到上限 %d, 开始淘汰最久未访问的限流键, 可能正在遭受扫描或攻击, 如为正常流量请调大 rateLimit.memoryStore.maxEntries", ml.opts.MaxEntries)
+	}
+}
+
+// cleanup 定期清理空闲的限流器
+func (ml *MemoryLimiter) cleanup() {
+	ticker := time.NewTicker(ml.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ml.doCleanup(time.Now())
+		case <-ml.stopCh:
+			return
+		}
+	}
+}
+
+// doCleanup 删除在 now 之前超过空闲时间未访问的限流器，并重置数量上限的警告状态
+func (ml *MemoryLimiter) doCleanup(now time.Time) {
+	expire := now.Add(-ml.opts.IdleTTL).UnixNano()
+	for _, shard := range ml.shards {
+		shard.mu.Lock()
+		for key, entry := range shard.limiters {
+			if entry.lastSeen.Load() < expire {
+				delete(shard.limiters, key)
+				ml.evictedIdle.Add(1)
+			}
+		}
+		shard.mu.Unlock()
+	}
+	ml.capacityWarned.Store(false)
+}
+
+// Close 关闭限流器
+func (ml *MemoryLimiter) Close() error {
+	close(ml.stopCh)
+	return nil
+}
+
+// Len 获取当前的限流键数量
+func (ml *MemoryLimiter) Len() int {
+	count := 0
+	for _, shard := range ml.shards {
+		shard.mu.RLock()
+		count += len(shard.limiters)
+		shard.mu.RUnlock()
+	}
+	return count
+}
+
+// Evictions 获取超过空闲时间被清理和达到数量上限被淘汰的限流键数
+func (ml *MemoryLimiter) Evictions() EvictionStats {
+	return EvictionStats{
+		Idle:     ml.evictedIdle.Load(),
+		Capacity: ml.evictedCapacity.Load(),
+	}
+}
+
+// Stats 获取当前限流器统计信息
+func (ml *MemoryLimiter) Stats() map[string]interface{} {
+	evictions := ml.Evictions()
+	return map[string]interface{}{
+		"type":            "memory",
+		"count":           ml.Len(),
+		"interval":        ml.interval.String(),
+		"shards":          len(ml.shards),
+		"idleTTL":         ml.opts.IdleTTL.String(),
+		"maxEntries":      ml.opts.MaxEntries,
+		"evictedIdle":     evictions.Idle,
+		"evictedCapacity": evictions.Capacity,
+	}
+}
+
+// Keys 列出以 prefix 开头的限流键及令牌桶中的剩余令牌数
+func (ml *MemoryLimiter) Keys(ctx context.Context, prefix string, limit int) ([]KeyInfo, error) {
+	now := time.Now()
+	keys := make([]KeyInfo, 0)
+	for _, shard := range ml.shards {
+		shard.mu.RLock()
+		for name, entry := range shard.limiters {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			keys = append(keys, KeyInfo{
+				Key:       name,
+				Limit:     entry.burst,
+				Remaining: int(math.Floor(max(entry.limiter.TokensAt(now), 0))),
+			})
+		}
+		shard.mu.RUnlock()
+	}
+	slices.SortFunc(keys, func(a, b KeyInfo) int { return strings.Compare(a.Key, b.Key) })
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys, nil
+}
+
+// Delete 清除限流键，下次请求时以满令牌桶重新创建
+func (ml *MemoryLimiter) Delete(ctx context.Context, key string) (bool, error) {
+	shard := ml.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if _, ok := shard.limiters[key]; !ok {
+		return false, nil
+	}
+	delete(shard.limiters, key)
+	return true, nil
+}
@@ -5,6 +5,10 @@ package ratelimit
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -31,13 +35,17 @@ func NewRedisLimiter(client redis.UniversalClient, keyPrefix string) *RedisLimit
 	}
 }
 
+// slidingWindow 滑动窗口大小
+const slidingWindow = time.Second
+
 // slidingWindowScript 滑动窗口限流 Lua 脚本
-// 使用 Redis 的有序集合实现滑动窗口
+// 使用 Redis 的有序集合实现滑动窗口，每个配额对应一条记录，成员格式为 "{时间戳}-{配额上限}-{随机数}:{序号}"，供 Keys 读取配额上限
 const slidingWindowScript = `
 local key = KEYS[1]
 local now = tonumber(ARGV[1])
 local window = tonumber(ARGV[2])
 local limit = tonumber(ARGV[3])
+local n = tonumber(ARGV[4])
 
 -- 移除窗口外的请求记录
 redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
@@ -45,39 +53,119 @@ redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
 -- 获取当前窗口内的请求数
 local count = redis.call('ZCARD', key)
 
-if count < limit then
-    -- 添加当前请求
-    redis.call('ZADD', key, now, now .. '-' .. math.random())
+local allowed = 0
+if count + n <= limit then
+    -- 添加当前请求消耗的配额
+    local member = now .. '-' .. limit .. '-' .. math.random()
+    for i = 1, n do
+        redis.call('ZADD', key, now, member .. ':' .. i)
+    end
     -- 设置过期时间
     redis.call('EXPIRE', key, math.ceil(window / 1000))
-    return 1
-else
-    return 0
+    count = count + n
+    allowed = 1
 end
+
+-- 最早的请求移出窗口的时间（毫秒）
+local reset = 0
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+    reset = tonumber(oldest[2]) + window - now
+end
+
+-- 返回: 是否允许、剩余请求数、最早请求移出窗口的时间
+return {allowed, limit - count, reset}
 `
 
 // Allow 检查是否允许请求（滑动窗口算法）
 func (rl *RedisLimiter) Allow(ctx context.Context, key string, ratePerSecond int, burst int) (bool, error) {
+	result, err := rl.Take(ctx, key, ratePerSecond, burst)
+	return result.Allowed, err
+}
+
+// Take 检查是否允许请求（滑动窗口算法），并返回窗口内剩余请求数和恢复时间
+// 被拒绝时需要等待窗口内最早的请求移出窗口；为简化计算，ResetAfter 同样取最早请求移出窗口的时间
+func (rl *RedisLimiter) Take(ctx context.Context, key string, ratePerSecond int, burst int) (Result, error) {
+	return rl.TakeN(ctx, key, ratePerSecond, burst, 1)
+}
+
+// TakeN 在滑动窗口中原子地获取 n 个配额，窗口内剩余配额不足时不获取
+func (rl *RedisLimiter) TakeN(ctx context.Context, key string, ratePerSecond int, burst int, n int) (Result, error) {
 	if rl.client == nil {
-		return false, fmt.Errorf("redis client is nil")
+		return Result{}, fmt.Errorf("redis client is nil")
 	}
 
 	fullKey := rl.keyPrefix + key
 	now := time.Now().UnixMilli()
-	window := int64(1000) // 1 秒窗口（毫秒）
-	limit := int64(ratePerSecond)
-
-	// 如果 burst 大于 rate，使用 burst 作为限制
-	if burst > ratePerSecond {
-		limit = int64(burst)
+	window := slidingWindow.Milliseconds()
+	limit := windowLimit(ratePerSecond, burst)
+	if int64(n) > limit {
+		return Result{Limit: int(limit)}, ErrCostExceedsLimit
 	}
 
-	result, err := rl.client.Eval(ctx, slidingWindowScript, []string{fullKey}, now, window, limit).Int()
+	values, err := rl.client.Eval(ctx, slidingWindowScript, []string{fullKey}, now, window, limit, max(n, 0)).Int64Slice()
 	if err != nil {
-		return false, fmt.Errorf("redis eval error: %w", err)
+		return Result{}, fmt.Errorf("redis eval error: %w", err)
+	}
+	if len(values) != 3 {
+		return Result{}, fmt.Errorf("redis eval error: unexpected result %v", values)
 	}
 
-	return result == 1, nil
+	reset := time.Duration(max(values[2], 0)) * time.Millisecond
+	result := Result{
+		Allowed:    values[0] == 1,
+		Limit:      int(limit),
+		Remaining:  int(max(values[1], 0)),
+		ResetAfter: reset,
+	}
+	if !result.Allowed {
+		result.RetryAfter = reset
+	}
+	return result, nil
+}
+
+// windowLimit 滑动窗口内的配额上限，取速率和突发容量中较大的值
+func windowLimit(ratePerSecond int, burst int) int64 {
+	return int64(max(ratePerSecond, burst))
+}
+
+// adjustWindowScript 调整滑动窗口配额的 Lua 脚本
+// delta 大于 0 时以当前时间追加记录（不检查上限），小于 0 时移除最新的记录
+const adjustWindowScript = `
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local delta = tonumber(ARGV[4])
+
+if delta > 0 then
+    local member = now .. '-' .. limit .. '-' .. math.random()
+    for i = 1, delta do
+        redis.call('ZADD', key, now, member .. ':' .. i)
+    end
+    redis.call('EXPIRE', key, math.ceil(window / 1000))
+elseif delta < 0 then
+    redis.call('ZPOPMAX', key, -delta)
+end
+return 1
+`
+
+// Adjust 调整滑动窗口中的配额：delta 大于 0 时追加记录（最多追加配额上限条），小于 0 时移除窗口内最新的记录
+func (rl *RedisLimiter) Adjust(ctx context.Context, key string, ratePerSecond int, burst int, delta int) error {
+	if rl.client == nil {
+		return fmt.Errorf("redis client is nil")
+	}
+	if delta == 0 {
+		return nil
+	}
+	limit := windowLimit(ratePerSecond, burst)
+	delta = int(min(int64(delta), limit))
+	err := rl.client.Eval(ctx, adjustWindowScript, []string{rl.keyPrefix + key},
+		time.Now().UnixMilli(), slidingWindow.Milliseconds(), limit, delta).Err()
+	if err != nil {
+		return fmt.Errorf("redis eval error: %w", err)
+	}
+	return nil
 }
 
 // tokenBucketScript 令牌桶限流 Lua 脚本
@@ -148,3 +236,121 @@ func (rl *RedisLimiter) Stats() map[string]interface{} {
 		"keyPrefix": rl.keyPrefix,
 	}
 }
+
+// Keys 通过 SCAN 列出以 prefix 开头的滑动窗口限流键及窗口内的剩余请求数
+// 集群模式下遍历所有主节点；不包含 AllowTokenBucket 使用的令牌桶键。
+// 配额上限从窗口内最新的请求记录中读取，旧版本写入的记录无法确定上限时 Limit 和 Remaining 为 0
+func (rl *RedisLimiter) Keys(ctx context.Context, prefix string, limit int) ([]KeyInfo, error) {
+	if rl.client == nil {
+		return nil, fmt.Errorf("redis client is nil")
+	}
+
+	fullKeys, err := rl.scanKeys(ctx, rl.keyPrefix+escapeGlob(prefix)+"*")
+	if err != nil {
+		return nil, err
+	}
+	tbPrefix := rl.keyPrefix + "tb:"
+	fullKeys = slices.DeleteFunc(fullKeys, func(k string) bool { return strings.HasPrefix(k, tbPrefix) })
+	slices.Sort(fullKeys)
+	fullKeys = slices.Compact(fullKeys)
+	if limit > 0 && len(fullKeys) > limit {
+		fullKeys = fullKeys[:limit]
+	}
+
+	now := time.Now().Add(-slidingWindow).UnixMilli()
+	counts := make([]*redis.IntCmd, len(fullKeys))
+	latest := make([]*redis.StringSliceCmd, len(fullKeys))
+	pipe := rl.client.Pipeline()
+	for i, k := range fullKeys {
+		counts[i] = pipe.ZCount(ctx, k, "("+strconv.FormatInt(now, 10), "+inf")
+		latest[i] = pipe.ZRevRange(ctx, k, 0, 0)
+	}
+	if len(fullKeys) > 0 {
+		// 单个键的错误（如前缀下的其他类型的键）在下面逐个处理
+		_, _ = pipe.Exec(ctx)
+	}
+
+	keys := make([]KeyInfo, 0, len(fullKeys))
+	for i, k := range fullKeys {
+		if err := counts[i].Err(); err != nil {
+			if strings.HasPrefix(err.Error(), "WRONGTYPE") {
+				continue
+			}
+			return nil, fmt.Errorf("redis zcount error: %w", err)
+		}
+		info := KeyInfo{Key: strings.TrimPrefix(k, rl.keyPrefix)}
+		if members := latest[i].Val(); len(members) > 0 {
+			if parts := strings.SplitN(members[0], "-", 3); len(parts) == 3 {
+				info.Limit, _ = strconv.Atoi(parts[1])
+			}
+		}
+		info.Remaining = max(info.Limit-int(counts[i].Val()), 0)
+		keys = append(keys, info)
+	}
+	return keys, nil
+}
+
+// scanKeys 使用 SCAN 查找匹配 pattern 的键，集群模式下遍历所有主节点
+func (rl *RedisLimiter) scanKeys(ctx context.Context, pattern string) ([]string, error) {
+	scan := func(ctx context.Context, client redis.Cmdable) ([]string, error) {
+		var keys []string
+		iter := client.Scan(ctx, 0, pattern, 100).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return nil, fmt.Errorf("redis scan error: %w", err)
+		}
+		return keys, nil
+	}
+
+	cluster, ok := rl.client.(*redis.ClusterClient)
+	if !ok {
+		return scan(ctx, rl.client)
+	}
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		nodeKeys, err := scan(ctx, node)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		keys = append(keys, nodeKeys...)
+		mu.Unlock()
+		return nil
+	})
+	return keys, err
+}
+
+// escapeGlob 转义 SCAN MATCH 模式中的特殊字符
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Delete 清除限流键（包括 AllowTokenBucket 使用的同名令牌桶键），清除后该键的配额立即恢复
+func (rl *RedisLimiter) Delete(ctx context.Context, key string) (bool, error) {
+	if rl.client == nil {
+		return false, fmt.Errorf("redis client is nil")
+	}
+	// 两个键可能位于不同的集群槽位，分别删除
+	var deleted int64
+	for _, k := range []string{rl.keyPrefix + key, rl.keyPrefix + "tb:" + key} {
+		n, err := rl.client.Del(ctx, k).Result()
+		if err != nil {
+			return false, fmt.Errorf("redis del error: %w", err)
+		}
+		deleted += n
+	}
+	return deleted > 0, nil
+}
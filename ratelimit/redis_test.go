@@ -8,6 +8,9 @@
 // 2. 空客户端错误处理
 // 3. 统计信息获取
 // 4. Lua 脚本语法验证
+// 5. 滑动窗口的剩余配额与恢复时间（使用 miniredis）
+// 6. 通过 SCAN 枚举限流键、清除限流键（使用 miniredis）
+// 7. 按请求消耗获取配额（TakeN）与调整配额（Adjust）（使用 miniredis）
 //
 // 注意：需要真实 Redis 连接的集成测试在 redis_integration_test.go 中
 // 运行集成测试：go test -tags=integration ./ratelimit/...
@@ -16,7 +19,12 @@ package ratelimit
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 )
 
 // ==================== RedisLimiter 单元测试（不需要 Redis 连接） ====================
@@ -175,3 +183,172 @@ func containsStringHelper(s, substr string) bool {
 	}
 	return false
 }
+
+// TestRedisLimiter_Take 测试滑动窗口的剩余配额与恢复时间
+//
+// 【功能点】验证 Take 返回窗口内剩余请求数，被拒绝时返回最早请求移出窗口的时间
+// 【测试流程】
+//  1. 使用 miniredis，limit=3，连续请求 3 次，断言 Remaining 依次为 2、1、0
+//  2. 第 4 次请求被拒绝，断言 RetryAfter 在 (0, 1s] 之间
+func TestRedisLimiter_Take(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	limiter := NewRedisLimiter(client, "test:")
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Take(ctx, "take-key", 3, 3)
+		if err != nil {
+			t.Fatalf("Take 返回错误: %v", err)
+		}
+		if !result.Allowed || result.Limit != 3 || result.Remaining != 2-i {
+			t.Errorf("请求 %d: %+v, 期望允许、Limit=3、Remaining=%d", i+1, result, 2-i)
+		}
+	}
+
+	result, err := limiter.Take(ctx, "take-key", 3, 3)
+	if err != nil {
+		t.Fatalf("Take 返回错误: %v", err)
+	}
+	if result.Allowed || result.Remaining != 0 {
+		t.Errorf("第 4 次请求应被拒绝且 Remaining=0, 实际 %+v", result)
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > time.Second {
+		t.Errorf("RetryAfter = %v, 期望在 (0, 1s] 之间", result.RetryAfter)
+	}
+}
+
+// TestRedisLimiter_TakeN 测试按请求消耗获取配额
+//
+// 【功能点】验证 TakeN 原子地在窗口内记录 n 个配额，配额不足时不记录，n 超过配额上限时返回 ErrCostExceedsLimit
+// 【测试流程】
+//  1. 使用 miniredis，limit=10，消耗 5 的请求连续 2 次，断言 Remaining 依次为 5、0，第 3 次被拒绝
+//  2. 另一个键混合消耗 3、4、4、3，断言第 3 个请求被拒绝且不记录，第 4 个请求允许，Keys 返回剩余 0
+//  3. 消耗 11 的请求返回 ErrCostExceedsLimit
+func TestRedisLimiter_TakeN(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	limiter := NewRedisLimiter(client, "test:")
+	ctx := context.Background()
+	for i, want := range []int{5, 0} {
+		result, err := limiter.TakeN(ctx, "cost-key", 10, 10, 5)
+		if err != nil {
+			t.Fatalf("TakeN 返回错误: %v", err)
+		}
+		if !result.Allowed || result.Limit != 10 || result.Remaining != want {
+			t.Errorf("请求 %d: %+v, 期望允许、Limit=10、Remaining=%d", i+1, result, want)
+		}
+	}
+	if result, _ := limiter.TakeN(ctx, "cost-key", 10, 10, 5); result.Allowed {
+		t.Errorf("第 3 次请求应被拒绝, 实际 %+v", result)
+	}
+
+	for i, step := range []struct {
+		cost      int
+		allowed   bool
+		remaining int
+	}{{3, true, 7}, {4, true, 3}, {4, false, 3}, {3, true, 0}} {
+		result, err := limiter.TakeN(ctx, "mixed-key", 10, 10, step.cost)
+		if err != nil {
+			t.Fatalf("TakeN 返回错误: %v", err)
+		}
+		if result.Allowed != step.allowed || result.Remaining != step.remaining {
+			t.Errorf("请求 %d（消耗 %d）: %+v, 期望 Allowed=%v、Remaining=%d", i+1, step.cost, result, step.allowed, step.remaining)
+		}
+	}
+	keys, err := limiter.Keys(ctx, "mixed-key", 0)
+	if err != nil || len(keys) != 1 || keys[0].Limit != 10 || keys[0].Remaining != 0 {
+		t.Errorf("Keys = %+v, %v, 期望 Limit=10、Remaining=0", keys, err)
+	}
+
+	if _, err := limiter.TakeN(ctx, "big-key", 10, 10, 11); !errors.Is(err, ErrCostExceedsLimit) {
+		t.Errorf("消耗超过配额上限时应返回 ErrCostExceedsLimit, 实际 %v", err)
+	}
+}
+
+// TestRedisLimiter_Adjust 测试调整已获取的配额
+//
+// 【功能点】验证 Adjust 额外记录配额（不检查上限）和移除最新的记录
+// 【测试流程】
+//  1. 使用 miniredis，limit=10，获取 2 个配额后额外扣除 8 个，断言消耗 1 的请求被拒绝
+//  2. 归还 3 个，断言 Keys 返回剩余 3，消耗 3 的请求允许
+func TestRedisLimiter_Adjust(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	limiter := NewRedisLimiter(client, "test:")
+	ctx := context.Background()
+	if _, err := limiter.TakeN(ctx, "adjust-key", 10, 10, 2); err != nil {
+		t.Fatalf("TakeN 返回错误: %v", err)
+	}
+	if err := limiter.Adjust(ctx, "adjust-key", 10, 10, 8); err != nil {
+		t.Fatalf("Adjust 返回错误: %v", err)
+	}
+	if result, _ := limiter.TakeN(ctx, "adjust-key", 10, 10, 1); result.Allowed {
+		t.Fatalf("额外扣除后请求应被拒绝, 实际 %+v", result)
+	}
+
+	if err := limiter.Adjust(ctx, "adjust-key", 10, 10, -3); err != nil {
+		t.Fatalf("Adjust 返回错误: %v", err)
+	}
+	if keys, _ := limiter.Keys(ctx, "adjust-key", 0); len(keys) != 1 || keys[0].Remaining != 3 {
+		t.Errorf("归还后剩余应为 3, 实际 %+v", keys)
+	}
+	if result, _ := limiter.TakeN(ctx, "adjust-key", 10, 10, 3); !result.Allowed {
+		t.Errorf("归还后消耗 3 的请求应允许, 实际 %+v", result)
+	}
+}
+
+// TestRedisLimiter_KeysAndDelete 测试枚举和清除限流键
+//
+// 【功能点】验证 Keys 通过 SCAN 按前缀返回键和窗口内剩余请求数，前缀中的通配符按字面匹配，Delete 后配额立即恢复
+// 【测试流程】
+//  1. 使用 miniredis，ip:a 请求 2 次（limit=2），ip:b 请求 1 次，另有令牌桶键 tb:ip:c 和前缀外的键
+//  2. 以前缀 ip: 枚举，断言返回 ip:a（剩余 0）、ip:b（剩余 1），不含令牌桶键；前缀 ip:* 不匹配任何键
+//  3. ip:a 被限流，Delete 后断言请求立即放行，删除不存在的键返回 false
+func TestRedisLimiter_KeysAndDelete(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	limiter := NewRedisLimiter(client, "test:")
+	ctx := context.Background()
+	for _, key := range []string{"ip:a", "ip:a", "ip:b"} {
+		if _, err := limiter.Take(ctx, key, 2, 2); err != nil {
+			t.Fatalf("Take 返回错误: %v", err)
+		}
+	}
+	if _, err := limiter.AllowTokenBucket(ctx, "ip:c", 2, 2); err != nil {
+		t.Fatalf("AllowTokenBucket 返回错误: %v", err)
+	}
+	mr.Set("other:ip:d", "1")
+
+	keys, err := limiter.Keys(ctx, "ip:", 0)
+	if err != nil {
+		t.Fatalf("Keys 返回错误: %v", err)
+	}
+	want := []KeyInfo{{Key: "ip:a", Limit: 2, Remaining: 0}, {Key: "ip:b", Limit: 2, Remaining: 1}}
+	if len(keys) != len(want) || keys[0] != want[0] || keys[1] != want[1] {
+		t.Errorf("Keys = %+v, want %+v", keys, want)
+	}
+	if keys, _ := limiter.Keys(ctx, "ip:*", 0); len(keys) != 0 {
+		t.Errorf("前缀中的 * 应按字面匹配, 实际 %+v", keys)
+	}
+
+	if result, _ := limiter.Take(ctx, "ip:a", 2, 2); result.Allowed {
+		t.Fatal("ip:a 窗口内请求数已达上限，应被限流")
+	}
+	if deleted, err := limiter.Delete(ctx, "ip:a"); err != nil || !deleted {
+		t.Fatalf("Delete = %v, %v, want true, nil", deleted, err)
+	}
+	if result, _ := limiter.Take(ctx, "ip:a", 2, 2); !result.Allowed {
+		t.Error("Delete 后配额应立即恢复")
+	}
+	if deleted, _ := limiter.Delete(ctx, "ip:missing"); deleted {
+		t.Error("删除不存在的键应返回 false")
+	}
+}
@@ -0,0 +1,141 @@
+package seeds
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zzsen/gin_core/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RunOptions 数据填充执行选项
+type RunOptions struct {
+	// Force 忽略执行记录，重新执行与运行环境匹配的所有数据填充
+	Force bool
+	// Fresh 执行前先清空数据填充通过 WithTables 声明的表，同时忽略执行记录
+	Fresh bool
+}
+
+// Result 数据填充执行结果
+type Result struct {
+	Applied []string         // 本次执行成功的数据填充名称
+	Skipped []string         // 当前版本已执行过而跳过的数据填充名称
+	Failed  []string         // 执行失败的数据填充名称
+	Errors  map[string]error // 执行失败的原因，键为数据填充名称
+}
+
+// Runner 数据填充执行器
+type Runner struct {
+	db      *gorm.DB
+	seeders []Seeder
+}
+
+// NewRunner 创建数据填充执行器
+// 参数：
+//   - db: 数据库连接
+//   - seeders: 按执行顺序排列的数据填充
+//
+// 返回：
+//   - *Runner: 数据填充执行器
+//   - error: 名称为空或重复、未指定运行环境、Fn 为 nil 时返回错误
+func NewRunner(db *gorm.DB, seeders []Seeder) (*Runner, error) {
+	seen := make(map[string]struct{}, len(seeders))
+	for _, s := range seeders {
+		if s.Name == "" {
+			return nil, fmt.Errorf("数据填充名称不能为空")
+		}
+		if _, ok := seen[s.Name]; ok {
+			return nil, fmt.Errorf("数据填充名称重复: %s", s.Name)
+		}
+		if len(s.Envs) == 0 {
+			return nil, fmt.Errorf("数据填充 %s 未指定运行环境", s.Name)
+		}
+		if s.Fn == nil {
+			return nil, fmt.Errorf("数据填充 %s 未设置执行函数", s.Name)
+		}
+		seen[s.Name] = struct{}{}
+	}
+	return &Runner{db: db, seeders: seeders}, nil
+}
+
+// Run 按注册顺序执行与 env 匹配的数据填充
+// 每个数据填充在单独的事务中执行并写入执行记录，失败时回滚该数据填充并继续执行后续的数据填充；
+// 不匹配 env 的数据填充不执行，也不计入结果
+// 参数：
+//   - env: 当前运行环境
+//   - opts: 执行选项
+//
+// 返回：
+//   - Result: 执行结果
+//   - error: 创建或读取执行记录失败时返回错误
+func (r *Runner) Run(env string, opts RunOptions) (Result, error) {
+	result := Result{Errors: map[string]error{}}
+	var matched []Seeder
+	for _, s := range r.seeders {
+		if s.MatchEnv(env) {
+			matched = append(matched, s)
+		}
+	}
+	if len(matched) == 0 {
+		return result, nil
+	}
+
+	applied, err := r.loadApplied()
+	if err != nil {
+		return result, err
+	}
+	for _, s := range matched {
+		checksum := s.Checksum()
+		if _, ok := applied[s.Name+"\x00"+checksum]; ok && !opts.Force && !opts.Fresh {
+			result.Skipped = append(result.Skipped, s.Name)
+			continue
+		}
+		start := time.Now()
+		if err := r.apply(s, checksum, opts.Fresh); err != nil {
+			logger.Error("[数据填充] 执行 %s 失败: %v", s.Name, err)
+			result.Failed = append(result.Failed, s.Name)
+			result.Errors[s.Name] = err
+			continue
+		}
+		logger.Info("[数据填充] 已执行 %s, 版本: %s, 耗时: %v", s.Name, s.Version, time.Since(start))
+		result.Applied = append(result.Applied, s.Name)
+	}
+	logger.Info("[数据填充] 完成, 运行环境: %s, 已执行: %d, 已跳过: %d, 失败: %d",
+		env, len(result.Applied), len(result.Skipped), len(result.Failed))
+	return result, nil
+}
+
+// loadApplied 创建执行记录表并读取已执行的数据填充，键为 "{名称}\x00{校验和}"
+func (r *Runner) loadApplied() (map[string]struct{}, error) {
+	if err := r.db.AutoMigrate(&Record{}); err != nil {
+		return nil, fmt.Errorf("创建数据填充记录表失败: %w", err)
+	}
+	var records []Record
+	if err := r.db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("读取数据填充记录失败: %w", err)
+	}
+	applied := make(map[string]struct{}, len(records))
+	for _, record := range records {
+		applied[record.Name+"\x00"+record.Checksum] = struct{}{}
+	}
+	return applied, nil
+}
+
+// apply 在事务中执行单个数据填充并写入记录，fresh 为 true 时先清空声明的表
+func (r *Runner) apply(s Seeder, checksum string, fresh bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if fresh {
+			for _, table := range s.Tables {
+				if err := tx.Exec("DELETE FROM ?", clause.Table{Name: table}).Error; err != nil {
+					return fmt.Errorf("清空表 %s 失败: %w", table, err)
+				}
+			}
+		}
+		if err := s.Fn(tx); err != nil {
+			return err
+		}
+		// 强制执行时记录已存在，按主键更新执行时间
+		return tx.Save(&Record{Name: s.Name, Checksum: checksum, Version: s.Version, AppliedAt: time.Now()}).Error
+	})
+}
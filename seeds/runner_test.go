@@ -0,0 +1,222 @@
+// Package seeds 数据填充测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含数据填充执行器的单元测试，使用 SQLite 内存数据库，不需要 MySQL。
+//
+// 测试覆盖内容：
+// 1. 首次执行写入数据和记录，再次执行时跳过，版本变化后重新执行
+// 2. 只执行与运行环境匹配的数据填充
+// 3. 执行失败时回滚该数据填充且不写入记录，继续执行后续的数据填充
+// 4. 强制执行忽略执行记录，fresh 模式先清空声明的表
+// 5. 名称为空或重复、未指定运行环境时返回错误
+//
+// 运行测试：go test -v ./seeds/...
+// ==================================================
+package seeds
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// seedUser 测试使用的用户表
+type seedUser struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+}
+
+// newTestDB 创建包含用户表的 SQLite 内存数据库
+func newTestDB(t *testing.T) *gorm.DB {
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&seedUser{}))
+	return db
+}
+
+// insertUsers 返回插入指定用户的数据填充函数，calls 记录执行次数
+func insertUsers(calls *int, names ...string) Func {
+	return func(db *gorm.DB) error {
+		*calls++
+		for _, name := range names {
+			if err := db.Create(&seedUser{Name: name}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// userNames 读取用户表中的用户名，按 ID 排序
+func userNames(t *testing.T, db *gorm.DB) []string {
+	var names []string
+	require.NoError(t, db.Model(&seedUser{}).Order("id").Pluck("name", &names).Error)
+	return names
+}
+
+// run 创建执行器并在 env 环境中执行
+func run(t *testing.T, db *gorm.DB, seeders []Seeder, env string, opts RunOptions) Result {
+	runner, err := NewRunner(db, seeders)
+	require.NoError(t, err)
+	result, err := runner.Run(env, opts)
+	require.NoError(t, err)
+	return result
+}
+
+// TestRunner_Idempotent 测试重复执行与版本变化
+//
+// 【功能点】验证首次执行写入数据和记录，再次执行时跳过，版本变化后重新执行
+// 【测试流程】
+//  1. 注册 users 数据填充，在 dev 环境执行，断言已执行、写入数据和一条记录
+//  2. 再次执行，断言跳过且数据填充函数只执行了一次
+//  3. 以 WithVersion("2") 重新注册后执行，断言重新执行且记录中有两个校验和
+func TestRunner_Idempotent(t *testing.T) {
+	db := newTestDB(t)
+	var calls int
+	seeder := Seeder{Name: "users", Envs: []string{"dev"}, Fn: insertUsers(&calls, "alice")}
+
+	result := run(t, db, []Seeder{seeder}, "dev", RunOptions{})
+	assert.Equal(t, []string{"users"}, result.Applied)
+	assert.Empty(t, result.Skipped)
+	assert.Equal(t, []string{"alice"}, userNames(t, db))
+	var count int64
+	require.NoError(t, db.Model(&Record{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	result = run(t, db, []Seeder{seeder}, "dev", RunOptions{})
+	assert.Empty(t, result.Applied)
+	assert.Equal(t, []string{"users"}, result.Skipped)
+	assert.Equal(t, 1, calls)
+
+	WithVersion("2")(&seeder)
+	result = run(t, db, []Seeder{seeder}, "dev", RunOptions{})
+	assert.Equal(t, []string{"users"}, result.Applied)
+	assert.Equal(t, 2, calls)
+	var records []Record
+	require.NoError(t, db.Find(&records).Error)
+	require.Len(t, records, 2)
+	assert.NotEqual(t, records[0].Checksum, records[1].Checksum)
+}
+
+// TestRunner_EnvFilter 测试按运行环境过滤
+//
+// 【功能点】验证只执行与运行环境匹配的数据填充，不匹配的不计入结果
+// 【测试流程】注册 dev 和 dev/test 两个数据填充，在 test 环境执行断言只执行后者，在 prod 环境执行断言没有执行且未创建记录表
+func TestRunner_EnvFilter(t *testing.T) {
+	db := newTestDB(t)
+	var devCalls, sharedCalls int
+	seeders := []Seeder{
+		{Name: "dev_only", Envs: []string{"dev"}, Fn: insertUsers(&devCalls, "dev")},
+		{Name: "shared", Envs: []string{"dev", "test"}, Fn: insertUsers(&sharedCalls, "shared")},
+	}
+
+	result := run(t, db, seeders, "test", RunOptions{})
+	assert.Equal(t, []string{"shared"}, result.Applied)
+	assert.Empty(t, result.Skipped)
+	assert.Equal(t, 0, devCalls)
+
+	t.Run("prod", func(t *testing.T) {
+		db := newTestDB(t)
+		result := run(t, db, seeders, "prod", RunOptions{Force: true})
+		assert.Empty(t, result.Applied)
+		assert.Empty(t, userNames(t, db))
+		assert.False(t, db.Migrator().HasTable(TableName))
+	})
+}
+
+// TestRunner_Failure 测试执行失败
+//
+// 【功能点】验证执行失败时回滚该数据填充且不写入记录，后续的数据填充继续执行，下次执行时重试
+// 【测试流程】
+//  1. 注册插入数据后返回错误的 broken 和正常的 users，执行断言 broken 失败、数据被回滚，users 已执行
+//  2. 修复 broken 后再次执行，断言 broken 重新执行、users 跳过
+func TestRunner_Failure(t *testing.T) {
+	db := newTestDB(t)
+	var brokenCalls, usersCalls int
+	errBroken := errors.New("数据有误")
+	seeders := []Seeder{
+		{Name: "broken", Envs: []string{"dev"}, Fn: func(db *gorm.DB) error {
+			_ = insertUsers(&brokenCalls, "partial")(db)
+			return errBroken
+		}},
+		{Name: "users", Envs: []string{"dev"}, Fn: insertUsers(&usersCalls, "alice")},
+	}
+
+	result := run(t, db, seeders, "dev", RunOptions{})
+	assert.Equal(t, []string{"broken"}, result.Failed)
+	assert.ErrorIs(t, result.Errors["broken"], errBroken)
+	assert.Equal(t, []string{"users"}, result.Applied)
+	assert.Equal(t, []string{"alice"}, userNames(t, db))
+
+	seeders[0].Fn = insertUsers(&brokenCalls, "bob")
+	result = run(t, db, seeders, "dev", RunOptions{})
+	assert.Equal(t, []string{"broken"}, result.Applied)
+	assert.Equal(t, []string{"users"}, result.Skipped)
+	assert.Empty(t, result.Failed)
+	assert.Equal(t, []string{"alice", "bob"}, userNames(t, db))
+}
+
+// TestRunner_ForceAndFresh 测试强制执行和 fresh 模式
+//
+// 【功能点】验证强制执行忽略执行记录，fresh 模式先清空 WithTables 声明的表，未声明的表不受影响
+// 【测试流程】
+//  1. 执行 users 数据填充（声明 seed_users 表），手动插入一条数据
+//  2. 强制执行，断言重新执行且数据累加，记录仍只有一条
+//  3. fresh 模式执行，断言表被清空后只剩数据填充写入的数据
+func TestRunner_ForceAndFresh(t *testing.T) {
+	db := newTestDB(t)
+	var calls int
+	seeder := Seeder{Name: "users", Envs: []string{"dev"}, Fn: insertUsers(&calls, "alice")}
+	WithTables("seed_users")(&seeder)
+
+	run(t, db, []Seeder{seeder}, "dev", RunOptions{})
+	require.NoError(t, db.Create(&seedUser{Name: "manual"}).Error)
+
+	result := run(t, db, []Seeder{seeder}, "dev", RunOptions{Force: true})
+	assert.Equal(t, []string{"users"}, result.Applied)
+	assert.Equal(t, []string{"alice", "manual", "alice"}, userNames(t, db))
+	var count int64
+	require.NoError(t, db.Model(&Record{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	result = run(t, db, []Seeder{seeder}, "dev", RunOptions{Fresh: true})
+	assert.Equal(t, []string{"users"}, result.Applied)
+	assert.Equal(t, []string{"alice"}, userNames(t, db))
+	assert.Equal(t, 3, calls)
+}
+
+// TestNewRunner_Invalid 测试非法的数据填充
+//
+// 【功能点】验证名称为空或重复、未指定运行环境、未设置执行函数时返回错误
+// 【测试流程】分别构造非法的数据填充列表，断言 NewRunner 返回包含原因的错误
+func TestNewRunner_Invalid(t *testing.T) {
+	fn := func(db *gorm.DB) error { return nil }
+	tests := []struct {
+		name    string
+		seeders []Seeder
+		wantErr string
+	}{
+		{"empty name", []Seeder{{Envs: []string{"dev"}, Fn: fn}}, "名称不能为空"},
+		{"duplicate", []Seeder{{Name: "a", Envs: []string{"dev"}, Fn: fn}, {Name: "a", Envs: []string{"dev"}, Fn: fn}}, "名称重复: a"},
+		{"no envs", []Seeder{{Name: "a", Fn: fn}}, "未指定运行环境"},
+		{"nil fn", []Seeder{{Name: "a", Envs: []string{"dev"}}}, "未设置执行函数"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRunner(nil, tt.seeders)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Package seeds 提供开发、测试环境的数据填充功能
+//
+// 数据填充函数在启动前通过 Register（或 core.RegisterSeeder）注册，声明可运行的环境，
+// 启动时在数据库初始化和迁移完成后，按注册顺序执行与当前运行环境匹配的数据填充，每个数据填充在单独的事务中执行。
+// 执行记录保存在 seed_history 表中，以名称和校验和（由名称和版本计算）为键：
+// 同一版本只执行一次，版本变化后重新执行。
+package seeds
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TableName 数据填充记录表名
+const TableName = "seed_history"
+
+// Func 数据填充函数，db 为事务
+type Func func(db *gorm.DB) error
+
+// Seeder 数据填充
+type Seeder struct {
+	// Name 数据填充名称，全局唯一
+	Name string
+	// Envs 可运行的环境（对应 app.Env），不在其中的环境不执行
+	Envs []string
+	// Fn 数据填充函数
+	Fn Func
+	// Version 版本，变化后重新执行
+	Version string
+	// Tables 数据填充写入的表，-seed-fresh 时先清空这些表
+	Tables []string
+}
+
+// Option 数据填充选项
+type Option func(*Seeder)
+
+// WithVersion 设置数据填充的版本，修改数据填充函数后更新版本即可在下次启动时重新执行
+func WithVersion(version string) Option {
+	return func(s *Seeder) {
+		s.Version = version
+	}
+}
+
+// WithTables 声明数据填充写入的表，-seed-fresh 时先清空这些表再执行
+func WithTables(tables ...string) Option {
+	return func(s *Seeder) {
+		s.Tables = append(s.Tables, tables...)
+	}
+}
+
+// Checksum 数据填充的校验和，由名称和版本计算
+func (s Seeder) Checksum() string {
+	sum := sha256.Sum256([]byte(s.Name + "\x00" + s.Version))
+	return hex.EncodeToString(sum[:])
+}
+
+// MatchEnv 判断数据填充是否可以在 env 环境中运行
+func (s Seeder) MatchEnv(env string) bool {
+	return slices.Contains(s.Envs, env)
+}
+
+// Record 数据填充执行记录
+type Record struct {
+	Name      string    `gorm:"primaryKey;size:255"`
+	Checksum  string    `gorm:"primaryKey;size:64"`
+	Version   string    `gorm:"size:255"`
+	AppliedAt time.Time `gorm:"not null"` // 执行时间
+}
+
+// TableName 指定 GORM 表名
+func (Record) TableName() string {
+	return TableName
+}
+
+var (
+	mu         sync.Mutex
+	registered []Seeder
+)
+
+// Register 注册数据填充
+// 应在 Start 之前调用（如 main 函数或 init 函数中），数据填充按注册顺序执行。
+// 名称重复、未指定运行环境不在注册时报错，而是在执行时报告
+// 参数：
+//   - name: 数据填充名称，全局唯一
+//   - envs: 可运行的环境
+//   - fn: 数据填充函数
+//   - opts: 数据填充选项（WithVersion、WithTables）
+func Register(name string, envs []string, fn Func, opts ...Option) {
+	s := Seeder{Name: name, Envs: append([]string(nil), envs...), Fn: fn}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	registered = append(registered, s)
+}
+
+// Registered 获取已注册的数据填充，按注册顺序返回
+func Registered() []Seeder {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Seeder(nil), registered...)
+}
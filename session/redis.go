@@ -0,0 +1,57 @@
+// Package session 提供基于 Cookie 的服务端会话功能
+// 本文件实现基于 Redis 的会话存储
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore Redis 会话存储
+// 适用于分布式部署场景，多个实例共享会话数据
+type RedisStore struct {
+	client    redis.UniversalClient
+	keyPrefix string
+}
+
+// NewRedisStore 创建 Redis 会话存储
+// client: Redis 客户端
+// keyPrefix: 键前缀，如 "session:"
+func NewRedisStore(client redis.UniversalClient, keyPrefix string) *RedisStore {
+	return &RedisStore{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+// Get 读取会话数据，使用 GETEX 在读取的同时顺延过期时间
+func (rs *RedisStore) Get(ctx context.Context, id string, ttl time.Duration) ([]byte, error) {
+	data, err := rs.client.GetEx(ctx, rs.keyPrefix+id, ttl).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Set 保存会话数据
+// 会话数据整体写入，并发保存同一会话时以最后一次写入为准，不会出现部分写入
+func (rs *RedisStore) Set(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	return rs.client.Set(ctx, rs.keyPrefix+id, data, ttl).Err()
+}
+
+// Delete 删除会话
+func (rs *RedisStore) Delete(ctx context.Context, id string) error {
+	return rs.client.Del(ctx, rs.keyPrefix+id).Err()
+}
+
+// Close 关闭存储
+// Redis 客户端由调用方管理，这里不关闭客户端
+func (rs *RedisStore) Close() error {
+	return nil
+}
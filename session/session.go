@@ -0,0 +1,261 @@
+// Package session 提供基于 Cookie 的服务端会话功能
+// 本文件实现会话对象与会话管理器
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContextKey 会话对象在 gin.Context 中的存储键
+const ContextKey = "_ginCore_session"
+
+// Options 会话 Cookie 选项
+type Options struct {
+	CookieName string        // Cookie 名称
+	Domain     string        // Cookie 作用域名
+	Path       string        // Cookie 作用路径
+	MaxAge     int           // 会话有效期（秒）
+	Secure     bool          // 是否仅在 HTTPS 下发送
+	HttpOnly   bool          // 是否禁止 JavaScript 访问
+	SameSite   http.SameSite // SameSite 属性
+}
+
+// Manager 会话管理器
+// 负责从请求中加载会话，以及会话 Cookie 的写入
+type Manager struct {
+	store   Store
+	options Options
+}
+
+// NewManager 创建会话管理器
+// 参数：
+//   - store: 会话存储
+//   - options: Cookie 选项
+//
+// 返回：
+//   - *Manager: 会话管理器
+func NewManager(store Store, options Options) *Manager {
+	return &Manager{
+		store:   store,
+		options: options,
+	}
+}
+
+// Store 获取会话存储
+func (m *Manager) Store() Store {
+	return m.store
+}
+
+// Load 从请求中加载会话
+//
+// 执行流程：
+// 1. 读取会话 Cookie，不存在时创建新会话
+// 2. 从存储中读取会话数据并顺延过期时间，数据不存在时创建新会话
+// 3. 已有会话重新写入 Cookie，使 Cookie 过期时间同步顺延
+//
+// 存储读取失败或数据损坏时同样返回一个新会话，并附带错误信息
+func (m *Manager) Load(w http.ResponseWriter, r *http.Request) (*Session, error) {
+	cookie, err := r.Cookie(m.options.CookieName)
+	if err != nil || cookie.Value == "" {
+		return m.newSession(w, r), nil
+	}
+
+	data, err := m.store.Get(r.Context(), cookie.Value, m.ttl())
+	if err != nil {
+		return m.newSession(w, r), fmt.Errorf("【会话】读取会话失败: %w", err)
+	}
+	if data == nil {
+		return m.newSession(w, r), nil
+	}
+
+	values := make(map[string]any)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return m.newSession(w, r), fmt.Errorf("【会话】会话数据解码失败: %w", err)
+	}
+
+	s := &Session{
+		id:      cookie.Value,
+		values:  values,
+		manager: m,
+		w:       w,
+		ctx:     r.Context(),
+	}
+	m.setCookie(w, s.id, m.options.MaxAge)
+	return s, nil
+}
+
+// newSession 创建尚未持久化的新会话，首次调用 Save 时才会写入存储和 Cookie
+func (m *Manager) newSession(w http.ResponseWriter, r *http.Request) *Session {
+	return &Session{
+		id:      generateID(),
+		values:  make(map[string]any),
+		isNew:   true,
+		manager: m,
+		w:       w,
+		ctx:     r.Context(),
+	}
+}
+
+// ttl 会话在存储中的过期时间
+func (m *Manager) ttl() time.Duration {
+	return time.Duration(m.options.MaxAge) * time.Second
+}
+
+// setCookie 写入会话 Cookie，maxAge < 0 表示删除 Cookie
+// 同一响应中多次写入时只保留最后一次，避免出现多个同名 Set-Cookie 头
+func (m *Manager) setCookie(w http.ResponseWriter, id string, maxAge int) {
+	header := w.Header()
+	prefix := m.options.CookieName + "="
+	existing := header.Values("Set-Cookie")
+	header.Del("Set-Cookie")
+	for _, v := range existing {
+		if !strings.HasPrefix(v, prefix) {
+			header.Add("Set-Cookie", v)
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     m.options.CookieName,
+		Value:    id,
+		Domain:   m.options.Domain,
+		Path:     m.options.Path,
+		MaxAge:   maxAge,
+		Secure:   m.options.Secure,
+		HttpOnly: m.options.HttpOnly,
+		SameSite: m.options.SameSite,
+	})
+}
+
+// Session 会话对象
+// 会话数据以 JSON 编码后整体保存，因此读取时数字类型为 float64、结构体为 map[string]any。
+// 同一请求内的并发访问是安全的；不同请求并发保存同一会话时以最后一次保存为准。
+type Session struct {
+	mu        sync.RWMutex
+	id        string
+	values    map[string]any
+	isNew     bool
+	destroyed bool
+	manager   *Manager
+	w         http.ResponseWriter
+	ctx       context.Context
+}
+
+// ID 获取会话 ID
+func (s *Session) ID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.id
+}
+
+// IsNew 是否为尚未保存过的新会话
+func (s *Session) IsNew() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.isNew
+}
+
+// Get 获取会话值
+func (s *Session) Get(key string) (any, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// Set 设置会话值，需调用 Save 后才会持久化
+func (s *Session) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+// Delete 删除会话值，需调用 Save 后才会持久化
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+// Save 保存会话数据到存储并写入会话 Cookie
+// 必须在响应体写出之前调用，否则 Cookie 无法写入响应头
+func (s *Session) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.destroyed {
+		return fmt.Errorf("【会话】会话已销毁, id: %s", s.id)
+	}
+	if err := s.save(); err != nil {
+		return err
+	}
+	s.manager.setCookie(s.w, s.id, s.manager.options.MaxAge)
+	return nil
+}
+
+// save 编码并写入存储，调用方需持有写锁
+func (s *Session) save() error {
+	data, err := json.Marshal(s.values)
+	if err != nil {
+		return fmt.Errorf("【会话】会话数据编码失败: %w", err)
+	}
+	if err := s.manager.store.Set(s.ctx, s.id, data, s.manager.ttl()); err != nil {
+		return fmt.Errorf("【会话】保存会话失败: %w", err)
+	}
+	s.isNew = false
+	return nil
+}
+
+// Destroy 销毁会话，删除存储中的数据并清除客户端 Cookie
+func (s *Session) Destroy() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.manager.store.Delete(s.ctx, s.id); err != nil {
+		return fmt.Errorf("【会话】删除会话失败: %w", err)
+	}
+	s.values = make(map[string]any)
+	s.destroyed = true
+	s.manager.setCookie(s.w, "", -1)
+	return nil
+}
+
+// RegenerateID 重新生成会话 ID
+// 用于登录等权限变更场景，防止会话固定攻击（Session Fixation）。
+// 会话数据迁移到新 ID 下并立即保存，旧 ID 随即失效。
+func (s *Session) RegenerateID() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.destroyed {
+		return fmt.Errorf("【会话】会话已销毁, id: %s", s.id)
+	}
+
+	oldID := s.id
+	s.id = generateID()
+	if err := s.save(); err != nil {
+		s.id = oldID
+		return err
+	}
+	if err := s.manager.store.Delete(s.ctx, oldID); err != nil {
+		return fmt.Errorf("【会话】删除旧会话失败: %w", err)
+	}
+	s.manager.setCookie(s.w, s.id, s.manager.options.MaxAge)
+	return nil
+}
+
+// generateID 生成 256 位随机会话 ID
+func generateID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("【会话】生成会话 ID 失败: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,304 @@
+// Package session 会话测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含会话存储和会话对象的单元测试，使用 miniredis 模拟 Redis，不需要真实 Redis 连接。
+//
+// 测试覆盖内容：
+// 1. 内存存储的读写、滑动过期和过期清理
+// 2. Redis 存储的读写和滑动过期
+// 3. 新会话首次 Save 时写入存储和 Cookie
+// 4. Destroy 清除存储数据和 Cookie
+// 5. RegenerateID 使旧 ID 失效
+// 6. 并发保存同一会话不会损坏编码
+//
+// 运行测试：go test -v ./session/...
+// ==================================================
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testOptions 测试用 Cookie 选项
+var testOptions = Options{
+	CookieName: "sid",
+	Path:       "/",
+	MaxAge:     60,
+	HttpOnly:   true,
+	SameSite:   http.SameSiteLaxMode,
+}
+
+// newTestRedisStore 创建基于 miniredis 的 Redis 存储
+func newTestRedisStore(t *testing.T) (*miniredis.Miniredis, *RedisStore) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return mr, NewRedisStore(client, "session:")
+}
+
+// loadWithCookie 携带指定会话 Cookie 加载会话
+func loadWithCookie(t *testing.T, m *Manager, id string) (*Session, *httptest.ResponseRecorder) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if id != "" {
+		r.AddCookie(&http.Cookie{Name: testOptions.CookieName, Value: id})
+	}
+	w := httptest.NewRecorder()
+	s, err := m.Load(w, r)
+	require.NoError(t, err)
+	return s, w
+}
+
+// responseCookie 获取响应中的会话 Cookie
+func responseCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	resp := http.Response{Header: w.Header()}
+	for _, c := range resp.Cookies() {
+		if c.Name == testOptions.CookieName {
+			return c
+		}
+	}
+	return nil
+}
+
+// TestMemoryStore_GetSetDelete 测试内存存储的基本读写
+//
+// 【功能点】验证 Set/Get/Delete 以及过期数据不可读
+// 【测试流程】写入后读取，删除后读取为空；写入短 TTL 数据，过期后读取为空
+func TestMemoryStore_GetSetDelete(t *testing.T) {
+	store := NewMemoryStore(time.Minute)
+	defer store.Close()
+	ctx := context.Background()
+
+	require.NoError(t, store.Set(ctx, "a", []byte(`{"k":"v"}`), time.Minute))
+	data, err := store.Get(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, `{"k":"v"}`, string(data))
+
+	require.NoError(t, store.Delete(ctx, "a"))
+	data, err = store.Get(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	require.NoError(t, store.Set(ctx, "b", []byte(`{}`), 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	data, err = store.Get(ctx, "b", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, data)
+}
+
+// TestMemoryStore_SlidingAndCleanup 测试内存存储的滑动过期和后台清理
+//
+// 【功能点】验证读取会顺延过期时间，清理协程会删除过期条目
+// 【测试流程】
+//  1. 写入 50ms TTL 的数据，在过期前多次读取并顺延，总时长超过 50ms 后仍可读
+//  2. 停止读取后等待过期，清理协程删除条目
+func TestMemoryStore_SlidingAndCleanup(t *testing.T) {
+	store := NewMemoryStore(10 * time.Millisecond)
+	defer store.Close()
+	ctx := context.Background()
+
+	require.NoError(t, store.Set(ctx, "s", []byte(`{}`), 50*time.Millisecond))
+	for i := 0; i < 4; i++ {
+		time.Sleep(30 * time.Millisecond)
+		data, err := store.Get(ctx, "s", 50*time.Millisecond)
+		require.NoError(t, err)
+		require.NotNil(t, data, "第 %d 次读取时会话不应过期", i+1)
+	}
+
+	assert.Eventually(t, func() bool { return store.Len() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+// TestRedisStore_SlidingExpiration 测试 Redis 存储的滑动过期
+//
+// 【功能点】验证 Get 通过 GETEX 顺延键的 TTL
+// 【测试流程】写入 10s TTL 的数据，快进 8s 后读取并顺延为 10s，断言 TTL 被重置
+func TestRedisStore_SlidingExpiration(t *testing.T) {
+	mr, store := newTestRedisStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.Set(ctx, "id", []byte(`{"a":1}`), 10*time.Second))
+	assert.Equal(t, 10*time.Second, mr.TTL("session:id"))
+
+	mr.FastForward(8 * time.Second)
+	data, err := store.Get(ctx, "id", 10*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(data))
+	assert.Equal(t, 10*time.Second, mr.TTL("session:id"))
+
+	data, err = store.Get(ctx, "missing", 10*time.Second)
+	require.NoError(t, err)
+	assert.Nil(t, data)
+}
+
+// TestSession_SaveIssuesCookie 测试新会话首次 Save 时下发 Cookie
+//
+// 【功能点】验证新会话在 Save 之前不写存储和 Cookie，Save 之后写入
+// 【测试流程】加载新会话并 Set，检查无 Cookie；Save 后检查 Cookie 属性和存储数据
+func TestSession_SaveIssuesCookie(t *testing.T) {
+	mr, store := newTestRedisStore(t)
+	m := NewManager(store, testOptions)
+
+	s, w := loadWithCookie(t, m, "")
+	assert.True(t, s.IsNew())
+	s.Set("user", "alice")
+	assert.Nil(t, responseCookie(w))
+	assert.False(t, mr.Exists("session:"+s.ID()))
+
+	require.NoError(t, s.Save())
+	cookie := responseCookie(w)
+	require.NotNil(t, cookie)
+	assert.Equal(t, s.ID(), cookie.Value)
+	assert.Equal(t, 60, cookie.MaxAge)
+	assert.True(t, cookie.HttpOnly)
+	assert.False(t, s.IsNew())
+
+	raw, err := mr.Get("session:" + s.ID())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user":"alice"}`, raw)
+
+	// 多次 Save 只保留一个 Set-Cookie 头
+	require.NoError(t, s.Save())
+	assert.Len(t, w.Header().Values("Set-Cookie"), 1)
+}
+
+// TestSession_PersistAcrossRequests 测试会话数据跨请求持久化
+//
+// 【功能点】验证携带 Cookie 的后续请求可以读取之前保存的数据
+// 【测试流程】第一次请求保存数据，第二次请求携带 Cookie 读取并修改，第三次请求验证修改生效
+func TestSession_PersistAcrossRequests(t *testing.T) {
+	store := NewMemoryStore(time.Minute)
+	defer store.Close()
+	m := NewManager(store, testOptions)
+
+	s1, _ := loadWithCookie(t, m, "")
+	s1.Set("count", 1)
+	require.NoError(t, s1.Save())
+
+	s2, w2 := loadWithCookie(t, m, s1.ID())
+	assert.False(t, s2.IsNew())
+	v, ok := s2.Get("count")
+	require.True(t, ok)
+	assert.Equal(t, float64(1), v)
+	require.NotNil(t, responseCookie(w2), "已有会话应刷新 Cookie 过期时间")
+
+	s2.Set("count", 2)
+	s2.Delete("missing")
+	require.NoError(t, s2.Save())
+
+	s3, _ := loadWithCookie(t, m, s1.ID())
+	v, _ = s3.Get("count")
+	assert.Equal(t, float64(2), v)
+}
+
+// TestSession_Destroy 测试销毁会话
+//
+// 【功能点】验证 Destroy 删除存储中的数据并下发过期 Cookie
+// 【测试流程】保存会话后 Destroy，检查 Redis 键被删除、Cookie MaxAge < 0，再次 Save 返回错误
+func TestSession_Destroy(t *testing.T) {
+	mr, store := newTestRedisStore(t)
+	m := NewManager(store, testOptions)
+
+	s, _ := loadWithCookie(t, m, "")
+	s.Set("user", "alice")
+	require.NoError(t, s.Save())
+	id := s.ID()
+
+	s2, w := loadWithCookie(t, m, id)
+	require.NoError(t, s2.Destroy())
+	assert.False(t, mr.Exists("session:"+id))
+	cookie := responseCookie(w)
+	require.NotNil(t, cookie)
+	assert.Empty(t, cookie.Value)
+	assert.Less(t, cookie.MaxAge, 0)
+	assert.Error(t, s2.Save())
+
+	s3, _ := loadWithCookie(t, m, id)
+	assert.True(t, s3.IsNew())
+	assert.NotEqual(t, id, s3.ID())
+}
+
+// TestSession_RegenerateID 测试重新生成会话 ID
+//
+// 【功能点】验证重新生成 ID 后数据迁移到新 ID，旧 ID 失效
+// 【测试流程】保存会话后 RegenerateID，检查新旧 Redis 键、Cookie 值，并用旧 ID 加载得到新会话
+func TestSession_RegenerateID(t *testing.T) {
+	mr, store := newTestRedisStore(t)
+	m := NewManager(store, testOptions)
+
+	s, _ := loadWithCookie(t, m, "")
+	s.Set("cart", "x")
+	require.NoError(t, s.Save())
+	oldID := s.ID()
+
+	s2, w := loadWithCookie(t, m, oldID)
+	s2.Set("user", "alice")
+	require.NoError(t, s2.RegenerateID())
+	newID := s2.ID()
+	assert.NotEqual(t, oldID, newID)
+	assert.False(t, mr.Exists("session:"+oldID))
+	assert.True(t, mr.Exists("session:"+newID))
+	assert.Equal(t, newID, responseCookie(w).Value)
+
+	old, _ := loadWithCookie(t, m, oldID)
+	assert.True(t, old.IsNew())
+	_, ok := old.Get("user")
+	assert.False(t, ok)
+
+	current, _ := loadWithCookie(t, m, newID)
+	v, _ := current.Get("user")
+	assert.Equal(t, "alice", v)
+	v, _ = current.Get("cart")
+	assert.Equal(t, "x", v)
+}
+
+// TestSession_ConcurrentSave 测试并发保存同一会话
+//
+// 【功能点】验证多个请求并发保存同一会话时数据编码完整（以最后一次保存为准）
+// 【测试流程】50 个协程分别加载同一会话、写入不同值并保存，最终数据可被正确解码且为某一次保存的结果
+func TestSession_ConcurrentSave(t *testing.T) {
+	mr, store := newTestRedisStore(t)
+	m := NewManager(store, testOptions)
+
+	s, _ := loadWithCookie(t, m, "")
+	require.NoError(t, s.Save())
+	id := s.ID()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(&http.Cookie{Name: testOptions.CookieName, Value: id})
+			sess, err := m.Load(httptest.NewRecorder(), r)
+			if err != nil {
+				t.Errorf("加载会话失败: %v", err)
+				return
+			}
+			sess.Set("writer", i)
+			sess.Set("payload", fmt.Sprintf("payload-%d", i))
+			if err := sess.Save(); err != nil {
+				t.Errorf("保存会话失败: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	raw, err := mr.Get("session:" + id)
+	require.NoError(t, err)
+	var values map[string]any
+	require.NoError(t, json.Unmarshal([]byte(raw), &values))
+	writer := int(values["writer"].(float64))
+	assert.Equal(t, fmt.Sprintf("payload-%d", writer), values["payload"])
+}
@@ -0,0 +1,138 @@
+// Package session 提供基于 Cookie 的服务端会话功能
+// 支持内存和 Redis 两种存储方式，适用于单机和分布式场景
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store 会话存储接口
+// 存储层只负责保存会话的编码数据，编码与解码由 Session 完成
+type Store interface {
+	// Get 读取会话数据并将过期时间顺延为 ttl（滑动过期）
+	// 会话不存在或已过期时返回 nil, nil
+	Get(ctx context.Context, id string, ttl time.Duration) ([]byte, error)
+
+	// Set 保存会话数据，过期时间为 ttl
+	Set(ctx context.Context, id string, data []byte, ttl time.Duration) error
+
+	// Delete 删除会话
+	Delete(ctx context.Context, id string) error
+
+	// Close 关闭存储，释放资源
+	Close() error
+}
+
+// MemoryStore 内存会话存储
+// 适用于单机部署和测试场景，后台协程定期清理过期会话
+type MemoryStore struct {
+	mu        sync.Mutex
+	items     map[string]*memoryItem
+	interval  time.Duration
+	stopCh    chan struct{}
+	closeOnce sync.Once
+}
+
+// memoryItem 内存会话条目
+type memoryItem struct {
+	data     []byte
+	expireAt time.Time
+}
+
+// NewMemoryStore 创建内存会话存储
+// cleanupInterval: 清理过期会话的间隔时间
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	ms := &MemoryStore{
+		items:    make(map[string]*memoryItem),
+		interval: cleanupInterval,
+		stopCh:   make(chan struct{}),
+	}
+
+	// 启动清理协程
+	go ms.cleanup()
+
+	return ms
+}
+
+// Get 读取会话数据并顺延过期时间
+func (ms *MemoryStore) Get(ctx context.Context, id string, ttl time.Duration) ([]byte, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	item, ok := ms.items[id]
+	if !ok {
+		return nil, nil
+	}
+	if time.Now().After(item.expireAt) {
+		delete(ms.items, id)
+		return nil, nil
+	}
+	item.expireAt = time.Now().Add(ttl)
+	return item.data, nil
+}
+
+// Set 保存会话数据
+func (ms *MemoryStore) Set(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.items[id] = &memoryItem{
+		data:     data,
+		expireAt: time.Now().Add(ttl),
+	}
+	return nil
+}
+
+// Delete 删除会话
+func (ms *MemoryStore) Delete(ctx context.Context, id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.items, id)
+	return nil
+}
+
+// Len 返回当前存储的会话数量（包含尚未清理的过期会话）
+func (ms *MemoryStore) Len() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return len(ms.items)
+}
+
+// cleanup 定期清理过期会话
+func (ms *MemoryStore) cleanup() {
+	ticker := time.NewTicker(ms.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ms.doCleanup()
+		case <-ms.stopCh:
+			return
+		}
+	}
+}
+
+// doCleanup 执行清理操作
+func (ms *MemoryStore) doCleanup() {
+	now := time.Now()
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	for id, item := range ms.items {
+		if now.After(item.expireAt) {
+			delete(ms.items, id)
+		}
+	}
+}
+
+// Close 关闭存储，停止清理协程
+func (ms *MemoryStore) Close() error {
+	ms.closeOnce.Do(func() {
+		close(ms.stopCh)
+	})
+	return nil
+}
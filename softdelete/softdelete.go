@@ -0,0 +1,207 @@
+// Package softdelete 提供基于 gorm.DeletedAt 的软删除辅助函数
+// 解决"软删除的数据占用唯一索引，导致无法重新创建相同唯一键的数据"的问题：
+// CreateOrRestore 在唯一键对应的数据已被软删除时恢复并更新该数据，而不是插入新数据
+package softdelete
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// deletedAtType gorm.DeletedAt 的类型，用于查找模型的软删除字段
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// WithDeleted 查询作用域：包含已软删除的数据
+//
+// 使用示例：
+//
+//	db.Scopes(softdelete.WithDeleted).Find(&users)
+func WithDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// OnlyDeleted 查询作用域：只查询已软删除的数据，要求软删除字段的列名为 deleted_at
+//
+// 使用示例：
+//
+//	db.Scopes(softdelete.OnlyDeleted).Find(&users)
+func OnlyDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where(clause.Neq{
+		Column: clause.Column{Table: clause.CurrentTable, Name: "deleted_at"},
+		Value:  nil,
+	})
+}
+
+// Restore 恢复已软删除的数据（将 deleted_at 置为 NULL）
+// 参数：
+//   - db: 数据库连接，传入事务时在该事务中执行
+//   - model: 模型指针，如 &User{}，需包含 gorm.DeletedAt 字段
+//   - id: 主键值
+//
+// 返回：
+//   - error: 数据不存在时返回 gorm.ErrRecordNotFound，数据未被删除时返回 nil
+func Restore(db *gorm.DB, model any, id any) error {
+	s, deletedAt, err := parseSchema(db, model)
+	if err != nil {
+		return err
+	}
+	pk := clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: s.PrioritizedPrimaryField.DBName}, Value: id}
+
+	result := db.Unscoped().Model(model).
+		Where(pk).
+		Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: deletedAt.DBName}, Value: nil}).
+		Update(deletedAt.DBName, nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	// 没有更新任何数据：数据不存在，或未被删除
+	var count int64
+	if err := db.Model(model).Where(pk).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ExistsIncludingDeleted 判断满足条件的数据是否存在，包含已软删除的数据
+// 参数：
+//   - db: 数据库连接
+//   - model: 模型指针，如 &User{}
+//   - conds: 查询条件，与 db.Where 的参数相同，如 "email = ?", email 或 map[string]any{"email": email}
+//
+// 返回：
+//   - bool: 是否存在
+//   - error: 查询失败时返回错误
+func ExistsIncludingDeleted(db *gorm.DB, model any, conds ...any) (bool, error) {
+	query := db.Unscoped().Model(model)
+	if len(conds) > 0 {
+		query = query.Where(conds[0], conds[1:]...)
+	}
+	var count int64
+	if err := query.Limit(1).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CreateOrRestore 创建数据；唯一条件对应的数据已被软删除时，恢复该数据并用 model 的值更新
+// 适用于唯一索引不包含 deleted_at 的场景（如 MySQL 不支持部分索引），避免重新创建时的唯一键冲突。
+// 在事务中执行，传入的 db 已处于事务中时使用保存点，随调用方的事务一起提交或回滚。
+//
+// 恢复时保留原数据的主键和创建时间，其余字段（包括零值）全部使用 model 的值；
+// 执行成功后 model 中为数据库中的最新数据。
+//
+// 使用示例：
+//
+//	user := &User{Email: "a@example.com", Name: "Alice"}
+//	err := softdelete.CreateOrRestore(db, user, map[string]any{"email": user.Email})
+//	if errors.Is(err, gorm.ErrDuplicatedKey) {
+//	    // 未删除的数据已存在
+//	}
+//
+// 参数：
+//   - db: 数据库连接或调用方的事务
+//   - model: 模型指针，需包含 gorm.DeletedAt 字段
+//   - uniqueConds: 唯一条件，列名到值的映射
+//
+// 返回：
+//   - error: 未删除的数据已存在时返回 gorm.ErrDuplicatedKey，其他错误原样返回
+func CreateOrRestore(db *gorm.DB, model any, uniqueConds map[string]any) error {
+	if len(uniqueConds) == 0 {
+		return errors.New("softdelete: 唯一条件不能为空")
+	}
+	s, deletedAt, err := parseSchema(db, model)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		existing := reflect.New(s.ModelType)
+		err := lockingQuery(tx, uniqueConds).Take(existing.Interface()).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return create(tx, model, uniqueConds)
+		}
+		if err != nil {
+			return err
+		}
+
+		ctx := tx.Statement.Context
+		if _, isZero := deletedAt.ValueOf(ctx, existing.Elem()); isZero {
+			return gorm.ErrDuplicatedKey
+		}
+		return restore(tx, s, deletedAt, model, existing.Elem())
+	})
+}
+
+// create 插入数据，并发插入导致唯一键冲突时返回 gorm.ErrDuplicatedKey
+func create(tx *gorm.DB, model any, uniqueConds map[string]any) error {
+	// 在保存点中插入，失败时回滚到保存点，事务仍可继续使用
+	err := tx.Transaction(func(sp *gorm.DB) error {
+		return sp.Create(model).Error
+	})
+	if err == nil {
+		return nil
+	}
+	var count int64
+	if lockingQuery(tx, uniqueConds).Model(model).Count(&count).Error == nil && count > 0 {
+		return gorm.ErrDuplicatedKey
+	}
+	return err
+}
+
+// restore 恢复已删除的数据并用 model 的值更新，保留原数据的主键和创建时间
+func restore(tx *gorm.DB, s *schema.Schema, deletedAt *schema.Field, model any, existing reflect.Value) error {
+	ctx := tx.Statement.Context
+	target := reflect.Indirect(reflect.ValueOf(model))
+	pk := s.PrioritizedPrimaryField
+	id, _ := pk.ValueOf(ctx, existing)
+	if err := pk.Set(ctx, target, id); err != nil {
+		return err
+	}
+	if err := deletedAt.Set(ctx, target, gorm.DeletedAt{}); err != nil {
+		return err
+	}
+
+	omit := []string{pk.DBName}
+	if createdAt := s.LookUpField("CreatedAt"); createdAt != nil {
+		omit = append(omit, createdAt.DBName)
+	}
+
+	if err := tx.Unscoped().Model(model).Select("*").Omit(omit...).Updates(model).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Value: id}).Take(model).Error
+}
+
+// lockingQuery 按唯一条件加锁查询（SELECT ... FOR UPDATE），包含已删除的数据
+func lockingQuery(tx *gorm.DB, uniqueConds map[string]any) *gorm.DB {
+	return tx.Unscoped().Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Where(uniqueConds)
+}
+
+// parseSchema 解析模型，返回模型结构和软删除字段
+func parseSchema(db *gorm.DB, model any) (*schema.Schema, *schema.Field, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, nil, err
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil, nil, fmt.Errorf("softdelete: %s 没有主键", stmt.Schema.Name)
+	}
+	for _, field := range stmt.Schema.Fields {
+		if field.FieldType == deletedAtType && field.DBName != "" {
+			return stmt.Schema, field, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("softdelete: %s 没有 gorm.DeletedAt 字段", stmt.Schema.Name)
+}
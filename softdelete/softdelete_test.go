@@ -0,0 +1,211 @@
+// Package softdelete 软删除辅助函数测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含软删除辅助函数的单元测试，使用 SQLite 内存数据库，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. 创建数据，未删除的数据已存在时返回 gorm.ErrDuplicatedKey
+// 2. 软删除后直接创建触发唯一键冲突，CreateOrRestore 恢复并更新原数据
+// 3. Restore 恢复已删除的数据，WithDeleted / OnlyDeleted 查询作用域
+// 4. 并发 CreateOrRestore 不产生重复数据
+// 5. 传入调用方的事务时随事务回滚
+//
+// 运行测试：go test -v ./softdelete/...
+// ==================================================
+package softdelete
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/entity"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// user 测试用实体，email 上的唯一索引不包含 deleted_at
+type user struct {
+	entity.SoftDeleteModel
+	Email string `gorm:"size:128;uniqueIndex"`
+	Name  string `gorm:"size:64"`
+	Age   int
+}
+
+// newTestDB 创建 SQLite 内存数据库并建表
+func newTestDB(t *testing.T) *gorm.DB {
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared&_busy_timeout=5000", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormLogger.Default.LogMode(gormLogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&user{}))
+	return db
+}
+
+// countAll 统计 email 对应的数据条数，包含已删除的数据
+func countAll(t *testing.T, db *gorm.DB, email string) int64 {
+	var count int64
+	require.NoError(t, db.Scopes(WithDeleted).Model(&user{}).Where("email = ?", email).Count(&count).Error)
+	return count
+}
+
+// TestCreateOrRestore_Create 测试创建数据
+//
+// 【功能点】验证数据不存在时插入，未删除的数据已存在时返回 gorm.ErrDuplicatedKey
+// 【测试流程】
+//  1. CreateOrRestore 创建 a@example.com，断言生成主键
+//  2. 再次 CreateOrRestore 相同 email，断言返回 gorm.ErrDuplicatedKey
+//  3. 断言唯一条件为空、模型没有 DeletedAt 字段时返回错误
+func TestCreateOrRestore_Create(t *testing.T) {
+	db := newTestDB(t)
+
+	u := &user{Email: "a@example.com", Name: "Alice"}
+	require.NoError(t, CreateOrRestore(db, u, map[string]any{"email": u.Email}))
+	assert.NotZero(t, u.ID)
+
+	err := CreateOrRestore(db, &user{Email: "a@example.com"}, map[string]any{"email": "a@example.com"})
+	assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+	assert.Equal(t, int64(1), countAll(t, db, "a@example.com"))
+
+	assert.Error(t, CreateOrRestore(db, &user{}, nil), "唯一条件为空时应返回错误")
+	type noSoftDelete struct{ ID uint64 }
+	assert.Error(t, CreateOrRestore(db, &noSoftDelete{}, map[string]any{"id": 1}), "没有 DeletedAt 字段时应返回错误")
+}
+
+// TestCreateOrRestore_RestoresDeleted 测试删除后重新创建
+//
+// 【功能点】验证软删除的数据被恢复并更新，而不是插入新数据
+// 【测试流程】
+//  1. 创建并软删除 a@example.com，断言直接 Create 触发唯一键冲突
+//  2. 断言 ExistsIncludingDeleted 返回 true，普通查询查不到
+//  3. CreateOrRestore 相同 email，断言主键不变、字段已更新（包括零值）、deleted_at 为 NULL
+func TestCreateOrRestore_RestoresDeleted(t *testing.T) {
+	db := newTestDB(t)
+
+	original := &user{Email: "a@example.com", Name: "Alice", Age: 30}
+	require.NoError(t, db.Create(original).Error)
+	require.NoError(t, db.Delete(original).Error)
+
+	assert.Error(t, db.Create(&user{Email: "a@example.com"}).Error, "软删除的数据应占用唯一索引")
+	exists, err := ExistsIncludingDeleted(db, &user{}, "email = ?", "a@example.com")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.ErrorIs(t, db.Where("email = ?", "a@example.com").First(&user{}).Error, gorm.ErrRecordNotFound)
+
+	u := &user{Email: "a@example.com", Name: "Alice2"}
+	require.NoError(t, CreateOrRestore(db, u, map[string]any{"email": u.Email}))
+	assert.Equal(t, original.ID, u.ID, "应恢复原数据而不是插入新数据")
+	assert.Equal(t, original.CreatedAt.Unix(), u.CreatedAt.Unix(), "应保留原数据的创建时间")
+
+	var loaded user
+	require.NoError(t, db.First(&loaded, original.ID).Error)
+	assert.Equal(t, "Alice2", loaded.Name)
+	assert.Zero(t, loaded.Age, "零值字段同样应被更新")
+	assert.False(t, loaded.DeletedAt.Valid)
+	assert.Equal(t, int64(1), countAll(t, db, "a@example.com"))
+}
+
+// TestRestore 测试恢复数据
+//
+// 【功能点】验证 Restore 清除 deleted_at，以及 WithDeleted / OnlyDeleted 查询作用域
+// 【测试流程】
+//  1. 创建两条数据并软删除其中一条，断言 OnlyDeleted 只查到已删除的数据，WithDeleted 查到全部
+//  2. Restore 已删除的数据，断言普通查询可以查到
+//  3. 断言 Restore 未删除的数据返回 nil，Restore 不存在的数据返回 gorm.ErrRecordNotFound
+func TestRestore(t *testing.T) {
+	db := newTestDB(t)
+
+	deleted := &user{Email: "a@example.com"}
+	active := &user{Email: "b@example.com"}
+	require.NoError(t, db.Create(deleted).Error)
+	require.NoError(t, db.Create(active).Error)
+	require.NoError(t, db.Delete(deleted).Error)
+
+	var users []user
+	require.NoError(t, db.Scopes(OnlyDeleted).Find(&users).Error)
+	require.Len(t, users, 1)
+	assert.Equal(t, deleted.ID, users[0].ID)
+	require.NoError(t, db.Scopes(WithDeleted).Find(&users).Error)
+	assert.Len(t, users, 2)
+
+	require.NoError(t, Restore(db, &user{}, deleted.ID))
+	require.NoError(t, db.First(&user{}, deleted.ID).Error, "恢复后应能查到")
+
+	assert.NoError(t, Restore(db, &user{}, active.ID), "恢复未删除的数据应返回 nil")
+	assert.ErrorIs(t, Restore(db, &user{}, 999), gorm.ErrRecordNotFound)
+}
+
+// TestCreateOrRestore_Concurrent 测试并发创建
+//
+// 【功能点】验证并发 CreateOrRestore 相同的唯一条件时只有一个成功，不产生重复数据
+// 【测试流程】
+//  1. 创建并软删除 a@example.com
+//  2. 并发 10 个 CreateOrRestore a@example.com 和 10 个 CreateOrRestore 新的 b@example.com
+//  3. 断言每个 email 只有 1 个成功，其余返回 gorm.ErrDuplicatedKey，数据各只有 1 条
+func TestCreateOrRestore_Concurrent(t *testing.T) {
+	db := newTestDB(t)
+	original := &user{Email: "a@example.com"}
+	require.NoError(t, db.Create(original).Error)
+	require.NoError(t, db.Delete(original).Error)
+
+	var mu sync.Mutex
+	succeeded := map[string]int{}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for _, email := range []string{"a@example.com", "b@example.com"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := CreateOrRestore(db, &user{Email: email, Name: fmt.Sprint(i)}, map[string]any{"email": email})
+				if err != nil && !errors.Is(err, gorm.ErrDuplicatedKey) {
+					t.Errorf("期望成功或 gorm.ErrDuplicatedKey, 实际 %v", err)
+					return
+				}
+				if err == nil {
+					mu.Lock()
+					succeeded[email]++
+					mu.Unlock()
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	assert.Equal(t, map[string]int{"a@example.com": 1, "b@example.com": 1}, succeeded)
+	assert.Equal(t, int64(1), countAll(t, db, "a@example.com"))
+	assert.Equal(t, int64(1), countAll(t, db, "b@example.com"))
+}
+
+// TestCreateOrRestore_CallerTransaction 测试调用方的事务
+//
+// 【功能点】验证传入调用方的事务时，恢复操作随事务一起回滚
+// 【测试流程】
+//  1. 创建并软删除 a@example.com
+//  2. 在事务中 CreateOrRestore a@example.com 和新的 b@example.com，然后返回错误
+//  3. 断言 a@example.com 仍为已删除，b@example.com 不存在
+func TestCreateOrRestore_CallerTransaction(t *testing.T) {
+	db := newTestDB(t)
+	original := &user{Email: "a@example.com"}
+	require.NoError(t, db.Create(original).Error)
+	require.NoError(t, db.Delete(original).Error)
+
+	errRollback := errors.New("rollback")
+	err := db.Transaction(func(tx *gorm.DB) error {
+		require.NoError(t, CreateOrRestore(tx, &user{Email: "a@example.com"}, map[string]any{"email": "a@example.com"}))
+		require.NoError(t, CreateOrRestore(tx, &user{Email: "b@example.com"}, map[string]any{"email": "b@example.com"}))
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	var loaded user
+	require.NoError(t, db.Scopes(WithDeleted).First(&loaded, original.ID).Error)
+	assert.True(t, loaded.DeletedAt.Valid, "事务回滚后应仍为已删除")
+	assert.Zero(t, countAll(t, db, "b@example.com"), "事务回滚后不应存在")
+}
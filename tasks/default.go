@@ -0,0 +1,62 @@
+package tasks
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// defaultRunner 默认执行器，由框架的后台任务服务在启动时设置
+var defaultRunner atomic.Pointer[Runner]
+
+// SetDefault 设置默认执行器
+// 框架启动时由后台任务服务根据 tasks 配置创建并设置，一般无需手动调用
+// 参数：
+//   - r: 执行器，为 nil 时清除默认执行器
+func SetDefault(r *Runner) {
+	defaultRunner.Store(r)
+}
+
+// Default 获取默认执行器，未初始化时返回 nil
+func Default() *Runner {
+	return defaultRunner.Load()
+}
+
+// Submit 向默认执行器提交任务
+// 参数：
+//   - ctx: 提交上下文，任务的 ctx 继承其中的值但不继承其取消
+//   - name: 任务名称，用于日志
+//   - fn: 任务函数
+//
+// 返回：
+//   - error: 队列已满时返回 ErrQueueFull，执行器已关闭时返回 ErrRunnerClosed，未初始化时返回 ErrRunnerNotStarted
+//
+// 使用示例：
+//
+//	err := tasks.Submit(ctx, "sendWelcomeEmail", func(ctx context.Context) error {
+//	  return email.Send(ctx, user.Email, "欢迎注册")
+//	})
+//	if err != nil {
+//	  logger.Warn("提交后台任务失败: %v", err)
+//	}
+func Submit(ctx context.Context, name string, fn Func) error {
+	r := Default()
+	if r == nil {
+		return ErrRunnerNotStarted
+	}
+	return r.Submit(ctx, name, fn)
+}
+
+// traceIDKey 追踪 ID 在 context.Context 中的存储键类型
+type traceIDKey struct{}
+
+// WithTraceID 将追踪 ID 存入 context.Context，任务失败时的日志会带上该追踪 ID
+// ginContext.Async 会自动存入当前请求的追踪 ID
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceID 从 context.Context 中获取追踪 ID，未设置时返回空字符串
+func TraceID(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDKey{}).(string)
+	return traceID
+}
@@ -0,0 +1,296 @@
+// Package tasks 提供进程内的后台任务执行器
+//
+// 用于替代在 handler 中直接启动协程执行的"发后即忘"任务（如响应后发送邮件）：
+// 任务在有界队列中排队，由固定数量的协程执行，执行时捕获 panic、限制超时，失败时记录日志并按配置重试。
+// 队列已满时 Submit 立即返回 ErrQueueFull（或按配置等待一段时间），不会无限堆积协程。
+//
+// 应用关闭时执行器停止接收新任务，在关闭超时时间内执行完队列中的任务，超时后放弃剩余任务并报告数量。
+// 任务只保存在内存中，进程异常退出时会丢失，需要可靠投递的场景请使用发件箱（outbox）或消息队列。
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/zzsen/gin_core/logger"
+)
+
+var (
+	// ErrQueueFull 队列已满
+	ErrQueueFull = errors.New("后台任务队列已满")
+	// ErrRunnerClosed 执行器已关闭，不再接收任务
+	ErrRunnerClosed = errors.New("后台任务执行器已关闭")
+	// ErrRunnerNotStarted 默认执行器未初始化
+	ErrRunnerNotStarted = errors.New("后台任务执行器未初始化")
+)
+
+// Func 任务函数
+// ctx 在单次执行超时或执行器关闭超时后取消，任务应据此及时退出
+type Func func(ctx context.Context) error
+
+// Options 执行器配置
+type Options struct {
+	// Workers 执行任务的协程数，默认 4
+	Workers int
+	// QueueSize 队列容量，默认 1000
+	QueueSize int
+	// Timeout 单次执行的超时时间，默认 60s
+	Timeout time.Duration
+	// MaxRetries 失败后的最大重试次数，默认 0（不重试）
+	MaxRetries int
+	// RetryInterval 重试间隔，默认 1s
+	RetryInterval time.Duration
+	// BlockOnFull 队列已满时是否等待，false 时立即返回 ErrQueueFull
+	BlockOnFull bool
+	// BlockTimeout 队列已满时的最长等待时间，默认 1s；Submit 的 ctx 先结束时以 ctx 为准
+	BlockTimeout time.Duration
+}
+
+// withDefaults 补全未配置的选项
+func (o Options) withDefaults() Options {
+	if o.Workers <= 0 {
+		o.Workers = 4
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1000
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 60 * time.Second
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = time.Second
+	}
+	if o.BlockTimeout <= 0 {
+		o.BlockTimeout = time.Second
+	}
+	return o
+}
+
+// task 队列中的任务
+type task struct {
+	ctx  context.Context
+	name string
+	fn   Func
+}
+
+// Runner 后台任务执行器
+// 任务按提交顺序出队，由 Workers 个协程并发执行；Workers 为 1 时严格按提交顺序执行
+type Runner struct {
+	opts  Options
+	queue chan task
+
+	// submitMu 保护 closed 与向 queue 发送，关闭时持写锁关闭 queue，避免向已关闭的通道发送
+	submitMu sync.RWMutex
+	closed   bool
+	closing  chan struct{}
+
+	// stateMu 保护 pending 与 abandoned，保证关闭超时时统计的放弃数量与实际未执行的任务一致
+	stateMu   sync.Mutex
+	pending   int
+	abandoned bool
+
+	baseCtx context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	done    chan struct{}
+}
+
+// NewRunner 创建并启动后台任务执行器
+// 参数：
+//   - opts: 执行器配置，未配置的选项使用默认值
+//
+// 返回：
+//   - *Runner: 执行器实例
+func NewRunner(opts Options) *Runner {
+	opts = opts.withDefaults()
+	baseCtx, cancel := context.WithCancel(context.Background())
+	r := &Runner{
+		opts:    opts,
+		queue:   make(chan task, opts.QueueSize),
+		closing: make(chan struct{}),
+		baseCtx: baseCtx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	r.wg.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go r.worker()
+	}
+	go func() {
+		r.wg.Wait()
+		close(r.done)
+	}()
+	return r
+}
+
+// Submit 提交任务
+// 任务的 ctx 继承 ctx 中的值（如追踪 ID），但不继承其取消：请求结束后任务仍会执行
+// 参数：
+//   - ctx: 提交上下文，队列已满且配置了等待时用于限制等待时间
+//   - name: 任务名称，用于日志
+//   - fn: 任务函数
+//
+// 返回：
+//   - error: 队列已满时返回 ErrQueueFull，执行器已关闭时返回 ErrRunnerClosed
+func (r *Runner) Submit(ctx context.Context, name string, fn Func) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	t := task{ctx: context.WithoutCancel(ctx), name: name, fn: fn}
+
+	r.submitMu.RLock()
+	defer r.submitMu.RUnlock()
+	if r.closed {
+		return ErrRunnerClosed
+	}
+
+	r.addPending(1)
+	select {
+	case r.queue <- t:
+		return nil
+	default:
+	}
+	if !r.opts.BlockOnFull {
+		r.addPending(-1)
+		return ErrQueueFull
+	}
+
+	timer := time.NewTimer(r.opts.BlockTimeout)
+	defer timer.Stop()
+	select {
+	case r.queue <- t:
+		return nil
+	case <-timer.C:
+		r.addPending(-1)
+		return ErrQueueFull
+	case <-ctx.Done():
+		r.addPending(-1)
+		return fmt.Errorf("%w: %w", ErrQueueFull, ctx.Err())
+	case <-r.closing:
+		r.addPending(-1)
+		return ErrRunnerClosed
+	}
+}
+
+// Pending 获取已提交但尚未开始执行的任务数
+func (r *Runner) Pending() int {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	return r.pending
+}
+
+// Shutdown 关闭执行器
+// 立即停止接收新任务，等待队列中的任务执行完毕；ctx 结束时取消正在执行的任务，
+// 放弃尚未开始的任务并返回其数量
+// 参数：
+//   - ctx: 关闭上下文，通常带有关闭超时时间
+//
+// 返回：
+//   - int: 放弃的任务数
+//   - error: ctx 结束前未执行完时返回包装了 ctx.Err() 的错误
+func (r *Runner) Shutdown(ctx context.Context) (int, error) {
+	r.submitMu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.closing)
+		close(r.queue)
+	}
+	r.submitMu.Unlock()
+
+	select {
+	case <-r.done:
+		return 0, nil
+	case <-ctx.Done():
+	}
+
+	r.stateMu.Lock()
+	r.abandoned = true
+	abandoned := r.pending
+	r.stateMu.Unlock()
+	r.cancel()
+	return abandoned, fmt.Errorf("等待后台任务执行完毕超时, 放弃 %d 个任务: %w", abandoned, ctx.Err())
+}
+
+// addPending 调整尚未开始执行的任务数
+func (r *Runner) addPending(delta int) {
+	r.stateMu.Lock()
+	r.pending += delta
+	r.stateMu.Unlock()
+}
+
+// start 标记任务开始执行，执行器关闭超时后返回 false
+func (r *Runner) start() bool {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	if r.abandoned {
+		return false
+	}
+	r.pending--
+	return true
+}
+
+// worker 从队列中取出任务执行，队列关闭且为空时退出
+func (r *Runner) worker() {
+	defer r.wg.Done()
+	for t := range r.queue {
+		if !r.start() {
+			continue
+		}
+		r.run(t)
+	}
+}
+
+// run 执行任务，失败时按配置重试
+func (r *Runner) run(t task) {
+	for attempt := 0; ; attempt++ {
+		err := r.execute(t)
+		if err == nil {
+			return
+		}
+
+		fields := map[string]any{
+			"task":    t.name,
+			"attempt": attempt + 1,
+			"error":   err.Error(),
+		}
+		if traceID := TraceID(t.ctx); traceID != "" {
+			fields["traceId"] = traceID
+		}
+		if attempt >= r.opts.MaxRetries || r.baseCtx.Err() != nil {
+			logger.ErrorWithFields(fields, "[后台任务] 任务 %s 执行失败", t.name)
+			return
+		}
+		logger.WarnWithFields(fields, "[后台任务] 任务 %s 执行失败, %v 后重试", t.name, r.opts.RetryInterval)
+
+		timer := time.NewTimer(r.opts.RetryInterval)
+		select {
+		case <-timer.C:
+		case <-r.baseCtx.Done():
+			timer.Stop()
+			logger.ErrorWithFields(fields, "[后台任务] 任务 %s 执行失败, 执行器已关闭, 不再重试", t.name)
+			return
+		}
+	}
+}
+
+// execute 单次执行任务，捕获 panic 并转换为错误
+func (r *Runner) execute(t task) (err error) {
+	ctx, cancel := context.WithTimeout(t.ctx, r.opts.Timeout)
+	defer cancel()
+	stop := context.AfterFunc(r.baseCtx, cancel)
+	defer stop()
+
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("panic: %v\n%s", v, debug.Stack())
+		}
+	}()
+	return t.fn(ctx)
+}
@@ -0,0 +1,282 @@
+// Package tasks 后台任务执行器测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 Runner 的单元测试。
+//
+// 测试覆盖内容：
+// 1. 队列已满时立即返回 ErrQueueFull，或配置等待时在超时后返回
+// 2. 任务 panic 被捕获，不影响后续任务执行
+// 3. 关闭时停止接收任务，并按提交顺序执行完队列中的任务
+// 4. 关闭超时时取消正在执行的任务，放弃未执行的任务并返回数量
+// 5. 失败重试、单次执行超时
+// 6. 默认执行器与追踪 ID 传递
+//
+// 运行测试：go test -v ./tasks/...
+// ==================================================
+package tasks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingTask 返回阻塞直到 release 关闭的任务，started 在任务开始时写入
+func blockingTask(started chan<- struct{}, release <-chan struct{}) Func {
+	return func(ctx context.Context) error {
+		started <- struct{}{}
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	}
+}
+
+// TestRunner_QueueFull 测试队列已满
+//
+// 【功能点】验证队列已满时 Submit 立即返回 ErrQueueFull，不阻塞
+// 【测试流程】
+//  1. 1 个协程、容量 1 的队列，提交一个阻塞任务占用协程，再提交一个任务填满队列
+//  2. 再次提交，断言立即返回 ErrQueueFull，Pending 为 1
+//  3. 释放阻塞任务，关闭执行器，断言排队的任务被执行
+func TestRunner_QueueFull(t *testing.T) {
+	r := NewRunner(Options{Workers: 1, QueueSize: 1})
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	require.NoError(t, r.Submit(context.Background(), "block", blockingTask(started, release)))
+	<-started
+
+	var ran atomic.Bool
+	require.NoError(t, r.Submit(context.Background(), "queued", func(ctx context.Context) error {
+		ran.Store(true)
+		return nil
+	}))
+
+	begin := time.Now()
+	err := r.Submit(context.Background(), "overflow", func(ctx context.Context) error { return nil })
+	assert.ErrorIs(t, err, ErrQueueFull)
+	assert.Less(t, time.Since(begin), 100*time.Millisecond)
+	assert.Equal(t, 1, r.Pending())
+
+	close(release)
+	abandoned, err := r.Shutdown(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, abandoned)
+	assert.True(t, ran.Load())
+}
+
+// TestRunner_BlockOnFull 测试队列已满时等待
+//
+// 【功能点】验证配置 BlockOnFull 后，队列有空位时等待成功，超过 BlockTimeout 或 ctx 结束时返回 ErrQueueFull
+// 【测试流程】
+//  1. 占满协程和队列，提交任务并在 20ms 后释放阻塞任务，断言提交成功
+//  2. 再次占满，提交任务，断言约 BlockTimeout 后返回 ErrQueueFull
+//  3. 以已取消的 ctx 提交，断言返回的错误同时包含 ErrQueueFull 和 context.Canceled
+func TestRunner_BlockOnFull(t *testing.T) {
+	r := NewRunner(Options{Workers: 1, QueueSize: 1, BlockOnFull: true, BlockTimeout: 50 * time.Millisecond})
+	defer r.Shutdown(context.Background())
+	noop := func(ctx context.Context) error { return nil }
+
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	require.NoError(t, r.Submit(context.Background(), "block", blockingTask(started, release)))
+	<-started
+	require.NoError(t, r.Submit(context.Background(), "queued", noop))
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+	require.NoError(t, r.Submit(context.Background(), "waited", noop))
+
+	started2, release2 := make(chan struct{}, 1), make(chan struct{})
+	defer close(release2)
+	require.Eventually(t, func() bool { return r.Pending() == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, r.Submit(context.Background(), "block2", blockingTask(started2, release2)))
+	<-started2
+	require.NoError(t, r.Submit(context.Background(), "queued2", noop))
+
+	begin := time.Now()
+	err := r.Submit(context.Background(), "timeout", noop)
+	assert.ErrorIs(t, err, ErrQueueFull)
+	assert.GreaterOrEqual(t, time.Since(begin), 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = r.Submit(ctx, "canceled", noop)
+	assert.ErrorIs(t, err, ErrQueueFull)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestRunner_PanicContained 测试 panic 捕获
+//
+// 【功能点】验证任务 panic 被捕获并按失败处理，执行协程不退出，后续任务正常执行
+// 【测试流程】1 个协程依次提交 panic 任务和普通任务，关闭后断言普通任务已执行
+func TestRunner_PanicContained(t *testing.T) {
+	r := NewRunner(Options{Workers: 1})
+	require.NoError(t, r.Submit(context.Background(), "panic", func(ctx context.Context) error {
+		panic("boom")
+	}))
+	var ran atomic.Bool
+	require.NoError(t, r.Submit(context.Background(), "after", func(ctx context.Context) error {
+		ran.Store(true)
+		return nil
+	}))
+
+	_, err := r.Shutdown(context.Background())
+	require.NoError(t, err)
+	assert.True(t, ran.Load())
+}
+
+// TestRunner_DrainOrder 测试关闭时执行完队列
+//
+// 【功能点】验证关闭后拒绝新任务，队列中的任务按提交顺序全部执行完后 Shutdown 才返回
+// 【测试流程】
+//  1. 1 个协程，阻塞第一个任务后依次提交 1~5
+//  2. 异步调用 Shutdown，等待其生效后提交任务，断言返回 ErrRunnerClosed
+//  3. 释放阻塞任务，断言 Shutdown 返回且执行顺序为 1~5
+func TestRunner_DrainOrder(t *testing.T) {
+	r := NewRunner(Options{Workers: 1, QueueSize: 10})
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	require.NoError(t, r.Submit(context.Background(), "block", blockingTask(started, release)))
+	<-started
+
+	var (
+		mu    sync.Mutex
+		order []int
+	)
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, r.Submit(context.Background(), "ordered", func(ctx context.Context) error {
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			return nil
+		}))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		abandoned, err := r.Shutdown(context.Background())
+		assert.NoError(t, err)
+		assert.Zero(t, abandoned)
+	}()
+	require.Eventually(t, func() bool {
+		return errors.Is(r.Submit(context.Background(), "late", func(ctx context.Context) error { return nil }), ErrRunnerClosed)
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	<-done
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, order)
+}
+
+// TestRunner_ShutdownTimeout 测试关闭超时
+//
+// 【功能点】验证关闭超时时正在执行的任务 ctx 被取消，未开始的任务被放弃且不再执行，返回放弃数量
+// 【测试流程】
+//  1. 1 个协程执行阻塞任务（只响应 ctx 取消），队列中再排 3 个任务
+//  2. 以 30ms 超时关闭，断言返回 3 和包装了 DeadlineExceeded 的错误
+//  3. 等待阻塞任务退出，断言排队的任务均未执行
+func TestRunner_ShutdownTimeout(t *testing.T) {
+	r := NewRunner(Options{Workers: 1})
+	started := make(chan struct{}, 1)
+	canceled := make(chan struct{})
+	require.NoError(t, r.Submit(context.Background(), "block", func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}))
+	<-started
+
+	var ran atomic.Int32
+	for i := 0; i < 3; i++ {
+		require.NoError(t, r.Submit(context.Background(), "queued", func(ctx context.Context) error {
+			ran.Add(1)
+			return nil
+		}))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	abandoned, err := r.Shutdown(ctx)
+	assert.Equal(t, 3, abandoned)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("正在执行的任务未被取消")
+	}
+	<-r.done
+	assert.Zero(t, ran.Load())
+}
+
+// TestRunner_RetryAndTimeout 测试重试与单次执行超时
+//
+// 【功能点】验证失败任务按 MaxRetries 重试，单次执行超过 Timeout 时 ctx 被取消
+// 【测试流程】
+//  1. MaxRetries=2，任务前两次失败第三次成功，断言共执行 3 次
+//  2. 任务等待 ctx 结束，断言返回 DeadlineExceeded
+func TestRunner_RetryAndTimeout(t *testing.T) {
+	r := NewRunner(Options{Workers: 1, MaxRetries: 2, RetryInterval: time.Millisecond, Timeout: 20 * time.Millisecond})
+
+	var attempts atomic.Int32
+	require.NoError(t, r.Submit(context.Background(), "flaky", func(ctx context.Context) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	}))
+
+	timeoutErr := make(chan error, 3)
+	require.NoError(t, r.Submit(context.Background(), "slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		timeoutErr <- ctx.Err()
+		return ctx.Err()
+	}))
+
+	_, err := r.Shutdown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), attempts.Load())
+	assert.ErrorIs(t, <-timeoutErr, context.DeadlineExceeded)
+}
+
+// TestSubmit_Default 测试默认执行器
+//
+// 【功能点】验证未设置默认执行器时返回 ErrRunnerNotStarted；设置后任务 ctx 携带追踪 ID 且不随提交 ctx 取消
+// 【测试流程】
+//  1. 清除默认执行器，断言 Submit 返回 ErrRunnerNotStarted
+//  2. 设置默认执行器，以带追踪 ID、随后取消的 ctx 提交，断言任务读取到追踪 ID 且 ctx 未取消
+func TestSubmit_Default(t *testing.T) {
+	original := Default()
+	t.Cleanup(func() { SetDefault(original) })
+
+	SetDefault(nil)
+	assert.ErrorIs(t, Submit(context.Background(), "none", func(ctx context.Context) error { return nil }), ErrRunnerNotStarted)
+
+	r := NewRunner(Options{Workers: 1})
+	SetDefault(r)
+
+	ctx, cancel := context.WithCancel(WithTraceID(context.Background(), "trace-1"))
+	type result struct {
+		traceID string
+		err     error
+	}
+	got := make(chan result, 1)
+	release := make(chan struct{})
+	require.NoError(t, Submit(ctx, "traced", func(ctx context.Context) error {
+		<-release
+		got <- result{TraceID(ctx), ctx.Err()}
+		return nil
+	}))
+	cancel()
+	close(release)
+
+	_, err := r.Shutdown(context.Background())
+	require.NoError(t, err)
+	res := <-got
+	assert.Equal(t, "trace-1", res.traceID)
+	assert.NoError(t, res.err)
+}
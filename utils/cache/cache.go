@@ -0,0 +1,297 @@
+// Package cache 提供基于 Redis 的旁路缓存（cache-aside）工具。
+//
+// 核心类型 Cache[T] 封装了"读缓存 → 未命中加载 → 回写缓存"的完整流程：
+//   - 使用 singleflight 合并同一进程内对同一 key 的并发加载，防止缓存击穿
+//   - 支持对"数据不存在"的结果做短 TTL 的负缓存，防止缓存穿透
+//   - Redis 不可用时降级为直接调用 loader，并输出告警日志
+//
+// 值通过 encoding/json 序列化后存储，因此字段映射遵循 json tag 规则。
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/logger"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNotFound 数据不存在
+// loader 返回该错误（或包装了该错误）时，若启用了负缓存，结果会以 NegativeTTL 缓存
+var ErrNotFound = errors.New("cache: not found")
+
+// negativeValue 负缓存占位值
+// 正常值均为 JSON 编码，不会与该占位值冲突
+const negativeValue = "\x00cache:not-found"
+
+// errDecode 缓存数据反序列化失败
+var errDecode = errors.New("【缓存】反序列化失败")
+
+// Config 缓存配置
+type Config struct {
+	// KeyPrefix 缓存键前缀
+	// 默认值: "cache:"
+	KeyPrefix string
+
+	// NegativeTTL 负缓存（数据不存在）的过期时间，0 表示不启用负缓存
+	// 默认值: 0
+	NegativeTTL time.Duration
+
+	// TagBatchSize InvalidateTag 每批删除的缓存键数量
+	// 默认值: 500
+	TagBatchSize int
+}
+
+// GetTagBatchSize 获取 InvalidateTag 每批删除的缓存键数量，如果未配置则返回 500
+func (c *Config) GetTagBatchSize() int {
+	if c.TagBatchSize <= 0 {
+		return 500
+	}
+	return c.TagBatchSize
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		KeyPrefix: "cache:",
+	}
+}
+
+// Option 配置选项函数
+type Option func(*Config)
+
+// WithKeyPrefix 设置缓存键前缀
+func WithKeyPrefix(prefix string) Option {
+	return func(c *Config) {
+		c.KeyPrefix = prefix
+	}
+}
+
+// WithNegativeTTL 设置负缓存过期时间
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.NegativeTTL = ttl
+	}
+}
+
+// WithTagBatchSize 设置 InvalidateTag 每批删除的缓存键数量
+func WithTagBatchSize(size int) Option {
+	return func(c *Config) {
+		c.TagBatchSize = size
+	}
+}
+
+// Cache 类型化的 Redis 缓存
+// T 为缓存值类型，需可被 encoding/json 序列化
+type Cache[T any] struct {
+	client redis.UniversalClient
+	config *Config
+	group  singleflight.Group
+}
+
+// New 创建类型化缓存
+// 参数：
+//   - client: Redis 客户端，传 nil 时在每次调用时使用 app.Redis
+//   - opts: 配置选项
+//
+// 返回：
+//   - *Cache[T]: 缓存实例
+//
+// 使用示例：
+//
+//	userCache := cache.New[User](nil, cache.WithNegativeTTL(30*time.Second))
+//	user, err := userCache.GetOrLoad(ctx, "user:1", 10*time.Minute, func(ctx context.Context) (User, error) {
+//	    return repo.FindUser(ctx, 1)
+//	})
+func New[T any](client redis.UniversalClient, opts ...Option) *Cache[T] {
+	config := DefaultConfig()
+	for _, opt := range opts {
+		opt(config)
+	}
+	return &Cache[T]{
+		client: client,
+		config: config,
+	}
+}
+
+// redisClient 获取当前使用的 Redis 客户端
+func (c *Cache[T]) redisClient() redis.UniversalClient {
+	if c.client != nil {
+		return c.client
+	}
+	return app.Redis
+}
+
+// fullKey 拼接带前缀的缓存键
+func (c *Cache[T]) fullKey(key string) string {
+	return c.config.KeyPrefix + key
+}
+
+// Get 从缓存中读取值
+// 返回：
+//   - T: 缓存值
+//   - bool: 是否命中（负缓存命中时返回 true，同时 error 为 ErrNotFound）
+//   - error: Redis 错误或反序列化错误
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	var zero T
+	client := c.redisClient()
+	if client == nil {
+		return zero, false, errors.New("【缓存】Redis 未初始化")
+	}
+
+	data, err := client.Get(ctx, c.fullKey(key)).Result()
+	if errors.Is(err, redis.Nil) {
+		return zero, false, nil
+	}
+	if err != nil {
+		return zero, false, err
+	}
+	if data == negativeValue {
+		return zero, true, ErrNotFound
+	}
+
+	var value T
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return zero, false, fmt.Errorf("%w, key: %s: %v", errDecode, key, err)
+	}
+	return value, true, nil
+}
+
+// Set 写入缓存
+func (c *Cache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	return c.SetWithTags(ctx, key, value, ttl)
+}
+
+// GetOrLoad 读取缓存，未命中时调用 loader 加载并回写缓存
+//
+// 执行流程：
+// 1. 读取缓存，命中则直接返回（负缓存命中返回 ErrNotFound）
+// 2. 未命中时通过 singleflight 合并并发请求，同一 key 同一时刻只执行一次 loader
+// 3. loader 成功则以 ttl 回写缓存；返回 ErrNotFound 且启用负缓存时以 NegativeTTL 写入占位值
+// 4. Redis 不可用时降级为直接调用 loader，并输出告警日志
+//
+// 参数：
+//   - ctx: 上下文
+//   - key: 缓存键（不含前缀）
+//   - ttl: 缓存过期时间
+//   - loader: 数据加载函数
+//
+// 返回：
+//   - T: 缓存值或加载结果
+//   - error: loader 错误、ErrNotFound 或序列化错误
+func (c *Cache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+	return c.getOrLoad(ctx, key, ttl, loader, nil)
+}
+
+// getOrLoad 读取缓存，未命中时调用 loader 加载并回写缓存，回写时登记 tags
+func (c *Cache[T]) getOrLoad(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error), tags []string) (T, error) {
+	value, hit, err := c.Get(ctx, key)
+	if hit {
+		return value, err
+	}
+	// 反序列化失败说明缓存数据已损坏，继续加载并覆盖即可；其他错误视为 Redis 不可用
+	if err != nil && !errors.Is(err, errDecode) {
+		logger.Warn("【缓存】Redis 不可用, 降级为直接加载, key: %s, error: %v", key, err)
+		return loader(ctx)
+	}
+
+	result, err, _ := c.group.Do(key, func() (any, error) {
+		loaded, loadErr := loader(ctx)
+		if loadErr != nil {
+			if errors.Is(loadErr, ErrNotFound) && c.config.NegativeTTL > 0 {
+				c.setNegative(ctx, key, tags)
+			}
+			return loaded, loadErr
+		}
+		if setErr := c.SetWithTags(ctx, key, loaded, ttl, tags...); setErr != nil {
+			logger.Warn("【缓存】回写缓存失败, key: %s, error: %v", key, setErr)
+		}
+		return loaded, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result.(T), nil
+}
+
+// setNegative 写入负缓存占位值，并登记 tags
+func (c *Cache[T]) setNegative(ctx context.Context, key string, tags []string) {
+	client := c.redisClient()
+	if client == nil {
+		return
+	}
+	if err := c.addTags(ctx, client, key, c.config.NegativeTTL, tags); err != nil {
+		logger.Warn("【缓存】写入负缓存失败, key: %s, error: %v", key, err)
+		return
+	}
+	if err := client.Set(ctx, c.fullKey(key), negativeValue, c.config.NegativeTTL).Err(); err != nil {
+		logger.Warn("【缓存】写入负缓存失败, key: %s, error: %v", key, err)
+	}
+}
+
+// Delete 删除一个或多个缓存键
+// 通过 Pipeline 逐个键执行 DEL，集群模式下不会因键分布在不同槽位而失败
+func (c *Cache[T]) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	client := c.redisClient()
+	if client == nil {
+		return errors.New("【缓存】Redis 未初始化")
+	}
+
+	pipe := client.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, c.fullKey(key))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Invalidate 按模式批量失效缓存
+// 参数 pattern 为不含前缀的 Redis glob 模式，如 "user:*"
+// 使用 SCAN 遍历，避免 KEYS 命令阻塞 Redis；集群模式下在每个主节点上分别遍历
+//
+// 返回：
+//   - int64: 删除的键数量
+//   - error: Redis 错误
+func (c *Cache[T]) Invalidate(ctx context.Context, pattern string) (int64, error) {
+	client := c.redisClient()
+	if client == nil {
+		return 0, errors.New("【缓存】Redis 未初始化")
+	}
+
+	match := c.fullKey(pattern)
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		var deleted atomic.Int64
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			n, err := invalidateMatched(ctx, node, match)
+			deleted.Add(n)
+			return err
+		})
+		return deleted.Load(), err
+	}
+	return invalidateMatched(ctx, client, match)
+}
+
+// invalidateMatched 遍历并删除单个节点上匹配 match 的缓存键
+// 逐个删除，避免集群模式下跨槽位的 DEL
+func invalidateMatched(ctx context.Context, client redis.UniversalClient, match string) (int64, error) {
+	var deleted int64
+	iter := client.Scan(ctx, 0, match, 100).Iterator()
+	for iter.Next(ctx) {
+		n, err := client.Del(ctx, iter.Val()).Result()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	return deleted, iter.Err()
+}
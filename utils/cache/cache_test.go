@@ -0,0 +1,518 @@
+// Package cache 类型化 Redis 缓存测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 Cache[T] 的单元测试，使用 miniredis 模拟 Redis，不需要真实 Redis 连接。
+//
+// 测试覆盖内容：
+// 1. 并发未命中时 loader 只执行一次（singleflight）
+// 2. 缓存 TTL 正确设置
+// 3. 负缓存行为
+// 4. 结构体值的类型化反序列化
+// 5. Redis 不可用时降级为直接加载
+// 6. Delete / Invalidate 失效缓存
+// 7. SetWithTags / GetOrLoadWithTags 登记标签，标签集合的过期时间
+// 8. InvalidateTag 分批失效标签下的缓存键，中途失败后可再次失效
+// 9. 集群模式下 Delete / Invalidate 删除分布在不同节点上的缓存键
+//
+// 运行测试：go test -v ./utils/cache/...
+// ==================================================
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testUser 测试用结构体
+type testUser struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags"`
+	Admin bool     `json:"admin"`
+}
+
+// newTestRedis 创建测试用的 miniredis 实例和客户端
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, redis.UniversalClient) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return mr, client
+}
+
+// TestGetOrLoad_SingleflightConcurrent 测试并发未命中时 loader 只执行一次
+//
+// 【功能点】验证 singleflight 合并同一 key 的并发加载
+// 【测试流程】100 个协程同时调用 GetOrLoad，loader 内部短暂阻塞，断言 loader 只被调用一次且所有结果一致
+func TestGetOrLoad_SingleflightConcurrent(t *testing.T) {
+	_, client := newTestRedis(t)
+	c := New[testUser](client)
+
+	var calls int32
+	loader := func(ctx context.Context) (testUser, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		return testUser{ID: 1, Name: "alice"}, nil
+	}
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	results := make([]testUser, 100)
+	errs := make([]error, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			results[i], errs[i] = c.GetOrLoad(context.Background(), "user:1", time.Minute, loader)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for i := 0; i < 100; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, "alice", results[i].Name)
+	}
+}
+
+// TestGetOrLoad_SetsTTL 测试缓存回写时设置 TTL
+//
+// 【功能点】验证加载结果以指定 TTL 写入 Redis，且再次读取命中缓存
+// 【测试流程】调用 GetOrLoad 后检查 miniredis 中键的 TTL；再次调用时 loader 不应执行
+func TestGetOrLoad_SetsTTL(t *testing.T) {
+	mr, client := newTestRedis(t)
+	c := New[string](client, WithKeyPrefix("test:"))
+
+	var calls int32
+	loader := func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "value", nil
+	}
+
+	v, err := c.GetOrLoad(context.Background(), "k", 5*time.Minute, loader)
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.Equal(t, 5*time.Minute, mr.TTL("test:k"))
+
+	v, err = c.GetOrLoad(context.Background(), "k", 5*time.Minute, loader)
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 过期后重新加载
+	mr.FastForward(6 * time.Minute)
+	_, err = c.GetOrLoad(context.Background(), "k", 5*time.Minute, loader)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+// TestGetOrLoad_NegativeCache 测试负缓存
+//
+// 【功能点】验证 loader 返回 ErrNotFound 时以 NegativeTTL 缓存不存在结果
+// 【测试流程】
+//  1. 启用负缓存，loader 返回 ErrNotFound
+//  2. 断言返回 ErrNotFound 且 TTL 为 NegativeTTL
+//  3. 再次调用不执行 loader，仍返回 ErrNotFound
+//  4. 负缓存过期后重新执行 loader
+func TestGetOrLoad_NegativeCache(t *testing.T) {
+	mr, client := newTestRedis(t)
+	c := New[testUser](client, WithNegativeTTL(10*time.Second))
+
+	var calls int32
+	loader := func(ctx context.Context) (testUser, error) {
+		atomic.AddInt32(&calls, 1)
+		return testUser{}, ErrNotFound
+	}
+
+	_, err := c.GetOrLoad(context.Background(), "missing", time.Minute, loader)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, 10*time.Second, mr.TTL("cache:missing"))
+
+	_, err = c.GetOrLoad(context.Background(), "missing", time.Minute, loader)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	mr.FastForward(11 * time.Second)
+	_, err = c.GetOrLoad(context.Background(), "missing", time.Minute, loader)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+// TestGetOrLoad_NegativeCacheDisabled 测试未启用负缓存时不缓存不存在结果
+//
+// 【功能点】验证 NegativeTTL 为 0 时 ErrNotFound 不会被缓存，其他 loader 错误同样不会被缓存
+// 【测试流程】连续两次调用，loader 均被执行
+func TestGetOrLoad_NegativeCacheDisabled(t *testing.T) {
+	mr, client := newTestRedis(t)
+	c := New[int](client)
+
+	var calls int32
+	loadErr := errors.New("db down")
+	loader := func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, loadErr
+	}
+
+	_, err := c.GetOrLoad(context.Background(), "n", time.Minute, loader)
+	assert.ErrorIs(t, err, loadErr)
+	_, err = c.GetOrLoad(context.Background(), "n", time.Minute, loader)
+	assert.ErrorIs(t, err, loadErr)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.False(t, mr.Exists("cache:n"))
+}
+
+// TestGetOrLoad_TypedStruct 测试结构体值的类型化反序列化
+//
+// 【功能点】验证缓存命中时能正确反序列化为结构体（含切片和布尔字段）
+// 【测试流程】通过 Set 写入结构体，再用 Get/GetOrLoad 读取并比较
+func TestGetOrLoad_TypedStruct(t *testing.T) {
+	_, client := newTestRedis(t)
+	c := New[testUser](client)
+
+	want := testUser{ID: 7, Name: "bob", Tags: []string{"a", "b"}, Admin: true}
+	require.NoError(t, c.Set(context.Background(), "user:7", want, time.Minute))
+
+	got, hit, err := c.Get(context.Background(), "user:7")
+	require.NoError(t, err)
+	assert.True(t, hit)
+	assert.Equal(t, want, got)
+
+	got, err = c.GetOrLoad(context.Background(), "user:7", time.Minute, func(ctx context.Context) (testUser, error) {
+		t.Fatal("缓存命中时不应调用 loader")
+		return testUser{}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// 指针类型同样支持
+	pc := New[*testUser](client)
+	require.NoError(t, pc.Set(context.Background(), "user:ptr", &want, time.Minute))
+	gotPtr, hit, err := pc.Get(context.Background(), "user:ptr")
+	require.NoError(t, err)
+	assert.True(t, hit)
+	assert.Equal(t, want, *gotPtr)
+}
+
+// TestGetOrLoad_CorruptedValue 测试缓存数据损坏时重新加载
+//
+// 【功能点】验证缓存值无法反序列化时重新调用 loader 并覆盖缓存
+// 【测试流程】直接写入非法 JSON，调用 GetOrLoad 后断言返回 loader 结果且缓存被修复
+func TestGetOrLoad_CorruptedValue(t *testing.T) {
+	mr, client := newTestRedis(t)
+	c := New[testUser](client)
+	require.NoError(t, mr.Set("cache:user:1", "not-json"))
+
+	got, err := c.GetOrLoad(context.Background(), "user:1", time.Minute, func(ctx context.Context) (testUser, error) {
+		return testUser{ID: 1}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.ID)
+
+	raw, err := mr.Get("cache:user:1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"name":"","tags":null,"admin":false}`, raw)
+}
+
+// TestGetOrLoad_RedisUnavailable 测试 Redis 不可用时降级为直接加载
+//
+// 【功能点】验证 Redis 连接失败时不返回错误，而是直接调用 loader
+// 【测试流程】关闭 miniredis 后调用 GetOrLoad，断言 loader 结果被返回
+func TestGetOrLoad_RedisUnavailable(t *testing.T) {
+	mr, client := newTestRedis(t)
+	mr.Close()
+	c := New[string](client)
+
+	var calls int32
+	for i := 0; i < 2; i++ {
+		v, err := c.GetOrLoad(context.Background(), "k", time.Minute, func(ctx context.Context) (string, error) {
+			atomic.AddInt32(&calls, 1)
+			return "fallback", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "fallback", v)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+// TestDeleteAndInvalidate 测试删除与批量失效
+//
+// 【功能点】验证 Delete 删除指定键、Invalidate 按模式删除且不影响其他前缀的键
+// 【测试流程】写入多个键，分别调用 Delete 和 Invalidate 并检查剩余键
+func TestDeleteAndInvalidate(t *testing.T) {
+	mr, client := newTestRedis(t)
+	c := New[int](client)
+	ctx := context.Background()
+
+	for _, key := range []string{"user:1", "user:2", "user:3", "order:1"} {
+		require.NoError(t, c.Set(ctx, key, 1, time.Minute))
+	}
+	require.NoError(t, mr.Set("other:user:1", "x"))
+
+	require.NoError(t, c.Delete(ctx, "user:1"))
+	assert.False(t, mr.Exists("cache:user:1"))
+
+	n, err := c.Invalidate(ctx, "user:*")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.False(t, mr.Exists("cache:user:2"))
+	assert.True(t, mr.Exists("cache:order:1"))
+	assert.True(t, mr.Exists("other:user:1"))
+}
+
+// newTestCluster 创建两个 miniredis 节点组成的集群客户端，前一半槽位分配给第一个节点，后一半分配给第二个节点
+func newTestCluster(t *testing.T) (*miniredis.Miniredis, *miniredis.Miniredis, redis.UniversalClient) {
+	mr1, mr2 := miniredis.RunT(t), miniredis.RunT(t)
+	client := redis.NewClusterClient(&redis.ClusterOptions{
+		ClusterSlots: func(ctx context.Context) ([]redis.ClusterSlot, error) {
+			return []redis.ClusterSlot{
+				{Start: 0, End: 8191, Nodes: []redis.ClusterNode{{Addr: mr1.Addr()}}},
+				{Start: 8192, End: 16383, Nodes: []redis.ClusterNode{{Addr: mr2.Addr()}}},
+			}, nil
+		},
+	})
+	t.Cleanup(func() { _ = client.Close() })
+	return mr1, mr2, client
+}
+
+// TestDeleteAndInvalidate_Cluster 测试集群模式下的删除与批量失效
+//
+// 【功能点】验证缓存键分布在不同节点上时，Delete 删除全部指定键，Invalidate 遍历每个主节点删除匹配的键
+// 【测试流程】
+//  1. 通过集群客户端写入 20 个 user 键和 1 个 order 键，断言两个节点上都有键
+//  2. Delete 前 10 个 user 键，断言这些键在两个节点上均不存在
+//  3. Invalidate("user:*")，断言删除 10 个键、两个节点上都没有 user 键、order 键保留
+func TestDeleteAndInvalidate_Cluster(t *testing.T) {
+	mr1, mr2, client := newTestCluster(t)
+	c := New[int](client)
+	ctx := context.Background()
+
+	keys := make([]string, 20)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("user:%d", i)
+		require.NoError(t, c.Set(ctx, keys[i], i, time.Minute))
+	}
+	require.NoError(t, c.Set(ctx, "order:1", 1, time.Minute))
+	require.NotEmpty(t, mr1.Keys())
+	require.NotEmpty(t, mr2.Keys())
+
+	require.NoError(t, c.Delete(ctx, keys[:10]...))
+	for _, key := range keys[:10] {
+		assert.False(t, mr1.Exists("cache:"+key) || mr2.Exists("cache:"+key), key)
+	}
+
+	n, err := c.Invalidate(ctx, "user:*")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), n)
+	for _, key := range keys[10:] {
+		assert.False(t, mr1.Exists("cache:"+key) || mr2.Exists("cache:"+key), key)
+	}
+	assert.True(t, mr1.Exists("cache:order:1") || mr2.Exists("cache:order:1"))
+}
+
+// TestSetWithTags 测试写入带标签的缓存
+//
+// 【功能点】验证 SetWithTags 写入的值可以读取，缓存键（含前缀）登记到每个标签集合
+// 【测试流程】以标签 users、tenant:1 写入两个键，断言读取结果和两个标签集合的成员
+func TestSetWithTags(t *testing.T) {
+	mr, client := newTestRedis(t)
+	c := New[testUser](client)
+	ctx := context.Background()
+
+	require.NoError(t, c.SetWithTags(ctx, "user:1", testUser{ID: 1, Name: "alice"}, time.Minute, "users", "tenant:1"))
+	require.NoError(t, c.SetWithTags(ctx, "user:2", testUser{ID: 2, Name: "bob"}, time.Minute, "users"))
+
+	value, hit, err := c.Get(ctx, "user:1")
+	require.NoError(t, err)
+	assert.True(t, hit)
+	assert.Equal(t, "alice", value.Name)
+
+	members, err := mr.Members("cache:tag:users")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"cache:user:1", "cache:user:2"}, members)
+	members, err = mr.Members("cache:tag:tenant:1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cache:user:1"}, members)
+}
+
+// TestSetWithTags_TagTTL 测试标签集合的过期时间
+//
+// 【功能点】验证标签集合的过期时间不短于成员的最长过期时间，成员永不过期时标签集合永不过期
+// 【测试流程】
+//  1. 依次写入过期时间为 1m、10m、30s 的成员，断言标签集合的过期时间为 1m、10m、10m
+//  2. 写入永不过期的成员，断言标签集合永不过期；再写入 5m 的成员，仍永不过期
+func TestSetWithTags_TagTTL(t *testing.T) {
+	mr, client := newTestRedis(t)
+	c := New[int](client)
+	ctx := context.Background()
+
+	steps := []struct {
+		ttl  time.Duration
+		want time.Duration // 0 表示永不过期
+	}{
+		{time.Minute, time.Minute},
+		{10 * time.Minute, 10 * time.Minute},
+		{30 * time.Second, 10 * time.Minute},
+		{0, 0},
+		{5 * time.Minute, 0},
+	}
+	for i, step := range steps {
+		require.NoError(t, c.SetWithTags(ctx, fmt.Sprintf("k:%d", i), i, step.ttl, "t"))
+		assert.Equal(t, step.want, mr.TTL("cache:tag:t"), "第 %d 次写入（ttl=%s）后标签集合的过期时间", i+1, step.ttl)
+	}
+}
+
+// TestGetOrLoadWithTags 测试加载时登记标签
+//
+// 【功能点】验证 GetOrLoadWithTags 回写缓存和负缓存时登记标签，失效标签后重新加载
+// 【测试流程】
+//  1. 加载存在和不存在（负缓存）的两个键，断言两个键都登记到标签
+//  2. 再次读取命中缓存，loader 不再调用
+//  3. InvalidateTag 后再次读取，loader 重新调用
+func TestGetOrLoadWithTags(t *testing.T) {
+	mr, client := newTestRedis(t)
+	c := New[testUser](client, WithNegativeTTL(time.Minute))
+	ctx := context.Background()
+
+	var calls int32
+	loader := func(id int) func(ctx context.Context) (testUser, error) {
+		return func(ctx context.Context) (testUser, error) {
+			atomic.AddInt32(&calls, 1)
+			if id == 0 {
+				return testUser{}, ErrNotFound
+			}
+			return testUser{ID: id}, nil
+		}
+	}
+
+	user, err := c.GetOrLoadWithTags(ctx, "user:1", time.Minute, loader(1), "users")
+	require.NoError(t, err)
+	assert.Equal(t, 1, user.ID)
+	_, err = c.GetOrLoadWithTags(ctx, "user:0", time.Minute, loader(0), "users")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	members, err := mr.Members("cache:tag:users")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"cache:user:0", "cache:user:1"}, members)
+
+	_, _ = c.GetOrLoadWithTags(ctx, "user:1", time.Minute, loader(1), "users")
+	_, _ = c.GetOrLoadWithTags(ctx, "user:0", time.Minute, loader(0), "users")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	require.NoError(t, c.InvalidateTag(ctx, "users"))
+	_, _ = c.GetOrLoadWithTags(ctx, "user:1", time.Minute, loader(1), "users")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+// TestInvalidateTag 测试按标签失效
+//
+// 【功能点】验证 InvalidateTag 分批删除标签下的所有缓存键和标签集合，容忍已过期的成员，不影响其他键
+// 【测试流程】
+//  1. 每批 100 个，写入 1050 个带标签 users 的键，其中 1 个键已被删除（模拟过期）
+//  2. 写入一个不带标签的键和一个带其他标签的键
+//  3. 调用 InvalidateTag，断言所有成员和标签集合被删除，其他键保留
+func TestInvalidateTag(t *testing.T) {
+	mr, client := newTestRedis(t)
+	c := New[int](client, WithTagBatchSize(100))
+	ctx := context.Background()
+
+	for i := 0; i < 1050; i++ {
+		require.NoError(t, c.SetWithTags(ctx, fmt.Sprintf("user:%d", i), i, time.Hour, "users"))
+	}
+	mr.Del("cache:user:7")
+	require.NoError(t, c.Set(ctx, "config", 1, time.Hour))
+	require.NoError(t, c.SetWithTags(ctx, "order:1", 1, time.Hour, "orders"))
+
+	require.NoError(t, c.InvalidateTag(ctx, "users"))
+
+	for i := 0; i < 1050; i++ {
+		if mr.Exists(fmt.Sprintf("cache:user:%d", i)) {
+			t.Fatalf("cache:user:%d 未被删除", i)
+		}
+	}
+	assert.False(t, mr.Exists("cache:tag:users"))
+	assert.True(t, mr.Exists("cache:config"))
+	assert.True(t, mr.Exists("cache:order:1"))
+	assert.True(t, mr.Exists("cache:tag:orders"))
+
+	// 不存在的标签
+	assert.NoError(t, c.InvalidateTag(ctx, "missing"))
+}
+
+// failingPipelineHook 调用 failAt 后，第 n 次执行管道时返回错误
+// 只统计调用 failAt 之后的管道，不包括建立连接时的握手管道
+type failingPipelineHook struct {
+	armed atomic.Int32
+	calls atomic.Int32
+}
+
+func (h *failingPipelineHook) failAt(n int32) {
+	h.calls.Store(0)
+	h.armed.Store(n)
+}
+
+func (h *failingPipelineHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *failingPipelineHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+
+func (h *failingPipelineHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if n := h.armed.Load(); n > 0 && h.calls.Add(1) == n {
+			return errors.New("injected pipeline failure")
+		}
+		return next(ctx, cmds)
+	}
+}
+
+// TestInvalidateTag_PartialFailure 测试失效中途失败
+//
+// 【功能点】验证某一批删除失败时返回错误，未删除的缓存键仍登记在标签中，再次调用可继续失效
+// 【测试流程】
+//  1. 每批 10 个，写入 50 个带标签的键，第 3 批的删除管道返回错误
+//  2. 断言返回错误，已删除 20 个键，剩余 30 个键都仍是标签集合的成员
+//  3. 再次调用 InvalidateTag，断言所有键和标签集合被删除
+func TestInvalidateTag_PartialFailure(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	hook := &failingPipelineHook{}
+	client.AddHook(hook)
+
+	c := New[int](client, WithTagBatchSize(10))
+	ctx := context.Background()
+	for i := 0; i < 50; i++ {
+		require.NoError(t, c.SetWithTags(ctx, fmt.Sprintf("user:%d", i), i, time.Hour, "users"))
+	}
+
+	hook.failAt(3)
+	err := c.InvalidateTag(ctx, "users")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "injected pipeline failure")
+
+	var remaining []string
+	for i := 0; i < 50; i++ {
+		if key := fmt.Sprintf("cache:user:%d", i); mr.Exists(key) {
+			remaining = append(remaining, key)
+		}
+	}
+	assert.Len(t, remaining, 30)
+	members, err := mr.Members("cache:tag:users")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, remaining, members)
+
+	require.NoError(t, c.InvalidateTag(ctx, "users"))
+	for _, key := range remaining {
+		assert.False(t, mr.Exists(key), "%s 未被删除", key)
+	}
+	assert.False(t, mr.Exists("cache:tag:users"))
+}
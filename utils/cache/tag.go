@@ -0,0 +1,153 @@
+// Package cache 提供基于 Redis 的旁路缓存（cache-aside）工具。
+// 本文件实现缓存标签：写入时将缓存键登记到标签集合，按标签批量失效一组缓存键
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tagAddScript 将缓存键加入标签集合，并保证标签集合的过期时间不短于成员的过期时间
+// KEYS[1]: 标签集合；ARGV[1]: 缓存键（含前缀）；ARGV[2]: 缓存键的过期时间（毫秒），<= 0 表示永不过期
+// 标签集合原本没有过期时间，或成员永不过期时，标签集合永不过期
+// 只访问一个键，集群模式下同样适用
+var tagAddScript = redis.NewScript(`
+local pttl = redis.call('PTTL', KEYS[1])
+redis.call('SADD', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+	if pttl >= 0 then
+		redis.call('PERSIST', KEYS[1])
+	end
+elseif pttl == -2 or (pttl >= 0 and pttl < ttl) then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// tagKey 拼接标签集合的键
+func (c *Cache[T]) tagKey(tag string) string {
+	return c.config.KeyPrefix + "tag:" + tag
+}
+
+// SetWithTags 写入缓存，并将缓存键登记到各标签集合，之后可通过 InvalidateTag 按标签批量失效
+// 先登记标签再写入缓存，登记失败时不写入缓存；写入失败时标签集合中残留的成员在失效时被忽略
+// 参数：
+//   - ctx: 上下文
+//   - key: 缓存键（不含前缀）
+//   - value: 缓存值
+//   - ttl: 缓存过期时间，0 表示永不过期；标签集合的过期时间不短于其中成员的最长过期时间
+//   - tags: 标签，如 "users"、"tenant:1"
+//
+// 返回：
+//   - error: Redis 错误或序列化错误
+func (c *Cache[T]) SetWithTags(ctx context.Context, key string, value T, ttl time.Duration, tags ...string) error {
+	client := c.redisClient()
+	if client == nil {
+		return errors.New("【缓存】Redis 未初始化")
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("【缓存】序列化失败, key: %s: %w", key, err)
+	}
+	if err := c.addTags(ctx, client, key, ttl, tags); err != nil {
+		return err
+	}
+	return client.Set(ctx, c.fullKey(key), data, ttl).Err()
+}
+
+// addTags 将缓存键登记到各标签集合
+func (c *Cache[T]) addTags(ctx context.Context, client redis.UniversalClient, key string, ttl time.Duration, tags []string) error {
+	ttlMs := int64(0)
+	if ttl > 0 {
+		ttlMs = max(ttl.Milliseconds(), 1)
+	}
+	for _, tag := range tags {
+		if err := tagAddScript.Run(ctx, client, []string{c.tagKey(tag)}, c.fullKey(key), ttlMs).Err(); err != nil {
+			return fmt.Errorf("【缓存】登记标签失败, key: %s, tag: %s: %w", key, tag, err)
+		}
+	}
+	return nil
+}
+
+// GetOrLoadWithTags 读取缓存，未命中时调用 loader 加载，回写缓存时登记标签
+// 执行流程与 GetOrLoad 相同，负缓存的占位值同样登记标签
+//
+// 参数：
+//   - ctx: 上下文
+//   - key: 缓存键（不含前缀）
+//   - ttl: 缓存过期时间
+//   - loader: 数据加载函数
+//   - tags: 标签
+//
+// 返回：
+//   - T: 缓存值或加载结果
+//   - error: loader 错误、ErrNotFound 或序列化错误
+func (c *Cache[T]) GetOrLoadWithTags(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error), tags ...string) (T, error) {
+	return c.getOrLoad(ctx, key, ttl, loader, tags)
+}
+
+// InvalidateTag 删除标签下的所有缓存键，最后删除标签集合
+// 每批通过 SRANDMEMBER 取出 TagBatchSize 个成员，以管道删除缓存键后再从标签集合中移除，
+// 大标签分多批处理，不会长时间阻塞 Redis；已过期的缓存键仍留在标签集合中，删除时被忽略。
+// 中途失败时，未删除的缓存键仍在标签集合中，再次调用即可继续失效。
+// 失效期间新登记到该标签的缓存键可能随标签集合一起被移除登记，但缓存本身不受影响
+//
+// 参数：
+//   - ctx: 上下文
+//   - tag: 标签
+//
+// 返回：
+//   - error: Redis 错误
+func (c *Cache[T]) InvalidateTag(ctx context.Context, tag string) error {
+	client := c.redisClient()
+	if client == nil {
+		return errors.New("【缓存】Redis 未初始化")
+	}
+
+	tagKey := c.tagKey(tag)
+	batchSize := c.config.GetTagBatchSize()
+	total, err := client.SCard(ctx, tagKey).Result()
+	if err != nil {
+		return fmt.Errorf("【缓存】读取标签失败, tag: %s: %w", tag, err)
+	}
+
+	// 每批至少移除一个成员，轮数上限避免失效期间持续写入的标签无法结束
+	for rounds := total/int64(batchSize) + 1; rounds > 0; rounds-- {
+		members, err := client.SRandMemberN(ctx, tagKey, int64(batchSize)).Result()
+		if err != nil {
+			return fmt.Errorf("【缓存】读取标签成员失败, tag: %s: %w", tag, err)
+		}
+		if len(members) == 0 {
+			break
+		}
+		if err := deleteTagMembers(ctx, client, tagKey, members); err != nil {
+			return fmt.Errorf("【缓存】失效标签失败, tag: %s: %w", tag, err)
+		}
+	}
+	return client.Del(ctx, tagKey).Err()
+}
+
+// deleteTagMembers 删除一批缓存键，全部删除成功后再从标签集合中移除
+// 逐个键执行 DEL，集群模式下不会因键分布在不同槽位而失败
+func deleteTagMembers(ctx context.Context, client redis.UniversalClient, tagKey string, members []string) error {
+	pipe := client.Pipeline()
+	for _, member := range members {
+		pipe.Del(ctx, member)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	args := make([]any, len(members))
+	for i, member := range members {
+		args[i] = member
+	}
+	return client.SRem(ctx, tagKey, args...).Err()
+}
@@ -5,9 +5,13 @@ import (
 	"bytes"
 	"crypto/aes"
 	"encoding/base64"
+	"errors"
 	"fmt"
 )
 
+// ErrInvalidPadding 解密结果的 PKCS7 填充不合法，通常是密钥错误或密文被篡改
+var ErrInvalidPadding = errors.New("invalid PKCS7 padding")
+
 // AesEcbEncrypt AES ECB模式加密
 // plainText: 待加密的明文
 // key: 加密密钥，长度必须为16、24或32字节
@@ -50,40 +54,72 @@ func AesEcbEncrypt(plainText string, key string, isPad ...bool) (string, error)
 // isPad: 是否使用padding填充，默认为true
 // 返回: 解密后的明文和错误信息
 func AesEcbDecrypt(cryptText string, key string, isPad ...bool) (string, error) {
+	decrypted, err := aesEcbDecryptBlocks(cryptText, key)
+	if err != nil {
+		return "", err
+	}
+
+	// 根据参数决定是否去除padding填充
+	if len(isPad) > 0 && !isPad[0] {
+		decrypted = unNoPadding(decrypted)
+	} else {
+		decrypted = unPadding(decrypted)
+	}
+
+	return string(decrypted), nil
+}
+
+// AesEcbDecryptStrict AES ECB模式解密，要求 PKCS7 填充合法
+// 与 AesEcbDecrypt 不同，填充不合法时返回 ErrInvalidPadding 而不是原样返回，
+// 用于依次尝试多个密钥时判断密钥是否正确；ECB 模式没有完整性校验，错误的密钥仍有很小的概率得到合法的填充
+// cryptText: base64编码的密文
+// key: 解密密钥，长度必须为16、24或32字节
+// 返回: 解密后的明文和错误信息
+func AesEcbDecryptStrict(cryptText string, key string) (string, error) {
+	decrypted, err := aesEcbDecryptBlocks(cryptText, key)
+	if err != nil {
+		return "", err
+	}
+	p := int(decrypted[len(decrypted)-1])
+	if p == 0 || p > aes.BlockSize {
+		return "", ErrInvalidPadding
+	}
+	for _, b := range decrypted[len(decrypted)-p:] {
+		if b != byte(p) {
+			return "", ErrInvalidPadding
+		}
+	}
+	return string(decrypted[:len(decrypted)-p]), nil
+}
+
+// aesEcbDecryptBlocks 解码 base64 密文并分块解密，返回未去除填充的明文
+func aesEcbDecryptBlocks(cryptText string, key string) ([]byte, error) {
 	// 将base64编码的密文解码为字节数组
 	cryptBytes, err := base64.StdEncoding.DecodeString(cryptText)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if len(cryptBytes) == 0 {
-		return "", fmt.Errorf("content is empty")
+		return nil, fmt.Errorf("content is empty")
 	}
 	if len(cryptBytes)%aes.BlockSize != 0 {
-		return "", fmt.Errorf("ciphertext length %d is not a multiple of AES block size %d", len(cryptBytes), aes.BlockSize)
+		return nil, fmt.Errorf("ciphertext length %d is not a multiple of AES block size %d", len(cryptBytes), aes.BlockSize)
 	}
 
 	// 创建AES密码块
 	block, err := aes.NewCipher([]byte(key))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// 分块解密
 	buf := make([]byte, aes.BlockSize)
-	decrypted := make([]byte, 0)
+	decrypted := make([]byte, 0, len(cryptBytes))
 	for i := 0; i < len(cryptBytes); i += aes.BlockSize {
 		block.Decrypt(buf, cryptBytes[i:i+aes.BlockSize])
 		decrypted = append(decrypted, buf...)
 	}
-
-	// 根据参数决定是否去除padding填充
-	if len(isPad) > 0 && !isPad[0] {
-		decrypted = unNoPadding(decrypted)
-	} else {
-		decrypted = unPadding(decrypted)
-	}
-
-	return string(decrypted), nil
+	return decrypted, nil
 }
 
 // noPadding 零填充模式
@@ -7,6 +7,7 @@
 // 1. AesEcbEncrypt - AES ECB 模式加密
 // 2. AesEcbDecrypt - AES ECB 模式解密（含密文长度校验等异常场景）
 // 3. AesEcbCrypt - 加密解密完整流程（加密→解密→验证一致性）
+// 4. AesEcbDecryptStrict - 填充校验（错误密钥返回 ErrInvalidPadding）
 //
 // 密钥要求：
 // - AES-128: 16字节密钥
@@ -187,3 +188,22 @@ func TestAesEcbCrypt(t *testing.T) {
 		})
 	}
 }
+
+// ==================== AES ECB 严格解密测试 ====================
+
+// TestAesEcbDecryptStrict 测试AES ECB模式严格解密功能
+//
+// 【功能点】验证严格解密在填充合法时返回明文，填充不合法时返回 ErrInvalidPadding
+// 【测试流程】
+//  1. 使用正确密钥解密，验证明文
+//  2. 使用错误密钥解密，验证返回 ErrInvalidPadding（AesEcbDecrypt 则原样返回不报错）
+func TestAesEcbDecryptStrict(t *testing.T) {
+	got, err := AesEcbDecryptStrict("MUnLTlM2mFGy5FJ3FFSaqw==", "UTabIUiHgDyh464+")
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello World", got)
+
+	_, err = AesEcbDecryptStrict("MUnLTlM2mFGy5FJ3FFSaqw==", "0123456789abcdef")
+	assert.ErrorIs(t, err, ErrInvalidPadding)
+	_, err = AesEcbDecrypt("MUnLTlM2mFGy5FJ3FFSaqw==", "0123456789abcdef")
+	assert.Nil(t, err)
+}
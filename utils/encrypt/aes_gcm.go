@@ -0,0 +1,67 @@
+// Package encrypt 提供AES GCM模式的加密解密功能
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// AesGcmEncrypt AES GCM模式加密
+// 每次加密使用随机的 12 字节 nonce，相同明文的加密结果不同；密文带认证标签，篡改后解密失败
+// plainText: 待加密的明文
+// key: 加密密钥，长度必须为16、24或32字节
+// 返回: base64编码的 nonce + 密文 + 认证标签和错误信息
+func AesGcmEncrypt(plainText string, key string) (string, error) {
+	if plainText == "" {
+		return "", fmt.Errorf("content is empty")
+	}
+	gcm, err := newGcm(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	// 密文追加在 nonce 之后，解密时按 nonce 长度拆分
+	sealed := gcm.Seal(nonce, nonce, []byte(plainText), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// AesGcmDecrypt AES GCM模式解密
+// cryptText: AesGcmEncrypt 返回的base64编码密文
+// key: 解密密钥，长度必须为16、24或32字节
+// 返回: 解密后的明文和错误信息，密钥错误或密文被篡改时认证失败并返回错误
+func AesGcmDecrypt(cryptText string, key string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(cryptText)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGcm(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize()+gcm.Overhead() {
+		return "", fmt.Errorf("ciphertext length %d is too short", len(sealed))
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// newGcm 使用密钥创建 AES GCM 实例
+func newGcm(key string) (cipher.AEAD, error) {
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,73 @@
+// Package encrypt AES GCM模式加密解密功能测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 AES GCM 模式加密解密功能的单元测试。
+//
+// 测试覆盖内容：
+// 1. AesGcmEncrypt / AesGcmDecrypt - 加密解密完整流程
+// 2. 随机 nonce - 相同明文的加密结果不同
+// 3. 认证失败 - 密文被篡改或密钥错误时解密返回错误
+//
+// 运行测试：go test -v ./utils/encrypt/...
+// ==================================================
+package encrypt
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAesGcmCrypt 测试AES GCM模式加密解密完整流程
+//
+// 【功能点】验证 AES-128 / AES-256 密钥下 明文 → 加密 → 解密 → 明文 一致，且每次加密结果不同
+// 【测试流程】
+//  1. 分别使用 16、32 字节密钥加密两次，验证两次密文不同
+//  2. 解密两次密文，验证与原始明文一致
+//  3. 空明文和非法长度的密钥返回错误
+func TestAesGcmCrypt(t *testing.T) {
+	for _, key := range []string{"UTabIUiHgDyh464+", "UTabIUiHgDyh464+UTabIUiHgDyh464+"} {
+		first, err := AesGcmEncrypt("Hello World", key)
+		assert.Nil(t, err)
+		second, err := AesGcmEncrypt("Hello World", key)
+		assert.Nil(t, err)
+		assert.NotEqual(t, first, second)
+
+		for _, cryptText := range []string{first, second} {
+			plain, err := AesGcmDecrypt(cryptText, key)
+			assert.Nil(t, err)
+			assert.Equal(t, "Hello World", plain)
+		}
+	}
+
+	_, err := AesGcmEncrypt("", "UTabIUiHgDyh464+")
+	assert.Error(t, err)
+	_, err = AesGcmEncrypt("Hello World", "short")
+	assert.Error(t, err)
+}
+
+// TestAesGcmDecrypt_Tampered 测试AES GCM模式的篡改检测
+//
+// 【功能点】验证密文被篡改、截断或使用错误密钥时解密失败
+// 【测试流程】
+//  1. 加密后翻转密文中的一个字节，验证解密返回错误
+//  2. 截断到 nonce 长度以内，验证返回错误
+//  3. 使用错误密钥解密，验证返回错误
+func TestAesGcmDecrypt_Tampered(t *testing.T) {
+	key := "UTabIUiHgDyh464+"
+	cryptText, err := AesGcmEncrypt("Hello World", key)
+	assert.Nil(t, err)
+	sealed, _ := base64.StdEncoding.DecodeString(cryptText)
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-20] ^= 0x01
+	_, err = AesGcmDecrypt(base64.StdEncoding.EncodeToString(tampered), key)
+	assert.Error(t, err)
+
+	_, err = AesGcmDecrypt(base64.StdEncoding.EncodeToString(sealed[:8]), key)
+	assert.Error(t, err)
+
+	_, err = AesGcmDecrypt(cryptText, "0123456789abcdef")
+	assert.Error(t, err)
+}
@@ -0,0 +1,45 @@
+package ginContext
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/tasks"
+)
+
+// Async 将任务提交到后台任务执行器，替代在 handler 中直接启动协程
+// 任务的 ctx 携带当前请求的追踪 ID（tasks.TraceID）、RequestContext（FromStdContext）和链路追踪 Span，
+// 不随请求结束而取消；任务中的 panic 会被捕获并记录日志，不会导致进程退出
+//
+// 参数：
+//   - c: Gin上下文
+//   - name: 任务名称，用于日志
+//   - fn: 任务函数
+//
+// 返回值：
+//   - error: 队列已满时返回 tasks.ErrQueueFull，执行器已关闭时返回 tasks.ErrRunnerClosed
+//
+// 使用示例：
+//
+//	func Register(c *gin.Context) {
+//	  user := createUser(c)
+//	  if err := ginContext.Async(c, "sendWelcomeEmail", func(ctx context.Context) error {
+//	    return sendWelcomeEmail(ctx, user.Email)
+//	  }); err != nil {
+//	    logger.Warn("提交发送欢迎邮件任务失败: %v", err)
+//	  }
+//	  response.Ok(c)
+//	}
+func Async(c *gin.Context, name string, fn func(ctx context.Context) error) error {
+	return tasks.Submit(asyncContext(c), name, fn)
+}
+
+// asyncContext 构造任务的上下文，携带当前请求的追踪 ID 和 RequestContext
+func asyncContext(c *gin.Context) context.Context {
+	ctx := context.Background()
+	if c.Request != nil {
+		ctx = c.Request.Context()
+	}
+	ctx = WithRequestContext(ctx, GetRequestContext(c))
+	return tasks.WithTraceID(ctx, GetTraceID(c))
+}
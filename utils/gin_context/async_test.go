@@ -0,0 +1,74 @@
+// Package ginContext 后台任务提交测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 Async 的单元测试。
+//
+// 测试覆盖内容：
+// 1. 任务 ctx 携带请求的追踪 ID 和 RequestContext，且不随请求结束而取消
+// 2. 旧版键 "traceId" 写入的追踪 ID 同样可以传递
+//
+// 运行测试：go test -v ./utils/gin_context/... -run Async
+// ==================================================
+package ginContext
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/tasks"
+)
+
+// TestAsync_TracePropagation 测试追踪 ID 传递
+//
+// 【功能点】验证 Async 提交的任务可通过 tasks.TraceID 和 FromStdContext 读取请求的追踪 ID、用户 ID，
+// 请求 ctx 取消后任务 ctx 仍然有效
+// 【测试流程】
+//  1. 设置默认执行器，请求中写入追踪 ID 和用户 ID，请求 ctx 可取消
+//  2. 调用 Async 后取消请求 ctx，关闭执行器，断言任务读取到的值和 ctx 状态
+//  3. 仅通过旧版键写入追踪 ID，断言任务同样可以读取
+func TestAsync_TracePropagation(t *testing.T) {
+	original := tasks.Default()
+	t.Cleanup(func() { tasks.SetDefault(original) })
+	runner := tasks.NewRunner(tasks.Options{Workers: 1})
+	tasks.SetDefault(runner)
+
+	type result struct {
+		traceID, rcTraceID, userID string
+		err                        error
+	}
+	got := make(chan result, 2)
+	capture := func(ctx context.Context) error {
+		res := result{traceID: tasks.TraceID(ctx), err: ctx.Err()}
+		if rc, ok := FromStdContext(ctx); ok {
+			res.rcTraceID, res.userID = rc.TraceID(), rc.UserID()
+		}
+		got <- res
+		return nil
+	}
+
+	c := newRequestContextTestContext()
+	reqCtx, cancel := context.WithCancel(c.Request.Context())
+	c.Request = c.Request.WithContext(reqCtx)
+	SetTraceID(c, "trace-async")
+	SetUserID(c, "u1")
+	require.NoError(t, Async(c, "capture", capture))
+	cancel()
+
+	legacy := newRequestContextTestContext()
+	legacy.Set("traceId", "trace-legacy")
+	require.NoError(t, Async(legacy, "capture", capture))
+
+	_, err := runner.Shutdown(context.Background())
+	require.NoError(t, err)
+
+	res := <-got
+	assert.Equal(t, "trace-async", res.traceID)
+	assert.Equal(t, "trace-async", res.rcTraceID)
+	assert.Equal(t, "u1", res.userID)
+	assert.NoError(t, res.err)
+
+	res = <-got
+	assert.Equal(t, "trace-legacy", res.traceID)
+}
@@ -0,0 +1,167 @@
+package ginContext
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/zzsen/gin_core/exception"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// supportedContentTypes 支持绑定的请求体类型
+var supportedContentTypes = map[string]bool{
+	binding.MIMEJSON:              true,
+	binding.MIMEXML:               true,
+	binding.MIMEXML2:              true,
+	binding.MIMEPOSTForm:          true,
+	binding.MIMEMultipartPOSTForm: true,
+	binding.MIMEYAML:              true,
+	binding.MIMEYAML2:             true,
+	binding.MIMETOML:              true,
+	binding.MIMEPROTOBUF:          true,
+	binding.MIMEMSGPACK:           true,
+	binding.MIMEMSGPACK2:          true,
+}
+
+// BindAndValidate 绑定请求参数并校验，失败时直接写入参数校验失败响应
+// 在一次调用中合并以下来源的参数（后者覆盖前者）：
+// 1. URL查询参数（form 标签）
+// 2. 请求体，根据请求方法和 Content-Type 选择绑定器（JSON、XML、表单等）
+// 3. URL路径参数（uri 标签），gin 原生不支持与请求体同时绑定
+//
+// 所有来源绑定完成后统一执行一次校验（binding 标签）。
+// 失败时写入与 ExceptionHandler 处理 InvalidParam 异常完全一致的响应，并中断后续处理。
+//
+// 参数：
+//   - c: Gin上下文
+//
+// 返回值：
+//   - T: 绑定后的请求结构体
+//   - bool: 是否绑定并校验成功，为 false 时响应已写入，handler 应直接返回
+//
+// 使用示例：
+//
+//	type UpdateUserReq struct {
+//	  ID   int    `uri:"id" binding:"required"`
+//	  Name string `json:"name" binding:"required"`
+//	}
+//
+//	func UpdateUser(c *gin.Context) {
+//	  req, ok := ginContext.BindAndValidate[UpdateUserReq](c)
+//	  if !ok {
+//	    return
+//	  }
+//	  ...
+//	}
+func BindAndValidate[T any](c *gin.Context) (T, bool) {
+	var req T
+	if err := bindAll(c, &req); err != nil {
+		abortWithInvalidParam(c, err, nil)
+		return req, false
+	}
+	return req, true
+}
+
+// MustBindAndValidate 绑定请求参数并校验，失败时 panic InvalidParam 异常
+// 绑定规则与 BindAndValidate 相同，适用于依赖 ExceptionHandler 统一处理异常的 handler
+//
+// 注意：此函数会 panic，请确保在有 recover 中间件保护的 handler 链中调用。
+func MustBindAndValidate[T any](c *gin.Context) T {
+	var req T
+	if err := bindAll(c, &req); err != nil {
+		panic(toInvalidParam(err, nil))
+	}
+	return req
+}
+
+// bindAll 依次从查询参数、请求体和路径参数绑定到 obj，最后统一校验
+func bindAll(c *gin.Context, obj any) error {
+	if err := bindRequest(c, obj); err != nil {
+		return err
+	}
+	return validate(obj)
+}
+
+// bindRequest 依次从查询参数、请求体和路径参数绑定到 obj，不执行校验
+func bindRequest(c *gin.Context, obj any) error {
+	method := c.Request.Method
+	hasBody := method != http.MethodGet && method != http.MethodHead && c.Request.ContentLength != 0
+
+	// 1. 查询参数：GET 等无请求体的方法由 binding.Form 统一处理
+	if !hasBody {
+		if err := ignoreValidation(binding.Form.Bind(c.Request, obj)); err != nil {
+			return err
+		}
+	} else {
+		if err := ignoreValidation(binding.Query.Bind(c.Request, obj)); err != nil {
+			return err
+		}
+
+		// 2. 请求体
+		contentType := c.ContentType()
+		if !supportedContentTypes[contentType] {
+			return fmt.Errorf("不支持的Content-Type: %s", contentType)
+		}
+		if err := ignoreValidation(c.ShouldBindWith(obj, binding.Default(method, contentType))); err != nil {
+			return err
+		}
+	}
+
+	// 3. 路径参数
+	if len(c.Params) > 0 {
+		params := make(map[string][]string, len(c.Params))
+		for _, param := range c.Params {
+			params[param.Key] = []string{param.Value}
+		}
+		if err := binding.MapFormWithTag(obj, params, "uri"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate 使用 binding.Validator 统一校验
+func validate(obj any) error {
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// ignoreValidation 忽略绑定阶段的校验错误
+// gin 的绑定器在解析完成后会立即校验，而此时其他来源的参数尚未绑定，
+// 因此中间步骤只关心解析错误，校验统一在全部绑定完成后执行
+func ignoreValidation(err error) error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return nil
+	}
+	return err
+}
+
+// toInvalidParam 将绑定或校验错误转换为 InvalidParam 异常
+// fields 不为空时，校验错误消息中的字段名按 fields 替换为参数来源名称
+func toInvalidParam(err error, fields map[string]string) exception.InvalidParam {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		if len(fields) > 0 {
+			return exception.NewInvalidParamFromValidatorWithFields(validationErrors, fields)
+		}
+		return exception.NewInvalidParamFromValidator(validationErrors)
+	}
+	return exception.NewInvalidParam(err.Error())
+}
+
+// abortWithInvalidParam 写入参数校验失败响应并中断请求
+// 响应格式与 ExceptionHandler 处理 InvalidParam 异常时保持一致；
+// 错误以 gin.ErrorTypeBind 类型记录到 c.Errors，供链路日志使用，ExceptionHandler 不再将其作为告警记录
+func abortWithInvalidParam(c *gin.Context, err error, fields map[string]string) {
+	message, code := toInvalidParam(err, fields).OnException(c)
+	status, body := response.NewError(c, response.Of(code), response.WithMessage(message))
+	_ = c.Error(fmt.Errorf("%d : %s", code, body.Msg)).SetType(gin.ErrorTypeBind)
+	c.AbortWithStatusJSON(status, body)
+}
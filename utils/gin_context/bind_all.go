@@ -0,0 +1,303 @@
+package ginContext
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// 参数来源标签，按优先级排列：同一字段声明了多个来源标签时，错误消息使用第一个
+var sourceTags = []struct {
+	tag   string
+	label string
+}{
+	{tag: "uri", label: "path"},
+	{tag: "header", label: "header"},
+	{tag: "form", label: "query"},
+}
+
+// timeType time.Time 的反射类型，默认值按 time_format 解析，不作为嵌套结构体展开
+var timeType = reflect.TypeOf(time.Time{})
+
+// durationType time.Duration 的反射类型，默认值按 time.ParseDuration 解析
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// BindAll 绑定路径参数、请求头、查询参数和请求体并校验，失败时直接写入参数校验失败响应
+// 在 BindAndValidate 的基础上增加：
+// 1. 请求头（header 标签），如 `header:"X-Tenant-Id" binding:"required"`
+// 2. 默认值（default 标签），在绑定前写入，请求中携带该参数时被覆盖
+// 3. 校验失败的消息标明参数来源，如 "header X-Tenant-Id不能为空"、"query status的值必须是以下之一: a b c"
+//
+// 绑定规则：
+// - 查询参数重复出现时绑定到切片字段，如 ?tag=a&tag=b 绑定到 []string
+// - time.Time 字段按 time_format 标签的布局解析，如 `form:"since" time_format:"2006-01-02"`
+// - 指针字段表示可选参数，请求未携带时保持 nil
+//
+// 参数：
+//   - c: Gin上下文
+//
+// 返回值：
+//   - T: 绑定后的请求结构体
+//   - bool: 是否绑定并校验成功，为 false 时响应已写入，handler 应直接返回
+//
+// 使用示例：
+//
+//	type ListOrderReq struct {
+//	  ShopID   string     `uri:"shopId" binding:"required,uuid4"`
+//	  TenantID string     `header:"X-Tenant-Id" binding:"required"`
+//	  Status   string     `form:"status" default:"a" binding:"oneof=a b c"`
+//	  Tags     []string   `form:"tag"`
+//	  Since    *time.Time `form:"since" time_format:"2006-01-02"`
+//	}
+//
+//	func ListOrder(c *gin.Context) {
+//	  req, ok := ginContext.BindAll[ListOrderReq](c)
+//	  if !ok {
+//	    return
+//	  }
+//	  ...
+//	}
+func BindAll[T any](c *gin.Context) (T, bool) {
+	var req T
+	if err := bindAllSources(c, &req); err != nil {
+		abortWithInvalidParam(c, err, fieldLabels(reflect.TypeOf(req)))
+		return req, false
+	}
+	return req, true
+}
+
+// bindAllSources 写入默认值，绑定查询参数、请求体、路径参数和请求头后统一校验
+// gin 按字段名从查询参数绑定未声明 form 标签的字段，因此绑定请求头前先重置声明了 header 标签的字段，
+// 避免请求头字段被同名查询参数填充或覆盖
+func bindAllSources(c *gin.Context, obj any) error {
+	value := reflect.ValueOf(obj).Elem()
+	if value.Kind() == reflect.Struct {
+		if err := applyDefaults(value, ""); err != nil {
+			return err
+		}
+	}
+	if err := bindRequest(c, obj); err != nil {
+		return err
+	}
+	if value.Kind() == reflect.Struct {
+		headers, err := resetHeaderFields(value, "")
+		if err != nil {
+			return err
+		}
+		if len(headers) > 0 {
+			// 只传入声明了 header 标签的请求头，避免未声明标签的字段按字段名绑定到同名请求头
+			form := make(map[string][]string, len(headers))
+			for _, name := range headers {
+				if values := c.Request.Header.Values(name); len(values) > 0 {
+					form[name] = values
+				}
+			}
+			if err := binding.MapFormWithTag(obj, form, "header"); err != nil {
+				return err
+			}
+		}
+	}
+	return validate(obj)
+}
+
+// tagName 获取标签中的参数名称（逗号前的部分），"-" 表示忽略
+func tagName(field reflect.StructField, tag string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// isNestedStruct 字段是否为需要展开的嵌套结构体（含指针），time.Time 除外
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+// fieldLabels 生成字段路径到参数来源名称的映射，用于校验失败消息
+// 字段路径与 validator 的命名空间去除顶层结构体后一致，如 "TenantID"、"Filter.Status"
+func fieldLabels(t reflect.Type) map[string]string {
+	labels := make(map[string]string)
+	collectFieldLabels(t, "", labels)
+	return labels
+}
+
+// collectFieldLabels 递归收集字段的参数来源名称
+func collectFieldLabels(t reflect.Type, prefix string, labels map[string]string) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		path := prefix + field.Name
+		labeled := false
+		for _, source := range sourceTags {
+			if name := tagName(field, source.tag); name != "" {
+				labels[path] = source.label + " " + name
+				labeled = true
+				break
+			}
+		}
+		if !labeled && isNestedStruct(field.Type) {
+			collectFieldLabels(field.Type, path+".", labels)
+		}
+	}
+}
+
+// resetHeaderFields 将声明了 header 标签的字段重置为零值或 default 标签的默认值，递归处理嵌套结构体
+//
+// 返回：
+//   - []string: 声明了 header 标签的请求头名称
+//   - error: 默认值无效时返回错误
+func resetHeaderFields(value reflect.Value, prefix string) ([]string, error) {
+	var names []string
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := value.Field(i)
+		path := prefix + field.Name
+		if name := tagName(field, "header"); name != "" {
+			names = append(names, name)
+			fieldValue.Set(reflect.Zero(field.Type))
+			if def, ok := field.Tag.Lookup("default"); ok {
+				if err := setDefault(fieldValue, field, def); err != nil {
+					return nil, fmt.Errorf("字段 %s 的默认值 %q 无效: %w", path, def, err)
+				}
+			}
+			continue
+		}
+		if fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() {
+			fieldValue = fieldValue.Elem()
+		}
+		if fieldValue.Kind() == reflect.Struct && fieldValue.Type() != timeType {
+			nested, err := resetHeaderFields(fieldValue, path+".")
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, nested...)
+		}
+	}
+	return names, nil
+}
+
+// applyDefaults 为零值字段写入 default 标签的默认值，递归处理嵌套结构体
+func applyDefaults(value reflect.Value, prefix string) error {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := value.Field(i)
+		path := prefix + field.Name
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if !fieldValue.IsZero() {
+				continue
+			}
+			if err := setDefault(fieldValue, field, def); err != nil {
+				return fmt.Errorf("字段 %s 的默认值 %q 无效: %w", path, def, err)
+			}
+			continue
+		}
+		if fieldValue.Kind() == reflect.Struct && field.Type != timeType {
+			if err := applyDefaults(fieldValue, path+"."); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setDefault 将默认值解析为字段类型并写入
+// 指针字段分配新值；切片字段按逗号分隔；time.Time 按 time_format 标签解析，未配置时使用 RFC3339
+func setDefault(value reflect.Value, field reflect.StructField, def string) error {
+	switch value.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(value.Type().Elem())
+		if err := setDefault(elem.Elem(), field, def); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	case reflect.Slice:
+		parts := strings.Split(def, ",")
+		slice := reflect.MakeSlice(value.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setDefault(slice.Index(i), field, strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+		return nil
+	}
+
+	switch {
+	case value.Type() == timeType:
+		layout := field.Tag.Get("time_format")
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, def)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(t))
+		return nil
+	case value.Type() == durationType:
+		d, err := time.ParseDuration(def)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(def)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(def)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(def, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(def, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(def, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	default:
+		return fmt.Errorf("不支持的字段类型 %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Package ginContext 多来源请求绑定测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 BindAll 的单元测试。
+//
+// 测试覆盖内容：
+// 1. 路径参数、请求头、查询参数合并绑定
+// 2. 重复查询参数绑定到切片、time_format 解析时间、指针字段表示可选参数
+// 3. default 标签的默认值及被请求参数覆盖
+// 4. 校验失败的消息标明参数来源（path / header / query）
+// 5. 只声明 header 标签的字段不被同名查询参数覆盖
+//
+// 运行测试：go test -v ./utils/gin_context/... -run BindAll
+// ==================================================
+package ginContext_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// listOrderReq 混合路径参数、请求头和查询参数的测试请求
+type listOrderReq struct {
+	ShopID   string     `uri:"shopId" binding:"required,uuid4"`
+	TenantID string     `header:"X-Tenant-Id" binding:"required"`
+	Status   string     `form:"status" default:"a" binding:"oneof=a b c"`
+	Tags     []string   `form:"tag"`
+	Since    *time.Time `form:"since" time_format:"2006-01-02"`
+	Limit    *int       `form:"limit" binding:"omitempty,min=1"`
+	Page     listPage
+}
+
+// listPage 嵌套的分页参数
+type listPage struct {
+	PageSize int      `form:"pageSize" default:"20" binding:"max=100"`
+	Sort     []string `form:"sort" default:"id, createdAt"`
+}
+
+const testShopID = "9b2d0f4e-7c1a-4e8b-9f3d-2a6c5e8b1d70"
+
+// newBindAllRouter 创建注册了 BindAll 的测试路由
+func newBindAllRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/shops/:shopId/orders", func(c *gin.Context) {
+		req, ok := ginContext.BindAll[listOrderReq](c)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	})
+	return r
+}
+
+// doBindAllRequest 发送带请求头的 GET 请求
+func doBindAllRequest(r http.Handler, url string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// TestBindAll_Sources 测试多来源合并绑定
+//
+// 【功能点】验证一次调用同时绑定路径参数、请求头和查询参数，支持切片、时间和指针字段
+// 【测试流程】
+//  1. 携带全部参数，?tag=x&tag=y 绑定为切片，since 按 2006-01-02 解析，limit 绑定为指针
+//  2. 不携带可选参数，断言指针字段为 nil
+func TestBindAll_Sources(t *testing.T) {
+	r := newBindAllRouter()
+
+	w := doBindAllRequest(r, "/shops/"+testShopID+"/orders?status=b&tag=x&tag=y&since=2026-10-01&limit=5&pageSize=50",
+		map[string]string{"X-Tenant-Id": "t1"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got listOrderReq
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, testShopID, got.ShopID)
+	assert.Equal(t, "t1", got.TenantID)
+	assert.Equal(t, "b", got.Status)
+	assert.Equal(t, []string{"x", "y"}, got.Tags)
+	require.NotNil(t, got.Since)
+	assert.Equal(t, "2026-10-01", got.Since.Format("2006-01-02"))
+	require.NotNil(t, got.Limit)
+	assert.Equal(t, 5, *got.Limit)
+	assert.Equal(t, 50, got.Page.PageSize)
+
+	w = doBindAllRequest(r, "/shops/"+testShopID+"/orders", map[string]string{"X-Tenant-Id": "t1"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	got = listOrderReq{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Nil(t, got.Since)
+	assert.Nil(t, got.Limit)
+}
+
+// TestBindAll_Defaults 测试 default 标签
+//
+// 【功能点】验证未携带参数时使用默认值（含嵌套结构体和切片），携带时被覆盖
+// 【测试流程】
+//  1. 不携带 status、pageSize、sort，断言为默认值 a、20、[id createdAt]
+//  2. 携带 status=c&sort=name，断言覆盖默认值
+func TestBindAll_Defaults(t *testing.T) {
+	r := newBindAllRouter()
+	headers := map[string]string{"X-Tenant-Id": "t1"}
+
+	w := doBindAllRequest(r, "/shops/"+testShopID+"/orders", headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got listOrderReq
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "a", got.Status)
+	assert.Equal(t, 20, got.Page.PageSize)
+	assert.Equal(t, []string{"id", "createdAt"}, got.Page.Sort)
+
+	w = doBindAllRequest(r, "/shops/"+testShopID+"/orders?status=c&sort=name", headers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	got = listOrderReq{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "c", got.Status)
+	assert.Equal(t, []string{"name"}, got.Page.Sort)
+}
+
+// TestBindAll_ErrorSource 测试校验失败消息中的参数来源
+//
+// 【功能点】验证校验失败时返回参数校验响应码，消息按 path / header / query 标明字段来源
+// 【测试流程】
+//  1. 缺少 X-Tenant-Id 请求头，断言消息包含 "header X-Tenant-Id不能为空"
+//  2. shopId 不是 uuid4，断言消息包含 "path shopId"
+//  3. status 不在可选值中，断言消息包含 "query status的值必须是以下之一: a b c"
+//  4. 嵌套结构体的 pageSize 超过上限，断言消息包含 "query pageSize"
+func TestBindAll_ErrorSource(t *testing.T) {
+	r := newBindAllRouter()
+
+	cases := []struct {
+		name    string
+		url     string
+		headers map[string]string
+		want    string
+	}{
+		{"请求头", "/shops/" + testShopID + "/orders", nil, "header X-Tenant-Id不能为空"},
+		{"路径参数", "/shops/not-uuid/orders", map[string]string{"X-Tenant-Id": "t1"}, "path shopId"},
+		{"查询参数", "/shops/" + testShopID + "/orders?status=d", map[string]string{"X-Tenant-Id": "t1"}, "query status的值必须是以下之一: a b c"},
+		{"嵌套查询参数", "/shops/" + testShopID + "/orders?pageSize=500", map[string]string{"X-Tenant-Id": "t1"}, "query pageSize"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := doBindAllRequest(r, tc.url, tc.headers)
+			var resp map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, float64(response.ResponseParamInvalid.GetCode()), resp["code"])
+			assert.Contains(t, resp["msg"], tc.want)
+		})
+	}
+}
+
+// TestBindAll_HeaderNotOverriddenByQuery 测试请求头字段不被同名查询参数覆盖
+//
+// 【功能点】验证只声明 header 标签的字段不会按字段名从查询参数绑定
+// 【测试流程】
+//  1. 请求头 X-Tenant-Id 为 t1，查询参数 TenantID 为 t2，断言绑定结果为 t1
+//  2. 不携带请求头、只携带查询参数 TenantID，断言校验失败
+func TestBindAll_HeaderNotOverriddenByQuery(t *testing.T) {
+	r := newBindAllRouter()
+
+	w := doBindAllRequest(r, "/shops/"+testShopID+"/orders?TenantID=t2", map[string]string{"X-Tenant-Id": "t1"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var got listOrderReq
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "t1", got.TenantID)
+
+	w = doBindAllRequest(r, "/shops/"+testShopID+"/orders?TenantID=t2", nil)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(response.ResponseParamInvalid.GetCode()), resp["code"])
+	assert.Contains(t, resp["msg"], "header X-Tenant-Id不能为空")
+}
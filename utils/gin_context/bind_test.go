@@ -0,0 +1,206 @@
+// Package ginContext 请求绑定工具测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 BindAndValidate / MustBindAndValidate 的单元测试。
+//
+// 测试覆盖内容：
+// 1. JSON 请求体与路径参数合并绑定
+// 2. GET 请求的查询参数绑定
+// 3. Content-Type 与请求体不匹配
+// 4. 校验失败响应与 ExceptionHandler 的输出一致
+// 5. MustBindAndValidate 的 panic 路径
+//
+// 运行测试：go test -v ./utils/gin_context/...
+// ==================================================
+package ginContext_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/middleware"
+	"github.com/zzsen/gin_core/model/response"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// updateUserReq 路径参数 + JSON 请求体的测试请求
+type updateUserReq struct {
+	ID    int    `uri:"id" binding:"required"`
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email" binding:"omitempty,email"`
+	Trace string `form:"trace"`
+}
+
+// listUserReq GET 查询参数的测试请求
+type listUserReq struct {
+	PageIndex int    `form:"pageIndex" binding:"required,min=1"`
+	PageSize  int    `form:"pageSize,default=20"`
+	Keyword   string `form:"keyword"`
+}
+
+// newBindRouter 创建注册了 BindAndValidate 的测试路由
+func newBindRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/users/:id", func(c *gin.Context) {
+		req, ok := ginContext.BindAndValidate[updateUserReq](c)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	})
+	r.GET("/users", func(c *gin.Context) {
+		req, ok := ginContext.BindAndValidate[listUserReq](c)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, req)
+	})
+	return r
+}
+
+// doRequest 发送测试请求
+func doRequest(r http.Handler, method, url, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// TestBindAndValidate_JSONWithURIParam 测试 JSON 请求体与路径参数合并绑定
+//
+// 【功能点】验证一次调用同时绑定 uri、json 和 query 三种来源的字段
+// 【测试流程】发送 PUT /users/42?trace=abc，body 为 JSON，验证所有字段均被绑定
+func TestBindAndValidate_JSONWithURIParam(t *testing.T) {
+	r := newBindRouter()
+	w := doRequest(r, http.MethodPut, "/users/42?trace=abc", "application/json", `{"name":"alice","email":"a@b.com"}`)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var got updateUserReq
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, 42, got.ID)
+	assert.Equal(t, "alice", got.Name)
+	assert.Equal(t, "a@b.com", got.Email)
+	assert.Equal(t, "abc", got.Trace)
+}
+
+// TestBindAndValidate_GETQuery 测试 GET 请求的查询参数绑定
+//
+// 【功能点】验证 GET 请求从查询参数绑定，支持 default 默认值，且校验生效
+// 【测试流程】
+//  1. 合法查询参数，验证绑定结果和默认值
+//  2. 缺少必填参数，验证返回参数校验失败响应
+func TestBindAndValidate_GETQuery(t *testing.T) {
+	r := newBindRouter()
+
+	w := doRequest(r, http.MethodGet, "/users?pageIndex=2&keyword=go", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var got listUserReq
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, 2, got.PageIndex)
+	assert.Equal(t, 20, got.PageSize)
+	assert.Equal(t, "go", got.Keyword)
+
+	w = doRequest(r, http.MethodGet, "/users?keyword=go", "", "")
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(response.ResponseParamInvalid.GetCode()), resp["code"])
+	assert.Contains(t, resp["msg"], "PageIndex不能为空")
+}
+
+// TestBindAndValidate_ContentTypeMismatch 测试 Content-Type 与请求体不匹配
+//
+// 【功能点】验证不支持的 Content-Type 和无法解析的请求体均返回参数校验失败
+// 【测试流程】
+//  1. Content-Type 为 text/plain，验证返回不支持的 Content-Type
+//  2. Content-Type 为 application/json 但请求体不是合法 JSON，验证返回参数校验失败
+func TestBindAndValidate_ContentTypeMismatch(t *testing.T) {
+	r := newBindRouter()
+
+	w := doRequest(r, http.MethodPut, "/users/1", "text/plain", `{"name":"alice"}`)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(response.ResponseParamInvalid.GetCode()), resp["code"])
+	assert.Contains(t, resp["msg"], "不支持的Content-Type")
+
+	w = doRequest(r, http.MethodPut, "/users/1", "application/json", `name=alice`)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(response.ResponseParamInvalid.GetCode()), resp["code"])
+}
+
+// TestBindAndValidate_MatchesExceptionHandler 测试失败响应与 ExceptionHandler 一致
+//
+// 【功能点】验证 BindAndValidate 写入的响应与 panic 校验错误后由 ExceptionHandler 生成的响应完全相同
+// 【测试流程】
+//  1. 路由 A 使用 BindAndValidate
+//  2. 路由 B 使用 ShouldBindJSON + panic(err)，由 ExceptionHandler 处理
+//  3. 发送相同的非法请求，比较状态码和响应体
+func TestBindAndValidate_MatchesExceptionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ExceptionHandler())
+
+	type createReq struct {
+		Name string `json:"name" binding:"required"`
+		Age  int    `json:"age" binding:"min=18"`
+	}
+	r.POST("/helper", func(c *gin.Context) {
+		if _, ok := ginContext.BindAndValidate[createReq](c); !ok {
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	r.POST("/panic", func(c *gin.Context) {
+		var req createReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			panic(err)
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	body := `{"age":3}`
+	helper := doRequest(r, http.MethodPost, "/helper", "application/json", body)
+	panicked := doRequest(r, http.MethodPost, "/panic", "application/json", body)
+
+	assert.Equal(t, panicked.Code, helper.Code)
+	assert.JSONEq(t, panicked.Body.String(), helper.Body.String())
+}
+
+// TestMustBindAndValidate 测试 panic 路径
+//
+// 【功能点】验证 MustBindAndValidate 成功时返回结果，失败时 panic 并由 ExceptionHandler 转换为参数校验失败响应
+// 【测试流程】分别发送合法和非法请求，验证响应
+func TestMustBindAndValidate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ExceptionHandler())
+	r.PUT("/users/:id", func(c *gin.Context) {
+		req := ginContext.MustBindAndValidate[updateUserReq](c)
+		c.JSON(http.StatusOK, req)
+	})
+
+	w := doRequest(r, http.MethodPut, "/users/7", "application/json", `{"name":"bob"}`)
+	var got updateUserReq
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, 7, got.ID)
+	assert.Equal(t, "bob", got.Name)
+
+	req := httptest.NewRequest(http.MethodPut, "/users/7", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(response.ResponseParamInvalid.GetCode()), resp["code"])
+	assert.Contains(t, resp["msg"], "Name不能为空")
+}
@@ -0,0 +1,30 @@
+package ginContext
+
+import "github.com/gin-gonic/gin"
+
+// noCacheKey 不缓存标记在 gin.Context 中的存储键
+const noCacheKey = "_ginCore_noCache"
+
+// NoCache 标记当前请求的响应不写入缓存
+// 用于 CacheHandler 中间件匹配的路径中，响应包含当前请求特有的数据、不应共享的场景
+//
+// 参数：
+//   - c: Gin上下文
+//
+// 使用示例：
+//
+//	func GetArticle(c *gin.Context) {
+//	  article, draft := loadArticle(c.Param("id"))
+//	  if draft {
+//	    ginContext.NoCache(c)
+//	  }
+//	  response.OkWithData(c, article)
+//	}
+func NoCache(c *gin.Context) {
+	c.Set(noCacheKey, true)
+}
+
+// IsNoCache 判断当前请求是否通过 NoCache 标记为不缓存
+func IsNoCache(c *gin.Context) bool {
+	return c.GetBool(noCacheKey)
+}
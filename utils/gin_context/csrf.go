@@ -0,0 +1,43 @@
+package ginContext
+
+import "github.com/gin-gonic/gin"
+
+// csrfTokenKey CSRF 令牌获取函数在 gin.Context 中的存储键
+const csrfTokenKey = "_ginCore_csrfToken"
+
+// SetCSRFTokenSource 设置当前请求的 CSRF 令牌获取函数，由 CSRFHandler 中间件调用
+// 令牌在首次获取时才生成（启用会话时保存到会话中），因此存入的是获取函数而不是令牌本身
+//
+// 参数：
+//   - c: Gin上下文
+//   - source: 令牌获取函数
+func SetCSRFTokenSource(c *gin.Context, source func() string) {
+	c.Set(csrfTokenKey, source)
+}
+
+// CSRFToken 获取当前请求的 CSRF 令牌，用于渲染表单的隐藏字段或返回给前端放入请求头
+// 未启用会话时令牌保存在 Cookie 中（双重提交）；启用会话时令牌保存在会话中，会话 ID 重新生成（如登录）后返回新的令牌。
+// 未启用 CSRFHandler 中间件时返回空字符串
+//
+// 参数：
+//   - c: Gin上下文
+//
+// 返回值：
+//   - string: CSRF 令牌
+//
+// 使用示例：
+//
+//	func LoginPage(c *gin.Context) {
+//	  c.HTML(http.StatusOK, "login.html", gin.H{"csrfToken": ginContext.CSRFToken(c)})
+//	}
+func CSRFToken(c *gin.Context) string {
+	v, ok := c.Get(csrfTokenKey)
+	if !ok {
+		return ""
+	}
+	source, ok := v.(func() string)
+	if !ok {
+		return ""
+	}
+	return source()
+}
@@ -0,0 +1,27 @@
+package ginContext
+
+import "github.com/gin-gonic/gin"
+
+// skipEnvelopeKey 不检查统一响应结构标记在 gin.Context 中的存储键
+const skipEnvelopeKey = "_ginCore_skipEnvelope"
+
+// SkipEnvelope 标记当前请求的响应不检查统一响应结构
+// 用于开启 service.enforceEnvelope 时，路由前缀下按约定返回其他 JSON 结构的处理函数（如第三方回调、OpenAPI 文档）
+//
+// 参数：
+//   - c: Gin上下文
+//
+// 使用示例：
+//
+//	func PayNotify(c *gin.Context) {
+//	  ginContext.SkipEnvelope(c)
+//	  c.JSON(http.StatusOK, gin.H{"return_code": "SUCCESS"})
+//	}
+func SkipEnvelope(c *gin.Context) {
+	c.Set(skipEnvelopeKey, true)
+}
+
+// IsSkipEnvelope 判断当前请求是否通过 SkipEnvelope 标记为不检查统一响应结构
+func IsSkipEnvelope(c *gin.Context) bool {
+	return c.GetBool(skipEnvelopeKey)
+}
@@ -0,0 +1,30 @@
+package ginContext
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/featureflags"
+)
+
+// Feature 判断功能开关对当前请求的用户是否开启
+// 用户 ID 通过 GetUserID 获取，判断规则见 featureflags.IsEnabled
+//
+// 参数：
+//   - c: Gin上下文
+//   - name: 开关名称
+//
+// 返回值：
+//   - bool: 是否开启
+//
+// 使用示例：
+//
+//	func Checkout(c *gin.Context) {
+//	  if ginContext.Feature(c, "newCheckout") {
+//	    checkoutV2(c)
+//	    return
+//	  }
+//	  // ...
+//	}
+func Feature(c *gin.Context, name string) bool {
+	userID, _ := GetUserID(c)
+	return featureflags.IsEnabledFor(name, userID)
+}
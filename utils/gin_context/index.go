@@ -2,22 +2,52 @@ package ginContext
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
+	"strings"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/utils/jsoncodec"
 )
 
 const parsedBodyKey = "_ginCtx_parsedBody"
 
+// defaultMaxParseBodyBytes Get 解析请求体的默认大小上限（1MB）
+const defaultMaxParseBodyBytes = 1 << 20
+
+// maxParseBodyBytes Get 解析请求体的大小上限，由 SetMaxParseBodyBytes 设置
+var maxParseBodyBytes atomic.Int64
+
+func init() {
+	maxParseBodyBytes.Store(defaultMaxParseBodyBytes)
+}
+
+// SetMaxParseBodyBytes 设置 Get 解析 JSON 请求体的大小上限
+// 请求体超过上限时 Get 不读取请求体，只从查询参数、表单和路径参数中获取值；
+// 框架启动时按 service.maxParseBodyBytes 配置设置
+//
+// 参数：
+//   - n: 大小上限（字节），小于等于 0 时使用默认值 1MB
+func SetMaxParseBodyBytes(n int64) {
+	if n <= 0 {
+		n = defaultMaxParseBodyBytes
+	}
+	maxParseBodyBytes.Store(n)
+}
+
 // Get 从Gin上下文中获取指定键的值
 // 按照优先级顺序依次从以下位置获取：
 // 1. URL查询参数 (Query)
 // 2. POST表单数据 (PostForm)
-// 3. JSON请求体 (RawData)，解析结果会缓存到 context 中避免重复解析
+// 3. JSON请求体 (RawData)，每个请求最多解析一次，解析结果缓存到 context 中
 // 4. URL路径参数 (Param)
 //
+// 只解析 JSON 类型的请求体：Content-Type 为 application/json、text/json 或带 +json 后缀的类型（忽略 charset 等参数），
+// 未设置 Content-Type 时也会尝试解析；Content-Type 无法解析、请求体为空或超过 SetMaxParseBodyBytes 的上限时跳过请求体。
+// 读取后的请求体会重新写回 Request.Body，后续的绑定函数仍可读取。
+//
 // 参数:
 //   - ctx: Gin上下文对象
 //   - key: 要获取的键名
@@ -47,7 +77,7 @@ func Get(ctx *gin.Context, key string) (value string) {
 }
 
 // getParsedBody 获取并缓存 JSON 请求体的解析结果
-// 首次调用时读取 RawData 并解析 JSON，结果缓存到 gin.Context 中；
+// 首次调用时读取并解析请求体，结果（包括跳过解析时的空结果）缓存到 gin.Context 中；
 // 后续调用直接返回缓存，避免重复 IO 和反序列化开销。
 func getParsedBody(ctx *gin.Context) map[string]any {
 	if cached, exists := ctx.Get(parsedBodyKey); exists {
@@ -56,12 +86,57 @@ func getParsedBody(ctx *gin.Context) map[string]any {
 		}
 	}
 
-	b, _ := ctx.GetRawData()
-	ctx.Request.Body = io.NopCloser(bytes.NewBuffer(b))
-
 	m := map[string]any{}
-	json.Unmarshal(b, &m)
+	if b, ok := readJSONBody(ctx); ok {
+		_ = jsoncodec.Unmarshal(b, &m)
+	}
 
 	ctx.Set(parsedBodyKey, m)
 	return m
 }
+
+// readJSONBody 读取不超过大小上限的 JSON 请求体，并将其写回 Request.Body
+// 请求体不是 JSON、为空或超过上限时返回 false；长度未知且超过上限时，已读取的部分与剩余部分一起写回
+func readJSONBody(ctx *gin.Context) ([]byte, bool) {
+	req := ctx.Request
+	if req == nil || req.Body == nil || req.ContentLength == 0 || !isJSONContentType(req.Header.Get("Content-Type")) {
+		return nil, false
+	}
+	limit := maxParseBodyBytes.Load()
+	if req.ContentLength > limit {
+		return nil, false
+	}
+
+	body := req.Body
+	b, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if int64(len(b)) > limit {
+		req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(b), body), Closer: body}
+		return nil, false
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	return b, err == nil && len(b) > 0
+}
+
+// readCloser 组合 Reader 和原始请求体的 Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// isJSONContentType 判断 Content-Type 是否为 JSON 类型
+// 支持 application/json、text/json 和带 +json 后缀的类型（如 application/vnd.api+json），忽略 charset 等参数；
+// 未设置时返回 true，无法解析时返回 false
+func isJSONContentType(contentType string) bool {
+	if strings.TrimSpace(contentType) == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/json", "text/json":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+json")
+}
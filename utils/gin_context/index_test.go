@@ -12,6 +12,8 @@
 // 6. GetClientIP - 获取客户端IP（支持代理）
 // 7. GetHeader - 获取请求头
 // 8. SetHeader - 设置响应头
+// 9. Get 的 Content-Type 匹配（charset 参数、+json 后缀、无法解析的类型）和请求体大小上限
+// 10. 同一请求多次 Get 只读取一次请求体（含基准测试）
 //
 // 参数优先级：Query > Form > Body > Header
 //
@@ -23,8 +25,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -928,3 +932,178 @@ func TestGet_BodyParseCaching(t *testing.T) {
 		assert.Equal(t, "", response["val2"])
 	})
 }
+
+// TestGet_ContentType 测试 JSON 请求体的 Content-Type 匹配
+//
+// 【功能点】验证按媒体类型匹配 JSON 请求体，忽略 charset 等参数，支持 text/json 和 +json 后缀，其他或无法解析的类型跳过请求体
+// 【测试流程】
+//  1. 以不同的 Content-Type 发送 {"name":"john"}，调用 Get 获取 name
+//  2. 断言 JSON 类型和未设置 Content-Type 时返回 john，其他类型返回空字符串
+//  3. 断言跳过请求体时请求体仍可被读取
+func TestGet_ContentType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		contentType string
+		expected    string
+	}{
+		{"application/json", "john"},
+		{"application/json; charset=utf-8", "john"},
+		{"Application/JSON; charset=UTF-8", "john"},
+		{"text/json", "john"},
+		{"application/vnd.api+json", "john"},
+		{"application/problem+json; charset=utf-8", "john"},
+		{"", "john"},
+		{"text/plain", ""},
+		{"application/jsonp", ""},
+		{"application/json; charset", ""},
+		{"/json", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			r := gin.New()
+			r.POST("/test", func(c *gin.Context) {
+				value := Get(c, "name")
+				body, _ := c.GetRawData()
+				c.JSON(200, gin.H{"value": value, "body": string(body)})
+			})
+
+			req, _ := http.NewRequest("POST", "/test", strings.NewReader(`{"name":"john"}`))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			var response map[string]string
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expected, response["value"])
+			assert.Equal(t, `{"name":"john"}`, response["body"])
+		})
+	}
+}
+
+// TestGet_MaxParseBodyBytes 测试请求体大小上限
+//
+// 【功能点】验证请求体超过 SetMaxParseBodyBytes 的上限时不解析请求体，只从查询参数和路径参数获取值，请求体完整保留
+// 【测试流程】
+//  1. 设置上限为 32 字节，发送超过上限的 JSON 请求体（分别为已知长度和未知长度）
+//  2. 断言 Get 获取请求体中的键返回空字符串，查询参数和路径参数仍可获取
+//  3. 断言后续读取的请求体与原始请求体一致
+//  4. 发送不超过上限的请求体，断言正常解析
+func TestGet_MaxParseBodyBytes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetMaxParseBodyBytes(32)
+	t.Cleanup(func() { SetMaxParseBodyBytes(0) })
+
+	r := gin.New()
+	r.POST("/test/:id", func(c *gin.Context) {
+		name := Get(c, "name")
+		id := Get(c, "id")
+		page := Get(c, "page")
+		body, _ := c.GetRawData()
+		c.JSON(200, gin.H{"name": name, "id": id, "page": page, "body": string(body)})
+	})
+
+	large := `{"name":"john","padding":"` + strings.Repeat("x", 64) + `"}`
+	do := func(body io.Reader, contentLength int64) map[string]string {
+		req, _ := http.NewRequest("POST", "/test/42?page=3", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = contentLength
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var response map[string]string
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	for name, contentLength := range map[string]int64{"known length": int64(len(large)), "unknown length": -1} {
+		t.Run(name, func(t *testing.T) {
+			response := do(io.MultiReader(strings.NewReader(large)), contentLength)
+			assert.Equal(t, "", response["name"])
+			assert.Equal(t, "42", response["id"])
+			assert.Equal(t, "3", response["page"])
+			assert.Equal(t, large, response["body"])
+		})
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		small := `{"name":"john"}`
+		response := do(strings.NewReader(small), int64(len(small)))
+		assert.Equal(t, "john", response["name"])
+		assert.Equal(t, small, response["body"])
+	})
+}
+
+// countingReader 记录 Read 调用次数和读取字节数的请求体
+type countingReader struct {
+	reader *strings.Reader
+	reads  int
+	bytes  int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.reads++
+	r.bytes += n
+	return n, err
+}
+
+// newRepeatedGetContext 创建携带 JSON 请求体的 gin.Context，用于重复调用 Get
+func newRepeatedGetContext(body string) (*gin.Context, *countingReader) {
+	reader := &countingReader{reader: strings.NewReader(body)}
+	req := httptest.NewRequest(http.MethodPost, "/test", reader)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.ContentLength = int64(len(body))
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = req
+	return c, reader
+}
+
+// TestGet_BodyReadOnce 测试同一请求多次 Get 只读取一次请求体
+//
+// 【功能点】验证首次 Get 读取并解析请求体后，后续 Get（包括不存在的键）不再读取原始请求体
+// 【测试流程】
+//  1. 使用记录 Read 次数的请求体，调用一次 Get，记录读取次数
+//  2. 再调用 10 次 Get 获取存在和不存在的键，断言读取次数不变、返回值正确，原始请求体只读取了一遍
+func TestGet_BodyReadOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, reader := newRepeatedGetContext(`{"name":"john","age":25}`)
+
+	assert.Equal(t, "john", Get(c, "name"))
+	reads := reader.reads
+	assert.Greater(t, reads, 0)
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "25", Get(c, "age"))
+		assert.Equal(t, "", Get(c, "missing"))
+	}
+	assert.Equal(t, reads, reader.reads)
+	assert.Equal(t, len(`{"name":"john","age":25}`), reader.bytes)
+}
+
+// BenchmarkGet_RepeatedKeys 基准测试同一请求中重复调用 Get
+// 测试场景：每次迭代创建一个请求，连续获取 10 个请求体中的键；
+// bodyPasses/op 为每个请求读取原始请求体的遍数（读取字节数 / 请求体长度），应为 1，不随 Get 调用次数增长
+func BenchmarkGet_RepeatedKeys(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	fields := make(map[string]any, 10)
+	for i := 0; i < 10; i++ {
+		fields[fmt.Sprintf("key%d", i)] = strings.Repeat("v", 1024)
+	}
+	payload, _ := json.Marshal(fields)
+	body := string(payload)
+
+	totalBytes := 0
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c, reader := newRepeatedGetContext(body)
+		for k := 0; k < 10; k++ {
+			Get(c, fmt.Sprintf("key%d", k))
+		}
+		totalBytes += reader.bytes
+	}
+	b.ReportMetric(float64(totalBytes)/float64(len(body)*b.N), "bodyPasses/op")
+}
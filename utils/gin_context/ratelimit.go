@@ -0,0 +1,39 @@
+package ginContext
+
+import "github.com/gin-gonic/gin"
+
+// rateLimitCostKey 限流消耗在 gin.Context 中的存储键
+const rateLimitCostKey = "_ginCore_rateLimitCost"
+
+// SetRateLimitCost 设置当前请求消耗的限流配额，覆盖限流规则中的 cost
+// 在限流中间件之前（如认证中间件中）调用时，限流中间件按该值获取配额；
+// 在处理函数中调用时，限流中间件已按规则的 cost 预先获取配额，处理函数结束后按差值扣除或归还
+//
+// 参数：
+//   - c: Gin上下文
+//   - cost: 消耗的配额，小于 0 时按 0 处理
+//
+// 使用示例：
+//
+//	func Export(c *gin.Context) {
+//	  rows := countRows(c)
+//	  ginContext.SetRateLimitCost(c, 1+rows/10000)
+//	  // ...
+//	}
+func SetRateLimitCost(c *gin.Context, cost int) {
+	c.Set(rateLimitCostKey, max(cost, 0))
+}
+
+// GetRateLimitCost 获取通过 SetRateLimitCost 设置的限流消耗
+//
+// 返回：
+//   - int: 消耗的配额
+//   - bool: 是否设置过
+func GetRateLimitCost(c *gin.Context) (int, bool) {
+	v, ok := c.Get(rateLimitCostKey)
+	if !ok {
+		return 0, false
+	}
+	cost, ok := v.(int)
+	return cost, ok
+}
@@ -0,0 +1,39 @@
+package ginContext
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RawBody 获取原始请求体
+// 首次调用时读取请求体，缓存到 gin.Context（与 ShouldBindBodyWith 共用 gin.BodyBytesKey）并写回 Request.Body，
+// 之后的 RawBody、ShouldBindBodyWith、Get 和绑定函数都能读取到完整的请求体；
+// 用于校验签名等需要原始字节的场景
+//
+// 参数：
+//   - c: Gin上下文
+//
+// 返回：
+//   - []byte: 请求体，没有请求体时为 nil
+//   - error: 读取请求体失败时返回错误，如超过 http.MaxBytesReader 的大小限制
+func RawBody(c *gin.Context) ([]byte, error) {
+	if cached, ok := c.Get(gin.BodyBytesKey); ok {
+		if b, ok := cached.([]byte); ok {
+			return b, nil
+		}
+	}
+	req := c.Request
+	if req == nil || req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	c.Set(gin.BodyBytesKey, b)
+	return b, nil
+}
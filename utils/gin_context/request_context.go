@@ -0,0 +1,363 @@
+package ginContext
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestContextKey RequestContext 在 gin.Context 中的存储键
+const requestContextKey = "_ginCore_requestContext"
+
+// 旧版上下文键，框架中间件与业务代码曾直接通过 c.Set/c.Get 读写这些键。
+// 通过本文件的 Set 系列函数写入时会同步写入旧键，读取时在 RequestContext 未设置的情况下回退到旧键。
+const (
+	legacyTraceIDKey   = "traceId"
+	legacySpanIDKey    = "spanId"
+	legacyRequestIDKey = "requestId"
+	legacyUserIDKey    = "userID"
+	legacyUserIDKey2   = "user_id"
+	legacyClaimsKey    = "claims"
+	legacyLocaleKey    = "locale"
+)
+
+// stdContextKey RequestContext 在 context.Context 中的存储键类型
+type stdContextKey struct{}
+
+// RequestContext 请求级上下文数据
+// 将追踪 ID、用户 ID、认证信息等请求级数据集中保存在一个结构体中，
+// 以一个键存入 gin.Context，并可通过 context.Context 传递给 MQ 消费者、定时任务等非 HTTP 场景。
+//
+// 通过 c.Copy() 复制的上下文与原上下文共享同一个 RequestContext，
+// 因此可在 handler 派生的协程中读取；所有方法均为并发安全。
+type RequestContext struct {
+	mu        sync.RWMutex
+	traceID   string
+	spanID    string
+	requestID string
+	userID    string
+	claims    any
+	scopes    []string
+	locale    string
+	tenantID  string
+}
+
+// NewRequestContext 创建空的请求级上下文
+// 用于 MQ 消费者、定时任务等没有 gin.Context 的场景
+func NewRequestContext() *RequestContext {
+	return &RequestContext{}
+}
+
+// TraceID 获取追踪 ID
+func (rc *RequestContext) TraceID() string {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.traceID
+}
+
+// SetTraceID 设置追踪 ID
+func (rc *RequestContext) SetTraceID(traceID string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.traceID = traceID
+}
+
+// SpanID 获取 Span ID
+func (rc *RequestContext) SpanID() string {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.spanID
+}
+
+// SetSpanID 设置 Span ID
+func (rc *RequestContext) SetSpanID(spanID string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.spanID = spanID
+}
+
+// RequestID 获取请求 ID
+func (rc *RequestContext) RequestID() string {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.requestID
+}
+
+// SetRequestID 设置请求 ID
+func (rc *RequestContext) SetRequestID(requestID string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requestID = requestID
+}
+
+// UserID 获取用户 ID
+func (rc *RequestContext) UserID() string {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.userID
+}
+
+// SetUserID 设置用户 ID
+func (rc *RequestContext) SetUserID(userID string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.userID = userID
+}
+
+// Claims 获取认证信息（如 JWT claims）
+func (rc *RequestContext) Claims() any {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.claims
+}
+
+// SetClaims 设置认证信息
+func (rc *RequestContext) SetClaims(claims any) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.claims = claims
+}
+
+// Scopes 获取权限范围（如 API Key 授予的 scopes）
+func (rc *RequestContext) Scopes() []string {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return slices.Clone(rc.scopes)
+}
+
+// SetScopes 设置权限范围
+func (rc *RequestContext) SetScopes(scopes []string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.scopes = slices.Clone(scopes)
+}
+
+// HasScope 判断是否拥有指定的权限范围
+func (rc *RequestContext) HasScope(scope string) bool {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return slices.Contains(rc.scopes, scope)
+}
+
+// Locale 获取语言区域
+func (rc *RequestContext) Locale() string {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.locale
+}
+
+// SetLocale 设置语言区域
+func (rc *RequestContext) SetLocale(locale string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.locale = locale
+}
+
+// TenantID 获取租户 ID
+func (rc *RequestContext) TenantID() string {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.tenantID
+}
+
+// SetTenantID 设置租户 ID
+func (rc *RequestContext) SetTenantID(tenantID string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.tenantID = tenantID
+}
+
+// WithRequestContext 将 RequestContext 存入 context.Context
+func WithRequestContext(ctx context.Context, rc *RequestContext) context.Context {
+	return context.WithValue(ctx, stdContextKey{}, rc)
+}
+
+// FromStdContext 从 context.Context 中获取 RequestContext
+// 支持直接传入 *gin.Context，或由 c.Request.Context() 派生的上下文
+//
+// 返回值：
+//   - *RequestContext: 请求级上下文
+//   - bool: 是否存在
+func FromStdContext(ctx context.Context) (*RequestContext, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	if c, ok := ctx.(*gin.Context); ok {
+		return lookupRequestContext(c)
+	}
+	rc, ok := ctx.Value(stdContextKey{}).(*RequestContext)
+	return rc, ok && rc != nil
+}
+
+// GetRequestContext 获取当前请求的 RequestContext，不存在时创建
+// 新创建的 RequestContext 会同时存入 gin.Context 和 c.Request 的 context.Context，
+// 使通过 c.Request.Context() 传递给下游的上下文也能获取到相同的数据
+func GetRequestContext(c *gin.Context) *RequestContext {
+	if rc, ok := lookupRequestContext(c); ok {
+		return rc
+	}
+
+	rc := NewRequestContext()
+	c.Set(requestContextKey, rc)
+	if c.Request != nil {
+		c.Request = c.Request.WithContext(WithRequestContext(c.Request.Context(), rc))
+	}
+	return rc
+}
+
+// lookupRequestContext 查找已存在的 RequestContext，不会创建
+func lookupRequestContext(c *gin.Context) (*RequestContext, bool) {
+	value, exists := c.Get(requestContextKey)
+	if !exists {
+		return nil, false
+	}
+	rc, ok := value.(*RequestContext)
+	return rc, ok && rc != nil
+}
+
+// SetTraceID 设置追踪 ID，同时写入旧版键 "traceId"
+func SetTraceID(c *gin.Context, traceID string) {
+	GetRequestContext(c).SetTraceID(traceID)
+	c.Set(legacyTraceIDKey, traceID)
+}
+
+// GetTraceID 获取追踪 ID，RequestContext 未设置时回退到旧版键 "traceId"
+func GetTraceID(c *gin.Context) string {
+	if rc, ok := lookupRequestContext(c); ok {
+		if traceID := rc.TraceID(); traceID != "" {
+			return traceID
+		}
+	}
+	return c.GetString(legacyTraceIDKey)
+}
+
+// SetSpanID 设置 Span ID，同时写入旧版键 "spanId"
+func SetSpanID(c *gin.Context, spanID string) {
+	GetRequestContext(c).SetSpanID(spanID)
+	c.Set(legacySpanIDKey, spanID)
+}
+
+// GetSpanID 获取 Span ID，RequestContext 未设置时回退到旧版键 "spanId"
+func GetSpanID(c *gin.Context) string {
+	if rc, ok := lookupRequestContext(c); ok {
+		if spanID := rc.SpanID(); spanID != "" {
+			return spanID
+		}
+	}
+	return c.GetString(legacySpanIDKey)
+}
+
+// SetRequestID 设置请求 ID，同时写入旧版键 "requestId"
+func SetRequestID(c *gin.Context, requestID string) {
+	GetRequestContext(c).SetRequestID(requestID)
+	c.Set(legacyRequestIDKey, requestID)
+}
+
+// GetRequestID 获取请求 ID，RequestContext 未设置时回退到旧版键 "requestId"
+func GetRequestID(c *gin.Context) string {
+	if rc, ok := lookupRequestContext(c); ok {
+		if requestID := rc.RequestID(); requestID != "" {
+			return requestID
+		}
+	}
+	return c.GetString(legacyRequestIDKey)
+}
+
+// SetUserID 设置用户 ID，同时写入旧版键 "userID"
+// 认证中间件在解析出当前用户后应调用该函数，限流等中间件通过 GetUserID 读取
+func SetUserID(c *gin.Context, userID string) {
+	GetRequestContext(c).SetUserID(userID)
+	c.Set(legacyUserIDKey, userID)
+}
+
+// GetUserID 获取用户 ID
+// RequestContext 未设置时依次回退到旧版键 "userID"、"user_id"，非字符串类型的值会转换为字符串
+//
+// 返回值：
+//   - string: 用户 ID
+//   - bool: 是否存在
+func GetUserID(c *gin.Context) (string, bool) {
+	if rc, ok := lookupRequestContext(c); ok {
+		if userID := rc.UserID(); userID != "" {
+			return userID, true
+		}
+	}
+	for _, key := range []string{legacyUserIDKey, legacyUserIDKey2} {
+		if value, exists := c.Get(key); exists && value != nil {
+			if s, ok := value.(string); ok {
+				return s, true
+			}
+			return fmt.Sprint(value), true
+		}
+	}
+	return "", false
+}
+
+// SetClaims 设置认证信息，同时写入旧版键 "claims"
+func SetClaims(c *gin.Context, claims any) {
+	GetRequestContext(c).SetClaims(claims)
+	c.Set(legacyClaimsKey, claims)
+}
+
+// GetClaims 获取认证信息，RequestContext 未设置时回退到旧版键 "claims"
+func GetClaims(c *gin.Context) (any, bool) {
+	if rc, ok := lookupRequestContext(c); ok {
+		if claims := rc.Claims(); claims != nil {
+			return claims, true
+		}
+	}
+	return c.Get(legacyClaimsKey)
+}
+
+// SetScopes 设置权限范围
+// 认证中间件（如 apiKeyHandler）在认证通过后调用，middleware.RequireScope 通过 HasScope 读取
+func SetScopes(c *gin.Context, scopes []string) {
+	GetRequestContext(c).SetScopes(scopes)
+}
+
+// GetScopes 获取权限范围，未设置时返回 nil
+func GetScopes(c *gin.Context) []string {
+	if rc, ok := lookupRequestContext(c); ok {
+		return rc.Scopes()
+	}
+	return nil
+}
+
+// HasScope 判断当前请求是否拥有指定的权限范围
+func HasScope(c *gin.Context, scope string) bool {
+	rc, ok := lookupRequestContext(c)
+	return ok && rc.HasScope(scope)
+}
+
+// SetLocale 设置语言区域，同时写入旧版键 "locale"
+func SetLocale(c *gin.Context, locale string) {
+	GetRequestContext(c).SetLocale(locale)
+	c.Set(legacyLocaleKey, locale)
+}
+
+// GetLocale 获取语言区域，RequestContext 未设置时回退到旧版键 "locale"
+func GetLocale(c *gin.Context) string {
+	if rc, ok := lookupRequestContext(c); ok {
+		if locale := rc.Locale(); locale != "" {
+			return locale
+		}
+	}
+	return c.GetString(legacyLocaleKey)
+}
+
+// SetTenantID 设置租户 ID
+// 由 tenantHandler 中间件在识别出租户后调用，app.TenantDB 通过 GetTenantID 读取
+func SetTenantID(c *gin.Context, tenantID string) {
+	GetRequestContext(c).SetTenantID(tenantID)
+}
+
+// GetTenantID 获取租户 ID，未设置时返回空字符串
+func GetTenantID(c *gin.Context) string {
+	if rc, ok := lookupRequestContext(c); ok {
+		return rc.TenantID()
+	}
+	return ""
+}
@@ -0,0 +1,202 @@
+// Package ginContext 请求级上下文测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 RequestContext 的单元测试。
+//
+// 测试覆盖内容：
+// 1. Set 系列函数同步写入旧版键，旧版键写入的值可通过 Get 系列函数读取
+// 2. 并发请求之间不共享 RequestContext
+// 3. c.Copy() 后的上下文在协程中可读取同一 RequestContext
+// 4. FromStdContext 支持 c.Request.Context()、*gin.Context 以及手动构造的 context.Context
+//
+// 运行测试：go test -v ./utils/gin_context/... -run RequestContext
+// ==================================================
+package ginContext
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRequestContextTestContext 创建测试用 gin.Context
+func newRequestContextTestContext() *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	return c
+}
+
+// TestRequestContext_LegacyKeys 测试与旧版上下文键的兼容
+//
+// 【功能点】验证 Set 系列函数同时写入旧版键，且仅写入旧版键时 Get 系列函数可回退读取
+// 【测试流程】
+//  1. 通过 SetTraceID/SetUserID 等写入，检查 c.Get 旧版键的值
+//  2. 仅通过 c.Set 写入旧版键，检查 GetTraceID/GetUserID 等的返回值
+//  3. 非字符串类型的 user_id 转换为字符串
+func TestRequestContext_LegacyKeys(t *testing.T) {
+	t.Run("新接口写入旧键可读", func(t *testing.T) {
+		c := newRequestContextTestContext()
+		claims := map[string]any{"role": "admin"}
+		SetTraceID(c, "trace-1")
+		SetSpanID(c, "span-1")
+		SetRequestID(c, "req-1")
+		SetUserID(c, "alice")
+		SetClaims(c, claims)
+		SetLocale(c, "zh-CN")
+
+		assert.Equal(t, "trace-1", c.GetString("traceId"))
+		assert.Equal(t, "span-1", c.GetString("spanId"))
+		assert.Equal(t, "req-1", c.GetString("requestId"))
+		assert.Equal(t, "alice", c.GetString("userID"))
+		assert.Equal(t, "zh-CN", c.GetString("locale"))
+		v, _ := c.Get("claims")
+		assert.Equal(t, claims, v)
+
+		rc := GetRequestContext(c)
+		assert.Equal(t, "trace-1", rc.TraceID())
+		assert.Equal(t, "alice", rc.UserID())
+		assert.Equal(t, claims, rc.Claims())
+	})
+
+	t.Run("旧键写入新接口可读", func(t *testing.T) {
+		c := newRequestContextTestContext()
+		c.Set("traceId", "trace-2")
+		c.Set("spanId", "span-2")
+		c.Set("requestId", "req-2")
+		c.Set("userID", "bob")
+		c.Set("claims", "token-claims")
+		c.Set("locale", "en-US")
+
+		assert.Equal(t, "trace-2", GetTraceID(c))
+		assert.Equal(t, "span-2", GetSpanID(c))
+		assert.Equal(t, "req-2", GetRequestID(c))
+		userID, ok := GetUserID(c)
+		assert.True(t, ok)
+		assert.Equal(t, "bob", userID)
+		claims, ok := GetClaims(c)
+		assert.True(t, ok)
+		assert.Equal(t, "token-claims", claims)
+		assert.Equal(t, "en-US", GetLocale(c))
+	})
+
+	t.Run("user_id 非字符串值", func(t *testing.T) {
+		c := newRequestContextTestContext()
+		c.Set("user_id", int64(1001))
+		userID, ok := GetUserID(c)
+		assert.True(t, ok)
+		assert.Equal(t, "1001", userID)
+	})
+
+	t.Run("未设置", func(t *testing.T) {
+		c := newRequestContextTestContext()
+		_, ok := GetUserID(c)
+		assert.False(t, ok)
+		_, ok = GetClaims(c)
+		assert.False(t, ok)
+		assert.Empty(t, GetTraceID(c))
+		_, exists := c.Get(requestContextKey)
+		assert.False(t, exists, "只读访问不应创建 RequestContext")
+	})
+}
+
+// TestRequestContext_ConcurrentRequests 测试并发请求之间不共享状态
+//
+// 【功能点】验证每个请求拥有独立的 RequestContext
+// 【测试流程】100 个并发请求各自写入不同的用户 ID 和追踪 ID，处理函数读取后原样返回，断言响应与请求一一对应
+func TestRequestContext_ConcurrentRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		SetUserID(c, c.GetHeader("X-User"))
+		SetTraceID(c, "trace-"+c.GetHeader("X-User"))
+		c.Next()
+	})
+	router.GET("/", func(c *gin.Context) {
+		userID, _ := GetUserID(c)
+		c.String(http.StatusOK, userID+"|"+GetTraceID(c))
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user := fmt.Sprintf("user-%d", i)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-User", user)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, user+"|trace-"+user, w.Body.String())
+		}(i)
+	}
+	wg.Wait()
+}
+
+// TestRequestContext_SurvivesCopy 测试 c.Copy() 后在协程中访问
+//
+// 【功能点】验证 handler 派生协程通过 c.Copy() 得到的上下文可读取同一 RequestContext
+// 【测试流程】写入用户 ID 和追踪 ID 后复制上下文，在协程中通过副本、副本的 Request.Context() 读取
+func TestRequestContext_SurvivesCopy(t *testing.T) {
+	c := newRequestContextTestContext()
+	SetUserID(c, "carol")
+	SetTraceID(c, "trace-copy")
+
+	cp := c.Copy()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		userID, ok := GetUserID(cp)
+		assert.True(t, ok)
+		assert.Equal(t, "carol", userID)
+		assert.Equal(t, "trace-copy", GetTraceID(cp))
+
+		rc, ok := FromStdContext(cp.Request.Context())
+		if assert.True(t, ok) {
+			assert.Equal(t, "carol", rc.UserID())
+		}
+		assert.Same(t, GetRequestContext(c), GetRequestContext(cp))
+	}()
+	<-done
+}
+
+// TestRequestContext_FromStdContext 测试从 context.Context 获取 RequestContext
+//
+// 【功能点】验证 FromStdContext 在 HTTP 与非 HTTP 场景下的行为
+// 【测试流程】
+//  1. 传入 *gin.Context 和 c.Request.Context()，得到同一 RequestContext
+//  2. MQ 消费者等场景手动构造并传递
+//  3. 不存在时返回 false
+func TestRequestContext_FromStdContext(t *testing.T) {
+	c := newRequestContextTestContext()
+	SetRequestID(c, "req-std")
+	expected := GetRequestContext(c)
+
+	rc, ok := FromStdContext(c)
+	require.True(t, ok)
+	assert.Same(t, expected, rc)
+
+	rc, ok = FromStdContext(c.Request.Context())
+	require.True(t, ok)
+	assert.Same(t, expected, rc)
+	assert.Equal(t, "req-std", rc.RequestID())
+
+	consumerRC := NewRequestContext()
+	consumerRC.SetTraceID("trace-mq")
+	ctx := WithRequestContext(context.Background(), consumerRC)
+	rc, ok = FromStdContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "trace-mq", rc.TraceID())
+
+	_, ok = FromStdContext(context.Background())
+	assert.False(t, ok)
+	_, ok = FromStdContext(newRequestContextTestContext())
+	assert.False(t, ok)
+}
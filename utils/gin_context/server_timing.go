@@ -0,0 +1,280 @@
+package ginContext
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timingsKey Timings 在 gin.Context 中的存储键
+const timingsKey = "_ginCore_timings"
+
+// timingsContextKey Timings 在 context.Context 中的存储键类型
+type timingsContextKey struct{}
+
+// noopSpanEnd 未记录耗时时 StartSpan 返回的结束函数，不产生内存分配
+var noopSpanEnd = func() {}
+
+// Span 请求中一个阶段的耗时
+type Span struct {
+	// Name 阶段名称
+	Name string `json:"name"`
+	// Path 由外层阶段名称和当前阶段名称组成的路径，如 handler/db
+	Path string `json:"path"`
+	// Start 阶段开始时间相对请求开始时间的偏移
+	Start time.Duration `json:"start"`
+	// Duration 阶段耗时，尚未结束的阶段为到当前时间的耗时；中间件阶段不含其中记录的子阶段
+	Duration time.Duration `json:"duration"`
+	// Depth 嵌套层级，最外层为 0
+	Depth int `json:"depth"`
+}
+
+// timingSpan Timings 内部记录的阶段
+type timingSpan struct {
+	name      string
+	start     time.Duration
+	end       time.Duration
+	parent    int
+	exclusive bool
+	ended     bool
+}
+
+// Timings 请求级的阶段耗时记录
+// 由 serverTiming 相关中间件在请求开始时创建，阶段按开始顺序保存，开始时尚未结束的最近一个阶段为其外层阶段；
+// 所有方法均为并发安全，可在处理函数派生的协程中记录阶段
+type Timings struct {
+	mu    sync.Mutex
+	start time.Time
+	spans []timingSpan
+	open  []int
+}
+
+// NewTimings 创建以当前时间为请求开始时间的耗时记录
+func NewTimings() *Timings {
+	return &Timings{start: time.Now()}
+}
+
+// EnableTimings 为当前请求创建耗时记录，同时存入 gin.Context 和 c.Request 的 context.Context
+// 使绑定请求上下文的数据库查询等也能记录阶段；已存在时返回已有的记录
+func EnableTimings(c *gin.Context) *Timings {
+	if t, ok := GetTimings(c); ok {
+		return t
+	}
+	t := NewTimings()
+	c.Set(timingsKey, t)
+	if c.Request != nil {
+		c.Request = c.Request.WithContext(WithTimings(c.Request.Context(), t))
+	}
+	return t
+}
+
+// WithTimings 返回携带耗时记录的 context.Context，用于在 gin 请求以外（如任务、消息消费）记录阶段
+func WithTimings(ctx context.Context, t *Timings) context.Context {
+	return context.WithValue(ctx, timingsContextKey{}, t)
+}
+
+// GetTimings 获取当前请求的耗时记录，未开启 service.enableServerTiming 时不存在
+func GetTimings(c *gin.Context) (*Timings, bool) {
+	value, exists := c.Get(timingsKey)
+	if !exists {
+		return nil, false
+	}
+	t, ok := value.(*Timings)
+	return t, ok && t != nil
+}
+
+// TimingsFromContext 从 context.Context 中获取耗时记录，支持直接传入 *gin.Context
+func TimingsFromContext(ctx context.Context) (*Timings, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	if c, ok := ctx.(*gin.Context); ok {
+		return GetTimings(c)
+	}
+	t, ok := ctx.Value(timingsContextKey{}).(*Timings)
+	return t, ok && t != nil
+}
+
+// StartSpan 开始记录当前请求中的一个阶段，返回结束记录的函数
+// 未开启 service.enableServerTiming 时返回空函数，不产生内存分配；结束函数重复调用时只记录第一次
+//
+// 使用示例：
+//
+//	end := ginContext.StartSpan(c, "loadUser")
+//	user, err := loadUser(c, id)
+//	end()
+func StartSpan(c *gin.Context, name string) func() {
+	if t, ok := GetTimings(c); ok {
+		return t.Start(name)
+	}
+	return noopSpanEnd
+}
+
+// StartSpanFromContext 与 StartSpan 相同，用于只有 context.Context 的场景（如数据库回调、下游客户端）
+func StartSpanFromContext(ctx context.Context, name string) func() {
+	if t, ok := TimingsFromContext(ctx); ok {
+		return t.Start(name)
+	}
+	return noopSpanEnd
+}
+
+// StartMiddlewareSpan 开始记录中间件阶段，返回结束记录的函数
+// 与 StartSpan 不同，阶段耗时不含其中记录的子阶段（后续的中间件、处理函数），只统计中间件自身的耗时
+func StartMiddlewareSpan(c *gin.Context, name string) func() {
+	if t, ok := GetTimings(c); ok {
+		return t.startSpan(name, true)
+	}
+	return noopSpanEnd
+}
+
+// Start 开始记录一个阶段，返回结束记录的函数
+func (t *Timings) Start(name string) func() {
+	return t.startSpan(name, false)
+}
+
+// startSpan 开始记录一个阶段，exclusive 为 true 时阶段耗时不含子阶段
+func (t *Timings) startSpan(name string, exclusive bool) func() {
+	t.mu.Lock()
+	parent := -1
+	if n := len(t.open); n > 0 {
+		parent = t.open[n-1]
+	}
+	index := len(t.spans)
+	t.spans = append(t.spans, timingSpan{name: name, start: time.Since(t.start), parent: parent, exclusive: exclusive})
+	t.open = append(t.open, index)
+	t.mu.Unlock()
+	return func() { t.end(index) }
+}
+
+// end 结束记录下标为 index 的阶段
+func (t *Timings) end(index int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &t.spans[index]
+	if span.ended {
+		return
+	}
+	span.end = time.Since(t.start)
+	span.ended = true
+	for i := len(t.open) - 1; i >= 0; i-- {
+		if t.open[i] == index {
+			t.open = append(t.open[:i], t.open[i+1:]...)
+			break
+		}
+	}
+}
+
+// Elapsed 返回从请求开始到当前的耗时
+func (t *Timings) Elapsed() time.Duration {
+	return time.Since(t.start)
+}
+
+// Spans 返回按开始顺序排列的所有阶段，尚未结束的阶段按到当前时间计算耗时
+func (t *Timings) Spans() []Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Since(t.start)
+	totals := make([]time.Duration, len(t.spans))
+	children := make([]time.Duration, len(t.spans))
+	for i, span := range t.spans {
+		end := span.end
+		if !span.ended {
+			end = now
+		}
+		totals[i] = end - span.start
+		if span.parent >= 0 {
+			children[span.parent] += totals[i]
+		}
+	}
+
+	result := make([]Span, len(t.spans))
+	for i, span := range t.spans {
+		duration := totals[i]
+		if span.exclusive {
+			duration = max(duration-children[i], 0)
+		}
+		result[i] = Span{Name: span.name, Path: span.name, Start: span.start, Duration: duration}
+		// 外层阶段先于内层阶段开始，下标更小，已计算完成
+		if span.parent >= 0 {
+			result[i].Depth = result[span.parent].Depth + 1
+			result[i].Path = result[span.parent].Path + "/" + span.name
+		}
+	}
+	return result
+}
+
+// ServerTimingHeader 生成 Server-Timing 响应头的值
+// 同名阶段合并耗时（多次出现时在 desc 中注明次数），按耗时从长到短输出前 topN 个，最后输出请求至今的总耗时 total，耗时单位为毫秒
+//
+// 参数：
+//   - topN: 输出的阶段数量，小于等于 0 时输出全部
+//
+// 返回：
+//   - string: 如 db;dur=12.35;desc="x3", auth;dur=1.02, total;dur=15.80
+func (t *Timings) ServerTimingHeader(topN int) string {
+	type metric struct {
+		name     string
+		duration time.Duration
+		count    int
+	}
+	var metrics []*metric
+	byName := make(map[string]*metric)
+	for _, span := range t.Spans() {
+		m, ok := byName[span.Name]
+		if !ok {
+			m = &metric{name: serverTimingToken(span.Name)}
+			byName[span.Name] = m
+			metrics = append(metrics, m)
+		}
+		m.duration += span.Duration
+		m.count++
+	}
+	sort.SliceStable(metrics, func(i, j int) bool { return metrics[i].duration > metrics[j].duration })
+	if topN > 0 && len(metrics) > topN {
+		metrics = metrics[:topN]
+	}
+
+	var builder strings.Builder
+	for _, m := range metrics {
+		builder.WriteString(m.name)
+		builder.WriteString(";dur=")
+		builder.WriteString(formatTimingMillis(m.duration))
+		if m.count > 1 {
+			builder.WriteString(`;desc="x`)
+			builder.WriteString(strconv.Itoa(m.count))
+			builder.WriteString(`"`)
+		}
+		builder.WriteString(", ")
+	}
+	builder.WriteString("total;dur=")
+	builder.WriteString(formatTimingMillis(t.Elapsed()))
+	return builder.String()
+}
+
+// formatTimingMillis 将耗时格式化为保留两位小数的毫秒数
+func formatTimingMillis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 2, 64)
+}
+
+// serverTimingToken 将阶段名称中不能出现在 Server-Timing 指标名中的字符替换为下划线
+func serverTimingToken(name string) string {
+	if name == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", r):
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,163 @@
+// Package ginContext 请求耗时记录测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 Timings 阶段耗时记录的单元测试和基准测试。
+//
+// 测试覆盖内容：
+// 1. 阶段按开始顺序记录，嵌套阶段的层级、路径和耗时正确
+// 2. 中间件阶段的耗时不含其中记录的子阶段
+// 3. Server-Timing 响应头合并同名阶段、按耗时排序并截取前 topN 个，阶段名称中的非法字符被替换
+// 4. 未开启耗时记录时 StartSpan 不产生内存分配；StartSpanFromContext 可通过请求的 context.Context 记录
+//
+// 运行测试：go test -v ./utils/gin_context/... -run Timing
+// 基准测试：go test -bench StartSpan -benchmem ./utils/gin_context/...
+// ==================================================
+package ginContext
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTimingTestContext 创建测试用 gin.Context，enabled 为 true 时开启耗时记录
+func newTimingTestContext(enabled bool) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if enabled {
+		EnableTimings(c)
+	}
+	return c
+}
+
+// TestTimings_NestedSpans 测试嵌套阶段
+//
+// 【功能点】验证阶段按开始顺序返回，内层阶段的层级、路径正确，外层阶段耗时包含内层阶段
+// 【测试流程】
+//  1. 开始 handler 阶段，在其中依次记录 db、cache 两个阶段后结束 handler
+//  2. 断言阶段顺序、Depth、Path，以及各阶段耗时不小于 sleep 时长
+//  3. 重复调用结束函数，断言耗时不变
+func TestTimings_NestedSpans(t *testing.T) {
+	c := newTimingTestContext(true)
+	endHandler := StartSpan(c, "handler")
+	endDB := StartSpan(c, "db")
+	time.Sleep(5 * time.Millisecond)
+	endDB()
+	endCache := StartSpanFromContext(c.Request.Context(), "cache")
+	time.Sleep(2 * time.Millisecond)
+	endCache()
+	endHandler()
+
+	timings, ok := GetTimings(c)
+	require.True(t, ok)
+	spans := timings.Spans()
+	require.Len(t, spans, 3)
+	assert.Equal(t, []string{"handler", "handler/db", "handler/cache"}, []string{spans[0].Path, spans[1].Path, spans[2].Path})
+	assert.Equal(t, []int{0, 1, 1}, []int{spans[0].Depth, spans[1].Depth, spans[2].Depth})
+	assert.GreaterOrEqual(t, spans[1].Duration, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, spans[2].Duration, 2*time.Millisecond)
+	assert.GreaterOrEqual(t, spans[0].Duration, spans[1].Duration+spans[2].Duration)
+	assert.GreaterOrEqual(t, spans[2].Start, spans[1].Start+spans[1].Duration)
+
+	endDB()
+	assert.Equal(t, spans[1].Duration, timings.Spans()[1].Duration)
+}
+
+// TestTimings_MiddlewareSpan 测试中间件阶段
+//
+// 【功能点】验证中间件阶段的耗时只包含中间件自身，不含后续的处理函数阶段
+// 【测试流程】
+//  1. 开始 auth 中间件阶段，sleep 2ms 后在其中记录 sleep 20ms 的 handler 阶段，结束 auth
+//  2. 断言 auth 耗时不小于 2ms 且小于 handler 耗时，handler 的层级为 1
+func TestTimings_MiddlewareSpan(t *testing.T) {
+	c := newTimingTestContext(true)
+	endAuth := StartMiddlewareSpan(c, "auth")
+	time.Sleep(2 * time.Millisecond)
+	endHandler := StartSpan(c, "handler")
+	time.Sleep(20 * time.Millisecond)
+	endHandler()
+	endAuth()
+
+	timings, _ := GetTimings(c)
+	spans := timings.Spans()
+	require.Len(t, spans, 2)
+	assert.GreaterOrEqual(t, spans[0].Duration, 2*time.Millisecond)
+	assert.Less(t, spans[0].Duration, spans[1].Duration)
+	assert.Equal(t, "auth/handler", spans[1].Path)
+	assert.Equal(t, 1, spans[1].Depth)
+}
+
+// TestTimings_ServerTimingHeader 测试 Server-Timing 响应头
+//
+// 【功能点】验证同名阶段合并并注明次数，按耗时从长到短排序，只输出前 topN 个，最后输出 total
+// 【测试流程】
+//  1. 记录 3 次 db、1 次较长的 render、1 次较短的 "my cache"
+//  2. topN 为 2 时断言只包含合并后的 db 和 render，db 带 desc="x3"，以 total 结尾
+//  3. topN 为 0 时断言包含全部阶段，名称中的空格替换为下划线
+func TestTimings_ServerTimingHeader(t *testing.T) {
+	timings := NewTimings()
+	for i := 0; i < 3; i++ {
+		end := timings.Start("db")
+		time.Sleep(3 * time.Millisecond)
+		end()
+	}
+	end := timings.Start("render")
+	time.Sleep(5 * time.Millisecond)
+	end()
+	timings.Start("my cache")()
+
+	header := timings.ServerTimingHeader(2)
+	metrics := strings.Split(header, ", ")
+	require.Len(t, metrics, 3, header)
+	assert.True(t, strings.HasPrefix(metrics[0], "db;dur="), header)
+	assert.True(t, strings.HasSuffix(metrics[0], `;desc="x3"`), header)
+	assert.True(t, strings.HasPrefix(metrics[1], "render;dur="), header)
+	assert.True(t, strings.HasPrefix(metrics[2], "total;dur="), header)
+
+	all := timings.ServerTimingHeader(0)
+	assert.Len(t, strings.Split(all, ", "), 4, all)
+	assert.Contains(t, all, "my_cache;dur=")
+}
+
+// TestStartSpan_Disabled 测试未开启耗时记录
+//
+// 【功能点】验证未开启耗时记录时 StartSpan、StartMiddlewareSpan 返回空函数且不产生内存分配
+// 【测试流程】使用 testing.AllocsPerRun 统计开始并结束阶段的内存分配次数，断言为 0
+func TestStartSpan_Disabled(t *testing.T) {
+	c := newTimingTestContext(false)
+	allocs := testing.AllocsPerRun(1000, func() {
+		StartSpan(c, "db")()
+		StartMiddlewareSpan(c, "auth")()
+	})
+	assert.Zero(t, allocs)
+	_, ok := GetTimings(c)
+	assert.False(t, ok)
+}
+
+// BenchmarkStartSpan_Disabled 未开启耗时记录时开始并结束阶段的开销
+func BenchmarkStartSpan_Disabled(b *testing.B) {
+	c := newTimingTestContext(false)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		StartSpan(c, "db")()
+	}
+}
+
+// BenchmarkStartSpan_Enabled 开启耗时记录时开始并结束阶段的开销，每 1000 次重建记录避免阶段无限增长
+func BenchmarkStartSpan_Enabled(b *testing.B) {
+	c := newTimingTestContext(false)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if i%1000 == 0 {
+			c.Set(timingsKey, NewTimings())
+		}
+		StartSpan(c, "db")()
+	}
+}
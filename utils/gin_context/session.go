@@ -0,0 +1,34 @@
+package ginContext
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/session"
+)
+
+// GetSession 获取当前请求的会话对象
+// 会话由 SessionHandler 中间件加载，未启用该中间件时返回 nil
+//
+// 参数：
+//   - c: Gin上下文
+//
+// 返回值：
+//   - *session.Session: 会话对象
+//
+// 使用示例：
+//
+//	func Login(c *gin.Context) {
+//	  sess := ginContext.GetSession(c)
+//	  if err := sess.RegenerateID(); err != nil {
+//	    ...
+//	  }
+//	  sess.Set("userID", user.ID)
+//	  _ = sess.Save()
+//	}
+func GetSession(c *gin.Context) *session.Session {
+	value, exists := c.Get(session.ContextKey)
+	if !exists {
+		return nil
+	}
+	sess, _ := value.(*session.Session)
+	return sess
+}
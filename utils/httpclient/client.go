@@ -0,0 +1,360 @@
+// Package httpclient 提供调用下游服务的 HTTP 客户端
+// 支持单次请求超时、幂等请求的指数退避重试、追踪 ID 传递、熔断保护和每次请求的回调
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/tasks"
+	"github.com/zzsen/gin_core/tracing"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// maxErrorBodySize StatusError 中保留的响应体最大长度
+const maxErrorBodySize = 1024
+
+// errServerStatus 5xx 响应，仅用于熔断器记录失败
+var errServerStatus = errors.New("server error status")
+
+// idempotentMethods 默认重试的幂等方法
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// StatusError GetJSON、PostJSON 收到非 2xx 响应时返回的错误
+type StatusError struct {
+	// StatusCode 响应状态码
+	StatusCode int
+	// Body 响应体，最多保留 1KB
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// Client 调用下游服务的 HTTP 客户端，可并发使用
+type Client struct {
+	name       string
+	httpClient *http.Client
+	opts       options
+}
+
+// New 创建 HTTP 客户端
+// 参数：
+//   - name: 客户端名称，用于熔断器名称和日志，一般为下游服务名
+//   - opts: 客户端选项
+//
+// 返回：
+//   - *Client: HTTP 客户端
+//
+// 使用示例：
+//
+//	userClient := httpclient.New("user-service",
+//	    httpclient.WithTimeout(3*time.Second),
+//	    httpclient.WithCircuitBreaker(nil),
+//	    httpclient.WithAttemptHook(httpclient.LogAttempt),
+//	)
+//	var user User
+//	err := userClient.GetJSON(c, "http://user-service/api/users/1", &user)
+func New(name string, opts ...Option) *Client {
+	o := options{
+		timeout:             DefaultTimeout,
+		retry:               DefaultRetryPolicy(),
+		maxIdleConnsPerHost: DefaultMaxIdleConnsPerHost,
+		traceHeader:         "X-Trace-ID",
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	transport := o.transport
+	if transport == nil {
+		base := http.DefaultTransport.(*http.Transport).Clone()
+		base.MaxIdleConnsPerHost = o.maxIdleConnsPerHost
+		transport = base
+	}
+
+	return &Client{
+		name:       name,
+		httpClient: &http.Client{Transport: tracing.NewTracingTransport(transport)},
+		opts:       o,
+	}
+}
+
+// Name 返回客户端名称
+func (c *Client) Name() string {
+	return c.name
+}
+
+// CloseIdleConnections 关闭空闲连接
+func (c *Client) CloseIdleConnections() {
+	c.httpClient.CloseIdleConnections()
+}
+
+// Do 执行 HTTP 请求，按客户端选项进行超时控制、重试和熔断
+// 每次请求的超时时间单独计算，响应体关闭前超时仍然生效
+// 参数：
+//   - ctx: 上下文，可以是 *gin.Context，取消后停止请求和重试
+//   - req: HTTP 请求，请求体需要设置 GetBody 才会重试（http.NewRequest 会自动设置）
+//
+// 返回：
+//   - *http.Response: HTTP 响应，5xx 重试失败后返回最后一次的响应，调用方需关闭响应体
+//   - error: 请求错误，熔断器打开时返回 circuitbreaker.ErrCircuitOpen
+func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	ctx, traceID := resolveContext(ctx)
+	if c.opts.breakers == nil {
+		return c.doWithRetry(ctx, req, traceID)
+	}
+
+	var resp *http.Response
+	var doErr error
+	err := c.opts.breakers.Get(c.breakerName(req)).Execute(ctx, func() error {
+		resp, doErr = c.doWithRetry(ctx, req, traceID)
+		switch {
+		case doErr != nil:
+			// 调用方取消不记为下游服务的失败
+			if ctx.Err() != nil {
+				return nil
+			}
+			return doErr
+		case resp.StatusCode >= http.StatusInternalServerError:
+			return errServerStatus
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errServerStatus) && doErr == nil {
+		return nil, err
+	}
+	return resp, doErr
+}
+
+// GetJSON 发送 GET 请求，将 JSON 响应解析到 out
+// 参数：
+//   - ctx: 上下文，可以是 *gin.Context
+//   - url: 请求地址
+//   - out: 响应解析的目标，为 nil 时忽略响应体
+//
+// 返回：
+//   - error: 请求错误，非 2xx 响应返回 *StatusError
+func (c *Client) GetJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	return c.doJSON(ctx, req, out)
+}
+
+// PostJSON 发送 JSON 请求体的 POST 请求，将 JSON 响应解析到 out
+// POST 默认不重试，见 RetryPolicy.RetryNonIdempotent
+// 参数：
+//   - ctx: 上下文，可以是 *gin.Context
+//   - url: 请求地址
+//   - in: 请求体，序列化为 JSON
+//   - out: 响应解析的目标，为 nil 时忽略响应体
+//
+// 返回：
+//   - error: 请求错误，非 2xx 响应返回 *StatusError
+func (c *Client) PostJSON(ctx context.Context, url string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	return c.doJSON(ctx, req, out)
+}
+
+// doJSON 执行请求并解析 JSON 响应
+func (c *Client) doJSON(ctx context.Context, req *http.Request, out any) error {
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// doWithRetry 按重试策略执行请求
+func (c *Client) doWithRetry(ctx context.Context, req *http.Request, traceID string) (*http.Response, error) {
+	policy := c.opts.retry
+	retryable := policy.MaxRetries > 0 &&
+		(idempotentMethods[req.Method] || policy.RetryNonIdempotent) &&
+		(req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	for attempt := 1; ; attempt++ {
+		attemptReq, cancel, err := c.newAttemptRequest(ctx, req, attempt, traceID)
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := c.httpClient.Do(attemptReq)
+		willRetry := retryable && attempt <= policy.MaxRetries && ctx.Err() == nil && policy.shouldRetry(resp, err)
+		c.runHooks(Attempt{
+			Client:     c.name,
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			Attempt:    attempt,
+			StatusCode: statusCode(resp),
+			Err:        err,
+			Latency:    time.Since(start),
+			WillRetry:  willRetry,
+		})
+
+		if !willRetry {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+			resp.Body.Close()
+		}
+		cancel()
+
+		timer := time.NewTimer(policy.backoff(attempt - 1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// newAttemptRequest 创建单次请求，设置超时和追踪 ID，重试时重新获取请求体
+func (c *Client) newAttemptRequest(ctx context.Context, req *http.Request, attempt int, traceID string) (*http.Request, context.CancelFunc, error) {
+	cancel := context.CancelFunc(func() {})
+	if c.opts.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.opts.timeout)
+	}
+
+	attemptReq := req.Clone(ctx)
+	if attempt > 1 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		attemptReq.Body = body
+	}
+	if c.opts.traceHeader != "" && traceID != "" && attemptReq.Header.Get(c.opts.traceHeader) == "" {
+		attemptReq.Header.Set(c.opts.traceHeader, traceID)
+	}
+	return attemptReq, cancel, nil
+}
+
+// breakerName 熔断器名称
+func (c *Client) breakerName(req *http.Request) string {
+	if c.opts.breakerPerHost {
+		return c.name + ":" + req.URL.Host
+	}
+	return c.name
+}
+
+// runHooks 执行每次请求的回调
+func (c *Client) runHooks(a Attempt) {
+	for _, hook := range c.opts.hooks {
+		hook(a)
+	}
+}
+
+// shouldRetry 判断本次结果是否需要重试
+func (p RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(resp, err)
+	}
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// resolveContext 获取请求使用的上下文和追踪 ID
+// *gin.Context 使用 c.Request.Context()，使请求随客户端断开而取消
+func resolveContext(ctx context.Context) (context.Context, string) {
+	if c, ok := ctx.(*gin.Context); ok {
+		traceID := ginContext.GetTraceID(c)
+		if c.Request != nil {
+			return c.Request.Context(), traceID
+		}
+		return context.Background(), traceID
+	}
+	if rc, ok := ginContext.FromStdContext(ctx); ok {
+		if traceID := rc.TraceID(); traceID != "" {
+			return ctx, traceID
+		}
+	}
+	return ctx, tasks.TraceID(ctx)
+}
+
+// statusCode 响应状态码，响应为 nil 时返回 0
+func statusCode(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+// cancelOnClose 关闭响应体时取消单次请求的上下文
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// LogAttempt 记录每次请求的日志，可作为 WithAttemptHook 的参数
+// 请求失败或 5xx 响应记录为警告，其他记录为调试日志
+func LogAttempt(a Attempt) {
+	fields := map[string]any{
+		"client":  a.Client,
+		"method":  a.Method,
+		"url":     a.URL,
+		"attempt": a.Attempt,
+		"status":  a.StatusCode,
+		"latency": a.Latency.String(),
+		"retry":   a.WillRetry,
+	}
+	switch {
+	case a.Err != nil:
+		logger.WarnWithFields(fields, "[HTTP客户端] 请求失败: %v", a.Err)
+	case a.StatusCode >= http.StatusInternalServerError:
+		logger.WarnWithFields(fields, "[HTTP客户端] 服务端错误: %d", a.StatusCode)
+	default:
+		logger.DebugWithFields(fields, "[HTTP客户端] 请求完成")
+	}
+}
@@ -0,0 +1,242 @@
+// Package httpclient HTTP 客户端测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 HTTP 客户端的单元测试，使用 httptest 启动下游服务。
+//
+// 测试覆盖内容：
+// 1. GET 请求收到 503 后重试并成功，每次请求触发回调
+// 2. POST 请求默认不重试，开启 RetryNonIdempotent 后重试并重新发送请求体
+// 3. 连续失败后熔断器打开，之后的请求直接返回 ErrCircuitOpen 而不访问下游服务
+// 4. 从 *gin.Context 和 RequestContext 中获取追踪 ID 并设置到请求头
+// 5. 单次请求超时后重试，调用方取消后停止重试
+//
+// 运行测试：go test -v ./utils/httpclient/...
+// ==================================================
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/circuitbreaker"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// fastRetry 测试使用的重试策略，缩短等待时间
+var fastRetry = RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+// TestClient_RetryThenSuccess 测试 5xx 重试
+//
+// 【功能点】验证 GET 请求收到 503 后按重试策略重试，成功后返回响应，每次请求都触发回调
+// 【测试流程】
+//  1. 下游服务前两次返回 503，第三次返回 JSON
+//  2. 调用 GetJSON，断言解析结果正确，服务端收到 3 次请求
+//  3. 断言回调记录了 3 次请求，前两次 WillRetry 为 true
+func TestClient_RetryThenSuccess(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"ok"}`))
+	}))
+	defer server.Close()
+
+	var attempts []Attempt
+	client := New("retry", WithRetryPolicy(fastRetry), WithAttemptHook(func(a Attempt) {
+		attempts = append(attempts, a)
+	}))
+
+	var out struct {
+		Name string `json:"name"`
+	}
+	require.NoError(t, client.GetJSON(context.Background(), server.URL, &out))
+	assert.Equal(t, "ok", out.Name)
+	assert.Equal(t, int32(3), calls.Load())
+
+	require.Len(t, attempts, 3)
+	for i, a := range attempts {
+		assert.Equal(t, "retry", a.Client)
+		assert.Equal(t, i+1, a.Attempt)
+		assert.Equal(t, i < 2, a.WillRetry)
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, attempts[0].StatusCode)
+	assert.Equal(t, http.StatusOK, attempts[2].StatusCode)
+}
+
+// TestClient_RetryExhausted 测试重试次数用尽
+//
+// 【功能点】验证重试次数用尽后返回最后一次的响应，GetJSON 返回 *StatusError
+// 【测试流程】下游服务始终返回 502，断言请求 3 次，错误为状态码 502 的 StatusError
+func TestClient_RetryExhausted(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("bad gateway"))
+	}))
+	defer server.Close()
+
+	err := New("exhausted", WithRetryPolicy(fastRetry)).GetJSON(context.Background(), server.URL, nil)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusBadGateway, statusErr.StatusCode)
+	assert.Equal(t, "bad gateway", statusErr.Body)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+// TestClient_NoRetryOnPost 测试 POST 请求的重试
+//
+// 【功能点】验证 POST 请求默认不重试，开启 RetryNonIdempotent 后重试并重新发送请求体
+// 【测试流程】
+//  1. 下游服务始终返回 503，默认策略调用 PostJSON，断言只请求 1 次
+//  2. 开启 RetryNonIdempotent，断言请求 3 次，每次收到的请求体相同
+func TestClient_NoRetryOnPost(t *testing.T) {
+	var calls atomic.Int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := New("post", WithRetryPolicy(fastRetry)).PostJSON(context.Background(), server.URL, map[string]int{"id": 1}, nil)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, int32(1), calls.Load())
+
+	calls.Store(0)
+	bodies = nil
+	policy := fastRetry
+	policy.RetryNonIdempotent = true
+	err = New("post", WithRetryPolicy(policy)).PostJSON(context.Background(), server.URL, map[string]int{"id": 1}, nil)
+	require.Error(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, []string{`{"id":1}`, `{"id":1}`, `{"id":1}`}, bodies)
+}
+
+// TestClient_CircuitBreaker 测试熔断
+//
+// 【功能点】验证连续失败后熔断器打开，之后的请求直接返回 ErrCircuitOpen 而不访问下游服务
+// 【测试流程】
+//  1. 使用连续失败 2 次即熔断的注册中心，下游服务始终返回 500，不重试
+//  2. 请求 2 次，断言返回 StatusError，熔断器打开
+//  3. 再次请求，断言返回 ErrCircuitOpen，服务端请求次数不变
+//  4. 开启 WithBreakerPerHost，断言熔断器名称包含主机
+func TestClient_CircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	registry := circuitbreaker.NewRegistry(func(name string) *circuitbreaker.Config {
+		return circuitbreaker.NewConfig(name,
+			circuitbreaker.WithFailureThreshold(2),
+			circuitbreaker.WithTimeout(time.Minute),
+		)
+	})
+	client := New("breaker", WithRetryPolicy(RetryPolicy{}), WithCircuitBreaker(registry))
+
+	for i := 0; i < 2; i++ {
+		var statusErr *StatusError
+		require.ErrorAs(t, client.GetJSON(context.Background(), server.URL, nil), &statusErr)
+	}
+	assert.Equal(t, circuitbreaker.StateOpen, registry.Get("breaker").State())
+
+	err := client.GetJSON(context.Background(), server.URL, nil)
+	assert.ErrorIs(t, err, circuitbreaker.ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+
+	perHost := New("breaker", WithRetryPolicy(RetryPolicy{}), WithCircuitBreaker(registry), WithBreakerPerHost())
+	var statusErr *StatusError
+	require.ErrorAs(t, perHost.GetJSON(context.Background(), server.URL, nil), &statusErr)
+	assert.Contains(t, registry.List(), "breaker:"+server.Listener.Addr().String())
+}
+
+// TestClient_TraceHeader 测试追踪 ID 传递
+//
+// 【功能点】验证从 *gin.Context 和 RequestContext 中获取追踪 ID 并设置到 X-Trace-ID 请求头
+// 【测试流程】
+//  1. 以设置了追踪 ID 的 *gin.Context 调用，断言下游服务收到相同的追踪 ID
+//  2. 以包含 RequestContext 的 context.Context 调用，断言下游服务收到相同的追踪 ID
+//  3. 以空上下文调用，断言没有设置请求头
+func TestClient_TraceHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("X-Trace-ID")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client := New("trace")
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	ginContext.SetTraceID(c, "trace-from-gin")
+	require.NoError(t, client.GetJSON(c, server.URL, nil))
+	assert.Equal(t, "trace-from-gin", received)
+
+	rc := ginContext.NewRequestContext()
+	rc.SetTraceID("trace-from-std")
+	ctx := ginContext.WithRequestContext(context.Background(), rc)
+	require.NoError(t, client.GetJSON(ctx, server.URL, nil))
+	assert.Equal(t, "trace-from-std", received)
+
+	require.NoError(t, client.GetJSON(context.Background(), server.URL, nil))
+	assert.Empty(t, received)
+}
+
+// TestClient_Timeout 测试超时和取消
+//
+// 【功能点】验证单次请求超时后重试，调用方取消后停止重试并返回上下文错误
+// 【测试流程】
+//  1. 下游服务第一次请求等待 200ms，之后立即返回，单次超时 50ms，断言重试后成功
+//  2. 下游服务始终等待，调用方 80ms 后取消，断言返回 context.DeadlineExceeded 且不再重试
+func TestClient_Timeout(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(200 * time.Millisecond):
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := New("timeout", WithTimeout(50*time.Millisecond), WithRetryPolicy(fastRetry))
+	require.NoError(t, client.GetJSON(context.Background(), server.URL, nil))
+	assert.Equal(t, int32(2), calls.Load())
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+
+	var attempts atomic.Int32
+	client = New("timeout", WithTimeout(time.Second), WithRetryPolicy(fastRetry), WithAttemptHook(func(a Attempt) {
+		attempts.Add(1)
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 80*time.Millisecond)
+	defer cancel()
+	err := client.GetJSON(ctx, slow.URL, nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "err = %v", err)
+	assert.Equal(t, int32(1), attempts.Load())
+}
@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/zzsen/gin_core/circuitbreaker"
+)
+
+const (
+	// DefaultTimeout 默认的单次请求超时时间
+	DefaultTimeout = 10 * time.Second
+	// DefaultMaxIdleConnsPerHost 默认的每个主机最大空闲连接数（http.DefaultTransport 为 2）
+	DefaultMaxIdleConnsPerHost = 32
+)
+
+// RetryPolicy 重试策略
+type RetryPolicy struct {
+	// MaxRetries 最大重试次数，不含首次请求，<= 0 时不重试
+	MaxRetries int
+	// BaseDelay 首次重试前的等待时间，之后每次翻倍
+	BaseDelay time.Duration
+	// MaxDelay 重试等待时间上限
+	MaxDelay time.Duration
+	// RetryNonIdempotent 是否重试非幂等方法（POST、PATCH），默认只重试 GET、HEAD、OPTIONS、TRACE、PUT、DELETE
+	RetryNonIdempotent bool
+	// ShouldRetry 判断本次结果是否需要重试，为 nil 时连接错误、超时和 5xx 响应重试
+	ShouldRetry func(resp *http.Response, err error) bool
+}
+
+// DefaultRetryPolicy 返回默认的重试策略：最多重试 2 次，等待 100ms、200ms，上限 2s
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 2,
+		BaseDelay:  100 * time.Millisecond,
+		MaxDelay:   2 * time.Second,
+	}
+}
+
+// backoff 第 retry 次重试（从 0 开始）前的等待时间
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Attempt 单次请求的结果，用于 WithAttemptHook
+type Attempt struct {
+	// Client 客户端名称
+	Client string
+	// Method 请求方法
+	Method string
+	// URL 请求地址
+	URL string
+	// Attempt 第几次请求，从 1 开始
+	Attempt int
+	// StatusCode 响应状态码，请求失败时为 0
+	StatusCode int
+	// Err 请求错误
+	Err error
+	// Latency 耗时
+	Latency time.Duration
+	// WillRetry 是否会重试
+	WillRetry bool
+}
+
+// options 客户端选项
+type options struct {
+	timeout             time.Duration
+	retry               RetryPolicy
+	breakers            *circuitbreaker.Registry
+	breakerPerHost      bool
+	hooks               []func(Attempt)
+	maxIdleConnsPerHost int
+	transport           http.RoundTripper
+	traceHeader         string
+}
+
+// Option 客户端选项
+type Option func(*options)
+
+// WithTimeout 设置单次请求的超时时间，每次重试单独计时，默认 DefaultTimeout，<= 0 时不限制
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithRetryPolicy 设置重试策略，默认 DefaultRetryPolicy()
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = policy
+	}
+}
+
+// WithCircuitBreaker 启用熔断，熔断器从 registry 中按客户端名称获取，registry 为 nil 时使用 circuitbreaker.GetRegistry()
+// 连接错误、超时和 5xx 响应记为失败，熔断器打开时直接返回 circuitbreaker.ErrCircuitOpen
+func WithCircuitBreaker(registry *circuitbreaker.Registry) Option {
+	return func(o *options) {
+		if registry == nil {
+			registry = circuitbreaker.GetRegistry()
+		}
+		o.breakers = registry
+	}
+}
+
+// WithBreakerPerHost 熔断器按 "客户端名称:主机" 区分，一个客户端访问多个主机时使用，需同时启用 WithCircuitBreaker
+func WithBreakerPerHost() Option {
+	return func(o *options) {
+		o.breakerPerHost = true
+	}
+}
+
+// WithAttemptHook 添加每次请求（包括重试）完成后的回调，可用于记录日志和指标，见 LogAttempt
+func WithAttemptHook(hook func(Attempt)) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, hook)
+	}
+}
+
+// WithMaxIdleConnsPerHost 设置每个主机的最大空闲连接数，默认 DefaultMaxIdleConnsPerHost
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(o *options) {
+		o.maxIdleConnsPerHost = n
+	}
+}
+
+// WithTransport 使用自定义的 http.RoundTripper，设置后忽略 WithMaxIdleConnsPerHost
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = rt
+	}
+}
+
+// WithTraceHeader 设置传递追踪 ID 的请求头，默认 X-Trace-ID，为空时不传递
+func WithTraceHeader(header string) Option {
+	return func(o *options) {
+		o.traceHeader = header
+	}
+}
@@ -0,0 +1,127 @@
+// Package jsoncodec 提供框架统一使用的 JSON 编解码器
+//
+// 框架自身的 JSON 序列化路径（流式响应、请求体解析、审计日志、结构体与 map 转换等）都通过本包编解码，
+// 默认使用标准库 encoding/json。应用可通过 core.SetJSONCodec 替换为 sonic、jsoniter 等实现，
+// 也可以使用与 gin 相同的构建标签（-tags sonic / -tags jsoniter）切换默认实现。
+package jsoncodec
+
+import (
+	"encoding/json"
+	"io"
+	"sync/atomic"
+)
+
+// Codec JSON 编解码器，方法语义与 encoding/json 的同名函数一致
+type Codec interface {
+	// Marshal 序列化
+	Marshal(v any) ([]byte, error)
+	// Unmarshal 反序列化
+	Unmarshal(data []byte, v any) error
+	// MarshalIndent 带缩进的序列化
+	MarshalIndent(v any, prefix, indent string) ([]byte, error)
+	// NewEncoder 创建写入 w 的流式编码器
+	NewEncoder(w io.Writer) Encoder
+	// NewDecoder 创建读取 r 的流式解码器
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Encoder 流式编码器，方法语义与 *json.Encoder 一致
+type Encoder interface {
+	// Encode 写入 v 的 JSON 编码，并追加换行符
+	Encode(v any) error
+	// SetEscapeHTML 设置是否转义字符串中的 &、<、>
+	SetEscapeHTML(on bool)
+	// SetIndent 设置缩进
+	SetIndent(prefix, indent string)
+}
+
+// Decoder 流式解码器，方法语义与 *json.Decoder 一致
+type Decoder interface {
+	// Decode 读取下一个 JSON 值并解析到 v
+	Decode(v any) error
+	// UseNumber 将数字解析为 json.Number 而不是 float64
+	UseNumber()
+	// DisallowUnknownFields 目标为结构体时，JSON 中存在结构体没有的字段返回错误
+	DisallowUnknownFields()
+	// More 当前数组或对象中是否还有元素
+	More() bool
+}
+
+// holder 包装当前使用的编解码器，使 atomic.Pointer 可以存储接口值
+type holder struct {
+	codec Codec
+}
+
+// current 当前使用的编解码器，为空时使用标准库
+var current atomic.Pointer[holder]
+
+// Standard 返回基于标准库 encoding/json 的编解码器
+func Standard() Codec {
+	return stdCodec{}
+}
+
+// Set 设置框架使用的编解码器，c 为 nil 时恢复为标准库
+// 应用应通过 core.SetJSONCodec 设置，该方法同时切换 gin 的渲染和参数绑定，并拒绝在 Start 之后调用
+func Set(c Codec) {
+	if c == nil {
+		current.Store(nil)
+		return
+	}
+	current.Store(&holder{codec: c})
+}
+
+// Get 获取当前使用的编解码器
+func Get() Codec {
+	if h := current.Load(); h != nil {
+		return h.codec
+	}
+	return stdCodec{}
+}
+
+// Marshal 使用当前的编解码器序列化
+func Marshal(v any) ([]byte, error) {
+	return Get().Marshal(v)
+}
+
+// Unmarshal 使用当前的编解码器反序列化
+func Unmarshal(data []byte, v any) error {
+	return Get().Unmarshal(data, v)
+}
+
+// MarshalIndent 使用当前的编解码器带缩进序列化
+func MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return Get().MarshalIndent(v, prefix, indent)
+}
+
+// NewEncoder 使用当前的编解码器创建流式编码器
+func NewEncoder(w io.Writer) Encoder {
+	return Get().NewEncoder(w)
+}
+
+// NewDecoder 使用当前的编解码器创建流式解码器
+func NewDecoder(r io.Reader) Decoder {
+	return Get().NewDecoder(r)
+}
+
+// stdCodec 基于标准库 encoding/json 的编解码器
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (stdCodec) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return json.MarshalIndent(v, prefix, indent)
+}
+
+func (stdCodec) NewEncoder(w io.Writer) Encoder {
+	return json.NewEncoder(w)
+}
+
+func (stdCodec) NewDecoder(r io.Reader) Decoder {
+	return json.NewDecoder(r)
+}
@@ -0,0 +1,285 @@
+// Package jsoncodec JSON 编解码器测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 JSON 编解码器的一致性测试和基准测试，不需要外部依赖。
+//
+// 测试覆盖内容：
+//  1. Set/Get - 设置编解码器后包级函数使用该编解码器，设置为 nil 时恢复为标准库
+//  2. 一致性测试 - 标准库编解码器（以及构建标签选择的默认编解码器）在 HTML 转义、数字精度、
+//     map 键排序、流式编解码、未知字段和非法输入上的行为，替换实现时可据此确认差异
+//  3. 基准测试 - 直接调用 encoding/json、通过默认编解码器调用、通过假的"快速"编解码器调用的开销对比
+//
+// 运行测试：go test -v ./utils/jsoncodec/...
+// 运行基准测试：go test -bench=. -benchmem ./utils/jsoncodec/...
+// ==================================================
+package jsoncodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ==================== 测试辅助类型 ====================
+
+// conformanceItem 一致性测试使用的结构体，字段顺序与 JSON 输出顺序一致
+type conformanceItem struct {
+	Name     string            `json:"name"`
+	Count    int64             `json:"count"`
+	Price    float64           `json:"price"`
+	Tags     []string          `json:"tags"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+	Raw      []byte            `json:"raw"`
+	Created  time.Time         `json:"created"`
+	internal string
+}
+
+// upperText 实现 json.Marshaler / json.Unmarshaler 的类型，输出大写字符串
+type upperText string
+
+func (u upperText) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.ToUpper(string(u)))
+}
+
+func (u *upperText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*u = upperText(strings.ToLower(s))
+	return nil
+}
+
+// fastCodec 假的"快速"编解码器：Marshal 直接返回固定内容，用于衡量间接调用本身的开销
+type fastCodec struct {
+	stdCodec
+	out []byte
+}
+
+func (f fastCodec) Marshal(v any) ([]byte, error) {
+	return f.out, nil
+}
+
+// ==================== Set/Get 测试 ====================
+
+// TestSetGet 测试设置编解码器
+//
+// 【功能点】验证 Set 后包级函数使用设置的编解码器，Set(nil) 恢复为标准库
+// 【测试流程】
+//  1. 设置 fastCodec，断言 Get 返回该编解码器，Marshal 返回其固定内容
+//  2. 设置为 nil，断言 Marshal 恢复为标准库的输出
+func TestSetGet(t *testing.T) {
+	original := Get()
+	t.Cleanup(func() { Set(original) })
+
+	Set(fastCodec{out: []byte(`"fast"`)})
+	assert.IsType(t, fastCodec{}, Get())
+	b, err := Marshal(map[string]int{"a": 1})
+	require.NoError(t, err)
+	assert.Equal(t, `"fast"`, string(b))
+
+	Set(nil)
+	b, err = Marshal(map[string]int{"a": 1})
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(b))
+	assert.IsType(t, stdCodec{}, Get())
+}
+
+// ==================== 一致性测试 ====================
+
+// TestConformance 对标准库编解码器和当前默认编解码器运行一致性测试
+//
+// 【功能点】验证编解码器与 encoding/json 的语义一致，使用构建标签切换默认实现时同样运行
+// 【测试流程】分别以 Standard() 和 Get() 运行 runConformance
+func TestConformance(t *testing.T) {
+	t.Run("standard", func(t *testing.T) { runConformance(t, Standard()) })
+	t.Run("default", func(t *testing.T) { runConformance(t, Get()) })
+}
+
+// runConformance 一致性测试用例
+func runConformance(t *testing.T, c Codec) {
+	t.Run("html escaping", func(t *testing.T) {
+		// Marshal 默认转义 &、<、>
+		b, err := c.Marshal(map[string]string{"html": "<a href=\"x?a=1&b=2\">"})
+		require.NoError(t, err)
+		assert.Equal(t, `{"html":"\u003ca href=\"x?a=1\u0026b=2\"\u003e"}`, string(b))
+
+		// Encoder 关闭转义后原样输出，并追加换行符
+		var buf bytes.Buffer
+		enc := c.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		require.NoError(t, enc.Encode("<b>&</b>"))
+		assert.Equal(t, "\"<b>&</b>\"\n", buf.String())
+	})
+
+	t.Run("number precision", func(t *testing.T) {
+		const big = `{"id":9007199254740993,"ratio":0.1}`
+
+		// 解析到 any 时数字为 float64，超过 2^53 的整数丢失精度
+		var loose map[string]any
+		require.NoError(t, c.Unmarshal([]byte(big), &loose))
+		assert.IsType(t, float64(0), loose["id"])
+		assert.NotEqual(t, "9007199254740993", json.Number(formatFloat(loose["id"].(float64))).String())
+
+		// UseNumber 保留原始数字文本
+		dec := c.NewDecoder(strings.NewReader(big))
+		dec.UseNumber()
+		var exact map[string]any
+		require.NoError(t, dec.Decode(&exact))
+		assert.Equal(t, json.Number("9007199254740993"), exact["id"])
+		assert.Equal(t, json.Number("0.1"), exact["ratio"])
+
+		// 解析到 int64 字段时精确
+		var typed struct {
+			ID int64 `json:"id"`
+		}
+		require.NoError(t, c.Unmarshal([]byte(big), &typed))
+		assert.Equal(t, int64(9007199254740993), typed.ID)
+
+		// 浮点数使用最短的往返表示
+		b, err := c.Marshal([]float64{0.1, 1e21, 100, 1.5e-7})
+		require.NoError(t, err)
+		assert.Equal(t, `[0.1,1e+21,100,1.5e-7]`, string(b))
+	})
+
+	t.Run("map key ordering", func(t *testing.T) {
+		// map 按键排序输出，结构体按字段声明顺序输出，不导出的字段和 omitempty 的空值不输出
+		b, err := c.Marshal(map[string]int{"b": 2, "c": 3, "a": 1, "10": 10, "9": 9})
+		require.NoError(t, err)
+		assert.Equal(t, `{"10":10,"9":9,"a":1,"b":2,"c":3}`, string(b))
+
+		b, err = c.Marshal(map[int]string{3: "c", 1: "a", 20: "t"})
+		require.NoError(t, err)
+		assert.Equal(t, `{"1":"a","20":"t","3":"c"}`, string(b))
+
+		item := conformanceItem{
+			Name:     "book",
+			Count:    2,
+			Price:    9.5,
+			Raw:      []byte("hi"),
+			Created:  time.Date(2024, 5, 6, 7, 8, 9, 123000000, time.UTC),
+			internal: "x",
+		}
+		b, err = c.Marshal(item)
+		require.NoError(t, err)
+		assert.Equal(t, `{"name":"book","count":2,"price":9.5,"tags":null,"raw":"aGk=","created":"2024-05-06T07:08:09.123Z"}`, string(b))
+
+		var decoded conformanceItem
+		require.NoError(t, c.Unmarshal(b, &decoded))
+		item.internal = ""
+		assert.True(t, item.Created.Equal(decoded.Created))
+		decoded.Created = item.Created
+		assert.Equal(t, item, decoded)
+	})
+
+	t.Run("indent", func(t *testing.T) {
+		b, err := c.MarshalIndent(map[string]any{"b": []int{1}, "a": 1}, "", "  ")
+		require.NoError(t, err)
+		assert.Equal(t, "{\n  \"a\": 1,\n  \"b\": [\n    1\n  ]\n}", string(b))
+	})
+
+	t.Run("marshaler", func(t *testing.T) {
+		b, err := c.Marshal(map[string]upperText{"v": "abc"})
+		require.NoError(t, err)
+		assert.Equal(t, `{"v":"ABC"}`, string(b))
+
+		var v struct {
+			V upperText `json:"v"`
+		}
+		require.NoError(t, c.Unmarshal(b, &v))
+		assert.Equal(t, upperText("abc"), v.V)
+	})
+
+	t.Run("stream decoding", func(t *testing.T) {
+		dec := c.NewDecoder(strings.NewReader(`{"name":"a"} {"name":"b"}`))
+		var names []string
+		for {
+			var item conformanceItem
+			err := dec.Decode(&item)
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			names = append(names, item.Name)
+		}
+		assert.Equal(t, []string{"a", "b"}, names)
+
+		dec = c.NewDecoder(strings.NewReader(`{"name":"a","unknown":1}`))
+		dec.DisallowUnknownFields()
+		assert.Error(t, dec.Decode(&conformanceItem{}))
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		var v map[string]any
+		assert.Error(t, c.Unmarshal([]byte(`{"a":`), &v))
+		assert.Error(t, c.Unmarshal([]byte(`{"a":1}`), v))
+		_, err := c.Marshal(map[string]any{"ch": make(chan int)})
+		assert.Error(t, err)
+	})
+}
+
+// formatFloat 将 float64 按整数格式输出，用于比较精度
+func formatFloat(f float64) string {
+	b, _ := json.Marshal(int64(f))
+	return string(b)
+}
+
+// ==================== 基准测试 ====================
+
+// benchmarkPayload 基准测试使用的响应结构
+var benchmarkPayload = map[string]any{
+	"code": 20000,
+	"msg":  "操作成功",
+	"data": []conformanceItem{
+		{Name: "book", Count: 2, Price: 9.5, Tags: []string{"a", "b"}},
+		{Name: "pen", Count: 10, Price: 1.25, Tags: []string{"c"}},
+	},
+}
+
+// BenchmarkMarshal_EncodingJSON 直接调用 encoding/json
+func BenchmarkMarshal_EncodingJSON(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = json.Marshal(benchmarkPayload)
+	}
+}
+
+// BenchmarkMarshal_DefaultCodec 通过包级函数调用默认（标准库）编解码器，与直接调用的差值即间接调用的开销
+func BenchmarkMarshal_DefaultCodec(b *testing.B) {
+	original := Get()
+	defer Set(original)
+	Set(nil)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = Marshal(benchmarkPayload)
+	}
+}
+
+// BenchmarkMarshal_FastCodecDirect 直接调用假的"快速"编解码器
+func BenchmarkMarshal_FastCodecDirect(b *testing.B) {
+	codec := fastCodec{out: []byte(`{}`)}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = codec.Marshal(benchmarkPayload)
+	}
+}
+
+// BenchmarkMarshal_FastCodec 通过包级函数调用假的"快速"编解码器，耗时即间接调用本身的开销
+func BenchmarkMarshal_FastCodec(b *testing.B) {
+	original := Get()
+	defer Set(original)
+	Set(fastCodec{out: []byte(`{}`)})
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = Marshal(benchmarkPayload)
+	}
+}
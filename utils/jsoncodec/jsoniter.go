@@ -0,0 +1,40 @@
+//go:build jsoniter
+
+package jsoncodec
+
+import (
+	"io"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// 使用 -tags jsoniter 构建时，默认编解码器为 jsoniter（与标准库兼容的配置），与 gin 的构建标签一致
+func init() {
+	Set(jsoniterCodec{})
+}
+
+// jsoniterAPI 与标准库行为兼容的 jsoniter 配置
+var jsoniterAPI = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// jsoniterCodec 基于 jsoniter 的编解码器
+type jsoniterCodec struct{}
+
+func (jsoniterCodec) Marshal(v any) ([]byte, error) {
+	return jsoniterAPI.Marshal(v)
+}
+
+func (jsoniterCodec) Unmarshal(data []byte, v any) error {
+	return jsoniterAPI.Unmarshal(data, v)
+}
+
+func (jsoniterCodec) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return jsoniterAPI.MarshalIndent(v, prefix, indent)
+}
+
+func (jsoniterCodec) NewEncoder(w io.Writer) Encoder {
+	return jsoniterAPI.NewEncoder(w)
+}
+
+func (jsoniterCodec) NewDecoder(r io.Reader) Decoder {
+	return jsoniterAPI.NewDecoder(r)
+}
@@ -0,0 +1,40 @@
+//go:build sonic && (linux || windows || darwin)
+
+package jsoncodec
+
+import (
+	"io"
+
+	"github.com/bytedance/sonic"
+)
+
+// 使用 -tags sonic 构建时，默认编解码器为 sonic（与标准库兼容的配置），与 gin 的构建标签一致
+func init() {
+	Set(sonicCodec{})
+}
+
+// sonicAPI 与标准库行为兼容的 sonic 配置（转义 HTML、按键排序 map）
+var sonicAPI = sonic.ConfigStd
+
+// sonicCodec 基于 sonic 的编解码器
+type sonicCodec struct{}
+
+func (sonicCodec) Marshal(v any) ([]byte, error) {
+	return sonicAPI.Marshal(v)
+}
+
+func (sonicCodec) Unmarshal(data []byte, v any) error {
+	return sonicAPI.Unmarshal(data, v)
+}
+
+func (sonicCodec) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return sonicAPI.MarshalIndent(v, prefix, indent)
+}
+
+func (sonicCodec) NewEncoder(w io.Writer) Encoder {
+	return sonicAPI.NewEncoder(w)
+}
+
+func (sonicCodec) NewDecoder(r io.Reader) Decoder {
+	return sonicAPI.NewDecoder(r)
+}
@@ -0,0 +1,178 @@
+// Package netutil 提供网络地址相关的工具函数
+// 本文件实现受信任代理的配置和客户端真实 IP 的解析
+package netutil
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trustedProxies 受信任的代理网段，未配置时不信任任何代理
+var trustedProxies atomic.Pointer[[]netip.Prefix]
+
+// ParseProxies 解析代理地址列表，支持 IP 和 CIDR，单个 IP 按单地址网段处理
+// 参数：
+//   - proxies: 代理地址列表
+//
+// 返回：
+//   - []netip.Prefix: 解析成功的网段
+//   - error: 存在无法解析的地址时返回错误，错误中包含该地址
+func ParseProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return prefixes, fmt.Errorf("无法解析的代理地址: %s", proxy)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+// SetTrustedProxies 设置受信任的代理地址，ClientIP 只读取来自这些地址的转发请求头
+// 参数：
+//   - proxies: 代理地址列表（IP 或 CIDR），为空时不信任任何代理
+//
+// 返回：
+//   - error: 存在无法解析的地址时返回错误，此时保留原有配置
+func SetTrustedProxies(proxies []string) error {
+	prefixes, err := ParseProxies(proxies)
+	if err != nil {
+		return err
+	}
+	trustedProxies.Store(&prefixes)
+	return nil
+}
+
+// IsTrustedProxy 判断地址是否属于受信任的代理
+func IsTrustedProxy(addr netip.Addr) bool {
+	prefixes := trustedProxies.Load()
+	if prefixes == nil {
+		return false
+	}
+	addr = addr.Unmap().WithZone("")
+	for _, prefix := range *prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP 获取请求的客户端真实 IP
+// 只有直连地址属于受信任的代理时才读取转发请求头，防止客户端伪造 IP：
+//  1. 直连地址不受信任时直接返回直连地址
+//  2. 从右向左遍历 X-Forwarded-For，跳过受信任的代理，返回第一个不受信任的地址；全部受信任时返回最左侧的地址
+//  3. 没有 X-Forwarded-For 时读取 X-Real-IP
+//
+// 返回的 IPv6 地址去掉了 zone，IPv4 映射的 IPv6 地址转换为 IPv4
+//
+// 参数：
+//   - c: gin 上下文
+//
+// 返回：
+//   - string: 客户端 IP，直连地址无法解析时返回原始的主机部分
+func ClientIP(c *gin.Context) string {
+	return RequestClientIP(c.Request)
+}
+
+// RequestClientIP 获取 http.Request 的客户端真实 IP，规则与 ClientIP 相同
+func RequestClientIP(r *http.Request) string {
+	remote, ok := ParseIP(r.RemoteAddr)
+	if !ok {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return r.RemoteAddr
+		}
+		return host
+	}
+	if !IsTrustedProxy(remote) {
+		return remote.String()
+	}
+
+	if hops := forwardedFor(r.Header); len(hops) > 0 {
+		client := remote
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, ok := ParseIP(hops[i])
+			if !ok {
+				// 无法解析的地址之后的内容不可信，返回最后一个受信任的地址
+				break
+			}
+			client = hop
+			if !IsTrustedProxy(hop) {
+				break
+			}
+		}
+		return client.String()
+	}
+
+	if realIP, ok := ParseIP(r.Header.Get("X-Real-IP")); ok {
+		return realIP.String()
+	}
+	return remote.String()
+}
+
+// RequestScheme 获取请求的协议（http 或 https）
+// TLS 连接为 https；直连地址属于受信任的代理时读取 X-Forwarded-Proto（多级代理时取第一个值），防止客户端伪造协议；其余为 http
+func RequestScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	if remote, ok := ParseIP(r.RemoteAddr); ok && IsTrustedProxy(remote) {
+		proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+		if proto = strings.ToLower(strings.TrimSpace(proto)); proto == "https" || proto == "http" {
+			return proto
+		}
+	}
+	return "http"
+}
+
+// forwardedFor 返回 X-Forwarded-For 中的所有地址，多个请求头按出现顺序合并
+func forwardedFor(header http.Header) []string {
+	var hops []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// ParseIP 解析 IP 地址，支持以下格式：
+//   - 1.2.3.4、1.2.3.4:8080
+//   - 2001:db8::1、[2001:db8::1]、[2001:db8::1]:8080
+//   - fe80::1%eth0、[fe80::1%eth0]:8080（zone 会被去掉）
+//
+// 返回：
+//   - netip.Addr: 解析后的地址，IPv4 映射的 IPv6 地址转换为 IPv4
+//   - bool: 是否解析成功
+func ParseIP(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if addrPort, err := netip.ParseAddrPort(s); err == nil {
+		return normalizeAddr(addrPort.Addr()), true
+	}
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		s = s[1 : len(s)-1]
+	}
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return normalizeAddr(addr), true
+	}
+	return netip.Addr{}, false
+}
+
+// normalizeAddr 去掉 zone 并将 IPv4 映射的 IPv6 地址转换为 IPv4
+func normalizeAddr(addr netip.Addr) netip.Addr {
+	return addr.WithZone("").Unmap()
+}
@@ -0,0 +1,180 @@
+// Package netutil 客户端 IP 解析测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含受信任代理配置和客户端真实 IP 解析的单元测试。
+//
+// 测试覆盖内容：
+// 1. 不受信任的直连地址伪造的 X-Forwarded-For、X-Real-IP 被忽略
+// 2. 经过多个受信任代理时从右向左解析出真实客户端
+// 3. X-Real-IP 只在直连地址受信任且没有 X-Forwarded-For 时生效
+// 4. IPv4、IPv6、带端口、带方括号、带 zone 的地址解析
+// 5. 无法解析的代理地址
+// 6. 请求协议：TLS 连接为 https，只有受信任代理的 X-Forwarded-Proto 生效
+//
+// 运行测试：go test -v ./utils/netutil/... -run ClientIP
+// ==================================================
+package netutil
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// setTrusted 设置受信任的代理，测试结束后恢复为不信任任何代理
+func setTrusted(t *testing.T, proxies ...string) {
+	t.Helper()
+	if err := SetTrustedProxies(proxies); err != nil {
+		t.Fatalf("设置受信任代理失败: %v", err)
+	}
+	t.Cleanup(func() { _ = SetTrustedProxies(nil) })
+}
+
+// newRequest 创建指定直连地址和请求头的请求，headers 按 键、值 成对传入
+func newRequest(remoteAddr string, headers ...string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Add(headers[i], headers[i+1])
+	}
+	return req
+}
+
+// TestClientIP_UntrustedPeer 测试不受信任的直连地址
+//
+// 【功能点】验证直连地址不受信任时忽略转发请求头，返回直连地址
+// 【测试流程】
+//  1. 不配置受信任代理，请求携带伪造的 X-Forwarded-For 和 X-Real-IP
+//  2. 断言返回直连地址
+//  3. 信任 10.0.0.0/8 后，来自 203.0.113.9 的请求仍返回直连地址
+func TestClientIP_UntrustedPeer(t *testing.T) {
+	req := newRequest("203.0.113.9:5555", "X-Forwarded-For", "1.1.1.1", "X-Real-IP", "2.2.2.2")
+	if got := RequestClientIP(req); got != "203.0.113.9" {
+		t.Errorf("未配置受信任代理时应返回直连地址, 实际 %s", got)
+	}
+
+	setTrusted(t, "10.0.0.0/8")
+	if got := RequestClientIP(req); got != "203.0.113.9" {
+		t.Errorf("直连地址不受信任时应忽略转发请求头, 实际 %s", got)
+	}
+}
+
+// TestClientIP_MultiHop 测试多级代理
+//
+// 【功能点】验证从右向左遍历 X-Forwarded-For，跳过受信任的代理，返回第一个不受信任的地址
+// 【测试流程】
+//  1. 信任 10.0.0.1 和 10.0.0.2，请求经客户端 -> 10.0.0.2 -> 10.0.0.1 到达
+//  2. 客户端伪造了最左侧的地址，断言返回真实客户端 198.51.100.7
+//  3. X-Forwarded-For 分散在多个请求头中时结果相同
+//  4. 全部为受信任代理时返回最左侧的地址；遇到无法解析的地址时返回最后一个受信任的地址
+func TestClientIP_MultiHop(t *testing.T) {
+	setTrusted(t, "10.0.0.1", "10.0.0.2/32")
+
+	tests := []struct {
+		name    string
+		headers []string
+		want    string
+	}{
+		{"伪造最左侧地址", []string{"X-Forwarded-For", "6.6.6.6, 198.51.100.7, 10.0.0.2"}, "198.51.100.7"},
+		{"多个请求头", []string{"X-Forwarded-For", "6.6.6.6", "X-Forwarded-For", "198.51.100.7,10.0.0.2"}, "198.51.100.7"},
+		{"全部为受信任代理", []string{"X-Forwarded-For", "10.0.0.2"}, "10.0.0.2"},
+		{"无法解析的地址", []string{"X-Forwarded-For", "6.6.6.6, unknown, 10.0.0.2"}, "10.0.0.2"},
+		{"X-Forwarded-For 优先于 X-Real-IP", []string{"X-Forwarded-For", "198.51.100.7", "X-Real-IP", "2.2.2.2"}, "198.51.100.7"},
+		{"X-Real-IP", []string{"X-Real-IP", "198.51.100.8"}, "198.51.100.8"},
+		{"没有转发请求头", nil, "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RequestClientIP(newRequest("10.0.0.1:443", tt.headers...)); got != tt.want {
+				t.Errorf("期望 %s, 实际 %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestClientIP_IPv6 测试 IPv6 地址
+//
+// 【功能点】验证 IPv6 直连地址和转发地址的解析，包括方括号、端口、zone 和 IPv4 映射地址
+// 【测试流程】
+//  1. 解析各种格式的地址，断言结果
+//  2. 信任 fd00::/8，请求来自 [fd00::1%eth0]:443，X-Forwarded-For 为 [2001:db8::7]:5000，断言返回 2001:db8::7
+func TestClientIP_IPv6(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"2001:db8::1", "2001:db8::1"},
+		{"[2001:db8::1]", "2001:db8::1"},
+		{"[2001:db8::1]:8080", "2001:db8::1"},
+		{"fe80::1%eth0", "fe80::1"},
+		{"[fe80::1%eth0]:8080", "fe80::1"},
+		{"::ffff:192.0.2.1", "192.0.2.1"},
+		{"192.0.2.1:80", "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		addr, ok := ParseIP(tt.input)
+		if !ok || addr.String() != tt.want {
+			t.Errorf("ParseIP(%q) 期望 %s, 实际 %s (ok=%v)", tt.input, tt.want, addr, ok)
+		}
+	}
+	for _, input := range []string{"", "unknown", "[2001:db8::1", "2001:db8::1]:80:80"} {
+		if _, ok := ParseIP(input); ok {
+			t.Errorf("ParseIP(%q) 应解析失败", input)
+		}
+	}
+
+	setTrusted(t, "fd00::/8")
+	req := newRequest("[fd00::1%eth0]:443", "X-Forwarded-For", "[2001:db8::7]:5000")
+	if got := RequestClientIP(req); got != "2001:db8::7" {
+		t.Errorf("期望 2001:db8::7, 实际 %s", got)
+	}
+	if got := RequestClientIP(newRequest("[2001:db8::9]:443", "X-Forwarded-For", "1.1.1.1")); got != "2001:db8::9" {
+		t.Errorf("不受信任的 IPv6 直连地址应返回自身, 实际 %s", got)
+	}
+}
+
+// TestRequestScheme 测试请求协议
+//
+// 【功能点】验证 TLS 连接为 https；X-Forwarded-Proto 只在直连地址为受信任代理时生效，多级代理时取第一个值
+// 【测试流程】
+//  1. 不配置受信任代理，断言普通请求为 http，伪造 X-Forwarded-Proto: https 时仍为 http，TLS 连接为 https
+//  2. 信任 10.0.0.1 后，断言来自 10.0.0.1 的 X-Forwarded-Proto: https, http 为 https，无法识别的值为 http
+func TestRequestScheme(t *testing.T) {
+	if got := RequestScheme(newRequest("203.0.113.9:5555")); got != "http" {
+		t.Errorf("期望 http, 实际 %s", got)
+	}
+	if got := RequestScheme(newRequest("203.0.113.9:5555", "X-Forwarded-Proto", "https")); got != "http" {
+		t.Errorf("不受信任的直连地址伪造 X-Forwarded-Proto 时期望 http, 实际 %s", got)
+	}
+	req := newRequest("203.0.113.9:5555")
+	req.TLS = &tls.ConnectionState{}
+	if got := RequestScheme(req); got != "https" {
+		t.Errorf("TLS 连接期望 https, 实际 %s", got)
+	}
+
+	setTrusted(t, "10.0.0.1")
+	if got := RequestScheme(newRequest("10.0.0.1:443", "X-Forwarded-Proto", "HTTPS, http")); got != "https" {
+		t.Errorf("受信任代理的 X-Forwarded-Proto 期望 https, 实际 %s", got)
+	}
+	if got := RequestScheme(newRequest("10.0.0.1:443", "X-Forwarded-Proto", "ws")); got != "http" {
+		t.Errorf("无法识别的 X-Forwarded-Proto 期望 http, 实际 %s", got)
+	}
+}
+
+// TestSetTrustedProxies_Invalid 测试无法解析的代理地址
+//
+// 【功能点】验证存在无法解析的地址时返回错误，并保留原有配置
+// 【测试流程】
+//  1. 信任 10.0.0.1，再设置包含无法解析地址的列表
+//  2. 断言返回包含该地址的错误，10.0.0.1 仍受信任
+func TestSetTrustedProxies_Invalid(t *testing.T) {
+	setTrusted(t, "10.0.0.1")
+	err := SetTrustedProxies([]string{"10.0.0.0/8", "proxy.local"})
+	if err == nil || err.Error() != "无法解析的代理地址: proxy.local" {
+		t.Fatalf("期望返回无法解析的错误, 实际 %v", err)
+	}
+	if got := RequestClientIP(newRequest("10.0.0.1:443", "X-Real-IP", "198.51.100.8")); got != "198.51.100.8" {
+		t.Errorf("设置失败时应保留原有配置, 实际 %s", got)
+	}
+}
@@ -1,11 +1,12 @@
 // Package serialize 提供结构体与 map 之间的序列化转换工具。
 //
-// 内部通过 encoding/json 实现中间转换，因此字段映射遵循 json tag 规则。
+// 内部通过 jsoncodec（默认为 encoding/json）实现中间转换，因此字段映射遵循 json tag 规则。
 package serialize
 
 import (
-	"encoding/json"
 	"reflect"
+
+	"github.com/zzsen/gin_core/utils/jsoncodec"
 )
 
 // MapToStruct 将 map 对象转换为结构体对象。
@@ -18,12 +19,12 @@ import (
 // 返回：
 //   - error: 序列化或反序列化失败时返回错误
 func MapToStruct(m map[string]any, s any) error {
-	data, err := json.Marshal(m)
+	data, err := jsoncodec.Marshal(m)
 	if err != nil {
 		return err
 	}
 
-	if err := json.Unmarshal(data, s); err != nil {
+	if err := jsoncodec.Unmarshal(data, s); err != nil {
 		return err
 	}
 
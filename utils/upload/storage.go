@@ -0,0 +1,301 @@
+package upload
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// Storage 上传文件存储接口
+type Storage interface {
+	// Save 保存文件
+	// 参数：
+	//   - ctx: context
+	//   - file: 通过校验的上传文件
+	//   - key: 文件名，可包含子目录，为空时按 uuid 生成；实际保存位置由存储实现决定
+	//
+	// 返回：
+	//   - string: 文件访问地址
+	//   - error: 保存失败时返回错误
+	Save(ctx context.Context, file UploadedFile, key string) (string, error)
+}
+
+// NewStorage 根据配置创建存储
+// 参数：
+//   - cfg: 文件上传存储配置
+//
+// 返回：
+//   - Storage: 存储实例
+//   - error: 存储类型无法识别时返回错误
+func NewStorage(cfg config.UploadConfig) (Storage, error) {
+	switch cfg.GetStorage() {
+	case config.UploadStorageLocal:
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = "/uploads"
+		}
+		return NewLocalDiskStorage(cfg.GetLocalDir(), cfg.GetPathTemplate(), baseURL), nil
+	case config.UploadStorageS3:
+		return NewS3Storage(cfg.S3, cfg.GetPathTemplate(), cfg.BaseURL), nil
+	default:
+		return nil, fmt.Errorf("不支持的上传存储类型: %s，可选值 local / s3", cfg.Storage)
+	}
+}
+
+// renderPathTemplate 替换目录模板中的日期占位符
+func renderPathTemplate(template string, t time.Time) string {
+	return strings.NewReplacer(
+		"{yyyy}", t.Format("2006"),
+		"{mm}", t.Format("01"),
+		"{dd}", t.Format("02"),
+	).Replace(template)
+}
+
+// cleanKey 规范化文件名，去除 ".." 等越出存储目录的部分；为空时按 uuid 和扩展名生成
+func cleanKey(key, ext string) string {
+	key = strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(key, "\\", "/")), "/")
+	if key == "" {
+		return uuid.NewString() + ext
+	}
+	return key
+}
+
+// ==================== 本地磁盘存储 ====================
+
+// maxRenameAttempts 本地存储文件名冲突时追加序号的最大次数，超过后使用 uuid 文件名
+const maxRenameAttempts = 100
+
+// LocalDiskStorage 本地磁盘存储
+// 文件保存在 BaseDir/目录模板/key，同名文件已存在时追加序号（a.png → a-1.png），不会覆盖
+type LocalDiskStorage struct {
+	BaseDir      string // 根目录
+	PathTemplate string // 目录模板，支持 {yyyy}、{mm}、{dd}
+	BaseURL      string // 访问地址前缀
+
+	now func() time.Time
+}
+
+// NewLocalDiskStorage 创建本地磁盘存储
+// 参数：
+//   - baseDir: 根目录
+//   - pathTemplate: 目录模板，支持 {yyyy}、{mm}、{dd}
+//   - baseURL: 访问地址前缀，返回地址为 baseURL/目录/文件名
+//
+// 返回：
+//   - *LocalDiskStorage: 存储实例
+func NewLocalDiskStorage(baseDir, pathTemplate, baseURL string) *LocalDiskStorage {
+	return &LocalDiskStorage{BaseDir: baseDir, PathTemplate: pathTemplate, BaseURL: baseURL, now: time.Now}
+}
+
+// Save 保存文件到本地磁盘，返回访问地址
+func (s *LocalDiskStorage) Save(ctx context.Context, file UploadedFile, key string) (string, error) {
+	rel := path.Join(renderPathTemplate(s.PathTemplate, s.now()), cleanKey(key, file.Ext))
+	if err := os.MkdirAll(filepath.Join(s.BaseDir, filepath.FromSlash(path.Dir(rel))), 0o755); err != nil {
+		return "", fmt.Errorf("创建上传目录失败: %w", err)
+	}
+
+	dst, rel, err := s.create(rel, file.Ext)
+	if err != nil {
+		return "", fmt.Errorf("创建上传文件失败: %w", err)
+	}
+	src, err := file.Open()
+	if err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return "", fmt.Errorf("读取上传文件失败: %w", err)
+	}
+	defer src.Close()
+
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst.Name())
+		return "", fmt.Errorf("写入上传文件失败: %w", err)
+	}
+	return strings.TrimSuffix(s.BaseURL, "/") + "/" + rel, nil
+}
+
+// create 以独占方式创建文件，同名文件已存在时追加序号重试
+func (s *LocalDiskStorage) create(rel, ext string) (*os.File, string, error) {
+	base := strings.TrimSuffix(rel, path.Ext(rel))
+	candidate := rel
+	for i := 1; ; i++ {
+		f, err := os.OpenFile(filepath.Join(s.BaseDir, filepath.FromSlash(candidate)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			return f, candidate, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, "", err
+		}
+		switch {
+		case i < maxRenameAttempts:
+			candidate = fmt.Sprintf("%s-%d%s", base, i, path.Ext(rel))
+		case i == maxRenameAttempts:
+			candidate = path.Join(path.Dir(rel), uuid.NewString()+ext)
+		default:
+			return nil, "", err
+		}
+	}
+}
+
+// ==================== S3 兼容对象存储 ====================
+
+// S3Storage S3 兼容对象存储
+// 仅实现单次 PutObject（AWS Signature V4 签名），不支持分片上传，适用于 AWS S3、MinIO 等；
+// 对象键为 目录模板/uuid/key，同名文件不会互相覆盖
+type S3Storage struct {
+	Config       config.S3Config
+	PathTemplate string // 目录模板，支持 {yyyy}、{mm}、{dd}
+	BaseURL      string // 访问地址前缀，为空时返回对象地址
+
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Storage 创建 S3 兼容对象存储
+// 参数：
+//   - cfg: 连接配置
+//   - pathTemplate: 目录模板，支持 {yyyy}、{mm}、{dd}
+//   - baseURL: 访问地址前缀（如 CDN 域名），为空时返回对象地址
+//
+// 返回：
+//   - *S3Storage: 存储实例
+func NewS3Storage(cfg config.S3Config, pathTemplate, baseURL string) *S3Storage {
+	return &S3Storage{
+		Config:       cfg,
+		PathTemplate: pathTemplate,
+		BaseURL:      baseURL,
+		client:       &http.Client{Timeout: 60 * time.Second},
+		now:          time.Now,
+	}
+}
+
+// Save 上传文件到对象存储，返回访问地址
+func (s *S3Storage) Save(ctx context.Context, file UploadedFile, key string) (string, error) {
+	objectKey := path.Join(renderPathTemplate(s.PathTemplate, s.now()), uuid.NewString(), cleanKey(key, file.Ext))
+	objectURL, err := s.objectURL(objectKey)
+	if err != nil {
+		return "", err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("读取上传文件失败: %w", err)
+	}
+	defer src.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL.String(), src)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = file.Size
+	if file.Size == 0 {
+		req.Body = http.NoBody
+	}
+	if file.ContentType != "" {
+		req.Header.Set("Content-Type", file.ContentType)
+	}
+	s.sign(req, s.now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("上传文件到对象存储失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("上传文件到对象存储失败, status: %d, body: %s", resp.StatusCode, body)
+	}
+
+	if s.BaseURL != "" {
+		return strings.TrimSuffix(s.BaseURL, "/") + "/" + objectKey, nil
+	}
+	return objectURL.String(), nil
+}
+
+// objectURL 构造对象地址，路径风格为 endpoint/bucket/key，否则为 bucket.endpoint/key
+func (s *S3Storage) objectURL(objectKey string) (*url.URL, error) {
+	u, err := url.Parse(s.Config.Endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("对象存储地址无效: %s", s.Config.Endpoint)
+	}
+	p := "/" + objectKey
+	if s.Config.UsePathStyle {
+		p = "/" + s.Config.Bucket + p
+	} else {
+		u.Host = s.Config.Bucket + "." + u.Host
+	}
+	u.Path = p
+	u.RawPath = s3EscapePath(p)
+	return u, nil
+}
+
+// s3EscapePath 按 S3 规范编码路径：除字母、数字、"-_.~" 和 "/" 外全部百分号编码，保证签名与实际请求路径一致
+func s3EscapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// sign 使用 AWS Signature V4 签名请求，请求体不参与签名（UNSIGNED-PAYLOAD）
+func (s *S3Storage) sign(req *http.Request, t time.Time) {
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	const payloadHash = "UNSIGNED-PAYLOAD"
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		"host:" + req.URL.Host + "\nx-amz-content-sha256:" + payloadHash + "\nx-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	region := s.Config.GetRegion()
+	scope := date + "/" + region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.Config.SecretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.Config.AccessKey, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 计算 HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
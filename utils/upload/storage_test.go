@@ -0,0 +1,168 @@
+// Package upload 上传文件存储测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 LocalDiskStorage、S3Storage 与 NewStorage 的单元测试。
+//
+// 测试覆盖内容：
+// 1. 本地存储按日期目录写入临时目录，同名文件追加序号，越出根目录的文件名被规范化
+// 2. S3 存储以路径风格发送签名的 PUT 请求，返回对象地址或自定义地址前缀
+// 3. NewStorage 根据配置选择存储实现
+//
+// 运行测试：go test -v ./utils/upload/...
+// ==================================================
+package upload
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// fixedNow 测试使用的固定时间
+var fixedNow = time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC)
+
+// newTestFile 构造内容为 content 的上传文件
+func newTestFile(t *testing.T, filename string, content []byte) UploadedFile {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { form.RemoveAll() })
+	header := form.File["file"][0]
+	return UploadedFile{
+		Field:       "file",
+		Filename:    filename,
+		Ext:         strings.ToLower(filepath.Ext(filename)),
+		Size:        header.Size,
+		ContentType: "text/plain",
+		Header:      header,
+	}
+}
+
+// TestLocalDiskStorage_Save 测试本地存储
+//
+// 【功能点】验证文件写入 根目录/年/月/日/文件名，同名文件追加序号不覆盖，文件名中的 ".." 不会越出根目录
+// 【测试流程】
+//  1. 在临时目录中保存 a.txt 两次，断言地址分别为 /files/2026/03/05/a.txt 与 a-1.txt，内容正确
+//  2. 以 ../../evil.txt 保存，断言文件位于日期目录下
+//  3. 不指定文件名保存，断言按 uuid 生成文件名并保留扩展名
+func TestLocalDiskStorage_Save(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocalDiskStorage(dir, "{yyyy}/{mm}/{dd}", "/files/")
+	s.now = func() time.Time { return fixedNow }
+	ctx := context.Background()
+
+	url, err := s.Save(ctx, newTestFile(t, "a.txt", []byte("first")), "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "/files/2026/03/05/a.txt", url)
+
+	url, err = s.Save(ctx, newTestFile(t, "a.txt", []byte("second")), "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "/files/2026/03/05/a-1.txt", url)
+
+	content, err := os.ReadFile(filepath.Join(dir, "2026", "03", "05", "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(content))
+	content, err = os.ReadFile(filepath.Join(dir, "2026", "03", "05", "a-1.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+
+	url, err = s.Save(ctx, newTestFile(t, "evil.txt", []byte("x")), "../../evil.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "/files/2026/03/05/evil.txt", url)
+	assert.FileExists(t, filepath.Join(dir, "2026", "03", "05", "evil.txt"))
+
+	url, err = s.Save(ctx, newTestFile(t, "b.png", []byte("x")), "")
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^/files/2026/03/05/[0-9a-f-]{36}\.png$`), url)
+}
+
+// TestS3Storage_Save 测试 S3 存储
+//
+// 【功能点】验证以路径风格发送带 AWS Signature V4 签名的 PUT 请求，返回对象地址；配置地址前缀时返回自定义地址
+// 【测试流程】
+//  1. 启动模拟服务，记录请求方法、路径、签名头和请求体
+//  2. 保存 a b.txt，断言路径为 /bucket/2026/03/05/<uuid>/a%20b.txt，签名头和请求体正确
+//  3. 模拟服务返回 403 时断言返回错误
+func TestS3Storage_Save(t *testing.T) {
+	var (
+		gotMethod, gotPath, gotAuth, gotType string
+		gotBody                              []byte
+		status                               = http.StatusOK
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.EscapedPath()
+		gotAuth, gotType = r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s := NewS3Storage(config.S3Config{
+		Endpoint:     server.URL,
+		Bucket:       "bucket",
+		AccessKey:    "AKID",
+		SecretKey:    "secret",
+		UsePathStyle: true,
+	}, "{yyyy}/{mm}/{dd}", "")
+	s.now = func() time.Time { return fixedNow }
+
+	url, err := s.Save(context.Background(), newTestFile(t, "a b.txt", []byte("hello")), "a b.txt")
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPut, gotMethod)
+	assert.Regexp(t, regexp.MustCompile(`^/bucket/2026/03/05/[0-9a-f-]{36}/a%20b\.txt$`), gotPath)
+	assert.Equal(t, server.URL+gotPath, url)
+	assert.True(t, strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKID/20260305/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="), gotAuth)
+	assert.Equal(t, "text/plain", gotType)
+	assert.Equal(t, "hello", string(gotBody))
+
+	s.BaseURL = "https://cdn.example.com/"
+	url, err = s.Save(context.Background(), newTestFile(t, "c.txt", []byte("x")), "c.txt")
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^https://cdn\.example\.com/2026/03/05/[0-9a-f-]{36}/c\.txt$`), url)
+
+	status = http.StatusForbidden
+	_, err = s.Save(context.Background(), newTestFile(t, "d.txt", []byte("x")), "d.txt")
+	assert.ErrorContains(t, err, "status: 403")
+}
+
+// TestNewStorage 测试根据配置创建存储
+//
+// 【功能点】验证默认创建本地存储并使用默认目录和地址前缀，s3 创建对象存储，未知类型返回错误
+// 【测试流程】分别以空配置、s3、oss 调用 NewStorage，断言返回类型或错误
+func TestNewStorage(t *testing.T) {
+	s, err := NewStorage(config.UploadConfig{})
+	require.NoError(t, err)
+	local, ok := s.(*LocalDiskStorage)
+	require.True(t, ok)
+	assert.Equal(t, "./uploads", local.BaseDir)
+	assert.Equal(t, "/uploads", local.BaseURL)
+	assert.Equal(t, "{yyyy}/{mm}/{dd}", local.PathTemplate)
+
+	s, err = NewStorage(config.UploadConfig{Storage: config.UploadStorageS3, S3: config.S3Config{Endpoint: "http://minio:9000", Bucket: "b"}})
+	require.NoError(t, err)
+	assert.IsType(t, &S3Storage{}, s)
+
+	_, err = NewStorage(config.UploadConfig{Storage: "oss"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,195 @@
+// Package upload 提供 multipart 文件上传的解析、校验与存储
+//
+// ParseFiles 校验上传文件的数量、大小和类型，类型按文件内容（http.DetectContentType）识别，
+// 不信任扩展名和客户端声明的 Content-Type；校验失败返回 exception.InvalidParam，
+// 直接 panic 即可由 ExceptionHandler 返回参数校验失败响应。
+// Storage 接口负责保存文件，框架提供本地磁盘（LocalDiskStorage）和 S3 兼容对象存储（S3Storage）两种实现。
+package upload
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/exception"
+)
+
+// sniffLen http.DetectContentType 最多读取的字节数
+const sniffLen = 512
+
+// UploadOptions 上传文件校验选项
+type UploadOptions struct {
+	// MaxFiles 最多允许上传的文件数，默认 10
+	MaxFiles int
+	// MaxFileSize 单个文件的最大字节数，默认 10 MiB
+	MaxFileSize int64
+	// AllowedMIME 允许的内容类型（按文件内容识别），支持 "image/*" 通配，为空时不限制
+	AllowedMIME []string
+	// AllowedExt 允许的扩展名（如 ".jpg"，不区分大小写，可省略 "."），为空时不限制
+	// 扩展名可被随意修改，需要限制文件类型时应同时配置 AllowedMIME
+	AllowedExt []string
+}
+
+// GetMaxFiles 获取最多允许上传的文件数，如果未配置则返回 10
+func (o *UploadOptions) GetMaxFiles() int {
+	if o.MaxFiles <= 0 {
+		return 10
+	}
+	return o.MaxFiles
+}
+
+// GetMaxFileSize 获取单个文件的最大字节数，如果未配置则返回 10 MiB
+func (o *UploadOptions) GetMaxFileSize() int64 {
+	if o.MaxFileSize <= 0 {
+		return 10 << 20
+	}
+	return o.MaxFileSize
+}
+
+// UploadedFile 通过校验的上传文件
+type UploadedFile struct {
+	Field       string                // 表单字段名
+	Filename    string                // 客户端提交的文件名（已去除目录部分）
+	Ext         string                // 小写扩展名，含 "."，没有扩展名时为空
+	Size        int64                 // 文件大小（字节）
+	ContentType string                // 按文件内容识别的类型，不含参数（如 "image/png"）
+	Header      *multipart.FileHeader // 原始文件头
+}
+
+// Open 打开文件内容
+func (f UploadedFile) Open() (multipart.File, error) {
+	return f.Header.Open()
+}
+
+// ParseFiles 解析并校验 multipart 表单中指定字段的文件
+// 校验顺序：文件数量、单个文件大小、扩展名、按文件内容识别的类型
+// 参数：
+//   - c: Gin上下文
+//   - field: 表单字段名
+//   - opts: 校验选项
+//
+// 返回：
+//   - []UploadedFile: 通过校验的文件，顺序与表单一致
+//   - error: 未上传文件或校验失败时返回 exception.InvalidParam；读取文件失败时返回普通错误
+//
+// 使用示例：
+//
+//	files, err := upload.ParseFiles(c, "images", upload.UploadOptions{
+//	  MaxFiles:    5,
+//	  MaxFileSize: 2 << 20,
+//	  AllowedMIME: []string{"image/jpeg", "image/png"},
+//	})
+//	if err != nil {
+//	  panic(err)
+//	}
+func ParseFiles(c *gin.Context, field string, opts UploadOptions) ([]UploadedFile, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, exception.NewInvalidParam(fmt.Sprintf("解析上传文件失败: %v", err))
+	}
+	headers := form.File[field]
+	if len(headers) == 0 {
+		return nil, exception.NewInvalidParam(fmt.Sprintf("%s未上传文件", field))
+	}
+	if maxFiles := opts.GetMaxFiles(); len(headers) > maxFiles {
+		return nil, exception.NewInvalidParam(fmt.Sprintf("%s最多上传%d个文件，实际%d个", field, maxFiles, len(headers)))
+	}
+
+	files := make([]UploadedFile, 0, len(headers))
+	for _, header := range headers {
+		file, err := checkFile(field, header, &opts)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// MustParseFiles 解析并校验上传文件，失败时 panic
+// 校验规则与 ParseFiles 相同，适用于依赖 ExceptionHandler 统一处理异常的 handler
+//
+// 注意：此函数会 panic，请确保在有 recover 中间件保护的 handler 链中调用。
+func MustParseFiles(c *gin.Context, field string, opts UploadOptions) []UploadedFile {
+	files, err := ParseFiles(c, field, opts)
+	if err != nil {
+		panic(err)
+	}
+	return files
+}
+
+// checkFile 校验单个文件的大小、扩展名和内容类型
+func checkFile(field string, header *multipart.FileHeader, opts *UploadOptions) (UploadedFile, error) {
+	name := filepath.Base(strings.ReplaceAll(header.Filename, "\\", "/"))
+	file := UploadedFile{
+		Field:    field,
+		Filename: name,
+		Ext:      strings.ToLower(filepath.Ext(name)),
+		Size:     header.Size,
+		Header:   header,
+	}
+
+	if maxSize := opts.GetMaxFileSize(); file.Size > maxSize {
+		return file, exception.NewInvalidParam(fmt.Sprintf("文件%s大小为%d字节，超过限制%d字节", name, file.Size, maxSize))
+	}
+	if len(opts.AllowedExt) > 0 && !extAllowed(file.Ext, opts.AllowedExt) {
+		return file, exception.NewInvalidParam(fmt.Sprintf("文件%s的扩展名不允许，可选值: %s", name, strings.Join(opts.AllowedExt, ", ")))
+	}
+
+	contentType, err := sniffContentType(header)
+	if err != nil {
+		return file, fmt.Errorf("读取上传文件 %s 失败: %w", name, err)
+	}
+	file.ContentType = contentType
+	if len(opts.AllowedMIME) > 0 && !mimeAllowed(contentType, opts.AllowedMIME) {
+		return file, exception.NewInvalidParam(fmt.Sprintf("文件%s的类型 %s 不允许，可选值: %s", name, contentType, strings.Join(opts.AllowedMIME, ", ")))
+	}
+	return file, nil
+}
+
+// sniffContentType 读取文件开头识别内容类型，去除 charset 等参数
+func sniffContentType(header *multipart.FileHeader) (string, error) {
+	f, err := header.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	contentType := http.DetectContentType(buf[:n])
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.TrimSpace(contentType), nil
+}
+
+// extAllowed 扩展名是否在允许列表中
+func extAllowed(ext string, allowed []string) bool {
+	return slices.ContainsFunc(allowed, func(a string) bool {
+		a = strings.ToLower(a)
+		if !strings.HasPrefix(a, ".") {
+			a = "." + a
+		}
+		return a == ext
+	})
+}
+
+// mimeAllowed 内容类型是否在允许列表中，支持 "image/*" 通配
+func mimeAllowed(contentType string, allowed []string) bool {
+	return slices.ContainsFunc(allowed, func(a string) bool {
+		a = strings.ToLower(a)
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok {
+			return strings.HasPrefix(contentType, prefix+"/")
+		}
+		return a == contentType
+	})
+}
@@ -0,0 +1,177 @@
+// Package upload 上传文件解析与校验测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 ParseFiles / MustParseFiles 的单元测试，使用 multipart 表单构造上传请求。
+//
+// 测试覆盖内容：
+// 1. 多文件上传，按文件内容识别类型
+// 2. 超过单个文件大小限制
+// 3. 伪造扩展名（exe 改名为 .jpg）按内容识别后被拒绝
+// 4. 超过文件数量限制、未上传文件
+// 5. MustParseFiles 校验失败时由 ExceptionHandler 返回参数校验失败响应
+//
+// 运行测试：go test -v ./utils/upload/...
+// ==================================================
+package upload_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/exception"
+	"github.com/zzsen/gin_core/middleware"
+	"github.com/zzsen/gin_core/model/response"
+	"github.com/zzsen/gin_core/utils/upload"
+)
+
+// 测试文件内容，开头为各格式的文件签名
+var (
+	pngContent  = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+	jpegContent = append([]byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"), bytes.Repeat([]byte{0}, 64)...)
+	exeContent  = append([]byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff"), bytes.Repeat([]byte{0}, 64)...)
+)
+
+// fixture multipart 表单中的一个文件
+type fixture struct {
+	field    string
+	filename string
+	content  []byte
+}
+
+// newUploadRequest 构造 multipart 上传请求
+func newUploadRequest(t *testing.T, files ...fixture) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, f := range files {
+		part, err := w.CreateFormFile(f.field, f.filename)
+		require.NoError(t, err)
+		_, err = part.Write(f.content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+// parse 在 gin 上下文中调用 ParseFiles
+func parse(t *testing.T, req *http.Request, opts upload.UploadOptions) ([]upload.UploadedFile, error) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = req
+	return upload.ParseFiles(c, "files", opts)
+}
+
+// requireInvalidParam 断言错误为参数校验异常，并返回错误消息
+func requireInvalidParam(t *testing.T, err error) string {
+	t.Helper()
+	var invalid exception.InvalidParam
+	require.True(t, errors.As(err, &invalid), "期望 InvalidParam, 实际: %v", err)
+	msg, code := invalid.OnException(nil)
+	assert.Equal(t, response.ResponseParamInvalid.GetCode(), code)
+	return msg
+}
+
+// TestParseFiles_MultipleFiles 测试多文件上传
+//
+// 【功能点】验证多个文件均通过校验，内容类型按文件内容识别，文件名去除目录部分
+// 【测试流程】上传 PNG 和 JPEG 两个文件，限制为 image/*，断言返回两个文件及其类型、扩展名和大小
+func TestParseFiles_MultipleFiles(t *testing.T) {
+	req := newUploadRequest(t,
+		fixture{"files", "a.PNG", pngContent},
+		fixture{"files", `C:\photos\b.jpg`, jpegContent},
+	)
+	files, err := parse(t, req, upload.UploadOptions{AllowedMIME: []string{"image/*"}, AllowedExt: []string{"png", ".jpg"}})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	assert.Equal(t, "a.PNG", files[0].Filename)
+	assert.Equal(t, ".png", files[0].Ext)
+	assert.Equal(t, "image/png", files[0].ContentType)
+	assert.Equal(t, int64(len(pngContent)), files[0].Size)
+
+	assert.Equal(t, "b.jpg", files[1].Filename)
+	assert.Equal(t, "image/jpeg", files[1].ContentType)
+}
+
+// TestParseFiles_Oversize 测试文件大小限制
+//
+// 【功能点】验证超过 MaxFileSize 的文件被拒绝
+// 【测试流程】上传 72 字节的 PNG，限制 16 字节，断言返回参数校验异常
+func TestParseFiles_Oversize(t *testing.T) {
+	req := newUploadRequest(t, fixture{"files", "big.png", pngContent})
+	_, err := parse(t, req, upload.UploadOptions{MaxFileSize: 16})
+	assert.Contains(t, requireInvalidParam(t, err), "超过限制16字节")
+}
+
+// TestParseFiles_SpoofedExtension 测试伪造扩展名
+//
+// 【功能点】验证 exe 文件改名为 .jpg 后，扩展名校验通过但按内容识别的类型被拒绝
+// 【测试流程】上传内容为 exe 的 photo.jpg，允许 .jpg 和 image/jpeg，断言返回参数校验异常且消息包含识别出的类型
+func TestParseFiles_SpoofedExtension(t *testing.T) {
+	req := newUploadRequest(t, fixture{"files", "photo.jpg", exeContent})
+	_, err := parse(t, req, upload.UploadOptions{
+		AllowedMIME: []string{"image/jpeg"},
+		AllowedExt:  []string{".jpg"},
+	})
+	msg := requireInvalidParam(t, err)
+	assert.Contains(t, msg, "photo.jpg")
+	assert.Contains(t, msg, "application/octet-stream")
+}
+
+// TestParseFiles_CountAndExt 测试文件数量和扩展名限制
+//
+// 【功能点】验证超过 MaxFiles、扩展名不在允许列表、字段没有文件时返回参数校验异常
+// 【测试流程】分别构造三种请求，断言错误消息
+func TestParseFiles_CountAndExt(t *testing.T) {
+	req := newUploadRequest(t,
+		fixture{"files", "a.png", pngContent},
+		fixture{"files", "b.png", pngContent},
+	)
+	_, err := parse(t, req, upload.UploadOptions{MaxFiles: 1})
+	assert.Contains(t, requireInvalidParam(t, err), "最多上传1个文件")
+
+	req = newUploadRequest(t, fixture{"files", "a.gif", pngContent})
+	_, err = parse(t, req, upload.UploadOptions{AllowedExt: []string{".png"}})
+	assert.Contains(t, requireInvalidParam(t, err), "扩展名不允许")
+
+	req = newUploadRequest(t, fixture{"other", "a.png", pngContent})
+	_, err = parse(t, req, upload.UploadOptions{})
+	assert.Contains(t, requireInvalidParam(t, err), "未上传文件")
+}
+
+// TestMustParseFiles 测试 MustParseFiles 与异常处理
+//
+// 【功能点】验证校验失败时 panic 的异常由 ExceptionHandler 转换为参数校验失败响应
+// 【测试流程】注册使用 MustParseFiles 的路由，分别上传合法和伪造扩展名的文件，验证响应
+func TestMustParseFiles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ExceptionHandler())
+	r.POST("/upload", func(c *gin.Context) {
+		files := upload.MustParseFiles(c, "files", upload.UploadOptions{AllowedMIME: []string{"image/png"}})
+		c.JSON(http.StatusOK, gin.H{"count": len(files)})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newUploadRequest(t, fixture{"files", "a.png", pngContent}))
+	assert.JSONEq(t, `{"count":1}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, newUploadRequest(t, fixture{"files", "a.png", exeContent}))
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(response.ResponseParamInvalid.GetCode()), resp["code"])
+	assert.Contains(t, resp["msg"], "不允许")
+}
@@ -0,0 +1,28 @@
+// Package version 提供构建元数据，构建时通过 -ldflags 注入：
+//
+//	go build -ldflags "\
+//	  -X github.com/zzsen/gin_core/version.Version=v1.2.3 \
+//	  -X github.com/zzsen/gin_core/version.GitCommit=$(git rev-parse --short HEAD) \
+//	  -X github.com/zzsen/gin_core/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import "runtime"
+
+var (
+	// Version 版本号，未注入时为 "dev"
+	Version = "dev"
+	// GitCommit 构建时的 Git 提交，未注入时为 "unknown"
+	GitCommit = "unknown"
+	// BuildTime 构建时间，未注入时为 "unknown"
+	BuildTime = "unknown"
+	// GoVersion 构建使用的 Go 版本，未注入时为运行时的 Go 版本
+	GoVersion = ""
+)
+
+// GetGoVersion 获取 Go 版本，未注入 GoVersion 时返回 runtime.Version()
+func GetGoVersion() string {
+	if GoVersion == "" {
+		return runtime.Version()
+	}
+	return GoVersion
+}
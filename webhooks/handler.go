@@ -0,0 +1,240 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/idempotency"
+	"github.com/zzsen/gin_core/logger"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+// 投递结果，记录在日志的 outcome 字段中
+const (
+	OutcomeOK               = "ok"                // 处理成功
+	OutcomeInvalidSignature = "invalid_signature" // 签名缺失或不匹配
+	OutcomeInvalidTimestamp = "invalid_timestamp" // 时间戳缺失、格式错误或超出允许的偏差
+	OutcomeBodyTooLarge     = "body_too_large"    // 请求体超过大小上限
+	OutcomeInvalidBody      = "invalid_body"      // 读取请求体失败
+	OutcomeDuplicate        = "duplicate"         // 重复投递，已处理成功
+	OutcomeInProgress       = "in_progress"       // 重复投递，首次投递仍在处理
+	OutcomeHandlerError     = "handler_error"     // 处理函数返回错误或 panic
+)
+
+// 校验错误
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+)
+
+// NewHandler 创建回调的处理函数
+// 处理流程：
+//  1. 读取原始请求体（ginContext.RawBody，其他中间件之前或之后读取请求体均不影响），超过大小上限时响应 413
+//  2. 校验时间戳（设置了 TimestampHeader 时）和 HMAC 签名（常量时间比较），不通过时响应 400
+//  3. 开启去重时按校验通过的签名（HMAC 值）占用去重记录，已处理成功的重复投递响应 200，首次投递仍在处理时响应 409
+//  4. 调用处理函数，成功响应 200；返回错误或 panic 时释放去重记录并响应 500，由服务商重新投递
+//
+// 响应不使用统一响应结构（ginContext.SkipEnvelope），每次投递记录名称、追踪 ID、结果和耗时
+//
+// 参数：
+//   - name: 回调名称
+//   - cfg: 回调配置
+//
+// 返回：
+//   - gin.HandlerFunc: 处理函数，挂载到 POST cfg.Path
+//   - error: 配置有误时返回错误
+func NewHandler(name string, cfg Config) (gin.HandlerFunc, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("webhook %s: %w", name, err)
+	}
+	cfg = cfg.withDefaults()
+	return func(c *gin.Context) {
+		ginContext.SkipEnvelope(c)
+		start := time.Now()
+		// 确保处理函数收到的 ctx 中可获取追踪 ID 等请求数据
+		ginContext.GetRequestContext(c)
+		ctx := c.Request.Context()
+		status, outcome, err := deliver(ctx, c, name, cfg)
+		c.String(status, http.StatusText(status))
+		logDelivery(c, name, status, outcome, err, time.Since(start))
+	}, nil
+}
+
+// deliver 校验并处理一次投递，返回响应状态码、投递结果和错误
+func deliver(ctx context.Context, c *gin.Context, name string, cfg Config) (int, string, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxBodyBytes)
+	body, err := ginContext.RawBody(c)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return http.StatusRequestEntityTooLarge, OutcomeBodyTooLarge, err
+		}
+		return http.StatusBadRequest, OutcomeInvalidBody, err
+	}
+	// 其他中间件之前已读取的请求体不受 MaxBytesReader 限制
+	if int64(len(body)) > cfg.MaxBodyBytes {
+		return http.StatusRequestEntityTooLarge, OutcomeBodyTooLarge, fmt.Errorf("请求体 %d 字节超过上限 %d", len(body), cfg.MaxBodyBytes)
+	}
+
+	timestamp := ""
+	if cfg.TimestampHeader != "" {
+		timestamp = c.GetHeader(cfg.TimestampHeader)
+		if err := checkTimestamp(timestamp, cfg.Tolerance, time.Now()); err != nil {
+			return http.StatusBadRequest, OutcomeInvalidTimestamp, err
+		}
+	}
+	expected := Sign(cfg.Algorithm, cfg.Secret, signedPayload(cfg, timestamp, body))
+	if !verifySignature(c.GetHeader(cfg.SignatureHeader), expected) {
+		return http.StatusBadRequest, OutcomeInvalidSignature, ErrInvalidSignature
+	}
+
+	dedupKey := ""
+	if cfg.Dedup {
+		dedupKey = dedupKeyOf(name, expected)
+		record, err := cfg.DedupStore.Claim(ctx, dedupKey, cfg.DedupTTL)
+		switch {
+		case err != nil:
+			// 去重存储不可用时仍处理投递，由处理函数保证幂等
+			logger.FromContext(ctx).Warn("[webhook] %s 占用去重记录失败，跳过去重: %v", name, err)
+			dedupKey = ""
+		case record != nil && record.Completed:
+			return http.StatusOK, OutcomeDuplicate, nil
+		case record != nil:
+			return http.StatusConflict, OutcomeInProgress, nil
+		}
+	}
+
+	if err := callHandler(ctx, cfg.Handler, body, c.Request.Header); err != nil {
+		if dedupKey != "" {
+			if releaseErr := cfg.DedupStore.Release(ctx, dedupKey); releaseErr != nil {
+				logger.FromContext(ctx).Warn("[webhook] %s 释放去重记录失败: %v", name, releaseErr)
+			}
+		}
+		return http.StatusInternalServerError, OutcomeHandlerError, err
+	}
+	if dedupKey != "" {
+		record := &idempotency.Record{Completed: true, Status: http.StatusOK}
+		if err := cfg.DedupStore.Complete(ctx, dedupKey, record, cfg.DedupTTL); err != nil {
+			logger.FromContext(ctx).Warn("[webhook] %s 保存去重记录失败: %v", name, err)
+		}
+	}
+	return http.StatusOK, OutcomeOK, nil
+}
+
+// callHandler 调用处理函数，panic 转换为错误
+func callHandler(ctx context.Context, handler Handler, body []byte, headers http.Header) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("处理函数 panic: %v", r)
+		}
+	}()
+	return handler(ctx, body, headers)
+}
+
+// checkTimestamp 校验时间戳与 now 的偏差不超过 tolerance，时间戳为 Unix 秒，大于 1e12 时按毫秒处理
+func checkTimestamp(value string, tolerance time.Duration, now time.Time) error {
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidTimestamp, value)
+	}
+	ts := time.Unix(n, 0)
+	if n > 1e12 {
+		ts = time.UnixMilli(n)
+	}
+	if skew := now.Sub(ts).Abs(); skew > tolerance {
+		return fmt.Errorf("%w: 与当前时间相差 %s，超过允许的 %s", ErrInvalidTimestamp, skew.Round(time.Second), tolerance)
+	}
+	return nil
+}
+
+// signedPayload 获取签名内容
+func signedPayload(cfg Config, timestamp string, body []byte) []byte {
+	if cfg.SignedPayload != nil {
+		return cfg.SignedPayload(timestamp, body)
+	}
+	if cfg.TimestampHeader == "" {
+		return body
+	}
+	payload := make([]byte, 0, len(timestamp)+1+len(body))
+	payload = append(payload, timestamp...)
+	payload = append(payload, '.')
+	return append(payload, body...)
+}
+
+// verifySignature 使用常量时间比较校验签名与 expected 是否一致，支持十六进制和 Base64 编码，忽略 sha256= / sha1= 前缀
+func verifySignature(signature string, expected []byte) bool {
+	signature = strings.TrimSpace(signature)
+	for _, prefix := range []string{"sha256=", "sha1="} {
+		if len(signature) > len(prefix) && strings.EqualFold(signature[:len(prefix)], prefix) {
+			signature = signature[len(prefix):]
+			break
+		}
+	}
+	if signature == "" {
+		return false
+	}
+	if decoded, err := hex.DecodeString(signature); err == nil && hmac.Equal(decoded, expected) {
+		return true
+	}
+	decoded, err := base64.StdEncoding.DecodeString(signature)
+	return err == nil && hmac.Equal(decoded, expected)
+}
+
+// Sign 计算 HMAC 签名，用于测试和向下游发送回调
+// 参数：
+//   - algorithm: 签名算法，hmac-sha1 或 hmac-sha256（其他值按 hmac-sha256 处理）
+//   - secret: 签名密钥
+//   - payload: 签名内容
+//
+// 返回：
+//   - []byte: 签名，通常以十六进制编码放入请求头
+func Sign(algorithm, secret string, payload []byte) []byte {
+	newHash := sha256.New
+	if algorithm == AlgorithmHMACSHA1 {
+		newHash = func() hash.Hash { return sha1.New() }
+	}
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// dedupKeyOf 获取去重键：回调名称和校验通过的签名（HMAC 值，十六进制编码）
+// 使用服务端计算的签名而不是请求头中的原始值，避免改变签名的编码、大小写或前缀后绕过去重重放同一投递
+func dedupKeyOf(name string, mac []byte) string {
+	return "webhook:" + name + ":" + hex.EncodeToString(mac)
+}
+
+// logDelivery 记录一次投递的名称、追踪 ID、结果和耗时，处理失败为 error 级别，校验不通过为 warn 级别
+func logDelivery(c *gin.Context, name string, status int, outcome string, err error, elapsed time.Duration) {
+	fields := map[string]any{
+		"webhook": name,
+		"traceId": ginContext.GetTraceID(c),
+		"outcome": outcome,
+		"status":  status,
+		"latency": elapsed.Round(time.Microsecond).String(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	entry := logger.FromContext(c.Request.Context()).With(fields)
+	switch {
+	case status >= http.StatusInternalServerError:
+		entry.Error("[webhook] %s 处理失败", name)
+	case status >= http.StatusBadRequest:
+		entry.Warn("[webhook] %s 拒绝投递", name)
+	default:
+		entry.Info("[webhook] %s 投递完成", name)
+	}
+}
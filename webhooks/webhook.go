@@ -0,0 +1,174 @@
+// Package webhooks 提供第三方回调（Webhook）的接收框架
+// 校验请求体的 HMAC 签名和时间戳（防重放），可按签名去重，并按服务商约定的 HTTP 状态码响应
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zzsen/gin_core/idempotency"
+)
+
+// 签名算法
+const (
+	AlgorithmHMACSHA256 = "hmac-sha256" // HMAC-SHA256（默认）
+	AlgorithmHMACSHA1   = "hmac-sha1"   // HMAC-SHA1
+)
+
+// 默认配置
+const (
+	defaultSignatureHeader = "X-Signature"    // 默认签名请求头
+	defaultTolerance       = 5 * time.Minute  // 默认时间戳允许的偏差
+	defaultDedupTTL        = 24 * time.Hour   // 默认去重记录的保留时间
+	defaultMaxBodyBytes    = 1 << 20          // 默认请求体大小上限（1MB）
+	dedupCleanupInterval   = 10 * time.Minute // 内存去重存储清理过期记录的间隔
+)
+
+// Handler 回调处理函数
+// 返回错误时响应 500，服务商按其重试策略重新投递；处理函数应是幂等的
+// 参数：
+//   - ctx: 请求上下文，携带追踪 ID 和 RequestContext
+//   - payload: 原始请求体，已通过签名校验
+//   - headers: 请求头
+type Handler func(ctx context.Context, payload []byte, headers http.Header) error
+
+// Config 回调配置
+type Config struct {
+	// Path 路由路径，如 /webhooks/stripe，只接收 POST 请求，受 service.routePrefix 影响
+	Path string
+	// Secret 签名密钥
+	Secret string
+	// SignatureHeader 签名请求头，默认 X-Signature
+	// 签名为十六进制或 Base64 编码，可带 sha256= / sha1= 前缀（如 GitHub 的 X-Hub-Signature-256）
+	SignatureHeader string
+	// Algorithm 签名算法：hmac-sha256（默认）、hmac-sha1
+	Algorithm string
+	// TimestampHeader 时间戳请求头（Unix 秒或毫秒），为空时不校验时间戳
+	// 设置后签名内容为 "时间戳.请求体"，防止修改时间戳重放旧的请求
+	TimestampHeader string
+	// Tolerance 时间戳与当前时间允许的偏差，默认 5 分钟
+	Tolerance time.Duration
+	// SignedPayload 自定义签名内容，为空时按 TimestampHeader 是否设置使用请求体或 "时间戳.请求体"
+	SignedPayload func(timestamp string, body []byte) []byte
+	// Dedup 是否按签名去重：同一签名的请求处理成功后，再次投递直接响应 200，不调用处理函数
+	Dedup bool
+	// DedupTTL 去重记录的保留时间，默认 24 小时
+	DedupTTL time.Duration
+	// DedupStore 去重记录存储，为空时由框架设置：配置了 Redis 时使用 Redis，否则使用内存
+	DedupStore idempotency.Store
+	// MaxBodyBytes 请求体大小上限（字节），超过时响应 413，默认 1MB
+	MaxBodyBytes int64
+	// Handler 回调处理函数
+	Handler Handler
+}
+
+// Webhook 已注册的回调
+type Webhook struct {
+	Name   string // 回调名称，用于日志和去重键
+	Config Config // 回调配置
+}
+
+// 注册表
+var (
+	mu         sync.Mutex
+	registered []Webhook
+)
+
+// Register 注册回调，由 core.RegisterWebhook 调用
+// 参数：
+//   - name: 回调名称，全局唯一
+//   - cfg: 回调配置
+//
+// 返回：
+//   - error: 名称为空或重复、路径重复、配置有误时返回错误
+func Register(name string, cfg Config) error {
+	if name == "" {
+		return errors.New("webhook 名称不能为空")
+	}
+	if err := cfg.validate(); err != nil {
+		return fmt.Errorf("webhook %s: %w", name, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, w := range registered {
+		if w.Name == name {
+			return fmt.Errorf("webhook %s 已注册", name)
+		}
+		if w.Config.Path == cfg.Path {
+			return fmt.Errorf("webhook %s: 路径 %s 已被 webhook %s 使用", name, cfg.Path, w.Name)
+		}
+	}
+	registered = append(registered, Webhook{Name: name, Config: cfg})
+	return nil
+}
+
+// Registered 获取已注册的回调，按注册顺序返回
+func Registered() []Webhook {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Webhook(nil), registered...)
+}
+
+// validate 校验回调配置
+func (cfg Config) validate() error {
+	if !strings.HasPrefix(cfg.Path, "/") {
+		return fmt.Errorf("路径 %q 必须以 / 开头", cfg.Path)
+	}
+	if cfg.Secret == "" {
+		return errors.New("签名密钥不能为空")
+	}
+	if cfg.Handler == nil {
+		return errors.New("处理函数不能为空")
+	}
+	switch cfg.Algorithm {
+	case "", AlgorithmHMACSHA256, AlgorithmHMACSHA1:
+	default:
+		return fmt.Errorf("不支持的签名算法 %q，可选 %s、%s", cfg.Algorithm, AlgorithmHMACSHA256, AlgorithmHMACSHA1)
+	}
+	if cfg.Tolerance < 0 || cfg.DedupTTL < 0 || cfg.MaxBodyBytes < 0 {
+		return errors.New("tolerance、dedupTTL、maxBodyBytes 不能为负数")
+	}
+	return nil
+}
+
+// withDefaults 返回设置了默认值的配置
+func (cfg Config) withDefaults() Config {
+	if cfg.SignatureHeader == "" {
+		cfg.SignatureHeader = defaultSignatureHeader
+	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = AlgorithmHMACSHA256
+	}
+	if cfg.Tolerance == 0 {
+		cfg.Tolerance = defaultTolerance
+	}
+	if cfg.DedupTTL == 0 {
+		cfg.DedupTTL = defaultDedupTTL
+	}
+	if cfg.MaxBodyBytes == 0 {
+		cfg.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if cfg.Dedup && cfg.DedupStore == nil {
+		cfg.DedupStore = sharedMemoryStore()
+	}
+	return cfg
+}
+
+// 未设置去重存储时共用的内存存储
+var (
+	memoryStoreOnce sync.Once
+	memoryStore     *idempotency.MemoryStore
+)
+
+// sharedMemoryStore 获取共用的内存去重存储，首次调用时创建
+func sharedMemoryStore() *idempotency.MemoryStore {
+	memoryStoreOnce.Do(func() {
+		memoryStore = idempotency.NewMemoryStore(dedupCleanupInterval)
+	})
+	return memoryStore
+}
@@ -0,0 +1,426 @@
+// Package webhooks 回调接收测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含回调接收框架的单元测试，使用内存去重存储，不需要 Redis。
+//
+// 测试覆盖内容：
+// 1. 签名正确时调用处理函数并响应 200；签名错误、缺失或请求体被篡改时响应 400
+// 2. 十六进制 / Base64 编码、sha256= 前缀和 HMAC-SHA1 签名
+// 3. 时间戳超出允许的偏差或格式错误时响应 400，修改时间戳后签名不匹配
+// 4. 开启去重时重复投递（包括改变签名编码、大小写或前缀后的重放）不调用处理函数；处理函数返回错误时响应 500 并允许重新投递；首次投递处理中时响应 409
+// 5. 中间件先通过 ginContext.Get、ShouldBindBodyWith 读取请求体后，处理函数仍收到完整的原始请求体
+// 6. 请求体超过上限时响应 413，每次投递记录日志，注册时校验配置
+//
+// 运行测试：go test -v ./webhooks/...
+// ==================================================
+package webhooks
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/idempotency"
+	"github.com/zzsen/gin_core/logger"
+	ginContext "github.com/zzsen/gin_core/utils/gin_context"
+)
+
+const testSecret = "whsec_test"
+
+// delivery 记录处理函数收到的投递
+type delivery struct {
+	mu       sync.Mutex
+	payloads []string
+	headers  []http.Header
+}
+
+// handler 返回记录投递的处理函数，err 不为空时返回该错误
+func (d *delivery) handler(err *error) Handler {
+	return func(ctx context.Context, payload []byte, headers http.Header) error {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.payloads = append(d.payloads, string(payload))
+		d.headers = append(d.headers, headers)
+		if err != nil {
+			return *err
+		}
+		return nil
+	}
+}
+
+// count 返回处理函数被调用的次数
+func (d *delivery) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.payloads)
+}
+
+// newRouter 创建挂载回调处理函数的路由，middlewares 注册在处理函数之前
+func newRouter(t *testing.T, cfg Config, middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler, err := NewHandler("test", cfg)
+	require.NoError(t, err)
+	router := gin.New()
+	router.Use(middlewares...)
+	router.POST(cfg.Path, handler)
+	return router
+}
+
+// post 发送回调请求
+func post(router *gin.Engine, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// hexSign 计算十六进制编码的 HMAC-SHA256 签名
+func hexSign(payload string) string {
+	return hex.EncodeToString(Sign(AlgorithmHMACSHA256, testSecret, []byte(payload)))
+}
+
+// TestWebhook_Signature 测试签名校验
+//
+// 【功能点】验证签名正确时调用处理函数，签名错误、缺失、请求体被篡改时响应 400 且不调用处理函数
+// 【测试流程】
+//  1. 使用十六进制签名投递，断言响应 200，处理函数收到原始请求体和请求头
+//  2. 使用 sha256= 前缀、Base64 编码的签名投递，断言响应 200
+//  3. 签名错误、签名缺失、请求体与签名不一致时断言响应 400
+//  4. 断言处理函数共被调用 2 次
+func TestWebhook_Signature(t *testing.T) {
+	var d delivery
+	router := newRouter(t, Config{Path: "/hooks/pay", Secret: testSecret, Handler: d.handler(nil)})
+	body := `{"event":"paid","amount":100}`
+
+	w := post(router, "/hooks/pay", body, map[string]string{"X-Signature": hexSign(body), "X-Event": "paid"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, 1, d.count())
+	assert.Equal(t, body, d.payloads[0])
+	assert.Equal(t, "paid", d.headers[0].Get("X-Event"))
+
+	base64Sig := base64.StdEncoding.EncodeToString(Sign(AlgorithmHMACSHA256, testSecret, []byte(body)))
+	w = post(router, "/hooks/pay", body, map[string]string{"X-Signature": "sha256=" + base64Sig})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	for name, headers := range map[string]map[string]string{
+		"签名错误": {"X-Signature": hexSign(body + " ")},
+		"签名缺失": {},
+		"非法编码": {"X-Signature": "sha256=not-a-signature!"},
+	} {
+		w = post(router, "/hooks/pay", body, headers)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+	w = post(router, "/hooks/pay", `{"event":"paid","amount":1000}`, map[string]string{"X-Signature": hexSign(body)})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "请求体被篡改")
+	assert.Equal(t, 2, d.count())
+}
+
+// TestWebhook_SHA1 测试 HMAC-SHA1 签名
+//
+// 【功能点】验证 Algorithm 为 hmac-sha1 时按 SHA1 校验，SHA256 签名不通过
+// 【测试流程】
+//  1. 使用 HMAC-SHA1 签名投递，断言响应 200
+//  2. 使用 HMAC-SHA256 签名投递，断言响应 400
+func TestWebhook_SHA1(t *testing.T) {
+	var d delivery
+	router := newRouter(t, Config{Path: "/hooks/sha1", Secret: testSecret, Algorithm: AlgorithmHMACSHA1, SignatureHeader: "X-Hub-Signature", Handler: d.handler(nil)})
+	body := `{"ref":"main"}`
+
+	sig := "sha1=" + hex.EncodeToString(Sign(AlgorithmHMACSHA1, testSecret, []byte(body)))
+	assert.Equal(t, http.StatusOK, post(router, "/hooks/sha1", body, map[string]string{"X-Hub-Signature": sig}).Code)
+	assert.Equal(t, http.StatusBadRequest, post(router, "/hooks/sha1", body, map[string]string{"X-Hub-Signature": hexSign(body)}).Code)
+	assert.Equal(t, 1, d.count())
+}
+
+// TestWebhook_Timestamp 测试时间戳校验（防重放）
+//
+// 【功能点】验证签名内容为 "时间戳.请求体"，时间戳超出允许的偏差、格式错误或被修改时响应 400
+// 【测试流程】
+//  1. 使用当前时间戳签名投递，断言响应 200；毫秒时间戳同样通过
+//  2. 使用 10 分钟前的时间戳（允许偏差 5 分钟）正确签名后投递，断言响应 400
+//  3. 时间戳缺失、格式错误时断言响应 400
+//  4. 用旧签名配合新时间戳投递，断言签名不匹配响应 400
+func TestWebhook_Timestamp(t *testing.T) {
+	var d delivery
+	router := newRouter(t, Config{
+		Path: "/hooks/ts", Secret: testSecret, TimestampHeader: "X-Timestamp", Tolerance: 5 * time.Minute, Handler: d.handler(nil),
+	})
+	body := `{"event":"refund"}`
+	signAt := func(ts string) map[string]string {
+		return map[string]string{"X-Timestamp": ts, "X-Signature": hexSign(ts + "." + body)}
+	}
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	assert.Equal(t, http.StatusOK, post(router, "/hooks/ts", body, signAt(now)).Code)
+	nowMillis := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	assert.Equal(t, http.StatusOK, post(router, "/hooks/ts", body, signAt(nowMillis)).Code)
+
+	stale := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	assert.Equal(t, http.StatusBadRequest, post(router, "/hooks/ts", body, signAt(stale)).Code)
+	future := strconv.FormatInt(time.Now().Add(10*time.Minute).Unix(), 10)
+	assert.Equal(t, http.StatusBadRequest, post(router, "/hooks/ts", body, signAt(future)).Code)
+	assert.Equal(t, http.StatusBadRequest, post(router, "/hooks/ts", body, map[string]string{"X-Signature": hexSign("." + body)}).Code)
+	assert.Equal(t, http.StatusBadRequest, post(router, "/hooks/ts", body, signAt("yesterday")).Code)
+
+	replay := signAt(stale)
+	replay["X-Timestamp"] = now
+	assert.Equal(t, http.StatusBadRequest, post(router, "/hooks/ts", body, replay).Code)
+	assert.Equal(t, 2, d.count())
+}
+
+// TestWebhook_Dedup 测试重复投递检测
+//
+// 【功能点】验证开启去重时同一签名处理成功后不再调用处理函数，处理失败时允许服务商重新投递
+// 【测试流程】
+//  1. 处理函数第一次返回错误，断言响应 500
+//  2. 重新投递同一请求，处理函数成功，断言响应 200
+//  3. 再次投递同一请求，断言响应 200 且处理函数共调用 2 次
+//  4. 投递另一个请求，断言处理函数被调用
+func TestWebhook_Dedup(t *testing.T) {
+	var d delivery
+	handlerErr := errors.New("db unavailable")
+	var failing atomic.Bool
+	failing.Store(true)
+	handler := d.handler(nil)
+	router := newRouter(t, Config{
+		Path: "/hooks/dedup", Secret: testSecret, Dedup: true, DedupStore: idempotency.NewMemoryStore(time.Minute),
+		Handler: func(ctx context.Context, payload []byte, headers http.Header) error {
+			_ = handler(ctx, payload, headers)
+			if failing.Swap(false) {
+				return handlerErr
+			}
+			return nil
+		},
+	})
+	body := `{"id":"evt_1"}`
+	headers := map[string]string{"X-Signature": hexSign(body)}
+
+	assert.Equal(t, http.StatusInternalServerError, post(router, "/hooks/dedup", body, headers).Code)
+	assert.Equal(t, http.StatusOK, post(router, "/hooks/dedup", body, headers).Code)
+	assert.Equal(t, http.StatusOK, post(router, "/hooks/dedup", body, headers).Code)
+	assert.Equal(t, 2, d.count())
+
+	other := `{"id":"evt_2"}`
+	assert.Equal(t, http.StatusOK, post(router, "/hooks/dedup", other, map[string]string{"X-Signature": hexSign(other)}).Code)
+	assert.Equal(t, 3, d.count())
+}
+
+// TestWebhook_DedupReencodedSignature 测试改变签名编码后的重放
+//
+// 【功能点】验证同一投递的签名改为大写十六进制、Base64 编码或带 sha256= 前缀、首尾空白后重放时，仍按重复投递处理
+// 【测试流程】
+//  1. 使用小写十六进制签名投递，断言响应 200、处理函数调用 1 次
+//  2. 使用时间戳签名内容，依次以重新编码的签名重放，断言均响应 200、投递结果为 duplicate，处理函数仍只调用 1 次
+func TestWebhook_DedupReencodedSignature(t *testing.T) {
+	var d delivery
+	router := newRouter(t, Config{
+		Path: "/hooks/replay", Secret: testSecret, TimestampHeader: "X-Timestamp",
+		Dedup: true, DedupStore: idempotency.NewMemoryStore(time.Minute), Handler: d.handler(nil),
+	})
+	body := `{"id":"evt_1"}`
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := Sign(AlgorithmHMACSHA256, testSecret, []byte(ts+"."+body))
+	lower := hex.EncodeToString(mac)
+	require.Equal(t, http.StatusOK, post(router, "/hooks/replay", body, map[string]string{"X-Timestamp": ts, "X-Signature": lower}).Code)
+
+	hook := logtest.NewLocal(logger.Logger)
+	defer hook.Reset()
+	for _, signature := range []string{
+		strings.ToUpper(lower),
+		base64.StdEncoding.EncodeToString(mac),
+		"sha256=" + lower,
+		"  SHA256=" + strings.ToUpper(lower) + " ",
+	} {
+		w := post(router, "/hooks/replay", body, map[string]string{"X-Timestamp": ts, "X-Signature": signature})
+		assert.Equal(t, http.StatusOK, w.Code, signature)
+		entry := hook.LastEntry()
+		require.NotNil(t, entry)
+		assert.Equal(t, OutcomeDuplicate, entry.Data["outcome"], signature)
+	}
+	assert.Equal(t, 1, d.count())
+}
+
+// TestWebhook_DedupInProgress 测试首次投递仍在处理时的重复投递
+//
+// 【功能点】验证首次投递处理中时，重复投递响应 409（服务商稍后重试），不调用处理函数
+// 【测试流程】
+//  1. 处理函数阻塞，异步发送首次投递
+//  2. 处理函数开始执行后发送重复投递，断言响应 409
+//  3. 放行处理函数，断言首次投递响应 200，处理函数只调用 1 次
+func TestWebhook_DedupInProgress(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls atomic.Int32
+	router := newRouter(t, Config{
+		Path: "/hooks/slow", Secret: testSecret, Dedup: true, DedupStore: idempotency.NewMemoryStore(time.Minute),
+		Handler: func(ctx context.Context, payload []byte, headers http.Header) error {
+			calls.Add(1)
+			close(started)
+			<-release
+			return nil
+		},
+	})
+	body := `{"id":"evt_slow"}`
+	headers := map[string]string{"X-Signature": hexSign(body)}
+
+	first := make(chan int)
+	go func() { first <- post(router, "/hooks/slow", body, headers).Code }()
+	<-started
+	assert.Equal(t, http.StatusConflict, post(router, "/hooks/slow", body, headers).Code)
+	close(release)
+	assert.Equal(t, http.StatusOK, <-first)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+// TestWebhook_RawBodyWithMiddlewares 测试其他中间件读取请求体后的原始请求体完整性
+//
+// 【功能点】验证中间件先通过 ginContext.Get、ShouldBindBodyWith 读取请求体后，签名校验和处理函数使用的仍是完整的原始字节
+// 【测试流程】
+//  1. 注册通过 ginContext.Get 读取 event 字段、通过 ShouldBindBodyWith 绑定请求体的中间件
+//  2. 使用包含空格和换行、字段顺序非规范的请求体投递
+//  3. 断言中间件读取到字段值，响应 200，处理函数收到的请求体与发送的字节完全一致
+func TestWebhook_RawBodyWithMiddlewares(t *testing.T) {
+	var d delivery
+	var event, bound string
+	readByGet := func(c *gin.Context) {
+		event = ginContext.Get(c, "event")
+		c.Next()
+	}
+	readByBind := func(c *gin.Context) {
+		var payload struct {
+			ID string `json:"id"`
+		}
+		_ = c.ShouldBindBodyWith(&payload, binding.JSON)
+		bound = payload.ID
+		c.Next()
+	}
+	router := newRouter(t, Config{Path: "/hooks/raw", Secret: testSecret, Handler: d.handler(nil)}, readByGet, readByBind)
+	body := "{\n  \"id\": \"evt_9\",  \"event\" : \"paid\",\"amount\":1.50\n}"
+
+	w := post(router, "/hooks/raw", body, map[string]string{"X-Signature": hexSign(body)})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "paid", event)
+	assert.Equal(t, "evt_9", bound)
+	require.Equal(t, 1, d.count())
+	assert.Equal(t, body, d.payloads[0])
+}
+
+// TestWebhook_BodyTooLarge 测试请求体大小上限
+//
+// 【功能点】验证请求体超过 MaxBodyBytes 时响应 413 且不调用处理函数，包括中间件已读取请求体的情况
+// 【测试流程】
+//  1. 上限 16 字节，投递 32 字节的正确签名请求，断言响应 413
+//  2. 中间件先通过 ShouldBindBodyWith 读取请求体后投递，断言同样响应 413
+func TestWebhook_BodyTooLarge(t *testing.T) {
+	var d delivery
+	cfg := Config{Path: "/hooks/large", Secret: testSecret, MaxBodyBytes: 16, Handler: d.handler(nil)}
+	body := `{"data":"0123456789abcdefghij"}`
+	headers := map[string]string{"X-Signature": hexSign(body)}
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post(newRouter(t, cfg), "/hooks/large", body, headers).Code)
+	preRead := func(c *gin.Context) {
+		var v map[string]any
+		_ = c.ShouldBindBodyWith(&v, binding.JSON)
+	}
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post(newRouter(t, cfg, preRead), "/hooks/large", body, headers).Code)
+	assert.Equal(t, 0, d.count())
+}
+
+// TestWebhook_Log 测试投递日志
+//
+// 【功能点】验证每次投递记录回调名称、追踪 ID、结果和状态码，处理失败为 error 级别
+// 【测试流程】
+//  1. 设置追踪 ID 的中间件，处理函数 panic
+//  2. 断言响应 500，日志级别为 error，字段包含 webhook、traceId、outcome=handler_error、status=500
+//  3. 签名错误时断言 warn 级别日志，outcome=invalid_signature
+func TestWebhook_Log(t *testing.T) {
+	hook := logtest.NewLocal(logger.Logger)
+	defer hook.Reset()
+	setTrace := func(c *gin.Context) {
+		ginContext.SetTraceID(c, "trace-webhook-1")
+		c.Next()
+	}
+	router := newRouter(t, Config{
+		Path: "/hooks/log", Secret: testSecret,
+		Handler: func(ctx context.Context, payload []byte, headers http.Header) error { panic("boom") },
+	}, setTrace)
+	body := `{}`
+
+	assert.Equal(t, http.StatusInternalServerError, post(router, "/hooks/log", body, map[string]string{"X-Signature": hexSign(body)}).Code)
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.ErrorLevel, entry.Level)
+	assert.Equal(t, "test", entry.Data["webhook"])
+	assert.Equal(t, "trace-webhook-1", entry.Data["traceId"])
+	assert.Equal(t, OutcomeHandlerError, entry.Data["outcome"])
+	assert.Equal(t, http.StatusInternalServerError, entry.Data["status"])
+	assert.Contains(t, entry.Data["error"], "boom")
+
+	assert.Equal(t, http.StatusBadRequest, post(router, "/hooks/log", body, map[string]string{"X-Signature": "00"}).Code)
+	entry = hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, OutcomeInvalidSignature, entry.Data["outcome"])
+}
+
+// TestRegister 测试注册回调
+//
+// 【功能点】验证注册时校验名称、路径和配置，Registered 按注册顺序返回
+// 【测试流程】
+//  1. 注册两个回调，断言 Registered 按顺序返回
+//  2. 断言名称重复、路径重复、名称为空、缺少密钥或处理函数、路径格式错误、算法不支持时返回错误
+func TestRegister(t *testing.T) {
+	mu.Lock()
+	original := registered
+	registered = nil
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		registered = original
+		mu.Unlock()
+	})
+
+	noop := func(context.Context, []byte, http.Header) error { return nil }
+	require.NoError(t, Register("stripe", Config{Path: "/webhooks/stripe", Secret: "s", Handler: noop}))
+	require.NoError(t, Register("github", Config{Path: "/webhooks/github", Secret: "s", Algorithm: AlgorithmHMACSHA1, Handler: noop}))
+	list := Registered()
+	require.Len(t, list, 2)
+	assert.Equal(t, "stripe", list[0].Name)
+	assert.Equal(t, "github", list[1].Name)
+
+	invalid := map[string]struct {
+		name string
+		cfg  Config
+	}{
+		"名称重复":   {"stripe", Config{Path: "/webhooks/other", Secret: "s", Handler: noop}},
+		"路径重复":   {"other", Config{Path: "/webhooks/stripe", Secret: "s", Handler: noop}},
+		"名称为空":   {"", Config{Path: "/webhooks/a", Secret: "s", Handler: noop}},
+		"缺少密钥":   {"a", Config{Path: "/webhooks/a", Handler: noop}},
+		"缺少处理函数": {"a", Config{Path: "/webhooks/a", Secret: "s"}},
+		"路径格式错误": {"a", Config{Path: "webhooks/a", Secret: "s", Handler: noop}},
+		"算法不支持":  {"a", Config{Path: "/webhooks/a", Secret: "s", Algorithm: "md5", Handler: noop}},
+	}
+	for desc, tc := range invalid {
+		assert.Error(t, Register(tc.name, tc.cfg), desc)
+	}
+	assert.Len(t, Registered(), 2)
+}
@@ -0,0 +1,145 @@
+// Package ws 提供 WebSocket 连接升级与连接管理（Hub）功能
+// 本文件定义 WebSocket 处理器的配置与配置选项
+package ws
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/middleware"
+)
+
+// Config WebSocket 处理器配置
+type Config struct {
+	// UserIDFunc 从请求中提取用户 ID，返回错误时拒绝升级并响应 401
+	// 用户 ID 为空的连接为匿名连接，只能收到 Broadcast 消息
+	// 默认值: 所有连接均为匿名连接
+	UserIDFunc func(c *gin.Context) (string, error)
+
+	// CheckOrigin 校验请求来源，返回 false 时拒绝升级并响应 403
+	// 默认值: 开启 CORS 时按 cors.allowOrigins 校验，否则只允许同源；未携带 Origin 的请求（非浏览器客户端）直接放行
+	CheckOrigin func(r *http.Request) bool
+
+	// OnMessage 收到客户端消息的回调，在连接的读协程中同步调用
+	OnMessage func(conn *Conn, msg []byte)
+
+	// PingInterval 服务端发送 ping 的间隔，需小于 PongWait
+	// 默认值: 54s（PongWait 的 9/10）
+	PingInterval time.Duration
+
+	// PongWait 等待客户端消息（含 pong）的超时时间，超时后断开连接
+	// 默认值: 60s
+	PongWait time.Duration
+
+	// WriteWait 单条消息的写超时时间，超时后断开连接
+	// 默认值: 10s
+	WriteWait time.Duration
+
+	// ReadLimit 单条客户端消息的最大字节数，超过后断开连接
+	// 默认值: 64KB
+	ReadLimit int64
+
+	// SendQueueSize 每个连接的发送队列长度，队列写满的慢客户端会被断开
+	// 默认值: 256
+	SendQueueSize int
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		UserIDFunc:    func(*gin.Context) (string, error) { return "", nil },
+		CheckOrigin:   defaultCheckOrigin,
+		PingInterval:  54 * time.Second,
+		PongWait:      60 * time.Second,
+		WriteWait:     10 * time.Second,
+		ReadLimit:     64 * 1024,
+		SendQueueSize: 256,
+	}
+}
+
+// Option 配置选项函数
+type Option func(*Config)
+
+// WithUserIDFunc 设置用户 ID 提取函数
+func WithUserIDFunc(fn func(c *gin.Context) (string, error)) Option {
+	return func(c *Config) {
+		c.UserIDFunc = fn
+	}
+}
+
+// WithCheckOrigin 设置请求来源校验函数
+func WithCheckOrigin(fn func(r *http.Request) bool) Option {
+	return func(c *Config) {
+		c.CheckOrigin = fn
+	}
+}
+
+// WithOnMessage 设置收到客户端消息的回调
+func WithOnMessage(fn func(conn *Conn, msg []byte)) Option {
+	return func(c *Config) {
+		c.OnMessage = fn
+	}
+}
+
+// WithPongWait 设置等待客户端消息的超时时间
+func WithPongWait(wait time.Duration) Option {
+	return func(c *Config) {
+		c.PongWait = wait
+		// 自动调整 ping 间隔为 PongWait 的 9/10
+		if c.PingInterval == 0 || c.PingInterval >= wait {
+			c.PingInterval = wait * 9 / 10
+		}
+	}
+}
+
+// WithPingInterval 设置服务端发送 ping 的间隔
+func WithPingInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.PingInterval = interval
+	}
+}
+
+// WithWriteWait 设置单条消息的写超时时间
+func WithWriteWait(wait time.Duration) Option {
+	return func(c *Config) {
+		c.WriteWait = wait
+	}
+}
+
+// WithReadLimit 设置单条客户端消息的最大字节数
+func WithReadLimit(limit int64) Option {
+	return func(c *Config) {
+		c.ReadLimit = limit
+	}
+}
+
+// WithSendQueueSize 设置每个连接的发送队列长度
+func WithSendQueueSize(size int) Option {
+	return func(c *Config) {
+		c.SendQueueSize = size
+	}
+}
+
+// defaultCheckOrigin 默认的请求来源校验
+// 开启 CORS 时复用 cors.allowOrigins 配置，否则只允许与 Host 相同的来源
+func defaultCheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	cors := app.BaseConfig.CORS
+	if cors.Enabled {
+		return middleware.IsOriginAllowed(origin, cors.GetAllowOrigins())
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
@@ -0,0 +1,169 @@
+// Package ws 提供 WebSocket 连接升级与连接管理（Hub）功能
+// 本文件实现单个 WebSocket 连接的读写协程、心跳与发送队列
+package ws
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zzsen/gin_core/logger"
+)
+
+var (
+	// ErrConnClosed 连接已关闭
+	ErrConnClosed = errors.New("websocket 连接已关闭")
+	// ErrSendQueueFull 发送队列已满，连接已被断开
+	ErrSendQueueFull = errors.New("websocket 发送队列已满")
+)
+
+// Conn WebSocket 连接
+// 每个连接有一个读协程和一个写协程，所有消息经由有界发送队列写出，
+// 发送队列写满（客户端读取过慢）时断开连接，避免拖慢 Hub 的广播。
+type Conn struct {
+	hub    *Hub
+	ws     *websocket.Conn
+	cfg    *Config
+	userID string
+
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newConn 创建连接
+func newConn(hub *Hub, wsConn *websocket.Conn, cfg *Config, userID string) *Conn {
+	return &Conn{
+		hub:    hub,
+		ws:     wsConn,
+		cfg:    cfg,
+		userID: userID,
+		send:   make(chan []byte, cfg.SendQueueSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// UserID 获取连接所属的用户 ID，匿名连接返回空字符串
+func (c *Conn) UserID() string {
+	return c.userID
+}
+
+// RemoteAddr 获取客户端地址
+func (c *Conn) RemoteAddr() string {
+	return c.ws.RemoteAddr().String()
+}
+
+// Send 向当前连接发送文本消息
+// 消息写入发送队列后立即返回，不等待写出
+// 参数：
+//   - msg: 消息内容
+//
+// 返回：
+//   - error: 连接已关闭返回 ErrConnClosed；发送队列已满返回 ErrSendQueueFull，连接随后被断开
+func (c *Conn) Send(msg []byte) error {
+	select {
+	case <-c.done:
+		return ErrConnClosed
+	default:
+	}
+
+	select {
+	case c.send <- msg:
+		return nil
+	default:
+		logger.Warn("[websocket] 发送队列已满，断开慢客户端, userId: %s, remote: %s", c.userID, c.RemoteAddr())
+		// 写协程可能阻塞在慢客户端上，异步关闭，避免阻塞调用方
+		go c.closeWith(websocket.ClosePolicyViolation, "send queue full")
+		return ErrSendQueueFull
+	}
+}
+
+// Close 关闭连接，重复调用无效
+func (c *Conn) Close() {
+	c.closeWith(websocket.CloseNormalClosure, "")
+}
+
+// closeWith 发送关闭帧后关闭连接，并从 Hub 中移除
+func (c *Conn) closeWith(code int, text string) {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(c.cfg.WriteWait))
+		_ = c.ws.Close()
+		c.hub.unregister(c)
+	})
+}
+
+// closed 连接是否已关闭
+func (c *Conn) closed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// readPump 读协程：读取客户端消息，并通过读超时检测心跳
+// 客户端在 PongWait 内没有任何消息（含 pong）时断开连接
+func (c *Conn) readPump() {
+	defer c.Close()
+
+	c.ws.SetReadLimit(c.cfg.ReadLimit)
+	_ = c.ws.SetReadDeadline(time.Now().Add(c.cfg.PongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(c.cfg.PongWait))
+	})
+
+	for {
+		_, msg, err := c.ws.ReadMessage()
+		if err != nil {
+			if !c.closed() && websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logger.Warn("[websocket] 读取消息失败, userId: %s, remote: %s, err: %v", c.userID, c.RemoteAddr(), err)
+			}
+			return
+		}
+		_ = c.ws.SetReadDeadline(time.Now().Add(c.cfg.PongWait))
+		if c.cfg.OnMessage != nil {
+			c.handleMessage(msg)
+		}
+	}
+}
+
+// handleMessage 调用消息回调，回调 panic 不影响连接
+func (c *Conn) handleMessage(msg []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("[websocket] 消息回调执行异常, userId: %s, err: %v", c.userID, r)
+		}
+	}()
+	c.cfg.OnMessage(c, msg)
+}
+
+// writePump 写协程：写出发送队列中的消息，并定时发送 ping
+// 单条消息超过 WriteWait 未写出时断开连接
+func (c *Conn) writePump() {
+	ticker := time.NewTicker(c.cfg.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.send:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(c.cfg.WriteWait))
+			if err := c.ws.WriteMessage(websocket.TextMessage, msg); err != nil {
+				if !c.closed() {
+					logger.Warn("[websocket] 写出消息失败, userId: %s, remote: %s, err: %v", c.userID, c.RemoteAddr(), err)
+				}
+				c.Close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.cfg.WriteWait)); err != nil {
+				c.Close()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Package ws 提供 WebSocket 连接升级与连接管理（Hub）功能
+// 本文件实现将 HTTP 请求升级为 WebSocket 连接的处理器
+package ws
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/zzsen/gin_core/logger"
+	"github.com/zzsen/gin_core/model/response"
+)
+
+// Handler 创建 WebSocket 处理器
+// 处理流程：
+//  1. Hub 已关闭时响应 503
+//  2. 通过 UserIDFunc 提取用户 ID，失败时响应 401
+//  3. 校验请求来源（默认复用 cors.allowOrigins 配置），不通过时响应 403
+//  4. 升级连接并加入 Hub，启动写协程，当前协程作为读协程直到连接断开
+//
+// 参数：
+//   - hub: 连接管理中心
+//   - opts: 配置选项
+//
+// 返回：
+//   - gin.HandlerFunc: 处理器，需注册在 GET 路由上
+func Handler(hub *Hub, opts ...Option) gin.HandlerFunc {
+	cfg := DefaultConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	upgrader := websocket.Upgrader{
+		CheckOrigin: cfg.CheckOrigin,
+	}
+
+	return func(c *gin.Context) {
+		if hub.isClosed() {
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+
+		userID, err := cfg.UserIDFunc(c)
+		if err != nil {
+			// 握手失败时客户端只能看到 HTTP 状态码，不受 service.useHTTPStatus 影响，始终返回 401
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Response{
+				Code: response.ResponseUnauthorized.GetCode(),
+				Data: map[string]any{},
+				Msg:  err.Error(),
+			})
+			return
+		}
+
+		// 升级失败时 upgrader 已写入错误响应（如来源校验不通过返回 403）
+		wsConn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			logger.Warn("[websocket] 连接升级失败, remote: %s, origin: %s, err: %v", c.ClientIP(), c.Request.Header.Get("Origin"), err)
+			c.Abort()
+			return
+		}
+
+		conn := newConn(hub, wsConn, cfg, userID)
+		if err := hub.register(conn); err != nil {
+			conn.closeWith(websocket.CloseGoingAway, "server shutdown")
+			return
+		}
+
+		go conn.writePump()
+		conn.readPump()
+	}
+}
@@ -0,0 +1,254 @@
+// Package ws 提供 WebSocket 连接升级与连接管理（Hub）功能
+// 本文件实现连接管理中心：按用户 ID 管理连接、广播与定向推送、优雅关闭
+package ws
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/core/lifecycle"
+	"github.com/zzsen/gin_core/logger"
+)
+
+var (
+	// ErrHubClosed Hub 已关闭
+	ErrHubClosed = errors.New("websocket hub 已关闭")
+	// ErrUserOffline 用户没有在线连接
+	ErrUserOffline = errors.New("websocket 用户不在线")
+)
+
+// Hub WebSocket 连接管理中心
+// 管理所有通过 Handler 建立的连接，同一用户可以有多个连接（如多个标签页），
+// 推送消息只写入各连接的发送队列，不会因个别慢客户端阻塞。
+//
+// 创建时自动注册 AppBeforeShutdown 钩子，框架优雅关闭时调用 Shutdown 关闭所有连接。
+type Hub struct {
+	mu     sync.RWMutex
+	conns  map[*Conn]struct{}
+	users  map[string]map[*Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+
+	hooksMu      sync.RWMutex
+	onConnect    []func(conn *Conn)
+	onDisconnect []func(conn *Conn)
+}
+
+// NewHub 创建连接管理中心
+//
+// 使用示例：
+//
+//	hub := ws.NewHub()
+//	hub.OnConnect(func(conn *ws.Conn) {
+//	    logger.Info("用户上线: %s", conn.UserID())
+//	})
+//	router.GET("/ws", ws.Handler(hub, ws.WithUserIDFunc(func(c *gin.Context) (string, error) {
+//	    return auth.UserIDFromToken(c.Query("token"))
+//	})))
+//
+// 返回：
+//   - *Hub: 连接管理中心
+func NewHub() *Hub {
+	h := &Hub{
+		conns: make(map[*Conn]struct{}),
+		users: make(map[string]map[*Conn]struct{}),
+	}
+	lifecycle.RegisterAppHook(lifecycle.AppHook{
+		Phase: lifecycle.AppBeforeShutdown,
+		Name:  "websocket-hub",
+		Fn: func(ctx context.Context) error {
+			timeout := time.Duration(app.BaseConfig.Service.GetShutdownTimeout()) * time.Second
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return h.Shutdown(ctx)
+		},
+	})
+	return h
+}
+
+// OnConnect 注册连接建立回调，在连接加入 Hub 后调用
+func (h *Hub) OnConnect(fn func(conn *Conn)) {
+	h.hooksMu.Lock()
+	defer h.hooksMu.Unlock()
+	h.onConnect = append(h.onConnect, fn)
+}
+
+// OnDisconnect 注册连接断开回调，在连接从 Hub 移除后调用
+func (h *Hub) OnDisconnect(fn func(conn *Conn)) {
+	h.hooksMu.Lock()
+	defer h.hooksMu.Unlock()
+	h.onDisconnect = append(h.onDisconnect, fn)
+}
+
+// Broadcast 向所有连接（含匿名连接）发送文本消息
+// 发送队列已满的连接会被断开，不影响其他连接
+func (h *Hub) Broadcast(msg []byte) {
+	for _, conn := range h.snapshot("") {
+		_ = conn.Send(msg)
+	}
+}
+
+// SendTo 向指定用户的所有连接发送文本消息
+// 参数：
+//   - userID: 用户 ID
+//   - msg: 消息内容
+//
+// 返回：
+//   - error: 用户没有在线连接返回 ErrUserOffline；所有连接均发送失败时返回最后一个错误
+func (h *Hub) SendTo(userID string, msg []byte) error {
+	conns := h.snapshot(userID)
+	if userID == "" || len(conns) == 0 {
+		return ErrUserOffline
+	}
+
+	var lastErr error
+	sent := false
+	for _, conn := range conns {
+		if err := conn.Send(msg); err != nil {
+			lastErr = err
+			continue
+		}
+		sent = true
+	}
+	if sent {
+		return nil
+	}
+	return lastErr
+}
+
+// Count 获取当前连接数
+func (h *Hub) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+// IsOnline 用户是否有在线连接
+func (h *Hub) IsOnline(userID string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.users[userID]) > 0
+}
+
+// Shutdown 关闭 Hub：拒绝新连接，向所有连接发送关闭帧（1001 Going Away）后断开，
+// 并等待所有连接的读写协程退出
+// 参数：
+//   - ctx: 等待超时控制
+//
+// 返回：
+//   - error: 等待超时返回 ctx.Err()
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+
+	conns := h.snapshot("")
+	if len(conns) > 0 {
+		logger.Info("[websocket] 正在关闭 %d 个连接", len(conns))
+	}
+	for _, conn := range conns {
+		go conn.closeWith(websocket.CloseGoingAway, "server shutdown")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// snapshot 获取连接快照，userID 为空时返回所有连接
+// 推送时遍历快照，避免在持有锁时写入发送队列
+func (h *Hub) snapshot(userID string) []*Conn {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	set := h.conns
+	if userID != "" {
+		set = h.users[userID]
+	}
+	conns := make([]*Conn, 0, len(set))
+	for conn := range set {
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+// register 将连接加入 Hub，Hub 已关闭时返回 ErrHubClosed
+func (h *Hub) register(conn *Conn) error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return ErrHubClosed
+	}
+	h.conns[conn] = struct{}{}
+	if conn.userID != "" {
+		if h.users[conn.userID] == nil {
+			h.users[conn.userID] = make(map[*Conn]struct{})
+		}
+		h.users[conn.userID][conn] = struct{}{}
+	}
+	h.wg.Add(1)
+	h.mu.Unlock()
+
+	h.fireHooks(true, conn)
+	return nil
+}
+
+// unregister 将连接从 Hub 移除，重复调用无效
+func (h *Hub) unregister(conn *Conn) {
+	h.mu.Lock()
+	if _, ok := h.conns[conn]; !ok {
+		h.mu.Unlock()
+		return
+	}
+	delete(h.conns, conn)
+	if conns := h.users[conn.userID]; conns != nil {
+		delete(conns, conn)
+		if len(conns) == 0 {
+			delete(h.users, conn.userID)
+		}
+	}
+	h.mu.Unlock()
+
+	h.fireHooks(false, conn)
+	h.wg.Done()
+}
+
+// isClosed Hub 是否已关闭
+func (h *Hub) isClosed() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.closed
+}
+
+// fireHooks 依次调用连接建立（connect 为 true）或断开回调，单个回调 panic 不影响其他回调
+func (h *Hub) fireHooks(connect bool, conn *Conn) {
+	h.hooksMu.RLock()
+	fns := h.onDisconnect
+	if connect {
+		fns = h.onConnect
+	}
+	h.hooksMu.RUnlock()
+
+	for _, fn := range fns {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("[websocket] 连接回调执行异常, userId: %s, err: %v", conn.userID, r)
+				}
+			}()
+			fn(conn)
+		}()
+	}
+}
@@ -0,0 +1,222 @@
+// Package ws WebSocket 测试
+//
+// ==================== 测试说明 ====================
+// 本文件包含 WebSocket 处理器与 Hub 的单元测试，使用 httptest 服务和 gorilla/websocket 客户端，不需要外部依赖。
+//
+// 测试覆盖内容：
+// 1. Broadcast 向所有连接（含匿名连接）推送消息
+// 2. SendTo 只向指定用户的所有连接推送消息，用户不在线返回 ErrUserOffline
+// 3. 停止读取的慢客户端被断开，且不阻塞 Hub 推送
+// 4. OnConnect / OnDisconnect 回调与 Shutdown 关闭所有连接、拒绝新连接
+// 5. 用户 ID 提取失败返回 401，来源不在 cors.allowOrigins 中返回 403
+//
+// 运行测试：go test -v ./ws/...
+// ==================================================
+package ws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zzsen/gin_core/app"
+	"github.com/zzsen/gin_core/model/config"
+)
+
+// startServer 启动注册了 WebSocket 处理器的测试服务，用户 ID 取自查询参数 user
+func startServer(t *testing.T, hub *Hub, opts ...Option) string {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	opts = append([]Option{WithUserIDFunc(func(c *gin.Context) (string, error) {
+		return c.Query("user"), nil
+	})}, opts...)
+	router.GET("/ws", Handler(hub, opts...))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(func() {
+		_ = hub.Shutdown(context.Background())
+		server.Close()
+	})
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+// dial 以指定用户建立连接，并等待连接加入 Hub
+func dial(t *testing.T, hub *Hub, url, user string) *websocket.Conn {
+	before := hub.Count()
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?user="+user, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	require.Eventually(t, func() bool { return hub.Count() > before }, time.Second, 5*time.Millisecond)
+	return conn
+}
+
+// readText 读取一条消息，超时返回错误
+func readText(conn *websocket.Conn, timeout time.Duration) (string, error) {
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	_, msg, err := conn.ReadMessage()
+	return string(msg), err
+}
+
+// TestHub_Broadcast 测试广播
+//
+// 【功能点】验证 Broadcast 将消息推送给所有连接，包括匿名连接
+// 【测试流程】
+//  1. 建立 alice、bob 和匿名三个连接
+//  2. 广播一条消息
+//  3. 断言三个客户端都收到该消息
+func TestHub_Broadcast(t *testing.T) {
+	hub := NewHub()
+	url := startServer(t, hub)
+	clients := []*websocket.Conn{dial(t, hub, url, "alice"), dial(t, hub, url, "bob"), dial(t, hub, url, "")}
+
+	hub.Broadcast([]byte("系统维护通知"))
+
+	for _, client := range clients {
+		msg, err := readText(client, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "系统维护通知", msg)
+	}
+}
+
+// TestHub_SendTo 测试定向推送
+//
+// 【功能点】验证 SendTo 推送给指定用户的所有连接，其他用户收不到；用户不在线时返回 ErrUserOffline
+// 【测试流程】
+//  1. alice 建立两个连接，bob 建立一个连接
+//  2. 向 alice 推送消息，断言 alice 的两个连接都收到，bob 读取超时
+//  3. 向不在线用户和空用户 ID 推送，断言返回 ErrUserOffline
+func TestHub_SendTo(t *testing.T) {
+	hub := NewHub()
+	url := startServer(t, hub)
+	alice1 := dial(t, hub, url, "alice")
+	alice2 := dial(t, hub, url, "alice")
+	bob := dial(t, hub, url, "bob")
+	assert.True(t, hub.IsOnline("alice"))
+
+	require.NoError(t, hub.SendTo("alice", []byte("你有一条新订单")))
+
+	for _, client := range []*websocket.Conn{alice1, alice2} {
+		msg, err := readText(client, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "你有一条新订单", msg)
+	}
+	_, err := readText(bob, 100*time.Millisecond)
+	assert.Error(t, err, "bob 不应收到消息")
+
+	assert.ErrorIs(t, hub.SendTo("carol", []byte("hi")), ErrUserOffline)
+	assert.ErrorIs(t, hub.SendTo("", []byte("hi")), ErrUserOffline)
+}
+
+// TestHub_SlowClientDropped 测试慢客户端断开
+//
+// 【功能点】验证停止读取的客户端在发送队列写满后被断开，推送调用不被阻塞，其他客户端不受影响
+// 【测试流程】
+//  1. 发送队列长度设为 2，写超时设为 200ms
+//  2. slow 客户端连接后不再读取，持续向其推送 64KB 消息，直到返回错误
+//  3. 断言每次推送都立即返回，最终 slow 从 Hub 中移除
+//  4. 广播一条消息，断言正常读取的 fast 客户端收到
+func TestHub_SlowClientDropped(t *testing.T) {
+	hub := NewHub()
+	url := startServer(t, hub, WithSendQueueSize(2), WithWriteWait(200*time.Millisecond))
+	dial(t, hub, url, "slow")
+	fast := dial(t, hub, url, "fast")
+
+	payload := []byte(strings.Repeat("x", 64*1024))
+	var sendErr error
+	for i := 0; i < 10000 && sendErr == nil; i++ {
+		start := time.Now()
+		sendErr = hub.SendTo("slow", payload)
+		require.Less(t, time.Since(start), 100*time.Millisecond, "推送不应被慢客户端阻塞")
+	}
+	require.Error(t, sendErr)
+	assert.True(t, errors.Is(sendErr, ErrSendQueueFull) || errors.Is(sendErr, ErrConnClosed) || errors.Is(sendErr, ErrUserOffline))
+	assert.Eventually(t, func() bool { return !hub.IsOnline("slow") }, 2*time.Second, 10*time.Millisecond)
+
+	hub.Broadcast([]byte("after"))
+	msg, err := readText(fast, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "after", msg)
+	assert.Equal(t, 1, hub.Count())
+}
+
+// TestHub_HooksAndShutdown 测试连接回调与关闭
+//
+// 【功能点】验证连接建立和断开时调用回调；Shutdown 向客户端发送 1001 关闭帧、清空连接并拒绝新连接
+// 【测试流程】
+//  1. 注册 OnConnect / OnDisconnect 回调，建立两个连接，断言 OnConnect 调用 2 次
+//  2. 客户端主动关闭一个连接，断言 OnDisconnect 调用 1 次
+//  3. 调用 Shutdown，断言另一个客户端收到 CloseGoingAway，连接数为 0
+//  4. 再次连接，断言握手失败且响应 503
+func TestHub_HooksAndShutdown(t *testing.T) {
+	hub := NewHub()
+	var connected, disconnected atomic.Int32
+	hub.OnConnect(func(conn *Conn) { connected.Add(1) })
+	hub.OnDisconnect(func(conn *Conn) { disconnected.Add(1) })
+	url := startServer(t, hub)
+
+	first := dial(t, hub, url, "alice")
+	second := dial(t, hub, url, "bob")
+	assert.Equal(t, int32(2), connected.Load())
+
+	require.NoError(t, first.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+	assert.Eventually(t, func() bool { return disconnected.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, hub.Shutdown(ctx))
+	assert.Equal(t, 0, hub.Count())
+	assert.Equal(t, int32(2), disconnected.Load())
+
+	_, err := readText(second, time.Second)
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "应收到 1001 关闭帧, err: %v", err)
+
+	_, resp, err := websocket.DefaultDialer.Dial(url+"?user=carol", nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+// TestHandler_Reject 测试拒绝升级
+//
+// 【功能点】验证用户 ID 提取失败返回 401；开启 CORS 时来源不在 allowOrigins 中返回 403，在列表中允许连接
+// 【测试流程】
+//  1. UserIDFunc 返回错误，断言握手失败且响应 401
+//  2. 设置 cors.allowOrigins 为 *.example.com，分别以 evil.com 和 app.example.com 作为 Origin 连接
+//  3. 断言前者响应 403，后者连接成功
+func TestHandler_Reject(t *testing.T) {
+	hub := NewHub()
+	url := startServer(t, hub, WithUserIDFunc(func(c *gin.Context) (string, error) {
+		if c.Query("token") == "" {
+			return "", errors.New("缺少 token")
+		}
+		return "alice", nil
+	}))
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	originalConfig := app.BaseConfig
+	app.BaseConfig = config.BaseConfig{CORS: config.CORSConfig{Enabled: true, AllowOrigins: []string{"*.example.com"}}}
+	t.Cleanup(func() { app.BaseConfig = originalConfig })
+
+	_, resp, err = websocket.DefaultDialer.Dial(url+"?token=t", http.Header{"Origin": []string{"https://evil.com"}})
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token=t", http.Header{"Origin": []string{"https://app.example.com"}})
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Eventually(t, func() bool { return hub.IsOnline("alice") }, time.Second, 5*time.Millisecond)
+}